@@ -7,14 +7,17 @@ import (
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/app/batch"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/app/config"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/logging"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/buildinfo"
 )
 
 // main は設定を読み込んでロガーを設定し、batch.Run の戻り値で os.Exit するだけの薄いラッパー。
 // os.Exit は defer を実行しないため、後処理が走るよう実体は internal/app/batch に分離している。
 func main() {
 	cfg, err := config.LoadBatch()
-	logger := slog.New(logging.NewHandler(os.Stdout, cfg.Log.Level, cfg.Log.UseJSON))
+	logger := slog.New(logging.New(os.Stdout, cfg.Log.Options()))
 	slog.SetDefault(logger)
+	// どのビルドでジョブを実行したかを最初の行に残す（取り込んだデータの問題とデプロイを突き合わせるため）
+	slog.Info("starting batch", buildinfo.Get().LogAttrs()...)
 	for _, w := range cfg.Warnings {
 		slog.Warn(w)
 	}
@@ -0,0 +1,54 @@
+// Command lambda は API サーバーを AWS Lambda（API Gateway HTTP API の背後）で動かすエントリポイント。
+// 低トラフィックのリージョンで常駐サーバーを置かずに同じ API を提供するために使う。
+//
+// サーバーの組み立て（DB 接続・Redis クライアント・外部 API クライアント）はコールドスタート時に 1 回だけ行い、
+// 同じ実行環境での以降の呼び出しは接続ごと再利用する。WebSocket（/v1/ws）は API Gateway の HTTP API では使えない。
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"github.com/aws/aws-lambda-go/lambda"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/app/config"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/app/server"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/logging"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/buildinfo"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/apigw"
+)
+
+func main() {
+	cfg, err := config.LoadAPI()
+	logger := slog.New(logging.New(os.Stdout, cfg.Log.Options()))
+	slog.SetDefault(logger)
+	slog.Info("starting lambda", buildinfo.Get().LogAttrs()...)
+	for _, w := range cfg.Warnings {
+		slog.Warn(w)
+	}
+	if err != nil {
+		slog.Error("invalid configuration", "error", err)
+		os.Exit(2)
+	}
+
+	app, cleanup, err := server.New(cfg, server.Deps{})
+	if err != nil {
+		slog.Error("failed to set up server", "error", err)
+		os.Exit(1)
+	}
+
+	// バックグラウンド処理は呼び出しの間は実行環境ごと凍結され、次の呼び出しで再開する。
+	// 実行環境の終了（SIGTERM）で止め、接続を閉じる。
+	ctx, cancel := context.WithCancel(context.Background())
+	go app.Run(ctx)
+
+	lambda.StartWithOptions(apigw.Handler(app.Handler),
+		lambda.WithEnableSIGTERM(func() {
+			cancel()
+			if err := cleanup(); err != nil {
+				slog.Warn("failed to clean up", "error", err)
+			}
+		}),
+	)
+}
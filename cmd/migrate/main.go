@@ -13,7 +13,7 @@ import (
 // os.Exit は defer を実行しないため、後処理が走るよう実体は internal/app/migrate に分離している。
 func main() {
 	cfg, err := config.LoadMigrate()
-	logger := slog.New(logging.NewHandler(os.Stdout, cfg.Log.Level, cfg.Log.UseJSON))
+	logger := slog.New(logging.New(os.Stdout, cfg.Log.Options()))
 	slog.SetDefault(logger)
 	for _, w := range cfg.Warnings {
 		slog.Warn(w)
@@ -1,4 +1,4 @@
-// Package db はマイグレーション SQL ファイルを Go バイナリに埋め込むためのパッケージです。
+// Package db はマイグレーション SQL ファイルと開発用の初期データを Go バイナリに埋め込むためのパッケージです。
 // 実行時のファイル配置に依存せず、cmd/migrate などから embed.FS 経由でマイグレーションを参照します。
 package db
 
@@ -7,6 +7,9 @@ import "embed"
 //go:embed migrations/*.sql
 var migrationsFS embed.FS
 
+//go:embed seed/symbols.csv
+var seedSymbolsCSV []byte
+
 // MigrationsFS は db/migrations/*.sql を含む embed.FS を返します。
 // goose.SetBaseFS に渡して使用します。
 func MigrationsFS() embed.FS {
@@ -16,3 +19,9 @@ func MigrationsFS() embed.FS {
 // MigrationsDir は embed.FS 内のマイグレーションディレクトリパスです。
 // goose の dir 引数に渡します。
 const MigrationsDir = "migrations"
+
+// SeedSymbolsCSV は開発用の標準銘柄（db/seed/symbols.csv。列は code,name,market,timezone,currency）を返します。
+// batch seed ジョブが使います。docker compose の seed サービス（seed.sql）と同じ銘柄を保つこと。
+func SeedSymbolsCSV() []byte {
+	return seedSymbolsCSV
+}
@@ -0,0 +1,17 @@
+-- +goose Up
+
+-- ingest バッチが (interval, market) 単位で書き込むデータ鮮度マーカー。
+-- last_success_at は最後に全銘柄の取り込みが成功した日時、last_attempt_at は成否を問わない最終試行日時。
+CREATE TABLE data_freshness (
+    "interval"      VARCHAR(16)  NOT NULL,
+    market          VARCHAR(100) NOT NULL,
+    last_success_at TIMESTAMPTZ,
+    last_attempt_at TIMESTAMPTZ  NOT NULL,
+    last_error      TEXT,
+    updated_at      TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    PRIMARY KEY ("interval", market)
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS data_freshness;
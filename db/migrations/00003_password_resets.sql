@@ -0,0 +1,17 @@
+-- +goose Up
+
+-- パスワードリセットトークン。平文トークンは保存せず SHA-256 ハッシュのみを保持する。
+-- 1 ユーザーにつき有効なトークンは最新の 1 件のみ（発行時に既存分を削除する）。使用時に行を削除して単回使用とする。
+CREATE TABLE password_resets (
+    token_hash      BYTEA       PRIMARY KEY,
+    user_id         BIGINT      NOT NULL,
+    expires_at      TIMESTAMPTZ NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT fk_password_resets_user
+        FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX idx_password_resets_user_id ON password_resets (user_id);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS password_resets;
@@ -0,0 +1,12 @@
+-- +goose Up
+
+-- 銘柄の取引通貨（ISO 4217、例: USD, JPY）。価格の通貨換算（?currency=）で換算元として使う。
+-- 未設定（NULL）の銘柄は換算せずに警告付きで返す。既存行は市場から補完する。
+ALTER TABLE symbols ADD COLUMN currency CHAR(3);
+
+UPDATE symbols SET currency = 'USD' WHERE market IN ('NASDAQ', 'NYSE');
+UPDATE symbols SET currency = 'JPY' WHERE market = 'TSE';
+
+-- +goose Down
+
+ALTER TABLE symbols DROP COLUMN IF EXISTS currency;
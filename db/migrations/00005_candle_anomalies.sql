@@ -0,0 +1,28 @@
+-- +goose Up
+
+-- ingest で検出した日足終値の急変（株式分割・外部APIの誤データ）。管理者が確認して status を更新する。
+-- (symbol_code, "time") で一意とし、同じ日足を再検出しても確認状態を保持する。
+-- 分割の確認時に履歴の再取得を要求すると backfill_requested_at を設定し、backfill バッチの完了時に backfilled_at を設定する。
+CREATE TABLE candle_anomalies (
+    id                    BIGSERIAL        PRIMARY KEY,
+    symbol_code           VARCHAR(20)      NOT NULL,
+    "time"                TIMESTAMPTZ      NOT NULL,
+    prev_close            NUMERIC(15,4)    NOT NULL,
+    close                 NUMERIC(15,4)    NOT NULL,
+    ratio                 DOUBLE PRECISION NOT NULL,
+    status                VARCHAR(32)      NOT NULL DEFAULT 'pending',
+    detected_at           TIMESTAMPTZ      NOT NULL DEFAULT now(),
+    resolved_at           TIMESTAMPTZ,
+    backfill_requested_at TIMESTAMPTZ,
+    backfilled_at         TIMESTAMPTZ,
+    CONSTRAINT uq_candle_anomalies_symbol_time UNIQUE (symbol_code, "time"),
+    CONSTRAINT chk_candle_anomalies_status
+        CHECK (status IN ('pending', 'confirmed_split', 'confirmed_bad_data')),
+    CONSTRAINT fk_candle_anomalies_symbol
+        FOREIGN KEY (symbol_code) REFERENCES symbols(code) ON DELETE RESTRICT
+);
+CREATE INDEX idx_candle_anomalies_status_detected ON candle_anomalies (status, detected_at DESC);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS candle_anomalies;
@@ -0,0 +1,21 @@
+-- +goose Up
+
+-- 株式分割等の調整係数。保存済みのローソク足は未調整のまま保持し、読み取り時に適用する。
+-- effective_date より前の日足の O/H/L/C に factor を掛け、出来高を factor で割る（2対1分割なら factor = 0.5）。
+CREATE TABLE candle_adjustments (
+    id             BIGSERIAL        PRIMARY KEY,
+    symbol_code    VARCHAR(20)      NOT NULL,
+    effective_date DATE             NOT NULL,
+    factor         DOUBLE PRECISION NOT NULL,
+    reason         VARCHAR(255)     NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ      NOT NULL DEFAULT now(),
+    updated_at     TIMESTAMPTZ      NOT NULL DEFAULT now(),
+    CONSTRAINT uq_candle_adjustments_symbol_date UNIQUE (symbol_code, effective_date),
+    CONSTRAINT chk_candle_adjustments_factor CHECK (factor > 0),
+    CONSTRAINT fk_candle_adjustments_symbol
+        FOREIGN KEY (symbol_code) REFERENCES symbols(code) ON DELETE CASCADE
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS candle_adjustments;
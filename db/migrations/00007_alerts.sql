@@ -0,0 +1,26 @@
+-- +goose Up
+
+-- 価格アラート。ingest で最新の足が閾値を横切ったときに一度だけ発火する（triggered_at を記録して以降は評価しない）。
+-- direction: above（下から上へ横切る）/ below（上から下へ横切る）
+CREATE TABLE alerts (
+    id           BIGSERIAL        PRIMARY KEY,
+    user_id      BIGINT           NOT NULL,
+    symbol_code  VARCHAR(20)      NOT NULL,
+    "interval"   VARCHAR(16)      NOT NULL,
+    direction    VARCHAR(8)       NOT NULL,
+    threshold    DOUBLE PRECISION NOT NULL,
+    created_at   TIMESTAMPTZ      NOT NULL DEFAULT now(),
+    triggered_at TIMESTAMPTZ,
+    CONSTRAINT chk_alerts_direction CHECK (direction IN ('above', 'below')),
+    CONSTRAINT fk_alerts_user
+        FOREIGN KEY (user_id)     REFERENCES users(id)     ON DELETE CASCADE,
+    CONSTRAINT fk_alerts_symbol
+        FOREIGN KEY (symbol_code) REFERENCES symbols(code) ON DELETE CASCADE
+);
+-- ingest の評価（銘柄・時間間隔ごとの未発火アラートの取得）用。発火済みの行は含めない部分インデックス。
+CREATE INDEX idx_alerts_active_symbol_interval ON alerts (symbol_code, "interval") WHERE triggered_at IS NULL;
+CREATE INDEX idx_alerts_user_id ON alerts (user_id);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS alerts;
@@ -0,0 +1,21 @@
+-- +goose Up
+
+-- 銘柄名の多言語表記。symbols.name は既定ロケール（日本語）の名前で、ここには他ロケールの名前を保持する。
+-- 要求されたロケールの行がない場合は en、それもなければ symbols.name を使う（symbollist.LocalizedName）。
+CREATE TABLE symbol_names (
+    symbol_code VARCHAR(20)  NOT NULL,
+    locale      VARCHAR(8)   NOT NULL,
+    name        VARCHAR(255) NOT NULL,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    PRIMARY KEY (symbol_code, locale),
+    CONSTRAINT fk_symbol_names_symbol
+        FOREIGN KEY (symbol_code) REFERENCES symbols(code) ON DELETE CASCADE
+);
+
+-- 銘柄一覧はロケール単位でまとめて読み込むため
+CREATE INDEX idx_symbol_names_locale ON symbol_names (locale);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS symbol_names;
@@ -0,0 +1,15 @@
+-- +goose Up
+
+-- ローソク足の書き込み日時。as-of クエリ（?as_of=、バックテストの再現）で「その時点で保存されていた足」を絞り込む。
+-- created_at は最初の挿入日時、updated_at は値（OHLCV）が最後に変わった日時で、同じ値の再取り込みでは更新しない。
+-- 既存行の書き込み日時は分からないため、マイグレーション実行時刻（now() はトランザクション内で一定）で埋める。
+-- そのためマイグレーションより前の時点を指定した as-of クエリは既存の足を返さない（過大に返すより安全側に倒す）。
+ALTER TABLE candles
+    ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+-- +goose Down
+
+ALTER TABLE candles
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS created_at;
@@ -0,0 +1,12 @@
+-- +goose Up
+
+-- 銘柄の取り込み優先度（1 が最優先）。ingest は優先度の高い段（tier）から順に取り込み、
+-- レート制限やタイムアウトで打ち切られても重要な銘柄のデータが先に揃うようにする。
+-- 既存行・未指定の銘柄は標準の 3 とする。
+ALTER TABLE symbols
+    ADD COLUMN priority SMALLINT NOT NULL DEFAULT 3
+        CONSTRAINT symbols_priority_positive CHECK (priority >= 1);
+
+-- +goose Down
+
+ALTER TABLE symbols DROP COLUMN IF EXISTS priority;
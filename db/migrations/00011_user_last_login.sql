@@ -0,0 +1,9 @@
+-- +goose Up
+
+-- ユーザーの最終ログイン日時（パスワード・OAuth のログイン成功時に更新）。
+-- 管理者がサポート対応でユーザーを調べる際の手掛かりにする。未ログインのユーザーは NULL。
+ALTER TABLE users ADD COLUMN last_login_at TIMESTAMPTZ;
+
+-- +goose Down
+
+ALTER TABLE users DROP COLUMN IF EXISTS last_login_at;
@@ -0,0 +1,24 @@
+-- +goose Up
+
+-- チャートの注記。ユーザーが銘柄・時間間隔ごとのローソク足の日付に付けるメモ（「決算好調」「ここで購入」等）。
+-- "time" はローソク足と同じく UTC の 0 時で保持する。他のユーザーの注記は参照できないよう、全クエリで user_id を条件に含める。
+CREATE TABLE annotations (
+    id          BIGSERIAL    PRIMARY KEY,
+    user_id     BIGINT       NOT NULL,
+    symbol_code VARCHAR(20)  NOT NULL,
+    "interval"  VARCHAR(16)  NOT NULL,
+    "time"      TIMESTAMPTZ  NOT NULL,
+    text        TEXT         NOT NULL,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    CONSTRAINT fk_annotations_user
+        FOREIGN KEY (user_id)     REFERENCES users(id)     ON DELETE CASCADE,
+    CONSTRAINT fk_annotations_symbol
+        FOREIGN KEY (symbol_code) REFERENCES symbols(code) ON DELETE CASCADE
+);
+-- ユーザー・銘柄ごとの一覧（ID のカーソルでページング）用。
+CREATE INDEX idx_annotations_user_symbol ON annotations (user_id, symbol_code, id);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS annotations;
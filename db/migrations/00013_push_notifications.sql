@@ -0,0 +1,62 @@
+-- +goose Up
+
+-- プッシュ通知の送信先（FCM の登録トークン）。トークンは端末・アプリのインストールごとに一意で、
+-- 同じトークンの再登録は上書きする（端末のログインユーザーが変わった場合は user_id も付け替える）。
+-- プロバイダーが無効と応答したトークンは disabled_at を記録して以降は送らない（再登録で有効に戻る）。
+-- platform: ios / android / web
+CREATE TABLE push_devices (
+    id          BIGSERIAL    PRIMARY KEY,
+    user_id     BIGINT       NOT NULL,
+    token       TEXT         NOT NULL,
+    platform    VARCHAR(16)  NOT NULL,
+    app_version VARCHAR(32)  NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    disabled_at TIMESTAMPTZ,
+    CONSTRAINT uq_push_devices_token UNIQUE (token),
+    CONSTRAINT chk_push_devices_platform CHECK (platform IN ('ios', 'android', 'web')),
+    CONSTRAINT fk_push_devices_user
+        FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+-- 通知の登録（ユーザーの有効な端末の取得）用。
+CREATE INDEX idx_push_devices_user_active ON push_devices (user_id) WHERE disabled_at IS NULL;
+
+-- 送信待ちの通知。発火したアラートごとに、ユーザーの有効な端末ごとの 1 行を登録する（端末ごとに再試行する）。
+-- API のディスパッチャーが next_attempt_at を過ぎた pending の行を FOR UPDATE SKIP LOCKED で取得して送信する。
+-- 取得時に attempts を増やし next_attempt_at を送信の期限（lease）まで進めるため、送信中に停止した行は期限後に再取得される。
+-- status: pending（送信待ち・再試行待ち）/ sent（送信済み）/ failed（上限まで失敗・無効なトークン等で諦めた）
+CREATE TABLE push_jobs (
+    id              BIGSERIAL    PRIMARY KEY,
+    alert_id        BIGINT       NOT NULL,
+    device_id       BIGINT       NOT NULL,
+    title           TEXT         NOT NULL,
+    body            TEXT         NOT NULL,
+    data            TEXT         NOT NULL DEFAULT '{}',
+    status          VARCHAR(8)   NOT NULL DEFAULT 'pending',
+    attempts        INTEGER      NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    last_error      TEXT,
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    completed_at    TIMESTAMPTZ,
+    CONSTRAINT uq_push_jobs_alert_device UNIQUE (alert_id, device_id),
+    CONSTRAINT chk_push_jobs_status CHECK (status IN ('pending', 'sent', 'failed')),
+    CONSTRAINT fk_push_jobs_alert
+        FOREIGN KEY (alert_id)  REFERENCES alerts(id)       ON DELETE CASCADE,
+    CONSTRAINT fk_push_jobs_device
+        FOREIGN KEY (device_id) REFERENCES push_devices(id) ON DELETE CASCADE
+);
+-- ディスパッチャーの取得（期限を過ぎた送信待ち）用。送信済み・失敗の行は含めない部分インデックス。
+CREATE INDEX idx_push_jobs_due ON push_jobs (next_attempt_at) WHERE status = 'pending';
+
+-- 通知の配信結果。notified_at はいずれかの端末に送信できた日時、notify_error は最後に諦めた送信の理由。
+ALTER TABLE alerts
+    ADD COLUMN notified_at  TIMESTAMPTZ,
+    ADD COLUMN notify_error TEXT;
+
+-- +goose Down
+
+ALTER TABLE alerts
+    DROP COLUMN IF EXISTS notify_error,
+    DROP COLUMN IF EXISTS notified_at;
+DROP TABLE IF EXISTS push_jobs;
+DROP TABLE IF EXISTS push_devices;
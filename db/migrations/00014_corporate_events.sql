@@ -0,0 +1,26 @@
+-- +goose Up
+
+-- 銘柄のコーポレートイベント（配当の権利落ち日・決算発表日）。チャートへの表示用に batch events が週次で取り込む。
+-- 同じ銘柄・種類・日付のイベントは 1 行で、再取り込みは (symbol_code, type, "date") をキーに上書きする。
+-- value は配当なら 1 株当たりの配当額、決算なら EPS の実績（未発表は NULL）。estimate は決算の EPS の予想（配当は NULL）。
+CREATE TABLE corporate_events (
+    id          BIGSERIAL      PRIMARY KEY,
+    symbol_code VARCHAR(20)    NOT NULL,
+    type        VARCHAR(16)    NOT NULL,
+    "date"      DATE           NOT NULL,
+    value       NUMERIC(15,4),
+    estimate    NUMERIC(15,4),
+    source      VARCHAR(32)    NOT NULL,
+    created_at  TIMESTAMPTZ    NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ    NOT NULL DEFAULT now(),
+    CONSTRAINT uq_corporate_events_symbol_type_date UNIQUE (symbol_code, type, "date"),
+    CONSTRAINT chk_corporate_events_type CHECK (type IN ('dividend', 'earnings')),
+    CONSTRAINT fk_corporate_events_symbol
+        FOREIGN KEY (symbol_code) REFERENCES symbols(code) ON DELETE CASCADE
+);
+-- 銘柄ごとの日付範囲の取得（GET /v1/symbols/{code}/events・ローソク足の重ね合わせ）用。
+CREATE INDEX idx_corporate_events_symbol_date ON corporate_events (symbol_code, "date");
+
+-- +goose Down
+
+DROP TABLE IF EXISTS corporate_events;
@@ -0,0 +1,21 @@
+-- +goose Up
+
+-- 銘柄ごとの日足の要約統計のロールアップ（管理画面のダッシュボードで全銘柄をまとめて表示する用）。
+-- batch candles が日足の値の変わった銘柄について、保存後に ComputeStats で再計算して上書きする（1 銘柄 1 行）。
+-- as_of は集計基準の最新の日足の時刻、computed_at は再計算した日時。GET /v1/stats は computed_at が古い行を都度計算に切り替える。
+-- ytd_change_pct は基準値が算出できない場合 NULL。
+CREATE TABLE symbol_daily_stats (
+    symbol_code    VARCHAR(20)      PRIMARY KEY,
+    as_of          TIMESTAMPTZ      NOT NULL,
+    high_52w       NUMERIC(15,4)    NOT NULL,
+    low_52w        NUMERIC(15,4)    NOT NULL,
+    avg_vol_30d    DOUBLE PRECISION NOT NULL,
+    ytd_change_pct DOUBLE PRECISION,
+    computed_at    TIMESTAMPTZ      NOT NULL,
+    CONSTRAINT fk_symbol_daily_stats_symbol
+        FOREIGN KEY (symbol_code) REFERENCES symbols(code) ON DELETE CASCADE
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS symbol_daily_stats;
@@ -0,0 +1,26 @@
+-- +goose Up
+
+-- ウォッチリストの日次ダイジェストメールの購読（オプトイン）。行があるユーザーだけに送る（既定は送らない）。
+CREATE TABLE digest_subscriptions (
+    user_id    BIGINT      PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT fk_digest_subscriptions_user
+        FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- 送信済みのダイジェスト（ユーザー・対象日ごとに 1 行）。batch digest が送信前に行を挿入して送信権を取り、
+-- 挿入できなかった（送信済み・他の実行が送信中）ユーザーには送らないため、再実行しても二重に送らない。
+-- 送信に失敗した場合は行を削除し、次回の実行で再送する。digest_date は対象の日足の日付（市場のローカル日付）。
+CREATE TABLE digest_sends (
+    user_id     BIGINT      NOT NULL,
+    digest_date DATE        NOT NULL,
+    sent_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, digest_date),
+    CONSTRAINT fk_digest_sends_user
+        FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS digest_sends;
+DROP TABLE IF EXISTS digest_subscriptions;
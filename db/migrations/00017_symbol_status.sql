@@ -0,0 +1,43 @@
+-- +goose Up
+
+-- 銘柄の状態（is_active の真偽値を 3 状態に置き換える）。
+--   active   : 取り込み・一覧の対象
+--   delisted : 上場廃止。取り込みと一覧からは外すが、保存済みのローソク足は /candles で引き続き返す
+--   hidden   : すべての API で存在しない銘柄として扱う（従来の is_active = FALSE）
+-- status_since は状態の効力発生日（delisted では上場廃止日）。既存行・未指定は NULL。
+ALTER TABLE symbols
+    ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'active'
+        CONSTRAINT symbols_status_valid CHECK (status IN ('active', 'delisted', 'hidden')),
+    ADD COLUMN status_since DATE;
+
+UPDATE symbols SET status = 'hidden' WHERE NOT is_active;
+
+-- 移行期間中は is_active を status に追従させる（is_active = (status = 'active')）。
+-- is_active だけを書き換える従来の書き込み（手作業の SQL・テストのデータ投入）は、status を active / hidden に読み替える。
+-- +goose StatementBegin
+CREATE FUNCTION symbols_sync_is_active() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.status IS NOT DISTINCT FROM OLD.status
+        AND NEW.is_active IS DISTINCT FROM OLD.is_active THEN
+        NEW.status := CASE WHEN NEW.is_active THEN 'active' ELSE 'hidden' END;
+    ELSIF TG_OP = 'INSERT' AND NOT NEW.is_active AND NEW.status = 'active' THEN
+        NEW.status := 'hidden';
+    END IF;
+    NEW.is_active := NEW.status = 'active';
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER symbols_sync_is_active
+    BEFORE INSERT OR UPDATE ON symbols
+    FOR EACH ROW EXECUTE FUNCTION symbols_sync_is_active();
+
+-- +goose Down
+
+DROP TRIGGER IF EXISTS symbols_sync_is_active ON symbols;
+DROP FUNCTION IF EXISTS symbols_sync_is_active();
+UPDATE symbols SET is_active = (status = 'active');
+ALTER TABLE symbols
+    DROP COLUMN IF EXISTS status_since,
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,33 @@
+-- +goose Up
+
+-- トランザクショナルアウトボックス。DB への書き込みと同じトランザクションで、書き込みに伴うイベント
+-- （アラートの評価・キャッシュの破棄等）を 1 行ずつ登録する。書き込みの直後にプロセスが停止しても、
+-- イベントはコミット済みの行として残り、API のリレーが後から配信する。
+-- リレーは next_attempt_at を過ぎた pending の行を FOR UPDATE SKIP LOCKED で取得し、attempts を増やして
+-- next_attempt_at を処理の期限（lease）まで進める。処理中に停止した行は期限後に再取得される（少なくとも 1 回の配信）。
+-- 同じ aggregate_key の行は id の順に 1 件ずつ配信する（先の行が pending の間は後の行を取得しない）。
+-- status: pending（配信待ち・再試行待ち）/ done（配信済み）/ dead（上限まで失敗した。手動で調査する）
+CREATE TABLE outbox_events (
+    id              BIGSERIAL    PRIMARY KEY,
+    topic           VARCHAR(64)  NOT NULL,
+    aggregate_key   VARCHAR(128) NOT NULL,
+    payload         TEXT         NOT NULL,
+    status          VARCHAR(8)   NOT NULL DEFAULT 'pending',
+    attempts        INTEGER      NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    claimed_by      VARCHAR(64),
+    last_error      TEXT,
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    completed_at    TIMESTAMPTZ,
+    CONSTRAINT chk_outbox_events_status CHECK (status IN ('pending', 'done', 'dead'))
+);
+-- リレーの取得（期限を過ぎた配信待ち）用。
+CREATE INDEX idx_outbox_events_due ON outbox_events (next_attempt_at) WHERE status = 'pending';
+-- 同じ aggregate_key の先の配信待ちの有無の確認用。
+CREATE INDEX idx_outbox_events_key ON outbox_events (aggregate_key, id) WHERE status = 'pending';
+-- 配信済みの行の削除（保持期間の経過後）用。
+CREATE INDEX idx_outbox_events_done ON outbox_events (completed_at) WHERE status = 'done';
+
+-- +goose Down
+
+DROP TABLE IF EXISTS outbox_events;
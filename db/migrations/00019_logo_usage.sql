@@ -0,0 +1,19 @@
+-- +goose Up
+
+-- ロゴ検出・企業分析の利用回数（ユーザー・操作・UTC の日付ごとに 1 行）。通常は Redis の日次キーで数え、
+-- Redis に接続できない間だけこのテーブルで数える（フォールバック）。成功した呼び出しのみ数える。
+-- operation: detect（ロゴ検出）/ analyze（企業分析）
+CREATE TABLE logo_usage (
+    user_id    BIGINT      NOT NULL,
+    operation  VARCHAR(16) NOT NULL,
+    usage_date DATE        NOT NULL,
+    count      INTEGER     NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, operation, usage_date),
+    CONSTRAINT fk_logo_usage_user
+        FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS logo_usage;
@@ -0,0 +1,28 @@
+-- +goose Up
+
+-- 再アーム（re_arm）のアラート。one_shot は従来どおり一度発火すると triggered_at を記録して以降は評価しない。
+-- re_arm は triggered_at を記録せず、終値が閾値の反対側へ戻り、かつ前回の発火から cooldown_minutes 経過した後の
+-- 横切りで再び発火する（last_triggered_at: 最後に発火した日時、last_side: 最後に観測した終値の閾値に対する側）。
+ALTER TABLE alerts
+    ADD COLUMN mode              VARCHAR(8)  NOT NULL DEFAULT 'one_shot',
+    ADD COLUMN cooldown_minutes  INTEGER     NOT NULL DEFAULT 0,
+    ADD COLUMN last_triggered_at TIMESTAMPTZ,
+    ADD COLUMN last_side         VARCHAR(8),
+    ADD CONSTRAINT chk_alerts_mode CHECK (mode IN ('one_shot', 're_arm')),
+    ADD CONSTRAINT chk_alerts_cooldown
+        CHECK (cooldown_minutes BETWEEN 0 AND 43200 AND (mode = 're_arm' OR cooldown_minutes = 0)),
+    ADD CONSTRAINT chk_alerts_last_side CHECK (last_side IN ('above', 'below'));
+
+-- 発火済みの one_shot は最後に発火した日時を引き継ぐ（re_arm へ切り替えた場合のクールダウンの起点）
+UPDATE alerts SET last_triggered_at = triggered_at WHERE triggered_at IS NOT NULL;
+
+-- +goose Down
+
+ALTER TABLE alerts
+    DROP CONSTRAINT IF EXISTS chk_alerts_last_side,
+    DROP CONSTRAINT IF EXISTS chk_alerts_cooldown,
+    DROP CONSTRAINT IF EXISTS chk_alerts_mode,
+    DROP COLUMN IF EXISTS last_side,
+    DROP COLUMN IF EXISTS last_triggered_at,
+    DROP COLUMN IF EXISTS cooldown_minutes,
+    DROP COLUMN IF EXISTS mode;
@@ -0,0 +1,24 @@
+-- +goose Up
+
+-- 定期実行のジョブ（internal/infra/scheduler）の実行予定と直近の結果（ジョブ名ごとに 1 行）。
+-- API の各インスタンスが同じ行を読み、Redis のロックを取得したインスタンスが next_run_at を条件付きで進めてから実行する
+-- （同じ予定を二重に実行しない）。run_requested_at は管理 API の即時実行の依頼で、次の確認でいずれかのインスタンスが実行する。
+-- last_status: succeeded / failed / timed_out（実行前は NULL）
+CREATE TABLE scheduled_jobs (
+    name             VARCHAR(64)  PRIMARY KEY,
+    next_run_at      TIMESTAMPTZ  NOT NULL,
+    run_requested_at TIMESTAMPTZ,
+    last_started_at  TIMESTAMPTZ,
+    last_finished_at TIMESTAMPTZ,
+    last_status      VARCHAR(16),
+    last_error       TEXT,
+    last_duration_ms BIGINT       NOT NULL DEFAULT 0,
+    run_count        BIGINT       NOT NULL DEFAULT 0,
+    failure_count    BIGINT       NOT NULL DEFAULT 0,
+    updated_at       TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    CONSTRAINT chk_scheduled_jobs_last_status CHECK (last_status IN ('succeeded', 'failed', 'timed_out'))
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS scheduled_jobs;
@@ -0,0 +1,18 @@
+-- +goose Up
+
+-- ユーザーごとのリソース（resource: watchlist / digest）の楽観的同時実行制御のバージョン。
+-- 書き込みのたびに 1 ずつ増やし、GET の ETag として返す（行がなければ 0）。If-Match 付きの更新は
+-- WHERE version = <クライアントが見たバージョン> の条件付き UPDATE で増やし、0 行なら 412 にする
+-- （同じ行のロックで並行した条件付き更新は直列化され、成功するのは 1 つだけ）。
+CREATE TABLE user_resource_versions (
+    user_id  BIGINT      NOT NULL,
+    resource VARCHAR(32) NOT NULL,
+    version  BIGINT      NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, resource),
+    CONSTRAINT fk_user_resource_versions_user
+        FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS user_resource_versions;
@@ -0,0 +1,17 @@
+-- +goose Up
+
+-- ローソク足の取り込み元。複数の取り込み経路（TwelveData・CSV・日足からの集計）が同じテーブルに書くため、
+-- 値の食い違いを調べる際に足ごとの出どころを分かるようにする。"<種別>" または "<種別>:<詳細>"（例: csv:1a2b3c4d5e6f）。
+-- 上書きは取り込み元の優先順位（CANDLES_SOURCE_PRECEDENCE）が同じか高い場合のみ行う。
+-- 既存の足は日足を TwelveData、週足・月足を日足からの集計（ingest が常に集計して保存していた）とみなして埋める。
+ALTER TABLE candles
+    ADD COLUMN source VARCHAR(64) NOT NULL DEFAULT 'twelvedata';
+
+UPDATE candles
+SET source = 'derived'
+WHERE "interval" IN ('1week', '1month');
+
+-- +goose Down
+
+ALTER TABLE candles
+    DROP COLUMN IF EXISTS source;
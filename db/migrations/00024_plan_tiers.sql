@@ -0,0 +1,20 @@
+-- +goose Up
+
+-- 料金プランとデータの区分。free のユーザーは basic の銘柄だけを参照でき、premium の銘柄の
+-- ローソク足・統計・スパークラインは 402（upgrade_required）になる。premium のユーザーはすべて参照できる。
+-- 既存のユーザーは free、既存の銘柄は basic とする（free で参照できる銘柄は basic として 10 銘柄程度を運用で指定する）。
+ALTER TABLE users
+    ADD COLUMN plan VARCHAR(16) NOT NULL DEFAULT 'free'
+        CONSTRAINT users_plan_valid CHECK (plan IN ('free', 'premium'));
+
+ALTER TABLE symbols
+    ADD COLUMN tier VARCHAR(16) NOT NULL DEFAULT 'basic'
+        CONSTRAINT symbols_tier_valid CHECK (tier IN ('basic', 'premium'));
+
+-- +goose Down
+
+ALTER TABLE symbols
+    DROP COLUMN IF EXISTS tier;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS plan;
@@ -0,0 +1,20 @@
+-- +goose Up
+
+-- 株式分割・併合の調整係数（candle_adjustments）の登録時に、銘柄の未発火のアラートの閾値を係数で書き換える。
+-- original_threshold: 最初の書き換え前にユーザーが設定した閾値（複数回の調整でも最初の値を残す）
+-- adjustment_id: 最後に閾値を書き換えた調整係数（同じ調整の再実行では書き換えない。調整の削除で NULL）
+-- edited_at: ユーザーが最後にアラートを変更した日時（効力発生日以降に変更したアラートは分割後の価格で設定したとみなして書き換えない）
+ALTER TABLE alerts
+    ADD COLUMN original_threshold DOUBLE PRECISION,
+    ADD COLUMN adjustment_id      BIGINT,
+    ADD COLUMN edited_at          TIMESTAMPTZ,
+    ADD CONSTRAINT fk_alerts_adjustment
+        FOREIGN KEY (adjustment_id) REFERENCES candle_adjustments(id) ON DELETE SET NULL;
+
+-- +goose Down
+
+ALTER TABLE alerts
+    DROP CONSTRAINT IF EXISTS fk_alerts_adjustment,
+    DROP COLUMN IF EXISTS edited_at,
+    DROP COLUMN IF EXISTS adjustment_id,
+    DROP COLUMN IF EXISTS original_threshold;
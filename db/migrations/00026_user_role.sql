@@ -0,0 +1,12 @@
+-- +goose Up
+
+-- ユーザーの権限。管理ルートのうちユーザー管理など管理者ユーザー向けのもの（authhttp.AdminRequired）には
+-- admin のユーザーだけが到達できる（APIキーでは到達できない）。既存のユーザーは user とし、admin は運用者が SQL で付与する。
+ALTER TABLE users
+    ADD COLUMN role VARCHAR(16) NOT NULL DEFAULT 'user'
+        CONSTRAINT users_role_valid CHECK (role IN ('user', 'admin'));
+
+-- +goose Down
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS role;
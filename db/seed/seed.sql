@@ -1,5 +1,6 @@
 -- symbols の初期データ投入。INSERT ... ON CONFLICT による upsert のみで冪等。
 -- candles / watchlists 等の既存データは削除しないため、起動のたびに安全に再実行できる。
+-- 有効な銘柄は batch seed ジョブが埋め込む symbols.csv と揃えること。
 INSERT INTO symbols (code, name, market, timezone, created_at, updated_at) VALUES
 -- 時価総額上位（メガキャップ）
 ('NVDA', 'NVIDIA Corp', 'NASDAQ', 'America/New_York', NOW(), NOW()),
@@ -578,3 +579,7 @@ ON CONFLICT (code) DO UPDATE SET
   market = EXCLUDED.market,
   timezone = EXCLUDED.timezone,
   updated_at = NOW();
+
+-- 取引通貨は市場から補完する（個別に設定済みの値は上書きしない）。
+UPDATE symbols SET currency = 'USD' WHERE currency IS NULL AND market IN ('NASDAQ', 'NYSE');
+UPDATE symbols SET currency = 'JPY' WHERE currency IS NULL AND market = 'TSE';
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// 手書きの共通時刻型です。OpenAPI 仕様からは x-go-type: Date / Timestamp で参照します。
+// 日付のみか日時かの選択を型で明示し、ハンドラーごとの書式文字列の散在を防ぎます。
+
+// DateLayout は API の日付表現（YYYY-MM-DD）です。
+const DateLayout = time.DateOnly
+
+// TimestampLayout は API の日時表現（RFC 3339、UTC、秒精度）です。
+const TimestampLayout = time.RFC3339
+
+// jsonNull は JSON の null リテラルです。
+var jsonNull = []byte("null")
+
+// Date は日付のみを表す API 型です。
+// JSON では UTC の暦日を "YYYY-MM-DD" で表し、ゼロ値は null になります。
+type Date struct {
+	time.Time
+}
+
+// NewDate は t の UTC の暦日を表す Date を返します。t がゼロ値の場合はゼロ値の Date を返します。
+func NewDate(t time.Time) Date {
+	if t.IsZero() {
+		return Date{}
+	}
+	u := t.UTC()
+	return Date{time.Date(u.Year(), u.Month(), u.Day(), 0, 0, 0, 0, time.UTC)}
+}
+
+// ParseDate は "YYYY-MM-DD" を UTC の 0 時として解釈します。
+// クエリパラメータ等の入力もこの関数で解釈し、エラーメッセージを揃えます。
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %q: want YYYY-MM-DD", s)
+	}
+	return Date{t}, nil
+}
+
+// String は "YYYY-MM-DD" を返します。ゼロ値の場合は空文字です。
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.UTC().Format(DateLayout)
+}
+
+// MarshalJSON は Date を "YYYY-MM-DD" または null に変換します。
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return jsonNull, nil
+	}
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON は "YYYY-MM-DD" または null を Date に変換します。null はゼロ値になります。
+func (d *Date) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, jsonNull) {
+		*d = Date{}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("invalid date: %w", err)
+	}
+	v, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// Timestamp は日時を表す API 型です。
+// JSON では UTC の RFC 3339（秒精度、例: "2024-01-02T03:04:05Z"）で表し、ゼロ値は null になります。
+type Timestamp struct {
+	time.Time
+}
+
+// NewTimestamp は t を UTC・秒精度に正規化した Timestamp を返します。t がゼロ値の場合はゼロ値を返します。
+func NewTimestamp(t time.Time) Timestamp {
+	if t.IsZero() {
+		return Timestamp{}
+	}
+	return Timestamp{t.UTC().Truncate(time.Second)}
+}
+
+// ParseTimestamp は RFC 3339 の日時を解釈し、UTC に正規化します。
+// クエリパラメータ等の入力もこの関数で解釈し、エラーメッセージを揃えます。
+func ParseTimestamp(s string) (Timestamp, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return Timestamp{}, fmt.Errorf("invalid timestamp %q: want RFC 3339 (e.g. 2006-01-02T15:04:05Z)", s)
+	}
+	return NewTimestamp(t), nil
+}
+
+// String は RFC 3339（UTC）を返します。ゼロ値の場合は空文字です。
+func (ts Timestamp) String() string {
+	if ts.IsZero() {
+		return ""
+	}
+	return ts.UTC().Format(TimestampLayout)
+}
+
+// MarshalJSON は Timestamp を RFC 3339（UTC）または null に変換します。
+func (ts Timestamp) MarshalJSON() ([]byte, error) {
+	if ts.IsZero() {
+		return jsonNull, nil
+	}
+	return json.Marshal(ts.String())
+}
+
+// UnmarshalJSON は RFC 3339 または null を Timestamp に変換します。
+// オフセット付きの日時は UTC に正規化され、null はゼロ値になります。
+func (ts *Timestamp) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, jsonNull) {
+		*ts = Timestamp{}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("invalid timestamp: %w", err)
+	}
+	v, err := ParseTimestamp(s)
+	if err != nil {
+		return err
+	}
+	*ts = v
+	return nil
+}
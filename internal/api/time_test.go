@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDate_JSON(t *testing.T) {
+	t.Parallel()
+
+	jst := time.FixedZone("JST", 9*60*60)
+	tests := []struct {
+		name string
+		in   time.Time
+		want string
+	}{
+		{name: "UTC の暦日", in: time.Date(2024, 1, 15, 23, 59, 0, 0, time.UTC), want: `"2024-01-15"`},
+		{name: "オフセット付きは UTC の暦日に正規化", in: time.Date(2024, 1, 16, 8, 0, 0, 0, jst), want: `"2024-01-15"`},
+		{name: "ゼロ値は null", in: time.Time{}, want: `null`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			b, err := json.Marshal(NewDate(tt.in))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(b))
+
+			var got Date
+			require.NoError(t, json.Unmarshal(b, &got))
+			assert.Equal(t, NewDate(tt.in), got, "round-trip")
+		})
+	}
+}
+
+func TestDate_UnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	var d Date
+	require.NoError(t, json.Unmarshal([]byte(`"2024-02-29"`), &d))
+	assert.Equal(t, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), d.Time)
+	assert.Equal(t, time.UTC, d.Location())
+
+	// null はゼロ値に戻す
+	require.NoError(t, json.Unmarshal([]byte(`null`), &d))
+	assert.True(t, d.IsZero())
+
+	for _, in := range []string{`"2024-02-30"`, `"2024/01/15"`, `"2024-01-15T00:00:00Z"`, `20240115`} {
+		err := json.Unmarshal([]byte(in), &d)
+		assert.Error(t, err, in)
+	}
+	err := json.Unmarshal([]byte(`"2024/01/15"`), &d)
+	assert.EqualError(t, err, `invalid date "2024/01/15": want YYYY-MM-DD`)
+}
+
+func TestTimestamp_JSON(t *testing.T) {
+	t.Parallel()
+
+	jst := time.FixedZone("JST", 9*60*60)
+	tests := []struct {
+		name string
+		in   time.Time
+		want string
+	}{
+		{name: "UTC", in: time.Date(2024, 1, 15, 3, 4, 5, 0, time.UTC), want: `"2024-01-15T03:04:05Z"`},
+		{name: "オフセット付きは UTC に正規化", in: time.Date(2024, 1, 15, 12, 4, 5, 0, jst), want: `"2024-01-15T03:04:05Z"`},
+		{name: "秒未満は切り捨て", in: time.Date(2024, 1, 15, 3, 4, 5, 999_999_999, time.UTC), want: `"2024-01-15T03:04:05Z"`},
+		{name: "ゼロ値は null", in: time.Time{}, want: `null`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			b, err := json.Marshal(NewTimestamp(tt.in))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(b))
+
+			var got Timestamp
+			require.NoError(t, json.Unmarshal(b, &got))
+			assert.Equal(t, NewTimestamp(tt.in), got, "round-trip")
+		})
+	}
+}
+
+func TestTimestamp_UnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	var ts Timestamp
+	require.NoError(t, json.Unmarshal([]byte(`"2024-01-15T12:04:05+09:00"`), &ts))
+	assert.Equal(t, time.Date(2024, 1, 15, 3, 4, 5, 0, time.UTC), ts.Time)
+	assert.Equal(t, time.UTC, ts.Location(), "UTC に正規化される")
+
+	require.NoError(t, json.Unmarshal([]byte(`null`), &ts))
+	assert.True(t, ts.IsZero())
+
+	err := json.Unmarshal([]byte(`"2024-01-15"`), &ts)
+	assert.EqualError(t, err, `invalid timestamp "2024-01-15": want RFC 3339 (e.g. 2006-01-02T15:04:05Z)`)
+}
+
+func TestOmitZero(t *testing.T) {
+	t.Parallel()
+
+	// 任意項目（x-omitzero）はゼロ値のとき出力されない
+	b, err := json.Marshal(ExportJob{Id: "J", Status: "pending", CreatedAt: NewTimestamp(time.Date(2024, 1, 15, 3, 4, 5, 0, time.UTC))})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"J","status":"pending","createdAt":"2024-01-15T03:04:05Z"}`, string(b))
+}
@@ -4,13 +4,152 @@
 package api
 
 import (
+	"time"
+
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
 const (
+	ApiKeyAuthScopes = "apiKeyAuth.Scopes"
 	CookieAuthScopes = "cookieAuth.Scopes"
 )
 
+// Defines values for AlertDirection.
+const (
+	AlertDirectionAbove AlertDirection = "above"
+	AlertDirectionBelow AlertDirection = "below"
+)
+
+// Defines values for AlertMode.
+const (
+	AlertModeOneShot AlertMode = "one_shot"
+	AlertModeReArm   AlertMode = "re_arm"
+)
+
+// Defines values for AlertInputDirection.
+const (
+	AlertInputDirectionAbove AlertInputDirection = "above"
+	AlertInputDirectionBelow AlertInputDirection = "below"
+)
+
+// Defines values for AlertInputMode.
+const (
+	AlertInputModeOneShot AlertInputMode = "one_shot"
+	AlertInputModeReArm   AlertInputMode = "re_arm"
+)
+
+// Defines values for AnalysisJobResponseStatus.
+const (
+	Done    AnalysisJobResponseStatus = "done"
+	Failed  AnalysisJobResponseStatus = "failed"
+	Pending AnalysisJobResponseStatus = "pending"
+)
+
+// Defines values for CorporateEventType.
+const (
+	Dividend CorporateEventType = "dividend"
+	Earnings CorporateEventType = "earnings"
+)
+
+// Defines values for DevicePlatform.
+const (
+	DevicePlatformAndroid DevicePlatform = "android"
+	DevicePlatformIos     DevicePlatform = "ios"
+	DevicePlatformWeb     DevicePlatform = "web"
+)
+
+// Defines values for OperationUsageOperation.
+const (
+	Analyze OperationUsageOperation = "analyze"
+	Detect  OperationUsageOperation = "detect"
+)
+
+// Defines values for Plan.
+const (
+	PlanFree    Plan = "free"
+	PlanPremium Plan = "premium"
+)
+
+// Defines values for PutSymbolStatusRequestStatus.
+const (
+	PutSymbolStatusRequestStatusActive   PutSymbolStatusRequestStatus = "active"
+	PutSymbolStatusRequestStatusDelisted PutSymbolStatusRequestStatus = "delisted"
+	PutSymbolStatusRequestStatusHidden   PutSymbolStatusRequestStatus = "hidden"
+)
+
+// Defines values for ReadyResponseCache.
+const (
+	Disabled ReadyResponseCache = "disabled"
+	Enabled  ReadyResponseCache = "enabled"
+)
+
+// Defines values for ReadyResponseDb.
+const (
+	DBDown ReadyResponseDb = "down"
+	DBOk   ReadyResponseDb = "ok"
+)
+
+// Defines values for ReadyResponseFreshness.
+const (
+	FreshnessOk      ReadyResponseFreshness = "ok"
+	FreshnessStale   ReadyResponseFreshness = "stale"
+	FreshnessUnknown ReadyResponseFreshness = "unknown"
+)
+
+// Defines values for ReadyResponseMarket.
+const (
+	MarketDegraded ReadyResponseMarket = "degraded"
+	MarketDown     ReadyResponseMarket = "down"
+	MarketHealthy  ReadyResponseMarket = "healthy"
+	MarketUnknown  ReadyResponseMarket = "unknown"
+)
+
+// Defines values for ReadyResponseMode.
+const (
+	Maintenance ReadyResponseMode = "maintenance"
+	Normal      ReadyResponseMode = "normal"
+)
+
+// Defines values for ReadyResponseRedis.
+const (
+	RedisDisabled ReadyResponseRedis = "disabled"
+	RedisDown     ReadyResponseRedis = "down"
+	RedisOk       ReadyResponseRedis = "ok"
+)
+
+// Defines values for ReadyResponseSchema.
+const (
+	Drift   ReadyResponseSchema = "drift"
+	Ok      ReadyResponseSchema = "ok"
+	Unknown ReadyResponseSchema = "unknown"
+)
+
+// Defines values for RegisterDeviceRequestPlatform.
+const (
+	RegisterDeviceRequestPlatformAndroid RegisterDeviceRequestPlatform = "android"
+	RegisterDeviceRequestPlatformIos     RegisterDeviceRequestPlatform = "ios"
+	RegisterDeviceRequestPlatformWeb     RegisterDeviceRequestPlatform = "web"
+)
+
+// Defines values for SymbolDetailStatus.
+const (
+	SymbolDetailStatusActive   SymbolDetailStatus = "active"
+	SymbolDetailStatusDelisted SymbolDetailStatus = "delisted"
+)
+
+// Defines values for SymbolStatusResponseStatus.
+const (
+	Active   SymbolStatusResponseStatus = "active"
+	Delisted SymbolStatusResponseStatus = "delisted"
+	Hidden   SymbolStatusResponseStatus = "hidden"
+)
+
+// Defines values for SymbolTier.
+const (
+	SymbolTierBasic   SymbolTier = "basic"
+	SymbolTierPremium SymbolTier = "premium"
+)
+
 // Defines values for BeginOAuthParamsProvider.
 const (
 	BeginOAuthParamsProviderGithub BeginOAuthParamsProvider = "github"
@@ -23,17 +162,228 @@ const (
 	OauthCallbackParamsProviderGoogle OauthCallbackParamsProvider = "google"
 )
 
+// Defines values for LoginParamsInclude.
+const (
+	User LoginParamsInclude = "user"
+)
+
 // AddWatchlistRequest defines model for AddWatchlistRequest.
 type AddWatchlistRequest struct {
 	// SymbolCode 追加する銘柄コード（例: AAPL, 7203.T）
 	SymbolCode string `binding:"required,min=1,max=20" json:"symbol_code"`
 }
 
+// Adjustment defines model for Adjustment.
+type Adjustment struct {
+	// EffectiveDate 効力発生日（YYYY-MM-DD形式）。この日より前の足に適用する
+	EffectiveDate Date `json:"effectiveDate"`
+
+	// Factor 価格に掛ける係数（2対1分割なら 0.5）。出来高はこの係数で割る
+	Factor float64 `json:"factor"`
+	Id     int64   `json:"id"`
+	Reason string  `json:"reason"`
+
+	// Symbol 銘柄コード
+	Symbol string `json:"symbol"`
+
+	// UpdatedAt 最終更新日時（UTC、RFC 3339、秒精度）
+	UpdatedAt Timestamp `json:"updatedAt"`
+}
+
+// AdminUser 管理者向けのユーザー情報。パスワードハッシュは含まない
+type AdminUser struct {
+	// CreatedAt 登録日時（UTC、RFC 3339、秒精度）
+	CreatedAt Timestamp `json:"createdAt"`
+	Email     string    `json:"email"`
+	Id        int64     `json:"id"`
+
+	// LastLoginAt 最終ログイン日時。一度もログインしていない場合は省略
+	LastLoginAt Timestamp `json:"lastLoginAt,omitempty,omitzero"`
+
+	// Plan ユーザーの料金プラン。free は basic の銘柄だけ、premium はすべての銘柄を参照できる
+	Plan Plan `json:"plan"`
+
+	// UpdatedAt 最終更新日時（UTC、RFC 3339、秒精度）
+	UpdatedAt Timestamp `json:"updatedAt"`
+}
+
+// AdminUserPage defines model for AdminUserPage.
+type AdminUserPage struct {
+	// NextCursor 次のページのカーソル。最後のページでは省略
+	NextCursor string `json:"nextCursor,omitempty"`
+
+	// Total 検索に一致するユーザーの総数（ページングによらない）
+	Total int64       `json:"total"`
+	Users []AdminUser `json:"users"`
+}
+
+// Alert 価格アラート
+type Alert struct {
+	// AdjustmentId 最後に閾値を書き換えた調整係数の ID。書き換えていない場合は省略
+	AdjustmentId *int64 `json:"adjustment_id,omitempty"`
+
+	// CooldownMinutes re_arm の再発火までの最短の間隔（分）。one_shot は 0
+	CooldownMinutes int `json:"cooldown_minutes"`
+
+	// CreatedAt 登録日時（UTC、RFC 3339、秒精度）
+	CreatedAt Timestamp `json:"created_at"`
+
+	// Direction above は終値が閾値を下から上へ、below は上から下へ横切ったときに発火する
+	Direction AlertDirection `json:"direction"`
+	Id        int64          `json:"id"`
+	Interval  string         `json:"interval"`
+	Mode      AlertMode      `json:"mode"`
+
+	// OriginalThreshold 株式分割・併合で閾値を書き換える前にユーザーが設定した閾値。書き換えていない場合は省略
+	OriginalThreshold *float64 `json:"original_threshold,omitempty"`
+	Symbol            string   `json:"symbol"`
+	Threshold         float64  `json:"threshold"`
+
+	// TriggeredAt one_shot の発火日時（UTC、RFC 3339、秒精度）。未発火・re_arm の場合は省略
+	TriggeredAt *Timestamp `json:"triggered_at,omitempty"`
+}
+
+// AlertDirection above は終値が閾値を下から上へ、below は上から下へ横切ったときに発火する
+type AlertDirection string
+
+// AlertMode defines model for Alert.Mode.
+type AlertMode string
+
+// AlertExport defines model for AlertExport.
+type AlertExport struct {
+	Alerts []Alert `json:"alerts"`
+}
+
+// AlertInput 登録するアラート（Alert の id・発火の状態等のその他の項目は無視する）
+type AlertInput struct {
+	// CooldownMinutes re_arm は 0〜43200、one_shot は 0（省略時は 0）
+	CooldownMinutes int                 `json:"cooldown_minutes,omitempty"`
+	Direction       AlertInputDirection `json:"direction"`
+	Interval        string              `json:"interval"`
+
+	// Mode 省略時は one_shot
+	Mode   AlertInputMode `json:"mode,omitempty"`
+	Symbol string         `json:"symbol"`
+
+	// Threshold 正の数
+	Threshold float64 `json:"threshold"`
+}
+
+// AlertInputDirection defines model for AlertInput.Direction.
+type AlertInputDirection string
+
+// AlertInputMode 省略時は one_shot
+type AlertInputMode string
+
+// AnalysisJobResponse defines model for AnalysisJobResponse.
+type AnalysisJobResponse struct {
+	// CreatedAt 登録した日時（UTC、RFC 3339、秒精度）
+	CreatedAt Timestamp `json:"created_at"`
+
+	// Error 失敗の理由（quota_exceeded / analysis_failed）。status が failed の場合のみ
+	Error *string `json:"error,omitempty"`
+
+	// Id ジョブ ID
+	Id     string                   `json:"id"`
+	Result *CompanyAnalysisResponse `json:"result,omitempty"`
+
+	// Status pending（処理待ち・処理中）/ done（result に分析）/ failed（error に理由）
+	Status AnalysisJobResponseStatus `json:"status"`
+
+	// UpdatedAt 最後に状態が変わった日時（UTC、RFC 3339、秒精度）
+	UpdatedAt Timestamp `json:"updated_at"`
+}
+
+// AnalysisJobResponseStatus pending（処理待ち・処理中）/ done（result に分析）/ failed（error に理由）
+type AnalysisJobResponseStatus string
+
+// Annotation ユーザーがチャートの足に付けた注記
+type Annotation struct {
+	// CreatedAt 登録日時（UTC、RFC 3339、秒精度）
+	CreatedAt Timestamp `json:"createdAt"`
+	Id        int64     `json:"id"`
+
+	// Interval 時間間隔
+	Interval string `json:"interval"`
+
+	// Symbol 銘柄コード
+	Symbol string `json:"symbol"`
+
+	// Text 本文（最大 500 文字）
+	Text string `json:"text"`
+
+	// Time 注記を付けた足の日付（YYYY-MM-DD形式。ローソク足の time と同じ）
+	Time Date `json:"time"`
+
+	// UpdatedAt 最終更新日時（UTC、RFC 3339、秒精度）
+	UpdatedAt Timestamp `json:"updatedAt"`
+}
+
+// AnnotationPage defines model for AnnotationPage.
+type AnnotationPage struct {
+	Annotations []Annotation `json:"annotations"`
+
+	// NextCursor 次のページのカーソル。最後のページでは省略
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// Anomaly defines model for Anomaly.
+type Anomaly struct {
+	// BackfillRequestedAt 履歴の再取得を要求した日時。要求していない場合は省略
+	BackfillRequestedAt Timestamp `json:"backfillRequestedAt,omitempty,omitzero"`
+
+	// BackfilledAt 履歴の再取得の完了日時。未完了の場合は省略
+	BackfilledAt Timestamp `json:"backfilledAt,omitempty,omitzero"`
+
+	// Close 急変した日足の終値
+	Close float64 `json:"close"`
+
+	// DetectedAt 検出日時（UTC、RFC 3339、秒精度）
+	DetectedAt Timestamp `json:"detectedAt"`
+	Id         int64     `json:"id"`
+
+	// PrevClose 直前の日足の終値
+	PrevClose float64 `json:"prevClose"`
+
+	// Ratio close / prevClose（例: 2対1分割なら 0.5）
+	Ratio float64 `json:"ratio"`
+
+	// ResolvedAt 確認日時。未確認の場合は省略
+	ResolvedAt Timestamp `json:"resolvedAt,omitempty,omitzero"`
+
+	// Status pending / confirmed_split / confirmed_bad_data
+	Status string `json:"status"`
+
+	// Symbol 銘柄コード
+	Symbol string `json:"symbol"`
+
+	// Time 急変した日足の日付（YYYY-MM-DD形式）
+	Time Date `json:"time"`
+}
+
+// BuildInfo defines model for BuildInfo.
+type BuildInfo struct {
+	// BuildTime ビルド日時（RFC 3339、UTC。埋め込まれていない場合は dev）
+	BuildTime string `json:"build_time"`
+
+	// Commit git のコミット SHA（埋め込まれていない場合は dev）
+	Commit string `json:"commit"`
+
+	// GoVersion ビルドに使った Go のバージョン
+	GoVersion string `json:"go_version"`
+
+	// Version リリースのタグ（埋め込まれていない場合は dev）
+	Version string `json:"version"`
+}
+
 // CandleResponse defines model for CandleResponse.
 type CandleResponse struct {
 	// Close 終値
 	Close float64 `json:"close"`
 
+	// Events ?with_events=true の場合のみ。この足に付けたコーポレートイベント（イベントがない足では省略）
+	Events *[]CorporateEvent `json:"events,omitempty"`
+
 	// High 高値
 	High float64 `json:"high"`
 
@@ -43,13 +393,58 @@ type CandleResponse struct {
 	// Open 始値
 	Open float64 `json:"open"`
 
+	// Source ?as_of= の場合のみ。足の取り込み元（twelvedata / csv:<ファイル名のハッシュ> / derived 等）
+	Source *string `json:"source,omitempty"`
+
 	// Time 日付（YYYY-MM-DD形式）
-	Time string `json:"time"`
+	Time Date `json:"time"`
 
 	// Volume 出来高
 	Volume int64 `json:"volume"`
 }
 
+// CandleStatsResponse defines model for CandleStatsResponse.
+type CandleStatsResponse struct {
+	AllTimeHigh PricePoint `json:"all_time_high"`
+
+	// AsOf 集計基準となる最新ローソク足の日付（YYYY-MM-DD形式）
+	AsOf    Date       `json:"as_of"`
+	High52w PricePoint `json:"high_52w"`
+	Low52w  PricePoint `json:"low_52w"`
+
+	// Partial52w 保有データが52週に満たず、取得可能な範囲で算出した場合にtrue
+	Partial52w bool        `json:"partial_52w"`
+	Volume30d  VolumeStats `json:"volume_30d"`
+	Volume90d  VolumeStats `json:"volume_90d"`
+
+	// YtdChangePercent 年初来騰落率（%）。基準値が算出できない場合はnull
+	YtdChangePercent *float64 `json:"ytd_change_percent"`
+}
+
+// CandleSymbol ローソク足の銘柄の情報（銘柄一覧と同じキャッシュから引く）
+type CandleSymbol struct {
+	// Code 正規の銘柄コード（X-Resolved-Symbol と同じ）
+	Code string `json:"code"`
+
+	// Market 市場
+	Market string `json:"market"`
+
+	// Name 銘柄名（Content-Language のロケールの表記。登録がない場合は既定の表記）
+	Name string `json:"name"`
+
+	// Status 銘柄の状態。active または delisted（上場廃止で、新しい足は増えない）
+	Status string `json:"status"`
+}
+
+// CandlesEnvelope ?include=symbol（または v2 の Accept）の場合のローソク足の応答
+type CandlesEnvelope struct {
+	// Candles ローソク足データ一覧（?fields= 指定時は指定した項目のみ）
+	Candles []CandleResponse `json:"candles"`
+
+	// Symbol ローソク足の銘柄の情報（銘柄一覧と同じキャッシュから引く）
+	Symbol CandleSymbol `json:"symbol"`
+}
+
 // CompanyAnalysisRequest defines model for CompanyAnalysisRequest.
 type CompanyAnalysisRequest struct {
 	// CompanyName 分析対象の企業名
@@ -61,10 +456,104 @@ type CompanyAnalysisResponse struct {
 	// CompanyName 分析対象の企業名
 	CompanyName string `json:"company_name"`
 
+	// GeneratedAt サマリーを生成した日時（キャッシュから返した場合は元の生成日時。「X 時間前に更新」の表示に使う）
+	GeneratedAt time.Time `json:"generated_at"`
+
+	// Stale 鮮度期限（24 時間）を過ぎたサマリーか。true の場合はバックグラウンドで再生成中で、次回以降の呼び出しで新しいサマリーが返る
+	Stale bool `json:"stale"`
+
 	// Summary AI生成の企業分析サマリー
 	Summary string `json:"summary"`
 }
 
+// CorporateEvent defines model for CorporateEvent.
+type CorporateEvent struct {
+	// Date イベントの日付（YYYY-MM-DD形式）
+	Date Date `json:"date"`
+
+	// Estimate earnings の EPS の予想（dividend は null）
+	Estimate *float64 `json:"estimate"`
+
+	// Source 取り込み元
+	Source string `json:"source"`
+
+	// Type イベントの種類（dividend は配当の権利落ち日、earnings は決算発表日）
+	Type CorporateEventType `json:"type"`
+
+	// Value dividend は 1 株当たりの配当額、earnings は EPS の実績（未発表は null）
+	Value *float64 `json:"value"`
+}
+
+// CorporateEventType イベントの種類（dividend は配当の権利落ち日、earnings は決算発表日）
+type CorporateEventType string
+
+// CreateAdjustmentRequest defines model for CreateAdjustmentRequest.
+type CreateAdjustmentRequest struct {
+	// EffectiveDate 効力発生日（YYYY-MM-DD形式）。この日より前の足に適用する
+	EffectiveDate Date `binding:"required" json:"effectiveDate"`
+
+	// Factor 価格に掛ける係数（2対1分割なら 0.5）。出来高はこの係数で割る
+	Factor float64 `binding:"required,gt=0" json:"factor"`
+
+	// Reason 調整理由（任意）
+	Reason string `binding:"max=255" json:"reason,omitempty"`
+
+	// Symbol 銘柄コード
+	Symbol string `binding:"required,max=20" json:"symbol"`
+}
+
+// CreateAnnotationRequest defines model for CreateAnnotationRequest.
+type CreateAnnotationRequest struct {
+	// Interval 時間間隔（例 1day）
+	Interval string `binding:"required,max=16" json:"interval"`
+
+	// Symbol 銘柄コード
+	Symbol string `binding:"required,max=20" json:"symbol"`
+
+	// Text 本文（最大 500 文字）
+	Text string `binding:"required,max=500" json:"text"`
+
+	// Time 注記を付ける足の日付（YYYY-MM-DD形式）
+	Time Date `binding:"required" json:"time"`
+}
+
+// DailyStatsBatchResponse defines model for DailyStatsBatchResponse.
+type DailyStatsBatchResponse struct {
+	// Errors 銘柄ごとに取得できなかった理由（料金プランで参照できない銘柄は upgrade_required）
+	Errors []SymbolError      `json:"errors"`
+	Stats  []SymbolDailyStats `json:"stats"`
+
+	// Unknown 解決できなかった銘柄コード（指定されたまま）
+	Unknown []string `json:"unknown"`
+}
+
+// DedupeResult defines model for DedupeResult.
+type DedupeResult struct {
+	// DryRun true の場合は削除予定の報告のみ（データは変更していない）
+	DryRun bool `json:"dryRun"`
+
+	// Groups 重複していた組（最大1000件）
+	Groups []DuplicateCandleGroup `json:"groups"`
+
+	// GroupsAffected 重複していた (銘柄, 時間間隔, 時刻) の数
+	GroupsAffected int `json:"groupsAffected"`
+
+	// RowsDeleted 削除した（dryRun では削除予定の）行数
+	RowsDeleted int `json:"rowsDeleted"`
+
+	// Symbols 確認した銘柄数
+	Symbols int `json:"symbols"`
+
+	// Truncated groups が上限で打ち切られた場合 true
+	Truncated bool `json:"truncated"`
+}
+
+// DeleteAccountRequest defines model for DeleteAccountRequest.
+type DeleteAccountRequest struct {
+	// Password 本人確認のための現在のパスワード
+	Password string `binding:"required" json:"password"`
+}
+
 // DetectedLogoResponse defines model for DetectedLogoResponse.
 type DetectedLogoResponse struct {
 	// Confidence 信頼度スコア（0.0 ~ 1.0）
@@ -74,69 +563,738 @@ type DetectedLogoResponse struct {
 	Name string `json:"name"`
 }
 
+// Device プッシュ通知の送信先として登録された端末
+type Device struct {
+	// AppVersion アプリのバージョン（未指定なら空文字）
+	AppVersion string `json:"appVersion"`
+
+	// CreatedAt 登録日時（UTC、RFC 3339、秒精度）
+	CreatedAt Timestamp      `json:"createdAt"`
+	Id        int64          `json:"id"`
+	Platform  DevicePlatform `json:"platform"`
+
+	// UpdatedAt 最終登録日時（UTC、RFC 3339、秒精度）
+	UpdatedAt Timestamp `json:"updatedAt"`
+}
+
+// DevicePlatform defines model for Device.Platform.
+type DevicePlatform string
+
+// DigestSubscription defines model for DigestSubscription.
+type DigestSubscription struct {
+	// Subscribed ダイジェストメールを購読しているか
+	Subscribed bool `json:"subscribed"`
+}
+
+// DigestSubscriptionVersionConflict 購読の変更の If-Match が一致しなかった場合の現在の購読状態
+type DigestSubscriptionVersionConflict struct {
+	// Error エラーコード（"version mismatch"）
+	Error        string             `json:"error"`
+	Subscription DigestSubscription `json:"subscription"`
+
+	// Version 現在のバージョン（ETag と同じ値）
+	Version int64 `json:"version"`
+}
+
+// DuplicateCandleGroup defines model for DuplicateCandleGroup.
+type DuplicateCandleGroup struct {
+	// DeletedIds 削除した（dryRun では削除予定の）行のID
+	DeletedIds []int64 `json:"deletedIds"`
+
+	// Interval 時間間隔
+	Interval string `json:"interval"`
+
+	// KeptId 残す行のID（組の中で最大）
+	KeptId int64 `json:"keptId"`
+
+	// Symbol 銘柄コード
+	Symbol string `json:"symbol"`
+
+	// Time ローソク足の時刻（UTC、RFC 3339、秒精度）
+	Time Timestamp `json:"time"`
+}
+
 // ErrorResponse defines model for ErrorResponse.
 type ErrorResponse struct {
 	// Error エラーメッセージ
 	Error string `json:"error"`
+
+	// Fields 入力検証に失敗した項目（認証系のエンドポイントのみ。該当しない場合は省略）
+	Fields []FieldError `json:"fields,omitempty"`
+
+	// Hint クライアントが取るべき対処（例 取得範囲を狭める）。該当しない場合は省略
+	Hint string `json:"hint,omitempty"`
+
+	// Message 利用者に表示できる文言（Accept-Language で選んだロケール。en / ja）。認証系のエンドポイントのみ返し、
+	// 言語によらない判定には error を使う。翻訳がない場合は英語の文言
+	Message string `json:"message,omitempty"`
 }
 
-// HealthResponse defines model for HealthResponse.
-type HealthResponse struct {
-	// Status サービスステータス
+// ExportJob defines model for ExportJob.
+type ExportJob struct {
+	// CompletedAt 生成の完了日時（失敗を含む）。未完了の場合は省略
+	CompletedAt Timestamp `json:"completedAt,omitempty,omitzero"`
+
+	// CreatedAt 受付日時（UTC、RFC 3339、秒精度）
+	CreatedAt Timestamp `json:"createdAt"`
+
+	// DownloadUrl status が ready の場合のみ設定される署名付きダウンロードURL（相対パス）
+	DownloadUrl *string `json:"downloadUrl,omitempty"`
+
+	// ExpiresAt アーカイブとダウンロードリンクの有効期限。未完了の場合は省略
+	ExpiresAt Timestamp `json:"expiresAt,omitempty,omitzero"`
+	Id        string    `json:"id"`
+
+	// Status pending / running / ready / downloaded / expired / failed
 	Status string `json:"status"`
 }
 
-// LoginRequest defines model for LoginRequest.
-type LoginRequest struct {
-	// Email メールアドレス
-	Email string `binding:"required,email" json:"email"`
+// FieldError defines model for FieldError.
+type FieldError struct {
+	// Code 検証の種類（required / email / min / max 等）。ロケールによらず同じ値
+	Code string `json:"code"`
 
-	// Password パスワード
-	Password string `binding:"required" json:"password"`
-}
+	// Field リクエストの項目名（JSON のキー）
+	Field string `json:"field"`
 
-// MessageResponse defines model for MessageResponse.
-type MessageResponse struct {
+	// Message Accept-Language で選んだロケールの文言
 	Message string `json:"message"`
 }
 
-// ReorderWatchlistRequest defines model for ReorderWatchlistRequest.
-type ReorderWatchlistRequest struct {
-	// Codes 新しい順序での銘柄コード一覧
-	Codes []string `binding:"required,min=1" json:"codes"`
+// FlagListResponse defines model for FlagListResponse.
+type FlagListResponse struct {
+	Flags []FlagState `json:"flags"`
+
+	// Writable Redis バックエンドが有効で PUT による切り替えが可能か
+	Writable bool `json:"writable"`
 }
 
-// SignupRequest defines model for SignupRequest.
-type SignupRequest struct {
+// FlagState defines model for FlagState.
+type FlagState struct {
+	// Default コード上のデフォルト値
+	Default     bool   `json:"default"`
+	Description string `json:"description"`
+
+	// Enabled 現在の値
+	Enabled bool   `json:"enabled"`
+	Name    string `json:"name"`
+
+	// Source 値の決定元（redis / env / default。redis > env > default の優先順）
+	Source string `json:"source"`
+}
+
+// ForgotPasswordRequest defines model for ForgotPasswordRequest.
+type ForgotPasswordRequest struct {
 	// Email メールアドレス
 	Email string `binding:"required,email" json:"email"`
+}
 
-	// Password パスワード（12文字以上）
-	Password string `binding:"required,min=12" json:"password"`
+// HealthResponse defines model for HealthResponse.
+type HealthResponse struct {
+	// Status サービスステータス
+	Status string `json:"status"`
 }
 
-// SymbolItem defines model for SymbolItem.
-type SymbolItem struct {
-	// Code 銘柄コード（例: AAPL, 7203.T）
-	Code string `json:"code"`
+// ImpersonationToken なりすましトークン。リフレッシュトークンは含まない
+type ImpersonationToken struct {
+	// Actor 発行した管理者のユーザーID。トークンの act.sub と同じ
+	Actor string `json:"actor"`
 
-	// LogoUrl Twelve DataのロゴURL（未取得時はnull）
-	LogoUrl *string `json:"logo_url"`
+	// ExpiresAt 有効期限（UTC、RFC 3339、秒精度）
+	ExpiresAt Timestamp `json:"expiresAt"`
 
-	// Name 企業名
-	Name string `json:"name"`
-}
+	// ExpiresInSeconds 有効期間（秒）
+	ExpiresInSeconds int64 `json:"expiresInSeconds"`
 
-// WatchlistItem defines model for WatchlistItem.
-type WatchlistItem struct {
-	// Id ウォッチリストエントリのID
-	Id int64 `json:"id"`
+	// Token アクセストークン（Authorization Bearer で送信する）
+	Token string `json:"token"`
 
-	// SortKey 表示順序
-	SortKey int `json:"sort_key"`
+	// User 管理者向けのユーザー情報。パスワードハッシュは含まない
+	User AdminUser `json:"user"`
+}
 
-	// SymbolCode 銘柄コード（例: AAPL, 7203.T）
-	SymbolCode string `json:"symbol_code"`
+// ImportAlertsRequest defines model for ImportAlertsRequest.
+type ImportAlertsRequest struct {
+	Alerts []AlertInput `binding:"required" json:"alerts"`
+}
+
+// ImportAlertsResult defines model for ImportAlertsResult.
+type ImportAlertsResult struct {
+	// Created 登録したアラート
+	Created []Alert `json:"created"`
+
+	// Skipped 登録済みの未発火のアラート（または同じリクエストの前の要素）と同じ内容のため登録しなかった件数
+	Skipped int `json:"skipped"`
+}
+
+// JobListResponse defines model for JobListResponse.
+type JobListResponse struct {
+	Jobs []JobState `json:"jobs"`
+}
+
+// JobState 定期ジョブの定義・予定と直近の実行結果
+type JobState struct {
+	Description string `json:"description"`
+
+	// FailureCount 失敗・タイムアウトした回数の累計（全インスタンス）
+	FailureCount int64 `json:"failureCount"`
+
+	// LastDurationMs 直近の実行にかかった時間（ミリ秒）
+	LastDurationMs int64 `json:"lastDurationMs,omitempty"`
+
+	// LastError 直近の実行が失敗した理由（先頭 500 バイト）。成功した場合は省略
+	LastError string `json:"lastError,omitempty"`
+
+	// LastFinishedAt 直近の実行の終了日時。一度も実行していない場合は省略
+	LastFinishedAt Timestamp `json:"lastFinishedAt,omitempty,omitzero"`
+
+	// LastStartedAt 直近の実行の開始日時。一度も実行していない場合は省略
+	LastStartedAt Timestamp `json:"lastStartedAt,omitempty,omitzero"`
+
+	// LastStatus 直近の実行結果（succeeded / failed / timed_out）。一度も実行していない場合は省略
+	LastStatus string `json:"lastStatus,omitempty"`
+	Name       string `json:"name"`
+
+	// NextRunAt 次の実行予定（UTC、RFC 3339、秒精度）
+	NextRunAt Timestamp `json:"nextRunAt"`
+
+	// RunCount 実行回数の累計（全インスタンス）
+	RunCount int64 `json:"runCount"`
+
+	// RunRequestedAt 即時実行の依頼の日時。依頼がない・実行済みの場合は省略
+	RunRequestedAt Timestamp `json:"runRequestedAt,omitempty,omitzero"`
+
+	// Schedule 実行予定（"every 1h0m0s" か cron 形式。cron は UTC で解釈する）
+	Schedule string `json:"schedule"`
+
+	// TimeoutSeconds 1 回の実行の上限（秒）
+	TimeoutSeconds int64 `json:"timeoutSeconds"`
+}
+
+// LoginRequest defines model for LoginRequest.
+type LoginRequest struct {
+	// Email メールアドレス
+	Email string `binding:"required,email" json:"email"`
+
+	// Password パスワード
+	Password string `binding:"required" json:"password"`
+}
+
+// LoginResponse defines model for LoginResponse.
+type LoginResponse struct {
+	Message string       `json:"message"`
+	User    *UserProfile `json:"user,omitempty"`
+}
+
+// MessageResponse defines model for MessageResponse.
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+// OperationUsage defines model for OperationUsage.
+type OperationUsage struct {
+	// Limit 1 日あたりの上限
+	Limit int `json:"limit"`
+
+	// Operation 操作（detect はロゴ検出、analyze は企業分析）
+	Operation OperationUsageOperation `json:"operation"`
+
+	// Remaining 当日の残りの回数（exempt の場合も上限との差を返す）
+	Remaining int `json:"remaining"`
+
+	// Used 当日の利用回数（成功した呼び出しのみ）
+	Used int `json:"used"`
+}
+
+// OperationUsageOperation 操作（detect はロゴ検出、analyze は企業分析）
+type OperationUsageOperation string
+
+// Plan ユーザーの料金プラン。free は basic の銘柄だけ、premium はすべての銘柄を参照できる
+type Plan string
+
+// PricePoint defines model for PricePoint.
+type PricePoint struct {
+	// Time 日付（YYYY-MM-DD形式）
+	Time Date `json:"time"`
+
+	// Value 価格
+	Value float64 `json:"value"`
+}
+
+// PutSymbolNameRequest defines model for PutSymbolNameRequest.
+type PutSymbolNameRequest struct {
+	Name string `binding:"required,max=255" json:"name"`
+}
+
+// PutSymbolStatusRequest defines model for PutSymbolStatusRequest.
+type PutSymbolStatusRequest struct {
+	// EffectiveDate 効力発生日（YYYY-MM-DD形式。delisted では上場廃止日）。省略時は当日（UTC）
+	EffectiveDate Date `json:"effectiveDate,omitempty,omitzero"`
+
+	// Status 変更後の状態
+	Status PutSymbolStatusRequestStatus `binding:"required" json:"status"`
+}
+
+// PutSymbolStatusRequestStatus 変更後の状態
+type PutSymbolStatusRequestStatus string
+
+// ReadyResponse defines model for ReadyResponse.
+type ReadyResponse struct {
+	Build BuildInfo `json:"build"`
+
+	// Cache Redis キャッシュの状態（ヘルスモニターが正常と判定している間は enabled）
+	Cache ReadyResponseCache `json:"cache"`
+
+	// Db DB への ping の結果（上限 500ms）。down の場合は 503
+	Db ReadyResponseDb `json:"db"`
+
+	// Freshness 日足のデータ鮮度（data_freshness）。いずれかの市場の日足の最後の取り込み成功が直近の平日より前なら stale （週末を挟んでも金曜日の成功は stale としない。祝日は考慮しない）。マーカーがない・読み取れない場合は unknown。 保存済みのデータで応答できるため readiness（200・status）には影響しない
+	Freshness ReadyResponseFreshness `json:"freshness"`
+
+	// Market 市場データ（Twelve Data）連携の状態。ingest の直近の呼び出しの失敗率から判定する（unknown は直近の記録がない）。 down の間はキャッシュミス時の fetch-through を止めるが、キャッシュ・DB からの応答は続けるため readiness には影響しない
+	Market ReadyResponseMarket `json:"market"`
+
+	// Mode 動作モード（maintenance の間は書き込みを 503 で拒否し、読み取りのみ応答する。フィーチャーフラグ maintenance_mode）
+	Mode ReadyResponseMode `json:"mode"`
+
+	// Redis Redis への ping の結果（上限 500ms）。Redis を設定していない場合は disabled。down の場合は status を degraded にする（200 のまま）
+	Redis ReadyResponseRedis `json:"redis"`
+
+	// Schema 起動時のスキーマ検証の結果（unknown は DB を読み取れず検証できなかった）
+	Schema ReadyResponseSchema `json:"schema"`
+
+	// SchemaDrift 欠けているテーブル・列・インデックス（例 "missing_column candles.source"）。schema が drift の場合のみ
+	SchemaDrift *[]string `json:"schema_drift,omitempty"`
+
+	// Status サービスステータス（ok / degraded / unavailable。unavailable の場合は 503）
+	Status string `json:"status"`
+}
+
+// ReadyResponseCache Redis キャッシュの状態（ヘルスモニターが正常と判定している間は enabled）
+type ReadyResponseCache string
+
+// ReadyResponseDb DB への ping の結果（上限 500ms）。down の場合は 503
+type ReadyResponseDb string
+
+// ReadyResponseFreshness 日足のデータ鮮度（data_freshness）。いずれかの市場の日足の最後の取り込み成功が直近の平日より前なら stale （週末を挟んでも金曜日の成功は stale としない。祝日は考慮しない）。マーカーがない・読み取れない場合は unknown。 保存済みのデータで応答できるため readiness（200・status）には影響しない
+type ReadyResponseFreshness string
+
+// ReadyResponseMarket 市場データ（Twelve Data）連携の状態。ingest の直近の呼び出しの失敗率から判定する（unknown は直近の記録がない）。 down の間はキャッシュミス時の fetch-through を止めるが、キャッシュ・DB からの応答は続けるため readiness には影響しない
+type ReadyResponseMarket string
+
+// ReadyResponseMode 動作モード（maintenance の間は書き込みを 503 で拒否し、読み取りのみ応答する。フィーチャーフラグ maintenance_mode）
+type ReadyResponseMode string
+
+// ReadyResponseRedis Redis への ping の結果（上限 500ms）。Redis を設定していない場合は disabled。down の場合は status を degraded にする（200 のまま）
+type ReadyResponseRedis string
+
+// ReadyResponseSchema 起動時のスキーマ検証の結果（unknown は DB を読み取れず検証できなかった）
+type ReadyResponseSchema string
+
+// RecentSymbol defines model for RecentSymbol.
+type RecentSymbol struct {
+	// Name 企業名
+	Name string `json:"name"`
+
+	// SymbolCode 銘柄コード（例: AAPL, 7203.T）
+	SymbolCode string `json:"symbol_code"`
+
+	// ViewedAt 最後に閲覧した日時（UTC、RFC 3339、秒精度）
+	ViewedAt Timestamp `json:"viewed_at"`
+}
+
+// RegisterDeviceRequest defines model for RegisterDeviceRequest.
+type RegisterDeviceRequest struct {
+	// AppVersion アプリのバージョン（最大 32 文字）
+	AppVersion *string                       `binding:"omitempty,max=32" json:"appVersion,omitempty"`
+	Platform   RegisterDeviceRequestPlatform `binding:"required,oneof=ios android web" json:"platform"`
+
+	// Token FCM の登録トークン
+	Token string `binding:"required,max=4096" json:"token"`
+}
+
+// RegisterDeviceRequestPlatform defines model for RegisterDeviceRequest.Platform.
+type RegisterDeviceRequestPlatform string
+
+// ReorderWatchlistRequest defines model for ReorderWatchlistRequest.
+type ReorderWatchlistRequest struct {
+	// Codes 新しい順序での銘柄コード一覧
+	Codes []string `binding:"required,min=1" json:"codes"`
+}
+
+// ResetPasswordRequest defines model for ResetPasswordRequest.
+type ResetPasswordRequest struct {
+	// Password 新しいパスワード（12文字以上）
+	Password string `binding:"required" json:"password"`
+
+	// Token リセットトークン
+	Token string `binding:"required" json:"token"`
+}
+
+// ResolveAnomalyRequest defines model for ResolveAnomalyRequest.
+type ResolveAnomalyRequest struct {
+	// Backfill 分割調整後の履歴の再取得を要求するか（confirmed_split の場合のみ指定可）
+	Backfill bool `json:"backfill,omitempty"`
+
+	// Status 確認結果（confirmed_split / confirmed_bad_data）
+	Status string `binding:"required" json:"status"`
+}
+
+// SignupRequest defines model for SignupRequest.
+type SignupRequest struct {
+	// Email メールアドレス
+	Email string `binding:"required,email" json:"email"`
+
+	// Password パスワード（12文字以上）
+	Password string `binding:"required,min=12" json:"password"`
+}
+
+// Sparkline defines model for Sparkline.
+type Sparkline struct {
+	// Closes 間引いた終値（時刻の昇順）
+	Closes []float64 `json:"closes"`
+
+	// First 最初の足の時刻（UTC、RFC 3339、秒精度）。データが0件の場合は省略
+	First *Timestamp `json:"first,omitempty"`
+
+	// Last 最後の足の時刻（UTC、RFC 3339、秒精度）。データが0件の場合は省略
+	Last *Timestamp `json:"last,omitempty"`
+
+	// Symbol 正規の銘柄コード
+	Symbol string `json:"symbol"`
+}
+
+// SparklineBatchResponse defines model for SparklineBatchResponse.
+type SparklineBatchResponse struct {
+	// Errors 銘柄ごとに取得できなかった理由（料金プランで参照できない銘柄は upgrade_required）
+	Errors     []SymbolError `json:"errors"`
+	Sparklines []Sparkline   `json:"sparklines"`
+
+	// Unknown 解決できなかった銘柄コード（指定されたまま）
+	Unknown []string `json:"unknown"`
+}
+
+// StoredCandle 管理用の調査で返す保存済みのローソク足
+type StoredCandle struct {
+	Close float64 `json:"close"`
+
+	// CreatedAt 最初に挿入した日時（UTC、RFC 3339、秒精度）
+	CreatedAt Timestamp `json:"createdAt"`
+	High      float64   `json:"high"`
+
+	// Id 行のID
+	Id   int64   `json:"id"`
+	Low  float64 `json:"low"`
+	Open float64 `json:"open"`
+
+	// Source 取り込み元（twelvedata / csv:<ファイル名のハッシュ> / derived / seed）
+	Source string `json:"source"`
+
+	// Time ローソク足の時刻（UTC、RFC 3339、秒精度）
+	Time Timestamp `json:"time"`
+
+	// UpdatedAt 値（OHLCV）が最後に変わった日時（UTC、RFC 3339、秒精度）
+	UpdatedAt Timestamp `json:"updatedAt"`
+	Volume    int64     `json:"volume"`
+}
+
+// SymbolDailyStats defines model for SymbolDailyStats.
+type SymbolDailyStats struct {
+	// AsOf 集計基準となる最新ローソク足の日付（YYYY-MM-DD形式）
+	AsOf Date `json:"as_of"`
+
+	// AvgVolume30d 直近30日の平均出来高
+	AvgVolume30d float64 `json:"avg_volume_30d"`
+
+	// ComputedAt 統計を算出した時刻（UTC、RFC 3339、秒精度）
+	ComputedAt Timestamp `json:"computed_at"`
+
+	// High52w 52週高値（高値ベース）
+	High52w float64 `json:"high_52w"`
+
+	// Low52w 52週安値（安値ベース）
+	Low52w float64 `json:"low_52w"`
+
+	// Symbol 正規の銘柄コード
+	Symbol string `json:"symbol"`
+
+	// YtdChangePercent 年初来騰落率（%）。基準値が算出できない場合はnull
+	YtdChangePercent *float64 `json:"ytd_change_percent"`
+}
+
+// SymbolDetail defines model for SymbolDetail.
+type SymbolDetail struct {
+	// Code 銘柄コード（例: AAPL, 7203.T）
+	Code string `json:"code"`
+
+	// Currency 取引通貨（ISO 4217。未登録時はnull）
+	Currency *string `json:"currency"`
+
+	// DelistedAsOf 上場廃止日（YYYY-MM-DD形式）。status が delisted で日付が登録されている場合のみ
+	DelistedAsOf Date `json:"delisted_as_of,omitempty,omitzero"`
+
+	// LogoUrl Twelve DataのロゴURL（未取得時はnull）
+	LogoUrl *string `json:"logo_url"`
+
+	// Market 市場（例: NASDAQ, TSE）
+	Market string `json:"market"`
+
+	// Name 企業名
+	Name string `json:"name"`
+
+	// Status 銘柄の状態（active は取り込み・一覧の対象、delisted は上場廃止で保存済みのデータのみ返す）
+	Status SymbolDetailStatus `json:"status"`
+
+	// Tier 銘柄のデータの区分。premium の銘柄のローソク足・統計・スパークラインは premium プランのユーザーだけが参照できる
+	// （アプリは free のユーザーに鍵のアイコンを表示する）
+	Tier SymbolTier `json:"tier"`
+}
+
+// SymbolDetailStatus 銘柄の状態（active は取り込み・一覧の対象、delisted は上場廃止で保存済みのデータのみ返す）
+type SymbolDetailStatus string
+
+// SymbolError defines model for SymbolError.
+type SymbolError struct {
+	// Error 理由（upgrade_required は利用者の料金プランでは参照できない銘柄）
+	Error string `json:"error"`
+
+	// Symbol 正規の銘柄コード
+	Symbol string `json:"symbol"`
+}
+
+// SymbolEventsResponse defines model for SymbolEventsResponse.
+type SymbolEventsResponse struct {
+	Events []CorporateEvent `json:"events"`
+
+	// From 範囲の開始日（省略時の既定値を含む）
+	From Date `json:"from"`
+
+	// Symbol 銘柄コード
+	Symbol string `json:"symbol"`
+
+	// To 範囲の終了日（省略時の既定値を含む）
+	To Date `json:"to"`
+}
+
+// SymbolItem defines model for SymbolItem.
+type SymbolItem struct {
+	// Code 銘柄コード（例: AAPL, 7203.T）
+	Code string `json:"code"`
+
+	// LogoUrl Twelve DataのロゴURL（未取得時はnull）
+	LogoUrl *string `json:"logo_url"`
+
+	// Name 企業名
+	Name string `json:"name"`
+
+	// Tier 銘柄のデータの区分。premium の銘柄のローソク足・統計・スパークラインは premium プランのユーザーだけが参照できる
+	// （アプリは free のユーザーに鍵のアイコンを表示する）
+	Tier SymbolTier `json:"tier"`
+}
+
+// SymbolName defines model for SymbolName.
+type SymbolName struct {
+	// Locale ロケール（小文字の言語コード）
+	Locale string `json:"locale"`
+	Name   string `json:"name"`
+
+	// Symbol 銘柄コード
+	Symbol string `json:"symbol"`
+
+	// UpdatedAt 最終更新日時（UTC、RFC 3339、秒精度）
+	UpdatedAt Timestamp `json:"updatedAt"`
+}
+
+// SymbolSearchPage defines model for SymbolSearchPage.
+type SymbolSearchPage struct {
+	Items []SymbolItem `json:"items"`
+
+	// Total 検索に一致する銘柄の総数（ページングによらない）
+	Total int64 `json:"total"`
+}
+
+// SymbolStatusResponse defines model for SymbolStatusResponse.
+type SymbolStatusResponse struct {
+	// Code 銘柄コード
+	Code string `json:"code"`
+
+	// EffectiveDate 状態の効力発生日（YYYY-MM-DD形式）
+	EffectiveDate Date `json:"effectiveDate"`
+
+	// Status 銘柄の状態
+	Status SymbolStatusResponseStatus `json:"status"`
+}
+
+// SymbolStatusResponseStatus 銘柄の状態
+type SymbolStatusResponseStatus string
+
+// SymbolTier 銘柄のデータの区分。premium の銘柄のローソク足・統計・スパークラインは premium プランのユーザーだけが参照できる
+// （アプリは free のユーザーに鍵のアイコンを表示する）
+type SymbolTier string
+
+// UpdateAdjustmentRequest defines model for UpdateAdjustmentRequest.
+type UpdateAdjustmentRequest struct {
+	// EffectiveDate 効力発生日（YYYY-MM-DD形式）。この日より前の足に適用する
+	EffectiveDate Date `binding:"required" json:"effectiveDate"`
+
+	// Factor 価格に掛ける係数（2対1分割なら 0.5）。出来高はこの係数で割る
+	Factor float64 `binding:"required,gt=0" json:"factor"`
+
+	// Reason 調整理由（任意）
+	Reason string `binding:"max=255" json:"reason,omitempty"`
+}
+
+// UpdateAnnotationRequest 変更する項目のみを指定する。省略した項目は変更しない
+type UpdateAnnotationRequest struct {
+	// Text 本文（最大 500 文字）
+	Text *string `binding:"omitempty,max=500" json:"text,omitempty"`
+
+	// Time 注記を付ける足の日付（YYYY-MM-DD形式）
+	Time *Date `json:"time,omitempty"`
+}
+
+// UpdateDigestSubscriptionRequest defines model for UpdateDigestSubscriptionRequest.
+type UpdateDigestSubscriptionRequest struct {
+	// Subscribed 購読する場合は true、解除する場合は false（省略不可）
+	Subscribed *bool `binding:"required" json:"subscribed"`
+}
+
+// UpdateFlagRequest defines model for UpdateFlagRequest.
+type UpdateFlagRequest struct {
+	// Enabled 切り替え後の値（省略不可）
+	Enabled *bool `binding:"required" json:"enabled"`
+}
+
+// UpdateUserPlanRequest defines model for UpdateUserPlanRequest.
+type UpdateUserPlanRequest struct {
+	Plan Plan `binding:"required,oneof=free premium" json:"plan"`
+}
+
+// UsageResponse defines model for UsageResponse.
+type UsageResponse struct {
+	// Date 集計対象の日付（UTC、YYYY-MM-DD形式）
+	Date Date `json:"date"`
+
+	// Exempt 上限を適用しないユーザーか
+	Exempt     bool             `json:"exempt"`
+	Operations []OperationUsage `json:"operations"`
+
+	// ResetAt 利用回数が 0 に戻る時刻（翌日 0 時 UTC）
+	ResetAt time.Time `json:"reset_at"`
+}
+
+// UserProfile defines model for UserProfile.
+type UserProfile struct {
+	// CreatedAt 登録日時（UTC、RFC 3339、秒精度）
+	CreatedAt Timestamp `json:"createdAt"`
+	Email     string    `json:"email"`
+	Id        int64     `json:"id"`
+}
+
+// VolumeStats defines model for VolumeStats.
+type VolumeStats struct {
+	// Average 平均出来高
+	Average float64 `json:"average"`
+
+	// Max 最大出来高
+	Max int64 `json:"max"`
+}
+
+// WatchlistItem defines model for WatchlistItem.
+type WatchlistItem struct {
+	// Id ウォッチリストエントリのID
+	Id int64 `json:"id"`
+
+	// SortKey 表示順序
+	SortKey int `json:"sort_key"`
+
+	// SymbolCode 銘柄コード（例: AAPL, 7203.T）
+	SymbolCode string `json:"symbol_code"`
+}
+
+// WatchlistVersionConflict 並び替えの If-Match が一致しなかった場合の現在のウォッチリスト（クライアントでマージして再送する）
+type WatchlistVersionConflict struct {
+	// Error エラーコード（"version mismatch"）
+	Error string          `json:"error"`
+	Items []WatchlistItem `json:"items"`
+
+	// Version 現在のバージョン（ETag と同じ値）
+	Version int64 `json:"version"`
+}
+
+// ListAdjustmentsParams defines parameters for ListAdjustments.
+type ListAdjustmentsParams struct {
+	// Symbol 銘柄コードで絞り込み。filter[symbol] と同じ。省略時は全銘柄
+	Symbol *string `form:"symbol,omitempty" json:"symbol,omitempty"`
+
+	// Sort 並び替え（例 -updated_at）。省略時は symbol,effective_date
+	Sort *string `form:"sort,omitempty" json:"sort,omitempty"`
+
+	// Limit 最大件数（1〜500）
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Offset 読み飛ばす件数
+	Offset *int `form:"offset,omitempty" json:"offset,omitempty"`
+}
+
+// ListAnomaliesParams defines parameters for ListAnomalies.
+type ListAnomaliesParams struct {
+	// Status 確認状態で絞り込み（pending / confirmed_split / confirmed_bad_data）。filter[status] と同じ。省略時は全件
+	Status *string `form:"status,omitempty" json:"status,omitempty"`
+
+	// Sort 並び替え（例 -detected_at,symbol）。省略時は -detected_at
+	Sort *string `form:"sort,omitempty" json:"sort,omitempty"`
+
+	// Limit 最大件数（1〜200）
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Offset 読み飛ばす件数
+	Offset *int `form:"offset,omitempty" json:"offset,omitempty"`
+}
+
+// DedupeCandlesParams defines parameters for DedupeCandles.
+type DedupeCandlesParams struct {
+	// Symbol 対象の銘柄コード。省略時は重複がある全銘柄
+	Symbol *string `form:"symbol,omitempty" json:"symbol,omitempty"`
+
+	// DryRun true の場合は報告のみ行い、削除しない
+	DryRun *bool `form:"dry_run,omitempty" json:"dry_run,omitempty"`
+}
+
+// InspectCandlesParams defines parameters for InspectCandles.
+type InspectCandlesParams struct {
+	// Interval 時間間隔
+	Interval *string `form:"interval,omitempty" json:"interval,omitempty"`
+
+	// Limit 最大件数（1〜1000）
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// ListAdminUsersParams defines parameters for ListAdminUsers.
+type ListAdminUsersParams struct {
+	// Query メールアドレスに含まれる文字列（最大 254 文字）。省略時は全ユーザー
+	Query *string `form:"query,omitempty" json:"query,omitempty"`
+
+	// Limit 最大件数（1〜200）
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor 前のページの nextCursor。省略時は先頭ページ
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+}
+
+// ListAnnotationsParams defines parameters for ListAnnotations.
+type ListAnnotationsParams struct {
+	// Symbol 銘柄コードで絞り込み。省略時は全銘柄
+	Symbol *string `form:"symbol,omitempty" json:"symbol,omitempty"`
+
+	// Interval 時間間隔で絞り込み。省略時は全時間間隔
+	Interval *string `form:"interval,omitempty" json:"interval,omitempty"`
+
+	// Limit 最大件数（1〜500）
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor 前のページの nextCursor。省略時は先頭ページ
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
 }
 
 // BeginOAuthParamsProvider defines parameters for BeginOAuth.
@@ -151,13 +1309,136 @@ type OauthCallbackParams struct {
 // OauthCallbackParamsProvider defines parameters for OauthCallback.
 type OauthCallbackParamsProvider string
 
+// GetCandleSparklinesParams defines parameters for GetCandleSparklines.
+type GetCandleSparklinesParams struct {
+	// Symbols カンマ区切りの銘柄コード（例: AAPL,7203.T）。1〜50 銘柄
+	Symbols string `form:"symbols" json:"symbols"`
+
+	// Interval 時間間隔
+	Interval *string `form:"interval,omitempty" json:"interval,omitempty"`
+
+	// Outputsize 間引く前のローソク足の件数（最新から）。既定値と上限は GET /candles/{code} の outputsize と同じ（時間間隔ごと、範囲外は既定値）
+	Outputsize *int `form:"outputsize,omitempty" json:"outputsize,omitempty"`
+
+	// Points 返す終値の最大点数
+	Points *int `form:"points,omitempty" json:"points,omitempty"`
+
+	// Adjusted true の場合、登録済みの調整係数（株式分割・併合）を適用した終値を返す。
+	// false の場合は保存済み（未調整）の値を返す。未指定時はサーバーの既定値（フィーチャーフラグ adjusted_default、既定は false）に従う
+	Adjusted *bool `form:"adjusted,omitempty" json:"adjusted,omitempty"`
+}
+
 // GetCandlesParams defines parameters for GetCandles.
 type GetCandlesParams struct {
 	// Interval 時間間隔
 	Interval *string `form:"interval,omitempty" json:"interval,omitempty"`
 
-	// Outputsize 取得件数
+	// Outputsize 取得件数。既定値と上限は時間間隔ごとに決まり、未指定・範囲外（0 以下・上限超過）は既定値を返す。
+	// 組み込みの値は 1day が既定 200・上限 5000、1week が既定 156・上限 1000、1month が既定 120・上限 240。
+	// サーバー設定（CANDLES_OUTPUTSIZE）で上書きできる（上限は最大 5000）。
 	Outputsize *int `form:"outputsize,omitempty" json:"outputsize,omitempty"`
+
+	// Currency 価格の換算先通貨（ISO 4217、大文字小文字は区別しない。例: JPY）。指定可能な通貨はサーバー設定（FX_CURRENCIES）による。
+	// 価格は換算先通貨の補助単位の桁数（JPY は 0 桁、USD は 2 桁）に丸める。出来高と騰落率は換算しない。
+	// 銘柄の通貨が未登録、またはレートを取得できない場合は換算せずに元の通貨建てで返し、X-Currency-Warning を設定する
+	Currency *string `form:"currency,omitempty" json:"currency,omitempty"`
+
+	// Adjusted true の場合、登録済みの調整係数（株式分割・併合）を適用し、効力発生日より前の足の価格に係数を掛け、出来高を係数で割った値を返す。
+	// false の場合は保存済み（未調整）の値を返す。未指定時はサーバーの既定値（フィーチャーフラグ adjusted_default、既定は false）に従う
+	Adjusted *bool `form:"adjusted,omitempty" json:"adjusted,omitempty"`
+
+	// Start 期間の開始日（YYYY-MM-DD、UTC の日付。その日の足を含む）。指定すると start 以降の足を返す。end と組み合わせて期間を指定できる。
+	// 期間を指定した場合、outputsize の未指定は時間間隔の上限まで返す（期間の足が多い場合は新しい順に上限件数まで）。as_of とは併用できない
+	Start *openapi_types.Date `form:"start,omitempty" json:"start,omitempty"`
+
+	// End 期間の終了日（YYYY-MM-DD、UTC の日付。その日の足を含む）。start より前の日付は 400（invalid_range）
+	End *openapi_types.Date `form:"end,omitempty" json:"end,omitempty"`
+
+	// AsOf 指定時点で保存されていたローソク足を返す（バックテストの再現用）。RFC 3339 の日時、または YYYY-MM-DD（その日の終わり（UTC）まで）。
+	// APIキーのクライアントのみ指定できる（ユーザーは 403）。値は保存済み（未調整）で、adjusted=true とは併用できない。
+	// 指定時点より後に値が書き換わった足は当時の値が残っていないため含まない。キャッシュを経由せず DB から読み取る。
+	// 各足に取り込み元（source）を付ける
+	AsOf *string `form:"as_of,omitempty" json:"as_of,omitempty"`
+
+	// WithEvents true の場合、返した足の期間のコーポレートイベント（配当の権利落ち日・決算発表日）を各足の events に付ける。
+	// イベントは日付が一致する足、なければ直前の足（休場日・週足・月足の期間内）に付け、最古の足より前・最新の足より後のものは含まない。
+	// イベントを取得できない場合は events を付けずに足を返し、X-Events-Warning を設定する
+	WithEvents *bool `form:"with_events,omitempty" json:"with_events,omitempty"`
+
+	// Fields 返す項目のカンマ区切り（time, open, high, low, close, volume）。指定した項目だけを返し、他の項目は省略する（例: fields=time,close）。
+	// 項目の順序は指定の並びによらず time, open, high, low, close, volume の順で固定。events（?with_events=true）は指定に関わらず付ける。
+	// 未指定の場合は全項目を返す。未知の項目名・空の指定は 400
+	Fields *string `form:"fields,omitempty" json:"fields,omitempty"`
+
+	// Include symbol の場合、応答を {symbol, candles} の形（CandlesEnvelope）にし、銘柄のコード・名前・市場・状態を含める
+	// （銘柄一覧と別々に取得して名前が食い違わないように）。名前は Accept-Language で選んだロケールの表記で、
+	// Content-Language に選んだロケールを返す。未指定の場合は従来どおりローソク足の配列を返す。
+	// Accept: application/vnd.stock.v2+json を指定した場合は、未指定でもこの形で返す。symbol 以外の値は 400
+	Include *string `form:"include,omitempty" json:"include,omitempty"`
+}
+
+// GetCandleAnnotationsParams defines parameters for GetCandleAnnotations.
+type GetCandleAnnotationsParams struct {
+	// Interval 時間間隔
+	Interval *string `form:"interval,omitempty" json:"interval,omitempty"`
+
+	// From 範囲の開始日（YYYY-MM-DD形式）。省略時は制限なし
+	From *openapi_types.Date `form:"from,omitempty" json:"from,omitempty"`
+
+	// To 範囲の終了日（YYYY-MM-DD形式）。省略時は制限なし
+	To *openapi_types.Date `form:"to,omitempty" json:"to,omitempty"`
+
+	// Limit 最大件数（1〜500）
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor 前のページの nextCursor。省略時は先頭ページ
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+}
+
+// GetCandleSparklineParams defines parameters for GetCandleSparkline.
+type GetCandleSparklineParams struct {
+	// Interval 時間間隔
+	Interval *string `form:"interval,omitempty" json:"interval,omitempty"`
+
+	// Outputsize 間引く前のローソク足の件数（最新から）。既定値と上限は GET /candles/{code} の outputsize と同じ（時間間隔ごと、範囲外は既定値）
+	Outputsize *int `form:"outputsize,omitempty" json:"outputsize,omitempty"`
+
+	// Points 返す終値の最大点数
+	Points *int `form:"points,omitempty" json:"points,omitempty"`
+
+	// Adjusted true の場合、登録済みの調整係数（株式分割・併合）を適用した終値を返す。
+	// false の場合は保存済み（未調整）の値を返す。未指定時はサーバーの既定値（フィーチャーフラグ adjusted_default、既定は false）に従う
+	Adjusted *bool `form:"adjusted,omitempty" json:"adjusted,omitempty"`
+}
+
+// GetCandleStatsParams defines parameters for GetCandleStats.
+type GetCandleStatsParams struct {
+	// Interval 時間間隔
+	Interval *string `form:"interval,omitempty" json:"interval,omitempty"`
+
+	// Currency 価格の換算先通貨（ISO 4217、大文字小文字は区別しない。例: JPY）。指定可能な通貨はサーバー設定（FX_CURRENCIES）による。
+	// 価格は換算先通貨の補助単位の桁数（JPY は 0 桁、USD は 2 桁）に丸める。出来高と騰落率は換算しない。
+	// 銘柄の通貨が未登録、またはレートを取得できない場合は換算せずに元の通貨建てで返し、X-Currency-Warning を設定する
+	Currency *string `form:"currency,omitempty" json:"currency,omitempty"`
+
+	// Adjusted true の場合、登録済みの調整係数（株式分割・併合）を適用し、効力発生日より前の足の価格に係数を掛け、出来高を係数で割った値を返す。
+	// false の場合は保存済み（未調整）の値を返す。未指定時はサーバーの既定値（フィーチャーフラグ adjusted_default、既定は false）に従う
+	Adjusted *bool `form:"adjusted,omitempty" json:"adjusted,omitempty"`
+}
+
+// LoginParams defines parameters for Login.
+type LoginParams struct {
+	// Include user を指定すると応答にユーザーのプロフィールを含める
+	Include *LoginParamsInclude `form:"include,omitempty" json:"include,omitempty"`
+}
+
+// LoginParamsInclude defines parameters for Login.
+type LoginParamsInclude string
+
+// AnalyzeCompanyParams defines parameters for AnalyzeCompany.
+type AnalyzeCompanyParams struct {
+	// Async true の場合、分析をジョブとして登録して 202 を返す
+	Async *bool `form:"async,omitempty" json:"async,omitempty"`
 }
 
 // DetectLogoMultipartBody defines parameters for DetectLogo.
@@ -166,6 +1447,120 @@ type DetectLogoMultipartBody struct {
 	Image openapi_types.File `json:"image"`
 }
 
+// UpdateDigestSubscriptionParams defines parameters for UpdateDigestSubscription.
+type UpdateDigestSubscriptionParams struct {
+	// IfMatch 変更の元にした購読状態の ETag
+	IfMatch *string `json:"If-Match,omitempty"`
+}
+
+// DownloadExportParams defines parameters for DownloadExport.
+type DownloadExportParams struct {
+	// Expires 有効期限（Unix 秒）
+	Expires int64 `form:"expires" json:"expires"`
+
+	// Sig 署名
+	Sig string `form:"sig" json:"sig"`
+}
+
+// GetRecentSymbolsParams defines parameters for GetRecentSymbols.
+type GetRecentSymbolsParams struct {
+	// Limit 取得件数（1〜50）
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// AcceptLanguage 銘柄名の表記に使うロケールの希望（ja / en。地域付き・q 値可）。一致しない場合は ja
+	AcceptLanguage *string `json:"Accept-Language,omitempty"`
+}
+
+// GetDailyStatsParams defines parameters for GetDailyStats.
+type GetDailyStatsParams struct {
+	// Symbols カンマ区切りの銘柄コード（例: AAPL,7203.T）。1〜100 銘柄
+	Symbols string `form:"symbols" json:"symbols"`
+}
+
+// GetSymbolsParams defines parameters for GetSymbols.
+type GetSymbolsParams struct {
+	// AcceptLanguage 銘柄名の表記に使うロケールの希望（ja / en。地域付き・q 値可）。一致しない場合は ja
+	AcceptLanguage *string `json:"Accept-Language,omitempty"`
+}
+
+// SearchSymbolsParams defines parameters for SearchSymbols.
+type SearchSymbolsParams struct {
+	// Q 検索語（最大100文字。前後の空白は無視）
+	Q *string `form:"q,omitempty" json:"q,omitempty"`
+
+	// Limit 最大件数（1〜100）
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Offset 読み飛ばす件数
+	Offset *int `form:"offset,omitempty" json:"offset,omitempty"`
+
+	// AcceptLanguage 銘柄名の表記に使うロケールの希望（ja / en。地域付き・q 値可）。一致しない場合は ja
+	AcceptLanguage *string `json:"Accept-Language,omitempty"`
+}
+
+// GetSymbolParams defines parameters for GetSymbol.
+type GetSymbolParams struct {
+	// AcceptLanguage 銘柄名の表記に使うロケールの希望（ja / en。地域付き・q 値可）。一致しない場合は ja
+	AcceptLanguage *string `json:"Accept-Language,omitempty"`
+}
+
+// GetSymbolEventsParams defines parameters for GetSymbolEvents.
+type GetSymbolEventsParams struct {
+	// From 範囲の開始日（YYYY-MM-DD形式）。省略時は当日の 365 日前
+	From *openapi_types.Date `form:"from,omitempty" json:"from,omitempty"`
+
+	// To 範囲の終了日（YYYY-MM-DD形式）。省略時は当日の 90 日後
+	To *openapi_types.Date `form:"to,omitempty" json:"to,omitempty"`
+}
+
+// ReorderWatchlistParams defines parameters for ReorderWatchlist.
+type ReorderWatchlistParams struct {
+	// IfMatch 並び替えの元にしたウォッチリストの ETag
+	IfMatch *string `json:"If-Match,omitempty"`
+}
+
+// ConnectRealtimeParams defines parameters for ConnectRealtime.
+type ConnectRealtimeParams struct {
+	// AccessToken アクセストークン（ログインで発行される JWT）。省略時は最初のメッセージで渡す
+	AccessToken *string `form:"access_token,omitempty" json:"access_token,omitempty"`
+}
+
+// CreateAdjustmentJSONRequestBody defines body for CreateAdjustment for application/json ContentType.
+type CreateAdjustmentJSONRequestBody = CreateAdjustmentRequest
+
+// UpdateAdjustmentJSONRequestBody defines body for UpdateAdjustment for application/json ContentType.
+type UpdateAdjustmentJSONRequestBody = UpdateAdjustmentRequest
+
+// ResolveAnomalyJSONRequestBody defines body for ResolveAnomaly for application/json ContentType.
+type ResolveAnomalyJSONRequestBody = ResolveAnomalyRequest
+
+// UpdateFlagJSONRequestBody defines body for UpdateFlag for application/json ContentType.
+type UpdateFlagJSONRequestBody = UpdateFlagRequest
+
+// PutSymbolNameJSONRequestBody defines body for PutSymbolName for application/json ContentType.
+type PutSymbolNameJSONRequestBody = PutSymbolNameRequest
+
+// PutSymbolStatusJSONRequestBody defines body for PutSymbolStatus for application/json ContentType.
+type PutSymbolStatusJSONRequestBody = PutSymbolStatusRequest
+
+// UpdateUserPlanJSONRequestBody defines body for UpdateUserPlan for application/json ContentType.
+type UpdateUserPlanJSONRequestBody = UpdateUserPlanRequest
+
+// CreateAnnotationJSONRequestBody defines body for CreateAnnotation for application/json ContentType.
+type CreateAnnotationJSONRequestBody = CreateAnnotationRequest
+
+// UpdateAnnotationJSONRequestBody defines body for UpdateAnnotation for application/json ContentType.
+type UpdateAnnotationJSONRequestBody = UpdateAnnotationRequest
+
+// DeleteAccountJSONRequestBody defines body for DeleteAccount for application/json ContentType.
+type DeleteAccountJSONRequestBody = DeleteAccountRequest
+
+// ForgotPasswordJSONRequestBody defines body for ForgotPassword for application/json ContentType.
+type ForgotPasswordJSONRequestBody = ForgotPasswordRequest
+
+// ResetPasswordJSONRequestBody defines body for ResetPassword for application/json ContentType.
+type ResetPasswordJSONRequestBody = ResetPasswordRequest
+
 // LoginJSONRequestBody defines body for Login for application/json ContentType.
 type LoginJSONRequestBody = LoginRequest
 
@@ -175,6 +1570,15 @@ type AnalyzeCompanyJSONRequestBody = CompanyAnalysisRequest
 // DetectLogoMultipartRequestBody defines body for DetectLogo for multipart/form-data ContentType.
 type DetectLogoMultipartRequestBody DetectLogoMultipartBody
 
+// ImportAlertsJSONRequestBody defines body for ImportAlerts for application/json ContentType.
+type ImportAlertsJSONRequestBody = ImportAlertsRequest
+
+// RegisterDeviceJSONRequestBody defines body for RegisterDevice for application/json ContentType.
+type RegisterDeviceJSONRequestBody = RegisterDeviceRequest
+
+// UpdateDigestSubscriptionJSONRequestBody defines body for UpdateDigestSubscription for application/json ContentType.
+type UpdateDigestSubscriptionJSONRequestBody = UpdateDigestSubscriptionRequest
+
 // SignupJSONRequestBody defines body for Signup for application/json ContentType.
 type SignupJSONRequestBody = SignupRequest
 
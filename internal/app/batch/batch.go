@@ -1,22 +1,40 @@
 package batch
 
 import (
+	"context"
 	"log/slog"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/app/config"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/app/di"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/maintenance"
 )
 
 const (
 	rateLimitPerMinute = 7 // TwelveData APIのレートリミット（無料枠上限8/分、固定ウィンドウずれ対策で1つ余裕を持たせる）
+	// ingestUpstreamTimeout は ingest での TwelveData 呼び出し 1 回（リトライ込み）の上限時間。
+	// ユーザー待ちがないため、リトライのバックオフを使い切れるよう長めに取る。
+	ingestUpstreamTimeout = 2 * time.Minute
 )
 
 // jobs は job_id とバッチ実行関数の対応表。
 // 新しいバッチジョブを追加する場合はここに1行追加するだけでよい。
-var jobs = map[string]func(*config.Config) int{
-	"candles": runCandleIngest, // 株価取り込み
-	"logo":    runLogoIngest,   // ロゴURL取り込み
+// jobs は job_id ごとの実行関数です。args は job_id より後ろのコマンドライン引数です。
+var jobs = map[string]func(cfg *config.Config, args []string) int{
+	"candles":      runCandles,                     // 株価取り込み（-from-csv 指定時は CSV 取り込み）
+	"backfill":     withoutArgs(runCandleBackfill), // 分割と確認された銘柄の履歴の再取得
+	"logo":         withoutArgs(runLogoIngest),     // ロゴURL取り込み
+	"events":       withoutArgs(runEventIngest),    // 配当・決算のイベント取り込み（週次）
+	"digest":       withoutArgs(runDigest),         // ウォッチリストの日次ダイジェストメールの送信（candles の後）
+	"symbol-names": runSymbolNames,                 // 銘柄名の多言語表記の CSV 取り込み
+	"seed":         runSeed,                        // ローカル開発用データの投入
+}
+
+// withoutArgs は追加引数を受け取らないジョブを jobs の形に合わせます。
+func withoutArgs(job func(*config.Config) int) func(*config.Config, []string) int {
+	return func(cfg *config.Config, _ []string) int { return job(cfg) }
 }
 
 // supportedJobs は対応している job_id を辞書順で連結した文字列を返す（エラーメッセージ用）。
@@ -31,10 +49,25 @@ func supportedJobs() string {
 }
 
 // Run は job_id（コマンド引数）に応じてバッチを実行し、終了コードを返す。
-// candles: 株価取り込み、logo: ロゴURL取り込み。
+// candles: 株価取り込み、backfill: 分割確認後の履歴の再取得、logo: ロゴURL取り込み、
+// events: 配当・決算のイベント取り込み、digest: ウォッチリストの日次ダイジェストメールの送信、symbol-names: 銘柄名の多言語表記の CSV 取り込み、seed: ローカル開発用データの投入。
 // 環境変数から読み込んだ設定は cfg として注入される。
+// メンテナンスモード（フィーチャーフラグ maintenance_mode）の間はいずれのジョブも実行せず 0 を返す（書き込みを見送る）。
 // os.Exit は呼ばず、終了コードを返すのみ（呼び出し側の main で os.Exit する）。
 func Run(cfg *config.Config, args []string) int {
+	return run(cfg, args, maintenanceActive)
+}
+
+// maintenanceActive は API と同じフラグ（Redis / FLAG_MAINTENANCE_MODE）でメンテナンスモードが有効かを返す。
+// Redis に接続できない場合は FLAG_MAINTENANCE_MODE と既定値（無効）で判定する。
+func maintenanceActive(cfg *config.Config) bool {
+	rdb, closeRedis := connectRedis(cfg)
+	defer closeRedis()
+	return maintenance.New(di.NewFlagRegistry(rdb, cfg.Redis.Keys, cfg.Flags)).Active(context.Background())
+}
+
+// run は Run の本体。メンテナンスモードの判定を active で差し替えられる（テスト用）。
+func run(cfg *config.Config, args []string, active func(*config.Config) bool) int {
 	if len(args) < 1 {
 		slog.Error("job_id is required", "usage", "batch <"+supportedJobs()+">")
 		return 2
@@ -44,5 +77,9 @@ func Run(cfg *config.Config, args []string) int {
 		slog.Error("unknown job_id", "job_id", args[0], "supported", supportedJobs())
 		return 2
 	}
-	return job(cfg)
+	if active(cfg) {
+		slog.Warn("maintenance mode is active, skipping job", "job_id", args[0])
+		return 0
+	}
+	return job(cfg, args[1:])
 }
@@ -1,9 +1,12 @@
 package batch
 
 import (
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/app/config"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/app/seed"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist"
 	infradb "github.com/UCHIDAnobuhiro/stock-backend/internal/infra/db"
@@ -118,16 +121,206 @@ func TestRunInvalidJobID(t *testing.T) {
 	}
 }
 
+// inactive はメンテナンスモードが無効であることを返します（テストで Redis に接続しないため）。
+func inactive(*config.Config) bool { return false }
+
 func TestRun_ReturnsOneWhenDBConfigInvalid(t *testing.T) {
 	t.Parallel()
 
 	// DB_USER 未設定相当の不正な DB Config → OpenSQL の検証で失敗し 1 を返す。
 	cfg := &config.Config{DB: infradb.Config{}}
-	for _, jobID := range []string{"candles", "logo"} {
+	for _, jobID := range []string{"candles", "logo", "events", "digest"} {
+		t.Run(jobID, func(t *testing.T) {
+			if got := run(cfg, []string{jobID}, inactive); got != 1 {
+				t.Errorf("run(%q) = %d, want 1", jobID, got)
+			}
+		})
+	}
+}
+
+// TestRun_SkipsDuringMaintenance はメンテナンスモードの間はジョブを実行せず（DB に接続せず）0 を返すことを検証します。
+func TestRun_SkipsDuringMaintenance(t *testing.T) {
+	t.Parallel()
+
+	// 不正な DB Config でもジョブを実行しないため 1 にならない
+	cfg := &config.Config{DB: infradb.Config{}}
+	active := func(*config.Config) bool { return true }
+	for _, jobID := range []string{"candles", "backfill", "logo", "events", "digest"} {
 		t.Run(jobID, func(t *testing.T) {
-			if got := Run(cfg, []string{jobID}); got != 1 {
-				t.Errorf("Run(%q) = %d, want 1", jobID, got)
+			if got := run(cfg, []string{jobID}, active); got != 0 {
+				t.Errorf("run(%q) = %d, want 0", jobID, got)
+			}
+		})
+	}
+
+	// job_id の誤りはメンテナンス中でも 2 を返す
+	if got := run(cfg, []string{"bogus"}, active); got != 2 {
+		t.Errorf("run(bogus) = %d, want 2", got)
+	}
+}
+
+func TestParseCandleJobFlags(t *testing.T) {
+	testCases := []struct {
+		name    string
+		args    []string
+		want    candleJobFlags
+		wantErr bool
+	}{
+		{
+			name: "引数なしは通常の取り込み",
+			args: nil,
+			want: candleJobFlags{interval: "1day", dateLayout: candles.DefaultCSVDateLayout, maxErrors: candles.DefaultCSVMaxRowErrors},
+		},
+		{
+			name: "CSV 取り込み",
+			args: []string{"-from-csv", "/tmp/7203.csv", "-symbol", "7203.T", "-interval", "1week", "-date-layout", "01/02/2006", "-strict"},
+			want: candleJobFlags{path: "/tmp/7203.csv", symbol: "7203.T", interval: "1week", dateLayout: "01/02/2006", maxErrors: candles.DefaultCSVMaxRowErrors, strict: true},
+		},
+		{
+			name: "取り込みの絞り込み",
+			args: []string{"-intervals", "1day", "-outputsize", "30", "-symbols", "AAPL,7203.T"},
+			want: candleJobFlags{interval: "1day", dateLayout: candles.DefaultCSVDateLayout, maxErrors: candles.DefaultCSVMaxRowErrors,
+				ingest: candleIngestFlags{intervals: "1day", outputsize: 30, symbols: "AAPL,7203.T"}},
+		},
+		{name: "未対応の intervals", args: []string{"-intervals", "1day,1h"}, wantErr: true},
+		{name: "範囲外の outputsize", args: []string{"-outputsize", "-1"}, wantErr: true},
+		{name: "CSV 取り込みと絞り込みの併用", args: []string{"-from-csv", "a.csv", "-symbol", "AAPL", "-symbols", "AAPL"}, wantErr: true},
+		{name: "symbol 未指定", args: []string{"-from-csv", "a.csv"}, wantErr: true},
+		{name: "未対応の interval", args: []string{"-from-csv", "a.csv", "-symbol", "AAPL", "-interval", "1h"}, wantErr: true},
+		{name: "未知のフラグ", args: []string{"-bogus"}, wantErr: true},
+		{name: "余分な位置引数", args: []string{"extra"}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseCandleJobFlags(tc.args)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("parseCandleJobFlags(%v) err=nil, want error", tc.args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("parseCandleJobFlags(%v)=%+v, want %+v", tc.args, got, tc.want)
 			}
 		})
 	}
 }
+
+func TestCandleIngestFlags_Options(t *testing.T) {
+	got := candleIngestFlags{intervals: "1day, 1week", outputsize: 30, symbols: "AAPL,,7203.T "}.options()
+	if !reflect.DeepEqual(got.Intervals, []string{"1day", "1week"}) || got.OutputSize != 30 || !reflect.DeepEqual(got.Symbols, []string{"AAPL", "7203.T"}) {
+		t.Errorf("options() = %+v", got)
+	}
+	if got := (candleIngestFlags{}).options(); got.Intervals != nil || got.Symbols != nil || got.OutputSize != 0 {
+		t.Errorf("options() of empty flags = %+v, want zero value", got)
+	}
+}
+
+func TestRunCandles_InvalidArgs(t *testing.T) {
+	if got := run(&config.Config{}, []string{"candles", "-from-csv", "a.csv"}, inactive); got != 2 {
+		t.Errorf("Run(candles -from-csv without -symbol)=%d, want 2", got)
+	}
+}
+
+func TestParseSymbolNamesFlags(t *testing.T) {
+	testCases := []struct {
+		name    string
+		args    []string
+		want    symbolNamesFlags
+		wantErr bool
+	}{
+		{
+			name: "CSV 取り込み",
+			args: []string{"-from-csv", "/tmp/names.csv", "-max-errors", "10", "-strict"},
+			want: symbolNamesFlags{path: "/tmp/names.csv", maxErrors: 10, strict: true},
+		},
+		{
+			name: "既定値",
+			args: []string{"-from-csv", "names.csv"},
+			want: symbolNamesFlags{path: "names.csv", maxErrors: symbollist.DefaultNamesCSVMaxRowErrors},
+		},
+		{name: "from-csv 未指定", args: nil, wantErr: true},
+		{name: "未知のフラグ", args: []string{"-bogus"}, wantErr: true},
+		{name: "余分な位置引数", args: []string{"-from-csv", "a.csv", "extra"}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseSymbolNamesFlags(tc.args)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("parseSymbolNamesFlags(%v) err=nil, want error", tc.args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("parseSymbolNamesFlags(%v)=%+v, want %+v", tc.args, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRunSymbolNames_InvalidArgs(t *testing.T) {
+	if got := run(&config.Config{}, []string{"symbol-names"}, inactive); got != 2 {
+		t.Errorf("Run(symbol-names without -from-csv)=%d, want 2", got)
+	}
+}
+
+func TestParseSeedFlags(t *testing.T) {
+	now := time.Date(2026, 3, 13, 20, 0, 0, 0, time.FixedZone("JST", 9*60*60))
+	testCases := []struct {
+		name    string
+		args    []string
+		want    seed.Options
+		wantErr bool
+	}{
+		{
+			name: "既定値",
+			args: nil,
+			want: seed.Options{Email: defaultSeedEmail, Password: defaultSeedPassword, Days: seed.DefaultDays, Seed: 1, End: now.UTC()},
+		},
+		{
+			name: "すべて指定",
+			args: []string{"-email", "dev@example.com", "-password", "another-password", "-days", "30", "-seed", "7", "-end", "2025-12-31"},
+			want: seed.Options{Email: "dev@example.com", Password: "another-password", Days: 30, Seed: 7, End: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)},
+		},
+		{name: "days が 0", args: []string{"-days", "0"}, wantErr: true},
+		{name: "days が上限超過", args: []string{"-days", "5001"}, wantErr: true},
+		{name: "不正な end", args: []string{"-end", "2025/12/31"}, wantErr: true},
+		{name: "余分な位置引数", args: []string{"extra"}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseSeedFlags(tc.args, now)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("parseSeedFlags(%v) err=nil, want error", tc.args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("parseSeedFlags(%v)=%+v, want %+v", tc.args, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRunSeed_Refused(t *testing.T) {
+	if got := run(&config.Config{Batch: config.BatchConfig{Production: true, PasswordPepper: "p"}}, []string{"seed"}, inactive); got != 2 {
+		t.Errorf("Run(seed in production)=%d, want 2", got)
+	}
+	if got := run(&config.Config{}, []string{"seed"}, inactive); got != 2 {
+		t.Errorf("Run(seed without PASSWORD_PEPPER)=%d, want 2", got)
+	}
+}
@@ -2,6 +2,8 @@ package batch
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"log/slog"
 	"time"
 
@@ -10,14 +12,69 @@ import (
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/app/config"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/app/di"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/rates"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/db"
 	infraredis "github.com/UCHIDAnobuhiro/stock-backend/internal/infra/redis"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/clientratelimit"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/maintenance"
 )
 
+// runCandles は candles ジョブの引数を解釈し、-from-csv 指定時は CSV 取り込み、
+// それ以外は TwelveData からの取り込みを実行して終了コードを返す。
+func runCandles(cfg *config.Config, args []string) int {
+	opts, err := parseCandleJobFlags(args)
+	if err != nil {
+		slog.Error("invalid candles arguments", "error", err)
+		return 2
+	}
+	if opts.path != "" {
+		return runCandleCSVImport(cfg, opts)
+	}
+	return runCandleIngest(cfg, opts.ingest.options())
+}
+
+// connectRedis は Redis に接続し、クライアントとクローズ関数を返す。
+// 接続はベストエフォートで、失敗時は nil クライアント（キャッシュ更新なし）で続行する。
+func connectRedis(cfg *config.Config) (*redisv9.Client, func()) {
+	rdb, err := infraredis.NewRedisClient(cfg.Redis.Host, cfg.Redis.Port, cfg.Redis.Password)
+	if err != nil {
+		slog.Warn("Redis unavailable, cache warm-up disabled", "error", err)
+		return nil, func() {}
+	}
+	return rdb, func() {
+		if err := rdb.Close(); err != nil {
+			slog.Error("Failed to close Redis client", "error", err)
+		}
+	}
+}
+
+// newCachedCandleRepository は Redis キャッシュ付きの candles リポジトリと、Redis クライアント・クローズ関数を返す。
+// Redis 接続はベストエフォートで、接続失敗時はキャッシュなし（DB 直結、クライアントは nil）で続行する。
+// events を指定すると、保存した足のイベントを保存と同じトランザクションで登録する（nil なら登録しない）。
+// 保存済みの足の上書きは CANDLES_SOURCE_PRECEDENCE の取り込み元の優先順位に従う。
+func newCachedCandleRepository(cfg *config.Config, sqlDB *sql.DB, events candles.UpsertEventWriter) (*candles.CachingRepository, *redisv9.Client, func()) {
+	rdb, closeRedis := connectRedis(cfg)
+
+	// write-through 等のキャッシュ挙動は API と同じフラグ（Redis / FLAG_* 環境変数）で切り替える
+	flagRegistry := di.NewFlagRegistry(rdb, cfg.Redis.Keys, cfg.Flags)
+
+	// TTLはingest連続失敗時のセーフティネット、通常は UpsertBatch で日次上書き
+	return candles.NewCachingRepository(rdb, candles.DefaultCacheTTL, candles.NewRepository(sqlDB).WithUpsertEvents(events).WithSourcePrecedence(cfg.Batch.CandlesSourcePrecedence), cfg.Redis.Keys.Key("candles"), flagRegistry), rdb, closeRedis
+}
+
+// newMarketHealthTracker は TwelveData の呼び出し結果から市場データ連携の状態を判定するトラッカーを生成する。
+// 状態は Redis で API サーバーと共有し（rdb が nil なら共有しない）、遷移はログで通知する。
+func newMarketHealthTracker(cfg *config.Config, rdb *redisv9.Client) *candles.MarketHealthTracker {
+	return candles.NewMarketHealthTracker(cfg.Batch.MarketHealth).
+		WithPublisher(di.NewMarketHealthStore(rdb, cfg.Redis.Keys)).
+		OnChange(di.NotifyMarketHealthChange)
+}
+
 // runCandleIngest は TwelveData から株価データを取り込み、終了コード（0 or 1）を返す。
-func runCandleIngest(cfg *config.Config) int {
+// opts で時間間隔・件数・銘柄を絞り込める（-intervals / -outputsize / -symbols。ゼロ値は全件の取り込み）。
+// メンテナンスモードで途中から見送った場合は失敗とせず 0 を返す（為替レートの取得も見送る）。
+func runCandleIngest(cfg *config.Config, opts candles.IngestOptions) int {
 	sqlDB, err := db.OpenSQL(cfg.DB)
 	if err != nil {
 		slog.Error("DB open failed", "error", err)
@@ -28,46 +85,83 @@ func runCandleIngest(cfg *config.Config) int {
 			slog.Warn("failed to close sqlDB", "error", err)
 		}
 	}()
-	marketRepo := di.NewMarket(cfg.TwelveData)
-	candleRepo := candles.NewRepository(sqlDB)
+	rateLimiter := clientratelimit.NewRateLimiter(rateLimitPerMinute, time.Minute)
+	marketRepo, closeMarket := di.NewMarket(cfg.TwelveData, cfg.Upstream)
+	defer closeMarket()
+	// 利用枠の枯渇時に Retry-After がなければ、レートリミッタの次の枠まで待ってから再試行する
+	marketRepo = marketRepo.WithRequestTimeout(ingestUpstreamTimeout).WithNextSlot(rateLimiter)
 	symbolRepo := symbollist.NewRepository(sqlDB)
 	ingestSymbolRepo := di.NewIngestSymbolAdapter(symbolRepo)
-	rateLimiter := clientratelimit.NewRateLimiter(rateLimitPerMinute, time.Minute)
 
-	// Redis接続（ベストエフォート: 接続失敗時はキャッシュウォームアップなしで続行）
-	var rdb *redisv9.Client
-	if tmp, err := infraredis.NewRedisClient(cfg.Redis.Host, cfg.Redis.Port, cfg.Redis.Password); err != nil {
-		slog.Warn("Redis unavailable, cache warm-up disabled", "error", err)
-	} else {
-		rdb = tmp
-		defer func() {
-			if err := rdb.Close(); err != nil {
-				slog.Error("Failed to close Redis client", "error", err)
-			}
-		}()
-	}
+	// 保存した銘柄ごとのイベントを保存と同じトランザクションで outbox に登録し、API のリレーが価格アラートの評価・
+	// WebSocket の購読者への通知・キャッシュの破棄を行う（保存の直後にバッチが停止しても通知は失われない）
+	cachedCandleRepo, rdb, closeRedis := newCachedCandleRepository(cfg, sqlDB, di.NewCandleOutbox())
+	defer closeRedis()
 
-	// TTLはingest連続失敗時のセーフティネット、通常は UpsertBatch で日次上書き
-	cachedCandleRepo := candles.NewCachingRepository(rdb, candles.DefaultCacheTTL, candleRepo, "candles")
+	freshnessRepo := candles.NewFreshnessRepository(sqlDB)
+	// TwelveData の呼び出し結果から連携全体の状態を判定し、API サーバー（/readyz・fetch-through の停止）へ共有する
+	marketHealth := newMarketHealthTracker(cfg, rdb)
 
-	uc := candles.NewIngestUsecase(marketRepo, cachedCandleRepo, ingestSymbolRepo, rateLimiter)
+	// 終値の急変（株式分割・誤データ）は記録し、ANOMALY_QUARANTINE 指定時は管理者の確認まで取り込みを見送る。
+	// 実行中にメンテナンスモードへ切り替えた場合は、取り込み中の銘柄を終えた時点で残りを見送る
+	uc := candles.NewIngestUsecase(candles.NewHealthTrackingMarket(marketRepo, marketHealth), cachedCandleRepo, ingestSymbolRepo, rateLimiter, freshnessRepo).
+		WithAnomalyDetection(candles.NewAnomalyRepository(sqlDB), candles.NewRepository(sqlDB), cfg.Batch.Anomaly).
+		WithTierBudgets(cfg.Batch.CandlesTierBudgets).
+		WithBatchSize(cfg.Batch.CandlesBatchSize).
+		WithConcurrency(cfg.Batch.CandlesConcurrency).
+		WithOutputSizePolicy(cfg.OutputSize).
+		WithStatsRollup(newStatsRollup(sqlDB)).
+		WithMaintenance(maintenance.New(di.NewFlagRegistry(rdb, cfg.Redis.Keys, cfg.Flags)))
+
+	// 為替レートは API が外部APIを呼ばずに換算できるよう、ingest と同じバッチで取得してキャッシュに書き込む
+	fxCache := rates.NewCachingProvider(rdb, rates.NewStaticProvider(cfg.FX.StaticRates), cfg.Redis.Keys.Key("fx"), rates.DefaultCacheTTL)
+	fxRefresher := rates.NewRefresher(rates.NewUpstreamProvider(marketRepo), fxCache, freshnessRepo, rateLimiter)
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Batch.CandlesTimeoutHours)*time.Hour)
 	defer cancel()
 
 	maxFailureRate := cfg.Batch.CandlesMaxFailureRate
 
-	start := time.Now()
-	result, err := uc.IngestAll(ctx)
-	duration := time.Since(start)
+	result, err := uc.IngestAll(ctx, opts)
 
 	slog.Info("ingest summary",
 		"total", result.Total,
 		"succeeded", result.Succeeded,
 		"failed", result.Failed,
+		"aborted", result.Aborted,
+		"inserted", result.Inserted,
+		"updated", result.Updated,
 		"failure_rate", result.FailureRate(),
-		"duration", duration.String(),
+		"market_health", marketHealth.Snapshot().State,
+		"failed_symbols", result.FailedSymbols(),
+		"duration", result.Duration.String(),
 	)
+	for _, t := range result.Tiers {
+		slog.Info("ingest tier summary",
+			"priority", t.Priority,
+			"total", t.Total,
+			"succeeded", t.Succeeded,
+			"failed", t.Failed,
+			"aborted", t.Aborted,
+		)
+	}
+
+	// -symbols にアクティブでない銘柄がある場合は引数の誤りとして何も取り込まずに終える
+	if errors.Is(err, candles.ErrInvalidIngestOptions) {
+		slog.Error("invalid candles arguments", "error", err)
+		return 2
+	}
+	if errors.Is(err, candles.ErrIngestPaused) {
+		slog.Warn("ingest paused for maintenance mode", "aborted", result.Aborted)
+		return 0
+	}
+
+	// 為替レートの取得失敗はローソク足の取り込み結果（終了コード）に影響させず、鮮度マーカーとログで確認する
+	fxResult, fxErr := fxRefresher.Refresh(ctx, cfg.FX.Currencies)
+	slog.Info("fx refresh summary", "total", fxResult.Total, "succeeded", fxResult.Succeeded, "failed", fxResult.Failed)
+	if fxErr != nil {
+		slog.Warn("fx refresh incomplete", "error", fxErr)
+	}
 
 	if err != nil {
 		slog.Error("ingest aborted by fatal error", "error", err)
@@ -83,3 +177,60 @@ func runCandleIngest(cfg *config.Config) int {
 	slog.Info("ingest ok")
 	return 0
 }
+
+// runCandleBackfill は管理者が分割と確認した異常値について、銘柄の履歴を TwelveData から再取得して上書きし、終了コード（0 or 1）を返す。
+// 再取得の要求は POST /v1/admin/anomalies/{id}/resolve（backfill: true）で登録される。
+func runCandleBackfill(cfg *config.Config) int {
+	sqlDB, err := db.OpenSQL(cfg.DB)
+	if err != nil {
+		slog.Error("DB open failed", "error", err)
+		return 1
+	}
+	defer func() {
+		if err := sqlDB.Close(); err != nil {
+			slog.Warn("failed to close sqlDB", "error", err)
+		}
+	}()
+	rateLimiter := clientratelimit.NewRateLimiter(rateLimitPerMinute, time.Minute)
+	marketRepo, closeMarket := di.NewMarket(cfg.TwelveData, cfg.Upstream)
+	defer closeMarket()
+	marketRepo = marketRepo.WithRequestTimeout(ingestUpstreamTimeout).WithNextSlot(rateLimiter)
+	ingestSymbolRepo := di.NewIngestSymbolAdapter(symbollist.NewRepository(sqlDB))
+
+	cachedCandleRepo, rdb, closeRedis := newCachedCandleRepository(cfg, sqlDB, nil)
+	defer closeRedis()
+
+	uc := candles.NewIngestUsecase(candles.NewHealthTrackingMarket(marketRepo, newMarketHealthTracker(cfg, rdb)), cachedCandleRepo, ingestSymbolRepo, rateLimiter, candles.NewFreshnessRepository(sqlDB)).
+		WithOutputSizePolicy(cfg.OutputSize).
+		WithStatsRollup(newStatsRollup(sqlDB))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Batch.CandlesTimeoutHours)*time.Hour)
+	defer cancel()
+
+	result, err := uc.Backfill(ctx, candles.NewAnomalyRepository(sqlDB))
+	slog.Info("backfill summary",
+		"total", result.Total,
+		"succeeded", result.Succeeded,
+		"failed", result.Failed,
+		"aborted", result.Aborted,
+		"inserted", result.Inserted,
+		"updated", result.Updated,
+	)
+	if err != nil {
+		slog.Error("backfill aborted by fatal error", "error", err)
+		return 1
+	}
+	if result.Failed > 0 {
+		// 失敗分は完了を記録していないため、次回の実行で再取得される
+		slog.Error("backfill incomplete", "failed", result.Failed)
+		return 1
+	}
+	slog.Info("backfill ok")
+	return 0
+}
+
+// newStatsRollup は保存で日足の値が変わった銘柄の要約統計（symbol_daily_stats）を再計算するロールアップを生成します。
+// 日足はキャッシュを介さず DB から直接読みます（書き込み直後の値で再計算するため）。
+func newStatsRollup(sqlDB *sql.DB) *candles.StatsRollup {
+	return candles.NewStatsRollup(candles.NewRepository(sqlDB), candles.NewDailyStatsRepository(sqlDB))
+}
@@ -0,0 +1,184 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/app/config"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/app/di"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/db"
+)
+
+// candleJobFlags は candles ジョブのフラグです。path 以下は -from-csv の CSV 取り込み用、ingest は TwelveData 取り込みの絞り込みです。
+type candleJobFlags struct {
+	path       string
+	symbol     string
+	interval   string
+	dateLayout string
+	maxErrors  int
+	strict     bool
+	ingest     candleIngestFlags
+}
+
+// candleIngestFlags は TwelveData 取り込みの対象を絞り込むフラグです（-from-csv とは併用できません）。
+type candleIngestFlags struct {
+	intervals  string // 保存する時間間隔（カンマ区切り）
+	outputsize int    // 取得する日足の件数
+	symbols    string // 取り込む銘柄コード（カンマ区切り。DB のアクティブ銘柄に限る）
+}
+
+// options は IngestAll に渡す IngestOptions を返します。未指定の項目はゼロ値（従来どおり）です。
+func (f candleIngestFlags) options() candles.IngestOptions {
+	return candles.IngestOptions{Intervals: splitList(f.intervals), OutputSize: f.outputsize, Symbols: splitList(f.symbols)}
+}
+
+// splitList はカンマ区切りの値を前後の空白を除いて分割します（空の要素は捨てます）。
+func splitList(s string) []string {
+	var out []string
+	for item := range strings.SplitSeq(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// parseCandleJobFlags は candles ジョブの引数を解析します。
+// -from-csv 未指定の場合は path が空の値を返し、通常の TwelveData 取り込みとして扱われます。
+func parseCandleJobFlags(args []string) (candleJobFlags, error) {
+	var f candleJobFlags
+	fs := flag.NewFlagSet("candles", flag.ContinueOnError)
+	fs.SetOutput(io.Discard) // 解析エラーは呼び出し側で slog に出力する
+	fs.StringVar(&f.path, "from-csv", "", "取り込む OHLCV CSV ファイルのパス")
+	fs.StringVar(&f.symbol, "symbol", "", "取り込み先の銘柄コード（例: 7203.T）")
+	fs.StringVar(&f.interval, "interval", "1day", "取り込み先の時間間隔（1day / 1week / 1month）")
+	fs.StringVar(&f.dateLayout, "date-layout", candles.DefaultCSVDateLayout, "日付列の Go time レイアウト")
+	fs.IntVar(&f.maxErrors, "max-errors", candles.DefaultCSVMaxRowErrors, "表示する不正行の上限")
+	fs.BoolVar(&f.strict, "strict", false, "最初の不正行で中断する")
+	fs.StringVar(&f.ingest.intervals, "intervals", "", "保存する時間間隔（カンマ区切り。既定は 1day,1week,1month）")
+	fs.IntVar(&f.ingest.outputsize, "outputsize", 0, "取得する日足の件数（既定は CANDLES_OUTPUTSIZE の日足の上限）")
+	fs.StringVar(&f.ingest.symbols, "symbols", "", "取り込む銘柄コード（カンマ区切り。DB のアクティブ銘柄から絞り込む。アクティブでない銘柄はエラー。既定はアクティブな全銘柄）")
+	if err := fs.Parse(args); err != nil {
+		return candleJobFlags{}, err
+	}
+	if fs.NArg() > 0 {
+		return candleJobFlags{}, fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+	if f.path == "" {
+		if err := f.ingest.options().Validate(); err != nil {
+			return candleJobFlags{}, err
+		}
+		return f, nil
+	}
+	if f.ingest != (candleIngestFlags{}) {
+		return candleJobFlags{}, errors.New("-intervals / -outputsize / -symbols cannot be used with -from-csv")
+	}
+	if f.symbol == "" {
+		return candleJobFlags{}, errors.New("-symbol is required with -from-csv")
+	}
+	switch f.interval {
+	case "1day", "1week", "1month":
+	default:
+		return candleJobFlags{}, fmt.Errorf("unsupported -interval %q", f.interval)
+	}
+	return f, nil
+}
+
+// runCandleCSVImport は CSV ファイルのローソク足を取り込み、終了コード（0 or 1）を返す。
+// 日付は銘柄の取引所タイムゾーンで解釈する（TwelveData 取り込みと同じ扱い。ADR-0005 参照）。
+func runCandleCSVImport(cfg *config.Config, f candleJobFlags) int {
+	file, err := os.Open(f.path)
+	if err != nil {
+		slog.Error("failed to open csv", "path", f.path, "error", err)
+		return 1
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			slog.Warn("failed to close csv", "error", err)
+		}
+	}()
+
+	sqlDB, err := db.OpenSQL(cfg.DB)
+	if err != nil {
+		slog.Error("DB open failed", "error", err)
+		return 1
+	}
+	defer func() {
+		if err := sqlDB.Close(); err != nil {
+			slog.Warn("failed to close sqlDB", "error", err)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Batch.CandlesTimeoutHours)*time.Hour)
+	defer cancel()
+
+	loc, err := symbolLocation(ctx, di.NewIngestSymbolAdapter(symbollist.NewRepository(sqlDB)), f.symbol)
+	if err != nil {
+		slog.Error("failed to resolve symbol", "symbol", f.symbol, "error", err)
+		return 1
+	}
+
+	// UpsertBatch 経由でキャッシュも無効化されるよう、API と同じキャッシュ付きリポジトリに書き込む
+	cachedCandleRepo, _, closeRedis := newCachedCandleRepository(cfg, sqlDB, nil)
+	defer closeRedis()
+
+	// 取り込んだ足の取り込み元（csv:<ファイル名のハッシュ>）。サマリーに出して、調査時にファイルと対応付けられるようにする
+	source := candles.CSVSource(f.path)
+	start := time.Now()
+	result, err := candles.ImportCSV(ctx, file, cachedCandleRepo, candles.CSVImportOptions{
+		Symbol:     f.symbol,
+		Interval:   f.interval,
+		DateLayout: f.dateLayout,
+		Location:   loc,
+		MaxErrors:  f.maxErrors,
+		Strict:     f.strict,
+		Source:     source,
+	})
+
+	for _, rowErr := range result.Errors {
+		slog.Warn("skipped csv row", "line", rowErr.Line, "reason", rowErr.Reason)
+	}
+	if omitted := result.Skipped - len(result.Errors); omitted > 0 {
+		slog.Warn("more rows skipped", "omitted", omitted)
+	}
+	slog.Info("csv import summary",
+		"symbol", f.symbol,
+		"interval", f.interval,
+		"source", source,
+		"read", result.Read,
+		"inserted", result.Inserted,
+		"updated", result.Updated,
+		"skipped", result.Skipped,
+		"duration", time.Since(start).String(),
+	)
+
+	if err != nil {
+		slog.Error("csv import aborted", "error", err)
+		return 1
+	}
+	slog.Info("csv import ok")
+	return 0
+}
+
+// symbolLocation はアクティブ銘柄一覧から code の取引所タイムゾーンを返します。
+func symbolLocation(ctx context.Context, symbols candles.SymbolRepository, code string) (*time.Location, error) {
+	active, err := symbols.ListActiveSymbols(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range active {
+		if s.Code == code {
+			return time.LoadLocation(s.Timezone)
+		}
+	}
+	return nil, candles.ErrSymbolNotFound
+}
@@ -0,0 +1,70 @@
+package batch
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/app/config"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/app/di"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/digest"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/watchlist"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/db"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/clientratelimit"
+)
+
+// digestTimeout はダイジェスト送信ジョブ全体の上限時間です。
+const digestTimeout = 3 * time.Hour
+
+// runDigest はダイジェストの購読者にウォッチリストの日次ダイジェストメールを送り、終了コード（0 or 1）を返す。
+// 夜間の candles の取り込みの後に実行する。同じユーザー・対象日には 1 通だけ送るため、再実行しても二重に送らない。
+// メールの送信サービスは未接続のため、送信内容は digest.LogMailer でログに出力する。
+func runDigest(cfg *config.Config) int {
+	sqlDB, err := db.OpenSQL(cfg.DB)
+	if err != nil {
+		slog.Error("DB open failed", "error", err)
+		return 1
+	}
+	defer func() {
+		if err := sqlDB.Close(); err != nil {
+			slog.Warn("failed to close sqlDB", "error", err)
+		}
+	}()
+
+	uc := digest.NewSendUsecase(
+		digest.NewRepository(sqlDB),
+		di.NewDigestRecipients(auth.NewUserRepository(sqlDB)),
+		di.NewDigestWatchlist(watchlist.NewRepository(sqlDB)),
+		di.NewDigestBars(candles.NewRepository(sqlDB), symbollist.NewRepository(sqlDB)),
+		digest.LogMailer{},
+		clientratelimit.NewRateLimiter(cfg.Batch.DigestMaxPerMinute, time.Minute),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), digestTimeout)
+	defer cancel()
+
+	start := time.Now()
+	result, err := uc.SendAll(ctx)
+	slog.Info("digest summary",
+		"subscribers", result.Subscribers,
+		"sent", result.Sent,
+		"already_sent", result.AlreadySent,
+		"no_changes", result.NoChanges,
+		"failed", result.Failed,
+		"duration", time.Since(start).String(),
+	)
+	if err != nil {
+		slog.Error("digest aborted by fatal error", "error", err)
+		return 1
+	}
+	if result.Failed > 0 {
+		// 失敗したユーザーは送信済みとして記録していないため、再実行で送り直せる
+		slog.Error("digest failed for some subscribers", "failed", result.Failed)
+		return 1
+	}
+	slog.Info("digest ok")
+	return 0
+}
@@ -0,0 +1,68 @@
+package batch
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/app/config"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/app/di"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/events"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/db"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/clientratelimit"
+)
+
+// runEventIngest は TwelveData から配当・決算のイベントを取り込み、終了コード（0 or 1）を返す。
+func runEventIngest(cfg *config.Config) int {
+	sqlDB, err := db.OpenSQL(cfg.DB)
+	if err != nil {
+		slog.Error("DB open failed", "error", err)
+		return 1
+	}
+	defer func() {
+		if err := sqlDB.Close(); err != nil {
+			slog.Warn("failed to close sqlDB", "error", err)
+		}
+	}()
+	rateLimiter := clientratelimit.NewRateLimiter(rateLimitPerMinute, time.Minute)
+	market, closeMarket := di.NewMarket(cfg.TwelveData, cfg.Upstream)
+	defer closeMarket()
+	// 利用枠の枯渇時に Retry-After がなければ、レートリミッタの次の枠まで待ってから再試行する
+	market = market.WithRequestTimeout(ingestUpstreamTimeout).WithNextSlot(rateLimiter)
+	symbolRepo := di.NewEventSymbolAdapter(symbollist.NewRepository(sqlDB))
+	uc := events.NewIngestUsecase(di.NewEventProvider(market), events.NewRepository(sqlDB), symbolRepo, rateLimiter)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Batch.EventsTimeoutHours)*time.Hour)
+	defer cancel()
+
+	maxFailureRate := cfg.Batch.EventsMaxFailureRate
+
+	start := time.Now()
+	result, err := uc.IngestAll(ctx)
+	duration := time.Since(start)
+
+	slog.Info("events ingest summary",
+		"total", result.Total,
+		"succeeded", result.Succeeded,
+		"failed", result.Failed,
+		"inserted", result.Inserted,
+		"updated", result.Updated,
+		"failure_rate", result.FailureRate(),
+		"duration", duration.String(),
+	)
+
+	if err != nil {
+		slog.Error("events ingest aborted by fatal error", "error", err)
+		return 1
+	}
+	if shouldFailExit(result, maxFailureRate) {
+		slog.Error("events ingest failure rate exceeded threshold",
+			"failure_rate", result.FailureRate(),
+			"threshold", maxFailureRate,
+		)
+		return 1
+	}
+	slog.Info("events ingest ok")
+	return 0
+}
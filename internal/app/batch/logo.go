@@ -24,7 +24,8 @@ func runLogoIngest(cfg *config.Config) int {
 			slog.Warn("failed to close sqlDB", "error", err)
 		}
 	}()
-	logoProvider := di.NewMarket(cfg.TwelveData)
+	logoProvider, closeMarket := di.NewMarket(cfg.TwelveData, cfg.Upstream)
+	defer closeMarket()
 	symbolRepo := symbollist.NewRepository(sqlDB)
 	rateLimiter := clientratelimit.NewRateLimiter(rateLimitPerMinute, time.Minute)
 	uc := symbollist.NewLogoIngestUsecase(logoProvider, symbolRepo, rateLimiter)
@@ -50,6 +51,16 @@ func runLogoIngest(cfg *config.Config) int {
 		slog.Error("logo ingest aborted by fatal error", "error", err)
 		return 1
 	}
+
+	// 更新したロゴURLを銘柄一覧の last-known-good にも反映する（ベストエフォート）
+	rdb, closeRedis := connectRedis(cfg)
+	defer closeRedis()
+	if rdb != nil {
+		lkg := symbollist.NewCachingRepository(rdb, symbolRepo, cfg.Redis.Keys.Key("symbols", "lkg"), nil)
+		if err := lkg.Refresh(ctx); err != nil {
+			slog.Warn("failed to refresh symbols last-known-good", "error", err)
+		}
+	}
 	if shouldFailExit(result, maxFailureRate) {
 		slog.Error("logo ingest failure rate exceeded threshold",
 			"failure_rate", result.FailureRate(),
@@ -0,0 +1,112 @@
+package batch
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/app/config"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/app/seed"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/db"
+)
+
+const (
+	// seedTimeout は seed ジョブ全体の上限時間。外部 API を呼ばないため短めに取る。
+	seedTimeout = 10 * time.Minute
+	// defaultSeedEmail / defaultSeedPassword はデモユーザーの既定値（ローカル開発専用）。
+	defaultSeedEmail    = "demo@example.com"
+	defaultSeedPassword = "demo-password-123"
+)
+
+// parseSeedFlags は seed ジョブの引数を解析します。-end 未指定時は now（UTC）の日付までを生成します。
+func parseSeedFlags(args []string, now time.Time) (seed.Options, error) {
+	opts := seed.Options{}
+	var end string
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	fs.SetOutput(io.Discard) // 解析エラーは呼び出し側で slog に出力する
+	fs.StringVar(&opts.Email, "email", defaultSeedEmail, "デモユーザーのメールアドレス")
+	fs.StringVar(&opts.Password, "password", defaultSeedPassword, "デモユーザーのパスワード（12 文字以上）")
+	fs.IntVar(&opts.Days, "days", seed.DefaultDays, "生成する日足の営業日数")
+	fs.Uint64Var(&opts.Seed, "seed", seed.DefaultSeed, "合成ローソク足の乱数シード")
+	fs.StringVar(&end, "end", "", "合成ローソク足の最終日（YYYY-MM-DD）")
+	if err := fs.Parse(args); err != nil {
+		return seed.Options{}, err
+	}
+	if fs.NArg() > 0 {
+		return seed.Options{}, fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+	if opts.Days < 1 || opts.Days > seed.MaxDays {
+		return seed.Options{}, fmt.Errorf("-days must be between 1 and %d", seed.MaxDays)
+	}
+	opts.End = now.UTC()
+	if end != "" {
+		t, err := time.Parse(time.DateOnly, end)
+		if err != nil {
+			return seed.Options{}, fmt.Errorf("invalid -end %q: %w", end, err)
+		}
+		opts.End = t
+	}
+	return opts, nil
+}
+
+// runSeed はローカル開発用の銘柄・デモユーザー・合成ローソク足を投入し、終了コードを返す。
+// 何度実行しても重複しない。APP_ENV=production では実行しない。
+func runSeed(cfg *config.Config, args []string) int {
+	if cfg.Batch.Production {
+		slog.Error("seed refused in production", "reason", "APP_ENV=production")
+		return 2
+	}
+	opts, err := parseSeedFlags(args, time.Now())
+	if err != nil {
+		slog.Error("invalid seed arguments", "error", err)
+		return 2
+	}
+	if cfg.Batch.PasswordPepper == "" {
+		// API と同じ pepper でハッシュしないとデモユーザーでログインできない
+		slog.Error("PASSWORD_PEPPER is required for seed")
+		return 2
+	}
+
+	sqlDB, err := db.OpenSQL(cfg.DB)
+	if err != nil {
+		slog.Error("DB open failed", "error", err)
+		return 1
+	}
+	defer func() {
+		if err := sqlDB.Close(); err != nil {
+			slog.Warn("failed to close sqlDB", "error", err)
+		}
+	}()
+
+	// API が古いローソク足をキャッシュから返さないよう、書き込みはキャッシュ付きリポジトリ経由にする
+	cachedCandleRepo, _, closeRedis := newCachedCandleRepository(cfg, sqlDB, nil)
+	defer closeRedis()
+
+	ctx, cancel := context.WithTimeout(context.Background(), seedTimeout)
+	defer cancel()
+
+	start := time.Now()
+	result, err := seed.Run(ctx, seed.Deps{DB: sqlDB, Candles: cachedCandleRepo, Pepper: cfg.Batch.PasswordPepper}, opts)
+	slog.Info("seed summary",
+		"symbols", result.Symbols,
+		"symbols_changed", result.SymbolsChanged,
+		"user_id", result.UserID,
+		"user_created", result.UserCreated,
+		"candle_symbols", result.Candles.Succeeded,
+		"inserted", result.Candles.Inserted,
+		"updated", result.Candles.Updated,
+		"duration", time.Since(start).String(),
+	)
+	if err != nil {
+		slog.Error("seed failed", "error", err)
+		return 1
+	}
+	if !result.UserCreated {
+		slog.Info("demo user already exists, password unchanged")
+	}
+	slog.Info("seed ok")
+	return 0
+}
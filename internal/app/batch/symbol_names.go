@@ -0,0 +1,107 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/app/config"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/db"
+)
+
+// symbolNamesTimeout は symbol-names ジョブ全体の上限時間。外部 API を呼ばないため短めに取る。
+const symbolNamesTimeout = 10 * time.Minute
+
+// symbolNamesFlags は symbol-names ジョブのフラグです。
+type symbolNamesFlags struct {
+	path      string
+	maxErrors int
+	strict    bool
+}
+
+// parseSymbolNamesFlags は symbol-names ジョブの引数を解析します。-from-csv は必須です。
+func parseSymbolNamesFlags(args []string) (symbolNamesFlags, error) {
+	var f symbolNamesFlags
+	fs := flag.NewFlagSet("symbol-names", flag.ContinueOnError)
+	fs.SetOutput(io.Discard) // 解析エラーは呼び出し側で slog に出力する
+	fs.StringVar(&f.path, "from-csv", "", "取り込む symbol,locale,name CSV ファイルのパス")
+	fs.IntVar(&f.maxErrors, "max-errors", symbollist.DefaultNamesCSVMaxRowErrors, "表示する不正行の上限")
+	fs.BoolVar(&f.strict, "strict", false, "最初の不正行で中断する")
+	if err := fs.Parse(args); err != nil {
+		return symbolNamesFlags{}, err
+	}
+	if fs.NArg() > 0 {
+		return symbolNamesFlags{}, fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+	if f.path == "" {
+		return symbolNamesFlags{}, errors.New("-from-csv is required")
+	}
+	return f, nil
+}
+
+// runSymbolNames は CSV ファイルの銘柄名（既定ロケール以外）を取り込み、終了コードを返す。
+// 多言語の銘柄名はキャッシュしていないため（LKG は既定ロケールのみ）、取り込み後のキャッシュ無効化は不要。
+func runSymbolNames(cfg *config.Config, args []string) int {
+	f, err := parseSymbolNamesFlags(args)
+	if err != nil {
+		slog.Error("invalid symbol-names arguments", "error", err)
+		return 2
+	}
+
+	file, err := os.Open(f.path)
+	if err != nil {
+		slog.Error("failed to open csv", "path", f.path, "error", err)
+		return 1
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			slog.Warn("failed to close csv", "error", err)
+		}
+	}()
+
+	sqlDB, err := db.OpenSQL(cfg.DB)
+	if err != nil {
+		slog.Error("DB open failed", "error", err)
+		return 1
+	}
+	defer func() {
+		if err := sqlDB.Close(); err != nil {
+			slog.Warn("failed to close sqlDB", "error", err)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), symbolNamesTimeout)
+	defer cancel()
+
+	start := time.Now()
+	result, err := symbollist.ImportNamesCSV(ctx, file, symbollist.NewRepository(sqlDB), symbollist.NamesCSVOptions{
+		MaxErrors: f.maxErrors,
+		Strict:    f.strict,
+	})
+
+	for _, rowErr := range result.Errors {
+		slog.Warn("skipped csv row", "line", rowErr.Line, "reason", rowErr.Reason)
+	}
+	if omitted := result.Skipped - len(result.Errors); omitted > 0 {
+		slog.Warn("more rows skipped", "omitted", omitted)
+	}
+	slog.Info("symbol names import summary",
+		"read", result.Read,
+		"upserted", result.Upserted,
+		"skipped", result.Skipped,
+		"duration", time.Since(start).String(),
+	)
+
+	if err != nil {
+		slog.Error("symbol names import aborted", "error", err)
+		return 1
+	}
+	slog.Info("symbol names import ok")
+	return 0
+}
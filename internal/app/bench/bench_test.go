@@ -0,0 +1,204 @@
+package bench
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/app/seed"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/candleshttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/db/dbtest"
+)
+
+// benchSymbol はベンチマークで読み取る銘柄です。
+const benchSymbol = "AAPL"
+
+// dbUnavailable は PostgreSQL を起動できなかった（DB のベンチマークをスキップする）ことを示します。
+var dbUnavailable = true
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	// ベンチマークを実行する場合のみ PostgreSQL を起動する（go test ./... の退行ガードは DB なしで動かす）
+	if f := flag.Lookup("test.bench"); f == nil || f.Value.String() == "" {
+		os.Exit(m.Run())
+	}
+	dbUnavailable = false
+	code, err := dbtest.RunMainWithPostgres(m)
+	if err != nil {
+		// Docker も TEST_DB_DSN もない環境では、キャッシュ・ハンドラーのベンチマークだけを実行する
+		log.Printf("dbtest setup failed, skipping DB benchmarks: %v", err)
+		dbUnavailable = true
+		code = m.Run()
+	}
+	os.Exit(code)
+}
+
+// fixtureRepository は seed.Fixture の日足を返すメモリ上のリポジトリです（キャッシュミス時の DB の代わり）。
+type fixtureRepository struct {
+	all []candles.Candle // 新しい順
+}
+
+func newFixtureRepository(n int) *fixtureRepository {
+	cs := seed.Fixture(seed.DefaultSeed, benchSymbol, n)
+	// DB と同じく新しい順で返す
+	for i, j := 0, len(cs)-1; i < j; i, j = i+1, j-1 {
+		cs[i], cs[j] = cs[j], cs[i]
+	}
+	return &fixtureRepository{all: cs}
+}
+
+func (f *fixtureRepository) Find(_ context.Context, _, _ string, outputsize int) ([]candles.Candle, error) {
+	return f.all[:min(outputsize, len(f.all))], nil
+}
+
+func (f *fixtureRepository) UpsertBatch(_ context.Context, cs []candles.Candle) (candles.UpsertStats, error) {
+	return candles.UpsertStats{Inserted: int64(len(cs))}, nil
+}
+
+// activeSymbols は benchSymbol のみをアクティブ銘柄とする ActiveSymbolChecker です。
+type activeSymbols struct{}
+
+func (activeSymbols) Contains(_ context.Context, code string) (bool, error) {
+	return code == benchSymbol, nil
+}
+
+// newCachingRepository は miniredis を使う CachingRepository を生成します。
+// キャッシュには MaxOutputSize 件（5000 本）の日足を保持する本番と同じ形になります。
+func newCachingRepository(tb testing.TB) (*candles.CachingRepository, *miniredis.Miniredis) {
+	tb.Helper()
+	mr := miniredis.RunT(tb)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	tb.Cleanup(func() { _ = rdb.Close() })
+	return candles.NewCachingRepository(rdb, time.Hour, newFixtureRepository(candles.MaxOutputSize), "candles", nil), mr
+}
+
+// newLocalCachedRepository は newCachingRepository にプロセス内キャッシュ（L1）を重ねた CachingRepository を生成します。
+func newLocalCachedRepository(tb testing.TB) (*candles.CachingRepository, *miniredis.Miniredis) {
+	tb.Helper()
+	repo, mr := newCachingRepository(tb)
+	return repo.WithLocalCache(candles.LocalCacheConfig{TTL: candles.MaxLocalCacheTTL}), mr
+}
+
+// warm はキャッシュを埋めます。
+func warm(tb testing.TB, repo *candles.CachingRepository) {
+	tb.Helper()
+	if _, err := repo.Find(context.Background(), benchSymbol, "1day", 200); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+// BenchmarkCachingRepository_FindHit はキャッシュヒット時の Find（Redis の取得・JSON のデコード・切り出し）を計測します。
+func BenchmarkCachingRepository_FindHit(b *testing.B) {
+	repo, _ := newCachingRepository(b)
+	warm(b, repo)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := repo.Find(ctx, benchSymbol, "1day", 200); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkCachingRepository_FindLocalHit はプロセス内キャッシュのヒット時の Find（切り出しのコピーのみ）を計測します。
+// Redis の往復と JSON のデコードを行わないため、BenchmarkCachingRepository_FindHit との差がその費用です。
+func BenchmarkCachingRepository_FindLocalHit(b *testing.B) {
+	repo, _ := newLocalCachedRepository(b)
+	warm(b, repo)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := repo.Find(ctx, benchSymbol, "1day", 200); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkCachingRepository_FindMiss はキャッシュミス時の Find（内部リポジトリの取得・JSON のエンコード・Redis への保存）を計測します。
+// 内部リポジトリはメモリ上のため、DB の時間は含みません（BenchmarkRepository_Find を参照）。
+func BenchmarkCachingRepository_FindMiss(b *testing.B) {
+	repo, mr := newCachingRepository(b)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		b.StopTimer()
+		mr.FlushAll()
+		b.StartTimer()
+		if _, err := repo.Find(ctx, benchSymbol, "1day", 200); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkCandlesHandler_CacheHit は GET /candles/{code} の 1 リクエスト（銘柄の解決・キャッシュヒット・レスポンスの変換と書き込み）を計測します。
+func BenchmarkCandlesHandler_CacheHit(b *testing.B) {
+	repo, _ := newCachingRepository(b)
+	warm(b, repo)
+	h := candleshttp.NewHandler(candles.NewUsecase(repo, activeSymbols{}), nil, nil)
+	router := chi.NewRouter()
+	router.Get("/candles/{code}", h.GetCandlesHandler)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/candles/"+benchSymbol+"?interval=1day&outputsize=200", nil))
+		if w.Code != http.StatusOK {
+			b.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+		}
+	}
+}
+
+// BenchmarkRepository_Find は 10,000 行（2 銘柄 × 5000 本）を投入した PostgreSQL からの Find を計測します。
+func BenchmarkRepository_Find(b *testing.B) {
+	if dbUnavailable {
+		b.Skip("PostgreSQL is not available (requires Docker or TEST_DB_DSN)")
+	}
+	db := dbtest.OpenIsolatedDB(b)
+	ctx := context.Background()
+	if _, err := db.ExecContext(ctx,
+		`INSERT INTO symbols (code, name, market, timezone) VALUES
+		   ('AAPL', 'Apple Inc.', 'NASDAQ', 'America/New_York'),
+		   ('MSFT', 'Microsoft Corporation', 'NASDAQ', 'America/New_York')`); err != nil {
+		b.Fatal(err)
+	}
+	repo := candles.NewRepository(db)
+	for _, code := range []string{"AAPL", "MSFT"} {
+		if _, err := repo.UpsertBatch(ctx, seed.Fixture(seed.DefaultSeed, code, candles.MaxOutputSize)); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	for _, n := range []int{200, candles.MaxOutputSize} {
+		b.Run(benchName(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				cs, err := repo.Find(ctx, benchSymbol, "1day", n)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(cs) != n {
+					b.Fatalf("len = %d, want %d", len(cs), n)
+				}
+			}
+		})
+	}
+}
+
+// benchName はサブベンチマークの名前（outputsize=N）を返します。
+func benchName(n int) string {
+	return "outputsize=" + strconv.Itoa(n)
+}
@@ -0,0 +1,11 @@
+// Package bench はローソク足の読み取りのホットパス（キャッシュ・DB・HTTP ハンドラー）のベンチマークと、
+// 割り当て回数の退行ガードのテストを置くパッケージです。本番コードからは参照しません。
+//
+// 入力は seed ジョブと同じ合成ローソク足（seed.Fixture）で、実行ごとに同じデータになります。
+//
+//	# ベンチマーク（DB のベンチマークには Docker または TEST_DB_DSN が必要）
+//	go test ./internal/app/bench -run '^$' -bench . -benchmem
+//
+// ベンチマークを指定しない go test では PostgreSQL を起動せず、退行ガード（TestAllocBudget_*）のみを実行します。
+// レスポンスの型への変換（DTO）のベンチマークは非公開関数のため candleshttp に置いています。
+package bench
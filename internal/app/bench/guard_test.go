@@ -0,0 +1,64 @@
+package bench
+
+import (
+	"context"
+	"testing"
+)
+
+// findHitAllocBudget はキャッシュヒット時の Find 1 回あたりの割り当て回数の上限です。
+// 計測値（miniredis のサーバー側を含め 50 前後）に十分な余裕を持たせ、実行環境による揺れでは失敗せず、
+// 要素ごとに割り当てるようなデコードへの変更（5000 本で数千回）のような大きな退行だけを検出します。
+// 意図した変更で超える場合は、BenchmarkCachingRepository_FindHit の結果を確認してから引き上げてください。
+const findHitAllocBudget = 200
+
+// TestAllocBudget_CachingRepositoryFindHit はキャッシュヒット時の Find の割り当て回数が予算内であることを検証します。
+// 時間は環境に左右されるため検証せず、決定的な割り当て回数だけを少ない反復で計測します。
+func TestAllocBudget_CachingRepositoryFindHit(t *testing.T) {
+	repo, _ := newCachingRepository(t)
+	warm(t, repo)
+	ctx := context.Background()
+
+	var err error
+	allocs := testing.AllocsPerRun(5, func() {
+		if _, e := repo.Find(ctx, benchSymbol, "1day", 200); e != nil {
+			err = e
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if allocs > findHitAllocBudget {
+		t.Errorf("allocs per Find hit = %.0f, budget %d", allocs, findHitAllocBudget)
+	}
+	t.Logf("allocs per Find hit = %.0f (budget %d)", allocs, findHitAllocBudget)
+}
+
+// findLocalHitAllocBudget はプロセス内キャッシュのヒット時の Find 1 回あたりの割り当て回数の上限です。
+// 計測値（キーの組み立てと切り出しのコピーで 10 前後）に余裕を持たせ、JSON のデコードが入るような退行を検出します。
+const findLocalHitAllocBudget = 20
+
+// TestAllocBudget_CachingRepositoryFindLocalHit はプロセス内キャッシュのヒット時の Find が Redis に問い合わせず、
+// 割り当て回数が予算内であること（デコードしないこと）を検証します。
+func TestAllocBudget_CachingRepositoryFindLocalHit(t *testing.T) {
+	repo, mr := newLocalCachedRepository(t)
+	warm(t, repo)
+	ctx := context.Background()
+	commands := mr.CommandCount()
+
+	var err error
+	allocs := testing.AllocsPerRun(5, func() {
+		if _, e := repo.Find(ctx, benchSymbol, "1day", 200); e != nil {
+			err = e
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mr.CommandCount(); got != commands {
+		t.Errorf("redis commands during local hits = %d, want 0", got-commands)
+	}
+	if allocs > findLocalHitAllocBudget {
+		t.Errorf("allocs per Find local hit = %.0f, budget %d", allocs, findLocalHitAllocBudget)
+	}
+	t.Logf("allocs per Find local hit = %.0f (budget %d)", allocs, findLocalHitAllocBudget)
+}
@@ -1,28 +1,47 @@
 // Package config はアプリケーション全体の環境変数読み込みを一箇所に集約します。
 //
-// os.Getenv の呼び出しはこのパッケージ内に閉じ込め、他のパッケージは
-// 構築済みの設定値を注入される形にします。エントリポイントごとに必要な
+// 環境変数の読み取りはこのパッケージ内に閉じ込め（型付きの読み取りは env.Reader）、
+// 他のパッケージは構築済みの設定値を注入される形にします。エントリポイントごとに必要な
 // 環境変数と必須項目が異なるため、LoadAPI / LoadBatch / LoadMigrate に
 // 分割しています。
 //
-// 各 Load は logger を生成しません（副作用を持ちません）。返却される *Config は
-// 致命的エラー時でも Log を best-effort で埋めて非 nil で返し、非致命的な不正値は
-// Warnings に蓄積します。呼び出し側は cfg.Log でロガーを構成し、cfg.Warnings を
-// slog.Warn で出力したうえで、err を確認してください。
+// 各 Load は logger を生成しません（副作用を持ちません）。必須項目の欠落や解釈できない値は
+// デフォルト値に黙って戻さず、全てを errors.Join でまとめたエラーとして返します（1 回の起動で
+// 全ての誤りが分かるようにするため）。返却される *Config はエラー時でも Log を best-effort で
+// 埋めて非 nil で返し、ロガー自体の設定（LOG_*）と FLAG_* の不正値は Warnings に蓄積します。
+// 呼び出し側は cfg.Log でロガーを構成し、cfg.Warnings を slog.Warn で出力したうえで、
+// err を確認してください。
 package config
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/app/di"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/twelvedata"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/logodetection"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/push"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/rates"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/db"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/httpclient"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/logging"
+	infraredis "github.com/UCHIDAnobuhiro/stock-backend/internal/infra/redis"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/env"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/flags"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/apikey"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/deprecation"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/maintenance"
 )
 
 const (
@@ -32,25 +51,53 @@ const (
 	defaultIngestTimeoutHours = 3
 	// defaultMaxFailureRate は *_MAX_FAILURE_RATE のデフォルト値。
 	defaultMaxFailureRate = 0.2
+	// defaultDigestMaxPerMinute は DIGEST_MAX_PER_MINUTE のデフォルト値（メール送信サービスの一般的な送信レートに収まる値）。
+	defaultDigestMaxPerMinute = 60
+	// defaultIngestConcurrency は INGEST_CONCURRENCY のデフォルト値（保存の間に次の銘柄の取得を進めるのに十分な数）。
+	defaultIngestConcurrency = 3
+	// defaultAPIKeyRateLimit は API_KEY_RATE_LIMIT_PER_MINUTE のデフォルト値。
+	defaultAPIKeyRateLimit = 600
+	// defaultExportDirName は EXPORT_DIR 未設定時に OS の一時ディレクトリ配下へ作るディレクトリ名。
+	defaultExportDirName = "stock-backend-export"
 )
 
+// defaultFXCurrencies は FX_CURRENCIES 未設定時に換算先として受け付ける通貨です。
+var defaultFXCurrencies = []string{"JPY", "USD"}
+
 // Config はアプリケーション全体の設定を保持します。
 // 使用するエントリポイントによって、埋められるフィールドのグループが異なります。
 type Config struct {
-	Log        LogConfig         // 全エントリポイント共通
-	DB         db.Config         // API / batch / migrate
-	Redis      RedisConfig       // API / batch
-	Server     ServerConfig      // API のみ
-	OAuth      *di.OAuthConfig   // API のみ（OAuth 無効なら nil）
-	TwelveData twelvedata.Config // batch のみ
-	Batch      BatchConfig       // batch のみ
-	Warnings   []string          // 非致命的な不正値（呼び出し側で slog.Warn する）
+	Log        LogConfig                // 全エントリポイント共通
+	DB         db.Config                // API / batch / migrate
+	Redis      RedisConfig              // API / batch
+	Server     ServerConfig             // API のみ
+	OAuth      *di.OAuthConfig          // API のみ（OAuth 無効なら nil）
+	TwelveData twelvedata.Config        // batch のみ
+	Upstream   httpclient.Config        // batch のみ（外部APIクライアントの接続プール・プロキシ。Timeout は TwelveData.Timeout を使う）
+	FX         FXConfig                 // API / batch
+	OutputSize candles.OutputSizePolicy // API / batch（CANDLES_OUTPUTSIZE。時間間隔ごとの outputsize の既定値と上限）
+	Push       PushConfig               // API のみ
+	Batch      BatchConfig              // batch のみ
+	Flags      map[string]bool          // API / batch（FLAG_* 環境変数によるフィーチャーフラグの上書き値）
+	Warnings   []string                 // 非致命的な不正値（呼び出し側で slog.Warn する）
 }
 
 // LogConfig はロガー構成に必要な設定です。
 type LogConfig struct {
-	Level   slog.Level
-	UseJSON bool
+	Level           slog.Level
+	UseJSON         bool
+	ComponentLevels map[string]slog.Level // LOG_LEVEL_<COMPONENT> による上書き（キーは小文字）
+	SampleEvery     int                   // LOG_SAMPLE_EVERY（sampled=true の記録を N 件に 1 件残す）
+}
+
+// Options は logging.New に渡す形に変換します。
+func (c LogConfig) Options() logging.Options {
+	return logging.Options{
+		Level:           c.Level,
+		UseJSON:         c.UseJSON,
+		ComponentLevels: c.ComponentLevels,
+		SampleEvery:     c.SampleEvery,
+	}
 }
 
 // RedisConfig は Redis 接続設定です。
@@ -58,15 +105,70 @@ type RedisConfig struct {
 	Host     string
 	Port     string
 	Password string
+	// Keys は全 Redis キーに環境の名前空間（CACHE_NAMESPACE、未設定なら APP_ENV）を付与します。
+	Keys infraredis.KeyBuilder
+}
+
+// FXConfig は通貨換算（為替レート）の設定です。
+type FXConfig struct {
+	Currencies  []string           // FX_CURRENCIES。換算先として受け付け、batch がレートを取得する通貨（デフォルト: JPY,USD）
+	StaticRates map[string]float64 // FX_STATIC_RATES。キャッシュにレートがない場合の固定レート（例: USD/JPY=150）
 }
 
 // ServerConfig は API サーバー固有の検証済み設定です。
 type ServerConfig struct {
 	JWTSecret      string
+	JWTExpiration  time.Duration // JWT_EXPIRATION。トークンと認証 Cookie の有効期間（デフォルト: 1h）
 	PasswordPepper string
 	SecureCookie   bool
 	CORSOrigins    []string
-	GCPProjectID   string // GOOGLE_CLOUD_PROJECT。未設定可（トレース相関に使用）
+	GCPProjectID   string        // GOOGLE_CLOUD_PROJECT。未設定可（トレース相関に使用）
+	APIKeys        apikey.Config // API_KEYS。未設定ならAPIキー認証は常に 401
+	ExportDir      string        // EXPORT_DIR。データエクスポートのアーカイブ一時保存先（デフォルト: OS の一時ディレクトリ配下）
+	// CandlesRefreshAhead はローソク足キャッシュの先行再取得の設定です（CANDLES_REFRESH_AHEAD_THRESHOLD / CANDLES_REFRESH_AHEAD_CONCURRENCY）。
+	CandlesRefreshAhead candles.RefreshAheadConfig
+	// CandlesQueryTimeout はローソク足の読み取りクエリの実行時間の上限です（CANDLES_QUERY_TIMEOUT。デフォルト: 5s）。
+	CandlesQueryTimeout time.Duration
+	// CandlesCacheBuckets はローソク足キャッシュのエントリを分ける件数の区切りです（CANDLES_CACHE_BUCKETS。nil なら全データの 1 エントリ）。
+	CandlesCacheBuckets []int
+	// CandlesLocalCache はローソク足のプロセス内キャッシュ（Redis の手前の L1）の設定です
+	// （CANDLES_LOCAL_CACHE_TTL / CANDLES_LOCAL_CACHE_ENTRIES / CANDLES_LOCAL_CACHE_BYTES。TTL 未設定なら無効）。
+	CandlesLocalCache candles.LocalCacheConfig
+	// CandlesDebugHeaders はローソク足のすべての応答にデバッグ用のヘッダー（X-Cache-Status・X-Cache-Key・X-Timing-*）を付けるかです
+	// （CANDLES_DEBUG_HEADERS。デフォルト: false = APIキーのクライアント等が X-Debug で要求した場合のみ）。
+	CandlesDebugHeaders bool
+	// SymbolsActiveCodeTTL はアクティブな銘柄コード集合をプロセス内に保持する期間です（SYMBOLS_ACTIVE_CODE_TTL。デフォルト: 60s）。
+	SymbolsActiveCodeTTL time.Duration
+	// ServerHeader は Server レスポンスヘッダーにバージョンとコミットを付けるかです（SERVER_HEADER。デフォルト: true）。
+	ServerHeader bool
+	// RequireIfMatch はウォッチリストの並び替え・ダイジェストの購読の変更に If-Match を必須にするかです
+	// （REQUIRE_IF_MATCH。デフォルト: false = 省略を受け付ける。true の場合、ないと 428）。
+	RequireIfMatch bool
+	// SchemaStrict は起動時のスキーマ検証で差異（テーブル・列・インデックスの欠落）があれば起動しないかです
+	// （SCHEMA_STRICT。デフォルト: false = ログと /readyz の degraded で知らせて起動を続ける）。
+	SchemaStrict bool
+	// LogoQuota はロゴ検出・企業分析のユーザーごとの日次の利用枠です
+	// （LOGO_QUOTA_DETECT_DAILY / LOGO_QUOTA_ANALYZE_DAILY / LOGO_QUOTA_EXEMPT_USER_IDS）。
+	LogoQuota logodetection.QuotaConfig
+	// ImpersonationWriteAllowlist はなりすまし中でも書き込みを許可するルート（"<METHOD> <ルートのパターン>"）です
+	// （IMPERSONATION_WRITE_ALLOWLIST。未設定ならなりすまし中は読み取り専用）。
+	ImpersonationWriteAllowlist []string
+	// DeprecatedRoutes は Deprecation / Sunset / Link ヘッダーを付ける廃止予定のルートです（DEPRECATED_ROUTES。未設定なら付けない）。
+	DeprecatedRoutes []deprecation.Rule
+	// MaintenanceWriteAllowlist はメンテナンス中でも書き込みを許可するルート（"<METHOD> <ルートのパターン>"）です
+	// （MAINTENANCE_WRITE_ALLOWLIST。未設定ならログイン・ログアウト・フラグの切り替え。maintenance.DefaultWriteAllowlist）。
+	MaintenanceWriteAllowlist []string
+	// MaintenanceRetryAfter はメンテナンス中に拒否した書き込みに返す Retry-After です（MAINTENANCE_RETRY_AFTER。デフォルト: 5m）。
+	MaintenanceRetryAfter time.Duration
+}
+
+// PushConfig はプッシュ通知の送信（API の push.Dispatcher）の設定です。
+type PushConfig struct {
+	// FCMCredentialsFile は FCM の送信に使うサービスアカウントの JSON 鍵のパスです（PUSH_FCM_CREDENTIALS_FILE）。
+	// 未設定なら送信せずにログへ出力します（開発環境向け）。
+	FCMCredentialsFile string
+	// Dispatcher は送信の並行数と再試行の設定です（PUSH_WORKERS / PUSH_MAX_ATTEMPTS / PUSH_BASE_BACKOFF / PUSH_MAX_BACKOFF）。
+	Dispatcher push.DispatcherConfig
 }
 
 // BatchConfig はバッチ実行のタイムアウト・失敗率しきい値です。
@@ -75,240 +177,590 @@ type BatchConfig struct {
 	CandlesMaxFailureRate float64
 	LogoTimeoutHours      int
 	LogoMaxFailureRate    float64
+	EventsTimeoutHours    int
+	EventsMaxFailureRate  float64
+	// DigestMaxPerMinute はダイジェストメールの送信数の上限（全ユーザー合計、1 分あたり）です（DIGEST_MAX_PER_MINUTE）。
+	DigestMaxPerMinute int
+	// CandlesTierBudgets は ingest の優先度ごとの時間予算です（INGEST_TIER_BUDGETS。nil なら予算なし）。
+	CandlesTierBudgets map[int]time.Duration
+	// CandlesBatchSize は ingest が 1 回の time_series 呼び出しにまとめる銘柄数です（INGEST_BATCH_SIZE。1 なら銘柄ごと）。
+	CandlesBatchSize int
+	// CandlesConcurrency は ingest が段の中で並行して取り込むワーカー数です（INGEST_CONCURRENCY。既定は 3）。
+	CandlesConcurrency int
+	// Anomaly は ingest での終値急変（株式分割・誤データ）の検出設定です（ANOMALY_THRESHOLD / ANOMALY_QUARANTINE）。
+	Anomaly candles.AnomalyConfig
+	// MarketHealth は市場データ連携の状態判定のウィンドウと閾値です（MARKET_HEALTH_*）。
+	MarketHealth candles.MarketHealthConfig
+	// CandlesSourcePrecedence は保存済みの足を上書きする取り込み元の優先順位です（CANDLES_SOURCE_PRECEDENCE。先頭ほど高い）。
+	CandlesSourcePrecedence candles.SourcePrecedence
+	// PasswordPepper は seed ジョブがデモユーザーを作成する際に使う PASSWORD_PEPPER です（seed 以外では未使用）。
+	PasswordPepper string
+	// Production は APP_ENV=production かどうかです。seed ジョブは本番環境での実行を拒否します。
+	Production bool
 }
 
 // LoadAPI は API サーバー用の設定を読み込み検証します。
-// 必須項目（JWT_SECRET / PASSWORD_PEPPER）の欠落や OAuth 設定の不整合があれば
-// エラーを返します。DB の検証は接続時（db.OpenSQL）に行うため、ここでは行いません。
+// 必須項目（JWT_SECRET / PASSWORD_PEPPER）の欠落、解釈できない値、OAuth 設定の不整合があれば
+// 全てをまとめたエラーを返します。DB の検証は接続時（db.OpenSQL）に行うため、ここでは行いません。
 func LoadAPI() (*Config, error) {
-	cfg := &Config{}
-	cfg.Log = readLog(&cfg.Warnings)
-	cfg.DB = readDB()
-	cfg.Redis = readRedis()
-
-	server, err := readServer(&cfg.Warnings)
-	if err != nil {
-		return cfg, err
-	}
-	cfg.Server = server
-
-	oauth, err := readOAuth()
-	if err != nil {
-		return cfg, err
-	}
-	cfg.OAuth = oauth
+	return loadAPI(env.OS(), os.Environ())
+}
 
-	return cfg, nil
+// loadAPI は r から API サーバー用の設定を読み込みます。environ は FLAG_* / LOG_LEVEL_* の走査に使います。
+func loadAPI(r *env.Reader, environ []string) (*Config, error) {
+	cfg := &Config{}
+	cfg.Log = readLog(r, environ, &cfg.Warnings)
+	cfg.DB = readDB(r)
+	cfg.Redis = readRedis(r)
+	cfg.Flags = ParseFlagOverrides(environ, &cfg.Warnings)
+	cfg.FX = readFX(r)
+	cfg.OutputSize = readOutputSize(r)
+	cfg.Server = readServer(r)
+	cfg.Push = readPush(r)
+	cfg.OAuth = readOAuth(r)
+	return cfg, r.Err()
 }
 
 // LoadBatch はバッチ実行用の設定を読み込みます。
-// バッチには必須の環境変数がないため、現状エラーは返しません（将来の拡張のため戻り値は維持）。
+// Redis キーの名前空間が不正（本番で空を含む）な場合、TWELVE_DATA_PLAN が未知のプラン名の場合、
+// 解釈できない値がある場合は全てをまとめたエラーを返します。
 func LoadBatch() (*Config, error) {
+	return loadBatch(env.OS(), os.Environ())
+}
+
+// loadBatch は r からバッチ実行用の設定を読み込みます。
+func loadBatch(r *env.Reader, environ []string) (*Config, error) {
 	cfg := &Config{}
-	cfg.Log = readLog(&cfg.Warnings)
-	cfg.DB = readDB()
-	cfg.Redis = readRedis()
-	cfg.TwelveData = readTwelveData()
-	cfg.Batch = readBatch(&cfg.Warnings)
-	return cfg, nil
+	cfg.Log = readLog(r, environ, &cfg.Warnings)
+	cfg.DB = readDB(r)
+	cfg.Redis = readRedis(r)
+	cfg.Flags = ParseFlagOverrides(environ, &cfg.Warnings)
+	cfg.TwelveData = readTwelveData(r)
+	cfg.Upstream = readUpstream(r)
+	cfg.FX = readFX(r)
+	cfg.OutputSize = readOutputSize(r)
+	cfg.Batch = readBatch(r)
+	return cfg, r.Err()
 }
 
 // LoadMigrate はマイグレーション実行用の設定を読み込みます。
 func LoadMigrate() (*Config, error) {
+	r := env.OS()
 	cfg := &Config{}
-	cfg.Log = readLog(&cfg.Warnings)
-	cfg.DB = readDB()
-	return cfg, nil
+	cfg.Log = readLog(r, os.Environ(), &cfg.Warnings)
+	cfg.DB = readDB(r)
+	return cfg, r.Err()
 }
 
-// readLog は LOG_LEVEL / LOG_FORMAT / APP_ENV からロガー設定を組み立てます。
-func readLog(warn *[]string) LogConfig {
+// readLog は LOG_LEVEL / LOG_LEVEL_<COMPONENT> / LOG_FORMAT / LOG_SAMPLE_EVERY / APP_ENV からロガー設定を組み立てます。
+// 不正値を報告するロガー自体を構成するため、LOG_LEVEL / LOG_FORMAT の不正値はエラーにせず警告を蓄積します。
+func readLog(r *env.Reader, environ []string, warn *[]string) LogConfig {
 	level := slog.LevelInfo
-	if os.Getenv("LOG_LEVEL") == "DEBUG" {
-		level = slog.LevelDebug
+	if raw := r.String("LOG_LEVEL", ""); raw != "" {
+		if l, ok := ParseLogLevel(raw); ok {
+			level = l
+		} else {
+			*warn = append(*warn, fmt.Sprintf("invalid LOG_LEVEL value %q, using INFO", raw))
+		}
 	}
-	rawFormat := os.Getenv("LOG_FORMAT")
-	useJSON, ok := ParseLogFormat(rawFormat, os.Getenv("APP_ENV"))
+	rawFormat := r.String("LOG_FORMAT", "")
+	useJSON, ok := ParseLogFormat(rawFormat, r.String("APP_ENV", ""))
 	if !ok {
 		*warn = append(*warn, fmt.Sprintf("invalid LOG_FORMAT value %q, using default", rawFormat))
 	}
-	return LogConfig{Level: level, UseJSON: useJSON}
+	return LogConfig{
+		Level:           level,
+		UseJSON:         useJSON,
+		ComponentLevels: ParseComponentLevels(environ, warn),
+		SampleEvery:     positiveInt(r, "LOG_SAMPLE_EVERY", 1),
+	}
+}
+
+// ParseLogLevel は DEBUG / INFO / WARN / ERROR（大文字小文字は区別しない）を slog.Level に変換します。
+func ParseLogLevel(raw string) (slog.Level, bool) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(strings.TrimSpace(raw))); err != nil {
+		return slog.LevelInfo, false
+	}
+	return l, true
+}
+
+// logLevelEnvPrefix はコンポーネントごとのログレベル上書きの環境変数接頭辞です（例: LOG_LEVEL_CACHE=WARN）。
+const logLevelEnvPrefix = "LOG_LEVEL_"
+
+// ParseComponentLevels は "KEY=VALUE" 形式の環境変数一覧から LOG_LEVEL_<COMPONENT> を抽出し、
+// コンポーネント名（小文字）→ レベルのマップを返します。解釈できない値は警告を蓄積して無視します。
+func ParseComponentLevels(environ []string, warn *[]string) map[string]slog.Level {
+	out := map[string]slog.Level{}
+	for _, kv := range environ {
+		key, raw, _ := strings.Cut(kv, "=")
+		name, ok := strings.CutPrefix(key, logLevelEnvPrefix)
+		if !ok || name == "" {
+			continue
+		}
+		l, ok := ParseLogLevel(raw)
+		if !ok {
+			*warn = append(*warn, fmt.Sprintf("invalid %s=%q, ignoring log level override", key, raw))
+			continue
+		}
+		out[strings.ToLower(name)] = l
+	}
+	return out
 }
 
 // readDB は DB_* 環境変数からデータベース設定を組み立てます。
 // 必須項目の検証は接続時（Config.Validate）に行います。
-func readDB() db.Config {
+func readDB(r *env.Reader) db.Config {
 	return db.Config{
-		User:         os.Getenv("DB_USER"),
-		Password:     db.Password(os.Getenv("DB_PASSWORD")),
-		Name:         os.Getenv("DB_NAME"),
-		Host:         os.Getenv("DB_HOST"),
-		Port:         os.Getenv("DB_PORT"),
-		InstanceName: os.Getenv("INSTANCE_CONNECTION_NAME"),
+		User:         r.String("DB_USER", ""),
+		Password:     db.Password(r.String("DB_PASSWORD", "")),
+		Name:         r.String("DB_NAME", ""),
+		Host:         r.String("DB_HOST", ""),
+		Port:         r.String("DB_PORT", ""),
+		InstanceName: r.String("INSTANCE_CONNECTION_NAME", ""),
 	}
 }
 
-// readRedis は REDIS_* 環境変数から Redis 接続設定を組み立てます。
-func readRedis() RedisConfig {
+// readRedis は REDIS_* / CACHE_NAMESPACE 環境変数から Redis 接続設定を組み立てます。
+// staging と production が同じ Redis を共有してもキーが衝突しないよう、
+// APP_ENV=production では名前空間が空のまま起動することを拒否します。
+func readRedis(r *env.Reader) RedisConfig {
+	rawNamespace, set := r.Lookup("CACHE_NAMESPACE")
+	appEnv := r.String("APP_ENV", "")
+	namespace := ResolveCacheNamespace(rawNamespace, set, appEnv)
+	if namespace == "" && appEnv == "production" {
+		r.Invalid("CACHE_NAMESPACE", errors.New("must not be empty when APP_ENV=production"))
+	}
+	keys, err := infraredis.NewKeyBuilder(namespace)
+	if err != nil {
+		r.Invalid("CACHE_NAMESPACE", err)
+	}
 	return RedisConfig{
-		Host:     os.Getenv("REDIS_HOST"),
-		Port:     os.Getenv("REDIS_PORT"),
-		Password: os.Getenv("REDIS_PASSWORD"),
+		Host:     r.String("REDIS_HOST", ""),
+		Port:     r.String("REDIS_PORT", ""),
+		Password: r.String("REDIS_PASSWORD", ""),
+		Keys:     keys,
+	}
+}
+
+// ParseFlagOverrides は "KEY=VALUE" 形式の環境変数一覧から FLAG_* を抽出し、
+// フラグ名（小文字）→ 値のマップを返します。真偽値として解釈できない値は警告を蓄積して無視します。
+// 未定義のフラグ名の扱いは flags.NewRegistry に委ねます。
+func ParseFlagOverrides(environ []string, warn *[]string) map[string]bool {
+	out := map[string]bool{}
+	for _, kv := range environ {
+		key, raw, _ := strings.Cut(kv, "=")
+		name, ok := flags.NameFromEnv(key)
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			*warn = append(*warn, fmt.Sprintf("invalid %s=%q, ignoring flag override", key, raw))
+			continue
+		}
+		out[name] = v
 	}
+	return out
 }
 
 // readTwelveData は TWELVE_DATA_* 環境変数から TwelveData クライアント設定を組み立てます。
-func readTwelveData() twelvedata.Config {
-	return twelvedata.NewConfig(
-		os.Getenv("TWELVE_DATA_API_KEY"),
-		os.Getenv("TWELVE_DATA_BASE_URL"),
+// 取得制約は TWELVE_DATA_PLAN のプリセット（未設定なら basic）を基に、
+// TWELVE_DATA_MAX_OUTPUTSIZE / TWELVE_DATA_INTERVALS で個別に上書きできます。
+// レスポンスボディの読み込み上限は TWELVE_DATA_MAX_RESPONSE_BYTES（未設定なら 10MB）です。
+// TWELVE_DATA_RECORD_DIR を指定するとレスポンスをそのディレクトリに記録し、
+// TWELVE_DATA_RECORD_MAX_BYTES（未設定なら 256MB）・TWELVE_DATA_RECORD_MAX_AGE（未設定なら 7 日）を超えた古い記録を削除します。
+// キャッシュプロキシ経由で呼び出す場合は TWELVE_DATA_PROXY_AUTH_HEADER / TWELVE_DATA_PROXY_AUTH_VALUE（組で指定）と
+// TWELVE_DATA_API_KEY_IN_HEADER を使い、TWELVE_DATA_ALLOWED_HOSTS で環境ごとに TWELVE_DATA_BASE_URL のホストを制限します。
+func readTwelveData(r *env.Reader) twelvedata.Config {
+	cfg := twelvedata.NewConfig(
+		r.String("TWELVE_DATA_API_KEY", ""),
+		r.String("TWELVE_DATA_BASE_URL", ""),
 	)
+	if plan := r.String("TWELVE_DATA_PLAN", ""); plan != "" {
+		caps, err := twelvedata.CapabilitiesForPlan(plan)
+		if err != nil {
+			r.Invalid("TWELVE_DATA_PLAN", err)
+		} else {
+			cfg.Capabilities = caps
+		}
+	}
+	cfg.Capabilities.MaxOutputSize = positiveInt(r, "TWELVE_DATA_MAX_OUTPUTSIZE", cfg.Capabilities.MaxOutputSize)
+	cfg.MaxResponseBytes = int64(positiveInt(r, "TWELVE_DATA_MAX_RESPONSE_BYTES", int(cfg.MaxResponseBytes)))
+	cfg.Capabilities.Intervals = r.StringSlice("TWELVE_DATA_INTERVALS", cfg.Capabilities.Intervals)
+	cfg.Recording = twelvedata.RecordingConfig{
+		Dir:      r.String("TWELVE_DATA_RECORD_DIR", ""),
+		MaxBytes: int64(positiveInt(r, "TWELVE_DATA_RECORD_MAX_BYTES", int(twelvedata.DefaultRecordingMaxBytes))),
+		MaxAge:   positiveDuration(r, "TWELVE_DATA_RECORD_MAX_AGE", twelvedata.DefaultRecordingMaxAge),
+	}
+	cfg.ProxyAuthHeader = r.String("TWELVE_DATA_PROXY_AUTH_HEADER", "")
+	cfg.ProxyAuthValue = r.String("TWELVE_DATA_PROXY_AUTH_VALUE", "")
+	switch {
+	case cfg.ProxyAuthHeader != "" && cfg.ProxyAuthValue == "":
+		r.Invalid("TWELVE_DATA_PROXY_AUTH_VALUE", errors.New("must be set with TWELVE_DATA_PROXY_AUTH_HEADER"))
+	case cfg.ProxyAuthHeader == "" && cfg.ProxyAuthValue != "":
+		r.Invalid("TWELVE_DATA_PROXY_AUTH_HEADER", errors.New("must be set with TWELVE_DATA_PROXY_AUTH_VALUE"))
+	case strings.ContainsAny(cfg.ProxyAuthHeader, " :\r\n"):
+		r.Invalid("TWELVE_DATA_PROXY_AUTH_HEADER", errors.New("invalid header name"))
+	}
+	cfg.APIKeyInHeader = r.Bool("TWELVE_DATA_API_KEY_IN_HEADER", false)
+	cfg.AllowedHosts = r.StringSlice("TWELVE_DATA_ALLOWED_HOSTS", nil)
+	if err := cfg.ValidateBaseURL(); err != nil {
+		r.Invalid("TWELVE_DATA_BASE_URL", err)
+	}
+	return cfg
 }
 
-// readServer は API サーバー固有の環境変数を読み込み検証します。
-func readServer(warn *[]string) (ServerConfig, error) {
-	jwtSecret := os.Getenv(jwt.EnvKeyJWTSecret)
-	if jwtSecret == "" {
-		return ServerConfig{}, fmt.Errorf("%s is required", jwt.EnvKeyJWTSecret)
+// readUpstream は外部APIクライアントの接続プール・プロキシ設定を読み込みます。
+// UPSTREAM_PROXY_URL はスキームとホストを必須とします（意図しない直結を避けるため）。
+func readUpstream(r *env.Reader) httpclient.Config {
+	cfg := httpclient.Config{
+		MaxIdleConnsPerHost: positiveInt(r, "UPSTREAM_MAX_IDLE_CONNS_PER_HOST", httpclient.DefaultMaxIdleConnsPerHost),
+		IdleConnTimeout:     positiveDuration(r, "UPSTREAM_IDLE_CONN_TIMEOUT", httpclient.DefaultIdleConnTimeout),
 	}
+	if raw := r.String("UPSTREAM_PROXY_URL", ""); raw != "" {
+		u, err := url.Parse(raw)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			r.Invalid("UPSTREAM_PROXY_URL", errors.New("invalid proxy URL"))
+		} else {
+			cfg.Proxy = u
+		}
+	}
+	return cfg
+}
 
-	passwordPepper := os.Getenv(auth.EnvKeyPasswordPepper)
-	if passwordPepper == "" {
-		return ServerConfig{}, fmt.Errorf("%s is required", auth.EnvKeyPasswordPepper)
+// readFX は FX_CURRENCIES / FX_STATIC_RATES 環境変数から通貨換算の設定を組み立てます。
+func readFX(r *env.Reader) FXConfig {
+	currencies := defaultFXCurrencies
+	if raw := r.StringSlice("FX_CURRENCIES", nil); raw != nil {
+		currencies = make([]string, 0, len(raw))
+		for _, c := range raw {
+			n, err := rates.NormalizeCurrency(c)
+			if err != nil {
+				r.Invalid("FX_CURRENCIES", err)
+				continue
+			}
+			currencies = append(currencies, n)
+		}
 	}
+	static, err := rates.ParseStaticRates(r.String("FX_STATIC_RATES", ""))
+	if err != nil {
+		r.Invalid("FX_STATIC_RATES", err)
+	}
+	return FXConfig{Currencies: currencies, StaticRates: static}
+}
 
-	// COOKIE_SECURE を優先し、未設定なら APP_ENV=production をフォールバックとして使用
-	cookieSecureRaw := os.Getenv("COOKIE_SECURE")
-	defaultSecure := os.Getenv("APP_ENV") == "production"
-	secureCookie, ok := ParseBoolString(cookieSecureRaw, defaultSecure)
-	if !ok {
-		*warn = append(*warn, fmt.Sprintf("invalid COOKIE_SECURE value %q, falling back to default %v", cookieSecureRaw, secureCookie))
+// readServer は API サーバー固有の環境変数を読み込み検証します。
+func readServer(r *env.Reader) ServerConfig {
+	jwtSecret := r.Require(jwt.EnvKeyJWTSecret)
+	passwordPepper := r.Require(auth.EnvKeyPasswordPepper)
+
+	// サーバー間連携用の静的APIキー（ハッシュのみ保持）
+	apiKeys, err := apikey.ParseKeys(r.String("API_KEYS", ""))
+	if err != nil {
+		r.Invalid("API_KEYS", err)
 	}
 
-	// CORS許可オリジン（デフォルト: http://localhost:3000）
-	corsOrigins := ParseCORSOrigins(os.Getenv("CORS_ALLOWED_ORIGINS"))
-	if corsOrigins == nil {
-		corsOrigins = []string{defaultCORSOrigin}
+	impersonationAllow, err := jwt.ParseWriteAllowlist(r.StringSlice(jwt.EnvKeyImpersonationWriteAllowlist, nil))
+	if err != nil {
+		r.Invalid(jwt.EnvKeyImpersonationWriteAllowlist, err)
+	}
+	deprecatedRoutes, err := deprecation.ParseRules(r.StringSlice(deprecation.EnvKeyRoutes, nil))
+	if err != nil {
+		r.Invalid(deprecation.EnvKeyRoutes, err)
+	}
+	// 書式はなりすましの許可リストと同じ（"<METHOD> <ルートのパターン>"）
+	maintenanceAllow, err := jwt.ParseWriteAllowlist(r.StringSlice(maintenance.EnvKeyWriteAllowlist, maintenance.DefaultWriteAllowlist))
+	if err != nil {
+		r.Invalid(maintenance.EnvKeyWriteAllowlist, err)
 	}
 
 	return ServerConfig{
 		JWTSecret:      jwtSecret,
+		JWTExpiration:  positiveDuration(r, jwt.EnvKeyJWTExpiration, jwt.DefaultExpiration),
 		PasswordPepper: passwordPepper,
-		SecureCookie:   secureCookie,
-		CORSOrigins:    corsOrigins,
-		GCPProjectID:   os.Getenv("GOOGLE_CLOUD_PROJECT"),
-	}, nil
+		// COOKIE_SECURE を優先し、未設定なら APP_ENV=production をフォールバックとして使用
+		SecureCookie: r.Bool("COOKIE_SECURE", r.String("APP_ENV", "") == "production"),
+		CORSOrigins:  r.StringSlice("CORS_ALLOWED_ORIGINS", []string{defaultCORSOrigin}),
+		GCPProjectID: r.String("GOOGLE_CLOUD_PROJECT", ""),
+		APIKeys: apikey.Config{
+			Keys:   apiKeys,
+			Limit:  positiveInt(r, "API_KEY_RATE_LIMIT_PER_MINUTE", defaultAPIKeyRateLimit),
+			Window: time.Minute,
+		},
+		ExportDir:            r.String("EXPORT_DIR", filepath.Join(os.TempDir(), defaultExportDirName)),
+		CandlesRefreshAhead:  readRefreshAhead(r),
+		CandlesQueryTimeout:  positiveDuration(r, "CANDLES_QUERY_TIMEOUT", candles.DefaultQueryTimeout),
+		CandlesCacheBuckets:  readCacheBuckets(r),
+		CandlesLocalCache:    readLocalCache(r),
+		CandlesDebugHeaders:  r.Bool("CANDLES_DEBUG_HEADERS", false),
+		SymbolsActiveCodeTTL: positiveDuration(r, "SYMBOLS_ACTIVE_CODE_TTL", symbollist.DefaultActiveCodeTTL),
+		ServerHeader:         r.Bool("SERVER_HEADER", true),
+		RequireIfMatch:       r.Bool("REQUIRE_IF_MATCH", false),
+		SchemaStrict:         r.Bool("SCHEMA_STRICT", false),
+		LogoQuota:            readLogoQuota(r),
+
+		ImpersonationWriteAllowlist: impersonationAllow,
+		DeprecatedRoutes:            deprecatedRoutes,
+		MaintenanceWriteAllowlist:   maintenanceAllow,
+		MaintenanceRetryAfter:       positiveDuration(r, maintenance.EnvKeyRetryAfter, maintenance.DefaultRetryAfter),
+	}
 }
 
-// readOAuth は OAuth 関連の環境変数を検証します。
-// GOOGLE_CLIENT_ID / GITHUB_CLIENT_ID のいずれも未設定なら OAuth 無効として nil を返します。
-func readOAuth() (*di.OAuthConfig, error) {
-	googleClientID := os.Getenv("GOOGLE_CLIENT_ID")
-	githubClientID := os.Getenv("GITHUB_CLIENT_ID")
-	if googleClientID == "" && githubClientID == "" {
-		return nil, nil
+// readLogoQuota はロゴ検出・企業分析の 1 日あたりの上限と、上限を適用しないユーザー（カンマ区切りの ID）を読み込みます。
+func readLogoQuota(r *env.Reader) logodetection.QuotaConfig {
+	exempt, err := logodetection.ParseUserIDs(r.String("LOGO_QUOTA_EXEMPT_USER_IDS", ""))
+	if err != nil {
+		r.Invalid("LOGO_QUOTA_EXEMPT_USER_IDS", err)
+	}
+	return logodetection.QuotaConfig{
+		DetectDailyLimit:  positiveInt(r, "LOGO_QUOTA_DETECT_DAILY", logodetection.DefaultDetectDailyLimit),
+		AnalyzeDailyLimit: positiveInt(r, "LOGO_QUOTA_ANALYZE_DAILY", logodetection.DefaultAnalyzeDailyLimit),
+		ExemptUserIDs:     exempt,
+	}
+}
+
+// readPush はプッシュ通知の環境変数を読み込みます。鍵のファイルが読めない場合は起動時に気付けるようエラーを蓄積します。
+func readPush(r *env.Reader) PushConfig {
+	file := r.String("PUSH_FCM_CREDENTIALS_FILE", "")
+	if file != "" {
+		if _, err := os.Stat(file); err != nil {
+			r.Invalid("PUSH_FCM_CREDENTIALS_FILE", err)
+		}
+	}
+	return PushConfig{
+		FCMCredentialsFile: file,
+		Dispatcher: push.DispatcherConfig{
+			Workers:     positiveInt(r, "PUSH_WORKERS", push.DefaultWorkers),
+			MaxAttempts: positiveInt(r, "PUSH_MAX_ATTEMPTS", push.DefaultMaxAttempts),
+			BaseBackoff: positiveDuration(r, "PUSH_BASE_BACKOFF", push.DefaultBaseBackoff),
+			MaxBackoff:  positiveDuration(r, "PUSH_MAX_BACKOFF", push.DefaultMaxBackoff),
+		},
 	}
+}
 
-	frontendURL := os.Getenv("OAUTH_FRONTEND_REDIRECT_URL")
-	if frontendURL == "" {
-		return nil, fmt.Errorf("OAUTH_FRONTEND_REDIRECT_URL is required when OAuth is enabled")
+// readRefreshAhead はローソク足キャッシュの先行再取得の閾値（0〜1 未満の割合。0 で無効）と同時実行数の上限を読み込みます。
+func readRefreshAhead(r *env.Reader) candles.RefreshAheadConfig {
+	cfg := candles.RefreshAheadConfig{
+		MaxConcurrent: positiveInt(r, "CANDLES_REFRESH_AHEAD_CONCURRENCY", candles.DefaultRefreshAheadConcurrency),
+	}
+	if v := r.Float("CANDLES_REFRESH_AHEAD_THRESHOLD", 0); v >= 0 && v < 1 {
+		cfg.Threshold = v
+	} else {
+		r.Invalid("CANDLES_REFRESH_AHEAD_THRESHOLD", errors.New("must be in [0, 1)"))
 	}
+	return cfg
+}
 
-	cfg := &di.OAuthConfig{FrontendURL: frontendURL}
+// readOAuth は OAuth 関連の環境変数を検証します。
+// GOOGLE_CLIENT_ID / GITHUB_CLIENT_ID のいずれも未設定なら OAuth 無効として nil を返します。
+func readOAuth(r *env.Reader) *di.OAuthConfig {
+	googleClientID := r.String("GOOGLE_CLIENT_ID", "")
+	githubClientID := r.String("GITHUB_CLIENT_ID", "")
+	if googleClientID == "" && githubClientID == "" {
+		return nil
+	}
 
+	cfg := &di.OAuthConfig{FrontendURL: requireWhen(r, "OAUTH_FRONTEND_REDIRECT_URL", "OAuth is enabled")}
 	if googleClientID != "" {
-		secret := os.Getenv("GOOGLE_CLIENT_SECRET")
-		if secret == "" {
-			return nil, fmt.Errorf("GOOGLE_CLIENT_SECRET is required when GOOGLE_CLIENT_ID is set")
+		cfg.Google = &di.ProviderCredentials{
+			ClientID:     googleClientID,
+			ClientSecret: requireWhen(r, "GOOGLE_CLIENT_SECRET", "GOOGLE_CLIENT_ID is set"),
+			RedirectURL:  requireWhen(r, "GOOGLE_REDIRECT_URL", "GOOGLE_CLIENT_ID is set"),
 		}
-		redirectURL := os.Getenv("GOOGLE_REDIRECT_URL")
-		if redirectURL == "" {
-			return nil, fmt.Errorf("GOOGLE_REDIRECT_URL is required when GOOGLE_CLIENT_ID is set")
-		}
-		cfg.Google = &di.ProviderCredentials{ClientID: googleClientID, ClientSecret: secret, RedirectURL: redirectURL}
 	}
-
 	if githubClientID != "" {
-		secret := os.Getenv("GITHUB_CLIENT_SECRET")
-		if secret == "" {
-			return nil, fmt.Errorf("GITHUB_CLIENT_SECRET is required when GITHUB_CLIENT_ID is set")
+		cfg.GitHub = &di.ProviderCredentials{
+			ClientID:     githubClientID,
+			ClientSecret: requireWhen(r, "GITHUB_CLIENT_SECRET", "GITHUB_CLIENT_ID is set"),
+			RedirectURL:  requireWhen(r, "GITHUB_REDIRECT_URL", "GITHUB_CLIENT_ID is set"),
 		}
-		redirectURL := os.Getenv("GITHUB_REDIRECT_URL")
-		if redirectURL == "" {
-			return nil, fmt.Errorf("GITHUB_REDIRECT_URL is required when GITHUB_CLIENT_ID is set")
-		}
-		cfg.GitHub = &di.ProviderCredentials{ClientID: githubClientID, ClientSecret: secret, RedirectURL: redirectURL}
 	}
-
-	return cfg, nil
+	return cfg
 }
 
 // readBatch はバッチ実行のタイムアウト・失敗率しきい値を読み込みます。
-func readBatch(warn *[]string) BatchConfig {
+func readBatch(r *env.Reader) BatchConfig {
 	return BatchConfig{
-		CandlesTimeoutHours:   readTimeoutHours("INGEST_TIMEOUT_HOURS", defaultIngestTimeoutHours),
-		CandlesMaxFailureRate: readMaxFailureRate("INGEST_MAX_FAILURE_RATE", defaultMaxFailureRate, warn),
-		LogoTimeoutHours:      readTimeoutHours("LOGO_INGEST_TIMEOUT_HOURS", defaultIngestTimeoutHours),
-		LogoMaxFailureRate:    readMaxFailureRate("LOGO_INGEST_MAX_FAILURE_RATE", defaultMaxFailureRate, warn),
+		CandlesTimeoutHours:     positiveInt(r, "INGEST_TIMEOUT_HOURS", defaultIngestTimeoutHours),
+		CandlesMaxFailureRate:   readMaxFailureRate(r, "INGEST_MAX_FAILURE_RATE", defaultMaxFailureRate),
+		LogoTimeoutHours:        positiveInt(r, "LOGO_INGEST_TIMEOUT_HOURS", defaultIngestTimeoutHours),
+		LogoMaxFailureRate:      readMaxFailureRate(r, "LOGO_INGEST_MAX_FAILURE_RATE", defaultMaxFailureRate),
+		EventsTimeoutHours:      positiveInt(r, "EVENTS_INGEST_TIMEOUT_HOURS", defaultIngestTimeoutHours),
+		EventsMaxFailureRate:    readMaxFailureRate(r, "EVENTS_INGEST_MAX_FAILURE_RATE", defaultMaxFailureRate),
+		DigestMaxPerMinute:      positiveInt(r, "DIGEST_MAX_PER_MINUTE", defaultDigestMaxPerMinute),
+		CandlesTierBudgets:      readTierBudgets(r),
+		CandlesBatchSize:        readIngestBatchSize(r),
+		CandlesConcurrency:      readIngestConcurrency(r),
+		Anomaly:                 readAnomaly(r),
+		CandlesSourcePrecedence: readSourcePrecedence(r),
+		MarketHealth:            readMarketHealth(r),
+		PasswordPepper:          r.String(auth.EnvKeyPasswordPepper, ""),
+		Production:              r.String("APP_ENV", "") == "production",
 	}
 }
 
-// readTimeoutHours は env のタイムアウト時間（正の整数）を読み取ります。未設定・不正時は def を返します。
-func readTimeoutHours(key string, def int) int {
-	if v := os.Getenv(key); v != "" {
-		if n, err := strconv.Atoi(v); err == nil && n > 0 {
-			return n
-		}
+// readIngestBatchSize は INGEST_BATCH_SIZE（1 以上 candles.MaxIngestBatchSize 以下。未設定なら 1 = 銘柄ごと）を読み込みます。
+func readIngestBatchSize(r *env.Reader) int {
+	n := positiveInt(r, "INGEST_BATCH_SIZE", 1)
+	if n > candles.MaxIngestBatchSize {
+		r.Invalid("INGEST_BATCH_SIZE", fmt.Errorf("must be in [1, %d]", candles.MaxIngestBatchSize))
+		return 1
 	}
-	return def
+	return n
 }
 
-// readMaxFailureRate は env の失敗率しきい値（[0,1]）を読み取ります。不正時は警告を蓄積して def を返します。
-func readMaxFailureRate(key string, def float64, warn *[]string) float64 {
-	if v := os.Getenv(key); v != "" {
-		if r, err := strconv.ParseFloat(v, 64); err == nil && r >= 0 && r <= 1 {
-			return r
-		}
-		*warn = append(*warn, fmt.Sprintf("invalid max failure rate for %s=%q, using default %v", key, v, def))
+// readIngestConcurrency は INGEST_CONCURRENCY（1 以上 candles.MaxIngestConcurrency 以下。1 なら順に取り込む）を読み込みます。
+func readIngestConcurrency(r *env.Reader) int {
+	n := positiveInt(r, "INGEST_CONCURRENCY", defaultIngestConcurrency)
+	if n > candles.MaxIngestConcurrency {
+		r.Invalid("INGEST_CONCURRENCY", fmt.Errorf("must be in [1, %d]", candles.MaxIngestConcurrency))
+		return defaultIngestConcurrency
 	}
-	return def
+	return n
 }
 
-// ParseCORSOrigins は CORS_ALLOWED_ORIGINS env の生文字列を、カンマ区切りで
-// trim して空要素を除いたスライスに変換する。raw が空なら nil を返し、
-// 呼び出し側にデフォルト適用を委ねる。
-func ParseCORSOrigins(raw string) []string {
-	if raw == "" {
+// readTierBudgets は INGEST_TIER_BUDGETS（例: "2=30m,3=45m"）を読み込みます。未設定なら予算なし（nil）です。
+func readTierBudgets(r *env.Reader) map[int]time.Duration {
+	budgets, err := candles.ParseTierBudgets(r.String("INGEST_TIER_BUDGETS", ""))
+	if err != nil {
+		r.Invalid("INGEST_TIER_BUDGETS", err)
 		return nil
 	}
-	parts := strings.Split(raw, ",")
-	origins := make([]string, 0, len(parts))
-	for _, p := range parts {
-		if trimmed := strings.TrimSpace(p); trimmed != "" {
-			origins = append(origins, trimmed)
-		}
+	return budgets
+}
+
+// readSourcePrecedence は CANDLES_SOURCE_PRECEDENCE（例: "twelvedata,csv,derived"）を読み込みます。
+// 未設定なら candles.DefaultSourcePrecedence です。
+func readSourcePrecedence(r *env.Reader) candles.SourcePrecedence {
+	p, err := candles.ParseSourcePrecedence(r.String("CANDLES_SOURCE_PRECEDENCE", ""))
+	if err != nil {
+		r.Invalid("CANDLES_SOURCE_PRECEDENCE", err)
+		return candles.DefaultSourcePrecedence()
 	}
-	if len(origins) == 0 {
+	return p
+}
+
+// readCacheBuckets は CANDLES_CACHE_BUCKETS（例: "50,100,200,500,1000,5000"）を読み込みます。未設定なら区切りなしです。
+func readCacheBuckets(r *env.Reader) []int {
+	buckets, err := candles.ParseCacheBuckets(r.String("CANDLES_CACHE_BUCKETS", ""))
+	if err != nil {
+		r.Invalid("CANDLES_CACHE_BUCKETS", err)
 		return nil
 	}
-	return origins
+	return buckets
 }
 
-// ParseBoolString は raw を bool として解釈する。
-//   - raw が空文字の場合は (fallback, true) を返す（未設定は正常系扱い）。
-//   - strconv.ParseBool で解釈できる場合は (parsed, true) を返す。
-//   - 不正値の場合は (fallback, false) を返す。呼び出し側で警告ログなどの判断に利用する。
-//
-// env を直接読まず純粋な文字列を受け取るため、呼び出し側は os.Getenv 等で取得した値を渡す。
-func ParseBoolString(raw string, fallback bool) (value bool, ok bool) {
-	if raw == "" {
-		return fallback, true
+// readLocalCache はローソク足のプロセス内キャッシュの TTL（0 以上 candles.MaxLocalCacheTTL 以下。0 で無効）と上限を読み込みます。
+func readLocalCache(r *env.Reader) candles.LocalCacheConfig {
+	cfg := candles.LocalCacheConfig{
+		Entries: positiveInt(r, "CANDLES_LOCAL_CACHE_ENTRIES", candles.DefaultLocalCacheEntries),
+		Bytes:   int64(positiveInt(r, "CANDLES_LOCAL_CACHE_BYTES", candles.DefaultLocalCacheBytes)),
 	}
-	parsed, err := strconv.ParseBool(raw)
+	if v := r.Duration("CANDLES_LOCAL_CACHE_TTL", 0); v >= 0 && v <= candles.MaxLocalCacheTTL {
+		cfg.TTL = v
+	} else {
+		r.Invalid("CANDLES_LOCAL_CACHE_TTL", fmt.Errorf("must be in [0, %s]", candles.MaxLocalCacheTTL))
+	}
+	return cfg
+}
+
+// readOutputSize は CANDLES_OUTPUTSIZE（例: "1day=200:5000,1week=156:1000"）を読み込みます。
+// 指定のない時間間隔は組み込みの値（candles.DefaultOutputSizePolicy）のままです。
+func readOutputSize(r *env.Reader) candles.OutputSizePolicy {
+	p, err := candles.ParseOutputSizePolicy(r.String("CANDLES_OUTPUTSIZE", ""))
 	if err != nil {
-		return fallback, false
+		r.Invalid("CANDLES_OUTPUTSIZE", err)
+		return candles.DefaultOutputSizePolicy()
+	}
+	return p
+}
+
+// readAnomaly は異常値検出のしきい値（正の変動率）と隔離モードを読み込みます。
+func readAnomaly(r *env.Reader) candles.AnomalyConfig {
+	cfg := candles.AnomalyConfig{
+		Threshold:  r.Float("ANOMALY_THRESHOLD", candles.DefaultAnomalyThreshold),
+		Quarantine: r.Bool("ANOMALY_QUARANTINE", false),
+	}
+	if cfg.Threshold <= 0 {
+		r.Invalid("ANOMALY_THRESHOLD", errMustBePositive)
+		cfg.Threshold = candles.DefaultAnomalyThreshold
+	}
+	return cfg
+}
+
+// readMarketHealth は市場データ連携の状態判定のウィンドウ・最小の呼び出し数と、degraded / down に入る・抜ける閾値を読み込みます。
+// 閾値は (0, 1] で、抜ける閾値は入る閾値以下である必要があります（不正なら既定値に戻してエラーを蓄積します）。
+func readMarketHealth(r *env.Reader) candles.MarketHealthConfig {
+	def := candles.DefaultMarketHealthConfig()
+	rate := func(key string, def float64) float64 {
+		v := r.Float(key, def)
+		if v <= 0 || v > 1 {
+			r.Invalid(key, errors.New("must be in (0, 1]"))
+			return def
+		}
+		return v
+	}
+	cfg := candles.MarketHealthConfig{
+		Window:        positiveDuration(r, "MARKET_HEALTH_WINDOW", def.Window),
+		MinSamples:    positiveInt(r, "MARKET_HEALTH_MIN_SAMPLES", def.MinSamples),
+		DegradedEnter: rate("MARKET_HEALTH_DEGRADED_ENTER", def.DegradedEnter),
+		DegradedExit:  rate("MARKET_HEALTH_DEGRADED_EXIT", def.DegradedExit),
+		DownEnter:     rate("MARKET_HEALTH_DOWN_ENTER", def.DownEnter),
+		DownExit:      rate("MARKET_HEALTH_DOWN_EXIT", def.DownExit),
 	}
-	return parsed, true
+	if cfg.DegradedExit > cfg.DegradedEnter {
+		r.Invalid("MARKET_HEALTH_DEGRADED_EXIT", errors.New("must not exceed MARKET_HEALTH_DEGRADED_ENTER"))
+		cfg.DegradedEnter, cfg.DegradedExit = def.DegradedEnter, def.DegradedExit
+	}
+	if cfg.DownExit > cfg.DownEnter {
+		r.Invalid("MARKET_HEALTH_DOWN_EXIT", errors.New("must not exceed MARKET_HEALTH_DOWN_ENTER"))
+		cfg.DownEnter, cfg.DownExit = def.DownEnter, def.DownExit
+	}
+	return cfg
+}
+
+// errMustBePositive は正の値であるべき設定に 0 以下が指定されたことを示します。
+var errMustBePositive = errors.New("must be positive")
+
+// positiveInt は env の正の整数を読み取ります。0 以下はエラーを蓄積して def を返します。
+func positiveInt(r *env.Reader, key string, def int) int {
+	n := r.Int(key, def)
+	if n <= 0 {
+		r.Invalid(key, errMustBePositive)
+		return def
+	}
+	return n
+}
+
+// positiveDuration は env の正の期間（time.ParseDuration 形式、例: "30m"）を読み取ります。
+// 0 以下はエラーを蓄積して def を返します。
+func positiveDuration(r *env.Reader, key string, def time.Duration) time.Duration {
+	d := r.Duration(key, def)
+	if d <= 0 {
+		r.Invalid(key, errMustBePositive)
+		return def
+	}
+	return d
+}
+
+// readMaxFailureRate は env の失敗率しきい値（[0,1]）を読み取ります。範囲外はエラーを蓄積して def を返します。
+func readMaxFailureRate(r *env.Reader, key string, def float64) float64 {
+	v := r.Float(key, def)
+	if v < 0 || v > 1 {
+		r.Invalid(key, errors.New("must be in [0, 1]"))
+		return def
+	}
+	return v
+}
+
+// requireWhen は cond のときに必須の値を読み取ります。未設定ならエラーを蓄積して空文字を返します。
+func requireWhen(r *env.Reader, key, cond string) string {
+	v := r.String(key, "")
+	if v == "" {
+		r.Invalid(key, fmt.Errorf("%w when %s", env.ErrRequired, cond))
+	}
+	return v
 }
 
 // ParseLogFormat はログ出力を JSON にするか Text にするかを決定する。
@@ -319,7 +771,7 @@ func ParseBoolString(raw string, fallback bool) (value bool, ok bool) {
 //   - 上記以外の不正値の場合は appEnv ベースの既定値 + ok=false を返す。
 //     呼び出し側で警告ログなどの判断に利用する。
 //
-// env を直接読まず純粋な文字列を受け取るため、呼び出し側は os.Getenv 等で取得した値を渡す。
+// env を直接読まず純粋な文字列を受け取るため、呼び出し側は env.Reader 等で取得した値を渡す。
 func ParseLogFormat(logFormatRaw, appEnv string) (useJSON bool, ok bool) {
 	defaultJSON := appEnv == "production"
 	switch strings.ToLower(strings.TrimSpace(logFormatRaw)) {
@@ -333,3 +785,16 @@ func ParseLogFormat(logFormatRaw, appEnv string) (useJSON bool, ok bool) {
 		return defaultJSON, false
 	}
 }
+
+// ResolveCacheNamespace は Redis キーの名前空間を決定する。
+//   - CACHE_NAMESPACE が設定されている場合は、前後空白を除いた値をそのまま使う。
+//     空文字を明示すると名前空間なし（従来どおりのキー）になる。
+//   - 未設定（set=false）の場合は appEnv（小文字化・前後空白除去）を使う。
+//
+// env を直接読まず純粋な文字列を受け取るため、呼び出し側は env.Reader.Lookup 等で取得した値を渡す。
+func ResolveCacheNamespace(raw string, set bool, appEnv string) string {
+	if set {
+		return strings.TrimSpace(raw)
+	}
+	return strings.ToLower(strings.TrimSpace(appEnv))
+}
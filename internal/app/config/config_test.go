@@ -1,156 +1,12 @@
 package config
 
 import (
-	"reflect"
+	"log/slog"
+	"maps"
+	"strings"
 	"testing"
 )
 
-// TestParseCORSOrigins は CORS_ALLOWED_ORIGINS env の生文字列パースが
-// trim・空要素除去・複数要素対応を正しく行うことを検証します。
-func TestParseCORSOrigins(t *testing.T) {
-	t.Parallel()
-
-	tests := []struct {
-		name string
-		raw  string
-		want []string
-	}{
-		{
-			name: "空文字の場合は nil を返す",
-			raw:  "",
-			want: nil,
-		},
-		{
-			name: "単一オリジン",
-			raw:  "http://localhost:3000",
-			want: []string{"http://localhost:3000"},
-		},
-		{
-			name: "カンマ区切り複数オリジン",
-			raw:  "http://localhost:3000,https://example.com",
-			want: []string{"http://localhost:3000", "https://example.com"},
-		},
-		{
-			name: "前後のスペースを trim する",
-			raw:  "  http://a.example.com  ,  http://b.example.com  ",
-			want: []string{"http://a.example.com", "http://b.example.com"},
-		},
-		{
-			name: "連続カンマによる空要素を除去する",
-			raw:  "a,,b",
-			want: []string{"a", "b"},
-		},
-		{
-			name: "空白のみの要素を除去する",
-			raw:  "a, ,b",
-			want: []string{"a", "b"},
-		},
-		{
-			name: "末尾カンマによる空要素を除去する",
-			raw:  "a,b,",
-			want: []string{"a", "b"},
-		},
-		{
-			name: "全要素が空白のみなら nil を返す",
-			raw:  " , , ",
-			want: nil,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Parallel()
-
-			got := ParseCORSOrigins(tt.raw)
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("ParseCORSOrigins(%q) = %v, want %v", tt.raw, got, tt.want)
-			}
-		})
-	}
-}
-
-// TestParseBoolString は raw の bool パースとフォールバック動作を検証します。
-func TestParseBoolString(t *testing.T) {
-	t.Parallel()
-
-	tests := []struct {
-		name      string
-		raw       string
-		fallback  bool
-		wantValue bool
-		wantOK    bool
-	}{
-		{
-			name:      "空文字・fallback=false は (false, true)",
-			raw:       "",
-			fallback:  false,
-			wantValue: false,
-			wantOK:    true,
-		},
-		{
-			name:      "空文字・fallback=true は (true, true)",
-			raw:       "",
-			fallback:  true,
-			wantValue: true,
-			wantOK:    true,
-		},
-		{
-			name:      "raw=true は (true, true)",
-			raw:       "true",
-			fallback:  false,
-			wantValue: true,
-			wantOK:    true,
-		},
-		{
-			name:      "raw=false は fallback=true でも (false, true)",
-			raw:       "false",
-			fallback:  true,
-			wantValue: false,
-			wantOK:    true,
-		},
-		{
-			name:      "raw=1 は (true, true)",
-			raw:       "1",
-			fallback:  false,
-			wantValue: true,
-			wantOK:    true,
-		},
-		{
-			name:      "raw=0 は (false, true)",
-			raw:       "0",
-			fallback:  true,
-			wantValue: false,
-			wantOK:    true,
-		},
-		{
-			name:      "不正値は (fallback, false) を返す",
-			raw:       "yes",
-			fallback:  true,
-			wantValue: true,
-			wantOK:    false,
-		},
-		{
-			name:      "不正値・fallback=false は (false, false)",
-			raw:       "invalid",
-			fallback:  false,
-			wantValue: false,
-			wantOK:    false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Parallel()
-
-			gotValue, gotOK := ParseBoolString(tt.raw, tt.fallback)
-			if gotValue != tt.wantValue || gotOK != tt.wantOK {
-				t.Errorf("ParseBoolString(%q, %v) = (%v, %v), want (%v, %v)",
-					tt.raw, tt.fallback, gotValue, gotOK, tt.wantValue, tt.wantOK)
-			}
-		})
-	}
-}
-
 // TestParseLogFormat は LOG_FORMAT 明示指定と APP_ENV フォールバックの組み合わせを検証します。
 func TestParseLogFormat(t *testing.T) {
 	t.Parallel()
@@ -225,3 +81,103 @@ func TestParseLogFormat(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveCacheNamespace(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		raw    string
+		set    bool
+		appEnv string
+		want   string
+	}{
+		{name: "未設定は APP_ENV", set: false, appEnv: "production", want: "production"},
+		{name: "未設定は APP_ENV を小文字化", set: false, appEnv: " Staging ", want: "staging"},
+		{name: "未設定かつ APP_ENV なしは空", set: false, appEnv: "", want: ""},
+		{name: "明示値が優先", raw: "prod-eu", set: true, appEnv: "production", want: "prod-eu"},
+		{name: "明示の空は名前空間なし", raw: "  ", set: true, appEnv: "production", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := ResolveCacheNamespace(tt.raw, tt.set, tt.appEnv); got != tt.want {
+				t.Errorf("ResolveCacheNamespace(%q, %v, %q) = %q, want %q", tt.raw, tt.set, tt.appEnv, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLogLevel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		raw    string
+		want   slog.Level
+		wantOK bool
+	}{
+		{raw: "DEBUG", want: slog.LevelDebug, wantOK: true},
+		{raw: "info", want: slog.LevelInfo, wantOK: true},
+		{raw: " Warn ", want: slog.LevelWarn, wantOK: true},
+		{raw: "ERROR", want: slog.LevelError, wantOK: true},
+		{raw: "verbose", want: slog.LevelInfo, wantOK: false},
+		{raw: "", want: slog.LevelInfo, wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			t.Parallel()
+			got, ok := ParseLogLevel(tt.raw)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("ParseLogLevel(%q) = (%v, %v), want (%v, %v)", tt.raw, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseComponentLevels(t *testing.T) {
+	t.Parallel()
+
+	var warnings []string
+	got := ParseComponentLevels([]string{
+		"LOG_LEVEL_CACHE=WARN",
+		"LOG_LEVEL_CANDLES=debug",
+		"LOG_LEVEL_AUTH=loud",
+		"LOG_LEVEL_=ERROR",
+		"LOG_LEVEL=DEBUG",
+		"DB_LOG_LEVEL=warn",
+	}, &warnings)
+
+	want := map[string]slog.Level{"cache": slog.LevelWarn, "candles": slog.LevelDebug}
+	if !maps.Equal(got, want) {
+		t.Errorf("ParseComponentLevels = %v, want %v", got, want)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "LOG_LEVEL_AUTH") {
+		t.Errorf("warnings = %v, want one warning for LOG_LEVEL_AUTH", warnings)
+	}
+}
+
+func TestParseFlagOverrides(t *testing.T) {
+	t.Parallel()
+
+	var warnings []string
+	got := ParseFlagOverrides([]string{
+		"FLAG_NEGATIVE_CACHE=true",
+		"FLAG_WRITE_THROUGH= 0 ",
+		"FLAG_FETCH_THROUGH=maybe",
+		"FLAG_=true",
+		"REDIS_HOST=redis",
+	}, &warnings)
+
+	want := map[string]bool{"negative_cache": true, "write_through": false}
+	if len(got) != len(want) {
+		t.Fatalf("ParseFlagOverrides = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("flag %s = %v, want %v", k, got[k], v)
+		}
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "FLAG_FETCH_THROUGH") {
+		t.Errorf("warnings = %v, want one warning for FLAG_FETCH_THROUGH", warnings)
+	}
+}
@@ -1,10 +1,27 @@
 package config
 
 import (
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/twelvedata"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/logodetection"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/push"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/env"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/apikey"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/deprecation"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/maintenance"
 )
 
 // clearServerEnv は設定検証に関わる環境変数をすべて空にし、テストを決定的にする。
@@ -12,6 +29,7 @@ func clearServerEnv(t *testing.T) {
 	t.Helper()
 	for _, k := range []string{
 		jwt.EnvKeyJWTSecret,
+		jwt.EnvKeyJWTExpiration,
 		auth.EnvKeyPasswordPepper,
 		"COOKIE_SECURE",
 		"APP_ENV",
@@ -23,9 +41,48 @@ func clearServerEnv(t *testing.T) {
 		"GITHUB_CLIENT_SECRET",
 		"GITHUB_REDIRECT_URL",
 		"OAUTH_FRONTEND_REDIRECT_URL",
+		"API_KEYS",
+		"API_KEY_RATE_LIMIT_PER_MINUTE",
+		"EXPORT_DIR",
+		"FX_CURRENCIES",
+		"FX_STATIC_RATES",
+		"CANDLES_REFRESH_AHEAD_THRESHOLD",
+		"CANDLES_REFRESH_AHEAD_CONCURRENCY",
+		"CANDLES_QUERY_TIMEOUT",
+		"CANDLES_CACHE_BUCKETS",
+		"CANDLES_LOCAL_CACHE_TTL",
+		"CANDLES_LOCAL_CACHE_ENTRIES",
+		"CANDLES_LOCAL_CACHE_BYTES",
+		"CANDLES_DEBUG_HEADERS",
+		"SYMBOLS_ACTIVE_CODE_TTL",
+		"SERVER_HEADER",
+		"REQUIRE_IF_MATCH",
+		"SCHEMA_STRICT",
+		deprecation.EnvKeyRoutes,
+		maintenance.EnvKeyWriteAllowlist,
+		maintenance.EnvKeyRetryAfter,
+		"LOGO_QUOTA_DETECT_DAILY",
+		"LOGO_QUOTA_ANALYZE_DAILY",
+		"LOGO_QUOTA_EXEMPT_USER_IDS",
+		"CANDLES_OUTPUTSIZE",
+		"PUSH_FCM_CREDENTIALS_FILE",
+		"PUSH_WORKERS",
+		"PUSH_MAX_ATTEMPTS",
+		"PUSH_BASE_BACKOFF",
+		"PUSH_MAX_BACKOFF",
 	} {
 		t.Setenv(k, "")
 	}
+	unsetEnv(t, "CACHE_NAMESPACE")
+}
+
+// unsetEnv は環境変数を未設定状態にする（空文字の設定とは区別される）。テスト終了時に元の値へ戻す。
+func unsetEnv(t *testing.T, key string) {
+	t.Helper()
+	t.Setenv(key, "") // 終了時の復元を登録する
+	if err := os.Unsetenv(key); err != nil {
+		t.Fatalf("unsetenv %s: %v", key, err)
+	}
 }
 
 func TestLoadAPI(t *testing.T) {
@@ -60,9 +117,276 @@ func TestLoadAPI(t *testing.T) {
 		if cfg.Server.SecureCookie {
 			t.Error("secureCookie should default to false without APP_ENV=production")
 		}
+		if !cfg.Server.ServerHeader {
+			t.Error("serverHeader should default to true")
+		}
+		if cfg.Server.SchemaStrict {
+			t.Error("schemaStrict should default to false")
+		}
 		if len(cfg.Server.CORSOrigins) != 1 || cfg.Server.CORSOrigins[0] != defaultCORSOrigin {
 			t.Errorf("corsOrigins should default to %s, got %v", defaultCORSOrigin, cfg.Server.CORSOrigins)
 		}
+		if want := filepath.Join(os.TempDir(), defaultExportDirName); cfg.Server.ExportDir != want {
+			t.Errorf("exportDir should default to %s, got %s", want, cfg.Server.ExportDir)
+		}
+	})
+
+	t.Run("EXPORT_DIR を指定するとその値を使用", func(t *testing.T) {
+		clearServerEnv(t)
+		t.Setenv(jwt.EnvKeyJWTSecret, "secret")
+		t.Setenv(auth.EnvKeyPasswordPepper, "pepper")
+		t.Setenv("EXPORT_DIR", "/var/lib/stock/export")
+
+		cfg, err := LoadAPI()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Server.ExportDir != "/var/lib/stock/export" {
+			t.Errorf("exportDir: got %s, want /var/lib/stock/export", cfg.Server.ExportDir)
+		}
+	})
+
+	t.Run("先行再取得は未設定なら無効、範囲外はエラー", func(t *testing.T) {
+		clearServerEnv(t)
+		t.Setenv(jwt.EnvKeyJWTSecret, "secret")
+		t.Setenv(auth.EnvKeyPasswordPepper, "pepper")
+
+		cfg, err := LoadAPI()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := (candles.RefreshAheadConfig{MaxConcurrent: candles.DefaultRefreshAheadConcurrency}); cfg.Server.CandlesRefreshAhead != want {
+			t.Errorf("refresh-ahead: got %+v, want %+v", cfg.Server.CandlesRefreshAhead, want)
+		}
+
+		t.Setenv("CANDLES_REFRESH_AHEAD_THRESHOLD", "0.1")
+		t.Setenv("CANDLES_REFRESH_AHEAD_CONCURRENCY", "8")
+		cfg, err = LoadAPI()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := (candles.RefreshAheadConfig{Threshold: 0.1, MaxConcurrent: 8}); cfg.Server.CandlesRefreshAhead != want {
+			t.Errorf("refresh-ahead: got %+v, want %+v", cfg.Server.CandlesRefreshAhead, want)
+		}
+
+		t.Setenv("CANDLES_REFRESH_AHEAD_THRESHOLD", "1.5")
+		if _, err := LoadAPI(); err == nil {
+			t.Error("expected error for out-of-range CANDLES_REFRESH_AHEAD_THRESHOLD, got nil")
+		}
+	})
+
+	t.Run("CANDLES_CACHE_BUCKETS 未設定は区切りなし、不正値はエラー", func(t *testing.T) {
+		clearServerEnv(t)
+		t.Setenv(jwt.EnvKeyJWTSecret, "secret")
+		t.Setenv(auth.EnvKeyPasswordPepper, "pepper")
+
+		cfg, err := LoadAPI()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Server.CandlesCacheBuckets != nil {
+			t.Errorf("cache buckets: got %v, want nil", cfg.Server.CandlesCacheBuckets)
+		}
+
+		t.Setenv("CANDLES_CACHE_BUCKETS", "200,50")
+		cfg, err = LoadAPI()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := []int{50, 200, candles.MaxOutputSize}; !slices.Equal(cfg.Server.CandlesCacheBuckets, want) {
+			t.Errorf("cache buckets: got %v, want %v", cfg.Server.CandlesCacheBuckets, want)
+		}
+
+		t.Setenv("CANDLES_CACHE_BUCKETS", "0")
+		if _, err := LoadAPI(); err == nil {
+			t.Error("expected error for invalid CANDLES_CACHE_BUCKETS, got nil")
+		}
+	})
+
+	t.Run("CANDLES_LOCAL_CACHE_TTL 未設定は無効、上限を超える値はエラー", func(t *testing.T) {
+		clearServerEnv(t)
+		t.Setenv(jwt.EnvKeyJWTSecret, "secret")
+		t.Setenv(auth.EnvKeyPasswordPepper, "pepper")
+
+		cfg, err := LoadAPI()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := candles.LocalCacheConfig{Entries: candles.DefaultLocalCacheEntries, Bytes: candles.DefaultLocalCacheBytes}
+		if cfg.Server.CandlesLocalCache != want {
+			t.Errorf("local cache: got %+v, want %+v", cfg.Server.CandlesLocalCache, want)
+		}
+
+		t.Setenv("CANDLES_LOCAL_CACHE_TTL", "10s")
+		t.Setenv("CANDLES_LOCAL_CACHE_ENTRIES", "64")
+		t.Setenv("CANDLES_LOCAL_CACHE_BYTES", "1048576")
+		cfg, err = LoadAPI()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := (candles.LocalCacheConfig{TTL: 10 * time.Second, Entries: 64, Bytes: 1 << 20}); cfg.Server.CandlesLocalCache != want {
+			t.Errorf("local cache: got %+v, want %+v", cfg.Server.CandlesLocalCache, want)
+		}
+
+		t.Setenv("CANDLES_LOCAL_CACHE_TTL", "1m")
+		if _, err := LoadAPI(); err == nil {
+			t.Error("expected error for CANDLES_LOCAL_CACHE_TTL above the limit, got nil")
+		}
+	})
+
+	t.Run("CANDLES_QUERY_TIMEOUT 未設定はデフォルト、不正値はエラー", func(t *testing.T) {
+		clearServerEnv(t)
+		t.Setenv(jwt.EnvKeyJWTSecret, "secret")
+		t.Setenv(auth.EnvKeyPasswordPepper, "pepper")
+
+		cfg, err := LoadAPI()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Server.CandlesQueryTimeout != candles.DefaultQueryTimeout {
+			t.Errorf("query timeout: got %v, want %v", cfg.Server.CandlesQueryTimeout, candles.DefaultQueryTimeout)
+		}
+
+		t.Setenv("CANDLES_QUERY_TIMEOUT", "1500ms")
+		cfg, err = LoadAPI()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Server.CandlesQueryTimeout != 1500*time.Millisecond {
+			t.Errorf("query timeout: got %v, want 1.5s", cfg.Server.CandlesQueryTimeout)
+		}
+
+		t.Setenv("CANDLES_QUERY_TIMEOUT", "-1s")
+		if _, err := LoadAPI(); err == nil {
+			t.Error("expected error for negative CANDLES_QUERY_TIMEOUT, got nil")
+		}
+	})
+
+	t.Run("SYMBOLS_ACTIVE_CODE_TTL 未設定はデフォルト、指定時はその値を使用", func(t *testing.T) {
+		clearServerEnv(t)
+		t.Setenv(jwt.EnvKeyJWTSecret, "secret")
+		t.Setenv(auth.EnvKeyPasswordPepper, "pepper")
+
+		cfg, err := LoadAPI()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Server.SymbolsActiveCodeTTL != symbollist.DefaultActiveCodeTTL {
+			t.Errorf("active code ttl: got %v, want %v", cfg.Server.SymbolsActiveCodeTTL, symbollist.DefaultActiveCodeTTL)
+		}
+
+		t.Setenv("SYMBOLS_ACTIVE_CODE_TTL", "15s")
+		cfg, err = LoadAPI()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Server.SymbolsActiveCodeTTL != 15*time.Second {
+			t.Errorf("active code ttl: got %v, want 15s", cfg.Server.SymbolsActiveCodeTTL)
+		}
+	})
+
+	t.Run("LOGO_QUOTA_* 未設定はデフォルト、不正な除外ユーザー ID はエラー", func(t *testing.T) {
+		clearServerEnv(t)
+		t.Setenv(jwt.EnvKeyJWTSecret, "secret")
+		t.Setenv(auth.EnvKeyPasswordPepper, "pepper")
+
+		cfg, err := LoadAPI()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := logodetection.QuotaConfig{
+			DetectDailyLimit:  logodetection.DefaultDetectDailyLimit,
+			AnalyzeDailyLimit: logodetection.DefaultAnalyzeDailyLimit,
+		}
+		if !reflect.DeepEqual(cfg.Server.LogoQuota, want) {
+			t.Errorf("logo quota: got %+v, want %+v", cfg.Server.LogoQuota, want)
+		}
+
+		t.Setenv("LOGO_QUOTA_DETECT_DAILY", "10")
+		t.Setenv("LOGO_QUOTA_ANALYZE_DAILY", "5")
+		t.Setenv("LOGO_QUOTA_EXEMPT_USER_IDS", "1, 42")
+		cfg, err = LoadAPI()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want = logodetection.QuotaConfig{DetectDailyLimit: 10, AnalyzeDailyLimit: 5, ExemptUserIDs: []int64{1, 42}}
+		if !reflect.DeepEqual(cfg.Server.LogoQuota, want) {
+			t.Errorf("logo quota: got %+v, want %+v", cfg.Server.LogoQuota, want)
+		}
+
+		t.Setenv("LOGO_QUOTA_EXEMPT_USER_IDS", "1,admin")
+		if _, err := LoadAPI(); err == nil || !strings.Contains(err.Error(), "LOGO_QUOTA_EXEMPT_USER_IDS") {
+			t.Errorf("expected LOGO_QUOTA_EXEMPT_USER_IDS error, got %v", err)
+		}
+	})
+
+	t.Run("PUSH_* 未設定はログ出力・デフォルト、鍵のファイルが存在しなければエラー", func(t *testing.T) {
+		clearServerEnv(t)
+		t.Setenv(jwt.EnvKeyJWTSecret, "secret")
+		t.Setenv(auth.EnvKeyPasswordPepper, "pepper")
+
+		cfg, err := LoadAPI()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Push.FCMCredentialsFile != "" || cfg.Push.Dispatcher.Workers != push.DefaultWorkers {
+			t.Errorf("push: got %+v, want defaults", cfg.Push)
+		}
+
+		keyFile := filepath.Join(t.TempDir(), "fcm.json")
+		if err := os.WriteFile(keyFile, []byte("{}"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("PUSH_FCM_CREDENTIALS_FILE", keyFile)
+		t.Setenv("PUSH_MAX_ATTEMPTS", "3")
+		cfg, err = LoadAPI()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Push.FCMCredentialsFile != keyFile || cfg.Push.Dispatcher.MaxAttempts != 3 {
+			t.Errorf("push: got %+v", cfg.Push)
+		}
+
+		t.Setenv("PUSH_FCM_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "missing.json"))
+		if _, err := LoadAPI(); err == nil {
+			t.Error("expected error for missing PUSH_FCM_CREDENTIALS_FILE, got nil")
+		}
+	})
+
+	t.Run("JWT_EXPIRATION 未設定はデフォルト、指定時はその値を使用", func(t *testing.T) {
+		clearServerEnv(t)
+		t.Setenv(jwt.EnvKeyJWTSecret, "secret")
+		t.Setenv(auth.EnvKeyPasswordPepper, "pepper")
+
+		cfg, err := LoadAPI()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Server.JWTExpiration != jwt.DefaultExpiration {
+			t.Errorf("jwtExpiration: got %v, want %v", cfg.Server.JWTExpiration, jwt.DefaultExpiration)
+		}
+
+		t.Setenv(jwt.EnvKeyJWTExpiration, "15m")
+		cfg, err = LoadAPI()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Server.JWTExpiration != 15*time.Minute {
+			t.Errorf("jwtExpiration: got %v, want 15m", cfg.Server.JWTExpiration)
+		}
+	})
+
+	t.Run("不正な JWT_EXPIRATION はエラー", func(t *testing.T) {
+		for _, v := range []string{"soon", "0s", "-1h"} {
+			clearServerEnv(t)
+			t.Setenv(jwt.EnvKeyJWTSecret, "secret")
+			t.Setenv(auth.EnvKeyPasswordPepper, "pepper")
+			t.Setenv(jwt.EnvKeyJWTExpiration, v)
+
+			if _, err := LoadAPI(); err == nil {
+				t.Errorf("%s: expected error, got nil", v)
+			}
+		}
 	})
 
 	t.Run("APP_ENV=production で secureCookie が true", func(t *testing.T) {
@@ -80,110 +404,592 @@ func TestLoadAPI(t *testing.T) {
 		}
 	})
 
-	t.Run("不正な COOKIE_SECURE は Warnings に記録しデフォルト動作", func(t *testing.T) {
+	t.Run("COOKIE_SECURE は APP_ENV より優先", func(t *testing.T) {
+		clearServerEnv(t)
+		t.Setenv(jwt.EnvKeyJWTSecret, "secret")
+		t.Setenv(auth.EnvKeyPasswordPepper, "pepper")
+		t.Setenv("APP_ENV", "production")
+		t.Setenv("CACHE_NAMESPACE", "prod")
+		t.Setenv("COOKIE_SECURE", "false")
+
+		cfg, err := LoadAPI()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Server.SecureCookie {
+			t.Error("secureCookie should be false when COOKIE_SECURE=false")
+		}
+	})
+
+	t.Run("不正な COOKIE_SECURE はエラー", func(t *testing.T) {
 		clearServerEnv(t)
 		t.Setenv(jwt.EnvKeyJWTSecret, "secret")
 		t.Setenv(auth.EnvKeyPasswordPepper, "pepper")
 		t.Setenv("COOKIE_SECURE", "notabool")
 
+		if _, err := LoadAPI(); err == nil {
+			t.Fatal("expected error for invalid COOKIE_SECURE, got nil")
+		}
+	})
+
+	t.Run("API_KEYS を読み込みキーごとのレート上限を適用", func(t *testing.T) {
+		clearServerEnv(t)
+		t.Setenv(jwt.EnvKeyJWTSecret, "secret")
+		t.Setenv(auth.EnvKeyPasswordPepper, "pepper")
+		t.Setenv("API_KEYS", "analytics:"+apikey.HashKey("k")+":candles:read")
+		t.Setenv("API_KEY_RATE_LIMIT_PER_MINUTE", "30")
+
 		cfg, err := LoadAPI()
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
-		if len(cfg.Warnings) == 0 {
-			t.Error("expected a warning for invalid COOKIE_SECURE")
+		if len(cfg.Server.APIKeys.Keys) != 1 || cfg.Server.APIKeys.Keys[0].ID != "analytics" {
+			t.Errorf("unexpected api keys: %+v", cfg.Server.APIKeys.Keys)
 		}
-		if cfg.Server.SecureCookie {
-			t.Error("secureCookie should fall back to false")
+		if cfg.Server.APIKeys.Limit != 30 {
+			t.Errorf("api key limit: got %d, want 30", cfg.Server.APIKeys.Limit)
+		}
+	})
+
+	t.Run("不正な API_KEYS はエラー", func(t *testing.T) {
+		clearServerEnv(t)
+		t.Setenv(jwt.EnvKeyJWTSecret, "secret")
+		t.Setenv(auth.EnvKeyPasswordPepper, "pepper")
+		t.Setenv("API_KEYS", "analytics:plaintext:candles:read")
+
+		if _, err := LoadAPI(); err == nil {
+			t.Fatal("expected error for malformed API_KEYS, got nil")
 		}
 	})
 }
 
 func TestReadOAuth(t *testing.T) {
-	t.Run("プロバイダ未設定は無効(nil)", func(t *testing.T) {
-		clearServerEnv(t)
-		cfg, err := readOAuth()
+	t.Parallel()
+
+	google := map[string]string{
+		"GOOGLE_CLIENT_ID":            "gid",
+		"GOOGLE_CLIENT_SECRET":        "gsec",
+		"GOOGLE_REDIRECT_URL":         "https://api.example.com/google/cb",
+		"OAUTH_FRONTEND_REDIRECT_URL": "https://app.example.com",
+	}
+	github := map[string]string{
+		"GITHUB_CLIENT_ID":            "hid",
+		"GITHUB_CLIENT_SECRET":        "hsec",
+		"GITHUB_REDIRECT_URL":         "https://api.example.com/github/cb",
+		"OAUTH_FRONTEND_REDIRECT_URL": "https://app.example.com",
+	}
+	// merge は a と b を合わせたコピーを返す。
+	merge := func(a, b map[string]string) map[string]string {
+		out := maps.Clone(a)
+		maps.Copy(out, b)
+		return out
+	}
+	// without は m から keys を除いたコピーを返す。
+	without := func(m map[string]string, keys ...string) map[string]string {
+		out := maps.Clone(m)
+		for _, k := range keys {
+			delete(out, k)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name       string
+		vars       map[string]string
+		wantErr    string
+		wantGoogle bool
+		wantGitHub bool
+	}{
+		{name: "プロバイダ未設定は無効(nil)", vars: map[string]string{}},
+		{name: "frontend URL 欠落はエラー", vars: without(google, "OAUTH_FRONTEND_REDIRECT_URL"), wantErr: "OAUTH_FRONTEND_REDIRECT_URL is required when OAuth is enabled"},
+		{name: "Google secret 欠落はエラー", vars: without(google, "GOOGLE_CLIENT_SECRET"), wantErr: "GOOGLE_CLIENT_SECRET is required when GOOGLE_CLIENT_ID is set"},
+		{name: "Google redirect 欠落はエラー", vars: without(google, "GOOGLE_REDIRECT_URL"), wantErr: "GOOGLE_REDIRECT_URL is required when GOOGLE_CLIENT_ID is set"},
+		{name: "Google 完全設定で google のみ生成", vars: google, wantGoogle: true},
+		{name: "GitHub secret 欠落はエラー", vars: without(github, "GITHUB_CLIENT_SECRET"), wantErr: "GITHUB_CLIENT_SECRET is required when GITHUB_CLIENT_ID is set"},
+		{name: "両プロバイダ完全設定で両方生成", vars: merge(google, github), wantGoogle: true, wantGitHub: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := env.NewReader(env.MapLookup(tt.vars))
+			cfg := readOAuth(r)
+			err := r.Err()
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.wantGoogle && !tt.wantGitHub {
+				if cfg != nil {
+					t.Errorf("expected nil config when OAuth disabled, got %+v", cfg)
+				}
+				return
+			}
+			if cfg == nil || (cfg.Google != nil) != tt.wantGoogle || (cfg.GitHub != nil) != tt.wantGitHub {
+				t.Errorf("unexpected providers: %+v", cfg)
+			}
+		})
+	}
+}
+
+// TestLoad_AggregatesErrors は実際の環境変数に触れずに、欠落・不正値の全てが 1 つのエラーにまとまることを検証する。
+func TestLoad_AggregatesErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("API", func(t *testing.T) {
+		t.Parallel()
+		r := env.NewReader(env.MapLookup(map[string]string{
+			"COOKIE_SECURE":    "yes",
+			"PUSH_WORKERS":     "0",
+			"FX_CURRENCIES":    "JPY,YEN1",
+			"GITHUB_CLIENT_ID": "hid",
+			"LOG_LEVEL":        "loud",
+		}))
+		cfg, err := loadAPI(r, []string{"FLAG_WRITE_THROUGH=maybe"})
+		if cfg == nil {
+			t.Fatal("config should be returned even on error")
+		}
+		for _, want := range []string{
+			jwt.EnvKeyJWTSecret + " is required",
+			auth.EnvKeyPasswordPepper + " is required",
+			`COOKIE_SECURE="yes": invalid syntax`,
+			`PUSH_WORKERS="0": must be positive`,
+			`FX_CURRENCIES="JPY,YEN1"`,
+			"OAUTH_FRONTEND_REDIRECT_URL is required when OAuth is enabled",
+			"GITHUB_CLIENT_SECRET is required when GITHUB_CLIENT_ID is set",
+		} {
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("error should contain %q, got %v", want, err)
+			}
+		}
+		if !errors.Is(err, env.ErrRequired) {
+			t.Errorf("error should wrap env.ErrRequired, got %v", err)
+		}
+		// 不正値があってもデフォルト値で埋め、ロガーの設定・フラグの不正値は警告に留める
+		if cfg.Push.Dispatcher.Workers != push.DefaultWorkers {
+			t.Errorf("Workers = %d, want default %d", cfg.Push.Dispatcher.Workers, push.DefaultWorkers)
+		}
+		if len(cfg.Warnings) != 2 {
+			t.Errorf("expected warnings for LOG_LEVEL and FLAG_WRITE_THROUGH, got %v", cfg.Warnings)
+		}
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		t.Parallel()
+		r := env.NewReader(env.MapLookup(map[string]string{
+			"APP_ENV":                    "production",
+			"CACHE_NAMESPACE":            "", // 未設定なら APP_ENV になるが、空文字の明示は名前空間なし
+			"TWELVE_DATA_PLAN":           "unlimited",
+			"TWELVE_DATA_MAX_OUTPUTSIZE": "lots",
+			"INGEST_TIMEOUT_HOURS":       "-2",
+		}))
+		_, err := loadBatch(r, nil)
+		for _, want := range []string{
+			"CACHE_NAMESPACE: must not be empty when APP_ENV=production",
+			`TWELVE_DATA_PLAN="unlimited"`,
+			`TWELVE_DATA_MAX_OUTPUTSIZE="lots": invalid syntax`,
+			`INGEST_TIMEOUT_HOURS="-2": must be positive`,
+		} {
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("error should contain %q, got %v", want, err)
+			}
+		}
+	})
+
+	t.Run("全て有効ならエラーなし", func(t *testing.T) {
+		t.Parallel()
+		r := env.NewReader(env.MapLookup(map[string]string{
+			jwt.EnvKeyJWTSecret:       "secret",
+			auth.EnvKeyPasswordPepper: "pepper",
+			"PUSH_WORKERS":            " 8 ",
+		}))
+		cfg, err := loadAPI(r, nil)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
-		if cfg != nil {
-			t.Errorf("expected nil config when OAuth disabled, got %+v", cfg)
+		if cfg.Push.Dispatcher.Workers != 8 {
+			t.Errorf("Workers = %d, want 8", cfg.Push.Dispatcher.Workers)
 		}
 	})
+}
 
-	t.Run("frontend URL 欠落はエラー", func(t *testing.T) {
+func TestLoadAPI_CacheNamespace(t *testing.T) {
+	setRequired := func(t *testing.T) {
+		t.Helper()
 		clearServerEnv(t)
-		t.Setenv("GOOGLE_CLIENT_ID", "gid")
-		if _, err := readOAuth(); err == nil {
-			t.Fatal("expected error when OAUTH_FRONTEND_REDIRECT_URL is missing")
+		t.Setenv(jwt.EnvKeyJWTSecret, "secret")
+		t.Setenv(auth.EnvKeyPasswordPepper, "pepper")
+	}
+
+	t.Run("未設定なら APP_ENV から導出", func(t *testing.T) {
+		setRequired(t)
+		t.Setenv("APP_ENV", "production")
+		cfg, err := LoadAPI()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := cfg.Redis.Keys.Key("candles"); got != "production:candles" {
+			t.Errorf("Key(candles) = %q, want %q", got, "production:candles")
 		}
 	})
 
-	t.Run("Google secret 欠落はエラー", func(t *testing.T) {
-		clearServerEnv(t)
-		t.Setenv("GOOGLE_CLIENT_ID", "gid")
-		t.Setenv("OAUTH_FRONTEND_REDIRECT_URL", "https://app.example.com")
-		t.Setenv("GOOGLE_REDIRECT_URL", "https://api.example.com/cb")
-		if _, err := readOAuth(); err == nil {
-			t.Fatal("expected error when GOOGLE_CLIENT_SECRET is missing")
+	t.Run("明示した値が優先", func(t *testing.T) {
+		setRequired(t)
+		t.Setenv("APP_ENV", "production")
+		t.Setenv("CACHE_NAMESPACE", "prod-eu")
+		cfg, err := LoadAPI()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := cfg.Redis.Keys.Namespace(); got != "prod-eu" {
+			t.Errorf("Namespace = %q, want prod-eu", got)
+		}
+	})
+
+	t.Run("production で空の名前空間は起動拒否", func(t *testing.T) {
+		setRequired(t)
+		t.Setenv("APP_ENV", "production")
+		t.Setenv("CACHE_NAMESPACE", " ")
+		if _, err := LoadAPI(); err == nil {
+			t.Fatal("expected error for empty namespace in production, got nil")
+		}
+	})
+
+	t.Run("production 以外は空の名前空間を許容", func(t *testing.T) {
+		setRequired(t)
+		t.Setenv("CACHE_NAMESPACE", "")
+		cfg, err := LoadAPI()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := cfg.Redis.Keys.Key("candles"); got != "candles" {
+			t.Errorf("Key(candles) = %q, want candles", got)
+		}
+	})
+
+	t.Run("不正な名前空間はエラー", func(t *testing.T) {
+		setRequired(t)
+		t.Setenv("CACHE_NAMESPACE", "staging:candles")
+		if _, err := LoadAPI(); err == nil {
+			t.Fatal("expected error for invalid namespace, got nil")
 		}
 	})
 
-	t.Run("Google redirect 欠落はエラー", func(t *testing.T) {
+	t.Run("バッチも同じ検証を行う", func(t *testing.T) {
 		clearServerEnv(t)
-		t.Setenv("GOOGLE_CLIENT_ID", "gid")
-		t.Setenv("GOOGLE_CLIENT_SECRET", "gsec")
-		t.Setenv("OAUTH_FRONTEND_REDIRECT_URL", "https://app.example.com")
-		if _, err := readOAuth(); err == nil {
-			t.Fatal("expected error when GOOGLE_REDIRECT_URL is missing")
+		t.Setenv("APP_ENV", "production")
+		t.Setenv("CACHE_NAMESPACE", "")
+		if _, err := LoadBatch(); err == nil {
+			t.Fatal("expected error for empty namespace in production batch, got nil")
 		}
 	})
+}
 
-	t.Run("Google 完全設定で google のみ生成", func(t *testing.T) {
+func TestLoadAPI_FX(t *testing.T) {
+	setRequired := func(t *testing.T) {
+		t.Helper()
 		clearServerEnv(t)
-		t.Setenv("GOOGLE_CLIENT_ID", "gid")
-		t.Setenv("GOOGLE_CLIENT_SECRET", "gsec")
-		t.Setenv("GOOGLE_REDIRECT_URL", "https://api.example.com/cb")
-		t.Setenv("OAUTH_FRONTEND_REDIRECT_URL", "https://app.example.com")
+		t.Setenv(jwt.EnvKeyJWTSecret, "secret")
+		t.Setenv(auth.EnvKeyPasswordPepper, "pepper")
+	}
+
+	t.Run("未設定は JPY,USD で固定レートなし", func(t *testing.T) {
+		setRequired(t)
+		cfg, err := LoadAPI()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := cfg.FX.Currencies; len(got) != 2 || got[0] != "JPY" || got[1] != "USD" {
+			t.Errorf("Currencies = %v, want [JPY USD]", got)
+		}
+		if len(cfg.FX.StaticRates) != 0 {
+			t.Errorf("StaticRates = %v, want empty", cfg.FX.StaticRates)
+		}
+	})
 
-		cfg, err := readOAuth()
+	t.Run("通貨は大文字に正規化し固定レートを読み込む", func(t *testing.T) {
+		setRequired(t)
+		t.Setenv("FX_CURRENCIES", "jpy, usd, eur")
+		t.Setenv("FX_STATIC_RATES", "USD/JPY=150.25")
+		cfg, err := LoadAPI()
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
-		if cfg == nil || cfg.Google == nil {
-			t.Fatalf("expected google config, got %+v", cfg)
+		if got := cfg.FX.Currencies; len(got) != 3 || got[2] != "EUR" {
+			t.Errorf("Currencies = %v, want [JPY USD EUR]", got)
+		}
+		if got := cfg.FX.StaticRates["USD/JPY"]; got != 150.25 {
+			t.Errorf("StaticRates[USD/JPY] = %v, want 150.25", got)
 		}
-		if cfg.GitHub != nil {
-			t.Error("github should be nil when not configured")
+	})
+
+	t.Run("不正な通貨コードはエラー", func(t *testing.T) {
+		setRequired(t)
+		t.Setenv("FX_CURRENCIES", "JPY,YEN1")
+		if _, err := LoadAPI(); err == nil {
+			t.Fatal("expected error for invalid FX_CURRENCIES, got nil")
 		}
 	})
 
-	t.Run("GitHub secret 欠落はエラー", func(t *testing.T) {
+	t.Run("不正な固定レートはエラー", func(t *testing.T) {
+		setRequired(t)
+		t.Setenv("FX_STATIC_RATES", "USDJPY=150")
+		if _, err := LoadAPI(); err == nil {
+			t.Fatal("expected error for invalid FX_STATIC_RATES, got nil")
+		}
+	})
+}
+
+func TestLoad_CandlesOutputSize(t *testing.T) {
+	setRequired := func(t *testing.T) {
+		t.Helper()
 		clearServerEnv(t)
-		t.Setenv("GITHUB_CLIENT_ID", "hid")
-		t.Setenv("GITHUB_REDIRECT_URL", "https://api.example.com/cb")
-		t.Setenv("OAUTH_FRONTEND_REDIRECT_URL", "https://app.example.com")
-		if _, err := readOAuth(); err == nil {
-			t.Fatal("expected error when GITHUB_CLIENT_SECRET is missing")
+		t.Setenv(jwt.EnvKeyJWTSecret, "secret")
+		t.Setenv(auth.EnvKeyPasswordPepper, "pepper")
+	}
+
+	t.Run("未設定は組み込みの値", func(t *testing.T) {
+		setRequired(t)
+		cfg, err := LoadAPI()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := cfg.OutputSize.Limit("1month"); got != (candles.OutputSizeLimit{Default: 120, Max: 240}) {
+			t.Errorf("Limit(1month) = %+v, want {120 240}", got)
 		}
 	})
 
-	t.Run("両プロバイダ完全設定で両方生成", func(t *testing.T) {
+	t.Run("API と batch の両方で指定した値を使用", func(t *testing.T) {
+		setRequired(t)
+		t.Setenv("CANDLES_OUTPUTSIZE", "1day=100:3000,1week=52:520")
+		want := candles.OutputSizeLimit{Default: 52, Max: 520}
+		api, err := LoadAPI()
+		if err != nil {
+			t.Fatalf("LoadAPI: unexpected error: %v", err)
+		}
+		batch, err := LoadBatch()
+		if err != nil {
+			t.Fatalf("LoadBatch: unexpected error: %v", err)
+		}
+		for name, cfg := range map[string]*Config{"api": api, "batch": batch} {
+			if got := cfg.OutputSize.Limit("1week"); got != want {
+				t.Errorf("%s: Limit(1week) = %+v, want %+v", name, got, want)
+			}
+			if got := cfg.OutputSize.Limit("1day").Max; got != 3000 {
+				t.Errorf("%s: Limit(1day).Max = %d, want 3000", name, got)
+			}
+		}
+	})
+
+	t.Run("既定値が上限を超える指定はエラー", func(t *testing.T) {
+		setRequired(t)
+		t.Setenv("CANDLES_OUTPUTSIZE", "1month=300:240")
+		if _, err := LoadAPI(); err == nil || !strings.Contains(err.Error(), "CANDLES_OUTPUTSIZE") {
+			t.Errorf("LoadAPI: expected CANDLES_OUTPUTSIZE error, got %v", err)
+		}
+		if _, err := LoadBatch(); err == nil || !strings.Contains(err.Error(), "CANDLES_OUTPUTSIZE") {
+			t.Errorf("LoadBatch: expected CANDLES_OUTPUTSIZE error, got %v", err)
+		}
+	})
+
+	t.Run("上限が MaxOutputSize を超える指定・未知の時間間隔はエラー", func(t *testing.T) {
+		setRequired(t)
+		for _, v := range []string{"1day=200:6000", "1h=100:500", "1day=200"} {
+			t.Setenv("CANDLES_OUTPUTSIZE", v)
+			if _, err := LoadAPI(); err == nil {
+				t.Errorf("CANDLES_OUTPUTSIZE=%q: expected error, got nil", v)
+			}
+		}
+	})
+}
+
+func TestLoadAPI_DeprecatedRoutes(t *testing.T) {
+	clearServerEnv(t)
+	t.Setenv(jwt.EnvKeyJWTSecret, "secret")
+	t.Setenv(auth.EnvKeyPasswordPepper, "pepper")
+
+	cfg, err := LoadAPI()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Server.DeprecatedRoutes) != 0 {
+		t.Errorf("DeprecatedRoutes = %+v, want none by default", cfg.Server.DeprecatedRoutes)
+	}
+
+	t.Setenv(deprecation.EnvKeyRoutes, "GET /v1/candles/{code}|2027-03-31|/v2/candles/{code}")
+	cfg, err = LoadAPI()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []deprecation.Rule{{Method: "GET", Route: "/v1/candles/{code}", Sunset: time.Date(2027, 3, 31, 0, 0, 0, 0, time.UTC), Successor: "/v2/candles/{code}"}}
+	if !reflect.DeepEqual(cfg.Server.DeprecatedRoutes, want) {
+		t.Errorf("DeprecatedRoutes = %+v, want %+v", cfg.Server.DeprecatedRoutes, want)
+	}
+
+	t.Setenv(deprecation.EnvKeyRoutes, "GET /v1/candles/{code}|next-year")
+	if _, err := LoadAPI(); err == nil {
+		t.Fatal("expected error for invalid DEPRECATED_ROUTES, got nil")
+	}
+}
+
+func TestLoadAPI_Maintenance(t *testing.T) {
+	clearServerEnv(t)
+	t.Setenv(jwt.EnvKeyJWTSecret, "secret")
+	t.Setenv(auth.EnvKeyPasswordPepper, "pepper")
+
+	cfg, err := LoadAPI()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Server.MaintenanceWriteAllowlist, maintenance.DefaultWriteAllowlist) {
+		t.Errorf("MaintenanceWriteAllowlist = %v, want %v", cfg.Server.MaintenanceWriteAllowlist, maintenance.DefaultWriteAllowlist)
+	}
+	if cfg.Server.MaintenanceRetryAfter != maintenance.DefaultRetryAfter {
+		t.Errorf("MaintenanceRetryAfter = %v, want %v", cfg.Server.MaintenanceRetryAfter, maintenance.DefaultRetryAfter)
+	}
+
+	t.Setenv(maintenance.EnvKeyWriteAllowlist, "post /v1/login, PUT /v1/me/digest")
+	t.Setenv(maintenance.EnvKeyRetryAfter, "90s")
+	cfg, err = LoadAPI()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"POST /v1/login", "PUT /v1/me/digest"}
+	if !reflect.DeepEqual(cfg.Server.MaintenanceWriteAllowlist, want) {
+		t.Errorf("MaintenanceWriteAllowlist = %v, want %v", cfg.Server.MaintenanceWriteAllowlist, want)
+	}
+	if cfg.Server.MaintenanceRetryAfter != 90*time.Second {
+		t.Errorf("MaintenanceRetryAfter = %v, want 90s", cfg.Server.MaintenanceRetryAfter)
+	}
+
+	t.Setenv(maintenance.EnvKeyWriteAllowlist, "GET /v1/candles/{code}")
+	if _, err := LoadAPI(); err == nil {
+		t.Fatal("expected error for read method in MAINTENANCE_WRITE_ALLOWLIST, got nil")
+	}
+}
+
+func TestLoadBatch_TwelveDataCapabilities(t *testing.T) {
+	clearTwelveData := func(t *testing.T) {
+		t.Helper()
 		clearServerEnv(t)
-		t.Setenv("GOOGLE_CLIENT_ID", "gid")
-		t.Setenv("GOOGLE_CLIENT_SECRET", "gsec")
-		t.Setenv("GOOGLE_REDIRECT_URL", "https://api.example.com/google/cb")
-		t.Setenv("GITHUB_CLIENT_ID", "hid")
-		t.Setenv("GITHUB_CLIENT_SECRET", "hsec")
-		t.Setenv("GITHUB_REDIRECT_URL", "https://api.example.com/github/cb")
-		t.Setenv("OAUTH_FRONTEND_REDIRECT_URL", "https://app.example.com")
+		for _, k := range []string{"TWELVE_DATA_PLAN", "TWELVE_DATA_MAX_OUTPUTSIZE", "TWELVE_DATA_INTERVALS"} {
+			t.Setenv(k, "")
+		}
+	}
+
+	t.Run("未設定は basic プラン", func(t *testing.T) {
+		clearTwelveData(t)
+		cfg, err := LoadBatch()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if caps := cfg.TwelveData.Capabilities; caps.Plan != "basic" || caps.MaxOutputSize != 5000 {
+			t.Errorf("Capabilities = %+v, want basic preset", caps)
+		}
+	})
+
+	t.Run("プランと個別設定で上書き", func(t *testing.T) {
+		clearTwelveData(t)
+		t.Setenv("TWELVE_DATA_PLAN", "pro")
+		t.Setenv("TWELVE_DATA_MAX_OUTPUTSIZE", "1000")
+		t.Setenv("TWELVE_DATA_INTERVALS", "1day, 1week")
+		cfg, err := LoadBatch()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		caps := cfg.TwelveData.Capabilities
+		if caps.Plan != "pro" || caps.MaxOutputSize != 1000 {
+			t.Errorf("Capabilities = %+v, want pro with max 1000", caps)
+		}
+		if len(caps.Intervals) != 2 || caps.Intervals[0] != "1day" || caps.Intervals[1] != "1week" {
+			t.Errorf("Intervals = %v, want [1day 1week]", caps.Intervals)
+		}
+	})
+
+	t.Run("未知のプランはエラー", func(t *testing.T) {
+		clearTwelveData(t)
+		t.Setenv("TWELVE_DATA_PLAN", "unlimited")
+		if _, err := LoadBatch(); err == nil {
+			t.Fatal("expected error for unknown TWELVE_DATA_PLAN, got nil")
+		}
+	})
+}
+
+func TestLoadBatch_TwelveDataRecording(t *testing.T) {
+	for _, k := range []string{"TWELVE_DATA_RECORD_DIR", "TWELVE_DATA_RECORD_MAX_BYTES", "TWELVE_DATA_RECORD_MAX_AGE"} {
+		t.Setenv(k, "")
+	}
+	clearServerEnv(t)
+
+	cfg, err := LoadBatch()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec := cfg.TwelveData.Recording; rec.Enabled() || rec.MaxBytes != twelvedata.DefaultRecordingMaxBytes || rec.MaxAge != twelvedata.DefaultRecordingMaxAge {
+		t.Errorf("Recording = %+v, want disabled with defaults", rec)
+	}
+
+	t.Setenv("TWELVE_DATA_RECORD_DIR", "/var/tmp/twelvedata")
+	t.Setenv("TWELVE_DATA_RECORD_MAX_BYTES", "1048576")
+	t.Setenv("TWELVE_DATA_RECORD_MAX_AGE", "48h")
+	cfg, err = LoadBatch()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := twelvedata.RecordingConfig{Dir: "/var/tmp/twelvedata", MaxBytes: 1 << 20, MaxAge: 48 * time.Hour}
+	if cfg.TwelveData.Recording != want {
+		t.Errorf("Recording = %+v, want %+v", cfg.TwelveData.Recording, want)
+	}
+
+	t.Setenv("TWELVE_DATA_RECORD_MAX_BYTES", "0")
+	if _, err := LoadBatch(); err == nil {
+		t.Fatal("expected error for non-positive TWELVE_DATA_RECORD_MAX_BYTES, got nil")
+	}
+}
 
-		cfg, err := readOAuth()
+func TestLoadBatch_TwelveDataProxy(t *testing.T) {
+	for _, k := range []string{"TWELVE_DATA_PROXY_AUTH_HEADER", "TWELVE_DATA_PROXY_AUTH_VALUE", "TWELVE_DATA_API_KEY_IN_HEADER", "TWELVE_DATA_ALLOWED_HOSTS"} {
+		t.Setenv(k, "")
+	}
+	clearServerEnv(t)
+	t.Setenv("TWELVE_DATA_BASE_URL", "https://td-proxy.internal")
+
+	t.Run("プロキシの設定を読み込む", func(t *testing.T) {
+		t.Setenv("TWELVE_DATA_PROXY_AUTH_HEADER", "X-Proxy-Token")
+		t.Setenv("TWELVE_DATA_PROXY_AUTH_VALUE", "proxy-secret")
+		t.Setenv("TWELVE_DATA_API_KEY_IN_HEADER", "true")
+		t.Setenv("TWELVE_DATA_ALLOWED_HOSTS", "td-proxy.internal, api.twelvedata.com")
+		cfg, err := LoadBatch()
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
-		if cfg == nil || cfg.Google == nil || cfg.GitHub == nil {
-			t.Fatalf("expected both providers, got %+v", cfg)
+		td := cfg.TwelveData
+		if td.ProxyAuthHeader != "X-Proxy-Token" || td.ProxyAuthValue != "proxy-secret" || !td.APIKeyInHeader {
+			t.Errorf("proxy config = %q/%q/%v", td.ProxyAuthHeader, td.ProxyAuthValue, td.APIKeyInHeader)
+		}
+		if want := []string{"td-proxy.internal", "api.twelvedata.com"}; !slices.Equal(td.AllowedHosts, want) {
+			t.Errorf("AllowedHosts = %v, want %v", td.AllowedHosts, want)
+		}
+	})
+
+	t.Run("許可していないホストは起動時に拒否", func(t *testing.T) {
+		t.Setenv("TWELVE_DATA_BASE_URL", "https://td-proxy-staging.internal")
+		t.Setenv("TWELVE_DATA_ALLOWED_HOSTS", "td-proxy.internal")
+		_, err := LoadBatch()
+		if err == nil || !strings.Contains(err.Error(), "TWELVE_DATA_BASE_URL") {
+			t.Fatalf("expected TWELVE_DATA_BASE_URL error, got %v", err)
+		}
+	})
+
+	t.Run("認証ヘッダーの名前と値は組で指定", func(t *testing.T) {
+		t.Setenv("TWELVE_DATA_PROXY_AUTH_HEADER", "X-Proxy-Token")
+		_, err := LoadBatch()
+		if err == nil || !strings.Contains(err.Error(), "TWELVE_DATA_PROXY_AUTH_VALUE") {
+			t.Fatalf("expected TWELVE_DATA_PROXY_AUTH_VALUE error, got %v", err)
 		}
 	})
 }
@@ -193,6 +999,7 @@ func TestLoadBatch(t *testing.T) {
 		for _, k := range []string{
 			"INGEST_TIMEOUT_HOURS", "INGEST_MAX_FAILURE_RATE",
 			"LOGO_INGEST_TIMEOUT_HOURS", "LOGO_INGEST_MAX_FAILURE_RATE",
+			"EVENTS_INGEST_TIMEOUT_HOURS", "EVENTS_INGEST_MAX_FAILURE_RATE", "DIGEST_MAX_PER_MINUTE",
 		} {
 			t.Setenv(k, "")
 		}
@@ -206,6 +1013,12 @@ func TestLoadBatch(t *testing.T) {
 		if cfg.Batch.CandlesMaxFailureRate != defaultMaxFailureRate {
 			t.Errorf("CandlesMaxFailureRate = %v, want %v", cfg.Batch.CandlesMaxFailureRate, defaultMaxFailureRate)
 		}
+		if cfg.Batch.EventsTimeoutHours != defaultIngestTimeoutHours || cfg.Batch.EventsMaxFailureRate != defaultMaxFailureRate {
+			t.Errorf("unexpected events batch config: %+v", cfg.Batch)
+		}
+		if cfg.Batch.DigestMaxPerMinute != defaultDigestMaxPerMinute {
+			t.Errorf("DigestMaxPerMinute = %d, want %d", cfg.Batch.DigestMaxPerMinute, defaultDigestMaxPerMinute)
+		}
 	})
 
 	t.Run("有効な値を読み込む", func(t *testing.T) {
@@ -213,6 +1026,9 @@ func TestLoadBatch(t *testing.T) {
 		t.Setenv("INGEST_MAX_FAILURE_RATE", "0.5")
 		t.Setenv("LOGO_INGEST_TIMEOUT_HOURS", "2")
 		t.Setenv("LOGO_INGEST_MAX_FAILURE_RATE", "0.1")
+		t.Setenv("EVENTS_INGEST_TIMEOUT_HOURS", "1")
+		t.Setenv("EVENTS_INGEST_MAX_FAILURE_RATE", "0.3")
+		t.Setenv("DIGEST_MAX_PER_MINUTE", "10")
 
 		cfg, err := LoadBatch()
 		if err != nil {
@@ -224,23 +1040,249 @@ func TestLoadBatch(t *testing.T) {
 		if cfg.Batch.LogoTimeoutHours != 2 || cfg.Batch.LogoMaxFailureRate != 0.1 {
 			t.Errorf("unexpected logo batch config: %+v", cfg.Batch)
 		}
+		if cfg.Batch.EventsTimeoutHours != 1 || cfg.Batch.EventsMaxFailureRate != 0.3 {
+			t.Errorf("unexpected events batch config: %+v", cfg.Batch)
+		}
+		if cfg.Batch.DigestMaxPerMinute != 10 {
+			t.Errorf("DigestMaxPerMinute = %d, want 10", cfg.Batch.DigestMaxPerMinute)
+		}
 	})
 
-	t.Run("不正な失敗率は Warnings に記録しデフォルト", func(t *testing.T) {
+	t.Run("範囲外の失敗率はエラー", func(t *testing.T) {
 		t.Setenv("INGEST_TIMEOUT_HOURS", "")
 		t.Setenv("INGEST_MAX_FAILURE_RATE", "2.0") // 範囲外
 		t.Setenv("LOGO_INGEST_TIMEOUT_HOURS", "")
 		t.Setenv("LOGO_INGEST_MAX_FAILURE_RATE", "")
 
+		if _, err := LoadBatch(); err == nil {
+			t.Error("expected error for out-of-range INGEST_MAX_FAILURE_RATE, got nil")
+		}
+	})
+
+	t.Run("異常値検出の設定", func(t *testing.T) {
+		t.Setenv("ANOMALY_THRESHOLD", "")
+		t.Setenv("ANOMALY_QUARANTINE", "")
 		cfg, err := LoadBatch()
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
-		if len(cfg.Warnings) == 0 {
-			t.Error("expected a warning for invalid INGEST_MAX_FAILURE_RATE")
+		if cfg.Batch.Anomaly != (candles.AnomalyConfig{Threshold: candles.DefaultAnomalyThreshold}) {
+			t.Errorf("unexpected default anomaly config: %+v", cfg.Batch.Anomaly)
 		}
-		if cfg.Batch.CandlesMaxFailureRate != defaultMaxFailureRate {
-			t.Errorf("CandlesMaxFailureRate should fall back to default, got %v", cfg.Batch.CandlesMaxFailureRate)
+
+		t.Setenv("ANOMALY_THRESHOLD", "0.45")
+		t.Setenv("ANOMALY_QUARANTINE", "true")
+		cfg, err = LoadBatch()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Batch.Anomaly != (candles.AnomalyConfig{Threshold: 0.45, Quarantine: true}) {
+			t.Errorf("unexpected anomaly config: %+v", cfg.Batch.Anomaly)
+		}
+
+		t.Setenv("ANOMALY_THRESHOLD", "-1")
+		t.Setenv("ANOMALY_QUARANTINE", "maybe")
+		_, err = LoadBatch()
+		if err == nil || !strings.Contains(err.Error(), "ANOMALY_THRESHOLD") || !strings.Contains(err.Error(), "ANOMALY_QUARANTINE") {
+			t.Errorf("expected errors for both invalid anomaly settings, got %v", err)
+		}
+	})
+
+	t.Run("取り込み優先度ごとの時間予算", func(t *testing.T) {
+		t.Setenv("INGEST_TIER_BUDGETS", "2=30m,3=45m")
+		cfg, err := LoadBatch()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := map[int]time.Duration{2: 30 * time.Minute, 3: 45 * time.Minute}; !reflect.DeepEqual(cfg.Batch.CandlesTierBudgets, want) {
+			t.Errorf("CandlesTierBudgets = %v, want %v", cfg.Batch.CandlesTierBudgets, want)
+		}
+
+		t.Setenv("INGEST_TIER_BUDGETS", "2=soon")
+		if _, err := LoadBatch(); err == nil {
+			t.Error("expected error for invalid INGEST_TIER_BUDGETS, got nil")
+		}
+		t.Setenv("INGEST_TIER_BUDGETS", "")
+	})
+
+	t.Run("取り込みのワーカー数", func(t *testing.T) {
+		t.Setenv("INGEST_CONCURRENCY", "")
+		cfg, err := LoadBatch()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Batch.CandlesConcurrency != 3 {
+			t.Errorf("CandlesConcurrency = %d, want 3", cfg.Batch.CandlesConcurrency)
+		}
+
+		t.Setenv("INGEST_CONCURRENCY", "1")
+		if cfg, err = LoadBatch(); err != nil || cfg.Batch.CandlesConcurrency != 1 {
+			t.Errorf("CandlesConcurrency = %d (err %v), want 1", cfg.Batch.CandlesConcurrency, err)
+		}
+
+		for _, v := range []string{"0", "17"} {
+			t.Setenv("INGEST_CONCURRENCY", v)
+			if _, err := LoadBatch(); err == nil || !strings.Contains(err.Error(), "INGEST_CONCURRENCY") {
+				t.Errorf("INGEST_CONCURRENCY=%s: expected error, got %v", v, err)
+			}
 		}
+		t.Setenv("INGEST_CONCURRENCY", "")
 	})
+
+	t.Run("一括取得の銘柄数", func(t *testing.T) {
+		t.Setenv("INGEST_BATCH_SIZE", "")
+		cfg, err := LoadBatch()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Batch.CandlesBatchSize != 1 {
+			t.Errorf("CandlesBatchSize = %d, want 1 (per symbol)", cfg.Batch.CandlesBatchSize)
+		}
+
+		t.Setenv("INGEST_BATCH_SIZE", "8")
+		if cfg, err = LoadBatch(); err != nil || cfg.Batch.CandlesBatchSize != 8 {
+			t.Errorf("CandlesBatchSize = %d (err %v), want 8", cfg.Batch.CandlesBatchSize, err)
+		}
+
+		for _, v := range []string{"0", "121"} {
+			t.Setenv("INGEST_BATCH_SIZE", v)
+			if _, err := LoadBatch(); err == nil || !strings.Contains(err.Error(), "INGEST_BATCH_SIZE") {
+				t.Errorf("INGEST_BATCH_SIZE=%s: expected error, got %v", v, err)
+			}
+		}
+		t.Setenv("INGEST_BATCH_SIZE", "")
+	})
+
+	t.Run("取り込み元の優先順位", func(t *testing.T) {
+		t.Setenv("CANDLES_SOURCE_PRECEDENCE", "")
+		cfg, err := LoadBatch()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(cfg.Batch.CandlesSourcePrecedence, candles.DefaultSourcePrecedence()) {
+			t.Errorf("CandlesSourcePrecedence = %v, want default", cfg.Batch.CandlesSourcePrecedence)
+		}
+
+		t.Setenv("CANDLES_SOURCE_PRECEDENCE", "csv, twelvedata")
+		cfg, err = LoadBatch()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := (candles.SourcePrecedence{"csv", "twelvedata"}); !reflect.DeepEqual(cfg.Batch.CandlesSourcePrecedence, want) {
+			t.Errorf("CandlesSourcePrecedence = %v, want %v", cfg.Batch.CandlesSourcePrecedence, want)
+		}
+
+		t.Setenv("CANDLES_SOURCE_PRECEDENCE", "csv,CSV:x")
+		if _, err := LoadBatch(); err == nil || !strings.Contains(err.Error(), "CANDLES_SOURCE_PRECEDENCE") {
+			t.Errorf("expected error for invalid CANDLES_SOURCE_PRECEDENCE, got %v", err)
+		}
+		t.Setenv("CANDLES_SOURCE_PRECEDENCE", "")
+	})
+
+	t.Run("seed ジョブ用の pepper と本番判定", func(t *testing.T) {
+		t.Setenv(auth.EnvKeyPasswordPepper, "pepper")
+		t.Setenv("APP_ENV", "development")
+		cfg, err := LoadBatch()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Batch.PasswordPepper != "pepper" || cfg.Batch.Production {
+			t.Errorf("unexpected seed settings: pepper=%q production=%v", cfg.Batch.PasswordPepper, cfg.Batch.Production)
+		}
+
+		t.Setenv("APP_ENV", "production")
+		t.Setenv("CACHE_NAMESPACE", "prod")
+		cfg, err = LoadBatch()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !cfg.Batch.Production {
+			t.Error("Production should be true when APP_ENV=production")
+		}
+	})
+
+	t.Run("外部APIクライアントの接続プール・プロキシ", func(t *testing.T) {
+		t.Setenv("UPSTREAM_MAX_IDLE_CONNS_PER_HOST", "32")
+		t.Setenv("UPSTREAM_IDLE_CONN_TIMEOUT", "2m")
+		t.Setenv("UPSTREAM_PROXY_URL", "http://proxy.internal:3128")
+		cfg, err := LoadBatch()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Upstream.MaxIdleConnsPerHost != 32 || cfg.Upstream.IdleConnTimeout != 2*time.Minute {
+			t.Errorf("unexpected upstream pool config: %+v", cfg.Upstream)
+		}
+		if cfg.Upstream.Proxy == nil || cfg.Upstream.Proxy.Host != "proxy.internal:3128" {
+			t.Errorf("Proxy = %v, want proxy.internal:3128", cfg.Upstream.Proxy)
+		}
+
+		t.Setenv("UPSTREAM_MAX_IDLE_CONNS_PER_HOST", "-1")
+		t.Setenv("UPSTREAM_IDLE_CONN_TIMEOUT", "later")
+		t.Setenv("UPSTREAM_PROXY_URL", "")
+		_, err = LoadBatch()
+		if err == nil || !strings.Contains(err.Error(), "UPSTREAM_MAX_IDLE_CONNS_PER_HOST") || !strings.Contains(err.Error(), "UPSTREAM_IDLE_CONN_TIMEOUT") {
+			t.Errorf("expected errors for both invalid pool settings, got %v", err)
+		}
+
+		t.Setenv("UPSTREAM_MAX_IDLE_CONNS_PER_HOST", "")
+		t.Setenv("UPSTREAM_IDLE_CONN_TIMEOUT", "")
+		t.Setenv("UPSTREAM_PROXY_URL", "proxy.internal:3128")
+		if _, err := LoadBatch(); err == nil {
+			t.Error("expected error for proxy URL without scheme, got nil")
+		}
+	})
+}
+
+func TestLoadBatch_MarketHealth(t *testing.T) {
+	keys := []string{"MARKET_HEALTH_WINDOW", "MARKET_HEALTH_MIN_SAMPLES", "MARKET_HEALTH_DEGRADED_ENTER", "MARKET_HEALTH_DEGRADED_EXIT", "MARKET_HEALTH_DOWN_ENTER", "MARKET_HEALTH_DOWN_EXIT"}
+	reset := func(t *testing.T) {
+		t.Helper()
+		clearServerEnv(t)
+		for _, k := range keys {
+			t.Setenv(k, "")
+		}
+	}
+
+	t.Run("未設定は既定値", func(t *testing.T) {
+		reset(t)
+		cfg, err := LoadBatch()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Batch.MarketHealth != candles.DefaultMarketHealthConfig() {
+			t.Errorf("MarketHealth = %+v, want defaults", cfg.Batch.MarketHealth)
+		}
+	})
+
+	t.Run("上書き", func(t *testing.T) {
+		reset(t)
+		t.Setenv("MARKET_HEALTH_WINDOW", "10m")
+		t.Setenv("MARKET_HEALTH_MIN_SAMPLES", "20")
+		t.Setenv("MARKET_HEALTH_DOWN_ENTER", "0.8")
+		t.Setenv("MARKET_HEALTH_DOWN_EXIT", "0.4")
+		cfg, err := LoadBatch()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := candles.DefaultMarketHealthConfig()
+		want.Window, want.MinSamples, want.DownEnter, want.DownExit = 10*time.Minute, 20, 0.8, 0.4
+		if cfg.Batch.MarketHealth != want {
+			t.Errorf("MarketHealth = %+v, want %+v", cfg.Batch.MarketHealth, want)
+		}
+	})
+
+	for _, tt := range []struct{ key, value string }{
+		{"MARKET_HEALTH_DEGRADED_ENTER", "0"},
+		{"MARKET_HEALTH_DOWN_ENTER", "1.5"},
+		{"MARKET_HEALTH_DOWN_EXIT", "0.9"}, // 入る閾値（既定 0.5）を超える
+	} {
+		t.Run("不正値 "+tt.key, func(t *testing.T) {
+			reset(t)
+			t.Setenv(tt.key, tt.value)
+			_, err := LoadBatch()
+			if err == nil || !strings.Contains(err.Error(), tt.key) {
+				t.Errorf("expected error mentioning %s, got %v", tt.key, err)
+			}
+		})
+	}
 }
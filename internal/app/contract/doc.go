@@ -0,0 +1,15 @@
+// Package contract は公開 API（/v1 の公開・保護ルート）の応答の形を固定するコントラクトテストを置くパッケージです。
+// 本番コードからは参照しません。
+//
+//   - ルートごとの成功・代表的なエラーの要求と応答（ステータス・主要なヘッダー・キーを昇順にした JSON）を
+//     testdata/*.golden と比較します。応答は server.New で組み立てたアプリ全体（ミドルウェアを含む）を通します
+//   - API の型（internal/api と各フィーチャーの <name>http）の公開フィールドがすべて snake_case の json タグを持つことを
+//     検証します。v1 の既存の camelCase のフィールドは互換のため許可リストで据え置き、形の修正は v2 で行います
+//     （ADR-0011）
+//
+// 意図して応答を変えた場合は golden ファイルを更新し、差分をレビューしてください。
+//
+//	go test ./internal/app/contract -update
+//
+// DB を読むケースは PostgreSQL（Docker または TEST_DB_DSN）が必要で、起動できない環境ではスキップします。
+package contract
@@ -0,0 +1,168 @@
+package contract
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// snakeCasePattern は API の JSON フィールド名として許可する形（小文字・数字と単一のアンダースコア）です。
+var snakeCasePattern = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+// legacyCamelCaseFields は v1 で公開済みの camelCase のフィールド（"<型名>.<JSON 名>"）です。
+// 既存のクライアントを壊さないため v1 では据え置き、v2 のメディアタイプで snake_case に揃えます（ADR-0011）。
+// 新しいエントリは追加しないでください。フィールドを直した場合はここから削除します（残っていると失敗します）。
+var legacyCamelCaseFields = []string{
+	"Adjustment.effectiveDate",
+	"Adjustment.updatedAt",
+	"AdminUser.createdAt",
+	"AdminUser.lastLoginAt",
+	"AdminUser.updatedAt",
+	"AdminUserPage.nextCursor",
+	"Annotation.createdAt",
+	"Annotation.updatedAt",
+	"AnnotationPage.nextCursor",
+	"Anomaly.backfillRequestedAt",
+	"Anomaly.backfilledAt",
+	"Anomaly.detectedAt",
+	"Anomaly.prevClose",
+	"Anomaly.resolvedAt",
+	"CreateAdjustmentRequest.effectiveDate",
+	"DedupeResult.dryRun",
+	"DedupeResult.groupsAffected",
+	"DedupeResult.rowsDeleted",
+	"Device.appVersion",
+	"Device.createdAt",
+	"Device.updatedAt",
+	"DuplicateCandleGroup.deletedIds",
+	"DuplicateCandleGroup.keptId",
+	"ExportJob.completedAt",
+	"ExportJob.createdAt",
+	"ExportJob.downloadUrl",
+	"ExportJob.expiresAt",
+	"ImpersonationToken.expiresAt",
+	"ImpersonationToken.expiresInSeconds",
+	"JobState.failureCount",
+	"JobState.lastDurationMs",
+	"JobState.lastError",
+	"JobState.lastFinishedAt",
+	"JobState.lastStartedAt",
+	"JobState.lastStatus",
+	"JobState.nextRunAt",
+	"JobState.runCount",
+	"JobState.runRequestedAt",
+	"JobState.timeoutSeconds",
+	"PutSymbolStatusRequest.effectiveDate",
+	"RegisterDeviceRequest.appVersion",
+	"StoredCandle.createdAt",
+	"StoredCandle.updatedAt",
+	"SymbolName.updatedAt",
+	"SymbolStatusResponse.effectiveDate",
+	"UpdateAdjustmentRequest.effectiveDate",
+	"UserProfile.createdAt",
+}
+
+// dtoDirs は API の型を置くディレクトリのパターン（リポジトリのルートから）です。
+var dtoDirs = []string{"internal/api", "internal/feature/*/*http"}
+
+// TestDTOJSONTags は API の型（json タグを持つ構造体）の公開フィールドが明示的な json タグを持ち、その名前が snake_case であることを検証します。
+// クエリパラメータの型（*Params）は JSON として返さないため対象外です。
+func TestDTOJSONTags(t *testing.T) {
+	t.Parallel()
+
+	var found []string
+	for _, pattern := range dtoDirs {
+		dirs, err := filepath.Glob(filepath.Join("..", "..", "..", pattern))
+		require.NoError(t, err)
+		for _, dir := range dirs {
+			found = append(found, camelCaseFields(t, dir)...)
+		}
+	}
+
+	for _, f := range found {
+		if !slices.Contains(legacyCamelCaseFields, f) {
+			t.Errorf("json tag of %s must be snake_case", f)
+		}
+	}
+	for _, f := range legacyCamelCaseFields {
+		if !slices.Contains(found, f) {
+			t.Errorf("%s is no longer camelCase; remove it from legacyCamelCaseFields", f)
+		}
+	}
+}
+
+// camelCaseFields は dir の Go ファイル（テストを除く）で json タグが snake_case でないフィールドを "<型名>.<JSON 名>" で返します。
+// json タグのない公開フィールドはその場でテストを失敗させます。
+func camelCaseFields(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	require.NoError(t, err)
+
+	var out []string
+	fset := token.NewFileSet()
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		require.NoError(t, err)
+		ast.Inspect(file, func(n ast.Node) bool {
+			spec, ok := n.(*ast.TypeSpec)
+			if !ok || strings.HasSuffix(spec.Name.Name, "Params") {
+				return true
+			}
+			// 型の中の無名の構造体のフィールドも同じ型名で扱う
+			ast.Inspect(spec.Type, func(n ast.Node) bool {
+				st, ok := n.(*ast.StructType)
+				if !ok || !isDTO(st) {
+					return true
+				}
+				for _, field := range st.Fields.List {
+					if field.Tag == nil {
+						for _, ident := range field.Names {
+							if ident.IsExported() {
+								t.Errorf("%s: field %s.%s has no json tag", fset.Position(ident.Pos()), spec.Name.Name, ident.Name)
+							}
+						}
+						continue
+					}
+					if name := jsonName(field.Tag.Value); name != "" && !snakeCasePattern.MatchString(name) {
+						out = append(out, spec.Name.Name+"."+name)
+					}
+				}
+				return true
+			})
+			return false
+		})
+	}
+	return out
+}
+
+// isDTO は st が JSON で読み書きする構造体（json タグを持つフィールドがある）かを返します。
+func isDTO(st *ast.StructType) bool {
+	return slices.ContainsFunc(st.Fields.List, func(f *ast.Field) bool {
+		return f.Tag != nil && strings.Contains(f.Tag.Value, `json:"`)
+	})
+}
+
+// jsonName は構造体タグのリテラル（バッククォート付き）から json の名前を返します。json タグがない・"-" の場合は空文字を返します。
+func jsonName(lit string) string {
+	tag, err := strconv.Unquote(lit)
+	if err != nil {
+		return ""
+	}
+	name, _, _ := strings.Cut(reflect.StructTag(tag).Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,61 @@
+package di
+
+import (
+	"context"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/alerts"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+)
+
+// AlertEvaluator は取り込んだ足でアラートを評価する alerts.Evaluator の操作です。
+type AlertEvaluator interface {
+	Evaluate(ctx context.Context, symbol string, series map[string][]alerts.Bar) ([]alerts.Trigger, error)
+}
+
+// alertIngestObserver は candles の取り込み完了を alerts の評価へ橋渡しします。
+// feature 同士の直接依存を避けるため DI 層で candles.Candle を alerts.Bar へ詰め替えます。
+type alertIngestObserver struct {
+	eval AlertEvaluator
+}
+
+// NewAlertIngestObserver は取り込んだ足でアラートを評価する candles.IngestObserver を返します。
+func NewAlertIngestObserver(eval AlertEvaluator) candles.IngestObserver {
+	return &alertIngestObserver{eval: eval}
+}
+
+// CandlesIngested は保存した足を時間間隔ごとに分けてアラートを評価します。
+func (o *alertIngestObserver) CandlesIngested(ctx context.Context, symbol string, cs []candles.Candle) error {
+	series := make(map[string][]alerts.Bar)
+	for _, c := range cs {
+		series[c.Interval] = append(series[c.Interval], alerts.Bar{Time: c.Time, Close: c.Close})
+	}
+	_, err := o.eval.Evaluate(ctx, symbol, series)
+	return err
+}
+
+// AlertSplitMigrator は株式分割・併合の調整でアラートの閾値を書き換える alerts.SplitMigrator の操作です。
+type AlertSplitMigrator interface {
+	Migrate(ctx context.Context, s alerts.Split) ([]alerts.Migration, error)
+}
+
+// alertSplitObserver は candles の調整係数の登録を alerts の閾値の移行へ橋渡しします。
+// feature 同士の直接依存を避けるため DI 層で candles.Adjustment を alerts.Split へ詰め替えます。
+type alertSplitObserver struct {
+	migrator AlertSplitMigrator
+}
+
+// NewAlertSplitObserver は登録した調整係数で銘柄のアラートの閾値を書き換える candles.AdjustmentObserver を返します。
+func NewAlertSplitObserver(migrator AlertSplitMigrator) candles.AdjustmentObserver {
+	return &alertSplitObserver{migrator: migrator}
+}
+
+// AdjustmentCreated は調整係数 a で銘柄のアラートの閾値を書き換えます。
+func (o *alertSplitObserver) AdjustmentCreated(ctx context.Context, a candles.Adjustment) error {
+	_, err := o.migrator.Migrate(ctx, alerts.Split{
+		AdjustmentID:  a.ID,
+		SymbolCode:    a.SymbolCode,
+		EffectiveDate: a.EffectiveDate,
+		Factor:        a.Factor,
+	})
+	return err
+}
@@ -0,0 +1,69 @@
+package di
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/alerts"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+)
+
+type stubAlertEvaluator struct {
+	symbol string
+	series map[string][]alerts.Bar
+}
+
+func (s *stubAlertEvaluator) Evaluate(ctx context.Context, symbol string, series map[string][]alerts.Bar) ([]alerts.Trigger, error) {
+	s.symbol, s.series = symbol, series
+	return nil, nil
+}
+
+func TestAlertIngestObserver_CandlesIngested(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	week := time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	stub := &stubAlertEvaluator{}
+	err := NewAlertIngestObserver(stub).CandlesIngested(context.Background(), "AAPL", []candles.Candle{
+		{SymbolCode: "AAPL", Interval: "1day", Time: day, Close: 100},
+		{SymbolCode: "AAPL", Interval: "1day", Time: day.AddDate(0, 0, 1), Close: 110},
+		{SymbolCode: "AAPL", Interval: "1week", Time: week, Close: 110},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string][]alerts.Bar{
+		"1day":  {{Time: day, Close: 100}, {Time: day.AddDate(0, 0, 1), Close: 110}},
+		"1week": {{Time: week, Close: 110}},
+	}
+	if stub.symbol != "AAPL" || !reflect.DeepEqual(stub.series, want) {
+		t.Errorf("Evaluate(%q, %v), want (AAPL, %v)", stub.symbol, stub.series, want)
+	}
+}
+
+type stubSplitMigrator struct{ split alerts.Split }
+
+func (s *stubSplitMigrator) Migrate(_ context.Context, split alerts.Split) ([]alerts.Migration, error) {
+	s.split = split
+	return nil, nil
+}
+
+func TestAlertSplitObserver_AdjustmentCreated(t *testing.T) {
+	t.Parallel()
+
+	effective := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	stub := &stubSplitMigrator{}
+	err := NewAlertSplitObserver(stub).AdjustmentCreated(context.Background(), candles.Adjustment{
+		ID: 4, SymbolCode: "AAPL", EffectiveDate: effective, Factor: 0.25, Reason: "4-for-1 split",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := alerts.Split{AdjustmentID: 4, SymbolCode: "AAPL", EffectiveDate: effective, Factor: 0.25}
+	if stub.split != want {
+		t.Errorf("Migrate(%+v), want %+v", stub.split, want)
+	}
+}
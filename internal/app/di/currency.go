@@ -0,0 +1,72 @@
+package di
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/candleshttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/rates"
+)
+
+// currencyConverter は rates と symbollist を candleshttp.CurrencyConverter に適合させます。
+// feature 同士の直接依存を避けるため DI 層で変換を行います。
+type currencyConverter struct {
+	symbols   StaleSymbolLister
+	rates     rates.Provider
+	supported map[string]struct{}
+}
+
+// NewCurrencyConverter はローソク足の ?currency= に使う CurrencyConverter 実装を返します。
+// currencies は換算先として受け付ける通貨（FX_CURRENCIES）です。
+// 銘柄の通貨は銘柄一覧と同じく、DB 障害時は last-known-good の一覧から引きます。
+func NewCurrencyConverter(symbols StaleSymbolLister, provider rates.Provider, currencies []string) candleshttp.CurrencyConverter {
+	supported := make(map[string]struct{}, len(currencies))
+	for _, c := range currencies {
+		supported[c] = struct{}{}
+	}
+	return &currencyConverter{symbols: symbols, rates: provider, supported: supported}
+}
+
+// Supported は currency が換算先として設定されているかを返します。
+func (c *currencyConverter) Supported(currency string) bool {
+	_, ok := c.supported[currency]
+	return ok
+}
+
+// Resolve は symbol の通貨から currency へのレートを解決します。
+// 銘柄の通貨が未登録、またはレートを取得できない場合は警告付きの Conversion を返します。
+func (c *currencyConverter) Resolve(ctx context.Context, symbol, currency string) candleshttp.Conversion {
+	from, ok := c.symbolCurrency(ctx, symbol)
+	if !ok {
+		return candleshttp.Conversion{Warning: candleshttp.WarningCurrencyUnknown}
+	}
+	r := rates.Identity(from)
+	if from != currency {
+		var err error
+		if r, err = c.rates.Rate(ctx, from, currency); err != nil {
+			slog.Warn("exchange rate unavailable", "pair", rates.Pair(from, currency), "symbol", symbol, "error", err)
+			return candleshttp.Conversion{Currency: from, Warning: candleshttp.WarningRateUnavailable}
+		}
+	}
+	return candleshttp.Conversion{
+		Currency: r.Quote,
+		Rate:     r.Value,
+		AsOf:     r.AsOf,
+		Convert:  func(v float64) float64 { return rates.Convert(v, r) },
+	}
+}
+
+// symbolCurrency はアクティブな銘柄 symbol の通貨を返します。未登録の場合は ok=false を返します。
+func (c *currencyConverter) symbolCurrency(ctx context.Context, symbol string) (string, bool) {
+	syms, _, err := c.symbols.ListActiveOrStale(ctx)
+	if err != nil {
+		slog.Warn("failed to look up symbol currency", "symbol", symbol, "error", err)
+		return "", false
+	}
+	for _, s := range syms {
+		if s.Code == symbol && s.Currency != nil {
+			return *s.Currency, true
+		}
+	}
+	return "", false
+}
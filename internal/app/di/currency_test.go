@@ -0,0 +1,78 @@
+package di
+
+import (
+	"context"
+	"testing"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/candleshttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/rates"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist"
+)
+
+type stubStaleSymbolLister []symbollist.Symbol
+
+func (s stubStaleSymbolLister) ListActiveOrStale(ctx context.Context) ([]symbollist.Symbol, bool, error) {
+	return s, false, nil
+}
+
+func TestCurrencyConverter_Resolve(t *testing.T) {
+	t.Parallel()
+
+	usd, jpy := "USD", "JPY"
+	symbols := stubStaleSymbolLister{
+		{Code: "AAPL", Currency: &usd},
+		{Code: "7203.T", Currency: &jpy},
+		{Code: "NOCCY"},
+	}
+	provider := rates.NewStaticProvider(map[string]float64{"USD/JPY": 150.25})
+	conv := NewCurrencyConverter(symbols, provider, []string{"JPY", "USD", "EUR"})
+
+	tests := []struct {
+		name         string
+		symbol       string
+		currency     string
+		wantCurrency string
+		wantWarning  string
+		price        float64
+		wantPrice    float64
+	}{
+		{name: "USD to JPY rounds to yen", symbol: "AAPL", currency: "JPY", wantCurrency: "JPY", price: 189.98, wantPrice: 28544},
+		{name: "JPY to USD uses inverse rate", symbol: "7203.T", currency: "USD", wantCurrency: "USD", price: 3005, wantPrice: 20},
+		{name: "same currency", symbol: "AAPL", currency: "USD", wantCurrency: "USD", price: 189.98, wantPrice: 189.98},
+		{name: "rate unavailable", symbol: "AAPL", currency: "EUR", wantCurrency: "USD", wantWarning: candleshttp.WarningRateUnavailable},
+		{name: "symbol currency unknown", symbol: "NOCCY", currency: "JPY", wantWarning: candleshttp.WarningCurrencyUnknown},
+		{name: "inactive symbol", symbol: "MSFT", currency: "JPY", wantWarning: candleshttp.WarningCurrencyUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := conv.Resolve(context.Background(), tt.symbol, tt.currency)
+			if got.Currency != tt.wantCurrency || got.Warning != tt.wantWarning {
+				t.Fatalf("got currency=%q warning=%q, want currency=%q warning=%q",
+					got.Currency, got.Warning, tt.wantCurrency, tt.wantWarning)
+			}
+			if tt.wantWarning != "" {
+				if got.Convert != nil {
+					t.Error("Convert must be nil on warning")
+				}
+				return
+			}
+			if p := got.Convert(tt.price); p != tt.wantPrice {
+				t.Errorf("Convert(%v): got %v, want %v", tt.price, p, tt.wantPrice)
+			}
+		})
+	}
+}
+
+func TestCurrencyConverter_Supported(t *testing.T) {
+	t.Parallel()
+
+	conv := NewCurrencyConverter(stubStaleSymbolLister{}, rates.NewStaticProvider(nil), []string{"JPY", "USD"})
+	if !conv.Supported("JPY") {
+		t.Error("JPY should be supported")
+	}
+	if conv.Supported("EUR") {
+		t.Error("EUR should not be supported")
+	}
+}
@@ -0,0 +1,133 @@
+package di
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/digest"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/watchlist"
+)
+
+// DigestUserFinder は ID でユーザーを取得します（auth.UserRepository の一部）。
+type DigestUserFinder interface {
+	FindByID(ctx context.Context, id int64) (*auth.User, error)
+}
+
+// digestRecipients は auth のユーザーを digest.RecipientResolver に適合させます。
+type digestRecipients struct {
+	users DigestUserFinder
+}
+
+// NewDigestRecipients はユーザーのメールアドレスを返す digest.RecipientResolver を返します。
+func NewDigestRecipients(users DigestUserFinder) digest.RecipientResolver {
+	return &digestRecipients{users: users}
+}
+
+// EmailOf はユーザー userID のメールアドレスを返します。
+func (r *digestRecipients) EmailOf(ctx context.Context, userID int64) (string, error) {
+	u, err := r.users.FindByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return u.Email, nil
+}
+
+// DigestWatchlistLister はユーザーのウォッチリストを並び順で返します（watchlist.Repository の一部）。
+type DigestWatchlistLister interface {
+	ListByUser(ctx context.Context, userID int64) ([]watchlist.UserSymbol, error)
+}
+
+// digestWatchlist は watchlist のウォッチリストを digest.WatchlistReader に適合させます。
+type digestWatchlist struct {
+	repo DigestWatchlistLister
+}
+
+// NewDigestWatchlist はウォッチリストの銘柄コードを返す digest.WatchlistReader を返します。
+func NewDigestWatchlist(repo DigestWatchlistLister) digest.WatchlistReader {
+	return &digestWatchlist{repo: repo}
+}
+
+// Symbols はユーザー userID のウォッチリストの銘柄コードを並び順で返します。
+func (w *digestWatchlist) Symbols(ctx context.Context, userID int64) ([]string, error) {
+	entries, err := w.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, len(entries))
+	for _, e := range entries {
+		codes = append(codes, e.SymbolCode)
+	}
+	return codes, nil
+}
+
+// DigestCandleFinder は保存済みのローソク足を新しい順に返します（candles.Repository の一部）。
+type DigestCandleFinder interface {
+	Find(ctx context.Context, symbol, interval string, outputsize int) ([]candles.Candle, error)
+}
+
+// DigestSymbolLister はアクティブ銘柄（タイムゾーンを含む）を返します（symbollist のリポジトリの一部）。
+type DigestSymbolLister interface {
+	ListActive(ctx context.Context) ([]symbollist.Symbol, error)
+}
+
+// digestBars は candles の日足を digest.Bar に詰め替えます。
+// 日足の日付を市場のローカル日付として扱えるよう、銘柄のタイムゾーンに変換して返します。
+type digestBars struct {
+	candles DigestCandleFinder
+	symbols DigestSymbolLister
+
+	once    sync.Once
+	locs    map[string]*time.Location
+	locsErr error
+}
+
+// NewDigestBars は銘柄の日足を返す digest.BarReader を返します。
+// 銘柄のタイムゾーンは最初の呼び出しで 1 度だけ読み込みます（1 回の送信ジョブの間は変わらない前提）。
+func NewDigestBars(cs DigestCandleFinder, symbols DigestSymbolLister) digest.BarReader {
+	return &digestBars{candles: cs, symbols: symbols}
+}
+
+// DailyBars は銘柄 symbol の新しい日足を最大 n 本、銘柄のタイムゾーンで返します。
+// アクティブでない（タイムゾーンが分からない）銘柄は UTC のまま返します。
+func (b *digestBars) DailyBars(ctx context.Context, symbol string, n int) ([]digest.Bar, error) {
+	b.once.Do(func() { b.locs, b.locsErr = b.loadLocations(ctx) })
+	if b.locsErr != nil {
+		return nil, b.locsErr
+	}
+	cs, err := b.candles.Find(ctx, symbol, "1day", n)
+	if err != nil {
+		return nil, err
+	}
+	loc, ok := b.locs[symbol]
+	if !ok {
+		loc = time.UTC
+	}
+	out := make([]digest.Bar, 0, len(cs))
+	for _, c := range cs {
+		out = append(out, digest.Bar{Time: c.Time.In(loc), Close: c.Close})
+	}
+	return out, nil
+}
+
+func (b *digestBars) loadLocations(ctx context.Context) (map[string]*time.Location, error) {
+	syms, err := b.symbols.ListActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list active symbols: %w", err)
+	}
+	locs := make(map[string]*time.Location, len(syms))
+	for _, s := range syms {
+		loc, err := time.LoadLocation(s.Timezone)
+		if err != nil {
+			slog.Warn("invalid symbol timezone; using UTC for digest", "symbol", s.Code, "timezone", s.Timezone, "error", err)
+			continue
+		}
+		locs[s.Code] = loc
+	}
+	return locs, nil
+}
@@ -0,0 +1,96 @@
+package di
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/watchlist"
+)
+
+type stubDigestCandles map[string][]candles.Candle
+
+func (s stubDigestCandles) Find(_ context.Context, symbol, interval string, outputsize int) ([]candles.Candle, error) {
+	if interval != "1day" {
+		return nil, nil
+	}
+	cs := s[symbol]
+	if len(cs) > outputsize {
+		cs = cs[:outputsize]
+	}
+	return cs, nil
+}
+
+type stubDigestSymbols struct {
+	syms  []symbollist.Symbol
+	calls int
+}
+
+func (s *stubDigestSymbols) ListActive(context.Context) ([]symbollist.Symbol, error) {
+	s.calls++
+	return s.syms, nil
+}
+
+func TestDigestBars_DailyBars(t *testing.T) {
+	t.Parallel()
+
+	// 東証の 2026-08-05 の日足（JST の 0 時）は UTC では前日の 15 時
+	jstDay := time.Date(2026, 8, 4, 15, 0, 0, 0, time.UTC)
+	cs := stubDigestCandles{
+		"7203.T": {{Time: jstDay, Close: 3030}, {Time: jstDay.AddDate(0, 0, -1), Close: 3000}, {Time: jstDay.AddDate(0, 0, -2), Close: 2990}},
+		"DELIST": {{Time: time.Date(2026, 8, 5, 0, 0, 0, 0, time.UTC), Close: 1}},
+	}
+	syms := &stubDigestSymbols{syms: []symbollist.Symbol{
+		{Code: "7203.T", Timezone: "Asia/Tokyo"},
+		{Code: "BAD", Timezone: "Mars/Olympus"},
+	}}
+	r := NewDigestBars(cs, syms)
+	ctx := context.Background()
+
+	got, err := r.DailyBars(ctx, "7203.T", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("len = %d, want 2", len(got))
+	}
+	if y, m, d := got[0].Time.Date(); y != 2026 || m != time.August || d != 5 {
+		t.Errorf("date = %d-%d-%d, want 2026-8-5 (market local)", y, m, d)
+	}
+	if got[0].Close != 3030 || !got[0].Time.Equal(jstDay) {
+		t.Errorf("bar = %+v", got[0])
+	}
+
+	// アクティブでない銘柄は UTC のまま返す
+	got, err = r.DailyBars(ctx, "DELIST", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Time.Location() != time.UTC {
+		t.Errorf("delisted bars = %+v", got)
+	}
+	if syms.calls != 1 {
+		t.Errorf("ListActive calls = %d, want 1", syms.calls)
+	}
+}
+
+type stubDigestWatchlist []watchlist.UserSymbol
+
+func (s stubDigestWatchlist) ListByUser(context.Context, int64) ([]watchlist.UserSymbol, error) {
+	return s, nil
+}
+
+func TestDigestWatchlist_Symbols(t *testing.T) {
+	t.Parallel()
+	w := NewDigestWatchlist(stubDigestWatchlist{{SymbolCode: "MSFT", SortKey: 1}, {SymbolCode: "AAPL", SortKey: 2}})
+	got, err := w.Symbols(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"MSFT", "AAPL"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Symbols = %v, want %v", got, want)
+	}
+}
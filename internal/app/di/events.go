@@ -0,0 +1,117 @@
+package di
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/candleshttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/twelvedata"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/events"
+)
+
+// eventSourceTwelveData は Twelve Data から取り込んだイベントの Source です。
+const eventSourceTwelveData = "twelvedata"
+
+// EventMarket は Twelve Data の配当・決算の取得インターフェースです（twelvedata.TwelveDataMarket が実装）。
+type EventMarket interface {
+	GetDividends(ctx context.Context, symbol string, from, to time.Time) ([]twelvedata.Dividend, error)
+	GetEarnings(ctx context.Context, symbol string, from, to time.Time) ([]twelvedata.Earning, error)
+}
+
+// eventProvider は Twelve Data の配当・決算を events.Provider に適合させます。
+// twelvedata は candles の外部APIアダプタで events に依存できないため、DI 層で詰め替えます。
+type eventProvider struct {
+	market EventMarket
+}
+
+// NewEventProvider はイベント取り込みに使う events.Provider 実装を返します。
+func NewEventProvider(market EventMarket) events.Provider {
+	return &eventProvider{market: market}
+}
+
+// Fetch は種類に応じて配当（配当額を Value）または決算（EPS の実績を Value、予想を Estimate）を取得します。
+func (p *eventProvider) Fetch(ctx context.Context, symbol string, typ events.Type, from, to time.Time) ([]events.Event, error) {
+	switch typ {
+	case events.TypeDividend:
+		ds, err := p.market.GetDividends(ctx, symbol, from, to)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]events.Event, 0, len(ds))
+		for _, d := range ds {
+			amount := d.Amount
+			out = append(out, events.Event{SymbolCode: symbol, Type: typ, Date: d.ExDate, Value: &amount, Source: eventSourceTwelveData})
+		}
+		return out, nil
+	case events.TypeEarnings:
+		es, err := p.market.GetEarnings(ctx, symbol, from, to)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]events.Event, 0, len(es))
+		for _, e := range es {
+			out = append(out, events.Event{SymbolCode: symbol, Type: typ, Date: e.Date, Value: e.EPSActual, Estimate: e.EPSEstimate, Source: eventSourceTwelveData})
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("unsupported event type %q", typ)
+	}
+}
+
+// eventSymbolAdapter は symbollist のアクティブ銘柄を events.SymbolLister に適合させます。
+type eventSymbolAdapter struct {
+	src SymbolLister
+}
+
+// NewEventSymbolAdapter はイベント取り込みの対象銘柄に使う events.SymbolLister 実装を返します。
+func NewEventSymbolAdapter(src SymbolLister) events.SymbolLister {
+	return &eventSymbolAdapter{src: src}
+}
+
+// ListActiveCodes はアクティブな全銘柄のコードを返します。
+func (a *eventSymbolAdapter) ListActiveCodes(ctx context.Context) ([]string, error) {
+	syms, err := a.src.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, len(syms))
+	for _, s := range syms {
+		codes = append(codes, s.Code)
+	}
+	return codes, nil
+}
+
+// EventLister は銘柄の日付範囲のイベントを返すインターフェースです（events の usecase が実装）。
+type EventLister interface {
+	Between(ctx context.Context, code string, from, to time.Time) ([]events.Event, error)
+}
+
+// candleEventSource は events のイベントを candleshttp.EventSource に適合させます（?with_events=true）。
+type candleEventSource struct {
+	src EventLister
+}
+
+// NewCandleEventSource はローソク足に重ねるイベントの取得元を返します。
+func NewCandleEventSource(src EventLister) candleshttp.EventSource {
+	return &candleEventSource{src: src}
+}
+
+// Between は銘柄の from 〜 to のイベントを candleshttp.Event に詰め替えて返します。
+func (a *candleEventSource) Between(ctx context.Context, symbol string, from, to time.Time) ([]candleshttp.Event, error) {
+	evs, err := a.src.Between(ctx, symbol, from, to)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]candleshttp.Event, 0, len(evs))
+	for _, e := range evs {
+		out = append(out, candleshttp.Event{
+			Type:     string(e.Type),
+			Date:     e.Date,
+			Value:    e.Value,
+			Estimate: e.Estimate,
+			Source:   e.Source,
+		})
+	}
+	return out, nil
+}
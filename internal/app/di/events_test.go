@@ -0,0 +1,56 @@
+package di
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/twelvedata"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/events"
+)
+
+type stubEventMarket struct {
+	dividends []twelvedata.Dividend
+	earnings  []twelvedata.Earning
+}
+
+func (s stubEventMarket) GetDividends(context.Context, string, time.Time, time.Time) ([]twelvedata.Dividend, error) {
+	return s.dividends, nil
+}
+
+func (s stubEventMarket) GetEarnings(context.Context, string, time.Time, time.Time) ([]twelvedata.Earning, error) {
+	return s.earnings, nil
+}
+
+func TestEventProvider_Fetch(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2026, 4, 30, 0, 0, 0, 0, time.UTC)
+	estimate := 1.6
+	p := NewEventProvider(stubEventMarket{
+		dividends: []twelvedata.Dividend{{ExDate: day, Amount: 0.26}},
+		earnings:  []twelvedata.Earning{{Date: day, EPSEstimate: &estimate}},
+	})
+
+	divs, err := p.Fetch(context.Background(), "AAPL", events.TypeDividend, day, day)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(divs) != 1 || divs[0].Type != events.TypeDividend || *divs[0].Value != 0.26 || divs[0].Estimate != nil || divs[0].Source != "twelvedata" {
+		t.Errorf("dividends: got %+v", divs)
+	}
+
+	earnings, err := p.Fetch(context.Background(), "AAPL", events.TypeEarnings, day, day)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []events.Event{{SymbolCode: "AAPL", Type: events.TypeEarnings, Date: day, Estimate: &estimate, Source: "twelvedata"}}
+	if !reflect.DeepEqual(earnings, want) {
+		t.Errorf("earnings: got %+v, want %+v", earnings, want)
+	}
+
+	if _, err := p.Fetch(context.Background(), "AAPL", "split", day, day); err == nil {
+		t.Error("expected error for unsupported type")
+	}
+}
@@ -0,0 +1,166 @@
+package di
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/dataexport"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/recentlyviewed"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/watchlist"
+)
+
+// ExportSections はデータエクスポートのアーカイブに含めるセクションを、アーカイブ内の並び順で返す。
+// dataexport は他フィーチャーに依存できないため、各フィーチャーのリポジトリをここでセクションに適合させる。
+// ユーザーに紐付くデータを新しく保存するフィーチャーを追加した場合は、ここにセクションを追加すること。
+// Redis に保存するデータ（最近閲覧した銘柄）は recentViews から読み出す。
+func ExportSections(db *sql.DB, recentViews recentlyviewed.Reader) []dataexport.Section {
+	return []dataexport.Section{
+		profileSection{users: auth.NewUserRepository(db), accounts: auth.NewOAuthAccountRepository(db)},
+		watchlistSection{entries: watchlist.NewRepository(db)},
+		recentSymbolsSection{views: recentViews},
+	}
+}
+
+type exportUserFinder interface {
+	FindByID(ctx context.Context, id int64) (*auth.User, error)
+}
+
+type exportOAuthAccountLister interface {
+	ListByUser(ctx context.Context, userID int64) ([]auth.OAuthAccount, error)
+}
+
+type exportWatchlistLister interface {
+	ListByUser(ctx context.Context, userID int64) ([]watchlist.UserSymbol, error)
+}
+
+// profileSection はユーザー情報と連携済み OAuth アカウントを profile.json に書き出す。
+// パスワードハッシュは本人のデータであっても出力しない（有無のみ）。
+type profileSection struct {
+	users    exportUserFinder
+	accounts exportOAuthAccountLister
+}
+
+type exportProfile struct {
+	ID            int64                `json:"id"`
+	Email         string               `json:"email"`
+	HasPassword   bool                 `json:"has_password"`
+	CreatedAt     api.Timestamp        `json:"created_at"`
+	UpdatedAt     api.Timestamp        `json:"updated_at"`
+	OAuthAccounts []exportOAuthAccount `json:"oauth_accounts"`
+}
+
+type exportOAuthAccount struct {
+	Provider    string        `json:"provider"`
+	ProviderUID string        `json:"provider_uid"`
+	LinkedAt    api.Timestamp `json:"linked_at"`
+}
+
+func (profileSection) Name() string { return "profile" }
+
+func (s profileSection) Write(ctx context.Context, userID int64, w io.Writer) error {
+	u, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("find user: %w", err)
+	}
+	accounts, err := s.accounts.ListByUser(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("list oauth accounts: %w", err)
+	}
+
+	out := exportProfile{
+		ID:            u.ID,
+		Email:         u.Email,
+		HasPassword:   u.Password != nil,
+		CreatedAt:     api.NewTimestamp(u.CreatedAt),
+		UpdatedAt:     api.NewTimestamp(u.UpdatedAt),
+		OAuthAccounts: make([]exportOAuthAccount, 0, len(accounts)),
+	}
+	for _, a := range accounts {
+		out.OAuthAccounts = append(out.OAuthAccounts, exportOAuthAccount{Provider: a.Provider, ProviderUID: a.ProviderUID, LinkedAt: api.NewTimestamp(a.CreatedAt)})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// watchlistSection はウォッチリストを並び順どおりに watchlist.json（JSON 配列）へ書き出す。
+type watchlistSection struct {
+	entries exportWatchlistLister
+}
+
+type exportWatchlistItem struct {
+	SymbolCode string        `json:"symbol_code"`
+	SortKey    int           `json:"sort_key"`
+	AddedAt    api.Timestamp `json:"added_at"`
+}
+
+func (watchlistSection) Name() string { return "watchlist" }
+
+func (s watchlistSection) Write(ctx context.Context, userID int64, w io.Writer) error {
+	entries, err := s.entries.ListByUser(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("list watchlist: %w", err)
+	}
+	// 要素ごとにエンコードし、配列全体の JSON をメモリ上に組み立てない
+	return writeJSONArray(w, len(entries), func(i int) any {
+		e := entries[i]
+		return exportWatchlistItem{SymbolCode: e.SymbolCode, SortKey: e.SortKey, AddedAt: api.NewTimestamp(e.CreatedAt)}
+	})
+}
+
+// recentSymbolsSection は最近閲覧した銘柄を新しい順に recent_symbols.json（JSON 配列）へ書き出す。
+// 非アクティブ化された銘柄も本人の閲覧記録のため除外しない。
+type recentSymbolsSection struct {
+	views recentlyviewed.Reader
+}
+
+type exportRecentSymbol struct {
+	SymbolCode string        `json:"symbol_code"`
+	ViewedAt   api.Timestamp `json:"viewed_at"`
+}
+
+func (recentSymbolsSection) Name() string { return "recent_symbols" }
+
+func (s recentSymbolsSection) Write(ctx context.Context, userID int64, w io.Writer) error {
+	views, err := s.views.Recent(ctx, userID, recentlyviewed.MaxEntries)
+	if err != nil {
+		return fmt.Errorf("list recently viewed symbols: %w", err)
+	}
+	return writeJSONArray(w, len(views), func(i int) any {
+		return exportRecentSymbol{SymbolCode: views[i].SymbolCode, ViewedAt: api.NewTimestamp(views[i].ViewedAt)}
+	})
+}
+
+// writeJSONArray は n 要素の JSON 配列を 1 行 1 要素で w へ逐次書き込む。
+func writeJSONArray(w io.Writer, n int, item func(i int) any) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	for i := range n {
+		b, err := json.Marshal(item(i))
+		if err != nil {
+			return err
+		}
+		sep := "\n"
+		if i > 0 {
+			sep = ",\n"
+		}
+		if _, err := io.WriteString(w, sep); err != nil {
+			return err
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	end := "]\n"
+	if n > 0 {
+		end = "\n]\n"
+	}
+	_, err := io.WriteString(w, end)
+	return err
+}
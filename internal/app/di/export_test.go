@@ -0,0 +1,171 @@
+package di
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/recentlyviewed"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/watchlist"
+)
+
+// fakeExportStore は userID ごとのデータを保持し、要求された userID のデータのみを返す。
+type fakeExportStore struct {
+	users     map[int64]*auth.User
+	accounts  []auth.OAuthAccount
+	watchlist []watchlist.UserSymbol
+}
+
+func (f *fakeExportStore) FindByID(_ context.Context, id int64) (*auth.User, error) {
+	u, ok := f.users[id]
+	if !ok {
+		return nil, auth.ErrUserNotFound
+	}
+	return u, nil
+}
+
+type fakeAccountLister struct{ *fakeExportStore }
+
+func (f fakeAccountLister) ListByUser(_ context.Context, userID int64) ([]auth.OAuthAccount, error) {
+	var out []auth.OAuthAccount
+	for _, a := range f.accounts {
+		if a.UserID == userID {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+type fakeWatchlistLister struct{ *fakeExportStore }
+
+func (f fakeWatchlistLister) ListByUser(_ context.Context, userID int64) ([]watchlist.UserSymbol, error) {
+	var out []watchlist.UserSymbol
+	for _, e := range f.watchlist {
+		if e.UserID == userID {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func TestExportSections_OnlyRequestedUser(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2026, 10, 1, 18, 0, 0, 123, time.FixedZone("JST", 9*60*60)) // UTC 秒精度に正規化される
+	hash := "$2a$10$secret-hash"
+	store := &fakeExportStore{
+		users: map[int64]*auth.User{
+			1: {ID: 1, Email: "me@example.com", Password: &hash, CreatedAt: at, UpdatedAt: at},
+			2: {ID: 2, Email: "other@example.com", CreatedAt: at, UpdatedAt: at},
+		},
+		accounts: []auth.OAuthAccount{
+			{UserID: 1, Provider: "google", ProviderUID: "sub-1", CreatedAt: at},
+			{UserID: 2, Provider: "github", ProviderUID: "99", CreatedAt: at},
+		},
+		watchlist: []watchlist.UserSymbol{
+			{UserID: 1, SymbolCode: "AAPL", SortKey: 0, CreatedAt: at},
+			{UserID: 2, SymbolCode: "TSLA", SortKey: 0, CreatedAt: at},
+			{UserID: 1, SymbolCode: "7203.T", SortKey: 1, CreatedAt: at},
+		},
+	}
+	profile := profileSection{users: store, accounts: fakeAccountLister{store}}
+	wl := watchlistSection{entries: fakeWatchlistLister{store}}
+
+	var buf bytes.Buffer
+	if err := profile.Write(context.Background(), 1, &buf); err != nil {
+		t.Fatalf("profile: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"created_at": "2026-10-01T09:00:00Z"`) {
+		t.Errorf("timestamps should be UTC RFC 3339, got %s", buf.String())
+	}
+	if strings.Contains(buf.String(), hash) {
+		t.Error("profile.json must not contain the password hash")
+	}
+	var gotProfile exportProfile
+	if err := json.Unmarshal(buf.Bytes(), &gotProfile); err != nil {
+		t.Fatalf("profile json: %v", err)
+	}
+	if gotProfile.Email != "me@example.com" || !gotProfile.HasPassword {
+		t.Errorf("unexpected profile: %+v", gotProfile)
+	}
+	if len(gotProfile.OAuthAccounts) != 1 || gotProfile.OAuthAccounts[0].Provider != "google" {
+		t.Errorf("oauth accounts should contain only user 1's account, got %+v", gotProfile.OAuthAccounts)
+	}
+
+	buf.Reset()
+	if err := wl.Write(context.Background(), 1, &buf); err != nil {
+		t.Fatalf("watchlist: %v", err)
+	}
+	var gotWatchlist []exportWatchlistItem
+	if err := json.Unmarshal(buf.Bytes(), &gotWatchlist); err != nil {
+		t.Fatalf("watchlist json: %v\n%s", err, buf.String())
+	}
+	if len(gotWatchlist) != 2 || gotWatchlist[0].SymbolCode != "AAPL" || gotWatchlist[1].SymbolCode != "7203.T" {
+		t.Errorf("watchlist should contain only user 1's entries in order, got %+v", gotWatchlist)
+	}
+}
+
+// fakeRecentViews は userID ごとの閲覧履歴を返す recentlyviewed.Reader です。
+type fakeRecentViews map[int64][]recentlyviewed.View
+
+func (f fakeRecentViews) Recent(_ context.Context, userID int64, limit int) ([]recentlyviewed.View, error) {
+	views := f[userID]
+	if len(views) > limit {
+		views = views[:limit]
+	}
+	return views, nil
+}
+
+func TestRecentSymbolsSection_OnlyRequestedUser(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	section := recentSymbolsSection{views: fakeRecentViews{
+		1: {{SymbolCode: "AAPL", ViewedAt: at.Add(time.Minute)}, {SymbolCode: "7203.T", ViewedAt: at}},
+		2: {{SymbolCode: "TSLA", ViewedAt: at}},
+	}}
+
+	var buf bytes.Buffer
+	if err := section.Write(context.Background(), 1, &buf); err != nil {
+		t.Fatalf("recent symbols: %v", err)
+	}
+	var got []exportRecentSymbol
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("recent symbols json: %v\n%s", err, buf.String())
+	}
+	if len(got) != 2 || got[0].SymbolCode != "AAPL" || got[1].SymbolCode != "7203.T" {
+		t.Errorf("recent symbols should contain only user 1's views, newest first, got %+v", got)
+	}
+	if !strings.Contains(buf.String(), `"viewed_at":"2026-10-01T09:01:00Z"`) {
+		t.Errorf("timestamps should be UTC RFC 3339, got %s", buf.String())
+	}
+}
+
+func TestWriteJSONArray(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		items []int
+		want  string
+	}{
+		{name: "空配列", items: nil, want: "[]\n"},
+		{name: "1 行 1 要素", items: []int{1, 2}, want: "[\n1,\n2\n]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			if err := writeJSONArray(&buf, len(tt.items), func(i int) any { return tt.items[i] }); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("got %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package di
+
+import (
+	"github.com/redis/go-redis/v9"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist"
+	infraredis "github.com/UCHIDAnobuhiro/stock-backend/internal/infra/redis"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/flags"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/maintenance"
+)
+
+// FlagDefinitions はアプリケーションで定義するフィーチャーフラグの一覧です。
+// フラグ名とデフォルト値は利用側（candles / symbollist / maintenance）の定義に従います。
+func FlagDefinitions() []flags.Definition {
+	return []flags.Definition{
+		{
+			Name:        candles.FlagFetchThrough,
+			Default:     candles.FlagDefault(candles.FlagFetchThrough),
+			Description: "キャッシュミス時に DB から取得したローソク足をキャッシュへ書き込む",
+		},
+		{
+			Name:        candles.FlagWriteThrough,
+			Default:     candles.FlagDefault(candles.FlagWriteThrough),
+			Description: "ingest・CSV 取り込み後に最新データでローソク足キャッシュを再生成する",
+		},
+		{
+			Name:        candles.FlagNegativeCache,
+			Default:     candles.FlagDefault(candles.FlagNegativeCache),
+			Description: "0 件のローソク足結果を短い TTL でキャッシュする",
+		},
+		{
+			Name:        candles.FlagAdjustedDefault,
+			Default:     candles.FlagDefault(candles.FlagAdjustedDefault),
+			Description: "?adjusted= 未指定時に分割調整後のローソク足を返す",
+		},
+		{
+			Name:        symbollist.FlagServeStaleOnError,
+			Default:     symbollist.FlagDefault(symbollist.FlagServeStaleOnError),
+			Description: "DB 障害時に銘柄一覧を last-known-good（最後に取得できた一覧）で応答する",
+		},
+		{
+			Name:        maintenance.FlagName,
+			Default:     false,
+			Description: "メンテナンスモード。書き込みを 503 で拒否し、バッチ・定期ジョブの書き込みを見送る（読み取りは継続）",
+		},
+	}
+}
+
+// NewFlagRegistry はフィーチャーフラグのレジストリを生成します。
+// rdb が nil（Redis 未接続）の場合は環境変数とデフォルト値のみで解決し、実行時の切り替えは無効です。
+func NewFlagRegistry(rdb *redis.Client, keys infraredis.KeyBuilder, overrides map[string]bool) *flags.Registry {
+	var backend flags.Backend
+	if rdb != nil {
+		backend = flags.NewRedisBackend(rdb, keys.Key("flags"))
+	}
+	return flags.NewRegistry(FlagDefinitions(), overrides, backend, flags.DefaultCacheTTL)
+}
@@ -24,7 +24,7 @@ func NewIngestSymbolAdapter(src SymbolLister) candles.SymbolRepository {
 	return &ingestSymbolAdapter{src: src}
 }
 
-// ListActiveSymbols はアクティブな全銘柄をコード+タイムゾーンの組として返します。
+// ListActiveSymbols はアクティブな全銘柄をコード+市場+タイムゾーン+取り込み優先度の組として返します。
 func (a *ingestSymbolAdapter) ListActiveSymbols(ctx context.Context) ([]candles.ActiveSymbol, error) {
 	syms, err := a.src.ListActive(ctx)
 	if err != nil {
@@ -32,7 +32,7 @@ func (a *ingestSymbolAdapter) ListActiveSymbols(ctx context.Context) ([]candles.
 	}
 	out := make([]candles.ActiveSymbol, 0, len(syms))
 	for _, s := range syms {
-		out = append(out, candles.ActiveSymbol{Code: s.Code, Timezone: s.Timezone})
+		out = append(out, candles.ActiveSymbol{Code: s.Code, Market: s.Market, Timezone: s.Timezone, Priority: s.Priority})
 	}
 	return out, nil
 }
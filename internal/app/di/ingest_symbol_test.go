@@ -23,8 +23,8 @@ func TestIngestSymbolAdapter_ListActiveSymbols(t *testing.T) {
 
 	stub := &stubSymbolLister{
 		syms: []symbollist.Symbol{
-			{Code: "AAPL", Timezone: "America/New_York"},
-			{Code: "7203.T", Timezone: "Asia/Tokyo"},
+			{Code: "AAPL", Market: "NASDAQ", Timezone: "America/New_York", Priority: 1},
+			{Code: "7203.T", Market: "TSE", Timezone: "Asia/Tokyo", Priority: 3},
 		},
 	}
 
@@ -34,8 +34,8 @@ func TestIngestSymbolAdapter_ListActiveSymbols(t *testing.T) {
 	}
 
 	want := []candles.ActiveSymbol{
-		{Code: "AAPL", Timezone: "America/New_York"},
-		{Code: "7203.T", Timezone: "Asia/Tokyo"},
+		{Code: "AAPL", Market: "NASDAQ", Timezone: "America/New_York", Priority: 1},
+		{Code: "7203.T", Market: "TSE", Timezone: "Asia/Tokyo", Priority: 3},
 	}
 	if len(got) != len(want) {
 		t.Fatalf("len: got %d, want %d", len(got), len(want))
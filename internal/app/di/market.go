@@ -2,13 +2,30 @@
 package di
 
 import (
+	"log/slog"
+
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/twelvedata"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/httpclient"
 )
 
-// NewMarket は渡された設定で、HTTPクライアント付きの完全に設定された TwelveDataMarket を生成します。
+// NewMarket は渡された設定で、HTTPクライアント付きの完全に設定された TwelveDataMarket と、その後始末の関数を生成します。
+// upstream は接続プール・プロキシの設定で、タイムアウトは cfg.Timeout を使います。
+// HTTPクライアント（接続プール）は返した TwelveDataMarket と WithRequestTimeout で派生させたものの間で共有されます。
+// cfg.Recording が有効ならレスポンスを記録する twelvedata.Recorder を挟み、後始末の関数で書き込み待ちの記録を書き出します
+// （記録の準備に失敗した場合は警告を出して記録なしで続けます）。
 // 設定の読み込み（環境変数）は internal/app/config に集約されています。
-func NewMarket(cfg twelvedata.Config) *twelvedata.TwelveDataMarket {
-	httpClient := httpclient.New(cfg.Timeout)
-	return twelvedata.NewTwelveDataMarket(cfg, httpClient)
+func NewMarket(cfg twelvedata.Config, upstream httpclient.Config) (*twelvedata.TwelveDataMarket, func()) {
+	upstream.Timeout = cfg.Timeout
+	client := httpclient.NewWithConfig(upstream)
+	cleanup := func() {}
+	if cfg.Recording.Enabled() {
+		rec, err := twelvedata.NewRecorder(client.Transport, cfg.Recording)
+		if err != nil {
+			slog.Warn("twelvedata recording disabled", "error", err)
+		} else {
+			client.Transport = rec
+			cleanup = func() { _ = rec.Close() }
+		}
+	}
+	return twelvedata.NewTwelveDataMarket(cfg, client), cleanup
 }
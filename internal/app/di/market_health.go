@@ -0,0 +1,36 @@
+package di
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	infraredis "github.com/UCHIDAnobuhiro/stock-backend/internal/infra/redis"
+)
+
+// NewMarketHealthStore は市場データ連携の状態を共有する Redis ストアを生成します（キー "<namespace>:market:health"）。
+// ingest が書き込み、API サーバーが読み取るため、両方で同じキーを使います。
+func NewMarketHealthStore(rdb *redis.Client, keys infraredis.KeyBuilder) *candles.MarketHealthStore {
+	return candles.NewMarketHealthStore(rdb, keys.Key("market", "health"))
+}
+
+// NotifyMarketHealthChange は市場データ連携の状態の遷移をログで通知します。
+// down への遷移は ERROR、healthy への回復は INFO、それ以外（degraded への遷移・unknown への失効）は WARN です。
+func NotifyMarketHealthChange(ctx context.Context, c candles.MarketHealthChange) {
+	level := slog.LevelWarn
+	switch c.To {
+	case candles.MarketHealthDown:
+		level = slog.LevelError
+	case candles.MarketHealthHealthy:
+		level = slog.LevelInfo
+	}
+	slog.Log(ctx, level, "market data integration health changed",
+		"from", c.From,
+		"to", c.To,
+		"success", c.Counts.Success,
+		"throttled", c.Counts.Throttled,
+		"errors", c.Counts.Errors,
+	)
+}
@@ -3,13 +3,14 @@ package di
 import (
 	"database/sql"
 	"fmt"
-	"net/http"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth/authhttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/httpclient"
+	infraredis "github.com/UCHIDAnobuhiro/stock-backend/internal/infra/redis"
 )
 
 // oauthHTTPTimeout は OAuth プロバイダ（Google/GitHub）への HTTP 呼び出しに用いるタイムアウト。
@@ -42,6 +43,7 @@ func NewOAuthHandler(
 	cfg *OAuthConfig,
 	db *sql.DB,
 	rdb *redis.Client,
+	keys infraredis.KeyBuilder,
 	userStore OAuthUserStore,
 	jwtGen auth.JWTGenerator,
 	onUserCreated auth.UserCreatedHook,
@@ -51,7 +53,7 @@ func NewOAuthHandler(
 		return nil, fmt.Errorf("OAuth requires Redis but Redis is unavailable")
 	}
 
-	hc := &http.Client{Timeout: oauthHTTPTimeout}
+	hc := httpclient.New(oauthHTTPTimeout)
 	providers := map[string]auth.OAuthProvider{}
 	if cfg.Google != nil {
 		providers["google"] = auth.NewGoogleProvider(
@@ -74,7 +76,7 @@ func NewOAuthHandler(
 		userStore,
 		auth.NewOAuthAccountRepository(db),
 		userStore,
-		auth.NewRedisOAuthStateStore(rdb),
+		auth.NewRedisOAuthStateStore(rdb, keys.Key("oauth", "state")),
 		jwtGen,
 		providers,
 		onUserCreated,
@@ -4,10 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth"
+	infraredis "github.com/UCHIDAnobuhiro/stock-backend/internal/infra/redis"
 )
 
 // stubOAuthUserStore は OAuthUserStore（UserRepository + OAuthUserCreator）の最小実装。
@@ -20,6 +22,8 @@ func (s *stubOAuthUserStore) FindByEmail(ctx context.Context, email string) (*au
 func (s *stubOAuthUserStore) FindByID(ctx context.Context, id int64) (*auth.User, error) {
 	return nil, nil
 }
+func (s *stubOAuthUserStore) RecordLogin(ctx context.Context, id int64) error { return nil }
+func (s *stubOAuthUserStore) Delete(ctx context.Context, id int64) error      { return nil }
 func (s *stubOAuthUserStore) CreateUserWithOAuthAccount(ctx context.Context, user *auth.User, account *auth.OAuthAccount) error {
 	return nil
 }
@@ -31,6 +35,8 @@ func (s *stubJWTGenerator) GenerateToken(userID int64, email string) (string, er
 	return "", nil
 }
 
+func (s *stubJWTGenerator) ExpiresIn() time.Duration { return time.Hour }
+
 // stubUserCreatedHook は auth.UserCreatedHook の最小実装。
 type stubUserCreatedHook struct{}
 
@@ -44,7 +50,7 @@ func TestNewOAuthHandler_RequiresRedis(t *testing.T) {
 		Google:      &ProviderCredentials{ClientID: "id", ClientSecret: "secret", RedirectURL: "http://localhost/cb"},
 	}
 
-	h, err := NewOAuthHandler(cfg, nil, nil, &stubOAuthUserStore{}, &stubJWTGenerator{}, &stubUserCreatedHook{}, false)
+	h, err := NewOAuthHandler(cfg, nil, nil, infraredis.KeyBuilder{}, &stubOAuthUserStore{}, &stubJWTGenerator{}, &stubUserCreatedHook{}, false)
 	if err == nil {
 		t.Fatal("expected error when Redis is unavailable, got nil")
 	}
@@ -68,7 +74,7 @@ func TestNewOAuthHandler_BuildsHandler(t *testing.T) {
 	rdb := redis.NewClient(&redis.Options{})
 	t.Cleanup(func() { _ = rdb.Close() })
 
-	h, err := NewOAuthHandler(cfg, db, rdb, &stubOAuthUserStore{}, &stubJWTGenerator{}, &stubUserCreatedHook{}, true)
+	h, err := NewOAuthHandler(cfg, db, rdb, infraredis.KeyBuilder{}, &stubOAuthUserStore{}, &stubJWTGenerator{}, &stubUserCreatedHook{}, true)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
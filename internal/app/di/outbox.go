@@ -0,0 +1,95 @@
+package di
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/outbox"
+)
+
+// TopicCandlesUpserted は ingest が保存した銘柄ごとの足のイベントの Topic です。
+const TopicCandlesUpserted = "candles.upserted"
+
+// candlesUpsertedEvent は TopicCandlesUpserted のペイロードです。
+// candles.Candle に JSON の形を持たせないよう、DI 層で詰め替えて保存します。
+type candlesUpsertedEvent struct {
+	Symbol string        `json:"symbol"`
+	Bars   []upsertedBar `json:"bars"`
+}
+
+type upsertedBar struct {
+	Interval string    `json:"interval"`
+	Time     time.Time `json:"time"`
+	Open     float64   `json:"open"`
+	High     float64   `json:"high"`
+	Low      float64   `json:"low"`
+	Close    float64   `json:"close"`
+	Volume   int64     `json:"volume"`
+}
+
+// CandleOutbox は candles の保存に伴うイベントを outbox に登録する candles.UpsertEventWriter です。
+// 同じ銘柄のイベントは登録順に配信されるよう、Key を銘柄ごとにします。
+type CandleOutbox struct{}
+
+var _ candles.UpsertEventWriter = CandleOutbox{}
+
+// NewCandleOutbox は CandleOutbox を返します。
+func NewCandleOutbox() CandleOutbox {
+	return CandleOutbox{}
+}
+
+// CandlesUpserted は保存した足を TopicCandlesUpserted のイベントとして tx に登録します。
+func (CandleOutbox) CandlesUpserted(ctx context.Context, tx *sql.Tx, symbol string, cs []candles.Candle) error {
+	ev := candlesUpsertedEvent{Symbol: symbol, Bars: make([]upsertedBar, len(cs))}
+	for i, c := range cs {
+		ev.Bars[i] = upsertedBar{Interval: c.Interval, Time: c.Time, Open: c.Open, High: c.High, Low: c.Low, Close: c.Close, Volume: c.Volume}
+	}
+	return outbox.Enqueue(ctx, tx, outbox.Message{Topic: TopicCandlesUpserted, Key: "candles:" + symbol, Payload: ev})
+}
+
+// CandleCacheInvalidator は銘柄・時間間隔のローソク足キャッシュを破棄します（candles.CachingRepository が実装）。
+type CandleCacheInvalidator interface {
+	Invalidate(ctx context.Context, symbol, interval string) error
+}
+
+// NewCandlesUpsertedHandler は TopicCandlesUpserted のイベントで、保存した時間間隔のキャッシュを破棄してから
+// observer（アラートの評価・リアルタイム配信）に通知する outbox.Handler を返します。
+// 失敗は Relay が再試行するため、observer は同じ足を複数回受け取っても結果が変わらないようにします
+// （アラートは発火を記録した後は通知し直さない）。
+func NewCandlesUpsertedHandler(cache CandleCacheInvalidator, observer candles.IngestObserver) outbox.Handler {
+	return func(ctx context.Context, e outbox.Event) error {
+		var ev candlesUpsertedEvent
+		if err := e.Decode(&ev); err != nil {
+			return err
+		}
+		if ev.Symbol == "" {
+			return fmt.Errorf("%w: %s event without symbol", outbox.ErrPermanent, e.Topic)
+		}
+
+		cs := make([]candles.Candle, len(ev.Bars))
+		var intervals []string
+		seen := make(map[string]struct{})
+		for i, b := range ev.Bars {
+			cs[i] = candles.Candle{SymbolCode: ev.Symbol, Interval: b.Interval, Time: b.Time, Open: b.Open, High: b.High, Low: b.Low, Close: b.Close, Volume: b.Volume}
+			if _, ok := seen[b.Interval]; !ok {
+				seen[b.Interval] = struct{}{}
+				intervals = append(intervals, b.Interval)
+			}
+		}
+
+		var errs []error
+		for _, interval := range intervals {
+			if err := cache.Invalidate(ctx, ev.Symbol, interval); err != nil {
+				errs = append(errs, fmt.Errorf("invalidate %s/%s: %w", ev.Symbol, interval, err))
+			}
+		}
+		if err := observer.CandlesIngested(ctx, ev.Symbol, cs); err != nil {
+			errs = append(errs, err)
+		}
+		return errors.Join(errs...)
+	}
+}
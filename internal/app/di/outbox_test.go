@@ -0,0 +1,89 @@
+package di
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/outbox"
+)
+
+type stubCandleCache struct {
+	invalidated []string
+	err         error
+}
+
+func (c *stubCandleCache) Invalidate(_ context.Context, symbol, interval string) error {
+	c.invalidated = append(c.invalidated, symbol+"/"+interval)
+	return c.err
+}
+
+type recordingObserver struct {
+	symbol  string
+	candles []candles.Candle
+	err     error
+}
+
+func (o *recordingObserver) CandlesIngested(_ context.Context, symbol string, cs []candles.Candle) error {
+	o.symbol, o.candles = symbol, cs
+	return o.err
+}
+
+func upsertedEvent(t *testing.T, ev candlesUpsertedEvent) outbox.Event {
+	t.Helper()
+	payload, err := json.Marshal(ev)
+	require.NoError(t, err)
+	return outbox.Event{ID: 1, Topic: TopicCandlesUpserted, Key: "candles:" + ev.Symbol, Payload: payload, Attempts: 1}
+}
+
+// TestCandlesUpsertedHandler は保存した時間間隔のキャッシュを破棄し、足を observer に渡すことを検証します。
+func TestCandlesUpsertedHandler(t *testing.T) {
+	t.Parallel()
+	day := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	cache := &stubCandleCache{}
+	obs := &recordingObserver{}
+
+	err := NewCandlesUpsertedHandler(cache, obs)(context.Background(), upsertedEvent(t, candlesUpsertedEvent{
+		Symbol: "AAPL",
+		Bars: []upsertedBar{
+			{Interval: "1day", Time: day.AddDate(0, 0, -1), Close: 99},
+			{Interval: "1day", Time: day, Open: 100, High: 102, Low: 99, Close: 101, Volume: 1000},
+			{Interval: "1week", Time: day.AddDate(0, 0, -2), Close: 101},
+		},
+	}))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"AAPL/1day", "AAPL/1week"}, cache.invalidated)
+	assert.Equal(t, "AAPL", obs.symbol)
+	require.Len(t, obs.candles, 3)
+	assert.Equal(t, candles.Candle{SymbolCode: "AAPL", Interval: "1day", Time: day, Open: 100, High: 102, Low: 99, Close: 101, Volume: 1000}, obs.candles[1])
+}
+
+// TestCandlesUpsertedHandler_Errors は一時的な失敗は再試行に、解釈できないイベントは dead にすることを検証します。
+func TestCandlesUpsertedHandler_Errors(t *testing.T) {
+	t.Parallel()
+	ev := candlesUpsertedEvent{Symbol: "AAPL", Bars: []upsertedBar{{Interval: "1day", Time: time.Now(), Close: 1}}}
+
+	// キャッシュの破棄に失敗しても observer には通知し、失敗をまとめて返す（再試行）
+	cache := &stubCandleCache{err: errors.New("redis down")}
+	obs := &recordingObserver{err: errors.New("evaluate failed")}
+	err := NewCandlesUpsertedHandler(cache, obs)(context.Background(), upsertedEvent(t, ev))
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "redis down")
+	assert.ErrorContains(t, err, "evaluate failed")
+	assert.NotErrorIs(t, err, outbox.ErrPermanent)
+	assert.Equal(t, "AAPL", obs.symbol)
+
+	// ペイロードが壊れている・銘柄がないイベントは再試行しても成功しない
+	h := NewCandlesUpsertedHandler(&stubCandleCache{}, &recordingObserver{})
+	err = h(context.Background(), outbox.Event{Topic: TopicCandlesUpserted, Payload: []byte(`{`)})
+	assert.ErrorIs(t, err, outbox.ErrPermanent)
+	err = h(context.Background(), upsertedEvent(t, candlesUpsertedEvent{}))
+	assert.ErrorIs(t, err, outbox.ErrPermanent)
+}
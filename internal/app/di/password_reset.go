@@ -0,0 +1,23 @@
+package di
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/logging"
+)
+
+// LogResetSender はリセットトークンをメール送信せずログに出力する auth.PasswordResetSender です。
+// メール配信基盤が未整備の間の暫定実装で、トークンは Debug レベルでのみ出力します
+// （LOG_LEVEL=debug のローカル開発でのみ再設定フローを試せる）。メール送信の実装に差し替えること。
+type LogResetSender struct{}
+
+var _ auth.PasswordResetSender = LogResetSender{}
+
+// SendPasswordReset はリセットトークンの発行をログに記録します。
+func (LogResetSender) SendPasswordReset(ctx context.Context, email, token string) error {
+	slog.WarnContext(ctx, "password reset mail delivery is not configured; token logged at debug level only", "email_hash", logging.HashedEmail(email))
+	slog.DebugContext(ctx, "password reset token issued", "email_hash", logging.HashedEmail(email), "token", token)
+	return nil
+}
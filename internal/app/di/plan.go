@@ -0,0 +1,60 @@
+package di
+
+import (
+	"context"
+	"errors"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/candleshttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist"
+)
+
+// SymbolTierReader は銘柄の区分の取得インターフェースです。symbollist.ActiveCodeSet が実装します。
+type SymbolTierReader interface {
+	Tier(ctx context.Context, code string) (symbollist.Tier, error)
+}
+
+// candleSymbolTiers は symbollist の銘柄の区分を candles.SymbolTierSource に適合させます。
+// feature 同士の直接依存を避けるため DI 層で変換を行います。
+type candleSymbolTiers struct {
+	src SymbolTierReader
+}
+
+// NewCandleSymbolTiers はプランによる参照の制限に使う SymbolTierSource 実装を返します。
+// 区分は銘柄の存在チェックと同じ ActiveCodeSet から引くため、リクエストごとの DB 問い合わせは増えません。
+func NewCandleSymbolTiers(src SymbolTierReader) candles.SymbolTierSource {
+	return &candleSymbolTiers{src: src}
+}
+
+// SymbolTier は銘柄 code の区分を返します。参照できない銘柄の場合は空文字です（制限しません）。
+func (a *candleSymbolTiers) SymbolTier(ctx context.Context, code string) (candles.Tier, error) {
+	t, err := a.src.Tier(ctx, code)
+	return candles.Tier(t), err
+}
+
+// candleUserPlans は auth のユーザーの料金プランを candleshttp.PlanSource に適合させます。
+type candleUserPlans struct {
+	users auth.UserLoader
+}
+
+// NewCandleUserPlans は /candles・/stats の参照の制限に使う PlanSource 実装を返します。
+// users には auth.CachingUserRepository を渡し、リクエストごとの DB 問い合わせを避けます。
+func NewCandleUserPlans(users auth.UserLoader) candleshttp.PlanSource {
+	return &candleUserPlans{users: users}
+}
+
+// UserPlan は userID のユーザーの料金プランを返します。ユーザーが存在しない場合は free として扱います。
+func (a *candleUserPlans) UserPlan(ctx context.Context, userID int64) (candles.Plan, error) {
+	u, err := a.users.Load(ctx, userID)
+	if errors.Is(err, auth.ErrUserNotFound) {
+		return candles.PlanFree, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if u.Plan == auth.PlanPremium {
+		return candles.PlanPremium, nil
+	}
+	return candles.PlanFree, nil
+}
@@ -0,0 +1,83 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist"
+)
+
+type stubTierReader map[string]symbollist.Tier
+
+func (s stubTierReader) Tier(_ context.Context, code string) (symbollist.Tier, error) {
+	return s[code], nil
+}
+
+func TestCandleSymbolTiers(t *testing.T) {
+	t.Parallel()
+
+	src := NewCandleSymbolTiers(stubTierReader{"AAPL": symbollist.TierBasic, "NVDA": symbollist.TierPremium})
+	tests := []struct {
+		code string
+		want candles.Tier
+	}{
+		{"AAPL", candles.TierBasic},
+		{"NVDA", candles.TierPremium},
+		{"UNKNOWN", ""},
+	}
+	for _, tt := range tests {
+		got, err := src.SymbolTier(context.Background(), tt.code)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.code, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: tier = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
+
+type stubUserLoader map[int64]*auth.User
+
+func (s stubUserLoader) Load(_ context.Context, id int64) (*auth.User, error) {
+	if id == 99 {
+		return nil, errors.New("db down")
+	}
+	u, ok := s[id]
+	if !ok {
+		return nil, auth.ErrUserNotFound
+	}
+	return u, nil
+}
+
+func TestCandleUserPlans(t *testing.T) {
+	t.Parallel()
+
+	src := NewCandleUserPlans(stubUserLoader{
+		1: {ID: 1, Plan: auth.PlanPremium},
+		2: {ID: 2, Plan: auth.PlanFree},
+		3: {ID: 3}, // プラン未設定（古いキャッシュ）は free
+	})
+	tests := []struct {
+		id      int64
+		want    candles.Plan
+		wantErr bool
+	}{
+		{1, candles.PlanPremium, false},
+		{2, candles.PlanFree, false},
+		{3, candles.PlanFree, false},
+		{4, candles.PlanFree, false}, // 削除済みのユーザーは free
+		{99, "", true},
+	}
+	for _, tt := range tests {
+		got, err := src.UserPlan(context.Background(), tt.id)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%d: err = %v, wantErr %v", tt.id, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("%d: plan = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package di
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/alerts"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/push"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/push/fcm"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/httpclient"
+)
+
+// NewPushDispatcher はプッシュ通知のディスパッチャーを組み立てます。
+// credentialsFile（FCM のサービスアカウントの JSON 鍵）が空の場合は、送信せずにログへ出力する push.LogSender を使います。
+// 配信結果は alerts のリポジトリでアラートに記録します。
+func NewPushDispatcher(ctx context.Context, sqlDB *sql.DB, credentialsFile string, cfg push.DispatcherConfig) (*push.Dispatcher, error) {
+	var sender push.Sender = push.LogSender{}
+	if credentialsFile != "" {
+		key, err := os.ReadFile(credentialsFile)
+		if err != nil {
+			return nil, fmt.Errorf("read fcm credentials: %w", err)
+		}
+		// 1 件の送信の上限は Dispatcher の SendTimeout で決まるため、クライアント側ではタイムアウトを設けない
+		s, err := fcm.NewSender(ctx, key, httpclient.New(0))
+		if err != nil {
+			return nil, err
+		}
+		sender = s
+	} else {
+		slog.Info("PUSH_FCM_CREDENTIALS_FILE not set, push notifications are logged instead of sent")
+	}
+	return push.NewDispatcher(push.NewRepository(sqlDB), sender, alerts.NewRepository(sqlDB), cfg), nil
+}
+
+// PushEnqueuer は通知をユーザーの端末ごとの送信待ちとして登録する push のリポジトリの操作です。
+type PushEnqueuer interface {
+	Enqueue(ctx context.Context, n push.Notification) (int, error)
+}
+
+// PushAlertNotifier は発火したアラートをプッシュ通知の送信待ちとして登録する alerts.Notifier です。
+// 送信は API プロセスの push.Dispatcher が行います。feature 同士の直接依存を避けるため DI 層で
+// alerts.Trigger を push.Notification へ詰め替えます。
+type PushAlertNotifier struct {
+	queue PushEnqueuer
+}
+
+var _ alerts.Notifier = (*PushAlertNotifier)(nil)
+
+// NewPushAlertNotifier は PushAlertNotifier を生成します。
+func NewPushAlertNotifier(queue PushEnqueuer) *PushAlertNotifier {
+	return &PushAlertNotifier{queue: queue}
+}
+
+// Enqueue は発火したアラートを 1 件ずつ登録します。失敗したアラートがあっても残りは登録し、エラーをまとめて返します。
+// 端末を登録していないユーザーのアラートは何も登録しません。
+func (n *PushAlertNotifier) Enqueue(ctx context.Context, triggers []alerts.Trigger) error {
+	var errs []error
+	for _, t := range triggers {
+		if _, err := n.queue.Enqueue(ctx, alertNotification(t)); err != nil {
+			errs = append(errs, fmt.Errorf("enqueue push for alert %d: %w", t.Alert.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// alertNotification は発火したアラートの通知の文面と、アプリが画面遷移に使う data を組み立てます。
+func alertNotification(t alerts.Trigger) push.Notification {
+	a := t.Alert
+	verb := "を上回りました"
+	if a.Direction == alerts.DirectionBelow {
+		verb = "を下回りました"
+	}
+	price := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	return push.Notification{
+		AlertID: a.ID,
+		UserID:  a.UserID,
+		Title:   fmt.Sprintf("%s の価格アラート", a.SymbolCode),
+		Body:    fmt.Sprintf("終値 %s が %s %s（%s）", price(t.Close), price(a.Threshold), verb, a.Interval),
+		Data: map[string]string{
+			"type":      "alert.triggered",
+			"alert_id":  strconv.FormatInt(a.ID, 10),
+			"symbol":    a.SymbolCode,
+			"interval":  a.Interval,
+			"direction": string(a.Direction),
+			"threshold": price(a.Threshold),
+			"close":     price(t.Close),
+			"bar_time":  t.BarTime.UTC().Format(time.RFC3339),
+		},
+	}
+}
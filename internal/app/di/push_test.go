@@ -0,0 +1,69 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/alerts"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/push"
+)
+
+type stubPushEnqueuer struct {
+	got  []push.Notification
+	fail map[int64]bool
+}
+
+func (s *stubPushEnqueuer) Enqueue(_ context.Context, n push.Notification) (int, error) {
+	if s.fail[n.AlertID] {
+		return 0, errors.New("db down")
+	}
+	s.got = append(s.got, n)
+	return 1, nil
+}
+
+func TestPushAlertNotifier_Enqueue(t *testing.T) {
+	t.Parallel()
+
+	bar := time.Date(2026, 7, 31, 0, 0, 0, 0, time.UTC)
+	triggers := []alerts.Trigger{
+		{Alert: alerts.Alert{ID: 9, UserID: 3, SymbolCode: "AAPL", Interval: "1day", Direction: alerts.DirectionAbove, Threshold: 200}, BarTime: bar, PrevClose: 199, Close: 201.3},
+		{Alert: alerts.Alert{ID: 10, UserID: 3, SymbolCode: "7203.T", Interval: "1week", Direction: alerts.DirectionBelow, Threshold: 2500}, BarTime: bar, Close: 2480},
+		{Alert: alerts.Alert{ID: 11, UserID: 4, SymbolCode: "MSFT", Interval: "1day", Direction: alerts.DirectionAbove, Threshold: 1}, BarTime: bar, Close: 2},
+	}
+	stub := &stubPushEnqueuer{fail: map[int64]bool{11: true}}
+
+	err := NewPushAlertNotifier(stub).Enqueue(context.Background(), triggers)
+	if err == nil {
+		t.Fatal("expected error for the failed alert, got nil")
+	}
+	// 失敗したアラートがあっても残りは登録する
+	if len(stub.got) != 2 {
+		t.Fatalf("enqueued %d notifications, want 2", len(stub.got))
+	}
+
+	want := push.Notification{
+		AlertID: 9,
+		UserID:  3,
+		Title:   "AAPL の価格アラート",
+		Body:    "終値 201.3 が 200 を上回りました（1day）",
+		Data: map[string]string{
+			"type":      "alert.triggered",
+			"alert_id":  "9",
+			"symbol":    "AAPL",
+			"interval":  "1day",
+			"direction": "above",
+			"threshold": "200",
+			"close":     "201.3",
+			"bar_time":  "2026-07-31T00:00:00Z",
+		},
+	}
+	if !reflect.DeepEqual(stub.got[0], want) {
+		t.Errorf("notification = %+v, want %+v", stub.got[0], want)
+	}
+	if got := stub.got[1].Body; got != "終値 2480 が 2500 を下回りました（1week）" {
+		t.Errorf("body = %q", got)
+	}
+}
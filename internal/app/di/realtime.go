@@ -0,0 +1,161 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/alerts"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/realtime"
+)
+
+// RealtimePublisher はイベントをリアルタイム配信に発行します（realtime.RedisPublisher が実装）。
+type RealtimePublisher interface {
+	Publish(ctx context.Context, ev realtime.Event) error
+}
+
+// IngestObservers は複数の candles.IngestObserver に順に通知します。
+// 1 つが失敗しても残りには通知し、失敗をまとめて返します。
+type IngestObservers []candles.IngestObserver
+
+// CandlesIngested は全ての observer に通知します。
+func (os IngestObservers) CandlesIngested(ctx context.Context, symbol string, cs []candles.Candle) error {
+	var errs []error
+	for _, o := range os {
+		if err := o.CandlesIngested(ctx, symbol, cs); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// realtimeIngestObserver は保存した足のうち時間間隔ごとの最新の足を candle.updated として発行します。
+type realtimeIngestObserver struct {
+	pub RealtimePublisher
+}
+
+// NewRealtimeIngestObserver は取り込んだ足を WebSocket の購読者へ知らせる candles.IngestObserver を返します。
+func NewRealtimeIngestObserver(pub RealtimePublisher) candles.IngestObserver {
+	return &realtimeIngestObserver{pub: pub}
+}
+
+// CandlesIngested は時間間隔ごとに最新の足を 1 件ずつ発行します（再取り込みした過去の足は知らせない）。
+func (o *realtimeIngestObserver) CandlesIngested(ctx context.Context, symbol string, cs []candles.Candle) error {
+	latest := make(map[string]candles.Candle)
+	var order []string
+	for _, c := range cs {
+		cur, ok := latest[c.Interval]
+		if !ok {
+			order = append(order, c.Interval)
+		}
+		if !ok || c.Time.After(cur.Time) {
+			latest[c.Interval] = c
+		}
+	}
+	var errs []error
+	for _, interval := range order {
+		c := latest[interval]
+		ev, err := realtime.NewCandleUpdated(symbol, interval, realtime.CandleData{
+			Time: c.Time, Open: c.Open, High: c.High, Low: c.Low, Close: c.Close, Volume: c.Volume,
+		})
+		if err == nil {
+			err = o.pub.Publish(ctx, ev)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("publish %s/%s: %w", symbol, interval, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LocalCandleCache はプロセス内のローソク足キャッシュを無効化します（candles.CachingRepository が実装）。
+type LocalCandleCache interface {
+	InvalidateLocal(symbol, interval string)
+}
+
+// NewCandleCacheInvalidator は candle.updated を受け取ったら該当する銘柄・時間間隔のプロセス内キャッシュを削除する
+// realtime.RedisSubscriber のリスナーを返します（batch の取り込みを API の各インスタンスへ反映する）。
+func NewCandleCacheInvalidator(cache LocalCandleCache) func(realtime.Event) {
+	return func(ev realtime.Event) {
+		if ev.Type == realtime.EventCandleUpdated {
+			cache.InvalidateLocal(ev.Symbol, ev.Interval)
+		}
+	}
+}
+
+// RealtimeAlertNotifier は発火したアラートを alert.triggered として宛先ユーザーの WebSocket 接続へ発行し、
+// next（ログ出力等）にも渡す alerts.Notifier です。
+// 分割の調整で閾値を書き換えたアラートも alert.adjusted として発行します（alerts.MigrationNotifier。next には渡しません）。
+type RealtimeAlertNotifier struct {
+	pub  RealtimePublisher
+	next alerts.Notifier
+}
+
+var (
+	_ alerts.Notifier          = (*RealtimeAlertNotifier)(nil)
+	_ alerts.MigrationNotifier = (*RealtimeAlertNotifier)(nil)
+)
+
+// NewRealtimeAlertNotifier は RealtimeAlertNotifier を生成します。next が nil の場合は発行のみ行います。
+func NewRealtimeAlertNotifier(pub RealtimePublisher, next alerts.Notifier) *RealtimeAlertNotifier {
+	return &RealtimeAlertNotifier{pub: pub, next: next}
+}
+
+// Enqueue は next に渡したうえで、発火したアラートを 1 件ずつ発行します。
+// 発行の失敗（Redis 障害等）は next への通知を妨げず、まとめて返します。
+func (n *RealtimeAlertNotifier) Enqueue(ctx context.Context, triggers []alerts.Trigger) error {
+	var errs []error
+	if n.next != nil {
+		if err := n.next.Enqueue(ctx, triggers); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, t := range triggers {
+		ev, err := realtime.NewAlertTriggered(t.Alert.UserID, t.Alert.SymbolCode, t.Alert.Interval, realtime.AlertData{
+			AlertID:     t.Alert.ID,
+			Direction:   string(t.Alert.Direction),
+			Threshold:   t.Alert.Threshold,
+			Close:       t.Close,
+			BarTime:     t.BarTime,
+			TriggeredAt: t.TriggeredAt,
+		})
+		if err == nil {
+			err = n.pub.Publish(ctx, ev)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("publish alert %d: %w", t.Alert.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// EnqueueMigrated は閾値を書き換えたアラートを 1 件ずつ alert.adjusted として発行します。
+// 発行の失敗は残りの発行を妨げず、まとめて返します。
+func (n *RealtimeAlertNotifier) EnqueueMigrated(ctx context.Context, migrations []alerts.Migration) error {
+	var errs []error
+	for _, m := range migrations {
+		a := m.Alert
+		original := m.PreviousThreshold
+		if a.OriginalThreshold != nil {
+			original = *a.OriginalThreshold
+		}
+		ev, err := realtime.NewAlertAdjusted(a.UserID, a.SymbolCode, a.Interval, realtime.AlertAdjustedData{
+			AlertID:           a.ID,
+			Direction:         string(a.Direction),
+			Threshold:         a.Threshold,
+			PreviousThreshold: m.PreviousThreshold,
+			OriginalThreshold: original,
+			AdjustmentID:      m.Split.AdjustmentID,
+			Factor:            m.Split.Factor,
+			EffectiveDate:     m.Split.EffectiveDate,
+		})
+		if err == nil {
+			err = n.pub.Publish(ctx, ev)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("publish alert adjustment %d: %w", a.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
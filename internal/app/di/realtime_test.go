@@ -0,0 +1,128 @@
+package di
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/alerts"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/realtime"
+)
+
+type stubPublisher struct {
+	events []realtime.Event
+	err    error
+}
+
+func (p *stubPublisher) Publish(_ context.Context, ev realtime.Event) error {
+	p.events = append(p.events, ev)
+	return p.err
+}
+
+type stubNotifier struct{ triggers []alerts.Trigger }
+
+func (n *stubNotifier) Enqueue(_ context.Context, triggers []alerts.Trigger) error {
+	n.triggers = append(n.triggers, triggers...)
+	return nil
+}
+
+// TestRealtimeIngestObserver は時間間隔ごとに最新の足だけを candle.updated として発行することを検証します。
+func TestRealtimeIngestObserver(t *testing.T) {
+	t.Parallel()
+	day := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	pub := &stubPublisher{}
+	err := NewRealtimeIngestObserver(pub).CandlesIngested(context.Background(), "AAPL", []candles.Candle{
+		{Interval: "1day", Time: day.AddDate(0, 0, 1), Close: 110},
+		{Interval: "1day", Time: day, Close: 100},
+		{Interval: "1week", Time: day.AddDate(0, 0, -2), Close: 105},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, pub.events, 2)
+	assert.Equal(t, realtime.EventCandleUpdated, pub.events[0].Type)
+	assert.Equal(t, "1day", pub.events[0].Interval)
+	var c realtime.CandleData
+	require.NoError(t, json.Unmarshal(pub.events[0].Data, &c))
+	assert.Equal(t, 110.0, c.Close)
+	assert.Equal(t, "1week", pub.events[1].Interval)
+}
+
+func TestRealtimeAlertNotifier(t *testing.T) {
+	t.Parallel()
+	next := &stubNotifier{}
+	pub := &stubPublisher{err: errors.New("redis down")}
+	triggers := []alerts.Trigger{{
+		Alert: alerts.Alert{ID: 3, UserID: 7, SymbolCode: "AAPL", Interval: "1day", Direction: alerts.DirectionAbove, Threshold: 100},
+		Close: 101,
+	}}
+
+	err := NewRealtimeAlertNotifier(pub, next).Enqueue(context.Background(), triggers)
+	assert.Error(t, err, "発行の失敗は返す")
+	assert.Equal(t, triggers, next.triggers, "発行に失敗しても next には渡す")
+	require.Len(t, pub.events, 1)
+	assert.Equal(t, realtime.EventAlertTriggered, pub.events[0].Type)
+	assert.Equal(t, int64(7), pub.events[0].UserID)
+}
+
+type stubLocalCandleCache struct{ invalidated []string }
+
+func (c *stubLocalCandleCache) InvalidateLocal(symbol, interval string) {
+	c.invalidated = append(c.invalidated, symbol+"/"+interval)
+}
+
+// TestCandleCacheInvalidator は candle.updated だけでプロセス内キャッシュを無効化することを検証します。
+func TestCandleCacheInvalidator(t *testing.T) {
+	t.Parallel()
+	cache := &stubLocalCandleCache{}
+	listen := NewCandleCacheInvalidator(cache)
+
+	listen(realtime.Event{Type: realtime.EventCandleUpdated, Symbol: "AAPL", Interval: "1day"})
+	listen(realtime.Event{Type: realtime.EventAlertTriggered, Symbol: "MSFT", Interval: "1day", UserID: 7})
+
+	assert.Equal(t, []string{"AAPL/1day"}, cache.invalidated)
+}
+
+func TestIngestObservers(t *testing.T) {
+	t.Parallel()
+	failing := &stubPublisher{err: errors.New("boom")}
+	ok := &stubPublisher{}
+	cs := []candles.Candle{{Interval: "1day", Time: time.Now()}}
+
+	err := IngestObservers{NewRealtimeIngestObserver(failing), NewRealtimeIngestObserver(ok)}.CandlesIngested(context.Background(), "AAPL", cs)
+	assert.Error(t, err)
+	assert.Len(t, ok.events, 1, "失敗しても残りに通知する")
+}
+
+// TestRealtimeAlertNotifier_EnqueueMigrated は閾値の書き換えを宛先ユーザーへの alert.adjusted として発行することを検証します。
+func TestRealtimeAlertNotifier_EnqueueMigrated(t *testing.T) {
+	t.Parallel()
+	pub := &stubPublisher{}
+	effective := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	split := alerts.Split{AdjustmentID: 4, SymbolCode: "AAPL", EffectiveDate: effective, Factor: 0.5}
+	original := 3000.0
+	err := NewRealtimeAlertNotifier(pub, &stubNotifier{}).EnqueueMigrated(context.Background(), []alerts.Migration{
+		{Alert: alerts.Alert{ID: 3, UserID: 7, SymbolCode: "AAPL", Interval: "1day", Direction: alerts.DirectionAbove, Threshold: 750},
+			PreviousThreshold: 1500, Split: split},
+		{Alert: alerts.Alert{ID: 4, UserID: 8, SymbolCode: "AAPL", Interval: "1day", Direction: alerts.DirectionBelow, Threshold: 375,
+			OriginalThreshold: &original}, PreviousThreshold: 750, Split: split},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, pub.events, 2)
+	assert.Equal(t, realtime.EventAlertAdjusted, pub.events[0].Type)
+	assert.Equal(t, int64(7), pub.events[0].UserID)
+	var first, second realtime.AlertAdjustedData
+	require.NoError(t, json.Unmarshal(pub.events[0].Data, &first))
+	require.NoError(t, json.Unmarshal(pub.events[1].Data, &second))
+	assert.Equal(t, 1500.0, first.OriginalThreshold, "最初の書き換えでは書き換え前の閾値が最初の閾値")
+	assert.Equal(t, 0.5, first.Factor)
+	assert.True(t, effective.Equal(first.EffectiveDate))
+	assert.Equal(t, 3000.0, second.OriginalThreshold)
+	assert.Equal(t, 750.0, second.PreviousThreshold)
+}
@@ -0,0 +1,51 @@
+package di
+
+import (
+	"context"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/recentlyviewed"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist"
+)
+
+// StaleSymbolLister は DB 障害時に last-known-good を返せるアクティブ銘柄取得インターフェースです。
+// symbollist.CachingRepository が実装します。
+type StaleSymbolLister interface {
+	ListActiveOrStale(ctx context.Context) ([]symbollist.Symbol, bool, error)
+}
+
+// LocalizedSymbolLister は StaleSymbolLister の銘柄名を locale の表記で返す版です。
+// symbollist.CachingRepository が実装します。
+type LocalizedSymbolLister interface {
+	ListActiveLocalizedOrStale(ctx context.Context, locale string) ([]symbollist.Symbol, bool, error)
+}
+
+// recentSymbolNames は symbollist の銘柄一覧を recentlyviewed.SymbolNameLookup に適合させます。
+// feature 同士の直接依存を避けるため DI 層で変換を行います。
+type recentSymbolNames struct {
+	src LocalizedSymbolLister
+}
+
+// NewRecentSymbolNames は最近閲覧した銘柄の銘柄名参照に使う SymbolNameLookup 実装を返します。
+// 銘柄一覧と同じく、DB 障害時は last-known-good の一覧から名前を引きます。
+func NewRecentSymbolNames(src LocalizedSymbolLister) recentlyviewed.SymbolNameLookup {
+	return &recentSymbolNames{src: src}
+}
+
+// SymbolNames は codes のうちアクティブな銘柄について、コード → locale の企業名の対応を返します。
+func (a *recentSymbolNames) SymbolNames(ctx context.Context, codes []string, locale string) (map[string]string, error) {
+	syms, _, err := a.src.ListActiveLocalizedOrStale(ctx, locale)
+	if err != nil {
+		return nil, err
+	}
+	want := make(map[string]struct{}, len(codes))
+	for _, c := range codes {
+		want[c] = struct{}{}
+	}
+	out := make(map[string]string, len(codes))
+	for _, s := range syms {
+		if _, ok := want[s.Code]; ok {
+			out[s.Code] = s.Name
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,35 @@
+package di
+
+import (
+	"context"
+	"errors"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+)
+
+// SessionRevocations はトークンの一括失効のインターフェースです。jwt.Revocations が実装します。
+type SessionRevocations interface {
+	RevokeAllByUserID(ctx context.Context, userID int64) error
+}
+
+// sessionRevoker は jwt の一括失効を auth.SessionRevoker に適合させます。
+// auth のコアが transport に依存しないよう、失効を記録できないエラーの変換を DI 層で行います。
+type sessionRevoker struct {
+	rev SessionRevocations
+}
+
+// NewSessionRevoker はパスワードの再設定・全端末からのログアウト・アカウントの削除に使う SessionRevoker 実装を返します。
+func NewSessionRevoker(rev SessionRevocations) auth.SessionRevoker {
+	return &sessionRevoker{rev: rev}
+}
+
+// RevokeAllByUserID は userID のトークンをすべて失効させます。
+// Redis の障害中（jwt.ErrRevocationUnavailable）は auth.ErrSessionRevocationUnavailable を返します。
+func (a *sessionRevoker) RevokeAllByUserID(ctx context.Context, userID int64) error {
+	err := a.rev.RevokeAllByUserID(ctx, userID)
+	if errors.Is(err, jwt.ErrRevocationUnavailable) {
+		return auth.ErrSessionRevocationUnavailable
+	}
+	return err
+}
@@ -0,0 +1,28 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/testsupport"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+)
+
+func TestSessionRevoker(t *testing.T) {
+	t.Parallel()
+
+	// Redis がない間は auth のエラーに変換する
+	unavailable := NewSessionRevoker(jwt.NewRevocations(nil, "test:auth:revoked", time.Hour))
+	if err := unavailable.RevokeAllByUserID(context.Background(), 7); !errors.Is(err, auth.ErrSessionRevocationUnavailable) {
+		t.Errorf("without Redis: err = %v, want %v", err, auth.ErrSessionRevocationUnavailable)
+	}
+
+	_, rdb := testsupport.NewMiniRedis(t)
+	revoker := NewSessionRevoker(jwt.NewRevocations(rdb, "test:auth:revoked", time.Hour))
+	if err := revoker.RevokeAllByUserID(context.Background(), 7); err != nil {
+		t.Errorf("with Redis: unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package di
+
+import (
+	"context"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/candleshttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist"
+)
+
+// SymbolStatusReader は銘柄の状態の取得インターフェースです。symbollist.ActiveCodeSet が実装します。
+type SymbolStatusReader interface {
+	Status(ctx context.Context, code string) (symbollist.Status, error)
+}
+
+// candleSymbolStatus は symbollist の銘柄の状態を candleshttp.SymbolStatusSource に適合させます。
+// feature 同士の直接依存を避けるため DI 層で変換を行います。
+type candleSymbolStatus struct {
+	src SymbolStatusReader
+}
+
+// NewCandleSymbolStatus は /candles の X-Symbol-Status に使う SymbolStatusSource 実装を返します。
+// 状態は銘柄の存在チェックと同じ ActiveCodeSet から引くため、リクエストごとの DB 問い合わせは増えません。
+func NewCandleSymbolStatus(src SymbolStatusReader) candleshttp.SymbolStatusSource {
+	return &candleSymbolStatus{src: src}
+}
+
+// SymbolStatus は銘柄 code の状態（"active" / "delisted"）を返します。参照できない銘柄の場合は空文字です。
+func (a *candleSymbolStatus) SymbolStatus(ctx context.Context, code string) (string, error) {
+	st, err := a.src.Status(ctx, code)
+	return string(st), err
+}
+
+// SymbolLookup は銘柄の名前・市場・状態の取得インターフェースです。symbollist.ActiveCodeSet が実装します。
+type SymbolLookup interface {
+	Lookup(ctx context.Context, code, locale string) (symbollist.CodeStatus, bool, error)
+}
+
+// candleSymbolInfo は symbollist の銘柄の情報を candleshttp.SymbolInfoSource に適合させます。
+type candleSymbolInfo struct {
+	src SymbolLookup
+}
+
+// NewCandleSymbolInfo は /candles の ?include=symbol に使う SymbolInfoSource 実装を返します。
+// X-Symbol-Status と同じ ActiveCodeSet から引くため、銘柄一覧と名前が食い違わず、リクエストごとの DB 問い合わせも増えません。
+func NewCandleSymbolInfo(src SymbolLookup) candleshttp.SymbolInfoSource {
+	return &candleSymbolInfo{src: src}
+}
+
+// SymbolInfo は銘柄 code の情報を locale の名前で返します。参照できない銘柄の場合は ok=false です。
+func (a *candleSymbolInfo) SymbolInfo(ctx context.Context, code, locale string) (candleshttp.SymbolInfo, bool, error) {
+	cs, ok, err := a.src.Lookup(ctx, code, locale)
+	if err != nil || !ok {
+		return candleshttp.SymbolInfo{}, false, err
+	}
+	return candleshttp.SymbolInfo{Code: cs.Code, Name: cs.Name, Market: cs.Market, Status: string(cs.Status)}, true, nil
+}
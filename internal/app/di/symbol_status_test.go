@@ -0,0 +1,88 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/candleshttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist"
+)
+
+type stubStatusReader map[string]symbollist.Status
+
+func (s stubStatusReader) Status(ctx context.Context, code string) (symbollist.Status, error) {
+	if code == "ERR" {
+		return "", errors.New("db down")
+	}
+	return s[code], nil
+}
+
+func TestCandleSymbolStatus(t *testing.T) {
+	t.Parallel()
+
+	src := NewCandleSymbolStatus(stubStatusReader{"AAPL": symbollist.StatusActive, "TWTR": symbollist.StatusDelisted})
+	tests := []struct {
+		code    string
+		want    string
+		wantErr bool
+	}{
+		{"AAPL", "active", false},
+		{"TWTR", "delisted", false},
+		{"UNKNOWN", "", false},
+		{"ERR", "", true},
+	}
+	for _, tt := range tests {
+		got, err := src.SymbolStatus(context.Background(), tt.code)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: err = %v, wantErr %v", tt.code, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
+
+type stubSymbolLookup map[string]symbollist.CodeStatus
+
+func (s stubSymbolLookup) Lookup(ctx context.Context, code, locale string) (symbollist.CodeStatus, bool, error) {
+	if code == "ERR" {
+		return symbollist.CodeStatus{}, false, errors.New("db down")
+	}
+	cs, ok := s[code]
+	if ok && locale == "en" && cs.Names["en"] != "" {
+		cs.Name = cs.Names["en"]
+	}
+	cs.Names = nil
+	return cs, ok, nil
+}
+
+func TestCandleSymbolInfo(t *testing.T) {
+	t.Parallel()
+
+	src := NewCandleSymbolInfo(stubSymbolLookup{
+		"7203.T": {Code: "7203.T", Status: symbollist.StatusActive, Name: "トヨタ自動車", Market: "TSE", Names: map[string]string{"en": "Toyota Motor"}},
+		"TWTR":   {Code: "TWTR", Status: symbollist.StatusDelisted, Name: "Twitter Inc.", Market: "NYSE"},
+	})
+	tests := []struct {
+		code, locale string
+		want         candleshttp.SymbolInfo
+		wantOK       bool
+		wantErr      bool
+	}{
+		{"7203.T", "ja", candleshttp.SymbolInfo{Code: "7203.T", Name: "トヨタ自動車", Market: "TSE", Status: "active"}, true, false},
+		{"7203.T", "en", candleshttp.SymbolInfo{Code: "7203.T", Name: "Toyota Motor", Market: "TSE", Status: "active"}, true, false},
+		{"TWTR", "en", candleshttp.SymbolInfo{Code: "TWTR", Name: "Twitter Inc.", Market: "NYSE", Status: "delisted"}, true, false},
+		{"UNKNOWN", "ja", candleshttp.SymbolInfo{}, false, false},
+		{"ERR", "ja", candleshttp.SymbolInfo{}, false, true},
+	}
+	for _, tt := range tests {
+		got, ok, err := src.SymbolInfo(context.Background(), tt.code, tt.locale)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: err = %v, wantErr %v", tt.code, err, tt.wantErr)
+		}
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("%s/%s: got %+v, %v; want %+v, %v", tt.code, tt.locale, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
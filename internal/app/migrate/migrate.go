@@ -5,9 +5,10 @@
 //
 // 使い方:
 //
-//	migrate [up|down|down-to|status|version|reset|redo|up-by-one|up-to] [arg]
+//	migrate [up|down|down-to|status|version|reset|redo|up-by-one|up-to|verify] [arg]
 //
-// 引数なしで実行した場合は up を適用します。
+// 引数なしで実行した場合は up を適用します。up の後は、適用後のスキーマがアプリケーションの前提
+// （schemacheck.Expected）と一致するかを検証し、差異があれば失敗します。verify は検証のみを行います。
 package migrate
 
 import (
@@ -20,6 +21,7 @@ import (
 
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/app/config"
 	infradb "github.com/UCHIDAnobuhiro/stock-backend/internal/infra/db"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/db/schemacheck"
 )
 
 // allowedCommands は本バイナリから実行を許容する goose サブコマンドです。
@@ -34,6 +36,7 @@ var allowedCommands = map[string]struct{}{
 	"reset":     {},
 	"status":    {},
 	"version":   {},
+	"verify":    {}, // goose のコマンドではなく、スキーマの検証のみを行う
 }
 
 // Run は goose サブコマンド（コマンド引数）に応じてマイグレーションを実行し、終了コードを返す。
@@ -65,14 +68,24 @@ func Run(cfg *config.Config, args []string) int {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
-	if err := infradb.RunGoose(ctx, db, cmd, extra...); err != nil {
-		if errors.Is(err, context.DeadlineExceeded) {
-			slog.Error("migration timed out", "error", err)
-		} else {
-			slog.Error("migration failed", "command", cmd, "error", err)
+	if cmd != "verify" {
+		if err := infradb.RunGoose(ctx, db, cmd, extra...); err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				slog.Error("migration timed out", "error", err)
+			} else {
+				slog.Error("migration failed", "command", cmd, "error", err)
+			}
+			return 1
 		}
-		return 1
+		slog.Info("migration ok", "command", cmd)
+	}
+	// 最新まで適用した後（up）と verify のみ検証する（up-to・down などは途中のバージョンのため差異が出て当然）
+	if cmd == "up" || cmd == "verify" {
+		if _, err := schemacheck.Verify(ctx, db, slog.Default()); err != nil {
+			slog.Error("schema verification failed", "error", err)
+			return 1
+		}
+		slog.Info("schema verification ok")
 	}
-	slog.Info("migration ok", "command", cmd)
 	return 0
 }
@@ -60,6 +60,7 @@ func TestAllowedCommands_ContainsExpectedSet(t *testing.T) {
 		"down", "down-to",
 		"redo", "reset",
 		"status", "version",
+		"verify",
 	}
 	if len(allowedCommands) != len(want) {
 		t.Errorf("allowedCommands size = %d, want %d", len(allowedCommands), len(want))
@@ -8,31 +8,89 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
 
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/alerts/alertshttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/annotations/annotationshttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth/authhttp"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/candleshttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/dataexport/dataexporthttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/digest/digesthttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/events/eventshttp"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/logodetection/logodetectionhttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/push/pushhttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/realtime/realtimehttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/recentlyviewed/recentlyviewedhttp"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist/symbollisthttp"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/watchlist/watchlisthttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/apikey"
 	csrfmw "github.com/UCHIDAnobuhiro/stock-backend/internal/transport/csrf"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/deprecation"
 	handler "github.com/UCHIDAnobuhiro/stock-backend/internal/transport/handler"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpratelimit"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/i18n"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/maintenance"
 	httpmw "github.com/UCHIDAnobuhiro/stock-backend/internal/transport/middleware"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/stream"
 )
 
 // NewRouter はすべてのアプリケーションルートを設定したHTTPハンドラー（chiルーター）を生成します。
-// 公開ルート（signup, login）とJWT認証ミドルウェア付きの保護ルート（candles, symbols, logo, watchlist）を設定します。
+// 公開ルート（signup, login, version）とJWT認証ミドルウェア付きの保護ルート（auth/logout_all, auth/account, candles, stats, symbols, symbols/search, symbols/{code}, symbols/{code}/events, logo, watchlist, annotations, me/export, me/recent-symbols, me/devices, me/digest, me/alerts, me/usage）を設定します。
 // oauthHandler が nil の場合はOAuthルートを登録しません。
+// JWT 認証のルートでは revocations に記録された一括失効（パスワード再設定・全端末からのログアウト・アカウントの削除時）より前のトークンを拒否します。
+// なりすましトークンのリクエストは監査ログに記録し、impersonationWriteAllow にない書き込みを拒否します（jwt.ImpersonationGuard）。
+// 保護ルートでは認証済みユーザーを users で遅延読み込みする auth.UserGetter を context に格納します。
+// ローソク足・統計のルートでは利用者の料金プランを plans で解決し、free のユーザーには premium の銘柄を返しません（candleshttp.ResolvePlan）。
+// 管理ルート（/v1/admin）のうちフィーチャーフラグとユーザーの検索・参照・料金プランの変更・なりすましは管理者ユーザー（JWT・role が admin）でのみ到達できます（authhttp.AdminRequired）。
+// それ以外の管理ルートはAPIキーでのみ到達でき、経路ごとに candles:admin / symbols:admin / jobs:admin スコープを要求します。
+// 長時間のストリーミング応答（エクスポートのダウンロード、WebSocket の /v1/ws）は streams に登録し、シャットダウン時に排出します。
+// 認証系のルート（signup・login・logout・パスワード再設定・OAuth）のエラーは messages で Accept-Language のロケールに翻訳します。
+// deprecations に登録した /v1 のグループのルートには Deprecation / Sunset / Link ヘッダーを付けます（クライアントを区別するため認証の後に置く）。
+// 管理ルートはサブルーター（r.Route）のためミドルウェアの時点でパターンが決まらず、対象外です。
+// maintenanceMode が有効な間は /v1 の書き込みを maintenanceWriteAllow にあるものを除いて 503・Retry-After（maintenanceRetryAfter）で拒否し、
+// ローソク足の読み取りは DB よりキャッシュを優先します（maintenance.Guard・candleshttp.PreferCacheDuringMaintenance）。
 func NewRouter(authHandler *authhttp.Handler, oauthHandler *authhttp.OAuthHandler,
+	passwordReset *authhttp.PasswordResetHandler,
+	adminUsers *authhttp.AdminUserHandler,
 	candles *candleshttp.Handler,
-	symbol *symbollisthttp.Handler, logo *logodetectionhttp.Handler,
+	anomalies *candleshttp.AnomalyHandler,
+	adjustments *candleshttp.AdjustmentHandler,
+	dedupe *candleshttp.DedupeHandler,
+	inspect *candleshttp.InspectHandler,
+	dailyStats *candleshttp.DailyStatsHandler,
+	symbol *symbollisthttp.Handler, symbolNames *symbollisthttp.NameHandler, symbolStatus *symbollisthttp.StatusHandler,
+	events *eventshttp.Handler,
+	logo *logodetectionhttp.Handler,
 	watchlist *watchlisthttp.Handler,
+	annotations *annotationshttp.Handler,
+	realtime *realtimehttp.Handler,
+	export *dataexporthttp.Handler,
+	recent *recentlyviewedhttp.Handler,
+	devices *pushhttp.Handler,
+	digest *digesthttp.Handler,
+	alerts *alertshttp.Handler,
+	flags *handler.FlagsHandler,
+	jobs *handler.JobsHandler,
+	ready *handler.ReadyHandler,
 	limiter *httpratelimit.Limiter,
+	apiKeys apikey.Config,
 	allowedOrigins []string,
 	gcpProjectID string,
 	jwtSecret string,
+	revocations *jwt.Revocations,
+	impersonationWriteAllow []string,
+	maintenanceMode *maintenance.Mode,
+	maintenanceWriteAllow []string,
+	maintenanceRetryAfter time.Duration,
+	deprecations *deprecation.Tracker,
+	users auth.UserLoader,
+	plans candleshttp.PlanSource,
+	streams *stream.Registry,
+	messages *i18n.Catalog,
 ) http.Handler {
 	r := chi.NewRouter()
+	maintenanceGuard := maintenance.Guard(maintenanceMode, maintenanceWriteAllow, maintenanceRetryAfter)
 
 	// AccessLog を外側、Recover を内側に置くことで、panic を 500 に変換した結果も
 	// アクセスログに記録される。
@@ -42,59 +100,209 @@ func NewRouter(authHandler *authhttp.Handler, oauthHandler *authhttp.OAuthHandle
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   allowedOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Origin", "Content-Type", "Authorization", "X-CSRF-Token"},
+		AllowedHeaders:   []string{"Origin", "Content-Type", "Authorization", "X-CSRF-Token", apikey.HeaderName},
 		AllowCredentials: true,
 		MaxAge:           int((12 * time.Hour).Seconds()),
 	}))
 	r.Use(httpmw.SecurityHeaders())
 
+	// 未知のパス・メソッドの不一致も他のエンドポイントと同じ ErrorResponse の形で返す（/v1 等のサブルーターにも引き継がれる）
+	r.NotFound(handler.NotFound)
+	r.MethodNotAllowed(handler.MethodNotAllowed(r))
+
 	// ヘルスチェックエンドポイント（バージョンなし）。
 	// Health はメソッドごとの分岐を自身で行うため、全メソッドを単一ハンドラーで処理する。
 	r.Handle("/healthz", http.HandlerFunc(handler.Health))
+	// レディネス（キャッシュの有効・無効など依存先の状態を返す）
+	r.Get("/readyz", ready.Ready)
 
 	// API v1 ルート
 	r.Route("/v1", func(r chi.Router) {
+		// ビルド情報（認証不要・キャッシュ可）
+		r.Get("/version", handler.Version)
+
 		// 公開ルート（認証不要）+ レートリミット
-		r.With(httpratelimit.ByIP(limiter, httpratelimit.IPRateLimitConfig{
-			Prefix: "rl:signup:ip",
-			Limit:  5,
-			Window: 1 * time.Hour,
-		})).Post("/signup", authHandler.Signup)
+		// エラーの文言は Accept-Language のロケールで message に返す（error のコードはロケールによらない）
+		r.Group(func(r chi.Router) {
+			r.Use(httpx.Localize(messages))
+			r.Use(deprecations.Middleware())
+			r.Use(maintenanceGuard)
 
-		r.With(httpratelimit.ByIP(limiter, httpratelimit.IPRateLimitConfig{
-			Prefix: "rl:login:ip",
-			Limit:  10,
-			Window: 1 * time.Minute,
-		})).Post("/login", authHandler.Login)
-
-		// 期限切れトークンでもログアウトできるよう認証不要
-		r.Delete("/logout", authHandler.Logout)
-
-		// OAuthルート（環境変数が設定されている場合のみ登録）
-		if oauthHandler != nil {
-			r.Route("/auth/oauth", func(r chi.Router) {
-				r.Get("/{provider}", oauthHandler.BeginAuth)
-				r.With(httpratelimit.ByIP(limiter, httpratelimit.IPRateLimitConfig{
-					Prefix: "rl:oauth:callback:ip",
-					Limit:  20,
-					Window: 1 * time.Minute,
-				})).Get("/{provider}/callback", oauthHandler.Callback)
+			r.With(httpratelimit.ByIP(limiter, httpratelimit.IPRateLimitConfig{
+				Prefix: "rl:signup:ip",
+				Limit:  5,
+				Window: 1 * time.Hour,
+			})).Post("/signup", authHandler.Signup)
+
+			r.With(httpratelimit.ByIP(limiter, httpratelimit.IPRateLimitConfig{
+				Prefix: "rl:login:ip",
+				Limit:  10,
+				Window: 1 * time.Minute,
+			})).Post("/login", authHandler.Login)
+
+			// 期限切れトークンでもログアウトできるよう認証不要
+			r.Delete("/logout", authHandler.Logout)
+
+			// パスワード再設定（未ログインで利用するため認証不要）
+			r.With(httpratelimit.ByIP(limiter, httpratelimit.IPRateLimitConfig{
+				Prefix: "rl:forgot:ip",
+				Limit:  10,
+				Window: 1 * time.Hour,
+			})).Post("/auth/forgot", passwordReset.Forgot)
+
+			r.With(httpratelimit.ByIP(limiter, httpratelimit.IPRateLimitConfig{
+				Prefix: "rl:reset:ip",
+				Limit:  10,
+				Window: 1 * time.Minute,
+			})).Post("/auth/reset", passwordReset.Reset)
+
+			// OAuthルート（環境変数が設定されている場合のみ登録）
+			if oauthHandler != nil {
+				r.Route("/auth/oauth", func(r chi.Router) {
+					r.Get("/{provider}", oauthHandler.BeginAuth)
+					r.With(httpratelimit.ByIP(limiter, httpratelimit.IPRateLimitConfig{
+						Prefix: "rl:oauth:callback:ip",
+						Limit:  20,
+						Window: 1 * time.Minute,
+					})).Get("/{provider}/callback", oauthHandler.Callback)
+				})
+			}
+		})
+
+		// 読み取り専用の保護ルート（JWT またはAPIキーで認証・APIキーはスコープで制限）
+		// APIキーはユーザーを表さないため、ユーザー前提のルートはこのグループに置かないこと。
+		r.Group(func(r chi.Router) {
+			r.Use(apikey.Authenticate(limiter, apiKeys, jwt.AuthRequired(jwtSecret)))
+			r.Use(jwt.RejectRevoked(revocations))
+			r.Use(jwt.ImpersonationGuard(impersonationWriteAllow))
+			r.Use(csrfmw.Protect())
+			r.Use(deprecations.Middleware())
+			r.Use(maintenanceGuard)
+
+			// 銘柄の区分（basic / premium）で参照を制限するルート。APIキーは data:premium スコープで premium として扱う
+			r.Group(func(r chi.Router) {
+				r.Use(candleshttp.ResolvePlan(plans))
+				r.Use(candleshttp.PreferCacheDuringMaintenance())
+
+				r.With(apikey.RequireScope(apikey.ScopeCandlesRead)).Get("/candles/{code}", candles.GetCandlesHandler)
+				r.With(apikey.RequireScope(apikey.ScopeCandlesRead)).Get("/candles/{code}/stats", candles.GetStatsHandler)
+				r.With(apikey.RequireScope(apikey.ScopeCandlesRead)).Get("/candles/{code}/sparkline", candles.GetSparklineHandler)
+				r.With(apikey.RequireScope(apikey.ScopeCandlesRead)).Get("/candles/sparklines", candles.GetSparklinesHandler)
+				r.With(apikey.RequireScope(apikey.ScopeCandlesRead)).Get("/stats", dailyStats.List)
 			})
-		}
+			r.With(apikey.RequireScope(apikey.ScopeSymbolsRead)).Get("/symbols", symbol.List)
+			r.With(apikey.RequireScope(apikey.ScopeSymbolsRead)).Get("/symbols/search", symbol.Search)
+			r.With(apikey.RequireScope(apikey.ScopeSymbolsRead)).Get("/symbols/{code}", symbol.Get)
+			r.With(apikey.RequireScope(apikey.ScopeSymbolsRead)).Get("/symbols/{code}/events", events.List)
+		})
 
 		// 保護ルート（認証必須・CSRF保護）
 		r.Group(func(r chi.Router) {
 			r.Use(jwt.AuthRequired(jwtSecret))
+			r.Use(jwt.RejectRevoked(revocations))
+			r.Use(jwt.ImpersonationGuard(impersonationWriteAllow))
 			r.Use(csrfmw.Protect())
+			r.Use(deprecations.Middleware())
+			r.Use(maintenanceGuard)
+			// ユーザーの情報（ID 以外）が必要な場合は auth.CurrentUser で取得する（1 リクエストあたりの読み込みは最大 1 回）
+			r.Use(authhttp.LoadUser(users))
+
+			// 発行済みのトークンをすべて失効させる（このリクエストのトークンも含む）
+			r.Post("/auth/logout_all", authHandler.LogoutAll)
+			// アカウントの削除（現在のパスワードの総当たりを抑えるため IP でも制限する）
+			r.With(httpratelimit.ByIP(limiter, httpratelimit.IPRateLimitConfig{
+				Prefix: "rl:account_delete:ip",
+				Limit:  10,
+				Window: 1 * time.Hour,
+			})).Delete("/auth/account", authHandler.DeleteAccount)
 
-			r.Get("/candles/{code}", candles.GetCandlesHandler)
-			r.Get("/symbols", symbol.List)
 			r.Post("/logo/detect", logo.DetectLogos)
 			r.Post("/logo/analyze", logo.AnalyzeCompany)
+			r.Get("/logo/analyze/jobs/{id}", logo.AnalysisJob)
 			r.Get("/watchlist", watchlist.List)
 			r.Post("/watchlist", watchlist.Add)
 			r.Delete("/watchlist/{code}", watchlist.Remove)
 			r.Put("/watchlist/order", watchlist.Reorder)
+
+			// 注記はユーザーごとのデータのため、APIキーで読み取れる /candles とは別にこのグループに置く
+			r.Get("/annotations", annotations.List)
+			r.Post("/annotations", annotations.Create)
+			r.Get("/annotations/{id}", annotations.Get)
+			r.Patch("/annotations/{id}", annotations.Update)
+			r.Delete("/annotations/{id}", annotations.Delete)
+			r.Get("/candles/{code}/annotations", annotations.CandleAnnotations)
+
+			r.Get("/me/recent-symbols", recent.List)
+
+			r.Post("/me/devices", devices.Register)
+			r.Delete("/me/devices/{id}", devices.Unregister)
+
+			r.Get("/me/digest", digest.Get)
+			r.Put("/me/digest", digest.Update)
+
+			r.Get("/me/alerts/export", alerts.Export)
+			r.Post("/me/alerts/import", alerts.Import)
+
+			r.Get("/me/usage", logo.Usage)
+
+			r.Post("/me/export", export.Start)
+			r.Get("/me/export/{id}", export.Get)
+		})
+
+		// エクスポートのダウンロード（URL の署名で認可するため JWT・CSRF は不要。リンクは一度だけ有効）
+		// シャットダウン中の新規ダウンロードはリンクを消費する前に 503 で断り、別インスタンスでの再試行を促す
+		r.With(streams.Middleware()).Get("/me/export/{id}/download", export.Download)
+
+		// リアルタイム配信（WebSocket）。ブラウザ以外は Authorization ヘッダーを付けられないことがあるため、
+		// トークンは ?access_token= か最初のメッセージで受け取り、ハンドラーが検証する
+		r.With(httpratelimit.ByIP(limiter, httpratelimit.IPRateLimitConfig{
+			Prefix: "rl:ws:ip",
+			Limit:  60,
+			Window: 1 * time.Minute,
+		}), streams.Middleware()).Get("/ws", realtime.Serve)
+
+		// 管理ルート。ルートのパターンで許可リストと照合できるよう、ミドルウェアはグループに置く（サブルーターの r.Use ではパターンが決まらない）
+		r.Route("/admin", func(r chi.Router) {
+			// 管理スコープ付きAPIキーのみ（ユーザー（JWT）は到達できない）
+			r.Group(func(r chi.Router) {
+				r.Use(apikey.Authenticate(limiter, apiKeys, apikey.KeyRequired))
+				r.Use(maintenanceGuard)
+
+				r.With(apikey.RequireScope(apikey.ScopeCandlesAdmin)).Get("/anomalies", anomalies.List)
+				r.With(apikey.RequireScope(apikey.ScopeCandlesAdmin)).Post("/anomalies/{id}/resolve", anomalies.Resolve)
+				r.With(apikey.RequireScope(apikey.ScopeCandlesAdmin)).Get("/adjustments", adjustments.List)
+				r.With(apikey.RequireScope(apikey.ScopeCandlesAdmin)).Post("/adjustments", adjustments.Create)
+				r.With(apikey.RequireScope(apikey.ScopeCandlesAdmin)).Put("/adjustments/{id}", adjustments.Update)
+				r.With(apikey.RequireScope(apikey.ScopeCandlesAdmin)).Delete("/adjustments/{id}", adjustments.Delete)
+				r.With(apikey.RequireScope(apikey.ScopeCandlesAdmin)).Post("/candles/dedupe", dedupe.Dedupe)
+				r.With(apikey.RequireScope(apikey.ScopeCandlesAdmin)).Get("/candles/{code}", inspect.Inspect)
+
+				r.With(apikey.RequireScope(apikey.ScopeSymbolsAdmin)).Get("/symbols/{code}/names", symbolNames.List)
+				r.With(apikey.RequireScope(apikey.ScopeSymbolsAdmin)).Put("/symbols/{code}/names/{locale}", symbolNames.Put)
+				r.With(apikey.RequireScope(apikey.ScopeSymbolsAdmin)).Delete("/symbols/{code}/names/{locale}", symbolNames.Delete)
+				r.With(apikey.RequireScope(apikey.ScopeSymbolsAdmin)).Put("/symbols/{code}/status", symbolStatus.Put)
+
+				r.With(apikey.RequireScope(apikey.ScopeJobsAdmin)).Get("/jobs", jobs.List)
+				r.With(apikey.RequireScope(apikey.ScopeJobsAdmin)).Post("/jobs/{name}/run-now", jobs.RunNow)
+			})
+
+			// 管理者ユーザー（JWT・role が admin）のみ。ユーザーの個人情報・サービス全体の挙動を扱うためAPIキーでは到達できない
+			r.Group(func(r chi.Router) {
+				r.Use(jwt.AuthRequired(jwtSecret))
+				r.Use(jwt.RejectRevoked(revocations))
+				r.Use(csrfmw.Protect())
+				r.Use(maintenanceGuard)
+				r.Use(authhttp.LoadUser(users))
+				r.Use(authhttp.AdminRequired())
+
+				r.Get("/flags", flags.List)
+				r.Put("/flags/{name}", flags.Update)
+
+				r.Get("/users", adminUsers.List)
+				r.Get("/users/{id}", adminUsers.Get)
+				r.Put("/users/{id}/plan", adminUsers.SetPlan)
+				r.Post("/users/{id}/impersonate", adminUsers.Impersonate)
+			})
 		})
 	})
 
@@ -0,0 +1,23 @@
+package seed
+
+import (
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+)
+
+// FixtureEnd は Fixture が生成する日足の最終日です。
+// 実行日に依存しない固定の日付にすることで、ベンチマーク・テストの入力を常に同じにします。
+var FixtureEnd = time.Date(2026, 3, 13, 0, 0, 0, 0, time.UTC)
+
+// Fixture は seed ジョブと同じ RandomWalk で、FixtureEnd までの code の日足 n 本を古い順に生成します（UTC の暦）。
+// SymbolCode と Interval（1day）を設定済みのため、そのまま UpsertBatch やキャッシュに渡せます。
+// 同じ引数に対しては常に同じ結果を返します。
+func Fixture(seed uint64, code string, n int) []candles.Candle {
+	cs := RandomWalk(seed, code, FixtureEnd, n, time.UTC)
+	for i := range cs {
+		cs[i].SymbolCode = code
+		cs[i].Interval = "1day"
+	}
+	return cs
+}
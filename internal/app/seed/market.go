@@ -0,0 +1,42 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+)
+
+// Market は RandomWalk の日足を返す candles.MarketRepository の実装です。
+// TwelveData の代わりに ingest へ渡すことで、週足・月足の集約や UpsertBatch を本番と同じ経路で通します。
+type Market struct {
+	seed uint64
+	end  time.Time
+	days int
+}
+
+// NewMarket は end までの直近 days 営業日を返す Market を生成します。
+func NewMarket(seed uint64, end time.Time, days int) *Market {
+	return &Market{seed: seed, end: end, days: days}
+}
+
+// GetTimeSeries は symbol の合成日足のうち直近 outputsize 件を返します（取り込み元は SourceSeed）。1day 以外は ErrUnsupportedRequest です。
+func (m *Market) GetTimeSeries(_ context.Context, symbol, interval string, outputsize int, loc *time.Location) ([]candles.Candle, error) {
+	if interval != "1day" {
+		return nil, fmt.Errorf("%w: synthetic market serves 1day only, got %q", candles.ErrUnsupportedRequest, interval)
+	}
+	daily := RandomWalk(m.seed, symbol, m.end, m.days, loc)
+	if outputsize > 0 && len(daily) > outputsize {
+		daily = daily[len(daily)-outputsize:]
+	}
+	for i := range daily {
+		daily[i].Source = candles.SourceSeed
+	}
+	return daily, nil
+}
+
+// noWait は合成データ用の RateLimiter です。外部 API を呼ばないため待機しません。
+type noWait struct{}
+
+func (noWait) WaitIfNeeded(ctx context.Context) error { return ctx.Err() }
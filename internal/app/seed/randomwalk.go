@@ -0,0 +1,88 @@
+package seed
+
+import (
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+)
+
+// 乱数系列の開始日と値動きのパラメータ。
+// 系列は常に walkEpoch から生成するため、同じ日付の足は end や days によらず同じ値になります（再実行で既存の足が書き換わらない）。
+var walkEpoch = time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+
+const (
+	dailyVolatility = 0.018 // 日次リターンの標準偏差
+	maxDailyMove    = 0.10  // 日次リターンの上限（異常値検出のしきい値 30% を超えないようにする）
+	gapVolatility   = 0.004 // 前日終値から始値へのギャップの標準偏差
+	maxGap          = 0.03
+	wickVolatility  = 0.006 // 高値・安値のひげの標準偏差
+	maxWick         = 0.04
+	meanReversion   = 0.002 // 基準価格への回帰の強さ（長期間の生成で価格が発散・消滅しないようにする）
+	minPrice        = 1.0
+)
+
+// RandomWalk は seed と銘柄コードから決まる疑似乱数で、end の日付（loc の暦で、end を含む）までの
+// 直近 days 営業日（月〜金）の日足を古い順に生成します。同じ引数に対しては常に同じ結果を返します。
+//
+// 各足は candles.Candle.Validate の不変条件（安値 ≤ 始値・終値 ≤ 高値、価格は正、出来高は非負）を満たします。
+// 価格はセント単位に丸めます。SymbolCode / Interval は設定しません（取り込み側で設定されます）。
+func RandomWalk(seed uint64, code string, end time.Time, days int, loc *time.Location) []candles.Candle {
+	if days <= 0 {
+		return nil
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(code))
+	rng := rand.New(rand.NewPCG(seed, h.Sum64()))
+
+	base := 20 + rng.Float64()*480 // 基準価格 20〜500
+	baseVolume := 1e6 + rng.Float64()*4e7
+	price := base
+
+	y, m, d := end.In(loc).Date()
+	last := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	ey, em, ed := walkEpoch.Date()
+	var out []candles.Candle
+	for day := time.Date(ey, em, ed, 0, 0, 0, 0, loc); !day.After(last); day = day.AddDate(0, 0, 1) {
+		if wd := day.Weekday(); wd == time.Saturday || wd == time.Sunday {
+			continue
+		}
+
+		open := math.Max(price*(1+clamp(rng.NormFloat64()*gapVolatility, maxGap)), minPrice)
+		ret := meanReversion*math.Log(base/open) + rng.NormFloat64()*dailyVolatility
+		closePrice := math.Max(open*(1+clamp(ret, maxDailyMove)), minPrice)
+		high := math.Max(open, closePrice) * (1 + math.Min(math.Abs(rng.NormFloat64())*wickVolatility, maxWick))
+		low := math.Min(open, closePrice) * (1 - math.Min(math.Abs(rng.NormFloat64())*wickVolatility, maxWick))
+		volume := int64(baseVolume * math.Exp(clamp(rng.NormFloat64()*0.3, 1)))
+
+		c := candles.Candle{
+			Time:   day,
+			Open:   roundCents(open),
+			Close:  roundCents(closePrice),
+			High:   roundCents(high),
+			Low:    roundCents(low),
+			Volume: max(volume, 1),
+		}
+		// 丸めで順序が崩れないよう、高値・安値を始値・終値で挟み直す
+		c.High = max(c.High, c.Open, c.Close)
+		c.Low = min(c.Low, c.Open, c.Close)
+		price = c.Close
+		out = append(out, c)
+	}
+	if len(out) > days {
+		out = out[len(out)-days:]
+	}
+	return out
+}
+
+// clamp は v を [-limit, limit] に収めます。
+func clamp(v, limit float64) float64 {
+	return math.Max(-limit, math.Min(limit, v))
+}
+
+// roundCents は価格をセント単位に丸めます。
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,113 @@
+package seed
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+)
+
+func newYork(t *testing.T) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	return loc
+}
+
+// TestRandomWalk_Invariants は生成した全ての足が OHLC の不変条件を満たし、営業日だけが古い順に並ぶことを検証します。
+func TestRandomWalk_Invariants(t *testing.T) {
+	t.Parallel()
+
+	loc := newYork(t)
+	end := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC) // 日曜日
+	for _, code := range []string{"AAPL", "NVDA", "7203.T", "BRK.B"} {
+		for _, seed := range []uint64{0, 1, 42} {
+			got := RandomWalk(seed, code, end, MaxDays, loc)
+			require.Len(t, got, MaxDays)
+			assert.Equal(t, time.Date(2026, 3, 13, 0, 0, 0, 0, loc), got[len(got)-1].Time, "last trading day on or before end")
+
+			for i, c := range got {
+				c.SymbolCode, c.Interval = code, "1day"
+				require.NoError(t, c.Validate(), "%s seed=%d #%d %+v", code, seed, i, c)
+				require.Positive(t, c.Volume)
+				wd := c.Time.Weekday()
+				require.False(t, wd == time.Saturday || wd == time.Sunday, "weekend candle %v", c.Time)
+				if i > 0 {
+					require.True(t, c.Time.After(got[i-1].Time))
+					// 異常値検出（既定 30%）に引っかからない値動きに収まる
+					change := c.Close/got[i-1].Close - 1
+					require.Less(t, change, candles.DefaultAnomalyThreshold)
+					require.Greater(t, change, -candles.DefaultAnomalyThreshold)
+				}
+			}
+		}
+	}
+}
+
+// TestRandomWalk_Deterministic は同じ引数で同じ系列を返し、期間を変えても同じ日付の足が変わらないことを検証します。
+func TestRandomWalk_Deterministic(t *testing.T) {
+	t.Parallel()
+
+	loc := newYork(t)
+	end := time.Date(2026, 3, 13, 0, 0, 0, 0, loc)
+	a := RandomWalk(1, "AAPL", end, 100, loc)
+	assert.Equal(t, a, RandomWalk(1, "AAPL", end, 100, loc))
+
+	// 期間を延ばしても重なる日付の足は同じ（再実行で既存の足を書き換えない）
+	longer := RandomWalk(1, "AAPL", end.AddDate(0, 0, 7), 300, loc)
+	byTime := make(map[time.Time]candles.Candle, len(longer))
+	for _, c := range longer {
+		byTime[c.Time] = c
+	}
+	for _, c := range a {
+		assert.Equal(t, c, byTime[c.Time])
+	}
+
+	assert.NotEqual(t, a, RandomWalk(1, "MSFT", end, 100, loc), "codes should get different series")
+	assert.NotEqual(t, a, RandomWalk(2, "AAPL", end, 100, loc), "seeds should get different series")
+	assert.Empty(t, RandomWalk(1, "AAPL", end, 0, loc))
+}
+
+func TestMarket_GetTimeSeries(t *testing.T) {
+	t.Parallel()
+
+	loc := newYork(t)
+	end := time.Date(2026, 3, 13, 0, 0, 0, 0, loc)
+	m := NewMarket(1, end, 50)
+
+	got, err := m.GetTimeSeries(context.Background(), "AAPL", "1day", 10, loc)
+	require.NoError(t, err)
+	want := RandomWalk(1, "AAPL", end, 50, loc)[40:]
+	for i := range want {
+		want[i].Source = candles.SourceSeed
+	}
+	assert.Equal(t, want, got)
+
+	got, err = m.GetTimeSeries(context.Background(), "AAPL", "1day", 5000, loc)
+	require.NoError(t, err)
+	assert.Len(t, got, 50)
+
+	_, err = m.GetTimeSeries(context.Background(), "AAPL", "1week", 10, loc)
+	assert.ErrorIs(t, err, candles.ErrUnsupportedRequest)
+}
+
+// TestFixture は Fixture が seed ジョブと同じ系列に SymbolCode / Interval を設定したものを返すことを検証します。
+func TestFixture(t *testing.T) {
+	t.Parallel()
+
+	got := Fixture(DefaultSeed, "AAPL", 200)
+	require.Len(t, got, 200)
+	want := RandomWalk(DefaultSeed, "AAPL", FixtureEnd, 200, time.UTC)
+	for i, c := range got {
+		assert.Equal(t, "AAPL", c.SymbolCode)
+		assert.Equal(t, "1day", c.Interval)
+		require.NoError(t, c.Validate())
+		c.SymbolCode, c.Interval = "", ""
+		assert.Equal(t, want[i], c)
+	}
+	assert.Equal(t, got, Fixture(DefaultSeed, "AAPL", 200))
+}
@@ -0,0 +1,175 @@
+// Package seed はローカル開発用のデータ（銘柄・デモユーザー・合成ローソク足）を投入します。
+//
+// 何度実行しても行は重複しません。銘柄は埋め込みの db/seed/symbols.csv を UpsertSymbols で、
+// ユーザーは本番と同じ Signup（パスワード検証・ハッシュ化）で、ローソク足は RandomWalk の日足を
+// 本番と同じ ingest（週足・月足の集約と UpsertBatch）で書き込みます。
+// batch の seed ジョブから使います（APP_ENV=production では実行しません）。
+package seed
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	dbseed "github.com/UCHIDAnobuhiro/stock-backend/db"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/app/di"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/watchlist"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/db/schemacheck"
+)
+
+const (
+	// DefaultDays は生成する日足の営業日数のデフォルト（約 1 年）です。
+	DefaultDays = 260
+	// MaxDays は生成できる日足の営業日数の上限です（ingest が 1 回に取得する件数に合わせる）。
+	MaxDays = 5000
+	// DefaultSeed は合成ローソク足の乱数シードのデフォルトです。
+	DefaultSeed uint64 = 1
+)
+
+// symbolsCSVHeader は埋め込みの銘柄 CSV のヘッダーです。
+var symbolsCSVHeader = []string{"code", "name", "market", "timezone", "currency"}
+
+// Options は Run の投入内容です。
+type Options struct {
+	Email    string    // デモユーザーのメールアドレス
+	Password string    // デモユーザーのパスワード（Signup のパスワードポリシーを満たすこと）
+	Days     int       // 生成する日足の営業日数（1〜MaxDays）
+	Seed     uint64    // 合成ローソク足の乱数シード
+	End      time.Time // 合成ローソク足の最終日
+}
+
+// Deps は Run が書き込む先です。
+type Deps struct {
+	DB      *sql.DB
+	Candles candles.WriteRepository // nil なら DB に直接書き込む（batch ではキャッシュ無効化のため CachingRepository を渡す）
+	Pepper  string                  // PASSWORD_PEPPER（API と同じ値でないとデモユーザーでログインできない）
+}
+
+// Result は Run の結果です。
+type Result struct {
+	Symbols        int   // CSV の銘柄数
+	SymbolsChanged int   // 新規登録・更新した銘柄数（再実行では 0）
+	UserID         int64 // デモユーザーの ID
+	UserCreated    bool  // デモユーザーを今回作成したか（既存なら false。パスワードは変更しない）
+	Candles        candles.IngestResult
+}
+
+// Run は銘柄・デモユーザー・合成ローソク足を投入します。
+// ローソク足はアクティブな全銘柄（CSV 以外で登録済みの銘柄を含む）について生成します。
+// スキーマに差異（マイグレーションの適用漏れ）がある場合は、途中まで書き込まずに schemacheck.ErrDrift を返します。
+func Run(ctx context.Context, deps Deps, opts Options) (Result, error) {
+	if opts.Days < 1 || opts.Days > MaxDays {
+		return Result{}, fmt.Errorf("days must be between 1 and %d, got %d", MaxDays, opts.Days)
+	}
+	var res Result
+
+	if _, err := schemacheck.Verify(ctx, deps.DB, slog.Default()); err != nil {
+		return res, fmt.Errorf("verify schema (run migrate up first): %w", err)
+	}
+
+	syms, err := ParseSymbolsCSV(dbseed.SeedSymbolsCSV())
+	if err != nil {
+		return res, fmt.Errorf("parse embedded symbols: %w", err)
+	}
+	symbolRepo := symbollist.NewRepository(deps.DB)
+	res.Symbols = len(syms)
+	if res.SymbolsChanged, err = symbolRepo.UpsertSymbols(ctx, syms); err != nil {
+		return res, fmt.Errorf("upsert symbols: %w", err)
+	}
+
+	if res.UserID, res.UserCreated, err = ensureUser(ctx, deps, symbolRepo, opts); err != nil {
+		return res, fmt.Errorf("demo user: %w", err)
+	}
+
+	writer := deps.Candles
+	if writer == nil {
+		writer = candles.NewRepository(deps.DB)
+	}
+	ingest := candles.NewIngestUsecase(
+		NewMarket(opts.Seed, opts.End, opts.Days),
+		writer,
+		di.NewIngestSymbolAdapter(symbolRepo),
+		noWait{},
+		candles.NewFreshnessRepository(deps.DB),
+	)
+	if res.Candles, err = ingest.IngestAll(ctx, candles.IngestOptions{}); err != nil {
+		return res, fmt.Errorf("ingest candles: %w", err)
+	}
+	if res.Candles.Failed > 0 || res.Candles.Aborted > 0 {
+		return res, fmt.Errorf("ingest candles: %d failed, %d aborted", res.Candles.Failed, res.Candles.Aborted)
+	}
+	return res, nil
+}
+
+// ensureUser はデモユーザーを Signup で作成し、API のサインアップと同じく既定のウォッチリストを登録します。
+// 既に存在する場合は何も変更せず、その ID を返します。
+func ensureUser(ctx context.Context, deps Deps, symbolRepo watchlist.SymbolActiveChecker, opts Options) (int64, bool, error) {
+	users := auth.NewUserRepository(deps.DB)
+	// Signup はトークンを発行しないため JWTGenerator は不要
+	id, err := auth.NewUsecase(users, nil, deps.Pepper).Signup(ctx, opts.Email, opts.Password)
+	if errors.Is(err, auth.ErrEmailAlreadyExists) {
+		u, err := users.FindByEmail(ctx, opts.Email)
+		if err != nil {
+			return 0, false, err
+		}
+		return u.ID, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	if err := watchlist.NewUsecase(watchlist.NewRepository(deps.DB), symbolRepo).OnUserCreated(ctx, id); err != nil {
+		return id, true, fmt.Errorf("default watchlist: %w", err)
+	}
+	return id, true, nil
+}
+
+// ParseSymbolsCSV は code,name,market,timezone,currency 形式の銘柄 CSV を読み込みます。
+// ヘッダー行は必須で、currency は空なら未設定として扱います。
+func ParseSymbolsCSV(data []byte) ([]symbollist.Symbol, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = len(symbolsCSVHeader)
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	if !slices.Equal(header, symbolsCSVHeader) {
+		return nil, fmt.Errorf("unexpected header %v, want %v", header, symbolsCSVHeader)
+	}
+
+	var syms []symbollist.Symbol
+	seen := make(map[string]bool)
+	for {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return syms, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := r.FieldPos(0)
+		code := strings.TrimSpace(rec[0])
+		if code == "" || seen[code] {
+			return nil, fmt.Errorf("line %d: empty or duplicate code %q", line, code)
+		}
+		seen[code] = true
+		if _, err := time.LoadLocation(rec[3]); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		s := symbollist.Symbol{Code: code, Name: symbollist.NormalizeName(rec[1]), Market: rec[2], Timezone: rec[3]}
+		if c := strings.TrimSpace(rec[4]); c != "" {
+			s.Currency = &c
+		}
+		syms = append(syms, s)
+	}
+}
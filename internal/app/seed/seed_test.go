@@ -0,0 +1,155 @@
+package seed
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dbseed "github.com/UCHIDAnobuhiro/stock-backend/db"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/candleshttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/db/dbtest"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/db/schemacheck"
+)
+
+func TestMain(m *testing.M) {
+	code, err := dbtest.RunMainWithPostgres(m)
+	if err != nil {
+		log.Fatalf("dbtest setup: %v", err)
+	}
+	os.Exit(code)
+}
+
+func countRows(t *testing.T, db *sql.DB, table string) int {
+	t.Helper()
+	var n int
+	require.NoError(t, db.QueryRowContext(context.Background(), "SELECT count(*) FROM "+table).Scan(&n))
+	return n
+}
+
+// TestRun_Idempotent は 2 回実行しても行が増えず、2 回目は何も変更しないことと、
+// 投入したローソク足を candles エンドポイントが返すことを検証します。
+func TestRun_Idempotent(t *testing.T) {
+	db := dbtest.OpenIsolatedDB(t)
+	ctx := context.Background()
+	opts := Options{
+		Email:    "demo@example.com",
+		Password: "demo-password-123",
+		Days:     30,
+		Seed:     1,
+		End:      time.Date(2026, 3, 13, 0, 0, 0, 0, time.UTC),
+	}
+	deps := Deps{DB: db, Pepper: "pepper"}
+
+	first, err := Run(ctx, deps, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 10, first.Symbols)
+	assert.Equal(t, 10, first.SymbolsChanged)
+	assert.True(t, first.UserCreated)
+	assert.Equal(t, 10, first.Candles.Succeeded)
+	assert.Positive(t, first.Candles.Inserted)
+
+	tables := []string{"symbols", "users", "candles", "watchlists"}
+	counts := make(map[string]int, len(tables))
+	for _, table := range tables {
+		counts[table] = countRows(t, db, table)
+	}
+	assert.Equal(t, 1, counts["users"])
+	assert.Positive(t, counts["watchlists"], "default watchlist should be created like API signup")
+
+	second, err := Run(ctx, deps, opts)
+	require.NoError(t, err)
+	assert.Zero(t, second.SymbolsChanged)
+	assert.False(t, second.UserCreated)
+	assert.Equal(t, first.UserID, second.UserID)
+	assert.Zero(t, second.Candles.Inserted)
+	for _, table := range tables {
+		assert.Equal(t, counts[table], countRows(t, db, table), table)
+	}
+
+	uc := candles.NewUsecase(candles.NewRepository(db), symbollist.NewActiveCodeSet(symbollist.NewRepository(db), symbollist.DefaultActiveCodeTTL))
+	h := candleshttp.NewHandler(uc, nil, nil)
+	r := chi.NewRouter()
+	r.Get("/candles/{code}", h.GetCandlesHandler)
+
+	for _, interval := range []string{"1day", "1week", "1month"} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/candles/AAPL?interval="+interval, nil))
+		require.Equal(t, http.StatusOK, rec.Code, interval)
+
+		var body []map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.NotEmpty(t, body, interval)
+		if interval == "1day" {
+			assert.Len(t, body, opts.Days)
+		}
+	}
+}
+
+// TestRun_RejectsSchemaDrift はスキーマに差異がある場合、何も書き込まずに ErrDrift を返すことを検証します。
+func TestRun_RejectsSchemaDrift(t *testing.T) {
+	db := dbtest.OpenIsolatedDB(t)
+	ctx := context.Background()
+	_, err := db.ExecContext(ctx, `ALTER TABLE candles DROP COLUMN source`)
+	require.NoError(t, err)
+
+	_, err = Run(ctx, Deps{DB: db, Pepper: "pepper"}, Options{
+		Email: "demo@example.com", Password: "demo-password-123", Days: 5, Seed: 1,
+		End: time.Date(2026, 3, 13, 0, 0, 0, 0, time.UTC),
+	})
+	require.ErrorIs(t, err, schemacheck.ErrDrift)
+	assert.Contains(t, err.Error(), "missing_column candles.source")
+	assert.Zero(t, countRows(t, db, "symbols"))
+}
+
+// TestRun_RejectsWeakPassword は Signup のパスワード検証を通すため、弱いパスワードではユーザーもローソク足も作らないことを検証します。
+func TestRun_RejectsWeakPassword(t *testing.T) {
+	db := dbtest.OpenIsolatedDB(t)
+
+	_, err := Run(context.Background(), Deps{DB: db, Pepper: "pepper"}, Options{
+		Email: "demo@example.com", Password: "short", Days: 5, Seed: 1, End: time.Now(),
+	})
+	require.Error(t, err)
+	assert.Zero(t, countRows(t, db, "users"))
+	assert.Zero(t, countRows(t, db, "candles"))
+}
+
+func TestEmbeddedSymbolsCSV(t *testing.T) {
+	syms, err := ParseSymbolsCSV(dbseed.SeedSymbolsCSV())
+	require.NoError(t, err)
+	assert.Len(t, syms, 10)
+}
+
+func TestParseSymbolsCSV(t *testing.T) {
+	t.Parallel()
+
+	syms, err := ParseSymbolsCSV([]byte("code,name,market,timezone,currency\nAAPL,Apple Inc.,NASDAQ,America/New_York,USD\n7203.T,トヨタ自動車,TSE,Asia/Tokyo,\n9432.T,ＮＴＴ（日本電信電話）,TSE,Asia/Tokyo,\n"))
+	require.NoError(t, err)
+	require.Len(t, syms, 3)
+	assert.Equal(t, "AAPL", syms[0].Code)
+	require.NotNil(t, syms[0].Currency)
+	assert.Equal(t, "USD", *syms[0].Currency)
+	assert.Nil(t, syms[1].Currency)
+	assert.Equal(t, "NTT(日本電信電話)", syms[2].Name, "銘柄名は NFKC で正規化する")
+
+	for name, in := range map[string]string{
+		"missing header":    "AAPL,Apple Inc.,NASDAQ,America/New_York,USD\n",
+		"duplicate code":    "code,name,market,timezone,currency\nAAPL,a,NASDAQ,UTC,USD\nAAPL,b,NASDAQ,UTC,USD\n",
+		"unknown timezone":  "code,name,market,timezone,currency\nAAPL,a,NASDAQ,Mars/Olympus,USD\n",
+		"wrong field count": "code,name,market,timezone,currency\nAAPL,a,NASDAQ\n",
+	} {
+		_, err := ParseSymbolsCSV([]byte(in))
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+)
+
+// readyFreshness は日足のデータ鮮度マーカーを /readyz の freshness の値に変換します。
+type readyFreshness struct {
+	reader candles.FreshnessReader
+	now    func() time.Time
+}
+
+// Freshness は ReadyHandler の freshnessStatus を実装します。マーカーを読み取れない場合は unknown です。
+func (r readyFreshness) Freshness(ctx context.Context) api.ReadyResponseFreshness {
+	markers, err := r.reader.ListFreshness(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "readiness check: list freshness failed", "error", err)
+		return api.FreshnessUnknown
+	}
+	switch candles.DailyFreshness(markers, r.now()) {
+	case candles.FreshnessOK:
+		return api.FreshnessOk
+	case candles.FreshnessStale:
+		return api.FreshnessStale
+	default:
+		return api.FreshnessUnknown
+	}
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+)
+
+type fakeFreshnessReader struct {
+	markers []candles.Freshness
+	err     error
+}
+
+func (f fakeFreshnessReader) ListFreshness(context.Context) ([]candles.Freshness, error) {
+	return f.markers, f.err
+}
+
+// TestReadyFreshness は鮮度マーカーを /readyz の freshness に変換し、週末を挟んでも金曜日の取り込みを stale としないことを検証します。
+func TestReadyFreshness(t *testing.T) {
+	t.Parallel()
+
+	fridayNight := time.Date(2024, 1, 5, 22, 0, 0, 0, time.UTC) // 金曜日
+	markers := []candles.Freshness{{Interval: "1day", Market: "NASDAQ", LastSuccessAt: &fridayNight}}
+
+	tests := []struct {
+		name   string
+		reader candles.FreshnessReader
+		now    time.Time
+		want   api.ReadyResponseFreshness
+	}{
+		{"sunday after friday ingest", fakeFreshnessReader{markers: markers}, time.Date(2024, 1, 7, 9, 0, 0, 0, time.UTC), api.FreshnessOk},
+		{"monday after friday ingest", fakeFreshnessReader{markers: markers}, time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC), api.FreshnessOk},
+		{"tuesday without monday ingest", fakeFreshnessReader{markers: markers}, time.Date(2024, 1, 9, 9, 0, 0, 0, time.UTC), api.FreshnessStale},
+		{"no markers", fakeFreshnessReader{}, time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC), api.FreshnessUnknown},
+		{"read error", fakeFreshnessReader{err: errors.New("connection refused")}, time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC), api.FreshnessUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := readyFreshness{reader: tt.reader, now: func() time.Time { return tt.now }}
+			assert.Equal(t, tt.want, r.Freshness(context.Background()))
+		})
+	}
+}
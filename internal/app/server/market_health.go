@@ -0,0 +1,27 @@
+package server
+
+import (
+	"context"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+)
+
+// readyMarketHealth は市場データ連携の状態を /readyz の market の値に変換します。
+type readyMarketHealth struct {
+	monitor *candles.MarketHealthMonitor
+}
+
+// MarketHealth は ReadyHandler の marketHealthStatus を実装します。
+func (r readyMarketHealth) MarketHealth(ctx context.Context) api.ReadyResponseMarket {
+	switch r.monitor.MarketHealth(ctx) {
+	case candles.MarketHealthHealthy:
+		return api.MarketHealthy
+	case candles.MarketHealthDegraded:
+		return api.MarketDegraded
+	case candles.MarketHealthDown:
+		return api.MarketDown
+	default:
+		return api.MarketUnknown
+	}
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/db/schemacheck"
+)
+
+// schemaCheckTimeout は起動時のスキーマ検証（information_schema・pg_indexes の読み取り）の上限です。
+const schemaCheckTimeout = 10 * time.Second
+
+// checkSchema は DB のスキーマを schemacheck.Expected と比べ、差異を 1 件ずつ ERROR で記録します。
+// strict（SCHEMA_STRICT=true）の場合、検証できない・差異があるときはエラーを返して起動を止めます。
+// そうでない場合は /readyz に出す結果（ok / drift / unknown）を返して起動を続けます。
+func checkSchema(db *sql.DB, strict bool) (api.ReadyResponseSchema, []string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), schemaCheckTimeout)
+	defer cancel()
+
+	report, err := schemacheck.Check(ctx, db, schemacheck.Expected())
+	if err != nil {
+		if strict {
+			return "", nil, fmt.Errorf("verify schema: %w", err)
+		}
+		slog.Error("schema check failed; starting without verification", "error", err)
+		return api.Unknown, nil, nil
+	}
+	report.Log(ctx, slog.Default())
+	if len(report.Unregistered) > 0 {
+		slog.Info("tables not in the schema registry", "tables", report.Unregistered)
+	}
+	if !report.OK() {
+		if strict {
+			return "", nil, fmt.Errorf("verify schema (SCHEMA_STRICT=true): %w", report.Err())
+		}
+		slog.Warn("schema drift detected; starting in degraded mode", "drift", len(report.Drift))
+		return api.Drift, report.Strings(), nil
+	}
+	return api.Ok, nil, nil
+}
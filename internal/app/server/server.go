@@ -0,0 +1,469 @@
+// Package server は API サーバーの HTTP 面（ルーター・ハンドラー・ユースケース・リポジトリ）を組み立てる。
+// 常駐サーバー（cmd/api）とサーバーレス（cmd/lambda）で同じ構成を使うため、
+// 組み立ては副作用（os.Exit・シグナル待ち・ポートの listen）を持たない New に集約している。
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	redisv9 "github.com/redis/go-redis/v9"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/app/config"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/app/di"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/app/router"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/alerts"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/alerts/alertshttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/annotations"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/annotations/annotationshttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth/authhttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/candleshttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/dataexport"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/dataexport/dataexporthttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/digest"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/digest/digesthttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/events"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/events/eventshttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/logodetection"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/logodetection/gemini"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/logodetection/logodetectionhttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/logodetection/vision"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/push"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/push/pushhttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/rates"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/realtime"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/realtime/realtimehttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/recentlyviewed"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/recentlyviewed/recentlyviewedhttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist/symbollisthttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/watchlist"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/watchlist/watchlisthttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/asyncjob"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/blobstore"
+	infradb "github.com/UCHIDAnobuhiro/stock-backend/internal/infra/db"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/outbox"
+	infraredis "github.com/UCHIDAnobuhiro/stock-backend/internal/infra/redis"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/scheduler"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/buildinfo"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/deprecation"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/handler"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpratelimit"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/i18n"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/maintenance"
+	httpmw "github.com/UCHIDAnobuhiro/stock-backend/internal/transport/middleware"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/stream"
+)
+
+// Deps は New で組み立てる外部依存の差し替えです。nil のフィールドは cfg から生成する。
+// 差し替えた依存の Close は呼び出し側の責任で、New が返す cleanup は New が生成したものだけを閉じる。
+type Deps struct {
+	// DB は nil の場合 cfg.DB で接続する（接続の確立と文字コードの検証まで行う）。
+	DB *sql.DB
+	// Redis は nil の場合 cfg.Redis の接続先のクライアントを生成する。起動時に接続できなくても New は失敗しない。
+	Redis *redisv9.Client
+	// LogoDetector は nil の場合 Cloud Vision のクライアントを生成する。
+	LogoDetector logodetection.LogoDetector
+	// CompanyAnalyzer は nil の場合 Gemini のクライアントを生成する。
+	CompanyAnalyzer logodetection.CompanyAnalyzer
+}
+
+// exportCleaner はデータエクスポートの期限切れアーカイブを定期的に削除する操作です。
+type exportCleaner interface {
+	RunCleanup(ctx context.Context, interval time.Duration)
+}
+
+// App は組み立て済みの API サーバーです。
+// Handler を http.Server（または他のアダプター）に渡し、バックグラウンド処理は Run で動かす。
+type App struct {
+	// Handler はすべてのルートを登録したルーター。
+	Handler http.Handler
+	// Streams は長時間のストリーミング応答のレジストリ（シャットダウン時に Server.Shutdown より先に排出する）。
+	Streams *stream.Registry
+
+	exportUC         exportCleaner
+	recentRecorder   *recentlyviewed.Recorder
+	cacheState       *infraredis.CacheState
+	realtimeSub      *realtime.RedisSubscriber
+	pushDispatcher   *push.Dispatcher
+	outboxRelay      *outbox.Relay
+	jobScheduler     *scheduler.Scheduler
+	dedupeUC         *candles.DedupeUsecase
+	cachedCandleRepo *candles.CachingRepository
+	deprecations     *deprecation.Tracker
+	asyncWorker      *asyncjob.Worker
+}
+
+// New は cfg と deps から API サーバーを組み立てる。
+// 外部接続や設定の不備はエラーとして返し、プロセスは終了させない。
+// 返す cleanup は New が生成した接続・クライアントを閉じる（エラー時は New 内で閉じ済みのため nil）。
+func New(cfg *config.Config, deps Deps) (app *App, cleanup func() error, err error) {
+	if cfg == nil {
+		return nil, nil, errors.New("server: config is nil")
+	}
+
+	// 生成した依存の後処理を登録順の逆に実行する。組み立てに失敗した場合はその場で閉じる。
+	var closers []func() error
+	closeAll := func() error {
+		var errs []error
+		for i := len(closers) - 1; i >= 0; i-- {
+			errs = append(errs, closers[i]())
+		}
+		return errors.Join(errs...)
+	}
+	defer func() {
+		if err != nil {
+			if cerr := closeAll(); cerr != nil {
+				slog.Warn("cleanup after failed setup", "error", cerr)
+			}
+		}
+	}()
+
+	// データベース接続。スキーマ適用は cmd/migrate バイナリ（goose）で別途実施する。
+	sqlDB := deps.DB
+	if sqlDB == nil {
+		sqlDB, err = infradb.OpenSQL(cfg.DB)
+		if err != nil {
+			return nil, nil, fmt.Errorf("open DB: %w", err)
+		}
+		closers = append(closers, sqlDB.Close)
+	}
+	// 適用漏れ・手作業の変更によるスキーマの差異を起動時に検出する（SCHEMA_STRICT=true なら起動しない）
+	schemaStatus, schemaDrift, err := checkSchema(sqlDB, cfg.Server.SchemaStrict)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Redis接続。起動時に接続できなくてもクライアントは保持し、ヘルスモニター（cacheState.Run）が
+	// 復旧を検知した時点でキャッシュ等を再有効化する。
+	redisClient := deps.Redis
+	if redisClient == nil {
+		redisClient = infraredis.NewClient(cfg.Redis.Host, cfg.Redis.Port, cfg.Redis.Password)
+		closers = append(closers, redisClient.Close)
+	}
+	cacheState := infraredis.NewCacheState(context.Background(), redisClient, infraredis.CacheStateOptions{})
+	// フラグと OAuth は起動時の状態で構成を決めるため、起動時に接続できた場合のみ渡す。
+	var rdb *redisv9.Client
+	if cacheState.Enabled() {
+		rdb = redisClient
+	}
+
+	// 全 feature が sqlc 化済み。
+	// 認証済みユーザーの読み込みは短時間プロセス内にキャッシュする（パスワード更新・ログイン記録で破棄）
+	userRepo := auth.NewCachingUserRepository(auth.NewUserRepository(sqlDB))
+	symbolRepo := symbollist.NewRepository(sqlDB)
+	candleRepo := candles.NewRepository(sqlDB).WithQueryTimeout(cfg.Server.CandlesQueryTimeout)
+	watchlistRepo := watchlist.NewRepository(sqlDB)
+
+	// フィーチャーフラグ（Redis > FLAG_* 環境変数 > デフォルト。Redis 未接続時は切り替え不可）
+	flagRegistry := di.NewFlagRegistry(rdb, cfg.Redis.Keys, cfg.Flags)
+	// メンテナンスモード（フラグ maintenance_mode）。有効な間は書き込みを 503 で拒否し、DB に書き込むバックグラウンド処理を止める
+	maintenanceMode := maintenance.New(flagRegistry)
+
+	// 市場データ（Twelve Data）連携の状態。ingest が Redis に共有した状態を読み、遷移をログで通知する
+	marketHealth := candles.NewMarketHealthMonitor(di.NewMarketHealthStore(nil, cfg.Redis.Keys).WithRedisProvider(cacheState), candles.DefaultMarketHealthRefresh).
+		OnChange(di.NotifyMarketHealthChange)
+
+	// Redisキャッシュでラップ（TTLはingest連続失敗時のセーフティネット、通常は日次ingestで上書き）。
+	// 市場データ連携が down の間はキャッシュミス時の書き込み（fetch-through）を止める
+	cachedCandleRepo := candles.NewCachingRepository(nil, candles.DefaultCacheTTL, candleRepo, cfg.Redis.Keys.Key("candles"), flagRegistry).
+		WithRedisProvider(cacheState).
+		WithMarketHealth(marketHealth).
+		WithRefreshAhead(cfg.Server.CandlesRefreshAhead).
+		WithCacheBuckets(cfg.Server.CandlesCacheBuckets).
+		WithLocalCache(cfg.Server.CandlesLocalCache)
+
+	// 銘柄一覧の last-known-good（DB 障害時に /symbols を古い一覧で応答させる。TTL なしで保持）
+	cachedSymbolRepo := symbollist.NewCachingRepository(nil, symbolRepo, cfg.Redis.Keys.Key("symbols", "lkg"), flagRegistry).
+		WithRedisProvider(cacheState)
+
+	// 参照できる銘柄（active / delisted）のコード集合（/candles 等の銘柄存在チェック用。TTL 経過で再読み込み）
+	activeCodes := symbollist.NewActiveCodeSet(symbolRepo, cfg.Server.SymbolsActiveCodeTTL)
+
+	// JWTジェネレータ（有効期間は Cookie の Max-Age にもそのまま使われる）
+	jwtGen := jwt.NewGenerator(cfg.Server.JWTSecret, cfg.Server.JWTExpiration)
+	// トークンの一括失効（パスワード再設定時・全端末からのログアウト）。下限は最も長いトークン（通常・なりすまし）の有効期間だけ保持すれば足りる
+	revocations := jwt.NewRevocations(nil, cfg.Redis.Keys.Key("auth", "revoked"), max(jwtGen.ExpiresIn(), jwt.ImpersonationExpiration)).
+		WithRedisProvider(cacheState)
+	sessionRevoker := di.NewSessionRevoker(revocations)
+
+	// Google Cloudクライアント初期化
+	logoDetector := deps.LogoDetector
+	if logoDetector == nil {
+		visionDetector, err := vision.NewVisionLogoDetector(context.Background())
+		if err != nil {
+			return nil, nil, fmt.Errorf("create vision client: %w", err)
+		}
+		closers = append(closers, visionDetector.Close)
+		logoDetector = visionDetector
+	}
+	companyAnalyzer := deps.CompanyAnalyzer
+	if companyAnalyzer == nil {
+		geminiAnalyzer, err := gemini.NewGeminiAnalyzer(context.Background())
+		if err != nil {
+			return nil, nil, fmt.Errorf("create gemini client: %w", err)
+		}
+		companyAnalyzer = geminiAnalyzer
+	}
+
+	// レートリミッター
+	rateLimiter := httpratelimit.NewLimiter(nil, cfg.Redis.Keys).WithRedisProvider(cacheState)
+
+	// ユースケース
+	authUC := auth.NewUsecase(userRepo, jwtGen, cfg.Server.PasswordPepper).WithSessionRevoker(sessionRevoker)
+	// 未登録のメールアドレスとパスワード違いのログインの所要時間が揃っているか（タイミング攻撃の緩和が効いているか）を起動時に確かめる
+	authUC.CheckPasswordTiming(context.Background())
+	passwordResetUC := auth.NewPasswordResetUsecase(userRepo, auth.NewPasswordResetRepository(sqlDB), di.LogResetSender{}, sessionRevoker, cfg.Server.PasswordPepper)
+	symbolUC := symbollist.NewUsecase(cachedSymbolRepo, symbolRepo, symbolRepo)
+	adjustmentRepo := candles.NewAdjustmentRepository(sqlDB)
+	candlesUC := candles.NewUsecase(cachedCandleRepo, activeCodes).
+		WithAdjustments(adjustmentRepo, flagRegistry).
+		WithOutputSizePolicy(cfg.OutputSize).
+		WithTiers(di.NewCandleSymbolTiers(activeCodes))
+	anomalyUC := candles.NewAnomalyUsecase(candles.NewAnomalyRepository(sqlDB))
+	dedupeUC := candles.NewDedupeUsecase(candleRepo, cachedCandleRepo)
+	// 要約統計は batch のロールアップを読み、行がない・古い銘柄だけその場で算出する
+	dailyStatsUC := candles.NewDailyStatsUsecase(candles.NewStatsRollup(cachedCandleRepo, candles.NewDailyStatsRepository(sqlDB)), activeCodes).
+		WithTiers(di.NewCandleSymbolTiers(activeCodes))
+	// 企業分析は 24 時間キャッシュし、7 日までは古い分析を返しつつバックグラウンドで再生成する（stale-while-revalidate）
+	companyAnalyses := logodetection.NewCachingAnalyzer(nil, companyAnalyzer, cfg.Redis.Keys.Key("analysis"), logodetection.AnalysisCacheConfig{}).
+		WithRedisProvider(cacheState)
+	// ロゴ検出・企業分析はユーザーごとに日次の利用枠を設ける（Redis の日次キーで数え、Redis 障害中は DB で数える）
+	logoUsage := logodetection.NewRedisUsageCounter(nil, cfg.Redis.Keys.Key("logo", "usage"), logodetection.NewUsageRepository(sqlDB)).
+		WithRedisProvider(cacheState)
+	logoUC := logodetection.NewUsecase(logoDetector, companyAnalyses, logodetection.NewQuota(logoUsage, cfg.Server.LogoQuota))
+	watchlistUC := watchlist.NewUsecase(watchlistRepo, symbolRepo)
+	// 注記の日付は保存済みの足の範囲に限るため、範囲はキャッシュを経由せず DB から読む
+	annotationsUC := annotations.NewUsecase(annotations.NewRepository(sqlDB), activeCodes, candleRepo)
+	// 配当・決算のイベント（batch の events ジョブが取り込んだものを返す。/candles の with_events でも重ねる）
+	eventsUC := events.NewUsecase(events.NewRepository(sqlDB), activeCodes)
+
+	// 最近閲覧した銘柄（/candles の閲覧をバックグラウンドで Redis に記録する。Redis 障害時は記録を破棄）
+	recentStore := recentlyviewed.NewRedisStore(nil, cfg.Redis.Keys.Key("recent", "symbols")).WithRedisProvider(cacheState)
+	recentRecorder := recentlyviewed.NewRecorder(recentStore, recentlyviewed.DefaultBufferSize)
+	recentUC := recentlyviewed.NewUsecase(recentStore, di.NewRecentSymbolNames(cachedSymbolRepo))
+
+	// 通貨換算（為替レートは batch が取得してキャッシュに書き込む。キャッシュにない場合は FX_STATIC_RATES を使う）
+	fxRates := rates.NewCachingProvider(nil, rates.NewStaticProvider(cfg.FX.StaticRates), cfg.Redis.Keys.Key("fx"), rates.DefaultCacheTTL).
+		WithRedisProvider(cacheState)
+	currencyConverter := di.NewCurrencyConverter(cachedSymbolRepo, fxRates, cfg.FX.Currencies)
+
+	// データエクスポート（アーカイブはローカルに一時保存し、期限切れ分は定期的に削除）
+	exportBlobs, err := blobstore.NewFileStore(cfg.Server.ExportDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("prepare export dir: %w", err)
+	}
+	exportUC := dataexport.NewUsecase(exportBlobs, di.ExportSections(sqlDB, recentStore), cfg.Server.JWTSecret)
+	closers = append(closers, func() error { exportUC.Close(); return nil })
+
+	// プッシュ通知（batch が登録した送信待ちをバックグラウンドで FCM に送る。鍵が未設定ならログに出力）
+	pushDispatcher, err := di.NewPushDispatcher(context.Background(), sqlDB, cfg.Push.FCMCredentialsFile, cfg.Push.Dispatcher)
+	if err != nil {
+		return nil, nil, fmt.Errorf("set up push notifications: %w", err)
+	}
+	pushDispatcher.WithPause(maintenanceMode)
+
+	// outbox のリレー（batch が保存と同じトランザクションで登録した足のイベントを配信する）。保存した銘柄ごとに
+	// キャッシュを破棄し、価格アラートを評価して（発火はプッシュ通知の送信待ちに登録し、WebSocket で接続中のユーザーへ知らせる）、
+	// 最新の足の更新を WebSocket の購読者へ知らせる（Redis Pub/Sub 経由で全インスタンスに中継）
+	realtimePub := realtime.NewRedisPublisher(redisClient, cfg.Redis.Keys.Key("realtime", "events"))
+	alertRepo := alerts.NewRepository(sqlDB)
+	alertNotifier := di.NewRealtimeAlertNotifier(realtimePub, di.NewPushAlertNotifier(push.NewRepository(sqlDB)))
+	alertEval := alerts.NewEvaluator(alertRepo, alertNotifier)
+	outboxRelay := outbox.NewRelay(outbox.NewRepository(sqlDB), map[string]outbox.Handler{
+		di.TopicCandlesUpserted: di.NewCandlesUpsertedHandler(cachedCandleRepo,
+			di.IngestObservers{di.NewAlertIngestObserver(alertEval), di.NewRealtimeIngestObserver(realtimePub)}),
+	}, outbox.RelayConfig{}).WithPause(maintenanceMode)
+
+	// 定期ジョブ（予定は DB に保存し、全インスタンスが確認して Redis のロックを取得した 1 つだけが実行する）。
+	// エクスポートの掃除はインスタンスのメモリ上のアーカイブが対象のため、ここではなく各インスタンスで動かす
+	jobScheduler := scheduler.New(scheduler.NewRepository(sqlDB),
+		infraredis.NewLocker(redisClient, cfg.Redis.Keys.Key("scheduler", "lock")), scheduler.Config{}).WithPause(maintenanceMode)
+	for _, job := range []scheduler.Job{
+		{
+			Name:        "outbox-retention",
+			Description: "保持期間を過ぎた配信済みの outbox イベントを削除する",
+			Schedule:    scheduler.Every(time.Hour),
+			Run:         outboxRelay.Purge,
+		},
+		{
+			Name:        "password-reset-janitor",
+			Description: "期限切れのパスワード再設定トークンを削除する",
+			Schedule:    scheduler.MustParseCron("15 3 * * *"),
+			Timeout:     time.Minute,
+			Run:         passwordResetUC.PurgeExpired,
+		},
+	} {
+		if err := jobScheduler.Register(job); err != nil {
+			return nil, nil, fmt.Errorf("register scheduled job: %w", err)
+		}
+	}
+
+	// OAuth ハンドラー（cfg.OAuth が nil の場合はOAuth機能なしで起動）
+	var oauthH *authhttp.OAuthHandler
+	if cfg.OAuth != nil {
+		oauthH, err = di.NewOAuthHandler(cfg.OAuth, sqlDB, rdb, cfg.Redis.Keys, userRepo, jwtGen, watchlistUC, cfg.Server.SecureCookie)
+		if err != nil {
+			return nil, nil, fmt.Errorf("set up OAuth: %w", err)
+		}
+	}
+
+	// ハンドラー
+	authH := authhttp.NewHandler(authUC, rateLimiter, cfg.Server.SecureCookie, watchlistUC)
+	passwordResetH := authhttp.NewPasswordResetHandler(passwordResetUC, rateLimiter, cfg.Server.SecureCookie)
+	adminUsersH := authhttp.NewAdminUserHandler(auth.NewAdminUserUsecase(userRepo).WithImpersonation(jwtGen).WithPlans(userRepo))
+	symbolH := symbollisthttp.NewHandler(symbolUC)
+	symbolNamesH := symbollisthttp.NewNameHandler(symbollist.NewNameUsecase(symbolRepo))
+	// 状態の変更後はこのインスタンスのコード集合を破棄し、一覧の last-known-good を読み直す
+	symbolStatusH := symbollisthttp.NewStatusHandler(symbollist.NewStatusUsecase(symbolRepo, activeCodes, cachedSymbolRepo))
+	candlesH := candleshttp.NewHandler(candlesUC, recentRecorder, currencyConverter).
+		WithEventSource(di.NewCandleEventSource(eventsUC)).
+		WithSymbolStatus(di.NewCandleSymbolStatus(activeCodes)).
+		WithSymbolInfo(di.NewCandleSymbolInfo(activeCodes), symbollist.SupportedLocales).
+		WithDebugHeaders(cfg.Server.CandlesDebugHeaders)
+	eventsH := eventshttp.NewHandler(eventsUC)
+	anomalyH := candleshttp.NewAnomalyHandler(anomalyUC)
+	// 株式分割・併合の調整係数の登録時に、銘柄のアラートの閾値を係数で書き換えて WebSocket で知らせる
+	adjustmentH := candleshttp.NewAdjustmentHandler(candles.NewAdjustmentUsecase(adjustmentRepo).
+		WithObserver(di.NewAlertSplitObserver(alerts.NewSplitMigrator(alertRepo, alertNotifier))))
+	alertsH := alertshttp.NewHandler(alerts.NewTransferUsecase(alertRepo))
+	dedupeH := candleshttp.NewDedupeHandler(dedupeUC)
+	inspectH := candleshttp.NewInspectHandler(candleRepo)
+	dailyStatsH := candleshttp.NewDailyStatsHandler(dailyStatsUC)
+	// 非同期の企業分析（?async=true）のジョブ。結果は完了から 1 時間 Redis に残す
+	asyncJobs := asyncjob.NewStore(nil, cfg.Redis.Keys.Key("jobs"), asyncjob.DefaultRetention).WithRedisProvider(cacheState)
+	logoH := logodetectionhttp.NewHandler(logoUC).WithAnalysisJobs(asyncJobs)
+	asyncWorker := asyncjob.NewWorker(asyncJobs, map[string]asyncjob.Handler{
+		logodetectionhttp.AnalysisJobKind: logoH.RunAnalysisJob,
+	}, asyncjob.WorkerConfig{})
+	watchlistH := watchlisthttp.NewHandler(watchlistUC).WithRequireIfMatch(cfg.Server.RequireIfMatch)
+	annotationsH := annotationshttp.NewHandler(annotationsUC)
+	// リアルタイム配信（batch が Redis Pub/Sub に発行したローソク足の更新・アラートの発火を WebSocket 接続へ振り分ける）
+	realtimeHub := realtime.NewHub(realtime.Config{})
+	// 他プロセスでのローソク足の更新はプロセス内キャッシュの無効化にも使う
+	realtimeSub := realtime.NewRedisSubscriber(redisClient, cfg.Redis.Keys.Key("realtime", "events"), realtimeHub).
+		WithListener(di.NewCandleCacheInvalidator(cachedCandleRepo))
+	realtimeH := realtimehttp.NewHandler(realtimeHub, jwt.NewAuthenticator(cfg.Server.JWTSecret, revocations), candlesUC).
+		WithAllowedOrigins(cfg.Server.CORSOrigins)
+	exportH := dataexporthttp.NewHandler(exportUC)
+	recentH := recentlyviewedhttp.NewHandler(recentUC, symbollist.SupportedLocales)
+	devicesH := pushhttp.NewHandler(push.NewUsecase(push.NewRepository(sqlDB)))
+	digestH := digesthttp.NewHandler(digest.NewUsecase(digest.NewRepository(sqlDB))).WithRequireIfMatch(cfg.Server.RequireIfMatch)
+	flagsH := handler.NewFlagsHandler(flagRegistry)
+	jobsH := handler.NewJobsHandler(jobScheduler)
+	readyH := handler.NewReadyHandler(cacheState).WithDB(sqlDB).WithRedis(redisClient).WithSchema(schemaStatus, schemaDrift).WithMaintenance(maintenanceMode).
+		WithMarketHealth(readyMarketHealth{monitor: marketHealth}).
+		WithFreshness(readyFreshness{reader: candles.NewFreshnessRepository(sqlDB), now: time.Now})
+
+	// ストリーミング接続のレジストリ（シャットダウン時に Server.Shutdown より先に排出する）
+	streams := stream.NewRegistry()
+
+	// 認証系のエラーの文言（en / ja）
+	messages, err := i18n.NewCatalog()
+	if err != nil {
+		return nil, nil, fmt.Errorf("load message catalog: %w", err)
+	}
+
+	// 廃止予定のルート（DEPRECATED_ROUTES。未設定なら nil で無効）
+	deprecations := deprecation.NewTracker(cfg.Server.DeprecatedRoutes)
+
+	// ルーター作成
+	r := router.NewRouter(authH, oauthH, passwordResetH, adminUsersH, candlesH, anomalyH, adjustmentH, dedupeH, inspectH, dailyStatsH, symbolH, symbolNamesH, symbolStatusH, eventsH, logoH, watchlistH, annotationsH, realtimeH, exportH, recentH, devicesH, digestH, alertsH, flagsH, jobsH, readyH, rateLimiter, cfg.Server.APIKeys, cfg.Server.CORSOrigins, cfg.Server.GCPProjectID, cfg.Server.JWTSecret, revocations, cfg.Server.ImpersonationWriteAllowlist, maintenanceMode, cfg.Server.MaintenanceWriteAllowlist, cfg.Server.MaintenanceRetryAfter, deprecations, userRepo, di.NewCandleUserPlans(userRepo), streams, messages)
+
+	var h http.Handler = r
+	if cfg.Server.ServerHeader {
+		h = httpmw.ServerHeader(buildinfo.Get().ServerHeader())(h)
+	}
+
+	return &App{
+		Handler:          h,
+		Streams:          streams,
+		exportUC:         exportUC,
+		recentRecorder:   recentRecorder,
+		cacheState:       cacheState,
+		realtimeSub:      realtimeSub,
+		pushDispatcher:   pushDispatcher,
+		outboxRelay:      outboxRelay,
+		jobScheduler:     jobScheduler,
+		dedupeUC:         dedupeUC,
+		cachedCandleRepo: cachedCandleRepo,
+		deprecations:     deprecations,
+		asyncWorker:      asyncWorker,
+	}, closeAll, nil
+}
+
+// Run はバックグラウンド処理（エクスポートの掃除・閲覧履歴の記録・Redis の監視・リアルタイム配信の購読・
+// outbox のイベントの配信・定期ジョブ・非同期ジョブの処理・プッシュ通知の送信）を ctx が終了するまで動かす。戻るのは処理中のイベント・
+// 実行中の定期ジョブ・処理中の非同期ジョブの結果の記録と送信中のプッシュ通知の完了後（未配信のイベント・未送信の送信待ちは DB に残り、
+// 次の起動か他のインスタンスが処理する）。
+func (a *App) Run(ctx context.Context) {
+	// UNIQUE インデックス導入前の重複したローソク足が残っていれば警告する（起動は待たない）
+	go func() {
+		wctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		a.dedupeUC.WarnDuplicates(wctx)
+	}()
+
+	go a.exportUC.RunCleanup(ctx, dataexport.DefaultCleanupInterval)
+	go a.recentRecorder.Run(ctx)
+	go a.cacheState.Run(ctx)
+	go a.realtimeSub.Run(ctx)
+
+	var wg sync.WaitGroup
+	wg.Go(func() { a.outboxRelay.Run(ctx) })
+	wg.Go(func() { a.jobScheduler.Run(ctx) })
+	wg.Go(func() { a.asyncWorker.Run(ctx) })
+	a.pushDispatcher.Run(ctx)
+	wg.Wait()
+}
+
+// LogShutdown は停止時の集計（破棄した閲覧履歴・プッシュ通知の送信結果・outbox の配信結果・定期ジョブの実行結果・
+// 非同期ジョブの処理結果・ローソク足キャッシュのヒット率と先行再取得）をログに出す。
+func (a *App) LogShutdown() {
+	pushStats := a.pushDispatcher.Stats()
+	outboxStats := a.outboxRelay.Stats()
+	jobStats := a.jobScheduler.Stats()
+	asyncStats := a.asyncWorker.Stats()
+	refresh := a.cachedCandleRepo.RefreshAheadStats()
+	cache := a.cachedCandleRepo.CacheStats()
+	slog.Info("Server stopped gracefully",
+		"recent_views_dropped", a.recentRecorder.Dropped(),
+		"push_sent", pushStats.Sent,
+		"push_retried", pushStats.Retried,
+		"push_failed", pushStats.Failed,
+		"push_devices_deactivated", pushStats.Deactivated,
+		"outbox_delivered", outboxStats.Delivered,
+		"outbox_retried", outboxStats.Retried,
+		"outbox_dead_lettered", outboxStats.DeadLettered,
+		"outbox_lost_claims", outboxStats.LostClaims,
+		"scheduled_jobs_succeeded", jobStats.Succeeded,
+		"scheduled_jobs_failed", jobStats.Failed,
+		"scheduled_jobs_skipped", jobStats.Skipped,
+		"async_jobs_done", asyncStats.Done,
+		"async_jobs_failed", asyncStats.Failed,
+		"async_jobs_retried", asyncStats.Retried,
+		"candle_cache_local_hits", cache.LocalHits,
+		"candle_cache_local_evictions", cache.LocalEvictions,
+		"candle_cache_hits", cache.Hits,
+		"candle_cache_misses", cache.Misses,
+		"candle_cache_sliced_hits", cache.SlicedHits,
+		"candle_cache_hit_rate", cache.HitRate(),
+		"candle_cache_refresh_ahead_triggered", refresh.Triggered,
+		"candle_cache_refresh_ahead_refreshed", refresh.Refreshed,
+		"candle_cache_refresh_ahead_skipped", refresh.Skipped,
+		"candle_cache_refresh_ahead_failed", refresh.Failed,
+	)
+	// 廃止予定のルートをまだ使っているクライアント（廃止日までに移行を促す相手）
+	for _, hit := range a.deprecations.Stats() {
+		slog.Info("deprecated route hits", "route", hit.Route, "client", hit.Client, "count", hit.Count)
+	}
+}
@@ -0,0 +1,215 @@
+package alerts
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
+)
+
+// Direction はアラートが発火する閾値の横切り方向です。
+type Direction string
+
+const (
+	// DirectionAbove は終値が閾値を下から上へ横切ったときに発火します。
+	DirectionAbove Direction = "above"
+	// DirectionBelow は終値が閾値を上から下へ横切ったときに発火します。
+	DirectionBelow Direction = "below"
+)
+
+// opposite は d の反対の方向を返します（不明な方向は空文字）。
+func (d Direction) opposite() Direction {
+	switch d {
+	case DirectionAbove:
+		return DirectionBelow
+	case DirectionBelow:
+		return DirectionAbove
+	default:
+		return ""
+	}
+}
+
+// Mode はアラートの発火の繰り返し方です。
+type Mode string
+
+const (
+	// ModeOneShot は一度発火すると TriggeredAt を記録し、以降は評価しないモードです（既定）。
+	ModeOneShot Mode = "one_shot"
+	// ModeReArm は終値が閾値の反対側へ戻り、かつ前回の発火からクールダウンが経過した後の横切りで再び発火するモードです。
+	ModeReArm Mode = "re_arm"
+)
+
+const (
+	// DefaultReArmCooldownMinutes は re_arm のクールダウンの推奨値（1 日に 1 回まで）です。
+	DefaultReArmCooldownMinutes = 24 * 60
+	// MaxCooldownMinutes はクールダウンの上限（30 日）です。
+	MaxCooldownMinutes = 30 * 24 * 60
+)
+
+var (
+	// ErrInvalidMode は未知のモードが指定された場合に返されます。
+	ErrInvalidMode = apperr.New(apperr.KindInvalid, "invalid alert mode", "alert mode must be one_shot or re_arm")
+	// ErrInvalidCooldown はクールダウンが範囲外（re_arm は 0〜MaxCooldownMinutes、one_shot は 0 のみ）の場合に返されます。
+	ErrInvalidCooldown = apperr.New(apperr.KindInvalid, "invalid alert cooldown",
+		fmt.Sprintf("cooldown must be between 0 and %d minutes for re_arm and 0 for one_shot", MaxCooldownMinutes))
+)
+
+// Alert はユーザーが銘柄・時間間隔ごとに設定する価格アラートです。
+//
+// one_shot（既定。Mode が空の場合も同じ）は一度発火すると TriggeredAt を記録し、以降は評価しません。
+// re_arm は TriggeredAt を記録せず、LastTriggeredAt と LastSide で再発火の可否を判定します（Next 参照）。
+type Alert struct {
+	ID          int64
+	UserID      int64
+	SymbolCode  string
+	Interval    string
+	Direction   Direction
+	Threshold   float64
+	CreatedAt   time.Time
+	TriggeredAt *time.Time
+
+	Mode            Mode
+	CooldownMinutes int
+	// LastTriggeredAt は最後に発火した日時です（クールダウンの起点）。
+	LastTriggeredAt *time.Time
+	// LastSide は最後に観測した終値の閾値に対する側です（re_arm のみ。未観測は空文字。ちょうど閾値は Direction の側）。
+	LastSide Direction
+
+	// OriginalThreshold は株式分割・併合の移行（SplitMigrator）で最初に書き換える前の閾値です（書き換えていない場合は nil）。
+	OriginalThreshold *float64
+	// AdjustmentID は最後に閾値を書き換えた調整係数の ID です（書き換えていない・調整を削除した場合は nil）。
+	AdjustmentID *int64
+	// EditedAt はユーザーが最後にアラートを変更した日時です（変更していない場合は nil）。
+	EditedAt *time.Time
+}
+
+// mode は a のモードを返します。空は ModeOneShot として扱います。
+func (a Alert) mode() Mode {
+	if a.Mode == "" {
+		return ModeOneShot
+	}
+	return a.Mode
+}
+
+// Validate はモードとクールダウンの組み合わせを検証します。
+func (a Alert) Validate() error {
+	return validateMode(a.mode(), a.CooldownMinutes)
+}
+
+func validateMode(mode Mode, cooldownMinutes int) error {
+	switch mode {
+	case ModeOneShot:
+		if cooldownMinutes != 0 {
+			return ErrInvalidCooldown
+		}
+	case ModeReArm:
+		if cooldownMinutes < 0 || cooldownMinutes > MaxCooldownMinutes {
+			return ErrInvalidCooldown
+		}
+	default:
+		return ErrInvalidMode
+	}
+	return nil
+}
+
+// ChangeMode はモードとクールダウンを切り替えたアラートを返します。
+//
+//   - one_shot → re_arm: 発火済みであれば評価の対象に戻します（TriggeredAt を消し、LastSide を発火側にするため、
+//     終値が閾値の反対側へ戻ってから再び横切るまで発火しません）。クールダウンは LastTriggeredAt から数えます
+//   - re_arm → one_shot: 評価の対象のまま、次の発火で停止します
+//   - 同じモード: クールダウンのみ変更します
+func (a Alert) ChangeMode(mode Mode, cooldownMinutes int) (Alert, error) {
+	if err := validateMode(mode, cooldownMinutes); err != nil {
+		return Alert{}, err
+	}
+	if a.mode() == ModeOneShot && mode == ModeReArm && a.TriggeredAt != nil {
+		if a.LastTriggeredAt == nil {
+			t := *a.TriggeredAt
+			a.LastTriggeredAt = &t
+		}
+		a.TriggeredAt = nil
+		a.LastSide = a.Direction
+	}
+	if mode == ModeOneShot {
+		a.LastSide = ""
+	}
+	a.Mode = mode
+	a.CooldownMinutes = cooldownMinutes
+	return a, nil
+}
+
+// Crossed は直前の終値 prev から最新の終値 latest への変化が閾値を横切ったかを返します。
+// 直前の終値が閾値の反対側（above なら閾値未満、below なら閾値超）にある場合のみ横切りとみなします。
+// 最新の終値がちょうど閾値の場合は横切りに含めます。
+func (a Alert) Crossed(prev, latest float64) bool {
+	switch a.Direction {
+	case DirectionAbove:
+		return prev < a.Threshold && latest >= a.Threshold
+	case DirectionBelow:
+		return prev > a.Threshold && latest <= a.Threshold
+	default:
+		return false
+	}
+}
+
+// sideOf は終値 price の閾値に対する側を返します。ちょうど閾値の場合は発火側（Direction）とみなします（Crossed と同じ）。
+func (a Alert) sideOf(price float64) Direction {
+	switch {
+	case price > a.Threshold:
+		return DirectionAbove
+	case price < a.Threshold:
+		return DirectionBelow
+	default:
+		return a.Direction
+	}
+}
+
+// Step は Next による 1 回の評価の結果です。
+type Step struct {
+	// Fire は発火することを表します。
+	Fire bool
+	// Suppressed は横切ったがクールダウン中のため発火しないことを表します（re_arm のみ）。
+	Suppressed bool
+	// LastSide は評価後の LastSide です（one_shot は変えません）。
+	LastSide Direction
+}
+
+// Next は直前の終値 prev から最新の終値 latest への変化を現在時刻 now で評価し、発火の可否と次の状態を返す純粋関数です。
+//
+// one_shot は Crossed のとおりです。re_arm は最後に観測した側（LastSide。未観測なら prev の側）が閾値の反対側で、
+// latest が発火側にある場合を横切りとみなし、前回の発火から CooldownMinutes が経過していれば発火します。
+// クールダウン中の横切りは発火せず、終値が反対側へ戻るまで次の横切りを待ちます。
+// 同じ足を再評価しても LastSide が発火側のままのため二度は発火しません。
+func (a Alert) Next(prev, latest float64, now time.Time) Step {
+	if a.mode() != ModeReArm {
+		return Step{Fire: a.Crossed(prev, latest), LastSide: a.LastSide}
+	}
+	side := a.LastSide
+	if side == "" {
+		side = a.sideOf(prev)
+	}
+	next := a.sideOf(latest)
+	crossed := side == a.Direction.opposite() && next == a.Direction
+	if !crossed {
+		return Step{LastSide: next}
+	}
+	if a.LastTriggeredAt != nil && now.Sub(*a.LastTriggeredAt) < time.Duration(a.CooldownMinutes)*time.Minute {
+		return Step{Suppressed: true, LastSide: next}
+	}
+	return Step{Fire: true, LastSide: next}
+}
+
+// Bar はアラートの評価に使うローソク足の時刻と終値です。
+type Bar struct {
+	Time  time.Time
+	Close float64
+}
+
+// Trigger は発火したアラートと、発火させた足です。
+type Trigger struct {
+	Alert       Alert
+	BarTime     time.Time
+	PrevClose   float64
+	Close       float64
+	TriggeredAt time.Time
+}
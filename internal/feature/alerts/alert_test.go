@@ -0,0 +1,209 @@
+package alerts
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestAlert_Crossed は直前の終値が閾値の反対側にある場合のみ横切りとみなすことを検証します。
+func TestAlert_Crossed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		direction    Direction
+		prev, latest float64
+		want         bool
+	}{
+		{"above: crosses up", DirectionAbove, 99, 101, true},
+		{"above: lands exactly on threshold", DirectionAbove, 99, 100, true},
+		{"above: previous already at threshold", DirectionAbove, 100, 101, false},
+		{"above: stays above", DirectionAbove, 101, 102, false},
+		{"above: stays below", DirectionAbove, 98, 99, false},
+		{"above: crosses down", DirectionAbove, 101, 99, false},
+		{"below: crosses down", DirectionBelow, 101, 99, true},
+		{"below: lands exactly on threshold", DirectionBelow, 101, 100, true},
+		{"below: previous already at threshold", DirectionBelow, 100, 99, false},
+		{"below: stays below", DirectionBelow, 99, 98, false},
+		{"below: crosses up", DirectionBelow, 99, 101, false},
+		{"unknown direction", Direction("sideways"), 99, 101, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			a := Alert{Direction: tt.direction, Threshold: 100}
+			if got := a.Crossed(tt.prev, tt.latest); got != tt.want {
+				t.Errorf("Crossed(%v, %v) = %v, want %v", tt.prev, tt.latest, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestAlert_Next は one_shot・re_arm の 1 回の評価（横切り・クールダウン・ちょうど閾値・LastSide の更新）を網羅的に検証します。
+func TestAlert_Next(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+	recent := now.Add(-time.Hour)   // クールダウン（1 日）中
+	old := now.Add(-25 * time.Hour) // クールダウン経過
+	boundary := now.Add(-24 * time.Hour)
+
+	tests := []struct {
+		name         string
+		alert        Alert
+		prev, latest float64
+		want         Step
+	}{
+		// one_shot は Crossed のとおりで LastSide を変えない
+		{"one_shot: crosses", Alert{Direction: DirectionAbove}, 99, 101, Step{Fire: true}},
+		{"one_shot: no cross", Alert{Direction: DirectionAbove}, 101, 102, Step{}},
+		{"one_shot: ignores cooldown state", Alert{Direction: DirectionAbove, LastTriggeredAt: &recent}, 99, 101, Step{Fire: true}},
+
+		// re_arm: 初回（LastSide 未観測）は prev の側から判定する
+		{"re_arm: first cross up", reArm(DirectionAbove, "", nil), 99, 101, Step{Fire: true, LastSide: DirectionAbove}},
+		{"re_arm: first observation below", reArm(DirectionAbove, "", nil), 98, 99, Step{LastSide: DirectionBelow}},
+		{"re_arm: first observation above", reArm(DirectionAbove, "", nil), 101, 102, Step{LastSide: DirectionAbove}},
+		{"re_arm: prev exactly on threshold does not arm", reArm(DirectionAbove, "", nil), 100, 101, Step{LastSide: DirectionAbove}},
+		{"re_arm: lands exactly on threshold", reArm(DirectionAbove, DirectionBelow, nil), 99, 100, Step{Fire: true, LastSide: DirectionAbove}},
+
+		// re_arm: LastSide が発火側のままなら同じ足の再評価でも発火しない
+		{"re_arm: still above after trigger", reArm(DirectionAbove, DirectionAbove, &old), 99, 101, Step{LastSide: DirectionAbove}},
+		{"re_arm: crosses back down re-arms", reArm(DirectionAbove, DirectionAbove, &recent), 101, 99, Step{LastSide: DirectionBelow}},
+		{"re_arm: re-armed cross after cooldown", reArm(DirectionAbove, DirectionBelow, &old), 99, 101, Step{Fire: true, LastSide: DirectionAbove}},
+		{"re_arm: re-armed cross exactly at cooldown", reArm(DirectionAbove, DirectionBelow, &boundary), 99, 101, Step{Fire: true, LastSide: DirectionAbove}},
+		{"re_arm: re-armed cross within cooldown", reArm(DirectionAbove, DirectionBelow, &recent), 99, 101, Step{Suppressed: true, LastSide: DirectionAbove}},
+		{"re_arm: armed but stays below", reArm(DirectionAbove, DirectionBelow, &old), 98, 99, Step{LastSide: DirectionBelow}},
+
+		// re_arm: below 方向（ちょうど閾値は below 側）
+		{"re_arm below: crosses down", reArm(DirectionBelow, DirectionAbove, &old), 101, 99, Step{Fire: true, LastSide: DirectionBelow}},
+		{"re_arm below: lands exactly on threshold", reArm(DirectionBelow, DirectionAbove, nil), 101, 100, Step{Fire: true, LastSide: DirectionBelow}},
+		{"re_arm below: back above re-arms", reArm(DirectionBelow, DirectionBelow, &recent), 99, 101, Step{LastSide: DirectionAbove}},
+		{"re_arm below: exactly on threshold does not re-arm", reArm(DirectionBelow, DirectionBelow, &old), 99, 100, Step{LastSide: DirectionBelow}},
+
+		{"re_arm: unknown direction", Alert{Mode: ModeReArm, Direction: "sideways", Threshold: 100}, 99, 101, Step{LastSide: DirectionAbove}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			a := tt.alert
+			a.Threshold = 100
+			if got := a.Next(tt.prev, tt.latest, now); got != tt.want {
+				t.Errorf("Next(%v, %v) = %+v, want %+v", tt.prev, tt.latest, got, tt.want)
+			}
+		})
+	}
+}
+
+// reArm は 1 日のクールダウンの re_arm アラートを返します。
+func reArm(dir Direction, lastSide Direction, lastTriggeredAt *time.Time) Alert {
+	return Alert{Mode: ModeReArm, CooldownMinutes: DefaultReArmCooldownMinutes, Direction: dir, LastSide: lastSide, LastTriggeredAt: lastTriggeredAt}
+}
+
+// TestAlert_Next_Oscillation は閾値の周りを上下する終値の列を順に評価し、
+// 横切りごとに発火しつつクールダウン中の横切りは抑止されることを検証します。
+func TestAlert_Next_Oscillation(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	a := Alert{Mode: ModeReArm, CooldownMinutes: 8 * 60, Direction: DirectionAbove, Threshold: 100}
+
+	// 2 時間ごとの終値（i 番目は i*2h）。100 ちょうどは閾値以上（発火側）
+	closes := []float64{98, 101, 99, 102, 100, 99, 100, 97, 95, 103, 104, 99, 101}
+	// 2h・12h・24h で発火（12h は 10h 経過、24h は 12h 経過）。6h・18h の横切りは前回から 4h・6h でクールダウン（8h）中
+	wantFire := map[int]bool{1: true, 6: true, 12: true}
+	wantSuppressed := map[int]bool{3: true, 9: true}
+	var fired []int
+	for i := 1; i < len(closes); i++ {
+		now := start.Add(time.Duration(i) * 2 * time.Hour)
+		step := a.Next(closes[i-1], closes[i], now)
+		if step.Fire != wantFire[i] || step.Suppressed != wantSuppressed[i] {
+			t.Errorf("bar %d (%v→%v): %+v, want fire=%v suppressed=%v", i, closes[i-1], closes[i], step, wantFire[i], wantSuppressed[i])
+		}
+		if step.Fire {
+			fired = append(fired, i)
+			a.LastTriggeredAt = &now
+		}
+		a.LastSide = step.LastSide
+
+		// 同じ足を再評価しても二度は発火しない
+		if again := a.Next(closes[i-1], closes[i], now.Add(time.Minute)); again.Fire {
+			t.Errorf("bar %d fired again on re-evaluation", i)
+		}
+	}
+	if len(fired) != 3 {
+		t.Errorf("fired at %v, want 3 triggers", fired)
+	}
+}
+
+func TestAlert_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		alert Alert
+		want  error
+	}{
+		{"empty mode is one_shot", Alert{}, nil},
+		{"one_shot", Alert{Mode: ModeOneShot}, nil},
+		{"one_shot with cooldown", Alert{Mode: ModeOneShot, CooldownMinutes: 60}, ErrInvalidCooldown},
+		{"re_arm without cooldown", Alert{Mode: ModeReArm}, nil},
+		{"re_arm at max", Alert{Mode: ModeReArm, CooldownMinutes: MaxCooldownMinutes}, nil},
+		{"re_arm over max", Alert{Mode: ModeReArm, CooldownMinutes: MaxCooldownMinutes + 1}, ErrInvalidCooldown},
+		{"re_arm negative", Alert{Mode: ModeReArm, CooldownMinutes: -1}, ErrInvalidCooldown},
+		{"unknown mode", Alert{Mode: "twice"}, ErrInvalidMode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := tt.alert.Validate(); !errors.Is(err, tt.want) {
+				t.Errorf("Validate() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlert_ChangeMode(t *testing.T) {
+	t.Parallel()
+
+	triggered := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	// 発火済みの one_shot → re_arm: 評価の対象に戻り、反対側へ戻るまで発火しない
+	a := Alert{Direction: DirectionAbove, Threshold: 100, TriggeredAt: &triggered}
+	got, err := a.ChangeMode(ModeReArm, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.TriggeredAt != nil || got.LastTriggeredAt == nil || !got.LastTriggeredAt.Equal(triggered) ||
+		got.LastSide != DirectionAbove || got.Mode != ModeReArm || got.CooldownMinutes != 60 {
+		t.Errorf("one_shot→re_arm = %+v", got)
+	}
+	if a.TriggeredAt == nil {
+		t.Error("ChangeMode must not modify the receiver")
+	}
+
+	// re_arm → one_shot: クールダウンは 0 のみ、LastSide は使わない
+	if _, err := got.ChangeMode(ModeOneShot, 60); !errors.Is(err, ErrInvalidCooldown) {
+		t.Errorf("re_arm→one_shot with cooldown: err = %v", err)
+	}
+	back, err := got.ChangeMode(ModeOneShot, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.Mode != ModeOneShot || back.TriggeredAt != nil || back.LastSide != "" {
+		t.Errorf("re_arm→one_shot = %+v", back)
+	}
+
+	// 未発火の one_shot → re_arm は状態を変えない
+	fresh, err := Alert{Direction: DirectionBelow}.ChangeMode(ModeReArm, DefaultReArmCooldownMinutes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fresh.LastSide != "" || fresh.LastTriggeredAt != nil {
+		t.Errorf("fresh one_shot→re_arm = %+v", fresh)
+	}
+
+	if _, err := a.ChangeMode("forever", 0); !errors.Is(err, ErrInvalidMode) {
+		t.Errorf("unknown mode: err = %v", err)
+	}
+}
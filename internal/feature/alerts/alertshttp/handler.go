@@ -0,0 +1,113 @@
+package alertshttp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/alerts"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+)
+
+// maxImportBodyBytes はインポートのリクエストボディの上限です。
+// エクスポートの応答（1 件 300 バイト前後）を alerts.MaxImportAlerts 件そのまま送れる大きさにします。
+const maxImportBodyBytes = 256 << 10
+
+// Usecase は価格アラートの一括エクスポート・インポートのユースケースインターフェースを定義します。
+type Usecase interface {
+	Export(ctx context.Context, userID int64) ([]alerts.Alert, error)
+	Import(ctx context.Context, userID int64, items []alerts.Alert) (alerts.ImportResult, error)
+}
+
+// Handler は価格アラートに関連するHTTPリクエストを処理します。
+// ユーザーはJWTから取得し、リクエストで他のユーザーを指定する手段は設けません。
+type Handler struct {
+	uc Usecase
+}
+
+// NewHandler はHandlerの新しいインスタンスを生成します。
+func NewHandler(uc Usecase) *Handler {
+	return &Handler{uc: uc}
+}
+
+// Export はログインユーザーの全てのアラート（発火済みを含む）を ID 順に返します。
+func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
+	userID, ok := jwt.UserIDFromContext(r.Context())
+	if !ok {
+		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
+		return
+	}
+	as, err := h.uc.Export(r.Context(), userID)
+	if err != nil {
+		httpx.WriteError(w, err, "failed to export alerts", "userID", userID)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	httpx.WriteJSON(w, http.StatusOK, api.AlertExport{Alerts: toAlerts(as)})
+}
+
+// Import は alerts の各要素をログインユーザーのアラートとして登録し、登録したアラートと見送った件数を返します。
+// 不正な要素がある場合は何も登録せず、400 の hint に要素の位置と理由を返します。
+func (h *Handler) Import(w http.ResponseWriter, r *http.Request) {
+	userID, ok := jwt.UserIDFromContext(r.Context())
+	if !ok {
+		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
+		return
+	}
+	var req api.ImportAlertsRequest
+	if err := httpx.DecodeAndValidate(r, &req, httpx.MaxBytes(maxImportBodyBytes)); err != nil {
+		httpx.WriteDecodeError(w, err, "invalid request")
+		return
+	}
+	items := make([]alerts.Alert, len(req.Alerts))
+	for i, in := range req.Alerts {
+		items[i] = alerts.Alert{
+			SymbolCode:      in.Symbol,
+			Interval:        in.Interval,
+			Direction:       alerts.Direction(in.Direction),
+			Threshold:       in.Threshold,
+			Mode:            alerts.Mode(in.Mode),
+			CooldownMinutes: in.CooldownMinutes,
+		}
+	}
+
+	res, err := h.uc.Import(r.Context(), userID, items)
+	if err != nil {
+		httpx.WriteError(w, err, "failed to import alerts", "userID", userID, "count", len(items))
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, api.ImportAlertsResult{Created: toAlerts(res.Created), Skipped: res.Skipped})
+}
+
+func toAlerts(as []alerts.Alert) []api.Alert {
+	out := make([]api.Alert, 0, len(as))
+	for _, a := range as {
+		out = append(out, toAlert(a))
+	}
+	return out
+}
+
+func toAlert(a alerts.Alert) api.Alert {
+	mode := a.Mode
+	if mode == "" {
+		mode = alerts.ModeOneShot
+	}
+	out := api.Alert{
+		Id:                a.ID,
+		Symbol:            a.SymbolCode,
+		Interval:          a.Interval,
+		Direction:         api.AlertDirection(a.Direction),
+		Threshold:         a.Threshold,
+		Mode:              api.AlertMode(mode),
+		CooldownMinutes:   a.CooldownMinutes,
+		CreatedAt:         api.NewTimestamp(a.CreatedAt),
+		OriginalThreshold: a.OriginalThreshold,
+		AdjustmentId:      a.AdjustmentID,
+	}
+	if a.TriggeredAt != nil {
+		t := api.NewTimestamp(*a.TriggeredAt)
+		out.TriggeredAt = &t
+	}
+	return out
+}
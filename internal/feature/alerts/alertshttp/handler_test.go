@@ -0,0 +1,147 @@
+package alertshttp_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/alerts"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/alerts/alertshttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+)
+
+const testUserID int64 = 1
+
+// mockUsecase は Usecase インターフェースのモック実装です。インポートした要素を記録します。
+type mockUsecase struct {
+	exported []alerts.Alert
+	imported []alerts.Alert
+	result   alerts.ImportResult
+	err      error
+}
+
+func (m *mockUsecase) Export(_ context.Context, _ int64) ([]alerts.Alert, error) {
+	return m.exported, m.err
+}
+
+func (m *mockUsecase) Import(_ context.Context, _ int64, items []alerts.Alert) (alerts.ImportResult, error) {
+	m.imported = items
+	return m.result, m.err
+}
+
+// newRouter は認証済みユーザーIDを context に注入し、本番と同じパスでハンドラーを登録した chi ルーターを構築します。
+func newRouter(uc *mockUsecase) chi.Router {
+	h := alertshttp.NewHandler(uc)
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			next.ServeHTTP(w, req.WithContext(jwt.WithUserID(req.Context(), testUserID)))
+		})
+	})
+	r.Get("/me/alerts/export", h.Export)
+	r.Post("/me/alerts/import", h.Import)
+	return r
+}
+
+func serve(r http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandler_Export(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	original, adjustmentID := 3000.0, int64(4)
+	uc := &mockUsecase{exported: []alerts.Alert{
+		{ID: 1, SymbolCode: "AAPL", Interval: "1day", Direction: alerts.DirectionAbove, Threshold: 750, CreatedAt: created,
+			OriginalThreshold: &original, AdjustmentID: &adjustmentID},
+		{ID: 2, SymbolCode: "MSFT", Interval: "1week", Direction: alerts.DirectionBelow, Threshold: 300, Mode: alerts.ModeReArm,
+			CooldownMinutes: 60, CreatedAt: created, TriggeredAt: &created},
+	}}
+
+	w := serve(newRouter(uc), http.MethodGet, "/me/alerts/export", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+	var body api.AlertExport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body.Alerts, 2)
+	assert.Equal(t, api.AlertMode("one_shot"), body.Alerts[0].Mode, "空のモードは one_shot で返す")
+	require.NotNil(t, body.Alerts[0].OriginalThreshold)
+	assert.Equal(t, 3000.0, *body.Alerts[0].OriginalThreshold)
+	assert.Nil(t, body.Alerts[0].TriggeredAt)
+	assert.Equal(t, 60, body.Alerts[1].CooldownMinutes)
+	assert.NotNil(t, body.Alerts[1].TriggeredAt)
+}
+
+func TestHandler_Import(t *testing.T) {
+	t.Parallel()
+
+	uc := &mockUsecase{result: alerts.ImportResult{
+		Created: []alerts.Alert{{ID: 10, SymbolCode: "AAPL", Interval: "1day", Direction: alerts.DirectionAbove, Threshold: 100, Mode: alerts.ModeOneShot}},
+		Skipped: 1,
+	}}
+	w := serve(newRouter(uc), http.MethodPost, "/me/alerts/import", `{"alerts":[
+		{"symbol":"AAPL","interval":"1day","direction":"above","threshold":100},
+		{"symbol":"MSFT","interval":"1day","direction":"below","threshold":50,"mode":"re_arm","cooldown_minutes":30}
+	]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var body api.ImportAlertsResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, 1, body.Skipped)
+	require.Len(t, body.Created, 1)
+	assert.Equal(t, int64(10), body.Created[0].Id)
+
+	require.Len(t, uc.imported, 2)
+	assert.Equal(t, alerts.Alert{SymbolCode: "MSFT", Interval: "1day", Direction: alerts.DirectionBelow, Threshold: 50,
+		Mode: alerts.ModeReArm, CooldownMinutes: 30}, uc.imported[1])
+}
+
+func TestHandler_Import_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		body     string
+		err      error
+		wantCode int
+		wantHint string
+	}{
+		{"invalid item", `{"alerts":[{"symbol":"AAPL","interval":"1day","direction":"up","threshold":1}]}`,
+			&alerts.ItemError{Index: 0, Err: alerts.ErrInvalidDirection}, http.StatusBadRequest,
+			"alerts[0]: alert direction must be above or below"},
+		{"too many", `{"alerts":[]}`, alerts.ErrTooManyAlerts, http.StatusBadRequest, ""},
+		{"missing alerts", `{}`, nil, http.StatusBadRequest, ""},
+		{"malformed json", `{"alerts":`, nil, http.StatusBadRequest, ""},
+		{"too large", `{"alerts":[` + strings.Repeat(" ", 256<<10) + `]}`, nil, http.StatusRequestEntityTooLarge, ""},
+		{"store failure", `{"alerts":[]}`, errors.New("db down"), http.StatusInternalServerError, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			w := serve(newRouter(&mockUsecase{err: tt.err}), http.MethodPost, "/me/alerts/import", tt.body)
+			require.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			var body api.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.NotEmpty(t, body.Error)
+			assert.Equal(t, tt.wantHint, body.Hint)
+		})
+	}
+}
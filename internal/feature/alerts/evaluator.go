@@ -0,0 +1,155 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Repository はアラートの評価に必要な永続化操作を抽象化します。
+// Goの慣例に従い、インターフェースは利用者（evaluator）側で定義します。
+type Repository interface {
+	// FindActiveBySymbolInterval は銘柄・時間間隔の未発火のアラート（re_arm を含む）を返します（複合インデックスで引く）。
+	FindActiveBySymbolInterval(ctx context.Context, symbol, interval string) ([]Alert, error)
+	// UpdateTriggered は ids のうち未発火のアラートに発火日時 at を 1 回の書き込みで記録し、記録した ID を返します。
+	// one_shot は TriggeredAt を、re_arm は LastTriggeredAt と LastSide（発火側）を記録します。
+	// 同時に評価した別のプロセスが先に記録したアラート（re_arm はクールダウン中になったもの）は含めません。
+	UpdateTriggered(ctx context.Context, ids []int64, at time.Time) ([]int64, error)
+	// UpdateLastSides は re_arm のアラートの LastSide を 1 回の書き込みで記録します（発火しない横切り・反対側への戻り）。
+	UpdateLastSides(ctx context.Context, sides map[int64]Direction) error
+}
+
+// Notifier は発火したアラートの通知をまとめて登録します。
+type Notifier interface {
+	Enqueue(ctx context.Context, triggers []Trigger) error
+}
+
+// Evaluator は取り込んだローソク足に対してアラートを評価します。
+//
+// 銘柄ごとに、時間間隔ごとの未発火のアラートを 1 回ずつ読み、最新の足と直前の足でメモリ上で判定します（Alert.Next）。
+// 発火したアラートは銘柄ごとに 1 回の書き込みで記録し、通知も 1 回にまとめて登録します。
+// 発火済みの one_shot は読み込まず、re_arm は LastSide が発火側のままのため、同じ足を再取り込みしても二度は発火しません。
+// re_arm の LastSide の変化（反対側への戻り・クールダウン中の横切り）も銘柄ごとに 1 回の書き込みで記録します。
+type Evaluator struct {
+	repo     Repository
+	notifier Notifier
+	now      func() time.Time
+}
+
+// NewEvaluator は Evaluator の新しいインスタンスを生成します。notifier が nil の場合は通知を登録しません。
+func NewEvaluator(repo Repository, notifier Notifier) *Evaluator {
+	return &Evaluator{repo: repo, notifier: notifier, now: time.Now}
+}
+
+// Evaluate は銘柄 symbol の時間間隔ごとの足（series。順不同）でアラートを評価し、発火したアラートを返します。
+// 足が 2 本未満の時間間隔は評価しません。
+// 通知の登録・LastSide の記録に失敗した場合も発火の記録は取り消さず、発火したアラートとエラーを返します。
+func (e *Evaluator) Evaluate(ctx context.Context, symbol string, series map[string][]Bar) ([]Trigger, error) {
+	intervals := make([]string, 0, len(series))
+	for interval := range series {
+		intervals = append(intervals, interval)
+	}
+	slices.Sort(intervals)
+
+	at := e.now()
+	var triggers []Trigger
+	sides := make(map[int64]Direction)
+	for _, interval := range intervals {
+		prev, latest, ok := lastTwo(series[interval])
+		if !ok {
+			continue
+		}
+		active, err := e.repo.FindActiveBySymbolInterval(ctx, symbol, interval)
+		if err != nil {
+			return nil, fmt.Errorf("find active alerts %s/%s: %w", symbol, interval, err)
+		}
+		for _, a := range active {
+			step := a.Next(prev.Close, latest.Close, at)
+			if step.Fire {
+				triggers = append(triggers, Trigger{
+					Alert:       a,
+					BarTime:     latest.Time,
+					PrevClose:   prev.Close,
+					Close:       latest.Close,
+					TriggeredAt: at,
+				})
+				continue
+			}
+			if a.mode() == ModeReArm && step.LastSide != a.LastSide {
+				sides[a.ID] = step.LastSide
+			}
+		}
+	}
+
+	if len(triggers) > 0 {
+		ids := make([]int64, len(triggers))
+		for i, t := range triggers {
+			ids[i] = t.Alert.ID
+		}
+		updated, err := e.repo.UpdateTriggered(ctx, ids, at)
+		if err != nil {
+			return nil, fmt.Errorf("update triggered alerts %s: %w", symbol, err)
+		}
+		recorded := make(map[int64]struct{}, len(updated))
+		for _, id := range updated {
+			recorded[id] = struct{}{}
+		}
+		triggers = slices.DeleteFunc(triggers, func(t Trigger) bool {
+			if _, ok := recorded[t.Alert.ID]; ok {
+				return false
+			}
+			// 記録できなかった re_arm も横切りは観測済みのため、反対側へ戻るまで再び発火させない
+			if t.Alert.mode() == ModeReArm && t.Alert.LastSide != t.Alert.Direction {
+				sides[t.Alert.ID] = t.Alert.Direction
+			}
+			return true
+		})
+		for i := range triggers {
+			a := &triggers[i].Alert
+			a.LastTriggeredAt = &at
+			if a.mode() == ModeReArm {
+				a.LastSide = a.Direction
+			} else {
+				a.TriggeredAt = &at
+			}
+		}
+	}
+	if len(triggers) == 0 {
+		triggers = nil
+	}
+
+	var errs []error
+	if e.notifier != nil && len(triggers) > 0 {
+		if err := e.notifier.Enqueue(ctx, triggers); err != nil {
+			errs = append(errs, fmt.Errorf("enqueue alert notifications %s: %w", symbol, err))
+		}
+	}
+	if len(sides) > 0 {
+		if err := e.repo.UpdateLastSides(ctx, sides); err != nil {
+			errs = append(errs, fmt.Errorf("update alert sides %s: %w", symbol, err))
+		}
+	}
+	return triggers, errors.Join(errs...)
+}
+
+// lastTwo は bars のうち時刻が最も新しい足と、その直前の足を返します。2 本未満の場合は ok=false を返します。
+func lastTwo(bars []Bar) (prev, latest Bar, ok bool) {
+	if len(bars) < 2 {
+		return Bar{}, Bar{}, false
+	}
+	latest, prev = bars[0], bars[1]
+	if prev.Time.After(latest.Time) {
+		latest, prev = prev, latest
+	}
+	for _, b := range bars[2:] {
+		switch {
+		case b.Time.After(latest.Time):
+			prev, latest = latest, b
+		case b.Time.After(prev.Time):
+			prev = b
+		}
+	}
+	return prev, latest, true
+}
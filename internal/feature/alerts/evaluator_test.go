@@ -0,0 +1,379 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memRepository は (銘柄, 時間間隔) をキーにした索引を持つインメモリの Repository です。
+// 複合インデックスでの取得と、未発火の行のみを更新する UpdateTriggered（re_arm はクールダウン経過後のみ）を再現します。
+type memRepository struct {
+	mu          sync.Mutex
+	alerts      map[int64]*Alert
+	index       map[string][]int64
+	finds       int
+	updates     int
+	sideUpdates int
+}
+
+func newMemRepository(alerts ...Alert) *memRepository {
+	r := &memRepository{alerts: map[int64]*Alert{}, index: map[string][]int64{}}
+	for _, a := range alerts {
+		r.alerts[a.ID] = &a
+		key := a.SymbolCode + "/" + a.Interval
+		r.index[key] = append(r.index[key], a.ID)
+	}
+	return r
+}
+
+func (r *memRepository) FindActiveBySymbolInterval(_ context.Context, symbol, interval string) ([]Alert, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.finds++
+	var out []Alert
+	for _, id := range r.index[symbol+"/"+interval] {
+		if a := r.alerts[id]; a.TriggeredAt == nil {
+			out = append(out, *a)
+		}
+	}
+	return out, nil
+}
+
+func (r *memRepository) UpdateTriggered(_ context.Context, ids []int64, at time.Time) ([]int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.updates++
+	var updated []int64
+	for _, id := range ids {
+		a := r.alerts[id]
+		if a == nil || a.TriggeredAt != nil {
+			continue
+		}
+		if a.mode() == ModeReArm {
+			if a.LastTriggeredAt != nil && at.Sub(*a.LastTriggeredAt) < time.Duration(a.CooldownMinutes)*time.Minute {
+				continue
+			}
+			a.LastSide = a.Direction
+		} else {
+			a.TriggeredAt = &at
+		}
+		a.LastTriggeredAt = &at
+		updated = append(updated, id)
+	}
+	return updated, nil
+}
+
+func (r *memRepository) UpdateLastSides(_ context.Context, sides map[int64]Direction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sideUpdates++
+	for id, side := range sides {
+		if a := r.alerts[id]; a != nil && a.mode() == ModeReArm {
+			a.LastSide = side
+		}
+	}
+	return nil
+}
+
+// recordingNotifier は登録された通知をまとめて記録します。
+type recordingNotifier struct {
+	batches [][]Trigger
+	err     error
+}
+
+func (n *recordingNotifier) Enqueue(_ context.Context, triggers []Trigger) error {
+	n.batches = append(n.batches, triggers)
+	return n.err
+}
+
+func triggeredIDs(triggers []Trigger) []int64 {
+	ids := make([]int64, len(triggers))
+	for i, t := range triggers {
+		ids[i] = t.Alert.ID
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+var (
+	day1 = time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	day2 = day1.AddDate(0, 0, 1)
+	day3 = day1.AddDate(0, 0, 2)
+)
+
+// TestEvaluator_Evaluate_TriggersCrossedOnce は横切ったアラートだけが発火し、
+// 同じ足を繰り返し取り込んでも一度しか発火しないことを検証します。
+func TestEvaluator_Evaluate_TriggersCrossedOnce(t *testing.T) {
+	t.Parallel()
+
+	repo := newMemRepository(
+		Alert{ID: 1, SymbolCode: "AAPL", Interval: "1day", Direction: DirectionAbove, Threshold: 105},  // 100 → 110 で横切る
+		Alert{ID: 2, SymbolCode: "AAPL", Interval: "1day", Direction: DirectionAbove, Threshold: 120},  // 届かない
+		Alert{ID: 3, SymbolCode: "AAPL", Interval: "1day", Direction: DirectionBelow, Threshold: 105},  // 逆方向
+		Alert{ID: 4, SymbolCode: "AAPL", Interval: "1day", Direction: DirectionAbove, Threshold: 100},  // 直前がちょうど閾値
+		Alert{ID: 5, SymbolCode: "AAPL", Interval: "1week", Direction: DirectionBelow, Threshold: 95},  // 週足で横切る
+		Alert{ID: 6, SymbolCode: "GOOGL", Interval: "1day", Direction: DirectionAbove, Threshold: 105}, // 別銘柄
+	)
+	notifier := &recordingNotifier{}
+	e := NewEvaluator(repo, notifier)
+	e.now = func() time.Time { return day3 }
+
+	// 順不同で渡しても最新の 2 本（day1 → day2）で判定する
+	series := map[string][]Bar{
+		"1day":   {{Time: day2, Close: 110}, {Time: day1.AddDate(0, 0, -1), Close: 130}, {Time: day1, Close: 100}},
+		"1week":  {{Time: day1, Close: 100}, {Time: day2, Close: 90}},
+		"1month": {{Time: day1, Close: 100}}, // 1 本だけの時間間隔は評価しない
+	}
+	got, err := e.Evaluate(context.Background(), "AAPL", series)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ids := triggeredIDs(got); !slices.Equal(ids, []int64{1, 5}) {
+		t.Fatalf("triggered %v, want [1 5]", ids)
+	}
+	for _, tr := range got {
+		if !tr.BarTime.Equal(day2) || tr.TriggeredAt != day3 || tr.Alert.TriggeredAt == nil {
+			t.Errorf("trigger %+v: want bar day2, triggered at day3", tr)
+		}
+	}
+	if repo.finds != 2 || repo.updates != 1 {
+		t.Errorf("finds=%d updates=%d, want one find per evaluated interval and a single batched update", repo.finds, repo.updates)
+	}
+	if len(notifier.batches) != 1 || len(notifier.batches[0]) != 2 {
+		t.Errorf("notifications = %v, want one batch of 2", notifier.batches)
+	}
+
+	// 同じ足の再取り込みでは発火済みのアラートを読み込まないため、二度は発火しない
+	for range 3 {
+		got, err = e.Evaluate(context.Background(), "AAPL", series)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != 0 {
+			t.Fatalf("re-ingest triggered %v again", triggeredIDs(got))
+		}
+	}
+	if repo.updates != 1 || len(notifier.batches) != 1 {
+		t.Errorf("re-ingest wrote %d updates and %d notification batches, want 1 and 1", repo.updates, len(notifier.batches))
+	}
+}
+
+// TestEvaluator_Evaluate_SkipsAlertsTriggeredConcurrently は評価中に別のプロセスが先に発火を記録したアラートを
+// 通知しないことを検証します。
+func TestEvaluator_Evaluate_SkipsAlertsTriggeredConcurrently(t *testing.T) {
+	t.Parallel()
+
+	repo := newMemRepository(
+		Alert{ID: 1, SymbolCode: "AAPL", Interval: "1day", Direction: DirectionAbove, Threshold: 105},
+		Alert{ID: 2, SymbolCode: "AAPL", Interval: "1day", Direction: DirectionAbove, Threshold: 106},
+	)
+	racing := &racingRepository{memRepository: repo, steal: 2}
+	notifier := &recordingNotifier{}
+
+	got, err := NewEvaluator(racing, notifier).Evaluate(context.Background(), "AAPL", map[string][]Bar{
+		"1day": {{Time: day1, Close: 100}, {Time: day2, Close: 110}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ids := triggeredIDs(got); !slices.Equal(ids, []int64{1}) {
+		t.Errorf("triggered %v, want [1]", ids)
+	}
+	if len(notifier.batches) != 1 || len(notifier.batches[0]) != 1 {
+		t.Errorf("notifications = %v, want only alert 1", notifier.batches)
+	}
+}
+
+// racingRepository は UpdateTriggered の直前に steal のアラートを別のプロセスが発火させたことを再現します。
+type racingRepository struct {
+	*memRepository
+	steal int64
+}
+
+func (r *racingRepository) UpdateTriggered(ctx context.Context, ids []int64, at time.Time) ([]int64, error) {
+	if _, err := r.memRepository.UpdateTriggered(ctx, []int64{r.steal}, at); err != nil {
+		return nil, err
+	}
+	return r.memRepository.UpdateTriggered(ctx, ids, at)
+}
+
+// TestEvaluator_Evaluate_ReArmCooldown は re_arm のアラートが複数回の ingest をまたいで、
+// 反対側へ戻った後の横切りで再び発火し、クールダウン中と同じ足の再取り込みでは通知しないことを検証します。
+func TestEvaluator_Evaluate_ReArmCooldown(t *testing.T) {
+	t.Parallel()
+
+	repo := newMemRepository(
+		Alert{ID: 1, SymbolCode: "AAPL", Interval: "1day", Direction: DirectionAbove, Threshold: 105,
+			Mode: ModeReArm, CooldownMinutes: DefaultReArmCooldownMinutes},
+		Alert{ID: 2, SymbolCode: "AAPL", Interval: "1day", Direction: DirectionAbove, Threshold: 105}, // one_shot
+	)
+	notifier := &recordingNotifier{}
+	e := NewEvaluator(repo, notifier)
+	clock := time.Date(2026, 9, 1, 22, 0, 0, 0, time.UTC)
+	e.now = func() time.Time { return clock }
+
+	// ingest ごとに最新の 2 本を渡す（outbox の candles.upserted と同じ）
+	bars := []Bar{{Time: day1, Close: 100}}
+	ingest := func(close float64, after time.Duration, runs int) [][]int64 {
+		t.Helper()
+		clock = clock.Add(after)
+		bars = append(bars, Bar{Time: bars[len(bars)-1].Time.AddDate(0, 0, 1), Close: close})
+		var fired [][]int64
+		for range runs { // 再取り込み・outbox の再配信
+			got, err := e.Evaluate(context.Background(), "AAPL", map[string][]Bar{"1day": bars[len(bars)-2:]})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			fired = append(fired, triggeredIDs(got))
+		}
+		return fired
+	}
+	assertFired := func(step string, got [][]int64, want ...[]int64) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Fatalf("%s: fired %v, want %v", step, got, want)
+		}
+		for i := range got {
+			if !slices.Equal(got[i], want[i]) {
+				t.Errorf("%s: run %d fired %v, want %v", step, i, got[i], want[i])
+			}
+		}
+	}
+
+	// 100 → 110: 両方発火。同じ足の再取り込みでは二度は発火しない
+	assertFired("first cross", ingest(110, 0, 3), []int64{1, 2}, nil, nil)
+	// 110 → 100: 反対側へ戻る（re_arm の LastSide を記録）
+	assertFired("back below", ingest(100, 2*time.Hour, 2), nil, nil)
+	if repo.alerts[1].LastSide != DirectionBelow {
+		t.Fatalf("LastSide = %q, want below", repo.alerts[1].LastSide)
+	}
+	// 100 → 108: 前回の発火から 4 時間でクールダウン中のため通知しない
+	assertFired("within cooldown", ingest(108, 2*time.Hour, 2), nil, nil)
+	// 108 → 100 → 107: 前回の発火から 1 日以上経過した再度の横切りで発火（one_shot は発火済みのまま）
+	assertFired("back below again", ingest(100, 10*time.Hour, 1), nil)
+	assertFired("after cooldown", ingest(107, 12*time.Hour, 3), []int64{1}, nil, nil)
+
+	if got := len(notifier.batches); got != 2 {
+		t.Errorf("notification batches = %d, want 2", got)
+	}
+	a := repo.alerts[1]
+	if a.TriggeredAt != nil || a.LastTriggeredAt == nil || !a.LastTriggeredAt.Equal(clock) || a.LastSide != DirectionAbove {
+		t.Errorf("re_arm alert state = %+v", a)
+	}
+	if repo.alerts[2].TriggeredAt == nil {
+		t.Error("one_shot alert must stay triggered")
+	}
+}
+
+// TestEvaluator_Evaluate_ReArmLostRace は別のプロセスが先に発火を記録した re_arm のアラートを通知せず、
+// 横切りを観測済みとして LastSide を発火側にすることを検証します。
+func TestEvaluator_Evaluate_ReArmLostRace(t *testing.T) {
+	t.Parallel()
+
+	repo := newMemRepository(Alert{ID: 1, SymbolCode: "AAPL", Interval: "1day", Direction: DirectionAbove, Threshold: 105,
+		Mode: ModeReArm, CooldownMinutes: 60, LastSide: DirectionBelow})
+	racing := &racingRepository{memRepository: repo, steal: 1}
+	notifier := &recordingNotifier{}
+
+	got, err := NewEvaluator(racing, notifier).Evaluate(context.Background(), "AAPL", map[string][]Bar{
+		"1day": {{Time: day1, Close: 100}, {Time: day2, Close: 110}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 0 || len(notifier.batches) != 0 {
+		t.Errorf("triggered %v, notifications %v; want none", triggeredIDs(got), notifier.batches)
+	}
+	if repo.alerts[1].LastSide != DirectionAbove {
+		t.Errorf("LastSide = %q, want above", repo.alerts[1].LastSide)
+	}
+}
+
+func TestEvaluator_Evaluate_NotifierError(t *testing.T) {
+	t.Parallel()
+
+	repo := newMemRepository(Alert{ID: 1, SymbolCode: "AAPL", Interval: "1day", Direction: DirectionAbove, Threshold: 105})
+	notifier := &recordingNotifier{err: errors.New("queue down")}
+
+	got, err := NewEvaluator(repo, notifier).Evaluate(context.Background(), "AAPL", map[string][]Bar{
+		"1day": {{Time: day1, Close: 100}, {Time: day2, Close: 110}},
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if ids := triggeredIDs(got); !slices.Equal(ids, []int64{1}) {
+		t.Errorf("triggered %v, want [1] returned with the error", ids)
+	}
+	if repo.alerts[1].TriggeredAt == nil {
+		t.Error("the trigger must stay recorded")
+	}
+}
+
+// BenchmarkEvaluator_IngestBatch は 100 銘柄に分散した 10,000 件のアラートに対し、
+// 1 回の ingest（全銘柄の日足・週足・月足）での評価コストを測ります。
+// 閾値は足の終値の近くに散らしており、毎回約 1 割のアラートが横切ります（発火済みは都度リセット）。
+func BenchmarkEvaluator_IngestBatch(b *testing.B) {
+	const (
+		symbols         = 100
+		alertsPerSymbol = 100
+	)
+	intervals := []string{"1day", "1week", "1month"}
+
+	var all []Alert
+	for s := range symbols {
+		for i := range alertsPerSymbol {
+			dir := DirectionAbove
+			if i%2 == 1 {
+				dir = DirectionBelow
+			}
+			all = append(all, Alert{
+				ID:         int64(s*alertsPerSymbol + i + 1),
+				SymbolCode: fmt.Sprintf("SYM%03d", s),
+				Interval:   intervals[i%len(intervals)],
+				Direction:  dir,
+				Threshold:  90 + float64(i%20),
+			})
+		}
+	}
+	repo := newMemRepository(all...)
+
+	series := make(map[string][]Bar, len(intervals))
+	bars := make([]Bar, 0, 200)
+	for d := range 200 {
+		bars = append(bars, Bar{Time: day1.AddDate(0, 0, d), Close: 100})
+	}
+	bars[len(bars)-2].Close = 98
+	bars[len(bars)-1].Close = 100.5
+	for _, interval := range intervals {
+		series[interval] = bars
+	}
+
+	e := NewEvaluator(repo, &recordingNotifier{})
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for b.Loop() {
+		b.StopTimer()
+		for _, a := range repo.alerts {
+			a.TriggeredAt = nil
+		}
+		b.StartTimer()
+
+		triggered := 0
+		for s := range symbols {
+			got, err := e.Evaluate(ctx, fmt.Sprintf("SYM%03d", s), series)
+			if err != nil {
+				b.Fatal(err)
+			}
+			triggered += len(got)
+		}
+		if triggered == 0 {
+			b.Fatal("expected some alerts to trigger")
+		}
+	}
+}
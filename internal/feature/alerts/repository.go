@@ -0,0 +1,360 @@
+package alerts
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/alerts/sqlc"
+)
+
+// pgForeignKeyViolation は外部キー制約違反の SQLSTATE です（存在しない銘柄のアラートの登録）。
+const pgForeignKeyViolation = "23503"
+
+// repository は Repository の sqlc + 生 SQL 実装です。
+// UpdateTriggered・UpdateLastSides・MigrateSplit は可変個の ID を 1 ステートメントで更新するため raw SQL を組み立てます
+// （database/sql の sqlc 生成コードでは配列パラメータに lib/pq が必要になるため）。
+type repository struct {
+	db *sql.DB
+	q  *alertssqlc.Queries
+}
+
+var (
+	_ Repository      = (*repository)(nil)
+	_ SplitRepository = (*repository)(nil)
+	_ Store           = (*repository)(nil)
+)
+
+// NewRepository は指定された *sql.DB で repository の新しいインスタンスを生成します。
+func NewRepository(db *sql.DB) *repository {
+	return &repository{db: db, q: alertssqlc.New(db)}
+}
+
+// Create はアラートを登録し、a.ID と a.CreatedAt を設定します。
+// モードとクールダウンは Validate で検証します（Mode が空の場合は one_shot で登録します）。
+func (r *repository) Create(ctx context.Context, a *Alert) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+	a.Mode = a.mode()
+	row, err := r.q.InsertAlert(ctx, alertssqlc.InsertAlertParams{
+		UserID:          a.UserID,
+		SymbolCode:      a.SymbolCode,
+		Interval:        a.Interval,
+		Direction:       string(a.Direction),
+		Threshold:       a.Threshold,
+		Mode:            string(a.Mode),
+		CooldownMinutes: int32(a.CooldownMinutes),
+	})
+	if err != nil {
+		return err
+	}
+	a.ID = row.ID
+	a.CreatedAt = row.CreatedAt
+	return nil
+}
+
+// CreateMany は alerts を 1 つのトランザクションで登録し、ID と CreatedAt を設定したアラートを返します。
+// 1 件でも登録できない場合は何も登録せず、i 番目（0 始まり）の失敗を *ItemError で返します
+// （存在しない銘柄は ErrUnknownSymbol）。
+func (r *repository) CreateMany(ctx context.Context, alerts []Alert) ([]Alert, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback()
+		}
+	}()
+	qtx := r.q.WithTx(tx)
+
+	out := make([]Alert, 0, len(alerts))
+	for i, a := range alerts {
+		if err := a.Validate(); err != nil {
+			return nil, &ItemError{Index: i, Err: err}
+		}
+		a.Mode = a.mode()
+		row, err := qtx.InsertAlert(ctx, alertssqlc.InsertAlertParams{
+			UserID:          a.UserID,
+			SymbolCode:      a.SymbolCode,
+			Interval:        a.Interval,
+			Direction:       string(a.Direction),
+			Threshold:       a.Threshold,
+			Mode:            string(a.Mode),
+			CooldownMinutes: int32(a.CooldownMinutes),
+		})
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+				return nil, &ItemError{Index: i, Err: ErrUnknownSymbol}
+			}
+			return nil, fmt.Errorf("insert alert %d: %w", i, err)
+		}
+		a.ID = row.ID
+		a.CreatedAt = row.CreatedAt
+		out = append(out, a)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+	committed = true
+	return out, nil
+}
+
+// ListByUser はユーザー userID の全てのアラート（発火済みを含む）を ID 順に返します。
+func (r *repository) ListByUser(ctx context.Context, userID int64) ([]Alert, error) {
+	rows, err := r.q.ListAlertsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Alert, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, toAlert(alertssqlc.FindActiveAlertsBySymbolIntervalRow(row)))
+	}
+	return out, nil
+}
+
+// Get は ID でアラートを返します。存在しない場合は sql.ErrNoRows を返します。
+func (r *repository) Get(ctx context.Context, id int64) (Alert, error) {
+	row, err := r.q.GetAlert(ctx, id)
+	if err != nil {
+		return Alert{}, err
+	}
+	return toAlert(alertssqlc.FindActiveAlertsBySymbolIntervalRow(row)), nil
+}
+
+// ChangeMode はアラート id のモードとクールダウンを Alert.ChangeMode の規則で切り替え、切り替え後のアラートを返します。
+func (r *repository) ChangeMode(ctx context.Context, id int64, mode Mode, cooldownMinutes int) (Alert, error) {
+	current, err := r.Get(ctx, id)
+	if err != nil {
+		return Alert{}, err
+	}
+	next, err := current.ChangeMode(mode, cooldownMinutes)
+	if err != nil {
+		return Alert{}, err
+	}
+	params := alertssqlc.UpdateAlertModeParams{
+		ID:              id,
+		Mode:            string(next.Mode),
+		CooldownMinutes: int32(next.CooldownMinutes),
+		LastSide:        sql.NullString{String: string(next.LastSide), Valid: next.LastSide != ""},
+	}
+	if next.TriggeredAt != nil {
+		params.TriggeredAt = sql.NullTime{Time: *next.TriggeredAt, Valid: true}
+	}
+	if err := r.q.UpdateAlertMode(ctx, params); err != nil {
+		return Alert{}, err
+	}
+	return next, nil
+}
+
+// FindActiveBySymbolInterval は銘柄・時間間隔の未発火のアラートを ID 順に返します。
+func (r *repository) FindActiveBySymbolInterval(ctx context.Context, symbol, interval string) ([]Alert, error) {
+	rows, err := r.q.FindActiveAlertsBySymbolInterval(ctx, alertssqlc.FindActiveAlertsBySymbolIntervalParams{
+		SymbolCode: symbol,
+		Interval:   interval,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Alert, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, toAlert(row))
+	}
+	return out, nil
+}
+
+// UpdateTriggered は ids のうち未発火のアラートに発火日時 at を記録し、記録した ID を返します。
+// one_shot は triggered_at を、re_arm は last_triggered_at と last_side を記録します。
+// 1 ステートメントで全件処理するため round-trip は 1 回です。
+func (r *repository) UpdateTriggered(ctx context.Context, ids []int64, at time.Time) ([]int64, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	// one_shot は triggered_at を記録して評価の対象から外す。re_arm は前回の発火からクールダウンが経過している場合のみ記録する
+	// （同時に評価した別のプロセスが先に記録した場合は、経過していないため含めない）。
+	var sb strings.Builder
+	sb.WriteString(`UPDATE alerts SET
+		triggered_at = CASE WHEN mode = 'one_shot' THEN $1 ELSE triggered_at END,
+		last_triggered_at = $1,
+		last_side = CASE WHEN mode = 're_arm' THEN direction ELSE last_side END
+	WHERE triggered_at IS NULL
+	  AND (mode = 'one_shot' OR last_triggered_at IS NULL OR last_triggered_at + make_interval(mins => cooldown_minutes) <= $1)
+	  AND id IN (`)
+	args := make([]any, 0, len(ids)+1)
+	args = append(args, at)
+	for i, id := range ids {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(&sb, "$%d", i+2)
+		args = append(args, id)
+	}
+	sb.WriteString(`) RETURNING id`)
+
+	rows, err := r.db.QueryContext(ctx, sb.String(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("update triggered alerts: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	updated := make([]int64, 0, len(ids))
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		updated = append(updated, id)
+	}
+	return updated, rows.Err()
+}
+
+// UpdateLastSides は re_arm のアラートの last_side を記録します。VALUES の一覧と結合し 1 ステートメントで全件更新します。
+func (r *repository) UpdateLastSides(ctx context.Context, sides map[int64]Direction) error {
+	if len(sides) == 0 {
+		return nil
+	}
+	ids := make([]int64, 0, len(sides))
+	for id := range sides {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids) // 行ロックの取得順をそろえる
+
+	var sb strings.Builder
+	sb.WriteString(`UPDATE alerts AS a SET last_side = v.side FROM (VALUES `)
+	args := make([]any, 0, 2*len(ids))
+	for i, id := range ids {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(&sb, "($%d::bigint, $%d::varchar)", 2*i+1, 2*i+2)
+		args = append(args, id, string(sides[id]))
+	}
+	sb.WriteString(`) AS v(id, side) WHERE a.id = v.id AND a.mode = 're_arm'`)
+
+	if _, err := r.db.ExecContext(ctx, sb.String(), args...); err != nil {
+		return fmt.Errorf("update alert sides: %w", err)
+	}
+	return nil
+}
+
+// MigrateSplit は銘柄 s.SymbolCode の未発火のアラートを行ロックして読み、Alert.NeedsSplitMigration のものの閾値を
+// Alert.SplitThreshold に書き換えます。original_threshold は最初の書き換えの前の値を残し、adjustment_id に s.AdjustmentID を記録します。
+// 行ロックにより同じ調整の同時実行は直列化され、後の実行は書き換え済み（adjustment_id が一致）の行を書き換えません。
+func (r *repository) MigrateSplit(ctx context.Context, s Split) ([]Migration, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback()
+		}
+	}()
+
+	rows, err := r.q.WithTx(tx).LockActiveAlertsBySymbol(ctx, s.SymbolCode)
+	if err != nil {
+		return nil, fmt.Errorf("lock alerts: %w", err)
+	}
+	var migrations []Migration
+	for _, row := range rows {
+		a := toAlert(alertssqlc.FindActiveAlertsBySymbolIntervalRow(row))
+		if !a.NeedsSplitMigration(s) {
+			continue
+		}
+		prev := a.Threshold
+		if a.OriginalThreshold == nil {
+			a.OriginalThreshold = &prev
+		}
+		a.Threshold = a.SplitThreshold(s.Factor)
+		id := s.AdjustmentID
+		a.AdjustmentID = &id
+		migrations = append(migrations, Migration{Alert: a, PreviousThreshold: prev, Split: s})
+	}
+
+	if len(migrations) > 0 {
+		var sb strings.Builder
+		sb.WriteString(`UPDATE alerts AS a SET threshold = v.threshold, original_threshold = v.original, adjustment_id = $1 FROM (VALUES `)
+		args := make([]any, 0, 3*len(migrations)+1)
+		args = append(args, s.AdjustmentID)
+		for i, m := range migrations {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			fmt.Fprintf(&sb, "($%d::bigint, $%d::double precision, $%d::double precision)", 3*i+2, 3*i+3, 3*i+4)
+			args = append(args, m.Alert.ID, m.Alert.Threshold, *m.Alert.OriginalThreshold)
+		}
+		sb.WriteString(`) AS v(id, threshold, original) WHERE a.id = v.id`)
+		if _, err := tx.ExecContext(ctx, sb.String(), args...); err != nil {
+			return nil, fmt.Errorf("update split thresholds: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+	committed = true
+	return migrations, nil
+}
+
+// RecordDelivered はアラート alertID の通知をいずれかの端末に送信できた日時 at を記録します。
+// 既に記録済みの場合は最初の日時を残します。
+func (r *repository) RecordDelivered(ctx context.Context, alertID int64, at time.Time) error {
+	return r.q.RecordAlertNotified(ctx, alertssqlc.RecordAlertNotifiedParams{
+		ID:         alertID,
+		NotifiedAt: sql.NullTime{Time: at, Valid: true},
+	})
+}
+
+// RecordDeliveryFailure はアラート alertID の通知を諦めた理由を記録します（最後の失敗で上書きします）。
+func (r *repository) RecordDeliveryFailure(ctx context.Context, alertID int64, reason string) error {
+	return r.q.RecordAlertNotifyError(ctx, alertssqlc.RecordAlertNotifyErrorParams{
+		ID:          alertID,
+		NotifyError: sql.NullString{String: reason, Valid: true},
+	})
+}
+
+func toAlert(row alertssqlc.FindActiveAlertsBySymbolIntervalRow) Alert {
+	a := Alert{
+		ID:         row.ID,
+		UserID:     row.UserID,
+		SymbolCode: row.SymbolCode,
+		Interval:   row.Interval,
+		Direction:  Direction(row.Direction),
+		Threshold:  row.Threshold,
+		CreatedAt:  row.CreatedAt,
+
+		Mode:            Mode(row.Mode),
+		CooldownMinutes: int(row.CooldownMinutes),
+		LastSide:        Direction(row.LastSide.String),
+	}
+	if row.TriggeredAt.Valid {
+		t := row.TriggeredAt.Time
+		a.TriggeredAt = &t
+	}
+	if row.LastTriggeredAt.Valid {
+		t := row.LastTriggeredAt.Time
+		a.LastTriggeredAt = &t
+	}
+	if row.OriginalThreshold.Valid {
+		v := row.OriginalThreshold.Float64
+		a.OriginalThreshold = &v
+	}
+	if row.AdjustmentID.Valid {
+		id := row.AdjustmentID.Int64
+		a.AdjustmentID = &id
+	}
+	if row.EditedAt.Valid {
+		t := row.EditedAt.Time
+		a.EditedAt = &t
+	}
+	return a
+}
@@ -0,0 +1,329 @@
+package alerts
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/db/dbtest"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/testsupport"
+)
+
+func TestMain(m *testing.M) {
+	code, err := dbtest.RunMainWithPostgres(m)
+	if err != nil {
+		log.Fatalf("dbtest setup: %v", err)
+	}
+	os.Exit(code)
+}
+
+func TestRepository_FindActiveAndUpdateTriggered(t *testing.T) {
+	t.Parallel()
+	db := testsupport.NewDB(t, testsupport.InsertSymbols("AAPL", "GOOGL"))
+	userID := testsupport.InsertUser(t, db).ID
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	create := func(symbol, interval string, dir Direction, threshold float64) int64 {
+		a := &Alert{UserID: userID, SymbolCode: symbol, Interval: interval, Direction: dir, Threshold: threshold}
+		require.NoError(t, repo.Create(ctx, a))
+		require.NotZero(t, a.ID)
+		require.False(t, a.CreatedAt.IsZero())
+		return a.ID
+	}
+	a1 := create("AAPL", "1day", DirectionAbove, 105)
+	a2 := create("AAPL", "1day", DirectionBelow, 95.5)
+	create("AAPL", "1week", DirectionAbove, 105)
+	create("GOOGL", "1day", DirectionAbove, 105)
+
+	active, err := repo.FindActiveBySymbolInterval(ctx, "AAPL", "1day")
+	require.NoError(t, err)
+	require.Len(t, active, 2)
+	assert.Equal(t, a1, active[0].ID)
+	assert.Equal(t, DirectionAbove, active[0].Direction)
+	assert.Equal(t, 95.5, active[1].Threshold)
+	assert.Nil(t, active[0].TriggeredAt)
+
+	at := time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC)
+	updated, err := repo.UpdateTriggered(ctx, []int64{a1, a2}, at)
+	require.NoError(t, err)
+	slices.Sort(updated)
+	assert.Equal(t, []int64{a1, a2}, updated)
+
+	// 発火済みのアラートは取得せず、再度の記録もしない（発火日時は最初の記録のまま）
+	active, err = repo.FindActiveBySymbolInterval(ctx, "AAPL", "1day")
+	require.NoError(t, err)
+	assert.Empty(t, active)
+	updated, err = repo.UpdateTriggered(ctx, []int64{a1}, at.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, updated)
+
+	var triggeredAt time.Time
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT triggered_at FROM alerts WHERE id = $1`, a1).Scan(&triggeredAt))
+	assert.True(t, triggeredAt.Equal(at))
+
+	updated, err = repo.UpdateTriggered(ctx, nil, at)
+	require.NoError(t, err)
+	assert.Empty(t, updated)
+}
+
+// TestRepository_FindActiveUsesIndex は未発火のアラートの取得が部分インデックスを使うことを検証します。
+func TestRepository_FindActiveUsesIndex(t *testing.T) {
+	t.Parallel()
+	db := testsupport.NewDB(t, testsupport.InsertSymbols("AAPL", "GOOGL"))
+	userID := testsupport.InsertUser(t, db).ID
+	ctx := context.Background()
+
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO alerts (user_id, symbol_code, "interval", direction, threshold)
+		 SELECT $1, 'AAPL', '1day', 'above', g FROM generate_series(1, 100) g`, userID)
+	require.NoError(t, err)
+
+	// 行数が少ないとシーケンシャルスキャンが選ばれるため、同じ接続で無効化してから計画を確認する
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	_, err = conn.ExecContext(ctx, `SET enable_seqscan = off`)
+	require.NoError(t, err)
+
+	rows, err := conn.QueryContext(ctx,
+		`EXPLAIN SELECT id FROM alerts WHERE symbol_code = 'AAPL' AND "interval" = '1day' AND triggered_at IS NULL`)
+	require.NoError(t, err)
+	defer func() { _ = rows.Close() }()
+	var plan []string
+	for rows.Next() {
+		var line string
+		require.NoError(t, rows.Scan(&line))
+		plan = append(plan, line)
+	}
+	require.NoError(t, rows.Err())
+	assert.Contains(t, strings.Join(plan, "\n"), "idx_alerts_active_symbol_interval")
+}
+
+func TestRepository_RecordDelivery(t *testing.T) {
+	t.Parallel()
+	db := testsupport.NewDB(t, testsupport.InsertSymbols("AAPL", "GOOGL"))
+	userID := testsupport.InsertUser(t, db).ID
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	a := &Alert{UserID: userID, SymbolCode: "AAPL", Interval: "1day", Direction: DirectionAbove, Threshold: 100}
+	require.NoError(t, repo.Create(ctx, a))
+
+	first := time.Date(2026, 8, 1, 6, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.RecordDelivered(ctx, a.ID, first))
+	// 2 台目の端末への送信では最初の日時を残す
+	require.NoError(t, repo.RecordDelivered(ctx, a.ID, first.Add(time.Minute)))
+	require.NoError(t, repo.RecordDeliveryFailure(ctx, a.ID, "invalid token"))
+
+	var notifiedAt sql.NullTime
+	var notifyError sql.NullString
+	require.NoError(t, db.QueryRowContext(ctx,
+		`SELECT notified_at, notify_error FROM alerts WHERE id = $1`, a.ID).Scan(&notifiedAt, &notifyError))
+	require.True(t, notifiedAt.Valid)
+	assert.True(t, notifiedAt.Time.Equal(first))
+	assert.Equal(t, "invalid token", notifyError.String)
+}
+
+// TestRepository_ReArm は re_arm のアラートが発火後も評価の対象に残り、クールダウン中は発火を記録しないこと、
+// LastSide の記録とモードの切り替えを検証します。
+func TestRepository_ReArm(t *testing.T) {
+	t.Parallel()
+	db := testsupport.NewDB(t, testsupport.InsertSymbols("AAPL", "GOOGL"))
+	userID := testsupport.InsertUser(t, db).ID
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	reArm := &Alert{UserID: userID, SymbolCode: "AAPL", Interval: "1day", Direction: DirectionAbove, Threshold: 105,
+		Mode: ModeReArm, CooldownMinutes: 60}
+	require.NoError(t, repo.Create(ctx, reArm))
+	oneShot := &Alert{UserID: userID, SymbolCode: "AAPL", Interval: "1day", Direction: DirectionBelow, Threshold: 95}
+	require.NoError(t, repo.Create(ctx, oneShot))
+	assert.Equal(t, ModeOneShot, oneShot.Mode, "空のモードは one_shot で登録する")
+
+	invalid := &Alert{UserID: userID, SymbolCode: "AAPL", Interval: "1day", Direction: DirectionAbove, Threshold: 1,
+		Mode: ModeReArm, CooldownMinutes: MaxCooldownMinutes + 1}
+	require.ErrorIs(t, repo.Create(ctx, invalid), ErrInvalidCooldown)
+
+	at := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	updated, err := repo.UpdateTriggered(ctx, []int64{reArm.ID, oneShot.ID}, at)
+	require.NoError(t, err)
+	slices.Sort(updated)
+	assert.Equal(t, []int64{reArm.ID, oneShot.ID}, updated)
+
+	// re_arm は評価の対象に残り、最後に発火した日時と発火側を記録する
+	active, err := repo.FindActiveBySymbolInterval(ctx, "AAPL", "1day")
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	got := active[0]
+	assert.Equal(t, reArm.ID, got.ID)
+	assert.Equal(t, ModeReArm, got.Mode)
+	assert.Equal(t, 60, got.CooldownMinutes)
+	assert.Nil(t, got.TriggeredAt)
+	require.NotNil(t, got.LastTriggeredAt)
+	assert.True(t, got.LastTriggeredAt.Equal(at))
+	assert.Equal(t, DirectionAbove, got.LastSide)
+
+	// クールダウン中は記録しない。経過後は記録する
+	updated, err = repo.UpdateTriggered(ctx, []int64{reArm.ID}, at.Add(59*time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, updated)
+	updated, err = repo.UpdateTriggered(ctx, []int64{reArm.ID}, at.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []int64{reArm.ID}, updated)
+
+	// LastSide は re_arm のみ更新する
+	require.NoError(t, repo.UpdateLastSides(ctx, map[int64]Direction{reArm.ID: DirectionBelow, oneShot.ID: DirectionAbove}))
+	got, err = repo.Get(ctx, reArm.ID)
+	require.NoError(t, err)
+	assert.Equal(t, DirectionBelow, got.LastSide)
+	gotOneShot, err := repo.Get(ctx, oneShot.ID)
+	require.NoError(t, err)
+	assert.Empty(t, gotOneShot.LastSide)
+
+	// 発火済みの one_shot を re_arm に切り替えると評価の対象に戻る
+	switched, err := repo.ChangeMode(ctx, oneShot.ID, ModeReArm, DefaultReArmCooldownMinutes)
+	require.NoError(t, err)
+	assert.Nil(t, switched.TriggeredAt)
+	assert.Equal(t, DirectionBelow, switched.LastSide)
+	active, err = repo.FindActiveBySymbolInterval(ctx, "AAPL", "1day")
+	require.NoError(t, err)
+	assert.Len(t, active, 2)
+
+	_, err = repo.ChangeMode(ctx, reArm.ID, ModeOneShot, 60)
+	require.ErrorIs(t, err, ErrInvalidCooldown)
+	back, err := repo.ChangeMode(ctx, reArm.ID, ModeOneShot, 0)
+	require.NoError(t, err)
+	got, err = repo.Get(ctx, reArm.ID)
+	require.NoError(t, err)
+	assert.Equal(t, back.Mode, got.Mode)
+	assert.Zero(t, got.CooldownMinutes)
+	assert.Empty(t, got.LastSide)
+}
+
+// TestRepository_MigrateSplit は分割の移行が効力発生日より前に設定したアラートだけを書き換え、再実行では書き換えないことを検証します。
+func TestRepository_MigrateSplit(t *testing.T) {
+	t.Parallel()
+	db := testsupport.NewDB(t, testsupport.InsertSymbols("AAPL", "GOOGL"))
+	userID := testsupport.InsertUser(t, db).ID
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	effective := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	addAdjustment := func(date time.Time, factor float64) int64 {
+		var id int64
+		require.NoError(t, db.QueryRowContext(ctx,
+			`INSERT INTO candle_adjustments (symbol_code, effective_date, factor) VALUES ('AAPL', $1, $2) RETURNING id`,
+			date, factor).Scan(&id))
+		return id
+	}
+	create := func(symbol string, threshold float64, createdAt time.Time) int64 {
+		a := &Alert{UserID: userID, SymbolCode: symbol, Interval: "1day", Direction: DirectionAbove, Threshold: threshold}
+		require.NoError(t, repo.Create(ctx, a))
+		_, err := db.ExecContext(ctx, `UPDATE alerts SET created_at = $2 WHERE id = $1`, a.ID, createdAt)
+		require.NoError(t, err)
+		return a.ID
+	}
+	before := effective.AddDate(0, -1, 0)
+	old := create("AAPL", 3000, before)
+	edited := create("AAPL", 800, before)
+	_, err := db.ExecContext(ctx, `UPDATE alerts SET edited_at = $2 WHERE id = $1`, edited, effective.Add(time.Hour))
+	require.NoError(t, err)
+	fresh := create("AAPL", 760, effective.Add(time.Hour))
+	other := create("GOOGL", 3000, before)
+
+	split := Split{AdjustmentID: addAdjustment(effective, 0.25), SymbolCode: "AAPL", EffectiveDate: effective, Factor: 0.25}
+	migrations, err := repo.MigrateSplit(ctx, split)
+	require.NoError(t, err)
+	require.Len(t, migrations, 1)
+	assert.Equal(t, old, migrations[0].Alert.ID)
+	assert.Equal(t, 3000.0, migrations[0].PreviousThreshold)
+	assert.Equal(t, 750.0, migrations[0].Alert.Threshold)
+
+	got, err := repo.Get(ctx, old)
+	require.NoError(t, err)
+	assert.Equal(t, 750.0, got.Threshold)
+	require.NotNil(t, got.OriginalThreshold)
+	assert.Equal(t, 3000.0, *got.OriginalThreshold)
+	require.NotNil(t, got.AdjustmentID)
+	assert.Equal(t, split.AdjustmentID, *got.AdjustmentID)
+	for id, want := range map[int64]float64{edited: 800, fresh: 760, other: 3000} {
+		got, err := repo.Get(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, want, got.Threshold, "alert %d", id)
+		assert.Nil(t, got.AdjustmentID, "alert %d", id)
+	}
+
+	// 同じ調整の再実行では書き換えない
+	migrations, err = repo.MigrateSplit(ctx, split)
+	require.NoError(t, err)
+	assert.Empty(t, migrations)
+	got, err = repo.Get(ctx, old)
+	require.NoError(t, err)
+	assert.Equal(t, 750.0, got.Threshold)
+
+	// 後の調整では最初の閾値を残したまま書き換え、その後に古い調整を再実行しても書き換えない
+	later := effective.AddDate(0, 3, 0)
+	second := Split{AdjustmentID: addAdjustment(later, 0.5), SymbolCode: "AAPL", EffectiveDate: later, Factor: 0.5}
+	migrations, err = repo.MigrateSplit(ctx, second)
+	require.NoError(t, err)
+	ids := make([]int64, 0, len(migrations))
+	for _, m := range migrations {
+		ids = append(ids, m.Alert.ID)
+	}
+	slices.Sort(ids)
+	assert.Equal(t, []int64{old, edited, fresh}, ids)
+	got, err = repo.Get(ctx, old)
+	require.NoError(t, err)
+	assert.Equal(t, 375.0, got.Threshold)
+	require.NotNil(t, got.OriginalThreshold)
+	assert.Equal(t, 3000.0, *got.OriginalThreshold)
+
+	migrations, err = repo.MigrateSplit(ctx, split)
+	require.NoError(t, err)
+	assert.Empty(t, migrations)
+}
+
+// TestRepository_CreateManyAndListByUser は一括登録が 1 件の失敗で全体を取り消し、失敗した要素の位置を返すことを検証します。
+func TestRepository_CreateManyAndListByUser(t *testing.T) {
+	t.Parallel()
+	db := testsupport.NewDB(t, testsupport.InsertSymbols("AAPL", "GOOGL"))
+	userID := testsupport.InsertUser(t, db).ID
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	_, err := repo.CreateMany(ctx, []Alert{
+		{UserID: userID, SymbolCode: "AAPL", Interval: "1day", Direction: DirectionAbove, Threshold: 100},
+		{UserID: userID, SymbolCode: "NOPE", Interval: "1day", Direction: DirectionAbove, Threshold: 100},
+	})
+	var ie *ItemError
+	require.ErrorAs(t, err, &ie)
+	assert.Equal(t, 1, ie.Index)
+	assert.ErrorIs(t, err, ErrUnknownSymbol)
+	listed, err := repo.ListByUser(ctx, userID)
+	require.NoError(t, err)
+	assert.Empty(t, listed, "失敗した場合は何も登録しない")
+
+	created, err := repo.CreateMany(ctx, []Alert{
+		{UserID: userID, SymbolCode: "GOOGL", Interval: "1day", Direction: DirectionBelow, Threshold: 90},
+		{UserID: userID, SymbolCode: "AAPL", Interval: "1week", Direction: DirectionAbove, Threshold: 110, Mode: ModeReArm, CooldownMinutes: 30},
+	})
+	require.NoError(t, err)
+	require.Len(t, created, 2)
+	assert.Equal(t, ModeOneShot, created[0].Mode)
+
+	listed, err = repo.ListByUser(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+	assert.Equal(t, created[0].ID, listed[0].ID)
+	assert.Equal(t, "AAPL", listed[1].SymbolCode)
+	assert.Equal(t, 30, listed[1].CooldownMinutes)
+}
@@ -0,0 +1,114 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
+)
+
+// ErrInvalidSplit は分割の移行に使えない調整（ID・銘柄の欠落、正の有限でない係数）が渡された場合に返されます。
+var ErrInvalidSplit = apperr.New(apperr.KindInvalid, "invalid split", "split must have an adjustment id, a symbol and a positive finite factor")
+
+// Split は株式分割・併合の調整係数（candles の Adjustment）のうち、アラートの閾値の移行に必要な値です。
+type Split struct {
+	AdjustmentID int64
+	SymbolCode   string
+	// EffectiveDate は調整の効力発生日です（暦日。時刻は 0 時）。
+	EffectiveDate time.Time
+	// Factor は価格に掛ける係数です（4対1分割なら 0.25、1対10併合なら 10）。
+	Factor float64
+}
+
+func (s Split) validate() error {
+	if s.AdjustmentID == 0 || s.SymbolCode == "" || s.EffectiveDate.IsZero() ||
+		math.IsNaN(s.Factor) || math.IsInf(s.Factor, 0) || s.Factor <= 0 {
+		return ErrInvalidSplit
+	}
+	return nil
+}
+
+// SplitThreshold は閾値を分割の係数で換算した値を返します（保存精度の小数 4 桁に丸めます）。
+func (a Alert) SplitThreshold(factor float64) float64 {
+	return math.Round(a.Threshold*factor*1e4) / 1e4
+}
+
+// NeedsSplitMigration はアラートの閾値を分割 s で書き換えるかを返します。
+//
+// 銘柄の未発火のアラートのうち、効力発生日より前に設定したもの（作成・ユーザーの最後の変更がともに効力発生日より前）を
+// 書き換えます。効力発生日以降に作成・変更したアラートは分割後の価格で設定したとみなして書き換えません。
+// この調整、またはこれより後に登録した調整（ID が大きい）で書き換え済みのアラートも書き換えないため、再実行しても結果は変わりません。
+func (a Alert) NeedsSplitMigration(s Split) bool {
+	if a.SymbolCode != s.SymbolCode || a.TriggeredAt != nil {
+		return false
+	}
+	if a.AdjustmentID != nil && *a.AdjustmentID >= s.AdjustmentID {
+		return false
+	}
+	if !a.CreatedAt.Before(s.EffectiveDate) {
+		return false
+	}
+	return a.EditedAt == nil || a.EditedAt.Before(s.EffectiveDate)
+}
+
+// Migration は分割の移行で閾値を書き換えたアラートです。
+type Migration struct {
+	// Alert は書き換え後のアラートです（OriginalThreshold・AdjustmentID を含む）。
+	Alert Alert
+	// PreviousThreshold はこの分割で書き換える前の閾値です。
+	PreviousThreshold float64
+	Split             Split
+}
+
+// SplitRepository は分割の移行に必要な永続化操作を抽象化します。
+type SplitRepository interface {
+	// MigrateSplit は銘柄 s.SymbolCode の未発火のアラートを行ロックして読み、NeedsSplitMigration のものの閾値を
+	// SplitThreshold に書き換えて（OriginalThreshold に最初の閾値、AdjustmentID に s.AdjustmentID を記録）、書き換えたアラートを返します。
+	// 読み取りと書き換えは 1 つのトランザクションで行います。
+	MigrateSplit(ctx context.Context, s Split) ([]Migration, error)
+}
+
+// MigrationNotifier は分割で閾値を書き換えたアラートをユーザーへ知らせます。
+type MigrationNotifier interface {
+	EnqueueMigrated(ctx context.Context, migrations []Migration) error
+}
+
+// SplitMigrator は株式分割・併合の調整の登録時に、銘柄のアラートの閾値を調整係数で書き換えます。
+// 4対1分割の後も 3000 の閾値が残ると、750 前後になった終値では発火しなくなるためです。
+type SplitMigrator struct {
+	repo     SplitRepository
+	notifier MigrationNotifier
+}
+
+// NewSplitMigrator は SplitMigrator の新しいインスタンスを生成します。notifier が nil の場合は通知しません。
+func NewSplitMigrator(repo SplitRepository, notifier MigrationNotifier) *SplitMigrator {
+	return &SplitMigrator{repo: repo, notifier: notifier}
+}
+
+// Migrate は分割 s でアラートの閾値を書き換え、書き換えたアラートを返します。係数が 1 の場合は何もしません。
+// 通知の登録に失敗しても書き換えは取り消さず、書き換えたアラートとエラーを返します。
+func (m *SplitMigrator) Migrate(ctx context.Context, s Split) ([]Migration, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	if s.Factor == 1 {
+		return nil, nil
+	}
+	migrations, err := m.repo.MigrateSplit(ctx, s)
+	if err != nil {
+		return nil, fmt.Errorf("migrate alerts for split %d (%s): %w", s.AdjustmentID, s.SymbolCode, err)
+	}
+	if len(migrations) == 0 {
+		return nil, nil
+	}
+	var errs []error
+	if m.notifier != nil {
+		if err := m.notifier.EnqueueMigrated(ctx, migrations); err != nil {
+			errs = append(errs, fmt.Errorf("enqueue alert migration notifications %s: %w", s.SymbolCode, err))
+		}
+	}
+	return migrations, errors.Join(errs...)
+}
@@ -0,0 +1,165 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// TestAlert_SplitThreshold は分割・併合の係数で閾値を換算し、小数 4 桁に丸めることを検証します。
+func TestAlert_SplitThreshold(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name              string
+		threshold, factor float64
+		want              float64
+	}{
+		{"4-for-1 split", 3000, 0.25, 750},
+		{"3-for-1 split rounds to 4 decimals", 100, 1.0 / 3, 33.3333},
+		{"1-for-10 reverse split", 1.5, 10, 15},
+		{"tiny threshold", 0.0004, 0.1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			a := Alert{Threshold: tt.threshold}
+			if got := a.SplitThreshold(tt.factor); got != tt.want {
+				t.Errorf("SplitThreshold(%v) = %v, want %v", tt.factor, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestAlert_NeedsSplitMigration は書き換えの対象（効力発生日より前に設定した未発火のアラート）と対象外の組み合わせを検証します。
+func TestAlert_NeedsSplitMigration(t *testing.T) {
+	t.Parallel()
+
+	effective := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	before := effective.Add(-time.Second)
+	s := Split{AdjustmentID: 5, SymbolCode: "AAPL", EffectiveDate: effective, Factor: 0.25}
+	ptr := func(id int64) *int64 { return &id }
+
+	tests := []struct {
+		name  string
+		alert Alert
+		want  bool
+	}{
+		{"created before the effective date", Alert{SymbolCode: "AAPL", CreatedAt: before}, true},
+		{"edited before the effective date", Alert{SymbolCode: "AAPL", CreatedAt: before, EditedAt: &before}, true},
+		{"migrated by an earlier adjustment", Alert{SymbolCode: "AAPL", CreatedAt: before, AdjustmentID: ptr(4)}, true},
+		{"other symbol", Alert{SymbolCode: "MSFT", CreatedAt: before}, false},
+		{"triggered", Alert{SymbolCode: "AAPL", CreatedAt: before, TriggeredAt: &before}, false},
+		{"created on the effective date", Alert{SymbolCode: "AAPL", CreatedAt: effective}, false},
+		{"edited on the effective date", Alert{SymbolCode: "AAPL", CreatedAt: before, EditedAt: &effective}, false},
+		{"already migrated by this adjustment", Alert{SymbolCode: "AAPL", CreatedAt: before, AdjustmentID: ptr(5)}, false},
+		{"migrated by a later adjustment", Alert{SymbolCode: "AAPL", CreatedAt: before, AdjustmentID: ptr(6)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.alert.NeedsSplitMigration(s); got != tt.want {
+				t.Errorf("NeedsSplitMigration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeSplitRepository は MigrateSplit の呼び出しを記録し、固定の結果を返します。
+type fakeSplitRepository struct {
+	calls      int
+	migrations []Migration
+	err        error
+}
+
+func (r *fakeSplitRepository) MigrateSplit(_ context.Context, _ Split) ([]Migration, error) {
+	r.calls++
+	return r.migrations, r.err
+}
+
+// recordingMigrationNotifier は通知したアラートの書き換えを記録します。
+type recordingMigrationNotifier struct {
+	batches [][]Migration
+	err     error
+}
+
+func (n *recordingMigrationNotifier) EnqueueMigrated(_ context.Context, migrations []Migration) error {
+	n.batches = append(n.batches, migrations)
+	return n.err
+}
+
+func TestSplitMigrator_Migrate(t *testing.T) {
+	t.Parallel()
+
+	effective := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	split := Split{AdjustmentID: 1, SymbolCode: "AAPL", EffectiveDate: effective, Factor: 0.25}
+	migrated := []Migration{{Alert: Alert{ID: 7, Threshold: 750}, PreviousThreshold: 3000, Split: split}}
+
+	t.Run("migrates and notifies", func(t *testing.T) {
+		t.Parallel()
+		repo := &fakeSplitRepository{migrations: migrated}
+		notifier := &recordingMigrationNotifier{}
+		got, err := NewSplitMigrator(repo, notifier).Migrate(context.Background(), split)
+		if err != nil {
+			t.Fatalf("Migrate() error = %v", err)
+		}
+		if len(got) != 1 || len(notifier.batches) != 1 || len(notifier.batches[0]) != 1 {
+			t.Errorf("got %d migrations and %d notifications, want 1 each", len(got), len(notifier.batches))
+		}
+	})
+
+	t.Run("factor 1 is a no-op", func(t *testing.T) {
+		t.Parallel()
+		repo := &fakeSplitRepository{migrations: migrated}
+		s := split
+		s.Factor = 1
+		got, err := NewSplitMigrator(repo, nil).Migrate(context.Background(), s)
+		if err != nil || got != nil || repo.calls != 0 {
+			t.Errorf("Migrate() = %v, %v with %d repository calls, want no-op", got, err, repo.calls)
+		}
+	})
+
+	t.Run("invalid split", func(t *testing.T) {
+		t.Parallel()
+		for _, s := range []Split{
+			{SymbolCode: "AAPL", EffectiveDate: effective, Factor: 0.25},
+			{AdjustmentID: 1, EffectiveDate: effective, Factor: 0.25},
+			{AdjustmentID: 1, SymbolCode: "AAPL", Factor: 0.25},
+			{AdjustmentID: 1, SymbolCode: "AAPL", EffectiveDate: effective},
+			{AdjustmentID: 1, SymbolCode: "AAPL", EffectiveDate: effective, Factor: math.Inf(1)},
+		} {
+			repo := &fakeSplitRepository{}
+			if _, err := NewSplitMigrator(repo, nil).Migrate(context.Background(), s); !errors.Is(err, ErrInvalidSplit) {
+				t.Errorf("Migrate(%+v) error = %v, want ErrInvalidSplit", s, err)
+			}
+			if repo.calls != 0 {
+				t.Errorf("Migrate(%+v) called the repository", s)
+			}
+		}
+	})
+
+	t.Run("nothing migrated is not notified", func(t *testing.T) {
+		t.Parallel()
+		notifier := &recordingMigrationNotifier{}
+		if _, err := NewSplitMigrator(&fakeSplitRepository{}, notifier).Migrate(context.Background(), split); err != nil {
+			t.Fatalf("Migrate() error = %v", err)
+		}
+		if len(notifier.batches) != 0 {
+			t.Errorf("notified %d batches, want 0", len(notifier.batches))
+		}
+	})
+
+	t.Run("notifier error keeps the migrations", func(t *testing.T) {
+		t.Parallel()
+		notifier := &recordingMigrationNotifier{err: errors.New("queue down")}
+		got, err := NewSplitMigrator(&fakeSplitRepository{migrations: migrated}, notifier).Migrate(context.Background(), split)
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		if len(got) != 1 {
+			t.Errorf("got %d migrations, want 1 returned with the error", len(got))
+		}
+	})
+}
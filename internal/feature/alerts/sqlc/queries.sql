@@ -0,0 +1,56 @@
+-- name: FindActiveAlertsBySymbolInterval :many
+-- 部分インデックス idx_alerts_active_symbol_interval を使う（発火済みの one_shot は読まない。re_arm は triggered_at を記録しない）。
+SELECT id, user_id, symbol_code, "interval", direction, threshold, created_at, triggered_at,
+       mode, cooldown_minutes, last_triggered_at, last_side,
+       original_threshold, adjustment_id, edited_at
+FROM alerts
+WHERE symbol_code = $1 AND "interval" = $2 AND triggered_at IS NULL
+ORDER BY id;
+
+-- name: InsertAlert :one
+INSERT INTO alerts (user_id, symbol_code, "interval", direction, threshold, mode, cooldown_minutes)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, created_at;
+
+-- name: LockActiveAlertsBySymbol :many
+-- 分割の移行用。銘柄の未発火のアラート（全時間間隔）を行ロックして読む（同じ調整の同時実行で二重に書き換えない）。
+SELECT id, user_id, symbol_code, "interval", direction, threshold, created_at, triggered_at,
+       mode, cooldown_minutes, last_triggered_at, last_side,
+       original_threshold, adjustment_id, edited_at
+FROM alerts
+WHERE symbol_code = $1 AND triggered_at IS NULL
+ORDER BY id
+FOR UPDATE;
+
+-- name: ListAlertsByUser :many
+-- エクスポート用。ユーザーの全てのアラート（発火済みを含む）を ID 順に返す。
+SELECT id, user_id, symbol_code, "interval", direction, threshold, created_at, triggered_at,
+       mode, cooldown_minutes, last_triggered_at, last_side,
+       original_threshold, adjustment_id, edited_at
+FROM alerts
+WHERE user_id = $1
+ORDER BY id;
+
+-- name: GetAlert :one
+SELECT id, user_id, symbol_code, "interval", direction, threshold, created_at, triggered_at,
+       mode, cooldown_minutes, last_triggered_at, last_side,
+       original_threshold, adjustment_id, edited_at
+FROM alerts
+WHERE id = $1;
+
+-- name: UpdateAlertMode :exec
+-- モードの切り替え（Alert.ChangeMode の結果）を保存する。ユーザーの変更として edited_at を記録する。
+UPDATE alerts
+SET mode = $2, cooldown_minutes = $3, triggered_at = $4, last_side = $5, edited_at = now()
+WHERE id = $1;
+
+-- name: RecordAlertNotified :exec
+-- 最初に送信できた日時を残す（複数の端末に送っても上書きしない）。
+UPDATE alerts
+SET notified_at = COALESCE(notified_at, $2)
+WHERE id = $1;
+
+-- name: RecordAlertNotifyError :exec
+UPDATE alerts
+SET notify_error = $2
+WHERE id = $1;
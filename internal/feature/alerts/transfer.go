@@ -0,0 +1,180 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
+)
+
+const (
+	// MaxImportAlerts は 1 回のインポートで受け付けるアラートの上限です。
+	MaxImportAlerts = 200
+	// maxSymbolLength・maxIntervalLength は alerts.symbol_code・alerts.interval の長さの上限です。
+	maxSymbolLength   = 20
+	maxIntervalLength = 16
+)
+
+var (
+	// ErrInvalidDirection は向きが above / below 以外の場合に返されます。
+	ErrInvalidDirection = apperr.New(apperr.KindInvalid, "invalid alert direction", "alert direction must be above or below")
+	// ErrInvalidThreshold は閾値が正の有限数でない場合に返されます。
+	ErrInvalidThreshold = apperr.New(apperr.KindInvalid, "invalid alert threshold", "alert threshold must be a positive finite number")
+	// ErrInvalidTarget は銘柄・時間間隔が空または長すぎる場合に返されます。
+	ErrInvalidTarget = apperr.New(apperr.KindInvalid, "invalid alert target",
+		fmt.Sprintf("alert symbol must be 1-%d characters and interval 1-%d characters", maxSymbolLength, maxIntervalLength))
+	// ErrUnknownSymbol は存在しない銘柄のアラートを登録しようとした場合に返されます。
+	ErrUnknownSymbol = apperr.New(apperr.KindInvalid, "unknown alert symbol", "alert symbol does not exist")
+	// ErrTooManyAlerts はインポートするアラートが MaxImportAlerts を超える場合に返されます。
+	ErrTooManyAlerts = apperr.New(apperr.KindInvalid, "too many alerts",
+		fmt.Sprintf("at most %d alerts can be imported at once", MaxImportAlerts))
+)
+
+// ItemError はインポートの Index 番目（0 始まり）の要素のエラーです。
+// errors.Is で原因のセンチネル（ErrInvalidDirection 等）を判定でき、Hint で要素の位置と理由をクライアントに返します。
+type ItemError struct {
+	Index int
+	Err   error
+}
+
+func (e *ItemError) Error() string { return fmt.Sprintf("alerts[%d]: %v", e.Index, e.Err) }
+
+func (e *ItemError) Unwrap() error { return e.Err }
+
+// Hint はクライアントが直すべき要素と理由（例: "alerts[3]: alert direction must be above or below"）を返します。
+func (e *ItemError) Hint() string {
+	reason := e.Err.Error()
+	if ae, ok := apperr.As(e.Err); ok {
+		reason = ae.Message
+	}
+	return fmt.Sprintf("alerts[%d]: %s", e.Index, reason)
+}
+
+// validateInput はユーザーが登録するアラートの内容を検証します。
+// モードとクールダウンは Create・ChangeMode と同じ Validate で検証し、加えて向き・閾値・銘柄・時間間隔を検証します。
+func validateInput(a Alert) error {
+	if a.Direction != DirectionAbove && a.Direction != DirectionBelow {
+		return ErrInvalidDirection
+	}
+	if math.IsNaN(a.Threshold) || math.IsInf(a.Threshold, 0) || a.Threshold <= 0 {
+		return ErrInvalidThreshold
+	}
+	if a.SymbolCode == "" || len(a.SymbolCode) > maxSymbolLength || a.Interval == "" || len(a.Interval) > maxIntervalLength {
+		return ErrInvalidTarget
+	}
+	return a.Validate()
+}
+
+// Store はアラートのインポート・エクスポートに必要な永続化操作を抽象化します。
+type Store interface {
+	// ListByUser はユーザーの全てのアラート（発火済みを含む）を ID 順に返します。
+	ListByUser(ctx context.Context, userID int64) ([]Alert, error)
+	// CreateMany は alerts を 1 つのトランザクションで登録します。失敗した場合は何も登録せず *ItemError を返します。
+	CreateMany(ctx context.Context, alerts []Alert) ([]Alert, error)
+}
+
+// ImportResult はインポートの結果です。
+type ImportResult struct {
+	// Created は登録したアラートです。
+	Created []Alert
+	// Skipped は登録済みの未発火のアラート（またはインポート内の前の要素）と同じ内容のため登録しなかった件数です。
+	Skipped int
+}
+
+// TransferUsecase はユーザーのアラートの一括エクスポート・インポートのユースケースです。
+type TransferUsecase struct {
+	store Store
+}
+
+// NewTransferUsecase は TransferUsecase の新しいインスタンスを生成します。
+func NewTransferUsecase(store Store) *TransferUsecase {
+	return &TransferUsecase{store: store}
+}
+
+// Export はユーザー userID の全てのアラート（発火済みを含む）を ID 順に返します。
+func (u *TransferUsecase) Export(ctx context.Context, userID int64) ([]Alert, error) {
+	return u.store.ListByUser(ctx, userID)
+}
+
+// Import は items をユーザー userID のアラートとして登録します（ID・発火の状態は無視し、未発火の新しいアラートにします）。
+//
+// 全ての要素を検証してから 1 つのトランザクションで登録し、1 件でも不正な場合は何も登録せず *ItemError を返します。
+// 件数が MaxImportAlerts を超える場合は ErrTooManyAlerts を返します。
+// 登録済みの未発火のアラートと内容（銘柄・時間間隔・向き・閾値・モード・クールダウン）が同じ要素は登録しないため、
+// エクスポートした内容を再度インポートしても重複しません。
+func (u *TransferUsecase) Import(ctx context.Context, userID int64, items []Alert) (ImportResult, error) {
+	if len(items) > MaxImportAlerts {
+		return ImportResult{}, ErrTooManyAlerts
+	}
+	inputs := make([]Alert, len(items))
+	for i, it := range items {
+		a := Alert{
+			UserID:          userID,
+			SymbolCode:      strings.TrimSpace(it.SymbolCode),
+			Interval:        strings.TrimSpace(it.Interval),
+			Direction:       it.Direction,
+			Threshold:       it.Threshold,
+			Mode:            it.mode(),
+			CooldownMinutes: it.CooldownMinutes,
+		}
+		if err := validateInput(a); err != nil {
+			return ImportResult{}, &ItemError{Index: i, Err: err}
+		}
+		inputs[i] = a
+	}
+
+	existing, err := u.store.ListByUser(ctx, userID)
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("list alerts: %w", err)
+	}
+	seen := make(map[importKey]struct{}, len(existing)+len(inputs))
+	for _, a := range existing {
+		if a.TriggeredAt == nil {
+			seen[keyOf(a)] = struct{}{}
+		}
+	}
+	var res ImportResult
+	toCreate := make([]Alert, 0, len(inputs))
+	indexes := make([]int, 0, len(inputs))
+	for i, a := range inputs {
+		k := keyOf(a)
+		if _, ok := seen[k]; ok {
+			res.Skipped++
+			continue
+		}
+		seen[k] = struct{}{}
+		toCreate = append(toCreate, a)
+		indexes = append(indexes, i)
+	}
+	if len(toCreate) == 0 {
+		return res, nil
+	}
+
+	created, err := u.store.CreateMany(ctx, toCreate)
+	if err != nil {
+		// 登録した要素の位置を、リクエストの要素の位置に戻す
+		var ie *ItemError
+		if errors.As(err, &ie) && ie.Index < len(indexes) {
+			return ImportResult{}, &ItemError{Index: indexes[ie.Index], Err: ie.Err}
+		}
+		return ImportResult{}, err
+	}
+	res.Created = created
+	return res, nil
+}
+
+// importKey はインポートで同じ内容とみなすアラートの項目です。
+type importKey struct {
+	symbol, interval string
+	direction        Direction
+	threshold        float64
+	mode             Mode
+	cooldown         int
+}
+
+func keyOf(a Alert) importKey {
+	return importKey{a.SymbolCode, a.Interval, a.Direction, a.Threshold, a.mode(), a.CooldownMinutes}
+}
@@ -0,0 +1,157 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+// memStore は ListByUser・CreateMany を再現するインメモリの Store です。
+// failAt が 0 以上の場合、CreateMany はその位置の要素で ErrUnknownSymbol の *ItemError を返します。
+type memStore struct {
+	alerts  []Alert
+	failAt  int
+	creates int
+}
+
+func (s *memStore) ListByUser(_ context.Context, userID int64) ([]Alert, error) {
+	var out []Alert
+	for _, a := range s.alerts {
+		if a.UserID == userID {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func (s *memStore) CreateMany(_ context.Context, alerts []Alert) ([]Alert, error) {
+	s.creates++
+	if s.failAt >= 0 && s.failAt < len(alerts) {
+		return nil, &ItemError{Index: s.failAt, Err: ErrUnknownSymbol}
+	}
+	out := make([]Alert, len(alerts))
+	for i, a := range alerts {
+		a.ID = int64(len(s.alerts) + 1)
+		s.alerts = append(s.alerts, a)
+		out[i] = a
+	}
+	return out, nil
+}
+
+// TestTransferUsecase_Import_Validation は不正な要素の位置と理由を返し、何も登録しないことを検証します。
+func TestTransferUsecase_Import_Validation(t *testing.T) {
+	t.Parallel()
+
+	valid := Alert{SymbolCode: "AAPL", Interval: "1day", Direction: DirectionAbove, Threshold: 100}
+	tests := []struct {
+		name   string
+		modify func(a *Alert)
+		want   error
+	}{
+		{"unknown direction", func(a *Alert) { a.Direction = "sideways" }, ErrInvalidDirection},
+		{"zero threshold", func(a *Alert) { a.Threshold = 0 }, ErrInvalidThreshold},
+		{"negative threshold", func(a *Alert) { a.Threshold = -1 }, ErrInvalidThreshold},
+		{"NaN threshold", func(a *Alert) { a.Threshold = math.NaN() }, ErrInvalidThreshold},
+		{"empty symbol", func(a *Alert) { a.SymbolCode = " " }, ErrInvalidTarget},
+		{"long symbol", func(a *Alert) { a.SymbolCode = strings.Repeat("A", maxSymbolLength+1) }, ErrInvalidTarget},
+		{"empty interval", func(a *Alert) { a.Interval = "" }, ErrInvalidTarget},
+		{"unknown mode", func(a *Alert) { a.Mode = "twice" }, ErrInvalidMode},
+		{"one_shot with cooldown", func(a *Alert) { a.CooldownMinutes = 5 }, ErrInvalidCooldown},
+		{"re_arm cooldown too long", func(a *Alert) { a.Mode = ModeReArm; a.CooldownMinutes = MaxCooldownMinutes + 1 }, ErrInvalidCooldown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			bad := valid
+			tt.modify(&bad)
+			store := &memStore{failAt: -1}
+			_, err := NewTransferUsecase(store).Import(context.Background(), 1, []Alert{valid, bad})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Import() error = %v, want %v", err, tt.want)
+			}
+			var ie *ItemError
+			if !errors.As(err, &ie) || ie.Index != 1 {
+				t.Errorf("Import() error = %v, want the item error for index 1", err)
+			}
+			if !strings.HasPrefix(ie.Hint(), "alerts[1]: ") {
+				t.Errorf("Hint() = %q, want the item position", ie.Hint())
+			}
+			if store.creates != 0 {
+				t.Error("nothing must be created when an item is invalid")
+			}
+		})
+	}
+}
+
+func TestTransferUsecase_Import_TooMany(t *testing.T) {
+	t.Parallel()
+
+	items := make([]Alert, MaxImportAlerts+1)
+	store := &memStore{failAt: -1}
+	if _, err := NewTransferUsecase(store).Import(context.Background(), 1, items); !errors.Is(err, ErrTooManyAlerts) {
+		t.Errorf("Import() error = %v, want ErrTooManyAlerts", err)
+	}
+}
+
+// TestTransferUsecase_Import_SkipsDuplicates は登録済みの未発火のアラート・インポート内の前の要素と同じ内容の要素を登録しないことを検証します。
+func TestTransferUsecase_Import_SkipsDuplicates(t *testing.T) {
+	t.Parallel()
+
+	triggered := day1
+	store := &memStore{failAt: -1, alerts: []Alert{
+		{ID: 1, UserID: 1, SymbolCode: "AAPL", Interval: "1day", Direction: DirectionAbove, Threshold: 100, Mode: ModeOneShot},
+		{ID: 2, UserID: 1, SymbolCode: "MSFT", Interval: "1day", Direction: DirectionAbove, Threshold: 100, Mode: ModeOneShot, TriggeredAt: &triggered},
+		{ID: 3, UserID: 2, SymbolCode: "GOOGL", Interval: "1day", Direction: DirectionAbove, Threshold: 100, Mode: ModeOneShot},
+	}}
+	uc := NewTransferUsecase(store)
+
+	res, err := uc.Import(context.Background(), 1, []Alert{
+		{ID: 99, SymbolCode: "AAPL", Interval: "1day", Direction: DirectionAbove, Threshold: 100},          // 登録済み
+		{SymbolCode: " MSFT ", Interval: "1day", Direction: DirectionAbove, Threshold: 100},                // 発火済みと同じ内容は登録する
+		{SymbolCode: "MSFT", Interval: "1day", Direction: DirectionAbove, Threshold: 100},                  // 前の要素と同じ
+		{SymbolCode: "GOOGL", Interval: "1day", Direction: DirectionAbove, Threshold: 100},                 // 他のユーザーのアラートは関係しない
+		{SymbolCode: "AAPL", Interval: "1day", Direction: DirectionAbove, Threshold: 100, Mode: ModeReArm}, // モードが異なる
+	})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if res.Skipped != 2 || len(res.Created) != 3 {
+		t.Fatalf("Import() = %d created, %d skipped, want 3 created, 2 skipped", len(res.Created), res.Skipped)
+	}
+	for _, a := range res.Created {
+		if a.UserID != 1 || a.ID == 99 || a.SymbolCode == " MSFT " {
+			t.Errorf("created %+v, want a new normalized alert of user 1", a)
+		}
+	}
+
+	// エクスポートした内容を再度インポートしても増えない
+	exported, err := uc.Export(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	res, err = uc.Import(context.Background(), 1, exported)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if len(res.Created) != 0 || res.Skipped != len(exported) {
+		t.Errorf("re-import = %d created, %d skipped, want all %d skipped", len(res.Created), res.Skipped, len(exported))
+	}
+}
+
+// TestTransferUsecase_Import_StoreItemError は登録時のエラーの位置を、見送った要素を含むリクエストの位置に戻すことを検証します。
+func TestTransferUsecase_Import_StoreItemError(t *testing.T) {
+	t.Parallel()
+
+	store := &memStore{failAt: 1}
+	_, err := NewTransferUsecase(store).Import(context.Background(), 1, []Alert{
+		{SymbolCode: "AAPL", Interval: "1day", Direction: DirectionAbove, Threshold: 100},
+		{SymbolCode: "AAPL", Interval: "1day", Direction: DirectionAbove, Threshold: 100},
+		{SymbolCode: "NOPE", Interval: "1day", Direction: DirectionAbove, Threshold: 100},
+	})
+	var ie *ItemError
+	if !errors.As(err, &ie) || ie.Index != 2 || !errors.Is(err, ErrUnknownSymbol) {
+		t.Errorf("Import() error = %v, want the unknown symbol at index 2", err)
+	}
+}
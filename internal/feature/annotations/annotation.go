@@ -0,0 +1,68 @@
+// Package annotations はユーザーがチャートの足に付ける注記（メモ）を扱います。
+package annotations
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxTextLength は注記本文の最大文字数（rune 数）です。
+const MaxTextLength = 500
+
+// Annotation はユーザーが銘柄・時間間隔ごとのローソク足の日付に付ける注記です（「決算好調」「ここで購入」等）。
+// 注記は作成したユーザーだけが参照・変更できます。
+type Annotation struct {
+	ID         int64
+	UserID     int64
+	SymbolCode string
+	Interval   string
+	// Time は注記を付けた足の日付です。ローソク足の API と同じく UTC の暦日（0 時）で保持します。
+	Time      time.Time
+	Text      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Validate は注記として保存できるかを検証します。不正な場合は ErrInvalidAnnotation をラップしたエラーを返します。
+// 銘柄の存在と足の範囲は usecase が検証します。
+func (a Annotation) Validate() error {
+	if a.Time.IsZero() {
+		return fmt.Errorf("%w: missing time", ErrInvalidAnnotation)
+	}
+	if strings.TrimSpace(a.Text) == "" {
+		return fmt.Errorf("%w: missing text", ErrInvalidAnnotation)
+	}
+	if utf8.RuneCountInString(a.Text) > MaxTextLength {
+		return fmt.Errorf("%w: text must be at most %d characters", ErrInvalidAnnotation, MaxTextLength)
+	}
+	return nil
+}
+
+// Filter は注記一覧の絞り込みです。空文字・ゼロ値の項目は絞り込みません。
+// From / To は足の日付の範囲で、両端を含みます。
+type Filter struct {
+	SymbolCode string
+	Interval   string
+	From       time.Time
+	To         time.Time
+}
+
+// Patch は注記の部分更新です。nil の項目は変更しません（銘柄・時間間隔は変更できません）。
+type Patch struct {
+	Time *time.Time
+	Text *string
+}
+
+// Page は注記一覧の 1 ページです。NextCursor は次ページのカーソルで、最後のページでは空です。
+type Page struct {
+	Annotations []Annotation
+	NextCursor  string
+}
+
+// utcDay は t の UTC の暦日（0 時）を返します。ローソク足の API が返す日付と同じ単位で比較するために使います。
+func utcDay(t time.Time) time.Time {
+	u := t.UTC()
+	return time.Date(u.Year(), u.Month(), u.Day(), 0, 0, 0, 0, time.UTC)
+}
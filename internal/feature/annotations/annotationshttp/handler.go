@@ -0,0 +1,236 @@
+package annotationshttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/annotations"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+)
+
+// symbolCodePattern は銘柄コードとして許可する形式（例: AAPL, 7203.T）。
+// symbols.code が VARCHAR(20) のため最大20文字、英数字と . _ - のみ許可する。
+var symbolCodePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,20}$`)
+
+// Usecase は注記操作のユースケースインターフェースを定義します。
+// 全ての操作はログイン中のユーザーの注記に限られます。
+type Usecase interface {
+	List(ctx context.Context, userID int64, f annotations.Filter, q url.Values) (annotations.Page, error)
+	Get(ctx context.Context, userID, id int64) (annotations.Annotation, error)
+	Create(ctx context.Context, a annotations.Annotation) (annotations.Annotation, error)
+	Update(ctx context.Context, userID, id int64, p annotations.Patch) (annotations.Annotation, error)
+	Delete(ctx context.Context, userID, id int64) error
+}
+
+// Handler は注記に関連するHTTPリクエストを処理します。
+// ユーザーはJWTから取得し、リクエストで他のユーザーを指定する手段は設けません。
+type Handler struct {
+	uc Usecase
+}
+
+// NewHandler はHandlerの新しいインスタンスを生成します。
+func NewHandler(uc Usecase) *Handler {
+	return &Handler{uc: uc}
+}
+
+// List はユーザーの注記を ID 順に返します（?symbol= / ?interval= で絞り込み、limit / cursor でページング）。
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := jwt.UserIDFromContext(r.Context())
+	if !ok {
+		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
+		return
+	}
+	q := r.URL.Query()
+	f := annotations.Filter{SymbolCode: q.Get("symbol"), Interval: q.Get("interval")}
+	h.writePage(w, r, userID, f)
+}
+
+// CandleAnnotations はチャートの表示範囲（?from= 〜 ?to=、両端を含む）にあるユーザーの注記を返します。
+//
+// エンドポイント例:
+// GET /candles/{code}/annotations?interval=1day&from=2024-01-01&to=2024-06-30
+func (h *Handler) CandleAnnotations(w http.ResponseWriter, r *http.Request) {
+	userID, ok := jwt.UserIDFromContext(r.Context())
+	if !ok {
+		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
+		return
+	}
+	code := chi.URLParam(r, "code")
+	if !symbolCodePattern.MatchString(code) {
+		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "invalid symbol code"})
+		return
+	}
+	q := r.URL.Query()
+	f := annotations.Filter{SymbolCode: code, Interval: q.Get("interval")}
+	if f.Interval == "" {
+		f.Interval = "1day"
+	}
+	var err error
+	if f.From, err = dateParam(q, "from"); err != nil {
+		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if f.To, err = dateParam(q, "to"); err != nil {
+		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		return
+	}
+	h.writePage(w, r, userID, f)
+}
+
+func (h *Handler) writePage(w http.ResponseWriter, r *http.Request, userID int64, f annotations.Filter) {
+	page, err := h.uc.List(r.Context(), userID, f, r.URL.Query())
+	if err != nil {
+		httpx.WriteError(w, err, "failed to list annotations", "userID", userID)
+		return
+	}
+
+	out := api.AnnotationPage{
+		Annotations: make([]api.Annotation, 0, len(page.Annotations)),
+		NextCursor:  page.NextCursor,
+	}
+	for _, a := range page.Annotations {
+		out.Annotations = append(out.Annotations, toAnnotation(a))
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+// Get は {id} の注記を返します。
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, ok := jwt.UserIDFromContext(r.Context())
+	if !ok {
+		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
+		return
+	}
+	id, ok := annotationID(w, r)
+	if !ok {
+		return
+	}
+
+	a, err := h.uc.Get(r.Context(), userID, id)
+	if err != nil {
+		httpx.WriteError(w, err, "failed to get annotation", "userID", userID, "id", id)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	httpx.WriteJSON(w, http.StatusOK, toAnnotation(a))
+}
+
+// Create は注記を登録し、登録した注記を 201 で返します。
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := jwt.UserIDFromContext(r.Context())
+	if !ok {
+		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
+		return
+	}
+	var req api.CreateAnnotationRequest
+	if err := httpx.DecodeAndValidate(r, &req); err != nil {
+		httpx.WriteDecodeError(w, err, "invalid request")
+		return
+	}
+
+	a, err := h.uc.Create(r.Context(), annotations.Annotation{
+		UserID:     userID,
+		SymbolCode: req.Symbol,
+		Interval:   req.Interval,
+		Time:       req.Time.Time,
+		Text:       req.Text,
+	})
+	if err != nil {
+		httpx.WriteError(w, err, "failed to create annotation", "userID", userID, "symbol", req.Symbol)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusCreated, toAnnotation(a))
+}
+
+// Update は {id} の注記のうち指定された項目（日付・本文）を変更し、変更後の注記を返します。
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	userID, ok := jwt.UserIDFromContext(r.Context())
+	if !ok {
+		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
+		return
+	}
+	id, ok := annotationID(w, r)
+	if !ok {
+		return
+	}
+	var req api.UpdateAnnotationRequest
+	if err := httpx.DecodeAndValidate(r, &req); err != nil {
+		httpx.WriteDecodeError(w, err, "invalid request")
+		return
+	}
+
+	p := annotations.Patch{Text: req.Text}
+	if req.Time != nil {
+		p.Time = &req.Time.Time
+	}
+	a, err := h.uc.Update(r.Context(), userID, id, p)
+	if err != nil {
+		httpx.WriteError(w, err, "failed to update annotation", "userID", userID, "id", id)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, toAnnotation(a))
+}
+
+// Delete は {id} の注記を削除します。
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := jwt.UserIDFromContext(r.Context())
+	if !ok {
+		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
+		return
+	}
+	id, ok := annotationID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.uc.Delete(r.Context(), userID, id); err != nil {
+		httpx.WriteError(w, err, "failed to delete annotation", "userID", userID, "id", id)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// annotationID は {id} を解釈します。数値でない場合は存在しない ID として 404 を書き込み ok=false を返します。
+func annotationID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	raw := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		httpx.WriteError(w, annotations.ErrAnnotationNotFound, "invalid annotation id", "id", raw)
+		return 0, false
+	}
+	return id, true
+}
+
+// dateParam はクエリパラメータ name（YYYY-MM-DD）を UTC の 0 時として解釈します。未指定の場合はゼロ値を返します。
+func dateParam(q url.Values, name string) (time.Time, error) {
+	raw := q.Get(name)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	d, err := api.ParseDate(raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %w", name, err)
+	}
+	return d.Time, nil
+}
+
+func toAnnotation(a annotations.Annotation) api.Annotation {
+	return api.Annotation{
+		Id:        a.ID,
+		Symbol:    a.SymbolCode,
+		Interval:  a.Interval,
+		Time:      api.NewDate(a.Time),
+		Text:      a.Text,
+		CreatedAt: api.NewTimestamp(a.CreatedAt),
+		UpdatedAt: api.NewTimestamp(a.UpdatedAt),
+	}
+}
@@ -0,0 +1,310 @@
+package annotationshttp_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/annotations"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/annotations/annotationshttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/queryspec"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+)
+
+const testUserID int64 = 1
+
+// mockUsecase は Usecase インターフェースのモック実装です。呼び出し時の引数を記録します。
+type mockUsecase struct {
+	ListFunc   func(ctx context.Context, userID int64, f annotations.Filter, q url.Values) (annotations.Page, error)
+	GetFunc    func(ctx context.Context, userID, id int64) (annotations.Annotation, error)
+	CreateFunc func(ctx context.Context, a annotations.Annotation) (annotations.Annotation, error)
+	UpdateFunc func(ctx context.Context, userID, id int64, p annotations.Patch) (annotations.Annotation, error)
+	DeleteFunc func(ctx context.Context, userID, id int64) error
+}
+
+func (m *mockUsecase) List(ctx context.Context, userID int64, f annotations.Filter, q url.Values) (annotations.Page, error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx, userID, f, q)
+	}
+	return annotations.Page{}, nil
+}
+
+func (m *mockUsecase) Get(ctx context.Context, userID, id int64) (annotations.Annotation, error) {
+	if m.GetFunc != nil {
+		return m.GetFunc(ctx, userID, id)
+	}
+	return annotations.Annotation{}, nil
+}
+
+func (m *mockUsecase) Create(ctx context.Context, a annotations.Annotation) (annotations.Annotation, error) {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, a)
+	}
+	return a, nil
+}
+
+func (m *mockUsecase) Update(ctx context.Context, userID, id int64, p annotations.Patch) (annotations.Annotation, error) {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, userID, id, p)
+	}
+	return annotations.Annotation{}, nil
+}
+
+func (m *mockUsecase) Delete(ctx context.Context, userID, id int64) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, userID, id)
+	}
+	return nil
+}
+
+// newRouter は認証済みユーザーIDを context に注入し、本番と同じパスでハンドラーを登録した chi ルーターを構築します。
+func newRouter(uc *mockUsecase) chi.Router {
+	h := annotationshttp.NewHandler(uc)
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			next.ServeHTTP(w, req.WithContext(jwt.WithUserID(req.Context(), testUserID)))
+		})
+	})
+	r.Get("/annotations", h.List)
+	r.Post("/annotations", h.Create)
+	r.Get("/annotations/{id}", h.Get)
+	r.Patch("/annotations/{id}", h.Update)
+	r.Delete("/annotations/{id}", h.Delete)
+	r.Get("/candles/{code}/annotations", h.CandleAnnotations)
+	return r
+}
+
+func serve(r http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+var sample = annotations.Annotation{
+	ID: 7, UserID: testUserID, SymbolCode: "AAPL", Interval: "1day",
+	Time: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), Text: "決算好調",
+	CreatedAt: time.Date(2026, 3, 3, 9, 0, 0, 0, time.UTC),
+	UpdatedAt: time.Date(2026, 3, 3, 9, 0, 0, 0, time.UTC),
+}
+
+func TestAnnotationsHandler_List(t *testing.T) {
+	t.Parallel()
+
+	var gotUser int64
+	var gotFilter annotations.Filter
+	var gotQuery url.Values
+	uc := &mockUsecase{ListFunc: func(_ context.Context, userID int64, f annotations.Filter, q url.Values) (annotations.Page, error) {
+		gotUser, gotFilter, gotQuery = userID, f, q
+		return annotations.Page{Annotations: []annotations.Annotation{sample}, NextCursor: "next"}, nil
+	}}
+
+	w := serve(newRouter(uc), http.MethodGet, "/annotations?symbol=AAPL&interval=1day&limit=1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	assert.JSONEq(t, `{"annotations":[{"id":7,"symbol":"AAPL","interval":"1day","time":"2026-03-02","text":"決算好調",
+		"createdAt":"2026-03-03T09:00:00Z","updatedAt":"2026-03-03T09:00:00Z"}],"nextCursor":"next"}`, w.Body.String())
+	assert.Equal(t, testUserID, gotUser)
+	assert.Equal(t, annotations.Filter{SymbolCode: "AAPL", Interval: "1day"}, gotFilter)
+	assert.Equal(t, "1", gotQuery.Get("limit"))
+}
+
+func TestAnnotationsHandler_List_InvalidQuery(t *testing.T) {
+	t.Parallel()
+	uc := &mockUsecase{ListFunc: func(context.Context, int64, annotations.Filter, url.Values) (annotations.Page, error) {
+		return annotations.Page{}, queryspec.ErrInvalidQuery
+	}}
+
+	w := serve(newRouter(uc), http.MethodGet, "/annotations?cursor=bad", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAnnotationsHandler_CandleAnnotations(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		wantFilter annotations.Filter
+	}{
+		{
+			name:       "range and interval",
+			target:     "/candles/AAPL/annotations?interval=1week&from=2026-01-01&to=2026-03-31",
+			wantStatus: http.StatusOK,
+			wantFilter: annotations.Filter{
+				SymbolCode: "AAPL", Interval: "1week",
+				From: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+				To:   time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:       "defaults to 1day and open range",
+			target:     "/candles/7203.T/annotations",
+			wantStatus: http.StatusOK,
+			wantFilter: annotations.Filter{SymbolCode: "7203.T", Interval: "1day"},
+		},
+		{name: "invalid from", target: "/candles/AAPL/annotations?from=2026/01/01", wantStatus: http.StatusBadRequest},
+		{name: "invalid to", target: "/candles/AAPL/annotations?to=tomorrow", wantStatus: http.StatusBadRequest},
+		{name: "invalid code", target: "/candles/AA%20PL/annotations", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var called bool
+			var gotFilter annotations.Filter
+			uc := &mockUsecase{ListFunc: func(_ context.Context, userID int64, f annotations.Filter, _ url.Values) (annotations.Page, error) {
+				called, gotFilter = true, f
+				assert.Equal(t, testUserID, userID)
+				return annotations.Page{}, nil
+			}}
+
+			w := serve(newRouter(uc), http.MethodGet, tt.target, "")
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus != http.StatusOK {
+				assert.False(t, called)
+				return
+			}
+			assert.Equal(t, tt.wantFilter, gotFilter)
+			assert.JSONEq(t, `{"annotations":[]}`, w.Body.String())
+		})
+	}
+}
+
+func TestAnnotationsHandler_Create(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		body       string
+		ucErr      error
+		wantStatus int
+	}{
+		{name: "created", body: `{"symbol":"AAPL","interval":"1day","time":"2026-03-02","text":"決算好調"}`, wantStatus: http.StatusCreated},
+		{name: "missing text", body: `{"symbol":"AAPL","interval":"1day","time":"2026-03-02"}`, wantStatus: http.StatusBadRequest},
+		{name: "malformed time", body: `{"symbol":"AAPL","interval":"1day","time":"03/02","text":"x"}`, wantStatus: http.StatusBadRequest},
+		{name: "text too long", body: `{"symbol":"AAPL","interval":"1day","time":"2026-03-02","text":"` + strings.Repeat("あ", 501) + `"}`, wantStatus: http.StatusBadRequest},
+		{name: "out of range", body: `{"symbol":"AAPL","interval":"1day","time":"1990-01-01","text":"x"}`, ucErr: annotations.ErrInvalidAnnotation, wantStatus: http.StatusBadRequest},
+		{name: "inactive symbol", body: `{"symbol":"OLD","interval":"1day","time":"2026-03-02","text":"x"}`, ucErr: annotations.ErrSymbolNotFound, wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var got annotations.Annotation
+			uc := &mockUsecase{CreateFunc: func(_ context.Context, a annotations.Annotation) (annotations.Annotation, error) {
+				got = a
+				if tt.ucErr != nil {
+					return annotations.Annotation{}, tt.ucErr
+				}
+				a.ID = 7
+				return a, nil
+			}}
+
+			w := serve(newRouter(uc), http.MethodPost, "/annotations", tt.body)
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus != http.StatusCreated {
+				return
+			}
+			assert.Equal(t, testUserID, got.UserID, "ユーザーは JWT から決める")
+			assert.Equal(t, time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), got.Time)
+			var resp api.Annotation
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, int64(7), resp.Id)
+			assert.Equal(t, "決算好調", resp.Text)
+		})
+	}
+}
+
+func TestAnnotationsHandler_Update(t *testing.T) {
+	t.Parallel()
+
+	var gotID int64
+	var gotPatch annotations.Patch
+	uc := &mockUsecase{UpdateFunc: func(_ context.Context, userID, id int64, p annotations.Patch) (annotations.Annotation, error) {
+		assert.Equal(t, testUserID, userID)
+		gotID, gotPatch = id, p
+		return sample, nil
+	}}
+	r := newRouter(uc)
+
+	w := serve(r, http.MethodPatch, "/annotations/7", `{"text":"更新"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(7), gotID)
+	require.NotNil(t, gotPatch.Text)
+	assert.Equal(t, "更新", *gotPatch.Text)
+	assert.Nil(t, gotPatch.Time, "指定しない項目は nil")
+
+	w = serve(r, http.MethodPatch, "/annotations/7", `{"time":"2026-03-05"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, gotPatch.Text)
+	require.NotNil(t, gotPatch.Time)
+	assert.Equal(t, time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC), *gotPatch.Time)
+}
+
+// TestAnnotationsHandler_OtherUsersAnnotation は他のユーザーの注記（usecase が ErrAnnotationNotFound を返す）と
+// 数値でない ID がいずれも 404 になることを検証します。
+func TestAnnotationsHandler_OtherUsersAnnotation(t *testing.T) {
+	t.Parallel()
+
+	uc := &mockUsecase{
+		GetFunc: func(context.Context, int64, int64) (annotations.Annotation, error) {
+			return annotations.Annotation{}, annotations.ErrAnnotationNotFound
+		},
+		UpdateFunc: func(context.Context, int64, int64, annotations.Patch) (annotations.Annotation, error) {
+			return annotations.Annotation{}, annotations.ErrAnnotationNotFound
+		},
+		DeleteFunc: func(context.Context, int64, int64) error { return annotations.ErrAnnotationNotFound },
+	}
+	r := newRouter(uc)
+
+	for _, tc := range []struct{ method, target, body string }{
+		{http.MethodGet, "/annotations/99", ""},
+		{http.MethodPatch, "/annotations/99", `{"text":"x"}`},
+		{http.MethodDelete, "/annotations/99", ""},
+		{http.MethodGet, "/annotations/abc", ""},
+		{http.MethodDelete, "/annotations/abc", ""},
+	} {
+		w := serve(r, tc.method, tc.target, tc.body)
+		assert.Equal(t, http.StatusNotFound, w.Code, "%s %s", tc.method, tc.target)
+	}
+}
+
+func TestAnnotationsHandler_Delete(t *testing.T) {
+	t.Parallel()
+
+	var gotID int64
+	uc := &mockUsecase{DeleteFunc: func(_ context.Context, userID, id int64) error {
+		assert.Equal(t, testUserID, userID)
+		gotID = id
+		return nil
+	}}
+
+	w := serve(newRouter(uc), http.MethodDelete, "/annotations/7", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, int64(7), gotID)
+}
+
+func TestAnnotationsHandler_MissingUser(t *testing.T) {
+	t.Parallel()
+
+	h := annotationshttp.NewHandler(&mockUsecase{})
+	r := chi.NewRouter()
+	r.Get("/annotations", h.List)
+
+	w := serve(r, http.MethodGet, "/annotations", "")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,15 @@
+package annotations
+
+import "github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
+
+var (
+	// ErrAnnotationNotFound は指定IDの注記が存在しないか、他のユーザーの注記である場合のエラーです。
+	// 他のユーザーの注記の存在を推測させないよう、両者を区別しません。
+	ErrAnnotationNotFound = apperr.New(apperr.KindNotFound, "annotation_not_found", "annotation not found")
+
+	// ErrInvalidAnnotation は注記の値が不正（本文の欠落・文字数超過、保存済みの足の範囲外の日付等）な場合のエラーです。
+	ErrInvalidAnnotation = apperr.New(apperr.KindInvalid, "invalid_annotation", "invalid annotation")
+
+	// ErrSymbolNotFound は指定された銘柄コードが存在しないか、アクティブでない場合のエラーです。
+	ErrSymbolNotFound = apperr.New(apperr.KindNotFound, "symbol_not_found", "symbol not found")
+)
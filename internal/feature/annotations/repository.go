@@ -0,0 +1,121 @@
+package annotations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/annotations/sqlc"
+)
+
+const pgForeignKeyViolation = "23503"
+
+// repository は Repository の sqlc ベース実装です。
+// 全てのクエリで user_id を条件に含めるため、他のユーザーの注記は存在しないものとして扱われます。
+type repository struct {
+	q *annotationssqlc.Queries
+}
+
+var _ Repository = (*repository)(nil)
+
+// NewRepository は指定された *sql.DB で repository の新しいインスタンスを生成します。
+func NewRepository(db *sql.DB) *repository {
+	return &repository{q: annotationssqlc.New(db)}
+}
+
+// Create は注記を登録し、採番された ID と作成日時を含む注記を返します。
+// 銘柄が symbols に存在しない場合（FK 違反）は ErrSymbolNotFound を返します。
+func (r *repository) Create(ctx context.Context, a Annotation) (Annotation, error) {
+	row, err := r.q.InsertAnnotation(ctx, annotationssqlc.InsertAnnotationParams{
+		UserID:     a.UserID,
+		SymbolCode: a.SymbolCode,
+		Interval:   a.Interval,
+		Time:       a.Time,
+		Text:       a.Text,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+			return Annotation{}, ErrSymbolNotFound
+		}
+		return Annotation{}, err
+	}
+	return toAnnotation(row), nil
+}
+
+// Get はユーザー userID の注記 id を返します。存在しない・他のユーザーの注記の場合は ErrAnnotationNotFound を返します。
+func (r *repository) Get(ctx context.Context, userID, id int64) (Annotation, error) {
+	row, err := r.q.GetAnnotation(ctx, annotationssqlc.GetAnnotationParams{UserID: userID, ID: id})
+	if errors.Is(err, sql.ErrNoRows) {
+		return Annotation{}, ErrAnnotationNotFound
+	}
+	if err != nil {
+		return Annotation{}, err
+	}
+	return toAnnotation(row), nil
+}
+
+// Update は a.UserID の注記 a.ID の日付と本文を置き換えます。存在しない・他のユーザーの注記の場合は ErrAnnotationNotFound を返します。
+func (r *repository) Update(ctx context.Context, a Annotation) (Annotation, error) {
+	row, err := r.q.UpdateAnnotation(ctx, annotationssqlc.UpdateAnnotationParams{
+		UserID: a.UserID,
+		ID:     a.ID,
+		Time:   a.Time,
+		Text:   a.Text,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return Annotation{}, ErrAnnotationNotFound
+	}
+	if err != nil {
+		return Annotation{}, err
+	}
+	return toAnnotation(row), nil
+}
+
+// Delete はユーザー userID の注記 id を削除します。存在しない・他のユーザーの注記の場合は ErrAnnotationNotFound を返します。
+func (r *repository) Delete(ctx context.Context, userID, id int64) error {
+	n, err := r.q.DeleteAnnotation(ctx, annotationssqlc.DeleteAnnotationParams{UserID: userID, ID: id})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrAnnotationNotFound
+	}
+	return nil
+}
+
+// List はユーザー userID の注記のうち f に一致するものを、ID が afterID より大きいものから ID 昇順で最大 limit 件返します。
+func (r *repository) List(ctx context.Context, userID int64, f Filter, limit int, afterID int64) ([]Annotation, error) {
+	rows, err := r.q.ListAnnotations(ctx, annotationssqlc.ListAnnotationsParams{
+		UserID:     userID,
+		AfterID:    afterID,
+		SymbolCode: f.SymbolCode,
+		Interval:   f.Interval,
+		FromTime:   sql.NullTime{Time: f.From, Valid: !f.From.IsZero()},
+		ToTime:     sql.NullTime{Time: f.To, Valid: !f.To.IsZero()},
+		MaxRows:    int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Annotation, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, toAnnotation(row))
+	}
+	return out, nil
+}
+
+func toAnnotation(row annotationssqlc.Annotation) Annotation {
+	return Annotation{
+		ID:         row.ID,
+		UserID:     row.UserID,
+		SymbolCode: row.SymbolCode,
+		Interval:   row.Interval,
+		Time:       row.Time,
+		Text:       row.Text,
+		CreatedAt:  row.CreatedAt,
+		UpdatedAt:  row.UpdatedAt,
+	}
+}
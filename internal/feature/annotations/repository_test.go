@@ -0,0 +1,145 @@
+package annotations
+
+import (
+	"context"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/db/dbtest"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/testsupport"
+)
+
+func TestMain(m *testing.M) {
+	code, err := dbtest.RunMainWithPostgres(m)
+	if err != nil {
+		log.Fatalf("dbtest setup: %v", err)
+	}
+	os.Exit(code)
+}
+
+func day(d int) time.Time {
+	return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestAnnotationRepository_CRUD(t *testing.T) {
+	t.Parallel()
+	db := testsupport.NewDB(t, testsupport.InsertSymbols("AAPL", "MSFT"))
+	u1 := testsupport.InsertUser(t, db).ID
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	created, err := repo.Create(ctx, Annotation{UserID: u1, SymbolCode: "AAPL", Interval: "1day", Time: day(2), Text: "決算好調"})
+	require.NoError(t, err)
+	assert.Positive(t, created.ID)
+	assert.Equal(t, u1, created.UserID)
+	assert.True(t, created.Time.Equal(day(2)))
+	assert.False(t, created.CreatedAt.IsZero())
+
+	got, err := repo.Get(ctx, u1, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "決算好調", got.Text)
+
+	got.Text = "ここで購入"
+	got.Time = day(3)
+	updated, err := repo.Update(ctx, got)
+	require.NoError(t, err)
+	assert.Equal(t, "ここで購入", updated.Text)
+	assert.True(t, updated.Time.Equal(day(3)))
+	assert.Equal(t, "AAPL", updated.SymbolCode)
+
+	require.NoError(t, repo.Delete(ctx, u1, created.ID))
+	_, err = repo.Get(ctx, u1, created.ID)
+	assert.ErrorIs(t, err, ErrAnnotationNotFound)
+	assert.ErrorIs(t, repo.Delete(ctx, u1, created.ID), ErrAnnotationNotFound)
+}
+
+func TestAnnotationRepository_Create_UnknownSymbol(t *testing.T) {
+	t.Parallel()
+	db := testsupport.NewDB(t, testsupport.InsertSymbols("AAPL", "MSFT"))
+	u1 := testsupport.InsertUser(t, db).ID
+	repo := NewRepository(db)
+
+	_, err := repo.Create(context.Background(), Annotation{UserID: u1, SymbolCode: "NOPE", Interval: "1day", Time: day(2), Text: "x"})
+	assert.ErrorIs(t, err, ErrSymbolNotFound)
+}
+
+// TestAnnotationRepository_CrossUserAccess は他のユーザーの注記を取得・変更・削除・一覧できないことを検証します。
+func TestAnnotationRepository_CrossUserAccess(t *testing.T) {
+	t.Parallel()
+	db := testsupport.NewDB(t, testsupport.InsertSymbols("AAPL", "MSFT"))
+	u1, u2 := testsupport.InsertUser(t, db).ID, testsupport.InsertUser(t, db).ID
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	own, err := repo.Create(ctx, Annotation{UserID: u1, SymbolCode: "AAPL", Interval: "1day", Time: day(2), Text: "u1 のメモ"})
+	require.NoError(t, err)
+
+	_, err = repo.Get(ctx, u2, own.ID)
+	assert.ErrorIs(t, err, ErrAnnotationNotFound)
+
+	_, err = repo.Update(ctx, Annotation{ID: own.ID, UserID: u2, Time: day(3), Text: "上書き"})
+	assert.ErrorIs(t, err, ErrAnnotationNotFound)
+
+	assert.ErrorIs(t, repo.Delete(ctx, u2, own.ID), ErrAnnotationNotFound)
+
+	list, err := repo.List(ctx, u2, Filter{}, 100, 0)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+	list, err = repo.List(ctx, u2, Filter{SymbolCode: "AAPL", Interval: "1day"}, 100, 0)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	// u1 の注記は変更されていない
+	got, err := repo.Get(ctx, u1, own.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "u1 のメモ", got.Text)
+	assert.True(t, got.Time.Equal(day(2)))
+}
+
+func TestAnnotationRepository_List(t *testing.T) {
+	t.Parallel()
+	db := testsupport.NewDB(t, testsupport.InsertSymbols("AAPL", "MSFT"))
+	u1 := testsupport.InsertUser(t, db).ID
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	create := func(code, interval string, d int) Annotation {
+		a, err := repo.Create(ctx, Annotation{UserID: u1, SymbolCode: code, Interval: interval, Time: day(d), Text: "note"})
+		require.NoError(t, err)
+		return a
+	}
+	a1 := create("AAPL", "1day", 2)
+	a2 := create("AAPL", "1day", 5)
+	a3 := create("AAPL", "1week", 2)
+	a4 := create("MSFT", "1day", 3)
+	a5 := create("AAPL", "1day", 9)
+
+	idsOf := func(as []Annotation) []int64 {
+		out := make([]int64, 0, len(as))
+		for _, a := range as {
+			out = append(out, a.ID)
+		}
+		return out
+	}
+
+	all, err := repo.List(ctx, u1, Filter{}, 100, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{a1.ID, a2.ID, a3.ID, a4.ID, a5.ID}, idsOf(all), "ID 昇順")
+
+	bySymbol, err := repo.List(ctx, u1, Filter{SymbolCode: "AAPL"}, 100, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{a1.ID, a2.ID, a3.ID, a5.ID}, idsOf(bySymbol))
+
+	inRange, err := repo.List(ctx, u1, Filter{SymbolCode: "AAPL", Interval: "1day", From: day(2), To: day(5)}, 100, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{a1.ID, a2.ID}, idsOf(inRange), "範囲の両端を含む")
+
+	page, err := repo.List(ctx, u1, Filter{SymbolCode: "AAPL", Interval: "1day"}, 2, a1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{a2.ID, a5.ID}, idsOf(page), "afterID より後から limit 件")
+}
@@ -0,0 +1,36 @@
+-- 全クエリで user_id を条件に含め、他のユーザーの注記を読み書きできないようにする。
+
+-- name: InsertAnnotation :one
+INSERT INTO annotations (user_id, symbol_code, "interval", "time", text)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, user_id, symbol_code, "interval", "time", text, created_at, updated_at;
+
+-- name: GetAnnotation :one
+SELECT id, user_id, symbol_code, "interval", "time", text, created_at, updated_at
+FROM annotations
+WHERE user_id = $1 AND id = $2;
+
+-- name: UpdateAnnotation :one
+UPDATE annotations
+SET "time" = $3,
+    text = $4,
+    updated_at = now()
+WHERE user_id = $1 AND id = $2
+RETURNING id, user_id, symbol_code, "interval", "time", text, created_at, updated_at;
+
+-- name: DeleteAnnotation :execrows
+DELETE FROM annotations
+WHERE user_id = $1 AND id = $2;
+
+-- name: ListAnnotations :many
+-- 空文字・NULL の条件は絞り込まない。ID が after_id より大きいものを ID 昇順で返す（カーソルによるページング）。
+SELECT id, user_id, symbol_code, "interval", "time", text, created_at, updated_at
+FROM annotations
+WHERE user_id = sqlc.arg(user_id)
+  AND id > sqlc.arg(after_id)
+  AND (sqlc.arg(symbol_code)::text = '' OR symbol_code = sqlc.arg(symbol_code))
+  AND (sqlc.arg(interval)::text = '' OR "interval" = sqlc.arg(interval))
+  AND (sqlc.narg(from_time)::timestamptz IS NULL OR "time" >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR "time" <= sqlc.narg(to_time))
+ORDER BY id ASC
+LIMIT sqlc.arg(max_rows);
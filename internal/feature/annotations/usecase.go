@@ -0,0 +1,155 @@
+package annotations
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/queryspec"
+)
+
+const (
+	// DefaultPageSize は注記一覧の limit 未指定時の件数です。
+	DefaultPageSize = 100
+	// MaxPageSize は注記一覧で指定可能な最大件数です。
+	MaxPageSize = 500
+)
+
+// Repository は注記の永続化層を抽象化します。
+// 全ての操作はユーザー ID で絞り込み、他のユーザーの注記は ErrAnnotationNotFound として扱います。
+type Repository interface {
+	Create(ctx context.Context, a Annotation) (Annotation, error)
+	Get(ctx context.Context, userID, id int64) (Annotation, error)
+	// Update は a.UserID の注記 a.ID の日付と本文を置き換えます。
+	Update(ctx context.Context, a Annotation) (Annotation, error)
+	Delete(ctx context.Context, userID, id int64) error
+	// List は f に一致する注記を、ID が afterID より大きいものから ID 昇順で最大 limit 件返します。
+	List(ctx context.Context, userID int64, f Filter, limit int, afterID int64) ([]Annotation, error)
+}
+
+// ActiveSymbolChecker はアクティブ銘柄かどうかの判定を行うインターフェースです。
+// annotations usecase が symbollist feature に直接依存しないよう、
+// 最小限の読み取り専用インターフェースをここで定義します。
+type ActiveSymbolChecker interface {
+	Contains(ctx context.Context, code string) (bool, error)
+}
+
+// CandleRangeFinder は保存済みのローソク足の時刻の範囲を返すインターフェースです（candles のリポジトリが実装）。
+type CandleRangeFinder interface {
+	// TimeRange は銘柄・時間間隔の最古・最新の足の時刻を返します。足が 1 件もない場合は found=false です。
+	TimeRange(ctx context.Context, symbol, interval string) (first, last time.Time, found bool, err error)
+}
+
+// usecase は注記の登録・参照のビジネスロジックを提供します。
+type usecase struct {
+	repo    Repository
+	symbols ActiveSymbolChecker
+	candles CandleRangeFinder
+}
+
+// NewUsecase は usecase の新しいインスタンスを生成します。
+func NewUsecase(repo Repository, symbols ActiveSymbolChecker, candles CandleRangeFinder) *usecase {
+	return &usecase{repo: repo, symbols: symbols, candles: candles}
+}
+
+// List はユーザーの注記のうち f に一致するものを ID 順に返します。
+// q の limit / cursor でページングし、不正な指定は queryspec.ErrInvalidQuery を返します。
+func (u *usecase) List(ctx context.Context, userID int64, f Filter, q url.Values) (Page, error) {
+	page, err := queryspec.ParsePage(q, DefaultPageSize, MaxPageSize)
+	if err != nil {
+		return Page{}, err
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && f.From.After(f.To) {
+		return Page{}, fmt.Errorf("%w: from must not be after to", queryspec.ErrInvalidQuery)
+	}
+
+	// 次ページの有無を知るため 1 件多く取得する
+	as, err := u.repo.List(ctx, userID, f, page.Limit+1, page.AfterID)
+	if err != nil {
+		return Page{}, fmt.Errorf("list annotations: %w", err)
+	}
+	var result Page
+	if len(as) > page.Limit {
+		as = as[:page.Limit]
+		result.NextCursor = queryspec.EncodeCursor(as[len(as)-1].ID)
+	}
+	result.Annotations = as
+	return result, nil
+}
+
+// Get はユーザーの注記 id を返します。
+func (u *usecase) Get(ctx context.Context, userID, id int64) (Annotation, error) {
+	return u.repo.Get(ctx, userID, id)
+}
+
+// Create は注記を登録します。本文が不正な場合、または日付が保存済みの足の範囲外の場合は ErrInvalidAnnotation、
+// 銘柄がアクティブでない場合は ErrSymbolNotFound を返します。
+func (u *usecase) Create(ctx context.Context, a Annotation) (Annotation, error) {
+	a.SymbolCode = strings.TrimSpace(a.SymbolCode)
+	a.Text = strings.TrimSpace(a.Text)
+	a.Time = utcDay(a.Time)
+	if err := a.Validate(); err != nil {
+		return Annotation{}, err
+	}
+	active, err := u.symbols.Contains(ctx, a.SymbolCode)
+	if err != nil {
+		return Annotation{}, fmt.Errorf("checking symbol %s: %w", a.SymbolCode, err)
+	}
+	if !active {
+		return Annotation{}, ErrSymbolNotFound
+	}
+	if err := u.checkInRange(ctx, a); err != nil {
+		return Annotation{}, err
+	}
+	return u.repo.Create(ctx, a)
+}
+
+// Update はユーザーの注記 id の日付・本文を p で更新します。日付を変更する場合は保存済みの足の範囲を再検証します。
+func (u *usecase) Update(ctx context.Context, userID, id int64, p Patch) (Annotation, error) {
+	a, err := u.repo.Get(ctx, userID, id)
+	if err != nil {
+		return Annotation{}, err
+	}
+	if p.Text != nil {
+		a.Text = strings.TrimSpace(*p.Text)
+	}
+	moved := false
+	if p.Time != nil {
+		t := utcDay(*p.Time)
+		moved = !t.Equal(a.Time)
+		a.Time = t
+	}
+	if err := a.Validate(); err != nil {
+		return Annotation{}, err
+	}
+	if moved {
+		if err := u.checkInRange(ctx, a); err != nil {
+			return Annotation{}, err
+		}
+	}
+	return u.repo.Update(ctx, a)
+}
+
+// Delete はユーザーの注記 id を削除します。
+func (u *usecase) Delete(ctx context.Context, userID, id int64) error {
+	return u.repo.Delete(ctx, userID, id)
+}
+
+// checkInRange は a の日付が銘柄・時間間隔の保存済みの足の範囲（最古〜最新の足の日付）に含まれるかを検証します。
+// 足が 1 件もない時間間隔（未対応の時間間隔を含む）には注記を付けられません。
+func (u *usecase) checkInRange(ctx context.Context, a Annotation) error {
+	first, last, found, err := u.candles.TimeRange(ctx, a.SymbolCode, a.Interval)
+	if err != nil {
+		return fmt.Errorf("candle range %s/%s: %w", a.SymbolCode, a.Interval, err)
+	}
+	if !found {
+		return fmt.Errorf("%w: no candles stored for %s %s", ErrInvalidAnnotation, a.SymbolCode, a.Interval)
+	}
+	if a.Time.Before(utcDay(first)) || a.Time.After(utcDay(last)) {
+		return fmt.Errorf("%w: time must be between %s and %s", ErrInvalidAnnotation,
+			utcDay(first).Format(time.DateOnly), utcDay(last).Format(time.DateOnly))
+	}
+	return nil
+}
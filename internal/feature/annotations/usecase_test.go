@@ -0,0 +1,248 @@
+package annotations_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/annotations"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/queryspec"
+)
+
+// fakeRepository はユーザーごとに分離したインメモリの Repository です。
+type fakeRepository struct {
+	nextID  int64
+	byID    map[int64]annotations.Annotation
+	created []annotations.Annotation
+	updated []annotations.Annotation
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{byID: make(map[int64]annotations.Annotation)}
+}
+
+func (f *fakeRepository) Create(_ context.Context, a annotations.Annotation) (annotations.Annotation, error) {
+	f.nextID++
+	a.ID = f.nextID
+	f.byID[a.ID] = a
+	f.created = append(f.created, a)
+	return a, nil
+}
+
+func (f *fakeRepository) Get(_ context.Context, userID, id int64) (annotations.Annotation, error) {
+	a, ok := f.byID[id]
+	if !ok || a.UserID != userID {
+		return annotations.Annotation{}, annotations.ErrAnnotationNotFound
+	}
+	return a, nil
+}
+
+func (f *fakeRepository) Update(_ context.Context, a annotations.Annotation) (annotations.Annotation, error) {
+	if cur, ok := f.byID[a.ID]; !ok || cur.UserID != a.UserID {
+		return annotations.Annotation{}, annotations.ErrAnnotationNotFound
+	}
+	f.byID[a.ID] = a
+	f.updated = append(f.updated, a)
+	return a, nil
+}
+
+func (f *fakeRepository) Delete(_ context.Context, userID, id int64) error {
+	if a, ok := f.byID[id]; !ok || a.UserID != userID {
+		return annotations.ErrAnnotationNotFound
+	}
+	delete(f.byID, id)
+	return nil
+}
+
+func (f *fakeRepository) List(_ context.Context, userID int64, _ annotations.Filter, limit int, afterID int64) ([]annotations.Annotation, error) {
+	var out []annotations.Annotation
+	for id := afterID + 1; id <= f.nextID && len(out) < limit; id++ {
+		if a, ok := f.byID[id]; ok && a.UserID == userID {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+type fakeSymbols map[string]bool
+
+func (s fakeSymbols) Contains(_ context.Context, code string) (bool, error) {
+	return s[code], nil
+}
+
+// fakeCandles は銘柄/時間間隔ごとの足の範囲を返します。
+type fakeCandles map[string][2]time.Time
+
+func (c fakeCandles) TimeRange(_ context.Context, symbol, interval string) (time.Time, time.Time, bool, error) {
+	r, ok := c[symbol+"/"+interval]
+	return r[0], r[1], ok, nil
+}
+
+var (
+	// ニューヨーク時間 0 時の足（UTC では 05:00）。API の日付は UTC の暦日で比較する。
+	firstCandle = time.Date(2026, 1, 2, 5, 0, 0, 0, time.UTC)
+	lastCandle  = time.Date(2026, 3, 13, 4, 0, 0, 0, time.UTC)
+)
+
+func newUsecase(repo *fakeRepository) annotationsUsecase {
+	return annotations.NewUsecase(repo,
+		fakeSymbols{"AAPL": true},
+		fakeCandles{"AAPL/1day": {firstCandle, lastCandle}},
+	)
+}
+
+type annotationsUsecase interface {
+	List(ctx context.Context, userID int64, f annotations.Filter, q url.Values) (annotations.Page, error)
+	Create(ctx context.Context, a annotations.Annotation) (annotations.Annotation, error)
+	Update(ctx context.Context, userID, id int64, p annotations.Patch) (annotations.Annotation, error)
+	Delete(ctx context.Context, userID, id int64) error
+}
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestUsecase_Create(t *testing.T) {
+	t.Parallel()
+
+	base := annotations.Annotation{UserID: 1, SymbolCode: "AAPL", Interval: "1day", Time: date(2026, 2, 10), Text: "決算好調"}
+	tests := []struct {
+		name    string
+		mutate  func(a *annotations.Annotation)
+		wantErr error
+	}{
+		{name: "valid", mutate: func(*annotations.Annotation) {}},
+		{name: "first candle day", mutate: func(a *annotations.Annotation) { a.Time = date(2026, 1, 2) }},
+		{name: "last candle day", mutate: func(a *annotations.Annotation) { a.Time = date(2026, 3, 13) }},
+		{name: "500 runes", mutate: func(a *annotations.Annotation) { a.Text = strings.Repeat("あ", annotations.MaxTextLength) }},
+		{name: "501 runes", mutate: func(a *annotations.Annotation) { a.Text = strings.Repeat("あ", annotations.MaxTextLength+1) }, wantErr: annotations.ErrInvalidAnnotation},
+		{name: "blank text", mutate: func(a *annotations.Annotation) { a.Text = "  \n" }, wantErr: annotations.ErrInvalidAnnotation},
+		{name: "missing time", mutate: func(a *annotations.Annotation) { a.Time = time.Time{} }, wantErr: annotations.ErrInvalidAnnotation},
+		{name: "before first candle", mutate: func(a *annotations.Annotation) { a.Time = date(2026, 1, 1) }, wantErr: annotations.ErrInvalidAnnotation},
+		{name: "after last candle", mutate: func(a *annotations.Annotation) { a.Time = date(2026, 3, 14) }, wantErr: annotations.ErrInvalidAnnotation},
+		{name: "interval without candles", mutate: func(a *annotations.Annotation) { a.Interval = "1week" }, wantErr: annotations.ErrInvalidAnnotation},
+		{name: "inactive symbol", mutate: func(a *annotations.Annotation) { a.SymbolCode = "MSFT" }, wantErr: annotations.ErrSymbolNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repo := newFakeRepository()
+			a := base
+			tt.mutate(&a)
+
+			got, err := newUsecase(repo).Create(context.Background(), a)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, repo.created)
+				return
+			}
+			require.NoError(t, err)
+			assert.Positive(t, got.ID)
+			require.Len(t, repo.created, 1)
+		})
+	}
+}
+
+func TestUsecase_Create_Normalizes(t *testing.T) {
+	t.Parallel()
+	repo := newFakeRepository()
+
+	got, err := newUsecase(repo).Create(context.Background(), annotations.Annotation{
+		UserID: 1, SymbolCode: " AAPL ", Interval: "1day",
+		Time: time.Date(2026, 2, 10, 15, 30, 0, 0, time.UTC), Text: "  ここで購入\n",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "AAPL", got.SymbolCode)
+	assert.Equal(t, "ここで購入", got.Text)
+	assert.Equal(t, date(2026, 2, 10), got.Time, "UTC の暦日に丸める")
+}
+
+func TestUsecase_Update(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := newFakeRepository()
+	uc := newUsecase(repo)
+	a, err := uc.Create(ctx, annotations.Annotation{UserID: 1, SymbolCode: "AAPL", Interval: "1day", Time: date(2026, 2, 10), Text: "memo"})
+	require.NoError(t, err)
+
+	text := "更新"
+	got, err := uc.Update(ctx, 1, a.ID, annotations.Patch{Text: &text})
+	require.NoError(t, err)
+	assert.Equal(t, "更新", got.Text)
+	assert.Equal(t, date(2026, 2, 10), got.Time, "指定しない項目は変更しない")
+
+	moved := date(2026, 3, 2)
+	got, err = uc.Update(ctx, 1, a.ID, annotations.Patch{Time: &moved})
+	require.NoError(t, err)
+	assert.Equal(t, moved, got.Time)
+	assert.Equal(t, "更新", got.Text)
+
+	outside := date(2026, 4, 1)
+	_, err = uc.Update(ctx, 1, a.ID, annotations.Patch{Time: &outside})
+	assert.ErrorIs(t, err, annotations.ErrInvalidAnnotation)
+
+	blank := " "
+	_, err = uc.Update(ctx, 1, a.ID, annotations.Patch{Text: &blank})
+	assert.ErrorIs(t, err, annotations.ErrInvalidAnnotation)
+
+	_, err = uc.Update(ctx, 2, a.ID, annotations.Patch{Text: &text})
+	assert.ErrorIs(t, err, annotations.ErrAnnotationNotFound, "他のユーザーの注記は変更できない")
+	assert.Len(t, repo.updated, 2)
+}
+
+func TestUsecase_List_Paging(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := newFakeRepository()
+	uc := newUsecase(repo)
+	for i := range 5 {
+		_, err := uc.Create(ctx, annotations.Annotation{UserID: 1, SymbolCode: "AAPL", Interval: "1day", Time: date(2026, 2, 2+i), Text: "memo"})
+		require.NoError(t, err)
+	}
+
+	page, err := uc.List(ctx, 1, annotations.Filter{}, url.Values{"limit": {"2"}})
+	require.NoError(t, err)
+	require.Len(t, page.Annotations, 2)
+	require.NotEmpty(t, page.NextCursor)
+
+	var seen []int64
+	for _, a := range page.Annotations {
+		seen = append(seen, a.ID)
+	}
+	for page.NextCursor != "" {
+		page, err = uc.List(ctx, 1, annotations.Filter{}, url.Values{"limit": {"2"}, "cursor": {page.NextCursor}})
+		require.NoError(t, err)
+		for _, a := range page.Annotations {
+			seen = append(seen, a.ID)
+		}
+	}
+	assert.Equal(t, []int64{1, 2, 3, 4, 5}, seen)
+
+	page, err = uc.List(ctx, 2, annotations.Filter{}, url.Values{})
+	require.NoError(t, err)
+	assert.Empty(t, page.Annotations, "他のユーザーの注記は含まない")
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestUsecase_List_InvalidQuery(t *testing.T) {
+	t.Parallel()
+	uc := newUsecase(newFakeRepository())
+
+	for name, tc := range map[string]struct {
+		f annotations.Filter
+		q url.Values
+	}{
+		"limit too large": {q: url.Values{"limit": {"501"}}},
+		"bad cursor":      {q: url.Values{"cursor": {"!!"}}},
+		"from after to":   {f: annotations.Filter{From: date(2026, 3, 2), To: date(2026, 3, 1)}},
+	} {
+		_, err := uc.List(context.Background(), 1, tc.f, tc.q)
+		assert.True(t, errors.Is(err, queryspec.ErrInvalidQuery), name)
+	}
+}
@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/queryspec"
+)
+
+const (
+	// DefaultAdminUserPageSize は管理者向けユーザー一覧の limit 未指定時の件数です。
+	DefaultAdminUserPageSize = 50
+	// MaxAdminUserPageSize は管理者向けユーザー一覧で指定可能な最大件数です。
+	MaxAdminUserPageSize = 200
+	// maxUserQueryLength は検索文字列の最大文字数です（メールアドレスの上限 254 文字に合わせる）。
+	maxUserQueryLength = 254
+)
+
+// UserSearcher は管理者向けのユーザー検索を抽象化します。
+// Goの慣例に従い、インターフェースは利用者（usecase）側で定義します。
+type UserSearcher interface {
+	// Search はメールアドレスに query を含むユーザーを、ID が cursorID より大きいものから ID 昇順で最大 limit 件返します。
+	Search(ctx context.Context, query string, limit int, cursorID int64) ([]User, error)
+	// Count はメールアドレスに query を含むユーザーの総数を返します。
+	Count(ctx context.Context, query string) (int64, error)
+	// FindByID は ID でユーザーを取得します。存在しない場合は ErrUserNotFound を返します。
+	FindByID(ctx context.Context, id int64) (*User, error)
+}
+
+// UserPlanStore はユーザーの料金プランの変更を抽象化します。
+type UserPlanStore interface {
+	// UpdatePlan は id のユーザーの料金プランを更新し、更新後のユーザーを返します。存在しない場合は ErrUserNotFound を返します。
+	UpdatePlan(ctx context.Context, id int64, plan Plan) (*User, error)
+}
+
+// UserPage は管理者向けユーザー一覧の 1 ページです。
+// Total は検索に一致するユーザーの総数（ページングによらない）、NextCursor は次ページのカーソルで、最後のページでは空です。
+// Users の Password は常に nil です。
+type UserPage struct {
+	Users      []User
+	Total      int64
+	NextCursor string
+}
+
+// ImpersonationTokenGenerator はなりすましトークンの生成を抽象化します（transport/jwt の Generator が実装）。
+// Goの慣例に従い、インターフェースは利用者（usecase）側で定義します。
+type ImpersonationTokenGenerator interface {
+	// GenerateImpersonationToken は actor が userID のユーザーとして振る舞うトークンを生成します。
+	GenerateImpersonationToken(userID int64, email, actor string) (string, error)
+	// ImpersonationExpiresIn はなりすましトークンの有効期間を返します。
+	ImpersonationExpiresIn() time.Duration
+}
+
+// Impersonation は発行したなりすましトークンです。リフレッシュトークンは発行しません。
+type Impersonation struct {
+	Token     string
+	ExpiresIn time.Duration
+	ExpiresAt time.Time
+	User      User // 対象のユーザー（Password は nil）
+	Actor     string
+}
+
+// errImpersonationDisabled は WithImpersonation を設定せずに Impersonate を呼んだ場合のエラーです（構成の誤り）。
+var errImpersonationDisabled = errors.New("impersonation is not configured")
+
+// errPlansDisabled は WithPlans を設定せずに SetPlan を呼んだ場合のエラーです（構成の誤り）。
+var errPlansDisabled = errors.New("plan updates are not configured")
+
+// AdminUserUsecase はサポート対応のため管理者がユーザーを検索・参照するユースケースです。
+type AdminUserUsecase struct {
+	users         UserSearcher
+	plans         UserPlanStore
+	impersonation ImpersonationTokenGenerator
+	now           func() time.Time
+}
+
+// NewAdminUserUsecase は AdminUserUsecase の新しいインスタンスを生成します。
+func NewAdminUserUsecase(users UserSearcher) *AdminUserUsecase {
+	return &AdminUserUsecase{users: users, now: time.Now}
+}
+
+// WithImpersonation はなりすましトークンの発行（Impersonate）を gen で有効にします。
+func (u *AdminUserUsecase) WithImpersonation(gen ImpersonationTokenGenerator) *AdminUserUsecase {
+	u.impersonation = gen
+	return u
+}
+
+// WithPlans は料金プランの変更（SetPlan）を plans で有効にします。
+func (u *AdminUserUsecase) WithPlans(plans UserPlanStore) *AdminUserUsecase {
+	u.plans = plans
+	return u
+}
+
+// List は ?query=（メールアドレスの部分一致・大文字小文字を区別しない）に一致するユーザーを ID 順に返します。
+// limit / cursor でページングし、不正な指定は queryspec.ErrInvalidQuery を返します。
+func (u *AdminUserUsecase) List(ctx context.Context, q url.Values) (UserPage, error) {
+	page, err := queryspec.ParsePage(q, DefaultAdminUserPageSize, MaxAdminUserPageSize)
+	if err != nil {
+		return UserPage{}, err
+	}
+	query := strings.TrimSpace(q.Get("query"))
+	if utf8.RuneCountInString(query) > maxUserQueryLength {
+		return UserPage{}, fmt.Errorf("%w: query must be at most %d characters", queryspec.ErrInvalidQuery, maxUserQueryLength)
+	}
+
+	// 次ページの有無を知るため 1 件多く取得する
+	users, err := u.users.Search(ctx, query, page.Limit+1, page.AfterID)
+	if err != nil {
+		return UserPage{}, fmt.Errorf("search users: %w", err)
+	}
+	total, err := u.users.Count(ctx, query)
+	if err != nil {
+		return UserPage{}, fmt.Errorf("count users: %w", err)
+	}
+
+	result := UserPage{Total: total}
+	if len(users) > page.Limit {
+		users = users[:page.Limit]
+		result.NextCursor = queryspec.EncodeCursor(users[len(users)-1].ID)
+	}
+	for i := range users {
+		users[i].Password = nil
+	}
+	result.Users = users
+	return result, nil
+}
+
+// Get は id のユーザーを返します（Password は nil）。存在しない場合は ErrUserNotFound を返します。
+func (u *AdminUserUsecase) Get(ctx context.Context, id int64) (User, error) {
+	user, err := u.users.FindByID(ctx, id)
+	if err != nil {
+		return User{}, err
+	}
+	profile := *user
+	profile.Password = nil
+	return profile, nil
+}
+
+// SetPlan は actor（変更した管理者のユーザーID。例: "12"）が id のユーザーの料金プランを plan に変更し、変更後のユーザーを返します。
+// 定義されていないプランは ErrInvalidPlan、存在しないユーザーは ErrUserNotFound を返します。変更は監査ログ（audit=true）に記録します。
+// 参照の制限には CachingUserRepository のキャッシュの有効期間（他のインスタンスでは最大 DefaultUserCacheTTL）で反映されます。
+func (u *AdminUserUsecase) SetPlan(ctx context.Context, id int64, plan Plan, actor string) (User, error) {
+	if u.plans == nil {
+		return User{}, errPlansDisabled
+	}
+	if !plan.Valid() {
+		return User{}, ErrInvalidPlan
+	}
+	user, err := u.plans.UpdatePlan(ctx, id, plan)
+	if err != nil {
+		return User{}, err
+	}
+	slog.InfoContext(ctx, "user plan changed", "audit", true, "actor", actor, "user_id", id, "plan", plan)
+	profile := *user
+	profile.Password = nil
+	return profile, nil
+}
+
+// Impersonate は actor（発行した管理者のユーザーID。例: "12"）が id のユーザーとして振る舞う短命のトークンを発行します。
+// 存在しない場合は ErrUserNotFound を返します。発行は監査ログ（audit=true）に記録します。
+func (u *AdminUserUsecase) Impersonate(ctx context.Context, id int64, actor string) (Impersonation, error) {
+	if u.impersonation == nil {
+		return Impersonation{}, errImpersonationDisabled
+	}
+	user, err := u.Get(ctx, id)
+	if err != nil {
+		return Impersonation{}, err
+	}
+	token, err := u.impersonation.GenerateImpersonationToken(user.ID, user.Email, actor)
+	if err != nil {
+		return Impersonation{}, fmt.Errorf("generate impersonation token: %w", err)
+	}
+	ttl := u.impersonation.ImpersonationExpiresIn()
+	expiresAt := u.now().Add(ttl)
+	slog.InfoContext(ctx, "impersonation token issued", "audit", true, "actor", actor, "user_id", user.ID, "expires_at", expiresAt)
+	return Impersonation{Token: token, ExpiresIn: ttl, ExpiresAt: expiresAt, User: user, Actor: actor}, nil
+}
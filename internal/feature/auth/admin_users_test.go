@@ -0,0 +1,236 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/queryspec"
+)
+
+// fakeUserSearcher はユーザーを ID 順にメモリ上に保持する UserSearcher のフェイク実装です。
+// 一致の規則（メールアドレスの部分一致・大文字小文字を区別しない）はリポジトリと同じです。
+type fakeUserSearcher struct {
+	users []auth.User
+}
+
+func (f *fakeUserSearcher) add(email string) {
+	hash := "hash"
+	f.users = append(f.users, auth.User{ID: int64(len(f.users) + 1), Email: email, Password: &hash})
+}
+
+func (f *fakeUserSearcher) matches(u auth.User, query string) bool {
+	return strings.Contains(strings.ToLower(u.Email), strings.ToLower(query))
+}
+
+func (f *fakeUserSearcher) Search(_ context.Context, query string, limit int, cursorID int64) ([]auth.User, error) {
+	var out []auth.User
+	for _, u := range f.users {
+		if u.ID > cursorID && f.matches(u, query) && len(out) < limit {
+			out = append(out, u)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeUserSearcher) Count(_ context.Context, query string) (int64, error) {
+	var n int64
+	for _, u := range f.users {
+		if f.matches(u, query) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (f *fakeUserSearcher) FindByID(_ context.Context, id int64) (*auth.User, error) {
+	for _, u := range f.users {
+		if u.ID == id {
+			return &u, nil
+		}
+	}
+	return nil, auth.ErrUserNotFound
+}
+
+func (f *fakeUserSearcher) UpdatePlan(_ context.Context, id int64, plan auth.Plan) (*auth.User, error) {
+	for i := range f.users {
+		if f.users[i].ID == id {
+			f.users[i].Plan = plan
+			u := f.users[i]
+			return &u, nil
+		}
+	}
+	return nil, auth.ErrUserNotFound
+}
+
+func emails(users []auth.User) []string {
+	out := make([]string, 0, len(users))
+	for _, u := range users {
+		out = append(out, u.Email)
+	}
+	return out
+}
+
+func TestAdminUserUsecase_List_Search(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeUserSearcher{}
+	for _, e := range []string{"alice@example.com", "bob@example.org", "Carol@Example.com"} {
+		repo.add(e)
+	}
+	uc := auth.NewAdminUserUsecase(repo)
+
+	page, err := uc.List(context.Background(), url.Values{"query": {" EXAMPLE.COM "}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice@example.com", "Carol@Example.com"}, emails(page.Users))
+	assert.Equal(t, int64(2), page.Total)
+	assert.Empty(t, page.NextCursor)
+	for _, u := range page.Users {
+		assert.Nil(t, u.Password, "password hash must not leave the usecase")
+	}
+
+	page, err = uc.List(context.Background(), url.Values{})
+	require.NoError(t, err)
+	assert.Len(t, page.Users, 3)
+}
+
+// TestAdminUserUsecase_List_CursorStableAcrossInserts はページの途中でユーザーが追加されても、
+// カーソルで辿った結果に重複・欠落がなく、追加分は末尾に現れることを検証します。
+func TestAdminUserUsecase_List_CursorStableAcrossInserts(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeUserSearcher{}
+	for _, e := range []string{"u1@example.com", "u2@example.com", "u3@example.com", "u4@example.com", "u5@example.com"} {
+		repo.add(e)
+	}
+	uc := auth.NewAdminUserUsecase(repo)
+
+	var seen []string
+	q := url.Values{"limit": {"2"}}
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 10, "pagination did not terminate")
+		page, err := uc.List(context.Background(), q)
+		require.NoError(t, err)
+		seen = append(seen, emails(page.Users)...)
+		if pages == 0 {
+			repo.add("u6@example.com") // 1 ページ目を読んだ後に追加
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		q.Set("cursor", page.NextCursor)
+	}
+	assert.Equal(t, []string{
+		"u1@example.com", "u2@example.com", "u3@example.com",
+		"u4@example.com", "u5@example.com", "u6@example.com",
+	}, seen)
+}
+
+func TestAdminUserUsecase_List_Rejects(t *testing.T) {
+	t.Parallel()
+
+	uc := auth.NewAdminUserUsecase(&fakeUserSearcher{})
+	for _, q := range []url.Values{
+		{"limit": {"0"}},
+		{"limit": {"201"}},
+		{"cursor": {"not-a-cursor"}},
+		{"query": {strings.Repeat("a", 255)}},
+	} {
+		_, err := uc.List(context.Background(), q)
+		assert.ErrorIs(t, err, queryspec.ErrInvalidQuery, q.Encode())
+	}
+}
+
+func TestAdminUserUsecase_Get(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeUserSearcher{}
+	repo.add("alice@example.com")
+	uc := auth.NewAdminUserUsecase(repo)
+
+	u, err := uc.Get(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", u.Email)
+	assert.Nil(t, u.Password)
+	assert.NotNil(t, repo.users[0].Password, "the repository's user must not be modified")
+
+	_, err = uc.Get(context.Background(), 99)
+	assert.ErrorIs(t, err, auth.ErrUserNotFound)
+}
+
+// fakeImpersonationGenerator は引数を記録してトークンを返す ImpersonationTokenGenerator です。
+type fakeImpersonationGenerator struct {
+	userID int64
+	email  string
+	actor  string
+	err    error
+}
+
+func (g *fakeImpersonationGenerator) GenerateImpersonationToken(userID int64, email, actor string) (string, error) {
+	g.userID, g.email, g.actor = userID, email, actor
+	return "imp-token", g.err
+}
+
+func (g *fakeImpersonationGenerator) ImpersonationExpiresIn() time.Duration { return 15 * time.Minute }
+
+func TestAdminUserUsecase_Impersonate(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeUserSearcher{}
+	repo.add("alice@example.com")
+	gen := &fakeImpersonationGenerator{}
+	uc := auth.NewAdminUserUsecase(repo).WithImpersonation(gen)
+
+	before := time.Now()
+	imp, err := uc.Impersonate(context.Background(), 1, "12")
+	require.NoError(t, err)
+	assert.Equal(t, "imp-token", imp.Token)
+	assert.Equal(t, "12", imp.Actor)
+	assert.Equal(t, 15*time.Minute, imp.ExpiresIn)
+	assert.WithinRange(t, imp.ExpiresAt, before.Add(15*time.Minute), time.Now().Add(15*time.Minute))
+	assert.Equal(t, "alice@example.com", imp.User.Email)
+	assert.Nil(t, imp.User.Password)
+	assert.Equal(t, fakeImpersonationGenerator{userID: 1, email: "alice@example.com", actor: "12"}, *gen)
+
+	_, err = uc.Impersonate(context.Background(), 99, "12")
+	require.ErrorIs(t, err, auth.ErrUserNotFound)
+
+	gen.err = errors.New("sign failed")
+	_, err = uc.Impersonate(context.Background(), 1, "12")
+	require.Error(t, err)
+
+	_, err = auth.NewAdminUserUsecase(repo).Impersonate(context.Background(), 1, "12")
+	require.Error(t, err, "WithImpersonation を設定しない場合は発行しない")
+}
+
+func TestAdminUserUsecase_SetPlan(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeUserSearcher{}
+	repo.add("alice@example.com")
+	uc := auth.NewAdminUserUsecase(repo).WithPlans(repo)
+	ctx := context.Background()
+
+	u, err := uc.SetPlan(ctx, 1, auth.PlanPremium, "12")
+	require.NoError(t, err)
+	assert.Equal(t, auth.PlanPremium, u.Plan)
+	assert.Nil(t, u.Password)
+	assert.Equal(t, auth.PlanPremium, repo.users[0].Plan)
+
+	_, err = uc.SetPlan(ctx, 1, auth.Plan("gold"), "12")
+	require.ErrorIs(t, err, auth.ErrInvalidPlan)
+	assert.Equal(t, auth.PlanPremium, repo.users[0].Plan, "不正なプランでは変更しない")
+
+	_, err = uc.SetPlan(ctx, 99, auth.PlanFree, "12")
+	require.ErrorIs(t, err, auth.ErrUserNotFound)
+
+	_, err = auth.NewAdminUserUsecase(repo).SetPlan(ctx, 1, auth.PlanFree, "12")
+	require.Error(t, err, "WithPlans を設定しない場合は変更しない")
+}
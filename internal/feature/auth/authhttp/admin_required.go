@@ -0,0 +1,48 @@
+package authhttp
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+)
+
+// AdminRequired は管理者ユーザー（auth.RoleAdmin）のリクエストだけを通すミドルウェアを返します。
+// jwt.AuthRequired・LoadUser の後に置きます。APIキーはユーザーを表さないため、このミドルウェアのルートには到達できません。
+//
+//   - ユーザーIDのない（APIキー認証など）リクエスト・削除済みのユーザーは 401
+//   - なりすましトークン（jwt.ActorFromContext）のリクエストは、対象が管理者でも 403（なりすましで権限を得られないようにする）
+//   - 管理者でないユーザーは 403
+func AdminRequired() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := jwt.UserIDFromContext(r.Context()); !ok {
+				httpx.WriteJSON(w, http.StatusUnauthorized, api.ErrorResponse{Error: "missing authentication token"})
+				return
+			}
+			if _, ok := jwt.ActorFromContext(r.Context()); ok {
+				httpx.WriteJSON(w, http.StatusForbidden, api.ErrorResponse{Error: "admin required"})
+				return
+			}
+			u, err := auth.CurrentUser(r.Context())
+			if errors.Is(err, auth.ErrUserNotFound) {
+				httpx.WriteJSON(w, http.StatusUnauthorized, api.ErrorResponse{Error: "user not found"})
+				return
+			}
+			if err != nil {
+				httpx.WriteError(w, err, "failed to load user")
+				return
+			}
+			if !u.IsAdmin() {
+				slog.WarnContext(r.Context(), "admin route denied", "user_id", u.ID)
+				httpx.WriteJSON(w, http.StatusForbidden, api.ErrorResponse{Error: "admin required"})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
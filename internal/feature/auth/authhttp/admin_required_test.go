@@ -0,0 +1,84 @@
+package authhttp_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth/authhttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/apikey"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+)
+
+// TestAdminRequired は管理者ユーザーのリクエストだけが通り、APIキー・一般ユーザー・削除済みのユーザー・
+// なりすましトークンのリクエストが拒否されることを検証します。
+func TestAdminRequired(t *testing.T) {
+	t.Parallel()
+
+	loader := &countingLoader{users: map[int64]auth.User{
+		1: {ID: 1, Email: "admin@example.com", Role: auth.RoleAdmin},
+		2: {ID: 2, Email: "user@example.com", Role: auth.RoleUser},
+	}}
+
+	tests := []struct {
+		name     string
+		ctx      func(context.Context) context.Context
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "admin",
+			ctx:      func(ctx context.Context) context.Context { return jwt.WithUserID(ctx, 1) },
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "non-admin user",
+			ctx:      func(ctx context.Context) context.Context { return jwt.WithUserID(ctx, 2) },
+			wantCode: http.StatusForbidden,
+			wantBody: `{"error":"admin required"}`,
+		},
+		{
+			name:     "deleted user",
+			ctx:      func(ctx context.Context) context.Context { return jwt.WithUserID(ctx, 3) },
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "impersonating an admin",
+			ctx: func(ctx context.Context) context.Context {
+				return jwt.WithActor(jwt.WithUserID(ctx, 1), "2")
+			},
+			wantCode: http.StatusForbidden,
+			wantBody: `{"error":"admin required"}`,
+		},
+		{
+			name: "api key",
+			ctx: func(ctx context.Context) context.Context {
+				return apikey.WithPrincipal(ctx, apikey.Principal{KeyID: "ops", Scopes: []string{apikey.ScopeCandlesAdmin}})
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := authhttp.LoadUser(loader)(authhttp.AdminRequired()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})))
+			req := httptest.NewRequest(http.MethodGet, "/admin/users", nil)
+			req = req.WithContext(tt.ctx(req.Context()))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
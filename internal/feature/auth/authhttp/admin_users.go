@@ -0,0 +1,145 @@
+package authhttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+)
+
+// AdminUserUsecase は管理者向けのユーザー検索・参照のユースケースインターフェースです。
+type AdminUserUsecase interface {
+	List(ctx context.Context, q url.Values) (auth.UserPage, error)
+	Get(ctx context.Context, id int64) (auth.User, error)
+	Impersonate(ctx context.Context, id int64, actor string) (auth.Impersonation, error)
+	SetPlan(ctx context.Context, id int64, plan auth.Plan, actor string) (auth.User, error)
+}
+
+// AdminUserHandler は管理者向けのユーザー検索・参照・料金プランの変更・なりすましエンドポイントを処理します。
+// 認可（管理者ユーザーのみ）はルーター側のミドルウェア（AdminRequired）で行います。
+// 応答は api.AdminUser に変換して返すため、パスワードハッシュが含まれることはありません。
+type AdminUserHandler struct {
+	uc AdminUserUsecase
+}
+
+// NewAdminUserHandler は AdminUserHandler を生成します。
+func NewAdminUserHandler(uc AdminUserUsecase) *AdminUserHandler {
+	return &AdminUserHandler{uc: uc}
+}
+
+// List は ?query= に一致するユーザーを ID 順に返します（limit / cursor でページング）。
+// 検索文字列はメールアドレスを含み得るためログに出しません。
+func (h *AdminUserHandler) List(w http.ResponseWriter, r *http.Request) {
+	page, err := h.uc.List(r.Context(), r.URL.Query())
+	if err != nil {
+		httpx.WriteError(w, err, "failed to list users")
+		return
+	}
+
+	out := api.AdminUserPage{
+		Users:      make([]api.AdminUser, 0, len(page.Users)),
+		Total:      page.Total,
+		NextCursor: page.NextCursor,
+	}
+	for _, u := range page.Users {
+		out.Users = append(out.Users, toAdminUser(u))
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+// Get は {id} のユーザーを返します。
+func (h *AdminUserHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.WriteError(w, auth.ErrUserNotFound, "invalid user id", "id", chi.URLParam(r, "id"))
+		return
+	}
+
+	u, err := h.uc.Get(r.Context(), id)
+	if err != nil {
+		httpx.WriteError(w, err, "failed to get user", "id", id)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	httpx.WriteJSON(w, http.StatusOK, toAdminUser(u))
+}
+
+// SetPlan は {id} のユーザーの料金プランを変更し、変更後のユーザーを返します。変更者は呼び出した管理者のユーザーIDです。
+func (h *AdminUserHandler) SetPlan(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.WriteError(w, auth.ErrUserNotFound, "invalid user id", "id", chi.URLParam(r, "id"))
+		return
+	}
+	adminID, ok := jwt.UserIDFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, errors.New("missing admin user id"), "failed to set user plan", "id", id)
+		return
+	}
+	var req api.UpdateUserPlanRequest
+	if err := httpx.DecodeAndValidate(r, &req); err != nil {
+		httpx.WriteDecodeError(w, err, "invalid request")
+		return
+	}
+
+	u, err := h.uc.SetPlan(r.Context(), id, auth.Plan(req.Plan), strconv.FormatInt(adminID, 10))
+	if err != nil {
+		httpx.WriteError(w, err, "failed to set user plan", "id", id, "plan", req.Plan)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	httpx.WriteJSON(w, http.StatusOK, toAdminUser(u))
+}
+
+// Impersonate は {id} のユーザーとして振る舞う短命のトークンを発行します。発行者（トークンの act.sub）は呼び出した管理者のユーザーIDです。
+// トークンは応答の本文でのみ返し、ログには出しません。
+func (h *AdminUserHandler) Impersonate(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.WriteError(w, auth.ErrUserNotFound, "invalid user id", "id", chi.URLParam(r, "id"))
+		return
+	}
+	adminID, ok := jwt.UserIDFromContext(r.Context())
+	if !ok {
+		httpx.WriteError(w, errors.New("missing admin user id"), "failed to impersonate user", "id", id)
+		return
+	}
+
+	imp, err := h.uc.Impersonate(r.Context(), id, strconv.FormatInt(adminID, 10))
+	if err != nil {
+		httpx.WriteError(w, err, "failed to impersonate user", "id", id)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	httpx.WriteJSON(w, http.StatusCreated, api.ImpersonationToken{
+		Token:            imp.Token,
+		ExpiresAt:        api.NewTimestamp(imp.ExpiresAt),
+		ExpiresInSeconds: int64(imp.ExpiresIn / time.Second),
+		Actor:            imp.Actor,
+		User:             toAdminUser(imp.User),
+	})
+}
+
+func toAdminUser(u auth.User) api.AdminUser {
+	out := api.AdminUser{
+		Id:        u.ID,
+		Email:     u.Email,
+		Plan:      api.Plan(u.Plan),
+		CreatedAt: api.NewTimestamp(u.CreatedAt),
+		UpdatedAt: api.NewTimestamp(u.UpdatedAt),
+	}
+	if u.LastLoginAt != nil {
+		out.LastLoginAt = api.NewTimestamp(*u.LastLoginAt)
+	}
+	return out
+}
@@ -0,0 +1,251 @@
+package authhttp_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth/authhttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/queryspec"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+)
+
+// testPasswordHash はモックのユーザーが持つパスワードハッシュです。応答に現れてはいけません。
+const testPasswordHash = "$2a$10$abcdefghijklmnopqrstuv"
+
+// mockAdminUserUsecase は AdminUserUsecase インターフェースのモック実装です。
+// ハンドラー側の変換だけでパスワードが除かれることを確かめるため、Password を設定したまま返します。
+type mockAdminUserUsecase struct {
+	err     error
+	got     url.Values
+	gotPlan auth.Plan
+	actor   string
+}
+
+func (m *mockAdminUserUsecase) user(id int64) auth.User {
+	hash := testPasswordHash
+	login := time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC)
+	return auth.User{
+		ID:          id,
+		Email:       fmt.Sprintf("user%d@example.com", id),
+		Password:    &hash,
+		CreatedAt:   time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		UpdatedAt:   time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		LastLoginAt: &login,
+		Plan:        auth.PlanFree,
+	}
+}
+
+func (m *mockAdminUserUsecase) List(_ context.Context, q url.Values) (auth.UserPage, error) {
+	m.got = q
+	if m.err != nil {
+		return auth.UserPage{}, m.err
+	}
+	return auth.UserPage{Users: []auth.User{m.user(1), m.user(2)}, Total: 5, NextCursor: "next"}, nil
+}
+
+func (m *mockAdminUserUsecase) Get(_ context.Context, id int64) (auth.User, error) {
+	if m.err != nil {
+		return auth.User{}, m.err
+	}
+	return m.user(id), nil
+}
+
+func (m *mockAdminUserUsecase) Impersonate(_ context.Context, id int64, actor string) (auth.Impersonation, error) {
+	if m.err != nil {
+		return auth.Impersonation{}, m.err
+	}
+	return auth.Impersonation{
+		Token:     "imp-token",
+		ExpiresIn: 15 * time.Minute,
+		ExpiresAt: time.Date(2025, 5, 1, 10, 15, 0, 0, time.UTC),
+		User:      m.user(id),
+		Actor:     actor,
+	}, nil
+}
+
+func (m *mockAdminUserUsecase) SetPlan(_ context.Context, id int64, plan auth.Plan, actor string) (auth.User, error) {
+	m.gotPlan, m.actor = plan, actor
+	if m.err != nil {
+		return auth.User{}, m.err
+	}
+	u := m.user(id)
+	u.Plan = plan
+	return u, nil
+}
+
+func newAdminUserRouter(uc authhttp.AdminUserUsecase) http.Handler {
+	h := authhttp.NewAdminUserHandler(uc)
+	r := chi.NewRouter()
+	r.Get("/admin/users", h.List)
+	r.Get("/admin/users/{id}", h.Get)
+	r.Put("/admin/users/{id}/plan", h.SetPlan)
+	r.Post("/admin/users/{id}/impersonate", h.Impersonate)
+	return r
+}
+
+func TestAdminUserHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		url      string
+		err      error
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "list",
+			url:      "/admin/users?query=example&limit=2",
+			wantCode: http.StatusOK,
+			wantBody: `{"nextCursor":"next","total":5,"users":[{"createdAt":"2025-01-02T03:04:05Z","email":"user1@example.com","id":1,"lastLoginAt":"2025-03-04T05:06:07Z","plan":"free","updatedAt":"2025-01-02T03:04:05Z"},`,
+		},
+		{
+			name:     "list: invalid paging",
+			url:      "/admin/users?limit=0",
+			err:      fmt.Errorf("%w: limit", queryspec.ErrInvalidQuery),
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":"invalid_list_query"}`,
+		},
+		{
+			name:     "get",
+			url:      "/admin/users/7",
+			wantCode: http.StatusOK,
+			wantBody: `"email":"user7@example.com"`,
+		},
+		{
+			name:     "get: not found",
+			url:      "/admin/users/99",
+			err:      auth.ErrUserNotFound,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "get: malformed id",
+			url:      "/admin/users/abc",
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc := &mockAdminUserUsecase{err: tt.err}
+			rec := httptest.NewRecorder()
+			newAdminUserRouter(uc).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+			if tt.wantBody != "" {
+				assert.Contains(t, rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+// TestAdminUserHandler_NeverExposesPassword は応答の JSON にパスワード（ハッシュ）の項目・値が含まれないことを検証します。
+func TestAdminUserHandler_NeverExposesPassword(t *testing.T) {
+	t.Parallel()
+
+	for _, target := range []string{"/admin/users", "/admin/users/1"} {
+		rec := httptest.NewRecorder()
+		newAdminUserRouter(&mockAdminUserUsecase{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code, target)
+		body := strings.ToLower(rec.Body.String())
+		assert.NotContains(t, body, "password", target)
+		assert.NotContains(t, body, strings.ToLower(testPasswordHash), target)
+		assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"), target)
+	}
+}
+
+func TestAdminUserHandler_PassesQuery(t *testing.T) {
+	t.Parallel()
+
+	uc := &mockAdminUserUsecase{}
+	rec := httptest.NewRecorder()
+	newAdminUserRouter(uc).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/users?query=alice&cursor=abc", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "alice", uc.got.Get("query"))
+	assert.Equal(t, "abc", uc.got.Get("cursor"))
+}
+
+// TestAdminUserHandler_Impersonate は呼び出した管理者のユーザーIDを発行者としてトークンを 201 で返し、
+// ユーザーが存在しない場合は 404、認証を経ていない場合は 500 を返すことを検証します。
+func TestAdminUserHandler_Impersonate(t *testing.T) {
+	t.Parallel()
+
+	impersonate := func(uc *mockAdminUserUsecase, target string, withAdmin bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, target, nil)
+		if withAdmin {
+			req = req.WithContext(jwt.WithUserID(req.Context(), 12))
+		}
+		rec := httptest.NewRecorder()
+		newAdminUserRouter(uc).ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := impersonate(&mockAdminUserUsecase{}, "/admin/users/7/impersonate", true)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	assert.JSONEq(t, `{
+		"token": "imp-token",
+		"expiresAt": "2025-05-01T10:15:00Z",
+		"expiresInSeconds": 900,
+		"actor": "12",
+		"user": {"id": 7, "email": "user7@example.com", "createdAt": "2025-01-02T03:04:05Z", "updatedAt": "2025-01-02T03:04:05Z", "lastLoginAt": "2025-03-04T05:06:07Z", "plan": "free"}
+	}`, rec.Body.String())
+
+	rec = impersonate(&mockAdminUserUsecase{err: auth.ErrUserNotFound}, "/admin/users/99/impersonate", true)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = impersonate(&mockAdminUserUsecase{}, "/admin/users/abc/impersonate", true)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = impersonate(&mockAdminUserUsecase{}, "/admin/users/7/impersonate", false)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "imp-token")
+}
+
+// TestAdminUserHandler_SetPlan は呼び出した管理者のユーザーIDを変更者としてプランを変更し、変更後のユーザーを返すことを検証します。
+func TestAdminUserHandler_SetPlan(t *testing.T) {
+	t.Parallel()
+
+	setPlan := func(uc *mockAdminUserUsecase, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(jwt.WithUserID(req.Context(), 12))
+		rec := httptest.NewRecorder()
+		newAdminUserRouter(uc).ServeHTTP(rec, req)
+		return rec
+	}
+
+	uc := &mockAdminUserUsecase{}
+	rec := setPlan(uc, "/admin/users/7/plan", `{"plan":"premium"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	assert.Contains(t, rec.Body.String(), `"plan":"premium"`)
+	assert.NotContains(t, strings.ToLower(rec.Body.String()), "password")
+	assert.Equal(t, auth.PlanPremium, uc.gotPlan)
+	assert.Equal(t, "12", uc.actor)
+
+	uc = &mockAdminUserUsecase{}
+	rec = setPlan(uc, "/admin/users/7/plan", `{"plan":"gold"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Empty(t, uc.gotPlan, "不正なプランはユースケースに渡さない")
+
+	rec = setPlan(&mockAdminUserUsecase{}, "/admin/users/7/plan", `{}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = setPlan(&mockAdminUserUsecase{err: auth.ErrUserNotFound}, "/admin/users/99/plan", `{"plan":"free"}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
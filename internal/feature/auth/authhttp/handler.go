@@ -15,6 +15,7 @@ import (
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/csrf"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpratelimit"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
 )
 
 // setAuthCookie は SameSite=Lax の認証関連 Cookie をレスポンスへ設定します。
@@ -32,13 +33,23 @@ func setAuthCookie(w http.ResponseWriter, name, value string, maxAge int, secure
 	})
 }
 
+// maxAgeSeconds はトークンの有効期間を Cookie の Max-Age（秒）に変換します。
+// 1 秒未満は 0（Max-Age 属性なし＝セッション Cookie）にならないよう 1 秒に切り上げます。
+func maxAgeSeconds(d time.Duration) int {
+	return max(int(d/time.Second), 1)
+}
+
 // Usecase は認証操作のユースケースを定義します。
 // Goの慣例に従い、インターフェースはプロバイダー（usecase）ではなくコンシューマー（handler）が定義します。
 type Usecase interface {
 	// Signup は指定されたメールアドレスとパスワードで新規ユーザーを登録し、作成されたユーザーIDを返します。
 	Signup(ctx context.Context, email, password string) (int64, error)
-	// Login はユーザーを認証し、成功時にJWTトークンを返します。
-	Login(ctx context.Context, email, password string) (string, error)
+	// Login はユーザーを認証し、成功時にJWTトークンとその有効期間を返します。
+	Login(ctx context.Context, email, password string) (auth.LoginResult, error)
+	// LogoutAll はユーザーに発行済みのトークンをすべて失効させます。
+	LogoutAll(ctx context.Context, userID int64) error
+	// DeleteAccount は現在のパスワードを確かめたうえで、ユーザーのトークンをすべて失効させてユーザーを削除します。
+	DeleteAccount(ctx context.Context, userID int64, password string) error
 }
 
 // ログインのメールベースレートリミット設定
@@ -73,20 +84,20 @@ func (h *Handler) Signup(w http.ResponseWriter, r *http.Request) {
 	var req api.SignupRequest
 	if err := httpx.DecodeAndValidate(r, &req); err != nil {
 		slog.Warn("signup validation failed", "error", err, "remote_addr", httpx.ClientIP(r))
-		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "invalid request"})
+		httpx.WriteLocalizedDecodeError(w, r, err, "invalid request")
 		return
 	}
 	userID, err := h.uc.Signup(r.Context(), req.Email, req.Password)
 	if err != nil {
 		// ユーザー列挙攻撃を防止するため、実際のエラーを公開しない
 		slog.Warn("signup failed", "error", err, "email_hash", logging.HashedEmail(req.Email), "remote_addr", httpx.ClientIP(r))
-		httpx.WriteJSON(w, http.StatusConflict, api.ErrorResponse{Error: "signup failed"})
+		httpx.WriteLocalizedCode(w, r, http.StatusConflict, "signup failed")
 		return
 	}
 	for _, hook := range h.postHooks {
 		if err := hook.OnUserCreated(r.Context(), userID); err != nil {
 			slog.Error("post-signup hook failed", "error", err, "userID", userID)
-			httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "signup failed"})
+			httpx.WriteLocalizedCode(w, r, http.StatusInternalServerError, "signup failed")
 			return
 		}
 	}
@@ -98,12 +109,12 @@ func (h *Handler) Signup(w http.ResponseWriter, r *http.Request) {
 // - リクエストJSONをLoginReqにバインド
 // - バリデーションエラー時は400を返却
 // - 認証失敗時は401を返却
-// - 認証成功時はJWTトークン付きで200を返却
+// - 認証成功時はJWTトークン付きで200を返却（?include=user または v2 の Accept ではユーザーのプロフィールを含める）
 func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	var req api.LoginRequest
 	if err := httpx.DecodeAndValidate(r, &req); err != nil {
 		slog.Warn("login validation failed", "error", err, "remote_addr", httpx.ClientIP(r))
-		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "invalid request"})
+		httpx.WriteLocalizedDecodeError(w, r, err, "invalid request")
 		return
 	}
 
@@ -117,15 +128,15 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 			"remote_addr", httpx.ClientIP(r),
 		)
 		w.Header().Set("Retry-After", strconv.Itoa(int(result.RetryAfter.Seconds())))
-		httpx.WriteJSON(w, http.StatusTooManyRequests, api.ErrorResponse{Error: "too many requests"})
+		httpx.WriteLocalizedCode(w, r, http.StatusTooManyRequests, "too many requests")
 		return
 	}
 
-	token, err := h.uc.Login(r.Context(), req.Email, req.Password)
+	login, err := h.uc.Login(r.Context(), req.Email, req.Password)
 	if err != nil {
 		// ユーザー列挙攻撃を防止するため、実際のエラーを公開しない
 		slog.Warn("login failed", "error", err, "email_hash", logging.HashedEmail(req.Email), "remote_addr", httpx.ClientIP(r))
-		httpx.WriteJSON(w, http.StatusUnauthorized, api.ErrorResponse{Error: "invalid email or password"})
+		httpx.WriteLocalizedCode(w, r, http.StatusUnauthorized, "invalid email or password")
 		return
 	}
 
@@ -133,18 +144,38 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	csrfToken, err := csrf.GenerateToken()
 	if err != nil {
 		slog.Error("failed to generate csrf token", "error", err)
-		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal error"})
+		httpx.WriteLocalizedCode(w, r, http.StatusInternalServerError, "internal error")
 		return
 	}
 
 	// 両トークンが揃ってからCookieをセット（原子性保証）
+	// Cookie の寿命は JWT の有効期間（ジェネレーターの設定値）に合わせる
 	// auth_token: httpOnly Cookie（JavaScriptから読み取り不可 → XSS対策）
-	setAuthCookie(w, "auth_token", token, 3600, h.secureCookie, true)
+	setAuthCookie(w, "auth_token", login.Token, maxAgeSeconds(login.ExpiresIn), h.secureCookie, true)
 	// csrf_token: 非httpOnly Cookie（JavaScriptが読み取りX-CSRF-Tokenヘッダーにセット → CSRF対策）
-	setAuthCookie(w, "csrf_token", csrfToken, 3600, h.secureCookie, false)
+	setAuthCookie(w, "csrf_token", csrfToken, maxAgeSeconds(login.ExpiresIn), h.secureCookie, false)
 
 	slog.Info("user login successful", "email_hash", logging.HashedEmail(req.Email), "remote_addr", httpx.ClientIP(r))
-	httpx.WriteJSON(w, http.StatusOK, api.MessageResponse{Message: "ok"})
+	httpx.WriteJSON(w, http.StatusOK, toLoginResponse(login, wantsUser(r)))
+}
+
+// wantsUser はログイン応答にユーザーのプロフィールを含めるかを返します。
+// ?include=user または Accept に httpx.V2MediaType を指定したクライアントのみ対象で、従来のクライアントの応答は変わりません。
+func wantsUser(r *http.Request) bool {
+	return r.URL.Query().Get("include") == "user" || httpx.AcceptsMediaType(r, httpx.V2MediaType)
+}
+
+// toLoginResponse はログイン結果を応答に変換します。パスワードハッシュは含めません。
+func toLoginResponse(login auth.LoginResult, includeUser bool) api.LoginResponse {
+	res := api.LoginResponse{Message: "ok"}
+	if includeUser {
+		res.User = &api.UserProfile{
+			Id:        login.User.ID,
+			Email:     login.User.Email,
+			CreatedAt: api.NewTimestamp(login.User.CreatedAt),
+		}
+	}
+	return res
 }
 
 // Logout はauth_tokenとcsrf_tokenのCookieを削除してログアウトします。
@@ -156,3 +187,44 @@ func (h *Handler) Logout(w http.ResponseWriter, _ *http.Request) {
 
 	httpx.WriteJSON(w, http.StatusOK, api.MessageResponse{Message: "ok"})
 }
+
+// LogoutAll はログインユーザーに発行済みのトークンをすべて失効させ、すべての端末からログアウトします。
+// このリクエストのトークンも失効するため、Logout と同様に Cookie も削除します。
+// 他の端末でログインしていない場合も 200 を返します。
+func (h *Handler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	userID, ok := jwt.UserIDFromContext(r.Context())
+	if !ok {
+		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
+		return
+	}
+	if err := h.uc.LogoutAll(r.Context(), userID); err != nil {
+		httpx.WriteError(w, err, "failed to revoke all sessions", "user_id", userID)
+		return
+	}
+	setAuthCookie(w, "auth_token", "", -1, h.secureCookie, true)
+	setAuthCookie(w, "csrf_token", "", -1, h.secureCookie, false)
+
+	httpx.WriteJSON(w, http.StatusOK, api.MessageResponse{Message: "all sessions revoked"})
+}
+
+// DeleteAccount は現在のパスワードで本人であることを確かめ、ログインユーザーを削除します（成功時は 204）。
+// パスワードが違う場合は 401 を返します。削除したユーザーのトークンは失効させ、Cookie も削除します。
+func (h *Handler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := jwt.UserIDFromContext(r.Context())
+	if !ok {
+		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
+		return
+	}
+	var req api.DeleteAccountRequest
+	if err := httpx.DecodeAndValidate(r, &req); err != nil {
+		httpx.WriteDecodeError(w, err, "invalid request")
+		return
+	}
+	if err := h.uc.DeleteAccount(r.Context(), userID, req.Password); err != nil {
+		httpx.WriteError(w, err, "failed to delete account", "user_id", userID)
+		return
+	}
+	setAuthCookie(w, "auth_token", "", -1, h.secureCookie, true)
+	setAuthCookie(w, "csrf_token", "", -1, h.secureCookie, false)
+	w.WriteHeader(http.StatusNoContent)
+}
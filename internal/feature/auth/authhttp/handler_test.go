@@ -5,17 +5,24 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/go-redis/redismock/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth/authhttp"
+	infraredis "github.com/UCHIDAnobuhiro/stock-backend/internal/infra/redis"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/testsupport"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpratelimit"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
 )
 
 // H は JSON ボディ構築用の簡易マップ型です（旧 gin.H 相当）。
@@ -23,8 +30,10 @@ type H = map[string]any
 
 // mockUsecase はUsecaseインターフェースのモック実装です。
 type mockUsecase struct {
-	SignupFunc func(ctx context.Context, email, password string) (int64, error)
-	LoginFunc  func(ctx context.Context, email, password string) (string, error)
+	SignupFunc    func(ctx context.Context, email, password string) (int64, error)
+	LoginFunc     func(ctx context.Context, email, password string) (auth.LoginResult, error)
+	LogoutAllFunc func(ctx context.Context, userID int64) error
+	DeleteFunc    func(ctx context.Context, userID int64, password string) error
 }
 
 // Signup はSignupメソッドのモック実装です。
@@ -36,11 +45,27 @@ func (m *mockUsecase) Signup(ctx context.Context, email, password string) (int64
 }
 
 // Login はLoginメソッドのモック実装です。
-func (m *mockUsecase) Login(ctx context.Context, email, password string) (string, error) {
+func (m *mockUsecase) Login(ctx context.Context, email, password string) (auth.LoginResult, error) {
 	if m.LoginFunc != nil {
 		return m.LoginFunc(ctx, email, password)
 	}
-	return "", errors.New("login failed") // デフォルト: 失敗
+	return auth.LoginResult{}, errors.New("login failed") // デフォルト: 失敗
+}
+
+// LogoutAll はLogoutAllメソッドのモック実装です。
+func (m *mockUsecase) LogoutAll(ctx context.Context, userID int64) error {
+	if m.LogoutAllFunc != nil {
+		return m.LogoutAllFunc(ctx, userID)
+	}
+	return nil // デフォルト: 成功
+}
+
+// DeleteAccount はDeleteAccountメソッドのモック実装です。
+func (m *mockUsecase) DeleteAccount(ctx context.Context, userID int64, password string) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, userID, password)
+	}
+	return nil // デフォルト: 成功
 }
 
 // makeRequest はHTTPリクエストを作成し、指定ハンドラーを直接実行するヘルパー関数です。
@@ -163,6 +188,25 @@ func TestAuthHandler_Signup(t *testing.T) {
 	}
 }
 
+// TestAuthHandler_Signup_DuplicateUniform は同時登録の競合で検出した重複と通常の重複のレスポンスが同一であることを検証します
+// （レスポンスの違いからメールアドレスの登録有無を推測させない）。
+func TestAuthHandler_Signup_DuplicateUniform(t *testing.T) {
+	t.Parallel()
+
+	signup := func(err error) *httptest.ResponseRecorder {
+		mockUC := &mockUsecase{SignupFunc: func(ctx context.Context, email, password string) (int64, error) { return 0, err }}
+		h := authhttp.NewHandler(mockUC, nil, false)
+		return makeRequest(t, h.Signup, http.MethodPost, "/signup", H{"email": "dup@example.com", "password": "password12345"})
+	}
+
+	plain := signup(auth.ErrEmailAlreadyExists)
+	race := signup(fmt.Errorf("create user: %w", auth.ErrEmailAlreadyExists))
+	assert.Equal(t, http.StatusConflict, plain.Code)
+	assert.Equal(t, plain.Code, race.Code)
+	assert.Equal(t, plain.Header(), race.Header())
+	assert.Equal(t, plain.Body.String(), race.Body.String())
+}
+
 // TestAuthHandler_Login_RateLimited はメールベースのレートリミット超過時に429が返されることを検証します。
 func TestAuthHandler_Login_RateLimited(t *testing.T) {
 	t.Parallel()
@@ -177,12 +221,12 @@ func TestAuthHandler_Login_RateLimited(t *testing.T) {
 	key := "rl:login:email:test@example.com"
 	httpratelimit.ExpectAllow(match, key, false, 5)
 
-	limiter := httpratelimit.NewLimiter(rdb)
+	limiter := httpratelimit.NewLimiter(rdb, infraredis.KeyBuilder{})
 	loginCalled := false
 	mockUC := &mockUsecase{
-		LoginFunc: func(ctx context.Context, email, password string) (string, error) {
+		LoginFunc: func(ctx context.Context, email, password string) (auth.LoginResult, error) {
 			loginCalled = true
-			return "", errors.New("should not be called")
+			return auth.LoginResult{}, errors.New("should not be called")
 		},
 	}
 	h := authhttp.NewHandler(mockUC, limiter, false)
@@ -211,25 +255,29 @@ func TestAuthHandler_Login(t *testing.T) {
 	tests := []struct {
 		name           string
 		requestBody    H
-		mockLoginFunc  func(ctx context.Context, email, password string) (string, error)
+		mockLoginFunc  func(ctx context.Context, email, password string) (auth.LoginResult, error)
 		expectedStatus int
 		expectedBody   H
 		checkCookies   bool
 		secureCookie   bool
 	}{
 		{
-			name:           "success: user login",
-			requestBody:    H{"email": "test@example.com", "password": "password12345"},
-			mockLoginFunc:  func(ctx context.Context, email, password string) (string, error) { return "dummy-jwt-token", nil },
+			name:        "success: user login",
+			requestBody: H{"email": "test@example.com", "password": "password12345"},
+			mockLoginFunc: func(ctx context.Context, email, password string) (auth.LoginResult, error) {
+				return auth.LoginResult{Token: "dummy-jwt-token", ExpiresIn: time.Hour}, nil
+			},
 			expectedStatus: http.StatusOK,
 			expectedBody:   H{"message": "ok"},
 			checkCookies:   true,
 			secureCookie:   false,
 		},
 		{
-			name:           "success: user login (secureCookie=true)",
-			requestBody:    H{"email": "test@example.com", "password": "password12345"},
-			mockLoginFunc:  func(ctx context.Context, email, password string) (string, error) { return "dummy-jwt-token", nil },
+			name:        "success: user login (secureCookie=true)",
+			requestBody: H{"email": "test@example.com", "password": "password12345"},
+			mockLoginFunc: func(ctx context.Context, email, password string) (auth.LoginResult, error) {
+				return auth.LoginResult{Token: "dummy-jwt-token", ExpiresIn: time.Hour}, nil
+			},
 			expectedStatus: http.StatusOK,
 			expectedBody:   H{"message": "ok"},
 			checkCookies:   true,
@@ -252,8 +300,8 @@ func TestAuthHandler_Login(t *testing.T) {
 		{
 			name:        "failure: invalid credentials (usecase error)",
 			requestBody: H{"email": "wrong@example.com", "password": "wrong-password"},
-			mockLoginFunc: func(ctx context.Context, email, password string) (string, error) {
-				return "", errors.New("invalid email or password")
+			mockLoginFunc: func(ctx context.Context, email, password string) (auth.LoginResult, error) {
+				return auth.LoginResult{}, errors.New("invalid email or password")
 			},
 			expectedStatus: http.StatusUnauthorized,
 			expectedBody:   H{"error": "invalid email or password"},
@@ -261,8 +309,8 @@ func TestAuthHandler_Login(t *testing.T) {
 		{
 			name:        "failure: JWT secret not set (usecase error)",
 			requestBody: H{"email": "test@example.com", "password": "password12345"},
-			mockLoginFunc: func(ctx context.Context, email, password string) (string, error) {
-				return "", errors.New("server misconfigured: JWT_SECRET missing")
+			mockLoginFunc: func(ctx context.Context, email, password string) (auth.LoginResult, error) {
+				return auth.LoginResult{}, errors.New("server misconfigured: JWT_SECRET missing")
 			},
 			expectedStatus: http.StatusUnauthorized,
 			expectedBody:   H{"error": "invalid email or password"}, // Usecaseのエラーメッセージは隠蔽される
@@ -285,6 +333,89 @@ func TestAuthHandler_Login(t *testing.T) {
 	}
 }
 
+// TestAuthHandler_Login_BodyTooLarge は上限（64KB）を超えるボディをデコード前に 413 で拒否することを検証します。
+func TestAuthHandler_Login_BodyTooLarge(t *testing.T) {
+	t.Parallel()
+
+	mockUC := &mockUsecase{LoginFunc: func(ctx context.Context, email, password string) (auth.LoginResult, error) {
+		t.Error("上限超過時はUsecaseが呼ばれないこと")
+		return auth.LoginResult{}, nil
+	}}
+	h := authhttp.NewHandler(mockUC, nil, false)
+
+	w := makeRequest(t, h.Login, http.MethodPost, "/login", H{"email": "test@example.com", "password": strings.Repeat("x", 70<<10)})
+	assertJSONResponse(t, w, http.StatusRequestEntityTooLarge, H{"error": "request_too_large"})
+	assert.Equal(t, "close", w.Header().Get("Connection"))
+}
+
+// TestAuthHandler_Login_CookieMaxAgeFollowsTokenExpiry は Cookie の Max-Age が
+// 発行したトークンの有効期間（ExpiresIn）に一致することを検証します。
+func TestAuthHandler_Login_CookieMaxAgeFollowsTokenExpiry(t *testing.T) {
+	t.Parallel()
+
+	mockUC := &mockUsecase{
+		LoginFunc: func(ctx context.Context, email, password string) (auth.LoginResult, error) {
+			return auth.LoginResult{Token: "dummy-jwt-token", ExpiresIn: 30 * time.Minute}, nil
+		},
+	}
+	h := authhttp.NewHandler(mockUC, nil, false)
+
+	w := makeRequest(t, h.Login, http.MethodPost, "/login", H{"email": "test@example.com", "password": "password12345"})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 2)
+	for _, c := range cookies {
+		assert.Equal(t, 1800, c.MaxAge, "%s cookie should expire with the token", c.Name)
+	}
+}
+
+// TestAuthHandler_Login_IncludeUser は ?include=user または v2 の Accept の場合のみ
+// 応答にユーザーのプロフィールを含め（パスワードハッシュは含めない）、従来の応答は変わらないことを検証します。
+func TestAuthHandler_Login_IncludeUser(t *testing.T) {
+	t.Parallel()
+
+	hash := "$2a$10$secret-hash"
+	profile := H{"id": float64(42), "email": "test@example.com", "createdAt": "2025-01-02T03:04:05Z"}
+	tests := []struct {
+		name     string
+		path     string
+		accept   string
+		wantBody H
+	}{
+		{name: "v1", path: "/login", wantBody: H{"message": "ok"}},
+		{name: "v1 accept", path: "/login", accept: "application/json", wantBody: H{"message": "ok"}},
+		{name: "include=user", path: "/login?include=user", wantBody: H{"message": "ok", "user": profile}},
+		{name: "v2 accept", path: "/login", accept: "application/json, application/vnd.stock.v2+json; q=0.9", wantBody: H{"message": "ok", "user": profile}},
+		{name: "unknown include", path: "/login?include=password", wantBody: H{"message": "ok"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockUC := &mockUsecase{
+				LoginFunc: func(ctx context.Context, email, password string) (auth.LoginResult, error) {
+					return auth.LoginResult{Token: "dummy-jwt-token", ExpiresIn: time.Hour, User: auth.User{
+						ID: 42, Email: email, Password: &hash, CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+					}}, nil
+				},
+			}
+			h := authhttp.NewHandler(mockUC, nil, false)
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(`{"email":"test@example.com","password":"password12345"}`))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			h.Login(w, req)
+
+			assertJSONResponse(t, w, http.StatusOK, tt.wantBody)
+			assert.NotContains(t, w.Body.String(), hash)
+			assertLoginCookies(t, w, false)
+		})
+	}
+}
+
 // TestAuthHandler_Logout はログアウトハンドラーがCookieを削除することを検証します。
 func TestAuthHandler_Logout(t *testing.T) {
 	t.Parallel()
@@ -335,3 +466,223 @@ func TestAuthHandler_Logout(t *testing.T) {
 		})
 	}
 }
+
+// TestAuthHandler_LogoutAll は全端末からのログアウトがユーザーIDで失効を依頼し、Cookieを削除することを検証します。
+func TestAuthHandler_LogoutAll(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		var revoked []int64
+		h := authhttp.NewHandler(&mockUsecase{LogoutAllFunc: func(_ context.Context, userID int64) error {
+			revoked = append(revoked, userID)
+			return nil
+		}}, nil, false)
+		harness := testsupport.NewHarness(t, testsupport.WithContext(func(ctx context.Context) context.Context { return jwt.WithUserID(ctx, 7) }))
+		harness.Post("/auth/logout_all", h.LogoutAll)
+
+		w := harness.Do(http.MethodPost, "/auth/logout_all", nil, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"message":"all sessions revoked"}`, w.Body.String())
+		assert.Equal(t, []int64{7}, revoked)
+		cookies := strings.Join(w.Header().Values("Set-Cookie"), "\n")
+		assert.Contains(t, cookies, "auth_token=; Path=/; Max-Age=0")
+		assert.Contains(t, cookies, "csrf_token=; Path=/; Max-Age=0")
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		t.Parallel()
+		h := authhttp.NewHandler(&mockUsecase{LogoutAllFunc: func(context.Context, int64) error {
+			return errors.New("revocation store error")
+		}}, nil, false)
+		harness := testsupport.NewHarness(t, testsupport.WithContext(func(ctx context.Context) context.Context { return jwt.WithUserID(ctx, 7) }))
+		harness.Post("/auth/logout_all", h.LogoutAll)
+
+		w := harness.Do(http.MethodPost, "/auth/logout_all", nil, nil)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Empty(t, w.Header().Values("Set-Cookie"), "失効できなかった場合はCookieを残す")
+	})
+
+	t.Run("missing user", func(t *testing.T) {
+		t.Parallel()
+		h := authhttp.NewHandler(&mockUsecase{}, nil, false)
+		w := makeRequest(t, h.LogoutAll, http.MethodPost, "/auth/logout_all", H{})
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+// TestAuthHandler_LogoutAll_RevokesIssuedTokens は実際の失効ストア（miniredis）で、
+// 呼び出し前に発行したトークンがすべて拒否され、ログインしていない端末がないユーザーでも成功することを検証します。
+func TestAuthHandler_LogoutAll_RevokesIssuedTokens(t *testing.T) {
+	t.Parallel()
+
+	const secret = "test-secret-key-for-logout-all"
+	_, rdb := testsupport.NewMiniRedis(t)
+	revocations := jwt.NewRevocations(rdb, "test:auth:revoked", time.Hour)
+	uc := auth.NewUsecase(nil, nil, "").WithSessionRevoker(revocations)
+	h := authhttp.NewHandler(uc, nil, false)
+
+	harness := testsupport.NewHarness(t, jwt.AuthRequired(secret), jwt.RejectRevoked(revocations))
+	harness.Post("/auth/logout_all", h.LogoutAll)
+	harness.Get("/me", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	bearer := func(token string) http.Header { return http.Header{"Authorization": {"Bearer " + token}} }
+
+	issuedAt := time.Now().Add(-time.Minute)
+	laptop := testsupport.SessionToken(t, secret, 1, testsupport.IssuedAt(issuedAt))
+	phone := testsupport.SessionToken(t, secret, 1, testsupport.IssuedAt(issuedAt.Add(30*time.Second)))
+	other := testsupport.SessionToken(t, secret, 2, testsupport.IssuedAt(issuedAt))
+
+	require.Equal(t, http.StatusOK, harness.Do(http.MethodPost, "/auth/logout_all", nil, bearer(laptop)).Code)
+	assert.Equal(t, http.StatusUnauthorized, harness.DoGet("/me", bearer(laptop)).Code)
+	assert.Equal(t, http.StatusUnauthorized, harness.DoGet("/me", bearer(phone)).Code, "他の端末のトークンも失効する")
+	assert.Equal(t, http.StatusNoContent, harness.DoGet("/me", bearer(other)).Code, "他のユーザーは影響を受けない")
+
+	// 失効済みのトークンでは再度呼べない
+	assert.Equal(t, http.StatusUnauthorized, harness.Do(http.MethodPost, "/auth/logout_all", nil, bearer(phone)).Code)
+
+	// 一括失効の記録がないユーザー（他の端末がない）でも成功する
+	assert.Equal(t, http.StatusOK, harness.Do(http.MethodPost, "/auth/logout_all", nil, bearer(other)).Code)
+}
+
+// TestAuthHandler_DeleteAccount はアカウントの削除がパスワードをユースケースへ渡し、結果に応じた応答を返すことを検証します。
+func TestAuthHandler_DeleteAccount(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		body        string
+		ucErr       error
+		wantStatus  int
+		wantError   string
+		wantCleared bool
+	}{
+		{name: "success", body: `{"password":"password12345"}`, wantStatus: http.StatusNoContent, wantCleared: true},
+		{name: "wrong password", body: `{"password":"wrong"}`, ucErr: auth.ErrIncorrectPassword, wantStatus: http.StatusUnauthorized, wantError: "incorrect_password"},
+		{name: "missing password", body: `{}`, wantStatus: http.StatusBadRequest, wantError: "invalid request"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var gotPassword string
+			h := authhttp.NewHandler(&mockUsecase{DeleteFunc: func(_ context.Context, userID int64, password string) error {
+				assert.Equal(t, int64(7), userID)
+				gotPassword = password
+				return tt.ucErr
+			}}, nil, false)
+			harness := testsupport.NewHarness(t, testsupport.WithContext(func(ctx context.Context) context.Context { return jwt.WithUserID(ctx, 7) }))
+			harness.Delete("/auth/account", h.DeleteAccount)
+
+			w := harness.Do(http.MethodDelete, "/auth/account", strings.NewReader(tt.body), http.Header{"Content-Type": {"application/json"}})
+			require.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantError != "" {
+				var res H
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+				assert.Equal(t, tt.wantError, res["error"])
+			} else {
+				assert.Equal(t, "password12345", gotPassword)
+				assert.Empty(t, w.Body.String())
+			}
+			cookies := strings.Join(w.Header().Values("Set-Cookie"), "\n")
+			if tt.wantCleared {
+				assert.Contains(t, cookies, "auth_token=; Path=/; Max-Age=0")
+				assert.Contains(t, cookies, "csrf_token=; Path=/; Max-Age=0")
+			} else {
+				assert.Empty(t, cookies)
+			}
+		})
+	}
+}
+
+// memUserRepository は auth.UserRepository のインメモリ実装です（ユースケースを通した結合テスト用）。
+type memUserRepository struct {
+	mu    sync.Mutex
+	users map[int64]auth.User
+}
+
+func (r *memUserRepository) Create(_ context.Context, u *auth.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u.ID = int64(len(r.users) + 1)
+	r.users[u.ID] = *u
+	return nil
+}
+
+func (r *memUserRepository) FindByEmail(_ context.Context, email string) (*auth.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if u.Email == email {
+			return &u, nil
+		}
+	}
+	return nil, auth.ErrUserNotFound
+}
+
+func (r *memUserRepository) FindByID(_ context.Context, id int64) (*auth.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[id]
+	if !ok {
+		return nil, auth.ErrUserNotFound
+	}
+	return &u, nil
+}
+
+func (r *memUserRepository) Load(ctx context.Context, id int64) (*auth.User, error) {
+	return r.FindByID(ctx, id)
+}
+
+func (r *memUserRepository) RecordLogin(context.Context, int64) error { return nil }
+
+func (r *memUserRepository) Delete(_ context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[id]; !ok {
+		return auth.ErrUserNotFound
+	}
+	delete(r.users, id)
+	return nil
+}
+
+// TestAuthHandler_DeleteAccount_RejectsDeletedUsersToken は実際の失効ストア（miniredis）で、
+// 削除したユーザーの有効期限内のトークンが保護ルートを通らなくなることを検証します。
+func TestAuthHandler_DeleteAccount_RejectsDeletedUsersToken(t *testing.T) {
+	t.Parallel()
+
+	const secret = "test-secret-key-for-account-delete"
+	const pepper = "test-pepper-secret-32chars-long!"
+	ctx := context.Background()
+	_, rdb := testsupport.NewMiniRedis(t)
+	revocations := jwt.NewRevocations(rdb, "test:auth:revoked", time.Hour)
+	users := &memUserRepository{users: map[int64]auth.User{}}
+	uc := auth.NewUsecase(users, jwt.NewGenerator(secret, time.Hour), pepper).WithSessionRevoker(revocations)
+	userID, err := uc.Signup(ctx, "delete@example.com", "password12345")
+	require.NoError(t, err)
+	h := authhttp.NewHandler(uc, nil, false)
+
+	harness := testsupport.NewHarness(t, jwt.AuthRequired(secret), jwt.RejectRevoked(revocations), authhttp.LoadUser(users))
+	harness.Delete("/auth/account", h.DeleteAccount)
+	harness.Get("/me", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := auth.CurrentUser(r.Context()); err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	header := func(token string) http.Header {
+		return http.Header{"Authorization": {"Bearer " + token}, "Content-Type": {"application/json"}}
+	}
+	token := testsupport.SessionToken(t, secret, userID, testsupport.IssuedAt(time.Now().Add(-time.Minute)))
+	require.Equal(t, http.StatusNoContent, harness.DoGet("/me", header(token)).Code)
+
+	w := harness.Do(http.MethodDelete, "/auth/account", strings.NewReader(`{"password":"wrong-password"}`), header(token))
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, http.StatusNoContent, harness.DoGet("/me", header(token)).Code, "パスワードが違う場合は削除しない")
+
+	w = harness.Do(http.MethodDelete, "/auth/account", strings.NewReader(`{"password":"password12345"}`), header(token))
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	_, err = users.FindByID(ctx, userID)
+	require.ErrorIs(t, err, auth.ErrUserNotFound)
+
+	assert.Equal(t, http.StatusUnauthorized, harness.DoGet("/me", header(token)).Code, "削除したユーザーのトークンは失効している")
+}
@@ -0,0 +1,163 @@
+package authhttp_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth/authhttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/i18n"
+)
+
+// TestAuthHandler_LocalizedErrors は認証系の主なエラーが Accept-Language のロケールで message を返し、
+// error（コード）と fields の code はロケールによらず同じであることを検証します。
+func TestAuthHandler_LocalizedErrors(t *testing.T) {
+	t.Parallel()
+
+	cat, err := i18n.NewCatalog()
+	require.NoError(t, err)
+
+	authH := authhttp.NewHandler(&mockUsecase{
+		SignupFunc: func(context.Context, string, string) (int64, error) { return 0, auth.ErrEmailAlreadyExists },
+		LoginFunc: func(context.Context, string, string) (auth.LoginResult, error) {
+			return auth.LoginResult{}, auth.ErrInvalidCredentials
+		},
+	}, nil, false)
+	resetH := authhttp.NewPasswordResetHandler(&mockPasswordResetUsecase{
+		ResetFunc: func(context.Context, string, string) error { return auth.ErrInvalidResetToken },
+	}, nil, false)
+
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		body       H
+		wantStatus int
+		wantCode   string
+		wantFields []string // fields の code（ロケールによらない）
+		wantEN     string
+		wantJA     string
+	}{
+		{
+			name:       "signup: 入力検証",
+			handler:    authH.Signup,
+			body:       H{"email": "not-an-email", "password": "short"},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid request",
+			wantFields: []string{"email", "min"},
+			wantEN:     "The request is invalid.",
+			wantJA:     "リクエストの内容が正しくありません。",
+		},
+		{
+			name:       "signup: 登録失敗",
+			handler:    authH.Signup,
+			body:       H{"email": "taken@example.com", "password": "password12345"},
+			wantStatus: http.StatusConflict,
+			wantCode:   "signup failed",
+			wantEN:     "Could not create the account.",
+			wantJA:     "アカウントを作成できませんでした。",
+		},
+		{
+			name:       "login: 必須項目の欠落",
+			handler:    authH.Login,
+			body:       H{"email": "test@example.com"},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid request",
+			wantFields: []string{"required"},
+			wantEN:     "The request is invalid.",
+			wantJA:     "リクエストの内容が正しくありません。",
+		},
+		{
+			name:       "login: 認証失敗",
+			handler:    authH.Login,
+			body:       H{"email": "test@example.com", "password": "wrong-password"},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   "invalid email or password",
+			wantEN:     "The email address or password is incorrect.",
+			wantJA:     "メールアドレスまたはパスワードが正しくありません。",
+		},
+		{
+			name:       "reset: 無効なトークン",
+			handler:    resetH.Reset,
+			body:       H{"token": "used-token", "password": "brand-new-password"},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid or expired reset token",
+			wantEN:     "The password reset link is invalid or has expired.",
+			wantJA:     "パスワード再設定のリンクが無効か、有効期限が切れています。",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := httpx.Localize(cat)(tt.handler)
+			en := serveWithLanguage(t, h, "en-US", tt.body)
+			ja := serveWithLanguage(t, h, "ja-JP,ja;q=0.9,en;q=0.5", tt.body)
+
+			for locale, got := range map[string]localizedResult{"en": en, "ja": ja} {
+				assert.Equal(t, tt.wantStatus, got.status, locale)
+				assert.Equal(t, locale, got.contentLanguage)
+				assert.Equal(t, tt.wantCode, got.body.Error, "error はロケールによらない (%s)", locale)
+				codes := make([]string, 0, len(got.body.Fields))
+				for _, f := range got.body.Fields {
+					codes = append(codes, f.Code)
+					assert.NotEmpty(t, f.Message, locale)
+				}
+				if len(tt.wantFields) == 0 {
+					assert.Empty(t, codes, locale)
+				} else {
+					assert.Equal(t, tt.wantFields, codes, "fields の code はロケールによらない (%s)", locale)
+				}
+			}
+			assert.Equal(t, tt.wantEN, en.body.Message)
+			assert.Equal(t, tt.wantJA, ja.body.Message)
+			if len(tt.wantFields) > 0 {
+				assert.NotEqual(t, en.body.Fields[0].Message, ja.body.Fields[0].Message)
+			}
+		})
+	}
+}
+
+type localizedResult struct {
+	status          int
+	contentLanguage string
+	body            api.ErrorResponse
+}
+
+// serveWithLanguage は Accept-Language を付けて h に JSON の POST を送り、応答を返します。
+func serveWithLanguage(t *testing.T, h http.Handler, acceptLanguage string, body H) localizedResult {
+	t.Helper()
+	b, err := json.Marshal(body)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", acceptLanguage)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	var res api.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	return localizedResult{status: w.Code, contentLanguage: w.Header().Get("Content-Language"), body: res}
+}
+
+// TestAuthHandler_LocalizedErrors_WithoutLocalize は Localize を通らない場合に従来の応答（error のみ）のままであることを検証します。
+func TestAuthHandler_LocalizedErrors_WithoutLocalize(t *testing.T) {
+	t.Parallel()
+
+	h := authhttp.NewHandler(&mockUsecase{
+		LoginFunc: func(context.Context, string, string) (auth.LoginResult, error) {
+			return auth.LoginResult{}, errors.New("boom")
+		},
+	}, nil, false)
+	w := makeRequest(t, h.Login, http.MethodPost, "/login", H{"email": "test@example.com", "password": "x"})
+	assertJSONResponse(t, w, http.StatusUnauthorized, H{"error": "invalid email or password"})
+}
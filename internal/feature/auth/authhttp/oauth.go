@@ -2,13 +2,11 @@ package authhttp
 
 import (
 	"context"
-	"errors"
 	"log/slog"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
 
-	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/csrf"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
@@ -18,7 +16,7 @@ import (
 // Goの慣例に従い、インターフェースはプロバイダー（usecase）ではなくコンシューマー（handler）が定義します。
 type OAuthUsecase interface {
 	BeginAuth(ctx context.Context, provider string) (authURL string, err error)
-	HandleCallback(ctx context.Context, provider, code, state string) (token string, err error)
+	HandleCallback(ctx context.Context, provider, code, state string) (auth.LoginResult, error)
 }
 
 // OAuthHandler はOAuth2フローのHTTPリクエストを処理します。
@@ -44,7 +42,7 @@ func (h *OAuthHandler) BeginAuth(w http.ResponseWriter, r *http.Request) {
 	authURL, err := h.oauth.BeginAuth(r.Context(), provider)
 	if err != nil {
 		slog.Warn("oauth begin: failed", "provider", provider, "error", err)
-		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "unsupported provider"})
+		httpx.WriteLocalizedCode(w, r, http.StatusBadRequest, "unsupported provider")
 		return
 	}
 	http.Redirect(w, r, authURL, http.StatusFound)
@@ -59,22 +57,18 @@ func (h *OAuthHandler) Callback(w http.ResponseWriter, r *http.Request) {
 	state := r.URL.Query().Get("state")
 
 	if code == "" || state == "" {
-		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "missing code or state"})
+		httpx.WriteLocalizedCode(w, r, http.StatusBadRequest, "missing code or state")
 		return
 	}
 
-	token, err := h.oauth.HandleCallback(r.Context(), provider, code, state)
+	login, err := h.oauth.HandleCallback(r.Context(), provider, code, state)
 	if err != nil {
-		if errors.Is(err, auth.ErrStateNotFound) {
-			httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "invalid or expired state"})
-		} else if errors.Is(err, auth.ErrOAuthEmailUnavailable) {
-			httpx.WriteJSON(w, http.StatusBadGateway, api.ErrorResponse{Error: "cannot obtain verified email from provider"})
-		} else if errors.Is(err, auth.ErrUnknownProvider) {
-			httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "unsupported provider"})
-		} else {
+		status, code := httpx.ErrorStatus(err)
+		if status == http.StatusInternalServerError {
 			slog.Error("oauth callback failed", "provider", provider, "error", err)
-			httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "oauth failed"})
+			code = "oauth failed"
 		}
+		httpx.WriteLocalizedCode(w, r, status, code)
 		return
 	}
 
@@ -82,15 +76,15 @@ func (h *OAuthHandler) Callback(w http.ResponseWriter, r *http.Request) {
 	csrfToken, err := csrf.GenerateToken()
 	if err != nil {
 		slog.Error("failed to generate csrf token", "error", err)
-		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal error"})
+		httpx.WriteLocalizedCode(w, r, http.StatusInternalServerError, "internal error")
 		return
 	}
 
 	slog.Info("oauth login successful", "provider", provider)
 
 	// handler.go の Login と同一パターンで Cookie をセット
-	setAuthCookie(w, "auth_token", token, 3600, h.secureCookie, true)
-	setAuthCookie(w, "csrf_token", csrfToken, 3600, h.secureCookie, false)
+	setAuthCookie(w, "auth_token", login.Token, maxAgeSeconds(login.ExpiresIn), h.secureCookie, true)
+	setAuthCookie(w, "csrf_token", csrfToken, maxAgeSeconds(login.ExpiresIn), h.secureCookie, false)
 
 	http.Redirect(w, r, h.frontendURL, http.StatusFound)
 }
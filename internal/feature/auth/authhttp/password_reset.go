@@ -0,0 +1,93 @@
+package authhttp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/logging"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpratelimit"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+)
+
+// PasswordResetUsecase はパスワード再設定のユースケースインターフェースです。
+type PasswordResetUsecase interface {
+	// Forgot はリセットトークンを発行・送信します。未登録のメールアドレスでもエラーを返しません。
+	Forgot(ctx context.Context, email string) error
+	// Reset はトークンを検証してパスワードを更新し、発行済みトークンを一括失効させます。
+	Reset(ctx context.Context, token, newPassword string) error
+}
+
+// パスワード再設定申請のメールベースレートリミット設定
+const (
+	forgotEmailLimit  = 3         // 1時間のメールアドレスあたりの最大申請回数
+	forgotEmailWindow = time.Hour // メールベースレートリミットのウィンドウ
+)
+
+// PasswordResetHandler はパスワード再設定のHTTPリクエストを処理します。
+type PasswordResetHandler struct {
+	uc           PasswordResetUsecase
+	limiter      *httpratelimit.Limiter
+	secureCookie bool
+}
+
+// NewPasswordResetHandler は PasswordResetHandler の新しいインスタンスを生成します。
+func NewPasswordResetHandler(uc PasswordResetUsecase, limiter *httpratelimit.Limiter, secureCookie bool) *PasswordResetHandler {
+	return &PasswordResetHandler{uc: uc, limiter: limiter, secureCookie: secureCookie}
+}
+
+// Forgot はパスワード再設定の申請を受け付けます。
+// ユーザー列挙を防ぐため、メールアドレスの登録有無・処理の成否に関わらず 200 を返します。
+func (h *PasswordResetHandler) Forgot(w http.ResponseWriter, r *http.Request) {
+	var req api.ForgotPasswordRequest
+	if err := httpx.DecodeAndValidate(r, &req); err != nil {
+		slog.Warn("forgot password validation failed", "error", err, "remote_addr", httpx.ClientIP(r))
+		httpx.WriteLocalizedDecodeError(w, r, err, "invalid request")
+		return
+	}
+
+	// メールベースのレートリミット（登録有無に関わらず適用するため、列挙の手掛かりにならない）
+	key := fmt.Sprintf("rl:forgot:email:%s", strings.ToLower(req.Email))
+	result := h.limiter.Allow(r.Context(), key, forgotEmailLimit, forgotEmailWindow)
+	if !result.Allowed {
+		slog.Warn("forgot password rate limit exceeded",
+			"type", "email",
+			"email_hash", logging.HashedEmail(req.Email),
+			"remote_addr", httpx.ClientIP(r),
+		)
+		w.Header().Set("Retry-After", strconv.Itoa(int(result.RetryAfter.Seconds())))
+		httpx.WriteLocalizedCode(w, r, http.StatusTooManyRequests, "too many requests")
+		return
+	}
+
+	if err := h.uc.Forgot(r.Context(), req.Email); err != nil {
+		// 失敗は登録済みユーザーでのみ起こり得るため、応答に出すと列挙の手掛かりになる
+		slog.Error("forgot password failed", "error", err, "email_hash", logging.HashedEmail(req.Email), "remote_addr", httpx.ClientIP(r))
+	}
+	httpx.WriteJSON(w, http.StatusOK, api.MessageResponse{Message: "ok"})
+}
+
+// Reset はリセットトークンで新しいパスワードを設定します。
+// 成功時はリクエスト元の認証 Cookie も削除します（発行済みトークンはすべて失効済みのため）。
+func (h *PasswordResetHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	var req api.ResetPasswordRequest
+	if err := httpx.DecodeAndValidate(r, &req); err != nil {
+		slog.Warn("reset password validation failed", "error", err, "remote_addr", httpx.ClientIP(r))
+		httpx.WriteLocalizedDecodeError(w, r, err, "invalid request")
+		return
+	}
+
+	if err := h.uc.Reset(r.Context(), req.Token, req.Password); err != nil {
+		httpx.WriteLocalizedError(w, r, err, "reset password failed", "remote_addr", httpx.ClientIP(r))
+		return
+	}
+
+	setAuthCookie(w, "auth_token", "", -1, h.secureCookie, true)
+	setAuthCookie(w, "csrf_token", "", -1, h.secureCookie, false)
+	httpx.WriteJSON(w, http.StatusOK, api.MessageResponse{Message: "ok"})
+}
@@ -0,0 +1,182 @@
+package authhttp_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth/authhttp"
+	infraredis "github.com/UCHIDAnobuhiro/stock-backend/internal/infra/redis"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpratelimit"
+)
+
+// mockPasswordResetUsecase は PasswordResetUsecase インターフェースのモック実装です。
+type mockPasswordResetUsecase struct {
+	ForgotFunc func(ctx context.Context, email string) error
+	ResetFunc  func(ctx context.Context, token, newPassword string) error
+}
+
+func (m *mockPasswordResetUsecase) Forgot(ctx context.Context, email string) error {
+	if m.ForgotFunc != nil {
+		return m.ForgotFunc(ctx, email)
+	}
+	return nil
+}
+
+func (m *mockPasswordResetUsecase) Reset(ctx context.Context, token, newPassword string) error {
+	if m.ResetFunc != nil {
+		return m.ResetFunc(ctx, token, newPassword)
+	}
+	return nil
+}
+
+// TestPasswordResetHandler_Forgot は申請の成否に関わらず同じ応答を返すことを検証します。
+func TestPasswordResetHandler_Forgot(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		body           H
+		ucErr          error
+		expectedStatus int
+		expectedBody   H
+		expectCalled   bool
+	}{
+		{
+			name:           "登録済みメールアドレス",
+			body:           H{"email": "user@example.com"},
+			expectedStatus: http.StatusOK,
+			expectedBody:   H{"message": "ok"},
+			expectCalled:   true,
+		},
+		{
+			name:           "送信失敗でも応答は変えない",
+			body:           H{"email": "user@example.com"},
+			ucErr:          errors.New("smtp down"),
+			expectedStatus: http.StatusOK,
+			expectedBody:   H{"message": "ok"},
+			expectCalled:   true,
+		},
+		{
+			name:           "メールアドレス形式不正",
+			body:           H{"email": "not-an-email"},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   H{"error": "invalid request"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			called := false
+			uc := &mockPasswordResetUsecase{ForgotFunc: func(_ context.Context, _ string) error {
+				called = true
+				return tt.ucErr
+			}}
+			h := authhttp.NewPasswordResetHandler(uc, nil, false)
+
+			w := makeRequest(t, h.Forgot, http.MethodPost, "/auth/forgot", tt.body)
+
+			assertJSONResponse(t, w, tt.expectedStatus, tt.expectedBody)
+			assert.Equal(t, tt.expectCalled, called)
+		})
+	}
+}
+
+// TestPasswordResetHandler_Forgot_RateLimited はメールベースのレートリミット超過時に429が返されることを検証します。
+func TestPasswordResetHandler_Forgot_RateLimited(t *testing.T) {
+	t.Parallel()
+
+	rdb, mock := redismock.NewClientMock()
+	t.Cleanup(func() { _ = rdb.Close() })
+	match := mock.CustomMatch(func(expected, actual []interface{}) error { return nil })
+	httpratelimit.ExpectAllow(match, "rl:forgot:email:user@example.com", false, 3)
+
+	called := false
+	uc := &mockPasswordResetUsecase{ForgotFunc: func(_ context.Context, _ string) error {
+		called = true
+		return nil
+	}}
+	h := authhttp.NewPasswordResetHandler(uc, httpratelimit.NewLimiter(rdb, infraredis.KeyBuilder{}), false)
+
+	w := makeRequest(t, h.Forgot, http.MethodPost, "/auth/forgot", H{"email": "User@Example.com"})
+
+	assertJSONResponse(t, w, http.StatusTooManyRequests, H{"error": "too many requests"})
+	assert.False(t, called, "レートリミット超過時はUsecaseが呼ばれないこと")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestPasswordResetHandler_Reset はトークン検証結果に応じたレスポンスと Cookie の削除を検証します。
+func TestPasswordResetHandler_Reset(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		body           H
+		ucErr          error
+		expectedStatus int
+		expectedBody   H
+		expectCleared  bool
+	}{
+		{
+			name:           "成功",
+			body:           H{"token": "valid-token", "password": "brand-new-password"},
+			expectedStatus: http.StatusOK,
+			expectedBody:   H{"message": "ok"},
+			expectCleared:  true,
+		},
+		{
+			name:           "無効または期限切れのトークン",
+			body:           H{"token": "used-token", "password": "brand-new-password"},
+			ucErr:          auth.ErrInvalidResetToken,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   H{"error": "invalid or expired reset token"},
+		},
+		{
+			name:           "ポリシー違反のパスワード",
+			body:           H{"token": "valid-token", "password": "short"},
+			ucErr:          auth.ErrWeakPassword,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   H{"error": "password does not meet policy"},
+		},
+		{
+			name:           "失効を記録できない",
+			body:           H{"token": "valid-token", "password": "brand-new-password"},
+			ucErr:          fmt.Errorf("revoke sessions: %w", auth.ErrSessionRevocationUnavailable),
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   H{"error": "session revocation unavailable"},
+		},
+		{
+			name:           "トークン欠落",
+			body:           H{"password": "brand-new-password"},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   H{"error": "invalid request"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc := &mockPasswordResetUsecase{ResetFunc: func(_ context.Context, _, _ string) error { return tt.ucErr }}
+			h := authhttp.NewPasswordResetHandler(uc, nil, false)
+
+			w := makeRequest(t, h.Reset, http.MethodPost, "/auth/reset", tt.body)
+
+			assertJSONResponse(t, w, tt.expectedStatus, tt.expectedBody)
+			cleared := map[string]bool{}
+			for _, c := range w.Result().Cookies() {
+				if c.MaxAge < 0 {
+					cleared[c.Name] = true
+				}
+			}
+			assert.Equal(t, tt.expectCleared, cleared["auth_token"], "auth_token cleared")
+			assert.Equal(t, tt.expectCleared, cleared["csrf_token"], "csrf_token cleared")
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package authhttp
+
+import (
+	"net/http"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+)
+
+// LoadUser は認証済みリクエストの context に、ユーザーを遅延読み込みする auth.UserGetter を格納するミドルウェアを返します。
+// jwt.AuthRequired の後に置きます。ユーザーはハンドラー等が auth.CurrentUser を初めて呼んだときに loader で読み込み、
+// 同じリクエスト内の以降の呼び出しは結果を共有します（呼ばれなければ DB を読みません）。
+// ユーザー ID のない（API キー認証の）リクエストはそのまま次へ渡します。
+func LoadUser(loader auth.UserLoader) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := jwt.UserIDFromContext(r.Context())
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx := auth.WithUserGetter(r.Context(), auth.NewUserGetter(loader, userID))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
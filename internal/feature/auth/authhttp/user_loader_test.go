@@ -0,0 +1,109 @@
+package authhttp_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth/authhttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+)
+
+// countingLoader は Load の呼び出し回数を数える auth.UserLoader です。
+type countingLoader struct {
+	calls atomic.Int32
+	users map[int64]auth.User
+}
+
+func (l *countingLoader) Load(_ context.Context, id int64) (*auth.User, error) {
+	l.calls.Add(1)
+	u, ok := l.users[id]
+	if !ok {
+		return nil, auth.ErrUserNotFound
+	}
+	return &u, nil
+}
+
+// withUser は jwt.AuthRequired の代わりに認証済みユーザーIDを context に入れるテスト用ミドルウェアです。
+func withUser(userID int64, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(jwt.WithUserID(r.Context(), userID)))
+	})
+}
+
+// TestLoadUser_OneLoadPerRequest はミドルウェアとハンドラーの複数の利用者が auth.CurrentUser を呼んでも、
+// 読み込みがリクエストごとに 1 回であることを検証します。
+func TestLoadUser_OneLoadPerRequest(t *testing.T) {
+	t.Parallel()
+	loader := &countingLoader{users: map[int64]auth.User{7: {ID: 7, Email: "a@example.com"}}}
+
+	// 1 人目の利用者: ユーザーを確認するミドルウェア
+	check := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, err := auth.CurrentUser(r.Context()); err != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	// 2・3 人目の利用者: ハンドラー内の 2 箇所
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u1, err := auth.CurrentUser(r.Context())
+		require.NoError(t, err)
+		u2, err := auth.CurrentUser(r.Context())
+		require.NoError(t, err)
+		assert.Equal(t, u1, u2)
+		_, _ = w.Write([]byte(u1.Email))
+	})
+	h := withUser(7, authhttp.LoadUser(loader)(check(handler)))
+
+	for i := range 2 {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "a@example.com", w.Body.String())
+		assert.Equal(t, int32(i+1), loader.calls.Load(), "リクエストごとに 1 回")
+	}
+}
+
+func TestLoadUser_DeletedUser(t *testing.T) {
+	t.Parallel()
+	loader := &countingLoader{users: map[int64]auth.User{}}
+	h := withUser(7, authhttp.LoadUser(loader)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for range 2 {
+			_, err := auth.CurrentUser(r.Context())
+			assert.ErrorIs(t, err, auth.ErrUserNotFound)
+		}
+	})))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, int32(1), loader.calls.Load())
+}
+
+// TestLoadUser_LazyAndAPIKey は auth.CurrentUser を呼ばないリクエストでは読み込まず、
+// ユーザーIDのない（API キー認証の）リクエストには UserGetter を格納しないことを検証します。
+func TestLoadUser_LazyAndAPIKey(t *testing.T) {
+	t.Parallel()
+	loader := &countingLoader{}
+
+	idOnly := withUser(7, authhttp.LoadUser(loader)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := jwt.UserIDFromContext(r.Context())
+		assert.True(t, ok)
+		assert.Equal(t, int64(7), id)
+	})))
+	idOnly.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Zero(t, loader.calls.Load(), "ID だけを使うハンドラーでは読み込まない")
+
+	apiKey := authhttp.LoadUser(loader)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := auth.CurrentUser(r.Context())
+		assert.ErrorIs(t, err, auth.ErrNoUserGetter)
+	}))
+	apiKey.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Zero(t, loader.calls.Load())
+}
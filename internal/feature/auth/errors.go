@@ -1,23 +1,47 @@
 package auth
 
-import "errors"
+import (
+	"fmt"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
+)
 
 var (
 	// ErrUserNotFound はメールアドレスまたはIDでユーザーが見つからない場合に返されます。
-	ErrUserNotFound = errors.New("user not found")
+	ErrUserNotFound = apperr.New(apperr.KindNotFound, "user not found", "user not found")
 
 	// ErrEmailAlreadyExists は既に存在するメールアドレスでユーザーを作成しようとした場合に返されます。
-	ErrEmailAlreadyExists = errors.New("email already exists")
+	// ユーザー列挙を防ぐため、サインアップハンドラーはこのエラーを汎用の "signup failed" として返します。
+	ErrEmailAlreadyExists = apperr.New(apperr.KindConflict, "email already exists", "email already exists")
 
 	// ErrInvalidCredentials はメールアドレスまたはパスワードが正しくない場合に返されます。
-	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrInvalidCredentials = apperr.New(apperr.KindUnauthorized, "invalid email or password", "invalid email or password")
+
+	// ErrIncorrectPassword はアカウントの削除などの確認で入力された現在のパスワードが正しくない場合に返されます。
+	ErrIncorrectPassword = apperr.New(apperr.KindUnauthorized, "incorrect_password", "current password is incorrect")
+
+	// ErrWeakPassword はパスワードがポリシー（最低文字数）を満たさない場合に返されます。
+	ErrWeakPassword = apperr.New(apperr.KindInvalid, "password does not meet policy",
+		fmt.Sprintf("password must be at least %d characters long", minPasswordLength))
+
+	// ErrInvalidResetToken はパスワードリセットトークンが存在しない・使用済み・期限切れの場合に返されます。
+	// どの理由かはクライアントに区別させません。
+	ErrInvalidResetToken = apperr.New(apperr.KindInvalid, "invalid or expired reset token", "invalid or expired reset token")
+
+	// ErrSessionRevocationUnavailable は発行済みトークンの一括失効を記録できない（失効の保存先の障害中）ことを表します。
+	// SessionRevoker の実装はこのエラーに変換して返します。失効を前提に状態を変える操作（パスワードの再設定・
+	// アカウントの削除・全端末からのログアウト）は、この場合に何も変更せず 503 を返します。
+	ErrSessionRevocationUnavailable = apperr.New(apperr.KindUnavailable, "session revocation unavailable", "session revocation unavailable")
 
 	// ErrStateNotFound はOAuthのstateが存在しない・期限切れの場合に返されます。
-	ErrStateNotFound = errors.New("oauth state not found or expired")
+	ErrStateNotFound = apperr.New(apperr.KindInvalid, "invalid or expired state", "oauth state not found or expired")
 
 	// ErrOAuthEmailUnavailable はOAuthプロバイダーから検証済みメールアドレスが取得できない場合に返されます。
-	ErrOAuthEmailUnavailable = errors.New("verified email not available from oauth provider")
+	ErrOAuthEmailUnavailable = apperr.New(apperr.KindUpstream, "cannot obtain verified email from provider", "verified email not available from oauth provider")
+
+	// ErrInvalidPlan は定義されていない料金プランを指定した場合に返されます。
+	ErrInvalidPlan = apperr.New(apperr.KindInvalid, "invalid_plan", "plan must be free or premium")
 
 	// ErrUnknownProvider は未対応のOAuthプロバイダーが指定された場合に返されます。
-	ErrUnknownProvider = errors.New("unknown oauth provider")
+	ErrUnknownProvider = apperr.New(apperr.KindInvalid, "unsupported provider", "unknown oauth provider")
 )
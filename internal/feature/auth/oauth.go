@@ -78,39 +78,35 @@ func (uc *oauthUsecase) BeginAuth(ctx context.Context, providerName string) (str
 }
 
 // HandleCallback はプロバイダーから返却されたcodeとstateを検証し、
-// JWTトークンを返します。同メールのユーザーが存在する場合は自動リンクします。
-func (uc *oauthUsecase) HandleCallback(ctx context.Context, providerName, code, state string) (string, error) {
+// JWTトークンとその有効期間を返します。同メールのユーザーが存在する場合は自動リンクします。
+func (uc *oauthUsecase) HandleCallback(ctx context.Context, providerName, code, state string) (LoginResult, error) {
 	provider, ok := uc.providers[providerName]
 	if !ok {
-		return "", ErrUnknownProvider
+		return LoginResult{}, ErrUnknownProvider
 	}
 
 	// stateの検証と消費（リプレイ攻撃防止のため atomic に削除）
 	codeVerifier, err := uc.stateStore.ConsumeState(ctx, state)
 	if err != nil {
-		return "", err
+		return LoginResult{}, err
 	}
 
 	// authorization code を ユーザー情報に交換
 	info, err := provider.ExchangeCode(ctx, code, codeVerifier)
 	if err != nil {
-		return "", fmt.Errorf("oauth code exchange failed: %w", err)
+		return LoginResult{}, fmt.Errorf("oauth code exchange failed: %w", err)
 	}
 	if info.Email == "" {
-		return "", ErrOAuthEmailUnavailable
+		return LoginResult{}, ErrOAuthEmailUnavailable
 	}
 
 	userID, err := uc.findOrCreateUser(ctx, providerName, info)
 	if err != nil {
-		return "", err
-	}
-
-	tok, err := uc.jwtGen.GenerateToken(userID, info.Email)
-	if err != nil {
-		return "", fmt.Errorf("failed to generate token: %w", err)
+		return LoginResult{}, err
 	}
 
-	return tok, nil
+	recordLogin(ctx, uc.users, userID)
+	return issueToken(uc.jwtGen, &User{ID: userID, Email: info.Email})
 }
 
 // findOrCreateUser は既存OAuthAccountを探し、なければユーザーを作成・リンクします。
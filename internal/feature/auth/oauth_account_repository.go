@@ -53,3 +53,16 @@ func (r *oauthAccountRepository) Create(ctx context.Context, acct *OAuthAccount)
 	*acct = oauthAccountFromSQLC(row)
 	return nil
 }
+
+// ListByUser はユーザーに紐付く OAuthAccount を作成順で返します。
+func (r *oauthAccountRepository) ListByUser(ctx context.Context, userID int64) ([]OAuthAccount, error) {
+	rows, err := r.q.ListOAuthAccountsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]OAuthAccount, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, oauthAccountFromSQLC(row))
+	}
+	return out, nil
+}
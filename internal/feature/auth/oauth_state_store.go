@@ -11,29 +11,34 @@ import (
 
 // redisOAuthStateStore はOAuthStateStoreインターフェースのRedis実装です。
 type redisOAuthStateStore struct {
-	rdb *redis.Client
+	rdb       *redis.Client
+	keyPrefix string
 }
 
 var _ OAuthStateStore = (*redisOAuthStateStore)(nil)
 
 // NewRedisOAuthStateStore は指定されたRedisクライアントでredisOAuthStateStoreを生成します。
-func NewRedisOAuthStateStore(rdb *redis.Client) *redisOAuthStateStore {
-	return &redisOAuthStateStore{rdb: rdb}
+// keyPrefix は環境の名前空間を含むキープレフィックス（例: "staging:oauth:state"）で、空なら "oauth:state" を使用します。
+func NewRedisOAuthStateStore(rdb *redis.Client, keyPrefix string) *redisOAuthStateStore {
+	if keyPrefix == "" {
+		keyPrefix = "oauth:state"
+	}
+	return &redisOAuthStateStore{rdb: rdb, keyPrefix: keyPrefix}
 }
 
-func stateKey(state string) string {
-	return fmt.Sprintf("oauth:state:%s", state)
+func (s *redisOAuthStateStore) stateKey(state string) string {
+	return fmt.Sprintf("%s:%s", s.keyPrefix, state)
 }
 
 // SaveState はstateとcodeVerifierをTTL付きでRedisに保存します。
 func (s *redisOAuthStateStore) SaveState(ctx context.Context, state, codeVerifier string, ttl time.Duration) error {
-	return s.rdb.Set(ctx, stateKey(state), codeVerifier, ttl).Err()
+	return s.rdb.Set(ctx, s.stateKey(state), codeVerifier, ttl).Err()
 }
 
 // ConsumeState はstateに対応するcodeVerifierを取得して削除します（GETDEL: atomic）。
 // stateが存在しない・期限切れの場合はErrStateNotFoundを返します。
 func (s *redisOAuthStateStore) ConsumeState(ctx context.Context, state string) (string, error) {
-	val, err := s.rdb.GetDel(ctx, stateKey(state)).Result()
+	val, err := s.rdb.GetDel(ctx, s.stateKey(state)).Result()
 	if errors.Is(err, redis.Nil) {
 		return "", ErrStateNotFound
 	}
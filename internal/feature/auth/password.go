@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordCost はパスワードハッシュの bcrypt のコストです。
+// タイミング攻撃防止のダミーハッシュも同じコストで生成するため、変更する場合もここだけを変えます。
+const PasswordCost = bcrypt.DefaultCost
+
+// passwordHasher はペッパー + bcrypt によるパスワードのハッシュ化と照合を行います。
+//
+// 照合は verify を通します。ハッシュがない場合（ユーザーが存在しない・OAuth のみのユーザー）も
+// 同じコストで生成したダミーハッシュと比較し、応答時間からユーザーの有無を推測されないようにします。
+// ダミーハッシュはリテラルではなく生成時に cost から作るため、実際のユーザーのハッシュとコストが常に一致します。
+type passwordHasher struct {
+	pepper string
+	cost   int
+	dummy  []byte
+}
+
+// newPasswordHasher は pepper と cost の passwordHasher を生成します。cost が bcrypt の範囲外の場合はエラーを返します。
+func newPasswordHasher(pepper string, cost int) (*passwordHasher, error) {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("bcrypt cost %d out of range [%d, %d]", cost, bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	// ダミーハッシュの元のパスワードは推測されないよう乱数にする（一致させる必要はない）
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	dummy, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(b)), cost)
+	if err != nil {
+		return nil, fmt.Errorf("generate dummy hash: %w", err)
+	}
+	return &passwordHasher{pepper: pepper, cost: cost, dummy: dummy}, nil
+}
+
+// mustNewPasswordHasher は newPasswordHasher の結果を返し、失敗した場合は panic します。
+// コストは定数（PasswordCost）のため、失敗するのはプログラムの誤りに限られます。
+func mustNewPasswordHasher(pepper string) *passwordHasher {
+	h, err := newPasswordHasher(pepper, PasswordCost)
+	if err != nil {
+		panic("auth: " + err.Error())
+	}
+	return h
+}
+
+// hash はペッパー適用済みのパスワードを bcrypt でハッシュ化します。
+func (h *passwordHasher) hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(pepperPassword(h.pepper, password)), h.cost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hashed), nil
+}
+
+// verify は password が hashed と一致するかを返します。hashed が nil・空の場合もダミーハッシュと比較して false を返し、
+// ハッシュの有無によらず bcrypt の比較が必ず 1 回行われるようにします（認証の経路はこれを通すこと）。
+func (h *passwordHasher) verify(hashed *string, password string) bool {
+	target := h.dummy
+	if hashed != nil && *hashed != "" {
+		target = []byte(*hashed)
+	}
+	err := bcrypt.CompareHashAndPassword(target, []byte(pepperPassword(h.pepper, password)))
+	return err == nil && hashed != nil && *hashed != ""
+}
+
+// passwordTiming はパスワード照合の所要時間の自己診断の結果です。
+type passwordTiming struct {
+	UnknownUser   time.Duration // ハッシュがない場合（ダミーハッシュとの比較）
+	WrongPassword time.Duration // 実際のハッシュと一致しないパスワードの比較
+}
+
+// measure はユーザーが存在しない場合とパスワードが違う場合の照合にかかる時間を測ります。
+func (h *passwordHasher) measure() (passwordTiming, error) {
+	stored, err := h.hash("self-check-password")
+	if err != nil {
+		return passwordTiming{}, err
+	}
+	start := time.Now()
+	h.verify(nil, "self-check-wrong")
+	unknown := time.Since(start)
+	start = time.Now()
+	h.verify(&stored, "self-check-wrong")
+	return passwordTiming{UnknownUser: unknown, WrongPassword: time.Since(start)}, nil
+}
+
+// durationClass は所要時間を桁ごとの区分にします（ログでの比較用）。
+func durationClass(d time.Duration) string {
+	switch {
+	case d < time.Millisecond:
+		return "<1ms"
+	case d < 10*time.Millisecond:
+		return "<10ms"
+	case d < 100*time.Millisecond:
+		return "<100ms"
+	case d < time.Second:
+		return "<1s"
+	default:
+		return ">=1s"
+	}
+}
+
+// timingRatioLimit は、ユーザーが存在しない場合とパスワードが違う場合の照合の所要時間の比の許容範囲です。
+// 同じコストなら比はほぼ 1 で、ダミーハッシュが壊れていると比較が即座に失敗して桁違いになります。
+const timingRatioLimit = 4
+
+// checkTiming は照合の所要時間を測ってログに出します。ダミーハッシュのコストが設定と違う場合や、
+// ユーザーが存在しない場合とパスワードが違う場合の所要時間が timingRatioLimit 倍以上離れている場合は
+// 警告します（タイミング攻撃の緩和が効いていない）。
+func (h *passwordHasher) checkTiming(ctx context.Context) {
+	timing, err := h.measure()
+	if err != nil {
+		slog.WarnContext(ctx, "password timing self-check failed", "error", err)
+		return
+	}
+	dummyCost, err := bcrypt.Cost(h.dummy)
+	unknown, wrong := durationClass(timing.UnknownUser), durationClass(timing.WrongPassword)
+	attrs := []any{
+		"cost", h.cost, "dummy_cost", dummyCost,
+		"unknown_user_ms", timing.UnknownUser.Milliseconds(), "wrong_password_ms", timing.WrongPassword.Milliseconds(),
+		"unknown_user_class", unknown, "wrong_password_class", wrong,
+	}
+	if err != nil || dummyCost != h.cost || !similarDuration(timing.UnknownUser, timing.WrongPassword) {
+		slog.WarnContext(ctx, "password timing self-check: unknown-user and wrong-password logins are distinguishable", attrs...)
+		return
+	}
+	slog.InfoContext(ctx, "password timing self-check", attrs...)
+}
+
+// similarDuration は a と b が timingRatioLimit 倍未満の差に収まっているかを返します。
+func similarDuration(a, b time.Duration) bool {
+	return a*timingRatioLimit > b && b*timingRatioLimit > a
+}
+
+// pepperPassword は pepper で password にペッパーを適用します。pepper が空の場合は password をそのまま返します。
+// bcryptの72バイト制限を回避するため、HMAC-SHA256で固定長のハッシュを生成します。
+func pepperPassword(pepper, password string) string {
+	if pepper == "" {
+		return password
+	}
+	mac := hmac.New(sha256.New, []byte(pepper))
+	mac.Write([]byte(password))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// PasswordResetTokenTTL はパスワードリセットトークンの有効期間です。
+const PasswordResetTokenTTL = 30 * time.Minute
+
+// resetTokenBytes はリセットトークンの乱数部のバイト数です。
+const resetTokenBytes = 32
+
+// PasswordResetRepository はパスワードリセットトークンの永続化を抽象化します。
+// トークンは平文ではなく SHA-256 ハッシュで保存します。
+type PasswordResetRepository interface {
+	// Replace は userID の既存トークンを破棄し、新しいトークンを保存します。
+	Replace(ctx context.Context, userID int64, tokenHash []byte, expiresAt time.Time) error
+	// Consume はトークンを削除し、その持ち主と有効期限を返します。
+	// 存在しない・使用済みの場合は ErrInvalidResetToken を返します。
+	Consume(ctx context.Context, tokenHash []byte) (userID int64, expiresAt time.Time, err error)
+	// DeleteExpired は有効期限が before 以前のトークンを削除し、削除した件数を返します。
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+// PasswordResetUserStore はパスワードリセットが必要とするユーザー操作です。
+type PasswordResetUserStore interface {
+	FindByEmail(ctx context.Context, email string) (*User, error)
+	// UpdatePassword はパスワードハッシュを更新します。ユーザーが存在しない場合は ErrUserNotFound を返します。
+	UpdatePassword(ctx context.Context, userID int64, passwordHash string) error
+}
+
+// PasswordResetSender はリセットトークンを本人（メールアドレスの持ち主）へ届けます。
+type PasswordResetSender interface {
+	SendPasswordReset(ctx context.Context, email, token string) error
+}
+
+// SessionRevoker はユーザーが発行済みのトークンを一括で失効させます。
+// 失効を記録できない場合は ErrSessionRevocationUnavailable を返します。
+type SessionRevoker interface {
+	RevokeAllByUserID(ctx context.Context, userID int64) error
+}
+
+// passwordResetUsecase はパスワード再設定（forgot / reset）のビジネスロジックを実装します。
+type passwordResetUsecase struct {
+	users     PasswordResetUserStore
+	resets    PasswordResetRepository
+	sender    PasswordResetSender
+	revoker   SessionRevoker
+	passwords *passwordHasher
+	now       func() time.Time
+}
+
+// NewPasswordResetUsecase は passwordResetUsecase の新しいインスタンスを生成します。
+// pepper はサインアップ・ログインと同じ PASSWORD_PEPPER を渡します。
+func NewPasswordResetUsecase(users PasswordResetUserStore, resets PasswordResetRepository, sender PasswordResetSender, revoker SessionRevoker, pepper string) *passwordResetUsecase {
+	return &passwordResetUsecase{
+		users:     users,
+		resets:    resets,
+		sender:    sender,
+		revoker:   revoker,
+		passwords: mustNewPasswordHasher(pepper),
+		now:       time.Now,
+	}
+}
+
+// Forgot は email のユーザーにリセットトークンを発行して送信します。
+// ユーザー列挙を防ぐため、未登録のメールアドレスでもエラーを返しません（何もしない）。
+// 発行済みの未使用トークンは新しいトークンで置き換えられます。
+func (u *passwordResetUsecase) Forgot(ctx context.Context, email string) error {
+	user, err := u.users.FindByEmail(ctx, email)
+	if errors.Is(err, ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("find user: %w", err)
+	}
+
+	token, tokenHash, err := newResetToken()
+	if err != nil {
+		return err
+	}
+	if err := u.resets.Replace(ctx, user.ID, tokenHash, u.now().Add(PasswordResetTokenTTL)); err != nil {
+		return fmt.Errorf("save reset token: %w", err)
+	}
+	if err := u.sender.SendPasswordReset(ctx, user.Email, token); err != nil {
+		return fmt.Errorf("send reset token: %w", err)
+	}
+	slog.InfoContext(ctx, "password reset requested", "user_id", user.ID)
+	return nil
+}
+
+// Reset はトークンを検証してパスワードを更新し、既存のトークン（ログイン状態）をすべて失効させます。
+// パスワードがポリシーを満たさない場合は ErrWeakPassword を返し、トークンは消費しません。
+// トークンが不正・使用済み・期限切れの場合は ErrInvalidResetToken を返します。
+// 失効を記録できない場合は ErrSessionRevocationUnavailable を返し、パスワードを更新せずにトークンを戻します（再試行できるように）。
+func (u *passwordResetUsecase) Reset(ctx context.Context, token, newPassword string) error {
+	if err := validatePassword(newPassword); err != nil {
+		return err
+	}
+	hashed, err := u.passwords.hash(newPassword)
+	if err != nil {
+		return err
+	}
+
+	// 消費は検証より先に行い、同じトークンの並行使用でも成功は 1 回に限る
+	tokenHash := hashResetToken(token)
+	userID, expiresAt, err := u.resets.Consume(ctx, tokenHash)
+	if err != nil {
+		return err
+	}
+	if !u.now().Before(expiresAt) {
+		return ErrInvalidResetToken
+	}
+
+	// 旧パスワードで得たログイン状態が新パスワード設定後に残らないよう、更新より先に失効させる。
+	// 失効できない場合は更新しない（乗っ取られたアカウントの再設定で、攻撃者のログイン状態が残り続けるため）
+	if err := u.revoker.RevokeAllByUserID(ctx, userID); err != nil {
+		if rerr := u.resets.Replace(ctx, userID, tokenHash, expiresAt); rerr != nil {
+			slog.WarnContext(ctx, "failed to restore password reset token", "user_id", userID, "error", rerr)
+		}
+		return fmt.Errorf("revoke sessions: %w", err)
+	}
+	if err := u.users.UpdatePassword(ctx, userID, hashed); err != nil {
+		return fmt.Errorf("update password: %w", err)
+	}
+	slog.InfoContext(ctx, "password reset completed", "user_id", userID)
+	return nil
+}
+
+// PurgeExpired は使われずに期限切れになったトークンを削除します（定期ジョブから呼び出す）。
+// 期限切れのトークンは Reset で拒否されるため、削除は保存領域の掃除のみが目的です。
+func (u *passwordResetUsecase) PurgeExpired(ctx context.Context) error {
+	n, err := u.resets.DeleteExpired(ctx, u.now())
+	if err != nil {
+		return fmt.Errorf("delete expired password resets: %w", err)
+	}
+	if n > 0 {
+		slog.InfoContext(ctx, "purged expired password reset tokens", "deleted", n)
+	}
+	return nil
+}
+
+// newResetToken はリセットトークン（URL セーフな base64）と保存用のハッシュを生成します。
+func newResetToken() (string, []byte, error) {
+	b := make([]byte, resetTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, fmt.Errorf("failed to generate reset token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, hashResetToken(token), nil
+}
+
+// hashResetToken はトークンの保存・照合用の SHA-256 ハッシュを返します。
+// トークンは 256 ビットの乱数のため、パスワードと異なりソルト・ストレッチングは不要です。
+func hashResetToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth/sqlc"
+)
+
+// passwordResetRepository は PasswordResetRepository の sqlc ベース実装です。
+type passwordResetRepository struct {
+	db *sql.DB
+	q  *authsqlc.Queries
+}
+
+var _ PasswordResetRepository = (*passwordResetRepository)(nil)
+
+// NewPasswordResetRepository は指定された *sql.DB で passwordResetRepository の新しいインスタンスを生成します。
+func NewPasswordResetRepository(db *sql.DB) *passwordResetRepository {
+	return &passwordResetRepository{db: db, q: authsqlc.New(db)}
+}
+
+// Replace は userID の既存トークンを削除し、新しいトークンを保存します（トランザクション内で実行）。
+func (r *passwordResetRepository) Replace(ctx context.Context, userID int64, tokenHash []byte, expiresAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback()
+		}
+	}()
+
+	qtx := r.q.WithTx(tx)
+	if err := qtx.DeletePasswordResetsByUser(ctx, userID); err != nil {
+		return err
+	}
+	if err := qtx.CreatePasswordReset(ctx, authsqlc.CreatePasswordResetParams{
+		TokenHash: tokenHash,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	committed = true
+	return nil
+}
+
+// Consume は tokenHash のトークンを削除し、その持ち主と有効期限を返します（DELETE ... RETURNING で原子的）。
+// 存在しない（未発行・使用済み）場合は ErrInvalidResetToken を返します。
+func (r *passwordResetRepository) Consume(ctx context.Context, tokenHash []byte) (int64, time.Time, error) {
+	row, err := r.q.ConsumePasswordReset(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, time.Time{}, ErrInvalidResetToken
+		}
+		return 0, time.Time{}, err
+	}
+	return row.UserID, row.ExpiresAt, nil
+}
+
+// DeleteExpired は有効期限が before 以前のトークンを削除し、削除した件数を返します。
+func (r *passwordResetRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	return r.q.DeleteExpiredPasswordResets(ctx, before)
+}
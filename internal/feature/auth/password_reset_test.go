@@ -0,0 +1,350 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/auth"
+)
+
+// fakeResetStore はユーザーとリセットトークンをメモリ上に保持する
+// PasswordResetUserStore / PasswordResetRepository のフェイク実装です。
+type fakeResetStore struct {
+	users  map[string]*auth.User
+	tokens map[[sha256.Size]byte]fakeResetToken
+}
+
+type fakeResetToken struct {
+	userID    int64
+	expiresAt time.Time
+}
+
+func newFakeResetStore(users ...*auth.User) *fakeResetStore {
+	s := &fakeResetStore{users: map[string]*auth.User{}, tokens: map[[sha256.Size]byte]fakeResetToken{}}
+	for _, u := range users {
+		s.users[u.Email] = u
+	}
+	return s
+}
+
+func (s *fakeResetStore) FindByEmail(_ context.Context, email string) (*auth.User, error) {
+	u, ok := s.users[email]
+	if !ok {
+		return nil, auth.ErrUserNotFound
+	}
+	return u, nil
+}
+
+func (s *fakeResetStore) UpdatePassword(_ context.Context, userID int64, passwordHash string) error {
+	for _, u := range s.users {
+		if u.ID == userID {
+			u.Password = &passwordHash
+			return nil
+		}
+	}
+	return auth.ErrUserNotFound
+}
+
+func (s *fakeResetStore) Replace(_ context.Context, userID int64, tokenHash []byte, expiresAt time.Time) error {
+	for k, v := range s.tokens {
+		if v.userID == userID {
+			delete(s.tokens, k)
+		}
+	}
+	s.tokens[[sha256.Size]byte(tokenHash)] = fakeResetToken{userID: userID, expiresAt: expiresAt}
+	return nil
+}
+
+func (s *fakeResetStore) Consume(_ context.Context, tokenHash []byte) (int64, time.Time, error) {
+	k := [sha256.Size]byte(tokenHash)
+	v, ok := s.tokens[k]
+	if !ok {
+		return 0, time.Time{}, auth.ErrInvalidResetToken
+	}
+	delete(s.tokens, k)
+	return v.userID, v.expiresAt, nil
+}
+
+func (s *fakeResetStore) DeleteExpired(_ context.Context, before time.Time) (int64, error) {
+	var n int64
+	for k, v := range s.tokens {
+		if !v.expiresAt.After(before) {
+			delete(s.tokens, k)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// expireAll は保存済みトークンの有効期限をすべて過去にします。
+func (s *fakeResetStore) expireAll() {
+	for k, v := range s.tokens {
+		v.expiresAt = time.Now().Add(-time.Second)
+		s.tokens[k] = v
+	}
+}
+
+// captureSender は送信されたトークンを記録する PasswordResetSender です。
+type captureSender struct {
+	sent map[string]string // email → token
+}
+
+func (c *captureSender) SendPasswordReset(_ context.Context, email, token string) error {
+	if c.sent == nil {
+		c.sent = map[string]string{}
+	}
+	c.sent[email] = token
+	return nil
+}
+
+// recordingRevoker は RevokeAllByUserID の呼び出しを記録する SessionRevoker です。
+type recordingRevoker struct {
+	revoked []int64
+	err     error
+}
+
+func (r *recordingRevoker) RevokeAllByUserID(_ context.Context, userID int64) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.revoked = append(r.revoked, userID)
+	return nil
+}
+
+// resetter はテストケースから Reset を呼ぶための最小インターフェースです。
+type resetter interface {
+	Reset(ctx context.Context, token, newPassword string) error
+}
+
+const resetTestEmail = "reset@example.com"
+
+// newResetFixture は登録済みユーザー1名（ID=7）のフィクスチャを生成します。
+func newResetFixture(t *testing.T) (*fakeResetStore, *captureSender, *recordingRevoker, *auth.User) {
+	t.Helper()
+	oldHash := "old-hash"
+	user := &auth.User{ID: 7, Email: resetTestEmail, Password: &oldHash}
+	return newFakeResetStore(user), &captureSender{}, &recordingRevoker{}, user
+}
+
+func TestPasswordReset_ForgotThenReset(t *testing.T) {
+	t.Parallel()
+
+	store, sender, revoker, user := newResetFixture(t)
+	uc := auth.NewPasswordResetUsecase(store, store, sender, revoker, testPepper)
+	ctx := context.Background()
+
+	if err := uc.Forgot(ctx, resetTestEmail); err != nil {
+		t.Fatalf("Forgot: %v", err)
+	}
+	token := sender.sent[resetTestEmail]
+	if len(token) < 43 {
+		t.Fatalf("token should encode 32 random bytes, got %q", token)
+	}
+	for k := range store.tokens {
+		if string(k[:]) == token {
+			t.Fatal("token must be stored hashed, not in plaintext")
+		}
+	}
+
+	if err := uc.Reset(ctx, token, "brand-new-password"); err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(*user.Password), []byte(pepperPasswordForTest("brand-new-password", testPepper))); err != nil {
+		t.Errorf("password hash was not updated with the peppered new password: %v", err)
+	}
+	if len(revoker.revoked) != 1 || revoker.revoked[0] != user.ID {
+		t.Errorf("expected sessions of user %d to be revoked, got %v", user.ID, revoker.revoked)
+	}
+	if len(store.tokens) != 0 {
+		t.Errorf("token should be deleted after use, %d left", len(store.tokens))
+	}
+}
+
+// TestPasswordReset_RevocationUnavailable は失効を記録できない場合に、パスワードを更新せずに
+// ErrSessionRevocationUnavailable を返し、同じトークンで再試行できることを検証します。
+func TestPasswordReset_RevocationUnavailable(t *testing.T) {
+	t.Parallel()
+
+	store, sender, revoker, user := newResetFixture(t)
+	revoker.err = auth.ErrSessionRevocationUnavailable
+	uc := auth.NewPasswordResetUsecase(store, store, sender, revoker, testPepper)
+	ctx := context.Background()
+
+	if err := uc.Forgot(ctx, resetTestEmail); err != nil {
+		t.Fatalf("Forgot: %v", err)
+	}
+	oldHash := *user.Password
+	token := sender.sent[resetTestEmail]
+	if err := uc.Reset(ctx, token, "brand-new-password"); !errors.Is(err, auth.ErrSessionRevocationUnavailable) {
+		t.Fatalf("Reset() error = %v, want %v", err, auth.ErrSessionRevocationUnavailable)
+	}
+	if *user.Password != oldHash {
+		t.Error("password must not be updated without revocation")
+	}
+
+	// 失効の保存先が回復すれば同じトークンで再設定できる
+	revoker.err = nil
+	if err := uc.Reset(ctx, token, "brand-new-password"); err != nil {
+		t.Fatalf("Reset after recovery: %v", err)
+	}
+	if len(revoker.revoked) != 1 || revoker.revoked[0] != user.ID {
+		t.Errorf("expected sessions of user %d to be revoked, got %v", user.ID, revoker.revoked)
+	}
+}
+
+func TestPasswordReset_ForgotUnknownEmail(t *testing.T) {
+	t.Parallel()
+
+	store, sender, revoker, _ := newResetFixture(t)
+	uc := auth.NewPasswordResetUsecase(store, store, sender, revoker, testPepper)
+
+	if err := uc.Forgot(context.Background(), "nobody@example.com"); err != nil {
+		t.Fatalf("unknown email must not be an error (no enumeration), got %v", err)
+	}
+	if len(sender.sent) != 0 || len(store.tokens) != 0 {
+		t.Errorf("nothing should be issued for an unknown email: sent=%v tokens=%d", sender.sent, len(store.tokens))
+	}
+}
+
+func TestPasswordReset_ResetFailures(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		prepare     func(t *testing.T, uc resetter, store *fakeResetStore, token string)
+		password    string
+		wantErr     error
+		wantRevoked bool
+	}{
+		{
+			name: "使用済みトークン",
+			prepare: func(t *testing.T, uc resetter, _ *fakeResetStore, token string) {
+				if err := uc.Reset(context.Background(), token, "first-new-password"); err != nil {
+					t.Fatalf("first reset: %v", err)
+				}
+			},
+			password:    "second-new-password",
+			wantErr:     auth.ErrInvalidResetToken,
+			wantRevoked: true, // 1 回目の成功分のみ
+		},
+		{
+			name: "期限切れトークン",
+			prepare: func(_ *testing.T, _ resetter, store *fakeResetStore, _ string) {
+				store.expireAll()
+			},
+			password: "brand-new-password",
+			wantErr:  auth.ErrInvalidResetToken,
+		},
+		{
+			name: "再発行で無効になった古いトークン",
+			prepare: func(t *testing.T, _ resetter, store *fakeResetStore, _ string) {
+				if err := store.Replace(context.Background(), 7, []byte("newer-token-hash-0123456789abcdef"), time.Now().Add(time.Hour)); err != nil {
+					t.Fatal(err)
+				}
+			},
+			password: "brand-new-password",
+			wantErr:  auth.ErrInvalidResetToken,
+		},
+		{
+			name:     "ポリシー違反のパスワード",
+			password: "short",
+			wantErr:  auth.ErrWeakPassword,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			store, sender, revoker, user := newResetFixture(t)
+			uc := auth.NewPasswordResetUsecase(store, store, sender, revoker, testPepper)
+			if err := uc.Forgot(context.Background(), resetTestEmail); err != nil {
+				t.Fatalf("Forgot: %v", err)
+			}
+			token := sender.sent[resetTestEmail]
+			if tt.prepare != nil {
+				tt.prepare(t, uc, store, token)
+			}
+			hashBefore := *user.Password
+
+			err := uc.Reset(context.Background(), token, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Reset error = %v, want %v", err, tt.wantErr)
+			}
+			if *user.Password != hashBefore {
+				t.Error("password must not change on failure")
+			}
+			if got := len(revoker.revoked) > 0; got != tt.wantRevoked {
+				t.Errorf("revoked = %v, want %v", revoker.revoked, tt.wantRevoked)
+			}
+		})
+	}
+}
+
+func TestPasswordReset_WeakPasswordKeepsToken(t *testing.T) {
+	t.Parallel()
+
+	store, sender, revoker, _ := newResetFixture(t)
+	uc := auth.NewPasswordResetUsecase(store, store, sender, revoker, testPepper)
+	ctx := context.Background()
+	if err := uc.Forgot(ctx, resetTestEmail); err != nil {
+		t.Fatalf("Forgot: %v", err)
+	}
+	token := sender.sent[resetTestEmail]
+
+	if err := uc.Reset(ctx, token, "short"); !errors.Is(err, auth.ErrWeakPassword) {
+		t.Fatalf("expected ErrWeakPassword, got %v", err)
+	}
+	// ポリシー違反ではトークンを消費しないため、同じトークンで再試行できる
+	if err := uc.Reset(ctx, token, "long-enough-password"); err != nil {
+		t.Fatalf("retry with valid password: %v", err)
+	}
+}
+
+func TestPasswordReset_RevokeFailureKeepsOldPassword(t *testing.T) {
+	t.Parallel()
+
+	store, sender, _, user := newResetFixture(t)
+	revoker := &recordingRevoker{err: errors.New("redis down")}
+	uc := auth.NewPasswordResetUsecase(store, store, sender, revoker, testPepper)
+	ctx := context.Background()
+	if err := uc.Forgot(ctx, resetTestEmail); err != nil {
+		t.Fatalf("Forgot: %v", err)
+	}
+
+	if err := uc.Reset(ctx, sender.sent[resetTestEmail], "brand-new-password"); err == nil {
+		t.Fatal("expected error when sessions cannot be revoked")
+	}
+	if *user.Password != "old-hash" {
+		t.Error("password must not be updated while old sessions remain valid")
+	}
+}
+
+// TestPasswordReset_PurgeExpired は期限切れのトークンだけを削除することを検証します。
+func TestPasswordReset_PurgeExpired(t *testing.T) {
+	t.Parallel()
+
+	store, sender, revoker, _ := newResetFixture(t)
+	uc := auth.NewPasswordResetUsecase(store, store, sender, revoker, testPepper)
+	ctx := context.Background()
+	if err := uc.Forgot(ctx, resetTestEmail); err != nil {
+		t.Fatalf("Forgot: %v", err)
+	}
+	if err := store.Replace(ctx, 8, []byte("expired-token-hash-0123456789abc"), time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("Replace: %v", err)
+	}
+
+	if err := uc.PurgeExpired(ctx); err != nil {
+		t.Fatalf("PurgeExpired: %v", err)
+	}
+	if len(store.tokens) != 1 {
+		t.Fatalf("tokens after purge = %d, want 1 (only the valid one)", len(store.tokens))
+	}
+	if err := uc.Reset(ctx, sender.sent[resetTestEmail], "long-enough-password"); err != nil {
+		t.Fatalf("valid token should survive the purge: %v", err)
+	}
+}
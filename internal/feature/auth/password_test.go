@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const hasherTestPepper = "test-pepper-secret-32chars-long!"
+
+// TestNewPasswordHasher_DummyCost はダミーハッシュのコストが設定したコスト（ユーザーのハッシュと同じ）になることを検証します。
+func TestNewPasswordHasher_DummyCost(t *testing.T) {
+	t.Parallel()
+
+	for _, cost := range []int{bcrypt.MinCost, bcrypt.MinCost + 2, PasswordCost} {
+		h, err := newPasswordHasher(hasherTestPepper, cost)
+		if err != nil {
+			t.Fatalf("newPasswordHasher(%d): %v", cost, err)
+		}
+		if got, err := bcrypt.Cost(h.dummy); err != nil || got != cost {
+			t.Errorf("dummy cost = (%d, %v), want %d", got, err, cost)
+		}
+		hashed, err := h.hash("password12345")
+		if err != nil {
+			t.Fatalf("hash: %v", err)
+		}
+		if got, _ := bcrypt.Cost([]byte(hashed)); got != cost {
+			t.Errorf("user hash cost = %d, want %d", got, cost)
+		}
+	}
+
+	if got, _ := bcrypt.Cost(mustNewPasswordHasher(hasherTestPepper).dummy); got != PasswordCost {
+		t.Errorf("default dummy cost = %d, want PasswordCost %d", got, PasswordCost)
+	}
+
+	for _, cost := range []int{bcrypt.MinCost - 1, bcrypt.MaxCost + 1} {
+		if _, err := newPasswordHasher(hasherTestPepper, cost); err == nil {
+			t.Errorf("newPasswordHasher(%d) expected error", cost)
+		}
+	}
+}
+
+// TestPasswordHasher_Verify はハッシュがない場合も含めて照合の結果が正しいことを検証します。
+func TestPasswordHasher_Verify(t *testing.T) {
+	t.Parallel()
+
+	h, err := newPasswordHasher(hasherTestPepper, bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashed, err := h.hash("password12345")
+	if err != nil {
+		t.Fatal(err)
+	}
+	empty := ""
+
+	tests := []struct {
+		name     string
+		hashed   *string
+		password string
+		want     bool
+	}{
+		{"correct password", &hashed, "password12345", true},
+		{"wrong password", &hashed, "password54321", false},
+		{"no hash", nil, "password12345", false},
+		{"empty hash", &empty, "", false},
+	}
+	for _, tt := range tests {
+		if got := h.verify(tt.hashed, tt.password); got != tt.want {
+			t.Errorf("%s: verify = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestPasswordHasher_Timing は、ユーザーが存在しない場合とパスワードが違う場合の照合が同じ桁の時間かかることを検証します。
+// 並列実行や CI の揺らぎに耐えるよう、それぞれ数回測った最小値を timingRatioLimit 倍の許容で比べます。
+// ダミーハッシュが壊れている場合（比較が即座に失敗する）に差が検出されることも確かめます。
+func TestPasswordHasher_Timing(t *testing.T) {
+	h, err := newPasswordHasher(hasherTestPepper, PasswordCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	minTiming := func(h *passwordHasher) passwordTiming {
+		t.Helper()
+		best := passwordTiming{UnknownUser: time.Hour, WrongPassword: time.Hour}
+		for range 3 {
+			got, err := h.measure()
+			if err != nil {
+				t.Fatalf("measure: %v", err)
+			}
+			best.UnknownUser = min(best.UnknownUser, got.UnknownUser)
+			best.WrongPassword = min(best.WrongPassword, got.WrongPassword)
+		}
+		return best
+	}
+
+	got := minTiming(h)
+	if !similarDuration(got.UnknownUser, got.WrongPassword) {
+		t.Errorf("unknown user %v vs wrong password %v: want the same order of time", got.UnknownUser, got.WrongPassword)
+	}
+
+	broken := *h
+	broken.dummy = []byte("$2a$10$not-a-valid-bcrypt-hash")
+	got = minTiming(&broken)
+	if similarDuration(got.UnknownUser, got.WrongPassword) {
+		t.Errorf("broken dummy: unknown user %v vs wrong password %v: want a detectable difference", got.UnknownUser, got.WrongPassword)
+	}
+}
+
+func TestDurationClass(t *testing.T) {
+	t.Parallel()
+
+	tests := map[time.Duration]string{
+		500 * time.Microsecond: "<1ms",
+		5 * time.Millisecond:   "<10ms",
+		60 * time.Millisecond:  "<100ms",
+		300 * time.Millisecond: "<1s",
+		2 * time.Second:        ">=1s",
+	}
+	for d, want := range tests {
+		if got := durationClass(d); got != want {
+			t.Errorf("durationClass(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -6,14 +6,29 @@ package authsqlc
 
 import (
 	"context"
+	"time"
 )
 
 type Querier interface {
+	ConsumePasswordReset(ctx context.Context, tokenHash []byte) (ConsumePasswordResetRow, error)
+	CountUsers(ctx context.Context, query string) (int64, error)
 	CreateOAuthAccount(ctx context.Context, arg CreateOAuthAccountParams) (OauthAccount, error)
+	CreatePasswordReset(ctx context.Context, arg CreatePasswordResetParams) error
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	// 有効期限が before 以前のトークン（使われずに期限切れになったもの）を削除する。
+	DeleteExpiredPasswordResets(ctx context.Context, expiresAt time.Time) (int64, error)
+	DeletePasswordResetsByUser(ctx context.Context, userID int64) error
+	// ユーザーに紐づく行（ウォッチリスト・注記・OAuth アカウント等）は外部キーの ON DELETE CASCADE で削除される。
+	DeleteUser(ctx context.Context, id int64) (int64, error)
 	FindOAuthAccountByProvider(ctx context.Context, arg FindOAuthAccountByProviderParams) (OauthAccount, error)
 	FindUserByEmail(ctx context.Context, email string) (User, error)
 	FindUserByID(ctx context.Context, id int64) (User, error)
+	ListOAuthAccountsByUser(ctx context.Context, userID int64) ([]OauthAccount, error)
+	RecordUserLogin(ctx context.Context, id int64) (int64, error)
+	// query は LIKE のワイルドカードをエスケープ済みの部分文字列（空文字なら全件）。id のキーセットでページングする。
+	SearchUsers(ctx context.Context, arg SearchUsersParams) ([]User, error)
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (int64, error)
+	UpdateUserPlan(ctx context.Context, arg UpdateUserPlanParams) (User, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -1,20 +1,39 @@
 -- name: CreateUser :one
 INSERT INTO users (email, password)
 VALUES ($1, $2)
-RETURNING id, email, password, created_at, updated_at;
+RETURNING id, email, password, created_at, updated_at, last_login_at, plan, role;
 
 -- name: FindUserByEmail :one
-SELECT id, email, password, created_at, updated_at
+SELECT id, email, password, created_at, updated_at, last_login_at, plan, role
 FROM users
 WHERE email = $1
 LIMIT 1;
 
 -- name: FindUserByID :one
-SELECT id, email, password, created_at, updated_at
+SELECT id, email, password, created_at, updated_at, last_login_at, plan, role
 FROM users
 WHERE id = $1
 LIMIT 1;
 
+-- name: RecordUserLogin :execrows
+UPDATE users
+SET last_login_at = now()
+WHERE id = $1;
+
+-- name: SearchUsers :many
+-- query は LIKE のワイルドカードをエスケープ済みの部分文字列（空文字なら全件）。id のキーセットでページングする。
+SELECT id, email, password, created_at, updated_at, last_login_at, plan, role
+FROM users
+WHERE (sqlc.arg(query)::text = '' OR email ILIKE '%' || sqlc.arg(query)::text || '%')
+  AND id > sqlc.arg(after_id)::bigint
+ORDER BY id
+LIMIT sqlc.arg(row_limit)::int;
+
+-- name: CountUsers :one
+SELECT count(*)
+FROM users
+WHERE (sqlc.arg(query)::text = '' OR email ILIKE '%' || sqlc.arg(query)::text || '%');
+
 -- name: CreateOAuthAccount :one
 INSERT INTO oauth_accounts (user_id, provider, provider_uid)
 VALUES ($1, $2, $3)
@@ -25,3 +44,43 @@ SELECT id, user_id, provider, provider_uid, created_at
 FROM oauth_accounts
 WHERE provider = $1 AND provider_uid = $2
 LIMIT 1;
+
+-- name: ListOAuthAccountsByUser :many
+SELECT id, user_id, provider, provider_uid, created_at
+FROM oauth_accounts
+WHERE user_id = $1
+ORDER BY id;
+
+-- name: UpdateUserPassword :execrows
+UPDATE users
+SET password = $2, updated_at = now()
+WHERE id = $1;
+
+-- name: DeleteUser :execrows
+-- ユーザーに紐づく行（ウォッチリスト・注記・OAuth アカウント等）は外部キーの ON DELETE CASCADE で削除される。
+DELETE FROM users
+WHERE id = $1;
+
+-- name: DeletePasswordResetsByUser :exec
+DELETE FROM password_resets
+WHERE user_id = $1;
+
+-- name: CreatePasswordReset :exec
+INSERT INTO password_resets (token_hash, user_id, expires_at)
+VALUES ($1, $2, $3);
+
+-- name: ConsumePasswordReset :one
+DELETE FROM password_resets
+WHERE token_hash = $1
+RETURNING user_id, expires_at;
+
+-- name: DeleteExpiredPasswordResets :execrows
+-- 有効期限が before 以前のトークン（使われずに期限切れになったもの）を削除する。
+DELETE FROM password_resets
+WHERE expires_at <= $1;
+
+-- name: UpdateUserPlan :one
+UPDATE users
+SET plan = $2, updated_at = now()
+WHERE id = $1
+RETURNING id, email, password, created_at, updated_at, last_login_at, plan, role;
@@ -8,8 +8,40 @@ package authsqlc
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
+const consumePasswordReset = `-- name: ConsumePasswordReset :one
+DELETE FROM password_resets
+WHERE token_hash = $1
+RETURNING user_id, expires_at
+`
+
+type ConsumePasswordResetRow struct {
+	UserID    int64
+	ExpiresAt time.Time
+}
+
+func (q *Queries) ConsumePasswordReset(ctx context.Context, tokenHash []byte) (ConsumePasswordResetRow, error) {
+	row := q.db.QueryRowContext(ctx, consumePasswordReset, tokenHash)
+	var i ConsumePasswordResetRow
+	err := row.Scan(&i.UserID, &i.ExpiresAt)
+	return i, err
+}
+
+const countUsers = `-- name: CountUsers :one
+SELECT count(*)
+FROM users
+WHERE ($1::text = '' OR email ILIKE '%' || $1::text || '%')
+`
+
+func (q *Queries) CountUsers(ctx context.Context, query string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUsers, query)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createOAuthAccount = `-- name: CreateOAuthAccount :one
 INSERT INTO oauth_accounts (user_id, provider, provider_uid)
 VALUES ($1, $2, $3)
@@ -35,10 +67,26 @@ func (q *Queries) CreateOAuthAccount(ctx context.Context, arg CreateOAuthAccount
 	return i, err
 }
 
+const createPasswordReset = `-- name: CreatePasswordReset :exec
+INSERT INTO password_resets (token_hash, user_id, expires_at)
+VALUES ($1, $2, $3)
+`
+
+type CreatePasswordResetParams struct {
+	TokenHash []byte
+	UserID    int64
+	ExpiresAt time.Time
+}
+
+func (q *Queries) CreatePasswordReset(ctx context.Context, arg CreatePasswordResetParams) error {
+	_, err := q.db.ExecContext(ctx, createPasswordReset, arg.TokenHash, arg.UserID, arg.ExpiresAt)
+	return err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (email, password)
 VALUES ($1, $2)
-RETURNING id, email, password, created_at, updated_at
+RETURNING id, email, password, created_at, updated_at, last_login_at, plan, role
 `
 
 type CreateUserParams struct {
@@ -55,10 +103,51 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Password,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.LastLoginAt,
+		&i.Plan,
+		&i.Role,
 	)
 	return i, err
 }
 
+const deleteExpiredPasswordResets = `-- name: DeleteExpiredPasswordResets :execrows
+DELETE FROM password_resets
+WHERE expires_at <= $1
+`
+
+// 有効期限が before 以前のトークン（使われずに期限切れになったもの）を削除する。
+func (q *Queries) DeleteExpiredPasswordResets(ctx context.Context, expiresAt time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteExpiredPasswordResets, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deletePasswordResetsByUser = `-- name: DeletePasswordResetsByUser :exec
+DELETE FROM password_resets
+WHERE user_id = $1
+`
+
+func (q *Queries) DeletePasswordResetsByUser(ctx context.Context, userID int64) error {
+	_, err := q.db.ExecContext(ctx, deletePasswordResetsByUser, userID)
+	return err
+}
+
+const deleteUser = `-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = $1
+`
+
+// ユーザーに紐づく行（ウォッチリスト・注記・OAuth アカウント等）は外部キーの ON DELETE CASCADE で削除される。
+func (q *Queries) DeleteUser(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const findOAuthAccountByProvider = `-- name: FindOAuthAccountByProvider :one
 SELECT id, user_id, provider, provider_uid, created_at
 FROM oauth_accounts
@@ -85,7 +174,7 @@ func (q *Queries) FindOAuthAccountByProvider(ctx context.Context, arg FindOAuthA
 }
 
 const findUserByEmail = `-- name: FindUserByEmail :one
-SELECT id, email, password, created_at, updated_at
+SELECT id, email, password, created_at, updated_at, last_login_at, plan, role
 FROM users
 WHERE email = $1
 LIMIT 1
@@ -100,12 +189,15 @@ func (q *Queries) FindUserByEmail(ctx context.Context, email string) (User, erro
 		&i.Password,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.LastLoginAt,
+		&i.Plan,
+		&i.Role,
 	)
 	return i, err
 }
 
 const findUserByID = `-- name: FindUserByID :one
-SELECT id, email, password, created_at, updated_at
+SELECT id, email, password, created_at, updated_at, last_login_at, plan, role
 FROM users
 WHERE id = $1
 LIMIT 1
@@ -120,6 +212,154 @@ func (q *Queries) FindUserByID(ctx context.Context, id int64) (User, error) {
 		&i.Password,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.LastLoginAt,
+		&i.Plan,
+		&i.Role,
+	)
+	return i, err
+}
+
+const listOAuthAccountsByUser = `-- name: ListOAuthAccountsByUser :many
+SELECT id, user_id, provider, provider_uid, created_at
+FROM oauth_accounts
+WHERE user_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListOAuthAccountsByUser(ctx context.Context, userID int64) ([]OauthAccount, error) {
+	rows, err := q.db.QueryContext(ctx, listOAuthAccountsByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []OauthAccount{}
+	for rows.Next() {
+		var i OauthAccount
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Provider,
+			&i.ProviderUid,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recordUserLogin = `-- name: RecordUserLogin :execrows
+UPDATE users
+SET last_login_at = now()
+WHERE id = $1
+`
+
+func (q *Queries) RecordUserLogin(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, recordUserLogin, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const searchUsers = `-- name: SearchUsers :many
+SELECT id, email, password, created_at, updated_at, last_login_at, plan, role
+FROM users
+WHERE ($1::text = '' OR email ILIKE '%' || $1::text || '%')
+  AND id > $2::bigint
+ORDER BY id
+LIMIT $3::int
+`
+
+type SearchUsersParams struct {
+	Query    string
+	AfterID  int64
+	RowLimit int32
+}
+
+// query は LIKE のワイルドカードをエスケープ済みの部分文字列（空文字なら全件）。id のキーセットでページングする。
+func (q *Queries) SearchUsers(ctx context.Context, arg SearchUsersParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, searchUsers, arg.Query, arg.AfterID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Password,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.LastLoginAt,
+			&i.Plan,
+			&i.Role,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :execrows
+UPDATE users
+SET password = $2, updated_at = now()
+WHERE id = $1
+`
+
+type UpdateUserPasswordParams struct {
+	ID       int64
+	Password sql.NullString
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateUserPassword, arg.ID, arg.Password)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateUserPlan = `-- name: UpdateUserPlan :one
+UPDATE users
+SET plan = $2, updated_at = now()
+WHERE id = $1
+RETURNING id, email, password, created_at, updated_at, last_login_at, plan, role
+`
+
+type UpdateUserPlanParams struct {
+	ID   int64
+	Plan string
+}
+
+func (q *Queries) UpdateUserPlan(ctx context.Context, arg UpdateUserPlanParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserPlan, arg.ID, arg.Plan)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LastLoginAt,
+		&i.Plan,
+		&i.Role,
 	)
 	return i, err
 }
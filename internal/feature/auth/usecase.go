@@ -2,13 +2,10 @@ package auth
 
 import (
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
+	"errors"
 	"fmt"
+	"log/slog"
 	"time"
-
-	"golang.org/x/crypto/bcrypt"
 )
 
 const (
@@ -80,6 +77,13 @@ type UserRepository interface {
 	// FindByID は指定されたIDに一致するユーザーを取得します。
 	// ユーザーが存在しない場合、エラーを返します。
 	FindByID(ctx context.Context, id int64) (*User, error)
+
+	// RecordLogin は指定されたIDのユーザーの最終ログイン日時を現在時刻に更新します。
+	RecordLogin(ctx context.Context, id int64) error
+
+	// Delete は指定されたIDのユーザーと、ユーザーに紐づくデータを削除します。
+	// ユーザーが存在しない場合、ErrUserNotFound を返します。
+	Delete(ctx context.Context, id int64) error
 }
 
 // JWTGenerator はJWTトークン生成のインターフェースを定義します。
@@ -87,99 +91,164 @@ type UserRepository interface {
 type JWTGenerator interface {
 	// GenerateToken は指定されたユーザーの署名済みJWTトークンを生成します。
 	GenerateToken(userID int64, email string) (string, error)
+	// ExpiresIn は生成するトークンの有効期間を返します。
+	ExpiresIn() time.Duration
+}
+
+// LoginResult はログイン成功時に発行したトークンとその有効期間、認証したユーザーです。
+// ExpiresIn は常にトークンを生成した JWTGenerator の設定値と一致します（Cookie の Max-Age に使用）。
+// User は認証時に読み込んだユーザーで、ログイン直後のプロフィール取得の往復を省くために応答へ埋め込めます。
+// パスワードハッシュは含みません（Password は常に nil）。
+type LoginResult struct {
+	Token     string
+	ExpiresIn time.Duration
+	User      User
 }
 
 // usecase は認証ビジネスロジックを実装します。
 type usecase struct {
 	users        UserRepository
 	jwtGenerator JWTGenerator
-	pepper       string
-	dummyHash    string // タイミング攻撃防止用のダミーハッシュ
+	passwords    *passwordHasher
+	revoker      SessionRevoker // nil の場合 LogoutAll・DeleteAccount はエラーを返す（WithSessionRevoker 参照）
 }
 
 // NewUsecase はusecaseの新しいインスタンスを生成します。
+// タイミング攻撃防止用のダミーハッシュはここで PasswordCost から生成します（passwordHasher 参照）。
 func NewUsecase(users UserRepository, jwtGenerator JWTGenerator, pepper string) *usecase {
-	uc := &usecase{
+	return &usecase{
 		users:        users,
 		jwtGenerator: jwtGenerator,
-		pepper:       pepper,
+		passwords:    mustNewPasswordHasher(pepper),
 	}
-	// ペッパー適用済みのダミーハッシュを事前計算（タイミング攻撃防止用）
-	pepperedDummy := uc.pepperPassword("dummy")
-	dummyHash, _ := bcrypt.GenerateFromPassword([]byte(pepperedDummy), bcrypt.DefaultCost)
-	uc.dummyHash = string(dummyHash)
-	return uc
 }
 
-// pepperPassword はHMAC-SHA256を使用してパスワードにペッパーを適用します。
-// bcryptの72バイト制限を回避するため、HMAC-SHA256で固定長のハッシュを生成します。
-func (u *usecase) pepperPassword(password string) string {
-	if u.pepper == "" {
-		return password
+// WithSessionRevoker は LogoutAll・DeleteAccount で発行済みのトークンを一括失効させる手段を設定します。
+func (u *usecase) WithSessionRevoker(r SessionRevoker) *usecase {
+	u.revoker = r
+	return u
+}
+
+// errRevokerNotConfigured は SessionRevoker を設定せずに LogoutAll・DeleteAccount を呼んだ場合のエラーです（構成の誤り）。
+var errRevokerNotConfigured = errors.New("session revoker is not configured")
+
+// LogoutAll は userID に発行済みのトークンをすべて失効させ、すべての端末からログアウトします。
+// 発行済みのトークンがない場合も成功します（失効は「この時刻より前に発行されたトークン」の下限として記録するため）。
+// 失効を記録できない場合（ErrSessionRevocationUnavailable を含む）はエラーを返します。
+func (u *usecase) LogoutAll(ctx context.Context, userID int64) error {
+	if u.revoker == nil {
+		return errRevokerNotConfigured
 	}
-	mac := hmac.New(sha256.New, []byte(u.pepper))
-	mac.Write([]byte(password))
-	return hex.EncodeToString(mac.Sum(nil))
+	if err := u.revoker.RevokeAllByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("revoke sessions of user %d: %w", userID, err)
+	}
+	return nil
+}
+
+// DeleteAccount は現在のパスワードで本人であることを確かめたうえで、userID のトークンをすべて失効させ、ユーザーを削除します。
+// パスワードが違う場合（パスワードを設定していない OAuth のみのユーザーを含む）は ErrIncorrectPassword を返し、何も変更しません。
+// 削除済みのユーザーが失効前のトークンで操作を続けられないよう、削除より先に失効させます。
+// 失効を記録できない場合（ErrSessionRevocationUnavailable を含む）は削除しません。
+func (u *usecase) DeleteAccount(ctx context.Context, userID int64, password string) error {
+	if u.revoker == nil {
+		return errRevokerNotConfigured
+	}
+	user, err := u.users.FindByID(ctx, userID)
+	if err != nil && !errors.Is(err, ErrUserNotFound) {
+		return fmt.Errorf("find user: %w", err)
+	}
+	// タイミング攻撃防止のため、ユーザーが存在しない場合もダミーハッシュと比較する
+	var passwordHash *string
+	if err == nil {
+		passwordHash = user.Password
+	}
+	if !u.passwords.verify(passwordHash, password) || err != nil {
+		return ErrIncorrectPassword
+	}
+
+	if err := u.revoker.RevokeAllByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("revoke sessions: %w", err)
+	}
+	if err := u.users.Delete(ctx, userID); err != nil && !errors.Is(err, ErrUserNotFound) {
+		return fmt.Errorf("delete user: %w", err)
+	}
+	slog.InfoContext(ctx, "account deleted", "user_id", userID)
+	return nil
+}
+
+// CheckPasswordTiming は起動時の自己診断として、ユーザーが存在しない場合とパスワードが違う場合の
+// ログインの照合にかかる時間を測ってログに出し、差がある場合は警告します。
+func (u *usecase) CheckPasswordTiming(ctx context.Context) {
+	u.passwords.checkTiming(ctx)
 }
 
 // validatePassword はパスワードがセキュリティ要件を満たしているかチェックします。
 func validatePassword(password string) error {
 	if len(password) < minPasswordLength {
-		return fmt.Errorf("password must be at least %d characters long", minPasswordLength)
+		return ErrWeakPassword
 	}
 	return nil
 }
 
 // Signup はハッシュ化されたパスワードで新規ユーザーを登録します。
 // 成功時に作成されたユーザーのIDを返します。
+// メールアドレスの重複は事前に検索せず Create（ユニークインデックス）で判定するため、同じメールアドレスの
+// 同時登録で後から挿入した側も、通常の重複と同じ ErrEmailAlreadyExists を返します。
 func (u *usecase) Signup(ctx context.Context, email, password string) (int64, error) {
 	// パスワード強度を検証
 	if err := validatePassword(password); err != nil {
 		return 0, err
 	}
 
-	pepperedPassword := u.pepperPassword(password)
-	hashed, err := bcrypt.GenerateFromPassword([]byte(pepperedPassword), bcrypt.DefaultCost)
+	hashed, err := u.passwords.hash(password)
 	if err != nil {
-		return 0, fmt.Errorf("failed to hash password: %w", err)
+		return 0, err
 	}
-	hashedStr := string(hashed)
-	user := &User{Email: email, Password: &hashedStr}
+	user := &User{Email: email, Password: &hashed}
 	if err := u.users.Create(ctx, user); err != nil {
 		return 0, err
 	}
 	return user.ID, nil
 }
 
-// Login はユーザーを認証し、成功時にJWTトークンを返します。
+// Login はユーザーを認証し、成功時にJWTトークンとその有効期間を返します。
 // メールアドレスとパスワードを検証し、署名済みJWTトークンを生成します。
 // タイミング攻撃を防止するため、ユーザーが存在しない場合でもbcrypt比較を実行します。
-func (u *usecase) Login(ctx context.Context, email, password string) (string, error) {
+func (u *usecase) Login(ctx context.Context, email, password string) (LoginResult, error) {
 	// メールアドレスでユーザーを検索
 	user, err := u.users.FindByEmail(ctx, email)
 
-	// ユーザーが存在しない場合のタイミング攻撃緩和用ダミーハッシュ
-	// bcrypt.CompareHashAndPasswordが常に呼ばれることを保証する
-	passwordHash := u.dummyHash
-	if err == nil && user.Password != nil {
-		passwordHash = *user.Password
+	// タイミング攻撃防止のため、ユーザーが存在しない場合もダミーハッシュと比較する（verify が常に bcrypt を実行する）
+	var passwordHash *string
+	if err == nil {
+		passwordHash = user.Password
 	}
-
-	// タイミング攻撃防止のため、常にパスワードを検証
-	// 第1引数はハッシュ化パスワード、第2引数は平文パスワード
-	pepperedPassword := u.pepperPassword(password)
-	compareErr := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(pepperedPassword))
+	ok := u.passwords.verify(passwordHash, password)
 
 	// ユーザー未検出またはパスワード不一致の場合、汎用エラーを返す
-	if err != nil || compareErr != nil {
-		return "", ErrInvalidCredentials
+	if err != nil || !ok {
+		return LoginResult{}, ErrInvalidCredentials
 	}
 
-	// 注入されたジェネレーターを使用してJWTトークンを生成
-	token, tokenErr := u.jwtGenerator.GenerateToken(user.ID, user.Email)
-	if tokenErr != nil {
-		return "", fmt.Errorf("failed to generate token: %w", tokenErr)
+	recordLogin(ctx, u.users, user.ID)
+	return issueToken(u.jwtGenerator, user)
+}
+
+// recordLogin はユーザーの最終ログイン日時を記録します。
+// 管理者向けの参考情報のため、記録に失敗してもログイン自体は失敗させません。
+func recordLogin(ctx context.Context, users UserRepository, userID int64) {
+	if err := users.RecordLogin(ctx, userID); err != nil {
+		slog.WarnContext(ctx, "failed to record last login", "user_id", userID, "error", err)
 	}
+}
 
-	return token, nil
+// issueToken は注入されたジェネレーターで user のJWTトークンを生成し、有効期間・ユーザー（パスワードを除く）とともに返します。
+func issueToken(gen JWTGenerator, user *User) (LoginResult, error) {
+	token, err := gen.GenerateToken(user.ID, user.Email)
+	if err != nil {
+		return LoginResult{}, fmt.Errorf("failed to generate token: %w", err)
+	}
+	profile := *user
+	profile.Password = nil
+	return LoginResult{Token: token, ExpiresIn: gen.ExpiresIn(), User: profile}, nil
 }
@@ -6,7 +6,9 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 
@@ -34,6 +36,10 @@ type mockUserRepository struct {
 	FindByEmailFunc func(ctx context.Context, email string) (*auth.User, error)
 	// FindByIDFunc はFindByIDメソッド呼び出し時に実行されます。
 	FindByIDFunc func(ctx context.Context, id int64) (*auth.User, error)
+	// RecordLoginFunc はRecordLoginメソッド呼び出し時に実行されます。
+	RecordLoginFunc func(ctx context.Context, id int64) error
+	// DeleteFunc はDeleteメソッド呼び出し時に実行されます。
+	DeleteFunc func(ctx context.Context, id int64) error
 }
 
 // mockJWTGenerator はJWTGeneratorインターフェースのモック実装です。
@@ -41,6 +47,8 @@ type mockUserRepository struct {
 type mockJWTGenerator struct {
 	// GenerateTokenFunc はGenerateTokenメソッド呼び出し時に実行されます。
 	GenerateTokenFunc func(userID int64, email string) (string, error)
+	// Expiration はExpiresInが返す有効期間です（ゼロ値なら1時間）。
+	Expiration time.Duration
 }
 
 // GenerateToken はGenerateTokenメソッドのモック実装です。
@@ -52,6 +60,14 @@ func (m *mockJWTGenerator) GenerateToken(userID int64, email string) (string, er
 	return "mock-jwt-token", nil
 }
 
+// ExpiresIn はExpiresInメソッドのモック実装です。
+func (m *mockJWTGenerator) ExpiresIn() time.Duration {
+	if m.Expiration != 0 {
+		return m.Expiration
+	}
+	return time.Hour
+}
+
 // Create はCreateメソッドのモック実装です。
 func (m *mockUserRepository) Create(ctx context.Context, user *auth.User) error {
 	if m.CreateFunc != nil {
@@ -78,6 +94,22 @@ func (m *mockUserRepository) FindByID(ctx context.Context, id int64) (*auth.User
 	return nil, errors.New("user not found")
 }
 
+// RecordLogin はRecordLoginメソッドのモック実装です。
+func (m *mockUserRepository) RecordLogin(ctx context.Context, id int64) error {
+	if m.RecordLoginFunc != nil {
+		return m.RecordLoginFunc(ctx, id)
+	}
+	return nil // デフォルト: 成功
+}
+
+// Delete はDeleteメソッドのモック実装です。
+func (m *mockUserRepository) Delete(ctx context.Context, id int64) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, id)
+	}
+	return nil // デフォルト: 成功
+}
+
 // createTestUser はテスト用にハッシュ化パスワードを持つテストユーザーを作成します。
 // このヘルパーはコードの重複を削減し、テストの保守性を向上させます。
 func createTestUser(t *testing.T, id int64, email, password string) *auth.User {
@@ -212,6 +244,24 @@ func TestAuthUsecase_Signup(t *testing.T) {
 	}
 }
 
+// TestAuthUsecase_Signup_DuplicateEmail はリポジトリがラップして返したメールアドレスの重複（同時登録の競合を含む）を
+// 通常の重複と同じ ErrEmailAlreadyExists として返すことを検証します。
+func TestAuthUsecase_Signup_DuplicateEmail(t *testing.T) {
+	t.Parallel()
+
+	mockRepo := &mockUserRepository{
+		CreateFunc: func(ctx context.Context, user *auth.User) error {
+			return fmt.Errorf("create user: %w", auth.ErrEmailAlreadyExists)
+		},
+	}
+	uc := auth.NewUsecase(mockRepo, &mockJWTGenerator{}, testPepper)
+
+	_, err := uc.Signup(context.Background(), "dup@example.com", "password12345")
+	if !errors.Is(err, auth.ErrEmailAlreadyExists) {
+		t.Errorf("expected ErrEmailAlreadyExists, got: %v", err)
+	}
+}
+
 func TestAuthUsecase_Login(t *testing.T) {
 	t.Parallel() // enable parallel execution for test function
 
@@ -301,13 +351,14 @@ func TestAuthUsecase_Login(t *testing.T) {
 			}
 
 			uc := auth.NewUsecase(mockRepo, mockJWT, testPepper)
-			token, err := uc.Login(context.Background(), tt.email, tt.password)
+			res, err := uc.Login(context.Background(), tt.email, tt.password)
 
 			// エラーの期待値を検証
 			assertError(t, err, tt.wantErr, tt.errMsg)
 
 			// 成功ケースの期待値を検証
 			if !tt.wantErr {
+				token := res.Token
 				if token == "" {
 					t.Error("token is empty")
 				}
@@ -319,6 +370,231 @@ func TestAuthUsecase_Login(t *testing.T) {
 	}
 }
 
+// TestAuthUsecase_Login_ExpiresInMatchesGenerator は LoginResult.ExpiresIn が
+// 常にジェネレーターの設定値と一致する（ユースケース側で値を持たない）ことを検証します。
+func TestAuthUsecase_Login_ExpiresInMatchesGenerator(t *testing.T) {
+	t.Parallel()
+
+	testUser := createTestUser(t, 1, "test@example.com", "password12345")
+	for _, expiration := range []time.Duration{15 * time.Minute, time.Hour, 24 * time.Hour} {
+		t.Run(expiration.String(), func(t *testing.T) {
+			t.Parallel()
+			mockRepo := &mockUserRepository{
+				FindByEmailFunc: func(ctx context.Context, email string) (*auth.User, error) { return testUser, nil },
+			}
+			uc := auth.NewUsecase(mockRepo, &mockJWTGenerator{Expiration: expiration}, testPepper)
+
+			res, err := uc.Login(context.Background(), "test@example.com", "password12345")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.ExpiresIn != expiration {
+				t.Errorf("ExpiresIn = %v, want generator's %v", res.ExpiresIn, expiration)
+			}
+		})
+	}
+}
+
+// TestAuthUsecase_Login_ReturnsUserWithoutPassword は LoginResult.User が認証時に読み込んだユーザーで、
+// パスワードハッシュを含まないことを検証します（ユーザーは 1 回だけ読み込む）。
+func TestAuthUsecase_Login_ReturnsUserWithoutPassword(t *testing.T) {
+	t.Parallel()
+
+	testUser := createTestUser(t, 42, "test@example.com", "password12345")
+	testUser.CreatedAt = time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	finds := 0
+	mockRepo := &mockUserRepository{
+		FindByEmailFunc: func(ctx context.Context, email string) (*auth.User, error) {
+			finds++
+			return testUser, nil
+		},
+	}
+	uc := auth.NewUsecase(mockRepo, &mockJWTGenerator{}, testPepper)
+
+	res, err := uc.Login(context.Background(), "test@example.com", "password12345")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.User.ID != 42 || res.User.Email != "test@example.com" || !res.User.CreatedAt.Equal(testUser.CreatedAt) {
+		t.Errorf("User = %+v, want the authenticated user", res.User)
+	}
+	if res.User.Password != nil {
+		t.Error("User.Password must not be exposed")
+	}
+	if testUser.Password == nil {
+		t.Error("the repository's user must not be modified")
+	}
+	if finds != 1 {
+		t.Errorf("FindByEmail called %d times, want 1", finds)
+	}
+}
+
+// TestAuthUsecase_Login_RecordsLastLogin はログイン成功時だけ最終ログイン日時を記録し、
+// 記録の失敗ではログインを失敗させないことを検証します。
+func TestAuthUsecase_Login_RecordsLastLogin(t *testing.T) {
+	t.Parallel()
+
+	testUser := createTestUser(t, 7, "test@example.com", "password12345")
+	var recorded []int64
+	mockRepo := &mockUserRepository{
+		FindByEmailFunc: func(ctx context.Context, email string) (*auth.User, error) { return testUser, nil },
+		RecordLoginFunc: func(ctx context.Context, id int64) error {
+			recorded = append(recorded, id)
+			return errors.New("db unavailable")
+		},
+	}
+	uc := auth.NewUsecase(mockRepo, &mockJWTGenerator{}, testPepper)
+
+	if _, err := uc.Login(context.Background(), "test@example.com", "wrong-password"); err == nil {
+		t.Fatal("expected error for wrong password")
+	}
+	if len(recorded) != 0 {
+		t.Errorf("failed login must not be recorded, got %v", recorded)
+	}
+
+	if _, err := uc.Login(context.Background(), "test@example.com", "password12345"); err != nil {
+		t.Fatalf("login should succeed even if recording fails: %v", err)
+	}
+	if len(recorded) != 1 || recorded[0] != 7 {
+		t.Errorf("recorded = %v, want [7]", recorded)
+	}
+}
+
+// TestAuthUsecase_LogoutAll は LogoutAll が SessionRevoker でユーザーのトークンを失効させることを検証します。
+func TestAuthUsecase_LogoutAll(t *testing.T) {
+	t.Parallel()
+
+	revoker := &recordingRevoker{}
+	uc := auth.NewUsecase(&mockUserRepository{}, &mockJWTGenerator{}, testPepper).WithSessionRevoker(revoker)
+	for range 2 {
+		if err := uc.LogoutAll(context.Background(), 7); err != nil {
+			t.Fatalf("LogoutAll() error = %v", err)
+		}
+	}
+	if len(revoker.revoked) != 2 || revoker.revoked[0] != 7 {
+		t.Errorf("revoked = %v, want [7 7]", revoker.revoked)
+	}
+
+	storeErr := errors.New("redis down")
+	uc = auth.NewUsecase(&mockUserRepository{}, &mockJWTGenerator{}, testPepper).WithSessionRevoker(&recordingRevoker{err: storeErr})
+	if err := uc.LogoutAll(context.Background(), 7); !errors.Is(err, storeErr) {
+		t.Errorf("LogoutAll() error = %v, want %v", err, storeErr)
+	}
+
+	if err := auth.NewUsecase(&mockUserRepository{}, &mockJWTGenerator{}, testPepper).LogoutAll(context.Background(), 7); err == nil {
+		t.Error("LogoutAll() without revoker should fail")
+	}
+}
+
+// TestAuthUsecase_DeleteAccount は DeleteAccount がパスワードを確かめ、トークンを失効させてからユーザーを削除することを検証します。
+func TestAuthUsecase_DeleteAccount(t *testing.T) {
+	t.Parallel()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(pepperPasswordForTest("password12345", testPepper)), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashStr := string(hash)
+	users := func(deleted *[]int64) *mockUserRepository {
+		return &mockUserRepository{
+			FindByIDFunc: func(_ context.Context, id int64) (*auth.User, error) {
+				switch id {
+				case 7:
+					return &auth.User{ID: 7, Email: "test@example.com", Password: &hashStr}, nil
+				case 8: // OAuth のみのユーザー
+					return &auth.User{ID: 8, Email: "oauth@example.com"}, nil
+				}
+				return nil, auth.ErrUserNotFound
+			},
+			DeleteFunc: func(_ context.Context, id int64) error {
+				*deleted = append(*deleted, id)
+				return nil
+			},
+		}
+	}
+
+	storeErr := errors.New("redis down")
+	tests := []struct {
+		name        string
+		userID      int64
+		password    string
+		revokeErr   error
+		wantErr     error
+		wantRevoked []int64
+		wantDeleted []int64
+	}{
+		{name: "success", userID: 7, password: "password12345", wantRevoked: []int64{7}, wantDeleted: []int64{7}},
+		{name: "wrong password", userID: 7, password: "wrong-password", wantErr: auth.ErrIncorrectPassword},
+		{name: "oauth only user", userID: 8, password: "password12345", wantErr: auth.ErrIncorrectPassword},
+		{name: "user not found", userID: 9, password: "password12345", wantErr: auth.ErrIncorrectPassword},
+		{name: "revoke failure keeps user", userID: 7, password: "password12345", revokeErr: storeErr, wantErr: storeErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var deleted []int64
+			revoker := &recordingRevoker{err: tt.revokeErr}
+			uc := auth.NewUsecase(users(&deleted), &mockJWTGenerator{}, testPepper).WithSessionRevoker(revoker)
+
+			err := uc.DeleteAccount(context.Background(), tt.userID, tt.password)
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Fatalf("DeleteAccount() error = %v", err)
+			case !errors.Is(err, tt.wantErr):
+				t.Fatalf("DeleteAccount() error = %v, want %v", err, tt.wantErr)
+			}
+			if fmt.Sprint(revoker.revoked) != fmt.Sprint(tt.wantRevoked) {
+				t.Errorf("revoked = %v, want %v", revoker.revoked, tt.wantRevoked)
+			}
+			if fmt.Sprint(deleted) != fmt.Sprint(tt.wantDeleted) {
+				t.Errorf("deleted = %v, want %v", deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+// TestAuthUsecase_DeleteAccount_RevocationUnavailable は失効を記録できない場合に、アカウントを削除せず
+// ErrSessionRevocationUnavailable を返すことを検証します（削除後も失効前のトークンが AuthRequired を通り続けないようにするため）。
+func TestAuthUsecase_DeleteAccount_RevocationUnavailable(t *testing.T) {
+	t.Parallel()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(pepperPasswordForTest("password12345", testPepper)), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashStr := string(hash)
+	var deleted []int64
+	users := &mockUserRepository{
+		FindByIDFunc: func(_ context.Context, id int64) (*auth.User, error) {
+			return &auth.User{ID: id, Email: "test@example.com", Password: &hashStr}, nil
+		},
+		DeleteFunc: func(_ context.Context, id int64) error {
+			deleted = append(deleted, id)
+			return nil
+		},
+	}
+	uc := auth.NewUsecase(users, &mockJWTGenerator{}, testPepper).WithSessionRevoker(unavailableRevoker{})
+
+	err = uc.DeleteAccount(context.Background(), 7, "password12345")
+	if !errors.Is(err, auth.ErrSessionRevocationUnavailable) {
+		t.Fatalf("DeleteAccount() error = %v, want %v", err, auth.ErrSessionRevocationUnavailable)
+	}
+	if len(deleted) != 0 {
+		t.Errorf("user must not be deleted without revocation, deleted = %v", deleted)
+	}
+
+	if err := uc.LogoutAll(context.Background(), 7); !errors.Is(err, auth.ErrSessionRevocationUnavailable) {
+		t.Errorf("LogoutAll() error = %v, want %v", err, auth.ErrSessionRevocationUnavailable)
+	}
+}
+
+// unavailableRevoker は失効の保存先の障害中を模す SessionRevoker です。
+type unavailableRevoker struct{}
+
+func (unavailableRevoker) RevokeAllByUserID(context.Context, int64) error {
+	return auth.ErrSessionRevocationUnavailable
+}
+
 // TestAuthUsecase_PepperApplied はペッパーが正しくパスワードに適用されることを検証します。
 func TestAuthUsecase_PepperApplied(t *testing.T) {
 	t.Parallel()
@@ -22,4 +22,44 @@ type User struct {
 
 	// UpdatedAt はユーザーが最後に更新された日時です。
 	UpdatedAt time.Time
+
+	// LastLoginAt はユーザーが最後にログイン（パスワードまたはOAuth）に成功した日時です。
+	// 一度もログインしていない場合は nil です。
+	LastLoginAt *time.Time
+
+	// Plan はユーザーの料金プランです（既定は PlanFree）。管理者が変更します（AdminUserUsecase.SetPlan）。
+	Plan Plan
+
+	// Role はユーザーの権限です（既定は RoleUser）。RoleAdmin は運用者が DB で付与します。
+	Role Role
+}
+
+// Plan はユーザーの料金プランです。参照できる銘柄の区分（basic / premium）を決めます。
+type Plan string
+
+const (
+	// PlanFree は無料プランです。basic の銘柄だけを参照できます。
+	PlanFree Plan = "free"
+	// PlanPremium は有料プランです。すべての銘柄を参照できます。
+	PlanPremium Plan = "premium"
+)
+
+// Valid は p が定義済みのプランかを返します。
+func (p Plan) Valid() bool {
+	return p == PlanFree || p == PlanPremium
+}
+
+// Role はユーザーの権限です。管理者ユーザー向けの管理ルートに到達できるかを決めます。
+type Role string
+
+const (
+	// RoleUser は一般のユーザーです。
+	RoleUser Role = "user"
+	// RoleAdmin は管理者です。管理ルートのユーザー管理などに到達できます（authhttp.AdminRequired）。
+	RoleAdmin Role = "admin"
+)
+
+// IsAdmin はユーザーが管理者かを返します。
+func (u User) IsAdmin() bool {
+	return u.Role == RoleAdmin
 }
@@ -0,0 +1,196 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultUserCacheTTL は認証済みユーザーのプロセス内キャッシュの有効期間です。
+// 同じユーザーからの連続したリクエストで DB の読み込みをまとめるための短い値で、
+// プロフィール・パスワードの変更は CachingUserRepository を経由する限り即座に破棄されます。
+const DefaultUserCacheTTL = 10 * time.Second
+
+// maxUserCacheEntries はキャッシュする最大ユーザー数です。超えた場合は期限切れの項目を掃除し、
+// それでも空かなければキャッシュ全体を捨てます（メモリを際限なく使わないための上限）。
+const maxUserCacheEntries = 10_000
+
+// cachedUserStore は CachingUserRepository が包むユーザーリポジトリの操作です。
+type cachedUserStore interface {
+	UserRepository         // usecase.go（サインアップ・ログイン）
+	PasswordResetUserStore // password_reset.go（UpdatePassword）
+	UserSearcher           // admin_users.go（Search・Count）
+	UserPlanStore          // admin_users.go（UpdatePlan）
+	OAuthUserCreator       // oauth.go（OAuth でのユーザー作成）
+}
+
+// userCacheEntry はキャッシュした FindByID の結果です。user が nil の場合はユーザーが存在しないこと（ErrUserNotFound）を表します。
+type userCacheEntry struct {
+	user    *User
+	expires time.Time
+}
+
+// CachingUserRepository はユーザーリポジトリのデコレータで、認証済みユーザーの読み込み（Load）を
+// プロセス内で短時間キャッシュします。存在しないユーザー（削除済み）も同じ期間キャッシュします。
+//
+// FindByID を含む他の操作はキャッシュせずそのまま委譲し、ユーザーを更新する操作（UpdatePassword・RecordLogin）は
+// 委譲後に該当ユーザーのキャッシュを破棄します（UpdatePlan・Delete も同様）。キャッシュはプロセスごとのため、別インスタンスでの変更は
+// 最大 TTL の間反映されません。
+type CachingUserRepository struct {
+	cachedUserStore
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[int64]userCacheEntry
+}
+
+// NewCachingUserRepository は users を包む CachingUserRepository を生成します（TTL は DefaultUserCacheTTL）。
+func NewCachingUserRepository(users cachedUserStore) *CachingUserRepository {
+	return &CachingUserRepository{
+		cachedUserStore: users,
+		ttl:             DefaultUserCacheTTL,
+		now:             time.Now,
+		entries:         make(map[int64]userCacheEntry),
+	}
+}
+
+// WithTTL はキャッシュの有効期間を設定します。0 以下の場合は DefaultUserCacheTTL を使います。
+func (r *CachingUserRepository) WithTTL(ttl time.Duration) *CachingUserRepository {
+	if ttl <= 0 {
+		ttl = DefaultUserCacheTTL
+	}
+	r.ttl = ttl
+	return r
+}
+
+// Load は id のユーザーをキャッシュ経由で返します。存在しない場合は ErrUserNotFound を返します。
+// 返すユーザーは呼び出しごとの複製のため、呼び出し側で変更してもキャッシュに影響しません。
+// ErrUserNotFound 以外のエラー（DB 障害など）はキャッシュしません。
+func (r *CachingUserRepository) Load(ctx context.Context, id int64) (*User, error) {
+	now := r.now()
+	r.mu.Lock()
+	e, ok := r.entries[id]
+	r.mu.Unlock()
+	if ok && now.Before(e.expires) {
+		return copyUser(e.user)
+	}
+
+	u, err := r.cachedUserStore.FindByID(ctx, id)
+	if err != nil && !errors.Is(err, ErrUserNotFound) {
+		return nil, err
+	}
+	r.store(id, userCacheEntry{user: u, expires: now.Add(r.ttl)})
+	return copyUser(u)
+}
+
+// Invalidate は id のユーザーのキャッシュを破棄します。
+func (r *CachingUserRepository) Invalidate(id int64) {
+	r.mu.Lock()
+	delete(r.entries, id)
+	r.mu.Unlock()
+}
+
+// UpdatePassword はパスワードハッシュを更新し、id のユーザーのキャッシュを破棄します。
+func (r *CachingUserRepository) UpdatePassword(ctx context.Context, id int64, passwordHash string) error {
+	defer r.Invalidate(id)
+	return r.cachedUserStore.UpdatePassword(ctx, id, passwordHash)
+}
+
+// UpdatePlan は料金プランを更新し、id のユーザーのキャッシュを破棄します。
+func (r *CachingUserRepository) UpdatePlan(ctx context.Context, id int64, plan Plan) (*User, error) {
+	defer r.Invalidate(id)
+	return r.cachedUserStore.UpdatePlan(ctx, id, plan)
+}
+
+// RecordLogin は最終ログイン日時を更新し、id のユーザーのキャッシュを破棄します。
+func (r *CachingUserRepository) RecordLogin(ctx context.Context, id int64) error {
+	defer r.Invalidate(id)
+	return r.cachedUserStore.RecordLogin(ctx, id)
+}
+
+// Delete はユーザーを削除し、id のユーザーのキャッシュを破棄します。
+func (r *CachingUserRepository) Delete(ctx context.Context, id int64) error {
+	defer r.Invalidate(id)
+	return r.cachedUserStore.Delete(ctx, id)
+}
+
+func (r *CachingUserRepository) store(id int64, e userCacheEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.entries) >= maxUserCacheEntries {
+		now := r.now()
+		for k, v := range r.entries {
+			if !now.Before(v.expires) {
+				delete(r.entries, k)
+			}
+		}
+		if len(r.entries) >= maxUserCacheEntries {
+			r.entries = make(map[int64]userCacheEntry)
+		}
+	}
+	r.entries[id] = e
+}
+
+// copyUser はキャッシュしたユーザーの複製を返します。nil（存在しない）の場合は ErrUserNotFound を返します。
+func copyUser(u *User) (*User, error) {
+	if u == nil {
+		return nil, ErrUserNotFound
+	}
+	c := *u
+	return &c, nil
+}
+
+// UserGetter はリクエストの認証済みユーザーを返す関数です。
+// 初回の呼び出しでのみ読み込み、以降は同じ結果を返します（1 リクエストあたり DB の読み込みは最大 1 回）。
+type UserGetter func(ctx context.Context) (*User, error)
+
+// UserLoader は ID でユーザーを読み込みます（CachingUserRepository が実装します）。
+type UserLoader interface {
+	Load(ctx context.Context, id int64) (*User, error)
+}
+
+// NewUserGetter は id のユーザーを loader で遅延読み込みし、結果を記憶する UserGetter を返します。
+// 並行に呼び出しても読み込みは 1 回です。
+func NewUserGetter(loader UserLoader, id int64) UserGetter {
+	var (
+		once sync.Once
+		user *User
+		err  error
+	)
+	return func(ctx context.Context) (*User, error) {
+		once.Do(func() {
+			user, err = loader.Load(ctx, id)
+		})
+		if err != nil {
+			return nil, err
+		}
+		c := *user
+		return &c, nil
+	}
+}
+
+// userGetterKey は UserGetter を context に格納するための非公開キー型です。
+type userGetterKey struct{}
+
+// ErrNoUserGetter は context に UserGetter が格納されていない場合（ユーザー読み込みのミドルウェアを
+// 通過していないルート、または API キー認証）に CurrentUser が返すエラーです。
+var ErrNoUserGetter = errors.New("auth: no user getter in context")
+
+// WithUserGetter は context に UserGetter を格納した新しい context を返します。
+// ユーザー読み込みのミドルウェア（authhttp.LoadUser）が使用するほか、テストでの注入にも利用できます。
+func WithUserGetter(ctx context.Context, get UserGetter) context.Context {
+	return context.WithValue(ctx, userGetterKey{}, get)
+}
+
+// CurrentUser はリクエストの認証済みユーザーを返します。
+// ユーザーが削除済みの場合は ErrUserNotFound、UserGetter がない場合は ErrNoUserGetter を返します。
+// ユーザー ID だけが必要な場合は jwt.UserIDFromContext を使い、DB を読まないようにしてください。
+func CurrentUser(ctx context.Context) (*User, error) {
+	get, ok := ctx.Value(userGetterKey{}).(UserGetter)
+	if !ok {
+		return nil, ErrNoUserGetter
+	}
+	return get(ctx)
+}
@@ -0,0 +1,214 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingUserStore は FindByID の呼び出し回数を数える cachedUserStore のインメモリ実装です。
+type countingUserStore struct {
+	mu      sync.Mutex
+	users   map[int64]User
+	finds   int
+	findErr error
+}
+
+func (s *countingUserStore) Create(context.Context, *User) error { return nil }
+func (s *countingUserStore) FindByEmail(context.Context, string) (*User, error) {
+	return nil, ErrUserNotFound
+}
+func (s *countingUserStore) Search(context.Context, string, int, int64) ([]User, error) {
+	return nil, nil
+}
+func (s *countingUserStore) Count(context.Context, string) (int64, error) { return 0, nil }
+func (s *countingUserStore) CreateUserWithOAuthAccount(context.Context, *User, *OAuthAccount) error {
+	return nil
+}
+
+func (s *countingUserStore) FindByID(_ context.Context, id int64) (*User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.finds++
+	if s.findErr != nil {
+		return nil, s.findErr
+	}
+	u, ok := s.users[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	return &u, nil
+}
+
+func (s *countingUserStore) UpdatePassword(_ context.Context, id int64, hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[id]
+	if !ok {
+		return ErrUserNotFound
+	}
+	u.Password = &hash
+	s.users[id] = u
+	return nil
+}
+
+func (s *countingUserStore) RecordLogin(_ context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := s.users[id]
+	now := time.Now()
+	u.LastLoginAt = &now
+	s.users[id] = u
+	return nil
+}
+
+func (s *countingUserStore) Delete(_ context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.users[id]; !ok {
+		return ErrUserNotFound
+	}
+	delete(s.users, id)
+	return nil
+}
+
+func (s *countingUserStore) UpdatePlan(_ context.Context, id int64, plan Plan) (*User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	u.Plan = plan
+	s.users[id] = u
+	return &u, nil
+}
+
+func (s *countingUserStore) findCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.finds
+}
+
+// userLoaderFunc は関数を UserLoader として使うためのアダプターです。
+type userLoaderFunc func(ctx context.Context, id int64) (*User, error)
+
+func (f userLoaderFunc) Load(ctx context.Context, id int64) (*User, error) { return f(ctx, id) }
+
+func newTestUserCache(store *countingUserStore) (*CachingUserRepository, *time.Time) {
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := NewCachingUserRepository(store)
+	repo.now = func() time.Time { return clock }
+	return repo, &clock
+}
+
+func TestCachingUserRepository_Load(t *testing.T) {
+	t.Parallel()
+	store := &countingUserStore{users: map[int64]User{1: {ID: 1, Email: "a@example.com"}}}
+	repo, clock := newTestUserCache(store)
+	ctx := context.Background()
+
+	u, err := repo.Load(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "a@example.com", u.Email)
+
+	// 返したユーザーを変更してもキャッシュは変わらない
+	u.Email = "changed@example.com"
+	u, err = repo.Load(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "a@example.com", u.Email)
+	assert.Equal(t, 1, store.findCount(), "TTL 内は DB を読まない")
+
+	*clock = clock.Add(DefaultUserCacheTTL)
+	_, err = repo.Load(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, store.findCount(), "TTL 経過後は読み直す")
+}
+
+// TestCachingUserRepository_Load_NegativeCache は削除済み（存在しない）ユーザーの結果も TTL の間キャッシュし、
+// DB 障害などのエラーはキャッシュしないことを検証します。
+func TestCachingUserRepository_Load_NegativeCache(t *testing.T) {
+	t.Parallel()
+	store := &countingUserStore{users: map[int64]User{}}
+	repo, clock := newTestUserCache(store)
+	ctx := context.Background()
+
+	for range 3 {
+		_, err := repo.Load(ctx, 9)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	}
+	assert.Equal(t, 1, store.findCount())
+
+	*clock = clock.Add(DefaultUserCacheTTL)
+	store.findErr = errors.New("db down")
+	for range 2 {
+		_, err := repo.Load(ctx, 9)
+		assert.EqualError(t, err, "db down")
+	}
+	assert.Equal(t, 3, store.findCount(), "ErrUserNotFound 以外のエラーはキャッシュしない")
+}
+
+// TestCachingUserRepository_InvalidatesOnUpdate はパスワード更新・ログイン記録・料金プランの変更・削除でキャッシュを破棄することを検証します。
+func TestCachingUserRepository_InvalidatesOnUpdate(t *testing.T) {
+	t.Parallel()
+	store := &countingUserStore{users: map[int64]User{1: {ID: 1, Email: "a@example.com"}}}
+	repo, _ := newTestUserCache(store)
+	ctx := context.Background()
+
+	u, err := repo.Load(ctx, 1)
+	require.NoError(t, err)
+	assert.Nil(t, u.Password)
+
+	require.NoError(t, repo.UpdatePassword(ctx, 1, "new-hash"))
+	u, err = repo.Load(ctx, 1)
+	require.NoError(t, err)
+	require.NotNil(t, u.Password)
+	assert.Equal(t, "new-hash", *u.Password)
+	assert.Equal(t, 2, store.findCount())
+
+	require.NoError(t, repo.RecordLogin(ctx, 1))
+	u, err = repo.Load(ctx, 1)
+	require.NoError(t, err)
+	assert.NotNil(t, u.LastLoginAt)
+	assert.Equal(t, 3, store.findCount())
+
+	_, err = repo.UpdatePlan(ctx, 1, PlanPremium)
+	require.NoError(t, err)
+	u, err = repo.Load(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, PlanPremium, u.Plan)
+	assert.Equal(t, 4, store.findCount())
+
+	require.NoError(t, repo.Delete(ctx, 1))
+	_, err = repo.Load(ctx, 1)
+	assert.ErrorIs(t, err, ErrUserNotFound, "削除したユーザーはキャッシュから返さない")
+	assert.Equal(t, 5, store.findCount())
+}
+
+// TestCurrentUser_LoadsOncePerRequest は同じリクエスト（UserGetter）内で何度・並行に呼んでも読み込みが 1 回であることを検証します。
+func TestCurrentUser_LoadsOncePerRequest(t *testing.T) {
+	t.Parallel()
+	store := &countingUserStore{users: map[int64]User{1: {ID: 1, Email: "a@example.com"}}}
+	// キャッシュを介さない loader で、UserGetter の記憶だけで 1 回になることを確かめる
+	loader := userLoaderFunc(store.FindByID)
+
+	ctx := WithUserGetter(context.Background(), NewUserGetter(loader, 1))
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Go(func() {
+			u, err := CurrentUser(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1), u.ID)
+		})
+	}
+	wg.Wait()
+	assert.Equal(t, 1, store.findCount())
+
+	_, err := CurrentUser(context.Background())
+	assert.ErrorIs(t, err, ErrNoUserGetter)
+}
@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgconn"
 
@@ -14,6 +16,9 @@ import (
 // pgErrUniqueViolation は PostgreSQL のユニーク制約違反コードです。
 const pgErrUniqueViolation = "23505"
 
+// usersEmailUniqueIndex は users.email のユニークインデックス名です（db/migrations/00001_init_schema.sql）。
+const usersEmailUniqueIndex = "idx_users_email"
+
 // userRepository は UserRepository / OAuthUserCreator の sqlc ベース実装です。
 type userRepository struct {
 	db *sql.DB
@@ -21,8 +26,11 @@ type userRepository struct {
 }
 
 var (
-	_ UserRepository   = (*userRepository)(nil)
-	_ OAuthUserCreator = (*userRepository)(nil)
+	_ UserRepository         = (*userRepository)(nil)
+	_ OAuthUserCreator       = (*userRepository)(nil)
+	_ PasswordResetUserStore = (*userRepository)(nil)
+	_ UserSearcher           = (*userRepository)(nil)
+	_ UserPlanStore          = (*userRepository)(nil)
 )
 
 // NewUserRepository は指定された *sql.DB で userRepository の新しいインスタンスを生成します。
@@ -32,6 +40,7 @@ func NewUserRepository(db *sql.DB) *userRepository {
 
 // Create はユーザーをデータベースに追加します。
 // 同じメールアドレスのユーザーが既に存在する場合、ErrEmailAlreadyExists を返します。
+// 重複はユニークインデックスで判定するため、同じメールアドレスの同時登録でも 1 件だけが成功します。
 func (r *userRepository) Create(ctx context.Context, u *User) error {
 	if u == nil {
 		return errors.New("user is nil")
@@ -75,6 +84,94 @@ func (r *userRepository) FindByID(ctx context.Context, id int64) (*User, error)
 	return &u, nil
 }
 
+// UpdatePassword は id のユーザーのパスワードハッシュを更新します。
+// ユーザーが存在しない場合、ErrUserNotFound を返します。
+func (r *userRepository) UpdatePassword(ctx context.Context, id int64, passwordHash string) error {
+	n, err := r.q.UpdateUserPassword(ctx, authsqlc.UpdateUserPasswordParams{
+		ID:       id,
+		Password: sql.NullString{String: passwordHash, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// UpdatePlan は id のユーザーの料金プランを更新し、更新後のユーザーを返します。
+// ユーザーが存在しない場合、ErrUserNotFound を返します。
+func (r *userRepository) UpdatePlan(ctx context.Context, id int64, plan Plan) (*User, error) {
+	row, err := r.q.UpdateUserPlan(ctx, authsqlc.UpdateUserPlanParams{ID: id, Plan: string(plan)})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	u := userFromSQLC(row)
+	return &u, nil
+}
+
+// RecordLogin は id のユーザーの最終ログイン日時を現在時刻に更新します。
+// ユーザーが存在しない場合、ErrUserNotFound を返します。
+func (r *userRepository) RecordLogin(ctx context.Context, id int64) error {
+	n, err := r.q.RecordUserLogin(ctx, id)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// Delete は id のユーザーを削除します。ユーザーに紐づくデータは外部キーの ON DELETE CASCADE で削除されます。
+// ユーザーが存在しない場合、ErrUserNotFound を返します。
+func (r *userRepository) Delete(ctx context.Context, id int64) error {
+	n, err := r.q.DeleteUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// Search はメールアドレスに query を含むユーザー（大文字小文字を区別しない。空文字なら全件）を
+// ID が cursorID より大きいものから ID 昇順で最大 limit 件返します。
+// query 中の LIKE のワイルドカード（% と _）は文字として扱います。
+func (r *userRepository) Search(ctx context.Context, query string, limit int, cursorID int64) ([]User, error) {
+	rows, err := r.q.SearchUsers(ctx, authsqlc.SearchUsersParams{
+		Query:    escapeLike(query),
+		AfterID:  cursorID,
+		RowLimit: int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	users := make([]User, 0, len(rows))
+	for _, row := range rows {
+		users = append(users, userFromSQLC(row))
+	}
+	return users, nil
+}
+
+// Count はメールアドレスに query を含むユーザーの総数を返します（一致の規則は Search と同じ）。
+func (r *userRepository) Count(ctx context.Context, query string) (int64, error) {
+	return r.q.CountUsers(ctx, escapeLike(query))
+}
+
+// likeEscaper は LIKE パターンの特殊文字をエスケープします（PostgreSQL の既定のエスケープ文字はバックスラッシュ）。
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike は s を LIKE パターン中で文字どおりに一致するようエスケープします。
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
 // CreateUserWithOAuthAccount は User と OAuthAccount をトランザクション内で原子的に作成します。
 func (r *userRepository) CreateUserWithOAuthAccount(ctx context.Context, user *User, account *OAuthAccount) error {
 	if user == nil || account == nil {
@@ -126,12 +223,20 @@ func userFromSQLC(m authsqlc.User) User {
 		s := m.Password.String
 		pwd = &s
 	}
+	var lastLogin *time.Time
+	if m.LastLoginAt.Valid {
+		t := m.LastLoginAt.Time
+		lastLogin = &t
+	}
 	return User{
-		ID:        m.ID,
-		Email:     m.Email,
-		Password:  pwd,
-		CreatedAt: m.CreatedAt,
-		UpdatedAt: m.UpdatedAt,
+		ID:          m.ID,
+		Email:       m.Email,
+		Password:    pwd,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+		LastLoginAt: lastLogin,
+		Plan:        Plan(m.Plan),
+		Role:        Role(m.Role),
 	}
 }
 
@@ -154,10 +259,11 @@ func toNullString(s *string) sql.NullString {
 	return sql.NullString{String: *s, Valid: true}
 }
 
-// mapEmailUniqueErr は PostgreSQL のユニーク制約違反を ErrEmailAlreadyExists にマッピングします。
+// mapEmailUniqueErr は users.email のユニーク制約違反を ErrEmailAlreadyExists にマッピングします。
+// 他のユニーク制約（OAuth アカウント等）の違反はメールアドレスの重複と区別するためそのまま返します。
 func mapEmailUniqueErr(err error) error {
 	var pgErr *pgconn.PgError
-	if errors.As(err, &pgErr) && pgErr.Code == pgErrUniqueViolation {
+	if errors.As(err, &pgErr) && pgErr.Code == pgErrUniqueViolation && pgErr.ConstraintName == usersEmailUniqueIndex {
 		return ErrEmailAlreadyExists
 	}
 	return err
@@ -5,7 +5,9 @@ import (
 	"database/sql"
 	"log"
 	"os"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -222,6 +224,7 @@ func TestUserRepository_FindByID(t *testing.T) {
 				assert.Equal(t, expected.ID, found.ID)
 				assert.Equal(t, expected.Email, found.Email)
 				assert.Equal(t, expected.Password, found.Password)
+				assert.Equal(t, RoleUser, found.Role, "既定の権限は user")
 			},
 		},
 		{
@@ -338,4 +341,218 @@ func TestUserRepository_CreateUserWithOAuthAccount(t *testing.T) {
 		assert.Zero(t, user.ID, "user should not be persisted")
 		assert.Zero(t, acct.ID, "account should not be persisted")
 	})
+
+	t.Run("failure: duplicate provider uid is not reported as duplicate email", func(t *testing.T) {
+		t.Parallel()
+		db := setupTestDB(t)
+		repo := NewUserRepository(db)
+
+		require.NoError(t, repo.CreateUserWithOAuthAccount(context.Background(),
+			&User{Email: "first@example.com"}, &OAuthAccount{Provider: "google", ProviderUID: "sub-dup"}))
+		err := repo.CreateUserWithOAuthAccount(context.Background(),
+			&User{Email: "second@example.com"}, &OAuthAccount{Provider: "google", ProviderUID: "sub-dup"})
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrEmailAlreadyExists)
+	})
+}
+
+// TestUsecase_Signup_ConcurrentSameEmail は同じメールアドレスでの同時サインアップで 1 件だけが成功し、
+// 残りは事前の検索なしにユニークインデックスの違反から ErrEmailAlreadyExists を返すことを検証します。
+func TestUsecase_Signup_ConcurrentSameEmail(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	uc := NewUsecase(NewUserRepository(db), nil, "test-pepper")
+
+	const n = 2
+	start := make(chan struct{})
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Go(func() {
+			<-start
+			_, errs[i] = uc.Signup(context.Background(), "race@example.com", "password12345")
+		})
+	}
+	close(start)
+	wg.Wait()
+
+	var succeeded int
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.ErrorIs(t, err, ErrEmailAlreadyExists)
+	}
+	assert.Equal(t, 1, succeeded, "exactly one signup should succeed")
+}
+
+func TestOAuthAccountRepository_ListByUser(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	ctx := context.Background()
+	accounts := NewOAuthAccountRepository(db)
+
+	owner := seedUser(t, db, "owner@example.com", "p")
+	other := seedUser(t, db, "other@example.com", "p")
+	require.NoError(t, accounts.Create(ctx, &OAuthAccount{UserID: owner.ID, Provider: "google", ProviderUID: "sub-1"}))
+	require.NoError(t, accounts.Create(ctx, &OAuthAccount{UserID: owner.ID, Provider: "github", ProviderUID: "42"}))
+	require.NoError(t, accounts.Create(ctx, &OAuthAccount{UserID: other.ID, Provider: "google", ProviderUID: "sub-2"}))
+
+	got, err := accounts.ListByUser(ctx, owner.ID)
+	require.NoError(t, err)
+	require.Len(t, got, 2, "他ユーザーのアカウントを含まない")
+	assert.Equal(t, "google", got[0].Provider)
+	assert.Equal(t, "github", got[1].Provider)
+
+	none, err := accounts.ListByUser(ctx, 0)
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func TestUserRepository_UpdatePassword(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+	user := seedUser(t, db, "reset@example.com", "old_hash")
+
+	require.NoError(t, repo.UpdatePassword(ctx, user.ID, "new_hash"))
+	got, err := repo.FindByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.Password)
+	assert.Equal(t, "new_hash", *got.Password)
+	assert.False(t, got.UpdatedAt.Before(user.UpdatedAt))
+
+	assert.ErrorIs(t, repo.UpdatePassword(ctx, user.ID+1000, "x"), ErrUserNotFound)
+}
+
+func TestUserRepository_RecordLogin(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+	user := seedUser(t, db, "login@example.com", "hash")
+	assert.Nil(t, user.LastLoginAt, "new users have never logged in")
+
+	require.NoError(t, repo.RecordLogin(ctx, user.ID))
+	got, err := repo.FindByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.LastLoginAt)
+	assert.WithinDuration(t, time.Now(), *got.LastLoginAt, time.Minute)
+
+	assert.ErrorIs(t, repo.RecordLogin(ctx, user.ID+1000), ErrUserNotFound)
+}
+
+// TestUserRepository_Delete はユーザーの削除で紐づくデータ（OAuth アカウント・再設定トークン）も削除されることを検証します。
+func TestUserRepository_Delete(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewUserRepository(db)
+	accounts := NewOAuthAccountRepository(db)
+	ctx := context.Background()
+	user := seedUser(t, db, "delete@example.com", "hash")
+	other := seedUser(t, db, "keep@example.com", "hash")
+	require.NoError(t, accounts.Create(ctx, &OAuthAccount{UserID: user.ID, Provider: "google", ProviderUID: "sub-1"}))
+	require.NoError(t, NewPasswordResetRepository(db).Replace(ctx, user.ID, []byte("token-hash"), time.Now().Add(time.Hour)))
+
+	require.NoError(t, repo.Delete(ctx, user.ID))
+	_, err := repo.FindByID(ctx, user.ID)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	linked, err := accounts.ListByUser(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, linked, "OAuth アカウントも削除される")
+	var resets int
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT count(*) FROM password_resets WHERE user_id = $1", user.ID).Scan(&resets))
+	assert.Zero(t, resets, "再設定トークンも削除される")
+
+	_, err = repo.FindByID(ctx, other.ID)
+	require.NoError(t, err, "他のユーザーは残る")
+	assert.ErrorIs(t, repo.Delete(ctx, user.ID), ErrUserNotFound)
+}
+
+func TestUserRepository_SearchAndCount(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+	alice := seedUser(t, db, "alice@example.com", "hash")
+	bob := seedUser(t, db, "bob_smith@example.org", "hash")
+	carol := seedUser(t, db, "Carol@Example.com", "hash")
+	seedUser(t, db, "bobXsmith@example.org", "hash")
+
+	ids := func(users []User) []int64 {
+		out := make([]int64, 0, len(users))
+		for _, u := range users {
+			out = append(out, u.ID)
+		}
+		return out
+	}
+
+	got, err := repo.Search(ctx, "EXAMPLE.COM", 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{alice.ID, carol.ID}, ids(got), "match is case-insensitive and ordered by id")
+	n, err := repo.Count(ctx, "EXAMPLE.COM")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	// _ はワイルドカードではなく文字として一致する
+	got, err = repo.Search(ctx, "bob_", 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{bob.ID}, ids(got))
+
+	// 空の検索は全件。cursorID より大きい ID から limit 件
+	got, err = repo.Search(ctx, "", 2, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{bob.ID, carol.ID}, ids(got))
+	n, err = repo.Count(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), n)
+}
+
+func TestPasswordResetRepository_ReplaceAndConsume(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewPasswordResetRepository(db)
+	ctx := context.Background()
+	user := seedUser(t, db, "reset@example.com", "hash")
+	expiresAt := time.Now().Add(PasswordResetTokenTTL).Truncate(time.Microsecond)
+
+	require.NoError(t, repo.Replace(ctx, user.ID, []byte("first"), expiresAt))
+	// 再発行で既存トークンは無効になる
+	require.NoError(t, repo.Replace(ctx, user.ID, []byte("second"), expiresAt))
+	_, _, err := repo.Consume(ctx, []byte("first"))
+	assert.ErrorIs(t, err, ErrInvalidResetToken)
+
+	userID, gotExpiry, err := repo.Consume(ctx, []byte("second"))
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, userID)
+	assert.True(t, gotExpiry.Equal(expiresAt), "expiresAt: got %v, want %v", gotExpiry, expiresAt)
+
+	// 単回使用
+	_, _, err = repo.Consume(ctx, []byte("second"))
+	assert.ErrorIs(t, err, ErrInvalidResetToken)
+}
+
+func TestPasswordResetRepository_DeleteExpired(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewPasswordResetRepository(db)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Microsecond)
+	expired := seedUser(t, db, "expired@example.com", "hash")
+	valid := seedUser(t, db, "valid@example.com", "hash")
+
+	require.NoError(t, repo.Replace(ctx, expired.ID, []byte("expired"), now.Add(-time.Minute)))
+	require.NoError(t, repo.Replace(ctx, valid.ID, []byte("valid"), now.Add(time.Hour)))
+
+	n, err := repo.DeleteExpired(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	_, _, err = repo.Consume(ctx, []byte("expired"))
+	assert.ErrorIs(t, err, ErrInvalidResetToken)
+	userID, _, err := repo.Consume(ctx, []byte("valid"))
+	require.NoError(t, err)
+	assert.Equal(t, valid.ID, userID)
 }
@@ -0,0 +1,109 @@
+package candles
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// MaxAdjustmentReasonLength は調整理由の最大文字数です（candle_adjustments.reason VARCHAR(255)）。
+const MaxAdjustmentReasonLength = 255
+
+// Adjustment は株式分割・併合等による過去のローソク足の調整係数です。
+// 保存済みのローソク足は未調整のまま保持し、読み取り時に ApplyAdjustments で適用します。
+type Adjustment struct {
+	ID         int64
+	SymbolCode string
+	// EffectiveDate は調整の効力発生日です。この日より前の足に Factor を適用します。
+	EffectiveDate time.Time
+	// Factor は価格に掛ける係数です（2対1分割なら 0.5、1対10併合なら 10）。出来高は Factor で割ります。
+	Factor    float64
+	Reason    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Validate は調整係数として登録できるかを検証します。不正な場合は ErrInvalidAdjustment をラップしたエラーを返します。
+func (a Adjustment) Validate() error {
+	if a.EffectiveDate.IsZero() {
+		return fmt.Errorf("%w: missing effective date", ErrInvalidAdjustment)
+	}
+	if math.IsNaN(a.Factor) || math.IsInf(a.Factor, 0) || a.Factor <= 0 {
+		return fmt.Errorf("%w: factor must be a positive finite number", ErrInvalidAdjustment)
+	}
+	if len([]rune(a.Reason)) > MaxAdjustmentReasonLength {
+		return fmt.Errorf("%w: reason must be at most %d characters", ErrInvalidAdjustment, MaxAdjustmentReasonLength)
+	}
+	return nil
+}
+
+// CumulativeFactor は時刻 t の足に適用する累積係数（t より後に効力が発生する全調整の Factor の積）を返します。
+// 効力発生日との比較は日付単位で、t はその足のタイムゾーンの暦日で判定します。調整がない場合は 1 を返します。
+func CumulativeFactor(adjs []Adjustment, t time.Time) float64 {
+	day := civilDay(t)
+	factor := 1.0
+	for _, a := range adjs {
+		if day < civilDay(a.EffectiveDate) {
+			factor *= a.Factor
+		}
+	}
+	return factor
+}
+
+// ApplyAdjustments は cs の各足の O/H/L/C に累積係数を掛け、出来高を累積係数で割った新しいスライスを返します。
+// cs は変更しません。価格は保存精度（小数 4 桁）、出来高は整数に丸めます。
+// 週足・月足は代表時刻（期間の開始日）で判定するため、期間の途中で効力が発生する調整は期間全体に適用されます。
+func ApplyAdjustments(cs []Candle, adjs []Adjustment) []Candle {
+	out := make([]Candle, len(cs))
+	copy(out, cs)
+	if len(adjs) == 0 {
+		return out
+	}
+	for i := range out {
+		f := CumulativeFactor(adjs, out[i].Time)
+		if f == 1 {
+			continue
+		}
+		out[i].Open = roundPrice(out[i].Open * f)
+		out[i].High = roundPrice(out[i].High * f)
+		out[i].Low = roundPrice(out[i].Low * f)
+		out[i].Close = roundPrice(out[i].Close * f)
+		out[i].Volume = int64(math.Round(float64(out[i].Volume) / f))
+	}
+	return out
+}
+
+// AdjustmentsVersion は調整係数の内容（効力発生日と係数）から短いハッシュを返します。
+// 調整後のローソク足のキャッシュキーに含め、調整の追加・変更・削除でキャッシュが切り替わるようにします。
+// 順序に依存せず、調整がない場合は空文字を返します。
+func AdjustmentsVersion(adjs []Adjustment) string {
+	if len(adjs) == 0 {
+		return ""
+	}
+	entries := make([]string, 0, len(adjs))
+	for _, a := range adjs {
+		entries = append(entries, a.EffectiveDate.Format(time.DateOnly)+"="+strconv.FormatFloat(a.Factor, 'g', -1, 64))
+	}
+	sort.Strings(entries)
+	h := sha256.New()
+	for _, e := range entries {
+		h.Write([]byte(e))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// civilDay は t の暦日を比較可能な整数（YYYYMMDD）で返します。
+func civilDay(t time.Time) int {
+	y, m, d := t.Date()
+	return y*10000 + int(m)*100 + d
+}
+
+// roundPrice は価格を保存精度（小数 4 桁、NUMERIC(15,4)）に丸めます。
+func roundPrice(v float64) float64 {
+	return math.Round(v*1e4) / 1e4
+}
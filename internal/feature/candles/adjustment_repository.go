@@ -0,0 +1,153 @@
+package candles
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/sqlc"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/queryspec"
+)
+
+// searchAdjustmentsQuery は調整係数一覧の SELECT です。絞り込み・並び替えは AdjustmentListSchema で付け加えます。
+// 列の順序は SearchAdjustments の Scan と対応させます。
+const searchAdjustmentsQuery = `SELECT id, symbol_code, effective_date, factor, reason, created_at, updated_at
+FROM candle_adjustments`
+
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+)
+
+// adjustmentRepository は AdjustmentRepository（AdjustmentSource を含む）の sqlc 実装です。
+// 管理用の一覧は絞り込みが動的なため、sqlc を使わず queryspec で組み立てた SQL を実行します。
+type adjustmentRepository struct {
+	db *sql.DB
+	q  *candlessqlc.Queries
+}
+
+var (
+	_ AdjustmentSource     = (*adjustmentRepository)(nil)
+	_ AdjustmentRepository = (*adjustmentRepository)(nil)
+)
+
+// NewAdjustmentRepository は指定された *sql.DB で adjustmentRepository の新しいインスタンスを生成します。
+func NewAdjustmentRepository(db *sql.DB) *adjustmentRepository {
+	return &adjustmentRepository{db: db, q: candlessqlc.New(db)}
+}
+
+// ListAdjustments は銘柄 symbol の調整係数を効力発生日の古い順に返します。symbol が空の場合は全銘柄を返します。
+func (r *adjustmentRepository) ListAdjustments(ctx context.Context, symbol string) ([]Adjustment, error) {
+	rows, err := r.q.ListAdjustments(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Adjustment, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, adjustmentFromSQLC(row))
+	}
+	return out, nil
+}
+
+// SearchAdjustments は spec の絞り込み・並び替え・ページングに従って調整係数を返します。
+func (r *adjustmentRepository) SearchAdjustments(ctx context.Context, spec queryspec.Spec) ([]Adjustment, error) {
+	query, args := AdjustmentListSchema.Query(searchAdjustmentsQuery, spec)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []Adjustment
+	for rows.Next() {
+		var row candlessqlc.CandleAdjustment
+		if err := rows.Scan(
+			&row.ID, &row.SymbolCode, &row.EffectiveDate, &row.Factor, &row.Reason, &row.CreatedAt, &row.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, adjustmentFromSQLC(row))
+	}
+	return out, rows.Err()
+}
+
+// CreateAdjustment は調整係数を登録します。
+// 同じ銘柄・効力発生日が登録済みの場合は ErrAdjustmentExists、銘柄が存在しない場合は ErrSymbolNotFound を返します。
+func (r *adjustmentRepository) CreateAdjustment(ctx context.Context, a Adjustment) (Adjustment, error) {
+	row, err := r.q.CreateAdjustment(ctx, candlessqlc.CreateAdjustmentParams{
+		SymbolCode:    a.SymbolCode,
+		EffectiveDate: a.EffectiveDate,
+		Factor:        a.Factor,
+		Reason:        a.Reason,
+	})
+	if err != nil {
+		return Adjustment{}, mapAdjustmentPGErr(err)
+	}
+	return adjustmentFromSQLC(row), nil
+}
+
+// UpdateAdjustment は id の調整係数の効力発生日・係数・理由を更新します（銘柄は変更できません）。
+// 存在しない場合は ErrAdjustmentNotFound、効力発生日が他の調整と重複する場合は ErrAdjustmentExists を返します。
+func (r *adjustmentRepository) UpdateAdjustment(ctx context.Context, id int64, a Adjustment) (Adjustment, error) {
+	row, err := r.q.UpdateAdjustment(ctx, candlessqlc.UpdateAdjustmentParams{
+		EffectiveDate: a.EffectiveDate,
+		Factor:        a.Factor,
+		Reason:        a.Reason,
+		ID:            id,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return Adjustment{}, ErrAdjustmentNotFound
+	}
+	if err != nil {
+		return Adjustment{}, mapAdjustmentPGErr(err)
+	}
+	return adjustmentFromSQLC(row), nil
+}
+
+// DeleteAdjustment は id の調整係数を削除します。存在しない場合は ErrAdjustmentNotFound を返します。
+func (r *adjustmentRepository) DeleteAdjustment(ctx context.Context, id int64) error {
+	n, err := r.q.DeleteAdjustment(ctx, id)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrAdjustmentNotFound
+	}
+	return nil
+}
+
+// mapAdjustmentPGErr は PostgreSQL 制約違反をドメインエラーに変換します。
+func mapAdjustmentPGErr(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgUniqueViolation:
+			return ErrAdjustmentExists
+		case pgForeignKeyViolation:
+			return ErrSymbolNotFound
+		}
+	}
+	return err
+}
+
+func adjustmentFromSQLC(row candlessqlc.CandleAdjustment) Adjustment {
+	return Adjustment{
+		ID:         row.ID,
+		SymbolCode: row.SymbolCode,
+		// DATE 列は UTC の 0 時として読み込まれる。暦日として扱うため UTC に揃える。
+		EffectiveDate: row.EffectiveDate.UTC(),
+		Factor:        row.Factor,
+		Reason:        row.Reason,
+		CreatedAt:     row.CreatedAt,
+		UpdatedAt:     row.UpdatedAt,
+	}
+}
+
+// adjustmentDate は効力発生日を UTC の 0 時（暦日）に正規化します。
+func adjustmentDate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
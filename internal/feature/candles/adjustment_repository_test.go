@@ -0,0 +1,54 @@
+package candles
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdjustmentRepository_CRUD(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewAdjustmentRepository(db)
+	ctx := context.Background()
+
+	split := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	created, err := repo.CreateAdjustment(ctx, Adjustment{SymbolCode: "AAPL", EffectiveDate: split, Factor: 0.25, Reason: "4-for-1 split"})
+	require.NoError(t, err)
+	assert.True(t, created.EffectiveDate.Equal(split))
+	assert.Equal(t, 0.25, created.Factor)
+
+	_, err = repo.CreateAdjustment(ctx, Adjustment{SymbolCode: "AAPL", EffectiveDate: split, Factor: 0.5})
+	assert.ErrorIs(t, err, ErrAdjustmentExists)
+	_, err = repo.CreateAdjustment(ctx, Adjustment{SymbolCode: "UNKNOWN", EffectiveDate: split, Factor: 0.5})
+	assert.ErrorIs(t, err, ErrSymbolNotFound)
+
+	_, err = repo.CreateAdjustment(ctx, Adjustment{SymbolCode: "GOOGL", EffectiveDate: split, Factor: 0.05})
+	require.NoError(t, err)
+
+	aapl, err := repo.ListAdjustments(ctx, "AAPL")
+	require.NoError(t, err)
+	require.Len(t, aapl, 1)
+	all, err := repo.ListAdjustments(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+	searched, err := repo.SearchAdjustments(ctx, listSpec(t, AdjustmentListSchema, "filter[symbol][in]=AAPL,GOOGL&sort=-symbol"))
+	require.NoError(t, err)
+	require.Len(t, searched, 2)
+	assert.Equal(t, "GOOGL", searched[0].SymbolCode)
+
+	updated, err := repo.UpdateAdjustment(ctx, created.ID, Adjustment{EffectiveDate: split.AddDate(0, 0, 1), Factor: 0.5, Reason: "corrected"})
+	require.NoError(t, err)
+	assert.Equal(t, "AAPL", updated.SymbolCode)
+	assert.Equal(t, 0.5, updated.Factor)
+	assert.Equal(t, "corrected", updated.Reason)
+
+	_, err = repo.UpdateAdjustment(ctx, created.ID+1000, Adjustment{EffectiveDate: split, Factor: 1})
+	assert.ErrorIs(t, err, ErrAdjustmentNotFound)
+
+	require.NoError(t, repo.DeleteAdjustment(ctx, created.ID))
+	assert.ErrorIs(t, repo.DeleteAdjustment(ctx, created.ID), ErrAdjustmentNotFound)
+}
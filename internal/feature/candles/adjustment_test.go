@@ -0,0 +1,121 @@
+package candles
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// TestCumulativeFactor は効力発生日より前の足にだけ係数が掛かり、複数の調整が累積することを検証します。
+func TestCumulativeFactor(t *testing.T) {
+	t.Parallel()
+
+	split2 := Adjustment{EffectiveDate: day(2024, 6, 10), Factor: 0.5}    // 2対1分割
+	split3 := Adjustment{EffectiveDate: day(2022, 3, 1), Factor: 1.0 / 3} // 3対1分割
+	reverse := Adjustment{EffectiveDate: day(2023, 1, 5), Factor: 10}     // 1対10併合
+	ny, _ := time.LoadLocation("America/New_York")
+
+	tests := []struct {
+		name string
+		adjs []Adjustment
+		t    time.Time
+		want float64
+	}{
+		{"調整なし", nil, day(2020, 1, 1), 1},
+		{"効力発生日より前", []Adjustment{split2}, day(2024, 6, 7), 0.5},
+		{"効力発生日当日は対象外", []Adjustment{split2}, day(2024, 6, 10), 1},
+		{"効力発生日より後", []Adjustment{split2}, day(2024, 6, 11), 1},
+		{"併合", []Adjustment{reverse}, day(2022, 12, 30), 10},
+		{"複数の分割が累積する", []Adjustment{split2, split3}, day(2021, 1, 4), 0.5 / 3},
+		{"一部の分割のみ対象", []Adjustment{split2, split3}, day(2023, 1, 4), 0.5},
+		{"分割と併合の組み合わせ", []Adjustment{split2, reverse, split3}, day(2022, 1, 3), 0.5 * 10 / 3},
+		{"取引所の暦日で比較する", []Adjustment{split2}, time.Date(2024, 6, 9, 0, 0, 0, 0, ny), 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := CumulativeFactor(tt.adjs, tt.t); !approxEqual(got, tt.want) {
+				t.Errorf("CumulativeFactor = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestApplyAdjustments は価格に係数を掛け、出来高を係数で割り、元のスライスを変更しないことを検証します。
+func TestApplyAdjustments(t *testing.T) {
+	t.Parallel()
+
+	cs := []Candle{
+		{Time: day(2024, 6, 11), Open: 60, High: 62, Low: 59, Close: 61, Volume: 2000},
+		{Time: day(2024, 6, 7), Open: 120, High: 124, Low: 118, Close: 122, Volume: 1000},
+	}
+	got := ApplyAdjustments(cs, []Adjustment{{EffectiveDate: day(2024, 6, 10), Factor: 0.5}})
+
+	if got[0] != cs[0] {
+		t.Errorf("bar after the split must be unchanged: %+v", got[0])
+	}
+	want := Candle{Time: day(2024, 6, 7), Open: 60, High: 62, Low: 59, Close: 61, Volume: 2000}
+	if got[1] != want {
+		t.Errorf("adjusted bar = %+v, want %+v", got[1], want)
+	}
+	if cs[1].Close != 122 || cs[1].Volume != 1000 {
+		t.Errorf("input must not be modified: %+v", cs[1])
+	}
+
+	if got := ApplyAdjustments(cs, nil); len(got) != 2 || got[1] != cs[1] {
+		t.Errorf("no adjustments must return the candles unchanged: %+v", got)
+	}
+}
+
+// TestAdjustmentsVersion は版が順序に依存せず、係数・日付の変更で変わることを検証します。
+func TestAdjustmentsVersion(t *testing.T) {
+	t.Parallel()
+
+	a := Adjustment{EffectiveDate: day(2024, 6, 10), Factor: 0.5}
+	b := Adjustment{EffectiveDate: day(2022, 3, 1), Factor: 0.25}
+
+	if AdjustmentsVersion(nil) != "" {
+		t.Error("no adjustments must have an empty version")
+	}
+	if AdjustmentsVersion([]Adjustment{a, b}) != AdjustmentsVersion([]Adjustment{b, a}) {
+		t.Error("version must not depend on order")
+	}
+	edited := b
+	edited.Factor = 0.2
+	if AdjustmentsVersion([]Adjustment{a, b}) == AdjustmentsVersion([]Adjustment{a, edited}) {
+		t.Error("version must change when a factor is edited")
+	}
+	moved := a
+	moved.EffectiveDate = day(2024, 6, 11)
+	if AdjustmentsVersion([]Adjustment{a}) == AdjustmentsVersion([]Adjustment{moved}) {
+		t.Error("version must change when an effective date is edited")
+	}
+}
+
+func TestAdjustment_Validate(t *testing.T) {
+	t.Parallel()
+
+	valid := Adjustment{EffectiveDate: day(2024, 6, 10), Factor: 0.5}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for name, a := range map[string]Adjustment{
+		"missing date":  {Factor: 0.5},
+		"zero factor":   {EffectiveDate: day(2024, 6, 10)},
+		"negative":      {EffectiveDate: day(2024, 6, 10), Factor: -2},
+		"reason length": {EffectiveDate: day(2024, 6, 10), Factor: 2, Reason: string(make([]rune, MaxAdjustmentReasonLength+1))},
+	} {
+		if err := a.Validate(); !errors.Is(err, ErrInvalidAdjustment) {
+			t.Errorf("%s: err = %v, want ErrInvalidAdjustment", name, err)
+		}
+	}
+}
+
+func approxEqual(a, b float64) bool {
+	d := a - b
+	return d < 1e-12 && d > -1e-12
+}
@@ -0,0 +1,125 @@
+package candles
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/queryspec"
+)
+
+// DefaultAdjustmentListLimit は調整係数一覧の最大返却件数です。
+const DefaultAdjustmentListLimit = 500
+
+// AdjustmentListSchema は調整係数一覧で指定できる絞り込み・並び替えです。既定は銘柄・効力発生日の順です。
+var AdjustmentListSchema = queryspec.Schema{
+	Filters: map[string]queryspec.Field{
+		"symbol":         {Column: "symbol_code", Ops: []queryspec.Op{queryspec.OpEq, queryspec.OpIn}},
+		"effective_date": {Column: "effective_date", Kind: queryspec.KindTime, Ops: []queryspec.Op{queryspec.OpGte, queryspec.OpLte}},
+		"updated_at":     {Column: "updated_at", Kind: queryspec.KindTime, Ops: []queryspec.Op{queryspec.OpGte, queryspec.OpLte}},
+	},
+	Sorts: map[string]string{
+		"symbol":         "symbol_code",
+		"effective_date": "effective_date",
+		"updated_at":     "updated_at",
+	},
+	DefaultSort:  []queryspec.Sort{{Field: "symbol"}, {Field: "effective_date"}},
+	TieBreaker:   "id",
+	DefaultLimit: DefaultAdjustmentListLimit,
+	MaxLimit:     DefaultAdjustmentListLimit,
+}
+
+// AdjustmentSource は読み取り時に適用する銘柄の調整係数を返します。
+// Goの慣例に従い、インターフェースは利用者（usecase）側で定義します。
+type AdjustmentSource interface {
+	// ListAdjustments は銘柄 symbol の調整係数を返します。symbol が空の場合は全銘柄を返します。
+	ListAdjustments(ctx context.Context, symbol string) ([]Adjustment, error)
+}
+
+// AdjustmentRepository は管理者向けの調整係数の登録・変更・削除を抽象化します。
+type AdjustmentRepository interface {
+	AdjustmentSource
+	// SearchAdjustments は AdjustmentListSchema で検証済みの spec に従って調整係数を返します。
+	SearchAdjustments(ctx context.Context, spec queryspec.Spec) ([]Adjustment, error)
+	CreateAdjustment(ctx context.Context, a Adjustment) (Adjustment, error)
+	UpdateAdjustment(ctx context.Context, id int64, a Adjustment) (Adjustment, error)
+	DeleteAdjustment(ctx context.Context, id int64) error
+}
+
+// AdjustmentObserver は調整係数の登録を受け取ります（アラートの閾値の移行など）。
+type AdjustmentObserver interface {
+	AdjustmentCreated(ctx context.Context, a Adjustment) error
+}
+
+// AdjustmentUsecase は分割調整の係数を管理者が登録・変更するためのユースケースです。
+// 調整後のローソク足のキャッシュは係数の内容（AdjustmentsVersion）をキーに含むため、変更時の無効化は不要です。
+type AdjustmentUsecase struct {
+	repo     AdjustmentRepository
+	observer AdjustmentObserver
+}
+
+// NewAdjustmentUsecase は AdjustmentUsecase の新しいインスタンスを生成します。
+func NewAdjustmentUsecase(repo AdjustmentRepository) *AdjustmentUsecase {
+	return &AdjustmentUsecase{repo: repo}
+}
+
+// WithObserver は調整係数の登録を observer に通知します。
+// 通知の失敗は警告ログに記録するだけで、登録は失敗にしません（保存は完了しているため）。
+func (u *AdjustmentUsecase) WithObserver(observer AdjustmentObserver) *AdjustmentUsecase {
+	u.observer = observer
+	return u
+}
+
+// List は q（filter / sort / limit / offset。AdjustmentListSchema 参照）に従って調整係数を返します。
+// 不正な絞り込み・並び替えの場合は queryspec.ErrInvalidQuery を返します。
+// 従来の ?symbol= も filter[symbol] と同じ絞り込みとして受け付けます。
+func (u *AdjustmentUsecase) List(ctx context.Context, q url.Values) ([]Adjustment, error) {
+	spec, err := AdjustmentListSchema.Parse(q)
+	if err != nil {
+		return nil, err
+	}
+	if symbol := strings.TrimSpace(q.Get("symbol")); symbol != "" {
+		if spec, err = AdjustmentListSchema.Where(spec, "symbol", queryspec.OpEq, symbol); err != nil {
+			return nil, err
+		}
+	}
+	return u.repo.SearchAdjustments(ctx, spec)
+}
+
+// Create は調整係数を登録します。値が不正な場合は ErrInvalidAdjustment を返します。
+func (u *AdjustmentUsecase) Create(ctx context.Context, a Adjustment) (Adjustment, error) {
+	a.SymbolCode = strings.TrimSpace(a.SymbolCode)
+	if a.SymbolCode == "" {
+		return Adjustment{}, fmt.Errorf("%w: missing symbol", ErrInvalidAdjustment)
+	}
+	if err := a.Validate(); err != nil {
+		return Adjustment{}, err
+	}
+	a.EffectiveDate = adjustmentDate(a.EffectiveDate)
+	created, err := u.repo.CreateAdjustment(ctx, a)
+	if err != nil {
+		return Adjustment{}, err
+	}
+	if u.observer != nil {
+		if err := u.observer.AdjustmentCreated(ctx, created); err != nil {
+			slog.Warn("adjustment observer failed", "adjustment_id", created.ID, "symbol", created.SymbolCode, "error", err)
+		}
+	}
+	return created, nil
+}
+
+// Update は id の調整係数の効力発生日・係数・理由を置き換えます。値が不正な場合は ErrInvalidAdjustment を返します。
+func (u *AdjustmentUsecase) Update(ctx context.Context, id int64, a Adjustment) (Adjustment, error) {
+	if err := a.Validate(); err != nil {
+		return Adjustment{}, err
+	}
+	a.EffectiveDate = adjustmentDate(a.EffectiveDate)
+	return u.repo.UpdateAdjustment(ctx, id, a)
+}
+
+// Delete は id の調整係数を削除します。
+func (u *AdjustmentUsecase) Delete(ctx context.Context, id int64) error {
+	return u.repo.DeleteAdjustment(ctx, id)
+}
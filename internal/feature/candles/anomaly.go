@@ -0,0 +1,108 @@
+package candles
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+)
+
+// 異常値（前日終値からの急変）の確認状態です。
+const (
+	AnomalyPending          = "pending"            // 未確認
+	AnomalyConfirmedSplit   = "confirmed_split"    // 株式分割と確認済み（調整後の履歴の再取得が必要）
+	AnomalyConfirmedBadData = "confirmed_bad_data" // 外部APIの誤データと確認済み
+)
+
+// DefaultAnomalyThreshold は異常値とみなす前日終値からの変動率のデフォルト値です（30%）。
+const DefaultAnomalyThreshold = 0.3
+
+// Anomaly は ingest で検出した日足終値の急変です。
+// 株式分割や外部APIの誤データが 30% 超の「値動き」としてチャートやアラートを壊すため、
+// 取り込み時に記録して管理者が確認します。
+type Anomaly struct {
+	ID         int64
+	SymbolCode string
+	Time       time.Time // 急変した日足の時刻
+	PrevClose  float64   // 比較対象（直前の日足）の終値
+	Close      float64   // 急変した日足の終値
+	Ratio      float64   // Close / PrevClose
+	Status     string    // AnomalyPending / AnomalyConfirmedSplit / AnomalyConfirmedBadData
+	DetectedAt time.Time
+	ResolvedAt *time.Time
+	// BackfillRequestedAt は分割の確認時に履歴の再取得を要求した日時です。再取得が済むと BackfilledAt が設定されます。
+	BackfillRequestedAt *time.Time
+	BackfilledAt        *time.Time
+}
+
+// AnomalyConfig は ingest での異常値検出の設定です。
+type AnomalyConfig struct {
+	// Threshold は異常値とみなす前日終値からの変動率です（0.3 = 30%）。ちょうど Threshold の変動は異常値に含めません。
+	Threshold float64
+	// Quarantine が true の場合、未確認の異常値がある銘柄の取り込み（Upsert）を見送ります。
+	// false の場合は異常値を記録したうえで通常どおり取り込みます。
+	Quarantine bool
+}
+
+// AnomalyStore は異常値の保存先を抽象化します。
+// Goの慣例に従い、インターフェースは利用者（usecase）側で定義します。
+type AnomalyStore interface {
+	// RecordAnomalies は異常値を保存し、保存後の状態を返します。
+	// 同じ (銘柄, 時刻) が既に記録済みの場合は既存の行（確認状態を含む）を返します。
+	RecordAnomalies(ctx context.Context, anomalies []Anomaly) ([]Anomaly, error)
+}
+
+// LatestCandleReader は保存済みの最新のローソク足を返します。
+// 異常値検出で、新しく取得した日足の比較対象（直前の保存済み終値）に使います。
+type LatestCandleReader interface {
+	// FindLatest は最新のローソク足を返します。データがない場合は nil を返します。
+	FindLatest(ctx context.Context, symbol, interval string) (*Candle, error)
+}
+
+// MoveExceeds は前日終値 prevClose から close への変動率が threshold を超えるかを返します。
+// ratio は close / prevClose です。prevClose が 0 以下の場合は比較できないため anomalous=false を返します。
+// 変動率は |close - prevClose| / prevClose で求め、ちょうど threshold の変動は異常値に含めません。
+func MoveExceeds(prevClose, close, threshold float64) (ratio float64, anomalous bool) {
+	if prevClose <= 0 {
+		return 0, false
+	}
+	return close / prevClose, math.Abs(close-prevClose)/prevClose > threshold
+}
+
+// DetectAnomalies は保存済みの最新の日足 latest より新しい日足 daily について、
+// 直前の終値（最初の 1 本は latest、以降は直前の新しい日足）からの急変を検出します。
+// latest が nil（初回の取り込み）の場合は比較対象がないため検出しません。daily は任意の順序でよい。
+func DetectAnomalies(latest *Candle, daily []Candle, threshold float64) []Anomaly {
+	if latest == nil {
+		return nil
+	}
+	fresh := make([]Candle, 0, len(daily))
+	for _, c := range daily {
+		if c.Time.After(latest.Time) {
+			fresh = append(fresh, c)
+		}
+	}
+	sort.Slice(fresh, func(i, j int) bool { return fresh[i].Time.Before(fresh[j].Time) })
+
+	var out []Anomaly
+	prev := *latest
+	for _, c := range fresh {
+		if ratio, ok := MoveExceeds(prev.Close, c.Close, threshold); ok {
+			out = append(out, Anomaly{
+				SymbolCode: c.SymbolCode,
+				Time:       c.Time,
+				PrevClose:  prev.Close,
+				Close:      c.Close,
+				Ratio:      ratio,
+				Status:     AnomalyPending,
+			})
+		}
+		prev = c
+	}
+	return out
+}
+
+// ValidAnomalyResolution は status が異常値の確認結果として指定可能か（pending 以外の状態か）を返します。
+func ValidAnomalyResolution(status string) bool {
+	return status == AnomalyConfirmedSplit || status == AnomalyConfirmedBadData
+}
@@ -0,0 +1,151 @@
+package candles
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/sqlc"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/queryspec"
+)
+
+// listAnomaliesQuery は異常値一覧の SELECT です。絞り込み・並び替えは AnomalyListSchema で付け加えます。
+// 列の順序は ListAnomalies の Scan と対応させます。
+const listAnomaliesQuery = `SELECT id, symbol_code, "time", prev_close, close, ratio, status, detected_at, resolved_at, backfill_requested_at, backfilled_at
+FROM candle_anomalies`
+
+// anomalyRepository は AnomalyStore / BackfillQueue / AnomalyRepository の sqlc 実装です。
+// 管理用の一覧は絞り込みが動的なため、sqlc を使わず queryspec で組み立てた SQL を実行します。
+type anomalyRepository struct {
+	db *sql.DB
+	q  *candlessqlc.Queries
+}
+
+var (
+	_ AnomalyStore      = (*anomalyRepository)(nil)
+	_ BackfillQueue     = (*anomalyRepository)(nil)
+	_ AnomalyRepository = (*anomalyRepository)(nil)
+)
+
+// NewAnomalyRepository は指定された *sql.DB で anomalyRepository の新しいインスタンスを生成します。
+func NewAnomalyRepository(db *sql.DB) *anomalyRepository {
+	return &anomalyRepository{db: db, q: candlessqlc.New(db)}
+}
+
+// RecordAnomalies は異常値を 1 件ずつ保存し、保存後の状態（記録済みの場合は既存の行）を返します。
+// 1 銘柄あたりの異常値は通常 0〜1 件のため、バッチ化はしていません。
+func (r *anomalyRepository) RecordAnomalies(ctx context.Context, anomalies []Anomaly) ([]Anomaly, error) {
+	out := make([]Anomaly, 0, len(anomalies))
+	for _, a := range anomalies {
+		row, err := r.q.RecordAnomaly(ctx, candlessqlc.RecordAnomalyParams{
+			SymbolCode: a.SymbolCode,
+			Time:       a.Time,
+			PrevClose:  a.PrevClose,
+			Close:      a.Close,
+			Ratio:      a.Ratio,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("record anomaly %s %s: %w", a.SymbolCode, a.Time.Format(time.DateOnly), err)
+		}
+		out = append(out, anomalyFromSQLC(row))
+	}
+	return out, nil
+}
+
+// ListAnomalies は spec の絞り込み・並び替え・ページングに従って異常値を返します。
+func (r *anomalyRepository) ListAnomalies(ctx context.Context, spec queryspec.Spec) ([]Anomaly, error) {
+	query, args := AnomalyListSchema.Query(listAnomaliesQuery, spec)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []Anomaly
+	for rows.Next() {
+		var row candlessqlc.CandleAnomaly
+		if err := rows.Scan(
+			&row.ID, &row.SymbolCode, &row.Time, &row.PrevClose, &row.Close, &row.Ratio, &row.Status,
+			&row.DetectedAt, &row.ResolvedAt, &row.BackfillRequestedAt, &row.BackfilledAt,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, anomalyFromSQLC(row))
+	}
+	return out, rows.Err()
+}
+
+// ResolveAnomaly は異常値の確認結果を記録します。backfill が true の場合は履歴の再取得を要求します。
+// 指定IDの異常値が存在しない場合は ErrAnomalyNotFound を返します。
+func (r *anomalyRepository) ResolveAnomaly(ctx context.Context, id int64, status string, backfill bool, at time.Time) (Anomaly, error) {
+	row, err := r.q.ResolveAnomaly(ctx, candlessqlc.ResolveAnomalyParams{
+		Status:     status,
+		ResolvedAt: sql.NullTime{Time: at, Valid: true},
+		Backfill:   backfill,
+		ID:         id,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return Anomaly{}, ErrAnomalyNotFound
+	}
+	if err != nil {
+		return Anomaly{}, err
+	}
+	return anomalyFromSQLC(row), nil
+}
+
+// ListBackfillRequests は再取得が要求され、まだ完了していない異常値を要求順に返します。
+func (r *anomalyRepository) ListBackfillRequests(ctx context.Context) ([]Anomaly, error) {
+	rows, err := r.q.ListBackfillRequests(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return anomaliesFromSQLC(rows), nil
+}
+
+// MarkBackfilled は異常値の再取得の完了を記録します。
+func (r *anomalyRepository) MarkBackfilled(ctx context.Context, ids []int64, at time.Time) error {
+	for _, id := range ids {
+		if err := r.q.MarkAnomalyBackfilled(ctx, candlessqlc.MarkAnomalyBackfilledParams{
+			ID:           id,
+			BackfilledAt: sql.NullTime{Time: at, Valid: true},
+		}); err != nil {
+			return fmt.Errorf("mark anomaly %d backfilled: %w", id, err)
+		}
+	}
+	return nil
+}
+
+func anomaliesFromSQLC(rows []candlessqlc.CandleAnomaly) []Anomaly {
+	out := make([]Anomaly, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, anomalyFromSQLC(row))
+	}
+	return out
+}
+
+func anomalyFromSQLC(row candlessqlc.CandleAnomaly) Anomaly {
+	return Anomaly{
+		ID:                  row.ID,
+		SymbolCode:          row.SymbolCode,
+		Time:                row.Time,
+		PrevClose:           row.PrevClose,
+		Close:               row.Close,
+		Ratio:               row.Ratio,
+		Status:              row.Status,
+		DetectedAt:          row.DetectedAt,
+		ResolvedAt:          nullTimePtr(row.ResolvedAt),
+		BackfillRequestedAt: nullTimePtr(row.BackfillRequestedAt),
+		BackfilledAt:        nullTimePtr(row.BackfilledAt),
+	}
+}
+
+// nullTimePtr は sql.NullTime を *time.Time に変換します（NULL は nil）。
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	v := t.Time
+	return &v
+}
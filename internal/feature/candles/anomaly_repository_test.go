@@ -0,0 +1,116 @@
+package candles
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/queryspec"
+)
+
+func TestAnomalyRepository_RecordResolveBackfill(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewAnomalyRepository(db)
+	ctx := context.Background()
+
+	day := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	detected := Anomaly{SymbolCode: "AAPL", Time: day, PrevClose: 180, Close: 90, Ratio: 0.5}
+
+	got, err := repo.RecordAnomalies(ctx, []Anomaly{detected})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	id := got[0].ID
+	assert.Equal(t, AnomalyPending, got[0].Status)
+	assert.Nil(t, got[0].ResolvedAt)
+
+	// 確認済みの異常値を再検出しても状態は保持される。
+	resolvedAt := day.Add(48 * time.Hour)
+	resolved, err := repo.ResolveAnomaly(ctx, id, AnomalyConfirmedSplit, true, resolvedAt)
+	require.NoError(t, err)
+	assert.Equal(t, AnomalyConfirmedSplit, resolved.Status)
+	require.NotNil(t, resolved.BackfillRequestedAt)
+	assert.True(t, resolved.BackfillRequestedAt.Equal(resolvedAt))
+
+	got, err = repo.RecordAnomalies(ctx, []Anomaly{detected})
+	require.NoError(t, err)
+	assert.Equal(t, id, got[0].ID)
+	assert.Equal(t, AnomalyConfirmedSplit, got[0].Status)
+
+	pending, err := repo.ListAnomalies(ctx, listSpec(t, AnomalyListSchema, "filter[status]=pending"))
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+	all, err := repo.ListAnomalies(ctx, listSpec(t, AnomalyListSchema, ""))
+	require.NoError(t, err)
+	assert.Len(t, all, 1)
+
+	reqs, err := repo.ListBackfillRequests(ctx)
+	require.NoError(t, err)
+	require.Len(t, reqs, 1)
+	require.NoError(t, repo.MarkBackfilled(ctx, []int64{id}, resolvedAt.Add(time.Hour)))
+	reqs, err = repo.ListBackfillRequests(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, reqs)
+
+	_, err = repo.ResolveAnomaly(ctx, id+1000, AnomalyConfirmedBadData, false, resolvedAt)
+	assert.ErrorIs(t, err, ErrAnomalyNotFound)
+}
+
+// listSpec は raw をクエリパラメータとして schema で解釈した Spec を返します。
+func listSpec(t *testing.T, schema queryspec.Schema, raw string) queryspec.Spec {
+	t.Helper()
+	q, err := url.ParseQuery(raw)
+	require.NoError(t, err)
+	spec, err := schema.Parse(q)
+	require.NoError(t, err)
+	return spec
+}
+
+// TestAnomalyRepository_ListFilterAndPaging は絞り込み・並び替えを SQL で評価し、
+// 並び替えのキーが同値でもページ間で行が重複・欠落しないことを検証します。
+func TestAnomalyRepository_ListFilterAndPaging(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewAnomalyRepository(db)
+	ctx := context.Background()
+
+	// 同じ ratio の異常値を複数記録し、ratio だけでは順序が決まらない状態にする
+	var detected []Anomaly
+	for i := range 5 {
+		sym := "AAPL"
+		if i%2 == 1 {
+			sym = "GOOGL"
+		}
+		detected = append(detected, Anomaly{
+			SymbolCode: sym, Time: time.Date(2024, 1, 10+i, 0, 0, 0, 0, time.UTC),
+			PrevClose: 100, Close: 50, Ratio: 0.5,
+		})
+	}
+	_, err := repo.RecordAnomalies(ctx, detected)
+	require.NoError(t, err)
+
+	googl, err := repo.ListAnomalies(ctx, listSpec(t, AnomalyListSchema, "filter[symbol]=GOOGL&sort=time"))
+	require.NoError(t, err)
+	require.Len(t, googl, 2)
+	assert.True(t, googl[0].Time.Before(googl[1].Time))
+
+	ranged, err := repo.ListAnomalies(ctx, listSpec(t, AnomalyListSchema, "filter[time][gte]=2024-01-11&filter[time][lte]=2024-01-13"))
+	require.NoError(t, err)
+	assert.Len(t, ranged, 3)
+
+	seen := map[int64]bool{}
+	for offset := 0; offset < len(detected); offset += 2 {
+		page, err := repo.ListAnomalies(ctx, listSpec(t, AnomalyListSchema, "sort=-ratio&limit=2&offset="+strconv.Itoa(offset)))
+		require.NoError(t, err)
+		for _, a := range page {
+			assert.False(t, seen[a.ID], "anomaly %d appeared on two pages", a.ID)
+			seen[a.ID] = true
+		}
+	}
+	assert.Len(t, seen, len(detected))
+}
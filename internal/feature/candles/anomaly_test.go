@@ -0,0 +1,326 @@
+package candles
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/queryspec"
+)
+
+// TestMoveExceeds は変動率としきい値の境界を検証します。ちょうどしきい値の変動は異常値に含めません。
+func TestMoveExceeds(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		prev, close   float64
+		wantAnomalous bool
+		wantRatio     float64
+	}{
+		{"unchanged", 100, 100, false, 1},
+		{"exactly +30%", 100, 130, false, 1.3},
+		{"exactly -30%", 100, 70, false, 0.7},
+		{"just above +30%", 100, 130.01, true, 1.3001},
+		{"just below -30%", 100, 69.99, true, 0.6999},
+		{"2-for-1 split", 180, 90, true, 0.5},
+		{"bad tick spike", 100, 1000, true, 10},
+		{"no previous close", 0, 100, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ratio, anomalous := MoveExceeds(tt.prev, tt.close, DefaultAnomalyThreshold)
+			if anomalous != tt.wantAnomalous {
+				t.Errorf("anomalous = %v, want %v", anomalous, tt.wantAnomalous)
+			}
+			if math.Abs(ratio-tt.wantRatio) > 1e-9 {
+				t.Errorf("ratio = %v, want %v", ratio, tt.wantRatio)
+			}
+		})
+	}
+}
+
+// TestDetectAnomalies は保存済みの最新の日足より新しい日足だけを、直前の終値と比較することを検証します。
+func TestDetectAnomalies(t *testing.T) {
+	t.Parallel()
+
+	day := func(d int) time.Time { return time.Date(2026, 6, d, 0, 0, 0, 0, time.UTC) }
+	latest := &Candle{SymbolCode: "AAPL", Time: day(1), Close: 200}
+
+	t.Run("初回の取り込みは検出しない", func(t *testing.T) {
+		t.Parallel()
+		got := DetectAnomalies(nil, []Candle{{Time: day(2), Close: 1}}, DefaultAnomalyThreshold)
+		if len(got) != 0 {
+			t.Errorf("got %d anomalies, want 0", len(got))
+		}
+	})
+
+	t.Run("新しい日足を時刻順に比較する", func(t *testing.T) {
+		t.Parallel()
+		// API は最新順で返す。保存済み以前の日足（急変していても）は比較対象外。
+		daily := []Candle{
+			{SymbolCode: "AAPL", Time: day(4), Close: 101},
+			{SymbolCode: "AAPL", Time: day(3), Close: 100},
+			{SymbolCode: "AAPL", Time: day(2), Close: 100}, // 200 → 100: 分割
+			{SymbolCode: "AAPL", Time: day(1), Close: 200},
+			{SymbolCode: "AAPL", Time: time.Date(2026, 5, 29, 0, 0, 0, 0, time.UTC), Close: 1},
+		}
+		got := DetectAnomalies(latest, daily, DefaultAnomalyThreshold)
+		if len(got) != 1 {
+			t.Fatalf("got %d anomalies, want 1: %+v", len(got), got)
+		}
+		a := got[0]
+		if !a.Time.Equal(day(2)) || a.PrevClose != 200 || a.Close != 100 || a.Ratio != 0.5 || a.Status != AnomalyPending {
+			t.Errorf("unexpected anomaly: %+v", a)
+		}
+	})
+
+	t.Run("誤データの急騰と反落はそれぞれ検出する", func(t *testing.T) {
+		t.Parallel()
+		daily := []Candle{
+			{SymbolCode: "AAPL", Time: day(2), Close: 2000},
+			{SymbolCode: "AAPL", Time: day(3), Close: 201},
+		}
+		got := DetectAnomalies(latest, daily, DefaultAnomalyThreshold)
+		if len(got) != 2 {
+			t.Fatalf("got %d anomalies, want 2", len(got))
+		}
+		if got[1].PrevClose != 2000 {
+			t.Errorf("second anomaly should compare against the preceding new bar, got prev %v", got[1].PrevClose)
+		}
+	})
+}
+
+// fakeAnomalyStore は記録済みの状態を保持する AnomalyStore / BackfillQueue です。
+type fakeAnomalyStore struct {
+	status     map[int64]string // 時刻(Unix) → 既存の確認状態
+	recorded   []Anomaly
+	requests   []Anomaly
+	backfilled []int64
+}
+
+func (f *fakeAnomalyStore) RecordAnomalies(_ context.Context, anomalies []Anomaly) ([]Anomaly, error) {
+	out := make([]Anomaly, 0, len(anomalies))
+	for _, a := range anomalies {
+		if s, ok := f.status[a.Time.Unix()]; ok {
+			a.Status = s
+		}
+		f.recorded = append(f.recorded, a)
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+func (f *fakeAnomalyStore) ListBackfillRequests(context.Context) ([]Anomaly, error) {
+	return f.requests, nil
+}
+
+func (f *fakeAnomalyStore) MarkBackfilled(_ context.Context, ids []int64, _ time.Time) error {
+	f.backfilled = append(f.backfilled, ids...)
+	return nil
+}
+
+// fakeLatestReader は固定の最新ローソク足を返す LatestCandleReader です。
+type fakeLatestReader struct{ latest *Candle }
+
+func (f fakeLatestReader) FindLatest(context.Context, string, string) (*Candle, error) {
+	return f.latest, nil
+}
+
+// TestIngestUsecase_ScreenAnomalies は異常値の記録・隔離・誤データの除外を検証します。
+func TestIngestUsecase_ScreenAnomalies(t *testing.T) {
+	t.Parallel()
+
+	prev := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	spike := prev.AddDate(0, 0, 1)
+	market := &mockMarketRepository{
+		GetTimeSeriesFunc: func(context.Context, string, string, int, *time.Location) ([]Candle, error) {
+			return []Candle{
+				{Time: spike, Open: 100, High: 1000, Low: 100, Close: 1000},
+				{Time: prev, Open: 100, High: 100, Low: 100, Close: 100},
+			}, nil
+		},
+	}
+	latest := fakeLatestReader{latest: &Candle{Time: prev, Close: 100}}
+
+	tests := []struct {
+		name         string
+		existing     string // 既存の確認状態（空なら新規）
+		quarantine   bool
+		wantErr      error
+		wantUpserted bool
+		wantSpike    bool // 急変した日足が保存対象に含まれるか
+	}{
+		{name: "記録して取り込む", wantUpserted: true, wantSpike: true},
+		{name: "隔離モードは取り込まない", quarantine: true, wantErr: ErrQuarantined},
+		{name: "確認済みの分割は隔離しない", existing: AnomalyConfirmedSplit, quarantine: true, wantUpserted: true, wantSpike: true},
+		{name: "誤データと確認済みの日足は除外する", existing: AnomalyConfirmedBadData, wantUpserted: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := &fakeAnomalyStore{status: map[int64]string{}}
+			if tt.existing != "" {
+				store.status[spike.Unix()] = tt.existing
+			}
+			var upserted []Candle
+			repo := &mockWriteRepository{UpsertBatchFunc: func(_ context.Context, cs []Candle) error {
+				upserted = cs
+				return nil
+			}}
+			uc := NewIngestUsecase(market, repo, nil, &mockRateLimiter{}, &mockFreshnessWriter{}).
+				WithAnomalyDetection(store, latest, AnomalyConfig{Threshold: DefaultAnomalyThreshold, Quarantine: tt.quarantine})
+
+			_, err := uc.ingestOne(context.Background(), ActiveSymbol{Code: "AAPL", Timezone: "UTC"}, 5000, true)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if len(store.recorded) != 1 {
+				t.Errorf("recorded %d anomalies, want 1", len(store.recorded))
+			}
+			if (upserted != nil) != tt.wantUpserted {
+				t.Fatalf("upserted = %v, want %v", upserted != nil, tt.wantUpserted)
+			}
+			hasSpike := false
+			for _, c := range upserted {
+				if c.Interval == "1day" && c.Time.Equal(spike) {
+					hasSpike = true
+				}
+			}
+			if hasSpike != tt.wantSpike {
+				t.Errorf("spike upserted = %v, want %v", hasSpike, tt.wantSpike)
+			}
+		})
+	}
+}
+
+// TestIngestUsecase_Backfill は要求された銘柄を 1 回だけ再取得し、異常値検出を行わずに完了を記録することを検証します。
+func TestIngestUsecase_Backfill(t *testing.T) {
+	t.Parallel()
+
+	market := &mockMarketRepository{
+		GetTimeSeriesFunc: func(context.Context, string, string, int, *time.Location) ([]Candle, error) {
+			return []Candle{{Time: time.Date(2026, 6, 2, 0, 0, 0, 0, time.UTC), Open: 1, High: 1, Low: 1, Close: 1}}, nil
+		},
+	}
+	symbols := &mockSymbolRepository{ListActiveSymbolsFunc: func(context.Context) ([]ActiveSymbol, error) {
+		return []ActiveSymbol{{Code: "AAPL", Timezone: "UTC"}}, nil
+	}}
+	store := &fakeAnomalyStore{requests: []Anomaly{
+		{ID: 1, SymbolCode: "AAPL"},
+		{ID: 2, SymbolCode: "AAPL"},
+		{ID: 3, SymbolCode: "DELISTED"},
+	}}
+	repo := &mockWriteRepository{UpsertBatchFunc: func(context.Context, []Candle) error { return nil }}
+	uc := NewIngestUsecase(market, repo, symbols, &mockRateLimiter{}, &mockFreshnessWriter{}).
+		WithAnomalyDetection(store, fakeLatestReader{latest: &Candle{Close: 100}}, AnomalyConfig{Threshold: DefaultAnomalyThreshold, Quarantine: true})
+
+	result, err := uc.Backfill(context.Background(), store)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := result.FailedSymbols(); !reflect.DeepEqual(got, []string{"DELISTED"}) {
+		t.Errorf("FailedSymbols() = %v, want [DELISTED]", got)
+	}
+	result.Errors = nil
+	if !reflect.DeepEqual(result, IngestResult{Total: 2, Succeeded: 1, Failed: 1}) {
+		t.Errorf("result = %+v", result)
+	}
+	if market.GetTimeSeriesCalls != 1 {
+		t.Errorf("GetTimeSeries calls = %d, want 1", market.GetTimeSeriesCalls)
+	}
+	if len(store.recorded) != 0 {
+		t.Errorf("backfill must not screen anomalies, recorded %d", len(store.recorded))
+	}
+	if len(store.backfilled) != 2 || store.backfilled[0] != 1 || store.backfilled[1] != 2 {
+		t.Errorf("backfilled = %v, want [1 2]", store.backfilled)
+	}
+}
+
+// stubAnomalyRepository は呼び出し引数を記録する AnomalyRepository です。
+type stubAnomalyRepository struct {
+	gotSpec     queryspec.Spec
+	gotStatus   string
+	gotBackfill bool
+	calls       int
+}
+
+func (s *stubAnomalyRepository) ListAnomalies(_ context.Context, spec queryspec.Spec) ([]Anomaly, error) {
+	s.calls++
+	s.gotSpec = spec
+	return nil, nil
+}
+
+func (s *stubAnomalyRepository) ResolveAnomaly(_ context.Context, id int64, status string, backfill bool, _ time.Time) (Anomaly, error) {
+	s.calls++
+	s.gotStatus, s.gotBackfill = status, backfill
+	return Anomaly{ID: id, Status: status}, nil
+}
+
+// TestAnomalyUsecase_Validation は状態の検証と、再取得は分割の確認時のみ指定できることを検証します。
+func TestAnomalyUsecase_Validation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		status   string
+		backfill bool
+		wantErr  error
+	}{
+		{"分割と再取得", AnomalyConfirmedSplit, true, nil},
+		{"誤データ", AnomalyConfirmedBadData, false, nil},
+		{"誤データの再取得は不可", AnomalyConfirmedBadData, true, ErrBackfillRequiresSplit},
+		{"pending には戻せない", AnomalyPending, false, ErrInvalidAnomalyStatus},
+		{"不明な状態", "ignored", false, ErrInvalidAnomalyStatus},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repo := &stubAnomalyRepository{}
+			_, err := NewAnomalyUsecase(repo).Resolve(context.Background(), 1, tt.status, tt.backfill)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if wantCalls := map[bool]int{true: 1, false: 0}[tt.wantErr == nil]; repo.calls != wantCalls {
+				t.Errorf("repo calls = %d, want %d", repo.calls, wantCalls)
+			}
+		})
+	}
+
+	t.Run("List は不明な状態を拒否する", func(t *testing.T) {
+		t.Parallel()
+		repo := &stubAnomalyRepository{}
+		if _, err := NewAnomalyUsecase(repo).List(context.Background(), url.Values{"status": {"bogus"}}); !errors.Is(err, ErrInvalidAnomalyStatus) {
+			t.Fatalf("err = %v", err)
+		}
+		if _, err := NewAnomalyUsecase(repo).List(context.Background(), url.Values{"filter[status]": {"bogus"}}); !errors.Is(err, queryspec.ErrInvalidQuery) {
+			t.Fatalf("err = %v", err)
+		}
+		if _, err := NewAnomalyUsecase(repo).List(context.Background(), url.Values{}); err != nil {
+			t.Fatalf("err = %v", err)
+		}
+		if repo.gotSpec.Limit != DefaultAnomalyListLimit {
+			t.Errorf("limit = %d, want %d", repo.gotSpec.Limit, DefaultAnomalyListLimit)
+		}
+	})
+
+	t.Run("List は従来の status を filter[status] として渡す", func(t *testing.T) {
+		t.Parallel()
+		repo := &stubAnomalyRepository{}
+		if _, err := NewAnomalyUsecase(repo).List(context.Background(), url.Values{"status": {AnomalyPending}}); err != nil {
+			t.Fatalf("err = %v", err)
+		}
+		want := []queryspec.Condition{{Field: "status", Op: queryspec.OpEq, Values: []any{AnomalyPending}}}
+		if !reflect.DeepEqual(repo.gotSpec.Conditions, want) {
+			t.Errorf("conditions = %+v, want %+v", repo.gotSpec.Conditions, want)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package candles
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/queryspec"
+)
+
+// DefaultAnomalyListLimit は異常値一覧の最大返却件数です。
+const DefaultAnomalyListLimit = 200
+
+// AnomalyListSchema は異常値一覧で指定できる絞り込み・並び替えです。既定は検出日時の新しい順です。
+var AnomalyListSchema = queryspec.Schema{
+	Filters: map[string]queryspec.Field{
+		"symbol": {Column: "symbol_code", Ops: []queryspec.Op{queryspec.OpEq, queryspec.OpIn}},
+		"status": {
+			Column: "status",
+			Ops:    []queryspec.Op{queryspec.OpEq, queryspec.OpIn},
+			Enum:   []string{AnomalyPending, AnomalyConfirmedSplit, AnomalyConfirmedBadData},
+		},
+		"time":        {Column: `"time"`, Kind: queryspec.KindTime, Ops: []queryspec.Op{queryspec.OpGte, queryspec.OpLte}},
+		"detected_at": {Column: "detected_at", Kind: queryspec.KindTime, Ops: []queryspec.Op{queryspec.OpGte, queryspec.OpLte}},
+	},
+	Sorts: map[string]string{
+		"detected_at": "detected_at",
+		"time":        `"time"`,
+		"symbol":      "symbol_code",
+		"ratio":       "ratio",
+	},
+	DefaultSort:  []queryspec.Sort{{Field: "detected_at", Desc: true}},
+	TieBreaker:   "id",
+	DefaultLimit: DefaultAnomalyListLimit,
+	MaxLimit:     DefaultAnomalyListLimit,
+}
+
+// AnomalyRepository は管理者向けの異常値の参照・確認を抽象化します。
+// Goの慣例に従い、インターフェースは利用者（usecase）側で定義します。
+type AnomalyRepository interface {
+	// ListAnomalies は AnomalyListSchema で検証済みの spec に従って異常値を返します。
+	ListAnomalies(ctx context.Context, spec queryspec.Spec) ([]Anomaly, error)
+	ResolveAnomaly(ctx context.Context, id int64, status string, backfill bool, at time.Time) (Anomaly, error)
+}
+
+// AnomalyUsecase は ingest で検出した異常値を管理者が確認するためのユースケースです。
+type AnomalyUsecase struct {
+	repo AnomalyRepository
+	now  func() time.Time
+}
+
+// NewAnomalyUsecase は AnomalyUsecase の新しいインスタンスを生成します。
+func NewAnomalyUsecase(repo AnomalyRepository) *AnomalyUsecase {
+	return &AnomalyUsecase{repo: repo, now: time.Now}
+}
+
+// List は q（filter / sort / limit / offset。AnomalyListSchema 参照）に従って異常値を返します。
+// 不正な絞り込み・並び替えの場合は queryspec.ErrInvalidQuery を返します。
+// 従来の ?status= も filter[status] と同じ絞り込みとして受け付け、不明な status の場合は ErrInvalidAnomalyStatus を返します。
+func (u *AnomalyUsecase) List(ctx context.Context, q url.Values) ([]Anomaly, error) {
+	spec, err := AnomalyListSchema.Parse(q)
+	if err != nil {
+		return nil, err
+	}
+	if status := q.Get("status"); status != "" {
+		if status != AnomalyPending && !ValidAnomalyResolution(status) {
+			return nil, ErrInvalidAnomalyStatus
+		}
+		if spec, err = AnomalyListSchema.Where(spec, "status", queryspec.OpEq, status); err != nil {
+			return nil, err
+		}
+	}
+	return u.repo.ListAnomalies(ctx, spec)
+}
+
+// Resolve は異常値の確認結果（confirmed_split / confirmed_bad_data）を記録します。
+// backfill が true の場合は履歴の再取得を要求し、次回の backfill バッチで分割調整後の履歴を取り込みます。
+// 再取得は confirmed_split の場合のみ指定でき、それ以外は ErrBackfillRequiresSplit を返します。
+func (u *AnomalyUsecase) Resolve(ctx context.Context, id int64, status string, backfill bool) (Anomaly, error) {
+	if !ValidAnomalyResolution(status) {
+		return Anomaly{}, ErrInvalidAnomalyStatus
+	}
+	if backfill && status != AnomalyConfirmedSplit {
+		return Anomaly{}, ErrBackfillRequiresSplit
+	}
+	return u.repo.ResolveAnomaly(ctx, id, status, backfill, u.now())
+}
@@ -0,0 +1,86 @@
+package candles
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// BackfillQueue は株式分割の確認時に要求された、銘柄の履歴の再取得を管理します。
+// Goの慣例に従い、インターフェースは利用者（usecase）側で定義します。
+type BackfillQueue interface {
+	// ListBackfillRequests は再取得が要求され、まだ完了していない異常値を返します。
+	ListBackfillRequests(ctx context.Context) ([]Anomaly, error)
+	// MarkBackfilled は異常値の再取得の完了を記録します。
+	MarkBackfilled(ctx context.Context, ids []int64, at time.Time) error
+}
+
+// Backfill は queue で要求された銘柄の履歴（日足・週足・月足）を外部APIから再取得し、分割調整後の値で上書きします。
+// 管理者が分割と確認済みのため、異常値検出・隔離は行いません。
+// 1 銘柄に複数の要求がある場合もまとめて 1 回だけ取得します。
+// アクティブでない銘柄の要求は失敗として数え、完了を記録しません。
+func (iu *IngestUsecase) Backfill(ctx context.Context, queue BackfillQueue) (IngestResult, error) {
+	reqs, err := queue.ListBackfillRequests(ctx)
+	if err != nil {
+		return IngestResult{}, fmt.Errorf("list backfill requests: %w", err)
+	}
+	if len(reqs) == 0 {
+		return IngestResult{}, nil
+	}
+	symbols, err := iu.symbol.ListActiveSymbols(ctx)
+	if err != nil {
+		return IngestResult{}, err
+	}
+	active := make(map[string]ActiveSymbol, len(symbols))
+	for _, s := range symbols {
+		active[s.Code] = s
+	}
+
+	// 要求順を保ったまま銘柄ごとに異常値IDをまとめる
+	var codes []string
+	ids := make(map[string][]int64)
+	for _, r := range reqs {
+		if _, ok := ids[r.SymbolCode]; !ok {
+			codes = append(codes, r.SymbolCode)
+		}
+		ids[r.SymbolCode] = append(ids[r.SymbolCode], r.ID)
+	}
+
+	result := IngestResult{Total: len(codes)}
+	for _, code := range codes {
+		if err := ctx.Err(); err != nil {
+			result.Aborted = result.Total - result.Processed()
+			return result, err
+		}
+		sym, ok := active[code]
+		if !ok {
+			slog.Error("backfill skipped: symbol is not active", "symbol", code)
+			result.fail(code, fmt.Errorf("symbol %s is not active", code))
+			continue
+		}
+		if err := iu.rateLimiter.WaitIfNeeded(ctx); err != nil {
+			result.Aborted = result.Total - result.Processed()
+			return result, err
+		}
+		stats, err := iu.ingestOne(ctx, sym, iu.fetchOutputSize(), false)
+		if err != nil {
+			if isContextAbort(ctx, err) {
+				result.Aborted = result.Total - result.Processed()
+				return result, err
+			}
+			slog.Error("failed to backfill data", "symbol", code, "error", err)
+			result.fail(code, err)
+			continue
+		}
+		if err := queue.MarkBackfilled(ctx, ids[code], iu.now()); err != nil {
+			// 再取得自体は完了しているため、次回の再実行で同じ銘柄を取り直すだけで済む
+			slog.Error("failed to mark backfill done", "symbol", code, "error", err)
+			result.fail(code, err)
+			continue
+		}
+		result.Succeeded++
+		result.addStats(stats)
+	}
+	return result, nil
+}
@@ -0,0 +1,130 @@
+package candles
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// DefaultCacheBuckets はキャッシュの件数の区切りの推奨値です（CANDLES_CACHE_BUCKETS の例）。
+// 既定（未設定）では区切らず、MaxOutputSize 件の 1 エントリだけを保持します。
+var DefaultCacheBuckets = []int{50, 100, 200, 500, 1000, MaxOutputSize}
+
+// ParseCacheBuckets はキャッシュの件数の区切りのカンマ区切り（例: "50,100,200,500,1000,5000"）を解釈します。
+// 値は 1 以上 MaxOutputSize 以下の整数で、昇順に並べて重複を除き、MaxOutputSize がなければ末尾に加えます
+// （区切りを超える要求も最大のエントリで返せるように）。空文字列は nil（区切らない）を返します。
+func ParseCacheBuckets(s string) ([]int, error) {
+	var buckets []int
+	for item := range strings.SplitSeq(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		n, err := strconv.Atoi(item)
+		if err != nil || n <= 0 || n > MaxOutputSize {
+			return nil, fmt.Errorf("invalid cache bucket %q: want an integer in [1, %d]", item, MaxOutputSize)
+		}
+		buckets = append(buckets, n)
+	}
+	if len(buckets) == 0 {
+		return nil, nil
+	}
+	slices.Sort(buckets)
+	buckets = slices.Compact(buckets)
+	if buckets[len(buckets)-1] != MaxOutputSize {
+		buckets = append(buckets, MaxOutputSize)
+	}
+	return buckets, nil
+}
+
+// CacheStats はローソク足キャッシュ（Find）の累計です。
+type CacheStats struct {
+	LocalHits uint64 // プロセス内キャッシュ（L1）から返した読み取り
+	Hits      uint64 // Redis のキャッシュ（L2）から返した読み取り
+	Misses    uint64 // DB から読んだ読み取り
+	// SlicedHits は要求件数より大きい区切りのエントリを切り詰めて返したヒットです。
+	// 件数ごとにエントリを分けていれば、その件数を初めて要求したときにミスしていた読み取りの上限にあたります。
+	SlicedHits uint64
+	// LocalEvictions は上限（エントリ数・推定サイズ）によりプロセス内キャッシュから追い出したエントリです。
+	LocalEvictions uint64
+}
+
+// HitRate はヒット率（L1 と L2 のヒットの合計の割合。0〜1）を返します。読み取りがない場合は 0 です。
+func (s CacheStats) HitRate() float64 {
+	hits := s.LocalHits + s.Hits
+	total := hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(hits) / float64(total)
+}
+
+// cacheCounters は CacheStats の集計です。
+type cacheCounters struct {
+	localHits, hits, misses, slicedHits atomic.Uint64
+}
+
+// WithCacheBuckets はキャッシュのエントリを件数の区切り（ParseCacheBuckets の結果）ごとに分けます。
+//
+// 既定では要求件数によらず MaxOutputSize 件の 1 エントリを読み書きするため、30 本の要求でも
+// ミスのたびに最大 5000 本を DB から読み、ヒットのたびに全体を転送・デコードします。区切りを設定すると、
+// 要求件数以上で最小の区切りの件数だけを読み書きし、先頭 outputsize 件を返します（30・90・100 本の要求は
+// 100 本のエントリを共有します）。区切りの件数を先頭から切り詰められるのは、基盤リポジトリが
+// 新しい順（time DESC、(symbol, interval, time) は一意）で決定的に返すためです。
+//
+// MaxOutputSize の区切りは従来と同じキーを使うため、未設定（nil）の場合の挙動とキーは変わりません。
+// as-of クエリ（FindAsOf）は区切りに関わらずキャッシュを経由しません。
+func (c *CachingRepository) WithCacheBuckets(buckets []int) *CachingRepository {
+	c.buckets = buckets
+	return c
+}
+
+// CacheStats はキャッシュ（Find）の累計を返します。
+func (c *CachingRepository) CacheStats() CacheStats {
+	stats := CacheStats{
+		LocalHits:  c.stats.localHits.Load(),
+		Hits:       c.stats.hits.Load(),
+		Misses:     c.stats.misses.Load(),
+		SlicedHits: c.stats.slicedHits.Load(),
+	}
+	if c.local != nil {
+		stats.LocalEvictions = c.local.evictions.Load()
+	}
+	return stats
+}
+
+// bucketFor は outputsize 件の要求に使うエントリの件数（要求件数以上で最小の区切り）を返します。
+// 区切りが未設定、outputsize が 0 以下（全件）、または最大の区切りを超える場合は MaxOutputSize です。
+func (c *CachingRepository) bucketFor(outputsize int) int {
+	if outputsize <= 0 {
+		return MaxOutputSize
+	}
+	for _, b := range c.buckets {
+		if outputsize <= b {
+			return b
+		}
+	}
+	return MaxOutputSize
+}
+
+// bucketKey は symbol+interval の件数 size のエントリのキーを返します。MaxOutputSize は従来の cacheKey です。
+func (c *CachingRepository) bucketKey(symbol, interval string, size int) string {
+	key := c.cacheKey(symbol, interval)
+	if size == MaxOutputSize {
+		return key
+	}
+	return key + ":n" + strconv.Itoa(size)
+}
+
+// entryKeys は symbol+interval のすべてのキャッシュキー（区切りごとのエントリ・調整後・スパークライン・期間指定）を返します。
+func (c *CachingRepository) entryKeys(symbol, interval string) []string {
+	keys := []string{c.cacheKey(symbol, interval)}
+	for _, b := range c.buckets {
+		if b != MaxOutputSize {
+			keys = append(keys, c.bucketKey(symbol, interval, b))
+		}
+	}
+	return append(keys, c.adjustedCacheKey(symbol, interval), c.sparklineCacheKey(symbol, interval), c.rangeCacheKey(symbol, interval))
+}
@@ -0,0 +1,180 @@
+package candles
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/testsupport"
+)
+
+func TestParseCacheBuckets(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   string
+		want []int
+	}{
+		{"", nil},
+		{" , ", nil},
+		{"50,100,200,500,1000,5000", DefaultCacheBuckets},
+		{"200, 50,100,50", []int{50, 100, 200, MaxOutputSize}},
+		{"5000", []int{MaxOutputSize}},
+	}
+	for _, tt := range tests {
+		got, err := ParseCacheBuckets(tt.in)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+
+	for _, in := range []string{"0", "-50", "abc", "100,5001"} {
+		_, err := ParseCacheBuckets(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestCachingRepository_BucketFor(t *testing.T) {
+	t.Parallel()
+
+	c := NewCachingRepository(nil, 0, &mockReadWriteRepository{}, "", nil).WithCacheBuckets(DefaultCacheBuckets)
+	tests := []struct{ outputsize, want int }{
+		{1, 50},
+		{50, 50},
+		{51, 100},
+		{100, 100},
+		{200, 200},
+		{201, 500},
+		{1001, MaxOutputSize},
+		{MaxOutputSize, MaxOutputSize},
+		{MaxOutputSize + 1, MaxOutputSize},
+		{0, MaxOutputSize}, // 全件
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, c.bucketFor(tt.outputsize), "outputsize=%d", tt.outputsize)
+	}
+
+	unbucketed := NewCachingRepository(nil, 0, &mockReadWriteRepository{}, "", nil)
+	assert.Equal(t, MaxOutputSize, unbucketed.bucketFor(30), "区切りなしは常に全データのエントリ")
+}
+
+// newestFirst は base から 1 日ずつ遡る n 本の日足を新しい順に返します（Find の並び順）。
+func newestFirst(n int) []Candle {
+	base := time.Date(2026, 5, 12, 0, 0, 0, 0, time.UTC)
+	cs := make([]Candle, n)
+	for i := range cs {
+		cs[i] = Candle{SymbolCode: "AAPL", Interval: "1day", Time: base.AddDate(0, 0, -i), Close: float64(1000 - i)}
+	}
+	return cs
+}
+
+// countingInner は保存済みの足を新しい順に outputsize 件返し、要求された件数を記録する readWriteRepository です。
+type countingInner struct {
+	mockReadWriteRepository
+	stored    []Candle
+	requested []int
+}
+
+func newCountingInner(stored []Candle) *countingInner {
+	in := &countingInner{stored: stored}
+	in.findFn = func(_ context.Context, _, _ string, outputsize int) ([]Candle, error) {
+		in.requested = append(in.requested, outputsize)
+		return slicesHead(in.stored, outputsize), nil
+	}
+	return in
+}
+
+// slicesHead は cs の先頭 n 件のコピーを返します。
+func slicesHead(cs []Candle, n int) []Candle {
+	return append([]Candle(nil), cs[:min(n, len(cs))]...)
+}
+
+func TestCachingRepository_Find_Buckets(t *testing.T) {
+	t.Parallel()
+
+	mr, rdb := testsupport.NewMiniRedis(t)
+	inner := newCountingInner(newestFirst(150))
+	c := NewCachingRepository(rdb, time.Hour, inner, "candles", nil).WithCacheBuckets(DefaultCacheBuckets)
+	ctx := context.Background()
+
+	// 60・90・100 本の要求は 100 本のエントリを共有し、DB は 1 回だけ読む
+	for _, n := range []int{90, 60, 100} {
+		got, err := c.Find(ctx, "AAPL", "1day", n)
+		require.NoError(t, err)
+		assert.Equal(t, newestFirst(150)[:n], got, "先頭 %d 件（新しい順）", n)
+	}
+	assert.Equal(t, []int{100}, inner.requested)
+	assert.True(t, mr.Exists("candles:AAPL:1day:n100"))
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 1, SlicedHits: 1}, c.CacheStats(),
+		"100 本の要求は切り詰めないヒット")
+
+	// 区切りより足が少ない場合はある分だけ返す
+	got, err := c.Find(ctx, "AAPL", "1day", 180)
+	require.NoError(t, err)
+	assert.Len(t, got, 150)
+	assert.Equal(t, []int{100, 200}, inner.requested)
+
+	// 全件（0）と最大の区切りは従来のキー
+	_, err = c.Find(ctx, "AAPL", "1day", 0)
+	require.NoError(t, err)
+	assert.True(t, mr.Exists("candles:AAPL:1day"))
+	assert.InDelta(t, 2.0/5, c.CacheStats().HitRate(), 1e-9)
+}
+
+func TestCachingRepository_Buckets_UpsertAndInvalidate(t *testing.T) {
+	t.Parallel()
+
+	mr, rdb := testsupport.NewMiniRedis(t)
+	inner := newCountingInner(newestFirst(120))
+	c := NewCachingRepository(rdb, time.Hour, inner, "candles", nil).WithCacheBuckets([]int{50, 100, MaxOutputSize})
+	ctx := context.Background()
+
+	// write-through は全データを 1 回読み、区切りごとのエントリを先頭から作る
+	_, err := c.UpsertBatch(ctx, newestFirst(1))
+	require.NoError(t, err)
+	assert.Equal(t, []int{MaxOutputSize}, inner.requested)
+	for _, key := range []string{"candles:AAPL:1day", "candles:AAPL:1day:n50", "candles:AAPL:1day:n100"} {
+		assert.True(t, mr.Exists(key), key)
+	}
+
+	got, err := c.Find(ctx, "AAPL", "1day", 40)
+	require.NoError(t, err)
+	assert.Equal(t, newestFirst(120)[:40], got)
+	assert.Equal(t, []int{MaxOutputSize}, inner.requested, "ウォームアップしたエントリにヒットする")
+
+	require.NoError(t, c.Invalidate(ctx, "AAPL", "1day"))
+	assert.Empty(t, mr.Keys(), "区切りごとのエントリも削除する")
+}
+
+func TestCachingRepository_Buckets_AsOfBypassesCache(t *testing.T) {
+	t.Parallel()
+
+	mr, rdb := testsupport.NewMiniRedis(t)
+	inner := &asOfReadWriteRepository{}
+	c := NewCachingRepository(rdb, time.Hour, inner, "candles", nil).WithCacheBuckets(DefaultCacheBuckets)
+
+	_, err := c.FindAsOf(context.Background(), "AAPL", "1day", time.Now(), 30)
+	require.NoError(t, err)
+	assert.Equal(t, 1, inner.asOfCalls)
+	assert.Empty(t, mr.Keys(), "as-of クエリは区切りのエントリを読み書きしない")
+	assert.Zero(t, c.CacheStats())
+}
+
+// TestSliceCandles_Copy は切り詰めた結果を書き換え・append しても元のデータに影響しないことを検証します。
+func TestSliceCandles_Copy(t *testing.T) {
+	t.Parallel()
+
+	all := newestFirst(5)
+	got := sliceCandles(all, 3)
+	require.Len(t, got, 3)
+	got[0].Close = -1
+	_ = append(got, Candle{Close: -2})
+	assert.Equal(t, newestFirst(5), all)
+
+	assert.Len(t, sliceCandles(all, 5), 5, "境界: 件数ちょうど")
+	assert.Len(t, sliceCandles(all, 6), 5, "境界: 件数超過")
+	assert.Len(t, sliceCandles(all, 0), 5, "0 は全件")
+	assert.Len(t, sliceCandles(all, 1), 1)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -15,24 +16,74 @@ import (
 // この値はフォールバックとしてのみ機能する。
 const DefaultCacheTTL = 7 * 24 * time.Hour
 
+// DefaultNegativeCacheTTL は 0 件の結果をキャッシュする期間です（FlagNegativeCache 有効時のみ）。
+// 新規銘柄の初回 ingest 前など、空の結果が長く残り続けないよう短くしています。
+const DefaultNegativeCacheTTL = 1 * time.Minute
+
+// MaxRangeCacheFields は期間指定の結果を保存するハッシュ 1 つ（銘柄・時間足ごと）に置くフィールドの上限です。
+// フィールドはクライアントが選ぶ期間・件数の組み合わせで決まるため、上限に達した後は新しい期間を保存せず、
+// ハッシュは最後の書き込みから TTL で消えます（同時の書き込みにより上限をわずかに超えることはあります）。
+const MaxRangeCacheFields = 64
+
+// キャッシュ層の挙動を段階的に切り替えるフィーチャーフラグ名です。
+const (
+	// FlagFetchThrough はキャッシュミス時に DB から取得した結果をキャッシュへ書き込みます。
+	FlagFetchThrough = "fetch_through"
+	// FlagWriteThrough は UpsertBatch（ingest・CSV 取り込み）後に最新データでキャッシュを再生成します。
+	// 無効時は該当キーの削除のみを行い、次回の読み取りで再生成されます。
+	FlagWriteThrough = "write_through"
+	// FlagNegativeCache は 0 件の結果を DefaultNegativeCacheTTL の間キャッシュします。
+	FlagNegativeCache = "negative_cache"
+)
+
+// FlagDefault はフラグ未注入時に使う既定値を返します（既存の挙動を維持する値）。
+func FlagDefault(name string) bool {
+	switch name {
+	case FlagFetchThrough, FlagWriteThrough:
+		return true
+	default:
+		return false
+	}
+}
+
+// FlagChecker はフィーチャーフラグの参照を抽象化します。
+// Goの慣例に従い、インターフェースは利用者側で定義します。
+type FlagChecker interface {
+	Enabled(ctx context.Context, name string) bool
+}
+
 // readWriteRepository はCachingRepositoryが内部で必要とする読み書きインターフェースです。
 type readWriteRepository interface {
 	Repository      // usecase.go（Find）
 	WriteRepository // ingest.go（UpsertBatch）
 }
 
+// RedisProvider は現在利用できる Redis クライアントを返します。無効（未接続・障害中）の間は nil を返します。
+// Goの慣例に従い、インターフェースは利用者側で定義します（infra/redis の CacheState が実装します）。
+type RedisProvider interface {
+	Client() *redis.Client
+}
+
 // CachingRepository はRepositoryにRedisキャッシュをデコレータパターンで追加します。
 // 基盤となるリポジトリを変更せずに、透過的にキャッシュを追加します。
 type CachingRepository struct {
 	inner     readWriteRepository
 	rdb       *redis.Client
+	provider  RedisProvider // 設定時は rdb より優先する（WithRedisProvider 参照）
 	ttl       time.Duration
 	namespace string
+	flags     FlagChecker
+	refresh   *refreshAhead       // nil の場合は先行再取得を行わない（WithRefreshAhead 参照）
+	buckets   []int               // 件数の区切り（昇順）。nil の場合は MaxOutputSize 件の 1 エントリ（WithCacheBuckets 参照）
+	local     *localCache         // nil の場合はプロセス内キャッシュを使わない（WithLocalCache 参照）
+	market    MarketHealthChecker // nil の場合は市場データ連携の状態によらず fetch-through を行う（WithMarketHealth 参照）
+	stats     cacheCounters
 }
 
 // NewCachingRepository はRepositoryにRedisキャッシュを追加するデコレータを生成します。
 // ttlが0の場合はデフォルト5分、namespaceが空の場合は"candles"を使用します。
-func NewCachingRepository(rdb *redis.Client, ttl time.Duration, inner readWriteRepository, namespace string) *CachingRepository {
+// flagsがnilの場合は各フラグを FlagDefault の値として扱います。
+func NewCachingRepository(rdb *redis.Client, ttl time.Duration, inner readWriteRepository, namespace string, flags FlagChecker) *CachingRepository {
 	if ttl <= 0 {
 		ttl = 5 * time.Minute
 	}
@@ -44,21 +95,49 @@ func NewCachingRepository(rdb *redis.Client, ttl time.Duration, inner readWriteR
 		rdb:       rdb,
 		ttl:       ttl,
 		namespace: namespace,
+		flags:     flags,
 	}
 }
 
+// WithRedisProvider は Redis クライアントをリクエストごとに p から取得するよう設定します。
+// p が nil を返す間（Redis 障害中）は NewCachingRepository に nil を渡した場合と同じくキャッシュを使いません。
+func (c *CachingRepository) WithRedisProvider(p RedisProvider) *CachingRepository {
+	c.provider = p
+	return c
+}
+
+// MarketHealthChecker は市場データ連携の状態を返します（MarketHealthMonitor / MarketHealthTracker が実装）。
+type MarketHealthChecker interface {
+	MarketHealth(ctx context.Context) MarketHealth
+}
+
+// WithMarketHealth は市場データ連携が down の間、フラグ fetch_through によらずキャッシュミス時の書き込みを止めます。
+// 上流が止まっている間は ingest が DB を更新できず、その間に読んだ結果をキャッシュすると回復後も TTL の間古い値が残るためです。
+// キャッシュ・DB からの応答自体は続けます。
+func (c *CachingRepository) WithMarketHealth(m MarketHealthChecker) *CachingRepository {
+	c.market = m
+	return c
+}
+
+// client は現在利用できる Redis クライアントを返します。nil の場合はキャッシュを使いません。
+// 処理の途中で状態が切り替わっても nil 参照しないよう、各メソッドの先頭で 1 回だけ取得します。
+func (c *CachingRepository) client() *redis.Client {
+	if c.provider != nil {
+		return c.provider.Client()
+	}
+	return c.rdb
+}
+
 // UpsertBatch はローソク足データを挿入または更新し、キャッシュを最新データで更新します。
-func (c *CachingRepository) UpsertBatch(ctx context.Context, candles []Candle) error {
+// 行数は基盤リポジトリの結果をそのまま返します。
+func (c *CachingRepository) UpsertBatch(ctx context.Context, candles []Candle) (UpsertStats, error) {
 	// まず基盤リポジトリにUpsert
-	if err := c.inner.UpsertBatch(ctx, candles); err != nil {
-		return err
-	}
-	// Redisが未設定またはデータがない場合は早期リターン
-	if c.rdb == nil || len(candles) == 0 {
-		return nil
+	stats, err := c.inner.UpsertBatch(ctx, candles)
+	if err != nil {
+		return UpsertStats{}, err
 	}
 
-	// 影響を受ける symbol+interval を収集
+	// 影響を受ける symbol+interval を収集し、プロセス内キャッシュから削除
 	type symbolInterval struct {
 		symbol   string
 		interval string
@@ -67,63 +146,332 @@ func (c *CachingRepository) UpsertBatch(ctx context.Context, candles []Candle) e
 	for _, cd := range candles {
 		seen[symbolInterval{cd.SymbolCode, cd.Interval}] = struct{}{}
 	}
+	for si := range seen {
+		c.InvalidateLocal(si.symbol, si.interval)
+	}
+	// Redisが未設定またはデータがない場合は早期リターン
+	rdb := c.client()
+	if rdb == nil || len(seen) == 0 {
+		return stats, nil
+	}
 
-	// 各 symbol+interval のキャッシュを削除し、最新データで再生成（ウォームアップ）
+	// 各 symbol+interval のキャッシュを削除し、write-through 有効時は最新データで再生成（ウォームアップ）
+	writeThrough := c.enabled(ctx, FlagWriteThrough)
 	for si := range seen {
-		key := c.cacheKey(si.symbol, si.interval)
-		_ = c.rdb.Del(ctx, key).Err() // ベストエフォート
+		// 調整後・スパークライン・期間指定のキャッシュは版・点数・期間ごとにあるため、再生成せずハッシュごと削除する
+		_ = rdb.Del(ctx, c.entryKeys(si.symbol, si.interval)...).Err() // ベストエフォート
+		if !writeThrough {
+			continue
+		}
 
 		data, err := c.inner.Find(ctx, si.symbol, si.interval, MaxOutputSize)
 		if err != nil {
 			continue // ベストエフォート: エラー時はウォームアップをスキップ
 		}
-		if b, err := json.Marshal(data); err == nil {
-			_ = c.rdb.Set(ctx, key, b, c.ttl).Err() // ベストエフォート
+		// 区切りごとのエントリは全データの先頭を切り詰めて作る（DB の読み取りは 1 回）
+		c.store(ctx, rdb, c.cacheKey(si.symbol, si.interval), data)
+		for _, b := range c.buckets {
+			if b != MaxOutputSize {
+				c.store(ctx, rdb, c.bucketKey(si.symbol, si.interval, b), data[:min(b, len(data))])
+			}
 		}
 	}
-	return nil
+	return stats, nil
+}
+
+// Invalidate は symbol+interval のキャッシュ（区切りごとのエントリ・調整後・スパークライン・期間指定のハッシュを含む）を削除します。
+// DB の行を UpsertBatch 以外の経路で変更した後に呼びます。プロセス内キャッシュも削除します。
+func (c *CachingRepository) Invalidate(ctx context.Context, symbol, interval string) error {
+	c.InvalidateLocal(symbol, interval)
+	rdb := c.client()
+	if rdb == nil {
+		return nil
+	}
+	return rdb.Del(ctx, c.entryKeys(symbol, interval)...).Err()
 }
 
 // Find はローソク足データを取得します。まずキャッシュを確認し、なければデータベースにフォールバックします。
-// キャッシュには全データ（最大MaxOutputSize件）を保存し、outputsize件にスライスして返します。
+// キャッシュには区切りの件数（既定は全データ、最大MaxOutputSize件。WithCacheBuckets 参照）を保存し、
+// outputsize件にスライスして返します。プロセス内キャッシュが有効な場合は Redis より先に確認します（WithLocalCache 参照）。
+// ctx に WithPreferCache のヒントがある場合は DB を読む前に大きい区切りのエントリも確認します。
 func (c *CachingRepository) Find(ctx context.Context, symbol, interval string, outputsize int) ([]Candle, error) {
-	// Redisが未設定の場合はキャッシュをバイパス
-	if c.rdb == nil {
+	// Redisもプロセス内キャッシュも未設定の場合はキャッシュをバイパス
+	rdb := c.client()
+	tr := TraceFromContext(ctx)
+	if rdb == nil && c.local == nil {
+		tr.SetSource(TraceDB, "")
 		return c.inner.Find(ctx, symbol, interval, outputsize)
 	}
 
-	key := c.cacheKey(symbol, interval)
+	size := c.bucketFor(outputsize)
+	group := c.cacheKey(symbol, interval)
+	key := c.bucketKey(symbol, interval, size)
+
+	// 0) プロセス内キャッシュを確認（デコード済みのため、切り出したコピーを返すだけ）
+	if all, ok := c.local.get(group, key); ok {
+		tr.SetSource(TraceL1, key)
+		c.stats.localHits.Add(1)
+		if outputsize > 0 && outputsize < len(all) {
+			c.stats.slicedHits.Add(1)
+		}
+		return copyCandles(all, outputsize), nil
+	}
+
+	// 1) Redisのキャッシュを確認（期限が近いヒットは現在の値を返しつつ先行再取得する）
+	if rdb != nil {
+		start := tr.Begin()
+		b, remaining, err := c.getWithTTL(ctx, rdb, key)
+		tr.End(TraceCacheGet, start)
+		if err == nil && len(b) > 0 {
+			var all []Candle
+			if err := json.Unmarshal(b, &all); err == nil {
+				tr.SetSource(TraceRedis, key)
+				if len(all) > 0 && !preferCache(ctx) {
+					c.maybeRefresh(ctx, rdb, key, symbol, interval, size, remaining)
+				}
+				c.stats.hits.Add(1)
+				if outputsize > 0 && outputsize < len(all) {
+					c.stats.slicedHits.Add(1)
+				}
+				c.storeLocal(ctx, group, key, all)
+				return sliceCandles(all, outputsize), nil
+			}
+			// 破損したキャッシュエントリを削除
+			_ = rdb.Del(ctx, key).Err()
+		}
+		// キャッシュ優先のヒントがあれば、より大きい区切りのエントリを切り詰めて返す（WithPreferCache）
+		if preferCache(ctx) {
+			if all, larger, ok := c.findLargerBucket(ctx, rdb, symbol, interval, size); ok {
+				tr.SetSource(TraceRedis, larger)
+				c.stats.hits.Add(1)
+				c.stats.slicedHits.Add(1)
+				return sliceCandles(all, outputsize), nil
+			}
+		}
+	}
+
+	// 2) データベースにフォールバック（区切りの件数を取得してキャッシュに保存）
+	c.stats.misses.Add(1)
+	tr.SetSource(TraceDB, key)
+	all, err := c.inner.Find(ctx, symbol, interval, size)
+	if err != nil {
+		return nil, err
+	}
+	c.storeLocal(ctx, group, key, all)
+
+	// 3) fetch-through 有効時はキャッシュに保存（ベストエフォート）
+	if rdb != nil && c.enabled(ctx, FlagFetchThrough) {
+		c.store(ctx, rdb, key, all)
+	}
+
+	return sliceCandles(all, outputsize), nil
+}
+
+// FindAsOf は as-of クエリをキャッシュを経由せず基盤リポジトリに委譲します。
+// キャッシュは最新の値のみを保持するため、過去時点の結果をキャッシュから返すことはできません。
+func (c *CachingRepository) FindAsOf(ctx context.Context, symbol, interval string, asOf time.Time, outputsize int) ([]Candle, error) {
+	f, ok := c.inner.(AsOfFinder)
+	if !ok {
+		return nil, errAsOfUnsupported
+	}
+	return f.FindAsOf(ctx, symbol, interval, asOf, outputsize)
+}
+
+// FindRange は期間指定の読み取りの結果を Redis ハッシュ（フィールド: 開始日・終了日・件数）にキャッシュして返します。
+// 期間の指定ごとに別のフィールドに保存するため、期間を指定しない Find のエントリとは衝突しません。
+// 新しい足は UpsertBatch でハッシュごと削除されるため、保存した範囲に後から足が増えても古い結果は残りません。
+// ハッシュのフィールドが MaxRangeCacheFields に達している間は、新しい期間の結果を保存せずに返します。
+func (c *CachingRepository) FindRange(ctx context.Context, symbol, interval string, rng DateRange, outputsize int) ([]Candle, error) {
+	rdb := c.client()
+	tr := TraceFromContext(ctx)
+	if rdb == nil {
+		tr.SetSource(TraceDB, "")
+		return findRange(ctx, c.inner, symbol, interval, rng, outputsize)
+	}
+
+	key := c.rangeCacheKey(symbol, interval)
+	field := rng.cacheField(outputsize)
+	start := tr.Begin()
+	b, err := rdb.HGet(ctx, key, field).Bytes()
+	tr.End(TraceCacheGet, start)
+	if err == nil && len(b) > 0 {
+		var cs []Candle
+		if err := json.Unmarshal(b, &cs); err == nil {
+			tr.SetSource(TraceRedis, key)
+			return cs, nil
+		}
+		_ = rdb.HDel(ctx, key, field).Err()
+	}
+
+	tr.SetSource(TraceDB, key)
+	cs, err := findRange(ctx, c.inner, symbol, interval, rng, outputsize)
+	if err != nil {
+		return nil, err
+	}
+	if len(cs) > 0 && c.enabled(ctx, FlagFetchThrough) {
+		if n, err := rdb.HLen(ctx, key).Result(); err != nil || n >= MaxRangeCacheFields {
+			return cs, nil
+		}
+		if b, err := json.Marshal(cs); err == nil {
+			pipe := rdb.TxPipeline()
+			pipe.HSet(ctx, key, field, b)
+			pipe.Expire(ctx, key, c.ttl)
+			_, _ = pipe.Exec(ctx) // ベストエフォート
+		}
+	}
+	return cs, nil
+}
+
+// FindEach は走査をキャッシュを経由せず基盤リポジトリに委譲します。
+// キャッシュのエントリは最大 MaxOutputSize 件の全体を 1 つの値として保持するため、ページごとに読み込む走査には
+// 使えず、走査の結果をキャッシュに書き込むと表示用の Find のエントリを長い期間の走査で追い出すためです。
+// 基盤リポジトリが EachFinder を実装しない場合は Find の結果を渡します。
+func (c *CachingRepository) FindEach(ctx context.Context, opts FindOptions, fn func(Candle) error) error {
+	return findEach(ctx, c.inner, opts, fn)
+}
 
-	// 1) キャッシュを確認
-	if b, err := c.rdb.Get(ctx, key).Bytes(); err == nil && len(b) > 0 {
+// FindAdjusted は調整係数 adjs を適用したローソク足を返します。
+// 調整後の全データ（最大MaxOutputSize件）を Redis ハッシュ（フィールド: AdjustmentsVersion）にキャッシュするため、
+// 調整係数の変更は別フィールドとして扱われ、UpsertBatch ではハッシュごと削除されます。
+func (c *CachingRepository) FindAdjusted(ctx context.Context, symbol, interval string, outputsize int, adjs []Adjustment) ([]Candle, error) {
+	rdb := c.client()
+	if rdb == nil {
+		cs, err := c.inner.Find(ctx, symbol, interval, outputsize)
+		if err != nil {
+			return nil, err
+		}
+		return ApplyAdjustments(cs, adjs), nil
+	}
+
+	key := c.adjustedCacheKey(symbol, interval)
+	version := AdjustmentsVersion(adjs)
+	tr := TraceFromContext(ctx)
+	start := tr.Begin()
+	b, err := rdb.HGet(ctx, key, version).Bytes()
+	tr.End(TraceCacheGet, start)
+	if err == nil && len(b) > 0 {
 		var all []Candle
 		if err := json.Unmarshal(b, &all); err == nil {
+			tr.SetSource(TraceRedis, key)
 			return sliceCandles(all, outputsize), nil
 		}
-		// 破損したキャッシュエントリを削除
-		_ = c.rdb.Del(ctx, key).Err()
+		_ = rdb.HDel(ctx, key, version).Err()
 	}
 
-	// 2) データベースにフォールバック（全データ取得してキャッシュに保存）
-	all, err := c.inner.Find(ctx, symbol, interval, MaxOutputSize)
+	// 未調整の全データはキャッシュ経由で取得する
+	raw, err := c.Find(ctx, symbol, interval, MaxOutputSize)
 	if err != nil {
 		return nil, err
 	}
+	all := ApplyAdjustments(raw, adjs)
+	if len(all) > 0 && c.enabled(ctx, FlagFetchThrough) {
+		if b, err := json.Marshal(all); err == nil {
+			pipe := rdb.TxPipeline()
+			pipe.HSet(ctx, key, version, b)
+			pipe.Expire(ctx, key, c.ttl)
+			_, _ = pipe.Exec(ctx) // ベストエフォート
+		}
+	}
+	return sliceCandles(all, outputsize), nil
+}
 
-	// 3) キャッシュに保存（ベストエフォート）
-	if b, err := json.Marshal(all); err == nil {
-		_ = c.rdb.Set(ctx, key, b, c.ttl).Err()
+// FindSparkline は最新 outputsize 件のローソク足（adjs があれば調整後）を最大 points 点に間引いたスパークラインを返します。
+// 生成結果は Redis ハッシュ（フィールド: outputsize・points・調整係数の版）に TTL（既定 7 日）でキャッシュします。
+// 新しい足は ingest の UpsertBatch（データ鮮度マーカーの更新と同じ実行）でハッシュごと削除されるため、
+// TTL を長くしても古いスパークラインは残りません。
+func (c *CachingRepository) FindSparkline(ctx context.Context, symbol, interval string, outputsize, points int, adjs []Adjustment) (Sparkline, error) {
+	find := func() ([]Candle, error) {
+		if len(adjs) == 0 {
+			return c.Find(ctx, symbol, interval, outputsize)
+		}
+		return c.FindAdjusted(ctx, symbol, interval, outputsize, adjs)
+	}
+	rdb := c.client()
+	if rdb == nil {
+		cs, err := find()
+		if err != nil {
+			return Sparkline{}, err
+		}
+		return NewSparkline(cs, points), nil
 	}
 
-	return sliceCandles(all, outputsize), nil
+	key := c.sparklineCacheKey(symbol, interval)
+	field := fmt.Sprintf("%d:%d:%s", outputsize, points, AdjustmentsVersion(adjs))
+	if b, err := rdb.HGet(ctx, key, field).Bytes(); err == nil && len(b) > 0 {
+		var s Sparkline
+		if err := json.Unmarshal(b, &s); err == nil {
+			return s, nil
+		}
+		_ = rdb.HDel(ctx, key, field).Err()
+	}
+
+	cs, err := find()
+	if err != nil {
+		return Sparkline{}, err
+	}
+	s := NewSparkline(cs, points)
+	if len(s.Closes) > 0 && c.enabled(ctx, FlagFetchThrough) {
+		if b, err := json.Marshal(s); err == nil {
+			pipe := rdb.TxPipeline()
+			pipe.HSet(ctx, key, field, b)
+			pipe.Expire(ctx, key, c.ttl)
+			_, _ = pipe.Exec(ctx) // ベストエフォート
+		}
+	}
+	return s, nil
+}
+
+// store はデータをキャッシュに保存します（ベストエフォート）。
+// 0 件の結果は negative caching 有効時のみ、短い TTL で保存します。
+func (c *CachingRepository) store(ctx context.Context, rdb *redis.Client, key string, data []Candle) {
+	ttl := c.ttl
+	if len(data) == 0 {
+		if !c.enabled(ctx, FlagNegativeCache) {
+			return
+		}
+		ttl = min(ttl, DefaultNegativeCacheTTL)
+	}
+	if b, err := json.Marshal(data); err == nil {
+		_ = rdb.Set(ctx, key, b, ttl).Err()
+	}
+}
+
+// storeLocal はデータをプロセス内キャッシュに保存します。0 件の結果は negative caching 有効時のみ保存します。
+func (c *CachingRepository) storeLocal(ctx context.Context, group, key string, data []Candle) {
+	if c.local == nil || (len(data) == 0 && !c.enabled(ctx, FlagNegativeCache)) {
+		return
+	}
+	c.local.set(group, key, data)
+}
+
+// enabled はフラグの値を返します。flags 未注入時は FlagDefault を使います。
+func (c *CachingRepository) enabled(ctx context.Context, name string) bool {
+	if name == FlagFetchThrough && c.market != nil && c.market.MarketHealth(ctx) == MarketHealthDown {
+		return false
+	}
+	if c.flags == nil {
+		return FlagDefault(name)
+	}
+	return c.flags.Enabled(ctx, name)
 }
 
 // sliceCandles は全ローソク足データから先頭 outputsize 件を返します。
+// 切り詰める場合はコピーを返すため、呼び出し元が結果を書き換え・append しても all の残りには影響しません。
 func sliceCandles(all []Candle, outputsize int) []Candle {
 	if outputsize <= 0 || outputsize >= len(all) {
 		return all
 	}
-	return all[:outputsize]
+	return slices.Clone(all[:outputsize])
+}
+
+// copyCandles は all の先頭 outputsize 件（0 以下の場合は全件）のコピーを返します。
+// プロセス内キャッシュのエントリを共有したまま返すと、呼び出し元の書き換えが他のリクエストに見えるためです。
+func copyCandles(all []Candle, outputsize int) []Candle {
+	n := len(all)
+	if outputsize > 0 {
+		n = min(n, outputsize)
+	}
+	return slices.Clone(all[:n])
 }
 
 // cacheKey はキャッシュキーを生成します。
@@ -135,6 +483,21 @@ func (c *CachingRepository) cacheKey(symbol, interval string) string {
 	)
 }
 
+// adjustedCacheKey は調整後のローソク足を保存するハッシュのキーを生成します。
+func (c *CachingRepository) adjustedCacheKey(symbol, interval string) string {
+	return c.cacheKey(symbol, interval) + ":adjusted"
+}
+
+// sparklineCacheKey はスパークラインを保存するハッシュのキーを生成します。
+func (c *CachingRepository) sparklineCacheKey(symbol, interval string) string {
+	return c.cacheKey(symbol, interval) + ":sparkline"
+}
+
+// rangeCacheKey は期間指定の読み取りの結果を保存するハッシュのキーを生成します。
+func (c *CachingRepository) rangeCacheKey(symbol, interval string) string {
+	return c.cacheKey(symbol, interval) + ":range"
+}
+
 // safeCacheKey はRedisキーで問題となる文字をエスケープします。
 func safeCacheKey(s string) string {
 	s = strings.ReplaceAll(s, " ", "_")
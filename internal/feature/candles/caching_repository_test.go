@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"reflect"
 	"testing"
 	"time"
 
 	"github.com/go-redis/redismock/v9"
+	"github.com/redis/go-redis/v9"
 )
 
 // mockReadWriteRepository はテスト用の readWriteRepository（読み書き）モック実装です。
@@ -25,11 +27,14 @@ func (m *mockReadWriteRepository) Find(ctx context.Context, symbol, interval str
 }
 
 // UpsertBatch はモックのUpsertBatch関数を呼び出します。
-func (m *mockReadWriteRepository) UpsertBatch(ctx context.Context, candles []Candle) error {
+// 成功時は全件を新規挿入として数えます。
+func (m *mockReadWriteRepository) UpsertBatch(ctx context.Context, candles []Candle) (UpsertStats, error) {
 	if m.upsertBatchFn != nil {
-		return m.upsertBatchFn(ctx, candles)
+		if err := m.upsertBatchFn(ctx, candles); err != nil {
+			return UpsertStats{}, err
+		}
 	}
-	return nil
+	return UpsertStats{Inserted: int64(len(candles))}, nil
 }
 
 // TestNewCachingCandleRepository_Defaults はデフォルト値（TTLとnamespace）が正しく設定されることを検証します。
@@ -70,7 +75,7 @@ func TestNewCachingCandleRepository_Defaults(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			repo := NewCachingRepository(nil, tt.ttl, &mockReadWriteRepository{}, tt.namespace)
+			repo := NewCachingRepository(nil, tt.ttl, &mockReadWriteRepository{}, tt.namespace, nil)
 
 			if repo.ttl != tt.expectedTTL {
 				t.Errorf("expected TTL %v, got %v", tt.expectedTTL, repo.ttl)
@@ -97,7 +102,7 @@ func TestCachingCandleRepository_Find_NilRedis(t *testing.T) {
 	}
 
 	// Redis is nil - should bypass cache and call inner directly
-	repo := NewCachingRepository(nil, 5*time.Minute, inner, "candles")
+	repo := NewCachingRepository(nil, 5*time.Minute, inner, "candles", nil)
 
 	candles, err := repo.Find(context.Background(), "AAPL", "1day", 100)
 	if err != nil {
@@ -131,7 +136,7 @@ func TestCachingCandleRepository_Find_CacheHit(t *testing.T) {
 		},
 	}
 
-	repo := NewCachingRepository(rdb, 5*time.Minute, inner, "candles")
+	repo := NewCachingRepository(rdb, 5*time.Minute, inner, "candles", nil)
 	candles, err := repo.Find(context.Background(), "AAPL", "1day", 100)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -167,7 +172,7 @@ func TestCachingCandleRepository_Find_CacheHit_Slices(t *testing.T) {
 	mock.ExpectGet("candles:AAPL:1day").SetVal(string(cachedJSON))
 
 	inner := &mockReadWriteRepository{}
-	repo := NewCachingRepository(rdb, 5*time.Minute, inner, "candles")
+	repo := NewCachingRepository(rdb, 5*time.Minute, inner, "candles", nil)
 
 	// outputsize=3 を指定 → 先頭3件のみ返る
 	candles, err := repo.Find(context.Background(), "AAPL", "1day", 3)
@@ -209,7 +214,7 @@ func TestCachingCandleRepository_Find_CacheMiss(t *testing.T) {
 		},
 	}
 
-	repo := NewCachingRepository(rdb, 5*time.Minute, inner, "candles")
+	repo := NewCachingRepository(rdb, 5*time.Minute, inner, "candles", nil)
 	candles, err := repo.Find(context.Background(), "AAPL", "1day", 100)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -222,6 +227,57 @@ func TestCachingCandleRepository_Find_CacheMiss(t *testing.T) {
 	}
 }
 
+// TestCachingCandleRepository_Find_PreservesDBOrder はキャッシュに保存する内容が DB の結果と同じ順序のバイト列で、
+// キャッシュヒット時も同じ時間の足を含めて DB と同じ順序で返すことを検証します。
+func TestCachingCandleRepository_Find_PreservesDBOrder(t *testing.T) {
+	t.Parallel()
+
+	rdb, mock := redismock.NewClientMock()
+	defer func() { _ = rdb.Close() }()
+
+	day1 := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	// DB の順序（時間の降順、同じ時間は id の降順）。同じ時間の足は open で識別する
+	dbOrder := []Candle{
+		{SymbolCode: "AAPL", Interval: "1day", Time: day2, Open: 3},
+		{SymbolCode: "AAPL", Interval: "1day", Time: day2, Open: 2},
+		{SymbolCode: "AAPL", Interval: "1day", Time: day2, Open: 1},
+		{SymbolCode: "AAPL", Interval: "1day", Time: day1, Open: 5},
+		{SymbolCode: "AAPL", Interval: "1day", Time: day1, Open: 4},
+	}
+	payload, _ := json.Marshal(dbOrder)
+
+	mock.ExpectGet("candles:AAPL:1day").RedisNil()
+	mock.ExpectSet("candles:AAPL:1day", payload, 5*time.Minute).SetVal("OK")
+	mock.ExpectGet("candles:AAPL:1day").SetVal(string(payload))
+
+	inner := &mockReadWriteRepository{
+		findFn: func(ctx context.Context, symbol, interval string, outputsize int) ([]Candle, error) {
+			return dbOrder, nil
+		},
+	}
+	repo := NewCachingRepository(rdb, 5*time.Minute, inner, "candles", nil)
+
+	miss, err := repo.Find(context.Background(), "AAPL", "1day", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(miss, dbOrder) {
+		t.Errorf("cache miss = %+v, want %+v", miss, dbOrder)
+	}
+
+	hit, err := repo.Find(context.Background(), "AAPL", "1day", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(hit, dbOrder[:2]) {
+		t.Errorf("cache hit = %+v, want %+v", hit, dbOrder[:2])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled mock expectations: %v", err)
+	}
+}
+
 // TestCachingCandleRepository_Find_InnerError は内部リポジトリがエラーを返した場合にそのエラーが伝播されることを検証します。
 func TestCachingCandleRepository_Find_InnerError(t *testing.T) {
 	t.Parallel()
@@ -239,7 +295,7 @@ func TestCachingCandleRepository_Find_InnerError(t *testing.T) {
 		},
 	}
 
-	repo := NewCachingRepository(rdb, 5*time.Minute, inner, "candles")
+	repo := NewCachingRepository(rdb, 5*time.Minute, inner, "candles", nil)
 	_, err := repo.Find(context.Background(), "AAPL", "1day", 100)
 
 	if err == nil {
@@ -275,7 +331,7 @@ func TestCachingCandleRepository_Find_CorruptedCache(t *testing.T) {
 		},
 	}
 
-	repo := NewCachingRepository(rdb, 5*time.Minute, inner, "candles")
+	repo := NewCachingRepository(rdb, 5*time.Minute, inner, "candles", nil)
 	candles, err := repo.Find(context.Background(), "AAPL", "1day", 100)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -300,8 +356,8 @@ func TestCachingCandleRepository_UpsertBatch_NilRedis(t *testing.T) {
 		},
 	}
 
-	repo := NewCachingRepository(nil, 5*time.Minute, inner, "candles")
-	err := repo.UpsertBatch(context.Background(), []Candle{
+	repo := NewCachingRepository(nil, 5*time.Minute, inner, "candles", nil)
+	_, err := repo.UpsertBatch(context.Background(), []Candle{
 		{SymbolCode: "AAPL", Interval: "1day"},
 	})
 	if err != nil {
@@ -323,8 +379,8 @@ func TestCachingCandleRepository_UpsertBatch_InnerError(t *testing.T) {
 		},
 	}
 
-	repo := NewCachingRepository(nil, 5*time.Minute, inner, "candles")
-	err := repo.UpsertBatch(context.Background(), []Candle{
+	repo := NewCachingRepository(nil, 5*time.Minute, inner, "candles", nil)
+	_, err := repo.UpsertBatch(context.Background(), []Candle{
 		{SymbolCode: "AAPL", Interval: "1day"},
 	})
 
@@ -346,8 +402,8 @@ func TestCachingCandleRepository_UpsertBatch_EmptyCandles(t *testing.T) {
 		},
 	}
 
-	repo := NewCachingRepository(rdb, 5*time.Minute, inner, "candles")
-	err := repo.UpsertBatch(context.Background(), []Candle{})
+	repo := NewCachingRepository(rdb, 5*time.Minute, inner, "candles", nil)
+	_, err := repo.UpsertBatch(context.Background(), []Candle{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -374,12 +430,12 @@ func TestCachingCandleRepository_UpsertBatch_CacheWarmUp(t *testing.T) {
 		},
 	}
 
-	// 既存キャッシュを削除してから最新データで再生成
-	mock.ExpectDel("candles:AAPL:1day").SetVal(1)
+	// 既存キャッシュ（調整後のハッシュを含む）を削除してから最新データで再生成
+	mock.ExpectDel("candles:AAPL:1day", "candles:AAPL:1day:adjusted", "candles:AAPL:1day:sparkline", "candles:AAPL:1day:range").SetVal(1)
 	mock.ExpectSet("candles:AAPL:1day", warmJSON, 5*time.Minute).SetVal("OK")
 
-	repo := NewCachingRepository(rdb, 5*time.Minute, inner, "candles")
-	err := repo.UpsertBatch(context.Background(), []Candle{
+	repo := NewCachingRepository(rdb, 5*time.Minute, inner, "candles", nil)
+	_, err := repo.UpsertBatch(context.Background(), []Candle{
 		{SymbolCode: "AAPL", Interval: "1day"},
 	})
 	if err != nil {
@@ -390,6 +446,42 @@ func TestCachingCandleRepository_UpsertBatch_CacheWarmUp(t *testing.T) {
 	}
 }
 
+// TestCachingCandleRepository_UpsertBatch_NamespaceIsolation は同一 Redis を共有する環境間で、
+// 一方の無効化がもう一方のキーに触れないことを検証します。
+func TestCachingCandleRepository_UpsertBatch_NamespaceIsolation(t *testing.T) {
+	t.Parallel()
+
+	rdb, mock := redismock.NewClientMock()
+	defer func() { _ = rdb.Close() }()
+
+	warmCandles := []Candle{{SymbolCode: "AAPL", Interval: "1day", Close: 155.0}}
+	warmJSON, _ := json.Marshal(warmCandles)
+	inner := &mockReadWriteRepository{
+		upsertBatchFn: func(ctx context.Context, candles []Candle) error { return nil },
+		findFn: func(ctx context.Context, symbol, interval string, outputsize int) ([]Candle, error) {
+			return warmCandles, nil
+		},
+	}
+	staging := NewCachingRepository(rdb, 5*time.Minute, inner, "staging:candles", nil)
+	production := NewCachingRepository(rdb, 5*time.Minute, inner, "production:candles", nil)
+
+	// staging の書き込みは staging のキーのみ削除・再生成する（production のキーへの操作は期待外として失敗する）
+	mock.ExpectDel("staging:candles:AAPL:1day", "staging:candles:AAPL:1day:adjusted", "staging:candles:AAPL:1day:sparkline", "staging:candles:AAPL:1day:range").SetVal(1)
+	mock.ExpectSet("staging:candles:AAPL:1day", warmJSON, 5*time.Minute).SetVal("OK")
+	// production の読み取りは自身のキャッシュを参照する
+	mock.ExpectGet("production:candles:AAPL:1day").SetVal(string(warmJSON))
+
+	if _, err := staging.UpsertBatch(context.Background(), []Candle{{SymbolCode: "AAPL", Interval: "1day"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := production.Find(context.Background(), "AAPL", "1day", 100); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled mock expectations: %v", err)
+	}
+}
+
 // TestCachingCandleRepository_UpsertBatch_DeduplicatesWarmUp は同一symbol+intervalのウォームアップが重複せず1回のみ実行されることを検証します。
 func TestCachingCandleRepository_UpsertBatch_DeduplicatesWarmUp(t *testing.T) {
 	t.Parallel()
@@ -414,11 +506,11 @@ func TestCachingCandleRepository_UpsertBatch_DeduplicatesWarmUp(t *testing.T) {
 	}
 
 	// AAPL:1day が3件あっても DEL と SET は1回ずつのみ
-	mock.ExpectDel("candles:AAPL:1day").SetVal(1)
+	mock.ExpectDel("candles:AAPL:1day", "candles:AAPL:1day:adjusted", "candles:AAPL:1day:sparkline", "candles:AAPL:1day:range").SetVal(1)
 	mock.ExpectSet("candles:AAPL:1day", warmJSON, 5*time.Minute).SetVal("OK")
 
-	repo := NewCachingRepository(rdb, 5*time.Minute, inner, "candles")
-	err := repo.UpsertBatch(context.Background(), []Candle{
+	repo := NewCachingRepository(rdb, 5*time.Minute, inner, "candles", nil)
+	_, err := repo.UpsertBatch(context.Background(), []Candle{
 		{SymbolCode: "AAPL", Interval: "1day", Time: time.Now()},
 		{SymbolCode: "AAPL", Interval: "1day", Time: time.Now().Add(-24 * time.Hour)},
 		{SymbolCode: "AAPL", Interval: "1day", Time: time.Now().Add(-48 * time.Hour)},
@@ -434,6 +526,230 @@ func TestCachingCandleRepository_UpsertBatch_DeduplicatesWarmUp(t *testing.T) {
 	}
 }
 
+// fakeFlags はテストでフラグ値を固定する FlagChecker です。未指定のフラグは FlagDefault を返します。
+type fakeFlags map[string]bool
+
+func (f fakeFlags) Enabled(_ context.Context, name string) bool {
+	if v, ok := f[name]; ok {
+		return v
+	}
+	return FlagDefault(name)
+}
+
+// TestCachingCandleRepository_UpsertBatch_WriteThroughDisabled は write-through 無効時に
+// キャッシュの削除のみ行い、再生成しないことを検証します。
+func TestCachingCandleRepository_UpsertBatch_WriteThroughDisabled(t *testing.T) {
+	t.Parallel()
+
+	rdb, mock := redismock.NewClientMock()
+	defer func() { _ = rdb.Close() }()
+
+	inner := &mockReadWriteRepository{
+		upsertBatchFn: func(ctx context.Context, candles []Candle) error { return nil },
+		findFn: func(ctx context.Context, symbol, interval string, outputsize int) ([]Candle, error) {
+			t.Error("inner.Find should not be called when write-through is disabled")
+			return nil, nil
+		},
+	}
+	mock.ExpectDel("candles:AAPL:1day", "candles:AAPL:1day:adjusted", "candles:AAPL:1day:sparkline", "candles:AAPL:1day:range").SetVal(1)
+
+	repo := NewCachingRepository(rdb, 5*time.Minute, inner, "candles", fakeFlags{FlagWriteThrough: false})
+	if _, err := repo.UpsertBatch(context.Background(), []Candle{{SymbolCode: "AAPL", Interval: "1day"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled mock expectations: %v", err)
+	}
+}
+
+// TestCachingCandleRepository_Find_FetchThroughDisabled は fetch-through 無効時に
+// キャッシュミスの結果をキャッシュへ書き込まないことを検証します。
+func TestCachingCandleRepository_Find_FetchThroughDisabled(t *testing.T) {
+	t.Parallel()
+
+	rdb, mock := redismock.NewClientMock()
+	defer func() { _ = rdb.Close() }()
+
+	dbCandles := []Candle{{SymbolCode: "AAPL", Interval: "1day", Close: 155.0}}
+	inner := &mockReadWriteRepository{
+		findFn: func(ctx context.Context, symbol, interval string, outputsize int) ([]Candle, error) {
+			return dbCandles, nil
+		},
+	}
+	mock.ExpectGet("candles:AAPL:1day").RedisNil()
+
+	repo := NewCachingRepository(rdb, 5*time.Minute, inner, "candles", fakeFlags{FlagFetchThrough: false})
+	got, err := repo.Find(context.Background(), "AAPL", "1day", 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 {
+		t.Errorf("expected 1 candle, got %d", len(got))
+	}
+	// 期待外の SET が呼ばれた場合は redismock がエラーを返し、ExpectationsWereMet は成功する
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled mock expectations: %v", err)
+	}
+}
+
+// TestCachingCandleRepository_Find_NegativeCache は 0 件の結果が negative caching 有効時のみ
+// 短い TTL でキャッシュされることを検証します。
+func TestCachingCandleRepository_Find_NegativeCache(t *testing.T) {
+	t.Parallel()
+
+	emptyJSON, _ := json.Marshal([]Candle(nil))
+	tests := []struct {
+		name    string
+		enabled bool
+	}{
+		{name: "有効なら短いTTLで保存", enabled: true},
+		{name: "無効なら保存しない", enabled: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rdb, mock := redismock.NewClientMock()
+			defer func() { _ = rdb.Close() }()
+
+			inner := &mockReadWriteRepository{
+				findFn: func(ctx context.Context, symbol, interval string, outputsize int) ([]Candle, error) {
+					return nil, nil
+				},
+			}
+			mock.ExpectGet("candles:NEW:1day").RedisNil()
+			if tt.enabled {
+				mock.ExpectSet("candles:NEW:1day", emptyJSON, DefaultNegativeCacheTTL).SetVal("OK")
+			}
+
+			repo := NewCachingRepository(rdb, DefaultCacheTTL, inner, "candles", fakeFlags{FlagNegativeCache: tt.enabled})
+			got, err := repo.Find(context.Background(), "NEW", "1day", 10)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != 0 {
+				t.Errorf("expected no candles, got %d", len(got))
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled mock expectations: %v", err)
+			}
+		})
+	}
+}
+
+// TestCachingCandleRepository_FindAdjusted は調整後のローソク足が調整係数の版をフィールドとするハッシュにキャッシュされることを検証します。
+func TestCachingCandleRepository_FindAdjusted(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	raw := []Candle{{SymbolCode: "AAPL", Interval: "1day", Time: day, Open: 100, High: 110, Low: 90, Close: 100, Volume: 10}}
+	adjs := []Adjustment{{EffectiveDate: day.AddDate(0, 0, 1), Factor: 0.5}}
+	adjusted := ApplyAdjustments(raw, adjs)
+	rawJSON, _ := json.Marshal(raw)
+	adjustedJSON, _ := json.Marshal(adjusted)
+	version := AdjustmentsVersion(adjs)
+
+	t.Run("hit", func(t *testing.T) {
+		t.Parallel()
+		rdb, mock := redismock.NewClientMock()
+		defer func() { _ = rdb.Close() }()
+		mock.ExpectHGet("candles:AAPL:1day:adjusted", version).SetVal(string(adjustedJSON))
+
+		repo := NewCachingRepository(rdb, 5*time.Minute, &mockReadWriteRepository{}, "candles", nil)
+		got, err := repo.FindAdjusted(context.Background(), "AAPL", "1day", 10, adjs)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != 1 || got[0].Close != 50 {
+			t.Errorf("unexpected candles: %+v", got)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled mock expectations: %v", err)
+		}
+	})
+
+	t.Run("miss", func(t *testing.T) {
+		t.Parallel()
+		rdb, mock := redismock.NewClientMock()
+		defer func() { _ = rdb.Close() }()
+		mock.ExpectHGet("candles:AAPL:1day:adjusted", version).RedisNil()
+		mock.ExpectGet("candles:AAPL:1day").SetVal(string(rawJSON))
+		mock.ExpectTxPipeline()
+		mock.ExpectHSet("candles:AAPL:1day:adjusted", version, adjustedJSON).SetVal(1)
+		mock.ExpectExpire("candles:AAPL:1day:adjusted", 5*time.Minute).SetVal(true)
+		mock.ExpectTxPipelineExec()
+
+		repo := NewCachingRepository(rdb, 5*time.Minute, &mockReadWriteRepository{}, "candles", nil)
+		got, err := repo.FindAdjusted(context.Background(), "AAPL", "1day", 10, adjs)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != 1 || got[0].Close != 50 || got[0].Volume != 20 {
+			t.Errorf("unexpected candles: %+v", got)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled mock expectations: %v", err)
+		}
+	})
+}
+
+// TestCachingCandleRepository_FindSparkline はスパークラインが outputsize・points・調整係数の版をフィールドとするハッシュにキャッシュされることを検証します。
+func TestCachingCandleRepository_FindSparkline(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	raw := []Candle{
+		{SymbolCode: "AAPL", Interval: "1day", Time: day.AddDate(0, 0, 1), Close: 101},
+		{SymbolCode: "AAPL", Interval: "1day", Time: day, Close: 100},
+	}
+	rawJSON, _ := json.Marshal(raw)
+	want := Sparkline{Closes: []float64{100, 101}, First: day, Last: day.AddDate(0, 0, 1)}
+	wantJSON, _ := json.Marshal(want)
+
+	t.Run("hit", func(t *testing.T) {
+		t.Parallel()
+		rdb, mock := redismock.NewClientMock()
+		defer func() { _ = rdb.Close() }()
+		mock.ExpectHGet("candles:AAPL:1day:sparkline", "7:30:").SetVal(string(wantJSON))
+
+		repo := NewCachingRepository(rdb, 5*time.Minute, &mockReadWriteRepository{}, "candles", nil)
+		got, err := repo.FindSparkline(context.Background(), "AAPL", "1day", 7, 30, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(got.Closes, want.Closes) || !got.Last.Equal(want.Last) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled mock expectations: %v", err)
+		}
+	})
+
+	t.Run("miss", func(t *testing.T) {
+		t.Parallel()
+		rdb, mock := redismock.NewClientMock()
+		defer func() { _ = rdb.Close() }()
+		mock.ExpectHGet("candles:AAPL:1day:sparkline", "7:30:").RedisNil()
+		mock.ExpectGet("candles:AAPL:1day").SetVal(string(rawJSON))
+		mock.ExpectTxPipeline()
+		mock.ExpectHSet("candles:AAPL:1day:sparkline", "7:30:", wantJSON).SetVal(1)
+		mock.ExpectExpire("candles:AAPL:1day:sparkline", 5*time.Minute).SetVal(true)
+		mock.ExpectTxPipelineExec()
+
+		repo := NewCachingRepository(rdb, 5*time.Minute, &mockReadWriteRepository{}, "candles", nil)
+		got, err := repo.FindSparkline(context.Background(), "AAPL", "1day", 7, 30, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(got.Closes, want.Closes) {
+			t.Errorf("closes = %v, want %v", got.Closes, want.Closes)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled mock expectations: %v", err)
+		}
+	})
+}
+
 // TestSafeCacheKey はsafeCacheKey関数がRedisキーで問題となる文字を正しくエスケープすることを検証します。
 func TestSafeCacheKey(t *testing.T) {
 	t.Parallel()
@@ -462,3 +778,153 @@ func TestSafeCacheKey(t *testing.T) {
 		})
 	}
 }
+
+// asOfReadWriteRepository は FindAsOf を実装する readWriteRepository のモックです。
+type asOfReadWriteRepository struct {
+	mockReadWriteRepository
+	asOfCalls int
+}
+
+func (m *asOfReadWriteRepository) FindAsOf(_ context.Context, symbol, interval string, _ time.Time, _ int) ([]Candle, error) {
+	m.asOfCalls++
+	return []Candle{{SymbolCode: symbol, Interval: interval, Open: 99.0}}, nil
+}
+
+// TestCachingCandleRepository_FindAsOf_BypassesCache は as-of クエリが Redis を読み書きせず基盤リポジトリに委譲されることを検証します。
+func TestCachingCandleRepository_FindAsOf_BypassesCache(t *testing.T) {
+	t.Parallel()
+
+	rdb, mock := redismock.NewClientMock() // 期待値なし: Redis へのコマンドはすべて失敗扱いになる
+	defer func() { _ = rdb.Close() }()
+
+	inner := &asOfReadWriteRepository{}
+	repo := NewCachingRepository(rdb, 5*time.Minute, inner, "candles", nil)
+
+	got, err := repo.FindAsOf(context.Background(), "AAPL", "1day", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || inner.asOfCalls != 1 {
+		t.Errorf("got %d candles, inner calls = %d; want 1, 1", len(got), inner.asOfCalls)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled mock expectations: %v", err)
+	}
+
+	if _, err := NewCachingRepository(rdb, 0, &mockReadWriteRepository{}, "", nil).FindAsOf(context.Background(), "AAPL", "1day", time.Now(), 10); err == nil {
+		t.Error("expected error when inner repository does not support as-of queries")
+	}
+}
+
+// eachReadWriteRepository は FindEach を実装する readWriteRepository のモックです。
+type eachReadWriteRepository struct {
+	mockReadWriteRepository
+	opts []FindOptions
+}
+
+func (m *eachReadWriteRepository) FindEach(_ context.Context, opts FindOptions, fn func(Candle) error) error {
+	m.opts = append(m.opts, opts)
+	return fn(Candle{SymbolCode: opts.Symbol, Interval: opts.Interval})
+}
+
+// TestCachingCandleRepository_FindEach_BypassesCache は走査が Redis を読み書きせず基盤リポジトリに委譲され、
+// 基盤リポジトリが EachFinder を実装しない場合は Find の結果を ErrStop まで渡すことを検証します。
+func TestCachingCandleRepository_FindEach_BypassesCache(t *testing.T) {
+	t.Parallel()
+
+	rdb, mock := redismock.NewClientMock() // 期待値なし: Redis へのコマンドはすべて失敗扱いになる
+	defer func() { _ = rdb.Close() }()
+
+	inner := &eachReadWriteRepository{}
+	opts := FindOptions{Symbol: "AAPL", Interval: "1day", Limit: 100, BatchSize: 10}
+	var n int
+	err := NewCachingRepository(rdb, 5*time.Minute, inner, "candles", nil).FindEach(context.Background(), opts, func(Candle) error {
+		n++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 1 || !reflect.DeepEqual(inner.opts, []FindOptions{opts}) {
+		t.Errorf("got %d candles, inner opts = %+v; want 1, [%+v]", n, inner.opts, opts)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled mock expectations: %v", err)
+	}
+
+	var gotSize int
+	plain := &mockReadWriteRepository{findFn: func(_ context.Context, _, _ string, outputsize int) ([]Candle, error) {
+		gotSize = outputsize
+		return []Candle{{Open: 3}, {Open: 2}, {Open: 1}}, nil
+	}}
+	var opens []float64
+	err = NewCachingRepository(rdb, 0, plain, "", nil).FindEach(context.Background(), opts, func(c Candle) error {
+		opens = append(opens, c.Open)
+		if len(opens) == 2 {
+			return ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotSize != 100 || !reflect.DeepEqual(opens, []float64{3, 2}) {
+		t.Errorf("outputsize = %d, opens = %v; want 100, [3 2]", gotSize, opens)
+	}
+
+	boom := errors.New("boom")
+	err = NewCachingRepository(rdb, 0, plain, "", nil).FindEach(context.Background(), opts, func(Candle) error { return boom })
+	if !errors.Is(err, boom) {
+		t.Errorf("err = %v, want %v", err, boom)
+	}
+}
+
+// switchProvider はテスト用の RedisProvider で、client を差し替えて有効・無効を切り替えます。
+type switchProvider struct {
+	client *redis.Client
+}
+
+func (p *switchProvider) Client() *redis.Client { return p.client }
+
+// TestCachingCandleRepository_WithRedisProvider はプロバイダーが nil を返す間はキャッシュをバイパスし、
+// クライアントを返すようになるとキャッシュを使うことを検証します。
+func TestCachingCandleRepository_WithRedisProvider(t *testing.T) {
+	t.Parallel()
+
+	rdb, mock := redismock.NewClientMock()
+	defer func() { _ = rdb.Close() }()
+
+	cached := []Candle{{SymbolCode: "AAPL", Interval: "1day", Open: 150.0}}
+	cachedJSON, _ := json.Marshal(cached)
+	innerCalls := 0
+	inner := &mockReadWriteRepository{
+		findFn: func(ctx context.Context, symbol, interval string, outputsize int) ([]Candle, error) {
+			innerCalls++
+			return []Candle{{SymbolCode: symbol, Interval: interval, Open: 1.0}}, nil
+		},
+	}
+	provider := &switchProvider{}
+	repo := NewCachingRepository(rdb, 5*time.Minute, inner, "candles", nil).WithRedisProvider(provider)
+
+	// 無効: Redis へのコマンドを発行せず inner に委譲する
+	if _, err := repo.Find(context.Background(), "AAPL", "1day", 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if innerCalls != 1 {
+		t.Errorf("inner calls = %d, want 1", innerCalls)
+	}
+
+	// 有効: キャッシュから返す
+	provider.client = rdb
+	mock.ExpectGet("candles:AAPL:1day").SetVal(string(cachedJSON))
+	got, err := repo.Find(context.Background(), "AAPL", "1day", 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, cached) || innerCalls != 1 {
+		t.Errorf("got %+v (inner calls %d), want cached %+v", got, innerCalls, cached)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled mock expectations: %v", err)
+	}
+}
@@ -1,6 +1,10 @@
 package candles
 
-import "time"
+import (
+	"fmt"
+	"math"
+	"time"
+)
 
 // Candle は特定の銘柄・時間間隔におけるOHLCV（始値、高値、安値、終値、出来高）ローソク足データを表します。
 type Candle struct {
@@ -12,4 +16,43 @@ type Candle struct {
 	Low        float64   // 期間中の安値
 	Close      float64   // 終値
 	Volume     int64     // 出来高
+	Source     string    // 取り込み元（SourceTwelveData 等。空なら SourceTwelveData として保存する）
+}
+
+// StoredCandle は保存済みのローソク足と、その行の ID・書き込み日時です（管理用の調査。dbRepository.Inspect）。
+type StoredCandle struct {
+	ID int64
+	Candle
+	CreatedAt time.Time // 最初に挿入した日時
+	UpdatedAt time.Time // 値（OHLCV）が最後に変わった日時
+}
+
+// Validate はローソク足として整合しているかを検証します。
+// 外部ソース（TwelveData 以外の CSV 等）から取り込む値の共通チェックとして使用し、
+// 不正な場合は ErrInvalidCandle をラップしたエラーを返します。
+func (c Candle) Validate() error {
+	if c.Time.IsZero() {
+		return fmt.Errorf("%w: missing time", ErrInvalidCandle)
+	}
+	for _, p := range []struct {
+		name  string
+		value float64
+	}{{"open", c.Open}, {"high", c.High}, {"low", c.Low}, {"close", c.Close}} {
+		if math.IsNaN(p.value) || math.IsInf(p.value, 0) || p.value <= 0 {
+			return fmt.Errorf("%w: %s must be a positive finite number", ErrInvalidCandle, p.name)
+		}
+	}
+	if c.High < c.Low {
+		return fmt.Errorf("%w: high is below low", ErrInvalidCandle)
+	}
+	if c.Open > c.High || c.Close > c.High {
+		return fmt.Errorf("%w: open/close above high", ErrInvalidCandle)
+	}
+	if c.Open < c.Low || c.Close < c.Low {
+		return fmt.Errorf("%w: open/close below low", ErrInvalidCandle)
+	}
+	if c.Volume < 0 {
+		return fmt.Errorf("%w: negative volume", ErrInvalidCandle)
+	}
+	return nil
 }
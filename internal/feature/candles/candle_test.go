@@ -0,0 +1,47 @@
+package candles
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCandle_Validate(t *testing.T) {
+	valid := Candle{Time: time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC), Open: 100, High: 110, Low: 90, Close: 105, Volume: 1000}
+
+	testCases := []struct {
+		name    string
+		mutate  func(c *Candle)
+		wantErr bool
+	}{
+		{name: "valid", mutate: func(c *Candle) {}},
+		{name: "flat candle", mutate: func(c *Candle) { c.Open, c.High, c.Low, c.Close = 100, 100, 100, 100 }},
+		{name: "zero volume", mutate: func(c *Candle) { c.Volume = 0 }},
+		{name: "zero time", mutate: func(c *Candle) { c.Time = time.Time{} }, wantErr: true},
+		{name: "zero price", mutate: func(c *Candle) { c.Low = 0 }, wantErr: true},
+		{name: "NaN", mutate: func(c *Candle) { c.Close = math.NaN() }, wantErr: true},
+		{name: "Inf", mutate: func(c *Candle) { c.High = math.Inf(1) }, wantErr: true},
+		{name: "high below low", mutate: func(c *Candle) { c.High, c.Low = 80, 90 }, wantErr: true},
+		{name: "close above high", mutate: func(c *Candle) { c.Close = 111 }, wantErr: true},
+		{name: "open below low", mutate: func(c *Candle) { c.Open = 89 }, wantErr: true},
+		{name: "negative volume", mutate: func(c *Candle) { c.Volume = -1 }, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := valid
+			tc.mutate(&c)
+			err := c.Validate()
+			if tc.wantErr {
+				if !errors.Is(err, ErrInvalidCandle) {
+					t.Errorf("Validate()=%v, want ErrInvalidCandle", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Validate()=%v, want nil", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package candleshttp
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+)
+
+// AdjustmentUsecase は分割調整の係数の管理操作のユースケースインターフェースを定義します。
+// Goの慣例に従い、インターフェースは利用者（handler）側で定義します。
+type AdjustmentUsecase interface {
+	List(ctx context.Context, q url.Values) ([]candles.Adjustment, error)
+	Create(ctx context.Context, a candles.Adjustment) (candles.Adjustment, error)
+	Update(ctx context.Context, id int64, a candles.Adjustment) (candles.Adjustment, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+// AdjustmentHandler は分割調整の係数の管理エンドポイントを処理します。
+// 認可（candles:admin スコープ）はルーター側のミドルウェアで行います。
+type AdjustmentHandler struct {
+	uc AdjustmentUsecase
+}
+
+// NewAdjustmentHandler は AdjustmentHandler を生成します。
+func NewAdjustmentHandler(uc AdjustmentUsecase) *AdjustmentHandler {
+	return &AdjustmentHandler{uc: uc}
+}
+
+// List は調整係数を返します（既定は銘柄・効力発生日の順）。
+// filter / sort / limit / offset（candles.AdjustmentListSchema）と従来の ?symbol= で絞り込めます。
+func (h *AdjustmentHandler) List(w http.ResponseWriter, r *http.Request) {
+	adjs, err := h.uc.List(r.Context(), r.URL.Query())
+	if err != nil {
+		httpx.WriteError(w, err, "failed to list adjustments", "query", r.URL.RawQuery)
+		return
+	}
+
+	out := make([]api.Adjustment, 0, len(adjs))
+	for _, a := range adjs {
+		out = append(out, toAdjustment(a))
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+// Create は調整係数を登録し、登録した調整係数を 201 で返します。
+func (h *AdjustmentHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req api.CreateAdjustmentRequest
+	if err := httpx.DecodeAndValidate(r, &req); err != nil {
+		httpx.WriteDecodeError(w, err, "invalid request")
+		return
+	}
+
+	a, err := h.uc.Create(r.Context(), candles.Adjustment{
+		SymbolCode:    req.Symbol,
+		EffectiveDate: req.EffectiveDate.Time,
+		Factor:        req.Factor,
+		Reason:        req.Reason,
+	})
+	if err != nil {
+		httpx.WriteError(w, err, "failed to create adjustment", "symbol", req.Symbol)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusCreated, toAdjustment(a))
+}
+
+// Update は {id} の調整係数の効力発生日・係数・理由を置き換え、変更後の調整係数を返します。
+func (h *AdjustmentHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := adjustmentID(w, r)
+	if !ok {
+		return
+	}
+	var req api.UpdateAdjustmentRequest
+	if err := httpx.DecodeAndValidate(r, &req); err != nil {
+		httpx.WriteDecodeError(w, err, "invalid request")
+		return
+	}
+
+	a, err := h.uc.Update(r.Context(), id, candles.Adjustment{
+		EffectiveDate: req.EffectiveDate.Time,
+		Factor:        req.Factor,
+		Reason:        req.Reason,
+	})
+	if err != nil {
+		httpx.WriteError(w, err, "failed to update adjustment", "id", id)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, toAdjustment(a))
+}
+
+// Delete は {id} の調整係数を削除します。
+func (h *AdjustmentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := adjustmentID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.uc.Delete(r.Context(), id); err != nil {
+		httpx.WriteError(w, err, "failed to delete adjustment", "id", id)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// adjustmentID は {id} を解釈します。数値でない場合は存在しない ID として 404 を書き込み ok=false を返します。
+func adjustmentID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	raw := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		httpx.WriteError(w, candles.ErrAdjustmentNotFound, "invalid adjustment id", "id", raw)
+		return 0, false
+	}
+	return id, true
+}
+
+func toAdjustment(a candles.Adjustment) api.Adjustment {
+	return api.Adjustment{
+		Id:            a.ID,
+		Symbol:        a.SymbolCode,
+		EffectiveDate: api.NewDate(a.EffectiveDate),
+		Factor:        a.Factor,
+		Reason:        a.Reason,
+		UpdatedAt:     api.NewTimestamp(a.UpdatedAt),
+	}
+}
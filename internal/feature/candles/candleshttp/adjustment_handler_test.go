@@ -0,0 +1,178 @@
+package candleshttp_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/candleshttp"
+)
+
+// mockAdjustmentUsecase は AdjustmentUsecase インターフェースのモック実装です。
+// 登録・変更された調整係数をそのまま返し、err が設定されていれば全操作で返します。
+type mockAdjustmentUsecase struct {
+	err     error
+	created candles.Adjustment
+	deleted int64
+}
+
+func (m *mockAdjustmentUsecase) List(_ context.Context, q url.Values) ([]candles.Adjustment, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return []candles.Adjustment{{ID: 1, SymbolCode: q.Get("symbol"), EffectiveDate: time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC), Factor: 0.5}}, nil
+}
+
+func (m *mockAdjustmentUsecase) Create(_ context.Context, a candles.Adjustment) (candles.Adjustment, error) {
+	m.created = a
+	a.ID = 1
+	return a, m.err
+}
+
+func (m *mockAdjustmentUsecase) Update(_ context.Context, id int64, a candles.Adjustment) (candles.Adjustment, error) {
+	a.ID = id
+	return a, m.err
+}
+
+func (m *mockAdjustmentUsecase) Delete(_ context.Context, id int64) error {
+	m.deleted = id
+	return m.err
+}
+
+func newAdjustmentRouter(uc candleshttp.AdjustmentUsecase) http.Handler {
+	h := candleshttp.NewAdjustmentHandler(uc)
+	r := chi.NewRouter()
+	r.Get("/admin/adjustments", h.List)
+	r.Post("/admin/adjustments", h.Create)
+	r.Put("/admin/adjustments/{id}", h.Update)
+	r.Delete("/admin/adjustments/{id}", h.Delete)
+	return r
+}
+
+func TestAdjustmentHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		method   string
+		url      string
+		body     string
+		err      error
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "list",
+			method:   http.MethodGet,
+			url:      "/admin/adjustments?symbol=AAPL",
+			wantCode: http.StatusOK,
+			wantBody: `"effectiveDate":"2024-06-10"`,
+		},
+		{
+			name:     "create",
+			method:   http.MethodPost,
+			url:      "/admin/adjustments",
+			body:     `{"symbol":"AAPL","effectiveDate":"2024-06-10","factor":0.25,"reason":"4-for-1 split"}`,
+			wantCode: http.StatusCreated,
+			wantBody: `"factor":0.25`,
+		},
+		{
+			name:     "create: factor must be positive",
+			method:   http.MethodPost,
+			url:      "/admin/adjustments",
+			body:     `{"symbol":"AAPL","effectiveDate":"2024-06-10","factor":-1}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `"invalid request"`,
+		},
+		{
+			name:     "create: missing effective date",
+			method:   http.MethodPost,
+			url:      "/admin/adjustments",
+			body:     `{"symbol":"AAPL","factor":0.5}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `"invalid request"`,
+		},
+		{
+			name:     "create: duplicate",
+			method:   http.MethodPost,
+			url:      "/admin/adjustments",
+			body:     `{"symbol":"AAPL","effectiveDate":"2024-06-10","factor":0.5}`,
+			err:      candles.ErrAdjustmentExists,
+			wantCode: http.StatusConflict,
+			wantBody: `"adjustment_exists"`,
+		},
+		{
+			name:     "update",
+			method:   http.MethodPut,
+			url:      "/admin/adjustments/7",
+			body:     `{"effectiveDate":"2024-06-11","factor":0.5}`,
+			wantCode: http.StatusOK,
+			wantBody: `"id":7`,
+		},
+		{
+			name:     "update: not found",
+			method:   http.MethodPut,
+			url:      "/admin/adjustments/99",
+			body:     `{"effectiveDate":"2024-06-11","factor":0.5}`,
+			err:      candles.ErrAdjustmentNotFound,
+			wantCode: http.StatusNotFound,
+			wantBody: `"adjustment_not_found"`,
+		},
+		{
+			name:     "delete",
+			method:   http.MethodDelete,
+			url:      "/admin/adjustments/7",
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:     "delete: non-numeric id",
+			method:   http.MethodDelete,
+			url:      "/admin/adjustments/abc",
+			wantCode: http.StatusNotFound,
+			wantBody: `"adjustment_not_found"`,
+		},
+		{
+			name:     "usecase validation error",
+			method:   http.MethodPost,
+			url:      "/admin/adjustments",
+			body:     `{"symbol":"AAPL","effectiveDate":"2024-06-10","factor":0.5}`,
+			err:      fmt.Errorf("%w: factor must be a positive finite number", candles.ErrInvalidAdjustment),
+			wantCode: http.StatusBadRequest,
+			wantBody: `"invalid_adjustment"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			uc := &mockAdjustmentUsecase{err: tt.err}
+
+			w := httptest.NewRecorder()
+			newAdjustmentRouter(uc).ServeHTTP(w, httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+		})
+	}
+
+	t.Run("create passes the request to the usecase", func(t *testing.T) {
+		t.Parallel()
+		uc := &mockAdjustmentUsecase{}
+		w := httptest.NewRecorder()
+		body := `{"symbol":"AAPL","effectiveDate":"2024-06-10","factor":0.25,"reason":"4-for-1 split"}`
+		newAdjustmentRouter(uc).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/adjustments", strings.NewReader(body)))
+
+		assert.Equal(t, "AAPL", uc.created.SymbolCode)
+		assert.True(t, uc.created.EffectiveDate.Equal(time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)))
+		assert.Equal(t, 0.25, uc.created.Factor)
+		assert.Equal(t, "4-for-1 split", uc.created.Reason)
+	})
+}
@@ -0,0 +1,96 @@
+package candleshttp
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+)
+
+// AnomalyUsecase は異常値の確認操作のユースケースインターフェースを定義します。
+// Goの慣例に従い、インターフェースは利用者（handler）側で定義します。
+type AnomalyUsecase interface {
+	List(ctx context.Context, q url.Values) ([]candles.Anomaly, error)
+	Resolve(ctx context.Context, id int64, status string, backfill bool) (candles.Anomaly, error)
+}
+
+// AnomalyHandler は ingest で検出した異常値の管理エンドポイントを処理します。
+// 認可（candles:admin スコープ）はルーター側のミドルウェアで行います。
+type AnomalyHandler struct {
+	uc AnomalyUsecase
+}
+
+// NewAnomalyHandler は AnomalyHandler を生成します。
+func NewAnomalyHandler(uc AnomalyUsecase) *AnomalyHandler {
+	return &AnomalyHandler{uc: uc}
+}
+
+// List は異常値を返します（既定は検出日時の新しい順）。
+// filter / sort / limit / offset（candles.AnomalyListSchema）と従来の ?status= で絞り込めます。
+func (h *AnomalyHandler) List(w http.ResponseWriter, r *http.Request) {
+	anomalies, err := h.uc.List(r.Context(), r.URL.Query())
+	if err != nil {
+		httpx.WriteError(w, err, "failed to list anomalies", "query", r.URL.RawQuery)
+		return
+	}
+
+	out := make([]api.Anomaly, 0, len(anomalies))
+	for _, a := range anomalies {
+		out = append(out, toAnomaly(a))
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+// Resolve は {id} の異常値の確認結果を記録し、記録後の異常値を返します。
+func (h *AnomalyHandler) Resolve(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.WriteError(w, candles.ErrAnomalyNotFound, "invalid anomaly id", "id", chi.URLParam(r, "id"))
+		return
+	}
+
+	var req api.ResolveAnomalyRequest
+	if err := httpx.DecodeAndValidate(r, &req); err != nil {
+		httpx.WriteDecodeError(w, err, "invalid request")
+		return
+	}
+
+	a, err := h.uc.Resolve(r.Context(), id, req.Status, req.Backfill)
+	if err != nil {
+		httpx.WriteError(w, err, "failed to resolve anomaly", "id", id)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, toAnomaly(a))
+}
+
+func toAnomaly(a candles.Anomaly) api.Anomaly {
+	return api.Anomaly{
+		Id:                  a.ID,
+		Symbol:              a.SymbolCode,
+		Time:                api.NewDate(a.Time),
+		PrevClose:           a.PrevClose,
+		Close:               a.Close,
+		Ratio:               a.Ratio,
+		Status:              a.Status,
+		DetectedAt:          api.NewTimestamp(a.DetectedAt),
+		ResolvedAt:          timestampOrZero(a.ResolvedAt),
+		BackfillRequestedAt: timestampOrZero(a.BackfillRequestedAt),
+		BackfilledAt:        timestampOrZero(a.BackfilledAt),
+	}
+}
+
+// timestampOrZero は nil の場合にゼロ値（JSON では省略）を返します。
+func timestampOrZero(t *time.Time) api.Timestamp {
+	if t == nil {
+		return api.Timestamp{}
+	}
+	return api.NewTimestamp(*t)
+}
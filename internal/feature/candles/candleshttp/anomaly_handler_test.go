@@ -0,0 +1,195 @@
+package candleshttp_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/candleshttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/queryspec"
+)
+
+// mockAnomalyUsecase は AnomalyUsecase インターフェースのモック実装です。
+type mockAnomalyUsecase struct {
+	ListFunc    func(ctx context.Context, q url.Values) ([]candles.Anomaly, error)
+	ResolveFunc func(ctx context.Context, id int64, status string, backfill bool) (candles.Anomaly, error)
+}
+
+func (m *mockAnomalyUsecase) List(ctx context.Context, q url.Values) ([]candles.Anomaly, error) {
+	return m.ListFunc(ctx, q)
+}
+
+func (m *mockAnomalyUsecase) Resolve(ctx context.Context, id int64, status string, backfill bool) (candles.Anomaly, error) {
+	return m.ResolveFunc(ctx, id, status, backfill)
+}
+
+func newAnomalyRouter(uc candleshttp.AnomalyUsecase) http.Handler {
+	h := candleshttp.NewAnomalyHandler(uc)
+	r := chi.NewRouter()
+	r.Get("/admin/anomalies", h.List)
+	r.Post("/admin/anomalies/{id}/resolve", h.Resolve)
+	return r
+}
+
+func TestAnomalyHandler_List(t *testing.T) {
+	t.Parallel()
+
+	detected := time.Date(2024, 1, 11, 9, 30, 0, 0, time.UTC)
+	day := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		url        string
+		listErr    error
+		wantStatus string
+		wantCode   int
+		wantBody   string
+	}{
+		{
+			name:       "success: pending anomalies",
+			url:        "/admin/anomalies?status=pending",
+			wantStatus: candles.AnomalyPending,
+			wantCode:   http.StatusOK,
+			wantBody: `[{"id":7,"symbol":"AAPL","time":"2024-01-10","prevClose":180,"close":90,"ratio":0.5,
+				"status":"pending","detectedAt":"2024-01-11T09:30:00Z"}]`,
+		},
+		{
+			name:     "error: invalid status",
+			url:      "/admin/anomalies?status=bogus",
+			listErr:  candles.ErrInvalidAnomalyStatus,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":"invalid_anomaly_status"}`,
+		},
+		{
+			name:     "error: unknown filter field",
+			url:      "/admin/anomalies?filter[password]=x",
+			listErr:  queryspec.ErrInvalidQuery,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":"invalid_list_query"}`,
+		},
+		{
+			name:     "error: repository failure",
+			url:      "/admin/anomalies",
+			listErr:  errors.New("db down"),
+			wantCode: http.StatusInternalServerError,
+			wantBody: `{"error":"internal server error"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var gotStatus string
+			uc := &mockAnomalyUsecase{ListFunc: func(_ context.Context, q url.Values) ([]candles.Anomaly, error) {
+				gotStatus = q.Get("status")
+				if tt.listErr != nil {
+					return nil, tt.listErr
+				}
+				return []candles.Anomaly{{
+					ID: 7, SymbolCode: "AAPL", Time: day, PrevClose: 180, Close: 90, Ratio: 0.5,
+					Status: candles.AnomalyPending, DetectedAt: detected,
+				}}, nil
+			}}
+
+			w := httptest.NewRecorder()
+			newAnomalyRouter(uc).ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+			if tt.wantStatus != "" {
+				assert.Equal(t, tt.wantStatus, gotStatus)
+			}
+		})
+	}
+}
+
+func TestAnomalyHandler_Resolve(t *testing.T) {
+	t.Parallel()
+
+	resolved := time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		url          string
+		body         string
+		resolveErr   error
+		wantCode     int
+		wantBody     string
+		wantCalled   bool
+		wantBackfill bool
+	}{
+		{
+			name:         "success: confirmed split with backfill",
+			url:          "/admin/anomalies/7/resolve",
+			body:         `{"status":"confirmed_split","backfill":true}`,
+			wantCode:     http.StatusOK,
+			wantBody:     `"backfillRequestedAt":"2024-01-12T00:00:00Z"`,
+			wantCalled:   true,
+			wantBackfill: true,
+		},
+		{
+			name:         "error: backfill without split",
+			url:          "/admin/anomalies/7/resolve",
+			body:         `{"status":"confirmed_bad_data","backfill":true}`,
+			resolveErr:   candles.ErrBackfillRequiresSplit,
+			wantCode:     http.StatusBadRequest,
+			wantBody:     `"backfill_requires_split"`,
+			wantCalled:   true,
+			wantBackfill: true,
+		},
+		{
+			name:       "error: anomaly not found",
+			url:        "/admin/anomalies/99/resolve",
+			body:       `{"status":"confirmed_bad_data"}`,
+			resolveErr: candles.ErrAnomalyNotFound,
+			wantCode:   http.StatusNotFound,
+			wantBody:   `"anomaly_not_found"`,
+			wantCalled: true,
+		},
+		{
+			name:     "error: non-numeric id",
+			url:      "/admin/anomalies/abc/resolve",
+			body:     `{"status":"confirmed_split"}`,
+			wantCode: http.StatusNotFound,
+			wantBody: `"anomaly_not_found"`,
+		},
+		{
+			name:     "error: missing status",
+			url:      "/admin/anomalies/7/resolve",
+			body:     `{"backfill":true}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `"invalid request"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			called := false
+			uc := &mockAnomalyUsecase{ResolveFunc: func(_ context.Context, id int64, status string, backfill bool) (candles.Anomaly, error) {
+				called = true
+				assert.Equal(t, tt.wantBackfill, backfill)
+				if tt.resolveErr != nil {
+					return candles.Anomaly{}, tt.resolveErr
+				}
+				return candles.Anomaly{ID: id, SymbolCode: "AAPL", Status: status, ResolvedAt: &resolved, BackfillRequestedAt: &resolved}, nil
+			}}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, tt.url, strings.NewReader(tt.body))
+			newAnomalyRouter(uc).ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+			assert.Equal(t, tt.wantCalled, called)
+		})
+	}
+}
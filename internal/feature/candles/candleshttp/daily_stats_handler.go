@@ -0,0 +1,71 @@
+package candleshttp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+)
+
+// MaxDailyStatsSymbols は GET /stats の ?symbols= で一度に指定できる銘柄数の上限です。
+const MaxDailyStatsSymbols = 100
+
+// DailyStatsUsecase は銘柄の要約統計の一括取得のユースケースインターフェースを定義します。
+// Goの慣例に従い、インターフェースは利用者（handler）側で定義します。
+type DailyStatsUsecase interface {
+	GetDailyStats(ctx context.Context, codes []string) (candles.DailyStatsResult, error)
+}
+
+// DailyStatsHandler は複数銘柄の要約統計（ロールアップ）を返すエンドポイントを処理します。
+type DailyStatsHandler struct {
+	uc DailyStatsUsecase
+}
+
+// NewDailyStatsHandler は DailyStatsHandler を生成します。
+func NewDailyStatsHandler(uc DailyStatsUsecase) *DailyStatsHandler {
+	return &DailyStatsHandler{uc: uc}
+}
+
+// List はカンマ区切りの銘柄コードを受け取り、各銘柄の要約統計をまとめてJSONで返します（管理ダッシュボード向け）。
+// 解決できない銘柄はエラーにせず unknown に、利用者のプランでは参照できない銘柄は errors に入れて返します。
+//
+// エンドポイント例:
+// GET /stats?symbols=AAPL,7203.T
+func (h *DailyStatsHandler) List(w http.ResponseWriter, r *http.Request) {
+	codes, ok := parseSymbols(w, r, MaxDailyStatsSymbols)
+	if !ok {
+		return
+	}
+
+	res, err := h.uc.GetDailyStats(r.Context(), codes)
+	if err != nil {
+		httpx.WriteError(w, err, "failed to get daily stats", "symbols", len(codes))
+		return
+	}
+
+	out := api.DailyStatsBatchResponse{
+		Stats:   make([]api.SymbolDailyStats, 0, len(res.Stats)),
+		Unknown: res.Unknown,
+		Errors:  make([]api.SymbolError, 0, len(res.Locked)),
+	}
+	if out.Unknown == nil {
+		out.Unknown = []string{}
+	}
+	for _, code := range res.Locked {
+		out.Errors = append(out.Errors, lockedSymbol(code))
+	}
+	for _, s := range res.Stats {
+		out.Stats = append(out.Stats, api.SymbolDailyStats{
+			Symbol:           s.SymbolCode,
+			AsOf:             api.NewDate(s.AsOf),
+			High52w:          s.High52W,
+			Low52w:           s.Low52W,
+			AvgVolume30d:     s.AvgVolume30D,
+			YtdChangePercent: s.YTDChangePercent,
+			ComputedAt:       api.NewTimestamp(s.ComputedAt),
+		})
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
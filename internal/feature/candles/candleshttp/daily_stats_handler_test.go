@@ -0,0 +1,117 @@
+package candleshttp_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/candleshttp"
+)
+
+// mockDailyStatsUsecase は DailyStatsUsecase インターフェースのモック実装です。
+type mockDailyStatsUsecase struct {
+	GetDailyStatsFunc func(ctx context.Context, codes []string) (candles.DailyStatsResult, error)
+}
+
+func (m *mockDailyStatsUsecase) GetDailyStats(ctx context.Context, codes []string) (candles.DailyStatsResult, error) {
+	return m.GetDailyStatsFunc(ctx, codes)
+}
+
+func newDailyStatsRouter(uc candleshttp.DailyStatsUsecase) http.Handler {
+	h := candleshttp.NewDailyStatsHandler(uc)
+	r := chi.NewRouter()
+	r.Get("/stats", h.List)
+	return r
+}
+
+func TestDailyStatsHandler_List(t *testing.T) {
+	t.Parallel()
+
+	ytd := 12.5
+	asOf := time.Date(2024, 5, 14, 0, 0, 0, 0, time.UTC)
+	computed := time.Date(2024, 5, 15, 6, 0, 0, 0, time.UTC)
+
+	t.Run("success: stats, unknown and locked", func(t *testing.T) {
+		t.Parallel()
+		uc := &mockDailyStatsUsecase{GetDailyStatsFunc: func(ctx context.Context, codes []string) (candles.DailyStatsResult, error) {
+			assert.Equal(t, []string{"AAPL", "7203.T", "ZZZZ"}, codes)
+			return candles.DailyStatsResult{
+				Stats: []candles.DailyStats{
+					{SymbolCode: "AAPL", AsOf: asOf, High52W: 200, Low52W: 150, AvgVolume30D: 1000.5, YTDChangePercent: &ytd, ComputedAt: computed},
+					{SymbolCode: "7203.T", AsOf: asOf, High52W: 3000, Low52W: 2000, AvgVolume30D: 50, ComputedAt: computed},
+				},
+				Unknown: []string{"ZZZZ"},
+				Locked:  []string{"NVDA"},
+			}, nil
+		}}
+		w := httptest.NewRecorder()
+		newDailyStatsRouter(uc).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stats?symbols=AAPL,+7203.T,ZZZZ", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"stats":[
+			{"symbol":"AAPL","as_of":"2024-05-14","high_52w":200,"low_52w":150,"avg_volume_30d":1000.5,
+			 "ytd_change_percent":12.5,"computed_at":"2024-05-15T06:00:00Z"},
+			{"symbol":"7203.T","as_of":"2024-05-14","high_52w":3000,"low_52w":2000,"avg_volume_30d":50,
+			 "ytd_change_percent":null,"computed_at":"2024-05-15T06:00:00Z"}],
+			"unknown":["ZZZZ"],
+			"errors":[{"symbol":"NVDA","error":"upgrade_required"}]}`, w.Body.String())
+	})
+
+	t.Run("success: nothing found returns empty lists", func(t *testing.T) {
+		t.Parallel()
+		uc := &mockDailyStatsUsecase{GetDailyStatsFunc: func(ctx context.Context, codes []string) (candles.DailyStatsResult, error) {
+			return candles.DailyStatsResult{}, nil
+		}}
+		w := httptest.NewRecorder()
+		newDailyStatsRouter(uc).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stats?symbols=NEWCO", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"stats":[],"unknown":[],"errors":[]}`, w.Body.String())
+	})
+
+	t.Run("error: usecase failure returns 500", func(t *testing.T) {
+		t.Parallel()
+		uc := &mockDailyStatsUsecase{GetDailyStatsFunc: func(ctx context.Context, codes []string) (candles.DailyStatsResult, error) {
+			return candles.DailyStatsResult{}, errors.New("db down")
+		}}
+		w := httptest.NewRecorder()
+		newDailyStatsRouter(uc).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stats?symbols=AAPL", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	overCap := make([]string, candleshttp.MaxDailyStatsSymbols+1)
+	for i := range overCap {
+		overCap[i] = "AAPL"
+	}
+	for _, tt := range []struct {
+		name         string
+		url          string
+		expectedBody string
+	}{
+		{name: "missing symbols", url: "/stats", expectedBody: `{"error":"symbols must list 1 to 100 codes"}`},
+		{name: "over the cap", url: "/stats?symbols=" + strings.Join(overCap, ","), expectedBody: `{"error":"symbols must list 1 to 100 codes"}`},
+		{name: "invalid code", url: "/stats?symbols=AAPL,7203%26T", expectedBody: `{"error":"invalid symbol code"}`},
+	} {
+		t.Run("error: "+tt.name+" returns 400", func(t *testing.T) {
+			t.Parallel()
+			uc := &mockDailyStatsUsecase{GetDailyStatsFunc: func(ctx context.Context, codes []string) (candles.DailyStatsResult, error) {
+				t.Error("GetDailyStats should not be called")
+				return candles.DailyStatsResult{}, nil
+			}}
+			w := httptest.NewRecorder()
+			newDailyStatsRouter(uc).ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
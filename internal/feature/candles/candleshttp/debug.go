@@ -0,0 +1,73 @@
+package candleshttp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/apikey"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+)
+
+// debugRequestHeader はリクエストごとにデバッグ用のヘッダーを要求するリクエストヘッダーです（値は "1" または "true"）。
+// APIキーのクライアントと、なりすましトークンを使う管理者のリクエストでのみ有効です。
+const debugRequestHeader = "X-Debug"
+
+// デバッグ用のヘッダーです（クライアントから「チャートが遅い・古い」原因を調べるため）。
+const (
+	cacheStatusHeader  = "X-Cache-Status" // ローソク足を返した層（l1 / redis / db）
+	cacheKeyHeader     = "X-Cache-Key"    // 取得元のキャッシュキーの SHA-256 の先頭 16 桁（キーの形式を公開しない）
+	timingHeaderPrefix = "X-Timing-"      // 段階ごとの所要時間（ミリ秒）。例: X-Timing-Cache-Get
+)
+
+// WithDebugHeaders は always が true の場合、ローソク足のすべての応答にデバッグ用のヘッダーを付けます（開発・検証環境向け）。
+// false の場合も、APIキーのクライアント・管理者のなりすましのリクエストは X-Debug で要求できます。
+func (h *Handler) WithDebugHeaders(always bool) *Handler {
+	h.debugAlways = always
+	return h
+}
+
+// trace は r の応答にデバッグ用のヘッダーを付ける場合に candles.Trace を格納した context とその Trace を返します。
+// 付けない場合は r の context と nil を返します（記録しないため、キャッシュ・リポジトリの処理は増えません）。
+func (h *Handler) trace(r *http.Request) (context.Context, *candles.Trace) {
+	if !h.debugAlways && !debugRequested(r) {
+		return r.Context(), nil
+	}
+	return candles.WithTrace(r.Context())
+}
+
+// debugRequested は r が X-Debug でデバッグ用のヘッダーを要求し、その権限を持つかを返します。
+// ユーザー（JWT）の要求は、キャッシュの構成を一般の利用者に見せないため無視します。
+func debugRequested(r *http.Request) bool {
+	v, err := strconv.ParseBool(r.Header.Get(debugRequestHeader))
+	if err != nil || !v {
+		return false
+	}
+	if _, ok := apikey.PrincipalFromContext(r.Context()); ok {
+		return true
+	}
+	_, ok := jwt.ActorFromContext(r.Context())
+	return ok
+}
+
+// writeTraceHeaders は tr に記録した取得元と所要時間をデバッグ用のヘッダーに設定します。tr が nil の場合は何もしません。
+func writeTraceHeaders(w http.ResponseWriter, tr *candles.Trace) {
+	if tr == nil {
+		return
+	}
+	res := tr.Result()
+	if res.Source != "" {
+		w.Header().Set(cacheStatusHeader, string(res.Source))
+	}
+	if res.Key != "" {
+		sum := sha256.Sum256([]byte(res.Key))
+		w.Header().Set(cacheKeyHeader, hex.EncodeToString(sum[:8]))
+	}
+	for _, t := range res.Timings {
+		ms := float64(t.Duration.Microseconds()) / 1000
+		w.Header().Set(timingHeaderPrefix+t.Stage.String(), strconv.FormatFloat(ms, 'f', 3, 64))
+	}
+}
@@ -0,0 +1,140 @@
+package candleshttp_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/candleshttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/apikey"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+)
+
+// tracingUsecase は context の candles.Trace にキャッシュ・リポジトリと同じ形で書き込むモックを返します。
+func tracingUsecase(source candles.TraceSource, key string, timings map[candles.TraceStage]time.Duration) *mockUsecase {
+	return &mockUsecase{GetCandlesFunc: func(ctx context.Context, _, _ string, _ int) ([]candles.Candle, error) {
+		tr := candles.TraceFromContext(ctx)
+		tr.SetSource(source, key)
+		for stage, d := range timings {
+			tr.Add(stage, d)
+		}
+		return []candles.Candle{}, nil
+	}}
+}
+
+func serveDebug(t *testing.T, h *candleshttp.Handler, withContext func(context.Context) context.Context, debug string) http.Header {
+	t.Helper()
+	router := chi.NewRouter()
+	router.Get("/candles/{code}", h.GetCandlesHandler)
+	req := httptest.NewRequest(http.MethodGet, "/candles/AAPL", nil)
+	if debug != "" {
+		req.Header.Set("X-Debug", debug)
+	}
+	req = req.WithContext(withContext(req.Context()))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	return w.Header()
+}
+
+func asAPIKey(ctx context.Context) context.Context {
+	return apikey.WithPrincipal(ctx, apikey.Principal{KeyID: "mobile-debug", Scopes: []string{apikey.ScopeCandlesRead}})
+}
+
+func asUser(ctx context.Context) context.Context { return jwt.WithUserID(ctx, 1) }
+
+// TestCandlesHandler_DebugHeaders は取得元の層ごとにデバッグ用のヘッダーを返すことを検証します。
+func TestCandlesHandler_DebugHeaders(t *testing.T) {
+	t.Parallel()
+	const key = "candles:AAPL:1day:n200"
+	sum := sha256.Sum256([]byte(key))
+	hashed := hex.EncodeToString(sum[:8])
+
+	tests := []struct {
+		name    string
+		source  candles.TraceSource
+		key     string
+		timings map[candles.TraceStage]time.Duration
+		want    map[string]string
+	}{
+		{name: "l1", source: candles.TraceL1, key: key, want: map[string]string{
+			"X-Cache-Status": "l1", "X-Cache-Key": hashed,
+		}},
+		{name: "redis", source: candles.TraceRedis, key: key, timings: map[candles.TraceStage]time.Duration{candles.TraceCacheGet: 1500 * time.Microsecond}, want: map[string]string{
+			"X-Cache-Status": "redis", "X-Cache-Key": hashed, "X-Timing-Cache-Get": "1.500",
+		}},
+		{name: "db", source: candles.TraceDB, key: key, timings: map[candles.TraceStage]time.Duration{candles.TraceCacheGet: time.Millisecond, candles.TraceDBQuery: 12 * time.Millisecond}, want: map[string]string{
+			"X-Cache-Status": "db", "X-Cache-Key": hashed, "X-Timing-Cache-Get": "1.000", "X-Timing-DB-Query": "12.000",
+		}},
+		{name: "db without cache", source: candles.TraceDB, timings: map[candles.TraceStage]time.Duration{candles.TraceDBQuery: 3 * time.Millisecond}, want: map[string]string{
+			"X-Cache-Status": "db", "X-Timing-DB-Query": "3.000",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := candleshttp.NewHandler(tracingUsecase(tt.source, tt.key, tt.timings), nil, nil)
+			got := serveDebug(t, h, asAPIKey, "1")
+			for name, want := range tt.want {
+				assert.Equal(t, want, got.Get(name), name)
+			}
+			for _, name := range []string{"X-Cache-Key", "X-Timing-Cache-Get", "X-Timing-DB-Query", "X-Timing-Upstream"} {
+				if _, ok := tt.want[name]; !ok {
+					assert.Empty(t, got.Get(name), name)
+				}
+			}
+		})
+	}
+}
+
+// TestCandlesHandler_DebugHeaders_Gating は X-Debug をAPIキー・管理者のなりすましだけに許可し、
+// 無効な場合は記録もヘッダーも付けないことを検証します。
+func TestCandlesHandler_DebugHeaders_Gating(t *testing.T) {
+	t.Parallel()
+	impersonating := func(ctx context.Context) context.Context { return jwt.WithActor(asUser(ctx), "12") }
+
+	tests := []struct {
+		name        string
+		always      bool
+		withContext func(context.Context) context.Context
+		debug       string
+		want        bool
+	}{
+		{name: "api key with X-Debug", withContext: asAPIKey, debug: "true", want: true},
+		{name: "impersonating admin with X-Debug", withContext: impersonating, debug: "1", want: true},
+		{name: "user with X-Debug is ignored", withContext: asUser, debug: "1", want: false},
+		{name: "api key without X-Debug", withContext: asAPIKey, want: false},
+		{name: "api key with X-Debug false", withContext: asAPIKey, debug: "0", want: false},
+		{name: "enabled by config", always: true, withContext: asUser, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var traced bool
+			uc := &mockUsecase{GetCandlesFunc: func(ctx context.Context, _, _ string, _ int) ([]candles.Candle, error) {
+				tr := candles.TraceFromContext(ctx)
+				traced = tr != nil
+				tr.SetSource(candles.TraceRedis, "k")
+				return []candles.Candle{}, nil
+			}}
+			h := candleshttp.NewHandler(uc, nil, nil).WithDebugHeaders(tt.always)
+			got := serveDebug(t, h, tt.withContext, tt.debug)
+
+			assert.Equal(t, tt.want, traced, "無効な場合は Trace を格納しない")
+			if tt.want {
+				assert.Equal(t, "redis", got.Get("X-Cache-Status"))
+			} else {
+				assert.Empty(t, got.Get("X-Cache-Status"))
+				assert.Empty(t, got.Get("X-Cache-Key"))
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package candleshttp
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+)
+
+// DedupeUsecase は重複したローソク足の解消のユースケースインターフェースを定義します。
+// Goの慣例に従い、インターフェースは利用者（handler）側で定義します。
+type DedupeUsecase interface {
+	Dedupe(ctx context.Context, symbol string, dryRun bool) (candles.DedupeResult, error)
+}
+
+// DedupeHandler は重複したローソク足の解消エンドポイントを処理します。
+// 認可（candles:admin スコープ）はルーター側のミドルウェアで行います。
+type DedupeHandler struct {
+	uc DedupeUsecase
+}
+
+// NewDedupeHandler は DedupeHandler を生成します。
+func NewDedupeHandler(uc DedupeUsecase) *DedupeHandler {
+	return &DedupeHandler{uc: uc}
+}
+
+// Dedupe は ?symbol=（省略時は全銘柄）の重複を解消し、結果を返します。
+// ?dry_run= は既定で true で、誤って削除しないよう明示的に false を指定した場合のみ削除します。
+func (h *DedupeHandler) Dedupe(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	dryRun := true
+	if raw := q.Get("dry_run"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "dry_run must be true or false"})
+			return
+		}
+		dryRun = v
+	}
+	symbol := q.Get("symbol")
+
+	res, err := h.uc.Dedupe(r.Context(), symbol, dryRun)
+	if err != nil {
+		httpx.WriteError(w, err, "failed to dedupe candles", "symbol", symbol, "dry_run", dryRun)
+		return
+	}
+
+	groups := make([]api.DuplicateCandleGroup, 0, len(res.Groups))
+	for _, g := range res.Groups {
+		groups = append(groups, api.DuplicateCandleGroup{
+			Symbol:     g.SymbolCode,
+			Interval:   g.Interval,
+			Time:       api.NewTimestamp(g.Time),
+			KeptId:     g.KeptID,
+			DeletedIds: g.DeletedIDs,
+		})
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	httpx.WriteJSON(w, http.StatusOK, api.DedupeResult{
+		DryRun:         res.DryRun,
+		Symbols:        res.Symbols,
+		GroupsAffected: res.GroupsAffected,
+		RowsDeleted:    res.RowsDeleted,
+		Groups:         groups,
+		Truncated:      res.Truncated,
+	})
+}
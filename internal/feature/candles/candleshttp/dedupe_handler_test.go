@@ -0,0 +1,103 @@
+package candleshttp_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/candleshttp"
+)
+
+// mockDedupeUsecase は DedupeUsecase インターフェースのモック実装です。受け取った引数を記録します。
+type mockDedupeUsecase struct {
+	err    error
+	called bool
+	symbol string
+	dryRun bool
+}
+
+func (m *mockDedupeUsecase) Dedupe(_ context.Context, symbol string, dryRun bool) (candles.DedupeResult, error) {
+	m.called, m.symbol, m.dryRun = true, symbol, dryRun
+	if m.err != nil {
+		return candles.DedupeResult{}, m.err
+	}
+	return candles.DedupeResult{
+		DryRun:         dryRun,
+		Symbols:        1,
+		GroupsAffected: 1,
+		RowsDeleted:    2,
+		Groups: []candles.DuplicateGroup{{
+			SymbolCode: "AAPL", Interval: "1day", Time: time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC),
+			KeptID: 9, DeletedIDs: []int64{3, 5},
+		}},
+	}, nil
+}
+
+func TestDedupeHandler_Dedupe(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		url        string
+		err        error
+		wantCode   int
+		wantBody   string
+		wantCalled bool
+		wantSymbol string
+		wantDryRun bool
+	}{
+		{
+			name:       "dry run by default",
+			url:        "/admin/candles/dedupe?symbol=AAPL",
+			wantCode:   http.StatusOK,
+			wantBody:   `"groups":[{"deletedIds":[3,5],"interval":"1day","keptId":9,"symbol":"AAPL","time":"2024-06-10T00:00:00Z"}]`,
+			wantCalled: true,
+			wantSymbol: "AAPL",
+			wantDryRun: true,
+		},
+		{
+			name:       "delete all symbols",
+			url:        "/admin/candles/dedupe?dry_run=false",
+			wantCode:   http.StatusOK,
+			wantBody:   `"dryRun":false`,
+			wantCalled: true,
+		},
+		{
+			name:     "invalid dry_run",
+			url:      "/admin/candles/dedupe?dry_run=maybe",
+			wantCode: http.StatusBadRequest,
+			wantBody: `"dry_run must be true or false"`,
+		},
+		{
+			name:       "usecase error",
+			url:        "/admin/candles/dedupe?dry_run=false",
+			err:        errors.New("db down"),
+			wantCode:   http.StatusInternalServerError,
+			wantCalled: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			uc := &mockDedupeUsecase{err: tt.err}
+			h := candleshttp.NewDedupeHandler(uc)
+
+			w := httptest.NewRecorder()
+			h.Dedupe(w, httptest.NewRequest(http.MethodPost, tt.url, nil))
+
+			assert.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+			assert.Equal(t, tt.wantCalled, uc.called)
+			if tt.wantCalled {
+				assert.Equal(t, tt.wantSymbol, uc.symbol)
+				assert.Equal(t, tt.wantDryRun, uc.dryRun)
+			}
+		})
+	}
+}
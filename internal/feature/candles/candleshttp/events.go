@@ -0,0 +1,121 @@
+package candleshttp
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+)
+
+// eventsWarningHeader は ?with_events=true でイベントを取得できなかった場合に設定するレスポンスヘッダーです。
+const eventsWarningHeader = "X-Events-Warning"
+
+// WarningEventsUnavailable はイベントを取得できず、events を付けずに足を返したことを示す X-Events-Warning の値です。
+const WarningEventsUnavailable = "events_unavailable"
+
+// Event はローソク足に重ねるコーポレートイベント（配当の権利落ち日・決算発表日）です。
+type Event struct {
+	Type     string    // "dividend" / "earnings"
+	Date     time.Time // UTC の暦日
+	Value    *float64
+	Estimate *float64
+	Source   string
+}
+
+// EventSource は銘柄のコーポレートイベントを提供します（?with_events=true）。
+// candleshttp が events feature に直接依存しないよう、合成ルートで適合させて注入します。
+type EventSource interface {
+	// Between は銘柄 symbol の from 〜 to（両端を含む、UTC の暦日）のイベントを日付順に返します。
+	Between(ctx context.Context, symbol string, from, to time.Time) ([]Event, error)
+}
+
+// WithEventSource は ?with_events=true で足に重ねるイベントの取得元を設定します。
+// 未設定の場合 ?with_events=true は 400 になります。
+func (h *Handler) WithEventSource(src EventSource) *Handler {
+	h.events = src
+	return h
+}
+
+// withEvents は ?with_events=（true / false）を解釈します。未指定の場合は false です。
+// 真偽値として解釈できない場合、またはイベントの取得元が未設定で true の場合は 400 を書き込み ok=false を返します。
+func (h *Handler) withEvents(w http.ResponseWriter, r *http.Request) (bool, bool) {
+	raw := r.URL.Query().Get("with_events")
+	if raw == "" {
+		return false, true
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "with_events must be true or false"})
+		return false, false
+	}
+	if v && h.events == nil {
+		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "with_events is not available"})
+		return false, false
+	}
+	return v, true
+}
+
+// overlayEvents は out の足の期間（最古〜最新の足の日付）のイベントを取得し、各足の Events に付けます。
+// 取得に失敗した場合は足をそのまま返せるよう Events を付けず、X-Events-Warning を設定します。
+func (h *Handler) overlayEvents(w http.ResponseWriter, r *http.Request, code string, out []api.CandleResponse) {
+	if len(out) == 0 {
+		return
+	}
+	first, last := out[0].Time.Time, out[0].Time.Time
+	for _, c := range out[1:] {
+		if c.Time.Before(first) {
+			first = c.Time.Time
+		}
+		if c.Time.After(last) {
+			last = c.Time.Time
+		}
+	}
+	evs, err := h.events.Between(r.Context(), code, first, last)
+	if err != nil {
+		slog.WarnContext(r.Context(), "failed to load events for candles", "code", code, "error", err)
+		w.Header().Set(eventsWarningHeader, WarningEventsUnavailable)
+		return
+	}
+	attachEvents(out, evs)
+}
+
+// attachEvents は各イベントを、日付が一致する足、なければ直前の足（休場日のイベント、週足・月足の期間内のイベント）に付けます。
+// 最古の足より前・最新の足より後のイベントは付けません。out の並び順（昇順・降順）は問いません。
+func attachEvents(out []api.CandleResponse, evs []Event) {
+	if len(out) == 0 {
+		return
+	}
+	order := make([]int, len(out))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return out[order[a]].Time.Before(out[order[b]].Time.Time) })
+	last := out[order[len(order)-1]].Time.Time
+
+	for _, e := range evs {
+		if e.Date.After(last) {
+			continue
+		}
+		// 日付が e.Date 以下の最新の足
+		k := sort.Search(len(order), func(i int) bool { return out[order[i]].Time.After(e.Date) }) - 1
+		if k < 0 {
+			continue
+		}
+		c := &out[order[k]]
+		if c.Events == nil {
+			c.Events = &[]api.CorporateEvent{}
+		}
+		*c.Events = append(*c.Events, api.CorporateEvent{
+			Type:     api.CorporateEventType(e.Type),
+			Date:     api.NewDate(e.Date),
+			Value:    e.Value,
+			Estimate: e.Estimate,
+			Source:   e.Source,
+		})
+	}
+}
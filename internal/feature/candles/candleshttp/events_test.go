@@ -0,0 +1,152 @@
+package candleshttp_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/candleshttp"
+)
+
+// fakeEventSource は Between の引数を記録し、範囲内の events を返す EventSource です。
+type fakeEventSource struct {
+	events   []candleshttp.Event
+	err      error
+	from, to time.Time
+}
+
+func (f *fakeEventSource) Between(ctx context.Context, symbol string, from, to time.Time) ([]candleshttp.Event, error) {
+	f.from, f.to = from, to
+	if f.err != nil {
+		return nil, f.err
+	}
+	var out []candleshttp.Event
+	for _, e := range f.events {
+		if !e.Date.Before(from) && !e.Date.After(to) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func utcDay(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func serveWithEvents(t *testing.T, cs []candles.Candle, src *fakeEventSource, url string) *httptest.ResponseRecorder {
+	t.Helper()
+	uc := &mockUsecase{GetCandlesFunc: func(ctx context.Context, symbol, interval string, outputsize int) ([]candles.Candle, error) {
+		return cs, nil
+	}}
+	h := candleshttp.NewHandler(uc, nil, nil)
+	if src != nil {
+		h = h.WithEventSource(src)
+	}
+	router := chi.NewRouter()
+	router.Get("/candles/{code}", h.GetCandlesHandler)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+	return w
+}
+
+// TestCandlesHandler_WithEvents_Daily は日足で、同じ日付の足にイベントが付くことを検証します。
+func TestCandlesHandler_WithEvents_Daily(t *testing.T) {
+	t.Parallel()
+
+	amount, estimate := 0.26, 1.6
+	src := &fakeEventSource{events: []candleshttp.Event{
+		{Type: "dividend", Date: utcDay(2026, 5, 11), Value: &amount, Source: "twelvedata"},
+		{Type: "earnings", Date: utcDay(2026, 5, 12), Estimate: &estimate, Source: "twelvedata"},
+	}}
+	// ローソク足は新しい順（API の並び）でも突き合わせられる
+	cs := []candles.Candle{
+		{Time: utcDay(2026, 5, 12), Open: 1, High: 1, Low: 1, Close: 1, Volume: 10},
+		{Time: utcDay(2026, 5, 11), Open: 2, High: 2, Low: 2, Close: 2, Volume: 20},
+	}
+
+	w := serveWithEvents(t, cs, src, "/candles/AAPL?with_events=true")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, utcDay(2026, 5, 11), src.from, "最古〜最新の足の期間で取得する")
+	assert.Equal(t, utcDay(2026, 5, 12), src.to)
+	assert.JSONEq(t, `[
+		{"time":"2026-05-12","open":1,"high":1,"low":1,"close":1,"volume":10,
+		 "events":[{"type":"earnings","date":"2026-05-12","value":null,"estimate":1.6,"source":"twelvedata"}]},
+		{"time":"2026-05-11","open":2,"high":2,"low":2,"close":2,"volume":20,
+		 "events":[{"type":"dividend","date":"2026-05-11","value":0.26,"estimate":null,"source":"twelvedata"}]}
+	]`, w.Body.String())
+	assert.Empty(t, w.Header().Get("X-Events-Warning"))
+}
+
+// TestCandlesHandler_WithEvents_Weekly は足の間（休場日・週足の期間内）のイベントが直前の足に付き、
+// 最古の足より前・最新の足より後のイベントは付かないことを検証します。
+func TestCandlesHandler_WithEvents_Weekly(t *testing.T) {
+	t.Parallel()
+
+	src := &fakeEventSource{events: []candleshttp.Event{
+		{Type: "earnings", Date: utcDay(2026, 4, 30), Source: "twelvedata"}, // 4/27 週の木曜
+		{Type: "dividend", Date: utcDay(2026, 5, 4), Source: "twelvedata"},  // 5/4 週の足と同じ日
+		{Type: "dividend", Date: utcDay(2026, 5, 9), Source: "twelvedata"},  // 5/4 週の土曜
+	}}
+	cs := []candles.Candle{
+		{Time: utcDay(2026, 4, 27), Close: 1},
+		{Time: utcDay(2026, 5, 4), Close: 2},
+		{Time: utcDay(2026, 5, 11), Close: 3},
+	}
+
+	w := serveWithEvents(t, cs, src, "/candles/AAPL?interval=1week&with_events=1")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[
+		{"time":"2026-04-27","open":0,"high":0,"low":0,"close":1,"volume":0,
+		 "events":[{"type":"earnings","date":"2026-04-30","value":null,"estimate":null,"source":"twelvedata"}]},
+		{"time":"2026-05-04","open":0,"high":0,"low":0,"close":2,"volume":0,
+		 "events":[
+			{"type":"dividend","date":"2026-05-04","value":null,"estimate":null,"source":"twelvedata"},
+			{"type":"dividend","date":"2026-05-09","value":null,"estimate":null,"source":"twelvedata"}
+		 ]},
+		{"time":"2026-05-11","open":0,"high":0,"low":0,"close":3,"volume":0}
+	]`, w.Body.String())
+}
+
+// TestCandlesHandler_WithEvents_NotRequested は未指定・false ではイベントを取得も付与もしないことを検証します。
+func TestCandlesHandler_WithEvents_NotRequested(t *testing.T) {
+	t.Parallel()
+
+	for _, url := range []string{"/candles/AAPL", "/candles/AAPL?with_events=false"} {
+		src := &fakeEventSource{events: []candleshttp.Event{{Type: "dividend", Date: utcDay(2026, 5, 11), Source: "twelvedata"}}}
+		w := serveWithEvents(t, []candles.Candle{{Time: utcDay(2026, 5, 11)}}, src, url)
+		require.Equal(t, http.StatusOK, w.Code, url)
+		assert.NotContains(t, w.Body.String(), "events", url)
+		assert.True(t, src.from.IsZero(), "取得しない: %s", url)
+	}
+}
+
+// TestCandlesHandler_WithEvents_SourceError はイベントを取得できない場合に、足をそのまま返して警告することを検証します。
+func TestCandlesHandler_WithEvents_SourceError(t *testing.T) {
+	t.Parallel()
+
+	src := &fakeEventSource{err: errors.New("db down")}
+	w := serveWithEvents(t, []candles.Candle{{Time: utcDay(2026, 5, 11), Close: 1}}, src, "/candles/AAPL?with_events=true")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{"time":"2026-05-11","open":0,"high":0,"low":0,"close":1,"volume":0}]`, w.Body.String())
+	assert.Equal(t, candleshttp.WarningEventsUnavailable, w.Header().Get("X-Events-Warning"))
+}
+
+// TestCandlesHandler_WithEvents_BadRequest は解釈できない値と、取得元が未設定の場合に 400 を返すことを検証します。
+func TestCandlesHandler_WithEvents_BadRequest(t *testing.T) {
+	t.Parallel()
+
+	w := serveWithEvents(t, nil, &fakeEventSource{}, "/candles/AAPL?with_events=yes")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"with_events must be true or false"}`, w.Body.String())
+
+	w = serveWithEvents(t, nil, nil, "/candles/AAPL?with_events=true")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
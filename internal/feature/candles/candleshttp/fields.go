@@ -0,0 +1,95 @@
+package candleshttp
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+)
+
+// candleField は ?fields= で選べるローソク足の項目のビット集合です。ゼロ値は「指定なし（全項目）」を表します。
+type candleField uint8
+
+const (
+	fieldTime candleField = 1 << iota
+	fieldOpen
+	fieldHigh
+	fieldLow
+	fieldClose
+	fieldVolume
+)
+
+// candleFieldNames は ?fields= に指定できる項目名です。
+var candleFieldNames = map[string]candleField{
+	"time":   fieldTime,
+	"open":   fieldOpen,
+	"high":   fieldHigh,
+	"low":    fieldLow,
+	"close":  fieldClose,
+	"volume": fieldVolume,
+}
+
+// candleFields は ?fields=（time, open, high, low, close, volume のカンマ区切り）を解釈します。
+// 未指定の場合はゼロ値（全項目）を返します。空の指定・未知の項目名は 400 を書き込み ok=false を返します。
+func candleFields(w http.ResponseWriter, r *http.Request) (candleField, bool) {
+	q := r.URL.Query()
+	if !q.Has("fields") {
+		return 0, true
+	}
+	var set candleField
+	for name := range strings.SplitSeq(q.Get("fields"), ",") {
+		f, ok := candleFieldNames[strings.TrimSpace(name)]
+		if !ok {
+			httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "fields must be a comma-separated list of time, open, high, low, close, volume"})
+			return 0, false
+		}
+		set |= f
+	}
+	return set, true
+}
+
+// projectedCandle は ?fields= で項目を絞ったローソク足です。
+// 選ばなかった項目は nil にして omitempty で省略します。項目の順序は構造体の定義順（api.CandleResponse と同じ
+// time, open, high, low, close, volume, source, events）で固定され、?fields= の並びには依存しません。
+type projectedCandle struct {
+	Time   *api.Date             `json:"time,omitempty"`
+	Open   *float64              `json:"open,omitempty"`
+	High   *float64              `json:"high,omitempty"`
+	Low    *float64              `json:"low,omitempty"`
+	Close  *float64              `json:"close,omitempty"`
+	Volume *int64                `json:"volume,omitempty"`
+	Source *string               `json:"source,omitempty"`
+	Events *[]api.CorporateEvent `json:"events,omitempty"`
+}
+
+// projectCandles は out を fields の項目に絞ります。source（?as_of=）・events（?with_events=true）は項目の指定に関わらずそのまま残します。
+// 値は out の要素を指すため、out を書き換えずに書き出しまで保持してください。
+func projectCandles(out []api.CandleResponse, fields candleField) []projectedCandle {
+	ps := make([]projectedCandle, len(out))
+	for i := range out {
+		c := &out[i]
+		p := &ps[i]
+		if fields&fieldTime != 0 {
+			p.Time = &c.Time
+		}
+		if fields&fieldOpen != 0 {
+			p.Open = &c.Open
+		}
+		if fields&fieldHigh != 0 {
+			p.High = &c.High
+		}
+		if fields&fieldLow != 0 {
+			p.Low = &c.Low
+		}
+		if fields&fieldClose != 0 {
+			p.Close = &c.Close
+		}
+		if fields&fieldVolume != 0 {
+			p.Volume = &c.Volume
+		}
+		p.Source = c.Source
+		p.Events = c.Events
+	}
+	return ps
+}
@@ -0,0 +1,135 @@
+package candleshttp_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/candleshttp"
+)
+
+// TestCandlesHandler_Fields は ?fields= で指定した項目だけを返すことを検証します。
+func TestCandlesHandler_Fields(t *testing.T) {
+	t.Parallel()
+
+	cs := []candles.Candle{{Time: utcDay(2026, 5, 12), Open: 100, High: 110, Low: 90, Close: 105, Volume: 1000}}
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantBody   string
+	}{
+		{"未指定は全項目", "", http.StatusOK, `[{"time":"2026-05-12","open":100,"high":110,"low":90,"close":105,"volume":1000}]`},
+		{"time のみ", "?fields=time", http.StatusOK, `[{"time":"2026-05-12"}]`},
+		{"open のみ", "?fields=open", http.StatusOK, `[{"open":100}]`},
+		{"high のみ", "?fields=high", http.StatusOK, `[{"high":110}]`},
+		{"low のみ", "?fields=low", http.StatusOK, `[{"low":90}]`},
+		{"close のみ", "?fields=close", http.StatusOK, `[{"close":105}]`},
+		{"volume のみ", "?fields=volume", http.StatusOK, `[{"volume":1000}]`},
+		{"time と close", "?fields=time,close", http.StatusOK, `[{"time":"2026-05-12","close":105}]`},
+		{"time と close と volume", "?fields=time,close,volume", http.StatusOK, `[{"time":"2026-05-12","close":105,"volume":1000}]`},
+		{"全項目を列挙", "?fields=time,open,high,low,close,volume", http.StatusOK, `[{"time":"2026-05-12","open":100,"high":110,"low":90,"close":105,"volume":1000}]`},
+		{"空白と重複は許す", "?fields=close,%20time,close", http.StatusOK, `[{"time":"2026-05-12","close":105}]`},
+		{"未知の項目名", "?fields=time,price", http.StatusBadRequest, `{"error":"fields must be a comma-separated list of time, open, high, low, close, volume"}`},
+		{"大文字は未知の項目名", "?fields=Close", http.StatusBadRequest, `{"error":"fields must be a comma-separated list of time, open, high, low, close, volume"}`},
+		{"空の指定", "?fields=", http.StatusBadRequest, `{"error":"fields must be a comma-separated list of time, open, high, low, close, volume"}`},
+		{"末尾のカンマ", "?fields=time,", http.StatusBadRequest, `{"error":"fields must be a comma-separated list of time, open, high, low, close, volume"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			w := serveWithEvents(t, cs, nil, "/candles/AAPL"+tt.query)
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
+
+// TestCandlesHandler_Fields_StableOrder は項目の順序が ?fields= の並びによらず固定であることを、JSON の文字列として検証します。
+func TestCandlesHandler_Fields_StableOrder(t *testing.T) {
+	t.Parallel()
+
+	cs := []candles.Candle{{Time: utcDay(2026, 5, 12), Open: 100, High: 110, Low: 90, Close: 105, Volume: 1000}}
+	want := `[{"time":"2026-05-12","close":105,"volume":1000}]` + "\n"
+	for _, q := range []string{"volume,close,time", "close,time,volume", "time,close,volume"} {
+		w := serveWithEvents(t, cs, nil, "/candles/AAPL?fields="+q)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, want, w.Body.String(), q)
+	}
+}
+
+// TestCandlesHandler_Fields_ZeroValues は選んだ項目の値が 0 でも省略しないことを検証します。
+func TestCandlesHandler_Fields_ZeroValues(t *testing.T) {
+	t.Parallel()
+
+	cs := []candles.Candle{{Time: utcDay(2026, 5, 12), Close: 0, Volume: 0}}
+	w := serveWithEvents(t, cs, nil, "/candles/AAPL?fields=close,volume")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{"close":0,"volume":0}]`, w.Body.String())
+}
+
+// TestCandlesHandler_Fields_WithEvents は ?with_events=true の events が ?fields= の指定に関わらず残ることを検証します。
+func TestCandlesHandler_Fields_WithEvents(t *testing.T) {
+	t.Parallel()
+
+	amount := 0.26
+	src := &fakeEventSource{events: []candleshttp.Event{
+		{Type: "dividend", Date: utcDay(2026, 5, 11), Value: &amount, Source: "twelvedata"},
+	}}
+	cs := []candles.Candle{
+		{Time: utcDay(2026, 5, 12), Open: 1, High: 1, Low: 1, Close: 1, Volume: 10},
+		{Time: utcDay(2026, 5, 11), Open: 2, High: 2, Low: 2, Close: 2, Volume: 20},
+	}
+
+	w := serveWithEvents(t, cs, src, "/candles/AAPL?with_events=true&fields=time,close")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[
+		{"time":"2026-05-12","close":1},
+		{"time":"2026-05-11","close":2,"events":[{"type":"dividend","date":"2026-05-11","value":0.26,"estimate":null,"source":"twelvedata"}]}
+	]`, w.Body.String())
+}
+
+// TestCandlesHandler_Fields_ValidatedBeforeFetch は不正な ?fields= ではローソク足を取得しないことを検証します。
+func TestCandlesHandler_Fields_ValidatedBeforeFetch(t *testing.T) {
+	t.Parallel()
+
+	uc := &mockUsecase{GetCandlesFunc: func(context.Context, string, string, int) ([]candles.Candle, error) {
+		t.Error("不正な fields ではローソク足を取得しないこと")
+		return nil, nil
+	}}
+	router := chi.NewRouter()
+	router.Get("/candles/{code}", candleshttp.NewHandler(uc, nil, nil).GetCandlesHandler)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/candles/AAPL?fields=bogus", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// TestCandlesHandler_Fields_PayloadSize は項目を絞るとレスポンスが小さくなることを検証します。
+func TestCandlesHandler_Fields_PayloadSize(t *testing.T) {
+	t.Parallel()
+
+	cs := make([]candles.Candle, 200)
+	start := utcDay(2026, 1, 1)
+	for i := range cs {
+		cs[i] = candles.Candle{Time: start.Add(time.Duration(i) * 24 * time.Hour), Open: 1234.5, High: 1250.25, Low: 1220.75, Close: 1240.5, Volume: 12345678}
+	}
+	size := func(query string) int {
+		w := serveWithEvents(t, cs, nil, "/candles/AAPL"+query)
+		require.Equal(t, http.StatusOK, w.Code)
+		return w.Body.Len()
+	}
+	full := size("")
+	timeClose := size("?fields=time,close")
+	timeCloseVolume := size("?fields=time,close,volume")
+
+	assert.Less(t, timeClose, full/2, "time,close は全項目の半分未満")
+	assert.Less(t, timeCloseVolume, full*2/3, "time,close,volume は全項目の 3 分の 2 未満")
+	assert.Less(t, timeClose, timeCloseVolume)
+}
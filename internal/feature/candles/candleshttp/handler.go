@@ -6,75 +6,438 @@ import (
 	"net/http"
 	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/apikey"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
 )
 
 // symbolCodePattern は銘柄コードとして許可する形式（例: AAPL, 7203.T）。
 // symbols.code が VARCHAR(20) のため最大20文字、英数字と . _ - のみ許可する。
 var symbolCodePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,20}$`)
 
+// resolvedSymbolHeader は入力コードを解決した正規の銘柄コードを返すレスポンスヘッダーです（クライアントのデバッグ用）。
+const resolvedSymbolHeader = "X-Resolved-Symbol"
+
+// symbolStatusHeader は銘柄が active 以外（上場廃止）の場合に状態を返すレスポンスヘッダーです。
+// 上場廃止の銘柄は新しい足が増えないため、クライアントはこのヘッダーで「最新の足が古い」理由を表示できます。
+const symbolStatusHeader = "X-Symbol-Status"
+
 // Usecase はローソク足データ操作のユースケースインターフェースを定義します。
 // Goの慣例に従い、インターフェースは利用者（handler）側で定義します。
 type Usecase interface {
-	GetCandles(ctx context.Context, symbol, interval string, outputsize int) ([]candles.Candle, error)
+	ResolveSymbol(ctx context.Context, symbol string) (string, error)
+	GetCandles(ctx context.Context, symbol, interval string, outputsize int, adjust candles.AdjustMode) ([]candles.Candle, error)
+	GetCandlesAsOf(ctx context.Context, symbol, interval string, outputsize int, asOf time.Time) ([]candles.Candle, error)
+	GetCandlesRange(ctx context.Context, symbol, interval string, rng candles.DateRange, outputsize int, adjust candles.AdjustMode) ([]candles.Candle, error)
+	GetStats(ctx context.Context, symbol, interval string, adjust candles.AdjustMode) (candles.Stats, error)
+	GetSparkline(ctx context.Context, symbol, interval string, outputsize, points int, adjust candles.AdjustMode) (candles.Sparkline, error)
+}
+
+// ViewRecorder は銘柄の閲覧を記録するフックです（最近閲覧した銘柄）。
+// 記録はリクエストを待たせない fire-and-forget で行われる前提です。
+type ViewRecorder interface {
+	Record(userID int64, symbolCode string)
+}
+
+// 通貨換算（?currency=）の結果を返すレスポンスヘッダーです。
+// ローソク足のレスポンスは配列のため、換算情報はボディではなくヘッダーで返します。
+const (
+	currencyHeader        = "X-Currency"
+	fxRateHeader          = "X-FX-Rate"
+	fxRateTimestampHeader = "X-FX-Rate-Timestamp"
+	currencyWarningHeader = "X-Currency-Warning"
+)
+
+// 換算できなかった理由として X-Currency-Warning に設定する値です。
+// 警告時は換算せず、銘柄の元の通貨建ての値を返します。
+const (
+	WarningCurrencyUnknown = "currency_unknown" // 銘柄の通貨が未登録
+	WarningRateUnavailable = "rate_unavailable" // 為替レートを取得できない
+)
+
+// CurrencyConverter は銘柄の価格を指定通貨に換算する手段を提供します。
+type CurrencyConverter interface {
+	// Supported は currency（ISO 4217、大文字）が換算先として利用可能かを返します。
+	Supported(currency string) bool
+	// Resolve は symbol の通貨から currency への換算を解決します。
+	// 換算できない場合は Warning を設定した Conversion を返します。
+	Resolve(ctx context.Context, symbol, currency string) Conversion
+}
+
+// Conversion は 1 銘柄分の通貨換算の解決結果です。
+type Conversion struct {
+	Currency string    // 換算後（警告時は換算前）の通貨。不明な場合は空
+	Rate     float64   // 適用したレート
+	AsOf     time.Time // レートの時刻。固定レートなど時刻がない場合はゼロ値
+	Warning  string    // 換算できなかった理由。空なら換算済み
+	// Convert は価格を換算し、換算先通貨の桁数に丸めます。Warning が空の場合のみ設定されます。
+	Convert func(float64) float64
+}
+
+// SymbolStatusSource は銘柄の状態（"active" / "delisted"）を提供します。
+// candleshttp が symbollist feature に直接依存しないよう、合成ルートで適合させて注入します。
+type SymbolStatusSource interface {
+	// SymbolStatus は銘柄 code の状態を返します。不明な場合は空文字を返します。
+	SymbolStatus(ctx context.Context, code string) (string, error)
 }
 
 // Handler はローソク足データのHTTPリクエストを処理します。
 type Handler struct {
-	uc Usecase
+	uc       Usecase
+	views    ViewRecorder
+	fx       CurrencyConverter
+	events   EventSource
+	statuses SymbolStatusSource
+	symbols  SymbolInfoSource
+	locales  []string
+
+	debugAlways bool // すべての応答にデバッグ用のヘッダーを付ける（WithDebugHeaders）
 }
 
 // NewHandler は指定されたusecaseでHandlerの新しいインスタンスを生成します。
-func NewHandler(uc Usecase) *Handler {
-	return &Handler{uc: uc}
+// views が nil の場合は閲覧を記録しません。fx が nil の場合 ?currency= は 400 になります。
+func NewHandler(uc Usecase, views ViewRecorder, fx CurrencyConverter) *Handler {
+	return &Handler{uc: uc, views: views, fx: fx}
 }
 
 // GetCandlesHandler は銘柄コードと時間間隔を受け取り、ローソク足データをJSONで返します。
 //
 // エンドポイント例:
 // GET /candles/{code}?interval=1day&outputsize=200
+//
+// ?start= / ?end=（YYYY-MM-DD、両端の日を含む）を指定すると、その期間の足を返します（片方のみの指定も可）。
+// ?as_of= を指定すると、その時点で保存されていたローソク足（未調整）を取り込み元（source）付きで返します（APIキーのクライアントのみ）。
+// ?with_events=true を指定すると、足の期間のコーポレートイベントを各足の events に付けます。
+// ?fields=time,close のように項目を指定すると、指定した項目だけを返します（キャッシュは全項目のまま、取得後に絞ります）。
+// ?include=symbol（または v2 の Accept）を指定すると、{symbol, candles} の形で銘柄の名前・市場・状態を含めて返します。
+// デバッグ用のヘッダー（WithDebugHeaders・X-Debug）を付ける場合は、取得元と所要時間を X-Cache-Status・X-Cache-Key・X-Timing-* で返します。
 func (h *Handler) GetCandlesHandler(w http.ResponseWriter, r *http.Request) {
 	code := chi.URLParam(r, "code")
 	if !symbolCodePattern.MatchString(code) {
 		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "invalid symbol code"})
 		return
 	}
-	// 未指定の場合はデフォルト値を使用
+	// 未指定の場合はデフォルト値を使用（outputsize の未指定（0）は usecase が時間間隔ごとの既定値を適用する）
 	interval := queryOrDefault(r, "interval", "1day")
-	outputsizeStr := queryOrDefault(r, "outputsize", "200")
+	outputsizeStr := queryOrDefault(r, "outputsize", "0")
 	// 文字列を整数に変換
 	outputsize, err := strconv.Atoi(outputsizeStr)
 	if err != nil {
 		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "outputsize must be an integer"})
 		return
 	}
+	currency, ok := h.currency(w, r)
+	if !ok {
+		return
+	}
+	adjust, ok := adjustMode(w, r)
+	if !ok {
+		return
+	}
+	asOf, ok := asOfParam(w, r, adjust)
+	if !ok {
+		return
+	}
+	rng, ok := dateRangeParam(w, r, asOf)
+	if !ok {
+		return
+	}
+	withEvents, ok := h.withEvents(w, r)
+	if !ok {
+		return
+	}
+	fields, ok := candleFields(w, r)
+	if !ok {
+		return
+	}
+	withSymbol, ok := h.includeSymbol(w, r)
+	if !ok {
+		return
+	}
 
-	candles, err := h.uc.GetCandles(r.Context(), code, interval, outputsize)
+	code, ok = h.resolve(w, r, code)
+	if !ok {
+		return
+	}
+	var symbol api.CandleSymbol
+	if withSymbol {
+		if symbol, ok = h.symbolInfo(w, r, code); !ok {
+			return
+		}
+	}
+	var cs []candles.Candle
+	ctx, tr := h.trace(r)
+	switch {
+	case !asOf.IsZero():
+		cs, err = h.uc.GetCandlesAsOf(ctx, code, interval, outputsize, asOf)
+	case !rng.IsZero():
+		cs, err = h.uc.GetCandlesRange(ctx, code, interval, rng, outputsize, adjust)
+	default:
+		cs, err = h.uc.GetCandles(ctx, code, interval, outputsize, adjust)
+	}
+	writeTraceHeaders(w, tr)
 	if err != nil {
-		slog.Error("failed to get candles", "error", err, "code", code)
-		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
+		httpx.WriteError(w, err, "failed to get candles", "code", code)
 		return
 	}
+	h.recordView(r, code)
+	convert := h.conversion(w, r, code, currency)
+
+	out := toCandleResponses(cs, convert)
+	if !asOf.IsZero() {
+		// 取り込み元は調査用のため as-of クエリのみで返す（通常の応答・キャッシュの形は変えない）
+		for i := range out {
+			out[i].Source = &cs[i].Source
+		}
+	}
+	if withEvents {
+		h.overlayEvents(w, r, code, out)
+	}
+	switch {
+	case withSymbol && fields != 0:
+		httpx.WriteJSON(w, http.StatusOK, projectedEnvelope{Symbol: symbol, Candles: projectCandles(out, fields)})
+	case withSymbol:
+		httpx.WriteJSON(w, http.StatusOK, api.CandlesEnvelope{Symbol: symbol, Candles: out})
+	case fields != 0:
+		httpx.WriteJSON(w, http.StatusOK, projectCandles(out, fields))
+	default:
+		httpx.WriteJSON(w, http.StatusOK, out)
+	}
+}
 
-	// データをフォーマット
-	out := make([]api.CandleResponse, 0, len(candles))
-	for _, x := range candles {
+// toCandleResponses はローソク足をレスポンスの型に変換します（出来高は通貨に依存しないため換算しない）。
+func toCandleResponses(cs []candles.Candle, convert func(float64) float64) []api.CandleResponse {
+	out := make([]api.CandleResponse, 0, len(cs))
+	for _, x := range cs {
 		out = append(out, api.CandleResponse{
-			Time:   x.Time.UTC().Format("2006-01-02"),
-			Open:   x.Open,
-			High:   x.High,
-			Low:    x.Low,
-			Close:  x.Close,
+			Time:   api.NewDate(x.Time),
+			Open:   convert(x.Open),
+			High:   convert(x.High),
+			Low:    convert(x.Low),
+			Close:  convert(x.Close),
 			Volume: x.Volume,
 		})
 	}
+	return out
+}
+
+// GetStatsHandler は銘柄コードと時間間隔を受け取り、ローソク足の要約統計をJSONで返します。
+//
+// エンドポイント例:
+// GET /candles/{code}/stats?interval=1day
+func (h *Handler) GetStatsHandler(w http.ResponseWriter, r *http.Request) {
+	code := chi.URLParam(r, "code")
+	if !symbolCodePattern.MatchString(code) {
+		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "invalid symbol code"})
+		return
+	}
+	interval := queryOrDefault(r, "interval", "1day")
+	currency, ok := h.currency(w, r)
+	if !ok {
+		return
+	}
+	adjust, ok := adjustMode(w, r)
+	if !ok {
+		return
+	}
+
+	code, ok = h.resolve(w, r, code)
+	if !ok {
+		return
+	}
+	s, err := h.uc.GetStats(r.Context(), code, interval, adjust)
+	if err != nil {
+		httpx.WriteError(w, err, "failed to get candle stats", "code", code)
+		return
+	}
+	convert := h.conversion(w, r, code, currency)
+
+	httpx.WriteJSON(w, http.StatusOK, api.CandleStatsResponse{
+		AsOf:             api.NewDate(s.AsOf),
+		High52w:          toPricePoint(s.High52W, convert),
+		Low52w:           toPricePoint(s.Low52W, convert),
+		Partial52w:       s.Partial52W,
+		YtdChangePercent: s.YTDChangePercent,
+		Volume30d:        api.VolumeStats{Average: s.Volume30D.Average, Max: s.Volume30D.Max},
+		Volume90d:        api.VolumeStats{Average: s.Volume90D.Average, Max: s.Volume90D.Max},
+		AllTimeHigh:      toPricePoint(s.AllTimeHigh, convert),
+	})
+}
+
+// WithSymbolStatus は銘柄の状態の取得元を設定します。設定すると、上場廃止の銘柄の応答に X-Symbol-Status を付けます。
+func (h *Handler) WithSymbolStatus(src SymbolStatusSource) *Handler {
+	h.statuses = src
+	return h
+}
+
+// resolve は code を正規コードに解決し、X-Resolved-Symbol ヘッダーに設定します。
+// 銘柄が active 以外（上場廃止）の場合は X-Symbol-Status ヘッダーに状態を設定します。
+// 解決できない場合はエラーレスポンスを書き込み ok=false を返します。
+func (h *Handler) resolve(w http.ResponseWriter, r *http.Request, code string) (string, bool) {
+	resolved, err := h.uc.ResolveSymbol(r.Context(), code)
+	if err != nil {
+		httpx.WriteError(w, err, "failed to resolve symbol", "code", code)
+		return "", false
+	}
+	w.Header().Set(resolvedSymbolHeader, resolved)
+	h.setSymbolStatus(w, r, resolved)
+	return resolved, true
+}
+
+// setSymbolStatus は銘柄が active 以外の場合に X-Symbol-Status を設定します。
+// 状態はヘッダーのための補足情報のため、取得に失敗してもローソク足は返します。
+func (h *Handler) setSymbolStatus(w http.ResponseWriter, r *http.Request, code string) {
+	if h.statuses == nil {
+		return
+	}
+	st, err := h.statuses.SymbolStatus(r.Context(), code)
+	if err != nil {
+		slog.WarnContext(r.Context(), "failed to get symbol status", "code", code, "error", err)
+		return
+	}
+	if st != "" && st != "active" {
+		w.Header().Set(symbolStatusHeader, st)
+	}
+}
+
+// currency は ?currency= を検証し、大文字に正規化して返します。未指定の場合は空文字を返します。
+// 換算先として利用できない通貨の場合は 400 を書き込み ok=false を返します。
+func (h *Handler) currency(w http.ResponseWriter, r *http.Request) (string, bool) {
+	c := strings.ToUpper(r.URL.Query().Get("currency"))
+	if c == "" {
+		return "", true
+	}
+	if h.fx == nil || !h.fx.Supported(c) {
+		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "unsupported currency"})
+		return "", false
+	}
+	return c, true
+}
+
+// adjustMode は ?adjusted=（true / false）を解釈します。未指定の場合は AdjustDefault（サーバーの既定値）を返します。
+// 真偽値として解釈できない場合は 400 を書き込み ok=false を返します。
+func adjustMode(w http.ResponseWriter, r *http.Request) (candles.AdjustMode, bool) {
+	raw := r.URL.Query().Get("adjusted")
+	if raw == "" {
+		return candles.AdjustDefault, true
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "adjusted must be true or false"})
+		return candles.AdjustDefault, false
+	}
+	if v {
+		return candles.AdjustOn, true
+	}
+	return candles.AdjustOff, true
+}
+
+// asOfParam は ?as_of=（RFC 3339 の日時、または YYYY-MM-DD）を解釈します。未指定の場合はゼロ値を返します。
+// 日付のみの場合はその日の終わり（UTC）までに保存された足を対象とします。
+// as-of クエリはキャッシュを経由せず DB を読むため、APIキー（サーバー間連携）のクライアントに限ります。
+// ユーザー（JWT）からの指定は 403、解釈できない値と adjusted=true との併用は 400 を書き込み ok=false を返します。
+func asOfParam(w http.ResponseWriter, r *http.Request, adjust candles.AdjustMode) (time.Time, bool) {
+	raw := r.URL.Query().Get("as_of")
+	if raw == "" {
+		return time.Time{}, true
+	}
+	if _, ok := apikey.PrincipalFromContext(r.Context()); !ok {
+		httpx.WriteJSON(w, http.StatusForbidden, api.ErrorResponse{Error: "as_of is only available to API key clients"})
+		return time.Time{}, false
+	}
+	asOf, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		d, derr := time.Parse(time.DateOnly, raw)
+		if derr != nil {
+			httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "as_of must be an RFC 3339 timestamp or YYYY-MM-DD"})
+			return time.Time{}, false
+		}
+		asOf = d.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	if adjust == candles.AdjustOn {
+		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "as_of cannot be combined with adjusted=true"})
+		return time.Time{}, false
+	}
+	return asOf, true
+}
+
+// dateRangeParam は ?start= / ?end=（YYYY-MM-DD）を解釈します。いずれも未指定の場合はゼロ値を返します。
+// 解釈できない日付、開始日が終了日より後（invalid_range）、?as_of= との併用は 400 を書き込み ok=false を返します。
+func dateRangeParam(w http.ResponseWriter, r *http.Request, asOf time.Time) (candles.DateRange, bool) {
+	q := r.URL.Query()
+	var rng candles.DateRange
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"start", &rng.Start}, {"end", &rng.End}} {
+		raw := q.Get(p.name)
+		if raw == "" {
+			continue
+		}
+		d, err := api.ParseDate(raw)
+		if err != nil {
+			httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: p.name + ": " + err.Error()})
+			return candles.DateRange{}, false
+		}
+		*p.dst = d.Time
+	}
+	if rng.IsZero() {
+		return rng, true
+	}
+	if !asOf.IsZero() {
+		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "start/end cannot be combined with as_of"})
+		return candles.DateRange{}, false
+	}
+	if err := rng.Validate(); err != nil {
+		httpx.WriteError(w, err, "invalid date range")
+		return candles.DateRange{}, false
+	}
+	return rng, true
+}
+
+// conversion は code の価格を currency に換算する関数を返し、換算結果をヘッダーに設定します。
+// currency が空、または換算できない場合（警告）は値をそのまま返す関数を返します。
+func (h *Handler) conversion(w http.ResponseWriter, r *http.Request, code, currency string) func(float64) float64 {
+	identity := func(v float64) float64 { return v }
+	if currency == "" {
+		return identity
+	}
+	c := h.fx.Resolve(r.Context(), code, currency)
+	if c.Currency != "" {
+		w.Header().Set(currencyHeader, c.Currency)
+	}
+	if c.Warning != "" || c.Convert == nil {
+		w.Header().Set(currencyWarningHeader, c.Warning)
+		return identity
+	}
+	w.Header().Set(fxRateHeader, strconv.FormatFloat(c.Rate, 'f', -1, 64))
+	if !c.AsOf.IsZero() {
+		w.Header().Set(fxRateTimestampHeader, c.AsOf.UTC().Format(time.RFC3339))
+	}
+	return c.Convert
+}
+
+// recordView はユーザー（JWT）のリクエストであれば閲覧を記録します。
+// APIキーでのリクエストはユーザーを表さないため context にユーザーIDがなく、記録されません。
+func (h *Handler) recordView(r *http.Request, code string) {
+	if h.views == nil {
+		return
+	}
+	if userID, ok := jwt.UserIDFromContext(r.Context()); ok {
+		h.views.Record(userID, code)
+	}
+}
 
-	httpx.WriteJSON(w, http.StatusOK, out)
+// toPricePoint はドメインの PricePoint を convert で換算した API 型に変換します。
+func toPricePoint(p candles.PricePoint, convert func(float64) float64) api.PricePoint {
+	return api.PricePoint{Value: convert(p.Value), Time: api.NewDate(p.Time)}
 }
 
 // queryOrDefault はクエリパラメータ key の値を返します。key が存在しない場合のみ def を返します。
@@ -3,8 +3,10 @@ package candleshttp_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
@@ -13,17 +15,59 @@ import (
 
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/candleshttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/recentlyviewed"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/apikey"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
 )
 
 // mockUsecase はusecaseインターフェースのモック実装です。
 type mockUsecase struct {
-	GetCandlesFunc func(ctx context.Context, symbol, interval string, outputsize int) ([]candles.Candle, error)
+	ResolveSymbolFunc func(ctx context.Context, symbol string) (string, error)
+	GetCandlesFunc    func(ctx context.Context, symbol, interval string, outputsize int) ([]candles.Candle, error)
+	GetStatsFunc      func(ctx context.Context, symbol, interval string) (candles.Stats, error)
+	GetSparklineFunc  func(ctx context.Context, symbol, interval string, outputsize, points int) (candles.Sparkline, error)
+
+	gotAdjust candles.AdjustMode // 直近の呼び出しで渡された分割調整の指定
+	gotAsOf   time.Time          // 直近の GetCandlesAsOf で渡された時点
+	gotRange  candles.DateRange  // 直近の GetCandlesRange で渡された期間
+}
+
+// ResolveSymbol は ResolveSymbolFunc 未設定時は入力コードをそのまま正規コードとして返します。
+func (m *mockUsecase) ResolveSymbol(ctx context.Context, symbol string) (string, error) {
+	if m.ResolveSymbolFunc != nil {
+		return m.ResolveSymbolFunc(ctx, symbol)
+	}
+	return symbol, nil
+}
+
+func (m *mockUsecase) GetCandles(ctx context.Context, symbol, interval string, outputsize int, adjust candles.AdjustMode) ([]candles.Candle, error) {
+	m.gotAdjust = adjust
+	return m.GetCandlesFunc(ctx, symbol, interval, outputsize)
+}
+
+// GetCandlesAsOf は渡された時点を記録し、GetCandlesFunc の結果を返します。
+func (m *mockUsecase) GetCandlesAsOf(ctx context.Context, symbol, interval string, outputsize int, asOf time.Time) ([]candles.Candle, error) {
+	m.gotAsOf = asOf
+	return m.GetCandlesFunc(ctx, symbol, interval, outputsize)
 }
 
-func (m *mockUsecase) GetCandles(ctx context.Context, symbol, interval string, outputsize int) ([]candles.Candle, error) {
+// GetCandlesRange は渡された期間を記録し、GetCandlesFunc の結果を返します。
+func (m *mockUsecase) GetCandlesRange(ctx context.Context, symbol, interval string, rng candles.DateRange, outputsize int, adjust candles.AdjustMode) ([]candles.Candle, error) {
+	m.gotRange = rng
+	m.gotAdjust = adjust
 	return m.GetCandlesFunc(ctx, symbol, interval, outputsize)
 }
 
+func (m *mockUsecase) GetStats(ctx context.Context, symbol, interval string, adjust candles.AdjustMode) (candles.Stats, error) {
+	m.gotAdjust = adjust
+	return m.GetStatsFunc(ctx, symbol, interval)
+}
+
+func (m *mockUsecase) GetSparkline(ctx context.Context, symbol, interval string, outputsize, points int, adjust candles.AdjustMode) (candles.Sparkline, error) {
+	m.gotAdjust = adjust
+	return m.GetSparklineFunc(ctx, symbol, interval, outputsize, points)
+}
+
 // TestCandlesHandler_GetCandlesHandler はGetCandlesHandlerのHTTPリクエスト/レスポンス処理をテストします。
 func TestCandlesHandler_GetCandlesHandler(t *testing.T) {
 	// テスト用の固定時刻
@@ -56,7 +100,7 @@ func TestCandlesHandler_GetCandlesHandler(t *testing.T) {
 			mockGetCandles: func(ctx context.Context, symbol, interval string, outputsize int) ([]candles.Candle, error) {
 				assert.Equal(t, "7203.T", symbol)
 				assert.Equal(t, "1day", interval) // デフォルト値
-				assert.Equal(t, 200, outputsize)  // デフォルト値
+				assert.Equal(t, 0, outputsize)    // 未指定（usecase が時間間隔ごとの既定値を適用）
 				return []candles.Candle{}, nil
 			},
 			expectedStatus: http.StatusOK,
@@ -71,6 +115,15 @@ func TestCandlesHandler_GetCandlesHandler(t *testing.T) {
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   `{"error":"internal server error"}`,
 		},
+		{
+			name: "error: unknown or inactive symbol returns 404",
+			url:  "/candles/UNKNOWN",
+			mockGetCandles: func(ctx context.Context, symbol, interval string, outputsize int) ([]candles.Candle, error) {
+				return nil, candles.ErrSymbolNotFound
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"symbol_not_found"}`,
+		},
 		{
 			name:           "error: invalid outputsize string returns 400",
 			url:            "/candles/7203.T?outputsize=invalid",
@@ -101,10 +154,214 @@ func TestCandlesHandler_GetCandlesHandler(t *testing.T) {
 				GetCandlesFunc: tt.mockGetCandles,
 			}
 
-			h := candleshttp.NewHandler(mockUC)
+			h := candleshttp.NewHandler(mockUC, nil, nil)
+
+			router := chi.NewRouter()
+			router.Get("/candles/{code}", h.GetCandlesHandler)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+// TestCandlesHandler_ResolvedSymbol は解決後の正規コードがユースケースに渡され、
+// X-Resolved-Symbol ヘッダーで返されることを検証します。
+func TestCandlesHandler_ResolvedSymbol(t *testing.T) {
+	resolve := func(ctx context.Context, symbol string) (string, error) {
+		switch symbol {
+		case "7203":
+			return "7203.T", nil
+		case "1234":
+			return "", candles.ErrAmbiguousSymbol
+		}
+		return "", candles.ErrSymbolNotFound
+	}
+
+	tests := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedHeader string
+		expectedBody   string
+	}{
+		{name: "candles: resolved", url: "/candles/7203", expectedStatus: http.StatusOK, expectedHeader: "7203.T", expectedBody: `[]`},
+		{name: "candles: ambiguous returns 404", url: "/candles/1234", expectedStatus: http.StatusNotFound, expectedBody: `{"error":"symbol_ambiguous"}`},
+		{name: "candles: unknown returns 404", url: "/candles/ZZZZ", expectedStatus: http.StatusNotFound, expectedBody: `{"error":"symbol_not_found"}`},
+		{name: "stats: resolved", url: "/candles/7203/stats", expectedStatus: http.StatusOK, expectedHeader: "7203.T"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUC := &mockUsecase{
+				ResolveSymbolFunc: resolve,
+				GetCandlesFunc: func(ctx context.Context, symbol, interval string, outputsize int) ([]candles.Candle, error) {
+					assert.Equal(t, "7203.T", symbol)
+					return []candles.Candle{}, nil
+				},
+				GetStatsFunc: func(ctx context.Context, symbol, interval string) (candles.Stats, error) {
+					assert.Equal(t, "7203.T", symbol)
+					return candles.Stats{}, nil
+				},
+			}
+			h := candleshttp.NewHandler(mockUC, nil, nil)
+			router := chi.NewRouter()
+			router.Get("/candles/{code}", h.GetCandlesHandler)
+			router.Get("/candles/{code}/stats", h.GetStatsHandler)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedHeader, w.Header().Get("X-Resolved-Symbol"))
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+// symbolStatusFunc は関数で SymbolStatusSource を満たします。
+type symbolStatusFunc func(ctx context.Context, code string) (string, error)
+
+func (f symbolStatusFunc) SymbolStatus(ctx context.Context, code string) (string, error) {
+	return f(ctx, code)
+}
+
+// TestCandlesHandler_SymbolStatus は上場廃止の銘柄にのみ X-Symbol-Status が付くことを検証します。
+func TestCandlesHandler_SymbolStatus(t *testing.T) {
+	statuses := symbolStatusFunc(func(ctx context.Context, code string) (string, error) {
+		switch code {
+		case "TWTR":
+			return "delisted", nil
+		case "FAIL":
+			return "", errors.New("db down")
+		}
+		return "active", nil
+	})
+
+	tests := []struct {
+		name           string
+		url            string
+		expectedHeader string
+	}{
+		{name: "candles: delisted", url: "/candles/TWTR", expectedHeader: "delisted"},
+		{name: "stats: delisted", url: "/candles/TWTR/stats", expectedHeader: "delisted"},
+		{name: "candles: active has no header", url: "/candles/AAPL"},
+		{name: "candles: status lookup failure still serves candles", url: "/candles/FAIL"},
+	}
 
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUC := &mockUsecase{
+				GetCandlesFunc: func(ctx context.Context, symbol, interval string, outputsize int) ([]candles.Candle, error) {
+					return []candles.Candle{}, nil
+				},
+				GetStatsFunc: func(ctx context.Context, symbol, interval string) (candles.Stats, error) {
+					return candles.Stats{}, nil
+				},
+			}
+			h := candleshttp.NewHandler(mockUC, nil, nil).WithSymbolStatus(statuses)
 			router := chi.NewRouter()
 			router.Get("/candles/{code}", h.GetCandlesHandler)
+			router.Get("/candles/{code}/stats", h.GetStatsHandler)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.expectedHeader, w.Header().Get("X-Symbol-Status"))
+		})
+	}
+}
+
+// TestCandlesHandler_GetStatsHandler はGetStatsHandlerのHTTPリクエスト/レスポンス処理をテストします。
+func TestCandlesHandler_GetStatsHandler(t *testing.T) {
+	ytd := 12.5
+
+	tests := []struct {
+		name           string
+		url            string
+		mockGetStats   func(ctx context.Context, symbol, interval string) (candles.Stats, error)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "success: returns stats with default interval",
+			url:  "/candles/AAPL/stats",
+			mockGetStats: func(ctx context.Context, symbol, interval string) (candles.Stats, error) {
+				assert.Equal(t, "AAPL", symbol)
+				assert.Equal(t, "1day", interval)
+				return candles.Stats{
+					AsOf:             time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+					High52W:          candles.PricePoint{Value: 200, Time: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+					Low52W:           candles.PricePoint{Value: 100, Time: time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)},
+					Partial52W:       true,
+					YTDChangePercent: &ytd,
+					Volume30D:        candles.VolumeStats{Average: 1500, Max: 3000},
+					Volume90D:        candles.VolumeStats{Average: 1200, Max: 3000},
+					AllTimeHigh:      candles.PricePoint{Value: 200, Time: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+				}, nil
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{
+				"as_of":"2024-03-01",
+				"high_52w":{"value":200,"time":"2024-02-01"},
+				"low_52w":{"value":100,"time":"2023-05-01"},
+				"partial_52w":true,
+				"ytd_change_percent":12.5,
+				"volume_30d":{"average":1500,"max":3000},
+				"volume_90d":{"average":1200,"max":3000},
+				"all_time_high":{"value":200,"time":"2024-02-01"}
+			}`,
+		},
+		{
+			name: "error: unknown symbol returns 404",
+			url:  "/candles/UNKNOWN/stats",
+			mockGetStats: func(ctx context.Context, symbol, interval string) (candles.Stats, error) {
+				return candles.Stats{}, candles.ErrSymbolNotFound
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"symbol_not_found"}`,
+		},
+		{
+			name: "error: known symbol without candles returns 404 no_data",
+			url:  "/candles/NEWCO/stats",
+			mockGetStats: func(ctx context.Context, symbol, interval string) (candles.Stats, error) {
+				return candles.Stats{}, candles.ErrNoCandles
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"no_data"}`,
+		},
+		{
+			name: "error: usecase returns error",
+			url:  "/candles/AAPL/stats?interval=1week",
+			mockGetStats: func(ctx context.Context, symbol, interval string) (candles.Stats, error) {
+				assert.Equal(t, "1week", interval)
+				return candles.Stats{}, errors.New("db down")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"internal server error"}`,
+		},
+		{
+			name:           "error: invalid symbol code returns 400",
+			url:            "/candles/7203%26T/stats",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid symbol code"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := candleshttp.NewHandler(&mockUsecase{GetStatsFunc: tt.mockGetStats}, nil, nil)
+
+			router := chi.NewRouter()
+			router.Get("/candles/{code}/stats", h.GetStatsHandler)
 
 			w := httptest.NewRecorder()
 			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
@@ -116,3 +373,387 @@ func TestCandlesHandler_GetCandlesHandler(t *testing.T) {
 		})
 	}
 }
+
+// captureViews は Record の呼び出しを記録する ViewRecorder です。
+type captureViews struct {
+	mu    sync.Mutex
+	views []string
+}
+
+func (c *captureViews) Record(userID int64, symbolCode string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.views = append(c.views, fmt.Sprintf("%d:%s", userID, symbolCode))
+}
+
+// TestCandlesHandler_RecordsViews はユーザー（JWT）のリクエストのみ、解決後の正規コードで閲覧が記録されることを検証します。
+func TestCandlesHandler_RecordsViews(t *testing.T) {
+	mockUC := &mockUsecase{
+		ResolveSymbolFunc: func(ctx context.Context, symbol string) (string, error) { return "7203.T", nil },
+		GetCandlesFunc: func(ctx context.Context, symbol, interval string, outputsize int) ([]candles.Candle, error) {
+			return []candles.Candle{}, nil
+		},
+	}
+	views := &captureViews{}
+	h := candleshttp.NewHandler(mockUC, views, nil)
+	router := chi.NewRouter()
+	router.Get("/candles/{code}", h.GetCandlesHandler)
+
+	// ユーザー（JWT）のリクエスト
+	req := httptest.NewRequest(http.MethodGet, "/candles/7203", nil)
+	req = req.WithContext(jwt.WithUserID(req.Context(), 42))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// APIキー等、ユーザーIDを持たないリクエスト
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/candles/7203", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	assert.Equal(t, []string{"42:7203.T"}, views.views)
+}
+
+// blockingStore は ctx が終了するまで書き込みをブロックする recentlyviewed.Store です（Redis の応答停止を模擬）。
+type blockingStore struct{}
+
+func (blockingStore) Add(ctx context.Context, _ int64, _ string, _ time.Time) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// TestCandlesHandler_SlowViewStoreAddsNoLatency は閲覧の書き込み先が応答しなくても、
+// ローソク足のリクエストが待たされないことを検証します。
+func TestCandlesHandler_SlowViewStoreAddsNoLatency(t *testing.T) {
+	rec := recentlyviewed.NewRecorder(blockingStore{}, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go rec.Run(ctx)
+
+	mockUC := &mockUsecase{
+		GetCandlesFunc: func(ctx context.Context, symbol, interval string, outputsize int) ([]candles.Candle, error) {
+			return []candles.Candle{}, nil
+		},
+	}
+	h := candleshttp.NewHandler(mockUC, rec, nil)
+	router := chi.NewRouter()
+	router.Get("/candles/{code}", h.GetCandlesHandler)
+
+	const requests = 20
+	const bound = 50 * time.Millisecond
+	for i := range requests {
+		req := httptest.NewRequest(http.MethodGet, "/candles/AAPL", nil)
+		req = req.WithContext(jwt.WithUserID(req.Context(), int64(i)))
+		w := httptest.NewRecorder()
+
+		start := time.Now()
+		router.ServeHTTP(w, req)
+		elapsed := time.Since(start)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Less(t, elapsed, bound, "request %d was delayed by the view recorder", i)
+	}
+	// 書き込み中の 1 件とバッファの 1 件を除き、残りは破棄される
+	assert.GreaterOrEqual(t, rec.Dropped(), uint64(requests-2))
+}
+
+// fakeConverter は USD 建て銘柄を固定レートで換算する CurrencyConverter です。
+// 銘柄 UNKNOWN は通貨未登録、換算先 EUR はレート取得不可として扱います。
+type fakeConverter struct{}
+
+func (fakeConverter) Supported(currency string) bool {
+	return currency == "JPY" || currency == "USD" || currency == "EUR"
+}
+
+func (fakeConverter) Resolve(_ context.Context, symbol, currency string) candleshttp.Conversion {
+	switch {
+	case symbol == "UNKNOWN":
+		return candleshttp.Conversion{Warning: candleshttp.WarningCurrencyUnknown}
+	case currency == "EUR":
+		return candleshttp.Conversion{Currency: "USD", Warning: candleshttp.WarningRateUnavailable}
+	}
+	return candleshttp.Conversion{
+		Currency: currency,
+		Rate:     150.25,
+		AsOf:     time.Date(2026, 10, 1, 21, 0, 0, 0, time.UTC),
+		Convert:  func(v float64) float64 { return v * 150 },
+	}
+}
+
+// TestCandlesHandler_Currency は ?currency= による価格の換算と、換算情報のヘッダーを検証します。
+func TestCandlesHandler_Currency(t *testing.T) {
+	testTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockUC := &mockUsecase{
+		GetCandlesFunc: func(ctx context.Context, symbol, interval string, outputsize int) ([]candles.Candle, error) {
+			return []candles.Candle{{Time: testTime, Open: 1, High: 2, Low: 0.5, Close: 1.5, Volume: 1000}}, nil
+		},
+	}
+
+	tests := []struct {
+		name            string
+		url             string
+		expectedStatus  int
+		expectedBody    string
+		expectedHeaders map[string]string
+	}{
+		{
+			name:           "success: converted to JPY, volume unchanged",
+			url:            "/candles/AAPL?currency=jpy",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"time":"2023-01-01","open":150,"high":300,"low":75,"close":225,"volume":1000}]`,
+			expectedHeaders: map[string]string{
+				"X-Currency":          "JPY",
+				"X-FX-Rate":           "150.25",
+				"X-FX-Rate-Timestamp": "2026-10-01T21:00:00Z",
+				"X-Currency-Warning":  "",
+			},
+		},
+		{
+			name:           "warning: rate unavailable returns native values",
+			url:            "/candles/AAPL?currency=EUR",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"time":"2023-01-01","open":1,"high":2,"low":0.5,"close":1.5,"volume":1000}]`,
+			expectedHeaders: map[string]string{
+				"X-Currency":         "USD",
+				"X-FX-Rate":          "",
+				"X-Currency-Warning": "rate_unavailable",
+			},
+		},
+		{
+			name:           "warning: symbol currency unknown",
+			url:            "/candles/UNKNOWN?currency=JPY",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"time":"2023-01-01","open":1,"high":2,"low":0.5,"close":1.5,"volume":1000}]`,
+			expectedHeaders: map[string]string{
+				"X-Currency":         "",
+				"X-Currency-Warning": "currency_unknown",
+			},
+		},
+		{
+			name:           "error: unsupported currency",
+			url:            "/candles/AAPL?currency=XYZ",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"unsupported currency"}`,
+		},
+		{
+			name:            "success: no currency leaves headers unset",
+			url:             "/candles/AAPL",
+			expectedStatus:  http.StatusOK,
+			expectedBody:    `[{"time":"2023-01-01","open":1,"high":2,"low":0.5,"close":1.5,"volume":1000}]`,
+			expectedHeaders: map[string]string{"X-Currency": "", "X-Currency-Warning": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := candleshttp.NewHandler(mockUC, nil, fakeConverter{})
+			router := chi.NewRouter()
+			router.Get("/candles/{code}", h.GetCandlesHandler)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			for k, v := range tt.expectedHeaders {
+				assert.Equal(t, v, w.Header().Get(k), k)
+			}
+		})
+	}
+}
+
+// TestCandlesHandler_CurrencyWithoutConverter は換算手段がない場合に ?currency= が 400 になることを検証します。
+func TestCandlesHandler_CurrencyWithoutConverter(t *testing.T) {
+	h := candleshttp.NewHandler(&mockUsecase{}, nil, nil)
+	router := chi.NewRouter()
+	router.Get("/candles/{code}/stats", h.GetStatsHandler)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/candles/AAPL/stats?currency=JPY", nil))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// TestCandlesHandler_StatsCurrency は統計の価格が換算され、騰落率と出来高は換算されないことを検証します。
+func TestCandlesHandler_StatsCurrency(t *testing.T) {
+	day := time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC)
+	ytd := 12.5
+	mockUC := &mockUsecase{
+		GetStatsFunc: func(ctx context.Context, symbol, interval string) (candles.Stats, error) {
+			return candles.Stats{
+				AsOf:             day,
+				High52W:          candles.PricePoint{Value: 2, Time: day},
+				Low52W:           candles.PricePoint{Value: 1, Time: day},
+				YTDChangePercent: &ytd,
+				Volume30D:        candles.VolumeStats{Average: 10, Max: 20},
+				Volume90D:        candles.VolumeStats{Average: 10, Max: 20},
+				AllTimeHigh:      candles.PricePoint{Value: 3, Time: day},
+			}, nil
+		},
+	}
+	h := candleshttp.NewHandler(mockUC, nil, fakeConverter{})
+	router := chi.NewRouter()
+	router.Get("/candles/{code}/stats", h.GetStatsHandler)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/candles/AAPL/stats?currency=JPY", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "JPY", w.Header().Get("X-Currency"))
+	assert.JSONEq(t, `{
+		"as_of":"2026-09-30",
+		"high_52w":{"value":300,"time":"2026-09-30"},
+		"low_52w":{"value":150,"time":"2026-09-30"},
+		"partial_52w":false,
+		"ytd_change_percent":12.5,
+		"volume_30d":{"average":10,"max":20},
+		"volume_90d":{"average":10,"max":20},
+		"all_time_high":{"value":450,"time":"2026-09-30"}
+	}`, w.Body.String())
+}
+
+// TestCandlesHandler_Adjusted は ?adjusted= を AdjustMode に変換して usecase に渡すことを検証します。
+func TestCandlesHandler_Adjusted(t *testing.T) {
+	tests := []struct {
+		query      string
+		wantStatus int
+		wantAdjust candles.AdjustMode
+	}{
+		{"", http.StatusOK, candles.AdjustDefault},
+		{"?adjusted=true", http.StatusOK, candles.AdjustOn},
+		{"?adjusted=false", http.StatusOK, candles.AdjustOff},
+		{"?adjusted=maybe", http.StatusBadRequest, candles.AdjustDefault},
+	}
+	for _, tt := range tests {
+		t.Run("candles"+tt.query, func(t *testing.T) {
+			uc := &mockUsecase{GetCandlesFunc: func(context.Context, string, string, int) ([]candles.Candle, error) {
+				return []candles.Candle{}, nil
+			}}
+			router := chi.NewRouter()
+			router.Get("/candles/{code}", candleshttp.NewHandler(uc, nil, nil).GetCandlesHandler)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/candles/AAPL"+tt.query, nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantAdjust, uc.gotAdjust)
+		})
+		t.Run("stats"+tt.query, func(t *testing.T) {
+			uc := &mockUsecase{GetStatsFunc: func(context.Context, string, string) (candles.Stats, error) {
+				return candles.Stats{}, nil
+			}}
+			router := chi.NewRouter()
+			router.Get("/candles/{code}/stats", candleshttp.NewHandler(uc, nil, nil).GetStatsHandler)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/candles/AAPL/stats"+tt.query, nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantAdjust, uc.gotAdjust)
+		})
+	}
+}
+
+// TestCandlesHandler_AsOf は ?as_of= の解釈と、APIキーのクライアントに限る制限を検証します。
+func TestCandlesHandler_AsOf(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		apiKey     bool
+		wantStatus int
+		wantAsOf   time.Time
+	}{
+		{name: "no as_of uses latest", query: "", apiKey: true, wantStatus: http.StatusOK},
+		{name: "timestamp", query: "?as_of=2026-03-01T09:00:00%2B09:00", apiKey: true, wantStatus: http.StatusOK, wantAsOf: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "date means end of day", query: "?as_of=2026-03-01", apiKey: true, wantStatus: http.StatusOK, wantAsOf: time.Date(2026, 3, 1, 23, 59, 59, 999999999, time.UTC)},
+		{name: "user (JWT) is forbidden", query: "?as_of=2026-03-01", apiKey: false, wantStatus: http.StatusForbidden},
+		{name: "invalid value", query: "?as_of=yesterday", apiKey: true, wantStatus: http.StatusBadRequest},
+		{name: "adjusted=true is rejected", query: "?as_of=2026-03-01&adjusted=true", apiKey: true, wantStatus: http.StatusBadRequest},
+		{name: "adjusted=false is allowed", query: "?as_of=2026-03-01&adjusted=false", apiKey: true, wantStatus: http.StatusOK, wantAsOf: time.Date(2026, 3, 1, 23, 59, 59, 999999999, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &mockUsecase{GetCandlesFunc: func(context.Context, string, string, int) ([]candles.Candle, error) {
+				return []candles.Candle{}, nil
+			}}
+			router := chi.NewRouter()
+			router.Get("/candles/{code}", candleshttp.NewHandler(uc, nil, nil).GetCandlesHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/candles/AAPL"+tt.query, nil)
+			if tt.apiKey {
+				req = req.WithContext(apikey.WithPrincipal(req.Context(), apikey.Principal{KeyID: "research", Scopes: []string{apikey.ScopeCandlesRead}}))
+			} else {
+				req = req.WithContext(jwt.WithUserID(req.Context(), 1))
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.True(t, tt.wantAsOf.Equal(uc.gotAsOf), "as_of = %v, want %v", uc.gotAsOf, tt.wantAsOf)
+		})
+	}
+}
+
+// TestCandlesHandler_DateRange は ?start= / ?end= の解釈と、不正な指定の 400 を検証します。
+func TestCandlesHandler_DateRange(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantRange  candles.DateRange
+		wantBody   string
+	}{
+		{name: "no range uses latest", query: "", wantStatus: http.StatusOK},
+		{name: "start and end", query: "?start=2024-01-01&end=2024-01-31", wantStatus: http.StatusOK, wantRange: candles.DateRange{Start: day(1), End: day(31)}},
+		{name: "start only", query: "?start=2024-01-15", wantStatus: http.StatusOK, wantRange: candles.DateRange{Start: day(15)}},
+		{name: "same day", query: "?start=2024-01-15&end=2024-01-15", wantStatus: http.StatusOK, wantRange: candles.DateRange{Start: day(15), End: day(15)}},
+		{name: "invalid date", query: "?start=2024-13-01", wantStatus: http.StatusBadRequest},
+		{name: "not a date", query: "?end=2024-01-15T00:00:00Z", wantStatus: http.StatusBadRequest},
+		{name: "start after end", query: "?start=2024-02-01&end=2024-01-01", wantStatus: http.StatusBadRequest, wantBody: `"invalid_range"`},
+		{name: "combined with as_of", query: "?start=2024-01-01&as_of=2024-03-01", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &mockUsecase{GetCandlesFunc: func(context.Context, string, string, int) ([]candles.Candle, error) {
+				return []candles.Candle{}, nil
+			}}
+			router := chi.NewRouter()
+			router.Get("/candles/{code}", candleshttp.NewHandler(uc, nil, nil).GetCandlesHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/candles/AAPL"+tt.query, nil)
+			req = req.WithContext(apikey.WithPrincipal(req.Context(), apikey.Principal{KeyID: "research", Scopes: []string{apikey.ScopeCandlesRead}}))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			assert.Equal(t, tt.wantRange, uc.gotRange)
+			if tt.wantBody != "" {
+				assert.Contains(t, w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+// TestCandlesHandler_AsOfSource は as-of クエリの応答にだけ取り込み元（source）を付けることを検証します。
+func TestCandlesHandler_AsOfSource(t *testing.T) {
+	uc := &mockUsecase{GetCandlesFunc: func(context.Context, string, string, int) ([]candles.Candle, error) {
+		return []candles.Candle{
+			{Time: time.Date(2026, 2, 27, 0, 0, 0, 0, time.UTC), Open: 1, High: 2, Low: 1, Close: 2, Volume: 10, Source: "csv:1a2b3c4d5e6f"},
+		}, nil
+	}}
+	router := chi.NewRouter()
+	router.Get("/candles/{code}", candleshttp.NewHandler(uc, nil, nil).GetCandlesHandler)
+	serve := func(query string) string {
+		req := httptest.NewRequest(http.MethodGet, "/candles/AAPL"+query, nil)
+		req = req.WithContext(apikey.WithPrincipal(req.Context(), apikey.Principal{KeyID: "research", Scopes: []string{apikey.ScopeCandlesRead}}))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		return w.Body.String()
+	}
+
+	assert.NotContains(t, serve(""), `"source"`, "通常の応答には含めない")
+	assert.Contains(t, serve("?as_of=2026-03-01"), `"source":"csv:1a2b3c4d5e6f"`)
+	assert.JSONEq(t, `[{"close":2,"source":"csv:1a2b3c4d5e6f"}]`, serve("?as_of=2026-03-01&fields=close"), "?fields= でも残す")
+}
@@ -0,0 +1,79 @@
+package candleshttp
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+)
+
+// 保存済みローソク足の調査で返す件数の既定値と上限です。
+const (
+	defaultInspectLimit = 100
+	maxInspectLimit     = 1000
+)
+
+// CandleInspector は保存済みローソク足を取り込み元・書き込み日時とともに読み取ります（candles.NewRepository が実装）。
+// Goの慣例に従い、インターフェースは利用者（handler）側で定義します。
+type CandleInspector interface {
+	Inspect(ctx context.Context, symbol, interval string, limit int) ([]candles.StoredCandle, error)
+}
+
+// InspectHandler は保存済みローソク足の調査エンドポイントを処理します。
+// 認可（candles:admin スコープ）はルーター側のミドルウェアで行います。
+type InspectHandler struct {
+	repo CandleInspector
+}
+
+// NewInspectHandler は InspectHandler を生成します。
+func NewInspectHandler(repo CandleInspector) *InspectHandler {
+	return &InspectHandler{repo: repo}
+}
+
+// Inspect は {code} の ?interval=（既定 1day）の足を、ID・取り込み元・書き込み日時とともに新しい順に最大 ?limit= 件返します。
+// 値の食い違いの調査用のため、銘柄コードは正規化せず、キャッシュを経由しません。
+func (h *InspectHandler) Inspect(w http.ResponseWriter, r *http.Request) {
+	code := chi.URLParam(r, "code")
+	if !symbolCodePattern.MatchString(code) {
+		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "invalid symbol code"})
+		return
+	}
+	interval := queryOrDefault(r, "interval", "1day")
+	limit := defaultInspectLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 1 || v > maxInspectLimit {
+			httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "limit must be an integer between 1 and 1000"})
+			return
+		}
+		limit = v
+	}
+
+	cs, err := h.repo.Inspect(r.Context(), code, interval, limit)
+	if err != nil {
+		httpx.WriteError(w, err, "failed to inspect candles", "code", code, "interval", interval)
+		return
+	}
+	out := make([]api.StoredCandle, 0, len(cs))
+	for _, c := range cs {
+		out = append(out, api.StoredCandle{
+			Id:        c.ID,
+			Time:      api.NewTimestamp(c.Time),
+			Open:      c.Open,
+			High:      c.High,
+			Low:       c.Low,
+			Close:     c.Close,
+			Volume:    c.Volume,
+			Source:    c.Source,
+			CreatedAt: api.NewTimestamp(c.CreatedAt),
+			UpdatedAt: api.NewTimestamp(c.UpdatedAt),
+		})
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
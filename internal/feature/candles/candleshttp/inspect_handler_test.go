@@ -0,0 +1,117 @@
+package candleshttp_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/candleshttp"
+)
+
+// mockCandleInspector は CandleInspector インターフェースのモック実装です。受け取った引数を記録します。
+type mockCandleInspector struct {
+	err      error
+	called   bool
+	symbol   string
+	interval string
+	limit    int
+}
+
+func (m *mockCandleInspector) Inspect(_ context.Context, symbol, interval string, limit int) ([]candles.StoredCandle, error) {
+	m.called, m.symbol, m.interval, m.limit = true, symbol, interval, limit
+	if m.err != nil {
+		return nil, m.err
+	}
+	day := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	return []candles.StoredCandle{{
+		ID: 9,
+		Candle: candles.Candle{
+			SymbolCode: symbol, Interval: interval, Time: day,
+			Open: 100, High: 110, Low: 90, Close: 105, Volume: 1000, Source: "csv:1a2b3c4d5e6f",
+		},
+		CreatedAt: day.Add(20 * time.Hour),
+		UpdatedAt: day.Add(44 * time.Hour),
+	}}, nil
+}
+
+func TestInspectHandler_Inspect(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		url          string
+		err          error
+		wantCode     int
+		wantBody     string
+		wantCalled   bool
+		wantInterval string
+		wantLimit    int
+	}{
+		{
+			name:         "defaults",
+			url:          "/admin/candles/AAPL",
+			wantCode:     http.StatusOK,
+			wantBody:     `[{"close":105,"createdAt":"2024-06-10T20:00:00Z","high":110,"id":9,"low":90,"open":100,"source":"csv:1a2b3c4d5e6f","time":"2024-06-10T00:00:00Z","updatedAt":"2024-06-11T20:00:00Z","volume":1000}]`,
+			wantCalled:   true,
+			wantInterval: "1day",
+			wantLimit:    100,
+		},
+		{
+			name:         "interval and limit",
+			url:          "/admin/candles/AAPL?interval=1week&limit=5",
+			wantCode:     http.StatusOK,
+			wantBody:     `"source":"csv:1a2b3c4d5e6f"`,
+			wantCalled:   true,
+			wantInterval: "1week",
+			wantLimit:    5,
+		},
+		{
+			name:     "invalid code",
+			url:      "/admin/candles/AA$PL",
+			wantCode: http.StatusBadRequest,
+			wantBody: `"invalid symbol code"`,
+		},
+		{
+			name:     "limit out of range",
+			url:      "/admin/candles/AAPL?limit=1001",
+			wantCode: http.StatusBadRequest,
+			wantBody: `"limit must be an integer between 1 and 1000"`,
+		},
+		{
+			name:         "repository error",
+			url:          "/admin/candles/AAPL",
+			err:          errors.New("db down"),
+			wantCode:     http.StatusInternalServerError,
+			wantCalled:   true,
+			wantInterval: "1day",
+			wantLimit:    100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repo := &mockCandleInspector{err: tt.err}
+			r := chi.NewRouter()
+			r.Get("/admin/candles/{code}", candleshttp.NewInspectHandler(repo).Inspect)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			assert.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+			assert.Equal(t, tt.wantCalled, repo.called)
+			if tt.wantCalled {
+				assert.Equal(t, "AAPL", repo.symbol)
+				assert.Equal(t, tt.wantInterval, repo.interval)
+				assert.Equal(t, tt.wantLimit, repo.limit)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package candleshttp
+
+import (
+	"net/http"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/maintenance"
+)
+
+// PreferCacheDuringMaintenance はメンテナンス中（maintenance.Guard が通したリクエスト）のローソク足の読み取りで、
+// DB よりキャッシュを優先するヒント（candles.WithPreferCache）を context に格納するミドルウェアを返します。
+// maintenance.Guard の後に置きます。メンテナンス中でなければ何もしません。
+func PreferCacheDuringMaintenance() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if maintenance.ActiveFromContext(r.Context()) {
+				r = r.WithContext(candles.WithPreferCache(r.Context()))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
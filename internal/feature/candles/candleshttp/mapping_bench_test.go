@@ -0,0 +1,30 @@
+package candleshttp
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+)
+
+// BenchmarkToCandleResponses はローソク足のレスポンスの型への変換を、既定の 200 本と上限の 5000 本で計測します。
+// 非公開関数のため、他のホットパスのベンチマーク（internal/app/bench）とは別にここに置きます。
+func BenchmarkToCandleResponses(b *testing.B) {
+	identity := func(v float64) float64 { return v }
+	for _, n := range []int{200, candles.MaxOutputSize} {
+		cs := make([]candles.Candle, n)
+		day := time.Date(2026, 3, 13, 0, 0, 0, 0, time.UTC)
+		for i := range cs {
+			cs[i] = candles.Candle{Time: day.AddDate(0, 0, -i), Open: 100, High: 110, Low: 90, Close: 105, Volume: 1000}
+		}
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if out := toCandleResponses(cs, identity); len(out) != n {
+					b.Fatalf("len = %d, want %d", len(out), n)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package candleshttp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/apikey"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+)
+
+// PlanSource はユーザーの料金プランの取得を抽象化します。
+// candleshttp が auth feature に直接依存しないよう、DI 層でユーザーのプランを candles.Plan に変換します。
+type PlanSource interface {
+	UserPlan(ctx context.Context, userID int64) (candles.Plan, error)
+}
+
+// ResolvePlan はリクエストの利用者の料金プランを candles.WithPlan で context に格納するミドルウェアを返します。
+// apikey.Authenticate / jwt.AuthRequired の後に置きます。
+//   - APIキーは apikey.ScopePremiumData を持てば premium、持たなければ free として扱う
+//   - JWT のユーザーは users から読み込んだプランを使う
+//
+// 認証情報のないリクエストはプランを設定せずに次へ渡します（usecase は制限しません）。
+func ResolvePlan(users PlanSource) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			if p, ok := apikey.PrincipalFromContext(ctx); ok {
+				plan := candles.PlanFree
+				if p.HasScope(apikey.ScopePremiumData) {
+					plan = candles.PlanPremium
+				}
+				next.ServeHTTP(w, r.WithContext(candles.WithPlan(ctx, plan)))
+				return
+			}
+			userID, ok := jwt.UserIDFromContext(ctx)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			plan, err := users.UserPlan(ctx, userID)
+			if err != nil {
+				httpx.WriteError(w, err, "failed to load user plan", "user_id", userID)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(candles.WithPlan(ctx, plan)))
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package candleshttp_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/candleshttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/apikey"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+)
+
+// stubPlans は PlanSource のスタブです。登録のないユーザーは free として扱います。
+type stubPlans struct {
+	plans map[int64]candles.Plan
+	err   error
+	calls int
+}
+
+func (s *stubPlans) UserPlan(_ context.Context, userID int64) (candles.Plan, error) {
+	s.calls++
+	if s.err != nil {
+		return "", s.err
+	}
+	if p, ok := s.plans[userID]; ok {
+		return p, nil
+	}
+	return candles.PlanFree, nil
+}
+
+// TestResolvePlan はAPIキーのスコープ・JWT のユーザーのプランから利用者の料金プランを context に設定することを検証します。
+func TestResolvePlan(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		ctx       func(context.Context) context.Context
+		wantPlan  candles.Plan
+		wantOK    bool
+		wantCalls int
+	}{
+		{
+			name: "api key with data:premium is premium",
+			ctx: func(ctx context.Context) context.Context {
+				return apikey.WithPrincipal(ctx, apikey.Principal{KeyID: "partner", Scopes: []string{apikey.ScopeCandlesRead, apikey.ScopePremiumData}})
+			},
+			wantPlan: candles.PlanPremium, wantOK: true,
+		},
+		{
+			name: "api key without data:premium is free",
+			ctx: func(ctx context.Context) context.Context {
+				return apikey.WithPrincipal(ctx, apikey.Principal{KeyID: "partner", Scopes: []string{apikey.ScopeCandlesRead}})
+			},
+			wantPlan: candles.PlanFree, wantOK: true,
+		},
+		{
+			name:     "premium user",
+			ctx:      func(ctx context.Context) context.Context { return jwt.WithUserID(ctx, 1) },
+			wantPlan: candles.PlanPremium, wantOK: true, wantCalls: 1,
+		},
+		{
+			name:     "free user",
+			ctx:      func(ctx context.Context) context.Context { return jwt.WithUserID(ctx, 2) },
+			wantPlan: candles.PlanFree, wantOK: true, wantCalls: 1,
+		},
+		{
+			name: "unauthenticated request has no plan",
+			ctx:  func(ctx context.Context) context.Context { return ctx },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			plans := &stubPlans{plans: map[int64]candles.Plan{1: candles.PlanPremium}}
+			var gotPlan candles.Plan
+			var gotOK bool
+			h := candleshttp.ResolvePlan(plans)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPlan, gotOK = candles.PlanFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/candles/AAPL", nil)
+			req = req.WithContext(tt.ctx(req.Context()))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.wantOK, gotOK)
+			assert.Equal(t, tt.wantPlan, gotPlan)
+			assert.Equal(t, tt.wantCalls, plans.calls)
+		})
+	}
+}
+
+// TestResolvePlan_LoadError はユーザーのプランを読み込めない場合に 500 を返し、ハンドラーを呼ばないことを検証します。
+func TestResolvePlan_LoadError(t *testing.T) {
+	t.Parallel()
+
+	h := candleshttp.ResolvePlan(&stubPlans{err: errors.New("db down")})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler should not be called")
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/candles/AAPL", nil)
+	req = req.WithContext(jwt.WithUserID(req.Context(), 1))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
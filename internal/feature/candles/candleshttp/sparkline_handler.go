@@ -0,0 +1,177 @@
+package candleshttp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+)
+
+// MaxSparklineSymbols は ?symbols= で一度に指定できる銘柄数の上限です。
+const MaxSparklineSymbols = 50
+
+// sparklineCacheControl はスパークラインのレスポンスに設定する Cache-Control です。
+// データは ingest（日次）でしか変わらないため、クライアント側でも短時間は再利用させます。
+const sparklineCacheControl = "private, max-age=300"
+
+// sparklineQuery はスパークラインの共通クエリパラメータです。
+type sparklineQuery struct {
+	interval   string
+	outputsize int
+	points     int
+	adjust     candles.AdjustMode
+}
+
+// GetSparklineHandler は銘柄コードを受け取り、間引いた終値の系列（スパークライン）をJSONで返します。
+//
+// エンドポイント例:
+// GET /candles/{code}/sparkline?interval=1day&points=30
+func (h *Handler) GetSparklineHandler(w http.ResponseWriter, r *http.Request) {
+	code := chi.URLParam(r, "code")
+	if !symbolCodePattern.MatchString(code) {
+		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "invalid symbol code"})
+		return
+	}
+	q, ok := parseSparklineQuery(w, r)
+	if !ok {
+		return
+	}
+
+	code, ok = h.resolve(w, r, code)
+	if !ok {
+		return
+	}
+	s, err := h.uc.GetSparkline(r.Context(), code, q.interval, q.outputsize, q.points, q.adjust)
+	if err != nil {
+		httpx.WriteError(w, err, "failed to get sparkline", "code", code)
+		return
+	}
+	w.Header().Set("Cache-Control", sparklineCacheControl)
+	httpx.WriteJSON(w, http.StatusOK, toSparkline(code, s))
+}
+
+// GetSparklinesHandler はカンマ区切りの銘柄コードを受け取り、各銘柄のスパークラインをまとめてJSONで返します（ウォッチリスト向け）。
+// 解決できない銘柄はエラーにせず unknown に、利用者のプランでは参照できない銘柄は errors に入れて返します。
+//
+// エンドポイント例:
+// GET /candles/sparklines?symbols=AAPL,7203.T&points=30
+func (h *Handler) GetSparklinesHandler(w http.ResponseWriter, r *http.Request) {
+	codes, ok := parseSymbols(w, r, MaxSparklineSymbols)
+	if !ok {
+		return
+	}
+	q, ok := parseSparklineQuery(w, r)
+	if !ok {
+		return
+	}
+
+	out := api.SparklineBatchResponse{Sparklines: []api.Sparkline{}, Unknown: []string{}, Errors: []api.SymbolError{}}
+	seen := make(map[string]struct{}, len(codes))
+	for _, code := range codes {
+		resolved, err := h.uc.ResolveSymbol(r.Context(), code)
+		if errors.Is(err, candles.ErrSymbolNotFound) || errors.Is(err, candles.ErrAmbiguousSymbol) {
+			out.Unknown = append(out.Unknown, code)
+			continue
+		}
+		if err != nil {
+			httpx.WriteError(w, err, "failed to resolve symbol", "code", code)
+			return
+		}
+		if _, dup := seen[resolved]; dup {
+			continue
+		}
+		seen[resolved] = struct{}{}
+
+		s, err := h.uc.GetSparkline(r.Context(), resolved, q.interval, q.outputsize, q.points, q.adjust)
+		if errors.Is(err, candles.ErrUpgradeRequired) {
+			out.Errors = append(out.Errors, lockedSymbol(resolved))
+			continue
+		}
+		if err != nil {
+			httpx.WriteError(w, err, "failed to get sparkline", "code", resolved)
+			return
+		}
+		out.Sparklines = append(out.Sparklines, toSparkline(resolved, s))
+	}
+	w.Header().Set("Cache-Control", sparklineCacheControl)
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+// lockedSymbol は利用者のプランでは参照できない銘柄 code の、一括取得の errors の要素を返します。
+func lockedSymbol(code string) api.SymbolError {
+	return api.SymbolError{Symbol: code, Error: candles.ErrUpgradeRequired.Code}
+}
+
+// parseSymbols は ?symbols= をカンマで分割して返します（前後の空白と空要素は無視）。
+// 空・上限（limit）超過・不正な形式の場合は 400 を書き込み ok=false を返します。
+func parseSymbols(w http.ResponseWriter, r *http.Request, limit int) ([]string, bool) {
+	var codes []string
+	for c := range strings.SplitSeq(r.URL.Query().Get("symbols"), ",") {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+		if !symbolCodePattern.MatchString(c) {
+			httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "invalid symbol code"})
+			return nil, false
+		}
+		codes = append(codes, c)
+	}
+	if len(codes) == 0 || len(codes) > limit {
+		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{
+			Error: fmt.Sprintf("symbols must list 1 to %d codes", limit),
+		})
+		return nil, false
+	}
+	return codes, true
+}
+
+// parseSparklineQuery は interval・outputsize・points・adjusted を解釈します。
+// 不正な値の場合は 400 を書き込み ok=false を返します。
+func parseSparklineQuery(w http.ResponseWriter, r *http.Request) (sparklineQuery, bool) {
+	q := sparklineQuery{interval: queryOrDefault(r, "interval", candles.DefaultInterval)}
+
+	// 未指定（0）は usecase が時間間隔ごとの既定値（candles.OutputSizePolicy）を適用する
+	outputsize, err := strconv.Atoi(queryOrDefault(r, "outputsize", "0"))
+	if err != nil {
+		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "outputsize must be an integer"})
+		return q, false
+	}
+	q.outputsize = outputsize
+
+	points, err := strconv.Atoi(queryOrDefault(r, "points", strconv.Itoa(candles.DefaultSparklinePoints)))
+	if err != nil || points < candles.MinSparklinePoints || points > candles.MaxSparklinePoints {
+		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{
+			Error: fmt.Sprintf("points must be an integer between %d and %d", candles.MinSparklinePoints, candles.MaxSparklinePoints),
+		})
+		return q, false
+	}
+	q.points = points
+
+	adjust, ok := adjustMode(w, r)
+	if !ok {
+		return q, false
+	}
+	q.adjust = adjust
+	return q, true
+}
+
+// toSparkline はドメインの Sparkline を API 型に変換します。データがない場合 first / last は省略します。
+func toSparkline(code string, s candles.Sparkline) api.Sparkline {
+	out := api.Sparkline{Symbol: code, Closes: s.Closes}
+	if out.Closes == nil {
+		out.Closes = []float64{}
+	}
+	if !s.First.IsZero() {
+		first, last := api.NewTimestamp(s.First), api.NewTimestamp(s.Last)
+		out.First, out.Last = &first, &last
+	}
+	return out
+}
@@ -0,0 +1,232 @@
+package candleshttp_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/candleshttp"
+)
+
+// newSparklineRouter はスパークラインの 2 つのエンドポイントを登録したルーターを返します。
+func newSparklineRouter(uc *mockUsecase) http.Handler {
+	h := candleshttp.NewHandler(uc, nil, nil)
+	router := chi.NewRouter()
+	router.Get("/candles/{code}/sparkline", h.GetSparklineHandler)
+	router.Get("/candles/sparklines", h.GetSparklinesHandler)
+	return router
+}
+
+var (
+	sparkFirst = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	sparkLast  = time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC)
+)
+
+func stubSparkline(ctx context.Context, symbol, interval string, outputsize, points int) (candles.Sparkline, error) {
+	return candles.Sparkline{Closes: []float64{1, 2, 3}, First: sparkFirst, Last: sparkLast}, nil
+}
+
+func TestCandlesHandler_GetSparklineHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		mockSparkline  func(ctx context.Context, symbol, interval string, outputsize, points int) (candles.Sparkline, error)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "success: defaults",
+			url:  "/candles/AAPL/sparkline",
+			mockSparkline: func(ctx context.Context, symbol, interval string, outputsize, points int) (candles.Sparkline, error) {
+				assert.Equal(t, "1day", interval)
+				assert.Equal(t, 0, outputsize) // 未指定（usecase が時間間隔ごとの既定値を適用）
+				assert.Equal(t, candles.DefaultSparklinePoints, points)
+				return stubSparkline(ctx, symbol, interval, outputsize, points)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"symbol":"AAPL","closes":[1,2,3],"first":"2024-03-01T00:00:00Z","last":"2024-03-08T00:00:00Z"}`,
+		},
+		{
+			name: "success: no candles omits range",
+			url:  "/candles/NEWCO/sparkline?interval=1week&outputsize=7&points=5",
+			mockSparkline: func(ctx context.Context, symbol, interval string, outputsize, points int) (candles.Sparkline, error) {
+				assert.Equal(t, "1week", interval)
+				assert.Equal(t, 7, outputsize)
+				assert.Equal(t, 5, points)
+				return candles.Sparkline{Closes: []float64{}}, nil
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"symbol":"NEWCO","closes":[]}`,
+		},
+		{
+			name: "error: unknown symbol returns 404",
+			url:  "/candles/UNKNOWN/sparkline",
+			mockSparkline: func(ctx context.Context, symbol, interval string, outputsize, points int) (candles.Sparkline, error) {
+				return candles.Sparkline{}, candles.ErrSymbolNotFound
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"symbol_not_found"}`,
+		},
+		{
+			name: "error: symbol above the plan returns 402",
+			url:  "/candles/NVDA/sparkline",
+			mockSparkline: func(ctx context.Context, symbol, interval string, outputsize, points int) (candles.Sparkline, error) {
+				return candles.Sparkline{}, &candles.UpgradeRequiredError{Symbol: symbol}
+			},
+			expectedStatus: http.StatusPaymentRequired,
+			expectedBody:   `{"error":"upgrade_required","hint":"upgrade to the premium plan to access NVDA"}`,
+		},
+		{
+			name:           "error: points out of range returns 400",
+			url:            "/candles/AAPL/sparkline?points=1",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"points must be an integer between 2 and 500"}`,
+		},
+		{
+			name:           "error: non-integer points returns 400",
+			url:            "/candles/AAPL/sparkline?points=many",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"points must be an integer between 2 and 500"}`,
+		},
+		{
+			name:           "error: invalid symbol code returns 400",
+			url:            "/candles/7203%26T/sparkline",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid symbol code"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			newSparklineRouter(&mockUsecase{GetSparklineFunc: tt.mockSparkline}).
+				ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			if w.Code == http.StatusOK {
+				assert.Equal(t, "private, max-age=300", w.Header().Get("Cache-Control"))
+			}
+		})
+	}
+}
+
+func TestCandlesHandler_GetSparklinesHandler(t *testing.T) {
+	// "7203" は 7203.T に解決され、"UNKNOWN" は解決できない
+	resolve := func(ctx context.Context, symbol string) (string, error) {
+		switch symbol {
+		case "UNKNOWN":
+			return "", candles.ErrSymbolNotFound
+		case "7203":
+			return "7203.T", nil
+		}
+		return symbol, nil
+	}
+
+	t.Run("success: resolves, dedupes and reports unknown and locked symbols", func(t *testing.T) {
+		var got []string
+		uc := &mockUsecase{
+			ResolveSymbolFunc: resolve,
+			GetSparklineFunc: func(ctx context.Context, symbol, interval string, outputsize, points int) (candles.Sparkline, error) {
+				got = append(got, symbol)
+				if symbol == "NVDA" {
+					return candles.Sparkline{}, &candles.UpgradeRequiredError{Symbol: symbol}
+				}
+				assert.Equal(t, 10, points)
+				return stubSparkline(ctx, symbol, interval, outputsize, points)
+			},
+		}
+		w := httptest.NewRecorder()
+		newSparklineRouter(uc).ServeHTTP(w, httptest.NewRequest(http.MethodGet,
+			"/candles/sparklines?symbols=AAPL,%207203,UNKNOWN,NVDA,7203.T,,&points=10", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{"AAPL", "7203.T", "NVDA"}, got)
+		assert.JSONEq(t, `{
+			"sparklines":[
+				{"symbol":"AAPL","closes":[1,2,3],"first":"2024-03-01T00:00:00Z","last":"2024-03-08T00:00:00Z"},
+				{"symbol":"7203.T","closes":[1,2,3],"first":"2024-03-01T00:00:00Z","last":"2024-03-08T00:00:00Z"}
+			],
+			"unknown":["UNKNOWN"],
+			"errors":[{"symbol":"NVDA","error":"upgrade_required"}]
+		}`, w.Body.String())
+		assert.Equal(t, "private, max-age=300", w.Header().Get("Cache-Control"))
+	})
+
+	t.Run("success: all unknown returns empty list", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		newSparklineRouter(&mockUsecase{ResolveSymbolFunc: resolve}).
+			ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/candles/sparklines?symbols=UNKNOWN", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"sparklines":[],"unknown":["UNKNOWN"],"errors":[]}`, w.Body.String())
+	})
+
+	overCap := make([]string, candleshttp.MaxSparklineSymbols+1)
+	for i := range overCap {
+		overCap[i] = "AAPL"
+	}
+
+	tests := []struct {
+		name         string
+		url          string
+		expectedBody string
+	}{
+		{
+			name:         "missing symbols",
+			url:          "/candles/sparklines",
+			expectedBody: `{"error":"symbols must list 1 to 50 codes"}`,
+		},
+		{
+			name:         "only separators",
+			url:          "/candles/sparklines?symbols=,,",
+			expectedBody: `{"error":"symbols must list 1 to 50 codes"}`,
+		},
+		{
+			name:         "over the cap",
+			url:          "/candles/sparklines?symbols=" + strings.Join(overCap, ","),
+			expectedBody: `{"error":"symbols must list 1 to 50 codes"}`,
+		},
+		{
+			name:         "invalid code",
+			url:          "/candles/sparklines?symbols=AAPL,7203%26T",
+			expectedBody: `{"error":"invalid symbol code"}`,
+		},
+		{
+			name:         "points out of range",
+			url:          "/candles/sparklines?symbols=AAPL&points=501",
+			expectedBody: `{"error":"points must be an integer between 2 and 500"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run("error: "+tt.name+" returns 400", func(t *testing.T) {
+			uc := &mockUsecase{GetSparklineFunc: func(ctx context.Context, symbol, interval string, outputsize, points int) (candles.Sparkline, error) {
+				t.Error("GetSparkline should not be called")
+				return candles.Sparkline{}, nil
+			}}
+			w := httptest.NewRecorder()
+			newSparklineRouter(uc).ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+
+	t.Run("error: usecase failure returns 500", func(t *testing.T) {
+		uc := &mockUsecase{GetSparklineFunc: func(ctx context.Context, symbol, interval string, outputsize, points int) (candles.Sparkline, error) {
+			return candles.Sparkline{}, errors.New("db down")
+		}}
+		w := httptest.NewRecorder()
+		newSparklineRouter(uc).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/candles/sparklines?symbols=AAPL", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -0,0 +1,82 @@
+package candleshttp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+)
+
+// SymbolInfo はローソク足の応答（?include=symbol）に含める銘柄の情報です。
+type SymbolInfo struct {
+	Code   string
+	Name   string // 指定されたロケールの表記（登録がない場合は既定の表記）
+	Market string
+	Status string // "active" / "delisted"
+}
+
+// SymbolInfoSource は銘柄の名前・市場・状態を提供します（?include=symbol）。
+// candleshttp が symbollist feature に直接依存しないよう、合成ルートで適合させて注入します。
+type SymbolInfoSource interface {
+	// SymbolInfo は銘柄 code の情報を locale の名前で返します。参照できない銘柄の場合は ok=false を返します。
+	SymbolInfo(ctx context.Context, code, locale string) (info SymbolInfo, ok bool, err error)
+}
+
+// WithSymbolInfo は ?include=symbol で応答に含める銘柄の情報の取得元と、銘柄名の応答ロケールの候補（先頭が既定）を設定します。
+// 未設定の場合 ?include=symbol は 400 になり、v2 の Accept でも従来の配列を返します。
+func (h *Handler) WithSymbolInfo(src SymbolInfoSource, locales []string) *Handler {
+	h.symbols = src
+	h.locales = locales
+	return h
+}
+
+// includeSymbol は応答を銘柄の情報を含む形（CandlesEnvelope）にするかを返します。
+// ?include=symbol、または Accept に httpx.V2MediaType を指定した場合が対象で、v1 のクライアントの応答は配列のまま変わりません。
+// symbol 以外の include、または取得元が未設定で include=symbol の場合は 400 を書き込み ok=false を返します。
+func (h *Handler) includeSymbol(w http.ResponseWriter, r *http.Request) (bool, bool) {
+	q := r.URL.Query()
+	if !q.Has("include") {
+		if h.symbols == nil {
+			return false, true
+		}
+		// 応答の形が Accept で変わるため、共有キャッシュが v1 と v2 の応答を取り違えないようにする
+		w.Header().Add("Vary", "Accept")
+		return httpx.AcceptsMediaType(r, httpx.V2MediaType), true
+	}
+	if q.Get("include") != "symbol" {
+		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "include must be symbol"})
+		return false, false
+	}
+	if h.symbols == nil {
+		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "include=symbol is not available"})
+		return false, false
+	}
+	return true, true
+}
+
+// symbolInfo は解決済みの code の情報を Accept-Language で選んだロケールの名前で取得し、Content-Language を設定します。
+// 取得元は存在チェックと同じキャッシュのため、1 回の呼び出しで DB は読みません。
+// 参照できない銘柄は ErrSymbolNotFound（404）、取得の失敗はそのエラーを書き込み ok=false を返します。
+func (h *Handler) symbolInfo(w http.ResponseWriter, r *http.Request, code string) (api.CandleSymbol, bool) {
+	locale := httpx.NegotiateLocale(r, h.locales)
+	info, ok, err := h.symbols.SymbolInfo(r.Context(), code, locale)
+	if err == nil && !ok {
+		err = candles.ErrSymbolNotFound
+	}
+	if err != nil {
+		httpx.WriteError(w, err, "failed to get symbol info", "code", code)
+		return api.CandleSymbol{}, false
+	}
+	if locale != "" {
+		httpx.SetLocaleHeaders(w, locale)
+	}
+	return api.CandleSymbol{Code: info.Code, Name: info.Name, Market: info.Market, Status: info.Status}, true
+}
+
+// projectedEnvelope は ?fields= で項目を絞った CandlesEnvelope です。
+type projectedEnvelope struct {
+	Symbol  api.CandleSymbol  `json:"symbol"`
+	Candles []projectedCandle `json:"candles"`
+}
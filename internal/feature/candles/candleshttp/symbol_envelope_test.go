@@ -0,0 +1,170 @@
+package candleshttp_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/candleshttp"
+)
+
+// stubSymbolInfo はインメモリの SymbolInfoSource です。names はロケールごとの名前で、ないロケールは "ja" の名前を返します。
+type stubSymbolInfo struct {
+	symbols map[string]candleshttp.SymbolInfo
+	names   map[string]map[string]string // code -> locale -> name
+	calls   int
+}
+
+func (s *stubSymbolInfo) SymbolInfo(_ context.Context, code, locale string) (candleshttp.SymbolInfo, bool, error) {
+	s.calls++
+	if code == "FAIL" {
+		return candleshttp.SymbolInfo{}, false, errors.New("cache down")
+	}
+	info, ok := s.symbols[code]
+	if !ok {
+		return candleshttp.SymbolInfo{}, false, nil
+	}
+	if name, ok := s.names[code][locale]; ok {
+		info.Name = name
+	}
+	return info, true, nil
+}
+
+func newStubSymbolInfo() *stubSymbolInfo {
+	return &stubSymbolInfo{
+		symbols: map[string]candleshttp.SymbolInfo{
+			"7203.T": {Code: "7203.T", Name: "トヨタ自動車", Market: "TSE", Status: "active"},
+			"TWTR":   {Code: "TWTR", Name: "Twitter Inc.", Market: "NYSE", Status: "delisted"},
+		},
+		names: map[string]map[string]string{"7203.T": {"en": "Toyota Motor"}},
+	}
+}
+
+// serveWithSymbolInfo は src を設定したハンドラーで GET を処理し、レスポンスを返します。
+func serveWithSymbolInfo(t *testing.T, src candleshttp.SymbolInfoSource, url string, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+	mockUC := &mockUsecase{
+		ResolveSymbolFunc: func(_ context.Context, symbol string) (string, error) {
+			if symbol == "7203" {
+				return "7203.T", nil
+			}
+			if symbol == "UNKNOWN" {
+				return "", candles.ErrSymbolNotFound
+			}
+			return symbol, nil
+		},
+		GetCandlesFunc: func(context.Context, string, string, int) ([]candles.Candle, error) {
+			return []candles.Candle{{Time: utcDay(2026, 5, 12), Open: 100, High: 110, Low: 90, Close: 105, Volume: 1000}}, nil
+		},
+	}
+	h := candleshttp.NewHandler(mockUC, nil, nil)
+	if src != nil {
+		h = h.WithSymbolInfo(src, []string{"ja", "en"})
+	}
+	router := chi.NewRouter()
+	router.Get("/candles/{code}", h.GetCandlesHandler)
+
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	for k, vs := range header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// TestCandlesHandler_IncludeSymbol は ?include=symbol と v2 の Accept で応答が {symbol, candles} の形になり、
+// それ以外は従来の配列のまま変わらないことを検証します。
+func TestCandlesHandler_IncludeSymbol(t *testing.T) {
+	t.Parallel()
+
+	const candle = `{"time":"2026-05-12","open":100,"high":110,"low":90,"close":105,"volume":1000}`
+	v2 := http.Header{"Accept": {"application/vnd.stock.v2+json"}}
+	tests := []struct {
+		name         string
+		url          string
+		header       http.Header
+		wantStatus   int
+		wantBody     string
+		wantLanguage string
+	}{
+		{"既定は配列", "/candles/7203.T", nil, http.StatusOK, `[` + candle + `]`, ""},
+		{"v1 の Accept は配列", "/candles/7203.T", http.Header{"Accept": {"application/json"}}, http.StatusOK, `[` + candle + `]`, ""},
+		{
+			"include=symbol", "/candles/7203.T?include=symbol", nil, http.StatusOK,
+			`{"symbol":{"code":"7203.T","name":"トヨタ自動車","market":"TSE","status":"active"},"candles":[` + candle + `]}`, "ja",
+		},
+		{
+			"v2 の Accept は既定でエンベロープ", "/candles/7203.T", v2, http.StatusOK,
+			`{"symbol":{"code":"7203.T","name":"トヨタ自動車","market":"TSE","status":"active"},"candles":[` + candle + `]}`, "ja",
+		},
+		{
+			"名前は Accept-Language のロケール", "/candles/7203.T?include=symbol", http.Header{"Accept-Language": {"en-US,en;q=0.9"}}, http.StatusOK,
+			`{"symbol":{"code":"7203.T","name":"Toyota Motor","market":"TSE","status":"active"},"candles":[` + candle + `]}`, "en",
+		},
+		{
+			"名前の登録がないロケールは既定の名前", "/candles/TWTR?include=symbol", http.Header{"Accept-Language": {"en"}}, http.StatusOK,
+			`{"symbol":{"code":"TWTR","name":"Twitter Inc.","market":"NYSE","status":"delisted"},"candles":[` + candle + `]}`, "en",
+		},
+		{
+			"解決した正規コードの銘柄を返す", "/candles/7203?include=symbol", nil, http.StatusOK,
+			`{"symbol":{"code":"7203.T","name":"トヨタ自動車","market":"TSE","status":"active"},"candles":[` + candle + `]}`, "ja",
+		},
+		{
+			"fields と併用", "/candles/7203.T?include=symbol&fields=time,close", nil, http.StatusOK,
+			`{"symbol":{"code":"7203.T","name":"トヨタ自動車","market":"TSE","status":"active"},"candles":[{"time":"2026-05-12","close":105}]}`, "ja",
+		},
+		{"存在しない銘柄は 404", "/candles/UNKNOWN?include=symbol", nil, http.StatusNotFound, `{"error":"symbol_not_found"}`, ""},
+		{"キャッシュで参照できない銘柄は 404", "/candles/HIDDEN?include=symbol", nil, http.StatusNotFound, `{"error":"symbol_not_found"}`, ""},
+		{"銘柄の情報を取得できない", "/candles/FAIL?include=symbol", nil, http.StatusInternalServerError, `{"error":"internal server error"}`, ""},
+		{"symbol 以外の include は 400", "/candles/7203.T?include=user", nil, http.StatusBadRequest, `{"error":"include must be symbol"}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			w := serveWithSymbolInfo(t, newStubSymbolInfo(), tt.url, tt.header)
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+			assert.Equal(t, tt.wantLanguage, w.Header().Get("Content-Language"))
+		})
+	}
+}
+
+// TestCandlesHandler_IncludeSymbolSingleLookup は銘柄の情報の取得が 1 回で、
+// v2 の Accept で応答の形が変わるため Vary: Accept を付けることを検証します。
+func TestCandlesHandler_IncludeSymbolSingleLookup(t *testing.T) {
+	t.Parallel()
+
+	src := newStubSymbolInfo()
+	w := serveWithSymbolInfo(t, src, "/candles/7203.T", http.Header{"Accept": {"application/vnd.stock.v2+json"}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, src.calls)
+	assert.Equal(t, []string{"Accept", "Accept-Language"}, w.Header().Values("Vary"))
+
+	src = newStubSymbolInfo()
+	w = serveWithSymbolInfo(t, src, "/candles/7203.T", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Zero(t, src.calls, "配列の応答では銘柄の情報を引かない")
+	assert.Equal(t, []string{"Accept"}, w.Header().Values("Vary"))
+}
+
+// TestCandlesHandler_IncludeSymbolWithoutSource は取得元が未設定の場合、include=symbol は 400、v2 の Accept は配列になることを検証します。
+func TestCandlesHandler_IncludeSymbolWithoutSource(t *testing.T) {
+	t.Parallel()
+
+	w := serveWithSymbolInfo(t, nil, "/candles/7203.T?include=symbol", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"include=symbol is not available"}`, w.Body.String())
+
+	w = serveWithSymbolInfo(t, nil, "/candles/7203.T", http.Header{"Accept": {"application/vnd.stock.v2+json"}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{"time":"2026-05-12","open":100,"high":110,"low":90,"close":105,"volume":1000}]`, w.Body.String())
+}
@@ -0,0 +1,279 @@
+package candles
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultCSVBatchSize は CSV 取り込み時に UpsertBatch 1 回で書き込む行数です。
+	DefaultCSVBatchSize = 500
+	// DefaultCSVMaxRowErrors は CSVImportResult に保持する不正行の上限数です。
+	DefaultCSVMaxRowErrors = 100
+	// DefaultCSVDateLayout は日付列のデフォルトのレイアウトです。
+	DefaultCSVDateLayout = "2006-01-02"
+)
+
+// csvColumnAliases はヘッダー名（小文字・前後空白除去後）から列種別への対応です。
+// ベンダーごとの表記揺れを吸収し、列順はヘッダーから検出します。
+var csvColumnAliases = map[string]string{
+	"date":      "time",
+	"time":      "time",
+	"datetime":  "time",
+	"timestamp": "time",
+	"open":      "open",
+	"high":      "high",
+	"low":       "low",
+	"close":     "close",
+	"volume":    "volume",
+	"vol":       "volume",
+}
+
+// csvRequiredColumns は CSV に必須の列です。
+var csvRequiredColumns = []string{"time", "open", "high", "low", "close", "volume"}
+
+// CSVImportOptions は ImportCSV の取り込み設定です。
+type CSVImportOptions struct {
+	Symbol     string         // 取り込み先の銘柄コード（例: "7203.T"）
+	Interval   string         // 取り込み先の時間間隔（例: "1day"）
+	DateLayout string         // 日付列の time.Parse レイアウト。空なら DefaultCSVDateLayout
+	Location   *time.Location // 日付列の解釈ロケール。nil なら UTC
+	BatchSize  int            // UpsertBatch 1 回あたりの行数。0 以下なら DefaultCSVBatchSize
+	MaxErrors  int            // 保持する不正行の上限。0 以下なら DefaultCSVMaxRowErrors
+	Strict     bool           // true なら最初の不正行で中断する
+	Source     string         // 取り込み元（例: CSVSource(ファイル名)）。空なら SourceCSV
+}
+
+// CSVRowError は取り込めなかった行とその理由です。Line はヘッダーを 1 行目とする行番号です。
+type CSVRowError struct {
+	Line   int
+	Reason string
+}
+
+func (e CSVRowError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Reason)
+}
+
+// CSVImportResult は ImportCSV の集計結果です。
+// Errors は先頭から MaxErrors 件までで、Skipped は上限を超えた分も含む総数です。
+type CSVImportResult struct {
+	Read     int // 読み込んだデータ行数（ヘッダーを除く）
+	Inserted int // 新規に挿入した行数
+	Updated  int // 既存の行を上書きした行数
+	Skipped  int // 不正・重複によりスキップした行数
+	Errors   []CSVRowError
+}
+
+// ImportCSV は OHLCV の CSV をストリームで読み込み、検証したうえで repo にバッチ Upsert します。
+//
+// 1 行目はヘッダーとして扱い、列順は列名（date/open/high/low/close/volume 等）から検出します。
+// 各行は Candle.Validate で検証し、ファイル内で重複するタイムスタンプは最初の行のみ採用します。
+// 不正行はスキップして CSVImportResult に記録しますが、opts.Strict が true の場合は
+// 最初の不正行で CSVRowError を返して中断します（それまでのバッチは書き込み済み）。
+// CLI と将来の管理画面アップロードの双方から使えるよう、入出力は io.Reader と WriteRepository に限定しています。
+func ImportCSV(ctx context.Context, r io.Reader, repo WriteRepository, opts CSVImportOptions) (CSVImportResult, error) {
+	opts = opts.withDefaults()
+	var result CSVImportResult
+
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+	cr.FieldsPerRecord = -1 // 列数の不一致は行単位のエラーとして扱う
+
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return result, errors.New("csv: empty input")
+		}
+		return result, fmt.Errorf("csv: read header: %w", err)
+	}
+	cols, err := detectCSVColumns(header)
+	if err != nil {
+		return result, err
+	}
+
+	batch := make([]Candle, 0, opts.BatchSize)
+	seen := make(map[int64]struct{})
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		stats, err := repo.UpsertBatch(ctx, batch)
+		if err != nil {
+			return fmt.Errorf("csv: upsert batch ending at row %d: %w", result.Read, err)
+		}
+		result.Inserted += int(stats.Inserted)
+		result.Updated += int(stats.Updated)
+		// 書き込み先がスライスを保持しても上書きされないよう、次のバッチは新しく確保する
+		batch = make([]Candle, 0, opts.BatchSize)
+		return nil
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return result, fmt.Errorf("csv: read: %w", err)
+			}
+			// 引用符の不整合等は行単位の不正として扱う
+			result.Read++
+			if err := result.skip(opts, CSVRowError{Line: parseErr.StartLine, Reason: parseErr.Err.Error()}); err != nil {
+				return result, err
+			}
+			continue
+		}
+		result.Read++
+		line, _ := cr.FieldPos(0)
+
+		c, err := parseCSVRow(record, cols, opts)
+		if err == nil {
+			err = c.Validate()
+		}
+		if err == nil {
+			if _, dup := seen[c.Time.Unix()]; dup {
+				err = fmt.Errorf("duplicate timestamp %s", c.Time.Format(opts.DateLayout))
+			}
+		}
+		if err != nil {
+			if err := result.skip(opts, CSVRowError{Line: line, Reason: err.Error()}); err != nil {
+				return result, err
+			}
+			continue
+		}
+
+		seen[c.Time.Unix()] = struct{}{}
+		batch = append(batch, c)
+		if len(batch) >= opts.BatchSize {
+			if err := flush(); err != nil {
+				return result, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// skip は不正行を記録します。Strict モードでは rowErr を返して取り込みを中断させます。
+func (r *CSVImportResult) skip(opts CSVImportOptions, rowErr CSVRowError) error {
+	r.Skipped++
+	if len(r.Errors) < opts.MaxErrors {
+		r.Errors = append(r.Errors, rowErr)
+	}
+	if opts.Strict {
+		return rowErr
+	}
+	return nil
+}
+
+func (o CSVImportOptions) withDefaults() CSVImportOptions {
+	if o.DateLayout == "" {
+		o.DateLayout = DefaultCSVDateLayout
+	}
+	if o.Location == nil {
+		o.Location = time.UTC
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = DefaultCSVBatchSize
+	}
+	if o.MaxErrors <= 0 {
+		o.MaxErrors = DefaultCSVMaxRowErrors
+	}
+	if o.Source == "" {
+		o.Source = SourceCSV
+	}
+	return o
+}
+
+// detectCSVColumns はヘッダー行から列種別ごとの列インデックスを返します。
+// 必須列が欠けている、または同じ種別の列が重複している場合はエラーを返します。
+func detectCSVColumns(header []string) (map[string]int, error) {
+	cols := make(map[string]int, len(csvRequiredColumns))
+	for i, name := range header {
+		// Excel 出力の UTF-8 BOM を除去する
+		key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		kind, ok := csvColumnAliases[key]
+		if !ok {
+			continue // adj close 等の未使用列は無視
+		}
+		if _, dup := cols[kind]; dup {
+			return nil, fmt.Errorf("csv: duplicate %s column %q", kind, name)
+		}
+		cols[kind] = i
+	}
+	var missing []string
+	for _, kind := range csvRequiredColumns {
+		if _, ok := cols[kind]; !ok {
+			missing = append(missing, kind)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("csv: header missing columns: %s", strings.Join(missing, ", "))
+	}
+	return cols, nil
+}
+
+// parseCSVRow は 1 行を Candle に変換します。値の整合性検証は呼び出し側で Validate により行います。
+func parseCSVRow(record []string, cols map[string]int, opts CSVImportOptions) (Candle, error) {
+	field := func(kind string) (string, error) {
+		i := cols[kind]
+		if i >= len(record) {
+			return "", fmt.Errorf("missing %s column", kind)
+		}
+		return strings.TrimSpace(record[i]), nil
+	}
+
+	raw, err := field("time")
+	if err != nil {
+		return Candle{}, err
+	}
+	t, err := time.ParseInLocation(opts.DateLayout, raw, opts.Location)
+	if err != nil {
+		return Candle{}, fmt.Errorf("invalid date %q: want layout %q", raw, opts.DateLayout)
+	}
+
+	c := Candle{SymbolCode: opts.Symbol, Interval: opts.Interval, Time: t, Source: opts.Source}
+	for _, p := range []struct {
+		kind string
+		dst  *float64
+	}{{"open", &c.Open}, {"high", &c.High}, {"low", &c.Low}, {"close", &c.Close}} {
+		raw, err := field(p.kind)
+		if err != nil {
+			return Candle{}, err
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return Candle{}, fmt.Errorf("invalid %s %q", p.kind, raw)
+		}
+		*p.dst = v
+	}
+
+	raw, err = field("volume")
+	if err != nil {
+		return Candle{}, err
+	}
+	// ベンダーによっては出来高が "1234.0" 形式のため、整数で読めなければ小数として解釈する
+	vol, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		f, ferr := strconv.ParseFloat(raw, 64)
+		if ferr != nil || f != float64(int64(f)) {
+			return Candle{}, fmt.Errorf("invalid volume %q", raw)
+		}
+		vol = int64(f)
+	}
+	c.Volume = vol
+	return c, nil
+}
@@ -0,0 +1,289 @@
+package candles
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingWriter は UpsertBatch に渡されたバッチを記録する WriteRepository です。
+// バッチの行はすべて新規挿入として数えます。
+type recordingWriter struct {
+	batches [][]Candle
+	err     error
+	onBatch func(batch []Candle)
+}
+
+func (w *recordingWriter) UpsertBatch(ctx context.Context, candles []Candle) (UpsertStats, error) {
+	if w.onBatch != nil {
+		w.onBatch(candles)
+	}
+	if w.err != nil {
+		return UpsertStats{}, w.err
+	}
+	w.batches = append(w.batches, candles)
+	return UpsertStats{Inserted: int64(len(candles))}, nil
+}
+
+func (w *recordingWriter) all() []Candle {
+	var out []Candle
+	for _, b := range w.batches {
+		out = append(out, b...)
+	}
+	return out
+}
+
+func TestImportCSV_HeaderPermutations(t *testing.T) {
+	testCases := []struct {
+		name string
+		csv  string
+	}{
+		{
+			name: "standard order",
+			csv:  "date,open,high,low,close,volume\n2024-01-05,100,110,90,105,1000\n",
+		},
+		{
+			name: "shuffled with extra column and mixed case",
+			csv:  "Volume, Close ,Adj Close,LOW,High,Open,Date\n1000,105,104.5,90,110,100,2024-01-05\n",
+		},
+		{
+			name: "aliases with BOM",
+			csv:  "\ufefftimestamp,open,high,low,close,vol\n2024-01-05,100,110,90,105,1000.0\n",
+		},
+	}
+
+	want := Candle{
+		SymbolCode: "7203.T", Interval: "1day",
+		Time: time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC),
+		Open: 100, High: 110, Low: 90, Close: 105, Volume: 1000,
+		Source: SourceCSV, // 取り込み元の指定がなければ csv
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := &recordingWriter{}
+			result, err := ImportCSV(context.Background(), strings.NewReader(tc.csv), w,
+				CSVImportOptions{Symbol: "7203.T", Interval: "1day"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Read != 1 || result.Inserted != 1 || result.Skipped != 0 {
+				t.Fatalf("result=%+v, want Read=1 Inserted=1 Skipped=0", result)
+			}
+			got := w.all()
+			if len(got) != 1 || got[0] != want {
+				t.Errorf("candles=%+v, want [%+v]", got, want)
+			}
+		})
+	}
+}
+
+func TestImportCSV_HeaderErrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		csv     string
+		wantMsg string
+	}{
+		{name: "empty", csv: "", wantMsg: "empty input"},
+		{name: "missing volume", csv: "date,open,high,low,close\n", wantMsg: "missing columns: volume"},
+		{name: "duplicate alias", csv: "date,timestamp,open,high,low,close,volume\n", wantMsg: "duplicate time column"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ImportCSV(context.Background(), strings.NewReader(tc.csv), &recordingWriter{}, CSVImportOptions{})
+			if err == nil || !strings.Contains(err.Error(), tc.wantMsg) {
+				t.Errorf("err=%v, want containing %q", err, tc.wantMsg)
+			}
+		})
+	}
+}
+
+func TestImportCSV_SkipsBadRows(t *testing.T) {
+	input := strings.Join([]string{
+		"date,open,high,low,close,volume",
+		"2024-01-02,100,110,90,105,1000", // line 2: ok
+		"2024/01/03,100,110,90,105,1000", // line 3: bad date
+		"2024-01-04,abc,110,90,105,1000", // line 4: bad number
+		"2024-01-05,100,80,90,105,1000",  // line 5: high < low
+		"2024-01-02,101,111,91,106,2000", // line 6: duplicate timestamp
+		"2024-01-08,100,110,90",          // line 7: short row
+		"2024-01-09,100,110,90,105,1000", // line 8: ok
+	}, "\n")
+
+	w := &recordingWriter{}
+	result, err := ImportCSV(context.Background(), strings.NewReader(input), w,
+		CSVImportOptions{Symbol: "AAPL", Interval: "1day"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Read != 7 || result.Inserted != 2 || result.Skipped != 5 {
+		t.Errorf("result=%+v, want Read=7 Inserted=2 Skipped=5", result)
+	}
+
+	wantReasons := map[int]string{
+		3: "invalid date",
+		4: "invalid open",
+		5: "high is below low",
+		6: "duplicate timestamp 2024-01-02",
+		7: "missing close column",
+	}
+	if len(result.Errors) != len(wantReasons) {
+		t.Fatalf("errors=%+v, want %d entries", result.Errors, len(wantReasons))
+	}
+	for _, e := range result.Errors {
+		want, ok := wantReasons[e.Line]
+		if !ok || !strings.Contains(e.Reason, want) {
+			t.Errorf("line %d reason=%q, want containing %q", e.Line, e.Reason, want)
+		}
+	}
+	// 重複時は最初の行が採用される
+	if got := w.all(); len(got) != 2 || got[0].Open != 100 {
+		t.Errorf("candles=%+v, want first occurrence of 2024-01-02 kept", got)
+	}
+}
+
+func TestImportCSV_MaxErrorsCap(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("date,open,high,low,close,volume\n")
+	for i := 0; i < 10; i++ {
+		sb.WriteString("bad,100,110,90,105,1000\n")
+	}
+
+	result, err := ImportCSV(context.Background(), strings.NewReader(sb.String()), &recordingWriter{},
+		CSVImportOptions{MaxErrors: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Skipped != 10 || len(result.Errors) != 3 {
+		t.Errorf("Skipped=%d len(Errors)=%d, want 10 and 3", result.Skipped, len(result.Errors))
+	}
+}
+
+func TestImportCSV_Strict(t *testing.T) {
+	input := "date,open,high,low,close,volume\n" +
+		"2024-01-02,100,110,90,105,1000\n" +
+		"2024-01-03,100,110,90,105,-5\n" +
+		"2024-01-04,100,110,90,105,1000\n"
+
+	w := &recordingWriter{}
+	result, err := ImportCSV(context.Background(), strings.NewReader(input), w, CSVImportOptions{Strict: true})
+
+	var rowErr CSVRowError
+	if !errors.As(err, &rowErr) || rowErr.Line != 3 {
+		t.Fatalf("err=%v, want CSVRowError at line 3", err)
+	}
+	if result.Read != 2 || result.Inserted != 0 {
+		t.Errorf("result=%+v, want Read=2 Inserted=0 (pending batch is not flushed)", result)
+	}
+	if len(w.batches) != 0 {
+		t.Errorf("batches=%d, want 0", len(w.batches))
+	}
+}
+
+func TestImportCSV_DateLayoutAndLocation(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := "Date,Open,High,Low,Close,Volume\n01/05/2024,100,110,90,105,1000\n"
+
+	w := &recordingWriter{}
+	if _, err := ImportCSV(context.Background(), strings.NewReader(input), w,
+		CSVImportOptions{DateLayout: "01/02/2006", Location: tokyo}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := time.Date(2024, 1, 5, 0, 0, 0, 0, tokyo)
+	if got := w.all(); len(got) != 1 || !got[0].Time.Equal(want) {
+		t.Errorf("candles=%+v, want time %v", got, want)
+	}
+}
+
+func TestImportCSV_Source(t *testing.T) {
+	input := "date,open,high,low,close,volume\n2024-01-05,100,110,90,105,1000\n2024-01-08,105,112,100,110,1200\n"
+	source := CSVSource("7203.csv")
+
+	w := &recordingWriter{}
+	if _, err := ImportCSV(context.Background(), strings.NewReader(input), w,
+		CSVImportOptions{Symbol: "7203.T", Interval: "1day", Source: source}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, c := range w.all() {
+		if c.Source != source {
+			t.Errorf("candle %v Source = %q, want %q", c.Time, c.Source, source)
+		}
+	}
+}
+
+func TestImportCSV_UpsertError(t *testing.T) {
+	input := "date,open,high,low,close,volume\n2024-01-02,100,110,90,105,1000\n"
+	w := &recordingWriter{err: ErrDB}
+
+	result, err := ImportCSV(context.Background(), strings.NewReader(input), w, CSVImportOptions{})
+	if !errors.Is(err, ErrDB) {
+		t.Fatalf("err=%v, want ErrDB", err)
+	}
+	if result.Inserted != 0 {
+		t.Errorf("Inserted=%d, want 0", result.Inserted)
+	}
+}
+
+// generatedCSV は n 行の CSV をオンデマンドで生成する io.Reader です。
+// 読み出し済みバイト数を記録し、取り込みがストリームで行われることの検証に使います。
+type generatedCSV struct {
+	rows, next int
+	buf        []byte
+	read       int64
+	start      time.Time
+}
+
+func (g *generatedCSV) Read(p []byte) (int, error) {
+	for len(g.buf) == 0 {
+		if g.next > g.rows {
+			return 0, io.EOF
+		}
+		if g.next == 0 {
+			g.buf = []byte("date,open,high,low,close,volume\n")
+		} else {
+			d := g.start.AddDate(0, 0, g.next)
+			g.buf = fmt.Appendf(nil, "%s,100,110,90,105,%d\n", d.Format("2006-01-02"), g.next)
+		}
+		g.next++
+	}
+	n := copy(p, g.buf)
+	g.buf = g.buf[n:]
+	g.read += int64(n)
+	return n, nil
+}
+
+// TestImportCSV_Streams は大きな入力を全件読み込まずに、バッチ単位で書き込むことを検証します。
+func TestImportCSV_Streams(t *testing.T) {
+	const rows = 50_000
+	src := &generatedCSV{rows: rows, start: time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	var firstBatchAt int64 = -1
+	var maxBatch int
+	w := &recordingWriter{onBatch: func(batch []Candle) {
+		if firstBatchAt < 0 {
+			firstBatchAt = src.read
+		}
+		maxBatch = max(maxBatch, len(batch))
+	}}
+
+	result, err := ImportCSV(context.Background(), src, w, CSVImportOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Read != rows || result.Inserted != rows {
+		t.Fatalf("result=%+v, want Read=Inserted=%d", result, rows)
+	}
+	if maxBatch != DefaultCSVBatchSize {
+		t.Errorf("max batch=%d, want %d", maxBatch, DefaultCSVBatchSize)
+	}
+	// 最初の書き込み時点で入力の 5% 未満しか読んでいなければストリーム処理とみなす
+	if firstBatchAt < 0 || firstBatchAt > src.read/20 {
+		t.Errorf("first batch after reading %d of %d bytes, want streaming", firstBatchAt, src.read)
+	}
+}
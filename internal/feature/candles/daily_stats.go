@@ -0,0 +1,204 @@
+package candles
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// DefaultDailyStatsMaxAge はロールアップの行をそのまま返す、再計算からの経過時間の上限です。
+// 日次の ingest で値の変わった銘柄は再計算されるため、1 日に余裕を持たせた値にします。
+const DefaultDailyStatsMaxAge = 36 * time.Hour
+
+// DailyStats は銘柄の日足の要約統計のロールアップ（symbol_daily_stats の 1 行）です。
+// 値は保存済み（未調整）の日足から ComputeStats で計算したものです。
+type DailyStats struct {
+	SymbolCode       string
+	AsOf             time.Time // 集計基準の最新の日足の時刻
+	High52W          float64
+	Low52W           float64
+	AvgVolume30D     float64
+	YTDChangePercent *float64 // 基準値が算出できない場合は nil
+	ComputedAt       time.Time
+}
+
+// newDailyStats は Stats からロールアップの行を作ります。
+func newDailyStats(code string, s Stats, computedAt time.Time) DailyStats {
+	return DailyStats{
+		SymbolCode:       code,
+		AsOf:             s.AsOf,
+		High52W:          s.High52W.Value,
+		Low52W:           s.Low52W.Value,
+		AvgVolume30D:     s.Volume30D.Average,
+		YTDChangePercent: s.YTDChangePercent,
+		ComputedAt:       computedAt,
+	}
+}
+
+// DailyStatsStore は要約統計のロールアップの保存先を抽象化します。
+// Goの慣例に従い、インターフェースは利用者（usecase）側で定義します。
+type DailyStatsStore interface {
+	// UpsertDailyStats は銘柄の行を上書きします。
+	UpsertDailyStats(ctx context.Context, s DailyStats) error
+	// ListDailyStats は全銘柄の行を返します（1 銘柄 1 行）。
+	ListDailyStats(ctx context.Context) ([]DailyStats, error)
+}
+
+// StatsRollup は銘柄の日足の要約統計を再計算し、ロールアップに保存します。
+type StatsRollup struct {
+	candles Repository
+	store   DailyStatsStore
+	now     func() time.Time
+}
+
+// NewStatsRollup は StatsRollup の新しいインスタンスを生成します。candles は日足の読み取りに使います。
+func NewStatsRollup(candles Repository, store DailyStatsStore) *StatsRollup {
+	return &StatsRollup{candles: candles, store: store, now: time.Now}
+}
+
+// Recompute は銘柄 code の日足を FindEach で走査して要約統計を再計算し、ロールアップに保存します。
+// 日足が 1 件もない場合は何もしません。
+func (r *StatsRollup) Recompute(ctx context.Context, code string) error {
+	s, ok, err := r.compute(ctx, code)
+	if err != nil || !ok {
+		return err
+	}
+	return r.store.UpsertDailyStats(ctx, s)
+}
+
+// compute は銘柄 code の日足（最大 MaxOutputSize 件）から要約統計を計算します。日足がない場合は ok=false を返します。
+// candles が EachFinder を実装していれば時間の降順に 1 件ずつ集計し、全件をスライスに載せません
+// （最初の足が期間の基準になる最新の足です）。実装しない場合は Find の結果を ComputeStats で集計します。
+func (r *StatsRollup) compute(ctx context.Context, code string) (DailyStats, bool, error) {
+	f, ok := r.candles.(EachFinder)
+	if !ok {
+		cs, err := r.candles.Find(ctx, code, DefaultInterval, MaxOutputSize)
+		if err != nil {
+			return DailyStats{}, false, err
+		}
+		s, ok := ComputeStats(cs)
+		if !ok {
+			return DailyStats{}, false, nil
+		}
+		return newDailyStats(code, s, r.now()), true, nil
+	}
+
+	var acc *statsAccumulator
+	err := f.FindEach(ctx, FindOptions{Symbol: code, Interval: DefaultInterval, Limit: MaxOutputSize}, func(c Candle) error {
+		if acc == nil {
+			acc = newStatsAccumulator(c)
+		}
+		acc.add(c)
+		return nil
+	})
+	if err != nil {
+		return DailyStats{}, false, err
+	}
+	if acc == nil {
+		return DailyStats{}, false, nil
+	}
+	return newDailyStats(code, acc.result(), r.now()), true, nil
+}
+
+// DailyStatsResult は複数銘柄の要約統計です。
+// Stats は指定順（重複と日足のない銘柄は除く）で、Unknown は正規コードに解決できなかった入力、
+// Locked は ctx のプランでは参照できない銘柄（正規コード）です。
+type DailyStatsResult struct {
+	Stats   []DailyStats
+	Unknown []string
+	Locked  []string
+}
+
+// DailyStatsUsecase は複数銘柄の要約統計をロールアップから返すユースケースです（管理画面のダッシュボード向け）。
+type DailyStatsUsecase struct {
+	rollup   *StatsRollup
+	resolver *SymbolResolver
+	tiers    SymbolTierSource
+	maxAge   time.Duration
+}
+
+// NewDailyStatsUsecase は DailyStatsUsecase の新しいインスタンスを生成します。
+// 銘柄コードは DefaultSymbolRules で正規コードに解決してから参照します。
+func NewDailyStatsUsecase(rollup *StatsRollup, symbols ActiveSymbolChecker) *DailyStatsUsecase {
+	return &DailyStatsUsecase{
+		rollup:   rollup,
+		resolver: NewSymbolResolver(symbols, DefaultSymbolRules),
+		maxAge:   DefaultDailyStatsMaxAge,
+	}
+}
+
+// WithMaxAge はロールアップの行をそのまま返す経過時間の上限を設定します。0 以下なら DefaultDailyStatsMaxAge を使います。
+func (u *DailyStatsUsecase) WithMaxAge(d time.Duration) *DailyStatsUsecase {
+	if d <= 0 {
+		d = DefaultDailyStatsMaxAge
+	}
+	u.maxAge = d
+	return u
+}
+
+// WithTiers は銘柄の区分の取得元を設定します。設定すると、ctx のプラン（WithPlan）で参照できない銘柄は
+// 統計を返さず Locked に入れます。未設定の場合は制限しません。
+func (u *DailyStatsUsecase) WithTiers(src SymbolTierSource) *DailyStatsUsecase {
+	u.tiers = src
+	return u
+}
+
+// GetDailyStats は codes の各銘柄の要約統計を返します。
+// ロールアップは 1 回の読み取りでまとめて取得し、行がない・再計算から maxAge を超えた銘柄だけ日足から計算します。
+// 計算した値はロールアップに書き戻します（失敗しても結果は返します）。
+// ctx のプランで参照できない銘柄はエラーにせず Locked に入れます（他の銘柄の統計は返します）。
+func (u *DailyStatsUsecase) GetDailyStats(ctx context.Context, codes []string) (DailyStatsResult, error) {
+	rows, err := u.rollup.store.ListDailyStats(ctx)
+	if err != nil {
+		return DailyStatsResult{}, err
+	}
+	byCode := make(map[string]DailyStats, len(rows))
+	for _, s := range rows {
+		byCode[s.SymbolCode] = s
+	}
+
+	now := u.rollup.now()
+	out := DailyStatsResult{Stats: []DailyStats{}, Unknown: []string{}, Locked: []string{}}
+	seen := make(map[string]struct{}, len(codes))
+	for _, code := range codes {
+		resolved, err := u.resolver.Resolve(ctx, code)
+		if errors.Is(err, ErrSymbolNotFound) || errors.Is(err, ErrAmbiguousSymbol) {
+			out.Unknown = append(out.Unknown, code)
+			continue
+		}
+		if err != nil {
+			return DailyStatsResult{}, err
+		}
+		if _, dup := seen[resolved]; dup {
+			continue
+		}
+		seen[resolved] = struct{}{}
+
+		err = checkTier(ctx, u.tiers, resolved)
+		if errors.Is(err, ErrUpgradeRequired) {
+			out.Locked = append(out.Locked, resolved)
+			continue
+		}
+		if err != nil {
+			return DailyStatsResult{}, err
+		}
+
+		if s, ok := byCode[resolved]; ok && now.Sub(s.ComputedAt) <= u.maxAge {
+			out.Stats = append(out.Stats, s)
+			continue
+		}
+		s, ok, err := u.rollup.compute(ctx, resolved)
+		if err != nil {
+			return DailyStatsResult{}, err
+		}
+		if !ok {
+			continue
+		}
+		if err := u.rollup.store.UpsertDailyStats(ctx, s); err != nil {
+			slog.WarnContext(ctx, "failed to write back daily stats", "symbol", resolved, "error", err)
+		}
+		out.Stats = append(out.Stats, s)
+	}
+	return out, nil
+}
@@ -0,0 +1,62 @@
+package candles
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/sqlc"
+)
+
+// dailyStatsRepository は DailyStatsStore の sqlc 実装です。
+type dailyStatsRepository struct {
+	q *candlessqlc.Queries
+}
+
+var _ DailyStatsStore = (*dailyStatsRepository)(nil)
+
+// NewDailyStatsRepository は指定された *sql.DB で dailyStatsRepository の新しいインスタンスを生成します。
+func NewDailyStatsRepository(db *sql.DB) *dailyStatsRepository {
+	return &dailyStatsRepository{q: candlessqlc.New(db)}
+}
+
+// UpsertDailyStats は銘柄の要約統計の行を上書きします。
+func (r *dailyStatsRepository) UpsertDailyStats(ctx context.Context, s DailyStats) error {
+	var ytd sql.NullFloat64
+	if s.YTDChangePercent != nil {
+		ytd = sql.NullFloat64{Float64: *s.YTDChangePercent, Valid: true}
+	}
+	return r.q.UpsertDailyStats(ctx, candlessqlc.UpsertDailyStatsParams{
+		SymbolCode:   s.SymbolCode,
+		AsOf:         s.AsOf,
+		High52w:      s.High52W,
+		Low52w:       s.Low52W,
+		AvgVol30d:    s.AvgVolume30D,
+		YtdChangePct: ytd,
+		ComputedAt:   s.ComputedAt,
+	})
+}
+
+// ListDailyStats は全銘柄の要約統計を銘柄コード順で返します。
+func (r *dailyStatsRepository) ListDailyStats(ctx context.Context) ([]DailyStats, error) {
+	rows, err := r.q.ListDailyStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]DailyStats, 0, len(rows))
+	for _, row := range rows {
+		s := DailyStats{
+			SymbolCode:   row.SymbolCode,
+			AsOf:         row.AsOf,
+			High52W:      row.High52w,
+			Low52W:       row.Low52w,
+			AvgVolume30D: row.AvgVol30d,
+			ComputedAt:   row.ComputedAt,
+		}
+		if row.YtdChangePct.Valid {
+			v := row.YtdChangePct.Float64
+			s.YTDChangePercent = &v
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
@@ -0,0 +1,88 @@
+package candles
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDailyStatsRepository_UpsertAndList(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewDailyStatsRepository(db)
+	ctx := context.Background()
+
+	asOf := time.Date(2024, 5, 14, 0, 0, 0, 0, time.UTC)
+	computed := time.Date(2024, 5, 15, 6, 0, 0, 0, time.UTC)
+	ytd := 12.5
+	require.NoError(t, repo.UpsertDailyStats(ctx, DailyStats{
+		SymbolCode: "GOOGL", AsOf: asOf, High52W: 180.25, Low52W: 120.5,
+		AvgVolume30D: 1500.5, YTDChangePercent: &ytd, ComputedAt: computed,
+	}))
+	require.NoError(t, repo.UpsertDailyStats(ctx, DailyStats{
+		SymbolCode: "AAPL", AsOf: asOf, High52W: 200, Low52W: 150,
+		AvgVolume30D: 1000, ComputedAt: computed,
+	}))
+
+	// 同じ銘柄への再書き込みは上書きする
+	later := computed.Add(24 * time.Hour)
+	require.NoError(t, repo.UpsertDailyStats(ctx, DailyStats{
+		SymbolCode: "AAPL", AsOf: asOf.Add(24 * time.Hour), High52W: 210, Low52W: 150,
+		AvgVolume30D: 1100, ComputedAt: later,
+	}))
+
+	got, err := repo.ListDailyStats(ctx)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+
+	aapl, googl := got[0], got[1]
+	assert.Equal(t, "AAPL", aapl.SymbolCode)
+	assert.Equal(t, 210.0, aapl.High52W)
+	assert.Equal(t, 1100.0, aapl.AvgVolume30D)
+	assert.Nil(t, aapl.YTDChangePercent)
+	assert.True(t, aapl.ComputedAt.Equal(later))
+
+	assert.Equal(t, "GOOGL", googl.SymbolCode)
+	assert.True(t, googl.AsOf.Equal(asOf))
+	assert.Equal(t, 180.25, googl.High52W)
+	assert.Equal(t, 120.5, googl.Low52W)
+	require.NotNil(t, googl.YTDChangePercent)
+	assert.Equal(t, ytd, *googl.YTDChangePercent)
+}
+
+// TestDailyStatsRepository_Recompute は保存済みの日足から再計算した値が、同じ日足を読んだ ComputeStats の値と一致することを検証します。
+func TestDailyStatsRepository_Recompute(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	candleRepo := NewRepository(db)
+	ctx := context.Background()
+
+	series := dailySeries(time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC), 300)
+	for i := range series {
+		series[i].SymbolCode = "AAPL"
+		series[i].Interval = DefaultInterval
+	}
+	_, err := candleRepo.UpsertBatch(ctx, series)
+	require.NoError(t, err)
+
+	store := NewDailyStatsRepository(db)
+	require.NoError(t, NewStatsRollup(candleRepo, store).Recompute(ctx, "AAPL"))
+
+	stored, err := candleRepo.Find(ctx, "AAPL", DefaultInterval, MaxOutputSize)
+	require.NoError(t, err)
+	want, ok := ComputeStats(stored)
+	require.True(t, ok)
+
+	got, err := store.ListDailyStats(ctx)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.True(t, got[0].AsOf.Equal(want.AsOf))
+	assert.Equal(t, want.High52W.Value, got[0].High52W)
+	assert.Equal(t, want.Low52W.Value, got[0].Low52W)
+	assert.InDelta(t, want.Volume30D.Average, got[0].AvgVolume30D, 1e-9)
+	require.NotNil(t, got[0].YTDChangePercent)
+	assert.InDelta(t, *want.YTDChangePercent, *got[0].YTDChangePercent, 1e-9)
+}
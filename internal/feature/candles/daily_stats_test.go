@@ -0,0 +1,303 @@
+package candles
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDailyStatsStore は DailyStatsStore のインメモリ実装です。
+type fakeDailyStatsStore struct {
+	rows      map[string]DailyStats
+	upserted  []string
+	upsertErr error
+}
+
+func newFakeDailyStatsStore(rows ...DailyStats) *fakeDailyStatsStore {
+	m := make(map[string]DailyStats, len(rows))
+	for _, s := range rows {
+		m[s.SymbolCode] = s
+	}
+	return &fakeDailyStatsStore{rows: m}
+}
+
+func (f *fakeDailyStatsStore) UpsertDailyStats(_ context.Context, s DailyStats) error {
+	f.upserted = append(f.upserted, s.SymbolCode)
+	if f.upsertErr != nil {
+		return f.upsertErr
+	}
+	f.rows[s.SymbolCode] = s
+	return nil
+}
+
+func (f *fakeDailyStatsStore) ListDailyStats(context.Context) ([]DailyStats, error) {
+	out := make([]DailyStats, 0, len(f.rows))
+	for _, s := range f.rows {
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// activeCodes は codes をアクティブ銘柄とみなす ActiveSymbolChecker です。
+type activeCodes map[string]bool
+
+func (a activeCodes) Contains(_ context.Context, code string) (bool, error) { return a[code], nil }
+
+// findCounter は銘柄ごとの Find の呼び出し回数を数え、series の日足を返す Repository です。
+type findCounter struct {
+	series map[string][]Candle
+	calls  map[string]int
+}
+
+func (f *findCounter) Find(_ context.Context, symbol, interval string, outputsize int) ([]Candle, error) {
+	if f.calls == nil {
+		f.calls = map[string]int{}
+	}
+	f.calls[symbol]++
+	if interval != DefaultInterval || outputsize != MaxOutputSize {
+		return nil, errors.New("unexpected find")
+	}
+	return f.series[symbol], nil
+}
+
+// TestStatsRollup_MatchesComputeStats はロールアップの値が同じ日足に対する ComputeStats（GET /candles/{code}/stats）と一致し、
+// 行のない銘柄を都度計算した値ともロールアップした値とも一致することを検証します。
+func TestStatsRollup_MatchesComputeStats(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, 5, 15, 6, 0, 0, 0, time.UTC)
+	cs := dailySeries(mustDate(2023, 1, 1), 500)
+	repo := &findCounter{series: map[string][]Candle{"AAPL": cs}}
+
+	rolled := newFakeDailyStatsStore()
+	rollup := NewStatsRollup(repo, rolled)
+	rollup.now = func() time.Time { return now }
+	require.NoError(t, rollup.Recompute(context.Background(), "AAPL"))
+
+	s, ok := ComputeStats(cs)
+	require.True(t, ok)
+	got := rolled.rows["AAPL"]
+	assert.Equal(t, s.AsOf, got.AsOf)
+	assert.Equal(t, s.High52W.Value, got.High52W)
+	assert.Equal(t, s.Low52W.Value, got.Low52W)
+	assert.Equal(t, s.Volume30D.Average, got.AvgVolume30D)
+	assert.Equal(t, s.YTDChangePercent, got.YTDChangePercent)
+	assert.Equal(t, now, got.ComputedAt)
+
+	// 行がない場合の都度計算も同じ値を返す
+	empty := NewStatsRollup(repo, newFakeDailyStatsStore())
+	empty.now = rollup.now
+	res, err := NewDailyStatsUsecase(empty, activeCodes{"AAPL": true}).GetDailyStats(context.Background(), []string{"AAPL"})
+	require.NoError(t, err)
+	assert.Equal(t, []DailyStats{got}, res.Stats)
+}
+
+// eachFinder は series を時間の降順で batch 件ずつ渡す EachFinder です。Find は呼ばれたら失敗します。
+type eachFinder struct {
+	findCounter
+	batch   int
+	opts    []FindOptions
+	batches int
+}
+
+func (f *eachFinder) Find(context.Context, string, string, int) ([]Candle, error) {
+	return nil, errors.New("Find must not be called")
+}
+
+func (f *eachFinder) FindEach(ctx context.Context, opts FindOptions, fn func(Candle) error) error {
+	f.opts = append(f.opts, opts)
+	desc := slices.Clone(f.series[opts.Symbol])
+	slices.Reverse(desc)
+	if opts.Limit > 0 && len(desc) > opts.Limit {
+		desc = desc[:opts.Limit]
+	}
+	for chunk := range slices.Chunk(desc, f.batch) {
+		f.batches++
+		for _, c := range chunk {
+			if err := fn(c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// TestStatsRollup_Recompute_FindEach は EachFinder を実装するリポジトリでは日足を FindEach で走査し、
+// ComputeStats と同じ値を計算することを検証します。
+func TestStatsRollup_Recompute_FindEach(t *testing.T) {
+	t.Parallel()
+	cs := dailySeries(mustDate(2023, 1, 1), 500)
+	repo := &eachFinder{findCounter: findCounter{series: map[string][]Candle{"AAPL": cs}}, batch: 64}
+	store := newFakeDailyStatsStore()
+	require.NoError(t, NewStatsRollup(repo, store).Recompute(context.Background(), "AAPL"))
+
+	assert.Equal(t, []FindOptions{{Symbol: "AAPL", Interval: DefaultInterval, Limit: MaxOutputSize}}, repo.opts)
+	assert.Equal(t, 8, repo.batches)
+
+	s, ok := ComputeStats(cs)
+	require.True(t, ok)
+	got := store.rows["AAPL"]
+	assert.Equal(t, s.AsOf, got.AsOf)
+	assert.Equal(t, s.High52W.Value, got.High52W)
+	assert.Equal(t, s.Low52W.Value, got.Low52W)
+	assert.Equal(t, s.Volume30D.Average, got.AvgVolume30D)
+	assert.Equal(t, *s.YTDChangePercent, *got.YTDChangePercent)
+
+	// 日足がない場合は行を作らない
+	require.NoError(t, NewStatsRollup(repo, store).Recompute(context.Background(), "MSFT"))
+	assert.Equal(t, []string{"AAPL"}, store.upserted)
+}
+
+// TestStatsRollup_Recompute_NoCandles は日足のない銘柄の行を作らないことを検証します。
+func TestStatsRollup_Recompute_NoCandles(t *testing.T) {
+	t.Parallel()
+	store := newFakeDailyStatsStore()
+	rollup := NewStatsRollup(&findCounter{}, store)
+	require.NoError(t, rollup.Recompute(context.Background(), "AAPL"))
+	assert.Empty(t, store.upserted)
+}
+
+// TestDailyStatsUsecase_GetDailyStats は新しい行はそのまま返し、行がない・古い銘柄だけ都度計算して書き戻すこと、
+// 結果が指定順（重複を除く）で解決できない銘柄を Unknown に入れることを検証します。
+func TestDailyStatsUsecase_GetDailyStats(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, 5, 15, 6, 0, 0, 0, time.UTC)
+	series := dailySeries(mustDate(2024, 1, 1), 100)
+	repo := &findCounter{series: map[string][]Candle{"AAPL": series, "MSFT": series, "7203.T": series}}
+	fresh := DailyStats{SymbolCode: "AAPL", High52W: 1, ComputedAt: now.Add(-time.Hour)}
+	stale := DailyStats{SymbolCode: "MSFT", High52W: 1, ComputedAt: now.Add(-DefaultDailyStatsMaxAge - time.Minute)}
+	store := newFakeDailyStatsStore(fresh, stale)
+
+	rollup := NewStatsRollup(repo, store)
+	rollup.now = func() time.Time { return now }
+	uc := NewDailyStatsUsecase(rollup, activeCodes{"AAPL": true, "MSFT": true, "7203.T": true, "EMPTY": true})
+
+	res, err := uc.GetDailyStats(context.Background(), []string{"7203", "AAPL", "ZZZZ", "msft", "EMPTY", "7203.T"})
+	require.NoError(t, err)
+
+	codes := make([]string, 0, len(res.Stats))
+	for _, s := range res.Stats {
+		codes = append(codes, s.SymbolCode)
+	}
+	assert.Equal(t, []string{"7203.T", "AAPL", "MSFT"}, codes, "指定順・重複と日足のない銘柄は除く")
+	assert.Equal(t, []string{"ZZZZ"}, res.Unknown)
+
+	assert.Equal(t, fresh, res.Stats[1], "新しい行はそのまま返す")
+	assert.Zero(t, repo.calls["AAPL"], "新しい行の銘柄は日足を読まない")
+	assert.Equal(t, 1, repo.calls["MSFT"], "古い行は都度計算する")
+	assert.Equal(t, 1, repo.calls["7203.T"], "行がない銘柄は都度計算する")
+	assert.Equal(t, now, res.Stats[2].ComputedAt)
+	assert.ElementsMatch(t, []string{"7203.T", "MSFT"}, store.upserted, "都度計算した値を書き戻す")
+}
+
+// TestDailyStatsUsecase_GetDailyStats_WriteBackFailure は書き戻しに失敗しても計算した値を返すことを検証します。
+func TestDailyStatsUsecase_GetDailyStats_WriteBackFailure(t *testing.T) {
+	t.Parallel()
+	repo := &findCounter{series: map[string][]Candle{"AAPL": dailySeries(mustDate(2024, 1, 1), 10)}}
+	store := newFakeDailyStatsStore()
+	store.upsertErr = errors.New("db down")
+
+	res, err := NewDailyStatsUsecase(NewStatsRollup(repo, store), activeCodes{"AAPL": true}).
+		GetDailyStats(context.Background(), []string{"AAPL"})
+	require.NoError(t, err)
+	require.Len(t, res.Stats, 1)
+	assert.Equal(t, "AAPL", res.Stats[0].SymbolCode)
+}
+
+// premiumTiers は指定した銘柄だけを premium とする SymbolTierSource のスタブです。
+type premiumTiers map[string]bool
+
+func (p premiumTiers) SymbolTier(_ context.Context, code string) (Tier, error) {
+	if p[code] {
+		return TierPremium, nil
+	}
+	return TierBasic, nil
+}
+
+// TestDailyStatsUsecase_GetDailyStats_Locked は free のプランでは premium の銘柄の統計を返さず Locked に入れ、
+// 他の銘柄の統計は返すこと、premium のプランとプランのない ctx では制限しないことを検証します。
+func TestDailyStatsUsecase_GetDailyStats_Locked(t *testing.T) {
+	t.Parallel()
+	series := dailySeries(mustDate(2024, 1, 1), 10)
+	repo := &findCounter{series: map[string][]Candle{"AAPL": series, "NVDA": series}}
+	uc := NewDailyStatsUsecase(NewStatsRollup(repo, newFakeDailyStatsStore()), activeCodes{"AAPL": true, "NVDA": true}).
+		WithTiers(premiumTiers{"NVDA": true})
+
+	symbols := func(res DailyStatsResult) []string {
+		out := make([]string, 0, len(res.Stats))
+		for _, s := range res.Stats {
+			out = append(out, s.SymbolCode)
+		}
+		return out
+	}
+
+	res, err := uc.GetDailyStats(WithPlan(context.Background(), PlanFree), []string{"NVDA", "AAPL"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AAPL"}, symbols(res))
+	assert.Equal(t, []string{"NVDA"}, res.Locked)
+	assert.Zero(t, repo.calls["NVDA"], "参照できない銘柄は日足を読まない")
+
+	res, err = uc.GetDailyStats(WithPlan(context.Background(), PlanPremium), []string{"NVDA", "AAPL"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"NVDA", "AAPL"}, symbols(res))
+	assert.Empty(t, res.Locked)
+
+	res, err = uc.GetDailyStats(context.Background(), []string{"NVDA"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"NVDA"}, symbols(res))
+	assert.Empty(t, res.Locked)
+}
+
+// recomputeRecorder は StatsRecomputer の呼び出しを記録します。
+type recomputeRecorder struct {
+	codes []string
+	err   error
+}
+
+func (r *recomputeRecorder) Recompute(_ context.Context, code string) error {
+	r.codes = append(r.codes, code)
+	return r.err
+}
+
+// changedBySymbol は銘柄ごとに Changed を返す WriteRepository です（AAPL のみ値が変わった想定）。
+type changedBySymbol map[string]int64
+
+func (c changedBySymbol) UpsertBatch(_ context.Context, cs []Candle) (UpsertStats, error) {
+	return UpsertStats{Updated: int64(len(cs)), Changed: c[cs[0].SymbolCode]}, nil
+}
+
+// TestIngestUsecase_WithStatsRollup は保存で値の変わった銘柄だけ要約統計を再計算し、
+// 再計算の失敗では取り込みを失敗にしないことを検証します。
+func TestIngestUsecase_WithStatsRollup(t *testing.T) {
+	t.Parallel()
+	market := &mockMarketRepository{GetTimeSeriesFunc: func(ctx context.Context, symbol, interval string, outputsize int, loc *time.Location) ([]Candle, error) {
+		return dailySeries(mustDate(2024, 1, 1), 5), nil
+	}}
+	symbols := &mockSymbolRepository{ListActiveSymbolsFunc: func(ctx context.Context) ([]ActiveSymbol, error) {
+		return activeSymbolsFromCodes([]string{"AAPL", "MSFT"}), nil
+	}}
+
+	for _, tc := range []struct {
+		name string
+		err  error
+	}{
+		{name: "値の変わった銘柄のみ再計算"},
+		{name: "再計算の失敗は取り込みを失敗にしない", err: errors.New("db down")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			rec := &recomputeRecorder{err: tc.err}
+			uc := NewIngestUsecase(market, changedBySymbol{"AAPL": 3}, symbols, &mockRateLimiter{}, &mockFreshnessWriter{}).
+				WithStatsRollup(rec)
+
+			result, err := uc.IngestAll(context.Background(), IngestOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, 2, result.Succeeded)
+			assert.Equal(t, []string{"AAPL"}, rec.codes)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package candles
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// dateFieldLayout はキャッシュのフィールドに期間の端を書くときの日付の形式です。
+const dateFieldLayout = "2006-01-02"
+
+// DateRange は期間指定の読み取り（?start= / ?end=）の日付の範囲です。
+// 端は UTC の 0 時の日付で、両端の日を含みます（End の日の足も返します）。ゼロ値の端は制限しません。
+type DateRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// IsZero は両端とも指定されていない（期間を制限しない）かを返します。
+func (r DateRange) IsZero() bool {
+	return r.Start.IsZero() && r.End.IsZero()
+}
+
+// Validate は開始日が終了日より後の場合に ErrInvalidRange を返します。
+func (r DateRange) Validate() error {
+	if !r.Start.IsZero() && !r.End.IsZero() && r.Start.After(r.End) {
+		return fmt.Errorf("%w: start must not be after end", ErrInvalidRange)
+	}
+	return nil
+}
+
+// Contains は時刻 t の足が範囲に含まれるかを返します。
+func (r DateRange) Contains(t time.Time) bool {
+	if !r.Start.IsZero() && t.Before(r.Start) {
+		return false
+	}
+	return r.End.IsZero() || t.Before(r.endBefore())
+}
+
+// endBefore は範囲に含まれない最初の時刻（終了日の翌日の 0 時）を返します。
+func (r DateRange) endBefore() time.Time {
+	return r.End.AddDate(0, 0, 1)
+}
+
+// cacheField は期間指定の結果を保存するハッシュのフィールド（開始日:終了日:件数。指定のない端は空）を返します。
+func (r DateRange) cacheField(outputsize int) string {
+	format := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format(dateFieldLayout)
+	}
+	return fmt.Sprintf("%s:%s:%d", format(r.Start), format(r.End), outputsize)
+}
+
+// RangeFinder は期間指定の読み取りに対応するリポジトリが実装します。
+// 実装していないリポジトリでは Find で全件を読み、範囲外の足を除いて返します（findRange 参照）。
+type RangeFinder interface {
+	// FindRange は rng に含まれる足を時間の降順（同じ時間は id の降順）で最大 outputsize 件返します。
+	FindRange(ctx context.Context, symbol, interval string, rng DateRange, outputsize int) ([]Candle, error)
+}
+
+// findRange は repo が RangeFinder を実装していれば FindRange で、そうでなければ Find の結果を範囲で絞って返します。
+func findRange(ctx context.Context, repo Repository, symbol, interval string, rng DateRange, outputsize int) ([]Candle, error) {
+	if f, ok := repo.(RangeFinder); ok {
+		return f.FindRange(ctx, symbol, interval, rng, outputsize)
+	}
+	cs, err := repo.Find(ctx, symbol, interval, 0)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Candle, 0, len(cs))
+	for _, c := range cs {
+		if !rng.Contains(c.Time) {
+			continue
+		}
+		out = append(out, c)
+		if outputsize > 0 && len(out) == outputsize {
+			break
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,112 @@
+package candles
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/testsupport"
+)
+
+func TestDateRange_ValidateAndContains(t *testing.T) {
+	t.Parallel()
+
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	require.NoError(t, DateRange{}.Validate())
+	require.NoError(t, DateRange{Start: day(5), End: day(5)}.Validate(), "同じ日は 1 日分")
+	require.ErrorIs(t, DateRange{Start: day(6), End: day(5)}.Validate(), ErrInvalidRange)
+
+	rng := DateRange{Start: day(2), End: day(4)}
+	assert.False(t, rng.Contains(day(1)))
+	assert.True(t, rng.Contains(day(2)))
+	assert.True(t, rng.Contains(day(4).Add(23*time.Hour)), "終了日の足を含む")
+	assert.False(t, rng.Contains(day(5)))
+	assert.True(t, DateRange{Start: day(2)}.Contains(day(31)), "終了日の指定がなければ制限しない")
+	assert.True(t, DateRange{End: day(4)}.Contains(day(1)), "開始日の指定がなければ制限しない")
+
+	assert.Equal(t, "2024-01-02:2024-01-04:10", rng.cacheField(10))
+	assert.Equal(t, ":2024-01-04:0", DateRange{End: day(4)}.cacheField(0))
+}
+
+// dailyCandles は 2024-01-01 から n 日分の日足を新しい順で返します（close は日付の日）。
+func dailyCandles(n int) []Candle {
+	out := make([]Candle, 0, n)
+	for d := n; d >= 1; d-- {
+		out = append(out, Candle{SymbolCode: "AAPL", Interval: "1day", Time: time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC), Close: float64(d)})
+	}
+	return out
+}
+
+func closesOf(cs []Candle) []float64 {
+	out := make([]float64, 0, len(cs))
+	for _, c := range cs {
+		out = append(out, c.Close)
+	}
+	return out
+}
+
+// TestCachingRepository_FindRange は期間指定の結果を期間ごとのフィールドにキャッシュし、
+// 期間を指定しない Find のエントリと衝突せず、UpsertBatch で破棄することを検証します。
+func TestCachingRepository_FindRange(t *testing.T) {
+	t.Parallel()
+
+	mr, rdb := testsupport.NewMiniRedis(t)
+	var findCalls []int
+	inner := &mockReadWriteRepository{findFn: func(_ context.Context, _, _ string, outputsize int) ([]Candle, error) {
+		findCalls = append(findCalls, outputsize)
+		return dailyCandles(10), nil
+	}}
+	repo := NewCachingRepository(rdb, time.Hour, inner, "candles", nil)
+	ctx := context.Background()
+	rng := DateRange{Start: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), End: time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC)}
+
+	// RangeFinder を実装しない基盤リポジトリでは全件を読んで範囲で絞る
+	got, err := repo.FindRange(ctx, "AAPL", "1day", rng, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{6, 5, 4}, closesOf(got))
+	assert.Equal(t, []int{0}, findCalls)
+	fields, err := mr.HKeys("candles:AAPL:1day:range")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2024-01-03:2024-01-06:3"}, fields, "期間と件数をフィールドにする")
+
+	got, err = repo.FindRange(ctx, "AAPL", "1day", rng, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{6, 5, 4}, closesOf(got))
+	assert.Len(t, findCalls, 1, "同じ期間はキャッシュから返す")
+
+	// 期間を指定しない読み取りは別のエントリを使う
+	got, err = repo.Find(ctx, "AAPL", "1day", 3)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{10, 9, 8}, closesOf(got))
+	assert.Len(t, findCalls, 2)
+
+	_, err = repo.UpsertBatch(ctx, []Candle{{SymbolCode: "AAPL", Interval: "1day", Time: time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC)}})
+	require.NoError(t, err)
+	assert.False(t, mr.Exists("candles:AAPL:1day:range"), "新しい足の保存で破棄する")
+}
+
+// TestCachingRepository_FindRange_FieldCap は期間の組み合わせごとのフィールドが MaxRangeCacheFields を超えて増えないことを検証します。
+func TestCachingRepository_FindRange_FieldCap(t *testing.T) {
+	t.Parallel()
+
+	mr, rdb := testsupport.NewMiniRedis(t)
+	inner := &mockReadWriteRepository{findFn: func(context.Context, string, string, int) ([]Candle, error) {
+		return dailyCandles(10), nil
+	}}
+	repo := NewCachingRepository(rdb, time.Hour, inner, "candles", nil)
+	ctx := context.Background()
+	rng := DateRange{Start: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)}
+
+	for n := 1; n <= MaxRangeCacheFields+10; n++ {
+		got, err := repo.FindRange(ctx, "AAPL", "1day", rng, n)
+		require.NoError(t, err)
+		assert.NotEmpty(t, got, "上限に達しても結果は返す")
+	}
+	fields, err := mr.HKeys("candles:AAPL:1day:range")
+	require.NoError(t, err)
+	assert.Len(t, fields, MaxRangeCacheFields)
+}
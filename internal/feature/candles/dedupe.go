@@ -0,0 +1,144 @@
+package candles
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// MaxReportedDuplicateGroups は重複解消の結果に含める重複グループの最大数です。
+// 件数（GroupsAffected / RowsDeleted）は上限を超えた分も数えます。
+const MaxReportedDuplicateGroups = 1000
+
+// DuplicateGroup は (銘柄, 時間間隔, 時刻) が重複したローソク足の組です。
+// UNIQUE インデックス導入前に書き込まれた行で、最大 ID の行（最後の書き込み）を残して他を削除します。
+type DuplicateGroup struct {
+	SymbolCode string
+	Interval   string
+	Time       time.Time
+	KeptID     int64
+	DeletedIDs []int64
+}
+
+// DedupeResult は重複解消の結果です。DryRun の場合は削除予定の行を数え、データは変更しません。
+type DedupeResult struct {
+	DryRun         bool
+	Symbols        int              // 重複を確認した銘柄数
+	GroupsAffected int              // 重複していた (銘柄, 時間間隔, 時刻) の数
+	RowsDeleted    int              // 削除した（DryRun では削除予定の）行数
+	Groups         []DuplicateGroup // 最大 MaxReportedDuplicateGroups 件
+	Truncated      bool             // Groups が上限で打ち切られた場合 true
+}
+
+// DuplicateRepository は重複したローソク足の検出・削除を抽象化します。
+// Goの慣例に従い、インターフェースは利用者（usecase）側で定義します。
+type DuplicateRepository interface {
+	// CountDuplicateGroups はテーブル全体の重複グループ数を返します。
+	CountDuplicateGroups(ctx context.Context) (int64, error)
+	// ListDuplicateSymbols は重複がある銘柄コードを昇順で返します。
+	ListDuplicateSymbols(ctx context.Context) ([]string, error)
+	// FindDuplicates は銘柄 symbol の重複グループを返します（データは変更しない）。
+	FindDuplicates(ctx context.Context, symbol string) ([]DuplicateGroup, error)
+	// DeleteDuplicates は銘柄 symbol の重複を 1 トランザクションで解消し、解消したグループを返します。
+	DeleteDuplicates(ctx context.Context, symbol string) ([]DuplicateGroup, error)
+}
+
+// CacheInvalidator は銘柄・時間間隔のローソク足キャッシュを破棄します（CachingRepository が実装）。
+type CacheInvalidator interface {
+	Invalidate(ctx context.Context, symbol, interval string) error
+}
+
+// DedupeUsecase は重複したローソク足を管理者が解消するためのユースケースです。
+type DedupeUsecase struct {
+	repo  DuplicateRepository
+	cache CacheInvalidator
+}
+
+// NewDedupeUsecase は DedupeUsecase の新しいインスタンスを生成します。cache が nil の場合はキャッシュを破棄しません。
+func NewDedupeUsecase(repo DuplicateRepository, cache CacheInvalidator) *DedupeUsecase {
+	return &DedupeUsecase{repo: repo, cache: cache}
+}
+
+// Dedupe は銘柄 symbol（空の場合はテーブル全体）の重複を解消します。dryRun の場合は報告のみ行います。
+// テーブル全体の場合は銘柄ごとに別のトランザクションで処理し、長時間のロックを避けます。
+// 途中で失敗した場合、それまでの銘柄の削除は確定したまま、その時点の結果とエラーを返します。
+// 削除した銘柄・時間間隔のキャッシュは破棄します（ベストエフォート）。
+func (u *DedupeUsecase) Dedupe(ctx context.Context, symbol string, dryRun bool) (DedupeResult, error) {
+	symbols := []string{symbol}
+	if symbol == "" {
+		var err error
+		if symbols, err = u.repo.ListDuplicateSymbols(ctx); err != nil {
+			return DedupeResult{DryRun: dryRun}, fmt.Errorf("list duplicate symbols: %w", err)
+		}
+	}
+
+	result := DedupeResult{DryRun: dryRun}
+	for _, s := range symbols {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		var (
+			groups []DuplicateGroup
+			err    error
+		)
+		if dryRun {
+			groups, err = u.repo.FindDuplicates(ctx, s)
+		} else {
+			groups, err = u.repo.DeleteDuplicates(ctx, s)
+		}
+		if err != nil {
+			return result, fmt.Errorf("dedupe %s: %w", s, err)
+		}
+		result.Symbols++
+		result.add(groups)
+		if !dryRun && len(groups) > 0 {
+			u.invalidate(ctx, s, groups)
+			slog.Info("deleted duplicate candles", "symbol", s, "groups", len(groups))
+		}
+	}
+	return result, nil
+}
+
+// add は groups を結果に加えます。Groups は MaxReportedDuplicateGroups 件までに制限します。
+func (r *DedupeResult) add(groups []DuplicateGroup) {
+	for _, g := range groups {
+		r.GroupsAffected++
+		r.RowsDeleted += len(g.DeletedIDs)
+		if len(r.Groups) < MaxReportedDuplicateGroups {
+			r.Groups = append(r.Groups, g)
+		} else {
+			r.Truncated = true
+		}
+	}
+}
+
+// invalidate は groups に含まれる時間間隔ごとに symbol のキャッシュを破棄します。
+func (u *DedupeUsecase) invalidate(ctx context.Context, symbol string, groups []DuplicateGroup) {
+	if u.cache == nil {
+		return
+	}
+	seen := map[string]struct{}{}
+	for _, g := range groups {
+		if _, ok := seen[g.Interval]; ok {
+			continue
+		}
+		seen[g.Interval] = struct{}{}
+		if err := u.cache.Invalidate(ctx, symbol, g.Interval); err != nil {
+			slog.Warn("failed to invalidate candle cache after dedupe", "symbol", symbol, "interval", g.Interval, "error", err)
+		}
+	}
+}
+
+// WarnDuplicates はテーブル全体の重複グループ数を数え、重複があれば警告ログを出します。
+// 起動時の確認用で、失敗してもエラーは返さずログに記録するだけです。
+func (u *DedupeUsecase) WarnDuplicates(ctx context.Context) {
+	n, err := u.repo.CountDuplicateGroups(ctx)
+	if err != nil {
+		slog.Warn("failed to count duplicate candles", "error", err)
+		return
+	}
+	if n > 0 {
+		slog.Warn("duplicate candles found; run POST /v1/admin/candles/dedupe to repair", "groups", n)
+	}
+}
@@ -0,0 +1,91 @@
+package candles
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/sqlc"
+)
+
+var _ DuplicateRepository = (*dbRepository)(nil)
+
+// CountDuplicateGroups はテーブル全体の重複グループ数を返します。
+func (r *dbRepository) CountDuplicateGroups(ctx context.Context) (int64, error) {
+	return r.q.CountDuplicateCandleGroups(ctx)
+}
+
+// ListDuplicateSymbols は重複がある銘柄コードを昇順で返します。
+func (r *dbRepository) ListDuplicateSymbols(ctx context.Context) ([]string, error) {
+	return r.q.ListDuplicateCandleSymbols(ctx)
+}
+
+// FindDuplicates は銘柄 symbol の重複グループを返します。データは変更しません。
+func (r *dbRepository) FindDuplicates(ctx context.Context, symbol string) ([]DuplicateGroup, error) {
+	rows, err := r.q.ListDuplicateCandles(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return duplicateGroups(symbol, rows), nil
+}
+
+// DeleteDuplicates は銘柄 symbol の重複グループをロックして、最大 ID 以外の行を削除します（トランザクション内で実行）。
+func (r *dbRepository) DeleteDuplicates(ctx context.Context, symbol string) ([]DuplicateGroup, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback()
+		}
+	}()
+
+	qtx := r.q.WithTx(tx)
+	rows, err := qtx.ListDuplicateCandles(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	groups := duplicateGroups(symbol, rows)
+	if len(groups) == 0 {
+		return nil, nil
+	}
+	deleted, err := qtx.DeleteDuplicateCandles(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if want := countDeleted(groups); deleted != int64(want) {
+		// ロック済みの行以外は消さないはずなので、一致しない場合は取り消す
+		return nil, fmt.Errorf("deleted %d rows, expected %d", deleted, want)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+	committed = true
+	return groups, nil
+}
+
+// duplicateGroups は (時間間隔, 時刻, ID) 順の行をグループにまとめます。各グループの最後の行（最大 ID）を残す行とします。
+func duplicateGroups(symbol string, rows []candlessqlc.ListDuplicateCandlesRow) []DuplicateGroup {
+	var out []DuplicateGroup
+	for i, row := range rows {
+		if i == 0 || row.Interval != rows[i-1].Interval || !row.Time.Equal(rows[i-1].Time) {
+			out = append(out, DuplicateGroup{SymbolCode: symbol, Interval: row.Interval, Time: row.Time})
+		}
+		g := &out[len(out)-1]
+		if g.KeptID != 0 {
+			g.DeletedIDs = append(g.DeletedIDs, g.KeptID)
+		}
+		g.KeptID = row.ID
+	}
+	return out
+}
+
+func countDeleted(groups []DuplicateGroup) int {
+	n := 0
+	for _, g := range groups {
+		n += len(g.DeletedIDs)
+	}
+	return n
+}
@@ -0,0 +1,139 @@
+package candles
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/testsupport"
+)
+
+// seedDuplicateCandles は UNIQUE インデックスを削除し、インデックス導入前の重複を再現します。
+// AAPL 1day の 2 組（3 行・2 行）と、重複のない AAPL 1h・GOOGL 1day を作成します。
+func seedDuplicateCandles(t *testing.T, db *sql.DB) (day1, day2 time.Time) {
+	t.Helper()
+	_, err := db.ExecContext(context.Background(), `DROP INDEX candle_sym_int_time`)
+	require.NoError(t, err)
+
+	day1 = time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	day2 = day1.AddDate(0, 0, 1)
+	for range 3 {
+		testsupport.InsertCandle(t, db, "AAPL", day1)
+	}
+	for range 2 {
+		testsupport.InsertCandle(t, db, "AAPL", day2)
+	}
+	testsupport.InsertCandle(t, db, "AAPL", day1, testsupport.WithInterval("1h"))
+	testsupport.InsertCandle(t, db, "GOOGL", day1)
+	return day1, day2
+}
+
+// candleIDs は symbol/interval/ts の行の ID を昇順で返します。
+func candleIDs(t *testing.T, db *sql.DB, symbol, interval string, ts time.Time) []int64 {
+	t.Helper()
+	rows, err := db.QueryContext(context.Background(),
+		`SELECT id FROM candles WHERE symbol_code = $1 AND "interval" = $2 AND "time" = $3 ORDER BY id`,
+		symbol, interval, ts)
+	require.NoError(t, err)
+	defer func() { _ = rows.Close() }()
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		require.NoError(t, rows.Scan(&id))
+		ids = append(ids, id)
+	}
+	require.NoError(t, rows.Err())
+	return ids
+}
+
+func TestDBRepository_Duplicates(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	day1, day2 := seedDuplicateCandles(t, db)
+	ids1 := candleIDs(t, db, "AAPL", "1day", day1)
+	ids2 := candleIDs(t, db, "AAPL", "1day", day2)
+	require.Len(t, ids1, 3)
+	require.Len(t, ids2, 2)
+
+	n, err := repo.CountDuplicateGroups(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	symbols, err := repo.ListDuplicateSymbols(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AAPL"}, symbols)
+
+	want := []DuplicateGroup{
+		{SymbolCode: "AAPL", Interval: "1day", Time: day1, KeptID: ids1[2], DeletedIDs: ids1[:2]},
+		{SymbolCode: "AAPL", Interval: "1day", Time: day2, KeptID: ids2[1], DeletedIDs: ids2[:1]},
+	}
+
+	// FindDuplicates は報告のみでデータを変更しない
+	before := candleCount(t, db)
+	found, err := repo.FindDuplicates(ctx, "AAPL")
+	require.NoError(t, err)
+	assertDuplicateGroups(t, want, found)
+	assert.Equal(t, before, candleCount(t, db))
+
+	deleted, err := repo.DeleteDuplicates(ctx, "AAPL")
+	require.NoError(t, err)
+	assertDuplicateGroups(t, want, deleted)
+	assert.Equal(t, before-3, candleCount(t, db))
+	assert.Equal(t, ids1[2:], candleIDs(t, db, "AAPL", "1day", day1), "the highest id is kept")
+	assert.Equal(t, ids2[1:], candleIDs(t, db, "AAPL", "1day", day2))
+
+	// 解消後は重複がなく、再実行しても何も削除しない
+	n, err = repo.CountDuplicateGroups(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	deleted, err = repo.DeleteDuplicates(ctx, "AAPL")
+	require.NoError(t, err)
+	assert.Empty(t, deleted)
+	assert.Equal(t, before-3, candleCount(t, db))
+
+	// 重複がなくなれば UNIQUE インデックスを作り直せる
+	_, err = db.ExecContext(ctx, `CREATE UNIQUE INDEX candle_sym_int_time ON candles (symbol_code, "interval", "time")`)
+	require.NoError(t, err)
+}
+
+func TestDBRepository_DedupeUsecase(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	uc := NewDedupeUsecase(NewRepository(db), nil)
+	ctx := context.Background()
+
+	seedDuplicateCandles(t, db)
+	before := candleCount(t, db)
+
+	dry, err := uc.Dedupe(ctx, "", true)
+	require.NoError(t, err)
+	assert.True(t, dry.DryRun)
+	assert.Equal(t, 1, dry.Symbols)
+	assert.Equal(t, 2, dry.GroupsAffected)
+	assert.Equal(t, 3, dry.RowsDeleted)
+	assert.Equal(t, before, candleCount(t, db), "dry run does not mutate")
+
+	res, err := uc.Dedupe(ctx, "", false)
+	require.NoError(t, err)
+	assert.False(t, res.DryRun)
+	assert.Equal(t, 3, res.RowsDeleted)
+	assert.Equal(t, before-3, candleCount(t, db))
+}
+
+// assertDuplicateGroups は時刻を time.Time.Equal で比較して重複グループを検証します。
+func assertDuplicateGroups(t *testing.T, want, got []DuplicateGroup) {
+	t.Helper()
+	require.Len(t, got, len(want))
+	for i := range want {
+		assert.True(t, want[i].Time.Equal(got[i].Time), "group %d time = %v, want %v", i, got[i].Time, want[i].Time)
+		got[i].Time = want[i].Time
+	}
+	assert.Equal(t, want, got)
+}
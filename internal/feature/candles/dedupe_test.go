@@ -0,0 +1,134 @@
+package candles
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubDuplicateRepository は DuplicateRepository のスタブです。銘柄ごとの重複グループを返し、削除した銘柄を記録します。
+type stubDuplicateRepository struct {
+	groups    map[string][]DuplicateGroup
+	symbols   []string
+	failOn    string
+	deletedOn []string
+}
+
+func (s *stubDuplicateRepository) CountDuplicateGroups(context.Context) (int64, error) {
+	n := 0
+	for _, g := range s.groups {
+		n += len(g)
+	}
+	return int64(n), nil
+}
+
+func (s *stubDuplicateRepository) ListDuplicateSymbols(context.Context) ([]string, error) {
+	return s.symbols, nil
+}
+
+func (s *stubDuplicateRepository) FindDuplicates(_ context.Context, symbol string) ([]DuplicateGroup, error) {
+	if symbol == s.failOn {
+		return nil, errors.New("boom")
+	}
+	return s.groups[symbol], nil
+}
+
+func (s *stubDuplicateRepository) DeleteDuplicates(_ context.Context, symbol string) ([]DuplicateGroup, error) {
+	if symbol == s.failOn {
+		return nil, errors.New("boom")
+	}
+	s.deletedOn = append(s.deletedOn, symbol)
+	return s.groups[symbol], nil
+}
+
+// recordingInvalidator は破棄したキャッシュを "symbol/interval" で記録します。
+type recordingInvalidator struct{ keys []string }
+
+func (r *recordingInvalidator) Invalidate(_ context.Context, symbol, interval string) error {
+	r.keys = append(r.keys, symbol+"/"+interval)
+	return nil
+}
+
+func newStubDuplicates() *stubDuplicateRepository {
+	day := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	return &stubDuplicateRepository{
+		symbols: []string{"AAPL", "GOOGL"},
+		groups: map[string][]DuplicateGroup{
+			"AAPL": {
+				{SymbolCode: "AAPL", Interval: "1day", Time: day, KeptID: 3, DeletedIDs: []int64{1, 2}},
+				{SymbolCode: "AAPL", Interval: "1day", Time: day.AddDate(0, 0, 1), KeptID: 5, DeletedIDs: []int64{4}},
+			},
+			"GOOGL": {
+				{SymbolCode: "GOOGL", Interval: "1h", Time: day, KeptID: 7, DeletedIDs: []int64{6}},
+			},
+		},
+	}
+}
+
+func TestDedupeUsecase_Dedupe(t *testing.T) {
+	t.Parallel()
+
+	t.Run("dry run reports without deleting", func(t *testing.T) {
+		t.Parallel()
+		repo, cache := newStubDuplicates(), &recordingInvalidator{}
+		res, err := NewDedupeUsecase(repo, cache).Dedupe(context.Background(), "", true)
+		require.NoError(t, err)
+
+		assert.Equal(t, DedupeResult{DryRun: true, Symbols: 2, GroupsAffected: 3, RowsDeleted: 4,
+			Groups: append(repo.groups["AAPL"], repo.groups["GOOGL"]...)}, res)
+		assert.Empty(t, repo.deletedOn)
+		assert.Empty(t, cache.keys)
+	})
+
+	t.Run("deletes per symbol and invalidates each interval once", func(t *testing.T) {
+		t.Parallel()
+		repo, cache := newStubDuplicates(), &recordingInvalidator{}
+		res, err := NewDedupeUsecase(repo, cache).Dedupe(context.Background(), "", false)
+		require.NoError(t, err)
+
+		assert.Equal(t, 4, res.RowsDeleted)
+		assert.Equal(t, []string{"AAPL", "GOOGL"}, repo.deletedOn)
+		assert.Equal(t, []string{"AAPL/1day", "GOOGL/1h"}, cache.keys)
+	})
+
+	t.Run("single symbol skips listing", func(t *testing.T) {
+		t.Parallel()
+		repo := newStubDuplicates()
+		res, err := NewDedupeUsecase(repo, nil).Dedupe(context.Background(), "GOOGL", false)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, res.Symbols)
+		assert.Equal(t, []string{"GOOGL"}, repo.deletedOn)
+	})
+
+	t.Run("failure keeps earlier symbols", func(t *testing.T) {
+		t.Parallel()
+		repo := newStubDuplicates()
+		repo.failOn = "GOOGL"
+		res, err := NewDedupeUsecase(repo, nil).Dedupe(context.Background(), "", false)
+		require.Error(t, err)
+
+		assert.Equal(t, 1, res.Symbols)
+		assert.Equal(t, 3, res.RowsDeleted)
+	})
+}
+
+func TestDedupeResult_TruncatesGroups(t *testing.T) {
+	t.Parallel()
+
+	groups := make([]DuplicateGroup, MaxReportedDuplicateGroups+1)
+	for i := range groups {
+		groups[i].DeletedIDs = []int64{int64(i)}
+	}
+	var res DedupeResult
+	res.add(groups)
+
+	assert.Len(t, res.Groups, MaxReportedDuplicateGroups)
+	assert.True(t, res.Truncated)
+	assert.Equal(t, MaxReportedDuplicateGroups+1, res.GroupsAffected)
+	assert.Equal(t, MaxReportedDuplicateGroups+1, res.RowsDeleted)
+}
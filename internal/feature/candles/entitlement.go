@@ -0,0 +1,73 @@
+package candles
+
+import (
+	"context"
+	"fmt"
+)
+
+// Plan は利用者の料金プランです。free は basic の銘柄だけ、premium はすべての銘柄を参照できます。
+type Plan string
+
+const (
+	// PlanFree は basic の銘柄だけを参照できるプランです（ユーザーの既定）。
+	PlanFree Plan = "free"
+	// PlanPremium はすべての銘柄を参照できるプランです。
+	PlanPremium Plan = "premium"
+)
+
+// Tier は銘柄の区分です（symbols.tier）。
+type Tier string
+
+const (
+	// TierBasic はすべてのプランで参照できる銘柄です。
+	TierBasic Tier = "basic"
+	// TierPremium は premium プランでだけ参照できる銘柄です。
+	TierPremium Tier = "premium"
+)
+
+// Allows はプラン p で区分 t の銘柄を参照できるかを返します。
+func (p Plan) Allows(t Tier) bool {
+	return t != TierPremium || p == PlanPremium
+}
+
+// SymbolTierSource は銘柄の区分の取得を抽象化します。
+// candles が symbollist feature に直接依存しないよう、ActiveSymbolChecker と同じく利用者側で定義します。
+type SymbolTierSource interface {
+	// SymbolTier は正規コード code の銘柄の区分を返します。
+	SymbolTier(ctx context.Context, code string) (Tier, error)
+}
+
+// planKey は context に利用者の料金プランを格納するキーです。
+type planKey struct{}
+
+// WithPlan はリクエストの利用者の料金プランを ctx に格納します（candleshttp.ResolvePlan が設定します）。
+// プランのない ctx（ジョブ等の内部の呼び出し）では銘柄の区分による制限を行いません。
+func WithPlan(ctx context.Context, p Plan) context.Context {
+	return context.WithValue(ctx, planKey{}, p)
+}
+
+// PlanFromContext は ctx の料金プランを返します。設定されていない場合は ok=false です。
+func PlanFromContext(ctx context.Context) (Plan, bool) {
+	p, ok := ctx.Value(planKey{}).(Plan)
+	return p, ok
+}
+
+// checkTier は ctx のプランで正規コード code の銘柄を参照できるかを確認し、できない場合は UpgradeRequiredError を返します。
+// tiers が nil、または ctx にプランがない場合は制限しません。
+func checkTier(ctx context.Context, tiers SymbolTierSource, code string) error {
+	if tiers == nil {
+		return nil
+	}
+	p, ok := PlanFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	t, err := tiers.SymbolTier(ctx, code)
+	if err != nil {
+		return fmt.Errorf("checking symbol tier: %w", err)
+	}
+	if !p.Allows(t) {
+		return &UpgradeRequiredError{Symbol: code}
+	}
+	return nil
+}
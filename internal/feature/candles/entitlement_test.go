@@ -0,0 +1,143 @@
+package candles_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+)
+
+// stubTiers は SymbolTierSource のスタブです。登録のない銘柄は basic として扱います。
+type stubTiers map[string]candles.Tier
+
+func (s stubTiers) SymbolTier(_ context.Context, code string) (candles.Tier, error) {
+	if t, ok := s[code]; ok {
+		return t, nil
+	}
+	return candles.TierBasic, nil
+}
+
+// readUsecase はプランによる制限の対象になる読み取りのメソッドです（candles.NewUsecase の戻り値が実装します）。
+type readUsecase interface {
+	GetCandles(ctx context.Context, symbol, interval string, outputsize int, adjust candles.AdjustMode) ([]candles.Candle, error)
+	GetCandlesAsOf(ctx context.Context, symbol, interval string, outputsize int, asOf time.Time) ([]candles.Candle, error)
+	GetStats(ctx context.Context, symbol, interval string, adjust candles.AdjustMode) (candles.Stats, error)
+	GetSparkline(ctx context.Context, symbol, interval string, outputsize, points int, adjust candles.AdjustMode) (candles.Sparkline, error)
+}
+
+// TestPlan_Allows はプランと銘柄の区分の組み合わせで参照できるかを検証します。
+func TestPlan_Allows(t *testing.T) {
+	tests := []struct {
+		plan candles.Plan
+		tier candles.Tier
+		want bool
+	}{
+		{candles.PlanFree, candles.TierBasic, true},
+		{candles.PlanFree, candles.TierPremium, false},
+		{candles.PlanPremium, candles.TierBasic, true},
+		{candles.PlanPremium, candles.TierPremium, true},
+		{candles.PlanFree, "", true}, // 区分の不明な銘柄は制限しない
+	}
+	for _, tt := range tests {
+		if got := tt.plan.Allows(tt.tier); got != tt.want {
+			t.Errorf("%s.Allows(%q) = %v, want %v", tt.plan, tt.tier, got, tt.want)
+		}
+	}
+}
+
+// TestCandlesUsecase_Entitlement はプラン × 銘柄の区分 × エンドポイントの組み合わせで、
+// 参照できない場合だけ UpgradeRequiredError を返し、リポジトリを読まないことを検証します。
+// プランのない ctx（ジョブ等の内部の呼び出し）は制限しません。
+func TestCandlesUsecase_Entitlement(t *testing.T) {
+	endpoints := []struct {
+		name string
+		call func(ctx context.Context, uc readUsecase, symbol string) error
+	}{
+		{"candles", func(ctx context.Context, uc readUsecase, symbol string) error {
+			_, err := uc.GetCandles(ctx, symbol, "1day", 10, candles.AdjustDefault)
+			return err
+		}},
+		{"candles_as_of", func(ctx context.Context, uc readUsecase, symbol string) error {
+			_, err := uc.GetCandlesAsOf(ctx, symbol, "1day", 10, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+			return err
+		}},
+		{"stats", func(ctx context.Context, uc readUsecase, symbol string) error {
+			_, err := uc.GetStats(ctx, symbol, "1day", candles.AdjustDefault)
+			return err
+		}},
+		{"sparkline", func(ctx context.Context, uc readUsecase, symbol string) error {
+			_, err := uc.GetSparkline(ctx, symbol, "1day", 10, 5, candles.AdjustDefault)
+			return err
+		}},
+	}
+	plans := []struct {
+		name    string
+		plan    candles.Plan
+		hasPlan bool
+	}{
+		{"free", candles.PlanFree, true},
+		{"premium", candles.PlanPremium, true},
+		{"no_plan", "", false},
+	}
+	tiers := []struct {
+		symbol string
+		tier   candles.Tier
+	}{
+		{"AAPL", candles.TierBasic},
+		{"NVDA", candles.TierPremium},
+	}
+
+	for _, ep := range endpoints {
+		for _, p := range plans {
+			for _, tr := range tiers {
+				t.Run(ep.name+"/"+p.name+"/"+string(tr.tier), func(t *testing.T) {
+					repo := &asOfRepository{}
+					repo.FindFunc = func(context.Context, string, string, int) ([]candles.Candle, error) {
+						return []candles.Candle{{Time: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Open: 1, High: 1, Low: 1, Close: 1, Volume: 1}}, nil
+					}
+					uc := candles.NewUsecase(repo, allActive("AAPL", "NVDA")).
+						WithTiers(stubTiers{"AAPL": candles.TierBasic, "NVDA": candles.TierPremium})
+
+					ctx := context.Background()
+					if p.hasPlan {
+						ctx = candles.WithPlan(ctx, p.plan)
+					}
+					err := ep.call(ctx, uc, tr.symbol)
+
+					wantLocked := p.hasPlan && p.plan == candles.PlanFree && tr.tier == candles.TierPremium
+					if !wantLocked {
+						if err != nil {
+							t.Fatalf("unexpected error: %v", err)
+						}
+						return
+					}
+					if !errors.Is(err, candles.ErrUpgradeRequired) {
+						t.Fatalf("err = %v, want ErrUpgradeRequired", err)
+					}
+					var ue *candles.UpgradeRequiredError
+					if !errors.As(err, &ue) || ue.Symbol != tr.symbol {
+						t.Errorf("err = %#v, want UpgradeRequiredError{Symbol: %q}", err, tr.symbol)
+					}
+					if repo.FindCalls != 0 || repo.gotSymbol != "" {
+						t.Errorf("repository was read for a locked symbol")
+					}
+				})
+			}
+		}
+	}
+}
+
+// TestCandlesUsecase_Entitlement_WithoutTiers は WithTiers を設定しない場合、プランがあっても制限しないことを検証します。
+func TestCandlesUsecase_Entitlement_WithoutTiers(t *testing.T) {
+	repo := &mockRepository{FindFunc: func(context.Context, string, string, int) ([]candles.Candle, error) {
+		return []candles.Candle{}, nil
+	}}
+	uc := candles.NewUsecase(repo, allActive("NVDA"))
+
+	ctx := candles.WithPlan(context.Background(), candles.PlanFree)
+	if _, err := uc.GetCandles(ctx, "NVDA", "1day", 10, candles.AdjustDefault); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
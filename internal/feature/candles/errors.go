@@ -0,0 +1,142 @@
+package candles
+
+import (
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
+)
+
+var (
+	// ErrSymbolNotFound は指定された銘柄コードが存在しないか、アクティブでない場合のエラーです。
+	// データ未取得の既知銘柄（0件）とタイプミス等の未知銘柄を区別するために使用します。
+	ErrSymbolNotFound = apperr.New(apperr.KindNotFound, "symbol_not_found", "symbol not found")
+
+	// ErrAmbiguousSymbol は入力された銘柄コードが正規化規則により複数のアクティブ銘柄に一致し、
+	// 1つに決められない場合のエラーです。推測で別銘柄を返さないよう、未知銘柄と同じく 404 とします。
+	ErrAmbiguousSymbol = apperr.New(apperr.KindNotFound, "symbol_ambiguous", "symbol is ambiguous")
+
+	// ErrNoCandles は既知の銘柄にローソク足データが1件もなく、統計を算出できない場合のエラーです。
+	ErrNoCandles = apperr.New(apperr.KindNotFound, "no_data", "no candles")
+
+	// ErrUpgradeRequired は利用者のプラン（free）では参照できない区分（premium）の銘柄を要求した場合のエラーです。
+	// 銘柄は存在するため 404 ではなく 402 とし、プランの変更を促します（UpgradeRequiredError 参照）。
+	ErrUpgradeRequired = apperr.New(apperr.KindPaymentRequired, "upgrade_required", "symbol requires the premium plan")
+
+	// ErrInvalidCandle は OHLCV の値が整合しない（高値 < 安値、非正の価格等）場合のエラーです。
+	ErrInvalidCandle = apperr.New(apperr.KindInvalid, "invalid_candle", "invalid candle")
+
+	// ErrUnsupportedRequest は外部データ取得元のプラン・仕様上受け付けられない取得要求
+	// （未対応の時間間隔、上限を超える outputsize 等）のエラーです。
+	// 外部 API を呼び出す前に検出し、API クレジットを消費しないために使用します。
+	ErrUnsupportedRequest = apperr.New(apperr.KindInvalid, "unsupported_request", "request not supported by market data provider")
+
+	// ErrUpstreamThrottled は外部データ取得元の利用枠（分あたりの呼び出し数・API クレジット）を使い切った場合のエラーです。
+	// 障害ではなく時間をおけば回復するため、503 と Retry-After でクライアントに待機を促します（ThrottledError 参照）。
+	ErrUpstreamThrottled = apperr.New(apperr.KindUnavailable, "upstream_throttled", "market data provider quota exhausted")
+
+	// ErrQueryTimeout はローソク足の読み取りクエリが実行時間の上限（statement_timeout）を超えて打ち切られた場合のエラーです。
+	// 障害ではなく要求が重すぎることが多いため、504 と取得範囲を狭めるヒントを返します（QueryTimeoutError 参照）。
+	ErrQueryTimeout = apperr.New(apperr.KindTimeout, "query_timeout", "candle query timed out")
+
+	// ErrInvalidRange は期間指定（?start= / ?end=）の開始日が終了日より後の場合のエラーです。
+	ErrInvalidRange = apperr.New(apperr.KindInvalid, "invalid_range", "invalid date range")
+
+	// ErrAnomalyNotFound は指定IDの異常値が存在しない場合のエラーです。
+	ErrAnomalyNotFound = apperr.New(apperr.KindNotFound, "anomaly_not_found", "anomaly not found")
+
+	// ErrInvalidAnomalyStatus は異常値の確認状態として指定できない値の場合のエラーです。
+	ErrInvalidAnomalyStatus = apperr.New(apperr.KindInvalid, "invalid_anomaly_status", "invalid anomaly status")
+
+	// ErrBackfillRequiresSplit は株式分割以外の確認結果で履歴の再取得を要求した場合のエラーです。
+	// 再取得は分割調整後の履歴を取り込むためのもので、誤データの確認では意味を持たないため拒否します。
+	ErrBackfillRequiresSplit = apperr.New(apperr.KindInvalid, "backfill_requires_split", "backfill is only available for confirmed splits")
+
+	// ErrQuarantined は未確認の異常値があるため、隔離モードで銘柄の取り込みを見送った場合のエラーです。
+	ErrQuarantined = apperr.New(apperr.KindConflict, "quarantined", "ingest skipped: pending anomalies")
+
+	// ErrAdjustmentNotFound は指定IDの調整係数が存在しない場合のエラーです。
+	ErrAdjustmentNotFound = apperr.New(apperr.KindNotFound, "adjustment_not_found", "adjustment not found")
+
+	// ErrInvalidAdjustment は調整係数の値が不正（非正の係数、効力発生日の欠落等）な場合のエラーです。
+	ErrInvalidAdjustment = apperr.New(apperr.KindInvalid, "invalid_adjustment", "invalid adjustment")
+
+	// ErrAdjustmentExists は同じ銘柄・効力発生日の調整係数が既に登録されている場合のエラーです。
+	ErrAdjustmentExists = apperr.New(apperr.KindConflict, "adjustment_exists", "adjustment already exists for the date")
+)
+
+// UnsupportedRequestError は ErrUnsupportedRequest の詳細（どの要求がなぜ拒否されたか）を保持します。
+// errors.Is(err, ErrUnsupportedRequest) で判定できます。
+type UnsupportedRequestError struct {
+	Interval   string
+	OutputSize int
+	Reason     string // 人が読める拒否理由（例: "interval 1min is not available on the basic plan"）
+}
+
+func (e *UnsupportedRequestError) Error() string {
+	return "unsupported market data request: " + e.Reason
+}
+
+func (e *UnsupportedRequestError) Unwrap() error {
+	return ErrUnsupportedRequest
+}
+
+// ThrottledError は ErrUpstreamThrottled の詳細（利用枠が回復するまでの待機時間）を保持します。
+// errors.Is(err, ErrUpstreamThrottled) で判定でき、HTTP 層は RetryAfter を Retry-After ヘッダに使います。
+type ThrottledError struct {
+	Wait   time.Duration // 利用枠が回復するまでの待機時間（不明なら 0）
+	Reason string        // 上流が返した理由（ログ用）
+}
+
+func (e *ThrottledError) Error() string {
+	if e.Reason == "" {
+		return "market data provider throttled"
+	}
+	return "market data provider throttled: " + e.Reason
+}
+
+func (e *ThrottledError) Unwrap() error {
+	return ErrUpstreamThrottled
+}
+
+// RetryAfter は再試行まで待つべき時間を返します。
+func (e *ThrottledError) RetryAfter() time.Duration {
+	return e.Wait
+}
+
+// QueryTimeoutError は ErrQueryTimeout の詳細（打ち切られた上限時間）を保持します。
+// errors.Is(err, ErrQueryTimeout) で判定でき、HTTP 層は Hint をレスポンスの hint に使います。
+type QueryTimeoutError struct {
+	Timeout time.Duration // 適用されていた statement_timeout
+}
+
+func (e *QueryTimeoutError) Error() string {
+	return "candle query exceeded " + e.Timeout.String()
+}
+
+func (e *QueryTimeoutError) Unwrap() error {
+	return ErrQueryTimeout
+}
+
+// Hint はクライアント向けの対処方法を返します。
+func (e *QueryTimeoutError) Hint() string {
+	return "narrow the requested range or retry later"
+}
+
+// UpgradeRequiredError は ErrUpgradeRequired の詳細（参照できなかった銘柄）を保持します。
+// errors.Is(err, ErrUpgradeRequired) で判定でき、HTTP 層は Hint をレスポンスの hint に使います。
+type UpgradeRequiredError struct {
+	Symbol string // 正規コード
+}
+
+func (e *UpgradeRequiredError) Error() string {
+	return "symbol " + e.Symbol + " requires the premium plan"
+}
+
+func (e *UpgradeRequiredError) Unwrap() error {
+	return ErrUpgradeRequired
+}
+
+// Hint はクライアント向けの対処方法を返します。
+func (e *UpgradeRequiredError) Hint() string {
+	return "upgrade to the premium plan to access " + e.Symbol
+}
@@ -0,0 +1,63 @@
+package candles
+
+import (
+	"context"
+	"errors"
+)
+
+// DefaultFindBatchSize は FindEach が 1 回のクエリで読み込む足の件数のデフォルトです。
+const DefaultFindBatchSize = 1000
+
+// ErrStop は FindEach のコールバックが返すと、以降の足を読まずに FindEach を正常終了（nil）させます。
+var ErrStop = errors.New("candles: stop iteration")
+
+// FindOptions は FindEach で読み込む足の条件です。
+type FindOptions struct {
+	Symbol   string
+	Interval string
+	// Limit は読み込む足の件数の上限です。0 以下なら上限を設けません。
+	Limit int
+	// BatchSize は 1 回のクエリで読み込む件数です。0 以下なら DefaultFindBatchSize を使います。
+	BatchSize int
+}
+
+// batchSize は 1 回のクエリで読み込む件数を返します。Limit の残りより多くは読みません。
+func (o FindOptions) batchSize(read int) int {
+	n := o.BatchSize
+	if n <= 0 {
+		n = DefaultFindBatchSize
+	}
+	if o.Limit > 0 {
+		n = min(n, o.Limit-read)
+	}
+	return n
+}
+
+// EachFinder は足を一定件数ずつ読み込み、結果全体をメモリーに載せずに 1 件ずつ渡すリポジトリが実装します。
+// 長い期間を走査する処理（ロールアップ等）で使い、実装しないリポジトリには Find で読み込んだ結果を渡します（findEach 参照）。
+type EachFinder interface {
+	// FindEach は opts の足を Find と同じ順序（時間の降順・同じ時間は id の降順）で fn に渡します。
+	// fn が ErrStop を返すと残りを読まずに nil を返し、それ以外のエラーはそのまま返します。
+	// コンテキストはクエリの間にも確認し、キャンセルされた場合は ctx.Err() を返します。
+	FindEach(ctx context.Context, opts FindOptions, fn func(Candle) error) error
+}
+
+// findEach は repo が EachFinder を実装していれば FindEach で、そうでなければ Find の結果を fn に渡します。
+func findEach(ctx context.Context, repo Repository, opts FindOptions, fn func(Candle) error) error {
+	if f, ok := repo.(EachFinder); ok {
+		return f.FindEach(ctx, opts, fn)
+	}
+	cs, err := repo.Find(ctx, opts.Symbol, opts.Interval, max(opts.Limit, 0))
+	if err != nil {
+		return err
+	}
+	for _, c := range cs {
+		if err := fn(c); err != nil {
+			if errors.Is(err, ErrStop) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package candles
+
+import (
+	"context"
+	"time"
+)
+
+// ingestIntervals は ingest 1 回で書き込まれる時間間隔です（日足を取得し週足・月足を集計）。
+var ingestIntervals = []string{"1day", "1week", "1month"}
+
+// Freshness は (interval, market) 単位のデータ鮮度マーカーです。
+// ingest バッチが各パスの終了時に書き込み、/readyz の freshness（DailyFreshness）が参照します。
+type Freshness struct {
+	Interval      string
+	Market        string
+	LastSuccessAt *time.Time // 最後に全銘柄の取り込みが成功した日時（未成功なら nil）
+	LastAttemptAt time.Time  // 成否を問わない最終試行日時
+	LastError     string     // 最終試行のエラー概要（成功時は空）
+}
+
+// FreshnessWriter はデータ鮮度マーカーの書き込みを抽象化します。
+// Goの慣例に従い、インターフェースは利用者（usecase）側で定義します。
+type FreshnessWriter interface {
+	// RecordSuccess は (interval, market) の成功を記録し、LastError をクリアします。
+	RecordSuccess(ctx context.Context, interval, market string, at time.Time) error
+	// RecordFailure は (interval, market) の試行日時とエラーを記録します。LastSuccessAt は保持します。
+	RecordFailure(ctx context.Context, interval, market string, at time.Time, errMsg string) error
+	// RecordFailureAll は既存の全マーカーに試行日時とエラーを記録します。
+	// 銘柄一覧の取得失敗など、対象市場が特定できない致命的エラー時に使用します。
+	RecordFailureAll(ctx context.Context, at time.Time, errMsg string) error
+}
+
+// FreshnessReader はデータ鮮度マーカーの読み取りを抽象化します。
+type FreshnessReader interface {
+	ListFreshness(ctx context.Context) ([]Freshness, error)
+}
+
+// LastExpectedTradingDay は now 時点で取り込み済みであるべき最新の取引日（0時）を返します。
+// now の前日から遡って最初の平日を返します（祝日は考慮しません）。
+func LastExpectedTradingDay(now time.Time) time.Time {
+	d := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, -1)
+	for d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+		d = d.AddDate(0, 0, -1)
+	}
+	return d
+}
+
+// IsStale は日足の鮮度マーカーが now 時点で古いかを返します。
+// 最後の成功が直近の取引日の開始より前（または未成功）の場合に古いとみなします。
+// 週末を挟む場合は金曜日が基準になるため、土日に ingest が走らなくても古いとは判定しません。
+func (f Freshness) IsStale(now time.Time) bool {
+	if f.LastSuccessAt == nil {
+		return true
+	}
+	return f.LastSuccessAt.Before(LastExpectedTradingDay(now))
+}
+
+// FreshnessState は日足のデータ鮮度の全体の状態です（/readyz の freshness）。
+type FreshnessState string
+
+const (
+	// FreshnessUnknown は日足の鮮度マーカーが 1 件もない（ingest が一度も走っていない）状態です。
+	FreshnessUnknown FreshnessState = "unknown"
+	// FreshnessOK はすべての市場の日足が直近の取引日まで取り込み済みの状態です。
+	FreshnessOK FreshnessState = "ok"
+	// FreshnessStale はいずれかの市場の日足が古い（Freshness.IsStale）状態です。
+	FreshnessStale FreshnessState = "stale"
+)
+
+// DailyFreshness は markers のうち日足（1day）のマーカーから、now 時点の鮮度の全体の状態を返します。
+// 1 市場でも古ければ FreshnessStale です。週足・月足・為替（fx）のマーカーは日足と同じ取り込みで更新されるか、
+// 別の周期で更新されるため判定に使いません。
+func DailyFreshness(markers []Freshness, now time.Time) FreshnessState {
+	state := FreshnessUnknown
+	for _, f := range markers {
+		if f.Interval != "1day" {
+			continue
+		}
+		if f.IsStale(now) {
+			return FreshnessStale
+		}
+		state = FreshnessOK
+	}
+	return state
+}
@@ -0,0 +1,74 @@
+package candles
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/sqlc"
+)
+
+// freshnessRepository は FreshnessWriter / FreshnessReader の sqlc 実装です。
+type freshnessRepository struct {
+	q *candlessqlc.Queries
+}
+
+var (
+	_ FreshnessWriter = (*freshnessRepository)(nil)
+	_ FreshnessReader = (*freshnessRepository)(nil)
+)
+
+// NewFreshnessRepository は指定された *sql.DB で freshnessRepository の新しいインスタンスを生成します。
+func NewFreshnessRepository(db *sql.DB) *freshnessRepository {
+	return &freshnessRepository{q: candlessqlc.New(db)}
+}
+
+// RecordSuccess は (interval, market) の成功を記録します。
+func (r *freshnessRepository) RecordSuccess(ctx context.Context, interval, market string, at time.Time) error {
+	return r.q.UpsertFreshnessSuccess(ctx, candlessqlc.UpsertFreshnessSuccessParams{
+		Interval:      interval,
+		Market:        market,
+		LastSuccessAt: sql.NullTime{Time: at, Valid: true},
+	})
+}
+
+// RecordFailure は (interval, market) の試行日時とエラーを記録します。
+func (r *freshnessRepository) RecordFailure(ctx context.Context, interval, market string, at time.Time, errMsg string) error {
+	return r.q.UpsertFreshnessFailure(ctx, candlessqlc.UpsertFreshnessFailureParams{
+		Interval:      interval,
+		Market:        market,
+		LastAttemptAt: at,
+		LastError:     sql.NullString{String: errMsg, Valid: true},
+	})
+}
+
+// RecordFailureAll は既存の全マーカーに試行日時とエラーを記録します。
+func (r *freshnessRepository) RecordFailureAll(ctx context.Context, at time.Time, errMsg string) error {
+	return r.q.MarkAllFreshnessFailed(ctx, candlessqlc.MarkAllFreshnessFailedParams{
+		LastAttemptAt: at,
+		LastError:     sql.NullString{String: errMsg, Valid: true},
+	})
+}
+
+// ListFreshness は全マーカーを (interval, market) 昇順で返します。
+func (r *freshnessRepository) ListFreshness(ctx context.Context) ([]Freshness, error) {
+	rows, err := r.q.ListFreshness(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Freshness, 0, len(rows))
+	for _, row := range rows {
+		f := Freshness{
+			Interval:      row.Interval,
+			Market:        row.Market,
+			LastAttemptAt: row.LastAttemptAt,
+			LastError:     row.LastError.String,
+		}
+		if row.LastSuccessAt.Valid {
+			t := row.LastSuccessAt.Time
+			f.LastSuccessAt = &t
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
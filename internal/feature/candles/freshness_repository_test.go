@@ -0,0 +1,56 @@
+package candles
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFreshnessRepository_RecordAndList(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewFreshnessRepository(db)
+	ctx := context.Background()
+
+	first := time.Date(2024, 1, 9, 22, 0, 0, 0, time.UTC)
+	second := first.Add(24 * time.Hour)
+
+	require.NoError(t, repo.RecordSuccess(ctx, "1day", "NASDAQ", first))
+	require.NoError(t, repo.RecordFailure(ctx, "1day", "TSE", first, "boom"))
+
+	// 成功済みマーカーへの失敗記録は last_success_at を保持する。
+	require.NoError(t, repo.RecordFailure(ctx, "1day", "NASDAQ", second, "1 of 2 symbols failed"))
+
+	got, err := repo.ListFreshness(ctx)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+
+	nasdaq, tse := got[0], got[1]
+	assert.Equal(t, "NASDAQ", nasdaq.Market)
+	require.NotNil(t, nasdaq.LastSuccessAt)
+	assert.True(t, nasdaq.LastSuccessAt.Equal(first))
+	assert.True(t, nasdaq.LastAttemptAt.Equal(second))
+	assert.Equal(t, "1 of 2 symbols failed", nasdaq.LastError)
+
+	assert.Equal(t, "TSE", tse.Market)
+	assert.Nil(t, tse.LastSuccessAt)
+	assert.Equal(t, "boom", tse.LastError)
+
+	// 成功記録はエラーをクリアする。
+	require.NoError(t, repo.RecordSuccess(ctx, "1day", "TSE", second))
+	// 全件失敗記録は既存行のみを更新する。
+	third := second.Add(time.Hour)
+	require.NoError(t, repo.RecordFailureAll(ctx, third, "listing active symbols failed"))
+
+	got, err = repo.ListFreshness(ctx)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	for _, f := range got {
+		assert.True(t, f.LastAttemptAt.Equal(third), "%s attempt", f.Market)
+		assert.Equal(t, "listing active symbols failed", f.LastError)
+		require.NotNil(t, f.LastSuccessAt)
+	}
+}
@@ -0,0 +1,93 @@
+package candles
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLastExpectedTradingDay(t *testing.T) {
+	testCases := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		// 2024-01-10 は水曜日
+		{name: "平日は前日", now: time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC), want: mustDate(2024, 1, 9)},
+		{name: "月曜は前週金曜", now: time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC), want: mustDate(2024, 1, 5)},
+		{name: "日曜は金曜", now: time.Date(2024, 1, 7, 9, 0, 0, 0, time.UTC), want: mustDate(2024, 1, 5)},
+		{name: "土曜は金曜", now: time.Date(2024, 1, 6, 9, 0, 0, 0, time.UTC), want: mustDate(2024, 1, 5)},
+		{name: "火曜は月曜", now: time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC), want: mustDate(2024, 1, 8)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := LastExpectedTradingDay(tc.now); !got.Equal(tc.want) {
+				t.Errorf("LastExpectedTradingDay(%v)=%v, want %v", tc.now, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFreshness_IsStale(t *testing.T) {
+	at := func(year int, month time.Month, day, hour int) *time.Time {
+		v := time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+		return &v
+	}
+
+	testCases := []struct {
+		name          string
+		lastSuccessAt *time.Time
+		now           time.Time
+		want          bool
+	}{
+		{name: "未成功は古い", lastSuccessAt: nil, now: time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC), want: true},
+		{name: "前日に成功していれば新しい", lastSuccessAt: at(2024, 1, 9, 22), now: time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC), want: false},
+		{name: "2営業日前の成功は古い", lastSuccessAt: at(2024, 1, 8, 22), now: time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC), want: true},
+		{name: "金曜の成功は月曜時点で新しい（週末を挟む）", lastSuccessAt: at(2024, 1, 5, 22), now: time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC), want: false},
+		{name: "金曜の成功は日曜時点で新しい", lastSuccessAt: at(2024, 1, 5, 22), now: time.Date(2024, 1, 7, 9, 0, 0, 0, time.UTC), want: false},
+		{name: "木曜の成功は月曜時点で古い", lastSuccessAt: at(2024, 1, 4, 22), now: time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC), want: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := Freshness{Interval: "1day", Market: "NASDAQ", LastSuccessAt: tc.lastSuccessAt}
+			if got := f.IsStale(tc.now); got != tc.want {
+				t.Errorf("IsStale(%v)=%v, want %v", tc.now, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDailyFreshness(t *testing.T) {
+	at := func(year int, month time.Month, day, hour int) *time.Time {
+		v := time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+		return &v
+	}
+	// 2024-01-05 は金曜日、2024-01-08 は月曜日
+	friday := Freshness{Interval: "1day", Market: "NASDAQ", LastSuccessAt: at(2024, 1, 5, 22)}
+	thursday := Freshness{Interval: "1day", Market: "NYSE", LastSuccessAt: at(2024, 1, 4, 22)}
+	oldWeekly := Freshness{Interval: "1week", Market: "NASDAQ", LastSuccessAt: at(2023, 12, 1, 22)}
+	oldFX := Freshness{Interval: "fx", Market: "USD/JPY", LastSuccessAt: at(2023, 12, 1, 22)}
+
+	testCases := []struct {
+		name    string
+		markers []Freshness
+		now     time.Time
+		want    FreshnessState
+	}{
+		{name: "マーカーなしは unknown", markers: nil, now: time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC), want: FreshnessUnknown},
+		{name: "日足以外だけは unknown", markers: []Freshness{oldWeekly, oldFX}, now: time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC), want: FreshnessUnknown},
+		{name: "金曜の成功は土曜時点で ok", markers: []Freshness{friday}, now: time.Date(2024, 1, 6, 9, 0, 0, 0, time.UTC), want: FreshnessOK},
+		{name: "金曜の成功は月曜時点で ok（週末を挟む）", markers: []Freshness{friday, oldWeekly, oldFX}, now: time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC), want: FreshnessOK},
+		{name: "金曜の成功は火曜時点で stale", markers: []Freshness{friday}, now: time.Date(2024, 1, 9, 9, 0, 0, 0, time.UTC), want: FreshnessStale},
+		{name: "1 市場でも古ければ stale", markers: []Freshness{friday, thursday}, now: time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC), want: FreshnessStale},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := DailyFreshness(tc.markers, tc.now); got != tc.want {
+				t.Errorf("DailyFreshness()=%v, want %v", got, tc.want)
+			}
+		})
+	}
+}
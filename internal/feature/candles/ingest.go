@@ -2,20 +2,32 @@ package candles
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"time"
 )
 
-const (
-	ingestOutputSize = 5000 // 1リクエストあたりの取得データポイント数（TwelveData 最大値）
-)
-
 // WriteRepository はローソク足データの書き込みレイヤーを抽象化します。
 // Goの慣例に従い、インターフェースは利用者（usecase）側で定義します。
 type WriteRepository interface {
-	// UpsertBatch は（symbol, interval, time）をユニークキーとしてUpsert操作を行います。
-	UpsertBatch(ctx context.Context, candles []Candle) error
+	// UpsertBatch は（symbol, interval, time）をユニークキーとしてUpsert操作を行い、新規挿入・上書きの行数を返します。
+	UpsertBatch(ctx context.Context, candles []Candle) (UpsertStats, error)
+}
+
+// UpsertStats は UpsertBatch で新規に挿入した行数と、既存の行を上書きした行数です。
+// 値が変わらない上書きも Updated に数えます（ON CONFLICT DO UPDATE は常に行を書き換えるため）。
+// Changed は挿入した行と値（OHLCV）が変わった上書きの行数で、0 なら保存済みのデータは変わっていません。
+type UpsertStats struct {
+	Inserted int64
+	Updated  int64
+	Changed  int64
+}
+
+// Add は other の行数を加算した UpsertStats を返します。
+func (s UpsertStats) Add(other UpsertStats) UpsertStats {
+	return UpsertStats{Inserted: s.Inserted + other.Inserted, Updated: s.Updated + other.Updated, Changed: s.Changed + other.Changed}
 }
 
 // MarketRepository は株式市場データ取得のリポジトリインターフェースを定義します。
@@ -27,11 +39,34 @@ type MarketRepository interface {
 	GetTimeSeries(ctx context.Context, symbol, interval string, outputsize int, loc *time.Location) ([]Candle, error)
 }
 
-// ActiveSymbol は ingest 対象銘柄のコードとタイムゾーン情報を保持します。
+// OutputSizeCapper は 1 リクエストあたりの outputsize 上限を公開する MarketRepository の任意拡張です。
+// 上限を超える要求は ErrUnsupportedRequest で拒否されるため、呼び出し側は ClampOutputSize で事前に丸めます。
+type OutputSizeCapper interface {
+	MaxOutputSize() int
+}
+
+// ClampOutputSize は market が OutputSizeCapper を実装し上限（正の値）を持つ場合、outputsize を上限に丸めます。
+// 件数を減らしても取得自体は成立するため、失敗させずに丸めるのが安全な補正です。
+func ClampOutputSize(market MarketRepository, outputsize int) int {
+	capper, ok := market.(OutputSizeCapper)
+	if !ok {
+		return outputsize
+	}
+	if limit := capper.MaxOutputSize(); limit > 0 && outputsize > limit {
+		return limit
+	}
+	return outputsize
+}
+
+// ActiveSymbol は ingest 対象銘柄のコード・市場・タイムゾーン情報を保持します。
 // Timezone は IANA タイムゾーン文字列（例: "America/New_York", "Asia/Tokyo"）。
+// Market はデータ鮮度マーカーの集計単位（例: "NASDAQ", "TSE"）です。
+// Priority は取り込み優先度（1 が最優先）で、0 以下は DefaultIngestPriority として扱います。
 type ActiveSymbol struct {
 	Code     string
+	Market   string
 	Timezone string
+	Priority int
 }
 
 // SymbolRepository はデータ取り込み対象の銘柄取得を抽象化します。
@@ -42,18 +77,59 @@ type SymbolRepository interface {
 
 // RateLimiter は外部 API 呼び出しの待機を抽象化します。
 // Goの慣例に従い、インターフェースは利用者（usecase）側で定義します。
+// WithConcurrency で並行に取り込む場合は複数 goroutine から呼び出されます（clientratelimit.RateLimiter は対応済み）。
 type RateLimiter interface {
 	WaitIfNeeded(ctx context.Context) error
 }
 
 // IngestResult は IngestAll 実行後の銘柄単位の集計結果を表します。
 // 致命的エラー時も部分集計が返されるため、main 側でサマリログを出力できます。
-// 個別エラーの内容は IngestAll 内で slog.Error として出力されるため、
-// 集約せず件数のみ保持します。
+// 失敗した銘柄とそのエラーは Errors に失敗した順で保持します（個別のエラーは slog.Error でも出力します）。
+// ctx のキャンセル/タイムアウトで処理できなかった銘柄は Failed ではなく Aborted に数えます。
+// Inserted / Updated は成功した銘柄のローソク足（日足・週足・月足の合計）の行数です。
+// Tiers は優先度ごとの内訳で、優先度の高い順に並びます。
 type IngestResult struct {
-	Total     int // 取り込み対象銘柄数
-	Succeeded int // 成功数
-	Failed    int // 失敗数
+	Total     int   // 取り込み対象銘柄数
+	Succeeded int   // 成功数
+	Failed    int   // 失敗数
+	Aborted   int   // ctx 中断・段の時間予算超過・メンテナンスモードにより未完了となった銘柄数
+	Inserted  int64 // 新規に挿入した行数（新しい足）
+	Updated   int64 // 既存の行を上書きした行数
+	Tiers     []TierResult
+	Errors    []SymbolError // 失敗した銘柄ごとのエラー（Failed と同数）
+	Duration  time.Duration // IngestAll の所要時間
+}
+
+// SymbolError は取り込みに失敗した 1 銘柄とその原因です。
+type SymbolError struct {
+	Symbol string
+	Err    error
+}
+
+// fail は銘柄の失敗を集計に加えます。
+func (r *IngestResult) fail(symbol string, err error) {
+	r.Failed++
+	r.Errors = append(r.Errors, SymbolError{Symbol: symbol, Err: err})
+}
+
+// FailedSymbols は失敗した銘柄コードを失敗した順で返します。
+func (r IngestResult) FailedSymbols() []string {
+	codes := make([]string, len(r.Errors))
+	for i, e := range r.Errors {
+		codes[i] = e.Symbol
+	}
+	return codes
+}
+
+// addStats は成功した銘柄の Upsert 行数を集計に加えます。
+func (r *IngestResult) addStats(stats UpsertStats) {
+	r.Inserted += stats.Inserted
+	r.Updated += stats.Updated
+}
+
+// Processed は中断前に処理を終えた（成功または失敗した）銘柄数を返します。
+func (r IngestResult) Processed() int {
+	return r.Succeeded + r.Failed
 }
 
 // FailureRate は失敗率を [0.0, 1.0] で返します。Total が 0 の場合は 0 を返します。
@@ -70,39 +146,170 @@ type IngestUsecase struct {
 	candle      WriteRepository
 	symbol      SymbolRepository
 	rateLimiter RateLimiter
+	freshness   FreshnessWriter
+	now         func() time.Time
+
+	// 異常値検出（WithAnomalyDetection で有効化。anomalies が nil なら検出しない）
+	anomalies     AnomalyStore
+	latest        LatestCandleReader
+	anomalyConfig AnomalyConfig
+
+	// 保存後の通知先（WithObserver で設定。nil なら通知しない）
+	observer IngestObserver
+
+	// 要約統計のロールアップ（WithStatsRollup で設定。nil なら再計算しない）
+	rollup StatsRecomputer
+
+	// 優先度ごとの時間予算（WithTierBudgets で設定。予算のない段は ctx の期限まで続ける）
+	tierBudgets map[int]time.Duration
+
+	// 時間間隔ごとの outputsize（WithOutputSizePolicy で設定。ゼロ値は DefaultOutputSizePolicy）
+	outputSizes OutputSizePolicy
+
+	// メンテナンスモード（WithMaintenance で設定。nil なら確認しない）
+	maintenance MaintenanceChecker
+
+	// 1 回の呼び出しでまとめて取得する銘柄数（WithBatchSize で設定。1 以下なら銘柄ごとに取得する）
+	batchSize int
+
+	// 段の中で並行して取り込むワーカー数（WithConcurrency で設定。1 以下なら順に取り込む）
+	concurrency int
+}
+
+// MaintenanceChecker はメンテナンスモード（書き込みの一時停止）が有効かを返します（transport/maintenance の Mode が実装）。
+type MaintenanceChecker interface {
+	Active(ctx context.Context) bool
+}
+
+// ErrIngestPaused はメンテナンスモードのため取り込みを途中で見送った場合のエラーです。
+// 障害ではないため、バッチは失敗として扱わずに終了します。
+var ErrIngestPaused = errors.New("ingest paused: maintenance mode is active")
+
+// IngestObserver は銘柄ごとの取り込み（保存）の完了を受け取ります（アラートの評価など）。
+// candles は保存した日足・週足・月足をまとめて渡します。
+type IngestObserver interface {
+	CandlesIngested(ctx context.Context, symbol string, candles []Candle) error
+}
+
+// StatsRecomputer は銘柄の要約統計のロールアップを再計算します（StatsRollup が実装）。
+type StatsRecomputer interface {
+	Recompute(ctx context.Context, code string) error
 }
 
 // NewIngestUsecase はIngestUsecaseの新しいインスタンスを生成します。
-func NewIngestUsecase(market MarketRepository, candle WriteRepository, symbol SymbolRepository, rateLimiter RateLimiter) *IngestUsecase {
-	return &IngestUsecase{market: market, candle: candle, symbol: symbol, rateLimiter: rateLimiter}
+func NewIngestUsecase(market MarketRepository, candle WriteRepository, symbol SymbolRepository, rateLimiter RateLimiter, freshness FreshnessWriter) *IngestUsecase {
+	return &IngestUsecase{
+		market:      market,
+		candle:      candle,
+		symbol:      symbol,
+		rateLimiter: rateLimiter,
+		freshness:   freshness,
+		now:         time.Now,
+	}
+}
+
+// WithAnomalyDetection は取り込み時の異常値検出を有効にします。
+// 新しい日足の終値を直前の保存済み終値（latest）と比較し、cfg.Threshold を超える変動を store に記録します。
+func (iu *IngestUsecase) WithAnomalyDetection(store AnomalyStore, latest LatestCandleReader, cfg AnomalyConfig) *IngestUsecase {
+	iu.anomalies = store
+	iu.latest = latest
+	iu.anomalyConfig = cfg
+	return iu
+}
+
+// WithObserver は銘柄ごとの保存の完了を observer に通知します。
+// 通知の失敗は警告ログに記録するだけで、取り込みは失敗にしません（保存は完了しているため）。
+func (iu *IngestUsecase) WithObserver(observer IngestObserver) *IngestUsecase {
+	iu.observer = observer
+	return iu
+}
+
+// WithStatsRollup は保存で値の変わった銘柄（UpsertStats.Changed > 0）の要約統計を保存後に再計算します。
+// 再計算の失敗は警告ログに記録するだけで、取り込みは失敗にしません（読み取り側が古い行を都度計算で補うため）。
+func (iu *IngestUsecase) WithStatsRollup(rollup StatsRecomputer) *IngestUsecase {
+	iu.rollup = rollup
+	return iu
+}
+
+// WithOutputSizePolicy は取得する日足の件数を p の日足の上限にします（未設定なら DefaultOutputSizePolicy）。
+// 週足・月足は取得した日足から集計するため、日足の上限が取り込む期間を決めます。
+func (iu *IngestUsecase) WithOutputSizePolicy(p OutputSizePolicy) *IngestUsecase {
+	iu.outputSizes = p
+	return iu
+}
+
+// WithMaintenance は銘柄ごとの取り込みの前に m でメンテナンスモードを確認し、有効なら残りを見送ります。
+// 実行中にフラグを切り替えた場合も、取り込み中の銘柄を終えた時点で止まります（IngestAll 参照）。
+func (iu *IngestUsecase) WithMaintenance(m MaintenanceChecker) *IngestUsecase {
+	iu.maintenance = m
+	return iu
+}
+
+// paused はメンテナンスモードが有効かを返します。
+func (iu *IngestUsecase) paused(ctx context.Context) bool {
+	return iu.maintenance != nil && iu.maintenance.Active(ctx)
+}
+
+// fetchOutputSize は 1 銘柄あたりに取得する日足の件数です。
+func (iu *IngestUsecase) fetchOutputSize() int {
+	return iu.outputSizes.Limit(DefaultInterval).Max
 }
 
 // ingestOne は指定された銘柄の日足データを外部リポジトリから取得し、
 // 週足・月足を集計して3種まとめてデータベースにバッチ挿入（または更新）します。
 // sym.Timezone は IANA タイムゾーン文字列で、外部 API レスポンスの解釈および
 // 集計境界判定（週月の開始）に使用されます。
-func (iu *IngestUsecase) ingestOne(ctx context.Context, sym ActiveSymbol, outputsize int) error {
-	loc, err := time.LoadLocation(sym.Timezone)
+// screen が true で異常値検出が有効な場合は、保存前に異常値を検出します（screenAnomalies 参照）。
+// 戻り値は UpsertBatch の新規挿入・上書きの行数です。
+func (iu *IngestUsecase) ingestOne(ctx context.Context, sym ActiveSymbol, outputsize int, screen bool) (UpsertStats, error) {
+	return iu.ingestSymbol(ctx, ingestRun{intervals: ingestIntervals, outputsize: outputsize}, sym, screen)
+}
+
+// ingestSymbol は ingestOne と同じく 1 銘柄を取り込みます。件数と保存する時間間隔は run に従います。
+func (iu *IngestUsecase) ingestSymbol(ctx context.Context, run ingestRun, sym ActiveSymbol, screen bool) (UpsertStats, error) {
+	loc, err := loadSymbolLocation(sym)
 	if err != nil {
-		return fmt.Errorf("load timezone %q: %w", sym.Timezone, err)
+		return UpsertStats{}, err
 	}
 
-	daily, err := iu.market.GetTimeSeries(ctx, sym.Code, "1day", outputsize, loc)
+	daily, err := iu.market.GetTimeSeries(ctx, sym.Code, "1day", ClampOutputSize(iu.market, run.outputsize), loc)
 	if err != nil {
-		return err
+		return UpsertStats{}, err
 	}
+	return iu.storeDaily(ctx, sym, loc, daily, run.intervals, screen)
+}
 
+// loadSymbolLocation は銘柄のタイムゾーン（IANA タイムゾーン文字列）を読み込みます。
+func loadSymbolLocation(sym ActiveSymbol) (*time.Location, error) {
+	loc, err := time.LoadLocation(sym.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("load timezone %q: %w", sym.Timezone, err)
+	}
+	return loc, nil
+}
+
+// storeDaily は取得した銘柄の日足から週足・月足を集計し、intervals の時間間隔の足をまとめて保存します（ingestOne と一括取得で共通）。
+// loc は銘柄のタイムゾーンで、集計境界判定（週月の開始）に使用されます。
+func (iu *IngestUsecase) storeDaily(ctx context.Context, sym ActiveSymbol, loc *time.Location, daily []Candle, intervals []string, screen bool) (UpsertStats, error) {
 	for i := range daily {
 		daily[i].SymbolCode = sym.Code
 		daily[i].Interval = "1day"
 	}
 
+	var err error
+	if screen && iu.anomalies != nil {
+		if daily, err = iu.screenAnomalies(ctx, sym.Code, daily); err != nil {
+			return UpsertStats{}, err
+		}
+	}
+
 	weekly := trimIncompleteFirstBucket(aggregateWeekly(daily, loc), daily, func(t time.Time) bool {
 		return int(t.In(loc).Weekday()) == 1 // 月曜日が ISO 週の開始
 	})
 	for i := range weekly {
 		weekly[i].SymbolCode = sym.Code
 		weekly[i].Interval = "1week"
+		weekly[i].Source = SourceDerived
 	}
 
 	monthly := trimIncompleteFirstBucket(aggregateMonthly(daily, loc), daily, func(t time.Time) bool {
@@ -111,14 +318,75 @@ func (iu *IngestUsecase) ingestOne(ctx context.Context, sym ActiveSymbol, output
 	for i := range monthly {
 		monthly[i].SymbolCode = sym.Code
 		monthly[i].Interval = "1month"
+		monthly[i].Source = SourceDerived
 	}
 
 	all := make([]Candle, 0, len(daily)+len(weekly)+len(monthly))
-	all = append(all, daily...)
-	all = append(all, weekly...)
-	all = append(all, monthly...)
+	for _, part := range [][]Candle{daily, weekly, monthly} {
+		if len(part) > 0 && slices.Contains(intervals, part[0].Interval) {
+			all = append(all, part...)
+		}
+	}
+
+	all = dedupCandles(all)
+	stats, err := iu.candle.UpsertBatch(ctx, all)
+	if err != nil {
+		return UpsertStats{}, err
+	}
+	if iu.observer != nil {
+		if err := iu.observer.CandlesIngested(ctx, sym.Code, all); err != nil {
+			slog.Warn("ingest observer failed", "symbol", sym.Code, "error", err)
+		}
+	}
+	if iu.rollup != nil && stats.Changed > 0 {
+		if err := iu.rollup.Recompute(ctx, sym.Code); err != nil {
+			slog.Warn("failed to recompute daily stats", "symbol", sym.Code, "error", err)
+		}
+	}
+	return stats, nil
+}
 
-	return iu.candle.UpsertBatch(ctx, dedupCandles(all))
+// screenAnomalies は新しい日足の異常値を検出・記録し、保存する日足を返します。
+// 誤データと確認済みの日足は除外し、週足・月足の集計にも含めません。
+// 隔離モードで未確認の異常値がある場合は ErrQuarantined を返し、銘柄の取り込みを見送ります。
+func (iu *IngestUsecase) screenAnomalies(ctx context.Context, code string, daily []Candle) ([]Candle, error) {
+	latest, err := iu.latest.FindLatest(ctx, code, "1day")
+	if err != nil {
+		return nil, fmt.Errorf("find latest candle: %w", err)
+	}
+	detected := DetectAnomalies(latest, daily, iu.anomalyConfig.Threshold)
+	if len(detected) == 0 {
+		return daily, nil
+	}
+	recorded, err := iu.anomalies.RecordAnomalies(ctx, detected)
+	if err != nil {
+		return nil, fmt.Errorf("record anomalies: %w", err)
+	}
+
+	pending := 0
+	bad := make(map[int64]struct{})
+	for _, a := range recorded {
+		switch a.Status {
+		case AnomalyPending:
+			pending++
+			slog.Warn("candle anomaly pending review", "symbol", code, "time", a.Time, "prev_close", a.PrevClose, "close", a.Close, "ratio", a.Ratio)
+		case AnomalyConfirmedBadData:
+			bad[a.Time.Unix()] = struct{}{}
+		}
+	}
+	if pending > 0 && iu.anomalyConfig.Quarantine {
+		return nil, fmt.Errorf("%w: %d pending", ErrQuarantined, pending)
+	}
+	if len(bad) == 0 {
+		return daily, nil
+	}
+	kept := make([]Candle, 0, len(daily))
+	for _, c := range daily {
+		if _, ok := bad[c.Time.Unix()]; !ok {
+			kept = append(kept, c)
+		}
+	}
+	return kept, nil
 }
 
 // dedupCandles は (symbol, interval, time) の組み合わせが重複するエントリを除去します。
@@ -141,32 +409,199 @@ func dedupCandles(candles []Candle) []Candle {
 // 日足・週足・月足をデータベースに永続化します。
 // APIレート制限を遵守し、必要に応じてリクエスト間で待機します。
 //
+// 銘柄は優先度（ActiveSymbol.Priority）の高い段から順に、段の中はリポジトリの返した順に処理します
+// （WithConcurrency で並行に取り込む場合は、段の中の順序は一定ではありません）。
+// 週足・月足は日足から集計するため外部APIの呼び出しは銘柄ごとに 1 回で、3 種の足は同時に揃います。
+// WithTierBudgets で段に時間予算を設定した場合、予算を使い切った段の残りは Aborted に数えて次の段へ進みます。
+//
 // 銘柄単位の失敗は IngestResult に集約され処理は継続します。
 // 致命的エラー（symbol 一覧取得失敗、ctx キャンセル、rateLimiter 失敗）は
 // それまでの部分集計と共に error を返します。
-func (iu *IngestUsecase) IngestAll(ctx context.Context) (IngestResult, error) {
+// ctx のキャンセル/タイムアウトは銘柄の失敗として数えず、残り全件を Aborted として即座に打ち切ります。
+// 成否に関わらず、終了時に (interval, market) 単位のデータ鮮度マーカーを更新します。
+//
+// opts で保存する時間間隔・取得件数・銘柄を絞り込めます（ゼロ値は全件。IngestOptions 参照）。
+// 指定が不正な場合は何も取り込まずに ErrInvalidIngestOptions を返します。
+//
+// WithMaintenance でメンテナンスモードが有効になった場合は、残り全件を Aborted に数えて ErrIngestPaused を返します。
+// メンテナンス中は DB に書き込まないため、鮮度マーカーも更新しません（次回の取り込みで更新されます）。
+func (iu *IngestUsecase) IngestAll(ctx context.Context, opts IngestOptions) (IngestResult, error) {
+	if err := opts.Validate(); err != nil {
+		return IngestResult{}, err
+	}
+	if iu.paused(ctx) {
+		return IngestResult{}, ErrIngestPaused
+	}
+	startedAt := iu.now()
+	result, err := iu.ingestAll(ctx, startedAt, opts)
+	result.Duration = iu.now().Sub(startedAt)
+	return result, err
+}
+
+// ingestAll は IngestAll の本体です（所要時間の計測を IngestAll に集約するために分けています）。
+func (iu *IngestUsecase) ingestAll(ctx context.Context, startedAt time.Time, opts IngestOptions) (IngestResult, error) {
+	run := iu.newIngestRun(opts)
 	symbols, err := iu.symbol.ListActiveSymbols(ctx)
 	if err != nil {
+		if !run.partial {
+			iu.recordFreshnessAll(ctx, startedAt, err)
+		}
 		return IngestResult{}, err
 	}
+	if run.partial {
+		if symbols, err = selectSymbols(symbols, opts.Symbols); err != nil {
+			return IngestResult{}, err
+		}
+	}
 
-	result := IngestResult{Total: len(symbols)}
-	for _, s := range symbols {
-		// WaitIfNeeded は limit 未到達なら cancelled ctx でも nil を返すため、
-		// ループごとに明示的に ctx をチェックして早期離脱する。
-		if err := ctx.Err(); err != nil {
+	tallies := newMarketTallies(symbols)
+	tiers := groupByPriority(symbols)
+	result := IngestResult{Total: len(symbols), Tiers: make([]TierResult, len(tiers))}
+	for i, tier := range tiers {
+		result.Tiers[i] = TierResult{Priority: tier.priority, Total: len(tier.symbols)}
+	}
+	for i, tier := range tiers {
+		if err := iu.ingestTier(ctx, run, tier, &result, &result.Tiers[i], tallies); err != nil {
+			if isContextAbort(ctx, err) {
+				return iu.abort(ctx, run, tallies, result, err)
+			}
+			if errors.Is(err, ErrIngestPaused) {
+				countAborted(&result)
+				return result, err
+			}
+			iu.recordFreshness(ctx, run, tallies, err)
 			return result, err
 		}
-		if err := iu.rateLimiter.WaitIfNeeded(ctx); err != nil {
-			return result, err
+	}
+	iu.recordFreshness(ctx, run, tallies, nil)
+	return result, nil
+}
+
+// ingestTier は 1 つの段の銘柄を取り込み、result と tier に集計します。
+// WithConcurrency で 2 以上を設定した場合は、段の中の銘柄（一括取得ではチャンク）をワーカーが並行して取り込みます。
+// 段の時間予算を使い切った場合は残りを Aborted に数えて nil を返します。
+// ctx の中断・メンテナンスモードと rateLimiter の失敗はエラーとして返し、IngestAll が打ち切ります。
+func (iu *IngestUsecase) ingestTier(ctx context.Context, run ingestRun, tier ingestTier, result *IngestResult, tr *TierResult, tallies map[string]*marketTally) error {
+	tierCtx := ctx
+	budget, hasBudget := iu.tierBudgets[tier.priority]
+	if hasBudget {
+		var cancel context.CancelFunc
+		tierCtx, cancel = context.WithTimeout(ctx, budget)
+		defer cancel()
+	}
+	t := &tierRun{
+		iu: iu, ctx: ctx, tierCtx: tierCtx, run: run, tier: tier,
+		budget: budget, hasBudget: hasBudget, start: iu.now(), size: iu.chunkSize(),
+		result: result, tr: tr, tallies: tallies, done: make([]bool, len(tier.symbols)),
+	}
+	t.runPool(iu.workerCount())
+	if t.err != nil {
+		return t.err
+	}
+	if t.exhausted {
+		iu.skipTier(t.rest(), budget, result, tr, tallies)
+	}
+	return nil
+}
+
+// isContextAbort は err が ctx 自身のキャンセル/タイムアウトに起因するかを返します。
+// 外部 API 側のタイムアウト等、ctx が生きている場合の DeadlineExceeded は銘柄の失敗として扱います。
+func isContextAbort(ctx context.Context, err error) bool {
+	ctxErr := ctx.Err()
+	return ctxErr != nil && errors.Is(err, ctxErr)
+}
+
+// abort は ctx 中断時の後処理を行います。未完了の銘柄を Aborted に計上し、
+// 鮮度マーカーに中断を記録したうえで "aborted after N of M items due to ..." 形式のエラーを返します。
+func (iu *IngestUsecase) abort(ctx context.Context, run ingestRun, tallies map[string]*marketTally, result IngestResult, cause error) (IngestResult, error) {
+	countAborted(&result)
+	reason := "context cancellation"
+	if errors.Is(cause, context.DeadlineExceeded) {
+		reason = "context deadline"
+	}
+	err := fmt.Errorf("aborted after %d of %d items due to %s: %w", result.Processed(), result.Total, reason, cause)
+	iu.recordFreshness(ctx, run, tallies, err)
+	return result, err
+}
+
+// countAborted は打ち切りで未完了となった銘柄を result と各段の Aborted に計上します。
+func countAborted(result *IngestResult) {
+	result.Aborted = result.Total - result.Processed()
+	for i := range result.Tiers {
+		t := &result.Tiers[i]
+		t.Aborted = t.Total - t.Succeeded - t.Failed
+	}
+}
+
+// freshnessWriteTimeout は鮮度マーカー書き込みの上限時間です。
+// 親 ctx がキャンセル済みでも失敗を記録できるよう、キャンセルを切り離した ctx で書き込みます。
+const freshnessWriteTimeout = 10 * time.Second
+
+// marketTally は市場単位の取り込み結果の集計です。
+type marketTally struct {
+	total   int
+	failed  int
+	lastErr error
+}
+
+func (t *marketTally) fail(err error) {
+	t.failed++
+	t.lastErr = err
+}
+
+// newMarketTallies は銘柄一覧から市場ごとの集計器を生成します。
+func newMarketTallies(symbols []ActiveSymbol) map[string]*marketTally {
+	tallies := make(map[string]*marketTally)
+	for _, s := range symbols {
+		t, ok := tallies[s.Market]
+		if !ok {
+			t = &marketTally{}
+			tallies[s.Market] = t
 		}
-		if err := iu.ingestOne(ctx, s, ingestOutputSize); err != nil {
-			// 1銘柄のエラーで処理を停止せず、エラーをログに記録して続行
-			slog.Error("failed to ingest data", "symbol", s.Code, "error", err)
-			result.Failed++
-			continue
+		t.total++
+	}
+	return tallies
+}
+
+// recordFreshness は市場ごとの集計結果から鮮度マーカーを書き込みます。
+// fatalErr が非 nil の場合は途中で中断したため全市場を失敗として記録します。
+// 書き込み失敗は取り込み結果に影響させず、警告ログのみ出力します。
+func (iu *IngestUsecase) recordFreshness(ctx context.Context, run ingestRun, tallies map[string]*marketTally, fatalErr error) {
+	if run.partial {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), freshnessWriteTimeout)
+	defer cancel()
+
+	at := iu.now()
+	for market, t := range tallies {
+		var errMsg string
+		switch {
+		case fatalErr != nil:
+			errMsg = fmt.Sprintf("ingest aborted: %v", fatalErr)
+		case t.failed > 0:
+			errMsg = fmt.Sprintf("%d of %d symbols failed: %v", t.failed, t.total, t.lastErr)
+		}
+		for _, interval := range run.intervals {
+			var err error
+			if errMsg == "" {
+				err = iu.freshness.RecordSuccess(ctx, interval, market, at)
+			} else {
+				err = iu.freshness.RecordFailure(ctx, interval, market, at, errMsg)
+			}
+			if err != nil {
+				slog.Warn("failed to record data freshness", "interval", interval, "market", market, "error", err)
+			}
 		}
-		result.Succeeded++
 	}
-	return result, nil
+}
+
+// recordFreshnessAll は対象市場が特定できない致命的エラーを既存の全マーカーに記録します。
+func (iu *IngestUsecase) recordFreshnessAll(ctx context.Context, at time.Time, fatalErr error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), freshnessWriteTimeout)
+	defer cancel()
+
+	if err := iu.freshness.RecordFailureAll(ctx, at, fmt.Sprintf("ingest aborted: %v", fatalErr)); err != nil {
+		slog.Warn("failed to record data freshness", "error", err)
+	}
 }
@@ -0,0 +1,121 @@
+package candles
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxIngestBatchSize は 1 回の一括取得にまとめられる銘柄数の上限です（Twelve Data の 1 リクエストあたりの上限）。
+const MaxIngestBatchSize = 120
+
+// BatchMarketRepository は複数銘柄の時系列を 1 回の呼び出しで取得できる MarketRepository の任意拡張です。
+// 実装していない market では、WithBatchSize を設定しても銘柄ごとに GetTimeSeries を呼び出します。
+type BatchMarketRepository interface {
+	// GetTimeSeriesBatch は symbols の時系列を銘柄コードごとに返します。
+	// locs は銘柄ごとの解釈ロケールで、タイムスタンプを各取引所のローカル時刻として解釈します。
+	// 一部の銘柄だけが失敗した場合は、成功した銘柄の結果と BatchSymbolErrors を返します。
+	// それ以外のエラーは呼び出し全体の失敗で、すべての銘柄が失敗したものとして扱います。
+	GetTimeSeriesBatch(ctx context.Context, symbols []string, interval string, outputsize int, locs map[string]*time.Location) (map[string][]Candle, error)
+}
+
+// BatchSymbolErrors は一括取得で失敗した銘柄ごとのエラーです（キーは銘柄コード）。
+type BatchSymbolErrors map[string]error
+
+func (e BatchSymbolErrors) Error() string {
+	codes := make([]string, 0, len(e))
+	for code := range e {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return fmt.Sprintf("time series failed for %d symbol(s): %s", len(codes), strings.Join(codes, ", "))
+}
+
+// WithBatchSize は n 銘柄ずつまとめて時系列を取得するよう設定します（market が BatchMarketRepository の場合のみ有効）。
+// n が 1 以下なら銘柄ごとに取得し、MaxIngestBatchSize を超える値は上限に丸めます。
+// 一括取得でも上流は銘柄ごとに利用枠を消費するため、rateLimiter はまとめた銘柄数だけ待ちます（減るのは HTTP の往復の回数です）。
+func (iu *IngestUsecase) WithBatchSize(n int) *IngestUsecase {
+	iu.batchSize = min(n, MaxIngestBatchSize)
+	return iu
+}
+
+// chunkSize は ingestTier が 1 回の取得にまとめる銘柄数です。
+func (iu *IngestUsecase) chunkSize() int {
+	if iu.batchSize <= 1 {
+		return 1
+	}
+	if _, ok := iu.market.(BatchMarketRepository); !ok {
+		return 1
+	}
+	return iu.batchSize
+}
+
+// ingestOutcome は ingestChunk の銘柄ごとの結果です。
+type ingestOutcome struct {
+	stats UpsertStats
+	err   error
+}
+
+// ingestChunk は chunk の銘柄の日足を取得して保存し、chunk と同じ順で銘柄ごとの結果を返します。
+// 1 銘柄なら ingestSymbol と同じです。複数銘柄は 1 回の GetTimeSeriesBatch で取得し、銘柄ごとに storeDaily で保存します。
+// 保存の前にメンテナンスモードを確認し、有効になっていれば残りの銘柄を ErrIngestPaused にします。
+func (iu *IngestUsecase) ingestChunk(ctx context.Context, run ingestRun, chunk []ActiveSymbol) []ingestOutcome {
+	out := make([]ingestOutcome, len(chunk))
+	if len(chunk) == 1 {
+		out[0].stats, out[0].err = iu.ingestSymbol(ctx, run, chunk[0], true)
+		return out
+	}
+
+	locs := make(map[string]*time.Location, len(chunk))
+	codes := make([]string, 0, len(chunk))
+	for i, sym := range chunk {
+		loc, err := loadSymbolLocation(sym)
+		if err != nil {
+			out[i].err = err
+			continue
+		}
+		locs[sym.Code] = loc
+		codes = append(codes, sym.Code)
+	}
+	if len(codes) == 0 {
+		return out
+	}
+
+	market := iu.market.(BatchMarketRepository)
+	series, err := market.GetTimeSeriesBatch(ctx, codes, "1day", ClampOutputSize(iu.market, run.outputsize), locs)
+	var symErrs BatchSymbolErrors
+	if err != nil && !errors.As(err, &symErrs) {
+		for i := range out {
+			if out[i].err == nil {
+				out[i].err = err
+			}
+		}
+		return out
+	}
+
+	for i, sym := range chunk {
+		if out[i].err != nil {
+			continue
+		}
+		if err := symErrs[sym.Code]; err != nil {
+			out[i].err = err
+			continue
+		}
+		daily, ok := series[sym.Code]
+		if !ok {
+			out[i].err = fmt.Errorf("time series for %s missing from batch response", sym.Code)
+			continue
+		}
+		if iu.paused(ctx) {
+			for j := i; j < len(out); j++ {
+				out[j] = ingestOutcome{err: ErrIngestPaused}
+			}
+			return out
+		}
+		out[i].stats, out[i].err = iu.storeDaily(ctx, sym, locs[sym.Code], daily, run.intervals, true)
+	}
+	return out
+}
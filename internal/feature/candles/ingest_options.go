@@ -0,0 +1,88 @@
+package candles
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
+)
+
+// ErrInvalidIngestOptions は IngestOptions の指定が不正（未知の時間間隔・範囲外の件数・アクティブでない銘柄）な場合のエラーです。
+var ErrInvalidIngestOptions = apperr.New(apperr.KindInvalid, "invalid_ingest_options", "invalid ingest options")
+
+// IngestOptions は 1 回の IngestAll の対象を絞り込みます。ゼロ値は従来どおりの取り込み
+// （3 種の時間間隔・OutputSizePolicy の日足の上限・DB のアクティブな全銘柄）です。
+// 取引時間中の日足だけの短い更新と、夜間の全件の取り込みを同じバッチで使い分けるために使います。
+type IngestOptions struct {
+	// Intervals は保存する時間間隔（1day / 1week / 1month）です。空なら 3 種すべて。
+	// 週足・月足は日足から集計するため、指定に関わらず外部APIからは日足を取得します。
+	Intervals []string
+	// OutputSize は取得する日足の件数（1 以上 MaxOutputSize 以下）です。0 なら OutputSizePolicy の日足の上限。
+	OutputSize int
+	// Symbols は取り込む銘柄コードです。空なら DB のアクティブな全銘柄。
+	// 市場・タイムゾーン・優先度はアクティブ銘柄の一覧から引くため、アクティブでない銘柄はエラーです。
+	// 一部の銘柄だけの取り込みは市場全体の鮮度を表さないため、鮮度マーカーを更新しません。
+	Symbols []string
+}
+
+// Validate は時間間隔・件数を検証します（銘柄の存在は IngestAll がアクティブ銘柄の一覧と突き合わせます）。
+func (o IngestOptions) Validate() error {
+	for _, interval := range o.Intervals {
+		if !slices.Contains(ingestIntervals, interval) {
+			return fmt.Errorf("%w: unknown interval %q: want one of %s", ErrInvalidIngestOptions, interval, strings.Join(ingestIntervals, ", "))
+		}
+	}
+	if o.OutputSize < 0 || o.OutputSize > MaxOutputSize {
+		return fmt.Errorf("%w: outputsize must be in [1, %d]", ErrInvalidIngestOptions, MaxOutputSize)
+	}
+	return nil
+}
+
+// ingestRun は IngestOptions を既定値で補った 1 回の取り込みの設定です。
+type ingestRun struct {
+	intervals  []string
+	outputsize int
+	// partial は銘柄を絞り込んだ実行か（鮮度マーカーを更新しない）
+	partial bool
+}
+
+// newIngestRun は opts の省略された項目を iu の設定で補います。
+func (iu *IngestUsecase) newIngestRun(opts IngestOptions) ingestRun {
+	run := ingestRun{intervals: ingestIntervals, outputsize: iu.fetchOutputSize(), partial: len(opts.Symbols) > 0}
+	if len(opts.Intervals) > 0 {
+		run.intervals = opts.Intervals
+	}
+	if opts.OutputSize > 0 {
+		run.outputsize = opts.OutputSize
+	}
+	return run
+}
+
+// selectSymbols は active から codes の銘柄を codes の順に選びます（重複は 1 つにまとめます）。
+// アクティブ銘柄にないコードがあれば ErrInvalidIngestOptions を返します。
+func selectSymbols(active []ActiveSymbol, codes []string) ([]ActiveSymbol, error) {
+	byCode := make(map[string]ActiveSymbol, len(active))
+	for _, s := range active {
+		byCode[s.Code] = s
+	}
+	out := make([]ActiveSymbol, 0, len(codes))
+	var unknown []string
+	seen := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		s, ok := byCode[code]
+		if !ok {
+			unknown = append(unknown, code)
+			continue
+		}
+		out = append(out, s)
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("%w: not active symbols: %s", ErrInvalidIngestOptions, strings.Join(unknown, ", "))
+	}
+	return out, nil
+}
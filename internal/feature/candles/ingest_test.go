@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -15,16 +17,21 @@ var ErrDB = errors.New("database error")
 var ErrMarketAPI = errors.New("market API error")
 
 // mockWriteRepository はWriteRepositoryインターフェースのモック実装です。
+// 成功時は Stats を行数として返します。
 type mockWriteRepository struct {
 	UpsertBatchFunc func(ctx context.Context, candles []Candle) error
+	Stats           UpsertStats
 }
 
 // UpsertBatch はUpsertBatchFuncが設定されていればそれを呼び出します。
-func (m *mockWriteRepository) UpsertBatch(ctx context.Context, candles []Candle) error {
-	if m.UpsertBatchFunc != nil {
-		return m.UpsertBatchFunc(ctx, candles)
+func (m *mockWriteRepository) UpsertBatch(ctx context.Context, candles []Candle) (UpsertStats, error) {
+	if m.UpsertBatchFunc == nil {
+		return UpsertStats{}, errors.New("UpsertBatchFunc is not implemented")
 	}
-	return errors.New("UpsertBatchFunc is not implemented")
+	if err := m.UpsertBatchFunc(ctx, candles); err != nil {
+		return UpsertStats{}, err
+	}
+	return m.Stats, nil
 }
 
 // mockMarketRepository はMarketRepositoryインターフェースのモック実装です。
@@ -55,6 +62,37 @@ func (m *mockSymbolRepository) ListActiveSymbols(ctx context.Context) ([]ActiveS
 	return nil, errors.New("ListActiveSymbolsFunc is not implemented")
 }
 
+// freshnessRecord は mockFreshnessWriter が記録した1件の書き込みです。
+type freshnessRecord struct {
+	Interval string
+	Market   string
+	Success  bool
+	ErrMsg   string
+}
+
+// mockFreshnessWriter はFreshnessWriterインターフェースのモック実装です。
+type mockFreshnessWriter struct {
+	Records          []freshnessRecord
+	FailureAllCalls  int
+	FailureAllErrMsg string
+}
+
+func (m *mockFreshnessWriter) RecordSuccess(ctx context.Context, interval, market string, at time.Time) error {
+	m.Records = append(m.Records, freshnessRecord{Interval: interval, Market: market, Success: true})
+	return nil
+}
+
+func (m *mockFreshnessWriter) RecordFailure(ctx context.Context, interval, market string, at time.Time, errMsg string) error {
+	m.Records = append(m.Records, freshnessRecord{Interval: interval, Market: market, ErrMsg: errMsg})
+	return nil
+}
+
+func (m *mockFreshnessWriter) RecordFailureAll(ctx context.Context, at time.Time, errMsg string) error {
+	m.FailureAllCalls++
+	m.FailureAllErrMsg = errMsg
+	return nil
+}
+
 // activeSymbolsFromCodes は文字列配列を Asia/Tokyo TZ の ActiveSymbol 配列に変換します（テスト用ヘルパ）。
 func activeSymbolsFromCodes(codes []string) []ActiveSymbol {
 	out := make([]ActiveSymbol, len(codes))
@@ -175,8 +213,8 @@ func TestIngestUsecase_ingestOne(t *testing.T) {
 	// 2022-12-31（土）と 2023-01-01（日）は同一 ISO 週（2022-W52）かつ異なる月
 	testTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
 	mockDailyCandles := []Candle{
-		{Time: testTime, Open: 100, High: 110, Low: 90, Close: 105},
-		{Time: testTime.AddDate(0, 0, -1), Open: 95, High: 105, Low: 85, Close: 100},
+		{Time: testTime, Open: 100, High: 110, Low: 90, Close: 105, Source: SourceTwelveData},
+		{Time: testTime.AddDate(0, 0, -1), Open: 95, High: 105, Low: 85, Close: 100, Source: SourceTwelveData},
 	}
 
 	testCases := []struct {
@@ -215,6 +253,14 @@ func TestIngestUsecase_ingestOne(t *testing.T) {
 					if c.SymbolCode != "AAPL" {
 						t.Errorf("candle SymbolCode not set: got %s, want AAPL", c.SymbolCode)
 					}
+					// 日足はプロバイダーの取り込み元のまま、集計した週足・月足は derived
+					wantSource := SourceDerived
+					if c.Interval == "1day" {
+						wantSource = SourceTwelveData
+					}
+					if c.Source != wantSource {
+						t.Errorf("%s candle Source: got %q, want %q", c.Interval, c.Source, wantSource)
+					}
 					counts[c.Interval]++
 				}
 				if counts["1day"] != 2 {
@@ -270,8 +316,8 @@ func TestIngestUsecase_ingestOne(t *testing.T) {
 			mockRL := &mockRateLimiter{}
 			mockSymbol := &mockSymbolRepository{}
 
-			uc := NewIngestUsecase(mockMarket, mockCandle, mockSymbol, mockRL)
-			err := uc.ingestOne(ctx, ActiveSymbol{Code: tc.inputSymbol, Timezone: "Asia/Tokyo"}, tc.inputOutputsize)
+			uc := NewIngestUsecase(mockMarket, mockCandle, mockSymbol, mockRL, &mockFreshnessWriter{})
+			_, err := uc.ingestOne(ctx, ActiveSymbol{Code: tc.inputSymbol, Timezone: "Asia/Tokyo"}, tc.inputOutputsize, true)
 
 			if tc.expectedErr == nil {
 				if err != nil {
@@ -292,6 +338,118 @@ func TestIngestUsecase_ingestOne(t *testing.T) {
 	}
 }
 
+// cappedMarketRepository は outputsize 上限を公開する（OutputSizeCapper を実装した）モックです。
+type cappedMarketRepository struct {
+	mockMarketRepository
+	max int
+}
+
+func (m *cappedMarketRepository) MaxOutputSize() int { return m.max }
+
+// TestIngestUsecase_ingestOne_ClampsOutputSize はプロバイダ上限を超える outputsize が
+// 失敗ではなく上限に丸めて要求されることを検証します。
+func TestIngestUsecase_ingestOne_ClampsOutputSize(t *testing.T) {
+	var requested int
+	market := &cappedMarketRepository{max: 1000}
+	market.GetTimeSeriesFunc = func(ctx context.Context, symbol, interval string, outputsize int, loc *time.Location) ([]Candle, error) {
+		requested = outputsize
+		return nil, nil
+	}
+	candle := &mockWriteRepository{UpsertBatchFunc: func(ctx context.Context, candles []Candle) error { return nil }}
+
+	uc := NewIngestUsecase(market, candle, &mockSymbolRepository{}, &mockRateLimiter{}, &mockFreshnessWriter{})
+	if _, err := uc.ingestOne(context.Background(), ActiveSymbol{Code: "AAPL", Timezone: "America/New_York"}, 5000, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requested != 1000 {
+		t.Errorf("requested outputsize = %d, want clamped 1000", requested)
+	}
+}
+
+// observerFunc は関数を IngestObserver として使うアダプターです。
+type observerFunc func(ctx context.Context, symbol string, candles []Candle) error
+
+func (f observerFunc) CandlesIngested(ctx context.Context, symbol string, candles []Candle) error {
+	return f(ctx, symbol, candles)
+}
+
+// TestIngestUsecase_ingestOne_NotifiesObserver は保存後に保存した足が observer に渡され、
+// observer の失敗では取り込みを失敗にしないこと、保存に失敗した場合は通知しないことを検証します。
+func TestIngestUsecase_ingestOne_NotifiesObserver(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	market := &mockMarketRepository{GetTimeSeriesFunc: func(ctx context.Context, symbol, interval string, outputsize int, loc *time.Location) ([]Candle, error) {
+		return []Candle{{Time: day, Close: 100}, {Time: day.AddDate(0, 0, 1), Close: 110}}, nil
+	}}
+
+	for _, tc := range []struct {
+		name        string
+		upsertErr   error
+		observerErr error
+		wantErr     bool
+		wantCalls   int
+	}{
+		{name: "notified after save", wantCalls: 1},
+		{name: "observer failure does not fail ingest", observerErr: errors.New("boom"), wantCalls: 1},
+		{name: "not notified when save fails", upsertErr: errors.New("db down"), wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			var saved, observed []Candle
+			calls := 0
+			candle := &mockWriteRepository{UpsertBatchFunc: func(ctx context.Context, candles []Candle) error {
+				saved = candles
+				return tc.upsertErr
+			}}
+			uc := NewIngestUsecase(market, candle, &mockSymbolRepository{}, &mockRateLimiter{}, &mockFreshnessWriter{}).
+				WithObserver(observerFunc(func(ctx context.Context, symbol string, candles []Candle) error {
+					calls++
+					if symbol != "AAPL" {
+						t.Errorf("symbol = %q, want AAPL", symbol)
+					}
+					observed = candles
+					return tc.observerErr
+				}))
+
+			_, err := uc.ingestOne(context.Background(), ActiveSymbol{Code: "AAPL", Timezone: "UTC"}, 5000, true)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tc.wantErr)
+			}
+			if calls != tc.wantCalls {
+				t.Fatalf("observer called %d times, want %d", calls, tc.wantCalls)
+			}
+			if calls > 0 && !reflect.DeepEqual(observed, saved) {
+				t.Errorf("observer got %v, want the saved candles %v", observed, saved)
+			}
+		})
+	}
+}
+
+func TestClampOutputSize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		market MarketRepository
+		in     int
+		want   int
+	}{
+		{name: "上限超過は丸める", market: &cappedMarketRepository{max: 5000}, in: 6000, want: 5000},
+		{name: "上限以内はそのまま", market: &cappedMarketRepository{max: 5000}, in: 200, want: 200},
+		{name: "上限 0 は無制限", market: &cappedMarketRepository{max: 0}, in: 6000, want: 6000},
+		{name: "OutputSizeCapper 未実装はそのまま", market: &mockMarketRepository{}, in: 6000, want: 6000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := ClampOutputSize(tt.market, tt.in); got != tt.want {
+				t.Errorf("ClampOutputSize(%d) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
 // TestIngestUsecase_IngestAll はIngestAllメソッドの全銘柄処理をテストします。
 func TestIngestUsecase_IngestAll(t *testing.T) {
 	ctx := context.Background()
@@ -440,8 +598,8 @@ func TestIngestUsecase_IngestAll(t *testing.T) {
 			}
 			mockRL := &mockRateLimiter{}
 
-			uc := NewIngestUsecase(mockMarket, mockCandle, mockSymbol, mockRL)
-			result, err := uc.IngestAll(ctx)
+			uc := NewIngestUsecase(mockMarket, mockCandle, mockSymbol, mockRL, &mockFreshnessWriter{})
+			result, err := uc.IngestAll(ctx, IngestOptions{})
 
 			if tc.expectedErr == nil {
 				if err != nil {
@@ -472,6 +630,126 @@ func TestIngestUsecase_IngestAll(t *testing.T) {
 	}
 }
 
+// TestIngestUsecase_IngestAll_AggregatesUpsertStats は成功した銘柄の Upsert 行数のみが
+// IngestResult の Inserted / Updated に集計されることを検証します。
+func TestIngestUsecase_IngestAll_AggregatesUpsertStats(t *testing.T) {
+	testTime := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	market := &mockMarketRepository{GetTimeSeriesFunc: func(ctx context.Context, symbol, interval string, outputsize int, loc *time.Location) ([]Candle, error) {
+		if symbol == "INVALID" {
+			return nil, ErrMarketAPI
+		}
+		return []Candle{{Time: testTime, Open: 100, High: 110, Low: 90, Close: 105}}, nil
+	}}
+	candle := &mockWriteRepository{
+		UpsertBatchFunc: func(ctx context.Context, candles []Candle) error { return nil },
+		Stats:           UpsertStats{Inserted: 3, Updated: 2},
+	}
+	symbol := &mockSymbolRepository{ListActiveSymbolsFunc: func(ctx context.Context) ([]ActiveSymbol, error) {
+		return activeSymbolsFromCodes([]string{"AAPL", "INVALID", "GOOG"}), nil
+	}}
+
+	uc := NewIngestUsecase(market, candle, symbol, &mockRateLimiter{}, &mockFreshnessWriter{})
+	result, err := uc.IngestAll(context.Background(), IngestOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Inserted != 6 || result.Updated != 4 {
+		t.Errorf("result Inserted=%d Updated=%d, want Inserted=6 Updated=4", result.Inserted, result.Updated)
+	}
+}
+
+// TestIngestUsecase_IngestAll_CollectsSymbolErrors は失敗した銘柄とその原因が失敗した順で Errors に集約され、
+// 所要時間が Duration に記録されることを検証します。
+func TestIngestUsecase_IngestAll_CollectsSymbolErrors(t *testing.T) {
+	testTime := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	market := &mockMarketRepository{GetTimeSeriesFunc: func(ctx context.Context, symbol, interval string, outputsize int, loc *time.Location) ([]Candle, error) {
+		if symbol == "INVALID" {
+			return nil, ErrMarketAPI
+		}
+		return []Candle{{Time: testTime, Open: 100, High: 110, Low: 90, Close: 105}}, nil
+	}}
+	candle := &mockWriteRepository{UpsertBatchFunc: func(ctx context.Context, candles []Candle) error {
+		if len(candles) > 0 && candles[0].SymbolCode == "GOOG" {
+			return ErrDB
+		}
+		return nil
+	}}
+	symbol := &mockSymbolRepository{ListActiveSymbolsFunc: func(ctx context.Context) ([]ActiveSymbol, error) {
+		return activeSymbolsFromCodes([]string{"AAPL", "INVALID", "MSFT", "GOOG"}), nil
+	}}
+
+	uc := NewIngestUsecase(market, candle, symbol, &mockRateLimiter{}, &mockFreshnessWriter{})
+	clock := testTime
+	uc.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+	result, err := uc.IngestAll(context.Background(), IngestOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Errors) != result.Failed {
+		t.Fatalf("len(result.Errors)=%d, want Failed=%d", len(result.Errors), result.Failed)
+	}
+	want := []struct {
+		symbol string
+		err    error
+	}{{"INVALID", ErrMarketAPI}, {"GOOG", ErrDB}}
+	if len(result.Errors) != len(want) {
+		t.Fatalf("result.Errors=%v, want %d entries", result.Errors, len(want))
+	}
+	for i, w := range want {
+		if got := result.Errors[i]; got.Symbol != w.symbol || !errors.Is(got.Err, w.err) {
+			t.Errorf("result.Errors[%d]={%s %v}, want {%s %v}", i, got.Symbol, got.Err, w.symbol, w.err)
+		}
+	}
+	if got := result.FailedSymbols(); len(got) != 2 || got[0] != "INVALID" || got[1] != "GOOG" {
+		t.Errorf("FailedSymbols()=%v, want [INVALID GOOG]", got)
+	}
+	if result.Duration <= 0 {
+		t.Errorf("result.Duration=%v, want > 0", result.Duration)
+	}
+}
+
+// TestIngestUsecase_IngestAll_OutputSizePolicy は取得する日足の件数が OutputSizePolicy の日足の上限に従うことを検証します。
+func TestIngestUsecase_IngestAll_OutputSizePolicy(t *testing.T) {
+	custom, err := NewOutputSizePolicy(map[string]OutputSizeLimit{"1day": {Default: 100, Max: 1500}})
+	if err != nil {
+		t.Fatalf("NewOutputSizePolicy: %v", err)
+	}
+	tests := []struct {
+		name   string
+		policy *OutputSizePolicy
+		want   int
+	}{
+		{name: "未設定なら組み込みの日足の上限", want: MaxOutputSize},
+		{name: "設定した日足の上限", policy: &custom, want: 1500},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var requested int
+			market := &mockMarketRepository{GetTimeSeriesFunc: func(ctx context.Context, symbol, interval string, outputsize int, loc *time.Location) ([]Candle, error) {
+				requested = outputsize
+				return nil, nil
+			}}
+			symbol := &mockSymbolRepository{ListActiveSymbolsFunc: func(ctx context.Context) ([]ActiveSymbol, error) {
+				return activeSymbolsFromCodes([]string{"AAPL"}), nil
+			}}
+			uc := NewIngestUsecase(market, &mockWriteRepository{}, symbol, &mockRateLimiter{}, &mockFreshnessWriter{})
+			if tc.policy != nil {
+				uc.WithOutputSizePolicy(*tc.policy)
+			}
+			if _, err := uc.IngestAll(context.Background(), IngestOptions{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if requested != tc.want {
+				t.Errorf("requested outputsize = %d, want %d", requested, tc.want)
+			}
+		})
+	}
+}
+
 // TestIngestUsecase_IngestAll_MidLoopFatal はループ途中で発生する致命的エラー
 // （ctx キャンセル、rateLimiter 失敗）が部分集計と共に error を返すことを検証します。
 func TestIngestUsecase_IngestAll_MidLoopFatal(t *testing.T) {
@@ -504,8 +782,8 @@ func TestIngestUsecase_IngestAll_MidLoopFatal(t *testing.T) {
 		}
 		mockRL := &mockRateLimiter{}
 
-		uc := NewIngestUsecase(mockMarket, mockCandle, mockSymbol, mockRL)
-		result, err := uc.IngestAll(ctx)
+		uc := NewIngestUsecase(mockMarket, mockCandle, mockSymbol, mockRL, &mockFreshnessWriter{})
+		result, err := uc.IngestAll(ctx, IngestOptions{})
 
 		if !errors.Is(err, context.Canceled) {
 			t.Fatalf("err=%v, want context.Canceled", err)
@@ -520,6 +798,12 @@ func TestIngestUsecase_IngestAll_MidLoopFatal(t *testing.T) {
 		if result.Failed != 0 {
 			t.Errorf("result.Failed=%d, want 0", result.Failed)
 		}
+		if result.Aborted != 2 {
+			t.Errorf("result.Aborted=%d, want 2", result.Aborted)
+		}
+		if want := "aborted after 1 of 3 items due to context cancellation"; !strings.Contains(err.Error(), want) {
+			t.Errorf("err=%q, want to contain %q", err.Error(), want)
+		}
 		// 部分集計は exit コード判定で問題ないこと（Failed=0 なので失敗率は 0）
 		if rate := result.FailureRate(); rate != 0 {
 			t.Errorf("FailureRate()=%v, want 0 (no symbol-level failures occurred)", rate)
@@ -552,8 +836,8 @@ func TestIngestUsecase_IngestAll_MidLoopFatal(t *testing.T) {
 			},
 		}
 
-		uc := NewIngestUsecase(mockMarket, mockCandle, mockSymbol, mockRL)
-		result, err := uc.IngestAll(ctx)
+		uc := NewIngestUsecase(mockMarket, mockCandle, mockSymbol, mockRL, &mockFreshnessWriter{})
+		result, err := uc.IngestAll(ctx, IngestOptions{})
 
 		if !errors.Is(err, errRateLimit) {
 			t.Fatalf("err=%v, want errRateLimit", err)
@@ -570,6 +854,73 @@ func TestIngestUsecase_IngestAll_MidLoopFatal(t *testing.T) {
 	})
 }
 
+// TestIngestUsecase_IngestAll_ContextAbortDuringFetch は取得中に ctx が切れた場合、
+// 残りの銘柄へ API を呼ばずに打ち切り、失敗ではなく中断として分類することを検証します。
+func TestIngestUsecase_IngestAll_ContextAbortDuringFetch(t *testing.T) {
+	testCases := []struct {
+		name       string
+		newContext func() (context.Context, context.CancelFunc)
+		// expire は取得中に ctx を終了させる（キャンセルする、または期限切れを待つ）
+		expire     func(ctx context.Context, cancel context.CancelFunc)
+		wantErr    error
+		wantReason string
+	}{
+		{
+			name:       "cancelled",
+			newContext: func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			expire:     func(ctx context.Context, cancel context.CancelFunc) { cancel() },
+			wantErr:    context.Canceled,
+			wantReason: "context cancellation",
+		},
+		{
+			name: "deadline exceeded",
+			newContext: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 20*time.Millisecond)
+			},
+			expire:     func(ctx context.Context, cancel context.CancelFunc) { <-ctx.Done() },
+			wantErr:    context.DeadlineExceeded,
+			wantReason: "context deadline",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := tc.newContext()
+			defer cancel()
+
+			var marketCalls int
+			mockMarket := &mockMarketRepository{
+				GetTimeSeriesFunc: func(ctx context.Context, symbol, interval string, outputsize int, loc *time.Location) ([]Candle, error) {
+					marketCalls++
+					tc.expire(ctx, cancel)
+					return nil, fmt.Errorf("twelvedata request: %w", ctx.Err())
+				},
+			}
+			mockSymbol := &mockSymbolRepository{
+				ListActiveSymbolsFunc: func(ctx context.Context) ([]ActiveSymbol, error) {
+					return activeSymbolsFromCodes([]string{"AAPL", "GOOG", "MSFT"}), nil
+				},
+			}
+
+			uc := NewIngestUsecase(mockMarket, &mockWriteRepository{}, mockSymbol, &mockRateLimiter{}, &mockFreshnessWriter{})
+			result, err := uc.IngestAll(ctx, IngestOptions{})
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("err=%v, want %v", err, tc.wantErr)
+			}
+			if marketCalls != 1 {
+				t.Errorf("market calls=%d, want 1", marketCalls)
+			}
+			if result.Succeeded != 0 || result.Failed != 0 || result.Aborted != 3 {
+				t.Errorf("result=%+v, want Succeeded=0 Failed=0 Aborted=3", result)
+			}
+			if want := "aborted after 0 of 3 items due to " + tc.wantReason; !strings.Contains(err.Error(), want) {
+				t.Errorf("err=%q, want to contain %q", err.Error(), want)
+			}
+		})
+	}
+}
+
 // TestIngestUsecase_ingestOne_InvalidTimezone は不正な TZ 文字列でエラーが返されることを検証します。
 func TestIngestUsecase_ingestOne_InvalidTimezone(t *testing.T) {
 	ctx := context.Background()
@@ -578,8 +929,8 @@ func TestIngestUsecase_ingestOne_InvalidTimezone(t *testing.T) {
 	mockSymbol := &mockSymbolRepository{}
 	mockRL := &mockRateLimiter{}
 
-	uc := NewIngestUsecase(mockMarket, mockCandle, mockSymbol, mockRL)
-	err := uc.ingestOne(ctx, ActiveSymbol{Code: "AAPL", Timezone: "Not/A_Real_Zone"}, 5000)
+	uc := NewIngestUsecase(mockMarket, mockCandle, mockSymbol, mockRL, &mockFreshnessWriter{})
+	_, err := uc.ingestOne(ctx, ActiveSymbol{Code: "AAPL", Timezone: "Not/A_Real_Zone"}, 5000, true)
 	if err == nil {
 		t.Fatal("expected error for invalid timezone, got nil")
 	}
@@ -607,8 +958,8 @@ func TestIngestUsecase_ingestOne_PassesLocation(t *testing.T) {
 	mockSymbol := &mockSymbolRepository{}
 	mockRL := &mockRateLimiter{}
 
-	uc := NewIngestUsecase(mockMarket, mockCandle, mockSymbol, mockRL)
-	if err := uc.ingestOne(ctx, ActiveSymbol{Code: "AAPL", Timezone: "America/New_York"}, 5000); err != nil {
+	uc := NewIngestUsecase(mockMarket, mockCandle, mockSymbol, mockRL, &mockFreshnessWriter{})
+	if _, err := uc.ingestOne(ctx, ActiveSymbol{Code: "AAPL", Timezone: "America/New_York"}, 5000, true); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if gotLoc == nil || gotLoc.String() != want.String() {
@@ -628,6 +979,7 @@ func TestIngestResult_FailureRate(t *testing.T) {
 		{name: "all failed", result: IngestResult{Total: 10, Failed: 10}, want: 1.0},
 		{name: "20% failure", result: IngestResult{Total: 10, Failed: 2}, want: 0.2},
 		{name: "50% failure", result: IngestResult{Total: 4, Failed: 2}, want: 0.5},
+		{name: "aborted items are not failures", result: IngestResult{Total: 10, Succeeded: 2, Aborted: 8}, want: 0},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -637,3 +989,435 @@ func TestIngestResult_FailureRate(t *testing.T) {
 		})
 	}
 }
+
+// TestIngestUsecase_IngestAll_RecordsFreshness は IngestAll が (interval, market) 単位で
+// 鮮度マーカーを書き込むこと、失敗・中断時も試行として記録することを検証します。
+func TestIngestUsecase_IngestAll_RecordsFreshness(t *testing.T) {
+	testTime := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	okCandles := []Candle{{Time: testTime, Open: 100, High: 110, Low: 90, Close: 105}}
+	symbols := []ActiveSymbol{
+		{Code: "AAPL", Market: "NASDAQ", Timezone: "America/New_York"},
+		{Code: "7203.T", Market: "TSE", Timezone: "Asia/Tokyo"},
+	}
+
+	newUsecase := func(market *mockMarketRepository, rl *mockRateLimiter, fw *mockFreshnessWriter) *IngestUsecase {
+		return NewIngestUsecase(
+			market,
+			&mockWriteRepository{UpsertBatchFunc: func(ctx context.Context, candles []Candle) error { return nil }},
+			&mockSymbolRepository{ListActiveSymbolsFunc: func(ctx context.Context) ([]ActiveSymbol, error) { return symbols, nil }},
+			rl,
+			fw,
+		)
+	}
+
+	// byMarket は市場ごとの書き込みを interval 数分まとめて検証しやすい形にします。
+	byMarket := func(records []freshnessRecord) map[string][]freshnessRecord {
+		out := map[string][]freshnessRecord{}
+		for _, r := range records {
+			out[r.Market] = append(out[r.Market], r)
+		}
+		return out
+	}
+
+	t.Run("all markets succeed", func(t *testing.T) {
+		fw := &mockFreshnessWriter{}
+		market := &mockMarketRepository{GetTimeSeriesFunc: func(ctx context.Context, symbol, interval string, outputsize int, loc *time.Location) ([]Candle, error) {
+			return okCandles, nil
+		}}
+		if _, err := newUsecase(market, &mockRateLimiter{}, fw).IngestAll(context.Background(), IngestOptions{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		got := byMarket(fw.Records)
+		for _, m := range []string{"NASDAQ", "TSE"} {
+			if len(got[m]) != len(ingestIntervals) {
+				t.Fatalf("%s: %d records, want %d", m, len(got[m]), len(ingestIntervals))
+			}
+			for _, r := range got[m] {
+				if !r.Success {
+					t.Errorf("%s/%s: want success, got failure %q", m, r.Interval, r.ErrMsg)
+				}
+			}
+		}
+	})
+
+	t.Run("symbol failure marks only its market as failed", func(t *testing.T) {
+		fw := &mockFreshnessWriter{}
+		market := &mockMarketRepository{GetTimeSeriesFunc: func(ctx context.Context, symbol, interval string, outputsize int, loc *time.Location) ([]Candle, error) {
+			if symbol == "AAPL" {
+				return nil, ErrMarketAPI
+			}
+			return okCandles, nil
+		}}
+		if _, err := newUsecase(market, &mockRateLimiter{}, fw).IngestAll(context.Background(), IngestOptions{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		got := byMarket(fw.Records)
+		for _, r := range got["NASDAQ"] {
+			if r.Success || r.ErrMsg == "" {
+				t.Errorf("NASDAQ/%s: want failure with message, got %+v", r.Interval, r)
+			}
+		}
+		for _, r := range got["TSE"] {
+			if !r.Success {
+				t.Errorf("TSE/%s: want success, got %+v", r.Interval, r)
+			}
+		}
+	})
+
+	t.Run("fatal error mid-loop records attempt for all markets", func(t *testing.T) {
+		fw := &mockFreshnessWriter{}
+		errRateLimit := errors.New("rate limit exceeded")
+		market := &mockMarketRepository{GetTimeSeriesFunc: func(ctx context.Context, symbol, interval string, outputsize int, loc *time.Location) ([]Candle, error) {
+			return okCandles, nil
+		}}
+		rl := &mockRateLimiter{WaitIfNeededFunc: func(ctx context.Context, callCount int) error {
+			if callCount == 2 {
+				return errRateLimit
+			}
+			return nil
+		}}
+		if _, err := newUsecase(market, rl, fw).IngestAll(context.Background(), IngestOptions{}); !errors.Is(err, errRateLimit) {
+			t.Fatalf("err=%v, want errRateLimit", err)
+		}
+
+		if len(fw.Records) != 2*len(ingestIntervals) {
+			t.Fatalf("%d records, want %d", len(fw.Records), 2*len(ingestIntervals))
+		}
+		for _, r := range fw.Records {
+			if r.Success {
+				t.Errorf("%s/%s: want failure after abort, got success", r.Market, r.Interval)
+			}
+		}
+	})
+
+	t.Run("symbol list failure records failure on all existing markers", func(t *testing.T) {
+		fw := &mockFreshnessWriter{}
+		uc := NewIngestUsecase(
+			&mockMarketRepository{},
+			&mockWriteRepository{},
+			&mockSymbolRepository{ListActiveSymbolsFunc: func(ctx context.Context) ([]ActiveSymbol, error) { return nil, ErrDB }},
+			&mockRateLimiter{},
+			fw,
+		)
+		if _, err := uc.IngestAll(context.Background(), IngestOptions{}); !errors.Is(err, ErrDB) {
+			t.Fatalf("err=%v, want ErrDB", err)
+		}
+		if fw.FailureAllCalls != 1 || fw.FailureAllErrMsg == "" {
+			t.Errorf("RecordFailureAll calls=%d msg=%q, want 1 call with message", fw.FailureAllCalls, fw.FailureAllErrMsg)
+		}
+		if len(fw.Records) != 0 {
+			t.Errorf("unexpected per-market records: %+v", fw.Records)
+		}
+	})
+}
+
+// stubMaintenance は呼び出し回数 n で有効・無効を返す MaintenanceChecker です。
+type stubMaintenance struct {
+	calls    int
+	activeAt func(n int) bool
+}
+
+func (s *stubMaintenance) Active(context.Context) bool {
+	s.calls++
+	return s.activeAt(s.calls)
+}
+
+// TestIngestUsecase_IngestAll_Maintenance はメンテナンスモードの間は書き込みを見送り、実行中の切り替えで残りを打ち切ることを検証します。
+func TestIngestUsecase_IngestAll_Maintenance(t *testing.T) {
+	testTime := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	newUsecase := func(candle *mockWriteRepository, symbol *mockSymbolRepository, fw *mockFreshnessWriter) *IngestUsecase {
+		market := &mockMarketRepository{GetTimeSeriesFunc: func(ctx context.Context, symbol, interval string, outputsize int, loc *time.Location) ([]Candle, error) {
+			return []Candle{{Time: testTime, Open: 100, High: 110, Low: 90, Close: 105}}, nil
+		}}
+		return NewIngestUsecase(market, candle, symbol, &mockRateLimiter{}, fw)
+	}
+
+	t.Run("active before start skips everything", func(t *testing.T) {
+		upserts := 0
+		candle := &mockWriteRepository{UpsertBatchFunc: func(ctx context.Context, candles []Candle) error { upserts++; return nil }}
+		symbol := &mockSymbolRepository{ListActiveSymbolsFunc: func(ctx context.Context) ([]ActiveSymbol, error) {
+			return activeSymbolsFromCodes([]string{"AAPL", "GOOG"}), nil
+		}}
+		fw := &mockFreshnessWriter{}
+		uc := newUsecase(candle, symbol, fw).WithMaintenance(&stubMaintenance{activeAt: func(int) bool { return true }})
+
+		_, err := uc.IngestAll(context.Background(), IngestOptions{})
+		if !errors.Is(err, ErrIngestPaused) {
+			t.Fatalf("err = %v, want ErrIngestPaused", err)
+		}
+		if upserts != 0 || symbol.ListActiveSymbolsCalls != 0 {
+			t.Errorf("upserts=%d list calls=%d, want no DB access", upserts, symbol.ListActiveSymbolsCalls)
+		}
+		if len(fw.Records) != 0 || fw.FailureAllCalls != 0 {
+			t.Errorf("freshness written during maintenance: %+v", fw)
+		}
+	})
+
+	t.Run("flipped mid-run aborts the rest", func(t *testing.T) {
+		upserts := 0
+		candle := &mockWriteRepository{UpsertBatchFunc: func(ctx context.Context, candles []Candle) error { upserts++; return nil }}
+		symbol := &mockSymbolRepository{ListActiveSymbolsFunc: func(ctx context.Context) ([]ActiveSymbol, error) {
+			return activeSymbolsFromCodes([]string{"AAPL", "GOOG", "MSFT"}), nil
+		}}
+		fw := &mockFreshnessWriter{}
+		// 開始時と 1 銘柄目の前は無効、2 銘柄目の前に有効になる
+		uc := newUsecase(candle, symbol, fw).WithMaintenance(&stubMaintenance{activeAt: func(n int) bool { return n >= 3 }})
+
+		result, err := uc.IngestAll(context.Background(), IngestOptions{})
+		if !errors.Is(err, ErrIngestPaused) {
+			t.Fatalf("err = %v, want ErrIngestPaused", err)
+		}
+		if result.Succeeded != 1 || result.Aborted != 2 {
+			t.Errorf("result = %+v, want Succeeded=1 Aborted=2", result)
+		}
+		if len(result.Tiers) != 1 || result.Tiers[0].Aborted != 2 {
+			t.Errorf("tiers = %+v, want Aborted=2", result.Tiers)
+		}
+		if upserts != 1 {
+			t.Errorf("upserts = %d, want 1", upserts)
+		}
+		if len(fw.Records) != 0 {
+			t.Errorf("freshness written during maintenance: %+v", fw.Records)
+		}
+	})
+
+	t.Run("inactive ingests normally", func(t *testing.T) {
+		candle := &mockWriteRepository{UpsertBatchFunc: func(ctx context.Context, candles []Candle) error { return nil }}
+		symbol := &mockSymbolRepository{ListActiveSymbolsFunc: func(ctx context.Context) ([]ActiveSymbol, error) {
+			return activeSymbolsFromCodes([]string{"AAPL", "GOOG"}), nil
+		}}
+		uc := newUsecase(candle, symbol, &mockFreshnessWriter{}).WithMaintenance(&stubMaintenance{activeAt: func(int) bool { return false }})
+
+		result, err := uc.IngestAll(context.Background(), IngestOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Succeeded != 2 {
+			t.Errorf("result = %+v, want Succeeded=2", result)
+		}
+	})
+}
+
+// mockBatchMarketRepository は BatchMarketRepository を実装する MarketRepository のモックです。
+type mockBatchMarketRepository struct {
+	mockMarketRepository
+	GetTimeSeriesBatchFunc func(ctx context.Context, symbols []string, interval string, outputsize int, locs map[string]*time.Location) (map[string][]Candle, error)
+	batches                [][]string
+}
+
+func (m *mockBatchMarketRepository) GetTimeSeriesBatch(ctx context.Context, symbols []string, interval string, outputsize int, locs map[string]*time.Location) (map[string][]Candle, error) {
+	m.batches = append(m.batches, symbols)
+	return m.GetTimeSeriesBatchFunc(ctx, symbols, interval, outputsize, locs)
+}
+
+// TestIngestUsecase_IngestAll_BatchSize は WithBatchSize の銘柄数ずつまとめて取得し、
+// 銘柄ごとの失敗と呼び出し全体の失敗を銘柄ごとに集計すること、レートリミッタは銘柄数だけ待つことを検証します。
+func TestIngestUsecase_IngestAll_BatchSize(t *testing.T) {
+	testTime := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	daily := func() []Candle { return []Candle{{Time: testTime, Open: 100, High: 110, Low: 90, Close: 105}} }
+	market := &mockBatchMarketRepository{
+		mockMarketRepository: mockMarketRepository{GetTimeSeriesFunc: func(ctx context.Context, symbol, interval string, outputsize int, loc *time.Location) ([]Candle, error) {
+			return daily(), nil
+		}},
+		GetTimeSeriesBatchFunc: func(ctx context.Context, symbols []string, interval string, outputsize int, locs map[string]*time.Location) (map[string][]Candle, error) {
+			if symbols[0] == "DOWN" {
+				return nil, ErrMarketAPI
+			}
+			out := map[string][]Candle{}
+			symErrs := BatchSymbolErrors{}
+			for _, s := range symbols {
+				if locs[s] == nil {
+					t.Errorf("loc for %s is nil", s)
+				}
+				switch s {
+				case "INVALID":
+					symErrs[s] = ErrMarketAPI
+				case "MISSING":
+				default:
+					out[s] = daily()
+				}
+			}
+			if len(symErrs) > 0 {
+				return out, symErrs
+			}
+			return out, nil
+		},
+	}
+	var upserted []string
+	candle := &mockWriteRepository{UpsertBatchFunc: func(ctx context.Context, candles []Candle) error {
+		upserted = append(upserted, candles[0].SymbolCode)
+		return nil
+	}}
+	symbol := &mockSymbolRepository{ListActiveSymbolsFunc: func(ctx context.Context) ([]ActiveSymbol, error) {
+		return activeSymbolsFromCodes([]string{"AAPL", "INVALID", "MSFT", "MISSING", "DOWN", "GOOG", "TSLA"}), nil
+	}}
+	limiter := &mockRateLimiter{}
+
+	uc := NewIngestUsecase(market, candle, symbol, limiter, &mockFreshnessWriter{}).WithBatchSize(2)
+	result, err := uc.IngestAll(context.Background(), IngestOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantBatches := [][]string{{"AAPL", "INVALID"}, {"MSFT", "MISSING"}, {"DOWN", "GOOG"}}
+	if fmt.Sprint(market.batches) != fmt.Sprint(wantBatches) {
+		t.Errorf("batches = %v, want %v", market.batches, wantBatches)
+	}
+	if market.GetTimeSeriesCalls != 1 {
+		t.Errorf("GetTimeSeriesCalls = %d, want 1 (the last chunk of one symbol)", market.GetTimeSeriesCalls)
+	}
+	if limiter.WaitIfNeededCalls != 7 {
+		t.Errorf("WaitIfNeededCalls = %d, want 7 (one per symbol)", limiter.WaitIfNeededCalls)
+	}
+	if result.Succeeded != 3 || result.Failed != 4 {
+		t.Errorf("result = %+v, want Succeeded=3 Failed=4", result)
+	}
+	if got := result.FailedSymbols(); fmt.Sprint(got) != "[INVALID MISSING DOWN GOOG]" {
+		t.Errorf("FailedSymbols() = %v, want [INVALID MISSING DOWN GOOG]", got)
+	}
+	if fmt.Sprint(upserted) != "[AAPL MSFT TSLA]" {
+		t.Errorf("upserted = %v, want [AAPL MSFT TSLA]", upserted)
+	}
+}
+
+// TestIngestUsecase_chunkSize は一括取得が market の対応と WithBatchSize の両方で有効になることを検証します。
+func TestIngestUsecase_chunkSize(t *testing.T) {
+	batch := &mockBatchMarketRepository{}
+	tests := []struct {
+		name   string
+		market MarketRepository
+		size   int
+		want   int
+	}{
+		{"default is per symbol", batch, 0, 1},
+		{"batch size applies", batch, 8, 8},
+		{"clamped to the max", batch, 500, MaxIngestBatchSize},
+		{"market without batch support", &mockMarketRepository{}, 8, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := NewIngestUsecase(tt.market, &mockWriteRepository{}, &mockSymbolRepository{}, &mockRateLimiter{}, &mockFreshnessWriter{})
+			if tt.size > 0 {
+				uc.WithBatchSize(tt.size)
+			}
+			if got := uc.chunkSize(); got != tt.want {
+				t.Errorf("chunkSize() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestIngestUsecase_IngestAll_Options は IngestOptions で保存する時間間隔・取得件数・銘柄を絞り込めること、
+// 銘柄を絞り込んだ実行では鮮度マーカーを更新しないことを検証します。
+func TestIngestUsecase_IngestAll_Options(t *testing.T) {
+	// 2 週にまたがる日足（週足・月足が集計される）
+	daily := []Candle{
+		{Time: time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC), Open: 100, High: 110, Low: 90, Close: 105},
+		{Time: time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC), Open: 100, High: 110, Low: 90, Close: 105},
+		{Time: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Open: 100, High: 110, Low: 90, Close: 105},
+		{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Open: 100, High: 110, Low: 90, Close: 105},
+	}
+	symbols := []ActiveSymbol{
+		{Code: "AAPL", Market: "NASDAQ", Timezone: "UTC"},
+		{Code: "MSFT", Market: "NASDAQ", Timezone: "UTC", Priority: 1},
+		{Code: "7203.T", Market: "TSE", Timezone: "UTC"},
+	}
+	type fixture struct {
+		uc        *IngestUsecase
+		fetched   []string
+		sizes     []int
+		intervals map[string]bool
+		fw        *mockFreshnessWriter
+	}
+	newFixture := func() *fixture {
+		f := &fixture{intervals: map[string]bool{}, fw: &mockFreshnessWriter{}}
+		market := &mockMarketRepository{GetTimeSeriesFunc: func(ctx context.Context, symbol, interval string, outputsize int, loc *time.Location) ([]Candle, error) {
+			f.fetched = append(f.fetched, symbol)
+			f.sizes = append(f.sizes, outputsize)
+			return append([]Candle(nil), daily...), nil
+		}}
+		candle := &mockWriteRepository{UpsertBatchFunc: func(ctx context.Context, candles []Candle) error {
+			for _, c := range candles {
+				f.intervals[c.Interval] = true
+			}
+			return nil
+		}}
+		symbol := &mockSymbolRepository{ListActiveSymbolsFunc: func(ctx context.Context) ([]ActiveSymbol, error) { return symbols, nil }}
+		f.uc = NewIngestUsecase(market, candle, symbol, &mockRateLimiter{}, f.fw)
+		return f
+	}
+
+	t.Run("empty options keep the full ingest", func(t *testing.T) {
+		f := newFixture()
+		if _, err := f.uc.IngestAll(context.Background(), IngestOptions{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(f.fetched) != 3 || f.sizes[0] != DefaultOutputSizePolicy().Limit("1day").Max {
+			t.Errorf("fetched=%v sizes=%v, want all symbols with the policy max", f.fetched, f.sizes)
+		}
+		if !f.intervals["1day"] || !f.intervals["1week"] || !f.intervals["1month"] {
+			t.Errorf("stored intervals = %v, want all three", f.intervals)
+		}
+		if len(f.fw.Records) != 2*len(ingestIntervals) {
+			t.Errorf("%d freshness records, want %d", len(f.fw.Records), 2*len(ingestIntervals))
+		}
+	})
+
+	t.Run("daily-only refresh", func(t *testing.T) {
+		f := newFixture()
+		if _, err := f.uc.IngestAll(context.Background(), IngestOptions{Intervals: []string{"1day"}, OutputSize: 30}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if f.sizes[0] != 30 {
+			t.Errorf("outputsize = %d, want 30", f.sizes[0])
+		}
+		if !f.intervals["1day"] || f.intervals["1week"] || f.intervals["1month"] {
+			t.Errorf("stored intervals = %v, want only 1day", f.intervals)
+		}
+		for _, r := range f.fw.Records {
+			if r.Interval != "1day" {
+				t.Errorf("freshness recorded for %s, want only 1day", r.Interval)
+			}
+		}
+		if len(f.fw.Records) != 2 {
+			t.Errorf("%d freshness records, want 2 (one per market)", len(f.fw.Records))
+		}
+	})
+
+	t.Run("symbols override the active list", func(t *testing.T) {
+		f := newFixture()
+		result, err := f.uc.IngestAll(context.Background(), IngestOptions{Symbols: []string{"7203.T", "MSFT", "7203.T"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// 優先度の段の順序は従来どおり（MSFT が最優先）
+		if fmt.Sprint(f.fetched) != "[MSFT 7203.T]" {
+			t.Errorf("fetched = %v, want [MSFT 7203.T]", f.fetched)
+		}
+		if result.Total != 2 || result.Succeeded != 2 {
+			t.Errorf("result = %+v, want Total=2 Succeeded=2", result)
+		}
+		if len(f.fw.Records) != 0 {
+			t.Errorf("freshness written for a partial run: %+v", f.fw.Records)
+		}
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		for _, opts := range []IngestOptions{
+			{Intervals: []string{"1h"}},
+			{OutputSize: MaxOutputSize + 1},
+			{Symbols: []string{"AAPL", "NOPE"}},
+		} {
+			f := newFixture()
+			_, err := f.uc.IngestAll(context.Background(), opts)
+			if !errors.Is(err, ErrInvalidIngestOptions) {
+				t.Errorf("IngestAll(%+v) err = %v, want ErrInvalidIngestOptions", opts, err)
+			}
+			if len(f.fetched) != 0 || len(f.fw.Records) != 0 || f.fw.FailureAllCalls != 0 {
+				t.Errorf("IngestAll(%+v) fetched=%v freshness=%+v, want nothing", opts, f.fetched, f.fw)
+			}
+		}
+	})
+}
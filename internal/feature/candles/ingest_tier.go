@@ -0,0 +1,107 @@
+package candles
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultIngestPriority は優先度が未設定（0 以下）の銘柄の取り込み優先度です（symbols.priority の既定値）。
+const DefaultIngestPriority = 3
+
+// errTierBudgetExceeded は段の時間予算を使い切り、残りの銘柄を取り込まなかったことを示します。
+// 鮮度マーカーに記録する失敗理由として使います。
+var errTierBudgetExceeded = errors.New("ingest tier time budget exceeded")
+
+// TierResult は IngestResult の優先度ごとの内訳です。
+// Aborted は ctx 中断または段の時間予算超過で取り込まなかった銘柄数です。
+type TierResult struct {
+	Priority  int
+	Total     int
+	Succeeded int
+	Failed    int
+	Aborted   int
+}
+
+// ingestTier は同じ優先度の銘柄の並びです。
+type ingestTier struct {
+	priority int
+	symbols  []ActiveSymbol
+}
+
+// WithTierBudgets は優先度ごとの時間予算を設定します（キーは優先度）。
+// 予算を使い切った段は残りの銘柄を Aborted に数えて打ち切り、次の段へ進みます。
+// 予算のない段は ctx の期限まで続けます。上位の段を先に処理するため、下位の段に予算を設定しておけば
+// 下位の段が長引いても実行時間の上限内に収まり、上位の段が予算で削られることはありません。
+func (iu *IngestUsecase) WithTierBudgets(budgets map[int]time.Duration) *IngestUsecase {
+	iu.tierBudgets = budgets
+	return iu
+}
+
+// groupByPriority は銘柄を優先度の高い（値の小さい）順の段に分けます。段の中は元の順序を保ちます。
+func groupByPriority(symbols []ActiveSymbol) []ingestTier {
+	sorted := slices.Clone(symbols)
+	slices.SortStableFunc(sorted, func(a, b ActiveSymbol) int {
+		return normalizePriority(a.Priority) - normalizePriority(b.Priority)
+	})
+	var tiers []ingestTier
+	for _, s := range sorted {
+		p := normalizePriority(s.Priority)
+		if n := len(tiers); n == 0 || tiers[n-1].priority != p {
+			tiers = append(tiers, ingestTier{priority: p})
+		}
+		tiers[len(tiers)-1].symbols = append(tiers[len(tiers)-1].symbols, s)
+	}
+	return tiers
+}
+
+func normalizePriority(p int) int {
+	if p <= 0 {
+		return DefaultIngestPriority
+	}
+	return p
+}
+
+// skipTier は時間予算を使い切った段の残り（rest）を Aborted に数え、市場の集計には失敗として記録します。
+func (iu *IngestUsecase) skipTier(rest []ActiveSymbol, budget time.Duration, result *IngestResult, tr *TierResult, tallies map[string]*marketTally) {
+	slog.Warn("ingest tier budget exceeded, skipping remaining symbols",
+		"priority", tr.Priority, "budget", budget.String(), "skipped", len(rest))
+	err := fmt.Errorf("%w (priority %d, budget %s)", errTierBudgetExceeded, tr.Priority, budget)
+	for _, s := range rest {
+		tallies[s.Market].fail(err)
+	}
+	result.Aborted += len(rest)
+	tr.Aborted += len(rest)
+}
+
+// ParseTierBudgets は "2=30m,3=15m" 形式（優先度=期間）の設定を解釈します。空文字は nil を返します。
+func ParseTierBudgets(s string) (map[int]time.Duration, error) {
+	var out map[int]time.Duration
+	for item := range strings.SplitSeq(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		p, d, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid tier budget %q: want PRIORITY=DURATION", item)
+		}
+		priority, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil || priority < 1 {
+			return nil, fmt.Errorf("invalid tier budget %q: priority must be a positive integer", item)
+		}
+		budget, err := time.ParseDuration(strings.TrimSpace(d))
+		if err != nil || budget <= 0 {
+			return nil, fmt.Errorf("invalid tier budget %q: duration must be positive (e.g. 30m)", item)
+		}
+		if out == nil {
+			out = make(map[int]time.Duration)
+		}
+		out[priority] = budget
+	}
+	return out, nil
+}
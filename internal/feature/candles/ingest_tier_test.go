@@ -0,0 +1,201 @@
+package candles
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTierFixture は fetched に取得順の銘柄コードを記録する IngestUsecase を生成します。
+// 時計は now を返し、rateLimiter の待機ごとに step だけ進みます（step が 0 なら進めない）。
+func newTierFixture(symbols []ActiveSymbol, fetched *[]string, step time.Duration, fetch func(ctx context.Context, symbol string) error) (*IngestUsecase, *mockFreshnessWriter) {
+	now := time.Date(2026, 3, 2, 6, 0, 0, 0, time.UTC)
+	market := &mockMarketRepository{GetTimeSeriesFunc: func(ctx context.Context, symbol, interval string, outputsize int, loc *time.Location) ([]Candle, error) {
+		*fetched = append(*fetched, symbol)
+		if fetch != nil {
+			if err := fetch(ctx, symbol); err != nil {
+				return nil, err
+			}
+		}
+		return []Candle{{Time: now, Open: 1, High: 1, Low: 1, Close: 1}}, nil
+	}}
+	candle := &mockWriteRepository{UpsertBatchFunc: func(ctx context.Context, candles []Candle) error { return nil }}
+	symbol := &mockSymbolRepository{ListActiveSymbolsFunc: func(ctx context.Context) ([]ActiveSymbol, error) { return symbols, nil }}
+	rl := &mockRateLimiter{WaitIfNeededFunc: func(ctx context.Context, _ int) error {
+		now = now.Add(step)
+		return nil
+	}}
+	freshness := &mockFreshnessWriter{}
+	uc := NewIngestUsecase(market, candle, symbol, rl, freshness)
+	uc.now = func() time.Time { return now }
+	return uc, freshness
+}
+
+func tierSymbol(code string, priority int) ActiveSymbol {
+	return ActiveSymbol{Code: code, Market: "TSE", Timezone: "Asia/Tokyo", Priority: priority}
+}
+
+// TestIngestUsecase_IngestAll_TierOrder は優先度の高い段から順に、段の中は元の順序で取り込み、
+// 段ごとの内訳を集計することを検証します。優先度 0 は既定の 3 として扱います。
+func TestIngestUsecase_IngestAll_TierOrder(t *testing.T) {
+	symbols := []ActiveSymbol{
+		tierSymbol("LOW", 3), tierSymbol("TOP1", 1), tierSymbol("MID", 2), tierSymbol("TOP2", 1), tierSymbol("UNSET", 0),
+	}
+	var fetched []string
+	uc, _ := newTierFixture(symbols, &fetched, 0, func(_ context.Context, symbol string) error {
+		if symbol == "MID" {
+			return ErrMarketAPI
+		}
+		return nil
+	})
+
+	result, err := uc.IngestAll(context.Background(), IngestOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := []string{"TOP1", "TOP2", "MID", "LOW", "UNSET"}; !reflect.DeepEqual(fetched, want) {
+		t.Errorf("fetch order = %v, want %v", fetched, want)
+	}
+	wantTiers := []TierResult{
+		{Priority: 1, Total: 2, Succeeded: 2},
+		{Priority: 2, Total: 1, Failed: 1},
+		{Priority: 3, Total: 2, Succeeded: 2},
+	}
+	if !reflect.DeepEqual(result.Tiers, wantTiers) {
+		t.Errorf("tiers = %+v, want %+v", result.Tiers, wantTiers)
+	}
+	if result.Total != 5 || result.Succeeded != 4 || result.Failed != 1 {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+// TestIngestUsecase_IngestAll_TightContextKeepsTopTier は実行時間の上限が足りない場合でも、
+// 打ち切られるのは下位の段で、最優先の段は取り込みを終えていることを検証します。
+func TestIngestUsecase_IngestAll_TightContextKeepsTopTier(t *testing.T) {
+	// 下位の段を先に並べ、リポジトリの返す順に依存しないことも確かめる
+	symbols := []ActiveSymbol{
+		tierSymbol("LOW1", 3), tierSymbol("LOW2", 3), tierSymbol("TOP1", 1), tierSymbol("MID", 2), tierSymbol("TOP2", 1),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var fetched []string
+	uc, _ := newTierFixture(symbols, &fetched, 0, func(_ context.Context, _ string) error {
+		if len(fetched) == 3 { // 3 銘柄目の取得後に実行時間の上限に達する
+			cancel()
+		}
+		return nil
+	})
+
+	result, err := uc.IngestAll(ctx, IngestOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if want := []string{"TOP1", "TOP2", "MID"}; !reflect.DeepEqual(fetched, want) {
+		t.Errorf("fetch order = %v, want %v", fetched, want)
+	}
+	wantTiers := []TierResult{
+		{Priority: 1, Total: 2, Succeeded: 2},
+		{Priority: 2, Total: 1, Succeeded: 1},
+		{Priority: 3, Total: 2, Aborted: 2},
+	}
+	if !reflect.DeepEqual(result.Tiers, wantTiers) {
+		t.Errorf("tiers = %+v, want %+v", result.Tiers, wantTiers)
+	}
+	if result.Aborted != 2 {
+		t.Errorf("result.Aborted = %d, want 2", result.Aborted)
+	}
+}
+
+// TestIngestUsecase_IngestAll_TierBudget は時間予算を使い切った段の残りを Aborted に数えて次の段へ進み、
+// 予算のない最優先の段は削られないことを検証します。
+func TestIngestUsecase_IngestAll_TierBudget(t *testing.T) {
+	symbols := []ActiveSymbol{
+		tierSymbol("TOP1", 1), tierSymbol("TOP2", 1), tierSymbol("TOP3", 1),
+		tierSymbol("MID1", 2), tierSymbol("MID2", 2), tierSymbol("MID3", 2), tierSymbol("MID4", 2),
+		tierSymbol("LOW1", 3),
+	}
+	var fetched []string
+	// 待機ごとに 1 分進む時計で、優先度 2 の段は 2 分で打ち切る
+	uc, freshness := newTierFixture(symbols, &fetched, time.Minute, nil)
+	uc.WithTierBudgets(map[int]time.Duration{2: 2 * time.Minute})
+
+	result, err := uc.IngestAll(context.Background(), IngestOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := []string{"TOP1", "TOP2", "TOP3", "MID1", "MID2", "LOW1"}; !reflect.DeepEqual(fetched, want) {
+		t.Errorf("fetch order = %v, want %v", fetched, want)
+	}
+	wantTiers := []TierResult{
+		{Priority: 1, Total: 3, Succeeded: 3},
+		{Priority: 2, Total: 4, Succeeded: 2, Aborted: 2},
+		{Priority: 3, Total: 1, Succeeded: 1},
+	}
+	if !reflect.DeepEqual(result.Tiers, wantTiers) {
+		t.Errorf("tiers = %+v, want %+v", result.Tiers, wantTiers)
+	}
+	if result.Succeeded != 6 || result.Failed != 0 || result.Aborted != 2 {
+		t.Errorf("result = %+v", result)
+	}
+	// 取り込めなかった銘柄がある市場は鮮度マーカーに失敗として記録する
+	for _, r := range freshness.Records {
+		if r.Success || !strings.Contains(r.ErrMsg, "budget exceeded") {
+			t.Errorf("freshness record = %+v, want failure mentioning the tier budget", r)
+		}
+	}
+}
+
+// TestIngestUsecase_IngestAll_TierBudgetDuringFetch は取得中に段の時間予算が切れた場合、
+// その銘柄を失敗ではなく Aborted に数えて次の段へ進むことを検証します。
+func TestIngestUsecase_IngestAll_TierBudgetDuringFetch(t *testing.T) {
+	symbols := []ActiveSymbol{tierSymbol("SLOW", 2), tierSymbol("NEXT", 2), tierSymbol("LOW", 3)}
+	var fetched []string
+	uc, _ := newTierFixture(symbols, &fetched, 0, func(ctx context.Context, symbol string) error {
+		if symbol == "SLOW" {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	})
+	uc.WithTierBudgets(map[int]time.Duration{2: 10 * time.Millisecond})
+
+	result, err := uc.IngestAll(context.Background(), IngestOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"SLOW", "LOW"}; !reflect.DeepEqual(fetched, want) {
+		t.Errorf("fetch order = %v, want %v", fetched, want)
+	}
+	wantTiers := []TierResult{
+		{Priority: 2, Total: 2, Aborted: 2},
+		{Priority: 3, Total: 1, Succeeded: 1},
+	}
+	if !reflect.DeepEqual(result.Tiers, wantTiers) {
+		t.Errorf("tiers = %+v, want %+v", result.Tiers, wantTiers)
+	}
+}
+
+func TestParseTierBudgets(t *testing.T) {
+	t.Parallel()
+
+	got, err := ParseTierBudgets(" 2=30m, 3=1h ,")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := map[int]time.Duration{2: 30 * time.Minute, 3: time.Hour}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got, err := ParseTierBudgets(""); err != nil || got != nil {
+		t.Errorf("empty: got %v, %v; want nil, nil", got, err)
+	}
+	for _, in := range []string{"2", "x=30m", "0=30m", "2=soon", "2=-1m", "2=0s"} {
+		if _, err := ParseTierBudgets(in); err == nil {
+			t.Errorf("ParseTierBudgets(%q): expected error", in)
+		}
+	}
+}
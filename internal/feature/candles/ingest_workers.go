@@ -0,0 +1,176 @@
+package candles
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// MaxIngestConcurrency は段の中で並行して取り込むワーカー数の上限です。
+// 外部 API の呼び出しはレートリミッタで直列化されるため、増やしても速くなるのは保存・集計が重なる分だけです。
+const MaxIngestConcurrency = 16
+
+// WithConcurrency は段の中の銘柄を n 個のワーカーで並行して取り込むよう設定します（1 以下なら順に取り込む）。
+// ワーカーは外部 API の呼び出しごとに rateLimiter で待機するため、上流への呼び出しの頻度は変わりません。
+// 保存（UpsertBatch）や集計の間に次の銘柄の取得を進め、遅い保存でレート制限の枠を無駄にしないために使います。
+// 並行時は段の中の取り込み順と IngestResult.Errors の順序は一定ではありません（段の順序は保ちます）。
+func (iu *IngestUsecase) WithConcurrency(n int) *IngestUsecase {
+	iu.concurrency = min(n, MaxIngestConcurrency)
+	return iu
+}
+
+// workerCount は段ごとに起動するワーカー数です。
+func (iu *IngestUsecase) workerCount() int {
+	return max(iu.concurrency, 1)
+}
+
+// tierRun は 1 つの段の取り込みの状態です。ワーカーはチャンクの先頭の位置を受け取り、結果を mu の下で集計します。
+type tierRun struct {
+	iu        *IngestUsecase
+	ctx       context.Context // IngestAll の ctx（中断の判定に使う）
+	tierCtx   context.Context // 段の時間予算を含む ctx
+	run       ingestRun
+	tier      ingestTier
+	budget    time.Duration
+	hasBudget bool
+	start     time.Time
+	size      int
+
+	mu        sync.Mutex
+	result    *IngestResult
+	tr        *TierResult
+	tallies   map[string]*marketTally
+	done      []bool // 成功または失敗を集計した銘柄（tier.symbols と同じ位置）
+	err       error  // 最初の致命的エラー（ctx の中断・メンテナンスモード・rateLimiter の失敗）
+	exhausted bool   // 段の時間予算を使い切った
+}
+
+// runPool はチャンクを workers 個のワーカーで取り込みます。1 なら呼び出し元の goroutine で順に取り込みます。
+// 致命的エラーか時間予算の超過が起きると、ワーカーは取り込み中のチャンクを集計してから新しいチャンクを取らずに終えます。
+func (t *tierRun) runPool(workers int) {
+	jobs := make(chan int, (len(t.tier.symbols)+t.size-1)/t.size)
+	for i := 0; i < len(t.tier.symbols); i += t.size {
+		jobs <- i
+	}
+	close(jobs)
+
+	workers = min(workers, cap(jobs))
+	if workers <= 1 {
+		t.work(jobs)
+		return
+	}
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Go(func() { t.work(jobs) })
+	}
+	wg.Wait()
+}
+
+func (t *tierRun) work(jobs <-chan int) {
+	for i := range jobs {
+		if t.stopped() {
+			return
+		}
+		t.step(i)
+	}
+}
+
+// stopped は致命的エラーか時間予算の超過で新しいチャンクを取らない状態かを返します。
+func (t *tierRun) stopped() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err != nil || t.exhausted
+}
+
+// stop は最初の致命的エラーを記録します。
+func (t *tierRun) stop(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stopLocked(err)
+}
+
+func (t *tierRun) stopLocked(err error) {
+	if t.err == nil {
+		t.err = err
+	}
+}
+
+func (t *tierRun) exhaust() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.exhausted = true
+}
+
+// step は位置 i から始まるチャンクを取り込んで集計します。
+func (t *tierRun) step(i int) {
+	iu := t.iu
+	chunk := t.tier.symbols[i:min(i+t.size, len(t.tier.symbols))]
+	// WaitIfNeeded は limit 未到達なら cancelled ctx でも nil を返すため、
+	// チャンクごとに明示的に ctx をチェックして早期離脱する。
+	if err := t.ctx.Err(); err != nil {
+		t.stop(err)
+		return
+	}
+	if iu.paused(t.ctx) {
+		t.stop(ErrIngestPaused)
+		return
+	}
+	if t.hasBudget && (t.tierCtx.Err() != nil || iu.now().Sub(t.start) >= t.budget) {
+		t.exhaust()
+		return
+	}
+	// 一括取得でも上流は銘柄ごとに利用枠を消費するため、銘柄数だけ待つ
+	for range chunk {
+		if err := iu.rateLimiter.WaitIfNeeded(t.tierCtx); err != nil {
+			if t.hasBudget && !isContextAbort(t.ctx, err) && isContextAbort(t.tierCtx, err) {
+				t.exhaust()
+				return
+			}
+			t.stop(err)
+			return
+		}
+	}
+
+	outcomes := iu.ingestChunk(t.tierCtx, t.run, chunk)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for j, outcome := range outcomes {
+		s := chunk[j]
+		if err := outcome.err; err != nil {
+			// 取得中に ctx が切れた場合は銘柄の失敗ではなく中断として扱う
+			if isContextAbort(t.ctx, err) || errors.Is(err, ErrIngestPaused) {
+				t.stopLocked(err)
+				return
+			}
+			if t.hasBudget && isContextAbort(t.tierCtx, err) {
+				t.exhausted = true
+				return
+			}
+			// 1銘柄のエラーで処理を停止せず、エラーをログに記録して続行
+			slog.Error("failed to ingest data", "symbol", s.Code, "priority", t.tier.priority, "error", err)
+			t.result.fail(s.Code, err)
+			t.tr.Failed++
+			t.tallies[s.Market].fail(err)
+			t.done[i+j] = true
+			continue
+		}
+		t.result.Succeeded++
+		t.tr.Succeeded++
+		t.result.addStats(outcome.stats)
+		t.done[i+j] = true
+	}
+}
+
+// rest は集計していない（取り込まなかった・中断した）銘柄を段の順に返します。
+func (t *tierRun) rest() []ActiveSymbol {
+	var out []ActiveSymbol
+	for i, s := range t.tier.symbols {
+		if !t.done[i] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
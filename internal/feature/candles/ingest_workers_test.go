@@ -0,0 +1,192 @@
+package candles
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// syncMarket は並行に呼び出せる MarketRepository のフェイクです（取得した銘柄を順に記録します）。
+type syncMarket struct {
+	mu      sync.Mutex
+	fetched []string
+	fetch   func(ctx context.Context, symbol string) error
+}
+
+func (m *syncMarket) GetTimeSeries(ctx context.Context, symbol, interval string, outputsize int, loc *time.Location) ([]Candle, error) {
+	m.mu.Lock()
+	m.fetched = append(m.fetched, symbol)
+	m.mu.Unlock()
+	if m.fetch != nil {
+		if err := m.fetch(ctx, symbol); err != nil {
+			return nil, err
+		}
+	}
+	return []Candle{{Time: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), Open: 1, High: 1, Low: 1, Close: 1}}, nil
+}
+
+// syncRateLimiter は待機の回数を数えるだけのレートリミッタです。
+type syncRateLimiter struct{ calls atomic.Int32 }
+
+func (r *syncRateLimiter) WaitIfNeeded(context.Context) error {
+	r.calls.Add(1)
+	return nil
+}
+
+// TestIngestUsecase_IngestAll_Concurrency はワーカーが保存の間に次の銘柄の取得を進めること
+// （保存が重なること）、銘柄ごとにレートリミッタで待機すること、段の順序を保つことを検証します。
+func TestIngestUsecase_IngestAll_Concurrency(t *testing.T) {
+	symbols := []ActiveSymbol{
+		tierSymbol("A1", 1), tierSymbol("A2", 1), tierSymbol("A3", 1),
+		tierSymbol("B1", 2), tierSymbol("B2", 2), tierSymbol("B3", 2), tierSymbol("BAD", 2),
+	}
+	market := &syncMarket{fetch: func(_ context.Context, symbol string) error {
+		if symbol == "BAD" {
+			return ErrMarketAPI
+		}
+		return nil
+	}}
+
+	// 3 つの保存が同時に進むまで各保存を止める（順に取り込むと 1 つ目で止まり、期限切れになる）
+	var inFlight, maxInFlight atomic.Int32
+	release := make(chan struct{})
+	var once sync.Once
+	candle := &syncWriteRepository{upsert: func(ctx context.Context, cs []Candle) error {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			if m := maxInFlight.Load(); n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		if n >= 3 {
+			once.Do(func() { close(release) })
+		}
+		select {
+		case <-release:
+		case <-time.After(2 * time.Second):
+		}
+		return nil
+	}}
+	symbol := &mockSymbolRepository{ListActiveSymbolsFunc: func(ctx context.Context) ([]ActiveSymbol, error) { return symbols, nil }}
+	rl := &syncRateLimiter{}
+
+	uc := NewIngestUsecase(market, candle, symbol, rl, &mockFreshnessWriter{}).WithConcurrency(3)
+	result, err := uc.IngestAll(context.Background(), IngestOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if maxInFlight.Load() < 3 {
+		t.Errorf("max concurrent upserts = %d, want 3", maxInFlight.Load())
+	}
+	if got := rl.calls.Load(); got != int32(len(symbols)) {
+		t.Errorf("WaitIfNeeded calls = %d, want %d", got, len(symbols))
+	}
+	if result.Succeeded != 6 || result.Failed != 1 || len(result.Tiers) != 2 || result.Tiers[1].Failed != 1 {
+		t.Errorf("result = %+v, want Succeeded=6 Failed=1 in tier 2", result)
+	}
+	// 段の中の順序は一定ではないが、優先度 1 の段を終えてから次の段に進む
+	for i, code := range market.fetched {
+		if (i < 3) != (code[0] == 'A') {
+			t.Errorf("fetched = %v, want the priority 1 tier first", market.fetched)
+			break
+		}
+	}
+}
+
+// TestIngestUsecase_IngestAll_ConcurrencyCancel は並行に取り込み中の ctx の中断で、
+// 取り込み中の銘柄を終えてワーカーが止まり、残りを Aborted に数えることを検証します。
+func TestIngestUsecase_IngestAll_ConcurrencyCancel(t *testing.T) {
+	var symbols []ActiveSymbol
+	for _, code := range []string{"S1", "S2", "S3", "S4", "S5", "S6", "S7", "S8"} {
+		symbols = append(symbols, tierSymbol(code, 1))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var fetches atomic.Int32
+	market := &syncMarket{fetch: func(ctx context.Context, symbol string) error {
+		if fetches.Add(1) == 3 {
+			cancel()
+		}
+		return nil
+	}}
+	symbol := &mockSymbolRepository{ListActiveSymbolsFunc: func(ctx context.Context) ([]ActiveSymbol, error) { return symbols, nil }}
+	fw := &mockFreshnessWriter{}
+
+	uc := NewIngestUsecase(market, &syncWriteRepository{}, symbol, &syncRateLimiter{}, fw).WithConcurrency(2)
+	result, err := uc.IngestAll(ctx, IngestOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if result.Processed()+result.Aborted != len(symbols) || result.Aborted == 0 {
+		t.Errorf("result = %+v, want every symbol processed or aborted", result)
+	}
+	if n := len(market.fetched); n > 4 {
+		t.Errorf("fetched %d symbols after cancel, want at most one in-flight per worker", n)
+	}
+	if len(fw.Records) == 0 || fw.Records[0].Success {
+		t.Errorf("freshness = %+v, want the abort recorded", fw.Records)
+	}
+}
+
+// syncWriteRepository は並行に呼び出せる WriteRepository のフェイクです（upsert が nil なら何もしない）。
+type syncWriteRepository struct {
+	upsert func(ctx context.Context, cs []Candle) error
+}
+
+func (r *syncWriteRepository) UpsertBatch(ctx context.Context, cs []Candle) (UpsertStats, error) {
+	if r.upsert != nil {
+		if err := r.upsert(ctx, cs); err != nil {
+			return UpsertStats{}, err
+		}
+	}
+	return UpsertStats{Inserted: int64(len(cs))}, nil
+}
+
+func TestIngestUsecase_WithConcurrency(t *testing.T) {
+	uc := NewIngestUsecase(&mockMarketRepository{}, &mockWriteRepository{}, &mockSymbolRepository{}, &mockRateLimiter{}, &mockFreshnessWriter{})
+	if got := uc.workerCount(); got != 1 {
+		t.Errorf("default workerCount() = %d, want 1", got)
+	}
+	if got := uc.WithConcurrency(100).workerCount(); got != MaxIngestConcurrency {
+		t.Errorf("workerCount() = %d, want %d", got, MaxIngestConcurrency)
+	}
+	if got := uc.WithConcurrency(0).workerCount(); got != 1 {
+		t.Errorf("workerCount() = %d, want 1", got)
+	}
+}
+
+// blockingRateLimiter は ctx が終了するまで待機し続けるレートリミッタです（利用枠を使い切った状態）。
+type blockingRateLimiter struct{}
+
+func (blockingRateLimiter) WaitIfNeeded(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// TestIngestUsecase_IngestAll_LimiterHonorsDeadline はワーカーがレートリミッタで待機中でも、
+// ctx の期限で IngestAll がすぐに戻り、未処理の銘柄を Aborted に数えることを検証します。
+func TestIngestUsecase_IngestAll_LimiterHonorsDeadline(t *testing.T) {
+	symbols := []ActiveSymbol{tierSymbol("S1", 1), tierSymbol("S2", 1), tierSymbol("S3", 1)}
+	symbol := &mockSymbolRepository{ListActiveSymbolsFunc: func(ctx context.Context) ([]ActiveSymbol, error) { return symbols, nil }}
+	market := &syncMarket{}
+	uc := NewIngestUsecase(market, &syncWriteRepository{}, symbol, blockingRateLimiter{}, &mockFreshnessWriter{}).WithConcurrency(2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	result, err := uc.IngestAll(ctx, IngestOptions{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("IngestAll returned after %v, want shortly after the deadline", elapsed)
+	}
+	if result.Aborted != len(symbols) || len(market.fetched) != 0 {
+		t.Errorf("result = %+v fetched = %v, want every symbol aborted without fetching", result, market.fetched)
+	}
+}
@@ -0,0 +1,201 @@
+package candles
+
+import (
+	"container/list"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// プロセス内キャッシュ（L1）の既定値と上限です。
+const (
+	// DefaultLocalCacheEntries はプロセス内キャッシュに保持するエントリ数の既定の上限です。
+	DefaultLocalCacheEntries = 256
+	// DefaultLocalCacheBytes はプロセス内キャッシュが保持するローソク足の推定サイズの既定の上限です。
+	DefaultLocalCacheBytes = 64 << 20
+	// MaxLocalCacheTTL はプロセス内キャッシュの TTL の上限です。
+	// 他インスタンスでの更新（更新通知が届かなかった場合を含む）が見えない期間をこの時間に抑えます。
+	MaxLocalCacheTTL = 30 * time.Second
+)
+
+// localCacheShards はプロセス内キャッシュのシャード数です（読み取りの排他を銘柄・時間間隔ごとに分散する）。
+const localCacheShards = 16
+
+// candleBytes はローソク足 1 本の固定部分のサイズです（文字列の中身は含まない）。
+const candleBytes = int64(unsafe.Sizeof(Candle{}))
+
+// LocalCacheConfig はプロセス内キャッシュ（L1）の設定です。
+//
+// 少数の人気銘柄に読み取りが集中するため、Redis の往復と JSON のデコードを省くようにデコード済みのローソク足を
+// プロセス内に短い TTL で保持します。Redis の障害中も DB の読み取り結果を保持するため、キャッシュがすべて
+// 失われることはありません。
+type LocalCacheConfig struct {
+	// TTL はエントリを保持する期間です（0 < TTL <= MaxLocalCacheTTL）。0 の場合は無効です。
+	TTL time.Duration
+	// Entries は保持するエントリ数の上限です。0 以下の場合は DefaultLocalCacheEntries を使います。
+	Entries int
+	// Bytes は保持するローソク足の推定サイズの上限です。0 以下の場合は DefaultLocalCacheBytes を使います。
+	Bytes int64
+}
+
+// localEntry はプロセス内キャッシュのエントリです。
+type localEntry struct {
+	key     string // bucketKey
+	group   string // cacheKey（symbol+interval。無効化の単位）
+	candles []Candle
+	size    int64
+	expires time.Time
+}
+
+// localShard はプロセス内キャッシュの 1 シャードです（LRU。先頭が直近に使ったエントリ）。
+type localShard struct {
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+	bytes int64
+}
+
+// localCache はデコード済みのローソク足を保持する、シャードごとの LRU のプロセス内キャッシュです。
+// 同じ symbol+interval のエントリ（件数の区切りごと）は同じシャードに置き、無効化を 1 シャードで済ませます。
+type localCache struct {
+	shards     []*localShard
+	maxEntries int   // シャードごとの上限
+	maxBytes   int64 // シャードごとの上限
+	ttl        time.Duration
+	now        func() time.Time
+	evictions  atomic.Uint64
+}
+
+// newLocalCache は shards 個のシャードに cfg の上限を等分したプロセス内キャッシュを返します。
+// cfg.TTL が 0 以下の場合は nil（無効）を返します。
+func newLocalCache(cfg LocalCacheConfig, shards int) *localCache {
+	if cfg.TTL <= 0 {
+		return nil
+	}
+	if cfg.Entries <= 0 {
+		cfg.Entries = DefaultLocalCacheEntries
+	}
+	if cfg.Bytes <= 0 {
+		cfg.Bytes = DefaultLocalCacheBytes
+	}
+	shards = max(1, min(shards, cfg.Entries))
+	l := &localCache{
+		shards:     make([]*localShard, shards),
+		maxEntries: (cfg.Entries + shards - 1) / shards,
+		maxBytes:   cfg.Bytes / int64(shards),
+		ttl:        min(cfg.TTL, MaxLocalCacheTTL),
+		now:        time.Now,
+	}
+	for i := range l.shards {
+		l.shards[i] = &localShard{ll: list.New(), items: map[string]*list.Element{}}
+	}
+	return l
+}
+
+// shard は group のエントリを置くシャードを返します（FNV-1a。読み取りごとに割り当てないよう hash/fnv を使わない）。
+func (l *localCache) shard(group string) *localShard {
+	h := uint32(2166136261)
+	for i := range len(group) {
+		h ^= uint32(group[i])
+		h *= 16777619
+	}
+	return l.shards[h%uint32(len(l.shards))]
+}
+
+// get は key のエントリを返します。期限切れのエントリは削除してミスとして扱います。
+// 返すスライスはキャッシュと共有しているため、呼び出し元は書き換えずにコピーして返してください。
+func (l *localCache) get(group, key string) ([]Candle, bool) {
+	if l == nil {
+		return nil, false
+	}
+	s := l.shard(group)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.items[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*localEntry)
+	if !l.now().Before(e.expires) {
+		s.remove(el)
+		return nil, false
+	}
+	s.ll.MoveToFront(el)
+	return e.candles, true
+}
+
+// set は cs のコピーを key のエントリとして保存し、上限を超えた分を使われていない順に追い出します。
+// 1 エントリでシャードの上限サイズを超える場合は保存しません。
+func (l *localCache) set(group, key string, cs []Candle) {
+	if l == nil {
+		return
+	}
+	e := &localEntry{key: key, group: group, candles: slices.Clone(cs), size: candlesSize(cs), expires: l.now().Add(l.ttl)}
+	if e.size > l.maxBytes {
+		return
+	}
+	s := l.shard(group)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.items[key]; ok {
+		s.remove(el)
+	}
+	s.items[key] = s.ll.PushFront(e)
+	s.bytes += e.size
+	for s.ll.Len() > l.maxEntries || s.bytes > l.maxBytes {
+		s.remove(s.ll.Back())
+		l.evictions.Add(1)
+	}
+}
+
+// invalidate は group（symbol+interval）のすべてのエントリを削除します。
+func (l *localCache) invalidate(group string) {
+	if l == nil {
+		return
+	}
+	s := l.shard(group)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for el := s.ll.Front(); el != nil; {
+		next := el.Next()
+		if el.Value.(*localEntry).group == group {
+			s.remove(el)
+		}
+		el = next
+	}
+}
+
+// remove は el をシャードから削除します。s.mu を保持して呼びます。
+func (s *localShard) remove(el *list.Element) {
+	e := s.ll.Remove(el).(*localEntry)
+	delete(s.items, e.key)
+	s.bytes -= e.size
+}
+
+// candlesSize は cs の推定サイズ（固定部分と文字列の長さの合計）を返します。
+func candlesSize(cs []Candle) int64 {
+	n := int64(len(cs)) * candleBytes
+	for _, c := range cs {
+		n += int64(len(c.SymbolCode) + len(c.Interval) + len(c.Source))
+	}
+	return n
+}
+
+// WithLocalCache は Redis の手前にプロセス内キャッシュ（L1）を置きます。cfg.TTL が 0 の場合は無効のままです。
+//
+// Find は L1 → Redis（L2）→ DB の順に読み、Redis のヒットと DB の読み取りの結果を L1 に保存します。
+// L1 にはデコード済みのスライスを保持し、ヒット時は JSON をデコードせずコピーを返します。
+// UpsertBatch・Invalidate はこのインスタンスの L1 も削除し、他インスタンスの更新は InvalidateLocal
+// （candle.updated の通知）または TTL（最大 MaxLocalCacheTTL）の経過で反映します。
+func (c *CachingRepository) WithLocalCache(cfg LocalCacheConfig) *CachingRepository {
+	c.local = newLocalCache(cfg, localCacheShards)
+	return c
+}
+
+// InvalidateLocal は symbol+interval のプロセス内キャッシュ（L1）のエントリを削除します。Redis のキャッシュは変更しません。
+// 他のプロセス（batch の ingest 等）でローソク足が更新された通知を受けて呼びます。
+func (c *CachingRepository) InvalidateLocal(symbol, interval string) {
+	c.local.invalidate(c.cacheKey(symbol, interval))
+}
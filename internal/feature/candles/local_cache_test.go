@@ -0,0 +1,253 @@
+package candles
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/testsupport"
+)
+
+// newTestLocalCache は 1 シャード（LRU の順序が決定的）で clock を時計にしたプロセス内キャッシュを返します。
+func newTestLocalCache(cfg LocalCacheConfig, clock *testsupport.Clock) *localCache {
+	l := newLocalCache(cfg, 1)
+	l.now = clock.Now
+	return l
+}
+
+func TestLocalCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+	l := newTestLocalCache(LocalCacheConfig{TTL: 10 * time.Second, Entries: 2}, testsupport.NewClock(time.Now()))
+	cs := newestFirst(3)
+
+	l.set("g:a", "a", cs)
+	l.set("g:b", "b", cs)
+	_, ok := l.get("g:a", "a") // a を直近に使ったエントリにする
+	require.True(t, ok)
+	l.set("g:c", "c", cs)
+
+	_, ok = l.get("g:b", "b")
+	assert.False(t, ok, "最も長く使われていない b を追い出す")
+	_, ok = l.get("g:a", "a")
+	assert.True(t, ok)
+	_, ok = l.get("g:c", "c")
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), l.evictions.Load())
+}
+
+func TestLocalCache_EvictsByBytes(t *testing.T) {
+	t.Parallel()
+	cs := newestFirst(10)
+	l := newTestLocalCache(LocalCacheConfig{TTL: 10 * time.Second, Entries: 100, Bytes: candlesSize(cs)*2 + 1}, testsupport.NewClock(time.Now()))
+
+	l.set("g:a", "a", cs)
+	l.set("g:b", "b", cs)
+	l.set("g:c", "c", cs)
+	_, ok := l.get("g:a", "a")
+	assert.False(t, ok, "推定サイズの上限を超えた分を古い順に追い出す")
+	_, ok = l.get("g:c", "c")
+	assert.True(t, ok)
+
+	l.set("g:big", "big", newestFirst(30))
+	_, ok = l.get("g:big", "big")
+	assert.False(t, ok, "1 エントリで上限を超える場合は保存しない")
+	_, ok = l.get("g:c", "c")
+	assert.True(t, ok, "保存しなかったエントリのために追い出さない")
+}
+
+func TestLocalCache_ExpiresAfterTTL(t *testing.T) {
+	t.Parallel()
+	clock := testsupport.NewClock(time.Date(2026, 5, 12, 0, 0, 0, 0, time.UTC))
+	l := newTestLocalCache(LocalCacheConfig{TTL: 10 * time.Second}, clock)
+	l.set("g", "k", newestFirst(3))
+
+	clock.Advance(9 * time.Second)
+	_, ok := l.get("g", "k")
+	assert.True(t, ok)
+
+	clock.Advance(time.Second)
+	_, ok = l.get("g", "k")
+	assert.False(t, ok, "TTL の経過で期限切れ")
+	assert.Zero(t, l.shards[0].ll.Len(), "期限切れのエントリは読み取りで削除する")
+}
+
+func TestNewLocalCache(t *testing.T) {
+	t.Parallel()
+	assert.Nil(t, newLocalCache(LocalCacheConfig{}, localCacheShards), "TTL 0 は無効")
+
+	l := newLocalCache(LocalCacheConfig{TTL: time.Hour}, localCacheShards)
+	require.NotNil(t, l)
+	assert.Equal(t, MaxLocalCacheTTL, l.ttl, "TTL は上限に切り詰める")
+	assert.Equal(t, DefaultLocalCacheEntries/localCacheShards, l.maxEntries)
+	assert.Len(t, l.shards, localCacheShards)
+}
+
+// newLocalCachedRepo は miniredis と countingInner の上に、時計を clock にしたプロセス内キャッシュを持つ CachingRepository を返します。
+func newLocalCachedRepo(t *testing.T, inner *countingInner, clock *testsupport.Clock) (*CachingRepository, *miniredis.Miniredis) {
+	t.Helper()
+	mr, rdb := testsupport.NewMiniRedis(t)
+	repo := NewCachingRepository(rdb, time.Hour, inner, "candles", nil).
+		WithCacheBuckets(DefaultCacheBuckets).
+		WithLocalCache(LocalCacheConfig{TTL: 10 * time.Second})
+	repo.local.now = clock.Now
+	return repo, mr
+}
+
+// TestCachingRepository_LocalCache_Tiers は L1 → Redis → DB の順に読み、ヒットした層を統計で区別することを検証します。
+func TestCachingRepository_LocalCache_Tiers(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	clock := testsupport.NewClock(time.Now())
+	inner := newCountingInner(newestFirst(300))
+	repo, mr := newLocalCachedRepo(t, inner, clock)
+
+	// DB から読み、L1 と Redis の両方に保存する
+	got, err := repo.Find(ctx, "AAPL", "1day", 30)
+	require.NoError(t, err)
+	assert.Len(t, got, 30)
+	assert.True(t, mr.Exists("candles:AAPL:1day:n50"))
+
+	// L1 のヒットは Redis に問い合わせない
+	commands := mr.CommandCount()
+	got, err = repo.Find(ctx, "AAPL", "1day", 40)
+	require.NoError(t, err)
+	assert.Equal(t, newestFirst(40), got)
+	assert.Equal(t, commands, mr.CommandCount(), "L1 のヒットで Redis に問い合わせない")
+
+	// L1 の期限切れ後は Redis から読み、L1 に戻す
+	clock.Advance(10 * time.Second)
+	_, err = repo.Find(ctx, "AAPL", "1day", 30)
+	require.NoError(t, err)
+	_, err = repo.Find(ctx, "AAPL", "1day", 30)
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{50}, inner.requested, "DB は最初の 1 回だけ")
+	stats := repo.CacheStats()
+	assert.Equal(t, uint64(2), stats.LocalHits)
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.InDelta(t, 0.75, stats.HitRate(), 1e-9)
+}
+
+// TestCachingRepository_LocalCache_CopyOnRead は L1 のヒットで返したスライスを書き換えてもキャッシュに影響しないことを検証します。
+func TestCachingRepository_LocalCache_CopyOnRead(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo, _ := newLocalCachedRepo(t, newCountingInner(newestFirst(100)), testsupport.NewClock(time.Now()))
+
+	first, err := repo.Find(ctx, "AAPL", "1day", 0)
+	require.NoError(t, err)
+	first[0].Close = -1
+
+	hit, err := repo.Find(ctx, "AAPL", "1day", 0)
+	require.NoError(t, err)
+	assert.Equal(t, newestFirst(100)[0].Close, hit[0].Close, "DB の読み取りで返した結果を書き換えても L1 に影響しない")
+	hit[1].Close = -1
+	_ = append(hit[:10], Candle{Close: -2}) // 呼び出し元の append も L1 に影響しない
+
+	again, err := repo.Find(ctx, "AAPL", "1day", 0)
+	require.NoError(t, err)
+	assert.Equal(t, newestFirst(100), again)
+	assert.Equal(t, uint64(2), repo.CacheStats().LocalHits)
+}
+
+// TestCachingRepository_LocalCache_Invalidation は UpsertBatch・Invalidate・InvalidateLocal が L1 のエントリを削除することを検証します。
+func TestCachingRepository_LocalCache_Invalidation(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		invalidate func(t *testing.T, repo *CachingRepository)
+	}{
+		{"UpsertBatch", func(t *testing.T, repo *CachingRepository) {
+			_, err := repo.UpsertBatch(ctx, []Candle{{SymbolCode: "AAPL", Interval: "1day", Time: time.Now()}})
+			require.NoError(t, err)
+		}},
+		{"Invalidate", func(t *testing.T, repo *CachingRepository) {
+			require.NoError(t, repo.Invalidate(ctx, "AAPL", "1day"))
+		}},
+		{"InvalidateLocal", func(_ *testing.T, repo *CachingRepository) {
+			repo.InvalidateLocal("AAPL", "1day")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repo, _ := newLocalCachedRepo(t, newCountingInner(newestFirst(300)), testsupport.NewClock(time.Now()))
+			for _, n := range []int{30, 200} { // 区切りごとのエントリ（n50・n200）
+				_, err := repo.Find(ctx, "AAPL", "1day", n)
+				require.NoError(t, err)
+			}
+			_, err := repo.Find(ctx, "MSFT", "1day", 30)
+			require.NoError(t, err)
+
+			tt.invalidate(t, repo)
+
+			group := repo.cacheKey("AAPL", "1day")
+			for _, n := range []int{50, 200} {
+				_, ok := repo.local.get(group, repo.bucketKey("AAPL", "1day", n))
+				assert.False(t, ok, "n%d は削除する", n)
+			}
+			_, ok := repo.local.get(repo.cacheKey("MSFT", "1day"), repo.bucketKey("MSFT", "1day", 50))
+			assert.True(t, ok, "他の銘柄は残す")
+		})
+	}
+}
+
+// TestCachingRepository_LocalCache_WithoutRedis は Redis が使えない間も DB の読み取り結果を L1 に保持することを検証します。
+func TestCachingRepository_LocalCache_WithoutRedis(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	inner := newCountingInner(newestFirst(100))
+	repo := NewCachingRepository(nil, time.Hour, inner, "candles", nil).WithLocalCache(LocalCacheConfig{TTL: 10 * time.Second})
+
+	for range 3 {
+		got, err := repo.Find(ctx, "AAPL", "1day", 30)
+		require.NoError(t, err)
+		assert.Len(t, got, 30)
+	}
+	assert.Equal(t, []int{MaxOutputSize}, inner.requested)
+	assert.Equal(t, uint64(2), repo.CacheStats().LocalHits)
+}
+
+// TestCachingRepository_LocalCache_Concurrent は読み取り・無効化・追い出しを並行に行っても壊れないことを検証します（-race で実行）。
+func TestCachingRepository_LocalCache_Concurrent(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	stored := newestFirst(100)
+	inner := &mockReadWriteRepository{findFn: func(_ context.Context, _, _ string, outputsize int) ([]Candle, error) {
+		return slicesHead(stored, outputsize), nil
+	}}
+	repo := NewCachingRepository(nil, time.Hour, inner, "candles", nil).
+		WithCacheBuckets(DefaultCacheBuckets).
+		WithLocalCache(LocalCacheConfig{TTL: 10 * time.Second, Entries: 8})
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Go(func() {
+			for i := range 200 {
+				symbol := fmt.Sprintf("S%d", (g+i)%12) // シャードあたりの上限を超えて追い出しも起こす
+				cs, err := repo.Find(ctx, symbol, "1day", 10+i%100)
+				if !assert.NoError(t, err) {
+					return
+				}
+				cs[0].Close = float64(i) // 返したスライスは呼び出し元が自由に書き換えられる
+				if i%17 == 0 {
+					repo.InvalidateLocal(symbol, "1day")
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	got, err := repo.Find(ctx, "S0", "1day", 10)
+	require.NoError(t, err)
+	assert.Equal(t, newestFirst(10), got)
+}
@@ -0,0 +1,376 @@
+package candles
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MarketHealth は市場データ（Twelve Data）連携全体の状態です。
+type MarketHealth string
+
+const (
+	// MarketHealthUnknown は判定に足る直近の記録がない状態です（ingest の実行外など）。
+	MarketHealthUnknown MarketHealth = "unknown"
+	// MarketHealthHealthy は失敗率が低く、連携が正常な状態です。
+	MarketHealthHealthy MarketHealth = "healthy"
+	// MarketHealthDegraded は失敗（エラー・利用枠の枯渇）が目立つものの、取得はおおむね成立している状態です。
+	MarketHealthDegraded MarketHealth = "degraded"
+	// MarketHealthDown はエラーが大半を占め、連携が停止しているとみなす状態です。
+	// この間はキャッシュミス時の fetch-through を止めます（CachingRepository.WithMarketHealth 参照）。
+	MarketHealthDown MarketHealth = "down"
+)
+
+// MarketOutcome は MarketRepository の 1 回の呼び出しの結果の区分です。
+type MarketOutcome int
+
+const (
+	MarketOutcomeSuccess   MarketOutcome = iota // 取得に成功した
+	MarketOutcomeThrottled                      // 利用枠を使い切った（ErrUpstreamThrottled）
+	MarketOutcomeError                          // それ以外のエラー（上流の障害・タイムアウト・不正なレスポンス）
+)
+
+// 市場データ連携の状態判定の既定値です。
+const (
+	DefaultMarketHealthWindow        = 5 * time.Minute
+	DefaultMarketHealthMinSamples    = 10
+	DefaultMarketHealthDegradedEnter = 0.2
+	DefaultMarketHealthDegradedExit  = 0.1
+	DefaultMarketHealthDownEnter     = 0.5
+	DefaultMarketHealthDownExit      = 0.3
+)
+
+// marketHealthBuckets はスライディングウィンドウを分割するバケット数です（ウィンドウの 1/10 の粒度で古い記録を捨てる）。
+const marketHealthBuckets = 10
+
+// MarketHealthConfig は市場データ連携の状態判定の設定です。
+//
+// 状態は直近 Window の呼び出しの失敗率で判定し、状態が行き来しないよう入る閾値と抜ける閾値を分けます（ヒステリシス）。
+//   - degraded: 失敗率（エラー + 利用枠の枯渇）が DegradedEnter 以上で入り、DegradedExit 未満で抜ける
+//   - down: エラー率（利用枠の枯渇を除く）が DownEnter 以上で入り、DownExit 未満で抜ける
+//
+// 利用枠の枯渇は時間をおけば回復するため down の判定には含めません。
+type MarketHealthConfig struct {
+	Window        time.Duration // 集計するスライディングウィンドウの長さ
+	MinSamples    int           // 状態を変えるのに必要な呼び出し数（未満の間は現在の状態を保つ）
+	DegradedEnter float64
+	DegradedExit  float64
+	DownEnter     float64
+	DownExit      float64
+}
+
+// DefaultMarketHealthConfig は既定の状態判定の設定を返します。
+func DefaultMarketHealthConfig() MarketHealthConfig {
+	return MarketHealthConfig{
+		Window:        DefaultMarketHealthWindow,
+		MinSamples:    DefaultMarketHealthMinSamples,
+		DegradedEnter: DefaultMarketHealthDegradedEnter,
+		DegradedExit:  DefaultMarketHealthDegradedExit,
+		DownEnter:     DefaultMarketHealthDownEnter,
+		DownExit:      DefaultMarketHealthDownExit,
+	}
+}
+
+// Validate は閾値が (0, 1] の範囲にあり、抜ける閾値が入る閾値以下かを検証します。
+func (c MarketHealthConfig) Validate() error {
+	if c.Window <= 0 || c.MinSamples <= 0 {
+		return errors.New("window and min samples must be positive")
+	}
+	for _, v := range []float64{c.DegradedEnter, c.DegradedExit, c.DownEnter, c.DownExit} {
+		if v <= 0 || v > 1 {
+			return fmt.Errorf("threshold %v must be in (0, 1]", v)
+		}
+	}
+	if c.DegradedExit > c.DegradedEnter {
+		return fmt.Errorf("degraded exit %v must not exceed enter %v", c.DegradedExit, c.DegradedEnter)
+	}
+	if c.DownExit > c.DownEnter {
+		return fmt.Errorf("down exit %v must not exceed enter %v", c.DownExit, c.DownEnter)
+	}
+	return nil
+}
+
+// MarketHealthCounts はウィンドウ内の呼び出し結果の件数です。
+type MarketHealthCounts struct {
+	Success   int `json:"success"`
+	Throttled int `json:"throttled"`
+	Errors    int `json:"errors"`
+}
+
+// Total は呼び出し数を返します。
+func (c MarketHealthCounts) Total() int {
+	return c.Success + c.Throttled + c.Errors
+}
+
+// FailureRate は失敗（エラー + 利用枠の枯渇）の割合を返します。呼び出しがなければ 0 です。
+func (c MarketHealthCounts) FailureRate() float64 {
+	if c.Total() == 0 {
+		return 0
+	}
+	return float64(c.Throttled+c.Errors) / float64(c.Total())
+}
+
+// ErrorRate は利用枠の枯渇を除くエラーの割合を返します。呼び出しがなければ 0 です。
+func (c MarketHealthCounts) ErrorRate() float64 {
+	if c.Total() == 0 {
+		return 0
+	}
+	return float64(c.Errors) / float64(c.Total())
+}
+
+func (c *MarketHealthCounts) add(o MarketOutcome) {
+	switch o {
+	case MarketOutcomeSuccess:
+		c.Success++
+	case MarketOutcomeThrottled:
+		c.Throttled++
+	default:
+		c.Errors++
+	}
+}
+
+// NextMarketHealth は現在の状態 cur とウィンドウ内の件数 c から次の状態を返します（副作用なし）。
+// 呼び出し数が cfg.MinSamples に満たない間は cur を保ちます。
+func NextMarketHealth(cur MarketHealth, c MarketHealthCounts, cfg MarketHealthConfig) MarketHealth {
+	if c.Total() < cfg.MinSamples {
+		return cur
+	}
+	errRate, failRate := c.ErrorRate(), c.FailureRate()
+	if cur == MarketHealthDown {
+		if errRate >= cfg.DownExit {
+			return MarketHealthDown
+		}
+		// down を抜けたら degraded の抜ける閾値で判定する（いきなり healthy に戻さない）
+		cur = MarketHealthDegraded
+	}
+	if errRate >= cfg.DownEnter {
+		return MarketHealthDown
+	}
+	threshold := cfg.DegradedEnter
+	if cur == MarketHealthDegraded {
+		threshold = cfg.DegradedExit
+	}
+	if failRate >= threshold {
+		return MarketHealthDegraded
+	}
+	return MarketHealthHealthy
+}
+
+// marketHealthWindow は呼び出し結果をバケットに分けて保持するスライディングウィンドウです（ロックは呼び出し側）。
+type marketHealthWindow struct {
+	width   time.Duration // 1 バケットの幅（Window / marketHealthBuckets）
+	buckets [marketHealthBuckets]struct {
+		start  time.Time
+		counts MarketHealthCounts
+	}
+}
+
+func newMarketHealthWindow(window time.Duration) *marketHealthWindow {
+	width := window / marketHealthBuckets
+	if width <= 0 {
+		width = 1
+	}
+	return &marketHealthWindow{width: width}
+}
+
+// add は now の時点の結果 o を記録します。バケットが古い期間のものなら空にしてから使います。
+func (w *marketHealthWindow) add(now time.Time, o MarketOutcome) {
+	start := now.Truncate(w.width)
+	b := &w.buckets[(start.UnixNano()/int64(w.width))%marketHealthBuckets]
+	if !b.start.Equal(start) {
+		b.start = start
+		b.counts = MarketHealthCounts{}
+	}
+	b.counts.add(o)
+}
+
+// sum は now の時点でウィンドウ内にあるバケットの件数を合計します。
+func (w *marketHealthWindow) sum(now time.Time) MarketHealthCounts {
+	oldest := now.Truncate(w.width).Add(-w.width * (marketHealthBuckets - 1))
+	var out MarketHealthCounts
+	for _, b := range w.buckets {
+		if b.start.IsZero() || b.start.Before(oldest) || b.start.After(now) {
+			continue
+		}
+		out.Success += b.counts.Success
+		out.Throttled += b.counts.Throttled
+		out.Errors += b.counts.Errors
+	}
+	return out
+}
+
+// MarketHealthSnapshot はある時点の連携の状態とウィンドウ内の件数です（Redis を介して API サーバーと共有します）。
+type MarketHealthSnapshot struct {
+	State  MarketHealth       `json:"state"`
+	Counts MarketHealthCounts `json:"counts"`
+	At     time.Time          `json:"at"`
+}
+
+// MarketHealthChange は状態の遷移です。
+type MarketHealthChange struct {
+	From, To MarketHealth
+	Counts   MarketHealthCounts
+	At       time.Time
+}
+
+// MarketHealthPublisher は連携の状態を他のプロセス（API サーバー）へ共有します（MarketHealthStore が実装）。
+// ttl を過ぎても更新がなければ、共有した状態は失効します（unknown に戻る）。
+type MarketHealthPublisher interface {
+	Publish(ctx context.Context, s MarketHealthSnapshot, ttl time.Duration) error
+}
+
+// MarketHealthTracker は MarketRepository の呼び出し結果を集計し、連携全体の状態を判定します。
+// ingest では HealthTrackingMarket で呼び出しを記録し、状態を WithPublisher の共有先へ書き込みます。
+// ゼロ値ではなく NewMarketHealthTracker で生成してください。複数のゴルーチンから安全に使えます。
+type MarketHealthTracker struct {
+	cfg       MarketHealthConfig
+	now       func() time.Time
+	publisher MarketHealthPublisher
+	onChange  func(ctx context.Context, c MarketHealthChange)
+
+	mu     sync.Mutex
+	window *marketHealthWindow
+	state  MarketHealth
+}
+
+// NewMarketHealthTracker は cfg で状態を判定するトラッカーを生成します。初期状態は unknown です。
+// cfg が不正な場合は DefaultMarketHealthConfig を使います（設定の検証は config で行います）。
+func NewMarketHealthTracker(cfg MarketHealthConfig) *MarketHealthTracker {
+	if cfg.Validate() != nil {
+		cfg = DefaultMarketHealthConfig()
+	}
+	return &MarketHealthTracker{
+		cfg:    cfg,
+		now:    time.Now,
+		window: newMarketHealthWindow(cfg.Window),
+		state:  MarketHealthUnknown,
+	}
+}
+
+// WithPublisher は記録のたびに状態を p へ共有するよう設定します（TTL はウィンドウの長さ）。
+// 共有の失敗は取得に影響させません。
+func (t *MarketHealthTracker) WithPublisher(p MarketHealthPublisher) *MarketHealthTracker {
+	t.publisher = p
+	return t
+}
+
+// OnChange は状態が遷移したときに fn を呼ぶよう設定します（通知用。記録したゴルーチンで同期的に呼びます）。
+func (t *MarketHealthTracker) OnChange(fn func(ctx context.Context, c MarketHealthChange)) *MarketHealthTracker {
+	t.onChange = fn
+	return t
+}
+
+// Record は呼び出し結果 o を記録し、状態を判定し直します。
+func (t *MarketHealthTracker) Record(ctx context.Context, o MarketOutcome) {
+	now := t.now()
+	t.mu.Lock()
+	t.window.add(now, o)
+	counts := t.window.sum(now)
+	from := t.state
+	t.state = NextMarketHealth(from, counts, t.cfg)
+	snap := MarketHealthSnapshot{State: t.state, Counts: counts, At: now}
+	t.mu.Unlock()
+
+	if snap.State != from && t.onChange != nil {
+		t.onChange(ctx, MarketHealthChange{From: from, To: snap.State, Counts: counts, At: now})
+	}
+	if t.publisher != nil {
+		_ = t.publisher.Publish(ctx, snap, t.cfg.Window)
+	}
+}
+
+// Snapshot は現在の状態とウィンドウ内の件数を返します。
+func (t *MarketHealthTracker) Snapshot() MarketHealthSnapshot {
+	now := t.now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return MarketHealthSnapshot{State: t.state, Counts: t.window.sum(now), At: now}
+}
+
+// MarketHealth は現在の状態を返します（同じプロセス内で記録と参照を行う場合に MarketHealthChecker として使えます）。
+func (t *MarketHealthTracker) MarketHealth(context.Context) MarketHealth {
+	return t.Snapshot().State
+}
+
+// HealthTrackingMarket は MarketRepository の呼び出し結果を MarketHealthTracker に記録するデコレータです。
+// 呼び出し前に拒否した要求（ErrUnsupportedRequest）と呼び出し側の中断（ctx の終了）は連携の状態と無関係なため記録しません。
+type HealthTrackingMarket struct {
+	inner   MarketRepository
+	tracker *MarketHealthTracker
+}
+
+var (
+	_ MarketRepository      = (*HealthTrackingMarket)(nil)
+	_ BatchMarketRepository = (*HealthTrackingMarket)(nil)
+	_ OutputSizeCapper      = (*HealthTrackingMarket)(nil)
+)
+
+// NewHealthTrackingMarket は inner の呼び出し結果を tracker に記録する MarketRepository を返します。
+func NewHealthTrackingMarket(inner MarketRepository, tracker *MarketHealthTracker) *HealthTrackingMarket {
+	return &HealthTrackingMarket{inner: inner, tracker: tracker}
+}
+
+// GetTimeSeries は inner の GetTimeSeries を呼び出し、結果を記録します。
+func (m *HealthTrackingMarket) GetTimeSeries(ctx context.Context, symbol, interval string, outputsize int, loc *time.Location) ([]Candle, error) {
+	cs, err := m.inner.GetTimeSeries(ctx, symbol, interval, outputsize, loc)
+	m.record(ctx, err)
+	return cs, err
+}
+
+// GetTimeSeriesBatch は inner の一括取得を呼び出し、銘柄ごとに結果を記録します（上流の利用枠と同じ数え方）。
+// inner が BatchMarketRepository でなければ銘柄ごとに GetTimeSeries を呼び出します。
+func (m *HealthTrackingMarket) GetTimeSeriesBatch(ctx context.Context, symbols []string, interval string, outputsize int, locs map[string]*time.Location) (map[string][]Candle, error) {
+	batch, ok := m.inner.(BatchMarketRepository)
+	if !ok {
+		out := make(map[string][]Candle, len(symbols))
+		symErrs := BatchSymbolErrors{}
+		for _, symbol := range symbols {
+			cs, err := m.GetTimeSeries(ctx, symbol, interval, outputsize, locs[symbol])
+			if err != nil {
+				symErrs[symbol] = err
+				continue
+			}
+			out[symbol] = cs
+		}
+		if len(symErrs) > 0 {
+			return out, symErrs
+		}
+		return out, nil
+	}
+
+	out, err := batch.GetTimeSeriesBatch(ctx, symbols, interval, outputsize, locs)
+	var symErrs BatchSymbolErrors
+	if err != nil && !errors.As(err, &symErrs) {
+		for range symbols {
+			m.record(ctx, err)
+		}
+		return out, err
+	}
+	for _, symbol := range symbols {
+		m.record(ctx, symErrs[symbol])
+	}
+	return out, err
+}
+
+// record は 1 銘柄分の呼び出し結果を tracker に記録します。
+func (m *HealthTrackingMarket) record(ctx context.Context, err error) {
+	switch {
+	case err == nil:
+		m.tracker.Record(ctx, MarketOutcomeSuccess)
+	case errors.Is(err, ErrUnsupportedRequest) || ctx.Err() != nil:
+	case errors.Is(err, ErrUpstreamThrottled):
+		m.tracker.Record(ctx, MarketOutcomeThrottled)
+	default:
+		m.tracker.Record(ctx, MarketOutcomeError)
+	}
+}
+
+// MaxOutputSize は inner の上限をそのまま返します（inner が OutputSizeCapper でなければ 0 = 上限なし）。
+func (m *HealthTrackingMarket) MaxOutputSize() int {
+	if capper, ok := m.inner.(OutputSizeCapper); ok {
+		return capper.MaxOutputSize()
+	}
+	return 0
+}
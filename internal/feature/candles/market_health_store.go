@@ -0,0 +1,137 @@
+package candles
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultMarketHealthRefresh は API サーバーが共有された連携の状態を読み直す間隔です。
+const DefaultMarketHealthRefresh = 10 * time.Second
+
+// MarketHealthStore は市場データ連携の状態を Redis の 1 キーに JSON で保存します。
+// ingest（MarketHealthTracker.WithPublisher）が書き込み、API サーバー（MarketHealthMonitor）が読み取ります。
+type MarketHealthStore struct {
+	rdb      *redis.Client
+	provider RedisProvider // 設定時は rdb より優先する（WithRedisProvider 参照）
+	key      string
+}
+
+var _ MarketHealthPublisher = (*MarketHealthStore)(nil)
+
+// NewMarketHealthStore は key に状態を保存するストアを生成します。rdb が nil の場合は何も保存せず、読み取りは常に記録なしです。
+func NewMarketHealthStore(rdb *redis.Client, key string) *MarketHealthStore {
+	return &MarketHealthStore{rdb: rdb, key: key}
+}
+
+// WithRedisProvider は Redis クライアントを呼び出しごとに p から取得するよう設定します。
+func (s *MarketHealthStore) WithRedisProvider(p RedisProvider) *MarketHealthStore {
+	s.provider = p
+	return s
+}
+
+func (s *MarketHealthStore) client() *redis.Client {
+	if s.provider != nil {
+		return s.provider.Client()
+	}
+	return s.rdb
+}
+
+// Publish は状態を ttl の期限付きで保存します（ttl の間に更新がなければ失効して記録なしに戻ります）。
+func (s *MarketHealthStore) Publish(ctx context.Context, snap MarketHealthSnapshot, ttl time.Duration) error {
+	rdb := s.client()
+	if rdb == nil {
+		return nil
+	}
+	raw, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	return rdb.Set(ctx, s.key, raw, ttl).Err()
+}
+
+// Load は保存された状態を返します。記録がない（失効した）場合は ok = false です。
+func (s *MarketHealthStore) Load(ctx context.Context) (snap MarketHealthSnapshot, ok bool, err error) {
+	rdb := s.client()
+	if rdb == nil {
+		return MarketHealthSnapshot{}, false, nil
+	}
+	raw, err := rdb.Get(ctx, s.key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return MarketHealthSnapshot{}, false, nil
+	}
+	if err != nil {
+		return MarketHealthSnapshot{}, false, err
+	}
+	if err := json.Unmarshal(raw, &snap); err != nil {
+		return MarketHealthSnapshot{}, false, fmt.Errorf("decode market health: %w", err)
+	}
+	return snap, true, nil
+}
+
+// marketHealthLoader は共有された連携の状態の読み取りです（MarketHealthStore が実装）。
+type marketHealthLoader interface {
+	Load(ctx context.Context) (MarketHealthSnapshot, bool, error)
+}
+
+// MarketHealthMonitor は ingest が共有した連携の状態を API サーバーで参照します。
+// 読み取りは refresh の間プロセス内に保持し、リクエストごとに Redis を読みません。
+// 読み取りに失敗した場合は直前の状態を保ちます（Redis の一時的な障害で fetch-through の可否を揺らさないため）。
+type MarketHealthMonitor struct {
+	store    marketHealthLoader
+	refresh  time.Duration
+	now      func() time.Time
+	onChange func(ctx context.Context, c MarketHealthChange)
+
+	mu       sync.Mutex
+	state    MarketHealth
+	loadedAt time.Time
+}
+
+// NewMarketHealthMonitor は store の状態を refresh ごとに読み直すモニターを生成します。refresh が 0 以下なら DefaultMarketHealthRefresh です。
+func NewMarketHealthMonitor(store marketHealthLoader, refresh time.Duration) *MarketHealthMonitor {
+	if refresh <= 0 {
+		refresh = DefaultMarketHealthRefresh
+	}
+	return &MarketHealthMonitor{store: store, refresh: refresh, now: time.Now, state: MarketHealthUnknown}
+}
+
+// OnChange は読み直した状態が遷移していたときに fn を呼ぶよう設定します（通知用）。
+func (m *MarketHealthMonitor) OnChange(fn func(ctx context.Context, c MarketHealthChange)) *MarketHealthMonitor {
+	m.onChange = fn
+	return m
+}
+
+// MarketHealth は連携の状態を返します。記録がない（ingest の実行外で失効した）場合は unknown です。
+func (m *MarketHealthMonitor) MarketHealth(ctx context.Context) MarketHealth {
+	now := m.now()
+	m.mu.Lock()
+	if !m.loadedAt.IsZero() && now.Sub(m.loadedAt) < m.refresh {
+		defer m.mu.Unlock()
+		return m.state
+	}
+	m.loadedAt = now
+	from := m.state
+	snap, ok, err := m.store.Load(ctx)
+	switch {
+	case err != nil:
+		slog.WarnContext(ctx, "failed to load market health", "error", err)
+	case !ok:
+		m.state = MarketHealthUnknown
+	default:
+		m.state = snap.State
+	}
+	to := m.state
+	m.mu.Unlock()
+
+	if to != from && m.onChange != nil {
+		m.onChange(ctx, MarketHealthChange{From: from, To: to, Counts: snap.Counts, At: now})
+	}
+	return to
+}
@@ -0,0 +1,295 @@
+package candles
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/testsupport"
+)
+
+// outcomes は "s"（成功）・"t"（利用枠の枯渇）・"e"（エラー）の並びを MarketOutcome に変換します。
+func outcomes(seq string) []MarketOutcome {
+	out := make([]MarketOutcome, 0, len(seq))
+	for _, r := range seq {
+		switch r {
+		case 's':
+			out = append(out, MarketOutcomeSuccess)
+		case 't':
+			out = append(out, MarketOutcomeThrottled)
+		case 'e':
+			out = append(out, MarketOutcomeError)
+		}
+	}
+	return out
+}
+
+func countsOf(seq string) MarketHealthCounts {
+	var c MarketHealthCounts
+	for _, o := range outcomes(seq) {
+		c.add(o)
+	}
+	return c
+}
+
+func TestNextMarketHealth(t *testing.T) {
+	t.Parallel()
+
+	cfg := DefaultMarketHealthConfig() // degraded 0.2 / 0.1、down 0.5 / 0.3、最小 10 件
+	tests := []struct {
+		name string
+		cur  MarketHealth
+		seq  string
+		want MarketHealth
+	}{
+		{"件数不足は現在の状態を保つ", MarketHealthUnknown, "eeeee", MarketHealthUnknown},
+		{"件数不足は down も保つ", MarketHealthDown, "sssss", MarketHealthDown},
+		{"失敗なしは healthy", MarketHealthUnknown, "ssssssssss", MarketHealthHealthy},
+		{"失敗率 0.2 で degraded に入る", MarketHealthHealthy, "sssssssstt", MarketHealthDegraded},
+		{"失敗率 0.1 では degraded に入らない", MarketHealthHealthy, "ssssssssse", MarketHealthHealthy},
+		{"degraded は失敗率 0.1 では抜けない", MarketHealthDegraded, "ssssssssse", MarketHealthDegraded},
+		{"degraded は失敗率 0.1 未満で抜ける", MarketHealthDegraded, "sssssssssssssssssssse", MarketHealthHealthy},
+		{"エラー率 0.5 未満は degraded", MarketHealthHealthy, "sssssseeeee", MarketHealthDegraded},
+		{"エラー率 0.5 ちょうどで down", MarketHealthHealthy, "sssssseeeeee", MarketHealthDown},
+		{"利用枠の枯渇だけでは down にならない", MarketHealthHealthy, "tttttttttt", MarketHealthDegraded},
+		{"down はエラー率 0.3 では抜けない", MarketHealthDown, "ssssssseee", MarketHealthDown},
+		{"down を抜けると degraded の抜ける閾値で判定する", MarketHealthDown, "sssssssssee", MarketHealthDegraded},
+		{"down から一気に healthy に戻る", MarketHealthDown, "ssssssssssssssssssssssssssssss", MarketHealthHealthy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, NextMarketHealth(tt.cur, countsOf(tt.seq), cfg))
+		})
+	}
+}
+
+func TestMarketHealthConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, DefaultMarketHealthConfig().Validate())
+
+	cfg := DefaultMarketHealthConfig()
+	cfg.DownExit = 0.6
+	assert.Error(t, cfg.Validate(), "抜ける閾値が入る閾値を超える")
+
+	cfg = DefaultMarketHealthConfig()
+	cfg.DegradedEnter = 1.5
+	assert.Error(t, cfg.Validate())
+
+	cfg = DefaultMarketHealthConfig()
+	cfg.MinSamples = 0
+	assert.Error(t, cfg.Validate())
+}
+
+func TestMarketHealthWindow_Slides(t *testing.T) {
+	t.Parallel()
+
+	w := newMarketHealthWindow(10 * time.Minute) // 1 バケット 1 分
+	base := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+
+	w.add(base, MarketOutcomeError)
+	w.add(base.Add(30*time.Second), MarketOutcomeSuccess)
+	w.add(base.Add(5*time.Minute), MarketOutcomeThrottled)
+	assert.Equal(t, MarketHealthCounts{Success: 1, Throttled: 1, Errors: 1}, w.sum(base.Add(5*time.Minute)))
+
+	// 10 分後には最初のバケットがウィンドウから外れる
+	assert.Equal(t, MarketHealthCounts{Throttled: 1}, w.sum(base.Add(10*time.Minute)))
+
+	// 同じ位置のバケットを再利用するときは古い件数を捨てる
+	w.add(base.Add(10*time.Minute), MarketOutcomeSuccess)
+	assert.Equal(t, MarketHealthCounts{Success: 1, Throttled: 1}, w.sum(base.Add(10*time.Minute)))
+
+	assert.Equal(t, MarketHealthCounts{}, w.sum(base.Add(30*time.Minute)))
+}
+
+type fakeMarketHealthPublisher struct {
+	published []MarketHealthSnapshot
+	ttl       time.Duration
+}
+
+func (p *fakeMarketHealthPublisher) Publish(_ context.Context, s MarketHealthSnapshot, ttl time.Duration) error {
+	p.published = append(p.published, s)
+	p.ttl = ttl
+	return nil
+}
+
+// newTestTracker は最小 4 件・ウィンドウ 1 分で、時刻を now で進められるトラッカーを返します。
+func newTestTracker(now *time.Time) *MarketHealthTracker {
+	cfg := DefaultMarketHealthConfig()
+	cfg.Window = time.Minute
+	cfg.MinSamples = 4
+	tr := NewMarketHealthTracker(cfg)
+	tr.now = func() time.Time { return *now }
+	return tr
+}
+
+func TestMarketHealthTracker_Transitions(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	pub := &fakeMarketHealthPublisher{}
+	var changes []string
+	tr := newTestTracker(&now).WithPublisher(pub).OnChange(func(_ context.Context, c MarketHealthChange) {
+		changes = append(changes, string(c.From)+"->"+string(c.To))
+	})
+	ctx := context.Background()
+	record := func(seq string) {
+		for _, o := range outcomes(seq) {
+			tr.Record(ctx, o)
+			now = now.Add(time.Second)
+		}
+	}
+
+	record("ssss")
+	record("eeeeee")
+	assert.Equal(t, MarketHealthDown, tr.MarketHealth(ctx))
+
+	// エラーがウィンドウから外れ、成功が続くと回復する
+	now = now.Add(time.Minute)
+	record("ssss")
+	assert.Equal(t, MarketHealthHealthy, tr.Snapshot().State)
+
+	assert.Equal(t, []string{"unknown->healthy", "healthy->degraded", "degraded->down", "down->healthy"}, changes)
+	require.Len(t, pub.published, 14, "記録のたびに共有する")
+	assert.Equal(t, MarketHealthHealthy, pub.published[13].State)
+	assert.Equal(t, MarketHealthCounts{Success: 4}, pub.published[13].Counts)
+	assert.Equal(t, time.Minute, pub.ttl, "共有した状態はウィンドウの長さで失効する")
+}
+
+func TestHealthTrackingMarket_Classifies(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	tr := newTestTracker(&now)
+	var next error
+	inner := &mockMarketRepository{GetTimeSeriesFunc: func(context.Context, string, string, int, *time.Location) ([]Candle, error) {
+		return nil, next
+	}}
+	m := NewHealthTrackingMarket(inner, tr)
+	ctx := context.Background()
+
+	for _, err := range []error{
+		nil,
+		&ThrottledError{Wait: time.Second},
+		errors.New("twelvedata: internal error"),
+		context.DeadlineExceeded, // 呼び出し単位の期限切れは上流の遅延として記録する
+		&UnsupportedRequestError{Interval: "1min", Reason: "not available"},
+	} {
+		next = err
+		_, got := m.GetTimeSeries(ctx, "AAPL", "1day", 10, time.UTC)
+		assert.Equal(t, err, got)
+	}
+	// 呼び出し側の中断は記録しない
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	next = context.Canceled
+	_, _ = m.GetTimeSeries(canceled, "AAPL", "1day", 10, time.UTC)
+
+	assert.Equal(t, MarketHealthCounts{Success: 1, Throttled: 1, Errors: 2}, tr.Snapshot().Counts)
+	assert.Equal(t, 0, m.MaxOutputSize(), "inner が上限を持たなければ 0")
+}
+
+// TestCachingRepository_FetchThrough_MarketHealth は ingest が共有した連携の状態が down の間は
+// キャッシュミス時の書き込みを止め、回復すると再開することを検証します（Redis を介して ingest と API で共有）。
+func TestCachingRepository_FetchThrough_MarketHealth(t *testing.T) {
+	t.Parallel()
+
+	mr, rdb := testsupport.NewMiniRedis(t)
+	store := NewMarketHealthStore(rdb, "test:market:health")
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	tr := newTestTracker(&now).WithPublisher(store)
+	monitor := NewMarketHealthMonitor(store, time.Nanosecond)
+	var changes []MarketHealth
+	monitor.OnChange(func(_ context.Context, c MarketHealthChange) { changes = append(changes, c.To) })
+
+	inner := newCountingInner(newestFirst(10))
+	repo := NewCachingRepository(rdb, time.Hour, inner, "candles", nil).WithMarketHealth(monitor)
+	ctx := context.Background()
+	cached := func(symbol string) bool {
+		for _, k := range mr.Keys() {
+			if strings.HasPrefix(k, "candles:"+symbol+":") {
+				return true
+			}
+		}
+		return false
+	}
+
+	// 記録がない間（unknown）は従来どおり書き込む
+	_, err := repo.Find(ctx, "AAPL", "1day", 10)
+	require.NoError(t, err)
+	assert.True(t, cached("AAPL"))
+
+	for range 6 {
+		tr.Record(ctx, MarketOutcomeError)
+	}
+	got, err := repo.Find(ctx, "MSFT", "1day", 10)
+	require.NoError(t, err)
+	assert.Len(t, got, 10, "down の間も DB から応答する")
+	assert.False(t, cached("MSFT"), "down の間は書き込まない")
+
+	// 既存のエントリからの応答は続ける
+	_, err = repo.Find(ctx, "AAPL", "1day", 10)
+	require.NoError(t, err)
+	assert.Len(t, inner.requested, 2)
+
+	now = now.Add(2 * time.Minute)
+	for range 4 {
+		tr.Record(ctx, MarketOutcomeSuccess)
+	}
+	_, err = repo.Find(ctx, "MSFT", "1day", 10)
+	require.NoError(t, err)
+	assert.True(t, cached("MSFT"), "回復後は書き込みを再開する")
+
+	// 共有した状態が失効すると unknown に戻る
+	mr.FastForward(2 * time.Minute)
+	assert.Equal(t, MarketHealthUnknown, monitor.MarketHealth(ctx))
+	assert.Equal(t, []MarketHealth{MarketHealthDown, MarketHealthHealthy, MarketHealthUnknown}, changes)
+}
+
+// TestHealthTrackingMarket_Batch は一括取得の結果を銘柄ごとに記録し、inner が一括取得に対応しなければ銘柄ごとに呼び出すことを検証します。
+func TestHealthTrackingMarket_Batch(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	locs := map[string]*time.Location{"AAPL": time.UTC, "MSFT": time.UTC, "NOPE": time.UTC}
+
+	tr := newTestTracker(&now)
+	inner := &mockBatchMarketRepository{GetTimeSeriesBatchFunc: func(context.Context, []string, string, int, map[string]*time.Location) (map[string][]Candle, error) {
+		return map[string][]Candle{"AAPL": {}, "MSFT": {}}, BatchSymbolErrors{"NOPE": errors.New("symbol not found")}
+	}}
+	got, err := NewHealthTrackingMarket(inner, tr).GetTimeSeriesBatch(ctx, []string{"AAPL", "MSFT", "NOPE"}, "1day", 10, locs)
+	require.Error(t, err)
+	assert.Len(t, got, 2)
+	assert.Equal(t, MarketHealthCounts{Success: 2, Errors: 1}, tr.Snapshot().Counts)
+
+	// 呼び出し全体の失敗は銘柄数だけ記録する
+	tr = newTestTracker(&now)
+	inner.GetTimeSeriesBatchFunc = func(context.Context, []string, string, int, map[string]*time.Location) (map[string][]Candle, error) {
+		return nil, &ThrottledError{Wait: time.Second}
+	}
+	_, err = NewHealthTrackingMarket(inner, tr).GetTimeSeriesBatch(ctx, []string{"AAPL", "MSFT"}, "1day", 10, locs)
+	require.ErrorIs(t, err, ErrUpstreamThrottled)
+	assert.Equal(t, MarketHealthCounts{Throttled: 2}, tr.Snapshot().Counts)
+
+	tr = newTestTracker(&now)
+	single := &mockMarketRepository{GetTimeSeriesFunc: func(_ context.Context, symbol, _ string, _ int, _ *time.Location) ([]Candle, error) {
+		if symbol == "NOPE" {
+			return nil, errors.New("symbol not found")
+		}
+		return []Candle{}, nil
+	}}
+	got, err = NewHealthTrackingMarket(single, tr).GetTimeSeriesBatch(ctx, []string{"AAPL", "NOPE"}, "1day", 10, locs)
+	var symErrs BatchSymbolErrors
+	require.ErrorAs(t, err, &symErrs)
+	assert.Contains(t, symErrs, "NOPE")
+	assert.Contains(t, got, "AAPL")
+	assert.Equal(t, 2, single.GetTimeSeriesCalls)
+	assert.Equal(t, MarketHealthCounts{Success: 1, Errors: 1}, tr.Snapshot().Counts)
+}
@@ -0,0 +1,112 @@
+package candles
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MaxOutputSize はローソク足の返却件数の絶対的な上限です。
+// キャッシュはこの件数の全データを保持し、OutputSizePolicy の上限はこれを超えられません。
+const MaxOutputSize = 5000
+
+// OutputSizeLimit は時間間隔ごとの outputsize の既定値と上限です。
+type OutputSizeLimit struct {
+	Default int // outputsize 未指定・範囲外の場合に返す件数
+	Max     int // 指定できる件数の上限（ingest は日足をこの件数まで取得する）
+}
+
+// defaultOutputSizeLimits は組み込みの時間間隔ごとの既定値と上限です。
+// 既定値は日足で約 10 か月、週足で約 3 年、月足で 10 年分です。
+var defaultOutputSizeLimits = map[string]OutputSizeLimit{
+	"1day":   {Default: 200, Max: MaxOutputSize},
+	"1week":  {Default: 156, Max: 1000},
+	"1month": {Default: 120, Max: 240},
+}
+
+// fallbackOutputSizeLimit はポリシーにない時間間隔に使う既定値と上限です。
+var fallbackOutputSizeLimit = OutputSizeLimit{Default: 200, Max: MaxOutputSize}
+
+// OutputSizePolicy は時間間隔ごとの outputsize の既定値と上限を決めます。
+// ゼロ値は DefaultOutputSizePolicy と同じ組み込みの値を使います。
+type OutputSizePolicy struct {
+	limits map[string]OutputSizeLimit
+}
+
+// DefaultOutputSizePolicy は組み込みの値（1day: 200/5000、1week: 156/1000、1month: 120/240）のポリシーを返します。
+func DefaultOutputSizePolicy() OutputSizePolicy {
+	return OutputSizePolicy{limits: maps.Clone(defaultOutputSizeLimits)}
+}
+
+// NewOutputSizePolicy は組み込みの値を overrides で上書きしたポリシーを返します。
+// 時間間隔は 1day / 1week / 1month のみ指定でき、0 < Default <= Max <= MaxOutputSize でない値はエラーです。
+func NewOutputSizePolicy(overrides map[string]OutputSizeLimit) (OutputSizePolicy, error) {
+	p := DefaultOutputSizePolicy()
+	for _, interval := range slices.Sorted(maps.Keys(overrides)) {
+		if _, ok := defaultOutputSizeLimits[interval]; !ok {
+			return OutputSizePolicy{}, fmt.Errorf("unknown interval %q: want one of %s", interval, strings.Join(ingestIntervals, ", "))
+		}
+		l := overrides[interval]
+		switch {
+		case l.Default <= 0 || l.Max <= 0:
+			return OutputSizePolicy{}, fmt.Errorf("outputsize for %s must be positive", interval)
+		case l.Default > l.Max:
+			return OutputSizePolicy{}, fmt.Errorf("default outputsize %d for %s exceeds its max %d", l.Default, interval, l.Max)
+		case l.Max > MaxOutputSize:
+			return OutputSizePolicy{}, fmt.Errorf("max outputsize %d for %s exceeds %d", l.Max, interval, MaxOutputSize)
+		}
+		p.limits[interval] = l
+	}
+	return p, nil
+}
+
+// ParseOutputSizePolicy は "INTERVAL=DEFAULT:MAX" のカンマ区切り（例: "1day=200:5000,1week=156:1000"）を解釈します。
+// 指定のない時間間隔は組み込みの値のままです。空文字列は DefaultOutputSizePolicy を返します。
+func ParseOutputSizePolicy(s string) (OutputSizePolicy, error) {
+	overrides := make(map[string]OutputSizeLimit)
+	for item := range strings.SplitSeq(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		interval, v, ok := strings.Cut(item, "=")
+		if !ok {
+			return OutputSizePolicy{}, fmt.Errorf("invalid outputsize limit %q: want INTERVAL=DEFAULT:MAX", item)
+		}
+		d, m, ok := strings.Cut(v, ":")
+		if !ok {
+			return OutputSizePolicy{}, fmt.Errorf("invalid outputsize limit %q: want INTERVAL=DEFAULT:MAX", item)
+		}
+		def, err1 := strconv.Atoi(strings.TrimSpace(d))
+		limit, err2 := strconv.Atoi(strings.TrimSpace(m))
+		if err1 != nil || err2 != nil {
+			return OutputSizePolicy{}, fmt.Errorf("invalid outputsize limit %q: default and max must be integers", item)
+		}
+		overrides[strings.TrimSpace(interval)] = OutputSizeLimit{Default: def, Max: limit}
+	}
+	return NewOutputSizePolicy(overrides)
+}
+
+// Limit は interval の既定値と上限を返します。ポリシーにない時間間隔は既定値 200・上限 MaxOutputSize です。
+func (p OutputSizePolicy) Limit(interval string) OutputSizeLimit {
+	limits := p.limits
+	if limits == nil {
+		limits = defaultOutputSizeLimits
+	}
+	if l, ok := limits[interval]; ok {
+		return l
+	}
+	return fallbackOutputSizeLimit
+}
+
+// Normalize は interval の要求件数 n を返却件数に正規化します。
+// n が 0 以下（未指定）または上限を超える場合は既定値を返します。
+func (p OutputSizePolicy) Normalize(interval string, n int) int {
+	l := p.Limit(interval)
+	if n <= 0 || n > l.Max {
+		return l.Default
+	}
+	return n
+}
@@ -0,0 +1,70 @@
+package candles
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOutputSizePolicy_Normalize(t *testing.T) {
+	t.Parallel()
+
+	p := DefaultOutputSizePolicy()
+	tests := []struct {
+		interval string
+		in       int
+		want     int
+	}{
+		{interval: "1day", in: 0, want: 200},
+		{interval: "1day", in: 100, want: 100},
+		{interval: "1day", in: 5000, want: 5000},
+		{interval: "1day", in: 5001, want: 200},
+		{interval: "1week", in: -1, want: 156},
+		{interval: "1week", in: 1000, want: 1000},
+		{interval: "1week", in: 1001, want: 156},
+		{interval: "1month", in: 0, want: 120},
+		{interval: "1month", in: 241, want: 120},
+		{interval: "1h", in: 0, want: 200},
+		{interval: "1h", in: 5000, want: 5000},
+	}
+	for _, tc := range tests {
+		if got := p.Normalize(tc.interval, tc.in); got != tc.want {
+			t.Errorf("Normalize(%q, %d) = %d, want %d", tc.interval, tc.in, got, tc.want)
+		}
+	}
+
+	// ゼロ値は組み込みの値と同じ
+	var zero OutputSizePolicy
+	if got := zero.Limit("1week"); got != (OutputSizeLimit{Default: 156, Max: 1000}) {
+		t.Errorf("zero value Limit(1week) = %+v, want {156 1000}", got)
+	}
+}
+
+func TestParseOutputSizePolicy(t *testing.T) {
+	t.Parallel()
+
+	p, err := ParseOutputSizePolicy(" 1day=100:2000, 1month=60:60 ,")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for interval, want := range map[string]OutputSizeLimit{
+		"1day":   {Default: 100, Max: 2000},
+		"1week":  {Default: 156, Max: 1000}, // 指定なしは組み込みの値
+		"1month": {Default: 60, Max: 60},
+	} {
+		if got := p.Limit(interval); got != want {
+			t.Errorf("Limit(%s) = %+v, want %+v", interval, got, want)
+		}
+	}
+	if got, err := ParseOutputSizePolicy(""); err != nil || got.Limit("1day") != (OutputSizeLimit{Default: 200, Max: MaxOutputSize}) {
+		t.Errorf("empty: got %+v, %v; want built-in", got.Limit("1day"), err)
+	}
+
+	for _, in := range []string{"1day", "1day=200", "1day=a:5000", "1day=0:100", "1day=100:-1", "1day=5001:5001", "1h=10:100"} {
+		if _, err := ParseOutputSizePolicy(in); err == nil {
+			t.Errorf("ParseOutputSizePolicy(%q): expected error", in)
+		}
+	}
+	if _, err := ParseOutputSizePolicy("1week=300:200"); err == nil || !strings.Contains(err.Error(), "exceeds its max") {
+		t.Errorf("default > max: got %v, want exceeds its max error", err)
+	}
+}
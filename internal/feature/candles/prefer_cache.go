@@ -0,0 +1,49 @@
+package candles
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// preferCacheKey は context に「DB よりキャッシュを優先する」ヒントを格納するための非公開キー型です。
+type preferCacheKey struct{}
+
+// WithPreferCache は読み取りで DB よりキャッシュを優先するヒントを格納した context を返します。
+// メンテナンス中（スキーマの移行など）に DB への負荷を抑えるために使います。ヒントのある Find は
+//
+//   - 期限が近いヒットでも先行再取得（WithRefreshAhead）を開始せず
+//   - 要求件数の区切りのエントリがない場合は、より大きい区切りのエントリを切り詰めて返します
+//
+// いずれのエントリもない場合は従来どおり DB にフォールバックします（結果が返らないよりは DB を読む）。
+func WithPreferCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, preferCacheKey{}, true)
+}
+
+// preferCache は ctx に WithPreferCache のヒントがあるかを返します。
+func preferCache(ctx context.Context) bool {
+	v, _ := ctx.Value(preferCacheKey{}).(bool)
+	return v
+}
+
+// findLargerBucket は size より大きい区切りのエントリを小さい順に Redis から探し、最初に見つかったものを返します。
+// 区切りが未設定（size が MaxOutputSize）の場合は探しません。
+func (c *CachingRepository) findLargerBucket(ctx context.Context, rdb *redis.Client, symbol, interval string, size int) ([]Candle, string, bool) {
+	for _, b := range c.buckets {
+		if b <= size {
+			continue
+		}
+		key := c.bucketKey(symbol, interval, b)
+		raw, err := rdb.Get(ctx, key).Bytes()
+		if err != nil || len(raw) == 0 {
+			continue
+		}
+		var all []Candle
+		if err := json.Unmarshal(raw, &all); err != nil {
+			continue
+		}
+		return all, key, true
+	}
+	return nil, "", false
+}
@@ -0,0 +1,68 @@
+package candles
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/testsupport"
+)
+
+func TestCachingRepository_Find_PreferCache_LargerBucket(t *testing.T) {
+	t.Parallel()
+
+	_, rdb := testsupport.NewMiniRedis(t)
+	inner := newCountingInner(newestFirst(600))
+	c := NewCachingRepository(rdb, time.Hour, inner, "candles", nil).WithCacheBuckets(DefaultCacheBuckets)
+	ctx := context.Background()
+
+	// 300 本の要求で 500 本のエントリを保存する
+	_, err := c.Find(ctx, "AAPL", "1day", 300)
+	require.NoError(t, err)
+	require.Equal(t, []int{500}, inner.requested)
+
+	// ヒントがあれば 100 本のエントリがなくても 500 本のエントリを切り詰めて返し、DB は読まない
+	got, err := c.Find(WithPreferCache(ctx), "AAPL", "1day", 60)
+	require.NoError(t, err)
+	assert.Equal(t, newestFirst(600)[:60], got)
+	assert.Equal(t, []int{500}, inner.requested)
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 1, SlicedHits: 1}, c.CacheStats())
+
+	// ヒントがなければ従来どおり区切りのエントリを DB から作る
+	_, err = c.Find(ctx, "AAPL", "1day", 60)
+	require.NoError(t, err)
+	assert.Equal(t, []int{500, 100}, inner.requested)
+
+	// より大きいエントリもなければ DB にフォールバックする
+	_, err = c.Find(WithPreferCache(ctx), "MSFT", "1day", 60)
+	require.NoError(t, err)
+	assert.Equal(t, []int{500, 100, 100}, inner.requested)
+}
+
+func TestCachingRepository_Find_PreferCache_SkipsRefreshAhead(t *testing.T) {
+	t.Parallel()
+
+	cs := []Candle{{SymbolCode: "AAPL", Interval: "1day", Close: 100}}
+	calls := 0
+	inner := &mockReadWriteRepository{findFn: func(context.Context, string, string, int) ([]Candle, error) {
+		calls++
+		return cs, nil
+	}}
+	repo, mr := newRefreshAheadRepo(t, inner, 1)
+	ctx := context.Background()
+
+	_, err := repo.Find(ctx, "AAPL", "1day", 10)
+	require.NoError(t, err)
+
+	// 残り TTL が閾値を下回っても、ヒントがあれば先行再取得で DB を読まない
+	mr.FastForward(9 * time.Minute)
+	got, err := repo.Find(WithPreferCache(ctx), "AAPL", "1day", 10)
+	require.NoError(t, err)
+	assert.Equal(t, cs, got)
+	repo.refresh.wg.Wait()
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, RefreshAheadStats{}, repo.RefreshAheadStats())
+}
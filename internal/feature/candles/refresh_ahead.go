@@ -0,0 +1,178 @@
+package candles
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultRefreshAheadConcurrency は同時に走らせる先行再取得の既定の上限です。
+const DefaultRefreshAheadConcurrency = 4
+
+// refreshAheadTimeout は 1 回の先行再取得（DB 読み取りとキャッシュ書き込み）にかける時間の上限です。
+const refreshAheadTimeout = 30 * time.Second
+
+// RefreshAheadConfig はキャッシュの先行再取得（refresh-ahead）の設定です。
+//
+// 人気銘柄のキャッシュがアクセス集中中に期限切れになると、一斉にミスして DB へ読み取りが集中し、
+// 周期的にレイテンシが跳ね上がります。先行再取得を有効にすると、期限が近いエントリのヒット時に
+// 現在の値を返しつつ、バックグラウンドで DB から読み直してエントリを書き換え（TTL を延長し）ます。
+type RefreshAheadConfig struct {
+	// Threshold は残り TTL がキャッシュ TTL のこの割合を下回ったヒットで先行再取得を行う値です（0 < Threshold < 1）。
+	// 0 の場合は無効です。
+	Threshold float64
+	// MaxConcurrent は同時に走らせる先行再取得の上限です。上限に達している間のヒットでは再取得しません。
+	// 0 以下の場合は DefaultRefreshAheadConcurrency を使います。
+	MaxConcurrent int
+}
+
+// RefreshAheadStats は先行再取得の累計です。
+type RefreshAheadStats struct {
+	Triggered uint64 // 開始した先行再取得
+	Refreshed uint64 // エントリを書き換えた先行再取得（期限切れによるミスを回避した回数）
+	Skipped   uint64 // 同時実行数の上限により見送ったヒット
+	Failed    uint64 // DB 読み取りまたはキャッシュ書き込みに失敗した先行再取得
+}
+
+// refreshAhead は先行再取得の実行状態です。同じキーの再取得は同時に 1 つだけ走らせます。
+type refreshAhead struct {
+	threshold float64
+	sem       chan struct{}
+	timeout   time.Duration
+
+	mu       sync.Mutex
+	inflight map[string]struct{}
+	wg       sync.WaitGroup
+
+	triggered, refreshed, skipped, failed atomic.Uint64
+}
+
+// WithRefreshAhead はキャッシュヒット時の先行再取得を有効にします。
+// cfg.Threshold が (0, 1) の範囲外の場合は無効のままです。
+// 有効時はヒットごとに残り TTL を PTTL で確認します（GET と同じパイプラインで送るため往復は増えません）。
+func (c *CachingRepository) WithRefreshAhead(cfg RefreshAheadConfig) *CachingRepository {
+	if cfg.Threshold <= 0 || cfg.Threshold >= 1 {
+		return c
+	}
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = DefaultRefreshAheadConcurrency
+	}
+	c.refresh = &refreshAhead{
+		threshold: cfg.Threshold,
+		sem:       make(chan struct{}, cfg.MaxConcurrent),
+		timeout:   refreshAheadTimeout,
+		inflight:  map[string]struct{}{},
+	}
+	return c
+}
+
+// RefreshAheadStats は先行再取得の累計を返します。無効時はゼロ値を返します。
+func (c *CachingRepository) RefreshAheadStats() RefreshAheadStats {
+	r := c.refresh
+	if r == nil {
+		return RefreshAheadStats{}
+	}
+	return RefreshAheadStats{
+		Triggered: r.triggered.Load(),
+		Refreshed: r.refreshed.Load(),
+		Skipped:   r.skipped.Load(),
+		Failed:    r.failed.Load(),
+	}
+}
+
+// getWithTTL はキャッシュの値と残り TTL を返します。先行再取得が無効の場合は GET のみを送り、残り TTL は -1 を返します。
+func (c *CachingRepository) getWithTTL(ctx context.Context, rdb *redis.Client, key string) ([]byte, time.Duration, error) {
+	if c.refresh == nil {
+		b, err := rdb.Get(ctx, key).Bytes()
+		return b, -1, err
+	}
+	pipe := rdb.Pipeline()
+	get := pipe.Get(ctx, key)
+	pttl := pipe.PTTL(ctx, key)
+	_, _ = pipe.Exec(ctx) // 結果は各コマンドから取り出す
+	b, err := get.Bytes()
+	if err != nil {
+		return nil, -1, err
+	}
+	remaining, err := pttl.Result()
+	if err != nil {
+		remaining = -1
+	}
+	return b, remaining, nil
+}
+
+// maybeRefresh は残り TTL が閾値を下回っていれば、key の先行再取得をバックグラウンドで開始します。
+// 残り TTL が不明（負）の場合、同じキーの再取得が実行中の場合は何もしません。
+// 再取得はクライアントのキャンセルの影響を受けないよう、ctx から切り離したコンテキストで行います。
+func (c *CachingRepository) maybeRefresh(ctx context.Context, rdb *redis.Client, key, symbol, interval string, size int, remaining time.Duration) {
+	r := c.refresh
+	if r == nil || remaining < 0 || remaining >= time.Duration(float64(c.ttl)*r.threshold) {
+		return
+	}
+
+	r.mu.Lock()
+	if _, ok := r.inflight[key]; ok {
+		r.mu.Unlock()
+		return
+	}
+	select {
+	case r.sem <- struct{}{}:
+	default:
+		r.mu.Unlock()
+		r.skipped.Add(1)
+		return
+	}
+	r.inflight[key] = struct{}{}
+	r.mu.Unlock()
+	r.triggered.Add(1)
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer func() {
+			r.mu.Lock()
+			delete(r.inflight, key)
+			r.mu.Unlock()
+			<-r.sem
+		}()
+
+		// リクエストのデバッグ用の記録（Trace）は応答後に書き換えないよう引き継がない
+		bctx, cancel := context.WithTimeout(context.WithoutCancel(withoutTrace(ctx)), r.timeout)
+		defer cancel()
+		if err := c.refreshEntry(bctx, rdb, key, symbol, interval, size); err != nil {
+			r.failed.Add(1)
+			slog.Warn("candle cache refresh-ahead failed", "key", key, "error", err)
+		}
+	}()
+}
+
+// refreshEntry は DB からエントリの件数 size（既定は全データ）を読み直して key を書き換えます。
+// 読み直しの間に UpsertBatch がキーを削除した場合に古いデータで復活させないよう、
+// キーが残っている場合のみ書き換えます（SET XX）。
+func (c *CachingRepository) refreshEntry(ctx context.Context, rdb *redis.Client, key, symbol, interval string, size int) error {
+	all, err := c.inner.Find(ctx, symbol, interval, size)
+	if err != nil {
+		return err
+	}
+	if len(all) == 0 {
+		// 0 件は negative caching の短い TTL に任せ、先行再取得では延長しない
+		return nil
+	}
+	b, err := json.Marshal(all)
+	if err != nil {
+		return err
+	}
+	ok, err := rdb.SetXX(ctx, key, b, c.ttl).Result()
+	if err != nil {
+		return err
+	}
+	if ok {
+		c.refresh.refreshed.Add(1)
+	}
+	return nil
+}
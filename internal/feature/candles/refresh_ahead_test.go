@@ -0,0 +1,160 @@
+package candles
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRefreshAheadRepo は miniredis 上で TTL 10 分・閾値 0.2（残り 2 分未満で先行再取得）の CachingRepository を返します。
+// miniredis の FastForward をキャッシュの時計として使います。
+func newRefreshAheadRepo(t *testing.T, inner readWriteRepository, maxConcurrent int) (*CachingRepository, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	repo := NewCachingRepository(rdb, 10*time.Minute, inner, "test:candles", nil).
+		WithRefreshAhead(RefreshAheadConfig{Threshold: 0.2, MaxConcurrent: maxConcurrent})
+	return repo, mr
+}
+
+func cachedCandles(t *testing.T, mr *miniredis.Miniredis, key string) []Candle {
+	t.Helper()
+	raw, err := mr.Get(key)
+	require.NoError(t, err)
+	var cs []Candle
+	require.NoError(t, json.Unmarshal([]byte(raw), &cs))
+	return cs
+}
+
+func TestCachingCandleRepository_RefreshAhead_Threshold(t *testing.T) {
+	t.Parallel()
+
+	old := []Candle{{SymbolCode: "AAPL", Interval: "1day", Close: 100}}
+	fresh := []Candle{{SymbolCode: "AAPL", Interval: "1day", Close: 101}}
+	release := make(chan struct{})
+	calls := 0
+	inner := &mockReadWriteRepository{findFn: func(ctx context.Context, _, _ string, _ int) ([]Candle, error) {
+		calls++
+		if calls == 1 {
+			return old, nil
+		}
+		<-release
+		return fresh, nil
+	}}
+	repo, mr := newRefreshAheadRepo(t, inner, 1)
+	ctx := context.Background()
+	key := repo.cacheKey("AAPL", "1day")
+
+	// ミスで保存（TTL 10 分）
+	_, err := repo.Find(ctx, "AAPL", "1day", 10)
+	require.NoError(t, err)
+
+	// 残り 2 分ちょうどは閾値を下回らないため再取得しない
+	mr.FastForward(8 * time.Minute)
+	got, err := repo.Find(ctx, "AAPL", "1day", 10)
+	require.NoError(t, err)
+	assert.Equal(t, old, got)
+	assert.Equal(t, RefreshAheadStats{}, repo.RefreshAheadStats())
+
+	// 閾値を下回ると現在の値を返しつつ先行再取得する。クライアントのキャンセルは再取得を止めない
+	mr.FastForward(time.Second)
+	cctx, cancel := context.WithCancel(ctx)
+	got, err = repo.Find(cctx, "AAPL", "1day", 10)
+	cancel()
+	require.NoError(t, err)
+	assert.Equal(t, old, got, "stale-but-valid value is served while refreshing")
+
+	// 再取得中のヒットは重複して再取得せず、引き続き現在の値を返す
+	got, err = repo.Find(ctx, "AAPL", "1day", 10)
+	require.NoError(t, err)
+	assert.Equal(t, old, got)
+
+	close(release)
+	repo.refresh.wg.Wait()
+
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, fresh, cachedCandles(t, mr, key))
+	assert.Equal(t, 10*time.Minute, mr.TTL(key), "refresh extends the entry to the full TTL")
+	assert.Equal(t, RefreshAheadStats{Triggered: 1, Refreshed: 1}, repo.RefreshAheadStats())
+}
+
+func TestCachingCandleRepository_RefreshAhead_ConcurrencyCap(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	warm := true
+	inner := &mockReadWriteRepository{findFn: func(_ context.Context, symbol, interval string, _ int) ([]Candle, error) {
+		if !warm {
+			<-release
+		}
+		return []Candle{{SymbolCode: symbol, Interval: interval, Close: 1}}, nil
+	}}
+	repo, mr := newRefreshAheadRepo(t, inner, 2)
+	ctx := context.Background()
+
+	symbols := []string{"AAPL", "GOOGL", "MSFT"}
+	for _, s := range symbols {
+		_, err := repo.Find(ctx, s, "1day", 1)
+		require.NoError(t, err)
+	}
+	warm = false
+
+	mr.FastForward(9 * time.Minute)
+	for _, s := range symbols {
+		_, err := repo.Find(ctx, s, "1day", 1)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, RefreshAheadStats{Triggered: 2, Skipped: 1}, repo.RefreshAheadStats())
+
+	close(release)
+	repo.refresh.wg.Wait()
+	assert.Equal(t, RefreshAheadStats{Triggered: 2, Refreshed: 2, Skipped: 1}, repo.RefreshAheadStats())
+}
+
+func TestCachingCandleRepository_RefreshAhead_DoesNotResurrectDeletedKey(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	calls := 0
+	inner := &mockReadWriteRepository{findFn: func(context.Context, string, string, int) ([]Candle, error) {
+		calls++
+		if calls > 1 {
+			<-release
+		}
+		return []Candle{{SymbolCode: "AAPL", Interval: "1day", Close: 1}}, nil
+	}}
+	repo, mr := newRefreshAheadRepo(t, inner, 1)
+	ctx := context.Background()
+	key := repo.cacheKey("AAPL", "1day")
+
+	_, err := repo.Find(ctx, "AAPL", "1day", 1)
+	require.NoError(t, err)
+	mr.FastForward(9 * time.Minute)
+	_, err = repo.Find(ctx, "AAPL", "1day", 1)
+	require.NoError(t, err)
+
+	// 再取得中に ingest がキーを削除した
+	mr.Del(key)
+	close(release)
+	repo.refresh.wg.Wait()
+
+	assert.False(t, mr.Exists(key))
+	assert.Equal(t, RefreshAheadStats{Triggered: 1}, repo.RefreshAheadStats())
+}
+
+func TestCachingCandleRepository_WithRefreshAhead_Disabled(t *testing.T) {
+	t.Parallel()
+
+	for _, threshold := range []float64{0, -0.1, 1, 1.5} {
+		repo := NewCachingRepository(nil, time.Minute, &mockReadWriteRepository{}, "", nil).
+			WithRefreshAhead(RefreshAheadConfig{Threshold: threshold})
+		assert.Nil(t, repo.refresh, "threshold %v", threshold)
+	}
+}
@@ -1,82 +1,300 @@
 package candles
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
 
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles/sqlc"
 )
 
+// pgQueryCanceled は statement_timeout 超過等でステートメントが取り消された場合の SQLSTATE です。
+const pgQueryCanceled = "57014"
+
+// DefaultQueryTimeout は読み取りクエリ（Find / FindAsOf）の実行時間の上限のデフォルトです。
+const DefaultQueryTimeout = 5 * time.Second
+
 // dbRepository は Repository / WriteRepository の sqlc + 生 SQL 実装です。
 // Find は sqlc 生成クエリを使用し、UpsertBatch は単発で大量の INSERT ... ON CONFLICT を
 // 1 ステートメントにまとめて発行するため raw SQL を組み立てます（sqlc では多値 VALUES の
 // ON CONFLICT を 1 クエリで表現しにくいため）。
 type dbRepository struct {
-	db *sql.DB
-	q  *candlessqlc.Queries
+	db           *sql.DB
+	q            *candlessqlc.Queries
+	queryTimeout time.Duration     // Find / FindAsOf の statement_timeout（0 なら設定しない）
+	events       UpsertEventWriter // UpsertBatch と同じトランザクションで登録するイベント（nil なら登録しない）
+	precedence   SourcePrecedence  // UpsertBatch で保存済みの足を上書きする取り込み元の優先順位
+}
+
+// upsertEventBars は UpsertBatch のイベントに含める、銘柄・時間間隔ごとの足の本数（時刻の新しい順）です。
+// アラートの評価に直前の足と最新の足の 2 本が必要です。
+const upsertEventBars = 2
+
+// UpsertEventWriter は UpsertBatch と同じトランザクションで、保存した足に伴うイベント（アラートの評価・
+// キャッシュの破棄等）を登録します（トランザクショナルアウトボックス。di.CandleOutbox が実装）。
+// 保存とイベントの登録は一緒にコミットされるため、保存の直後にプロセスが停止してもイベントは失われません。
+type UpsertEventWriter interface {
+	// CandlesUpserted は symbol の保存した足のうち、時間間隔ごとに新しい upsertEventBars 本（時刻の昇順）を受け取ります。
+	CandlesUpserted(ctx context.Context, tx *sql.Tx, symbol string, candles []Candle) error
 }
 
-var _ Repository = (*dbRepository)(nil)
+var (
+	_ Repository  = (*dbRepository)(nil)
+	_ AsOfFinder  = (*dbRepository)(nil)
+	_ EachFinder  = (*dbRepository)(nil)
+	_ RangeFinder = (*dbRepository)(nil)
+)
 
 // NewRepository は指定された *sql.DB で dbRepository の新しいインスタンスを生成します。
 func NewRepository(db *sql.DB) *dbRepository {
-	return &dbRepository{db: db, q: candlessqlc.New(db)}
+	return &dbRepository{db: db, q: candlessqlc.New(db), precedence: DefaultSourcePrecedence()}
+}
+
+// WithQueryTimeout は Find / FindAsOf の実行時間の上限を設定します。0 以下なら上限を設けません。
+// 上限を超えたクエリは PostgreSQL 側で打ち切られ、QueryTimeoutError（ErrQueryTimeout）を返します。
+// UpsertBatch 等の書き込みには適用しません（取り込みバッチの大量 Upsert を途中で打ち切らないため）。
+func (r *dbRepository) WithQueryTimeout(d time.Duration) *dbRepository {
+	r.queryTimeout = d
+	return r
+}
+
+// WithUpsertEvents は UpsertBatch で保存した銘柄ごとのイベントを w で同じトランザクションに登録します。
+// w の失敗は保存ごとロールバックし、UpsertBatch のエラーとして返します。
+func (r *dbRepository) WithUpsertEvents(w UpsertEventWriter) *dbRepository {
+	r.events = w
+	return r
+}
+
+// WithSourcePrecedence は UpsertBatch で保存済みの足を上書きする取り込み元の優先順位を設定します。
+// 空の場合は DefaultSourcePrecedence のままです。
+func (r *dbRepository) WithSourcePrecedence(p SourcePrecedence) *dbRepository {
+	if len(p) > 0 {
+		r.precedence = p
+	}
+	return r
+}
+
+// read は queryTimeout > 0 のとき、読み取り専用トランザクション内で statement_timeout を設定してから fn を実行します。
+// set_config の第 3 引数 true（SET LOCAL 相当）によりトランザクション終了時に元へ戻るため、
+// プールに返した接続を使う他のクエリ（UpsertBatch 等）には影響しません。
+func (r *dbRepository) read(ctx context.Context, fn func(q *candlessqlc.Queries) error) error {
+	if r.queryTimeout <= 0 {
+		return fn(r.q)
+	}
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	ms := max(r.queryTimeout.Milliseconds(), 1) // 0 は「上限なし」になるため最小 1ms
+	if _, err := tx.ExecContext(ctx, "SELECT set_config('statement_timeout', $1, true)", strconv.FormatInt(ms, 10)); err != nil {
+		return fmt.Errorf("set statement_timeout: %w", err)
+	}
+	if err := fn(r.q.WithTx(tx)); err != nil {
+		return r.mapTimeout(ctx, err)
+	}
+	return tx.Commit()
+}
+
+// mapTimeout は statement_timeout による打ち切りを QueryTimeoutError に変換します。
+// 呼び出し元のコンテキストが終了している場合（クライアント切断等）は打ち切りの原因が異なるため変換しません。
+func (r *dbRepository) mapTimeout(ctx context.Context, err error) error {
+	var pgErr *pgconn.PgError
+	if ctx.Err() == nil && errors.As(err, &pgErr) && pgErr.Code == pgQueryCanceled {
+		return &QueryTimeoutError{Timeout: r.queryTimeout}
+	}
+	return err
 }
 
+// upsertCandleConflict は既存の足の OHLCV・取り込み元を上書きします。created_at は挿入時の値を保持し、
+// updated_at は値が変わった場合のみ進めます（同じ値の再取り込みで as-of クエリから足が消えないように）。
+// 上書きは SourcePrecedence.Overwrites と同じく、取り込み元の種別の優先順位が保存済みの足と同じか高い場合のみ行います。
+// %[1]s は優先順位（text[]、先頭ほど高い）のプレースホルダーで、並びにない種別は最も低い順位（要素数 + 1）です。
 const upsertCandleConflict = `
 ON CONFLICT (symbol_code, "interval", "time") DO UPDATE
 SET open = EXCLUDED.open,
     high = EXCLUDED.high,
     low = EXCLUDED.low,
     close = EXCLUDED.close,
-    volume = EXCLUDED.volume`
+    volume = EXCLUDED.volume,
+    source = EXCLUDED.source,
+    updated_at = CASE
+        WHEN (candles.open, candles.high, candles.low, candles.close, candles.volume)
+             IS DISTINCT FROM (EXCLUDED.open, EXCLUDED.high, EXCLUDED.low, EXCLUDED.close, EXCLUDED.volume)
+        THEN now()
+        ELSE candles.updated_at
+    END
+WHERE COALESCE(array_position(%[1]s::text[], split_part(EXCLUDED.source, ':', 1)), cardinality(%[1]s::text[]) + 1)
+   <= COALESCE(array_position(%[1]s::text[], split_part(candles.source, ':', 1)), cardinality(%[1]s::text[]) + 1)`
+
+// upsertCandleStats は Upsert した行を挿入（xmax = 0）と上書きに分けて数えます。
+// 値の変わった行は updated_at がこのトランザクションの now() になった行として数えます（挿入も含む）。
+const upsertCandleStats = `
+RETURNING (xmax = 0) AS inserted, (updated_at = now()) AS changed)
+SELECT count(*) FILTER (WHERE inserted), count(*) FILTER (WHERE NOT inserted), count(*) FILTER (WHERE changed) FROM upserted`
 
-// UpsertBatch はローソク足データをバッチで挿入または更新します。
+// UpsertBatch はローソク足データをバッチで挿入または更新し、新規挿入・上書き・値の変わった行数を返します。
 // (symbol_code, interval, time) の複合 UNIQUE をキーに ON CONFLICT DO UPDATE で
 // OHLCV を上書きします。1 ステートメントで全件処理するため round-trip は 1 回です。
-func (r *dbRepository) UpsertBatch(ctx context.Context, candles []Candle) error {
+//
+// PostgreSQL の INSERT ... ON CONFLICT DO UPDATE は挿入・上書きのどちらも影響行数 1 と数えるため
+// （MySQL の ON DUPLICATE KEY UPDATE のように上書きを 2 と数えない）、RowsAffected では区別できません。
+// 代わりに RETURNING で各行の xmax を返し、xmax = 0（このトランザクションで新規に作られた行）を挿入として集計します。
+//
+// 取り込み元の優先順位（WithSourcePrecedence）が保存済みの足より低い足は上書きせず、Updated にも数えません。
+// 取り込み元が空の足は SourceTwelveData として保存します。
+func (r *dbRepository) UpsertBatch(ctx context.Context, candles []Candle) (UpsertStats, error) {
 	if len(candles) == 0 {
-		return nil
+		return UpsertStats{}, nil
 	}
 
 	var sb strings.Builder
-	sb.WriteString(`INSERT INTO candles (symbol_code, "interval", "time", open, high, low, close, volume) VALUES `)
-	args := make([]any, 0, len(candles)*8)
+	sb.WriteString(`WITH upserted AS (INSERT INTO candles (symbol_code, "interval", "time", open, high, low, close, volume, source) VALUES `)
+	args := make([]any, 0, len(candles)*9+1)
 	for i, c := range candles {
+		source := cmp.Or(c.Source, SourceTwelveData)
+		if len(source) > maxSourceLength {
+			return UpsertStats{}, fmt.Errorf("upsert candles: source %q is longer than %d bytes", source, maxSourceLength)
+		}
 		if i > 0 {
 			sb.WriteString(", ")
 		}
-		off := i * 8
-		fmt.Fprintf(&sb, "($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
-			off+1, off+2, off+3, off+4, off+5, off+6, off+7, off+8)
+		off := i * 9
+		fmt.Fprintf(&sb, "($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+			off+1, off+2, off+3, off+4, off+5, off+6, off+7, off+8, off+9)
 		args = append(args,
 			c.SymbolCode, c.Interval, c.Time,
-			c.Open, c.High, c.Low, c.Close, c.Volume,
+			c.Open, c.High, c.Low, c.Close, c.Volume, source,
 		)
 	}
-	sb.WriteString(upsertCandleConflict)
+	fmt.Fprintf(&sb, upsertCandleConflict, fmt.Sprintf("$%d", len(args)+1))
+	args = append(args, []string(r.precedence))
+	sb.WriteString(upsertCandleStats)
+
+	var stats UpsertStats
+	if r.events == nil {
+		if err := r.db.QueryRowContext(ctx, sb.String(), args...).Scan(&stats.Inserted, &stats.Updated, &stats.Changed); err != nil {
+			return UpsertStats{}, fmt.Errorf("upsert candles: %w", err)
+		}
+		return stats, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return UpsertStats{}, fmt.Errorf("begin upsert candles: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	if err := tx.QueryRowContext(ctx, sb.String(), args...).Scan(&stats.Inserted, &stats.Updated, &stats.Changed); err != nil {
+		return UpsertStats{}, fmt.Errorf("upsert candles: %w", err)
+	}
+	for _, symbol := range upsertedSymbols(candles) {
+		if err := r.events.CandlesUpserted(ctx, tx, symbol, latestBars(candles, symbol, upsertEventBars)); err != nil {
+			return UpsertStats{}, fmt.Errorf("record upsert events %s: %w", symbol, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return UpsertStats{}, fmt.Errorf("commit upsert candles: %w", err)
+	}
+	return stats, nil
+}
+
+// upsertedSymbols は candles に含まれる銘柄コードを最初に現れた順に返します。
+func upsertedSymbols(candles []Candle) []string {
+	var symbols []string
+	seen := make(map[string]struct{})
+	for _, c := range candles {
+		if _, ok := seen[c.SymbolCode]; !ok {
+			seen[c.SymbolCode] = struct{}{}
+			symbols = append(symbols, c.SymbolCode)
+		}
+	}
+	return symbols
+}
 
-	if _, err := r.db.ExecContext(ctx, sb.String(), args...); err != nil {
-		return fmt.Errorf("upsert candles: %w", err)
+// latestBars は candles のうち symbol の足を時間間隔ごとに新しい n 本まで選び、時間間隔・時刻の昇順で返します。
+func latestBars(candles []Candle, symbol string, n int) []Candle {
+	var out []Candle
+	for _, c := range candles {
+		if c.SymbolCode == symbol {
+			out = append(out, c)
+		}
 	}
-	return nil
+	slices.SortFunc(out, func(a, b Candle) int {
+		return cmp.Or(cmp.Compare(a.Interval, b.Interval), b.Time.Compare(a.Time))
+	})
+	var kept []Candle
+	for i, c := range out {
+		if i >= n && out[i-n].Interval == c.Interval {
+			continue // 同じ時間間隔の新しい n 本を既に選んだ
+		}
+		kept = append(kept, c)
+	}
+	slices.SortFunc(kept, func(a, b Candle) int {
+		return cmp.Or(cmp.Compare(a.Interval, b.Interval), a.Time.Compare(b.Time))
+	})
+	return kept
+}
+
+// FindLatest は指定された銘柄とインターバルの最新のローソク足を返します。データがない場合は nil を返します。
+func (r *dbRepository) FindLatest(ctx context.Context, symbol, interval string) (*Candle, error) {
+	cs, err := r.Find(ctx, symbol, interval, 1)
+	if err != nil || len(cs) == 0 {
+		return nil, err
+	}
+	return &cs[0], nil
 }
 
 // Find は指定された銘柄とインターバルのローソク足データを取得します。
 // 結果は時間の降順でソートされ、outputsize > 0 のときのみ件数で制限されます。
+// 同じ時間の行（UNIQUE インデックス導入前の重複）は id の降順で並べるため、同じデータに対する結果の順序は常に同じです。
+// WithQueryTimeout の上限を超えた場合は QueryTimeoutError を返します。
 func (r *dbRepository) Find(ctx context.Context, symbol, interval string, outputsize int) ([]Candle, error) {
-	if outputsize > 0 {
-		rows, err := r.q.FindCandlesLimit(ctx, candlessqlc.FindCandlesLimitParams{
+	tr := TraceFromContext(ctx)
+	defer tr.End(TraceDBQuery, tr.Begin())
+
+	var out []Candle
+	err := r.read(ctx, func(q *candlessqlc.Queries) error {
+		if outputsize > 0 {
+			rows, err := q.FindCandlesLimit(ctx, candlessqlc.FindCandlesLimitParams{
+				SymbolCode: symbol,
+				Interval:   interval,
+				Limit:      int32(outputsize),
+			})
+			if err != nil {
+				return err
+			}
+			out = make([]Candle, 0, len(rows))
+			for _, row := range rows {
+				out = append(out, Candle{
+					SymbolCode: row.SymbolCode,
+					Interval:   row.Interval,
+					Time:       row.Time,
+					Open:       row.Open,
+					High:       row.High,
+					Low:        row.Low,
+					Close:      row.Close,
+					Volume:     row.Volume,
+				})
+			}
+			return nil
+		}
+		rows, err := q.FindCandlesAll(ctx, candlessqlc.FindCandlesAllParams{
 			SymbolCode: symbol,
 			Interval:   interval,
-			Limit:      int32(outputsize),
 		})
 		if err != nil {
-			return nil, err
+			return err
 		}
-		out := make([]Candle, 0, len(rows))
+		out = make([]Candle, 0, len(rows))
 		for _, row := range rows {
 			out = append(out, Candle{
 				SymbolCode: row.SymbolCode,
@@ -89,27 +307,197 @@ func (r *dbRepository) Find(ctx context.Context, symbol, interval string, output
 				Volume:     row.Volume,
 			})
 		}
-		return out, nil
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	rows, err := r.q.FindCandlesAll(ctx, candlessqlc.FindCandlesAllParams{
-		SymbolCode: symbol,
-		Interval:   interval,
+	return out, nil
+}
+
+// FindAsOf は asOf 時点で保存されていたローソク足を時間の降順（同じ時間は id の降順）で最大 outputsize 件返します。
+// asOf より後に値が書き換わった足は当時の値が残っていないため結果から除外します（履歴は保持しません）。
+func (r *dbRepository) FindAsOf(ctx context.Context, symbol, interval string, asOf time.Time, outputsize int) ([]Candle, error) {
+	var out []Candle
+	err := r.read(ctx, func(q *candlessqlc.Queries) error {
+		rows, err := q.FindCandlesAsOf(ctx, candlessqlc.FindCandlesAsOfParams{
+			SymbolCode: symbol,
+			Interval:   interval,
+			AsOf:       asOf,
+			MaxRows:    int32(outputsize),
+		})
+		if err != nil {
+			return err
+		}
+		out = make([]Candle, 0, len(rows))
+		for _, row := range rows {
+			out = append(out, Candle{
+				SymbolCode: row.SymbolCode,
+				Interval:   row.Interval,
+				Time:       row.Time,
+				Open:       row.Open,
+				High:       row.High,
+				Low:        row.Low,
+				Close:      row.Close,
+				Volume:     row.Volume,
+				Source:     row.Source,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FindRange は rng に含まれるローソク足を時間の降順（同じ時間は id の降順）で最大 outputsize 件返します。
+// outputsize が 0 以下の場合は MaxOutputSize 件までです。WithQueryTimeout の上限は Find と同じく適用します。
+func (r *dbRepository) FindRange(ctx context.Context, symbol, interval string, rng DateRange, outputsize int) ([]Candle, error) {
+	tr := TraceFromContext(ctx)
+	defer tr.End(TraceDBQuery, tr.Begin())
+
+	if outputsize <= 0 {
+		outputsize = MaxOutputSize
+	}
+	var out []Candle
+	err := r.read(ctx, func(q *candlessqlc.Queries) error {
+		rows, err := q.FindCandlesRange(ctx, candlessqlc.FindCandlesRangeParams{
+			SymbolCode: symbol,
+			Interval:   interval,
+			HasStart:   !rng.Start.IsZero(),
+			StartTime:  rng.Start,
+			HasEnd:     !rng.End.IsZero(),
+			EndBefore:  rng.endBefore(),
+			MaxRows:    int32(outputsize),
+		})
+		if err != nil {
+			return err
+		}
+		out = make([]Candle, 0, len(rows))
+		for _, row := range rows {
+			out = append(out, Candle{
+				SymbolCode: row.SymbolCode,
+				Interval:   row.Interval,
+				Time:       row.Time,
+				Open:       row.Open,
+				High:       row.High,
+				Low:        row.Low,
+				Close:      row.Close,
+				Volume:     row.Volume,
+			})
+		}
+		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
-	out := make([]Candle, 0, len(rows))
-	for _, row := range rows {
-		out = append(out, Candle{
-			SymbolCode: row.SymbolCode,
-			Interval:   row.Interval,
-			Time:       row.Time,
-			Open:       row.Open,
-			High:       row.High,
-			Low:        row.Low,
-			Close:      row.Close,
-			Volume:     row.Volume,
+	return out, nil
+}
+
+// Inspect は保存済みのローソク足を取り込み元・書き込み日時とともに時間の降順（同じ時間は id の降順）で最大 limit 件返します。
+// 管理用の調査（値の食い違いの原因の特定）に使います。キャッシュを経由しません。
+func (r *dbRepository) Inspect(ctx context.Context, symbol, interval string, limit int) ([]StoredCandle, error) {
+	var out []StoredCandle
+	err := r.read(ctx, func(q *candlessqlc.Queries) error {
+		rows, err := q.InspectCandles(ctx, candlessqlc.InspectCandlesParams{
+			SymbolCode: symbol,
+			Interval:   interval,
+			MaxRows:    int32(limit),
 		})
+		if err != nil {
+			return err
+		}
+		out = make([]StoredCandle, 0, len(rows))
+		for _, row := range rows {
+			out = append(out, StoredCandle{
+				ID: row.ID,
+				Candle: Candle{
+					SymbolCode: symbol,
+					Interval:   interval,
+					Time:       row.Time,
+					Open:       row.Open,
+					High:       row.High,
+					Low:        row.Low,
+					Close:      row.Close,
+					Volume:     row.Volume,
+					Source:     row.Source,
+				},
+				CreatedAt: row.CreatedAt,
+				UpdatedAt: row.UpdatedAt,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return out, nil
 }
+
+// FindEach は opts の足を BatchSize 件ずつ読み込み、1 件ずつ fn に渡します（EachFinder の実装）。
+// 各ページは直前のページの最後の (time, id) より前の足をキーセットで読むため、件数が多くてもメモリー使用量は
+// ページの大きさで一定です。WithQueryTimeout の上限はページごとのクエリに適用します。
+func (r *dbRepository) FindEach(ctx context.Context, opts FindOptions, fn func(Candle) error) error {
+	params := candlessqlc.FindCandlesPageParams{SymbolCode: opts.Symbol, Interval: opts.Interval}
+	read := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		size := opts.batchSize(read)
+		if size <= 0 {
+			return nil
+		}
+		params.BatchSize = int32(size)
+
+		var rows []candlessqlc.FindCandlesPageRow
+		err := r.read(ctx, func(q *candlessqlc.Queries) error {
+			var err error
+			rows, err = q.FindCandlesPage(ctx, params)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			err := fn(Candle{
+				SymbolCode: row.SymbolCode,
+				Interval:   row.Interval,
+				Time:       row.Time,
+				Open:       row.Open,
+				High:       row.High,
+				Low:        row.Low,
+				Close:      row.Close,
+				Volume:     row.Volume,
+			})
+			if errors.Is(err, ErrStop) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+		read += len(rows)
+		if len(rows) < size {
+			return nil
+		}
+		last := rows[len(rows)-1]
+		params.HasAfter, params.AfterTime, params.AfterID = true, last.Time, last.ID
+	}
+}
+
+// TimeRange は銘柄・時間間隔の保存済みローソク足の最古・最新の時刻を返します。足が 1 件もない場合は found=false です。
+func (r *dbRepository) TimeRange(ctx context.Context, symbol, interval string) (first, last time.Time, found bool, err error) {
+	row, err := r.q.FindCandleTimeRange(ctx, candlessqlc.FindCandleTimeRangeParams{
+		SymbolCode: symbol,
+		Interval:   interval,
+	})
+	if err != nil {
+		return time.Time{}, time.Time{}, false, err
+	}
+	if row.N == 0 {
+		return time.Time{}, time.Time{}, false, nil
+	}
+	return row.FirstTime, row.LastTime, true, nil
+}
@@ -3,15 +3,19 @@ package candles
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"log"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/db/dbtest"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/testsupport"
 )
 
 func TestMain(m *testing.M) {
@@ -24,27 +28,8 @@ func TestMain(m *testing.M) {
 
 func setupTestDB(t *testing.T) *sql.DB {
 	t.Helper()
-	db := dbtest.OpenIsolatedDB(t)
 	// candles は symbols.code への FK 制約があるため、テスト用に必要な銘柄をあらかじめ作成する。
-	_, err := db.ExecContext(context.Background(),
-		`INSERT INTO symbols (code, name, market, timezone) VALUES
-		   ('AAPL', 'Apple Inc.', 'NASDAQ', 'America/New_York'),
-		   ('GOOGL', 'Alphabet Inc.', 'NASDAQ', 'America/New_York'),
-		   ('NOTFOUND', 'Placeholder', 'TEST', 'UTC')
-		 ON CONFLICT (code) DO NOTHING`)
-	require.NoError(t, err)
-	return db
-}
-
-// seedCandle はテスト用のローソク足データをデータベースに作成します。
-func seedCandle(t *testing.T, db *sql.DB, symbol, interval string, ts time.Time) {
-	t.Helper()
-	_, err := db.ExecContext(context.Background(),
-		`INSERT INTO candles (symbol_code, "interval", "time", open, high, low, close, volume)
-		 VALUES ($1, $2, $3, 100.0, 110.0, 90.0, 105.0, 1000)`,
-		symbol, interval, ts,
-	)
-	require.NoError(t, err, "failed to seed candle")
+	return testsupport.NewDB(t, testsupport.InsertSymbols("AAPL", "GOOGL", "NOTFOUND"))
 }
 
 // candleCount は candles テーブルの行数を返します。
@@ -72,6 +57,7 @@ func TestCandleRepository_UpsertBatch(t *testing.T) {
 		name         string
 		candles      []Candle
 		setupFunc    func(t *testing.T, db *sql.DB)
+		wantStats    UpsertStats
 		validateFunc func(t *testing.T, db *sql.DB)
 	}{
 		{
@@ -79,6 +65,7 @@ func TestCandleRepository_UpsertBatch(t *testing.T) {
 			candles: []Candle{
 				{SymbolCode: "AAPL", Interval: "1day", Time: baseTime, Open: 100, High: 110, Low: 90, Close: 105, Volume: 1000},
 			},
+			wantStats: UpsertStats{Inserted: 1, Changed: 1},
 			validateFunc: func(t *testing.T, db *sql.DB) {
 				assert.Equal(t, int64(1), candleCount(t, db))
 			},
@@ -89,6 +76,7 @@ func TestCandleRepository_UpsertBatch(t *testing.T) {
 				{SymbolCode: "AAPL", Interval: "1day", Time: baseTime, Open: 100, High: 110, Low: 90, Close: 105, Volume: 1000},
 				{SymbolCode: "AAPL", Interval: "1day", Time: baseTime.AddDate(0, 0, 1), Open: 105, High: 115, Low: 95, Close: 110, Volume: 1500},
 			},
+			wantStats: UpsertStats{Inserted: 2, Changed: 2},
 			validateFunc: func(t *testing.T, db *sql.DB) {
 				assert.Equal(t, int64(2), candleCount(t, db))
 			},
@@ -106,8 +94,9 @@ func TestCandleRepository_UpsertBatch(t *testing.T) {
 				{SymbolCode: "AAPL", Interval: "1day", Time: baseTime, Open: 200, High: 220, Low: 180, Close: 210, Volume: 2000},
 			},
 			setupFunc: func(t *testing.T, db *sql.DB) {
-				seedCandle(t, db, "AAPL", "1day", baseTime)
+				testsupport.InsertCandle(t, db, "AAPL", baseTime)
 			},
+			wantStats: UpsertStats{Updated: 1, Changed: 1},
 			validateFunc: func(t *testing.T, db *sql.DB) {
 				assert.Equal(t, int64(1), candleCount(t, db))
 				var o, h, l, c float64
@@ -128,8 +117,9 @@ func TestCandleRepository_UpsertBatch(t *testing.T) {
 				{SymbolCode: "AAPL", Interval: "1day", Time: baseTime.AddDate(0, 0, 1), Open: 210, High: 230, Low: 190, Close: 220, Volume: 2500},
 			},
 			setupFunc: func(t *testing.T, db *sql.DB) {
-				seedCandle(t, db, "AAPL", "1day", baseTime)
+				testsupport.InsertCandle(t, db, "AAPL", baseTime)
 			},
+			wantStats: UpsertStats{Inserted: 1, Updated: 1, Changed: 2},
 			validateFunc: func(t *testing.T, db *sql.DB) {
 				assert.Equal(t, int64(2), candleCount(t, db))
 			},
@@ -144,7 +134,9 @@ func TestCandleRepository_UpsertBatch(t *testing.T) {
 			if tt.setupFunc != nil {
 				tt.setupFunc(t, db)
 			}
-			require.NoError(t, repo.UpsertBatch(context.Background(), tt.candles))
+			stats, err := repo.UpsertBatch(context.Background(), tt.candles)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStats, stats)
 			if tt.validateFunc != nil {
 				tt.validateFunc(t, db)
 			}
@@ -167,8 +159,8 @@ func TestCandleRepository_Find(t *testing.T) {
 		{
 			name: "success: find candles by symbol and interval", symbol: "AAPL", interval: "1day", outputsize: 10,
 			setupFunc: func(t *testing.T, db *sql.DB) {
-				seedCandle(t, db, "AAPL", "1day", baseTime)
-				seedCandle(t, db, "AAPL", "1day", baseTime.AddDate(0, 0, 1))
+				testsupport.InsertCandle(t, db, "AAPL", baseTime)
+				testsupport.InsertCandle(t, db, "AAPL", baseTime.AddDate(0, 0, 1))
 			},
 			validateFunc: func(t *testing.T, candles []Candle) {
 				assert.Len(t, candles, 2)
@@ -183,8 +175,8 @@ func TestCandleRepository_Find(t *testing.T) {
 		{
 			name: "success: filter by symbol only", symbol: "AAPL", interval: "1day", outputsize: 10,
 			setupFunc: func(t *testing.T, db *sql.DB) {
-				seedCandle(t, db, "AAPL", "1day", baseTime)
-				seedCandle(t, db, "GOOGL", "1day", baseTime)
+				testsupport.InsertCandle(t, db, "AAPL", baseTime)
+				testsupport.InsertCandle(t, db, "GOOGL", baseTime)
 			},
 			validateFunc: func(t *testing.T, candles []Candle) {
 				assert.Len(t, candles, 1)
@@ -194,8 +186,8 @@ func TestCandleRepository_Find(t *testing.T) {
 		{
 			name: "success: filter by interval", symbol: "AAPL", interval: "1day", outputsize: 10,
 			setupFunc: func(t *testing.T, db *sql.DB) {
-				seedCandle(t, db, "AAPL", "1day", baseTime)
-				seedCandle(t, db, "AAPL", "1week", baseTime)
+				testsupport.InsertCandle(t, db, "AAPL", baseTime)
+				testsupport.InsertCandle(t, db, "AAPL", baseTime, testsupport.WithInterval("1week"))
 			},
 			validateFunc: func(t *testing.T, candles []Candle) {
 				assert.Len(t, candles, 1)
@@ -206,7 +198,7 @@ func TestCandleRepository_Find(t *testing.T) {
 			name: "success: respect outputsize limit", symbol: "AAPL", interval: "1day", outputsize: 2,
 			setupFunc: func(t *testing.T, db *sql.DB) {
 				for i := 0; i < 5; i++ {
-					seedCandle(t, db, "AAPL", "1day", baseTime.AddDate(0, 0, i))
+					testsupport.InsertCandle(t, db, "AAPL", baseTime.AddDate(0, 0, i))
 				}
 			},
 			validateFunc: func(t *testing.T, candles []Candle) {
@@ -217,7 +209,7 @@ func TestCandleRepository_Find(t *testing.T) {
 			name: "success: outputsize 0 returns all", symbol: "AAPL", interval: "1day", outputsize: 0,
 			setupFunc: func(t *testing.T, db *sql.DB) {
 				for i := 0; i < 5; i++ {
-					seedCandle(t, db, "AAPL", "1day", baseTime.AddDate(0, 0, i))
+					testsupport.InsertCandle(t, db, "AAPL", baseTime.AddDate(0, 0, i))
 				}
 			},
 			validateFunc: func(t *testing.T, candles []Candle) {
@@ -227,9 +219,9 @@ func TestCandleRepository_Find(t *testing.T) {
 		{
 			name: "success: results ordered by time descending", symbol: "AAPL", interval: "1day", outputsize: 10,
 			setupFunc: func(t *testing.T, db *sql.DB) {
-				seedCandle(t, db, "AAPL", "1day", baseTime)
-				seedCandle(t, db, "AAPL", "1day", baseTime.AddDate(0, 0, 2))
-				seedCandle(t, db, "AAPL", "1day", baseTime.AddDate(0, 0, 1))
+				testsupport.InsertCandle(t, db, "AAPL", baseTime)
+				testsupport.InsertCandle(t, db, "AAPL", baseTime.AddDate(0, 0, 2))
+				testsupport.InsertCandle(t, db, "AAPL", baseTime.AddDate(0, 0, 1))
 			},
 			validateFunc: func(t *testing.T, candles []Candle) {
 				assert.Len(t, candles, 3)
@@ -280,3 +272,576 @@ func TestCandleRepository_Find_EntityMapping(t *testing.T) {
 	assert.Equal(t, 154.0, result[0].Close)
 	assert.Equal(t, int64(5000000), result[0].Volume)
 }
+
+// TestCandleRepository_Find_TieBreak は同じ時間の行（UNIQUE インデックス導入前の重複）が
+// id の降順で並び、Find / FindAsOf を繰り返しても順序が変わらないことを検証します。
+func TestCandleRepository_Find_TieBreak(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	_, err := db.ExecContext(ctx, `DROP INDEX candle_sym_int_time`)
+	require.NoError(t, err)
+	day1 := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	// id の順が時間の順と一致しないよう、2 日分を交互に挿入する（open で行を識別する）
+	for _, row := range []struct {
+		ts   time.Time
+		open float64
+	}{
+		{day2, 1}, {day1, 4}, {day2, 2}, {day1, 5}, {day2, 3},
+	} {
+		_, err := db.ExecContext(ctx,
+			`INSERT INTO candles (symbol_code, "interval", "time", open, high, low, close, volume)
+			 VALUES ('AAPL', '1day', $1, $2, 110.0, 90.0, 105.0, 1000)`, row.ts, row.open)
+		require.NoError(t, err)
+	}
+	opens := func(cs []Candle) []float64 {
+		out := make([]float64, 0, len(cs))
+		for _, c := range cs {
+			out = append(out, c.Open)
+		}
+		return out
+	}
+	want := []float64{3, 2, 1, 5, 4}
+
+	for range 5 {
+		all, err := repo.Find(ctx, "AAPL", "1day", 0)
+		require.NoError(t, err)
+		assert.Equal(t, want, opens(all))
+
+		limited, err := repo.Find(ctx, "AAPL", "1day", 2)
+		require.NoError(t, err)
+		assert.Equal(t, want[:2], opens(limited), "LIMIT でも同じ順序の先頭を返す")
+
+		asOf, err := repo.FindAsOf(ctx, "AAPL", "1day", time.Now().Add(time.Hour), 10)
+		require.NoError(t, err)
+		assert.Equal(t, want, opens(asOf))
+	}
+}
+
+// TestCandleRepository_UpsertBatch_WriteTimes は上書き時に created_at を保持し、
+// updated_at は値が変わった場合のみ進めることを検証します。
+func TestCandleRepository_UpsertBatch_WriteTimes(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	day1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	written := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := repo.UpsertBatch(ctx, []Candle{
+		{SymbolCode: "AAPL", Interval: "1day", Time: day1, Open: 100, High: 110, Low: 90, Close: 105, Volume: 1000},
+		{SymbolCode: "AAPL", Interval: "1day", Time: day2, Open: 105, High: 115, Low: 95, Close: 110, Volume: 2000},
+	})
+	require.NoError(t, err)
+	// 書き込み日時を既知の値に固定する
+	_, err = db.ExecContext(ctx, `UPDATE candles SET created_at = $1, updated_at = $1`, written)
+	require.NoError(t, err)
+
+	// day1 は同じ値で再取り込み、day2 は値を書き換える
+	stats, err := repo.UpsertBatch(ctx, []Candle{
+		{SymbolCode: "AAPL", Interval: "1day", Time: day1, Open: 100, High: 110, Low: 90, Close: 105, Volume: 1000},
+		{SymbolCode: "AAPL", Interval: "1day", Time: day2, Open: 105, High: 115, Low: 95, Close: 111, Volume: 2000},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, UpsertStats{Updated: 2, Changed: 1}, stats, "値の変わった day2 のみ Changed に数える")
+
+	writeTimes := func(ts time.Time) (created, updated time.Time) {
+		t.Helper()
+		require.NoError(t, db.QueryRowContext(ctx,
+			`SELECT created_at, updated_at FROM candles WHERE symbol_code = 'AAPL' AND "time" = $1`, ts,
+		).Scan(&created, &updated))
+		return created, updated
+	}
+	created, updated := writeTimes(day1)
+	assert.True(t, created.Equal(written), "created_at is preserved")
+	assert.True(t, updated.Equal(written), "same values do not advance updated_at")
+
+	created, updated = writeTimes(day2)
+	assert.True(t, created.Equal(written), "created_at is preserved on rewrite")
+	assert.True(t, updated.After(written), "rewrite advances updated_at")
+}
+
+// TestCandleRepository_UpsertBatch_SourcePrecedence は取り込み元の優先順位が保存済みの足と同じか高い場合のみ上書きし、
+// 低い場合は値・取り込み元・書き込み日時を保持することを検証します。
+func TestCandleRepository_UpsertBatch_SourcePrecedence(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	ctx := context.Background()
+	day1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	day3 := day1.AddDate(0, 0, 2)
+	bar := func(ts time.Time, close float64, source string) Candle {
+		return Candle{SymbolCode: "AAPL", Interval: "1week", Time: ts, Open: 100, High: 120, Low: 90, Close: close, Volume: 1000, Source: source}
+	}
+	stored := func(ts time.Time) (close float64, source string) {
+		t.Helper()
+		require.NoError(t, db.QueryRowContext(ctx,
+			`SELECT close, source FROM candles WHERE symbol_code = 'AAPL' AND "time" = $1`, ts,
+		).Scan(&close, &source))
+		return close, source
+	}
+
+	repo := NewRepository(db) // twelvedata > csv > derived
+	stats, err := repo.UpsertBatch(ctx, []Candle{
+		bar(day1, 100, SourceTwelveData),
+		bar(day2, 100, SourceDerived),
+		bar(day3, 100, ""), // 空は twelvedata
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats.Inserted)
+
+	stats, err = repo.UpsertBatch(ctx, []Candle{
+		bar(day1, 101, SourceDerived),      // 低い: 保持
+		bar(day2, 102, "csv:1a2b3c4d5e6f"), // 高い: 上書き
+		bar(day3, 103, SourceTwelveData),   // 同じ: 上書き
+	})
+	require.NoError(t, err)
+	assert.Equal(t, UpsertStats{Updated: 2, Changed: 2}, stats, "保持した足は上書きに数えない")
+
+	close, source := stored(day1)
+	assert.Equal(t, 100.0, close)
+	assert.Equal(t, SourceTwelveData, source)
+	close, source = stored(day2)
+	assert.Equal(t, 102.0, close)
+	assert.Equal(t, "csv:1a2b3c4d5e6f", source)
+	close, source = stored(day3)
+	assert.Equal(t, 103.0, close)
+	assert.Equal(t, SourceTwelveData, source)
+
+	// 優先順位を入れ替えると、CSV の足を TwelveData で上書きしない
+	csvFirst := NewRepository(db).WithSourcePrecedence(SourcePrecedence{SourceCSV, SourceTwelveData})
+	_, err = csvFirst.UpsertBatch(ctx, []Candle{bar(day2, 104, SourceTwelveData)})
+	require.NoError(t, err)
+	close, _ = stored(day2)
+	assert.Equal(t, 102.0, close)
+
+	// 並びにない種別は最も低く、並びにある種別を上書きしない
+	_, err = csvFirst.UpsertBatch(ctx, []Candle{bar(day2, 105, SourceSeed)})
+	require.NoError(t, err)
+	close, source = stored(day2)
+	assert.Equal(t, 102.0, close)
+	assert.Equal(t, "csv:1a2b3c4d5e6f", source)
+}
+
+// TestCandleRepository_Inspect は足を取り込み元・書き込み日時とともに時間の降順で返すことを検証します。
+func TestCandleRepository_Inspect(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	day1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	_, err := repo.UpsertBatch(ctx, []Candle{
+		{SymbolCode: "AAPL", Interval: "1day", Time: day1, Open: 100, High: 110, Low: 90, Close: 105, Volume: 1000, Source: SourceTwelveData},
+		{SymbolCode: "AAPL", Interval: "1day", Time: day2, Open: 105, High: 115, Low: 95, Close: 110, Volume: 2000, Source: "csv:1a2b3c4d5e6f"},
+	})
+	require.NoError(t, err)
+
+	got, err := repo.Inspect(ctx, "AAPL", "1day", 10)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.True(t, got[0].Time.Equal(day2), "時間の降順")
+	assert.Equal(t, "csv:1a2b3c4d5e6f", got[0].Source)
+	assert.Equal(t, SourceTwelveData, got[1].Source)
+	assert.Equal(t, "AAPL", got[0].SymbolCode)
+	assert.NotZero(t, got[0].ID)
+	assert.False(t, got[0].CreatedAt.IsZero())
+	assert.False(t, got[0].UpdatedAt.IsZero())
+
+	got, err = repo.Inspect(ctx, "AAPL", "1day", 1)
+	require.NoError(t, err)
+	assert.Len(t, got, 1, "limit で件数を制限する")
+}
+
+// recordingEventWriter は UpsertBatch のイベントを記録する UpsertEventWriter です。
+// 受け取った時点でトランザクション内に保存済みの足の件数も記録します。
+type recordingEventWriter struct {
+	symbols []string
+	candles map[string][]Candle
+	visible []int64
+	err     error
+}
+
+func (w *recordingEventWriter) CandlesUpserted(ctx context.Context, tx *sql.Tx, symbol string, cs []Candle) error {
+	var n int64
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM candles WHERE symbol_code = $1`, symbol).Scan(&n); err != nil {
+		return err
+	}
+	w.symbols = append(w.symbols, symbol)
+	w.candles[symbol] = cs
+	w.visible = append(w.visible, n)
+	return w.err
+}
+
+// TestCandleRepository_UpsertBatch_Events は保存と同じトランザクションで銘柄ごとのイベントを登録し、
+// 登録に失敗した場合は保存ごとロールバックすることを検証します。
+func TestCandleRepository_UpsertBatch_Events(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	ctx := context.Background()
+	day := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	batch := []Candle{
+		{SymbolCode: "AAPL", Interval: "1day", Time: day.AddDate(0, 0, -2), Close: 98},
+		{SymbolCode: "AAPL", Interval: "1day", Time: day, Close: 100},
+		{SymbolCode: "AAPL", Interval: "1day", Time: day.AddDate(0, 0, -1), Close: 99},
+		{SymbolCode: "GOOGL", Interval: "1day", Time: day, Close: 200},
+		{SymbolCode: "AAPL", Interval: "1week", Time: day.AddDate(0, 0, -2), Close: 100},
+	}
+
+	w := &recordingEventWriter{candles: map[string][]Candle{}}
+	stats, err := NewRepository(db).WithUpsertEvents(w).UpsertBatch(ctx, batch)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), stats.Inserted)
+
+	assert.Equal(t, []string{"AAPL", "GOOGL"}, w.symbols)
+	assert.Equal(t, []int64{4, 1}, w.visible, "events are written after the upsert in the same transaction")
+	// 時間間隔ごとに新しい 2 本を時刻の昇順で渡す
+	assert.Equal(t, []Candle{batch[2], batch[1], batch[4]}, w.candles["AAPL"])
+	assert.Equal(t, []Candle{batch[3]}, w.candles["GOOGL"])
+
+	// イベントを登録できなければ保存もしない
+	failing := &recordingEventWriter{candles: map[string][]Candle{}, err: errors.New("outbox unavailable")}
+	_, err = NewRepository(db).WithUpsertEvents(failing).UpsertBatch(ctx, []Candle{
+		{SymbolCode: "AAPL", Interval: "1day", Time: day.AddDate(0, 0, 1), Close: 101},
+	})
+	require.ErrorContains(t, err, "outbox unavailable")
+	assert.Equal(t, int64(5), candleCount(t, db))
+}
+
+// TestCandleRepository_FindAsOf は as_of 以降に書き換えられた足を除外することを検証します。
+func TestCandleRepository_FindAsOf(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	day1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	testsupport.InsertCandle(t, db, "AAPL", day1)
+	testsupport.InsertCandle(t, db, "AAPL", day2)
+	_, err := db.ExecContext(ctx, `UPDATE candles SET updated_at = $1 WHERE "time" = $2`, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), day1)
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `UPDATE candles SET updated_at = $1 WHERE "time" = $2`, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), day2)
+	require.NoError(t, err)
+
+	got, err := repo.FindAsOf(ctx, "AAPL", "1day", time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), 10)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.True(t, got[0].Time.Equal(day1))
+	assert.Equal(t, SourceTwelveData, got[0].Source, "as-of クエリは取り込み元を返す")
+
+	got, err = repo.FindAsOf(ctx, "AAPL", "1day", time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), 1)
+	require.NoError(t, err)
+	require.Len(t, got, 1, "outputsize で件数を制限する")
+	assert.True(t, got[0].Time.Equal(day2), "時間の降順")
+}
+
+// TestCandleRepository_FindRange は期間の両端の日を含み、範囲外の足を除外することを検証します。
+func TestCandleRepository_FindRange(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	insertDailyCandles(t, db, "AAPL", start, 10) // 2024-01-01〜01-10（close は 0〜9）
+
+	closes := func(cs []Candle) []float64 {
+		out := make([]float64, 0, len(cs))
+		for _, c := range cs {
+			out = append(out, c.Close)
+		}
+		return out
+	}
+
+	got, err := repo.FindRange(ctx, "AAPL", "1day", DateRange{Start: start.AddDate(0, 0, 2), End: start.AddDate(0, 0, 4)}, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{4, 3, 2}, closes(got), "両端の日を含み、時間の降順")
+
+	got, err = repo.FindRange(ctx, "AAPL", "1day", DateRange{Start: start.AddDate(0, 0, 7)}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{9, 8, 7}, closes(got), "終了日の指定がなければ最新まで")
+
+	got, err = repo.FindRange(ctx, "AAPL", "1day", DateRange{End: start.AddDate(0, 0, 5)}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{5, 4}, closes(got), "outputsize で件数を制限する")
+}
+
+// insertDailyCandles は symbol の日足を start から n 日分、1 ステートメントで挿入します（close は 0 から 1 ずつ増える）。
+func insertDailyCandles(tb testing.TB, db *sql.DB, symbol string, start time.Time, n int) {
+	tb.Helper()
+	_, err := db.ExecContext(context.Background(), `
+INSERT INTO candles (symbol_code, "interval", "time", open, high, low, close, volume)
+SELECT $1, '1day', $2::timestamptz + make_interval(days => i), 100, 110, 90, i, 1000
+FROM generate_series(0, $3 - 1) AS i`, symbol, start, n)
+	require.NoError(tb, err)
+}
+
+// collectEach は FindEach で渡された足の close を順に返します。
+func collectEach(t *testing.T, repo *dbRepository, opts FindOptions) []float64 {
+	t.Helper()
+	var out []float64
+	require.NoError(t, repo.FindEach(context.Background(), opts, func(c Candle) error {
+		out = append(out, c.Close)
+		return nil
+	}))
+	return out
+}
+
+// descending は n-1 から n-limit までの降順の close を返します（limit <= 0 なら 0 まで）。
+func descending(n, limit int) []float64 {
+	if limit <= 0 || limit > n {
+		limit = n
+	}
+	out := make([]float64, 0, limit)
+	for i := n - 1; i >= n-limit; i-- {
+		out = append(out, float64(i))
+	}
+	return out
+}
+
+// TestCandleRepository_FindEach はページの区切り（端数・ちょうど割り切れる件数・Limit の途中）をまたいでも
+// Find と同じ順序で全件を 1 回ずつ渡すことを検証します。
+func TestCandleRepository_FindEach(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	insertDailyCandles(t, db, "AAPL", start, 10)
+	testsupport.InsertCandle(t, db, "GOOGL", start)
+
+	tests := []struct {
+		name string
+		opts FindOptions
+		want []float64
+	}{
+		{"端数のあるページ", FindOptions{BatchSize: 3}, descending(10, 0)},
+		{"ページの大きさで割り切れる", FindOptions{BatchSize: 5}, descending(10, 0)},
+		{"全件が 1 ページ", FindOptions{BatchSize: 100}, descending(10, 0)},
+		{"デフォルトのページの大きさ", FindOptions{}, descending(10, 0)},
+		{"Limit がページの途中", FindOptions{BatchSize: 3, Limit: 7}, descending(10, 7)},
+		{"Limit がページの境目", FindOptions{BatchSize: 3, Limit: 6}, descending(10, 6)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Symbol, tt.opts.Interval = "AAPL", "1day"
+			assert.Equal(t, tt.want, collectEach(t, repo, tt.opts))
+		})
+	}
+
+	assert.Empty(t, collectEach(t, repo, FindOptions{Symbol: "NOTFOUND", Interval: "1day"}))
+
+	// 同じ時間の重複は Find と同じく id の降順で、ページの境目でも読み飛ばさない
+	_, err := db.ExecContext(context.Background(), `DROP INDEX candle_sym_int_time`)
+	require.NoError(t, err)
+	testsupport.InsertCandle(t, db, "GOOGL", start, func(r *testsupport.CandleRow) { r.Close = 1 })
+	testsupport.InsertCandle(t, db, "GOOGL", start, func(r *testsupport.CandleRow) { r.Close = 2 })
+	want, err := repo.Find(context.Background(), "GOOGL", "1day", 0)
+	require.NoError(t, err)
+	require.Len(t, want, 3)
+	var got []Candle
+	require.NoError(t, repo.FindEach(context.Background(), FindOptions{Symbol: "GOOGL", Interval: "1day", BatchSize: 1}, func(c Candle) error {
+		got = append(got, c)
+		return nil
+	}))
+	assert.Equal(t, want, got)
+}
+
+// TestCandleRepository_FindEach_Stop は ErrStop で残りを読まずに正常終了し、それ以外のエラーはそのまま返すことを検証します。
+func TestCandleRepository_FindEach_Stop(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	insertDailyCandles(t, db, "AAPL", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 10)
+	opts := FindOptions{Symbol: "AAPL", Interval: "1day", BatchSize: 3}
+
+	var seen int
+	require.NoError(t, repo.FindEach(context.Background(), opts, func(Candle) error {
+		seen++
+		if seen == 4 {
+			return ErrStop
+		}
+		return nil
+	}))
+	assert.Equal(t, 4, seen)
+
+	boom := errors.New("boom")
+	err := repo.FindEach(context.Background(), opts, func(Candle) error { return fmt.Errorf("wrapped: %w", boom) })
+	require.ErrorIs(t, err, boom)
+}
+
+// TestCandleRepository_FindEach_ContextCanceled は走査の途中でコンテキストがキャンセルされると、
+// 読み込み済みのページを渡し終えた後に次のページを読まず ctx.Err() を返すことを検証します。
+func TestCandleRepository_FindEach_ContextCanceled(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	insertDailyCandles(t, db, "AAPL", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var seen int
+	err := repo.FindEach(ctx, FindOptions{Symbol: "AAPL", Interval: "1day", BatchSize: 3}, func(Candle) error {
+		seen++
+		if seen == 2 {
+			cancel()
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 3, seen, "キャンセルはページの間で確認する")
+
+	err = repo.FindEach(ctx, FindOptions{Symbol: "AAPL", Interval: "1day"}, func(Candle) error {
+		t.Fatal("キャンセル済みのコンテキストでは読み込まない")
+		return nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+// BenchmarkCandleRepository_FindVsFindEach は 10 万件の足を Find（全件をスライスに載せる）と
+// FindEach（ページごとに読み込む）で走査した場合のメモリー使用量を比べます（B/op を参照）。
+func BenchmarkCandleRepository_FindVsFindEach(b *testing.B) {
+	const rows = 100_000
+	db := testsupport.NewDB(b, testsupport.InsertSymbols("AAPL"))
+	insertDailyCandles(b, db, "AAPL", time.Date(1700, 1, 1, 0, 0, 0, 0, time.UTC), rows)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	b.Run("Find", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			cs, err := repo.Find(ctx, "AAPL", "1day", 0)
+			if err != nil || len(cs) != rows {
+				b.Fatalf("Find: %d rows, err = %v", len(cs), err)
+			}
+		}
+	})
+	b.Run("FindEach", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			var n int
+			err := repo.FindEach(ctx, FindOptions{Symbol: "AAPL", Interval: "1day"}, func(Candle) error {
+				n++
+				return nil
+			})
+			if err != nil || n != rows {
+				b.Fatalf("FindEach: %d rows, err = %v", n, err)
+			}
+		}
+	})
+}
+
+func TestCandleRepository_TimeRange(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	day1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	day3 := day1.AddDate(0, 0, 2)
+
+	_, _, found, err := repo.TimeRange(ctx, "AAPL", "1day")
+	require.NoError(t, err)
+	assert.False(t, found, "足がない場合は found=false")
+
+	testsupport.InsertCandle(t, db, "AAPL", day3)
+	testsupport.InsertCandle(t, db, "AAPL", day1)
+	testsupport.InsertCandle(t, db, "AAPL", day1.AddDate(0, 0, 1))
+	testsupport.InsertCandle(t, db, "AAPL", day1.AddDate(0, 0, -7), testsupport.WithInterval("1week"))
+
+	first, last, found, err := repo.TimeRange(ctx, "AAPL", "1day")
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.True(t, first.Equal(day1))
+	assert.True(t, last.Equal(day3))
+
+	_, _, found, err = repo.TimeRange(ctx, "GOOGL", "1day")
+	require.NoError(t, err)
+	assert.False(t, found, "他の銘柄の足は含めない")
+}
+
+// TestCandleRepository_QueryTimeout は Find / FindAsOf が statement_timeout を超えると ErrQueryTimeout を返し、
+// 上限が接続に残らず後続のクエリ（UpsertBatch 等）に影響しないことを検証します。
+func TestCandleRepository_QueryTimeout(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	ctx := context.Background()
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	testsupport.InsertCandle(t, db, "AAPL", day)
+	repo := NewRepository(db).WithQueryTimeout(50 * time.Millisecond)
+
+	// 別接続のトランザクションで candles をロックし、読み取りを待たせる
+	lockConn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	lockTx, err := lockConn.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	_, err = lockTx.ExecContext(ctx, `LOCK TABLE candles IN ACCESS EXCLUSIVE MODE`)
+	require.NoError(t, err)
+
+	_, err = repo.Find(ctx, "AAPL", "1day", 10)
+	require.ErrorIs(t, err, ErrQueryTimeout)
+	var qte *QueryTimeoutError
+	require.ErrorAs(t, err, &qte)
+	assert.Equal(t, 50*time.Millisecond, qte.Timeout)
+
+	_, err = repo.Find(ctx, "AAPL", "1day", 0)
+	assert.ErrorIs(t, err, ErrQueryTimeout, "件数制限なしの Find も打ち切る")
+	_, err = repo.FindAsOf(ctx, "AAPL", "1day", time.Now(), 10)
+	assert.ErrorIs(t, err, ErrQueryTimeout)
+
+	require.NoError(t, lockTx.Rollback())
+	require.NoError(t, lockConn.Close())
+
+	// 以降は 1 接続だけを使い回し、上限がその接続に残っていないことを確認する
+	db.SetMaxOpenConns(1)
+	got, err := repo.Find(ctx, "AAPL", "1day", 10)
+	require.NoError(t, err)
+	assert.Len(t, got, 1)
+
+	var timeout string
+	require.NoError(t, db.QueryRowContext(ctx, `SHOW statement_timeout`).Scan(&timeout))
+	assert.Equal(t, "0", timeout)
+
+	stats, err := repo.UpsertBatch(ctx, []Candle{{
+		SymbolCode: "AAPL", Interval: "1day", Time: day.AddDate(0, 0, 1),
+		Open: 100, High: 110, Low: 90, Close: 105, Volume: 1000,
+	}})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, stats.Inserted)
+}
+
+// TestCandleRepository_QueryTimeout_CallerCanceled は呼び出し元のコンテキスト終了を ErrQueryTimeout にしないことを検証します。
+func TestCandleRepository_QueryTimeout_CallerCanceled(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewRepository(db).WithQueryTimeout(time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := repo.Find(ctx, "AAPL", "1day", 10)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrQueryTimeout)
+}
+
+func TestCandleRepository_MapTimeout(t *testing.T) {
+	t.Parallel()
+	repo := NewRepository(nil).WithQueryTimeout(time.Second)
+	canceled := &pgconn.PgError{Code: pgQueryCanceled}
+
+	err := repo.mapTimeout(context.Background(), fmt.Errorf("query: %w", canceled))
+	var qte *QueryTimeoutError
+	require.ErrorAs(t, err, &qte)
+	assert.Equal(t, time.Second, qte.Timeout)
+	assert.ErrorIs(t, err, ErrQueryTimeout)
+	assert.Equal(t, "narrow the requested range or retry later", qte.Hint())
+
+	other := &pgconn.PgError{Code: "40P01"}
+	assert.Same(t, error(other), repo.mapTimeout(context.Background(), other), "他の SQLSTATE は変換しない")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Same(t, error(canceled), repo.mapTimeout(ctx, canceled), "呼び出し元の取り消しは変換しない")
+}
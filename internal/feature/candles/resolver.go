@@ -0,0 +1,86 @@
+package candles
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SymbolRule はクライアントから届いた銘柄コードを保存済みの正規コードへ書き換える規則です。
+// Pattern に一致したコードを Replace（regexp.Expand の書式、例: "${1}.T"）で書き換えた結果を候補とします。
+type SymbolRule struct {
+	Market  string // 規則の対象市場（ログ・説明用）
+	Pattern *regexp.Regexp
+	Replace string
+}
+
+// DefaultSymbolRules は既定の正規化規則です。
+//   - 数字4桁のみのコードは東証銘柄とみなし ".T" を付与する（例: 7203 → 7203.T）
+//   - 他データソースの米国株の取引所接尾辞（Reuters RIC の .O / .OQ / .N / .K）を取り除く（例: AAPL.O → AAPL）
+var DefaultSymbolRules = []SymbolRule{
+	{Market: "TSE", Pattern: regexp.MustCompile(`^([0-9]{4})$`), Replace: "${1}.T"},
+	{Market: "US", Pattern: regexp.MustCompile(`^([A-Z][A-Z0-9]{0,5})\.(?:O|OQ|N|K)$`), Replace: "${1}"},
+}
+
+// SymbolResolver は入力された銘柄コードを正規コード（symbols.code に保存された形式）に解決します。
+//
+// 解決順序:
+//  1. 入力コードがそのままアクティブ銘柄であればそれを返す（正規コードは常にこの経路）
+//  2. 英字を大文字にしたうえで各規則を適用し、アクティブ銘柄に一致した候補が1つならそれを返す
+//  3. 候補がなければ ErrSymbolNotFound、複数あれば ErrAmbiguousSymbol を返す
+type SymbolResolver struct {
+	symbols ActiveSymbolChecker
+	rules   []SymbolRule
+}
+
+// NewSymbolResolver は指定されたアクティブ銘柄判定と規則で SymbolResolver を生成します。
+func NewSymbolResolver(symbols ActiveSymbolChecker, rules []SymbolRule) *SymbolResolver {
+	return &SymbolResolver{symbols: symbols, rules: rules}
+}
+
+// Resolve は code を正規コードに解決します。
+func (r *SymbolResolver) Resolve(ctx context.Context, code string) (string, error) {
+	ok, err := r.symbols.Contains(ctx, code)
+	if err != nil {
+		return "", fmt.Errorf("checking active symbol: %w", err)
+	}
+	if ok {
+		return code, nil
+	}
+
+	upper := strings.ToUpper(code)
+	var candidates []string
+	if upper != code {
+		candidates = append(candidates, upper)
+	}
+	for _, rule := range r.rules {
+		m := rule.Pattern.FindStringSubmatchIndex(upper)
+		if m == nil {
+			continue
+		}
+		candidates = append(candidates, string(rule.Pattern.ExpandString(nil, rule.Replace, upper, m)))
+	}
+
+	var resolved string
+	for _, c := range candidates {
+		if c == resolved {
+			continue
+		}
+		ok, err := r.symbols.Contains(ctx, c)
+		if err != nil {
+			return "", fmt.Errorf("checking active symbol: %w", err)
+		}
+		if !ok {
+			continue
+		}
+		if resolved != "" {
+			return "", ErrAmbiguousSymbol
+		}
+		resolved = c
+	}
+	if resolved == "" {
+		return "", ErrSymbolNotFound
+	}
+	return resolved, nil
+}
@@ -0,0 +1,99 @@
+package candles_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+)
+
+// TestSymbolResolver_Resolve は既定の正規化規則の入力パターンごとの解決結果を検証します。
+func TestSymbolResolver_Resolve(t *testing.T) {
+	t.Parallel()
+
+	active := allActive("7203.T", "AAPL", "BRK.B", "6758.T")
+	tests := []struct {
+		name    string
+		code    string
+		want    string
+		wantErr error
+	}{
+		{name: "正規コード（東証）はそのまま", code: "7203.T", want: "7203.T"},
+		{name: "正規コード（米国）はそのまま", code: "AAPL", want: "AAPL"},
+		{name: "ドットを含む正規コードは接尾辞除去の対象外", code: "BRK.B", want: "BRK.B"},
+		{name: "数字4桁には .T を付与", code: "7203", want: "7203.T"},
+		{name: "RIC の .O を除去", code: "AAPL.O", want: "AAPL"},
+		{name: "RIC の .OQ を除去", code: "AAPL.OQ", want: "AAPL"},
+		{name: "RIC の .N を除去", code: "AAPL.N", want: "AAPL"},
+		{name: "小文字は大文字に正規化", code: "aapl", want: "AAPL"},
+		{name: "小文字の接尾辞も正規化", code: "aapl.o", want: "AAPL"},
+		{name: "小文字の .t も正規化", code: "7203.t", want: "7203.T"},
+		{name: "規則を適用しても未登録なら 404", code: "9999", wantErr: candles.ErrSymbolNotFound},
+		{name: "規則に該当しない未登録コード", code: "UNKNOWN", wantErr: candles.ErrSymbolNotFound},
+		{name: "数字5桁は対象外", code: "72030", wantErr: candles.ErrSymbolNotFound},
+		{name: "非対応の接尾辞は除去しない", code: "AAPL.X", wantErr: candles.ErrSymbolNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := candles.NewSymbolResolver(active, candles.DefaultSymbolRules)
+			got, err := r.Resolve(context.Background(), tt.code)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Resolve(%q) error = %v, want %v", tt.code, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve(%q) = %q, want %q", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSymbolResolver_Ambiguous は複数の規則が別々のアクティブ銘柄に一致する場合に
+// 推測せず ErrAmbiguousSymbol を返すこと、完全一致は曖昧さより優先されることを検証します。
+func TestSymbolResolver_Ambiguous(t *testing.T) {
+	t.Parallel()
+
+	rules := []candles.SymbolRule{
+		{Market: "TSE", Pattern: regexp.MustCompile(`^([0-9]{4})$`), Replace: "${1}.T"},
+		{Market: "HKEX", Pattern: regexp.MustCompile(`^([0-9]{4})$`), Replace: "${1}.HK"},
+	}
+	tests := []struct {
+		name    string
+		active  []string
+		code    string
+		want    string
+		wantErr error
+	}{
+		{name: "両市場に存在すれば曖昧", active: []string{"0700.T", "0700.HK"}, code: "0700", wantErr: candles.ErrAmbiguousSymbol},
+		{name: "片方のみ存在すれば解決", active: []string{"0700.HK"}, code: "0700", want: "0700.HK"},
+		{name: "完全一致が優先", active: []string{"0700", "0700.T", "0700.HK"}, code: "0700", want: "0700"},
+		{name: "正規コード指定は曖昧にならない", active: []string{"0700.T", "0700.HK"}, code: "0700.HK", want: "0700.HK"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := candles.NewSymbolResolver(allActive(tt.active...), rules)
+			got, err := r.Resolve(context.Background(), tt.code)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Resolve(%q) error = %v, want %v", tt.code, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve(%q) = %q, want %q", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSymbolResolver_CheckerError は銘柄判定の失敗をそのまま返し、404 にしないことを検証します。
+func TestSymbolResolver_CheckerError(t *testing.T) {
+	t.Parallel()
+
+	r := candles.NewSymbolResolver(&stubSymbolChecker{err: ErrDB}, candles.DefaultSymbolRules)
+	if _, err := r.Resolve(context.Background(), "7203"); !errors.Is(err, ErrDB) {
+		t.Errorf("expected ErrDB, got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package candles
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ローソク足の取り込み元（candles.source）です。"<種別>" または "<種別>:<詳細>" の形で保存し、
+// 上書きの優先順位（SourcePrecedence）は種別で判定します。
+const (
+	// SourceTwelveData は TwelveData から取得した足です（列の既定値。取り込み元のない既存の足もこれとみなす）。
+	SourceTwelveData = "twelvedata"
+	// SourceCSV は CSV から取り込んだ足の種別です（CSVSource で "csv:<ファイル名のハッシュ>" にする）。
+	SourceCSV = "csv"
+	// SourceDerived は日足から集計した週足・月足です。
+	SourceDerived = "derived"
+	// SourceSeed は seed ジョブが生成したデモ用の足です。
+	SourceSeed = "seed"
+)
+
+// maxSourceLength は candles.source（VARCHAR(64)）の長さの上限です。
+const maxSourceLength = 64
+
+// sourceKindPattern は取り込み元の種別として許可する形式です。
+var sourceKindPattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// CSVSource は CSV ファイル name から取り込んだ足の取り込み元（"csv:<ファイル名のハッシュ 12 桁>"）を返します。
+// ディレクトリを除いたファイル名のハッシュのため、置き場所が違っても同じファイル名なら同じ値です。
+func CSVSource(name string) string {
+	sum := sha256.Sum256([]byte(filepath.Base(name)))
+	return SourceCSV + ":" + hex.EncodeToString(sum[:6])
+}
+
+// SourceKind は取り込み元 source の種別（":" より前）を返します。空の場合は SourceTwelveData です。
+func SourceKind(source string) string {
+	if source == "" {
+		return SourceTwelveData
+	}
+	kind, _, _ := strings.Cut(source, ":")
+	return kind
+}
+
+// SourcePrecedence は取り込み元の種別の優先順位です（先頭ほど高い）。
+// 並びにない種別はどの種別よりも低い優先順位として扱います。
+type SourcePrecedence []string
+
+// DefaultSourcePrecedence は CANDLES_SOURCE_PRECEDENCE 未設定時の優先順位です。
+// プロバイダーの足を、他ベンダーの CSV や集計した足で上書きしないようにします。
+func DefaultSourcePrecedence() SourcePrecedence {
+	return SourcePrecedence{SourceTwelveData, SourceCSV, SourceDerived}
+}
+
+// ParseSourcePrecedence は CANDLES_SOURCE_PRECEDENCE（例: "twelvedata,csv,derived"）を解釈します。
+// 空の場合は DefaultSourcePrecedence を返します。種別の形式が不正な場合・重複がある場合はエラーです。
+func ParseSourcePrecedence(s string) (SourcePrecedence, error) {
+	if strings.TrimSpace(s) == "" {
+		return DefaultSourcePrecedence(), nil
+	}
+	var p SourcePrecedence
+	for kind := range strings.SplitSeq(s, ",") {
+		kind = strings.TrimSpace(kind)
+		if !sourceKindPattern.MatchString(kind) {
+			return nil, fmt.Errorf("invalid source kind %q", kind)
+		}
+		if slices.Contains(p, kind) {
+			return nil, fmt.Errorf("duplicate source kind %q", kind)
+		}
+		p = append(p, kind)
+	}
+	return p, nil
+}
+
+// rank は source の種別の順位（0 が最も高い）を返します。並びにない種別は len(p) です。
+func (p SourcePrecedence) rank(source string) int {
+	if i := slices.Index(p, SourceKind(source)); i >= 0 {
+		return i
+	}
+	return len(p)
+}
+
+// Overwrites は保存済みの足（取り込み元 existing）を incoming の足で上書きするかを返します。
+// incoming の優先順位が existing と同じか高い場合のみ上書きします（同じ取り込み元の再取り込みは常に上書き）。
+// UpsertBatch の ON CONFLICT の条件（upsertCandleConflict）はこの判定を SQL で表したものです。
+func (p SourcePrecedence) Overwrites(existing, incoming string) bool {
+	return p.rank(incoming) <= p.rank(existing)
+}
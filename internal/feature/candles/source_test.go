@@ -0,0 +1,79 @@
+package candles
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSourcePrecedence_Overwrites(t *testing.T) {
+	t.Parallel()
+
+	p := DefaultSourcePrecedence() // twelvedata > csv > derived > その他
+	tests := []struct {
+		existing, incoming string
+		want               bool
+	}{
+		{existing: "twelvedata", incoming: "twelvedata", want: true},
+		{existing: "derived", incoming: "derived", want: true},
+		{existing: "derived", incoming: "twelvedata", want: true},
+		{existing: "twelvedata", incoming: "derived", want: false},
+		{existing: "twelvedata", incoming: "csv:1a2b3c4d5e6f", want: false},
+		{existing: "csv:1a2b3c4d5e6f", incoming: "csv:ffffffffffff", want: true}, // 同じ種別は別のファイルでも上書き
+		{existing: "csv:1a2b3c4d5e6f", incoming: "derived", want: false},
+		{existing: "", incoming: "derived", want: false}, // 空は twelvedata
+		{existing: "derived", incoming: "", want: true},
+		{existing: "seed", incoming: "derived", want: true}, // 並びにない種別は最も低い
+		{existing: "derived", incoming: "seed", want: false},
+		{existing: "seed", incoming: "yahoo", want: true}, // 並びにない種別同士は同じ順位
+	}
+	for _, tt := range tests {
+		if got := p.Overwrites(tt.existing, tt.incoming); got != tt.want {
+			t.Errorf("Overwrites(%q, %q) = %v, want %v", tt.existing, tt.incoming, got, tt.want)
+		}
+	}
+
+	custom := SourcePrecedence{"csv", "twelvedata"}
+	if !custom.Overwrites("twelvedata", "csv:1a2b3c4d5e6f") {
+		t.Error("custom precedence: csv should overwrite twelvedata")
+	}
+	if custom.Overwrites("csv:1a2b3c4d5e6f", "twelvedata") {
+		t.Error("custom precedence: twelvedata should not overwrite csv")
+	}
+}
+
+func TestParseSourcePrecedence(t *testing.T) {
+	t.Parallel()
+
+	got, err := ParseSourcePrecedence("")
+	if err != nil || !reflect.DeepEqual(got, DefaultSourcePrecedence()) {
+		t.Errorf("ParseSourcePrecedence(\"\") = %v, %v; want default", got, err)
+	}
+	got, err = ParseSourcePrecedence(" csv , twelvedata,derived ")
+	if err != nil || !reflect.DeepEqual(got, SourcePrecedence{"csv", "twelvedata", "derived"}) {
+		t.Errorf("ParseSourcePrecedence = %v, %v", got, err)
+	}
+	for _, in := range []string{"csv,", "csv:abc", "CSV", "csv,twelvedata,csv", strings.Repeat("x", 33)} {
+		if _, err := ParseSourcePrecedence(in); err == nil {
+			t.Errorf("ParseSourcePrecedence(%q) expected error", in)
+		}
+	}
+}
+
+func TestCSVSource(t *testing.T) {
+	t.Parallel()
+
+	got := CSVSource("/data/7203.csv")
+	if !strings.HasPrefix(got, "csv:") || len(got) != len("csv:")+12 {
+		t.Errorf("CSVSource = %q, want csv:<12 hex digits>", got)
+	}
+	if other := CSVSource("./imports/7203.csv"); other != got {
+		t.Errorf("CSVSource depends on the directory: %q != %q", other, got)
+	}
+	if other := CSVSource("/data/6758.csv"); other == got {
+		t.Errorf("CSVSource(%q) collides with 7203.csv", "6758.csv")
+	}
+	if SourceKind(got) != SourceCSV {
+		t.Errorf("SourceKind(%q) = %q, want csv", got, SourceKind(got))
+	}
+}
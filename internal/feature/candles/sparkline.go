@@ -0,0 +1,93 @@
+package candles
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	// DefaultSparklinePoints はスパークラインの既定の点数です。
+	DefaultSparklinePoints = 30
+	// MinSparklinePoints / MaxSparklinePoints はスパークラインの点数として指定できる範囲です。
+	MinSparklinePoints = 2
+	MaxSparklinePoints = 500
+)
+
+// Sparkline はウォッチリスト等の小さなチャート向けに間引いた終値の系列です。
+// Closes は時刻の昇順で、First / Last は間引き前の最初・最後の足の時刻です（データがない場合はゼロ値）。
+type Sparkline struct {
+	Closes []float64
+	First  time.Time
+	Last   time.Time
+}
+
+// NewSparkline はローソク足（順不同）から最大 points 点に間引いたスパークラインを生成します。
+func NewSparkline(cs []Candle, points int) Sparkline {
+	sampled := Downsample(cs, points)
+	if len(sampled) == 0 {
+		return Sparkline{Closes: []float64{}}
+	}
+	closes := make([]float64, len(sampled))
+	for i, c := range sampled {
+		closes[i] = c.Close
+	}
+	return Sparkline{Closes: closes, First: sampled[0].Time, Last: sampled[len(sampled)-1].Time}
+}
+
+// Downsample はローソク足（順不同）を時刻の昇順に並べ、終値の形を保ったまま最大 points 本に間引きます。
+//
+// Largest-Triangle-Three-Buckets（LTTB）で、最初と最後の足は常に残し、間の足を points-2 個のバケットに分けて
+// 各バケットから「直前に選んだ点・次のバケットの平均点」と作る三角形の面積が最大の足を 1 本ずつ選びます。
+// 単純な間引き（stride）と違い、急騰・急落の山や谷が消えにくくなります。
+// 入力が points 本以下の場合は並べ替えただけのコピーを返し、points が 2 未満の場合は最新の足のみを返します。
+func Downsample(cs []Candle, points int) []Candle {
+	sorted := slices.Clone(cs)
+	slices.SortFunc(sorted, func(a, b Candle) int { return a.Time.Compare(b.Time) })
+	n := len(sorted)
+	if n <= points || n == 0 {
+		return sorted
+	}
+	if points < 2 {
+		return sorted[n-1:]
+	}
+
+	out := make([]Candle, 0, points)
+	out = append(out, sorted[0])
+	// 内側の足（先頭と末尾を除く n-2 本）を points-2 個のバケットに等分する（n > points のため空のバケットはない）
+	inner, buckets := n-2, points-2
+	bound := func(i int) int { return i*inner/buckets + 1 }
+	prev := 0
+	for i := range buckets {
+		start, end := bound(i), bound(i+1)
+
+		// 次のバケットの平均点（最後のバケットの次は末尾の足）
+		nextStart, nextEnd := end, bound(i+2)
+		if i == buckets-1 {
+			nextStart, nextEnd = n-1, n
+		}
+		var avgX, avgY float64
+		for _, c := range sorted[nextStart:nextEnd] {
+			avgX += float64(c.Time.Unix())
+			avgY += c.Close
+		}
+		cnt := float64(nextEnd - nextStart)
+		avgX, avgY = avgX/cnt, avgY/cnt
+
+		ax, ay := float64(sorted[prev].Time.Unix()), sorted[prev].Close
+		best, bestArea := start, -1.0
+		for j := start; j < end; j++ {
+			bx, by := float64(sorted[j].Time.Unix()), sorted[j].Close
+			// 三角形の面積の 2 倍（比較にのみ使うため 1/2 は省略）
+			area := (ax-avgX)*(by-ay) - (ax-bx)*(avgY-ay)
+			if area < 0 {
+				area = -area
+			}
+			if area > bestArea {
+				best, bestArea = j, area
+			}
+		}
+		out = append(out, sorted[best])
+		prev = best
+	}
+	return append(out, sorted[n-1])
+}
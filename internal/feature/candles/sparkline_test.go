@@ -0,0 +1,124 @@
+package candles
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// seriesCandles は day から 1 日刻みで n 本、終値が正弦波のローソク足を時刻の降順（Find と同じ順）で返します。
+func seriesCandles(n int) []Candle {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cs := make([]Candle, n)
+	for i := range n {
+		cs[n-1-i] = Candle{Time: day.AddDate(0, 0, i), Close: 100 + 10*math.Sin(float64(i)/5)}
+	}
+	return cs
+}
+
+func TestDownsample(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		n       int
+		points  int
+		wantLen int
+	}{
+		{name: "empty", n: 0, points: 30, wantLen: 0},
+		{name: "fewer candles than points", n: 7, points: 30, wantLen: 7},
+		{name: "exactly points", n: 30, points: 30, wantLen: 30},
+		{name: "one more than points", n: 31, points: 30, wantLen: 30},
+		{name: "large input", n: 5000, points: 30, wantLen: 30},
+		{name: "uneven buckets", n: 200, points: 7, wantLen: 7},
+		{name: "two points keeps first and last", n: 100, points: 2, wantLen: 2},
+		{name: "three points", n: 100, points: 3, wantLen: 3},
+		{name: "one point keeps latest", n: 100, points: 1, wantLen: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			in := seriesCandles(tt.n)
+			got := Downsample(in, tt.points)
+
+			if len(got) != tt.wantLen {
+				t.Fatalf("len = %d, want %d", len(got), tt.wantLen)
+			}
+			for i := 1; i < len(got); i++ {
+				if !got[i].Time.After(got[i-1].Time) {
+					t.Fatalf("not strictly ascending at %d: %v then %v", i, got[i-1].Time, got[i].Time)
+				}
+			}
+			if tt.n == 0 {
+				return
+			}
+			// 入力の最新の足は常に残る（最初の足は points >= 2 の場合のみ）
+			if !got[len(got)-1].Time.Equal(in[0].Time) {
+				t.Errorf("last = %v, want latest %v", got[len(got)-1].Time, in[0].Time)
+			}
+			if tt.points >= 2 && !got[0].Time.Equal(in[len(in)-1].Time) {
+				t.Errorf("first = %v, want earliest %v", got[0].Time, in[len(in)-1].Time)
+			}
+		})
+	}
+}
+
+// TestDownsample_KeepsSpike は単発の急騰が間引きで消えないことを検証します（単純な間引きとの違い）。
+func TestDownsample_KeepsSpike(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cs := make([]Candle, 100)
+	for i := range cs {
+		cs[i] = Candle{Time: day.AddDate(0, 0, i), Close: 100}
+	}
+	cs[37].Close = 500
+
+	found := false
+	for _, c := range Downsample(cs, 10) {
+		if c.Close == 500 {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("spike was dropped")
+	}
+}
+
+func TestDownsample_DoesNotModifyInput(t *testing.T) {
+	t.Parallel()
+
+	in := seriesCandles(50)
+	first := in[0].Time
+	_ = Downsample(in, 10)
+	if !in[0].Time.Equal(first) {
+		t.Error("input was reordered")
+	}
+}
+
+func TestNewSparkline(t *testing.T) {
+	t.Parallel()
+
+	t.Run("closes and range", func(t *testing.T) {
+		t.Parallel()
+		in := seriesCandles(100)
+		s := NewSparkline(in, 30)
+		if len(s.Closes) != 30 {
+			t.Fatalf("len(Closes) = %d, want 30", len(s.Closes))
+		}
+		if !s.First.Equal(in[len(in)-1].Time) || !s.Last.Equal(in[0].Time) {
+			t.Errorf("range = %v..%v, want %v..%v", s.First, s.Last, in[len(in)-1].Time, in[0].Time)
+		}
+		if s.Closes[len(s.Closes)-1] != in[0].Close {
+			t.Errorf("last close = %v, want %v", s.Closes[len(s.Closes)-1], in[0].Close)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+		s := NewSparkline(nil, 30)
+		if s.Closes == nil || len(s.Closes) != 0 || !s.First.IsZero() || !s.Last.IsZero() {
+			t.Errorf("got %+v, want empty closes and zero times", s)
+		}
+	})
+}
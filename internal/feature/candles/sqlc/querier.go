@@ -9,8 +9,42 @@ import (
 )
 
 type Querier interface {
+	CountDuplicateCandleGroups(ctx context.Context) (int64, error)
+	CreateAdjustment(ctx context.Context, arg CreateAdjustmentParams) (CandleAdjustment, error)
+	DeleteAdjustment(ctx context.Context, id int64) (int64, error)
+	// 同じ (symbol_code, "interval", "time") でより大きい id（後の書き込み）がある行を削除し、最大 id の行だけを残す。
+	DeleteDuplicateCandles(ctx context.Context, symbolCode string) (int64, error)
+	// 足がない場合は n = 0 を返す（first_time / last_time は意味を持たない）。
+	FindCandleTimeRange(ctx context.Context, arg FindCandleTimeRangeParams) (FindCandleTimeRangeRow, error)
+	// 同じ "time" の行（UNIQUE インデックス導入前の重複）は id の降順（後の書き込みが先）で並べ、結果の順序を一意に保つ。
 	FindCandlesAll(ctx context.Context, arg FindCandlesAllParams) ([]FindCandlesAllRow, error)
+	// updated_at が as_of 以前の足のみを返す（以降に値が書き換わった足は as_of 時点の値が残っていないため除外する）。
+	FindCandlesAsOf(ctx context.Context, arg FindCandlesAsOfParams) ([]FindCandlesAsOfRow, error)
 	FindCandlesLimit(ctx context.Context, arg FindCandlesLimitParams) ([]FindCandlesLimitRow, error)
+	// FindEach のキーセットページング。has_after のとき ("time", id) が (after_time, after_id) より前の足だけを返し、
+	// OFFSET を使わずに前のページの続きから読む（ページが進んでも読み飛ばす行が増えない）。
+	FindCandlesPage(ctx context.Context, arg FindCandlesPageParams) ([]FindCandlesPageRow, error)
+	// 期間指定の読み取り。has_start のとき start_time 以降、has_end のとき end_before より前の足だけを返す。
+	FindCandlesRange(ctx context.Context, arg FindCandlesRangeParams) ([]FindCandlesRangeRow, error)
+	// 管理用の調査。足の値に加えて id・取り込み元・書き込み日時を返す。
+	InspectCandles(ctx context.Context, arg InspectCandlesParams) ([]InspectCandlesRow, error)
+	ListAdjustments(ctx context.Context, symbolCode string) ([]CandleAdjustment, error)
+	ListBackfillRequests(ctx context.Context) ([]CandleAnomaly, error)
+	// 1 銘柄 1 行のため全件を返す（呼び出し側で必要な銘柄を選ぶ）。
+	ListDailyStats(ctx context.Context) ([]SymbolDailyStat, error)
+	ListDuplicateCandleSymbols(ctx context.Context) ([]string, error)
+	// トランザクション内では削除対象の行をロックする（トランザクション外では即座に解放される）。
+	ListDuplicateCandles(ctx context.Context, symbolCode string) ([]ListDuplicateCandlesRow, error)
+	ListFreshness(ctx context.Context) ([]ListFreshnessRow, error)
+	MarkAllFreshnessFailed(ctx context.Context, arg MarkAllFreshnessFailedParams) error
+	MarkAnomalyBackfilled(ctx context.Context, arg MarkAnomalyBackfilledParams) error
+	// 既に記録済みの (symbol_code, "time") は更新せず既存の行を返す（DO UPDATE は RETURNING で既存行を得るため）。
+	RecordAnomaly(ctx context.Context, arg RecordAnomalyParams) (CandleAnomaly, error)
+	ResolveAnomaly(ctx context.Context, arg ResolveAnomalyParams) (CandleAnomaly, error)
+	UpdateAdjustment(ctx context.Context, arg UpdateAdjustmentParams) (CandleAdjustment, error)
+	UpsertDailyStats(ctx context.Context, arg UpsertDailyStatsParams) error
+	UpsertFreshnessFailure(ctx context.Context, arg UpsertFreshnessFailureParams) error
+	UpsertFreshnessSuccess(ctx context.Context, arg UpsertFreshnessSuccessParams) error
 }
 
 var _ Querier = (*Queries)(nil)
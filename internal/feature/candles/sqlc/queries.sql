@@ -1,12 +1,206 @@
 -- name: FindCandlesAll :many
+-- 同じ "time" の行（UNIQUE インデックス導入前の重複）は id の降順（後の書き込みが先）で並べ、結果の順序を一意に保つ。
 SELECT symbol_code, "interval", "time", open, high, low, close, volume
 FROM candles
 WHERE symbol_code = $1 AND "interval" = $2
-ORDER BY "time" DESC;
+ORDER BY "time" DESC, id DESC;
 
 -- name: FindCandlesLimit :many
 SELECT symbol_code, "interval", "time", open, high, low, close, volume
 FROM candles
 WHERE symbol_code = $1 AND "interval" = $2
-ORDER BY "time" DESC
+ORDER BY "time" DESC, id DESC
 LIMIT $3;
+
+-- name: UpsertFreshnessSuccess :exec
+INSERT INTO data_freshness ("interval", market, last_success_at, last_attempt_at, last_error)
+VALUES ($1, $2, $3, $3, NULL)
+ON CONFLICT ("interval", market) DO UPDATE
+SET last_success_at = EXCLUDED.last_success_at,
+    last_attempt_at = EXCLUDED.last_attempt_at,
+    last_error      = NULL,
+    updated_at      = now();
+
+-- name: UpsertFreshnessFailure :exec
+INSERT INTO data_freshness ("interval", market, last_attempt_at, last_error)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT ("interval", market) DO UPDATE
+SET last_attempt_at = EXCLUDED.last_attempt_at,
+    last_error      = EXCLUDED.last_error,
+    updated_at      = now();
+
+-- name: MarkAllFreshnessFailed :exec
+UPDATE data_freshness
+SET last_attempt_at = $1,
+    last_error      = $2,
+    updated_at      = now();
+
+-- name: ListFreshness :many
+SELECT "interval", market, last_success_at, last_attempt_at, last_error
+FROM data_freshness
+ORDER BY "interval" ASC, market ASC;
+
+-- name: RecordAnomaly :one
+-- 既に記録済みの (symbol_code, "time") は更新せず既存の行を返す（DO UPDATE は RETURNING で既存行を得るため）。
+INSERT INTO candle_anomalies (symbol_code, "time", prev_close, close, ratio)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (symbol_code, "time") DO UPDATE
+SET symbol_code = candle_anomalies.symbol_code
+RETURNING id, symbol_code, "time", prev_close, close, ratio, status, detected_at, resolved_at, backfill_requested_at, backfilled_at;
+
+-- name: ResolveAnomaly :one
+UPDATE candle_anomalies
+SET status                = sqlc.arg(status),
+    resolved_at           = sqlc.arg(resolved_at),
+    backfill_requested_at = CASE WHEN sqlc.arg(backfill)::bool THEN sqlc.arg(resolved_at) ELSE NULL END,
+    backfilled_at         = CASE WHEN sqlc.arg(backfill)::bool THEN NULL ELSE backfilled_at END
+WHERE id = sqlc.arg(id)
+RETURNING id, symbol_code, "time", prev_close, close, ratio, status, detected_at, resolved_at, backfill_requested_at, backfilled_at;
+
+-- name: ListBackfillRequests :many
+SELECT id, symbol_code, "time", prev_close, close, ratio, status, detected_at, resolved_at, backfill_requested_at, backfilled_at
+FROM candle_anomalies
+WHERE status = 'confirmed_split'
+  AND backfill_requested_at IS NOT NULL
+  AND backfilled_at IS NULL
+ORDER BY backfill_requested_at ASC, id ASC;
+
+-- name: MarkAnomalyBackfilled :exec
+UPDATE candle_anomalies
+SET backfilled_at = $2
+WHERE id = $1;
+
+-- name: ListAdjustments :many
+SELECT id, symbol_code, effective_date, factor, reason, created_at, updated_at
+FROM candle_adjustments
+WHERE sqlc.arg(symbol_code)::text = '' OR symbol_code = sqlc.arg(symbol_code)::text
+ORDER BY symbol_code ASC, effective_date ASC;
+
+-- name: CreateAdjustment :one
+INSERT INTO candle_adjustments (symbol_code, effective_date, factor, reason)
+VALUES ($1, $2, $3, $4)
+RETURNING id, symbol_code, effective_date, factor, reason, created_at, updated_at;
+
+-- name: UpdateAdjustment :one
+UPDATE candle_adjustments
+SET effective_date = sqlc.arg(effective_date),
+    factor         = sqlc.arg(factor),
+    reason         = sqlc.arg(reason),
+    updated_at     = now()
+WHERE id = sqlc.arg(id)
+RETURNING id, symbol_code, effective_date, factor, reason, created_at, updated_at;
+
+-- name: DeleteAdjustment :execrows
+DELETE FROM candle_adjustments
+WHERE id = $1;
+
+-- name: CountDuplicateCandleGroups :one
+SELECT COUNT(*)::bigint AS groups
+FROM (
+    SELECT 1
+    FROM candles
+    GROUP BY symbol_code, "interval", "time"
+    HAVING COUNT(*) > 1
+) d;
+
+-- name: ListDuplicateCandleSymbols :many
+SELECT DISTINCT symbol_code
+FROM (
+    SELECT symbol_code
+    FROM candles
+    GROUP BY symbol_code, "interval", "time"
+    HAVING COUNT(*) > 1
+) d
+ORDER BY symbol_code;
+
+-- name: ListDuplicateCandles :many
+-- トランザクション内では削除対象の行をロックする（トランザクション外では即座に解放される）。
+SELECT c.id, c."interval", c."time"
+FROM candles c
+JOIN (
+    SELECT dup."interval", dup."time"
+    FROM candles dup
+    WHERE dup.symbol_code = sqlc.arg(symbol_code)
+    GROUP BY dup."interval", dup."time"
+    HAVING COUNT(*) > 1
+) d ON d."interval" = c."interval" AND d."time" = c."time"
+WHERE c.symbol_code = sqlc.arg(symbol_code)
+ORDER BY c."interval", c."time", c.id
+FOR UPDATE OF c;
+
+-- name: DeleteDuplicateCandles :execrows
+-- 同じ (symbol_code, "interval", "time") でより大きい id（後の書き込み）がある行を削除し、最大 id の行だけを残す。
+DELETE FROM candles c
+USING candles newer
+WHERE c.symbol_code = sqlc.arg(symbol_code)
+  AND newer.symbol_code = c.symbol_code
+  AND newer."interval" = c."interval"
+  AND newer."time" = c."time"
+  AND newer.id > c.id;
+
+-- name: FindCandlesAsOf :many
+-- updated_at が as_of 以前の足のみを返す（以降に値が書き換わった足は as_of 時点の値が残っていないため除外する）。
+SELECT symbol_code, "interval", "time", open, high, low, close, volume, source
+FROM candles
+WHERE symbol_code = sqlc.arg(symbol_code)
+  AND "interval" = sqlc.arg(interval)
+  AND updated_at <= sqlc.arg(as_of)
+ORDER BY "time" DESC, id DESC
+LIMIT sqlc.arg(max_rows);
+
+-- name: FindCandlesPage :many
+-- FindEach のキーセットページング。has_after のとき ("time", id) が (after_time, after_id) より前の足だけを返し、
+-- OFFSET を使わずに前のページの続きから読む（ページが進んでも読み飛ばす行が増えない）。
+SELECT id, symbol_code, "interval", "time", open, high, low, close, volume
+FROM candles
+WHERE symbol_code = sqlc.arg(symbol_code)
+  AND "interval" = sqlc.arg(interval)
+  AND (NOT sqlc.arg(has_after)::boolean
+       OR ("time", id) < (sqlc.arg(after_time)::timestamptz, sqlc.arg(after_id)::bigint))
+ORDER BY "time" DESC, id DESC
+LIMIT sqlc.arg(batch_size);
+
+-- name: FindCandlesRange :many
+-- 期間指定の読み取り。has_start のとき start_time 以降、has_end のとき end_before より前の足だけを返す。
+SELECT symbol_code, "interval", "time", open, high, low, close, volume
+FROM candles
+WHERE symbol_code = sqlc.arg(symbol_code)
+  AND "interval" = sqlc.arg(interval)
+  AND (NOT sqlc.arg(has_start)::boolean OR "time" >= sqlc.arg(start_time)::timestamptz)
+  AND (NOT sqlc.arg(has_end)::boolean OR "time" < sqlc.arg(end_before)::timestamptz)
+ORDER BY "time" DESC, id DESC
+LIMIT sqlc.arg(max_rows);
+
+-- name: InspectCandles :many
+-- 管理用の調査。足の値に加えて id・取り込み元・書き込み日時を返す。
+SELECT id, "time", open, high, low, close, volume, source, created_at, updated_at
+FROM candles
+WHERE symbol_code = sqlc.arg(symbol_code)
+  AND "interval" = sqlc.arg(interval)
+ORDER BY "time" DESC, id DESC
+LIMIT sqlc.arg(max_rows);
+
+-- name: FindCandleTimeRange :one
+-- 足がない場合は n = 0 を返す（first_time / last_time は意味を持たない）。
+SELECT count(*) AS n,
+       COALESCE(MIN("time"), 'epoch')::timestamptz AS first_time,
+       COALESCE(MAX("time"), 'epoch')::timestamptz AS last_time
+FROM candles
+WHERE symbol_code = $1 AND "interval" = $2;
+
+-- name: UpsertDailyStats :exec
+INSERT INTO symbol_daily_stats (symbol_code, as_of, high_52w, low_52w, avg_vol_30d, ytd_change_pct, computed_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (symbol_code) DO UPDATE
+SET as_of          = EXCLUDED.as_of,
+    high_52w       = EXCLUDED.high_52w,
+    low_52w        = EXCLUDED.low_52w,
+    avg_vol_30d    = EXCLUDED.avg_vol_30d,
+    ytd_change_pct = EXCLUDED.ytd_change_pct,
+    computed_at    = EXCLUDED.computed_at;
+
+-- name: ListDailyStats :many
+-- 1 銘柄 1 行のため全件を返す（呼び出し側で必要な銘柄を選ぶ）。
+SELECT symbol_code, as_of, high_52w, low_52w, avg_vol_30d, ytd_change_pct, computed_at
+FROM symbol_daily_stats
+ORDER BY symbol_code;
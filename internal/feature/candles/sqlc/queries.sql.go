@@ -7,14 +7,124 @@ package candlessqlc
 
 import (
 	"context"
+	"database/sql"
 	"time"
 )
 
+const countDuplicateCandleGroups = `-- name: CountDuplicateCandleGroups :one
+SELECT COUNT(*)::bigint AS groups
+FROM (
+    SELECT 1
+    FROM candles
+    GROUP BY symbol_code, "interval", "time"
+    HAVING COUNT(*) > 1
+) d
+`
+
+func (q *Queries) CountDuplicateCandleGroups(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countDuplicateCandleGroups)
+	var groups int64
+	err := row.Scan(&groups)
+	return groups, err
+}
+
+const createAdjustment = `-- name: CreateAdjustment :one
+INSERT INTO candle_adjustments (symbol_code, effective_date, factor, reason)
+VALUES ($1, $2, $3, $4)
+RETURNING id, symbol_code, effective_date, factor, reason, created_at, updated_at
+`
+
+type CreateAdjustmentParams struct {
+	SymbolCode    string
+	EffectiveDate time.Time
+	Factor        float64
+	Reason        string
+}
+
+func (q *Queries) CreateAdjustment(ctx context.Context, arg CreateAdjustmentParams) (CandleAdjustment, error) {
+	row := q.db.QueryRowContext(ctx, createAdjustment,
+		arg.SymbolCode,
+		arg.EffectiveDate,
+		arg.Factor,
+		arg.Reason,
+	)
+	var i CandleAdjustment
+	err := row.Scan(
+		&i.ID,
+		&i.SymbolCode,
+		&i.EffectiveDate,
+		&i.Factor,
+		&i.Reason,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteAdjustment = `-- name: DeleteAdjustment :execrows
+DELETE FROM candle_adjustments
+WHERE id = $1
+`
+
+func (q *Queries) DeleteAdjustment(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteAdjustment, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteDuplicateCandles = `-- name: DeleteDuplicateCandles :execrows
+DELETE FROM candles c
+USING candles newer
+WHERE c.symbol_code = $1
+  AND newer.symbol_code = c.symbol_code
+  AND newer."interval" = c."interval"
+  AND newer."time" = c."time"
+  AND newer.id > c.id
+`
+
+// 同じ (symbol_code, "interval", "time") でより大きい id（後の書き込み）がある行を削除し、最大 id の行だけを残す。
+func (q *Queries) DeleteDuplicateCandles(ctx context.Context, symbolCode string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteDuplicateCandles, symbolCode)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const findCandleTimeRange = `-- name: FindCandleTimeRange :one
+SELECT count(*) AS n,
+       COALESCE(MIN("time"), 'epoch')::timestamptz AS first_time,
+       COALESCE(MAX("time"), 'epoch')::timestamptz AS last_time
+FROM candles
+WHERE symbol_code = $1 AND "interval" = $2
+`
+
+type FindCandleTimeRangeParams struct {
+	SymbolCode string
+	Interval   string
+}
+
+type FindCandleTimeRangeRow struct {
+	N         int64
+	FirstTime time.Time
+	LastTime  time.Time
+}
+
+// 足がない場合は n = 0 を返す（first_time / last_time は意味を持たない）。
+func (q *Queries) FindCandleTimeRange(ctx context.Context, arg FindCandleTimeRangeParams) (FindCandleTimeRangeRow, error) {
+	row := q.db.QueryRowContext(ctx, findCandleTimeRange, arg.SymbolCode, arg.Interval)
+	var i FindCandleTimeRangeRow
+	err := row.Scan(&i.N, &i.FirstTime, &i.LastTime)
+	return i, err
+}
+
 const findCandlesAll = `-- name: FindCandlesAll :many
 SELECT symbol_code, "interval", "time", open, high, low, close, volume
 FROM candles
 WHERE symbol_code = $1 AND "interval" = $2
-ORDER BY "time" DESC
+ORDER BY "time" DESC, id DESC
 `
 
 type FindCandlesAllParams struct {
@@ -33,6 +143,7 @@ type FindCandlesAllRow struct {
 	Volume     int64
 }
 
+// 同じ "time" の行（UNIQUE インデックス導入前の重複）は id の降順（後の書き込みが先）で並べ、結果の順序を一意に保つ。
 func (q *Queries) FindCandlesAll(ctx context.Context, arg FindCandlesAllParams) ([]FindCandlesAllRow, error) {
 	rows, err := q.db.QueryContext(ctx, findCandlesAll, arg.SymbolCode, arg.Interval)
 	if err != nil {
@@ -65,11 +176,79 @@ func (q *Queries) FindCandlesAll(ctx context.Context, arg FindCandlesAllParams)
 	return items, nil
 }
 
+const findCandlesAsOf = `-- name: FindCandlesAsOf :many
+SELECT symbol_code, "interval", "time", open, high, low, close, volume, source
+FROM candles
+WHERE symbol_code = $1
+  AND "interval" = $2
+  AND updated_at <= $3
+ORDER BY "time" DESC, id DESC
+LIMIT $4
+`
+
+type FindCandlesAsOfParams struct {
+	SymbolCode string
+	Interval   string
+	AsOf       time.Time
+	MaxRows    int32
+}
+
+type FindCandlesAsOfRow struct {
+	SymbolCode string
+	Interval   string
+	Time       time.Time
+	Open       float64
+	High       float64
+	Low        float64
+	Close      float64
+	Volume     int64
+	Source     string
+}
+
+// updated_at が as_of 以前の足のみを返す（以降に値が書き換わった足は as_of 時点の値が残っていないため除外する）。
+func (q *Queries) FindCandlesAsOf(ctx context.Context, arg FindCandlesAsOfParams) ([]FindCandlesAsOfRow, error) {
+	rows, err := q.db.QueryContext(ctx, findCandlesAsOf,
+		arg.SymbolCode,
+		arg.Interval,
+		arg.AsOf,
+		arg.MaxRows,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FindCandlesAsOfRow{}
+	for rows.Next() {
+		var i FindCandlesAsOfRow
+		if err := rows.Scan(
+			&i.SymbolCode,
+			&i.Interval,
+			&i.Time,
+			&i.Open,
+			&i.High,
+			&i.Low,
+			&i.Close,
+			&i.Volume,
+			&i.Source,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const findCandlesLimit = `-- name: FindCandlesLimit :many
 SELECT symbol_code, "interval", "time", open, high, low, close, volume
 FROM candles
 WHERE symbol_code = $1 AND "interval" = $2
-ORDER BY "time" DESC
+ORDER BY "time" DESC, id DESC
 LIMIT $3
 `
 
@@ -121,3 +300,690 @@ func (q *Queries) FindCandlesLimit(ctx context.Context, arg FindCandlesLimitPara
 	}
 	return items, nil
 }
+
+const findCandlesPage = `-- name: FindCandlesPage :many
+SELECT id, symbol_code, "interval", "time", open, high, low, close, volume
+FROM candles
+WHERE symbol_code = $1
+  AND "interval" = $2
+  AND (NOT $3::boolean
+       OR ("time", id) < ($4::timestamptz, $5::bigint))
+ORDER BY "time" DESC, id DESC
+LIMIT $6
+`
+
+type FindCandlesPageParams struct {
+	SymbolCode string
+	Interval   string
+	HasAfter   bool
+	AfterTime  time.Time
+	AfterID    int64
+	BatchSize  int32
+}
+
+type FindCandlesPageRow struct {
+	ID         int64
+	SymbolCode string
+	Interval   string
+	Time       time.Time
+	Open       float64
+	High       float64
+	Low        float64
+	Close      float64
+	Volume     int64
+}
+
+// FindEach のキーセットページング。has_after のとき ("time", id) が (after_time, after_id) より前の足だけを返し、
+// OFFSET を使わずに前のページの続きから読む（ページが進んでも読み飛ばす行が増えない）。
+func (q *Queries) FindCandlesPage(ctx context.Context, arg FindCandlesPageParams) ([]FindCandlesPageRow, error) {
+	rows, err := q.db.QueryContext(ctx, findCandlesPage,
+		arg.SymbolCode,
+		arg.Interval,
+		arg.HasAfter,
+		arg.AfterTime,
+		arg.AfterID,
+		arg.BatchSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FindCandlesPageRow{}
+	for rows.Next() {
+		var i FindCandlesPageRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.SymbolCode,
+			&i.Interval,
+			&i.Time,
+			&i.Open,
+			&i.High,
+			&i.Low,
+			&i.Close,
+			&i.Volume,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findCandlesRange = `-- name: FindCandlesRange :many
+SELECT symbol_code, "interval", "time", open, high, low, close, volume
+FROM candles
+WHERE symbol_code = $1
+  AND "interval" = $2
+  AND (NOT $3::boolean OR "time" >= $4::timestamptz)
+  AND (NOT $5::boolean OR "time" < $6::timestamptz)
+ORDER BY "time" DESC, id DESC
+LIMIT $7
+`
+
+type FindCandlesRangeParams struct {
+	SymbolCode string
+	Interval   string
+	HasStart   bool
+	StartTime  time.Time
+	HasEnd     bool
+	EndBefore  time.Time
+	MaxRows    int32
+}
+
+type FindCandlesRangeRow struct {
+	SymbolCode string
+	Interval   string
+	Time       time.Time
+	Open       float64
+	High       float64
+	Low        float64
+	Close      float64
+	Volume     int64
+}
+
+// 期間指定の読み取り。has_start のとき start_time 以降、has_end のとき end_before より前の足だけを返す。
+func (q *Queries) FindCandlesRange(ctx context.Context, arg FindCandlesRangeParams) ([]FindCandlesRangeRow, error) {
+	rows, err := q.db.QueryContext(ctx, findCandlesRange,
+		arg.SymbolCode,
+		arg.Interval,
+		arg.HasStart,
+		arg.StartTime,
+		arg.HasEnd,
+		arg.EndBefore,
+		arg.MaxRows,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FindCandlesRangeRow{}
+	for rows.Next() {
+		var i FindCandlesRangeRow
+		if err := rows.Scan(
+			&i.SymbolCode,
+			&i.Interval,
+			&i.Time,
+			&i.Open,
+			&i.High,
+			&i.Low,
+			&i.Close,
+			&i.Volume,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const inspectCandles = `-- name: InspectCandles :many
+SELECT id, "time", open, high, low, close, volume, source, created_at, updated_at
+FROM candles
+WHERE symbol_code = $1
+  AND "interval" = $2
+ORDER BY "time" DESC, id DESC
+LIMIT $3
+`
+
+type InspectCandlesParams struct {
+	SymbolCode string
+	Interval   string
+	MaxRows    int32
+}
+
+type InspectCandlesRow struct {
+	ID        int64
+	Time      time.Time
+	Open      float64
+	High      float64
+	Low       float64
+	Close     float64
+	Volume    int64
+	Source    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// 管理用の調査。足の値に加えて id・取り込み元・書き込み日時を返す。
+func (q *Queries) InspectCandles(ctx context.Context, arg InspectCandlesParams) ([]InspectCandlesRow, error) {
+	rows, err := q.db.QueryContext(ctx, inspectCandles, arg.SymbolCode, arg.Interval, arg.MaxRows)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []InspectCandlesRow{}
+	for rows.Next() {
+		var i InspectCandlesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Time,
+			&i.Open,
+			&i.High,
+			&i.Low,
+			&i.Close,
+			&i.Volume,
+			&i.Source,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAdjustments = `-- name: ListAdjustments :many
+SELECT id, symbol_code, effective_date, factor, reason, created_at, updated_at
+FROM candle_adjustments
+WHERE $1::text = '' OR symbol_code = $1::text
+ORDER BY symbol_code ASC, effective_date ASC
+`
+
+func (q *Queries) ListAdjustments(ctx context.Context, symbolCode string) ([]CandleAdjustment, error) {
+	rows, err := q.db.QueryContext(ctx, listAdjustments, symbolCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CandleAdjustment{}
+	for rows.Next() {
+		var i CandleAdjustment
+		if err := rows.Scan(
+			&i.ID,
+			&i.SymbolCode,
+			&i.EffectiveDate,
+			&i.Factor,
+			&i.Reason,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBackfillRequests = `-- name: ListBackfillRequests :many
+SELECT id, symbol_code, "time", prev_close, close, ratio, status, detected_at, resolved_at, backfill_requested_at, backfilled_at
+FROM candle_anomalies
+WHERE status = 'confirmed_split'
+  AND backfill_requested_at IS NOT NULL
+  AND backfilled_at IS NULL
+ORDER BY backfill_requested_at ASC, id ASC
+`
+
+func (q *Queries) ListBackfillRequests(ctx context.Context) ([]CandleAnomaly, error) {
+	rows, err := q.db.QueryContext(ctx, listBackfillRequests)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CandleAnomaly{}
+	for rows.Next() {
+		var i CandleAnomaly
+		if err := rows.Scan(
+			&i.ID,
+			&i.SymbolCode,
+			&i.Time,
+			&i.PrevClose,
+			&i.Close,
+			&i.Ratio,
+			&i.Status,
+			&i.DetectedAt,
+			&i.ResolvedAt,
+			&i.BackfillRequestedAt,
+			&i.BackfilledAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDailyStats = `-- name: ListDailyStats :many
+SELECT symbol_code, as_of, high_52w, low_52w, avg_vol_30d, ytd_change_pct, computed_at
+FROM symbol_daily_stats
+ORDER BY symbol_code
+`
+
+// 1 銘柄 1 行のため全件を返す（呼び出し側で必要な銘柄を選ぶ）。
+func (q *Queries) ListDailyStats(ctx context.Context) ([]SymbolDailyStat, error) {
+	rows, err := q.db.QueryContext(ctx, listDailyStats)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SymbolDailyStat{}
+	for rows.Next() {
+		var i SymbolDailyStat
+		if err := rows.Scan(
+			&i.SymbolCode,
+			&i.AsOf,
+			&i.High52w,
+			&i.Low52w,
+			&i.AvgVol30d,
+			&i.YtdChangePct,
+			&i.ComputedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDuplicateCandleSymbols = `-- name: ListDuplicateCandleSymbols :many
+SELECT DISTINCT symbol_code
+FROM (
+    SELECT symbol_code
+    FROM candles
+    GROUP BY symbol_code, "interval", "time"
+    HAVING COUNT(*) > 1
+) d
+ORDER BY symbol_code
+`
+
+func (q *Queries) ListDuplicateCandleSymbols(ctx context.Context) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listDuplicateCandleSymbols)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var symbol_code string
+		if err := rows.Scan(&symbol_code); err != nil {
+			return nil, err
+		}
+		items = append(items, symbol_code)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDuplicateCandles = `-- name: ListDuplicateCandles :many
+SELECT c.id, c."interval", c."time"
+FROM candles c
+JOIN (
+    SELECT dup."interval", dup."time"
+    FROM candles dup
+    WHERE dup.symbol_code = $1
+    GROUP BY dup."interval", dup."time"
+    HAVING COUNT(*) > 1
+) d ON d."interval" = c."interval" AND d."time" = c."time"
+WHERE c.symbol_code = $1
+ORDER BY c."interval", c."time", c.id
+FOR UPDATE OF c
+`
+
+type ListDuplicateCandlesRow struct {
+	ID       int64
+	Interval string
+	Time     time.Time
+}
+
+// トランザクション内では削除対象の行をロックする（トランザクション外では即座に解放される）。
+func (q *Queries) ListDuplicateCandles(ctx context.Context, symbolCode string) ([]ListDuplicateCandlesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listDuplicateCandles, symbolCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListDuplicateCandlesRow{}
+	for rows.Next() {
+		var i ListDuplicateCandlesRow
+		if err := rows.Scan(&i.ID, &i.Interval, &i.Time); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFreshness = `-- name: ListFreshness :many
+SELECT "interval", market, last_success_at, last_attempt_at, last_error
+FROM data_freshness
+ORDER BY "interval" ASC, market ASC
+`
+
+type ListFreshnessRow struct {
+	Interval      string
+	Market        string
+	LastSuccessAt sql.NullTime
+	LastAttemptAt time.Time
+	LastError     sql.NullString
+}
+
+func (q *Queries) ListFreshness(ctx context.Context) ([]ListFreshnessRow, error) {
+	rows, err := q.db.QueryContext(ctx, listFreshness)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListFreshnessRow{}
+	for rows.Next() {
+		var i ListFreshnessRow
+		if err := rows.Scan(
+			&i.Interval,
+			&i.Market,
+			&i.LastSuccessAt,
+			&i.LastAttemptAt,
+			&i.LastError,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markAllFreshnessFailed = `-- name: MarkAllFreshnessFailed :exec
+UPDATE data_freshness
+SET last_attempt_at = $1,
+    last_error      = $2,
+    updated_at      = now()
+`
+
+type MarkAllFreshnessFailedParams struct {
+	LastAttemptAt time.Time
+	LastError     sql.NullString
+}
+
+func (q *Queries) MarkAllFreshnessFailed(ctx context.Context, arg MarkAllFreshnessFailedParams) error {
+	_, err := q.db.ExecContext(ctx, markAllFreshnessFailed, arg.LastAttemptAt, arg.LastError)
+	return err
+}
+
+const markAnomalyBackfilled = `-- name: MarkAnomalyBackfilled :exec
+UPDATE candle_anomalies
+SET backfilled_at = $2
+WHERE id = $1
+`
+
+type MarkAnomalyBackfilledParams struct {
+	ID           int64
+	BackfilledAt sql.NullTime
+}
+
+func (q *Queries) MarkAnomalyBackfilled(ctx context.Context, arg MarkAnomalyBackfilledParams) error {
+	_, err := q.db.ExecContext(ctx, markAnomalyBackfilled, arg.ID, arg.BackfilledAt)
+	return err
+}
+
+const recordAnomaly = `-- name: RecordAnomaly :one
+INSERT INTO candle_anomalies (symbol_code, "time", prev_close, close, ratio)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (symbol_code, "time") DO UPDATE
+SET symbol_code = candle_anomalies.symbol_code
+RETURNING id, symbol_code, "time", prev_close, close, ratio, status, detected_at, resolved_at, backfill_requested_at, backfilled_at
+`
+
+type RecordAnomalyParams struct {
+	SymbolCode string
+	Time       time.Time
+	PrevClose  float64
+	Close      float64
+	Ratio      float64
+}
+
+// 既に記録済みの (symbol_code, "time") は更新せず既存の行を返す（DO UPDATE は RETURNING で既存行を得るため）。
+func (q *Queries) RecordAnomaly(ctx context.Context, arg RecordAnomalyParams) (CandleAnomaly, error) {
+	row := q.db.QueryRowContext(ctx, recordAnomaly,
+		arg.SymbolCode,
+		arg.Time,
+		arg.PrevClose,
+		arg.Close,
+		arg.Ratio,
+	)
+	var i CandleAnomaly
+	err := row.Scan(
+		&i.ID,
+		&i.SymbolCode,
+		&i.Time,
+		&i.PrevClose,
+		&i.Close,
+		&i.Ratio,
+		&i.Status,
+		&i.DetectedAt,
+		&i.ResolvedAt,
+		&i.BackfillRequestedAt,
+		&i.BackfilledAt,
+	)
+	return i, err
+}
+
+const resolveAnomaly = `-- name: ResolveAnomaly :one
+UPDATE candle_anomalies
+SET status                = $1,
+    resolved_at           = $2,
+    backfill_requested_at = CASE WHEN $3::bool THEN $2 ELSE NULL END,
+    backfilled_at         = CASE WHEN $3::bool THEN NULL ELSE backfilled_at END
+WHERE id = $4
+RETURNING id, symbol_code, "time", prev_close, close, ratio, status, detected_at, resolved_at, backfill_requested_at, backfilled_at
+`
+
+type ResolveAnomalyParams struct {
+	Status     string
+	ResolvedAt sql.NullTime
+	Backfill   bool
+	ID         int64
+}
+
+func (q *Queries) ResolveAnomaly(ctx context.Context, arg ResolveAnomalyParams) (CandleAnomaly, error) {
+	row := q.db.QueryRowContext(ctx, resolveAnomaly,
+		arg.Status,
+		arg.ResolvedAt,
+		arg.Backfill,
+		arg.ID,
+	)
+	var i CandleAnomaly
+	err := row.Scan(
+		&i.ID,
+		&i.SymbolCode,
+		&i.Time,
+		&i.PrevClose,
+		&i.Close,
+		&i.Ratio,
+		&i.Status,
+		&i.DetectedAt,
+		&i.ResolvedAt,
+		&i.BackfillRequestedAt,
+		&i.BackfilledAt,
+	)
+	return i, err
+}
+
+const updateAdjustment = `-- name: UpdateAdjustment :one
+UPDATE candle_adjustments
+SET effective_date = $1,
+    factor         = $2,
+    reason         = $3,
+    updated_at     = now()
+WHERE id = $4
+RETURNING id, symbol_code, effective_date, factor, reason, created_at, updated_at
+`
+
+type UpdateAdjustmentParams struct {
+	EffectiveDate time.Time
+	Factor        float64
+	Reason        string
+	ID            int64
+}
+
+func (q *Queries) UpdateAdjustment(ctx context.Context, arg UpdateAdjustmentParams) (CandleAdjustment, error) {
+	row := q.db.QueryRowContext(ctx, updateAdjustment,
+		arg.EffectiveDate,
+		arg.Factor,
+		arg.Reason,
+		arg.ID,
+	)
+	var i CandleAdjustment
+	err := row.Scan(
+		&i.ID,
+		&i.SymbolCode,
+		&i.EffectiveDate,
+		&i.Factor,
+		&i.Reason,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertDailyStats = `-- name: UpsertDailyStats :exec
+INSERT INTO symbol_daily_stats (symbol_code, as_of, high_52w, low_52w, avg_vol_30d, ytd_change_pct, computed_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (symbol_code) DO UPDATE
+SET as_of          = EXCLUDED.as_of,
+    high_52w       = EXCLUDED.high_52w,
+    low_52w        = EXCLUDED.low_52w,
+    avg_vol_30d    = EXCLUDED.avg_vol_30d,
+    ytd_change_pct = EXCLUDED.ytd_change_pct,
+    computed_at    = EXCLUDED.computed_at
+`
+
+type UpsertDailyStatsParams struct {
+	SymbolCode   string
+	AsOf         time.Time
+	High52w      float64
+	Low52w       float64
+	AvgVol30d    float64
+	YtdChangePct sql.NullFloat64
+	ComputedAt   time.Time
+}
+
+func (q *Queries) UpsertDailyStats(ctx context.Context, arg UpsertDailyStatsParams) error {
+	_, err := q.db.ExecContext(ctx, upsertDailyStats,
+		arg.SymbolCode,
+		arg.AsOf,
+		arg.High52w,
+		arg.Low52w,
+		arg.AvgVol30d,
+		arg.YtdChangePct,
+		arg.ComputedAt,
+	)
+	return err
+}
+
+const upsertFreshnessFailure = `-- name: UpsertFreshnessFailure :exec
+INSERT INTO data_freshness ("interval", market, last_attempt_at, last_error)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT ("interval", market) DO UPDATE
+SET last_attempt_at = EXCLUDED.last_attempt_at,
+    last_error      = EXCLUDED.last_error,
+    updated_at      = now()
+`
+
+type UpsertFreshnessFailureParams struct {
+	Interval      string
+	Market        string
+	LastAttemptAt time.Time
+	LastError     sql.NullString
+}
+
+func (q *Queries) UpsertFreshnessFailure(ctx context.Context, arg UpsertFreshnessFailureParams) error {
+	_, err := q.db.ExecContext(ctx, upsertFreshnessFailure,
+		arg.Interval,
+		arg.Market,
+		arg.LastAttemptAt,
+		arg.LastError,
+	)
+	return err
+}
+
+const upsertFreshnessSuccess = `-- name: UpsertFreshnessSuccess :exec
+INSERT INTO data_freshness ("interval", market, last_success_at, last_attempt_at, last_error)
+VALUES ($1, $2, $3, $3, NULL)
+ON CONFLICT ("interval", market) DO UPDATE
+SET last_success_at = EXCLUDED.last_success_at,
+    last_attempt_at = EXCLUDED.last_attempt_at,
+    last_error      = NULL,
+    updated_at      = now()
+`
+
+type UpsertFreshnessSuccessParams struct {
+	Interval      string
+	Market        string
+	LastSuccessAt sql.NullTime
+}
+
+func (q *Queries) UpsertFreshnessSuccess(ctx context.Context, arg UpsertFreshnessSuccessParams) error {
+	_, err := q.db.ExecContext(ctx, upsertFreshnessSuccess, arg.Interval, arg.Market, arg.LastSuccessAt)
+	return err
+}
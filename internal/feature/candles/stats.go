@@ -0,0 +1,161 @@
+package candles
+
+import "time"
+
+const (
+	// statsYearWindow は52週高値・安値の集計期間です。
+	statsYearWindow = 52 * 7 * 24 * time.Hour
+	// statsShortWindowDays / statsLongWindowDays は出来高統計の集計期間（暦日）です。
+	statsShortWindowDays = 30
+	statsLongWindowDays  = 90
+)
+
+// PricePoint は価格とその値を記録したローソク足の時刻の組です。
+type PricePoint struct {
+	Value float64
+	Time  time.Time
+}
+
+// VolumeStats は指定期間の出来高統計です。
+type VolumeStats struct {
+	Average float64
+	Max     int64
+}
+
+// Stats は銘柄詳細画面の見出しに表示するローソク足の要約統計です。
+// 期間はすべて最新ローソク足の時刻（AsOf）を基準に計算します。
+type Stats struct {
+	AsOf time.Time // 最新ローソク足の時刻
+
+	High52W PricePoint // 52週高値
+	Low52W  PricePoint // 52週安値
+	// Partial52W は保有データが52週に満たず、取得可能な範囲で52週高値・安値を算出したことを示します。
+	Partial52W bool
+
+	// YTDChangePercent は年初来騰落率（%）です。基準値は前年最終終値、
+	// 前年データがない場合は当年最初の始値です。基準値が0の場合は nil です。
+	YTDChangePercent *float64
+
+	Volume30D VolumeStats // 直近30日の出来高統計
+	Volume90D VolumeStats // 直近90日の出来高統計
+
+	AllTimeHigh PricePoint // 保有データ内の最高値
+}
+
+// ComputeStats はローソク足から要約統計を計算します。
+// 入力は任意の順序でよく、空の場合は ok=false を返します。
+func ComputeStats(cs []Candle) (s Stats, ok bool) {
+	if len(cs) == 0 {
+		return Stats{}, false
+	}
+	latest := cs[0]
+	for _, c := range cs[1:] {
+		if c.Time.After(latest.Time) {
+			latest = c
+		}
+	}
+	acc := newStatsAccumulator(latest)
+	for i := range cs {
+		acc.add(cs[i])
+	}
+	return acc.result(), true
+}
+
+// statsAccumulator は要約統計を 1 件ずつ集計します。
+// 期間の基準に最新の足が必要なため、最新の足を先に受け取り、その後の入力は任意の順序で受け付けます
+// （時間の降順に読み込む FindEach の最初の足をそのまま基準にできます）。
+type statsAccumulator struct {
+	s        Stats
+	latest   Candle
+	earliest time.Time
+	n        int
+
+	yearFrom, shortFrom, longFrom time.Time
+	ytdYear                       int
+
+	has52W                           bool
+	prevYearClose, firstOfYear       Candle
+	hasPrevYearClose, hasFirstOfYear bool
+	shortSum, longSum                int64
+	shortCount, longCount            int
+}
+
+// newStatsAccumulator は latest を最新の足（AsOf の基準）として集計を始めます。latest 自身も add で渡します。
+func newStatsAccumulator(latest Candle) *statsAccumulator {
+	a := &statsAccumulator{latest: latest, earliest: latest.Time}
+	a.s.AsOf = latest.Time
+	a.yearFrom = a.s.AsOf.Add(-statsYearWindow)
+	a.shortFrom = a.s.AsOf.AddDate(0, 0, -statsShortWindowDays)
+	a.longFrom = a.s.AsOf.AddDate(0, 0, -statsLongWindowDays)
+	a.ytdYear = a.s.AsOf.Year()
+	return a
+}
+
+// add は 1 件の足を集計に加えます。
+func (a *statsAccumulator) add(c Candle) {
+	s := &a.s
+	if a.n == 0 || c.High > s.AllTimeHigh.Value {
+		s.AllTimeHigh = PricePoint{Value: c.High, Time: c.Time}
+	}
+	a.n++
+	if c.Time.Before(a.earliest) {
+		a.earliest = c.Time
+	}
+
+	if c.Time.After(a.yearFrom) {
+		if !a.has52W || c.High > s.High52W.Value {
+			s.High52W = PricePoint{Value: c.High, Time: c.Time}
+		}
+		if !a.has52W || c.Low < s.Low52W.Value {
+			s.Low52W = PricePoint{Value: c.Low, Time: c.Time}
+		}
+		a.has52W = true
+	}
+
+	switch {
+	case c.Time.Year() < a.ytdYear:
+		if !a.hasPrevYearClose || c.Time.After(a.prevYearClose.Time) {
+			a.prevYearClose, a.hasPrevYearClose = c, true
+		}
+	case c.Time.Year() == a.ytdYear:
+		if !a.hasFirstOfYear || c.Time.Before(a.firstOfYear.Time) {
+			a.firstOfYear, a.hasFirstOfYear = c, true
+		}
+	}
+
+	if c.Time.After(a.shortFrom) {
+		a.shortSum += c.Volume
+		a.shortCount++
+		s.Volume30D.Max = max(s.Volume30D.Max, c.Volume)
+	}
+	if c.Time.After(a.longFrom) {
+		a.longSum += c.Volume
+		a.longCount++
+		s.Volume90D.Max = max(s.Volume90D.Max, c.Volume)
+	}
+}
+
+// result は集計した要約統計を返します。
+func (a *statsAccumulator) result() Stats {
+	s := a.s
+	s.Partial52W = a.earliest.After(a.yearFrom)
+	if a.shortCount > 0 {
+		s.Volume30D.Average = float64(a.shortSum) / float64(a.shortCount)
+	}
+	if a.longCount > 0 {
+		s.Volume90D.Average = float64(a.longSum) / float64(a.longCount)
+	}
+
+	var base float64
+	switch {
+	case a.hasPrevYearClose:
+		base = a.prevYearClose.Close
+	case a.hasFirstOfYear:
+		base = a.firstOfYear.Open
+	}
+	if base != 0 {
+		pct := (a.latest.Close - base) / base * 100
+		s.YTDChangePercent = &pct
+	}
+	return s
+}
@@ -0,0 +1,102 @@
+package candles
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dailySeries は start から n 日分の日足を生成します。値は日ごとに 1 ずつ増加します。
+func dailySeries(start time.Time, n int) []Candle {
+	out := make([]Candle, 0, n)
+	for i := range n {
+		v := float64(100 + i)
+		out = append(out, Candle{
+			Time:   start.AddDate(0, 0, i),
+			Open:   v,
+			High:   v + 1,
+			Low:    v - 1,
+			Close:  v,
+			Volume: int64(1000 + i),
+		})
+	}
+	return out
+}
+
+func TestComputeStats_Empty(t *testing.T) {
+	_, ok := ComputeStats(nil)
+	assert.False(t, ok)
+}
+
+func TestComputeStats_FullYear(t *testing.T) {
+	// 2023-01-01 から 500 日分（最新は 2024-05-14）
+	cs := dailySeries(mustDate(2023, 1, 1), 500)
+	// 52週より前に最高値を置き、52週高値と全期間高値が異なることを確認する
+	cs[10].High = 1000
+
+	s, ok := ComputeStats(cs)
+	require.True(t, ok)
+
+	asOf := mustDate(2023, 1, 1).AddDate(0, 0, 499)
+	assert.Equal(t, asOf, s.AsOf)
+	assert.False(t, s.Partial52W)
+
+	assert.Equal(t, PricePoint{Value: 1000, Time: mustDate(2023, 1, 11)}, s.AllTimeHigh)
+	assert.Equal(t, PricePoint{Value: 600, Time: asOf}, s.High52W)
+	// 52週 = 364日前より後の最初の日足が安値
+	lowFrom := asOf.AddDate(0, 0, -363)
+	assert.Equal(t, lowFrom, s.Low52W.Time)
+
+	// 前年最終終値（2023-12-31 = index 364 → Close 464）基準の年初来騰落率
+	require.NotNil(t, s.YTDChangePercent)
+	assert.InDelta(t, (599.0-464.0)/464.0*100, *s.YTDChangePercent, 1e-9)
+
+	// 直近30日は index 470..499（30件）
+	assert.Equal(t, int64(1499), s.Volume30D.Max)
+	assert.InDelta(t, float64(1470+1499)/2, s.Volume30D.Average, 1e-9)
+	// 直近90日は index 410..499（90件）
+	assert.Equal(t, int64(1499), s.Volume90D.Max)
+	assert.InDelta(t, float64(1410+1499)/2, s.Volume90D.Average, 1e-9)
+}
+
+func TestComputeStats_ShortHistory(t *testing.T) {
+	// 2024-01-02 から 10 日分。前年データなし・52週に満たない
+	cs := dailySeries(mustDate(2024, 1, 2), 10)
+
+	s, ok := ComputeStats(cs)
+	require.True(t, ok)
+
+	assert.True(t, s.Partial52W)
+	assert.Equal(t, PricePoint{Value: 110, Time: mustDate(2024, 1, 11)}, s.High52W)
+	assert.Equal(t, PricePoint{Value: 99, Time: mustDate(2024, 1, 2)}, s.Low52W)
+	// 前年データがないため当年最初の始値（100）基準
+	require.NotNil(t, s.YTDChangePercent)
+	assert.InDelta(t, 9.0, *s.YTDChangePercent, 1e-9)
+	assert.Equal(t, int64(1009), s.Volume30D.Max)
+	assert.InDelta(t, 1004.5, s.Volume30D.Average, 1e-9)
+}
+
+func TestComputeStats_UnorderedInput(t *testing.T) {
+	cs := dailySeries(mustDate(2024, 1, 2), 5)
+	reversed := make([]Candle, len(cs))
+	for i, c := range cs {
+		reversed[len(cs)-1-i] = c
+	}
+
+	want, _ := ComputeStats(cs)
+	got, ok := ComputeStats(reversed)
+	require.True(t, ok)
+	assert.Equal(t, want, got)
+}
+
+func TestComputeStats_ZeroBase(t *testing.T) {
+	cs := []Candle{
+		{Time: mustDate(2024, 1, 2), Open: 0, High: 1, Low: 0, Close: 1, Volume: 10},
+	}
+
+	s, ok := ComputeStats(cs)
+	require.True(t, ok)
+	assert.Nil(t, s.YTDChangePercent)
+}
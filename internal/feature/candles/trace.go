@@ -0,0 +1,145 @@
+package candles
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TraceSource はローソク足を返した層です（デバッグ用のヘッダー X-Cache-Status の値）。
+type TraceSource string
+
+const (
+	TraceL1    TraceSource = "l1"    // プロセス内キャッシュ
+	TraceRedis TraceSource = "redis" // Redis のキャッシュ
+	TraceDB    TraceSource = "db"    // PostgreSQL
+)
+
+// TraceStage は所要時間を記録する処理の段階です。
+type TraceStage int
+
+const (
+	TraceCacheGet TraceStage = iota // Redis からの読み取り
+	TraceDBQuery                    // DB のクエリ
+	TraceUpstream                   // 外部API（Twelve Data）の呼び出し
+	traceStages
+)
+
+// String は段階の名前（ヘッダー名 X-Timing-<名前> に使う）を返します。
+func (s TraceStage) String() string {
+	switch s {
+	case TraceCacheGet:
+		return "Cache-Get"
+	case TraceDBQuery:
+		return "DB-Query"
+	case TraceUpstream:
+		return "Upstream"
+	default:
+		return "Unknown"
+	}
+}
+
+// Trace はリクエストのローソク足の取得元と段階ごとの所要時間を記録します（クライアントの調査用のデバッグヘッダー）。
+//
+// WithTrace で context に格納したリクエストでのみ記録し、キャッシュ・リポジトリ・外部APIのクライアントは
+// TraceFromContext で取り出して書き込みます。格納していない context では nil を返し、nil の Trace の
+// メソッドは何もしない（時刻も取得せず割り当てもしない）ため、無効時のコストは context の参照だけです。
+// 先行再取得などのバックグラウンド処理からの書き込みに備え、排他して記録します。
+type Trace struct {
+	mu      sync.Mutex
+	source  TraceSource
+	key     string
+	timings [traceStages]time.Duration
+	seen    [traceStages]bool
+}
+
+// TraceTiming は段階 1 つの所要時間です（同じ段階を複数回実行した場合は合計）。
+type TraceTiming struct {
+	Stage    TraceStage
+	Duration time.Duration
+}
+
+// TraceResult は Trace に記録した内容です。
+type TraceResult struct {
+	Source  TraceSource // 未記録の場合は空
+	Key     string      // 取得元のキャッシュキー（DB から直接読んだ場合は空）
+	Timings []TraceTiming
+}
+
+// traceKey は context に Trace を格納するための非公開キー型です。
+type traceKey struct{}
+
+// WithTrace は新しい Trace を格納した context と、その Trace を返します。
+func WithTrace(ctx context.Context) (context.Context, *Trace) {
+	t := &Trace{}
+	return context.WithValue(ctx, traceKey{}, t), t
+}
+
+// TraceFromContext は ctx の Trace を返します。格納されていない場合は nil を返します。
+func TraceFromContext(ctx context.Context) *Trace {
+	t, _ := ctx.Value(traceKey{}).(*Trace)
+	return t
+}
+
+// withoutTrace は ctx の Trace を取り除いた context を返します。
+// リクエストの応答後も続くバックグラウンド処理が、リクエストの記録を書き換えないようにするために使います。
+func withoutTrace(ctx context.Context) context.Context {
+	if TraceFromContext(ctx) == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, traceKey{}, (*Trace)(nil))
+}
+
+// Begin は段階の開始時刻を返します。t が nil の場合は時刻を取得せずゼロ値を返します。
+func (t *Trace) Begin() time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// End は start（Begin の戻り値）からの経過時間を stage に加えます。
+func (t *Trace) End(stage TraceStage, start time.Time) {
+	if t == nil {
+		return
+	}
+	t.Add(stage, time.Since(start))
+}
+
+// Add は stage に d を加えます。
+func (t *Trace) Add(stage TraceStage, d time.Duration) {
+	if t == nil || stage < 0 || stage >= traceStages {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.timings[stage] += d
+	t.seen[stage] = true
+}
+
+// SetSource はローソク足を返した層と、そのキャッシュキーを記録します（後の記録で上書きします）。
+func (t *Trace) SetSource(source TraceSource, key string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.source = source
+	t.key = key
+}
+
+// Result は記録した内容を返します。段階は記録したものだけを TraceStage の順に返します。
+func (t *Trace) Result() TraceResult {
+	if t == nil {
+		return TraceResult{}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	r := TraceResult{Source: t.source, Key: t.key}
+	for s := range traceStages {
+		if t.seen[s] {
+			r.Timings = append(r.Timings, TraceTiming{Stage: s, Duration: t.timings[s]})
+		}
+	}
+	return r
+}
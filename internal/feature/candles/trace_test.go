@@ -0,0 +1,92 @@
+package candles
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/testsupport"
+)
+
+func TestTrace_Result(t *testing.T) {
+	t.Parallel()
+	ctx, tr := WithTrace(context.Background())
+	require.Same(t, tr, TraceFromContext(ctx))
+
+	tr.Add(TraceDBQuery, 3*time.Millisecond)
+	tr.Add(TraceCacheGet, time.Millisecond)
+	tr.Add(TraceDBQuery, 2*time.Millisecond)
+	tr.SetSource(TraceRedis, "k1")
+	tr.SetSource(TraceDB, "k2")
+
+	assert.Equal(t, TraceResult{
+		Source: TraceDB,
+		Key:    "k2",
+		Timings: []TraceTiming{
+			{Stage: TraceCacheGet, Duration: time.Millisecond},
+			{Stage: TraceDBQuery, Duration: 5 * time.Millisecond},
+		},
+	}, tr.Result(), "段階の順に合計を返し、取得元は後の記録で上書きする")
+	assert.Nil(t, TraceFromContext(withoutTrace(ctx)))
+}
+
+// TestTrace_DisabledIsFree は Trace を格納していない context では記録の呼び出しが割り当てをしないことを検証します。
+func TestTrace_DisabledIsFree(t *testing.T) {
+	ctx := context.Background()
+	allocs := testing.AllocsPerRun(100, func() {
+		tr := TraceFromContext(ctx)
+		start := tr.Begin()
+		tr.End(TraceCacheGet, start)
+		tr.Add(TraceUpstream, time.Millisecond)
+		tr.SetSource(TraceL1, "key")
+		_ = tr.Result()
+	})
+	assert.Zero(t, allocs)
+	assert.True(t, TraceFromContext(ctx).Begin().IsZero(), "無効時は時刻を取得しない")
+	assert.Equal(t, ctx, withoutTrace(ctx))
+}
+
+// TestCachingRepository_Trace は Find が取得元の層（L1・Redis・DB）と Redis の読み取り時間を記録することを検証します。
+func TestCachingRepository_Trace(t *testing.T) {
+	t.Parallel()
+	clock := testsupport.NewClock(time.Now())
+	repo, _ := newLocalCachedRepo(t, newCountingInner(newestFirst(100)), clock)
+	find := func() TraceResult {
+		ctx, tr := WithTrace(context.Background())
+		_, err := repo.Find(ctx, "AAPL", "1day", 30)
+		require.NoError(t, err)
+		return tr.Result()
+	}
+	stages := func(r TraceResult) []TraceStage {
+		var out []TraceStage
+		for _, tm := range r.Timings {
+			out = append(out, tm.Stage)
+		}
+		return out
+	}
+	key := repo.bucketKey("AAPL", "1day", 50)
+
+	miss := find()
+	assert.Equal(t, TraceDB, miss.Source)
+	assert.Equal(t, key, miss.Key)
+	assert.Equal(t, []TraceStage{TraceCacheGet}, stages(miss), "DB の時間は基盤リポジトリが記録する")
+
+	l1 := find()
+	assert.Equal(t, TraceL1, l1.Source)
+	assert.Equal(t, key, l1.Key)
+	assert.Empty(t, l1.Timings, "L1 のヒットは Redis に問い合わせない")
+
+	clock.Advance(10 * time.Second) // L1 の期限切れ
+	hit := find()
+	assert.Equal(t, TraceRedis, hit.Source)
+	assert.Equal(t, []TraceStage{TraceCacheGet}, stages(hit))
+
+	bypass := NewCachingRepository(nil, time.Hour, newCountingInner(newestFirst(10)), "candles", nil)
+	ctx, tr := WithTrace(context.Background())
+	_, err := bypass.Find(ctx, "AAPL", "1day", 5)
+	require.NoError(t, err)
+	assert.Equal(t, TraceResult{Source: TraceDB}, tr.Result(), "キャッシュを使わない場合は DB（キーなし）")
+}
@@ -0,0 +1,83 @@
+package twelvedata
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+)
+
+// DefaultPlan は TWELVE_DATA_PLAN 未設定時に使用するプラン名です（無料プラン）。
+const DefaultPlan = "basic"
+
+// allIntervals は Twelve Data の time_series が受け付ける時間間隔の一覧です。
+var allIntervals = []string{"1min", "5min", "15min", "30min", "45min", "1h", "2h", "4h", "8h", "1day", "1week", "1month"}
+
+// planPresets はプラン名ごとの取得制約です。
+// 無料プランで拒否される組み合わせを事前に弾くため、本リポジトリで利用実績のある範囲に絞っています。
+var planPresets = map[string]Capabilities{
+	"basic": {
+		Plan:          "basic",
+		MaxOutputSize: 5000,
+		Intervals:     []string{"1h", "4h", "1day", "1week", "1month"},
+	},
+	"grow": {
+		Plan:          "grow",
+		MaxOutputSize: 5000,
+		Intervals:     allIntervals,
+	},
+	"pro": {
+		Plan:          "pro",
+		MaxOutputSize: 5000,
+		Intervals:     allIntervals,
+	},
+}
+
+// Capabilities は契約プランに応じた Twelve Data の取得制約です。
+// ゼロ値は制約なし（検証を行わない）として扱います。
+type Capabilities struct {
+	Plan          string   // プラン名（ログ・エラーメッセージ用）
+	MaxOutputSize int      // 1 リクエストあたりの outputsize 上限（0 以下なら無制限）
+	Intervals     []string // 利用可能な時間間隔（空ならすべて許可）
+}
+
+// CapabilitiesForPlan はプリセットからプラン名に対応する Capabilities を返します。
+// 名前は大文字小文字を区別せず、未知のプラン名はエラーを返します。
+func CapabilitiesForPlan(plan string) (Capabilities, error) {
+	c, ok := planPresets[strings.ToLower(strings.TrimSpace(plan))]
+	if !ok {
+		return Capabilities{}, fmt.Errorf("twelvedata: unknown plan %q", plan)
+	}
+	c.Intervals = slices.Clone(c.Intervals)
+	return c, nil
+}
+
+// Validate は interval と outputsize の組み合わせがプラン上取得可能かを検証します。
+// 取得不可の場合は理由を含む *candles.UnsupportedRequestError（candles.ErrUnsupportedRequest）を返します。
+func (c Capabilities) Validate(interval string, outputsize int) error {
+	if len(c.Intervals) > 0 && !slices.Contains(c.Intervals, interval) {
+		return &candles.UnsupportedRequestError{
+			Interval:   interval,
+			OutputSize: outputsize,
+			Reason: fmt.Sprintf("interval %q is not available on the %s plan (supported: %s)",
+				interval, c.planName(), strings.Join(c.Intervals, ", ")),
+		}
+	}
+	if c.MaxOutputSize > 0 && outputsize > c.MaxOutputSize {
+		return &candles.UnsupportedRequestError{
+			Interval:   interval,
+			OutputSize: outputsize,
+			Reason: fmt.Sprintf("outputsize %d exceeds the %s plan limit of %d",
+				outputsize, c.planName(), c.MaxOutputSize),
+		}
+	}
+	return nil
+}
+
+func (c Capabilities) planName() string {
+	if c.Plan == "" {
+		return "configured"
+	}
+	return c.Plan
+}
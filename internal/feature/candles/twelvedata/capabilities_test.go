@@ -0,0 +1,134 @@
+package twelvedata
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+)
+
+// countingTransport は発行された HTTP リクエスト数を数える RoundTripper です。
+// 実際の通信は行わず、常に空の時系列レスポンスを返します。
+type countingTransport struct {
+	requests atomic.Int32
+}
+
+func (c *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	c.requests.Add(1)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       http.NoBody,
+		Request:    r,
+	}, nil
+}
+
+func TestCapabilitiesForPlan(t *testing.T) {
+	t.Parallel()
+
+	caps, err := CapabilitiesForPlan(" Basic ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if caps.Plan != "basic" || caps.MaxOutputSize != 5000 {
+		t.Errorf("caps = %+v, want basic plan with 5000 max", caps)
+	}
+
+	// プリセットのスライスを書き換えても他の呼び出しに影響しない
+	caps.Intervals[0] = "mutated"
+	again, _ := CapabilitiesForPlan("basic")
+	if again.Intervals[0] == "mutated" {
+		t.Error("CapabilitiesForPlan must return a copy of the preset intervals")
+	}
+
+	if _, err := CapabilitiesForPlan("enterprise-plus"); err == nil {
+		t.Error("expected error for unknown plan")
+	}
+}
+
+func TestCapabilities_Validate(t *testing.T) {
+	t.Parallel()
+
+	basic, _ := CapabilitiesForPlan("basic")
+	tests := []struct {
+		name       string
+		caps       Capabilities
+		interval   string
+		outputsize int
+		wantReason string // 空なら成功を期待
+	}{
+		{name: "対応する組み合わせ", caps: basic, interval: "1day", outputsize: 5000},
+		{name: "未対応の時間間隔", caps: basic, interval: "1min", outputsize: 100, wantReason: `interval "1min" is not available on the basic plan`},
+		{name: "上限超過", caps: basic, interval: "1day", outputsize: 5001, wantReason: "outputsize 5001 exceeds the basic plan limit of 5000"},
+		{name: "ゼロ値は制約なし", caps: Capabilities{}, interval: "1min", outputsize: 100000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.caps.Validate(tt.interval, tt.outputsize)
+			if tt.wantReason == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, candles.ErrUnsupportedRequest) {
+				t.Fatalf("err = %v, want ErrUnsupportedRequest", err)
+			}
+			var ue *candles.UnsupportedRequestError
+			if !errors.As(err, &ue) || !strings.Contains(ue.Reason, tt.wantReason) {
+				t.Errorf("reason = %q, want containing %q", err, tt.wantReason)
+			}
+		})
+	}
+}
+
+// TestTwelveDataMarket_GetTimeSeries_RejectsWithoutRequest はプラン上取得できない要求で
+// HTTP リクエストを発行しない（API クレジットを消費しない）ことを検証します。
+func TestTwelveDataMarket_GetTimeSeries_RejectsWithoutRequest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		interval   string
+		outputsize int
+	}{
+		{name: "未対応の時間間隔", interval: "1min", outputsize: 100},
+		{name: "上限超過の outputsize", interval: "1day", outputsize: 6000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			transport := &countingTransport{}
+			market := NewTwelveDataMarket(NewConfig("test-key", "https://api.test.invalid"), &http.Client{Transport: transport})
+
+			_, err := market.GetTimeSeries(context.Background(), "AAPL", tt.interval, tt.outputsize, time.UTC)
+			if !errors.Is(err, candles.ErrUnsupportedRequest) {
+				t.Fatalf("err = %v, want ErrUnsupportedRequest", err)
+			}
+			if n := transport.requests.Load(); n != 0 {
+				t.Errorf("issued %d HTTP requests, want 0", n)
+			}
+		})
+	}
+
+	t.Run("対応する要求は発行される", func(t *testing.T) {
+		t.Parallel()
+		transport := &countingTransport{}
+		market := NewTwelveDataMarket(NewConfig("test-key", "https://api.test.invalid"), &http.Client{Transport: transport})
+		if market.MaxOutputSize() != 5000 {
+			t.Errorf("MaxOutputSize = %d, want 5000", market.MaxOutputSize())
+		}
+
+		// 空ボディのためデコードは失敗するが、リクエストが 1 回発行されたことのみ確認する
+		_, _ = market.GetTimeSeries(context.Background(), "AAPL", "1day", 5000, time.UTC)
+		if n := transport.requests.Load(); n != 1 {
+			t.Errorf("issued %d HTTP requests, want 1", n)
+		}
+	})
+}
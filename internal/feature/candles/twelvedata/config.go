@@ -2,33 +2,86 @@
 package twelvedata
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
 )
 
+// DefaultMaxResponseBytes は time_series レスポンスボディの読み込み上限のデフォルト値（10MB）です。
+// outputsize 5000 の日足でも 1MB 未満のため、十分な余裕を持たせています。
+const DefaultMaxResponseBytes int64 = 10 << 20
+
 // Config はTwelve Data APIクライアントの設定を保持します。
 type Config struct {
 	TwelveDataAPIKey string        // 認証用APIキー
 	BaseURL          string        // APIのベースURL（例: "https://api.twelvedata.com"）
-	Timeout          time.Duration // HTTPリクエストタイムアウト
+	Timeout          time.Duration // HTTPリクエストタイムアウト（http.Client 全体に適用）
+
+	// RequestTimeout は GetTimeSeries 1 回（リトライ込み）あたりの上限時間。0 以下なら ctx の期限のみに従う。
+	// ctx に期限がある場合は早い方が適用される。用途別（ingest は長め、ユーザー向けは短め）に
+	// WithRequestTimeout でクライアントを派生させて使い分ける。
+	RequestTimeout time.Duration
+	// MaxResponseBytes はレスポンスボディの読み込み上限。超過時は ErrResponseTooLarge を返す。0 以下なら DefaultMaxResponseBytes。
+	MaxResponseBytes int64
 
 	// リトライ設定（5xx・ネットワークエラー・429 を対象とする指数バックオフ）。
 	MaxRetries       int           // リトライ回数（0 でリトライ無効、合計試行回数は MaxRetries+1）
 	RetryBaseBackoff time.Duration // 初回バックオフ（係数 4 で増加: 例 500ms → 2s → 8s）
 	RetryMaxBackoff  time.Duration // バックオフ上限（Retry-After 含む）
 	RetryJitterRatio float64       // ジッター比率（0.2 なら ±20%）
+
+	// Capabilities は契約プランの取得制約。GetTimeSeries は呼び出し前にこれで要求を検証する。
+	Capabilities Capabilities
+
+	// Recording はリクエストの URL とレスポンスのボディの記録の設定（既定は無効）。HTTP クライアントの組み立て時に Recorder を挟む。
+	Recording RecordingConfig
+
+	// キャッシュプロキシ経由で呼び出すための設定（既定はいずれも無効で、Twelve Data を直接呼び出す）。
+	ProxyAuthHeader string   // 全リクエストに付与するプロキシの認証ヘッダーの名前（空なら付与しない）
+	ProxyAuthValue  string   // ProxyAuthHeader の値
+	APIKeyInHeader  bool     // true なら APIキーをクエリではなく Authorization ヘッダー（"apikey <key>"）で送る
+	AllowedHosts    []string // BaseURL に許可するホスト（空なら制限しない）。ValidateBaseURL で検証する
+}
+
+// ValidateBaseURL は BaseURL のホストが AllowedHosts に含まれることを検証します。
+// 環境ごとに許可するホストを設定し、本番環境がステージング用のプロキシを向く等の設定ミスを起動時に検出するためです。
+// AllowedHosts が空の場合、または BaseURL が未設定の場合は検証しません。
+// 許可するホストはホスト名（大文字小文字を区別しない）、またはポートを含む "host:port" で指定します。
+func (c Config) ValidateBaseURL() error {
+	if len(c.AllowedHosts) == 0 || c.BaseURL == "" {
+		return nil
+	}
+	u, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return err
+	}
+	if u.Host == "" {
+		return errors.New("missing host")
+	}
+	if slices.ContainsFunc(c.AllowedHosts, func(h string) bool {
+		return strings.EqualFold(h, u.Hostname()) || strings.EqualFold(h, u.Host)
+	}) {
+		return nil
+	}
+	return fmt.Errorf("host %q is not in allowed hosts %v", u.Host, c.AllowedHosts)
 }
 
 // NewConfig は呼び出し側から渡された APIキー・ベースURL を用いて Twelve Data の設定を組み立てます。
 // 環境変数は直接読まず（読み込みは internal/app/config に集約）、タイムアウト・リトライ等の
-// デフォルト値のみをこの層で所有します。
+// デフォルト値のみをこの層で所有します。取得制約は DefaultPlan のプリセットを使用します。
 func NewConfig(apiKey, baseURL string) Config {
 	return Config{
 		TwelveDataAPIKey: apiKey,
 		BaseURL:          baseURL,
 		Timeout:          10 * time.Second,
+		MaxResponseBytes: DefaultMaxResponseBytes,
 		MaxRetries:       3,
 		RetryBaseBackoff: 500 * time.Millisecond,
 		RetryMaxBackoff:  30 * time.Second,
 		RetryJitterRatio: 0.2,
+		Capabilities:     planPresets[DefaultPlan],
 	}
 }
@@ -0,0 +1,109 @@
+package twelvedata
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+)
+
+// Dividend は Twelve Data の Dividends endpoint の 1 件（権利落ち日と 1 株当たりの配当額）です。
+type Dividend struct {
+	ExDate time.Time // UTC の 0 時
+	Amount float64
+}
+
+// Earning は Twelve Data の Earnings endpoint の 1 件（決算発表日と EPS の予想・実績）です。
+// 未発表・未提供の値は nil です。
+type Earning struct {
+	Date        time.Time // UTC の 0 時
+	EPSEstimate *float64
+	EPSActual   *float64
+}
+
+type dividendsResponse struct {
+	Status    string `json:"status"`
+	Code      int    `json:"code"`
+	Message   string `json:"message"`
+	Dividends []struct {
+		ExDate string  `json:"ex_date"`
+		Amount float64 `json:"amount"`
+	} `json:"dividends"`
+}
+
+type earningsResponse struct {
+	Status   string `json:"status"`
+	Code     int    `json:"code"`
+	Message  string `json:"message"`
+	Earnings []struct {
+		Date        string   `json:"date"`
+		EPSEstimate *float64 `json:"eps_estimate"`
+		EPSActual   *float64 `json:"eps_actual"`
+	} `json:"earnings"`
+}
+
+// GetDividends はTwelve DataのDividends endpointから、from 〜 to（両端を含む）に権利落ち日がある配当を返します。
+func (t *TwelveDataMarket) GetDividends(ctx context.Context, symbol string, from, to time.Time) ([]Dividend, error) {
+	var body dividendsResponse
+	if err := t.getDateRange(ctx, "dividends", symbol, from, to, &body); err != nil {
+		return nil, err
+	}
+	if body.Status == "error" {
+		return nil, t.statusError(body.Code, body.Message)
+	}
+	out := make([]Dividend, 0, len(body.Dividends))
+	for _, d := range body.Dividends {
+		date, err := time.Parse(time.DateOnly, d.ExDate)
+		if err != nil {
+			return nil, fmt.Errorf("twelvedata dividends %s: parse ex_date %q: %w", symbol, d.ExDate, err)
+		}
+		out = append(out, Dividend{ExDate: date, Amount: d.Amount})
+	}
+	return out, nil
+}
+
+// GetEarnings はTwelve DataのEarnings endpointから、from 〜 to（両端を含む）の決算発表（予定を含む）を返します。
+func (t *TwelveDataMarket) GetEarnings(ctx context.Context, symbol string, from, to time.Time) ([]Earning, error) {
+	var body earningsResponse
+	if err := t.getDateRange(ctx, "earnings", symbol, from, to, &body); err != nil {
+		return nil, err
+	}
+	if body.Status == "error" {
+		return nil, t.statusError(body.Code, body.Message)
+	}
+	out := make([]Earning, 0, len(body.Earnings))
+	for _, e := range body.Earnings {
+		date, err := time.Parse(time.DateOnly, e.Date)
+		if err != nil {
+			return nil, fmt.Errorf("twelvedata earnings %s: parse date %q: %w", symbol, e.Date, err)
+		}
+		out = append(out, Earning{Date: date, EPSEstimate: e.EPSEstimate, EPSActual: e.EPSActual})
+	}
+	return out, nil
+}
+
+// getDateRange は start_date / end_date を指定して endpoint を呼び出し、レスポンスボディを dst にデコードします。
+func (t *TwelveDataMarket) getDateRange(ctx context.Context, endpoint, symbol string, from, to time.Time, dst any) error {
+	q := url.Values{}
+	q.Set("symbol", symbol)
+	q.Set("start_date", from.Format(time.DateOnly))
+	q.Set("end_date", to.Format(time.DateOnly))
+
+	if t.cfg.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.cfg.RequestTimeout)
+		defer cancel()
+	}
+
+	res, err := t.doRequestWithRetry(ctx, endpoint, q)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			slog.Warn("failed to close response body", "error", err)
+		}
+	}()
+	return t.decodeBody(res, dst)
+}
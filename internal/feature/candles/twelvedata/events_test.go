@@ -0,0 +1,148 @@
+package twelvedata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newEventsServer は path へのリクエストのクエリを検証し、body を返すテストサーバーを起動します。
+func newEventsServer(t *testing.T, path, body string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			t.Errorf("expected path %s, got %s", path, r.URL.Path)
+		}
+		q := r.URL.Query()
+		for key, want := range map[string]string{"symbol": "AAPL", "start_date": "2025-08-01", "end_date": "2026-01-31", "apikey": "test-key"} {
+			if got := q.Get(key); got != want {
+				t.Errorf("expected %s %s, got %s", key, want, got)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+var (
+	eventsFrom = time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	eventsTo   = time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+)
+
+func TestTwelveDataMarket_GetDividends(t *testing.T) {
+	t.Parallel()
+
+	server := newEventsServer(t, "/dividends", `{
+		"meta": {"symbol": "AAPL", "name": "Apple Inc", "currency": "USD", "exchange": "NASDAQ"},
+		"dividends": [
+			{"ex_date": "2025-11-10", "amount": 0.26},
+			{"ex_date": "2025-08-11", "amount": 0.26}
+		]
+	}`)
+	market := NewTwelveDataMarket(Config{TwelveDataAPIKey: "test-key", BaseURL: server.URL}, server.Client())
+
+	got, err := market.GetDividends(context.Background(), "AAPL", eventsFrom, eventsTo)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Dividend{
+		{ExDate: time.Date(2025, 11, 10, 0, 0, 0, 0, time.UTC), Amount: 0.26},
+		{ExDate: time.Date(2025, 8, 11, 0, 0, 0, 0, time.UTC), Amount: 0.26},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("len: got %d, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !got[i].ExDate.Equal(want[i].ExDate) || got[i].Amount != want[i].Amount {
+			t.Errorf("[%d]: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestTwelveDataMarket_GetEarnings(t *testing.T) {
+	t.Parallel()
+
+	// 発表予定の決算は eps_actual が null で返る
+	server := newEventsServer(t, "/earnings", `{
+		"meta": {"symbol": "AAPL", "name": "Apple Inc", "currency": "USD", "exchange": "NASDAQ"},
+		"earnings": [
+			{"date": "2026-01-29", "time": "After Hours", "eps_estimate": 2.35, "eps_actual": null, "difference": null, "surprise_prc": null},
+			{"date": "2025-10-30", "time": "After Hours", "eps_estimate": 1.77, "eps_actual": 1.85, "difference": 0.08, "surprise_prc": 4.52}
+		],
+		"status": "ok"
+	}`)
+	market := NewTwelveDataMarket(Config{TwelveDataAPIKey: "test-key", BaseURL: server.URL}, server.Client())
+
+	got, err := market.GetEarnings(context.Background(), "AAPL", eventsFrom, eventsTo)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("len: got %d, want 2", len(got))
+	}
+
+	if want := time.Date(2026, 1, 29, 0, 0, 0, 0, time.UTC); !got[0].Date.Equal(want) {
+		t.Errorf("date: got %v, want %v", got[0].Date, want)
+	}
+	if got[0].EPSEstimate == nil || *got[0].EPSEstimate != 2.35 {
+		t.Errorf("eps_estimate: got %v, want 2.35", got[0].EPSEstimate)
+	}
+	if got[0].EPSActual != nil {
+		t.Errorf("未発表の実績は nil: got %v", *got[0].EPSActual)
+	}
+
+	if want := time.Date(2025, 10, 30, 0, 0, 0, 0, time.UTC); !got[1].Date.Equal(want) {
+		t.Errorf("date: got %v, want %v", got[1].Date, want)
+	}
+	if got[1].EPSActual == nil || *got[1].EPSActual != 1.85 {
+		t.Errorf("eps_actual: got %v, want 1.85", got[1].EPSActual)
+	}
+}
+
+func TestTwelveDataMarket_GetEvents_Empty(t *testing.T) {
+	t.Parallel()
+
+	server := newEventsServer(t, "/dividends", `{"meta": {"symbol": "AAPL"}, "dividends": []}`)
+	market := NewTwelveDataMarket(Config{TwelveDataAPIKey: "test-key", BaseURL: server.URL}, server.Client())
+
+	got, err := market.GetDividends(context.Background(), "AAPL", eventsFrom, eventsTo)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("expected no dividends, got %v", got)
+	}
+}
+
+func TestTwelveDataMarket_GetEvents_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		body    string
+		wantMsg string
+	}{
+		{"API エラー", `{"status": "error", "code": 403, "message": "dividends is available exclusively with grow or pro plans"}`, "grow or pro plans"},
+		{"日付を解釈できない", `{"earnings": [{"date": "01/29/2026", "eps_estimate": 2.35}]}`, "parse date"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			server := newEventsServer(t, "/earnings", tt.body)
+			market := NewTwelveDataMarket(Config{TwelveDataAPIKey: "test-key", BaseURL: server.URL}, server.Client())
+
+			_, err := market.GetEarnings(context.Background(), "AAPL", eventsFrom, eventsTo)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("expected error containing %q, got %v", tt.wantMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package twelvedata
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+)
+
+type exchangeRateResponse struct {
+	Status    string  `json:"status"`
+	Code      int     `json:"code"`
+	Message   string  `json:"message"`
+	Symbol    string  `json:"symbol"`
+	Rate      float64 `json:"rate"`
+	Timestamp int64   `json:"timestamp"` // UNIX 秒
+}
+
+// GetExchangeRate はTwelve DataのExchange rate endpointから 1 base あたりの quote のレートと、その時刻を返します。
+func (t *TwelveDataMarket) GetExchangeRate(ctx context.Context, base, quote string) (float64, time.Time, error) {
+	pair := base + "/" + quote
+	q := url.Values{}
+	q.Set("symbol", pair)
+
+	// ユーザー向けのリクエストからも呼ばれるため、time_series と同じく RequestTimeout を適用する
+	if t.cfg.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.cfg.RequestTimeout)
+		defer cancel()
+	}
+
+	res, err := t.doRequestWithRetry(ctx, "exchange_rate", q)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			slog.Warn("failed to close response body", "error", err)
+		}
+	}()
+
+	var body exchangeRateResponse
+	if err := t.decodeBody(res, &body); err != nil {
+		return 0, time.Time{}, err
+	}
+	if body.Status == "error" {
+		return 0, time.Time{}, t.statusError(body.Code, body.Message)
+	}
+	if body.Rate <= 0 {
+		return 0, time.Time{}, fmt.Errorf("twelvedata: invalid exchange rate %v for %q", body.Rate, pair)
+	}
+	at := time.Now().UTC()
+	if body.Timestamp > 0 {
+		at = time.Unix(body.Timestamp, 0).UTC()
+	}
+	return body.Rate, at, nil
+}
@@ -0,0 +1,61 @@
+package twelvedata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTwelveDataMarket_GetExchangeRate_Success(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/exchange_rate" {
+			t.Errorf("expected path /exchange_rate, got %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("symbol") != "USD/JPY" {
+			t.Errorf("expected symbol USD/JPY, got %s", r.URL.Query().Get("symbol"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"symbol":"USD/JPY","rate":150.125,"timestamp":1790000000}`))
+	}))
+	defer server.Close()
+
+	market := NewTwelveDataMarket(Config{TwelveDataAPIKey: "test-key", BaseURL: server.URL}, server.Client())
+
+	rate, at, err := market.GetExchangeRate(context.Background(), "USD", "JPY")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rate != 150.125 {
+		t.Errorf("unexpected rate: %v", rate)
+	}
+	if !at.Equal(time.Unix(1790000000, 0)) {
+		t.Errorf("unexpected timestamp: %v", at)
+	}
+}
+
+func TestTwelveDataMarket_GetExchangeRate_APIError(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"error","message":"**symbol** not found: XXX/JPY"}`))
+	}))
+	defer server.Close()
+
+	market := NewTwelveDataMarket(Config{TwelveDataAPIKey: "test-key", BaseURL: server.URL}, server.Client())
+
+	_, _, err := market.GetExchangeRate(context.Background(), "XXX", "JPY")
+
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected API error message, got %v", err)
+	}
+}
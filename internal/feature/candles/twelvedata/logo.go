@@ -2,16 +2,15 @@ package twelvedata
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
-	"net/http"
 	"net/url"
 	"strings"
 )
 
 type logoResponse struct {
 	Status  string `json:"status"`
+	Code    int    `json:"code"`
 	Message string `json:"message"`
 	URL     string `json:"url"`
 }
@@ -20,10 +19,8 @@ type logoResponse struct {
 func (t *TwelveDataMarket) GetLogoURL(ctx context.Context, symbol string) (string, error) {
 	q := url.Values{}
 	q.Set("symbol", symbol)
-	q.Set("apikey", t.cfg.TwelveDataAPIKey)
 
-	u := fmt.Sprintf("%s/logo?%s", t.cfg.BaseURL, q.Encode())
-	res, err := t.doRequestWithRetry(ctx, http.MethodGet, u)
+	res, err := t.doRequestWithRetry(ctx, "logo", q)
 	if err != nil {
 		return "", err
 	}
@@ -34,11 +31,11 @@ func (t *TwelveDataMarket) GetLogoURL(ctx context.Context, symbol string) (strin
 	}()
 
 	var body logoResponse
-	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+	if err := t.decodeBody(res, &body); err != nil {
 		return "", err
 	}
 	if body.Status == "error" {
-		return "", fmt.Errorf("twelvedata: %s", body.Message)
+		return "", t.statusError(body.Code, body.Message)
 	}
 	logoURL := strings.TrimSpace(body.URL)
 	if logoURL == "" {
@@ -0,0 +1,313 @@
+package twelvedata
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 記録の上限の既定値です。RecordingConfig のゼロ値の項目にはこれらを使います。
+const (
+	DefaultRecordingMaxBytes int64 = 256 << 20
+	DefaultRecordingMaxAge         = 7 * 24 * time.Hour
+)
+
+// RecordingIDHeader は Recorder が記録したレスポンスに付ける記録IDのヘッダーです。
+// GetTimeSeries はこれをエラーとデバッグログに含め、取り込みのログから記録をたどれるようにします。
+const RecordingIDHeader = "X-Recording-Id"
+
+// redactedValue は記録する URL の APIキーを置き換える値です。
+const redactedValue = "REDACTED"
+
+// recordingQueueSize は書き込み待ちの記録の上限です。超えた記録は捨てます（呼び出しを待たせないため）。
+const recordingQueueSize = 64
+
+// recordingDrainBytes はボディを閉じる前に読む残りの上限です。
+const recordingDrainBytes = 4 << 10
+
+// RecordingConfig は外部APIのレスポンスの記録の設定です。Dir が空なら記録しません。
+type RecordingConfig struct {
+	Dir      string        // 記録を書き出すディレクトリ
+	MaxBytes int64         // 記録の合計サイズの上限。超えた分は古い順に削除する
+	MaxAge   time.Duration // 記録の保持期間。過ぎたものは削除する
+}
+
+// Enabled は記録が有効かを返します。
+func (c RecordingConfig) Enabled() bool {
+	return c.Dir != ""
+}
+
+func (c RecordingConfig) withDefaults() RecordingConfig {
+	if c.MaxBytes <= 0 {
+		c.MaxBytes = DefaultRecordingMaxBytes
+	}
+	if c.MaxAge <= 0 {
+		c.MaxAge = DefaultRecordingMaxAge
+	}
+	return c
+}
+
+// Recording は外部APIの呼び出し 1 回分の記録です。ReplayTransport で再生でき、テストのフィクスチャにもそのまま使えます。
+type Recording struct {
+	ID          string    `json:"id"`
+	RecordedAt  time.Time `json:"recorded_at"`
+	Method      string    `json:"method"`
+	URL         string    `json:"url"` // APIキーは REDACTED に置き換える
+	Status      int       `json:"status"`
+	ContentType string    `json:"content_type,omitempty"`
+	Body        string    `json:"body"`
+	// Truncated はボディを最後まで記録できなかったことを示します（呼び出し側が読み切らなかった、または MaxBytes を超えた）。
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Recorder は外部APIへのリクエストの URL とレスポンスのボディをディレクトリに記録する http.RoundTripper です。
+// 取り込み時点で上流が返した内容を後から確認・再現するために使います。
+//
+// 記録はボディを呼び出し側が読んだ分だけ写し取り、ボディを閉じた時点でキューに積んで別の goroutine で書き出します。
+// キューが一杯の場合や書き込みに失敗した場合は記録を諦めてログに残すだけで、元の呼び出しを待たせたり失敗させたりしません。
+// 書き出すたびに MaxAge を過ぎたものと、合計が MaxBytes を超える分を古い順に削除します。
+type Recorder struct {
+	next  http.RoundTripper
+	cfg   RecordingConfig
+	now   func() time.Time
+	queue chan Recording
+	done  chan struct{}
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewRecorder は next の呼び出しを cfg.Dir に記録する Recorder を作成します（ディレクトリがなければ作成します）。
+// next が nil なら http.DefaultTransport を使います。使い終わったら Close で書き込み待ちの記録を書き出してください。
+func NewRecorder(next http.RoundTripper, cfg RecordingConfig) (*Recorder, error) {
+	if !cfg.Enabled() {
+		return nil, errors.New("twelvedata: recording dir is empty")
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o750); err != nil {
+		return nil, fmt.Errorf("twelvedata: create recording dir: %w", err)
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	r := &Recorder{
+		next:  next,
+		cfg:   cfg.withDefaults(),
+		now:   time.Now,
+		queue: make(chan Recording, recordingQueueSize),
+		done:  make(chan struct{}),
+	}
+	go r.run()
+	return r, nil
+}
+
+// RoundTrip は next を呼び出し、レスポンスのボディを閉じた時点で記録します。next のエラーはそのまま返します（記録しません）。
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	id, err := newRecordingID()
+	if err != nil {
+		slog.WarnContext(req.Context(), "twelvedata recording skipped", "error", err)
+		return res, nil
+	}
+	rec := Recording{
+		ID:          id,
+		RecordedAt:  r.now().UTC(),
+		Method:      req.Method,
+		URL:         redactURL(req.URL),
+		Status:      res.StatusCode,
+		ContentType: res.Header.Get("Content-Type"),
+	}
+	res.Header.Set(RecordingIDHeader, id)
+	res.Body = &recordingBody{
+		ReadCloser: res.Body,
+		limit:      r.cfg.MaxBytes,
+		done: func(body []byte, complete bool) {
+			rec.Body = string(body)
+			rec.Truncated = !complete
+			r.enqueue(req.Context(), rec)
+		},
+	}
+	return res, nil
+}
+
+// Close は新しい記録の受け付けを止め、書き込み待ちの記録を書き出してから戻ります。
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.queue)
+	}
+	r.mu.Unlock()
+	<-r.done
+	return nil
+}
+
+// enqueue は記録を書き込みキューに積みます。キューが一杯、または Close 済みなら捨てます。
+func (r *Recorder) enqueue(ctx context.Context, rec Recording) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	select {
+	case r.queue <- rec:
+		slog.InfoContext(ctx, "twelvedata response recorded",
+			"recording_id", rec.ID, "url", rec.URL, "status", rec.Status, "bytes", len(rec.Body), "truncated", rec.Truncated)
+	default:
+		slog.WarnContext(ctx, "twelvedata recording dropped: queue full", "recording_id", rec.ID, "url", rec.URL)
+	}
+}
+
+// run はキューの記録を書き出し、上限を超えた古い記録を削除します。
+func (r *Recorder) run() {
+	defer close(r.done)
+	for rec := range r.queue {
+		if err := r.write(rec); err != nil {
+			slog.Warn("failed to write twelvedata recording", "recording_id", rec.ID, "error", err)
+			continue
+		}
+		if err := r.prune(); err != nil {
+			slog.Warn("failed to prune twelvedata recordings", "error", err)
+		}
+	}
+}
+
+// write は記録を 1 ファイルに書き出します。ファイル名は記録時刻で始まるため、名前順が古い順になります。
+// 書きかけのファイルを読まれないよう、一時ファイルに書いてから名前を変えます。
+func (r *Recorder) write(rec Recording) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	name := rec.RecordedAt.Format("20060102T150405.000000000Z") + "-" + rec.ID + ".json"
+	tmp := filepath.Join(r.cfg.Dir, "."+name+".tmp")
+	if err := os.WriteFile(tmp, data, 0o640); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(r.cfg.Dir, name)); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// prune は MaxAge を過ぎた記録と、合計サイズが MaxBytes を超える分の古い記録を削除します。
+func (r *Recorder) prune() error {
+	entries, err := os.ReadDir(r.cfg.Dir)
+	if err != nil {
+		return err
+	}
+	type file struct {
+		name string
+		size int64
+	}
+	var files []file
+	var total int64
+	cutoff := r.now().Add(-r.cfg.MaxAge)
+	var errs []error
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue // 並行して削除された
+		}
+		if info.ModTime().Before(cutoff) {
+			errs = append(errs, removeRecording(r.cfg.Dir, e.Name()))
+			continue
+		}
+		files = append(files, file{name: e.Name(), size: info.Size()})
+		total += info.Size()
+	}
+	slices.SortFunc(files, func(a, b file) int { return strings.Compare(a.name, b.name) })
+	for _, f := range files {
+		if total <= r.cfg.MaxBytes {
+			break
+		}
+		errs = append(errs, removeRecording(r.cfg.Dir, f.name))
+		total -= f.size
+	}
+	return errors.Join(errs...)
+}
+
+func removeRecording(dir, name string) error {
+	if err := os.Remove(filepath.Join(dir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// recordingBody は読まれたボディを limit バイトまで写し取り、Close 時に done へ渡す io.ReadCloser です。
+type recordingBody struct {
+	io.ReadCloser
+	buf      bytes.Buffer
+	limit    int64
+	exceeded bool
+	eof      bool
+	once     sync.Once
+	done     func(body []byte, complete bool)
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 && !b.exceeded {
+		if rest := b.limit - int64(b.buf.Len()); int64(n) > rest {
+			b.buf.Write(p[:rest])
+			b.exceeded = true
+		} else {
+			b.buf.Write(p[:n])
+		}
+	}
+	if errors.Is(err, io.EOF) {
+		b.eof = true
+	}
+	return n, err
+}
+
+// Close は元のボディを閉じ、記録を渡します。JSON のデコーダーは値の後ろ（末尾の改行など）を読まずに終えるため、
+// 閉じる前に残りを recordingDrainBytes まで読み、最後まで読めたかを判定します。
+func (b *recordingBody) Close() error {
+	if !b.eof && !b.exceeded {
+		_, _ = io.CopyN(io.Discard, b, recordingDrainBytes)
+	}
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.done(b.buf.Bytes(), b.eof && !b.exceeded) })
+	return err
+}
+
+// redactURL は APIキーのクエリパラメータを REDACTED に置き換えた URL を返します。
+func redactURL(u *url.URL) string {
+	c := *u
+	q := c.Query()
+	if q.Has("apikey") {
+		q.Set("apikey", redactedValue)
+	}
+	c.RawQuery = q.Encode()
+	return c.String()
+}
+
+// newRecordingID は記録を識別するランダムな ID を生成します。
+func newRecordingID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
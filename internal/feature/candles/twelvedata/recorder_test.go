@@ -0,0 +1,247 @@
+package twelvedata
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const recordedTimeSeries = `{"status":"ok","values":[` +
+	`{"datetime":"2024-01-05","open":"100.5","high":"102","low":"99.25","close":"101","volume":"12000"},` +
+	`{"datetime":"2024-01-04","open":"98","high":"100.75","low":"97.5","close":"100.5","volume":"9800"}]}` + "\n"
+
+// newRecordingMarket は server を呼び出し、レスポンスを dir に記録する TwelveDataMarket を返します。
+func newRecordingMarket(t *testing.T, serverURL string, cfg RecordingConfig) (*TwelveDataMarket, *Recorder) {
+	t.Helper()
+	rec, err := NewRecorder(http.DefaultTransport, cfg)
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+	t.Cleanup(func() { _ = rec.Close() })
+	return NewTwelveDataMarket(retryTestConfig(serverURL, 0), &http.Client{Transport: rec}), rec
+}
+
+// TestRecorder_RecordAndReplay は記録したレスポンスを ReplayTransport で再生すると、同じローソク足が得られることを検証します。
+func TestRecorder_RecordAndReplay(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(recordedTimeSeries))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	market, rec := newRecordingMarket(t, server.URL, RecordingConfig{Dir: dir})
+	live, err := market.GetTimeSeries(context.Background(), "6758.T", "1day", 2, time.UTC)
+	if err != nil {
+		t.Fatalf("GetTimeSeries: %v", err)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	recs, err := LoadRecordings(dir)
+	if err != nil {
+		t.Fatalf("LoadRecordings: %v", err)
+	}
+	if len(recs) != 1 {
+		t.Fatalf("recordings = %d, want 1", len(recs))
+	}
+	got := recs[0]
+	if got.Status != http.StatusOK || got.Body != recordedTimeSeries || got.Truncated || got.ContentType != "application/json" {
+		t.Errorf("recording = %+v, want the complete 200 response", got)
+	}
+
+	replay, err := NewReplayTransport(recs)
+	if err != nil {
+		t.Fatalf("NewReplayTransport: %v", err)
+	}
+	// 記録時とは別のホスト・APIキーでも、パスとクエリが同じなら再生できる
+	offline := NewTwelveDataMarket(retryTestConfig("http://replay.invalid", 0), &http.Client{Transport: replay})
+	offline.cfg.TwelveDataAPIKey = "another-key"
+	replayed, err := offline.GetTimeSeries(context.Background(), "6758.T", "1day", 2, time.UTC)
+	if err != nil {
+		t.Fatalf("replayed GetTimeSeries: %v", err)
+	}
+	if !reflect.DeepEqual(replayed, live) {
+		t.Errorf("replayed candles = %+v, want %+v", replayed, live)
+	}
+
+	if _, err := offline.GetTimeSeries(context.Background(), "7203.T", "1day", 2, time.UTC); !errors.Is(err, ErrNoRecording) {
+		t.Errorf("unrecorded request: err = %v, want ErrNoRecording", err)
+	}
+	if misses := replay.Misses(); len(misses) != 1 || !strings.Contains(misses[0], "symbol=7203.T") {
+		t.Errorf("Misses = %v, want the 7203.T request", misses)
+	}
+}
+
+// TestRecorder_RedactsAPIKey は記録した URL に APIキーが残らないことを検証します。
+func TestRecorder_RedactsAPIKey(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(recordedTimeSeries))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	market, rec := newRecordingMarket(t, server.URL, RecordingConfig{Dir: dir})
+	if _, err := market.GetTimeSeries(context.Background(), "AAPL", "1day", 2, time.UTC); err != nil {
+		t.Fatalf("GetTimeSeries: %v", err)
+	}
+	_ = rec.Close()
+
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(paths) != 1 {
+		t.Fatalf("recording files = %v, want 1", paths)
+	}
+	raw, err := os.ReadFile(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), "test-key") {
+		t.Errorf("recording contains the API key: %s", raw)
+	}
+	recs, _ := LoadRecordings(dir)
+	u, err := url.Parse(recs[0].URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := u.Query().Get("apikey"); got != redactedValue {
+		t.Errorf("apikey = %q, want %q", got, redactedValue)
+	}
+	if got := u.Query().Get("symbol"); got != "AAPL" {
+		t.Errorf("symbol = %q, want AAPL", got)
+	}
+}
+
+// TestRecorder_ErrorIncludesRecordingID は取り込みの失敗のエラーに記録IDが含まれることを検証します。
+func TestRecorder_ErrorIncludesRecordingID(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"error","code":400,"message":"**symbol** not found"}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	market, rec := newRecordingMarket(t, server.URL, RecordingConfig{Dir: dir})
+	_, err := market.GetTimeSeries(context.Background(), "NOPE", "1day", 2, time.UTC)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	_ = rec.Close()
+
+	recs, _ := LoadRecordings(dir)
+	if len(recs) != 1 {
+		t.Fatalf("recordings = %d, want 1", len(recs))
+	}
+	if !strings.Contains(err.Error(), "recording "+recs[0].ID) {
+		t.Errorf("err = %v, want it to mention recording %s", err, recs[0].ID)
+	}
+}
+
+// TestRecorder_WriteFailureDoesNotFailRequest は記録の書き込みに失敗しても元の呼び出しが成功することを検証します。
+func TestRecorder_WriteFailureDoesNotFailRequest(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(recordedTimeSeries))
+	}))
+	defer server.Close()
+
+	dir := filepath.Join(t.TempDir(), "recordings")
+	market, rec := newRecordingMarket(t, server.URL, RecordingConfig{Dir: dir})
+	// 記録先を消して書き込みを失敗させる
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	got, err := market.GetTimeSeries(context.Background(), "AAPL", "1day", 2, time.UTC)
+	if err != nil {
+		t.Fatalf("GetTimeSeries: %v", err)
+	}
+	if len(got) != 2 {
+		t.Errorf("candles = %d, want 2", len(got))
+	}
+	if err := rec.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+}
+
+// TestRecorder_Prune は保持期間を過ぎた記録と、合計サイズの上限を超える古い記録が削除されることを検証します。
+func TestRecorder_Prune(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	now := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	r := &Recorder{cfg: RecordingConfig{Dir: dir, MaxBytes: 250, MaxAge: 24 * time.Hour}, now: func() time.Time { return now }}
+
+	write := func(name string, size int, modTime time.Time) {
+		t.Helper()
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(strings.Repeat("x", size)), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(p, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("20240108T000000.000000000Z-expired.json", 10, now.Add(-48*time.Hour))
+	write("20240109T010000.000000000Z-oldest.json", 100, now.Add(-23*time.Hour))
+	write("20240109T020000.000000000Z-middle.json", 100, now.Add(-22*time.Hour))
+	write("20240109T030000.000000000Z-newest.json", 100, now.Add(-21*time.Hour))
+	write("notes.txt", 1000, now.Add(-72*time.Hour)) // 記録以外のファイルは対象外
+
+	if err := r.prune(); err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	want := []string{
+		"20240109T020000.000000000Z-middle.json",
+		"20240109T030000.000000000Z-newest.json",
+		"notes.txt",
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("remaining files = %v, want %v", names, want)
+	}
+}
+
+// TestRecordingBody_Truncated はボディが上限を超えた場合に記録を打ち切り、Truncated を立てることを検証します。
+func TestRecordingBody_Truncated(t *testing.T) {
+	t.Parallel()
+
+	var got string
+	var complete bool
+	body := &recordingBody{
+		ReadCloser: io.NopCloser(strings.NewReader("0123456789")),
+		limit:      4,
+		done:       func(b []byte, c bool) { got, complete = string(b), c },
+	}
+	buf := make([]byte, 16)
+	n, _ := body.Read(buf)
+	if n != 10 {
+		t.Fatalf("Read = %d, want the whole body for the caller", n)
+	}
+	_ = body.Close()
+	if got != "0123" || complete {
+		t.Errorf("recorded = (%q, complete %v), want (\"0123\", false)", got, complete)
+	}
+}
@@ -0,0 +1,111 @@
+package twelvedata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrNoRecording はリクエストに一致する記録がないことを示します（ReplayTransport）。
+var ErrNoRecording = errors.New("twelvedata: no recording for request")
+
+// LoadRecordings は dir 直下の記録（Recorder が書き出した *.json）を古い順に読み込みます。
+// テストのフィクスチャとして testdata に置いた記録の読み込みにも使えます。
+func LoadRecordings(dir string) ([]Recording, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	recs := make([]Recording, 0, len(paths))
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		var rec Recording
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, fmt.Errorf("parse recording %s: %w", filepath.Base(p), err)
+		}
+		recs = append(recs, rec)
+	}
+	slices.SortStableFunc(recs, func(a, b Recording) int { return a.RecordedAt.Compare(b.RecordedAt) })
+	return recs, nil
+}
+
+// ReplayTransport は記録したレスポンスを返す http.RoundTripper です。外部APIを呼ばずに取り込み時の応答を再現します。
+//
+// リクエストはメソッドとパス・クエリ（APIキーは記録と同じく置き換えて比較）で記録と照合し、ホストは無視します
+// （BaseURL をテストサーバー等に差し替えても再生できるように）。同じリクエストの記録が複数ある場合は最も新しいものを返します。
+// 一致する記録がなければ ErrNoRecording を返します。
+type ReplayTransport struct {
+	mu     sync.RWMutex
+	byKey  map[string]Recording
+	misses []string
+}
+
+// NewReplayTransport は recs（古い順）を再生する ReplayTransport を作成します。
+func NewReplayTransport(recs []Recording) (*ReplayTransport, error) {
+	t := &ReplayTransport{byKey: make(map[string]Recording, len(recs))}
+	for _, rec := range recs {
+		u, err := url.Parse(rec.URL)
+		if err != nil {
+			return nil, fmt.Errorf("recording %s: parse url: %w", rec.ID, err)
+		}
+		t.byKey[replayKey(rec.Method, u)] = rec
+	}
+	return t, nil
+}
+
+// RoundTrip は req に一致する記録をレスポンスとして返します。ボディを最後まで記録できなかった記録もそのまま返します。
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := replayKey(req.Method, req.URL)
+	t.mu.RLock()
+	rec, ok := t.byKey[key]
+	t.mu.RUnlock()
+	if !ok {
+		t.mu.Lock()
+		t.misses = append(t.misses, key)
+		t.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrNoRecording, key)
+	}
+	header := make(http.Header)
+	if rec.ContentType != "" {
+		header.Set("Content-Type", rec.ContentType)
+	}
+	header.Set(RecordingIDHeader, rec.ID)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.Status, http.StatusText(rec.Status)),
+		StatusCode:    rec.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(rec.Body)),
+		ContentLength: int64(len(rec.Body)),
+		Request:       req,
+	}, nil
+}
+
+// Misses は一致する記録がなかったリクエスト（"<METHOD> <パス>?<クエリ>"）を返します。フィクスチャの不足の確認に使います。
+func (t *ReplayTransport) Misses() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return slices.Clone(t.misses)
+}
+
+// replayKey は記録とリクエストを照合するキーです。クエリは APIキーを置き換えたうえで並びを正規化します。
+func replayKey(method string, u *url.URL) string {
+	q := u.Query()
+	if q.Has("apikey") {
+		q.Set("apikey", redactedValue)
+	}
+	return method + " " + u.Path + "?" + q.Encode()
+}
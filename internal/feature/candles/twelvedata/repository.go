@@ -5,25 +5,42 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"maps"
 	"math"
 	"math/rand/v2"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
 )
 
+// ErrResponseTooLarge は外部APIのレスポンスボディが Config.MaxResponseBytes を超えたことを示します。
+var ErrResponseTooLarge = apperr.New(apperr.KindUpstream, "upstream_response_too_large", "upstream response too large")
+
 // TwelveDataMarket はTwelve Data外部APIから株価データを取得するMarketRepository実装です。
 type TwelveDataMarket struct {
 	cfg    Config
 	client *http.Client
+	slots  NextSlotter // 利用枠の枯渇時に Retry-After がない場合の待機時間の目安（nil なら次の分の境界まで）
+}
+
+// NextSlotter は次の呼び出しを待機なしで実行できるまでの時間を返します（clientratelimit.RateLimiter が実装）。
+type NextSlotter interface {
+	NextSlot() time.Duration
 }
 
-// TwelveDataMarketがMarketRepositoryを実装していることをコンパイル時に検証します。
-var _ candles.MarketRepository = (*TwelveDataMarket)(nil)
+// TwelveDataMarketがMarketRepository・BatchMarketRepository・OutputSizeCapperを実装していることをコンパイル時に検証します。
+var (
+	_ candles.MarketRepository      = (*TwelveDataMarket)(nil)
+	_ candles.BatchMarketRepository = (*TwelveDataMarket)(nil)
+	_ candles.OutputSizeCapper      = (*TwelveDataMarket)(nil)
+)
 
 // NewTwelveDataMarket は指定された設定とHTTPクライアントでTwelveDataMarketの新しいインスタンスを生成します。
 func NewTwelveDataMarket(cfg Config, client *http.Client) *TwelveDataMarket {
@@ -33,21 +50,163 @@ func NewTwelveDataMarket(cfg Config, client *http.Client) *TwelveDataMarket {
 // GetTimeSeries はTwelve Data APIから時系列株価データを取得し、
 // domain.Candleのスライスとして返します。
 // loc は外部 API レスポンスの datetime（取引所ローカル時刻）を解釈するロケーションです。
+// プラン上取得できない interval / outputsize の要求は、API を呼び出さずに
+// candles.ErrUnsupportedRequest を返します（失敗が確定した呼び出しでクレジットを消費しないため）。
 func (t *TwelveDataMarket) GetTimeSeries(ctx context.Context, symbol, interval string, outputsize int, loc *time.Location) ([]candles.Candle, error) {
 	if loc == nil {
 		return nil, fmt.Errorf("twelvedata: loc must not be nil")
 	}
+	if err := t.cfg.Capabilities.Validate(interval, outputsize); err != nil {
+		return nil, err
+	}
+
+	// 呼び出し単位の期限（ctx の期限と RequestTimeout の早い方）。リトライの待機も含めて適用する
+	if t.cfg.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.cfg.RequestTimeout)
+		defer cancel()
+	}
+	start := time.Now()
+	result, err := t.getTimeSeries(ctx, symbol, interval, outputsize, loc)
+	elapsed := time.Since(start)
+	candles.TraceFromContext(ctx).Add(candles.TraceUpstream, elapsed)
+	if err != nil {
+		// 上流の所要時間を呼び出し側のエラーログに残す（期限切れの原因調査用）
+		return nil, fmt.Errorf("twelvedata time_series %s %s (upstream %dms): %w", symbol, interval, elapsed.Milliseconds(), err)
+	}
+	slog.DebugContext(ctx, "twelvedata time_series",
+		"symbol", symbol, "interval", interval, "points", len(result), "upstream_ms", elapsed.Milliseconds())
+	return result, nil
+}
+
+// GetTimeSeriesBatch は symbols の時系列を 1 回の time_series 呼び出し（symbol をカンマ区切り）でまとめて取得します。
+// 1 銘柄なら GetTimeSeries と同じです。クレジットは銘柄ごとに消費されるため、減るのは HTTP の往復の回数です。
+// 銘柄ごとの失敗（存在しない銘柄など）は成功した銘柄の結果とともに candles.BatchSymbolErrors で返します。
+// 全体の失敗（利用枠の枯渇・認証エラーなど）と、レスポンスの形が不正な場合はその他のエラーを返します。
+func (t *TwelveDataMarket) GetTimeSeriesBatch(ctx context.Context, symbols []string, interval string, outputsize int, locs map[string]*time.Location) (map[string][]candles.Candle, error) {
+	if len(symbols) == 1 {
+		cs, err := t.GetTimeSeries(ctx, symbols[0], interval, outputsize, locs[symbols[0]])
+		if err != nil {
+			return nil, err
+		}
+		return map[string][]candles.Candle{symbols[0]: cs}, nil
+	}
+	for _, symbol := range symbols {
+		if locs[symbol] == nil {
+			return nil, fmt.Errorf("twelvedata: loc for %s must not be nil", symbol)
+		}
+	}
+	if err := t.cfg.Capabilities.Validate(interval, outputsize); err != nil {
+		return nil, err
+	}
+
+	if t.cfg.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.cfg.RequestTimeout)
+		defer cancel()
+	}
+	start := time.Now()
+	result, err := t.getTimeSeriesBatch(ctx, symbols, interval, outputsize, locs)
+	elapsed := time.Since(start)
+	candles.TraceFromContext(ctx).Add(candles.TraceUpstream, elapsed)
+	var symErrs candles.BatchSymbolErrors
+	if err != nil && !errors.As(err, &symErrs) {
+		return nil, fmt.Errorf("twelvedata time_series batch of %d %s (upstream %dms): %w", len(symbols), interval, elapsed.Milliseconds(), err)
+	}
+	slog.DebugContext(ctx, "twelvedata time_series batch",
+		"symbols", len(symbols), "failed", len(symErrs), "interval", interval, "upstream_ms", elapsed.Milliseconds())
+	return result, err
+}
+
+// getTimeSeriesBatch は複数銘柄の time_series を呼び出してレスポンスを銘柄ごとに Candle に変換します。
+func (t *TwelveDataMarket) getTimeSeriesBatch(ctx context.Context, symbols []string, interval string, outputsize int, locs map[string]*time.Location) (map[string][]candles.Candle, error) {
+	q := url.Values{}
+	q.Set("symbol", strings.Join(symbols, ","))
+	q.Set("interval", interval)
+	q.Set("outputsize", strconv.Itoa(outputsize))
+
+	res, err := t.doRequestWithRetry(ctx, "time_series", q)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			slog.Warn("failed to close response body", "error", err)
+		}
+	}()
+
+	result, err := t.parseTimeSeriesBatch(res, symbols, locs)
+	var symErrs candles.BatchSymbolErrors
+	if err != nil && !errors.As(err, &symErrs) {
+		return nil, withRecordingID(res, err)
+	}
+	return result, err
+}
+
+// parseTimeSeriesBatch は複数銘柄の time_series のレスポンス（銘柄コードをキーとするオブジェクト）を変換します。
+// 全体の失敗はトップレベルの status が error のオブジェクトで返るため、その場合は statusError を返します。
+func (t *TwelveDataMarket) parseTimeSeriesBatch(res *http.Response, symbols []string, locs map[string]*time.Location) (map[string][]candles.Candle, error) {
+	var whole json.RawMessage
+	if err := t.decodeBody(res, &whole); err != nil {
+		return nil, err
+	}
+	var top TimeSeriesResponse
+	if err := json.Unmarshal(whole, &top); err == nil && top.Status == "error" {
+		return nil, t.statusError(top.Code, top.Message)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(whole, &raw); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]candles.Candle, len(symbols))
+	symErrs := candles.BatchSymbolErrors{}
+	for _, symbol := range symbols {
+		part, ok := raw[symbol]
+		if !ok {
+			symErrs[symbol] = fmt.Errorf("symbol %s missing from batch response", symbol)
+			continue
+		}
+		var body TimeSeriesResponse
+		if err := json.Unmarshal(part, &body); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", symbol, err)
+		}
+		cs, err := t.toCandles(body, locs[symbol])
+		if err != nil {
+			symErrs[symbol] = err
+			continue
+		}
+		result[symbol] = cs
+	}
+	if len(symErrs) > 0 {
+		return result, symErrs
+	}
+	return result, nil
+}
+
+// WithRequestTimeout は RequestTimeout のみを差し替えたクライアントを返します（http.Client は共有）。
+// ingest（長め）とユーザー向けの取得（短め）で同じ接続プールを使いつつ期限を使い分けるために使います。
+func (t *TwelveDataMarket) WithRequestTimeout(d time.Duration) *TwelveDataMarket {
+	cfg := t.cfg
+	cfg.RequestTimeout = d
+	return &TwelveDataMarket{cfg: cfg, client: t.client, slots: t.slots}
+}
+
+// WithNextSlot は利用枠の枯渇時に待機時間の目安として s を使うクライアントを返します（http.Client は共有）。
+// 呼び出し元のレートリミッタを渡すと、上流が Retry-After を返さない場合でも次に呼び出せる時刻を伝えられます。
+func (t *TwelveDataMarket) WithNextSlot(s NextSlotter) *TwelveDataMarket {
+	return &TwelveDataMarket{cfg: t.cfg, client: t.client, slots: s}
+}
+
+// getTimeSeries は time_series を呼び出してレスポンスを Candle に変換します。
+func (t *TwelveDataMarket) getTimeSeries(ctx context.Context, symbol, interval string, outputsize int, loc *time.Location) ([]candles.Candle, error) {
 	q := url.Values{}
 	// クエリパラメータを追加
 	q.Set("symbol", symbol)
 	q.Set("interval", interval)
 	q.Set("outputsize", strconv.Itoa(outputsize))
-	q.Set("apikey", t.cfg.TwelveDataAPIKey)
 
-	// URLを生成
-	u := fmt.Sprintf("%s/time_series?%s", t.cfg.BaseURL, q.Encode())
-
-	res, err := t.doRequestWithRetry(ctx, http.MethodGet, u)
+	res, err := t.doRequestWithRetry(ctx, "time_series", q)
 	if err != nil {
 		return nil, err
 	}
@@ -57,13 +216,27 @@ func (t *TwelveDataMarket) GetTimeSeries(ctx context.Context, symbol, interval s
 		}
 	}()
 
-	// JSONレスポンスをDTOにデコード
+	result, err := t.parseTimeSeries(res, loc)
+	if err != nil {
+		return nil, withRecordingID(res, err)
+	}
+	return result, nil
+}
+
+// parseTimeSeries は time_series のレスポンスを Candle に変換します。
+func (t *TwelveDataMarket) parseTimeSeries(res *http.Response, loc *time.Location) ([]candles.Candle, error) {
+	// JSONレスポンスをDTOにデコード（上限を超えるボディは読み切らずに打ち切る）
 	var body TimeSeriesResponse
-	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+	if err := t.decodeBody(res, &body); err != nil {
 		return nil, err
 	}
+	return t.toCandles(body, loc)
+}
+
+// toCandles は time_series の 1 銘柄分のレスポンスを Candle に変換します（status が error ならそのエラー）。
+func (t *TwelveDataMarket) toCandles(body TimeSeriesResponse, loc *time.Location) ([]candles.Candle, error) {
 	if body.Status == "error" {
-		return nil, fmt.Errorf("twelvedata: %s", body.Message)
+		return nil, t.statusError(body.Code, body.Message)
 	}
 
 	result := make([]candles.Candle, 0, len(body.Values))
@@ -111,16 +284,88 @@ func (t *TwelveDataMarket) GetTimeSeries(ctx context.Context, symbol, interval s
 			Low:    l,
 			Close:  c,
 			Volume: vol64,
+			Source: candles.SourceTwelveData,
 		})
 	}
 	return result, nil
 }
 
-// doRequestWithRetry は指定された HTTP リクエストを実行し、
+// decodeBody はレスポンスボディを MaxResponseBytes までに制限して JSON デコードします。
+// 上限を超えた場合はデコード結果に関わらず ErrResponseTooLarge を返します。
+func (t *TwelveDataMarket) decodeBody(res *http.Response, dst any) error {
+	limit := t.cfg.MaxResponseBytes
+	if limit <= 0 {
+		limit = DefaultMaxResponseBytes
+	}
+	body := &cappedReader{r: res.Body, remaining: limit}
+	err := json.NewDecoder(body).Decode(dst)
+	if body.exceeded {
+		return fmt.Errorf("%w: limit %d bytes", ErrResponseTooLarge, limit)
+	}
+	return err
+}
+
+// cappedReader は remaining バイトまでを読み、それを超えるデータがあれば exceeded を立てて
+// ErrResponseTooLarge を返す io.Reader です（io.LimitReader は超過を区別できないため）。
+type cappedReader struct {
+	r         io.Reader
+	remaining int64
+	exceeded  bool
+}
+
+func (c *cappedReader) Read(p []byte) (int, error) {
+	if c.remaining <= 0 {
+		// 上限ちょうどで終わるボディを誤検知しないよう、1 バイト先読みして超過を判定する
+		var probe [1]byte
+		n, err := c.r.Read(probe[:])
+		if n > 0 {
+			c.exceeded = true
+			return 0, ErrResponseTooLarge
+		}
+		return 0, err
+	}
+	if int64(len(p)) > c.remaining {
+		p = p[:c.remaining]
+	}
+	n, err := c.r.Read(p)
+	c.remaining -= int64(n)
+	return n, err
+}
+
+// MaxOutputSize は契約プランの outputsize 上限を返します（0 なら無制限）。
+func (t *TwelveDataMarket) MaxOutputSize() int {
+	return t.cfg.Capabilities.MaxOutputSize
+}
+
+// apiKeyHeader は APIKeyInHeader のときに APIキーを送るヘッダーです（値は "apikey <key>"）。
+const apiKeyHeader = "Authorization"
+
+// buildRequest は endpoint（例: "time_series"）へのクエリ q の GET リクエストを組み立てます。
+// 各エンドポイントの呼び出しはすべてこれを通り、APIキー（クエリまたはヘッダー）とプロキシの認証ヘッダーを一様に付与します。
+// q は変更しません（リトライのたびに同じクエリを組み立て直すため）。
+func (t *TwelveDataMarket) buildRequest(ctx context.Context, endpoint string, q url.Values) (*http.Request, error) {
+	if !t.cfg.APIKeyInHeader {
+		q = maps.Clone(q)
+		q.Set("apikey", t.cfg.TwelveDataAPIKey)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/%s?%s", t.cfg.BaseURL, endpoint, q.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+	if t.cfg.APIKeyInHeader {
+		req.Header.Set(apiKeyHeader, "apikey "+t.cfg.TwelveDataAPIKey)
+	}
+	if t.cfg.ProxyAuthHeader != "" {
+		req.Header.Set(t.cfg.ProxyAuthHeader, t.cfg.ProxyAuthValue)
+	}
+	return req, nil
+}
+
+// doRequestWithRetry は endpoint へのリクエスト（buildRequest で組み立てる）を実行し、
 // ネットワークエラー・5xx・429 に対して指数バックオフ + ジッターでリトライします。
 // 4xx（429 を除く）は即エラーを返し、ctx キャンセル時はリトライを中断します。
 // 外側のレートリミッタとは独立に動作するため、リトライは外側のレート消費を増やしません。
-func (t *TwelveDataMarket) doRequestWithRetry(ctx context.Context, method, urlStr string) (*http.Response, error) {
+func (t *TwelveDataMarket) doRequestWithRetry(ctx context.Context, endpoint string, q url.Values) (*http.Response, error) {
 	maxAttempts := t.cfg.MaxRetries + 1
 	if maxAttempts < 1 {
 		maxAttempts = 1
@@ -133,7 +378,7 @@ func (t *TwelveDataMarket) doRequestWithRetry(ctx context.Context, method, urlSt
 			return nil, err
 		}
 
-		req, err := http.NewRequestWithContext(ctx, method, urlStr, nil)
+		req, err := t.buildRequest(ctx, endpoint, q)
 		if err != nil {
 			return nil, err
 		}
@@ -161,7 +406,7 @@ func (t *TwelveDataMarket) doRequestWithRetry(ctx context.Context, method, urlSt
 
 		// リトライ対象外のエラーは即返す
 		if !isRetryableStatus(res.StatusCode) {
-			statusErr := fmt.Errorf("twelvedata http %d", res.StatusCode)
+			statusErr := withRecordingID(res, fmt.Errorf("twelvedata http %d", res.StatusCode))
 			_ = res.Body.Close()
 			return nil, statusErr
 		}
@@ -171,6 +416,17 @@ func (t *TwelveDataMarket) doRequestWithRetry(ctx context.Context, method, urlSt
 		lastErr = fmt.Errorf("twelvedata http %d", res.StatusCode)
 		_ = res.Body.Close()
 
+		if res.StatusCode == http.StatusTooManyRequests {
+			// 利用枠の枯渇は即時に再試行しても失敗するため、Retry-After（なければ次に呼び出せる時刻）まで待つ
+			if retryAfter <= 0 {
+				retryAfter = t.throttleDelay()
+			}
+			lastErr = &candles.ThrottledError{Wait: retryAfter, Reason: lastErr.Error()}
+			// 期限内に枠が回復しない場合は待たずに返し、呼び出し側に待機時間を伝える
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < computeRetryDelay(attempt, retryAfter, t.cfg) {
+				return nil, lastErr
+			}
+		}
 		if attempt == maxAttempts-1 {
 			break
 		}
@@ -185,6 +441,15 @@ func (t *TwelveDataMarket) doRequestWithRetry(ctx context.Context, method, urlSt
 	return nil, lastErr
 }
 
+// withRecordingID は Recorder が記録したレスポンスなら err に記録IDを添えます。
+// 取り込みの失敗のログから、上流が実際に返した内容（記録）をたどれるようにするためです。
+func withRecordingID(res *http.Response, err error) error {
+	if id := res.Header.Get(RecordingIDHeader); id != "" {
+		return fmt.Errorf("%w (recording %s)", err, id)
+	}
+	return err
+}
+
 // sleepBeforeRetry は次のリトライまで待機します。retryAfter > 0 ならそれを優先し、
 // それ以外は attempt（0 起算）に応じた指数バックオフ + ジッターで待機します。
 // ctx キャンセル時は false を返し即時に中断します。
@@ -230,6 +495,30 @@ func isRetryableStatus(status int) bool {
 	return status >= 500 && status < 600
 }
 
+// creditsExhaustedMessage は利用枠の枯渇時に Twelve Data が status: error のボディで返すメッセージの一部です。
+const creditsExhaustedMessage = "run out of api credits"
+
+// statusError は status: error のレスポンスボディをエラーに変換します。
+// 利用枠の枯渇（code 429 または API クレジット切れのメッセージ）は candles.ThrottledError にします。
+func (t *TwelveDataMarket) statusError(code int, message string) error {
+	if code == http.StatusTooManyRequests || strings.Contains(strings.ToLower(message), creditsExhaustedMessage) {
+		return &candles.ThrottledError{Wait: t.throttleDelay(), Reason: message}
+	}
+	return fmt.Errorf("twelvedata: %s", message)
+}
+
+// throttleDelay は上流が待機時間を返さなかった場合の目安です。
+// 呼び出し元のレートリミッタの次の枠を優先し、なければ次の分の境界（Twelve Data の分あたりの枠の更新時刻）までとします。
+func (t *TwelveDataMarket) throttleDelay() time.Duration {
+	if t.slots != nil {
+		if d := t.slots.NextSlot(); d > 0 {
+			return d
+		}
+	}
+	now := time.Now()
+	return now.Truncate(time.Minute).Add(time.Minute).Sub(now)
+}
+
 // maxRetryAfterSecs は Retry-After で受け入れる秒数の上限（int64 オーバーフロー回避と現実的な上限のため 1 時間）。
 const maxRetryAfterSecs = 3600
 
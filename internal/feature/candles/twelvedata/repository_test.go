@@ -2,12 +2,16 @@ package twelvedata
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/candles"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
 )
 
 // retryTestConfig はリトライ系テストで使用する高速バックオフ設定の Config を返します。
@@ -473,6 +477,98 @@ func TestTwelveDataMarket_GetTimeSeries_ContextCancellation(t *testing.T) {
 	}
 }
 
+// TestTwelveDataMarket_GetTimeSeries_RequestTimeout は遅い上流に対し、ctx に期限がなくても
+// RequestTimeout で打ち切られることを検証します。
+func TestTwelveDataMarket_GetTimeSeries_RequestTimeout(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	cfg := retryTestConfig(server.URL, 0)
+	market := NewTwelveDataMarket(cfg, server.Client()).WithRequestTimeout(50 * time.Millisecond)
+
+	parent := context.Background()
+	start := time.Now()
+	_, err := market.GetTimeSeries(parent, "AAPL", "1day", 100, time.UTC)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("GetTimeSeries took %v, want about 50ms", elapsed)
+	}
+	if !strings.Contains(err.Error(), "upstream") {
+		t.Errorf("err = %q, want elapsed upstream time in message", err)
+	}
+	// 呼び出し単位の期限は呼び出し元の ctx を中断しない（ingest では銘柄の失敗として扱われる）
+	if parent.Err() != nil {
+		t.Errorf("parent ctx must stay alive, got %v", parent.Err())
+	}
+	// 派生元のクライアントには期限が設定されない
+	if NewTwelveDataMarket(cfg, server.Client()).cfg.RequestTimeout != 0 {
+		t.Error("WithRequestTimeout must not mutate the original config")
+	}
+}
+
+// TestTwelveDataMarket_GetTimeSeries_ResponseSizeCap は上限を超えるレスポンスボディで
+// ErrResponseTooLarge を返し、上限ちょうどのボディは受け付けることを検証します。
+func TestTwelveDataMarket_GetTimeSeries_ResponseSizeCap(t *testing.T) {
+	t.Parallel()
+
+	value := `{"datetime":"2024-01-05","open":"1","high":"1","low":"1","close":"1","volume":"1"}`
+	small := `{"status":"ok","values":[` + value + `]}`
+	large := `{"status":"ok","values":[` + strings.Repeat(value+",", 1000) + value + `]}`
+
+	tests := []struct {
+		name    string
+		body    string
+		limit   int64
+		wantErr bool
+	}{
+		{name: "上限超過", body: large, limit: 4096, wantErr: true},
+		{name: "上限ちょうど", body: small, limit: int64(len(small))},
+		{name: "デフォルト上限", body: large, limit: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			cfg := retryTestConfig(server.URL, 0)
+			cfg.MaxResponseBytes = tt.limit
+			market := NewTwelveDataMarket(cfg, server.Client())
+
+			got, err := market.GetTimeSeries(context.Background(), "AAPL", "1day", 100, time.UTC)
+			if tt.wantErr {
+				if !errors.Is(err, ErrResponseTooLarge) {
+					t.Fatalf("err = %v, want ErrResponseTooLarge", err)
+				}
+				if apperr.KindOf(err) != apperr.KindUpstream {
+					t.Errorf("kind = %v, want KindUpstream", apperr.KindOf(err))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) == 0 {
+				t.Error("expected candles, got none")
+			}
+		})
+	}
+}
+
 // TestNewConfig はデフォルトのタイムアウト値とリトライ設定のデフォルト値が正しく設定されることを検証します。
 func TestNewConfig(t *testing.T) {
 	t.Parallel()
@@ -494,6 +590,12 @@ func TestNewConfig(t *testing.T) {
 	if cfg.RetryBaseBackoff != 500*time.Millisecond {
 		t.Errorf("expected RetryBaseBackoff 500ms, got %v", cfg.RetryBaseBackoff)
 	}
+	if cfg.MaxResponseBytes != DefaultMaxResponseBytes {
+		t.Errorf("expected MaxResponseBytes %d, got %d", DefaultMaxResponseBytes, cfg.MaxResponseBytes)
+	}
+	if cfg.RequestTimeout != 0 {
+		t.Errorf("expected no RequestTimeout by default, got %v", cfg.RequestTimeout)
+	}
 }
 
 // successTimeSeriesBody は GetTimeSeries 成功レスポンスの最小 JSON です。
@@ -633,6 +735,153 @@ func TestTwelveDataMarket_GetTimeSeries_Retry_RespectsRetryAfter(t *testing.T) {
 	}
 }
 
+// stubNextSlot は固定の待機時間を返す NextSlotter です。
+type stubNextSlot time.Duration
+
+func (s stubNextSlot) NextSlot() time.Duration { return time.Duration(s) }
+
+// throttleWait は err が candles.ErrUpstreamThrottled であることを確認し、待機時間を返します。
+func throttleWait(t *testing.T, err error) time.Duration {
+	t.Helper()
+	if !errors.Is(err, candles.ErrUpstreamThrottled) {
+		t.Fatalf("err = %v, want ErrUpstreamThrottled", err)
+	}
+	var te *candles.ThrottledError
+	if !errors.As(err, &te) {
+		t.Fatalf("err = %T, want *candles.ThrottledError in chain", err)
+	}
+	return te.RetryAfter()
+}
+
+// TestTwelveDataMarket_GetTimeSeries_Throttled は 429 が続いた場合に ThrottledError を返し、
+// 待機時間は Retry-After ヘッダ、なければレートリミッタの次の枠（さらになければ次の分の境界）から求めることを検証します。
+func TestTwelveDataMarket_GetTimeSeries_Throttled(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		retryAfter string
+		slots      NextSlotter
+		wantMin    time.Duration
+		wantMax    time.Duration
+	}{
+		{name: "Retry-After ヘッダあり", retryAfter: "7", slots: stubNextSlot(42 * time.Second), wantMin: 7 * time.Second, wantMax: 7 * time.Second},
+		{name: "ヘッダなしはレートリミッタの次の枠", slots: stubNextSlot(42 * time.Second), wantMin: 42 * time.Second, wantMax: 42 * time.Second},
+		{name: "ヘッダなし・枠に空きありは次の分の境界", slots: stubNextSlot(0), wantMin: time.Nanosecond, wantMax: time.Minute},
+		{name: "ヘッダなし・レートリミッタなし", wantMin: time.Nanosecond, wantMax: time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(http.StatusTooManyRequests)
+			}))
+			defer server.Close()
+
+			market := NewTwelveDataMarket(retryTestConfig(server.URL, 0), server.Client())
+			if tt.slots != nil {
+				market = market.WithNextSlot(tt.slots)
+			}
+
+			_, err := market.GetTimeSeries(context.Background(), "AAPL", "1day", 100, time.UTC)
+			wait := throttleWait(t, err)
+			if wait < tt.wantMin || wait > tt.wantMax {
+				t.Errorf("RetryAfter = %v, want [%v, %v]", wait, tt.wantMin, tt.wantMax)
+			}
+			if got := calls.Load(); got != 1 {
+				t.Errorf("expected 1 HTTP call, got %d", got)
+			}
+		})
+	}
+}
+
+// TestTwelveDataMarket_GetTimeSeries_ThrottledBeyondDeadline は枠の回復が呼び出しの期限に間に合わない場合、
+// 待たずに（リトライせずに）ThrottledError を返すことを検証します。
+func TestTwelveDataMarket_GetTimeSeries_ThrottledBeyondDeadline(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	cfg := retryTestConfig(server.URL, 3)
+	cfg.RetryMaxBackoff = time.Minute
+	cfg.RequestTimeout = 2 * time.Second
+	market := NewTwelveDataMarket(cfg, server.Client())
+
+	start := time.Now()
+	_, err := market.GetTimeSeries(context.Background(), "AAPL", "1day", 100, time.UTC)
+	if wait := throttleWait(t, err); wait != 30*time.Second {
+		t.Errorf("RetryAfter = %v, want 30s", wait)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("should not wait for the quota beyond the deadline, took %v", elapsed)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected 1 HTTP call (no retry), got %d", got)
+	}
+}
+
+// TestTwelveDataMarket_GetTimeSeries_CreditsExhaustedBody は HTTP 200 の status: error ボディで
+// 利用枠の枯渇が返された場合も ThrottledError とし、その他の status: error は従来どおりのエラーとすることを検証します。
+func TestTwelveDataMarket_GetTimeSeries_CreditsExhaustedBody(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		body          string
+		wantThrottled bool
+	}{
+		{
+			name:          "code 429",
+			body:          `{"code":429,"message":"You have run out of API credits for the current minute. 9 API credits were used, with the current limit being 8.","status":"error"}`,
+			wantThrottled: true,
+		},
+		{
+			name:          "メッセージのみ",
+			body:          `{"message":"You have run out of API credits for the day.","status":"error"}`,
+			wantThrottled: true,
+		},
+		{
+			name: "その他のエラー",
+			body: `{"code":404,"message":"**symbol** not found: ZZZZ","status":"error"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			market := NewTwelveDataMarket(retryTestConfig(server.URL, 0), server.Client()).WithNextSlot(stubNextSlot(5 * time.Second))
+			_, err := market.GetTimeSeries(context.Background(), "AAPL", "1day", 100, time.UTC)
+			if !tt.wantThrottled {
+				if err == nil || errors.Is(err, candles.ErrUpstreamThrottled) {
+					t.Fatalf("err = %v, want a non-throttle error", err)
+				}
+				return
+			}
+			if wait := throttleWait(t, err); wait != 5*time.Second {
+				t.Errorf("RetryAfter = %v, want 5s", wait)
+			}
+		})
+	}
+}
+
 // TestTwelveDataMarket_GetTimeSeries_Retry_RetryAfterHTTPDate は
 // Retry-After に HTTP-date 形式が渡された場合も正しく扱われることを検証します。
 func TestTwelveDataMarket_GetTimeSeries_Retry_RetryAfterHTTPDate(t *testing.T) {
@@ -860,3 +1109,244 @@ func TestTwelveDataMarket_GetTimeSeries_Retry_NetworkError_NoSleepOnLastAttempt(
 		t.Errorf("expected total elapsed <= %v (no trailing sleep), got %v", maxExpected, elapsed)
 	}
 }
+
+// newProxyServer は全エンドポイントの正常なレスポンスを返し、受け取ったリクエストを requests に記録するテストサーバーを返します。
+func newProxyServer(t *testing.T, requests chan<- *http.Request) *httptest.Server {
+	t.Helper()
+	bodies := map[string]string{
+		"/time_series":   `{"status":"ok","values":[{"datetime":"2025-01-15","open":"1","high":"1","low":"1","close":"1","volume":"1"}]}`,
+		"/logo":          `{"url":"https://logo.example.com/aapl.png"}`,
+		"/exchange_rate": `{"symbol":"USD/JPY","rate":150,"timestamp":1790000000}`,
+		"/dividends":     `{"dividends":[]}`,
+		"/earnings":      `{"earnings":[]}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- r
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(bodies[r.URL.Path]))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// callAllEndpoints はクライアントの全メソッドを 1 回ずつ呼び出します。
+func callAllEndpoints(t *testing.T, market *TwelveDataMarket) {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := market.GetTimeSeries(ctx, "AAPL", "1day", 1, time.UTC); err != nil {
+		t.Fatalf("GetTimeSeries: %v", err)
+	}
+	if _, err := market.GetLogoURL(ctx, "AAPL"); err != nil {
+		t.Fatalf("GetLogoURL: %v", err)
+	}
+	if _, _, err := market.GetExchangeRate(ctx, "USD", "JPY"); err != nil {
+		t.Fatalf("GetExchangeRate: %v", err)
+	}
+	if _, err := market.GetDividends(ctx, "AAPL", time.Now(), time.Now()); err != nil {
+		t.Fatalf("GetDividends: %v", err)
+	}
+	if _, err := market.GetEarnings(ctx, "AAPL", time.Now(), time.Now()); err != nil {
+		t.Fatalf("GetEarnings: %v", err)
+	}
+}
+
+// TestTwelveDataMarket_ProxyAuthHeader は全メソッドのリクエストにプロキシの認証ヘッダーを付与することを検証します。
+func TestTwelveDataMarket_ProxyAuthHeader(t *testing.T) {
+	t.Parallel()
+
+	requests := make(chan *http.Request, 5)
+	server := newProxyServer(t, requests)
+	market := NewTwelveDataMarket(Config{
+		TwelveDataAPIKey: "test-key",
+		BaseURL:          server.URL,
+		ProxyAuthHeader:  "X-Proxy-Token",
+		ProxyAuthValue:   "proxy-secret",
+	}, server.Client())
+
+	callAllEndpoints(t, market)
+	close(requests)
+	for r := range requests {
+		if got := r.Header.Get("X-Proxy-Token"); got != "proxy-secret" {
+			t.Errorf("%s: expected proxy auth header, got %q", r.URL.Path, got)
+		}
+		if got := r.URL.Query().Get("apikey"); got != "test-key" {
+			t.Errorf("%s: expected apikey in query by default, got %q", r.URL.Path, got)
+		}
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Errorf("%s: expected no Authorization header by default, got %q", r.URL.Path, got)
+		}
+	}
+}
+
+// TestTwelveDataMarket_APIKeyInHeader は APIKeyInHeader のとき全メソッドで APIキーをヘッダーで送り、クエリに含めないことを検証します。
+func TestTwelveDataMarket_APIKeyInHeader(t *testing.T) {
+	t.Parallel()
+
+	requests := make(chan *http.Request, 5)
+	server := newProxyServer(t, requests)
+	market := NewTwelveDataMarket(Config{
+		TwelveDataAPIKey: "test-key",
+		BaseURL:          server.URL,
+		APIKeyInHeader:   true,
+	}, server.Client())
+
+	callAllEndpoints(t, market)
+	close(requests)
+	for r := range requests {
+		if got := r.Header.Get("Authorization"); got != "apikey test-key" {
+			t.Errorf("%s: expected API key header, got %q", r.URL.Path, got)
+		}
+		if strings.Contains(r.URL.RawQuery, "test-key") || r.URL.Query().Has("apikey") {
+			t.Errorf("%s: expected query without API key, got %q", r.URL.Path, r.URL.RawQuery)
+		}
+	}
+}
+
+// TestConfig_ValidateBaseURL は BaseURL のホストを AllowedHosts で制限することを検証します。
+func TestConfig_ValidateBaseURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		baseURL string
+		allowed []string
+		wantErr bool
+	}{
+		{name: "no allowlist", baseURL: "https://proxy-staging.internal", wantErr: false},
+		{name: "empty base url", baseURL: "", allowed: []string{"api.twelvedata.com"}, wantErr: false},
+		{name: "allowed host", baseURL: "https://api.twelvedata.com", allowed: []string{"proxy.internal", "api.twelvedata.com"}, wantErr: false},
+		{name: "case insensitive", baseURL: "https://API.twelvedata.com", allowed: []string{"api.twelvedata.com"}, wantErr: false},
+		{name: "host with port", baseURL: "http://proxy.internal:8080", allowed: []string{"proxy.internal:8080"}, wantErr: false},
+		{name: "port mismatch", baseURL: "http://proxy.internal:9090", allowed: []string{"proxy.internal:8080"}, wantErr: true},
+		{name: "staging proxy rejected", baseURL: "https://proxy-staging.internal", allowed: []string{"proxy.internal"}, wantErr: true},
+		{name: "missing host", baseURL: "/relative", allowed: []string{"proxy.internal"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := Config{BaseURL: tt.baseURL, AllowedHosts: tt.allowed}.ValidateBaseURL()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateBaseURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestTwelveDataMarket_GetTimeSeries_Trace は context の candles.Trace に上流の呼び出し時間を記録することを検証します。
+func TestTwelveDataMarket_GetTimeSeries_Trace(t *testing.T) {
+	t.Parallel()
+
+	requests := make(chan *http.Request, 1)
+	server := newProxyServer(t, requests)
+	market := NewTwelveDataMarket(Config{TwelveDataAPIKey: "test-key", BaseURL: server.URL}, server.Client())
+
+	ctx, tr := candles.WithTrace(context.Background())
+	if _, err := market.GetTimeSeries(ctx, "AAPL", "1day", 1, time.UTC); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res := tr.Result()
+	if len(res.Timings) != 1 || res.Timings[0].Stage != candles.TraceUpstream || res.Timings[0].Duration <= 0 {
+		t.Errorf("expected upstream timing, got %+v", res.Timings)
+	}
+}
+
+// TestTwelveDataMarket_GetTimeSeriesBatch は複数銘柄を 1 回の呼び出しで取得し、
+// 銘柄ごとのタイムゾーンで解釈すること、銘柄ごとの失敗を BatchSymbolErrors で返すことを検証します。
+func TestTwelveDataMarket_GetTimeSeriesBatch(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if got := r.URL.Query().Get("symbol"); got != "AAPL,7203,NOPE" {
+			t.Errorf("expected comma-joined symbols, got %s", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"AAPL": {"meta": {"symbol": "AAPL"}, "status": "ok", "values": [
+				{"datetime": "2025-01-15", "open": "150", "high": "155", "low": "149", "close": "154.5", "volume": "1000000"}
+			]},
+			"7203": {"meta": {"symbol": "7203"}, "status": "ok", "values": [
+				{"datetime": "2025-01-15", "open": "2800", "high": "2850", "low": "2790", "close": "2840", "volume": "500000"}
+			]},
+			"NOPE": {"code": 400, "message": "symbol not found", "status": "error"}
+		}`))
+	}))
+	defer server.Close()
+
+	market := NewTwelveDataMarket(Config{TwelveDataAPIKey: "test-key", BaseURL: server.URL}, server.Client())
+	ny, _ := time.LoadLocation("America/New_York")
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	locs := map[string]*time.Location{"AAPL": ny, "7203": tokyo, "NOPE": time.UTC}
+
+	got, err := market.GetTimeSeriesBatch(context.Background(), []string{"AAPL", "7203", "NOPE"}, "1day", 100, locs)
+	var symErrs candles.BatchSymbolErrors
+	if !errors.As(err, &symErrs) {
+		t.Fatalf("expected BatchSymbolErrors, got %v", err)
+	}
+	if len(symErrs) != 1 || !strings.Contains(symErrs["NOPE"].Error(), "symbol not found") {
+		t.Errorf("expected only NOPE to fail, got %v", symErrs)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected 1 request, got %d", calls.Load())
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 symbols, got %d", len(got))
+	}
+	if want := time.Date(2025, 1, 15, 0, 0, 0, 0, ny); !got["AAPL"][0].Time.Equal(want) {
+		t.Errorf("expected AAPL time %v, got %v", want, got["AAPL"][0].Time)
+	}
+	if want := time.Date(2025, 1, 15, 0, 0, 0, 0, tokyo); !got["7203"][0].Time.Equal(want) {
+		t.Errorf("expected 7203 time %v, got %v", want, got["7203"][0].Time)
+	}
+	if got["7203"][0].Close != 2840 || got["7203"][0].Source != candles.SourceTwelveData {
+		t.Errorf("unexpected 7203 candle: %+v", got["7203"][0])
+	}
+}
+
+// TestTwelveDataMarket_GetTimeSeriesBatch_WholeRequestError はトップレベルの status が error の
+// レスポンスを呼び出し全体の失敗として返すことを検証します（利用枠の枯渇は ThrottledError）。
+func TestTwelveDataMarket_GetTimeSeriesBatch_WholeRequestError(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"code": 429, "message": "You have run out of API credits for the current minute.", "status": "error"}`))
+	}))
+	defer server.Close()
+
+	market := NewTwelveDataMarket(Config{TwelveDataAPIKey: "test-key", BaseURL: server.URL}, server.Client())
+	locs := map[string]*time.Location{"AAPL": time.UTC, "MSFT": time.UTC}
+
+	_, err := market.GetTimeSeriesBatch(context.Background(), []string{"AAPL", "MSFT"}, "1day", 100, locs)
+	var symErrs candles.BatchSymbolErrors
+	if errors.As(err, &symErrs) {
+		t.Fatalf("expected whole-request error, got %v", err)
+	}
+	if !errors.Is(err, candles.ErrUpstreamThrottled) {
+		t.Errorf("expected ErrUpstreamThrottled, got %v", err)
+	}
+}
+
+// TestTwelveDataMarket_GetTimeSeriesBatch_SingleSymbol は 1 銘柄なら従来の単一銘柄のレスポンスとして扱うことを検証します。
+func TestTwelveDataMarket_GetTimeSeriesBatch_SingleSymbol(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status": "ok", "symbol": "AAPL", "interval": "1day", "values": [
+			{"datetime": "2025-01-15", "open": "150", "high": "155", "low": "149", "close": "154.5", "volume": "1000000"}
+		]}`))
+	}))
+	defer server.Close()
+
+	market := NewTwelveDataMarket(Config{TwelveDataAPIKey: "test-key", BaseURL: server.URL}, server.Client())
+
+	got, err := market.GetTimeSeriesBatch(context.Background(), []string{"AAPL"}, "1day", 100, map[string]*time.Location{"AAPL": time.UTC})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got["AAPL"]) != 1 || got["AAPL"][0].Close != 154.5 {
+		t.Errorf("unexpected result: %+v", got)
+	}
+}
@@ -3,6 +3,7 @@ package twelvedata
 // TimeSeriesResponse はTwelve Data time_seriesエンドポイントからのJSONレスポンスを表します。
 type TimeSeriesResponse struct {
 	Status   string `json:"status"`
+	Code     int    `json:"code,omitempty"` // status が error の場合の HTTP 相当のコード（利用枠の枯渇は 429）
 	Message  string `json:"message,omitempty"`
 	Symbol   string `json:"symbol"`
 	Interval string `json:"interval"`
@@ -2,15 +2,14 @@ package candles
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 )
 
 const (
 	// DefaultInterval はローソク足クエリのデフォルト時間間隔です。
 	DefaultInterval = "1day"
-	// DefaultOutputSize はデフォルトのローソク足返却件数です。
-	DefaultOutputSize = 200
-	// MaxOutputSize はローソク足の最大返却件数です。
-	MaxOutputSize = 5000
 )
 
 // Repository はローソク足データの読み取りレイヤーを抽象化します。
@@ -20,29 +19,276 @@ type Repository interface {
 	Find(ctx context.Context, symbol, interval string, outputsize int) ([]Candle, error)
 }
 
+// AdjustMode は ?adjusted= で指定する分割調整の適用方法です。
+type AdjustMode int
+
+const (
+	// AdjustDefault は未指定です。FlagAdjustedDefault が有効なら調整後、無効なら未調整の値を返します。
+	AdjustDefault AdjustMode = iota
+	// AdjustOn は調整係数を適用した値を返します。
+	AdjustOn
+	// AdjustOff は保存済み（未調整）の値を返します。
+	AdjustOff
+)
+
+// FlagAdjustedDefault は ?adjusted= 未指定時に分割調整後の値を返すフィーチャーフラグです。
+// 調整係数の運用が安定するまでは無効（未調整）を既定とします。
+const FlagAdjustedDefault = "adjusted_default"
+
+// AdjustedFinder は調整後のローソク足をキャッシュ付きで返すリポジトリが実装します（CachingRepository）。
+// 実装していないリポジトリでは Find の結果に ApplyAdjustments を適用します。
+type AdjustedFinder interface {
+	FindAdjusted(ctx context.Context, symbol, interval string, outputsize int, adjs []Adjustment) ([]Candle, error)
+}
+
+// SparklineFinder はスパークラインをキャッシュ付きで返すリポジトリが実装します（CachingRepository）。
+// 実装していないリポジトリでは取得したローソク足から都度 NewSparkline で生成します。
+type SparklineFinder interface {
+	FindSparkline(ctx context.Context, symbol, interval string, outputsize, points int, adjs []Adjustment) (Sparkline, error)
+}
+
+// AsOfFinder は as-of クエリ（指定時点で保存されていたローソク足）に対応するリポジトリが実装します。
+// バックテストの再現用で、キャッシュを経由せず常に DB から読み取ります。
+type AsOfFinder interface {
+	FindAsOf(ctx context.Context, symbol, interval string, asOf time.Time, outputsize int) ([]Candle, error)
+}
+
+// errAsOfUnsupported は AsOfFinder を実装しないリポジトリで as-of クエリを行った場合のエラーです（構成の誤り）。
+var errAsOfUnsupported = errors.New("as-of queries are not supported by the repository")
+
+// ActiveSymbolChecker はアクティブ銘柄かどうかの判定を行うインターフェースです。
+// candles usecase が symbollist feature に直接依存しないよう、
+// 最小限の読み取り専用インターフェースをここで定義します。
+type ActiveSymbolChecker interface {
+	Contains(ctx context.Context, code string) (bool, error)
+}
+
 // usecase はローソク足データ操作のユースケースを定義します。
 type usecase struct {
-	candle Repository
+	candle      Repository
+	resolver    *SymbolResolver
+	adjustments AdjustmentSource
+	flags       FlagChecker
+	outputSizes OutputSizePolicy
+	tiers       SymbolTierSource
 }
 
 // NewUsecase はusecaseの新しいインスタンスを生成します。
-func NewUsecase(candle Repository) *usecase {
-	return &usecase{candle: candle}
+// 銘柄コードは DefaultSymbolRules で正規コードに解決してから参照します。
+func NewUsecase(candle Repository, symbols ActiveSymbolChecker) *usecase {
+	return &usecase{candle: candle, resolver: NewSymbolResolver(symbols, DefaultSymbolRules)}
+}
+
+// WithAdjustments は読み取り時に適用する調整係数の取得元と、?adjusted= 未指定時の既定値を決めるフラグを設定します。
+// 未設定の場合、ローソク足は常に保存済み（未調整）の値で返します。flags が nil の場合は FlagDefault に従います。
+func (cu *usecase) WithAdjustments(src AdjustmentSource, flags FlagChecker) *usecase {
+	cu.adjustments = src
+	cu.flags = flags
+	return cu
+}
+
+// WithOutputSizePolicy は時間間隔ごとの outputsize の既定値と上限を設定します（未設定なら DefaultOutputSizePolicy）。
+func (cu *usecase) WithOutputSizePolicy(p OutputSizePolicy) *usecase {
+	cu.outputSizes = p
+	return cu
+}
+
+// WithTiers は銘柄の区分の取得元を設定します。設定すると、ctx のプラン（WithPlan）で参照できない銘柄の
+// ローソク足・統計・スパークラインの要求に UpgradeRequiredError を返します。未設定の場合は制限しません。
+func (cu *usecase) WithTiers(src SymbolTierSource) *usecase {
+	cu.tiers = src
+	return cu
+}
+
+// ResolveSymbol は入力された銘柄コードを正規コードに解決します（SymbolResolver.Resolve 参照）。
+func (cu *usecase) ResolveSymbol(ctx context.Context, symbol string) (string, error) {
+	return cu.resolver.Resolve(ctx, symbol)
 }
 
 // GetCandles は指定された銘柄と時間間隔のローソク足データを取得します。
-func (cu *usecase) GetCandles(ctx context.Context, symbol, interval string, outputsize int) ([]Candle, error) {
+// 銘柄コードは正規コードに解決してからリポジトリ（およびキャッシュ）に渡します。
+// 解決できないコードの場合は ErrSymbolNotFound（複数候補に一致する場合は ErrAmbiguousSymbol）を、
+// ctx のプランで参照できない銘柄の場合は UpgradeRequiredError（ErrUpgradeRequired）を返します。
+// 既知の銘柄でデータが0件の場合はエラーとせず空スライスを返します。
+// adjust に従い、調整係数のある銘柄は O/H/L/C・出来高を分割調整した値で返します（保存済みのデータは変更しません）。
+func (cu *usecase) GetCandles(ctx context.Context, symbol, interval string, outputsize int, adjust AdjustMode) ([]Candle, error) {
+	symbol, err := cu.resolve(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
 	if interval == "" {
 		interval = DefaultInterval
 	}
-	if outputsize <= 0 || outputsize > MaxOutputSize {
-		outputsize = DefaultOutputSize
+	outputsize = cu.outputSizes.Normalize(interval, outputsize)
+
+	adjs, err := cu.adjustmentsFor(ctx, symbol, adjust)
+	if err != nil {
+		return nil, err
 	}
+	return cu.find(ctx, symbol, interval, outputsize, adjs)
+}
 
+// GetCandlesAsOf は asOf 時点で保存されていたローソク足を返します（バックテストの再現用）。
+// 銘柄の解決・プランによる制限と interval・outputsize の既定値は GetCandles と同じです。
+// 値は保存済み（未調整）のままで、asOf より後に値が書き換わった足は含みません。
+func (cu *usecase) GetCandlesAsOf(ctx context.Context, symbol, interval string, outputsize int, asOf time.Time) ([]Candle, error) {
+	f, ok := cu.candle.(AsOfFinder)
+	if !ok {
+		return nil, errAsOfUnsupported
+	}
+	symbol, err := cu.resolve(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	if interval == "" {
+		interval = DefaultInterval
+	}
+	outputsize = cu.outputSizes.Normalize(interval, outputsize)
+	return f.FindAsOf(ctx, symbol, interval, asOf, outputsize)
+}
+
+// GetCandlesRange は日付の範囲 rng（両端の日を含む）のローソク足を時間の降順で最大 outputsize 件返します。
+// 銘柄の解決・プランによる制限・分割調整（adjust）は GetCandles と同じです。開始日が終了日より後の場合は ErrInvalidRange を返します。
+// outputsize の未指定（0 以下）は範囲の足を既定の件数で切らないよう時間間隔の上限まで返し、それ以外は GetCandles と同じく正規化します。
+func (cu *usecase) GetCandlesRange(ctx context.Context, symbol, interval string, rng DateRange, outputsize int, adjust AdjustMode) ([]Candle, error) {
+	if err := rng.Validate(); err != nil {
+		return nil, err
+	}
+	symbol, err := cu.resolve(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	if interval == "" {
+		interval = DefaultInterval
+	}
+	if outputsize <= 0 {
+		outputsize = cu.outputSizes.Limit(interval).Max
+	} else {
+		outputsize = cu.outputSizes.Normalize(interval, outputsize)
+	}
+
+	adjs, err := cu.adjustmentsFor(ctx, symbol, adjust)
+	if err != nil {
+		return nil, err
+	}
+	cs, err := findRange(ctx, cu.candle, symbol, interval, rng, outputsize)
+	if err != nil {
+		return nil, err
+	}
+	if len(adjs) == 0 {
+		return cs, nil
+	}
+	return ApplyAdjustments(cs, adjs), nil
+}
+
+// resolve は symbol を正規コードに解決し、ctx のプランで参照できる銘柄かを確認します。
+func (cu *usecase) resolve(ctx context.Context, symbol string) (string, error) {
+	code, err := cu.resolver.Resolve(ctx, symbol)
+	if err != nil {
+		return "", err
+	}
+	if err := checkTier(ctx, cu.tiers, code); err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// adjustmentsFor は adjust に従って symbol に適用する調整係数を返します。適用しない場合は nil を返します。
+func (cu *usecase) adjustmentsFor(ctx context.Context, symbol string, adjust AdjustMode) ([]Adjustment, error) {
+	if !cu.adjusted(ctx, adjust) {
+		return nil, nil
+	}
+	adjs, err := cu.adjustments.ListAdjustments(ctx, symbol)
+	if err != nil {
+		// 未調整の値で代替すると分割前後で価格が不連続になるため、エラーとして返す
+		return nil, fmt.Errorf("list adjustments for %s: %w", symbol, err)
+	}
+	return adjs, nil
+}
+
+// find は正規コード symbol のローソク足を取得し、adjs があれば調整係数を適用して返します。
+func (cu *usecase) find(ctx context.Context, symbol, interval string, outputsize int, adjs []Adjustment) ([]Candle, error) {
+	if len(adjs) == 0 {
+		return cu.candle.Find(ctx, symbol, interval, outputsize)
+	}
+	if f, ok := cu.candle.(AdjustedFinder); ok {
+		return f.FindAdjusted(ctx, symbol, interval, outputsize, adjs)
+	}
 	cs, err := cu.candle.Find(ctx, symbol, interval, outputsize)
 	if err != nil {
 		return nil, err
 	}
+	return ApplyAdjustments(cs, adjs), nil
+}
 
-	return cs, nil
+// adjusted は adjust と FlagAdjustedDefault から調整係数を適用するかを返します。
+func (cu *usecase) adjusted(ctx context.Context, adjust AdjustMode) bool {
+	if cu.adjustments == nil {
+		return false
+	}
+	switch adjust {
+	case AdjustOn:
+		return true
+	case AdjustOff:
+		return false
+	}
+	if cu.flags == nil {
+		return FlagDefault(FlagAdjustedDefault)
+	}
+	return cu.flags.Enabled(ctx, FlagAdjustedDefault)
+}
+
+// GetStats は指定された銘柄と時間間隔のローソク足の要約統計を返します。
+// 保有データ（最大で時間間隔の outputsize の上限まで）を1回の Find で取得し、ComputeStats で集計します。
+// 未知・非アクティブ銘柄は ErrSymbolNotFound、参照できない銘柄は UpgradeRequiredError、データが0件の場合は ErrNoCandles を返します。
+// 分割調整は GetCandles と同じく adjust に従います。
+func (cu *usecase) GetStats(ctx context.Context, symbol, interval string, adjust AdjustMode) (Stats, error) {
+	if interval == "" {
+		interval = DefaultInterval
+	}
+	cs, err := cu.GetCandles(ctx, symbol, interval, cu.outputSizes.Limit(interval).Max, adjust)
+	if err != nil {
+		return Stats{}, err
+	}
+	s, ok := ComputeStats(cs)
+	if !ok {
+		return Stats{}, ErrNoCandles
+	}
+	return s, nil
+}
+
+// GetSparkline は指定された銘柄と時間間隔の最新 outputsize 件のローソク足を、最大 points 点の終値に間引いて返します。
+// outputsize・points が範囲外の場合はそれぞれ時間間隔ごとの既定値（OutputSizePolicy）・DefaultSparklinePoints を使います。
+// 未知・非アクティブ銘柄は ErrSymbolNotFound を、ctx のプランで参照できない銘柄は UpgradeRequiredError を返し、
+// データが0件の場合は空のスパークラインを返します。
+// 分割調整は GetCandles と同じく adjust に従います。
+func (cu *usecase) GetSparkline(ctx context.Context, symbol, interval string, outputsize, points int, adjust AdjustMode) (Sparkline, error) {
+	symbol, err := cu.resolve(ctx, symbol)
+	if err != nil {
+		return Sparkline{}, err
+	}
+
+	if interval == "" {
+		interval = DefaultInterval
+	}
+	outputsize = cu.outputSizes.Normalize(interval, outputsize)
+	if points < MinSparklinePoints || points > MaxSparklinePoints {
+		points = DefaultSparklinePoints
+	}
+
+	adjs, err := cu.adjustmentsFor(ctx, symbol, adjust)
+	if err != nil {
+		return Sparkline{}, err
+	}
+	if f, ok := cu.candle.(SparklineFinder); ok {
+		return f.FindSparkline(ctx, symbol, interval, outputsize, points, adjs)
+	}
+	cs, err := cu.find(ctx, symbol, interval, outputsize, adjs)
+	if err != nil {
+		return Sparkline{}, err
+	}
+	return NewSparkline(cs, points), nil
 }
@@ -28,6 +28,29 @@ func (m *mockRepository) Find(ctx context.Context, symbol, interval string, outp
 	return nil, errors.New("FindFunc is not implemented")
 }
 
+// stubSymbolChecker はActiveSymbolCheckerインターフェースのスタブ実装です。
+// codes に含まれるコードのみをアクティブとみなします。
+type stubSymbolChecker struct {
+	codes map[string]bool
+	err   error
+}
+
+func (s *stubSymbolChecker) Contains(ctx context.Context, code string) (bool, error) {
+	if s.err != nil {
+		return false, s.err
+	}
+	return s.codes[code], nil
+}
+
+// allActive はテスト対象の全銘柄をアクティブとして扱うスタブを返します。
+func allActive(codes ...string) *stubSymbolChecker {
+	m := make(map[string]bool, len(codes))
+	for _, c := range codes {
+		m[c] = true
+	}
+	return &stubSymbolChecker{codes: m}
+}
+
 // TestCandlesUsecase_GetCandles はGetCandlesメソッドのパラメータ処理とリポジトリ呼び出しをテストします。
 func TestCandlesUsecase_GetCandles(t *testing.T) {
 	ctx := context.Background()
@@ -83,7 +106,7 @@ func TestCandlesUsecase_GetCandles(t *testing.T) {
 			expectedCandles:    expectedCandles,
 			expectedErr:        nil,
 			expectedInterval:   "1month",
-			expectedOutputsize: 200,
+			expectedOutputsize: 120, // 月足の既定値
 		},
 		{
 			name:            "success: default value used when outputsize exceeds max",
@@ -125,9 +148,9 @@ func TestCandlesUsecase_GetCandles(t *testing.T) {
 					return tc.mockFindFunc(ctx, symbol, interval, outputsize)
 				},
 			}
-			uc := candles.NewUsecase(mockRepo)
+			uc := candles.NewUsecase(mockRepo, allActive(tc.inputSymbol))
 
-			candles, err := uc.GetCandles(ctx, tc.inputSymbol, tc.inputInterval, tc.inputOutputsize)
+			candles, err := uc.GetCandles(ctx, tc.inputSymbol, tc.inputInterval, tc.inputOutputsize, candles.AdjustDefault)
 
 			// センチネル比較によるエラー検証
 			if tc.expectedErr == nil {
@@ -150,3 +173,402 @@ func TestCandlesUsecase_GetCandles(t *testing.T) {
 		})
 	}
 }
+
+// TestCandlesUsecase_GetCandles_SymbolCheck はアクティブ銘柄チェックの挙動を検証します。
+func TestCandlesUsecase_GetCandles_SymbolCheck(t *testing.T) {
+	ctx := context.Background()
+	errChecker := errors.New("checker error")
+
+	testCases := []struct {
+		name          string
+		checker       *stubSymbolChecker
+		expectedErr   error
+		expectedFinds int
+	}{
+		{
+			name:          "error: unknown or inactive symbol returns ErrSymbolNotFound",
+			checker:       allActive("AAPL"),
+			expectedErr:   candles.ErrSymbolNotFound,
+			expectedFinds: 0,
+		},
+		{
+			name:          "error: checker failure is propagated",
+			checker:       &stubSymbolChecker{err: errChecker},
+			expectedErr:   errChecker,
+			expectedFinds: 0,
+		},
+		{
+			name:          "success: known symbol with no candles returns empty slice",
+			checker:       allActive("NEWCO"),
+			expectedErr:   nil,
+			expectedFinds: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := &mockRepository{
+				FindFunc: func(ctx context.Context, symbol, interval string, outputsize int) ([]candles.Candle, error) {
+					return []candles.Candle{}, nil
+				},
+			}
+			uc := candles.NewUsecase(mockRepo, tc.checker)
+
+			got, err := uc.GetCandles(ctx, "NEWCO", "", 0, candles.AdjustDefault)
+			if tc.expectedErr == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if got == nil || len(got) != 0 {
+					t.Errorf("expected empty non-nil slice, got %v", got)
+				}
+			} else if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected %v, got %v", tc.expectedErr, err)
+			}
+			if mockRepo.FindCalls != tc.expectedFinds {
+				t.Errorf("Find was called %d times, expected %d", mockRepo.FindCalls, tc.expectedFinds)
+			}
+		})
+	}
+}
+
+// TestCandlesUsecase_GetCandles_ResolvesSymbol は正規化後の銘柄コードがリポジトリに渡されることを検証します。
+// キャッシュキーもリポジトリへ渡したコードで決まるため、表記ゆれで別キーにならないことの担保となります。
+func TestCandlesUsecase_GetCandles_ResolvesSymbol(t *testing.T) {
+	var gotSymbol string
+	mockRepo := &mockRepository{
+		FindFunc: func(ctx context.Context, symbol, interval string, outputsize int) ([]candles.Candle, error) {
+			gotSymbol = symbol
+			return []candles.Candle{}, nil
+		},
+	}
+	uc := candles.NewUsecase(mockRepo, allActive("7203.T"))
+
+	if _, err := uc.GetCandles(context.Background(), "7203", "", 0, candles.AdjustDefault); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotSymbol != "7203.T" {
+		t.Errorf("repository received %q, want canonical 7203.T", gotSymbol)
+	}
+}
+
+// TestCandlesUsecase_GetStats はGetStatsメソッドの取得件数とエラー変換を検証します。
+func TestCandlesUsecase_GetStats(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success: computes stats from all stored candles", func(t *testing.T) {
+		mockRepo := &mockRepository{
+			FindFunc: func(ctx context.Context, symbol, interval string, outputsize int) ([]candles.Candle, error) {
+				if outputsize != candles.MaxOutputSize {
+					t.Errorf("outputsize: got %d, want %d", outputsize, candles.MaxOutputSize)
+				}
+				return []candles.Candle{
+					{Time: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), Open: 100, High: 120, Low: 95, Close: 110, Volume: 10},
+					{Time: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Open: 90, High: 105, Low: 85, Close: 100, Volume: 20},
+				}, nil
+			},
+		}
+		uc := candles.NewUsecase(mockRepo, allActive("AAPL"))
+
+		s, err := uc.GetStats(ctx, "AAPL", "1day", candles.AdjustDefault)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if s.High52W.Value != 120 || s.Low52W.Value != 85 {
+			t.Errorf("unexpected 52w range: %+v / %+v", s.High52W, s.Low52W)
+		}
+	})
+
+	t.Run("error: no candles returns ErrNoCandles", func(t *testing.T) {
+		mockRepo := &mockRepository{
+			FindFunc: func(ctx context.Context, symbol, interval string, outputsize int) ([]candles.Candle, error) {
+				return []candles.Candle{}, nil
+			},
+		}
+		uc := candles.NewUsecase(mockRepo, allActive("AAPL"))
+
+		if _, err := uc.GetStats(ctx, "AAPL", "1day", candles.AdjustDefault); !errors.Is(err, candles.ErrNoCandles) {
+			t.Fatalf("expected ErrNoCandles, got %v", err)
+		}
+	})
+
+	t.Run("error: unknown symbol returns ErrSymbolNotFound", func(t *testing.T) {
+		uc := candles.NewUsecase(&mockRepository{}, allActive())
+
+		if _, err := uc.GetStats(ctx, "UNKNOWN", "1day", candles.AdjustDefault); !errors.Is(err, candles.ErrSymbolNotFound) {
+			t.Fatalf("expected ErrSymbolNotFound, got %v", err)
+		}
+	})
+
+	t.Run("success: reads up to the interval max of the outputsize policy", func(t *testing.T) {
+		var got int
+		mockRepo := &mockRepository{
+			FindFunc: func(ctx context.Context, symbol, interval string, outputsize int) ([]candles.Candle, error) {
+				got = outputsize
+				return []candles.Candle{{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Close: 100}}, nil
+			},
+		}
+		uc := candles.NewUsecase(mockRepo, allActive("AAPL"))
+
+		if _, err := uc.GetStats(ctx, "AAPL", "1month", candles.AdjustDefault); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != 240 {
+			t.Errorf("outputsize: got %d, want 240 (1month max)", got)
+		}
+	})
+}
+
+// TestCandlesUsecase_GetCandles_OutputSizePolicy は WithOutputSizePolicy の既定値と上限で outputsize を正規化することを検証します。
+func TestCandlesUsecase_GetCandles_OutputSizePolicy(t *testing.T) {
+	policy, err := candles.ParseOutputSizePolicy("1week=52:104")
+	if err != nil {
+		t.Fatalf("ParseOutputSizePolicy: %v", err)
+	}
+	tests := []struct {
+		name string
+		in   int
+		want int
+	}{
+		{name: "default when omitted", in: 0, want: 52},
+		{name: "within max", in: 104, want: 104},
+		{name: "default when exceeding max", in: 105, want: 52},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got int
+			mockRepo := &mockRepository{
+				FindFunc: func(ctx context.Context, symbol, interval string, outputsize int) ([]candles.Candle, error) {
+					got = outputsize
+					return nil, nil
+				},
+			}
+			uc := candles.NewUsecase(mockRepo, allActive("AAPL")).WithOutputSizePolicy(policy)
+
+			if _, err := uc.GetCandles(context.Background(), "AAPL", "1week", tc.in, candles.AdjustDefault); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("outputsize: got %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCandlesUsecase_GetSparkline(t *testing.T) {
+	ctx := context.Background()
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("success: downsamples the latest outputsize candles", func(t *testing.T) {
+		mockRepo := &mockRepository{
+			FindFunc: func(ctx context.Context, symbol, interval string, outputsize int) ([]candles.Candle, error) {
+				if symbol != "AAPL" || interval != "1day" || outputsize != 100 {
+					t.Errorf("Find(%q, %q, %d), want (AAPL, 1day, 100)", symbol, interval, outputsize)
+				}
+				cs := make([]candles.Candle, outputsize)
+				for i := range cs {
+					cs[i] = candles.Candle{Time: day.AddDate(0, 0, outputsize-1-i), Close: float64(i)}
+				}
+				return cs, nil
+			},
+		}
+		uc := candles.NewUsecase(mockRepo, allActive("AAPL"))
+
+		s, err := uc.GetSparkline(ctx, "AAPL", "1day", 100, 10, candles.AdjustDefault)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(s.Closes) != 10 {
+			t.Errorf("len(Closes) = %d, want 10", len(s.Closes))
+		}
+		if !s.First.Equal(day) || !s.Last.Equal(day.AddDate(0, 0, 99)) {
+			t.Errorf("range = %v..%v", s.First, s.Last)
+		}
+	})
+
+	t.Run("out of range points falls back to default", func(t *testing.T) {
+		mockRepo := &mockRepository{
+			FindFunc: func(ctx context.Context, symbol, interval string, outputsize int) ([]candles.Candle, error) {
+				cs := make([]candles.Candle, outputsize)
+				for i := range cs {
+					cs[i] = candles.Candle{Time: day.AddDate(0, 0, -i)}
+				}
+				return cs, nil
+			},
+		}
+		uc := candles.NewUsecase(mockRepo, allActive("AAPL"))
+
+		s, err := uc.GetSparkline(ctx, "AAPL", "1day", 0, candles.MaxSparklinePoints+1, candles.AdjustDefault)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(s.Closes) != candles.DefaultSparklinePoints {
+			t.Errorf("len(Closes) = %d, want %d", len(s.Closes), candles.DefaultSparklinePoints)
+		}
+	})
+
+	t.Run("error: unknown symbol returns ErrSymbolNotFound", func(t *testing.T) {
+		uc := candles.NewUsecase(&mockRepository{}, allActive())
+
+		if _, err := uc.GetSparkline(ctx, "UNKNOWN", "1day", 30, 30, candles.AdjustDefault); !errors.Is(err, candles.ErrSymbolNotFound) {
+			t.Fatalf("expected ErrSymbolNotFound, got %v", err)
+		}
+	})
+}
+
+// stubAdjustments は固定の調整係数を返す AdjustmentSource です。
+type stubAdjustments struct {
+	adjs []candles.Adjustment
+	err  error
+}
+
+func (s stubAdjustments) ListAdjustments(context.Context, string) ([]candles.Adjustment, error) {
+	return s.adjs, s.err
+}
+
+// stubFlags は指定したフラグのみ有効とする FlagChecker です。
+type stubFlags map[string]bool
+
+func (f stubFlags) Enabled(_ context.Context, name string) bool { return f[name] }
+
+// TestCandlesUsecase_GetCandles_Adjusted は ?adjusted= と FlagAdjustedDefault に従って調整係数を適用することを検証します。
+func TestCandlesUsecase_GetCandles_Adjusted(t *testing.T) {
+	ctx := context.Background()
+	split := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	raw := []candles.Candle{{Time: split.AddDate(0, 0, -3), Open: 100, High: 100, Low: 100, Close: 100, Volume: 10}}
+	adjs := stubAdjustments{adjs: []candles.Adjustment{{EffectiveDate: split, Factor: 0.5}}}
+	repo := &mockRepository{FindFunc: func(context.Context, string, string, int) ([]candles.Candle, error) {
+		return raw, nil
+	}}
+
+	tests := []struct {
+		name      string
+		src       candles.AdjustmentSource
+		flagOn    bool
+		adjust    candles.AdjustMode
+		wantClose float64
+		wantErr   bool
+	}{
+		{name: "未指定・フラグ無効は未調整", src: adjs, adjust: candles.AdjustDefault, wantClose: 100},
+		{name: "未指定・フラグ有効は調整後", src: adjs, flagOn: true, adjust: candles.AdjustDefault, wantClose: 50},
+		{name: "adjusted=true", src: adjs, adjust: candles.AdjustOn, wantClose: 50},
+		{name: "adjusted=false はフラグより優先", src: adjs, flagOn: true, adjust: candles.AdjustOff, wantClose: 100},
+		{name: "調整係数のない銘柄", src: stubAdjustments{}, adjust: candles.AdjustOn, wantClose: 100},
+		{name: "取得元未設定は未調整", adjust: candles.AdjustOn, wantClose: 100},
+		{name: "調整係数の取得失敗はエラー", src: stubAdjustments{err: ErrDB}, adjust: candles.AdjustOn, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := candles.NewUsecase(repo, allActive("AAPL"))
+			if tt.src != nil {
+				uc = uc.WithAdjustments(tt.src, stubFlags{candles.FlagAdjustedDefault: tt.flagOn})
+			}
+
+			got, err := uc.GetCandles(ctx, "AAPL", "1day", 10, tt.adjust)
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrDB) {
+					t.Fatalf("expected ErrDB, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got[0].Close != tt.wantClose {
+				t.Errorf("close = %v, want %v", got[0].Close, tt.wantClose)
+			}
+			if raw[0].Close != 100 {
+				t.Fatal("stored candles must not be modified")
+			}
+		})
+	}
+}
+
+// asOfRepository は AsOfFinder を実装するモックリポジトリです。
+type asOfRepository struct {
+	mockRepository
+	gotSymbol     string
+	gotAsOf       time.Time
+	gotOutputsize int
+}
+
+func (m *asOfRepository) FindAsOf(_ context.Context, symbol, _ string, asOf time.Time, outputsize int) ([]candles.Candle, error) {
+	m.gotSymbol, m.gotAsOf, m.gotOutputsize = symbol, asOf, outputsize
+	return []candles.Candle{}, nil
+}
+
+// TestCandlesUsecase_GetCandlesAsOf は as-of クエリが正規コードと既定の件数で AsOfFinder に委譲されることを検証します。
+func TestCandlesUsecase_GetCandlesAsOf(t *testing.T) {
+	asOf := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	repo := &asOfRepository{}
+	uc := candles.NewUsecase(repo, allActive("7203.T"))
+
+	if _, err := uc.GetCandlesAsOf(context.Background(), "7203", "", 0, asOf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.gotSymbol != "7203.T" || !repo.gotAsOf.Equal(asOf) || repo.gotOutputsize != candles.DefaultOutputSizePolicy().Limit(candles.DefaultInterval).Default {
+		t.Errorf("FindAsOf got (%q, %v, %d)", repo.gotSymbol, repo.gotAsOf, repo.gotOutputsize)
+	}
+	if repo.FindCalls != 0 {
+		t.Errorf("Find called %d times, want 0", repo.FindCalls)
+	}
+
+	if _, err := uc.GetCandlesAsOf(context.Background(), "UNKNOWN", "", 0, asOf); !errors.Is(err, candles.ErrSymbolNotFound) {
+		t.Errorf("unknown symbol err = %v, want ErrSymbolNotFound", err)
+	}
+}
+
+// rangeRepository は RangeFinder を実装するモックリポジトリです。
+type rangeRepository struct {
+	mockRepository
+	gotSymbol     string
+	gotRange      candles.DateRange
+	gotOutputsize int
+}
+
+func (m *rangeRepository) FindRange(_ context.Context, symbol, _ string, rng candles.DateRange, outputsize int) ([]candles.Candle, error) {
+	m.gotSymbol, m.gotRange, m.gotOutputsize = symbol, rng, outputsize
+	return []candles.Candle{}, nil
+}
+
+// TestCandlesUsecase_GetCandlesRange は期間指定の読み取りが正規コードで RangeFinder に委譲され、
+// outputsize の未指定は時間間隔の上限になること、開始日が終了日より後なら ErrInvalidRange を返すことを検証します。
+func TestCandlesUsecase_GetCandlesRange(t *testing.T) {
+	rng := candles.DateRange{Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)}
+	repo := &rangeRepository{}
+	uc := candles.NewUsecase(repo, allActive("7203.T"))
+	ctx := context.Background()
+
+	if _, err := uc.GetCandlesRange(ctx, "7203", "", rng, 0, candles.AdjustDefault); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.gotSymbol != "7203.T" || repo.gotRange != rng || repo.gotOutputsize != candles.DefaultOutputSizePolicy().Limit(candles.DefaultInterval).Max {
+		t.Errorf("FindRange got (%q, %+v, %d)", repo.gotSymbol, repo.gotRange, repo.gotOutputsize)
+	}
+	if _, err := uc.GetCandlesRange(ctx, "7203", "", rng, 30, candles.AdjustDefault); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.gotOutputsize != 30 {
+		t.Errorf("outputsize = %d, want 30", repo.gotOutputsize)
+	}
+	if repo.FindCalls != 0 {
+		t.Errorf("Find called %d times, want 0", repo.FindCalls)
+	}
+
+	reversed := candles.DateRange{Start: rng.End, End: rng.Start}
+	if _, err := uc.GetCandlesRange(ctx, "7203", "", reversed, 0, candles.AdjustDefault); !errors.Is(err, candles.ErrInvalidRange) {
+		t.Errorf("reversed range err = %v, want ErrInvalidRange", err)
+	}
+	if _, err := uc.GetCandlesRange(ctx, "UNKNOWN", "", rng, 0, candles.AdjustDefault); !errors.Is(err, candles.ErrSymbolNotFound) {
+		t.Errorf("unknown symbol err = %v, want ErrSymbolNotFound", err)
+	}
+}
+
+// TestCandlesUsecase_GetCandlesAsOf_Unsupported は AsOfFinder を実装しないリポジトリではエラーを返すことを検証します。
+func TestCandlesUsecase_GetCandlesAsOf_Unsupported(t *testing.T) {
+	uc := candles.NewUsecase(&mockRepository{}, allActive("AAPL"))
+	if _, err := uc.GetCandlesAsOf(context.Background(), "AAPL", "1day", 10, time.Now()); err == nil {
+		t.Error("expected error for repository without FindAsOf")
+	}
+}
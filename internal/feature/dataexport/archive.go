@@ -0,0 +1,75 @@
+package dataexport
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ArchiveFormatVersion は manifest.json に記録するアーカイブ形式のバージョンです。
+// セクションの削除やフィールドの意味変更など、互換性のない変更をしたときに上げます。
+const ArchiveFormatVersion = 1
+
+// Section はアーカイブに含める 1 ファイル（<Name>.json）分のデータ源です。
+//
+// Write は userID のデータだけを w へ直接書き込みます。他ユーザーのデータが混入しないよう、
+// 実装はすべての問い合わせを userID で絞り込む必要があります（引数以外にユーザーを知る手段を持たせない）。
+// 件数の多いセクションは全件をメモリに載せず、逐次エンコードしてください。
+type Section interface {
+	Name() string
+	Write(ctx context.Context, userID int64, w io.Writer) error
+}
+
+// manifest はアーカイブ先頭に置く目次です。
+type manifest struct {
+	FormatVersion int       `json:"format_version"`
+	UserID        int64     `json:"user_id"`
+	GeneratedAt   time.Time `json:"generated_at"`
+	Files         []string  `json:"files"`
+}
+
+// WriteArchive は manifest.json と各セクションの <Name>.json を含む ZIP を w へ書き込みます。
+// エントリは順に圧縮しながら書き出すため、アーカイブ全体をメモリに保持しません。
+func WriteArchive(ctx context.Context, w io.Writer, userID int64, sections []Section, generatedAt time.Time) error {
+	zw := zip.NewWriter(w)
+
+	m := manifest{
+		FormatVersion: ArchiveFormatVersion,
+		UserID:        userID,
+		GeneratedAt:   generatedAt.UTC(),
+		Files:         make([]string, 0, len(sections)),
+	}
+	for _, s := range sections {
+		m.Files = append(m.Files, s.Name()+".json")
+	}
+	if err := writeEntry(zw, "manifest.json", generatedAt, func(ew io.Writer) error {
+		enc := json.NewEncoder(ew)
+		enc.SetIndent("", "  ")
+		return enc.Encode(m)
+	}); err != nil {
+		return err
+	}
+
+	for _, s := range sections {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := writeEntry(zw, s.Name()+".json", generatedAt, func(ew io.Writer) error {
+			return s.Write(ctx, userID, ew)
+		}); err != nil {
+			return fmt.Errorf("section %s: %w", s.Name(), err)
+		}
+	}
+	return zw.Close()
+}
+
+func writeEntry(zw *zip.Writer, name string, modified time.Time, write func(io.Writer) error) error {
+	ew, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+	return write(ew)
+}
@@ -0,0 +1,96 @@
+package dataexport
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubSection は userID を埋め込んだ JSON を書き込むテスト用セクションです。
+type stubSection struct {
+	name    string
+	err     error
+	started chan struct{} // 非 nil なら Write 開始を通知する
+	release chan struct{} // 非 nil なら close されるまで Write をブロックする
+}
+
+func (s *stubSection) Name() string { return s.name }
+
+func (s *stubSection) Write(ctx context.Context, userID int64, w io.Writer) error {
+	if s.started != nil {
+		s.started <- struct{}{}
+	}
+	if s.release != nil {
+		select {
+		case <-s.release:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if s.err != nil {
+		return s.err
+	}
+	return json.NewEncoder(w).Encode(map[string]any{"section": s.name, "user_id": userID})
+}
+
+// readArchive は ZIP をファイル名 → 内容のマップとエントリ順に展開します。
+func readArchive(t *testing.T, b []byte) (map[string][]byte, []string) {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	require.NoError(t, err)
+	files := make(map[string][]byte, len(zr.File))
+	order := make([]string, 0, len(zr.File))
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		files[f.Name] = content
+		order = append(order, f.Name)
+	}
+	return files, order
+}
+
+func TestWriteArchive_Structure(t *testing.T) {
+	t.Parallel()
+
+	generatedAt := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	sections := []Section{&stubSection{name: "profile"}, &stubSection{name: "watchlist"}}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteArchive(context.Background(), &buf, 42, sections, generatedAt))
+
+	files, order := readArchive(t, buf.Bytes())
+	assert.Equal(t, []string{"manifest.json", "profile.json", "watchlist.json"}, order, "manifest が先頭、セクションは指定順")
+
+	var m manifest
+	require.NoError(t, json.Unmarshal(files["manifest.json"], &m))
+	assert.Equal(t, manifest{
+		FormatVersion: ArchiveFormatVersion,
+		UserID:        42,
+		GeneratedAt:   generatedAt,
+		Files:         []string{"profile.json", "watchlist.json"},
+	}, m)
+
+	assert.JSONEq(t, `{"section":"watchlist","user_id":42}`, string(files["watchlist.json"]))
+}
+
+func TestWriteArchive_SectionError(t *testing.T) {
+	t.Parallel()
+
+	boom := errors.New("db down")
+	sections := []Section{&stubSection{name: "profile"}, &stubSection{name: "watchlist", err: boom}}
+
+	err := WriteArchive(context.Background(), io.Discard, 1, sections, time.Now())
+	require.ErrorIs(t, err, boom)
+	assert.Contains(t, err.Error(), "section watchlist")
+}
@@ -0,0 +1,130 @@
+package dataexporthttp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/dataexport"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+)
+
+// basePath はエクスポート API のパスです。Location ヘッダーとダウンロードURLの組み立てに使用します。
+const basePath = "/v1/me/export"
+
+// Usecase はデータエクスポートのユースケースインターフェースを定義します。
+type Usecase interface {
+	Start(ctx context.Context, userID int64) (dataexport.Job, error)
+	Get(ctx context.Context, userID int64, jobID string) (dataexport.Job, error)
+	Link(job dataexport.Job) (dataexport.DownloadLink, bool)
+	Open(ctx context.Context, jobID string, expires int64, signature string) (io.ReadCloser, dataexport.Job, error)
+}
+
+// Handler はデータエクスポートに関連するHTTPリクエストを処理します。
+type Handler struct {
+	uc Usecase
+}
+
+// NewHandler はHandlerの新しいインスタンスを生成します。
+func NewHandler(uc Usecase) *Handler {
+	return &Handler{uc: uc}
+}
+
+// Start はログインユーザーのエクスポートジョブを開始し、202 で受け付け内容を返します。
+func (h *Handler) Start(w http.ResponseWriter, r *http.Request) {
+	userID, ok := jwt.UserIDFromContext(r.Context())
+	if !ok {
+		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
+		return
+	}
+
+	job, err := h.uc.Start(r.Context(), userID)
+	if err != nil {
+		httpx.WriteError(w, err, "failed to start data export", "userID", userID)
+		return
+	}
+
+	w.Header().Set("Location", basePath+"/"+url.PathEscape(job.ID))
+	httpx.WriteJSON(w, http.StatusAccepted, h.toExportJob(job))
+}
+
+// Get はログインユーザーのエクスポートジョブの状態を返します。
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, ok := jwt.UserIDFromContext(r.Context())
+	if !ok {
+		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
+		return
+	}
+
+	job, err := h.uc.Get(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		httpx.WriteError(w, err, "failed to get data export", "userID", userID)
+		return
+	}
+
+	// downloadUrl は署名付きのため、中間キャッシュに残さない
+	w.Header().Set("Cache-Control", "no-store")
+	httpx.WriteJSON(w, http.StatusOK, h.toExportJob(job))
+}
+
+// Download は署名付きリンクを検証してアーカイブを返します。認証は署名で行うため JWT を要求しません。
+func (h *Handler) Download(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "id")
+	q := r.URL.Query()
+	expires, err := strconv.ParseInt(q.Get("expires"), 10, 64)
+	if err != nil {
+		httpx.WriteError(w, dataexport.ErrInvalidLink, "invalid export link", "jobID", jobID)
+		return
+	}
+
+	rc, job, err := h.uc.Open(r.Context(), jobID, expires, q.Get("sig"))
+	if err != nil {
+		httpx.WriteError(w, err, "failed to open data export", "jobID", jobID)
+		return
+	}
+	defer func() {
+		if err := rc.Close(); err != nil {
+			slog.Warn("failed to close export archive", "error", err, "jobID", jobID)
+		}
+	}()
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="stock-export-%s.zip"`, job.CreatedAt.UTC().Format("20060102-150405")))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, rc); err != nil {
+		// ヘッダー送信後のためステータスは変更できない。リンクは使用済みとなり、再取得は新しいジョブで行う。
+		slog.Warn("export download interrupted", "error", err, "jobID", jobID, "userID", job.UserID)
+	}
+}
+
+func (h *Handler) toExportJob(job dataexport.Job) api.ExportJob {
+	out := api.ExportJob{
+		Id:          job.ID,
+		Status:      string(job.Status),
+		CreatedAt:   api.NewTimestamp(job.CreatedAt),
+		CompletedAt: api.NewTimestamp(job.CompletedAt),
+		ExpiresAt:   api.NewTimestamp(job.ExpiresAt),
+	}
+	if link, ok := h.uc.Link(job); ok {
+		u := downloadURL(link)
+		out.DownloadUrl = &u
+	}
+	return out
+}
+
+// downloadURL は署名付きダウンロードURL（相対パス）を組み立てます。
+func downloadURL(link dataexport.DownloadLink) string {
+	q := url.Values{}
+	q.Set("expires", strconv.FormatInt(link.Expires.Unix(), 10))
+	q.Set("sig", link.Signature)
+	return basePath + "/" + url.PathEscape(link.JobID) + "/download?" + q.Encode()
+}
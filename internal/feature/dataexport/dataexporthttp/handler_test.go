@@ -0,0 +1,179 @@
+package dataexporthttp_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/dataexport"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/dataexport/dataexporthttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+)
+
+const testUserID int64 = 1
+
+var testCreatedAt = time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+// mockUsecase は Usecase インターフェースのモック実装です。
+type mockUsecase struct {
+	StartFunc func(ctx context.Context, userID int64) (dataexport.Job, error)
+	GetFunc   func(ctx context.Context, userID int64, jobID string) (dataexport.Job, error)
+	OpenFunc  func(ctx context.Context, jobID string, expires int64, signature string) (io.ReadCloser, dataexport.Job, error)
+}
+
+func (m *mockUsecase) Start(ctx context.Context, userID int64) (dataexport.Job, error) {
+	return m.StartFunc(ctx, userID)
+}
+
+func (m *mockUsecase) Get(ctx context.Context, userID int64, jobID string) (dataexport.Job, error) {
+	return m.GetFunc(ctx, userID, jobID)
+}
+
+func (m *mockUsecase) Link(job dataexport.Job) (dataexport.DownloadLink, bool) {
+	if job.Status != dataexport.StatusReady {
+		return dataexport.DownloadLink{}, false
+	}
+	return dataexport.DownloadLink{JobID: job.ID, Expires: job.ExpiresAt, Signature: "sig-" + job.ID}, true
+}
+
+func (m *mockUsecase) Open(ctx context.Context, jobID string, expires int64, signature string) (io.ReadCloser, dataexport.Job, error) {
+	return m.OpenFunc(ctx, jobID, expires, signature)
+}
+
+// newRouter は本番と同じパスでハンドラーを登録し、エクスポート API 側のみ認証済みユーザーを注入します。
+func newRouter(uc dataexporthttp.Usecase) http.Handler {
+	h := dataexporthttp.NewHandler(uc)
+	r := chi.NewRouter()
+	r.Group(func(r chi.Router) {
+		r.Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				next.ServeHTTP(w, req.WithContext(jwt.WithUserID(req.Context(), testUserID)))
+			})
+		})
+		r.Post("/v1/me/export", h.Start)
+		r.Get("/v1/me/export/{id}", h.Get)
+	})
+	r.Get("/v1/me/export/{id}/download", h.Download)
+	return r
+}
+
+func decodeJob(t *testing.T, w *httptest.ResponseRecorder) api.ExportJob {
+	t.Helper()
+	var out api.ExportJob
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &out))
+	return out
+}
+
+func TestExportHandler_Start(t *testing.T) {
+	t.Parallel()
+
+	t.Run("受け付けると 202 と Location を返す", func(t *testing.T) {
+		t.Parallel()
+		uc := &mockUsecase{StartFunc: func(_ context.Context, userID int64) (dataexport.Job, error) {
+			assert.Equal(t, testUserID, userID)
+			return dataexport.Job{ID: "JOB1", UserID: userID, Status: dataexport.StatusPending, CreatedAt: testCreatedAt}, nil
+		}}
+		w := httptest.NewRecorder()
+		newRouter(uc).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/me/export", nil))
+
+		require.Equal(t, http.StatusAccepted, w.Code)
+		assert.Equal(t, "/v1/me/export/JOB1", w.Header().Get("Location"))
+		assert.NotContains(t, w.Body.String(), "completedAt", "未完了のジョブは完了日時を省略する")
+		assert.Equal(t, api.ExportJob{Id: "JOB1", Status: "pending", CreatedAt: api.NewTimestamp(testCreatedAt)}, decodeJob(t, w))
+	})
+
+	t.Run("生成中なら 409", func(t *testing.T) {
+		t.Parallel()
+		uc := &mockUsecase{StartFunc: func(context.Context, int64) (dataexport.Job, error) {
+			return dataexport.Job{}, dataexport.ErrExportInProgress
+		}}
+		w := httptest.NewRecorder()
+		newRouter(uc).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/me/export", nil))
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.JSONEq(t, `{"error":"export_in_progress"}`, w.Body.String())
+	})
+}
+
+func TestExportHandler_Get(t *testing.T) {
+	t.Parallel()
+
+	completedAt := testCreatedAt.Add(time.Minute)
+	expiresAt := completedAt.Add(dataexport.DefaultLinkTTL)
+	uc := &mockUsecase{GetFunc: func(_ context.Context, userID int64, jobID string) (dataexport.Job, error) {
+		if jobID != "JOB1" || userID != testUserID {
+			return dataexport.Job{}, dataexport.ErrJobNotFound
+		}
+		return dataexport.Job{ID: "JOB1", UserID: userID, Status: dataexport.StatusReady, CreatedAt: testCreatedAt, CompletedAt: completedAt, ExpiresAt: expiresAt}, nil
+	}}
+	router := newRouter(uc)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/me/export/JOB1", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+	got := decodeJob(t, w)
+	assert.Equal(t, "ready", got.Status)
+	assert.Equal(t, api.NewTimestamp(completedAt), got.CompletedAt)
+	assert.Equal(t, api.NewTimestamp(expiresAt), got.ExpiresAt)
+	require.NotNil(t, got.DownloadUrl)
+	link, err := url.Parse(*got.DownloadUrl)
+	require.NoError(t, err)
+	assert.Equal(t, "/v1/me/export/JOB1/download", link.Path)
+	assert.Equal(t, "sig-JOB1", link.Query().Get("sig"))
+	assert.Equal(t, "1790846160", link.Query().Get("expires"))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/me/export/OTHER", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error":"export_not_found"}`, w.Body.String())
+}
+
+func TestExportHandler_Download(t *testing.T) {
+	t.Parallel()
+
+	uc := &mockUsecase{OpenFunc: func(_ context.Context, jobID string, expires int64, signature string) (io.ReadCloser, dataexport.Job, error) {
+		if jobID != "JOB1" || expires != 1790846160 || signature != "good" {
+			return nil, dataexport.Job{}, dataexport.ErrInvalidLink
+		}
+		return io.NopCloser(strings.NewReader("PK-archive")), dataexport.Job{ID: jobID, UserID: testUserID, CreatedAt: testCreatedAt}, nil
+	}}
+	router := newRouter(uc)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "正しい署名ならアーカイブを返す", query: "expires=1790846160&sig=good", wantStatus: http.StatusOK, wantBody: "PK-archive"},
+		{name: "署名不一致は 401", query: "expires=1790846160&sig=bad", wantStatus: http.StatusUnauthorized, wantBody: `{"error":"export_link_invalid"}`},
+		{name: "expires 不正は 401", query: "expires=soon&sig=good", wantStatus: http.StatusUnauthorized, wantBody: `{"error":"export_link_invalid"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/me/export/JOB1/download?"+tt.query, nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantBody, strings.TrimSpace(w.Body.String()))
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+				assert.Equal(t, `attachment; filename="stock-export-20261001-090000.zip"`, w.Header().Get("Content-Disposition"))
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package dataexport
+
+import "github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
+
+var (
+	// ErrJobNotFound はジョブが存在しない、または他ユーザーのジョブである場合のエラーです。
+	// 他ユーザーのジョブの存在を推測させないため、両者を区別しません。
+	ErrJobNotFound = apperr.New(apperr.KindNotFound, "export_not_found", "export job not found")
+
+	// ErrExportInProgress は同じユーザーのエクスポートが既に生成中の場合のエラーです。
+	ErrExportInProgress = apperr.New(apperr.KindConflict, "export_in_progress", "export already in progress")
+
+	// ErrInvalidLink はダウンロードリンクの署名不正・期限切れ・使用済みの場合のエラーです。
+	ErrInvalidLink = apperr.New(apperr.KindUnauthorized, "export_link_invalid", "export download link is invalid or expired")
+)
@@ -0,0 +1,51 @@
+package dataexport
+
+import "time"
+
+// Status はエクスポートジョブの状態です。
+type Status string
+
+const (
+	// StatusPending は受け付け済みでワーカーの開始待ちの状態です。
+	StatusPending Status = "pending"
+	// StatusRunning はアーカイブを生成中の状態です。
+	StatusRunning Status = "running"
+	// StatusReady はアーカイブが完成し、ダウンロード可能な状態です。
+	StatusReady Status = "ready"
+	// StatusDownloaded はダウンロード済み（アーカイブ削除済み）の状態です。リンクは一度しか使えません。
+	StatusDownloaded Status = "downloaded"
+	// StatusExpired はダウンロードされないまま保管期限を過ぎた状態です。
+	StatusExpired Status = "expired"
+	// StatusFailed は生成に失敗した状態です。
+	StatusFailed Status = "failed"
+)
+
+// Job はユーザー 1 人分のデータエクスポート要求を表します。
+type Job struct {
+	// ID はジョブの一意な識別子です（推測困難なランダム文字列）。
+	ID string
+
+	// UserID はエクスポート対象（かつ要求者）のユーザーIDです。
+	UserID int64
+
+	Status Status
+
+	// CreatedAt はジョブを受け付けた日時です。
+	CreatedAt time.Time
+
+	// CompletedAt は生成が完了（成功・失敗とも）した日時です。未完了ならゼロ値です。
+	CompletedAt time.Time
+
+	// ExpiresAt はアーカイブとダウンロードリンクの有効期限です。StatusReady 以降でのみ設定されます。
+	ExpiresAt time.Time
+}
+
+// active は生成が終わっていない（ユーザーごとの同時実行枠を占有している）かを返します。
+func (j Job) active() bool {
+	return j.Status == StatusPending || j.Status == StatusRunning
+}
+
+// blobKey はアーカイブの保存キーです。
+func (j Job) blobKey() string {
+	return j.ID + ".zip"
+}
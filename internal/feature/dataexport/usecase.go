@@ -0,0 +1,293 @@
+package dataexport
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultLinkTTL はアーカイブ完成からダウンロードリンク（とアーカイブ本体）が失効するまでの時間です。
+	DefaultLinkTTL = 15 * time.Minute
+
+	// DefaultJobTimeout は 1 件のアーカイブ生成にかける時間の上限です。
+	DefaultJobTimeout = 5 * time.Minute
+
+	// DefaultCleanupInterval は期限切れアーカイブを削除する間隔です。
+	DefaultCleanupInterval = time.Minute
+
+	// jobRetention は終了したジョブの状態を照会可能なまま保持する期間です。
+	// 経過後はジョブ自体を忘れ、照会は ErrJobNotFound になります。
+	jobRetention = 24 * time.Hour
+)
+
+// BlobStore は生成したアーカイブの一時保管先を抽象化します。
+// 実装は key をそのままファイル名等に使えるよう、英数字と . _ - のみを受け付ければ十分です。
+type BlobStore interface {
+	// Create は key への書き込みを開始します。Close で書き込みを確定します。
+	Create(ctx context.Context, key string) (io.WriteCloser, error)
+	// Open は key の内容を読み出します。
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete は key を削除します。存在しない場合もエラーにしません。
+	Delete(ctx context.Context, key string) error
+}
+
+// DownloadLink は署名付きダウンロードリンクの構成要素です。URL への組み立ては HTTP 層が行います。
+type DownloadLink struct {
+	JobID     string
+	Expires   time.Time
+	Signature string
+}
+
+// usecase はユーザーデータのエクスポート（データポータビリティ）を提供します。
+//
+// ジョブの状態はプロセス内に保持します。アーカイブの保管先（BlobStore）がローカルディスクである前提のため、
+// 複数インスタンス構成では照会・ダウンロードが生成したインスタンスに届く必要があります。
+type usecase struct {
+	blobs      BlobStore
+	sections   []Section
+	signer     signer
+	linkTTL    time.Duration
+	jobTimeout time.Duration
+	now        func() time.Time
+
+	mu     sync.Mutex
+	jobs   map[string]*Job
+	active map[int64]string // userID → 生成中のジョブID（ユーザーごとの同時実行数 1 の管理）
+	stored map[string]bool  // アーカイブが保管先に残っているジョブID（Cleanup の削除対象の候補）
+
+	// base はワーカーの親 context です。Close でキャンセルされます。
+	base   context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewUsecase は usecase の新しいインスタンスを生成します。
+// sections はアーカイブに含める順に並べます。secret はダウンロードリンクの署名に使用します。
+func NewUsecase(blobs BlobStore, sections []Section, secret string) *usecase {
+	base, cancel := context.WithCancel(context.Background())
+	return &usecase{
+		blobs:      blobs,
+		sections:   sections,
+		signer:     newSigner(secret),
+		linkTTL:    DefaultLinkTTL,
+		jobTimeout: DefaultJobTimeout,
+		now:        time.Now,
+		jobs:       make(map[string]*Job),
+		active:     make(map[int64]string),
+		stored:     make(map[string]bool),
+		base:       base,
+		cancel:     cancel,
+	}
+}
+
+// Start はエクスポートジョブを受け付け、バックグラウンドでアーカイブの生成を開始します。
+// 同じユーザーのジョブが生成中の場合は ErrExportInProgress を返します。
+func (u *usecase) Start(ctx context.Context, userID int64) (Job, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.base.Err() != nil {
+		return Job{}, errors.New("export usecase is closed")
+	}
+	if _, busy := u.active[userID]; busy {
+		return Job{}, ErrExportInProgress
+	}
+
+	job := &Job{ID: rand.Text(), UserID: userID, Status: StatusPending, CreatedAt: u.now()}
+	u.jobs[job.ID] = job
+	u.active[userID] = job.ID
+
+	u.wg.Add(1)
+	go u.run(*job)
+
+	slog.InfoContext(ctx, "data export started", "job_id", job.ID, "user_id", userID)
+	return *job, nil
+}
+
+// Get は userID のジョブを返します。存在しない・他ユーザーのジョブの場合は ErrJobNotFound を返します。
+func (u *usecase) Get(ctx context.Context, userID int64, jobID string) (Job, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	job, ok := u.jobs[jobID]
+	if !ok || job.UserID != userID {
+		return Job{}, ErrJobNotFound
+	}
+	u.expireLocked(job)
+	return *job, nil
+}
+
+// Link は StatusReady のジョブに対する署名付きダウンロードリンクを返します。
+// それ以外の状態では ok=false です。リンクの有効期限はアーカイブの有効期限と同じです。
+func (u *usecase) Link(job Job) (DownloadLink, bool) {
+	if job.Status != StatusReady {
+		return DownloadLink{}, false
+	}
+	return DownloadLink{
+		JobID:     job.ID,
+		Expires:   job.ExpiresAt,
+		Signature: u.signer.sign(job.ID, job.ExpiresAt),
+	}, true
+}
+
+// Open は署名付きリンクを検証し、アーカイブを返します。リンクは一度しか使えません。
+// 返した ReadCloser の Close でアーカイブを削除します。
+// 署名不正・期限切れ・使用済みの場合は ErrInvalidLink を返します。
+func (u *usecase) Open(ctx context.Context, jobID string, expires int64, signature string) (io.ReadCloser, Job, error) {
+	if !u.signer.verify(jobID, expires, signature, u.now()) {
+		return nil, Job{}, ErrInvalidLink
+	}
+
+	u.mu.Lock()
+	job, ok := u.jobs[jobID]
+	if ok {
+		u.expireLocked(job)
+	}
+	if !ok || job.Status != StatusReady {
+		u.mu.Unlock()
+		return nil, Job{}, ErrInvalidLink
+	}
+	// 読み出しに失敗しても再利用させない（再取得は新しいジョブで行う）
+	job.Status = StatusDownloaded
+	delete(u.stored, job.ID)
+	snapshot := *job
+	u.mu.Unlock()
+
+	rc, err := u.blobs.Open(ctx, snapshot.blobKey())
+	if err != nil {
+		return nil, Job{}, fmt.Errorf("open export archive: %w", err)
+	}
+	slog.InfoContext(ctx, "data export downloaded", "job_id", snapshot.ID, "user_id", snapshot.UserID)
+	return &deleteOnClose{ReadCloser: rc, blobs: u.blobs, key: snapshot.blobKey()}, snapshot, nil
+}
+
+// RunCleanup は ctx がキャンセルされるまで interval ごとに Cleanup を実行します。
+func (u *usecase) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			u.Cleanup(ctx)
+		}
+	}
+}
+
+// Cleanup は期限切れのアーカイブを削除し、保持期間を過ぎたジョブを忘れます。削除したアーカイブ数を返します。
+func (u *usecase) Cleanup(ctx context.Context) int {
+	now := u.now()
+	var stale []string
+
+	u.mu.Lock()
+	for id := range u.stored {
+		job := u.jobs[id]
+		u.expireLocked(job)
+		if job.Status == StatusExpired {
+			stale = append(stale, job.blobKey())
+			delete(u.stored, id)
+		}
+	}
+	for id, job := range u.jobs {
+		if !job.active() && !u.stored[id] && !job.CompletedAt.IsZero() && now.Sub(job.CompletedAt) >= jobRetention {
+			delete(u.jobs, id)
+		}
+	}
+	u.mu.Unlock()
+
+	for _, key := range stale {
+		if err := u.blobs.Delete(ctx, key); err != nil {
+			slog.WarnContext(ctx, "failed to delete expired export archive", "key", key, "error", err)
+		}
+	}
+	return len(stale)
+}
+
+// Close は生成中のジョブをキャンセルし、ワーカーの終了を待ちます。
+func (u *usecase) Close() {
+	u.mu.Lock()
+	u.cancel()
+	u.mu.Unlock()
+	u.wg.Wait()
+}
+
+// expireLocked は有効期限を過ぎた StatusReady のジョブを StatusExpired にします。
+// アーカイブの削除は Cleanup が行います。u.mu を保持して呼び出します。
+func (u *usecase) expireLocked(job *Job) {
+	if job.Status == StatusReady && !u.now().Before(job.ExpiresAt) {
+		job.Status = StatusExpired
+	}
+}
+
+// run はワーカーとして 1 件のアーカイブを生成し、結果をジョブに反映します。
+func (u *usecase) run(job Job) {
+	defer u.wg.Done()
+
+	ctx, cancel := context.WithTimeout(u.base, u.jobTimeout)
+	defer cancel()
+
+	u.update(job.ID, func(j *Job) { j.Status = StatusRunning })
+	err := u.build(ctx, job)
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	delete(u.active, job.UserID)
+	j := u.jobs[job.ID]
+	j.CompletedAt = u.now()
+	if err != nil {
+		j.Status = StatusFailed
+		slog.Error("data export failed", "job_id", job.ID, "user_id", job.UserID, "error", err)
+		return
+	}
+	j.Status = StatusReady
+	j.ExpiresAt = j.CompletedAt.Add(u.linkTTL)
+	u.stored[job.ID] = true
+	slog.Info("data export ready", "job_id", job.ID, "user_id", job.UserID)
+}
+
+// build はアーカイブを BlobStore へ書き出します。失敗時は書きかけのアーカイブを削除します。
+func (u *usecase) build(ctx context.Context, job Job) error {
+	w, err := u.blobs.Create(ctx, job.blobKey())
+	if err != nil {
+		return fmt.Errorf("create archive: %w", err)
+	}
+	writeErr := WriteArchive(ctx, w, job.UserID, u.sections, u.now())
+	closeErr := w.Close()
+	if err := errors.Join(writeErr, closeErr); err != nil {
+		if delErr := u.blobs.Delete(context.WithoutCancel(ctx), job.blobKey()); delErr != nil {
+			slog.Warn("failed to delete partial export archive", "job_id", job.ID, "error", delErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (u *usecase) update(jobID string, fn func(*Job)) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if j, ok := u.jobs[jobID]; ok {
+		fn(j)
+	}
+}
+
+// deleteOnClose は読み出し終了時にアーカイブを削除する ReadCloser です。
+type deleteOnClose struct {
+	io.ReadCloser
+	blobs BlobStore
+	key   string
+}
+
+func (d *deleteOnClose) Close() error {
+	err := d.ReadCloser.Close()
+	if delErr := d.blobs.Delete(context.Background(), d.key); delErr != nil {
+		slog.Warn("failed to delete downloaded export archive", "key", d.key, "error", delErr)
+	}
+	return err
+}
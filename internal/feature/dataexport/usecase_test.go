@@ -0,0 +1,287 @@
+package dataexport
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
+)
+
+// memBlobStore はメモリ上に保存するテスト用 BlobStore です。
+type memBlobStore struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+func newMemBlobStore() *memBlobStore { return &memBlobStore{blobs: map[string][]byte{}} }
+
+type memWriter struct {
+	bytes.Buffer
+	store *memBlobStore
+	key   string
+}
+
+func (w *memWriter) Close() error {
+	w.store.mu.Lock()
+	defer w.store.mu.Unlock()
+	w.store.blobs[w.key] = w.Bytes()
+	return nil
+}
+
+func (s *memBlobStore) Create(_ context.Context, key string) (io.WriteCloser, error) {
+	return &memWriter{store: s, key: key}, nil
+}
+
+func (s *memBlobStore) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.blobs[key]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return io.NopCloser(bytes.NewReader(b)), nil
+}
+
+func (s *memBlobStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.blobs, key)
+	return nil
+}
+
+func (s *memBlobStore) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.blobs)
+}
+
+// fakeClock はテストから進められる時計です。
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestUsecase(t *testing.T, sections ...Section) (*usecase, *memBlobStore, *fakeClock) {
+	t.Helper()
+	blobs := newMemBlobStore()
+	clock := &fakeClock{now: time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)}
+	uc := NewUsecase(blobs, sections, "test-secret")
+	uc.now = clock.Now
+	t.Cleanup(uc.Close)
+	return uc, blobs, clock
+}
+
+// waitFinished はジョブが生成中でなくなるまで待ち、最終状態を返します。
+func waitFinished(t *testing.T, uc *usecase, userID int64, jobID string) Job {
+	t.Helper()
+	var job Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = uc.Get(context.Background(), userID, jobID)
+		require.NoError(t, err)
+		return !job.active()
+	}, 2*time.Second, 5*time.Millisecond)
+	return job
+}
+
+func download(t *testing.T, uc *usecase, link DownloadLink) ([]byte, error) {
+	t.Helper()
+	rc, _, err := uc.Open(context.Background(), link.JobID, link.Expires.Unix(), link.Signature)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rc.Close() }()
+	return io.ReadAll(rc)
+}
+
+func TestUsecase_Lifecycle(t *testing.T) {
+	t.Parallel()
+
+	uc, blobs, clock := newTestUsecase(t, &stubSection{name: "profile"})
+	ctx := context.Background()
+
+	job, err := uc.Start(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, job.Status)
+	assert.NotEmpty(t, job.ID)
+
+	job = waitFinished(t, uc, 7, job.ID)
+	require.Equal(t, StatusReady, job.Status)
+	assert.Equal(t, clock.Now().Add(DefaultLinkTTL), job.ExpiresAt)
+
+	link, ok := uc.Link(job)
+	require.True(t, ok)
+	body, err := download(t, uc, link)
+	require.NoError(t, err)
+	files, _ := readArchive(t, body)
+	assert.JSONEq(t, `{"section":"profile","user_id":7}`, string(files["profile.json"]))
+
+	// 一度使ったリンクは無効になり、アーカイブも削除される
+	_, err = download(t, uc, link)
+	assert.ErrorIs(t, err, ErrInvalidLink)
+	assert.Zero(t, blobs.len())
+
+	job, err = uc.Get(ctx, 7, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDownloaded, job.Status)
+	_, ok = uc.Link(job)
+	assert.False(t, ok)
+}
+
+func TestUsecase_OtherUsersJobIsNotFound(t *testing.T) {
+	t.Parallel()
+
+	uc, _, _ := newTestUsecase(t, &stubSection{name: "profile"})
+	ctx := context.Background()
+
+	job, err := uc.Start(ctx, 7)
+	require.NoError(t, err)
+
+	_, err = uc.Get(ctx, 8, job.ID)
+	assert.ErrorIs(t, err, ErrJobNotFound)
+	assert.Equal(t, apperr.KindNotFound, apperr.KindOf(err))
+	_, err = uc.Get(ctx, 7, "missing")
+	assert.ErrorIs(t, err, ErrJobNotFound)
+}
+
+func TestUsecase_PerUserConcurrencyLimit(t *testing.T) {
+	t.Parallel()
+
+	section := &stubSection{name: "profile", started: make(chan struct{}, 2), release: make(chan struct{})}
+	uc, _, _ := newTestUsecase(t, section)
+	ctx := context.Background()
+
+	first, err := uc.Start(ctx, 7)
+	require.NoError(t, err)
+	<-section.started
+
+	_, err = uc.Start(ctx, 7)
+	assert.ErrorIs(t, err, ErrExportInProgress)
+	assert.Equal(t, apperr.KindConflict, apperr.KindOf(err))
+
+	// 他ユーザーは制限を受けない
+	other, err := uc.Start(ctx, 8)
+	require.NoError(t, err)
+	<-section.started
+
+	close(section.release)
+	assert.Equal(t, StatusReady, waitFinished(t, uc, 7, first.ID).Status)
+	assert.Equal(t, StatusReady, waitFinished(t, uc, 8, other.ID).Status)
+
+	// 完了後は再度受け付ける
+	_, err = uc.Start(ctx, 7)
+	assert.NoError(t, err)
+}
+
+func TestUsecase_FailedJobLeavesNoArchive(t *testing.T) {
+	t.Parallel()
+
+	uc, blobs, _ := newTestUsecase(t, &stubSection{name: "profile"}, &stubSection{name: "watchlist", err: errors.New("db down")})
+
+	job, err := uc.Start(context.Background(), 7)
+	require.NoError(t, err)
+
+	job = waitFinished(t, uc, 7, job.ID)
+	assert.Equal(t, StatusFailed, job.Status)
+	assert.Zero(t, blobs.len())
+
+	_, err = uc.Start(context.Background(), 7)
+	assert.NoError(t, err, "失敗後は同時実行枠が解放される")
+}
+
+func TestUsecase_LinkExpiry(t *testing.T) {
+	t.Parallel()
+
+	uc, blobs, clock := newTestUsecase(t, &stubSection{name: "profile"})
+	ctx := context.Background()
+
+	job, err := uc.Start(ctx, 7)
+	require.NoError(t, err)
+	job = waitFinished(t, uc, 7, job.ID)
+	link, ok := uc.Link(job)
+	require.True(t, ok)
+
+	t.Run("改ざんされたリンク", func(t *testing.T) {
+		_, _, err := uc.Open(ctx, link.JobID, link.Expires.Add(time.Hour).Unix(), link.Signature)
+		assert.ErrorIs(t, err, ErrInvalidLink)
+		_, _, err = uc.Open(ctx, "other-job", link.Expires.Unix(), link.Signature)
+		assert.ErrorIs(t, err, ErrInvalidLink)
+		_, _, err = uc.Open(ctx, link.JobID, link.Expires.Unix(), "not-base64!")
+		assert.ErrorIs(t, err, ErrInvalidLink)
+	})
+
+	clock.Advance(DefaultLinkTTL - time.Second)
+	assert.Equal(t, 0, uc.Cleanup(ctx), "期限前は削除しない")
+
+	clock.Advance(time.Second)
+	_, err = download(t, uc, link)
+	assert.ErrorIs(t, err, ErrInvalidLink, "期限ちょうどで失効")
+	assert.Equal(t, apperr.KindUnauthorized, apperr.KindOf(err))
+
+	job, err = uc.Get(ctx, 7, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusExpired, job.Status)
+
+	assert.Equal(t, 1, uc.Cleanup(ctx))
+	assert.Zero(t, blobs.len())
+
+	// 保持期間を過ぎるとジョブ自体を忘れる
+	clock.Advance(jobRetention)
+	uc.Cleanup(ctx)
+	_, err = uc.Get(ctx, 7, job.ID)
+	assert.ErrorIs(t, err, ErrJobNotFound)
+}
+
+func TestUsecase_CloseCancelsRunningJobs(t *testing.T) {
+	t.Parallel()
+
+	section := &stubSection{name: "profile", started: make(chan struct{}, 1), release: make(chan struct{})}
+	uc, blobs, _ := newTestUsecase(t, section)
+
+	job, err := uc.Start(context.Background(), 7)
+	require.NoError(t, err)
+	<-section.started
+
+	uc.Close()
+	job, err = uc.Get(context.Background(), 7, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, job.Status)
+	assert.Zero(t, blobs.len())
+
+	_, err = uc.Start(context.Background(), 7)
+	assert.Error(t, err, "Close 後は受け付けない")
+}
+
+func TestSigner_DomainSeparated(t *testing.T) {
+	t.Parallel()
+
+	exp := time.Unix(1_800_000_000, 0)
+	a, b := newSigner("secret"), newSigner("other")
+	sig := a.sign("job", exp)
+
+	assert.True(t, a.verify("job", exp.Unix(), sig, exp.Add(-time.Second)))
+	assert.False(t, b.verify("job", exp.Unix(), sig, exp.Add(-time.Second)), "異なるシークレット")
+	assert.False(t, a.verify("job", exp.Unix(), sig, exp), "期限ちょうどは無効")
+	assert.NotEqual(t, sig, a.sign("job", exp.Add(time.Second)))
+	assert.NotEqual(t, a.sign("job1", time.Unix(23, 0)), a.sign("job12", time.Unix(3, 0)), "ID と期限の境界が曖昧にならない")
+}
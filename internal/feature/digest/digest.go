@@ -0,0 +1,98 @@
+// Package digest はウォッチリストの銘柄の日次の値動きをまとめたダイジェストメールを提供します。
+// 購読（オプトイン）したユーザーにだけ、batch digest が夜間の取り込み後に 1 日 1 通まで送ります。
+package digest
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Bar は前日比の計算に使う日足です。Time は銘柄の市場のタイムゾーンで表した日足の開始時刻で、
+// 日付（Time.Date）をそのまま市場のローカル日付として扱います（ADR-0005）。
+type Bar struct {
+	Time  time.Time
+	Close float64
+}
+
+// SymbolChange は 1 銘柄の前日比です。
+type SymbolChange struct {
+	Symbol    string
+	Close     float64 // 対象日の終値
+	PrevClose float64 // 直前の日足の終値
+	ChangePct float64 // (Close - PrevClose) / PrevClose * 100
+}
+
+// Digest は 1 ユーザーに送るダイジェストの内容です。
+// Date は対象の日足の日付（市場のローカル日付を UTC の 0 時で表したもの）、Changes はウォッチリストの並び順です。
+type Digest struct {
+	Date    time.Time
+	Changes []SymbolChange
+}
+
+// Compute はウォッチリストの銘柄 symbols と銘柄ごとの日足 bars からダイジェストを計算します。
+//
+// 銘柄ごとに最新の 2 本の日足から前日比を求め、最新の日足の日付のうち最も新しい日を対象日とします。
+// 対象日の日足がない銘柄（休場・取り込み遅れ・上場廃止）と、日足が 2 本ない・直前の終値が正でない銘柄は含めません。
+// 含める銘柄がない場合は ok=false を返します（メールを送らない）。
+func Compute(symbols []string, bars map[string][]Bar) (d Digest, ok bool) {
+	type latest struct {
+		date time.Time
+		chg  SymbolChange
+	}
+	var (
+		rows []latest
+		day  time.Time
+	)
+	seen := make(map[string]bool, len(symbols))
+	for _, sym := range symbols {
+		if seen[sym] {
+			continue
+		}
+		seen[sym] = true
+		cur, prev, found := lastTwo(bars[sym])
+		if !found || prev.Close <= 0 || !isFinite(cur.Close) || !isFinite(prev.Close) {
+			continue
+		}
+		date := dateOf(cur.Time)
+		rows = append(rows, latest{date: date, chg: SymbolChange{
+			Symbol:    sym,
+			Close:     cur.Close,
+			PrevClose: prev.Close,
+			ChangePct: (cur.Close - prev.Close) / prev.Close * 100,
+		}})
+		if date.After(day) {
+			day = date
+		}
+	}
+	for _, r := range rows {
+		if r.date.Equal(day) {
+			d.Changes = append(d.Changes, r.chg)
+		}
+	}
+	if len(d.Changes) == 0 {
+		return Digest{}, false
+	}
+	d.Date = day
+	return d, true
+}
+
+// lastTwo は bs のうち時刻の新しい 2 本を返します。bs の並び順は問いません。
+func lastTwo(bs []Bar) (cur, prev Bar, ok bool) {
+	if len(bs) < 2 {
+		return Bar{}, Bar{}, false
+	}
+	sorted := append([]Bar(nil), bs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Time.After(sorted[j].Time) })
+	return sorted[0], sorted[1], true
+}
+
+// dateOf は t の（t のタイムゾーンでの）年月日を UTC の 0 時で返します。
+func dateOf(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
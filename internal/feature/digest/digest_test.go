@@ -0,0 +1,105 @@
+package digest
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func day(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+func TestCompute(t *testing.T) {
+	t.Parallel()
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d1, d2, d3 := day(2026, 8, 3), day(2026, 8, 4), day(2026, 8, 5)
+
+	tests := []struct {
+		name    string
+		symbols []string
+		bars    map[string][]Bar
+		want    Digest
+		wantOK  bool
+	}{
+		{
+			name:    "ウォッチリストの並び順で前日比を返す（日足の並び順は問わない）",
+			symbols: []string{"MSFT", "AAPL"},
+			bars: map[string][]Bar{
+				"AAPL": {{Time: d2, Close: 110}, {Time: d1, Close: 100}},
+				"MSFT": {{Time: d1, Close: 400}, {Time: d2, Close: 390}},
+			},
+			want: Digest{Date: d2, Changes: []SymbolChange{
+				{Symbol: "MSFT", Close: 390, PrevClose: 400, ChangePct: -2.5},
+				{Symbol: "AAPL", Close: 110, PrevClose: 100, ChangePct: 10},
+			}},
+			wantOK: true,
+		},
+		{
+			name:    "最新の日足が対象日より古い銘柄（休場・取り込み遅れ）は含めない",
+			symbols: []string{"AAPL", "7203.T"},
+			bars: map[string][]Bar{
+				"AAPL":   {{Time: d3, Close: 105}, {Time: d2, Close: 100}},
+				"7203.T": {{Time: d2, Close: 3000}, {Time: d1, Close: 2900}},
+			},
+			want:   Digest{Date: d3, Changes: []SymbolChange{{Symbol: "AAPL", Close: 105, PrevClose: 100, ChangePct: 5}}},
+			wantOK: true,
+		},
+		{
+			name:    "日付は市場のタイムゾーンで判定する",
+			symbols: []string{"7203.T"},
+			bars: map[string][]Bar{
+				"7203.T": {
+					{Time: time.Date(2026, 8, 5, 0, 0, 0, 0, tokyo), Close: 3030},
+					{Time: time.Date(2026, 8, 4, 0, 0, 0, 0, tokyo), Close: 3000},
+				},
+			},
+			want:   Digest{Date: d3, Changes: []SymbolChange{{Symbol: "7203.T", Close: 3030, PrevClose: 3000, ChangePct: 1}}},
+			wantOK: true,
+		},
+		{
+			name:    "重複した銘柄は 1 回だけ含める",
+			symbols: []string{"AAPL", "AAPL"},
+			bars:    map[string][]Bar{"AAPL": {{Time: d2, Close: 100}, {Time: d1, Close: 100}}},
+			want:    Digest{Date: d2, Changes: []SymbolChange{{Symbol: "AAPL", Close: 100, PrevClose: 100, ChangePct: 0}}},
+			wantOK:  true,
+		},
+		{
+			name:    "日足が 1 本しかない銘柄・直前の終値が正でない銘柄は含めない",
+			symbols: []string{"NEW", "ZERO", "NAN"},
+			bars: map[string][]Bar{
+				"NEW":  {{Time: d2, Close: 10}},
+				"ZERO": {{Time: d2, Close: 10}, {Time: d1, Close: 0}},
+				"NAN":  {{Time: d2, Close: math.NaN()}, {Time: d1, Close: 10}},
+			},
+			wantOK: false,
+		},
+		{
+			name:    "空のウォッチリスト",
+			symbols: nil,
+			wantOK:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := Compute(tt.symbols, tt.bars)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want.Date, got.Date)
+			assert.Len(t, got.Changes, len(tt.want.Changes))
+			for i, want := range tt.want.Changes {
+				if i >= len(got.Changes) {
+					break
+				}
+				assert.Equal(t, want.Symbol, got.Changes[i].Symbol)
+				assert.Equal(t, want.Close, got.Changes[i].Close)
+				assert.Equal(t, want.PrevClose, got.Changes[i].PrevClose)
+				assert.InDelta(t, want.ChangePct, got.Changes[i].ChangePct, 1e-9)
+			}
+		})
+	}
+}
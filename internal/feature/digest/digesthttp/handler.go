@@ -0,0 +1,98 @@
+package digesthttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/digest"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+)
+
+// Usecase はダイジェストメールの購読のユースケースインターフェースを定義します。
+type Usecase interface {
+	Subscription(ctx context.Context, userID int64) (digest.Subscription, error)
+	SetSubscribed(ctx context.Context, userID int64, subscribed bool, ifVersion *int64) (digest.Subscription, error)
+}
+
+// Handler はダイジェストメールの購読に関連するHTTPリクエストを処理します。
+// ユーザーはJWTから取得し、リクエストで他のユーザーを指定する手段は設けません。
+type Handler struct {
+	uc             Usecase
+	requireIfMatch bool
+}
+
+// NewHandler はHandlerの新しいインスタンスを生成します。
+func NewHandler(uc Usecase) *Handler {
+	return &Handler{uc: uc}
+}
+
+// WithRequireIfMatch は購読の変更に If-Match を必須にするかを設定します（必須の場合、ないと 428）。
+// 既定は省略を受け付けます（If-Match を送らない既存のクライアントとの互換のため）。
+func (h *Handler) WithRequireIfMatch(required bool) *Handler {
+	h.requireIfMatch = required
+	return h
+}
+
+// Get はログインユーザーの購読状態を返します。
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, ok := jwt.UserIDFromContext(r.Context())
+	if !ok {
+		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
+		return
+	}
+	sub, err := h.uc.Subscription(r.Context(), userID)
+	if err != nil {
+		httpx.WriteError(w, err, "failed to get digest subscription", "userID", userID)
+		return
+	}
+	w.Header().Set("ETag", httpx.ETag(sub.Version))
+	httpx.WriteJSON(w, http.StatusOK, api.DigestSubscription{Subscribed: sub.Subscribed})
+}
+
+// Update はログインユーザーの購読を設定し、設定後の購読状態を返します。
+// If-Match のバージョンが現在のバージョンと一致しない場合は変更せず、412 で現在の購読状態とバージョンを返します。
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	userID, ok := jwt.UserIDFromContext(r.Context())
+	if !ok {
+		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
+		return
+	}
+	ifVersion, ok := httpx.CheckIfMatch(w, r, h.requireIfMatch)
+	if !ok {
+		return
+	}
+	var req api.UpdateDigestSubscriptionRequest
+	if err := httpx.DecodeAndValidate(r, &req); err != nil {
+		httpx.WriteDecodeError(w, err, "invalid request")
+		return
+	}
+	sub, err := h.uc.SetSubscribed(r.Context(), userID, *req.Subscribed, ifVersion)
+	if errors.Is(err, digest.ErrVersionMismatch) {
+		h.writeConflict(w, r, userID)
+		return
+	}
+	if err != nil {
+		httpx.WriteError(w, err, "failed to update digest subscription", "userID", userID)
+		return
+	}
+	w.Header().Set("ETag", httpx.ETag(sub.Version))
+	httpx.WriteJSON(w, http.StatusOK, api.DigestSubscription{Subscribed: sub.Subscribed})
+}
+
+// writeConflict は 412 で現在の購読状態とバージョンを返します。
+func (h *Handler) writeConflict(w http.ResponseWriter, r *http.Request, userID int64) {
+	sub, err := h.uc.Subscription(r.Context(), userID)
+	if err != nil {
+		httpx.WriteError(w, err, "failed to get digest subscription", "userID", userID)
+		return
+	}
+	w.Header().Set("ETag", httpx.ETag(sub.Version))
+	httpx.WriteJSON(w, http.StatusPreconditionFailed, api.DigestSubscriptionVersionConflict{
+		Error:        digest.ErrVersionMismatch.Code,
+		Version:      sub.Version,
+		Subscription: api.DigestSubscription{Subscribed: sub.Subscribed},
+	})
+}
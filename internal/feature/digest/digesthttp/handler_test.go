@@ -0,0 +1,172 @@
+package digesthttp_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/digest"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/digest/digesthttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+)
+
+const testUserID int64 = 1
+
+// mockUsecase は Usecase インターフェースのモック実装です。変更のたびにバージョンを増やし、If-Match の不一致を再現します。
+type mockUsecase struct {
+	subscribed map[int64]bool
+	version    int64
+	err        error
+}
+
+func (m *mockUsecase) Subscription(_ context.Context, userID int64) (digest.Subscription, error) {
+	return digest.Subscription{Subscribed: m.subscribed[userID], Version: m.version}, m.err
+}
+
+func (m *mockUsecase) SetSubscribed(_ context.Context, userID int64, subscribed bool, ifVersion *int64) (digest.Subscription, error) {
+	if m.err != nil {
+		return digest.Subscription{}, m.err
+	}
+	if ifVersion != nil && *ifVersion != m.version {
+		return digest.Subscription{}, digest.ErrVersionMismatch
+	}
+	m.subscribed[userID] = subscribed
+	m.version++
+	return digest.Subscription{Subscribed: subscribed, Version: m.version}, nil
+}
+
+// newRouter は認証済みユーザーIDを context に注入し、本番と同じパスでハンドラーを登録した chi ルーターを構築します。
+func newRouter(uc *mockUsecase) chi.Router {
+	return newRouterWith(digesthttp.NewHandler(uc))
+}
+
+func newRouterWith(h *digesthttp.Handler) chi.Router {
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			next.ServeHTTP(w, req.WithContext(jwt.WithUserID(req.Context(), testUserID)))
+		})
+	})
+	r.Get("/me/digest", h.Get)
+	r.Put("/me/digest", h.Update)
+	return r
+}
+
+func serve(r http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	return serveIfMatch(r, method, target, body, "")
+}
+
+func serveIfMatch(r http.Handler, method, target, body, ifMatch string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestDigestHandler_GetAndUpdate(t *testing.T) {
+	t.Parallel()
+	uc := &mockUsecase{subscribed: map[int64]bool{}}
+	r := newRouter(uc)
+
+	decode := func(w *httptest.ResponseRecorder) api.DigestSubscription {
+		t.Helper()
+		var got api.DigestSubscription
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		return got
+	}
+
+	w := serve(r, http.MethodGet, "/me/digest", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, decode(w).Subscribed, "既定は購読しない")
+
+	w = serve(r, http.MethodPut, "/me/digest", `{"subscribed":true}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, decode(w).Subscribed)
+	assert.True(t, uc.subscribed[testUserID])
+
+	w = serve(r, http.MethodPut, "/me/digest", `{"subscribed":false}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, decode(w).Subscribed)
+	assert.False(t, uc.subscribed[testUserID])
+}
+
+// TestDigestHandler_IfMatch は GET の ETag を If-Match に付けた変更が成功し、古い ETag の変更が 412 で
+// 現在の購読状態とバージョンを返すことを検証します。
+func TestDigestHandler_IfMatch(t *testing.T) {
+	t.Parallel()
+	uc := &mockUsecase{subscribed: map[int64]bool{}, version: 2}
+	r := newRouter(uc)
+
+	w := serve(r, http.MethodGet, "/me/digest", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	assert.Equal(t, `"2"`, etag)
+
+	// 1 台目の端末: 見たバージョンのまま変更できる
+	w = serveIfMatch(r, http.MethodPut, "/me/digest", `{"subscribed":true}`, etag)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+	// 2 台目の端末: 同じ古い ETag での変更は 412 になり、変更されない
+	w = serveIfMatch(r, http.MethodPut, "/me/digest", `{"subscribed":false}`, etag)
+	require.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	assert.JSONEq(t, `{"error":"version mismatch","version":3,"subscription":{"subscribed":true}}`, w.Body.String())
+	assert.True(t, uc.subscribed[testUserID])
+
+	// 必須の場合は If-Match がないと 428
+	required := newRouterWith(digesthttp.NewHandler(uc).WithRequireIfMatch(true))
+	w = serve(required, http.MethodPut, "/me/digest", `{"subscribed":false}`)
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+	assert.True(t, uc.subscribed[testUserID])
+	w = serveIfMatch(required, http.MethodPut, "/me/digest", `{"subscribed":false}`, `"3"`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, uc.subscribed[testUserID])
+}
+
+func TestDigestHandler_UpdateValidation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"subscribed の欠落", `{}`},
+		{"null", `{"subscribed":null}`},
+		{"真偽値でない", `{"subscribed":"yes"}`},
+		{"JSON でない", `subscribed`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			uc := &mockUsecase{subscribed: map[int64]bool{}}
+			w := serve(newRouter(uc), http.MethodPut, "/me/digest", tt.body)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Empty(t, uc.subscribed)
+		})
+	}
+}
+
+func TestDigestHandler_UsecaseError(t *testing.T) {
+	t.Parallel()
+	uc := &mockUsecase{subscribed: map[int64]bool{}, err: errors.New("db down")}
+	r := newRouter(uc)
+
+	assert.Equal(t, http.StatusInternalServerError, serve(r, http.MethodGet, "/me/digest", "").Code)
+	assert.Equal(t, http.StatusInternalServerError, serve(r, http.MethodPut, "/me/digest", `{"subscribed":true}`).Code)
+}
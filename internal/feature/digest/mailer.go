@@ -0,0 +1,30 @@
+package digest
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Mail は 1 通のメールです。Text と HTML は同じ内容の text/plain と text/html の本文です。
+type Mail struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer はメールの送信を抽象化します。エラーは一時的な失敗として扱い、送信ジョブが 1 回だけ再試行します。
+type Mailer interface {
+	Send(ctx context.Context, m Mail) error
+}
+
+// LogMailer はメールを送らずにログへ出力する Mailer です。メール送信の資格情報を設定しない環境で使います。
+type LogMailer struct{}
+
+var _ Mailer = LogMailer{}
+
+// Send は宛先と件名をログに出力します。本文は出力しません（ウォッチリストの内容を残さないため）。
+func (LogMailer) Send(_ context.Context, m Mail) error {
+	slog.Info("digest mail (log mailer)", "to", m.To, "subject", m.Subject)
+	return nil
+}
@@ -0,0 +1,79 @@
+package digest
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"math"
+	texttemplate "text/template"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var (
+	textTmpl = texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/digest.txt.tmpl"))
+	htmlTmpl = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/digest.html.tmpl"))
+)
+
+// Content はダイジェストを描画した件名と本文です（宛先は含みません）。
+type Content struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// view はテンプレートに渡す値です。数値の書式はここで確定させ、テンプレートでは計算しません。
+type view struct {
+	Subject   string
+	DateLabel string
+	Rows      []viewRow
+	Up        int
+	Down      int
+	Flat      int
+}
+
+type viewRow struct {
+	Symbol string
+	Close  string
+	Change string
+	Color  string
+}
+
+// Render はダイジェスト d を件名・テキスト・HTML に描画します。同じ d からは常に同じ結果を返します。
+// 前日比は小数第 2 位に丸め、丸めて 0 になる銘柄は「変わらず」として数えます。
+func Render(d Digest) (Content, error) {
+	v := view{DateLabel: d.Date.Format("2006-01-02")}
+	for _, c := range d.Changes {
+		pct := math.Round(c.ChangePct*100) / 100
+		row := viewRow{
+			Symbol: c.Symbol,
+			Close:  fmt.Sprintf("%.2f", c.Close),
+			Change: fmt.Sprintf("%+.2f%%", pct),
+			Color:  "#57606a",
+		}
+		switch {
+		case pct > 0:
+			v.Up++
+			row.Color = "#1a7f37"
+		case pct < 0:
+			v.Down++
+			row.Color = "#cf222e"
+		default:
+			v.Flat++
+			row.Change = "0.00%"
+		}
+		v.Rows = append(v.Rows, row)
+	}
+	v.Subject = fmt.Sprintf("[stock] %s のウォッチリスト: 値上がり %d / 値下がり %d", v.DateLabel, v.Up, v.Down)
+
+	var text, html bytes.Buffer
+	if err := textTmpl.Execute(&text, v); err != nil {
+		return Content{}, fmt.Errorf("render digest text: %w", err)
+	}
+	if err := htmlTmpl.Execute(&html, v); err != nil {
+		return Content{}, fmt.Errorf("render digest html: %w", err)
+	}
+	return Content{Subject: v.Subject, Text: text.String(), HTML: html.String()}, nil
+}
@@ -0,0 +1,46 @@
+package digest
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// update が true の場合、golden ファイルを現在の描画結果で上書きします（go test ./internal/feature/digest/ -update）。
+var update = flag.Bool("update", false, "update golden files")
+
+// assertGolden は got が testdata/name と一致することを検証します。
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, os.WriteFile(path, []byte(got), 0o644))
+	}
+	want, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), got)
+}
+
+func TestRender_Golden(t *testing.T) {
+	t.Parallel()
+
+	d := Digest{
+		Date: day(2026, 8, 5),
+		Changes: []SymbolChange{
+			{Symbol: "AAPL", Close: 231.5, PrevClose: 225.1, ChangePct: 2.8431808},
+			{Symbol: "7203.T", Close: 2870, PrevClose: 2905, ChangePct: -1.2048193},
+			{Symbol: "MSFT", Close: 410.2, PrevClose: 410.21, ChangePct: -0.0024378},
+			{Symbol: "<script>", Close: 1, PrevClose: 1, ChangePct: 0},
+		},
+	}
+	got, err := Render(d)
+	require.NoError(t, err)
+
+	assert.Equal(t, "[stock] 2026-08-05 のウォッチリスト: 値上がり 1 / 値下がり 1", got.Subject)
+	assertGolden(t, "digest.txt.golden", got.Text)
+	assertGolden(t, "digest.html.golden", got.HTML)
+}
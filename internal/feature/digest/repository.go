@@ -0,0 +1,105 @@
+package digest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/digest/sqlc"
+)
+
+// repository は SubscriptionRepository・SendStore の sqlc ベース実装です。
+type repository struct {
+	db *sql.DB
+	q  *digestsqlc.Queries
+}
+
+var (
+	_ SubscriptionRepository = (*repository)(nil)
+	_ SendStore              = (*repository)(nil)
+)
+
+// NewRepository は指定された *sql.DB で repository の新しいインスタンスを生成します。
+func NewRepository(db *sql.DB) *repository {
+	return &repository{db: db, q: digestsqlc.New(db)}
+}
+
+// SubscriptionVersion は購読の設定のバージョンを返します（一度も変更していなければ 0）。
+func (r *repository) SubscriptionVersion(ctx context.Context, userID int64) (int64, error) {
+	return r.q.GetDigestVersion(ctx, userID)
+}
+
+// SetSubscribed はユーザーの購読を登録・解除し（登録済み・未登録の場合は何もしない）、同じトランザクションで
+// バージョンを増やして更新後のバージョンを返します。
+//
+// ifVersion が nil でない場合は、現在のバージョンが *ifVersion の場合だけ変更します（一致しなければ ErrVersionMismatch）。
+// 判定は WHERE version = ? の条件付き UPDATE の結果で行うため、読み取りと更新の間に他の更新が割り込むことはありません。
+func (r *repository) SetSubscribed(ctx context.Context, userID int64, subscribed bool, ifVersion *int64) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback()
+		}
+	}()
+	qtx := r.q.WithTx(tx)
+
+	var version int64
+	if ifVersion != nil {
+		if err := qtx.EnsureDigestVersion(ctx, userID); err != nil {
+			return 0, fmt.Errorf("ensure digest version: %w", err)
+		}
+		version, err = qtx.BumpDigestVersionIf(ctx, digestsqlc.BumpDigestVersionIfParams{UserID: userID, Expected: *ifVersion})
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrVersionMismatch
+		}
+	} else {
+		version, err = qtx.BumpDigestVersion(ctx, userID)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("bump digest version: %w", err)
+	}
+
+	if subscribed {
+		err = qtx.InsertSubscription(ctx, userID)
+	} else {
+		err = qtx.DeleteSubscription(ctx, userID)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit tx: %w", err)
+	}
+	committed = true
+	return version, nil
+}
+
+// IsSubscribed はユーザーが購読しているかを返します。
+func (r *repository) IsSubscribed(ctx context.Context, userID int64) (bool, error) {
+	return r.q.SubscriptionExists(ctx, userID)
+}
+
+// ListSubscribers は購読者のユーザー ID を昇順に、afterID より大きいものから最大 limit 件返します。
+func (r *repository) ListSubscribers(ctx context.Context, afterID int64, limit int) ([]int64, error) {
+	return r.q.ListSubscribers(ctx, digestsqlc.ListSubscribersParams{AfterID: afterID, MaxRows: int32(limit)})
+}
+
+// ClaimSend は (userID, date) の送信を記録し、新しく記録できた場合に true を返します。
+func (r *repository) ClaimSend(ctx context.Context, userID int64, date time.Time) (bool, error) {
+	n, err := r.q.ClaimSend(ctx, digestsqlc.ClaimSendParams{UserID: userID, DigestDate: date})
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// ReleaseSend は (userID, date) の送信の記録を削除します。
+func (r *repository) ReleaseSend(ctx context.Context, userID int64, date time.Time) error {
+	return r.q.ReleaseSend(ctx, digestsqlc.ReleaseSendParams{UserID: userID, DigestDate: date})
+}
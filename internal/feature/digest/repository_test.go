@@ -0,0 +1,111 @@
+package digest
+
+import (
+	"context"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/db/dbtest"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/testsupport"
+)
+
+func TestMain(m *testing.M) {
+	code, err := dbtest.RunMainWithPostgres(m)
+	if err != nil {
+		log.Fatalf("dbtest setup: %v", err)
+	}
+	os.Exit(code)
+}
+
+func TestRepository_Subscriptions(t *testing.T) {
+	t.Parallel()
+	db := testsupport.NewDB(t)
+	u1, u2 := testsupport.InsertUser(t, db).ID, testsupport.InsertUser(t, db).ID
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	ok, err := repo.IsSubscribed(ctx, u1)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = repo.SetSubscribed(ctx, u1, true, nil)
+	require.NoError(t, err)
+	_, err = repo.SetSubscribed(ctx, u1, true, nil)
+	require.NoError(t, err, "登録済みでもエラーにしない")
+	_, err = repo.SetSubscribed(ctx, u2, true, nil)
+	require.NoError(t, err)
+
+	ids, err := repo.ListSubscribers(ctx, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{u1, u2}, ids)
+	ids, err = repo.ListSubscribers(ctx, u1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{u2}, ids)
+
+	_, err = repo.SetSubscribed(ctx, u1, false, nil)
+	require.NoError(t, err)
+	_, err = repo.SetSubscribed(ctx, u1, false, nil)
+	require.NoError(t, err, "未登録でもエラーにしない")
+	ok, err = repo.IsSubscribed(ctx, u1)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	version, err := repo.SubscriptionVersion(ctx, u1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), version, "変更のたびに増える")
+}
+
+func TestRepository_SetSubscribed_IfVersion(t *testing.T) {
+	t.Parallel()
+	db := testsupport.NewDB(t)
+	u1 := testsupport.InsertUser(t, db).ID
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	version, err := repo.SubscriptionVersion(ctx, u1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), version)
+
+	seen := int64(0)
+	version, err = repo.SetSubscribed(ctx, u1, true, &seen)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), version)
+
+	_, err = repo.SetSubscribed(ctx, u1, false, &seen)
+	require.ErrorIs(t, err, ErrVersionMismatch)
+	ok, err := repo.IsSubscribed(ctx, u1)
+	require.NoError(t, err)
+	assert.True(t, ok, "不一致の場合は変更しない")
+}
+
+func TestRepository_ClaimAndReleaseSend(t *testing.T) {
+	t.Parallel()
+	db := testsupport.NewDB(t)
+	u1, u2 := testsupport.InsertUser(t, db).ID, testsupport.InsertUser(t, db).ID
+	repo := NewRepository(db)
+	ctx := context.Background()
+	d := day(2026, 8, 5)
+
+	claimed, err := repo.ClaimSend(ctx, u1, d)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+	claimed, err = repo.ClaimSend(ctx, u1, d)
+	require.NoError(t, err)
+	assert.False(t, claimed, "同じユーザー・対象日は二度記録できない")
+
+	claimed, err = repo.ClaimSend(ctx, u2, d)
+	require.NoError(t, err)
+	assert.True(t, claimed, "別のユーザーは記録できる")
+	claimed, err = repo.ClaimSend(ctx, u1, day(2026, 8, 6))
+	require.NoError(t, err)
+	assert.True(t, claimed, "別の対象日は記録できる")
+
+	require.NoError(t, repo.ReleaseSend(ctx, u1, d))
+	claimed, err = repo.ClaimSend(ctx, u1, d)
+	require.NoError(t, err)
+	assert.True(t, claimed, "取り消した記録は再度記録できる")
+}
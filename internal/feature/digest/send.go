@@ -0,0 +1,222 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const (
+	// MaxDigestAge は送信する対象日の古さの上限です。これより古い日足しかないユーザー（購読を始めたばかりで
+	// 休場が続いている・銘柄が上場廃止になった等）には送りません。週末と祝日の連休をまたげる長さにしています。
+	MaxDigestAge = 4 * 24 * time.Hour
+
+	// subscriberPageSize は購読者を 1 回に読み込む件数です。
+	subscriberPageSize = 500
+	// barsPerSymbol は前日比の計算に読み込む日足の本数です。
+	barsPerSymbol = 2
+	// defaultRetryDelay は送信に失敗したメールを再試行するまでの待ち時間です。
+	defaultRetryDelay = 5 * time.Second
+)
+
+// SendStore は購読者の列挙と送信済みの記録の永続化層を抽象化します。
+type SendStore interface {
+	// ListSubscribers は購読者のユーザー ID を昇順に、afterID より大きいものから最大 limit 件返します。
+	ListSubscribers(ctx context.Context, afterID int64, limit int) ([]int64, error)
+	// ClaimSend はユーザー userID の対象日 date のダイジェストを送信済みとして記録し、記録できた場合に true を返します。
+	// 記録済み（送信済み・他の実行が送信中）の場合は false を返します。
+	ClaimSend(ctx context.Context, userID int64, date time.Time) (bool, error)
+	// ReleaseSend は ClaimSend の記録を取り消します（送信に失敗した場合に次回の実行で再送するため）。
+	ReleaseSend(ctx context.Context, userID int64, date time.Time) error
+}
+
+// RecipientResolver はユーザーの宛先（メールアドレス）を返します。
+// digest が auth feature に直接依存しないよう、最小限の読み取り専用インターフェースをここで定義します。
+type RecipientResolver interface {
+	EmailOf(ctx context.Context, userID int64) (string, error)
+}
+
+// WatchlistReader はユーザーのウォッチリストの銘柄コードを並び順で返します。
+type WatchlistReader interface {
+	Symbols(ctx context.Context, userID int64) ([]string, error)
+}
+
+// BarReader は銘柄の新しい日足を最大 n 本返します。Bar.Time は銘柄の市場のタイムゾーンで返します。
+type BarReader interface {
+	DailyBars(ctx context.Context, symbol string, n int) ([]Bar, error)
+}
+
+// RateLimiter は送信の頻度の上限（全ユーザー合計）を抽象化します。clientratelimit.RateLimiter が実装します。
+type RateLimiter interface {
+	WaitIfNeeded(ctx context.Context) error
+}
+
+// SendResult はダイジェスト送信の集計結果です。
+type SendResult struct {
+	Subscribers int // 処理した購読者数
+	Sent        int // 送信したメール数
+	AlreadySent int // 対象日のダイジェストを送信済みのため送らなかった購読者数
+	NoChanges   int // ウォッチリストが空・対象の値動きがない・日足が古いため送らなかった購読者数
+	Failed      int // 読み込み・送信（再試行を含む）に失敗した購読者数（次回の実行で再送の対象になる）
+}
+
+// SendUsecase は購読者ごとにダイジェストを計算して送信します。
+// 同じユーザー・対象日には 1 通だけ送ります（SendStore.ClaimSend で記録してから送るため、再実行しても二重に送りません）。
+type SendUsecase struct {
+	store       SendStore
+	recipients  RecipientResolver
+	watchlists  WatchlistReader
+	bars        BarReader
+	mailer      Mailer
+	rateLimiter RateLimiter
+	now         func() time.Time
+	retryDelay  time.Duration
+}
+
+// NewSendUsecase は SendUsecase の新しいインスタンスを生成します。
+func NewSendUsecase(store SendStore, recipients RecipientResolver, watchlists WatchlistReader, bars BarReader, mailer Mailer, rateLimiter RateLimiter) *SendUsecase {
+	return &SendUsecase{
+		store:       store,
+		recipients:  recipients,
+		watchlists:  watchlists,
+		bars:        bars,
+		mailer:      mailer,
+		rateLimiter: rateLimiter,
+		now:         time.Now,
+		retryDelay:  defaultRetryDelay,
+	}
+}
+
+// SendAll は全購読者にダイジェストを送ります。
+// 購読者単位の失敗では処理を止めずに Failed に数え、ctx の終了・購読者の列挙とレートリミッタの待機の失敗でのみ中断します。
+// 送信に失敗したメールは retryDelay 後に 1 回だけ再試行し、それでも失敗した場合は送信済みの記録を取り消します。
+func (u *SendUsecase) SendAll(ctx context.Context) (SendResult, error) {
+	var (
+		result SendResult
+		after  int64
+	)
+	// 同じ銘柄を複数のユーザーがウォッチリストに入れていることが多いため、1 回の実行の中で日足を使い回す
+	cache := make(map[string][]Bar)
+	for {
+		ids, err := u.store.ListSubscribers(ctx, after, subscriberPageSize)
+		if err != nil {
+			return result, fmt.Errorf("list digest subscribers: %w", err)
+		}
+		for _, id := range ids {
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+			result.Subscribers++
+			if err := u.sendOne(ctx, id, cache, &result); err != nil {
+				return result, err
+			}
+		}
+		if len(ids) < subscriberPageSize {
+			return result, nil
+		}
+		after = ids[len(ids)-1]
+	}
+}
+
+// sendOne はユーザー userID にダイジェストを送り、結果を result に数えます。
+// 処理を中断すべきエラー（レートリミッタの待機の失敗）のみを返します。
+func (u *SendUsecase) sendOne(ctx context.Context, userID int64, cache map[string][]Bar, result *SendResult) error {
+	d, ok, err := u.build(ctx, userID, cache)
+	if err != nil {
+		slog.Error("failed to build digest", "userID", userID, "error", err)
+		result.Failed++
+		return nil
+	}
+	if !ok {
+		result.NoChanges++
+		return nil
+	}
+	content, err := Render(d)
+	if err != nil {
+		slog.Error("failed to render digest", "userID", userID, "error", err)
+		result.Failed++
+		return nil
+	}
+	to, err := u.recipients.EmailOf(ctx, userID)
+	if err != nil {
+		slog.Error("failed to resolve digest recipient", "userID", userID, "error", err)
+		result.Failed++
+		return nil
+	}
+
+	claimed, err := u.store.ClaimSend(ctx, userID, d.Date)
+	if err != nil {
+		slog.Error("failed to record digest send", "userID", userID, "date", d.Date.Format(time.DateOnly), "error", err)
+		result.Failed++
+		return nil
+	}
+	if !claimed {
+		result.AlreadySent++
+		return nil
+	}
+	if err := u.rateLimiter.WaitIfNeeded(ctx); err != nil {
+		u.release(ctx, userID, d.Date)
+		return err
+	}
+	mail := Mail{To: to, Subject: content.Subject, Text: content.Text, HTML: content.HTML}
+	if err := u.deliver(ctx, mail); err != nil {
+		slog.Error("failed to send digest", "userID", userID, "date", d.Date.Format(time.DateOnly), "error", err)
+		u.release(ctx, userID, d.Date)
+		result.Failed++
+		return nil
+	}
+	result.Sent++
+	return nil
+}
+
+// build はユーザー userID のダイジェストを計算します。送るべき内容がない場合は ok=false です。
+func (u *SendUsecase) build(ctx context.Context, userID int64, cache map[string][]Bar) (Digest, bool, error) {
+	symbols, err := u.watchlists.Symbols(ctx, userID)
+	if err != nil {
+		return Digest{}, false, fmt.Errorf("list watchlist: %w", err)
+	}
+	bars := make(map[string][]Bar, len(symbols))
+	for _, sym := range symbols {
+		bs, ok := cache[sym]
+		if !ok {
+			bs, err = u.bars.DailyBars(ctx, sym, barsPerSymbol)
+			if err != nil {
+				return Digest{}, false, fmt.Errorf("find daily bars of %s: %w", sym, err)
+			}
+			cache[sym] = bs
+		}
+		bars[sym] = bs
+	}
+	d, ok := Compute(symbols, bars)
+	if !ok || u.now().Sub(d.Date) > MaxDigestAge {
+		return Digest{}, false, nil
+	}
+	return d, true, nil
+}
+
+// deliver は m を送信し、失敗した場合は retryDelay 後に 1 回だけ再試行します。
+func (u *SendUsecase) deliver(ctx context.Context, m Mail) error {
+	err := u.mailer.Send(ctx, m)
+	if err == nil {
+		return nil
+	}
+	slog.Warn("digest send failed, retrying once", "error", err, "retry_in", u.retryDelay)
+	timer := time.NewTimer(u.retryDelay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		return fmt.Errorf("%w (retry canceled: %w)", err, ctx.Err())
+	}
+	return u.mailer.Send(ctx, m)
+}
+
+// release は送信済みの記録を取り消します。ctx の終了後も取り消せるよう、キャンセルを引き継がない context で実行します。
+func (u *SendUsecase) release(ctx context.Context, userID int64, date time.Time) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	if err := u.store.ReleaseSend(ctx, userID, date); err != nil {
+		slog.Error("failed to release digest send; it will not be resent", "userID", userID, "date", date.Format(time.DateOnly), "error", err)
+	}
+}
@@ -0,0 +1,260 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSendStore は SendStore のメモリ上の実装です。
+type fakeSendStore struct {
+	mu          sync.Mutex
+	subscribers []int64
+	sent        map[string]bool
+	claimErr    error
+	released    []string
+}
+
+func newFakeSendStore(ids ...int64) *fakeSendStore {
+	return &fakeSendStore{subscribers: ids, sent: make(map[string]bool)}
+}
+
+func sendKey(userID int64, date time.Time) string {
+	return fmt.Sprintf("%d/%s", userID, date.Format(time.DateOnly))
+}
+
+func (s *fakeSendStore) ListSubscribers(_ context.Context, afterID int64, limit int) ([]int64, error) {
+	var out []int64
+	for _, id := range s.subscribers {
+		if id > afterID && len(out) < limit {
+			out = append(out, id)
+		}
+	}
+	return out, nil
+}
+
+func (s *fakeSendStore) ClaimSend(_ context.Context, userID int64, date time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.claimErr != nil {
+		return false, s.claimErr
+	}
+	k := sendKey(userID, date)
+	if s.sent[k] {
+		return false, nil
+	}
+	s.sent[k] = true
+	return true, nil
+}
+
+func (s *fakeSendStore) ReleaseSend(_ context.Context, userID int64, date time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := sendKey(userID, date)
+	delete(s.sent, k)
+	s.released = append(s.released, k)
+	return nil
+}
+
+type emailsFunc func(ctx context.Context, userID int64) (string, error)
+
+func (f emailsFunc) EmailOf(ctx context.Context, userID int64) (string, error) { return f(ctx, userID) }
+
+func emailByID(_ context.Context, userID int64) (string, error) {
+	return fmt.Sprintf("u%d@example.com", userID), nil
+}
+
+type watchlistMap map[int64][]string
+
+func (w watchlistMap) Symbols(_ context.Context, userID int64) ([]string, error) {
+	return w[userID], nil
+}
+
+// countingBars は銘柄ごとの読み込み回数を数える BarReader です。
+type countingBars struct {
+	bars  map[string][]Bar
+	calls map[string]int
+}
+
+func (b *countingBars) DailyBars(_ context.Context, symbol string, n int) ([]Bar, error) {
+	if b.calls == nil {
+		b.calls = make(map[string]int)
+	}
+	b.calls[symbol]++
+	bs := b.bars[symbol]
+	if len(bs) > n {
+		bs = bs[:n]
+	}
+	return bs, nil
+}
+
+// recordingMailer は送信したメールを記録し、先頭の failures 回は失敗する Mailer です。
+type recordingMailer struct {
+	failures int
+	attempts int
+	sent     []Mail
+}
+
+func (m *recordingMailer) Send(_ context.Context, mail Mail) error {
+	m.attempts++
+	if m.failures > 0 {
+		m.failures--
+		return errors.New("smtp: 421 try again later")
+	}
+	m.sent = append(m.sent, mail)
+	return nil
+}
+
+type countingLimiter struct {
+	calls int
+	err   error
+}
+
+func (l *countingLimiter) WaitIfNeeded(context.Context) error {
+	l.calls++
+	return l.err
+}
+
+var (
+	digestDay  = day(2026, 8, 5)
+	digestNow  = time.Date(2026, 8, 5, 23, 0, 0, 0, time.UTC)
+	sampleBars = map[string][]Bar{
+		"AAPL": {{Time: digestDay, Close: 110}, {Time: day(2026, 8, 4), Close: 100}},
+		"MSFT": {{Time: digestDay, Close: 396}, {Time: day(2026, 8, 4), Close: 400}},
+		"OLD":  {{Time: day(2026, 7, 1), Close: 10}, {Time: day(2026, 6, 30), Close: 9}},
+	}
+)
+
+func newSend(store SendStore, lists watchlistMap, bars *countingBars, mailer Mailer, limiter RateLimiter) *SendUsecase {
+	u := NewSendUsecase(store, emailsFunc(emailByID), lists, bars, mailer, limiter)
+	u.now = func() time.Time { return digestNow }
+	u.retryDelay = 0
+	return u
+}
+
+func TestSendUsecase_SendAll(t *testing.T) {
+	t.Parallel()
+	store := newFakeSendStore(1, 2, 3, 4)
+	lists := watchlistMap{
+		1: {"AAPL", "MSFT"},
+		2: {"MSFT"},
+		3: {},      // 空のウォッチリスト
+		4: {"OLD"}, // 日足が古い（上場廃止など）
+	}
+	bars := &countingBars{bars: sampleBars}
+	mailer := &recordingMailer{}
+	limiter := &countingLimiter{}
+
+	res, err := newSend(store, lists, bars, mailer, limiter).SendAll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, SendResult{Subscribers: 4, Sent: 2, NoChanges: 2}, res)
+
+	require.Len(t, mailer.sent, 2)
+	assert.Equal(t, "u1@example.com", mailer.sent[0].To)
+	assert.Contains(t, mailer.sent[0].Text, "AAPL")
+	assert.Contains(t, mailer.sent[0].Text, "+10.00%")
+	assert.Contains(t, mailer.sent[0].HTML, "MSFT")
+	assert.Equal(t, "u2@example.com", mailer.sent[1].To)
+	assert.NotContains(t, mailer.sent[1].Text, "AAPL")
+
+	assert.Equal(t, 2, limiter.calls, "送信するメールごとに待機する")
+	assert.Equal(t, 1, bars.calls["MSFT"], "同じ銘柄の日足は 1 回の実行で 1 回だけ読む")
+	assert.True(t, store.sent[sendKey(1, digestDay)])
+}
+
+func TestSendUsecase_SendAll_Idempotent(t *testing.T) {
+	t.Parallel()
+	store := newFakeSendStore(1)
+	lists := watchlistMap{1: {"AAPL"}}
+	mailer := &recordingMailer{}
+
+	for range 2 {
+		_, err := newSend(store, lists, &countingBars{bars: sampleBars}, mailer, &countingLimiter{}).SendAll(context.Background())
+		require.NoError(t, err)
+	}
+	res, err := newSend(store, lists, &countingBars{bars: sampleBars}, mailer, &countingLimiter{}).SendAll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, SendResult{Subscribers: 1, AlreadySent: 1}, res)
+	assert.Len(t, mailer.sent, 1, "同じユーザー・対象日には 1 通だけ送る")
+}
+
+func TestSendUsecase_SendAll_RetriesOnce(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		failures     int
+		wantResult   SendResult
+		wantAttempts int
+		wantClaimed  bool
+	}{
+		{"1 回目の失敗は再試行で送る", 1, SendResult{Subscribers: 1, Sent: 1}, 2, true},
+		{"再試行も失敗したら記録を取り消して次回に再送する", 2, SendResult{Subscribers: 1, Failed: 1}, 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := newFakeSendStore(1)
+			mailer := &recordingMailer{failures: tt.failures}
+			res, err := newSend(store, watchlistMap{1: {"AAPL"}}, &countingBars{bars: sampleBars}, mailer, &countingLimiter{}).
+				SendAll(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantResult, res)
+			assert.Equal(t, tt.wantAttempts, mailer.attempts)
+			assert.Equal(t, tt.wantClaimed, store.sent[sendKey(1, digestDay)])
+		})
+	}
+}
+
+func TestSendUsecase_SendAll_PerUserFailuresContinue(t *testing.T) {
+	t.Parallel()
+	store := newFakeSendStore(1, 2)
+	u := newSend(store, watchlistMap{1: {"AAPL"}, 2: {"AAPL"}}, &countingBars{bars: sampleBars}, &recordingMailer{}, &countingLimiter{})
+	u.recipients = emailsFunc(func(ctx context.Context, userID int64) (string, error) {
+		if userID == 1 {
+			return "", errors.New("user lookup failed")
+		}
+		return emailByID(ctx, userID)
+	})
+
+	res, err := u.SendAll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, SendResult{Subscribers: 2, Sent: 1, Failed: 1}, res)
+	assert.False(t, store.sent[sendKey(1, digestDay)], "宛先を解決できないユーザーは記録しない（次回に送る）")
+}
+
+func TestSendUsecase_SendAll_RateLimiterAborts(t *testing.T) {
+	t.Parallel()
+	store := newFakeSendStore(1, 2)
+	mailer := &recordingMailer{}
+	limiter := &countingLimiter{err: context.DeadlineExceeded}
+
+	res, err := newSend(store, watchlistMap{1: {"AAPL"}, 2: {"AAPL"}}, &countingBars{bars: sampleBars}, mailer, limiter).
+		SendAll(context.Background())
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, res.Subscribers)
+	assert.Empty(t, mailer.sent)
+	assert.Equal(t, []string{sendKey(1, digestDay)}, store.released, "送らなかったユーザーの記録は取り消す")
+}
+
+func TestSendUsecase_SendAll_Paging(t *testing.T) {
+	t.Parallel()
+	ids := make([]int64, subscriberPageSize+3)
+	lists := watchlistMap{}
+	for i := range ids {
+		ids[i] = int64(i + 1)
+		lists[ids[i]] = []string{"AAPL"}
+	}
+	mailer := &recordingMailer{}
+	res, err := newSend(newFakeSendStore(ids...), lists, &countingBars{bars: sampleBars}, mailer, &countingLimiter{}).
+		SendAll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, len(ids), res.Subscribers)
+	assert.Len(t, mailer.sent, len(ids))
+}
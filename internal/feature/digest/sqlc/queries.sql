@@ -0,0 +1,57 @@
+-- name: InsertSubscription :exec
+INSERT INTO digest_subscriptions (user_id)
+VALUES ($1)
+ON CONFLICT (user_id) DO NOTHING;
+
+-- name: DeleteSubscription :exec
+DELETE FROM digest_subscriptions
+WHERE user_id = $1;
+
+-- name: SubscriptionExists :one
+SELECT EXISTS (
+    SELECT 1 FROM digest_subscriptions WHERE user_id = $1
+);
+
+-- name: ListSubscribers :many
+-- user_id の昇順のキーセットページング（after より大きい user_id を limit 件）。
+SELECT user_id
+FROM digest_subscriptions
+WHERE user_id > sqlc.arg(after_id)
+ORDER BY user_id
+LIMIT sqlc.arg(max_rows);
+
+-- name: ClaimSend :execrows
+-- 挿入できた（0 行でない）場合だけ送信する。
+INSERT INTO digest_sends (user_id, digest_date)
+VALUES ($1, $2)
+ON CONFLICT (user_id, digest_date) DO NOTHING;
+
+-- name: ReleaseSend :exec
+DELETE FROM digest_sends
+WHERE user_id = $1 AND digest_date = $2;
+
+-- name: GetDigestVersion :one
+-- 一度も書き込んでいない場合は 0。
+SELECT COALESCE((
+    SELECT version FROM user_resource_versions WHERE user_id = $1 AND resource = 'digest'
+), 0)::bigint AS version;
+
+-- name: EnsureDigestVersion :exec
+-- 条件付きの更新（BumpDigestVersionIf）の前に行を用意する（行がないと初回の更新が一致しないため）。
+INSERT INTO user_resource_versions (user_id, resource)
+VALUES ($1, 'digest')
+ON CONFLICT (user_id, resource) DO NOTHING;
+
+-- name: BumpDigestVersion :one
+INSERT INTO user_resource_versions (user_id, resource, version)
+VALUES ($1, 'digest', 1)
+ON CONFLICT (user_id, resource) DO UPDATE
+SET version = user_resource_versions.version + 1
+RETURNING version;
+
+-- name: BumpDigestVersionIf :one
+-- 現在のバージョンが expected の場合だけ増やす。一致しなければ行を返さない（sql.ErrNoRows）。
+UPDATE user_resource_versions
+SET version = version + 1
+WHERE user_id = sqlc.arg(user_id) AND resource = 'digest' AND version = sqlc.arg(expected)
+RETURNING version;
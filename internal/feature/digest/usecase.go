@@ -0,0 +1,68 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
+)
+
+// ErrVersionMismatch はクライアントが見た購読の設定のバージョン（If-Match）が現在のバージョンと一致しない場合のエラーです。
+var ErrVersionMismatch = apperr.New(apperr.KindPreconditionFailed, "version mismatch", "digest subscription version mismatch")
+
+// Subscription はユーザーのダイジェストの購読状態とそのバージョンです。
+// Version は購読の変更のたびに増え、変更の楽観的同時実行制御（If-Match）に使います。
+type Subscription struct {
+	Subscribed bool
+	Version    int64
+}
+
+// SubscriptionRepository はダイジェストの購読（オプトイン）の永続化層を抽象化します。
+type SubscriptionRepository interface {
+	IsSubscribed(ctx context.Context, userID int64) (bool, error)
+	// SubscriptionVersion は購読の設定のバージョンを返します（一度も変更していなければ 0）。
+	SubscriptionVersion(ctx context.Context, userID int64) (int64, error)
+	// SetSubscribed は購読を登録・解除し（登録済み・未登録でもエラーにしない）、更新後のバージョンを返します。
+	// ifVersion が nil でなければ現在のバージョンと一致する場合だけ更新します（不一致は ErrVersionMismatch）。
+	SetSubscribed(ctx context.Context, userID int64, subscribed bool, ifVersion *int64) (int64, error)
+}
+
+// usecase はダイジェストの購読の取得・変更のビジネスロジックを提供します。
+type usecase struct {
+	repo SubscriptionRepository
+}
+
+// NewUsecase は usecase の新しいインスタンスを生成します。
+func NewUsecase(repo SubscriptionRepository) *usecase {
+	return &usecase{repo: repo}
+}
+
+// Subscription はユーザー userID がダイジェストを購読しているかを、バージョンとともに返します。
+// バージョンを購読状態より先に読むため、間に変更が入った場合のバージョンは購読状態より古くなります
+// （その場合の変更は 412 になり、古い状態に基づく変更が成功することはありません）。
+func (u *usecase) Subscription(ctx context.Context, userID int64) (Subscription, error) {
+	version, err := u.repo.SubscriptionVersion(ctx, userID)
+	if err != nil {
+		return Subscription{}, fmt.Errorf("get digest subscription version: %w", err)
+	}
+	ok, err := u.repo.IsSubscribed(ctx, userID)
+	if err != nil {
+		return Subscription{}, fmt.Errorf("find digest subscription: %w", err)
+	}
+	return Subscription{Subscribed: ok, Version: version}, nil
+}
+
+// SetSubscribed はユーザー userID のダイジェストの購読を subscribed に設定し（冪等）、設定後の状態を返します。
+// ifVersion が nil でなければ、クライアントが見たバージョンが現在のバージョンと一致する場合だけ変更します
+// （他の端末が先に変更していれば ErrVersionMismatch）。
+func (u *usecase) SetSubscribed(ctx context.Context, userID int64, subscribed bool, ifVersion *int64) (Subscription, error) {
+	version, err := u.repo.SetSubscribed(ctx, userID, subscribed, ifVersion)
+	if errors.Is(err, ErrVersionMismatch) {
+		return Subscription{}, err
+	}
+	if err != nil {
+		return Subscription{}, fmt.Errorf("update digest subscription: %w", err)
+	}
+	return Subscription{Subscribed: subscribed, Version: version}, nil
+}
@@ -0,0 +1,99 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSubscriptions struct {
+	users    map[int64]bool
+	versions map[int64]int64
+	err      error
+}
+
+func (f *fakeSubscriptions) IsSubscribed(_ context.Context, userID int64) (bool, error) {
+	return f.users[userID], f.err
+}
+
+func (f *fakeSubscriptions) SubscriptionVersion(_ context.Context, userID int64) (int64, error) {
+	return f.versions[userID], f.err
+}
+
+func (f *fakeSubscriptions) SetSubscribed(_ context.Context, userID int64, subscribed bool, ifVersion *int64) (int64, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	if ifVersion != nil && *ifVersion != f.versions[userID] {
+		return 0, ErrVersionMismatch
+	}
+	if subscribed {
+		f.users[userID] = true
+	} else {
+		delete(f.users, userID)
+	}
+	f.versions[userID]++
+	return f.versions[userID], nil
+}
+
+func newFakeSubscriptions() *fakeSubscriptions {
+	return &fakeSubscriptions{users: map[int64]bool{}, versions: map[int64]int64{}}
+}
+
+func TestUsecase_SetSubscribed(t *testing.T) {
+	t.Parallel()
+	uc := NewUsecase(newFakeSubscriptions())
+	ctx := context.Background()
+
+	got, err := uc.Subscription(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, Subscription{}, got)
+
+	for range 2 { // 冪等
+		_, err := uc.SetSubscribed(ctx, 1, true, nil)
+		require.NoError(t, err)
+	}
+	got, err = uc.Subscription(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, Subscription{Subscribed: true, Version: 2}, got)
+
+	set, err := uc.SetSubscribed(ctx, 1, false, nil)
+	require.NoError(t, err)
+	assert.Equal(t, Subscription{Subscribed: false, Version: 3}, set)
+	got, err = uc.Subscription(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, set, got)
+}
+
+func TestUsecase_SetSubscribed_IfVersion(t *testing.T) {
+	t.Parallel()
+	uc := NewUsecase(newFakeSubscriptions())
+	ctx := context.Background()
+
+	seen := int64(0)
+	set, err := uc.SetSubscribed(ctx, 1, true, &seen)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), set.Version)
+
+	_, err = uc.SetSubscribed(ctx, 1, false, &seen)
+	require.ErrorIs(t, err, ErrVersionMismatch)
+	got, err := uc.Subscription(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, got.Subscribed, "不一致の場合は変更しない")
+}
+
+func TestUsecase_RepositoryError(t *testing.T) {
+	t.Parallel()
+	errDB := errors.New("db down")
+	repo := newFakeSubscriptions()
+	repo.err = errDB
+	uc := NewUsecase(repo)
+
+	_, err := uc.Subscription(context.Background(), 1)
+	require.ErrorIs(t, err, errDB)
+	_, err = uc.SetSubscribed(context.Background(), 1, true, nil)
+	require.ErrorIs(t, err, errDB)
+}
@@ -0,0 +1,11 @@
+package events
+
+import "github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
+
+var (
+	// ErrInvalidRange は日付範囲の指定が不正（from が to より後、範囲が MaxRange を超える）な場合のエラーです。
+	ErrInvalidRange = apperr.New(apperr.KindInvalid, "invalid_range", "invalid date range")
+
+	// ErrSymbolNotFound は指定された銘柄コードが存在しないか、アクティブでない場合のエラーです。
+	ErrSymbolNotFound = apperr.New(apperr.KindNotFound, "symbol_not_found", "symbol not found")
+)
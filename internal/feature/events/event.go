@@ -0,0 +1,64 @@
+// Package events は銘柄のコーポレートイベント（配当の権利落ち日・決算発表日）を扱います。
+// イベントは batch events が外部 API から週次で取り込み、チャートへの表示用に日付範囲で返します。
+package events
+
+import (
+	"fmt"
+	"time"
+)
+
+// Type はコーポレートイベントの種類です。
+type Type string
+
+const (
+	// TypeDividend は配当（権利落ち日）です。Value は 1 株当たりの配当額です。
+	TypeDividend Type = "dividend"
+	// TypeEarnings は決算発表です。Value は EPS の実績（未発表は nil）、Estimate は EPS の予想です。
+	TypeEarnings Type = "earnings"
+)
+
+// Valid は t が対応している種類かを返します。
+func (t Type) Valid() bool {
+	return t == TypeDividend || t == TypeEarnings
+}
+
+// Event は銘柄の 1 件のコーポレートイベントです。同じ銘柄・種類・日付のイベントは 1 件です。
+type Event struct {
+	SymbolCode string
+	Type       Type
+	// Date はイベントの日付です。ローソク足の API と同じく UTC の暦日（0 時）で保持します。
+	Date     time.Time
+	Value    *float64
+	Estimate *float64
+	// Source は取り込み元（例: "twelvedata"）です。
+	Source string
+}
+
+// Validate はイベントとして保存できるかを検証します。
+func (e Event) Validate() error {
+	if e.SymbolCode == "" {
+		return fmt.Errorf("event: missing symbol code")
+	}
+	if !e.Type.Valid() {
+		return fmt.Errorf("event: unknown type %q", e.Type)
+	}
+	if e.Date.IsZero() {
+		return fmt.Errorf("event: missing date")
+	}
+	if e.Source == "" {
+		return fmt.Errorf("event: missing source")
+	}
+	return nil
+}
+
+// UpsertStats は Upsert で新規に挿入・上書きしたイベントの件数です。
+type UpsertStats struct {
+	Inserted int
+	Updated  int
+}
+
+// utcDay は t の UTC の暦日（0 時）を返します。ローソク足の API が返す日付と同じ単位で比較するために使います。
+func utcDay(t time.Time) time.Time {
+	u := t.UTC()
+	return time.Date(u.Year(), u.Month(), u.Day(), 0, 0, 0, 0, time.UTC)
+}
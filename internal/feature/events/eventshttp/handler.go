@@ -0,0 +1,98 @@
+package eventshttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/events"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+)
+
+// symbolCodePattern は銘柄コードとして許可する形式（例: AAPL, 7203.T）。
+// symbols.code が VARCHAR(20) のため最大20文字、英数字と . _ - のみ許可する。
+var symbolCodePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,20}$`)
+
+// Usecase はコーポレートイベントの参照のユースケースインターフェースを定義します。
+type Usecase interface {
+	List(ctx context.Context, code string, from, to time.Time) (events.Calendar, error)
+}
+
+// Handler はコーポレートイベントに関連するHTTPリクエストを処理します。
+type Handler struct {
+	uc Usecase
+}
+
+// NewHandler はHandlerの新しいインスタンスを生成します。
+func NewHandler(uc Usecase) *Handler {
+	return &Handler{uc: uc}
+}
+
+// List は銘柄の配当・決算のイベントのうち、日付が ?from= 〜 ?to=（両端を含む）のものを日付順に返します。
+//
+// エンドポイント例:
+// GET /symbols/{code}/events?from=2026-01-01&to=2026-06-30
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	code := chi.URLParam(r, "code")
+	if !symbolCodePattern.MatchString(code) {
+		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "invalid symbol code"})
+		return
+	}
+	q := r.URL.Query()
+	from, err := dateParam(q, "from")
+	if err != nil {
+		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		return
+	}
+	to, err := dateParam(q, "to")
+	if err != nil {
+		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	cal, err := h.uc.List(r.Context(), code, from, to)
+	if err != nil {
+		httpx.WriteError(w, err, "failed to list events", "code", code)
+		return
+	}
+	out := api.SymbolEventsResponse{
+		Symbol: code,
+		From:   api.NewDate(cal.From),
+		To:     api.NewDate(cal.To),
+		Events: make([]api.CorporateEvent, 0, len(cal.Events)),
+	}
+	for _, e := range cal.Events {
+		out.Events = append(out.Events, toCorporateEvent(e))
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+// toCorporateEvent はドメインの Event を API 型に変換します。
+func toCorporateEvent(e events.Event) api.CorporateEvent {
+	return api.CorporateEvent{
+		Type:     api.CorporateEventType(e.Type),
+		Date:     api.NewDate(e.Date),
+		Value:    e.Value,
+		Estimate: e.Estimate,
+		Source:   e.Source,
+	}
+}
+
+// dateParam はクエリパラメータ name を YYYY-MM-DD として解釈します。未指定の場合はゼロ値を返します。
+func dateParam(q url.Values, name string) (time.Time, error) {
+	raw := q.Get(name)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	d, err := api.ParseDate(raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %w", name, err)
+	}
+	return d.Time, nil
+}
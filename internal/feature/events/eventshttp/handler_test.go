@@ -0,0 +1,123 @@
+package eventshttp_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/events"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/events/eventshttp"
+)
+
+// mockUsecase は Usecase インターフェースのモック実装です。
+type mockUsecase struct {
+	ListFunc func(ctx context.Context, code string, from, to time.Time) (events.Calendar, error)
+}
+
+func (m *mockUsecase) List(ctx context.Context, code string, from, to time.Time) (events.Calendar, error) {
+	return m.ListFunc(ctx, code, from, to)
+}
+
+func newRouter(uc *mockUsecase) chi.Router {
+	h := eventshttp.NewHandler(uc)
+	r := chi.NewRouter()
+	r.Get("/symbols/{code}/events", h.List)
+	return r
+}
+
+func serve(r http.Handler, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	return w
+}
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestHandler_List(t *testing.T) {
+	t.Parallel()
+
+	amount, estimate := 0.26, 1.6
+	var gotCode string
+	var gotFrom, gotTo time.Time
+	uc := &mockUsecase{ListFunc: func(ctx context.Context, code string, from, to time.Time) (events.Calendar, error) {
+		gotCode, gotFrom, gotTo = code, from, to
+		return events.Calendar{
+			From: day(2026, 1, 1),
+			To:   day(2026, 6, 30),
+			Events: []events.Event{
+				{SymbolCode: "AAPL", Type: events.TypeDividend, Date: day(2026, 2, 9), Value: &amount, Source: "twelvedata"},
+				{SymbolCode: "AAPL", Type: events.TypeEarnings, Date: day(2026, 4, 30), Estimate: &estimate, Source: "twelvedata"},
+			},
+		}, nil
+	}}
+
+	w := serve(newRouter(uc), "/symbols/AAPL/events?from=2026-01-01&to=2026-06-30")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "AAPL", gotCode)
+	assert.Equal(t, day(2026, 1, 1), gotFrom)
+	assert.Equal(t, day(2026, 6, 30), gotTo)
+	assert.JSONEq(t, `{
+		"symbol": "AAPL",
+		"from": "2026-01-01",
+		"to": "2026-06-30",
+		"events": [
+			{"type": "dividend", "date": "2026-02-09", "value": 0.26, "estimate": null, "source": "twelvedata"},
+			{"type": "earnings", "date": "2026-04-30", "value": null, "estimate": 1.6, "source": "twelvedata"}
+		]
+	}`, w.Body.String())
+}
+
+func TestHandler_List_DefaultsAndEmpty(t *testing.T) {
+	t.Parallel()
+
+	uc := &mockUsecase{ListFunc: func(ctx context.Context, code string, from, to time.Time) (events.Calendar, error) {
+		assert.True(t, from.IsZero(), "未指定は usecase が既定値を補う")
+		assert.True(t, to.IsZero())
+		return events.Calendar{From: day(2025, 6, 1), To: day(2026, 8, 30)}, nil
+	}}
+
+	w := serve(newRouter(uc), "/symbols/AAPL/events")
+	require.Equal(t, http.StatusOK, w.Code)
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, []any{}, body["events"], "イベントがなくても空配列を返す")
+	assert.Equal(t, "2025-06-01", body["from"])
+}
+
+func TestHandler_List_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		target string
+		ucErr  error
+		want   int
+	}{
+		{"不正な銘柄コード", "/symbols/AA%20PL/events", nil, http.StatusBadRequest},
+		{"不正な from", "/symbols/AAPL/events?from=2026/01/01", nil, http.StatusBadRequest},
+		{"不正な to", "/symbols/AAPL/events?to=tomorrow", nil, http.StatusBadRequest},
+		{"不正な範囲", "/symbols/AAPL/events?from=2026-02-01&to=2026-01-01", events.ErrInvalidRange, http.StatusBadRequest},
+		{"存在しない銘柄", "/symbols/NOPE/events", events.ErrSymbolNotFound, http.StatusNotFound},
+		{"内部エラー", "/symbols/AAPL/events", errors.New("db down"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			uc := &mockUsecase{ListFunc: func(ctx context.Context, code string, from, to time.Time) (events.Calendar, error) {
+				return events.Calendar{}, tt.ucErr
+			}}
+			w := serve(newRouter(uc), tt.target)
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+const (
+	// DefaultIngestLookback は取り込みで遡る期間です。取り込みは週次のため、遅れて公表・訂正された過去のイベントも拾えるよう長めに取ります。
+	DefaultIngestLookback = 365 * 24 * time.Hour
+	// DefaultIngestLookahead は取り込みで先へ進める期間です（決算発表の予定・発表済みの配当の権利落ち日）。
+	DefaultIngestLookahead = 180 * 24 * time.Hour
+)
+
+// ingestTypes は 1 銘柄ごとに取り込むイベントの種類です（種類ごとに外部 API を 1 回呼び出します）。
+var ingestTypes = []Type{TypeDividend, TypeEarnings}
+
+// Provider は外部 API から銘柄のコーポレートイベントを取得します。
+type Provider interface {
+	// Fetch は銘柄 symbol の種類 typ のイベントのうち、from 〜 to（両端を含む）のものを返します。
+	Fetch(ctx context.Context, symbol string, typ Type, from, to time.Time) ([]Event, error)
+}
+
+// SymbolLister は取り込み対象のアクティブ銘柄のコードを返します。
+type SymbolLister interface {
+	ListActiveCodes(ctx context.Context) ([]string, error)
+}
+
+// RateLimiter は外部 API 呼び出しの待機を抽象化します。
+// Goの慣例に従い、インターフェースは利用者（usecase）側で定義します。
+type RateLimiter interface {
+	WaitIfNeeded(ctx context.Context) error
+}
+
+// IngestResult はイベント取り込みの銘柄単位の集計結果を表します。
+// 一部の種類の取得に失敗した銘柄も、取得できた種類は保存したうえで Failed に数えます。
+type IngestResult struct {
+	Total     int
+	Succeeded int
+	Failed    int
+	Inserted  int // 新規に保存したイベント数
+	Updated   int // 上書きしたイベント数
+}
+
+// FailureRate は失敗率を [0.0, 1.0] で返します。Total が 0 の場合は 0 を返します。
+func (r IngestResult) FailureRate() float64 {
+	if r.Total == 0 {
+		return 0
+	}
+	return float64(r.Failed) / float64(r.Total)
+}
+
+// IngestUsecase はアクティブ銘柄の配当・決算のイベントを外部 API から取得して保存します。
+type IngestUsecase struct {
+	provider    Provider
+	repo        Repository
+	symbols     SymbolLister
+	rateLimiter RateLimiter
+	now         func() time.Time
+}
+
+// NewIngestUsecase は IngestUsecase の新しいインスタンスを生成します。
+func NewIngestUsecase(provider Provider, repo Repository, symbols SymbolLister, rateLimiter RateLimiter) *IngestUsecase {
+	return &IngestUsecase{
+		provider:    provider,
+		repo:        repo,
+		symbols:     symbols,
+		rateLimiter: rateLimiter,
+		now:         time.Now,
+	}
+}
+
+// IngestAll はアクティブ銘柄ごとに、当日の DefaultIngestLookback 前から DefaultIngestLookahead 後までの
+// 配当・決算のイベントを取得して保存します。
+// 銘柄単位の失敗では処理を止めず、保存済みのイベントも保持します。ctx の終了とレートリミッタの待機の失敗でのみ中断します。
+func (u *IngestUsecase) IngestAll(ctx context.Context) (IngestResult, error) {
+	codes, err := u.symbols.ListActiveCodes(ctx)
+	if err != nil {
+		return IngestResult{}, err
+	}
+	today := utcDay(u.now())
+	from, to := today.Add(-DefaultIngestLookback), today.Add(DefaultIngestLookahead)
+
+	result := IngestResult{Total: len(codes)}
+	for _, code := range codes {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		var fetched []Event
+		failed := false
+		for _, typ := range ingestTypes {
+			if err := u.rateLimiter.WaitIfNeeded(ctx); err != nil {
+				return result, err
+			}
+			evs, err := u.provider.Fetch(ctx, code, typ, from, to)
+			if err != nil {
+				slog.Error("failed to fetch events", "symbol", code, "type", typ, "error", err)
+				failed = true
+				continue
+			}
+			fetched = append(fetched, evs...)
+		}
+
+		stats, err := u.repo.Upsert(ctx, normalize(code, fetched))
+		if err != nil {
+			slog.Error("failed to save events", "symbol", code, "error", err)
+			failed = true
+		}
+		result.Inserted += stats.Inserted
+		result.Updated += stats.Updated
+		if failed {
+			result.Failed++
+			continue
+		}
+		result.Succeeded++
+	}
+	return result, nil
+}
+
+// normalize は取得したイベントを銘柄 code・UTC の暦日にそろえ、保存できないものを除き、
+// 同じ種類・日付のイベントを 1 件（後のもの）にまとめます（同じキーを 1 トランザクションで二度書かないため）。
+func normalize(code string, evs []Event) []Event {
+	type key struct {
+		typ  Type
+		date time.Time
+	}
+	index := make(map[key]int, len(evs))
+	out := make([]Event, 0, len(evs))
+	for _, e := range evs {
+		e.SymbolCode = code
+		e.Date = utcDay(e.Date)
+		if err := e.Validate(); err != nil {
+			slog.Warn("skipping invalid event", "symbol", code, "error", err)
+			continue
+		}
+		k := key{e.Type, e.Date}
+		if i, ok := index[k]; ok {
+			out[i] = e
+			continue
+		}
+		index[k] = len(out)
+		out = append(out, e)
+	}
+	return out
+}
@@ -0,0 +1,127 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fetchCall struct {
+	symbol   string
+	typ      Type
+	from, to time.Time
+}
+
+type mockProvider struct {
+	calls     []fetchCall
+	fetchFunc func(symbol string, typ Type) ([]Event, error)
+}
+
+func (m *mockProvider) Fetch(ctx context.Context, symbol string, typ Type, from, to time.Time) ([]Event, error) {
+	m.calls = append(m.calls, fetchCall{symbol, typ, from, to})
+	return m.fetchFunc(symbol, typ)
+}
+
+type stubCodes []string
+
+func (s stubCodes) ListActiveCodes(ctx context.Context) ([]string, error) { return s, nil }
+
+type countingLimiter struct {
+	calls int
+	err   error
+}
+
+func (l *countingLimiter) WaitIfNeeded(ctx context.Context) error {
+	l.calls++
+	return l.err
+}
+
+func newIngest(p Provider, repo Repository, codes []string, rl RateLimiter) *IngestUsecase {
+	uc := NewIngestUsecase(p, repo, stubCodes(codes), rl)
+	uc.now = func() time.Time { return time.Date(2026, 6, 1, 15, 0, 0, 0, time.UTC) }
+	return uc
+}
+
+func TestIngestUsecase_IngestAll(t *testing.T) {
+	t.Parallel()
+
+	provider := &mockProvider{fetchFunc: func(symbol string, typ Type) ([]Event, error) {
+		if typ == TypeDividend {
+			return []Event{{Type: TypeDividend, Date: day(2026, 5, 11), Value: ptr(0.27), Source: "twelvedata"}}, nil
+		}
+		return []Event{
+			{Type: TypeEarnings, Date: day(2026, 4, 30), Value: ptr(1.65), Estimate: ptr(1.6), Source: "twelvedata"},
+			{Type: TypeEarnings, Date: day(2026, 7, 30), Estimate: ptr(1.7), Source: "twelvedata"},
+		}, nil
+	}}
+	repo := &mockRepository{}
+	rl := &countingLimiter{}
+
+	result, err := newIngest(provider, repo, []string{"AAPL", "MSFT"}, rl).IngestAll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, IngestResult{Total: 2, Succeeded: 2, Inserted: 6}, result)
+	assert.Equal(t, 4, rl.calls, "銘柄 × 種類ごとに 1 回待機する")
+
+	// 取り込み範囲は当日の 1 年前〜180 日後
+	require.Len(t, provider.calls, 4)
+	assert.Equal(t, fetchCall{"AAPL", TypeDividend, day(2025, 6, 1), day(2026, 11, 28)}, provider.calls[0])
+	assert.Equal(t, TypeEarnings, provider.calls[1].typ)
+
+	// 取得したイベントには銘柄コードを付けて保存する
+	require.Len(t, repo.upserts, 2)
+	for _, e := range repo.upserts[1] {
+		assert.Equal(t, "MSFT", e.SymbolCode)
+	}
+}
+
+func TestIngestUsecase_IngestAll_PartialFailure(t *testing.T) {
+	t.Parallel()
+
+	// AAPL は決算の取得に失敗（取得できた配当は保存する）、MSFT は保存に失敗
+	provider := &mockProvider{fetchFunc: func(symbol string, typ Type) ([]Event, error) {
+		if symbol == "AAPL" && typ == TypeEarnings {
+			return nil, errors.New("earnings is available exclusively with grow or pro plans")
+		}
+		return []Event{{Type: typ, Date: day(2026, 5, 11), Value: ptr(1), Source: "twelvedata"}}, nil
+	}}
+	repo := &mockRepository{upsertFunc: func(events []Event) (UpsertStats, error) {
+		if events[0].SymbolCode == "MSFT" {
+			return UpsertStats{}, errors.New("db down")
+		}
+		return UpsertStats{Updated: len(events)}, nil
+	}}
+
+	result, err := newIngest(provider, repo, []string{"AAPL", "MSFT", "GOOG"}, &countingLimiter{}).IngestAll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, IngestResult{Total: 3, Succeeded: 1, Failed: 2, Updated: 3}, result)
+	assert.InDelta(t, 2.0/3.0, result.FailureRate(), 1e-9)
+	require.Len(t, repo.upserts[0], 1)
+	assert.Equal(t, TypeDividend, repo.upserts[0][0].Type)
+}
+
+func TestIngestUsecase_IngestAll_RateLimiterAborts(t *testing.T) {
+	t.Parallel()
+
+	provider := &mockProvider{fetchFunc: func(string, Type) ([]Event, error) { return nil, nil }}
+	_, err := newIngest(provider, &mockRepository{}, []string{"AAPL"}, &countingLimiter{err: context.Canceled}).IngestAll(context.Background())
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, provider.calls)
+}
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	got := normalize("AAPL", []Event{
+		{Type: TypeEarnings, Date: time.Date(2026, 4, 30, 20, 30, 0, 0, time.UTC), Estimate: ptr(1.6), Source: "twelvedata"},
+		{Type: "split", Date: day(2026, 5, 1), Source: "twelvedata"},
+		{Type: TypeDividend, Date: day(2026, 5, 11), Value: ptr(0.26), Source: "twelvedata"},
+		{Type: TypeEarnings, Date: day(2026, 4, 30), Value: ptr(1.65), Estimate: ptr(1.6), Source: "twelvedata"},
+	})
+	require.Len(t, got, 2, "未対応の種類を除き、同じ種類・日付は 1 件にまとめる")
+	assert.Equal(t, Event{SymbolCode: "AAPL", Type: TypeEarnings, Date: day(2026, 4, 30), Value: ptr(1.65), Estimate: ptr(1.6), Source: "twelvedata"}, got[0])
+	assert.Equal(t, TypeDividend, got[1].Type)
+}
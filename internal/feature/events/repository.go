@@ -0,0 +1,97 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/events/sqlc"
+)
+
+// repository は Repository の sqlc ベース実装です。
+type repository struct {
+	db *sql.DB
+	q  *eventssqlc.Queries
+}
+
+var _ Repository = (*repository)(nil)
+
+// NewRepository は指定された *sql.DB で repository の新しいインスタンスを生成します。
+func NewRepository(db *sql.DB) *repository {
+	return &repository{db: db, q: eventssqlc.New(db)}
+}
+
+// Upsert は (銘柄, 種類, 日付) をキーにイベントを挿入または上書きし、新規挿入・上書きの件数を返します。
+// 1 銘柄のイベントは数十件程度のため、1 トランザクション内で 1 件ずつ Upsert します（途中で失敗した場合は全件を戻します）。
+// 同じキーのイベントを重複して渡した場合は後のものが残ります。
+func (r *repository) Upsert(ctx context.Context, events []Event) (UpsertStats, error) {
+	if len(events) == 0 {
+		return UpsertStats{}, nil
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return UpsertStats{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	q := r.q.WithTx(tx)
+	var stats UpsertStats
+	for _, e := range events {
+		inserted, err := q.UpsertEvent(ctx, eventssqlc.UpsertEventParams{
+			SymbolCode: e.SymbolCode,
+			Type:       string(e.Type),
+			Date:       e.Date,
+			Value:      nullFloat(e.Value),
+			Estimate:   nullFloat(e.Estimate),
+			Source:     e.Source,
+		})
+		if err != nil {
+			return UpsertStats{}, fmt.Errorf("upsert event %s %s %s: %w", e.SymbolCode, e.Type, e.Date.Format(time.DateOnly), err)
+		}
+		if inserted {
+			stats.Inserted++
+		} else {
+			stats.Updated++
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return UpsertStats{}, err
+	}
+	return stats, nil
+}
+
+// List は銘柄の from 〜 to（両端を含む）のイベントを日付・種類の順に返します。
+func (r *repository) List(ctx context.Context, symbol string, from, to time.Time) ([]Event, error) {
+	rows, err := r.q.ListEvents(ctx, eventssqlc.ListEventsParams{SymbolCode: symbol, FromDate: from, ToDate: to})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Event, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, Event{
+			SymbolCode: row.SymbolCode,
+			Type:       Type(row.Type),
+			Date:       utcDay(row.Date),
+			Value:      floatPtr(row.Value),
+			Estimate:   floatPtr(row.Estimate),
+			Source:     row.Source,
+		})
+	}
+	return out, nil
+}
+
+func nullFloat(v *float64) sql.NullFloat64 {
+	if v == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: *v, Valid: true}
+}
+
+func floatPtr(v sql.NullFloat64) *float64 {
+	if !v.Valid {
+		return nil
+	}
+	f := v.Float64
+	return &f
+}
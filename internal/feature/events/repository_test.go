@@ -0,0 +1,108 @@
+package events
+
+import (
+	"context"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/db/dbtest"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/testsupport"
+)
+
+func TestMain(m *testing.M) {
+	code, err := dbtest.RunMainWithPostgres(m)
+	if err != nil {
+		log.Fatalf("dbtest setup: %v", err)
+	}
+	os.Exit(code)
+}
+
+func TestRepository_Upsert_Idempotent(t *testing.T) {
+	t.Parallel()
+	db := testsupport.NewDB(t, testsupport.InsertSymbols("AAPL", "MSFT"))
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	evs := []Event{
+		{SymbolCode: "AAPL", Type: TypeDividend, Date: day(2026, 5, 11), Value: ptr(0.26), Source: "twelvedata"},
+		{SymbolCode: "AAPL", Type: TypeEarnings, Date: day(2026, 5, 11), Estimate: ptr(1.6), Source: "twelvedata"},
+	}
+	stats, err := repo.Upsert(ctx, evs)
+	require.NoError(t, err)
+	assert.Equal(t, UpsertStats{Inserted: 2}, stats, "同じ日付でも種類が違えば別のイベント")
+
+	var updatedAt time.Time
+	require.NoError(t, db.QueryRowContext(ctx,
+		`SELECT updated_at FROM corporate_events WHERE symbol_code = 'AAPL' AND type = 'dividend'`).Scan(&updatedAt))
+
+	// 同じ内容の再取り込みは行を増やさず、updated_at も進めない
+	stats, err = repo.Upsert(ctx, evs)
+	require.NoError(t, err)
+	assert.Equal(t, UpsertStats{Updated: 2}, stats)
+	var count int
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT count(*) FROM corporate_events`).Scan(&count))
+	assert.Equal(t, 2, count)
+	var again time.Time
+	require.NoError(t, db.QueryRowContext(ctx,
+		`SELECT updated_at FROM corporate_events WHERE symbol_code = 'AAPL' AND type = 'dividend'`).Scan(&again))
+	assert.True(t, updatedAt.Equal(again))
+
+	// 決算の発表後は実績で上書きする
+	evs[1].Value = ptr(1.65)
+	_, err = repo.Upsert(ctx, evs[1:])
+	require.NoError(t, err)
+	got, err := repo.List(ctx, "AAPL", day(2026, 5, 11), day(2026, 5, 11))
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	require.NotNil(t, got[1].Value)
+	assert.InDelta(t, 1.65, *got[1].Value, 1e-9)
+}
+
+func TestRepository_Upsert_RollsBackOnError(t *testing.T) {
+	t.Parallel()
+	db := testsupport.NewDB(t, testsupport.InsertSymbols("AAPL", "MSFT"))
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	// 未登録の銘柄（FK 違反）を含むと、同じ呼び出しの他のイベントも保存しない
+	_, err := repo.Upsert(ctx, []Event{
+		{SymbolCode: "AAPL", Type: TypeDividend, Date: day(2026, 5, 11), Value: ptr(0.26), Source: "twelvedata"},
+		{SymbolCode: "UNKNOWN", Type: TypeDividend, Date: day(2026, 5, 11), Value: ptr(1), Source: "twelvedata"},
+	})
+	require.Error(t, err)
+	got, err := repo.List(ctx, "AAPL", day(2026, 1, 1), day(2026, 12, 31))
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestRepository_List_DateRange(t *testing.T) {
+	t.Parallel()
+	db := testsupport.NewDB(t, testsupport.InsertSymbols("AAPL", "MSFT"))
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	_, err := repo.Upsert(ctx, []Event{
+		{SymbolCode: "AAPL", Type: TypeEarnings, Date: day(2026, 1, 29), Value: ptr(2.4), Estimate: ptr(2.35), Source: "twelvedata"},
+		{SymbolCode: "AAPL", Type: TypeDividend, Date: day(2026, 2, 9), Value: ptr(0.26), Source: "twelvedata"},
+		{SymbolCode: "AAPL", Type: TypeEarnings, Date: day(2026, 4, 30), Estimate: ptr(1.6), Source: "twelvedata"},
+		{SymbolCode: "MSFT", Type: TypeDividend, Date: day(2026, 2, 9), Value: ptr(0.83), Source: "twelvedata"},
+	})
+	require.NoError(t, err)
+
+	got, err := repo.List(ctx, "AAPL", day(2026, 1, 29), day(2026, 4, 29))
+	require.NoError(t, err)
+	require.Len(t, got, 2, "両端を含み、他の銘柄は含まない")
+	assert.Equal(t, Event{SymbolCode: "AAPL", Type: TypeEarnings, Date: day(2026, 1, 29), Value: ptr(2.4), Estimate: ptr(2.35), Source: "twelvedata"}, got[0])
+	assert.Equal(t, TypeDividend, got[1].Type)
+	assert.Nil(t, got[1].Estimate)
+
+	got, err = repo.List(ctx, "AAPL", day(2026, 4, 30), day(2026, 12, 31))
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Nil(t, got[0].Value, "未発表の実績は NULL")
+}
@@ -0,0 +1,24 @@
+-- name: UpsertEvent :one
+-- (symbol_code, type, "date") をキーに上書きする。値が変わらない再取り込みでは updated_at を進めない。
+-- 戻り値は新規挿入（xmax = 0）かどうか。
+INSERT INTO corporate_events (symbol_code, type, "date", value, estimate, source)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (symbol_code, type, "date") DO UPDATE
+SET value = EXCLUDED.value,
+    estimate = EXCLUDED.estimate,
+    source = EXCLUDED.source,
+    updated_at = CASE
+        WHEN (corporate_events.value, corporate_events.estimate, corporate_events.source)
+             IS DISTINCT FROM (EXCLUDED.value, EXCLUDED.estimate, EXCLUDED.source)
+        THEN now()
+        ELSE corporate_events.updated_at
+    END
+RETURNING (xmax = 0) AS inserted;
+
+-- name: ListEvents :many
+-- 銘柄の from 〜 to（両端を含む）のイベントを日付・種類の順に返す。
+SELECT symbol_code, type, "date", value, estimate, source
+FROM corporate_events
+WHERE symbol_code = sqlc.arg(symbol_code)
+  AND "date" BETWEEN sqlc.arg(from_date)::date AND sqlc.arg(to_date)::date
+ORDER BY "date", type;
@@ -0,0 +1,94 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultLookback は一覧の from 未指定時に遡る期間です（当日から）。
+	DefaultLookback = 365 * 24 * time.Hour
+	// DefaultLookahead は一覧の to 未指定時に先へ進める期間です（当日から。決算の予定を含めるため）。
+	DefaultLookahead = 90 * 24 * time.Hour
+	// MaxRange は一覧で指定可能な日付範囲の最大幅です。
+	MaxRange = 5 * 366 * 24 * time.Hour
+)
+
+// Repository はコーポレートイベントの永続化層を抽象化します。
+type Repository interface {
+	// Upsert は (銘柄, 種類, 日付) をキーにイベントを挿入または上書きします。
+	Upsert(ctx context.Context, events []Event) (UpsertStats, error)
+	// List は銘柄の from 〜 to（両端を含む）のイベントを日付・種類の順に返します。
+	List(ctx context.Context, symbol string, from, to time.Time) ([]Event, error)
+}
+
+// ActiveSymbolChecker はアクティブ銘柄かどうかの判定を行うインターフェースです。
+// events usecase が symbollist feature に直接依存しないよう、
+// 最小限の読み取り専用インターフェースをここで定義します。
+type ActiveSymbolChecker interface {
+	Contains(ctx context.Context, code string) (bool, error)
+}
+
+// Calendar は銘柄の日付範囲のイベント一覧です。From / To は既定値を補い UTC の暦日にそろえた範囲です。
+type Calendar struct {
+	From   time.Time
+	To     time.Time
+	Events []Event
+}
+
+// usecase はコーポレートイベントの参照のビジネスロジックを提供します。
+type usecase struct {
+	repo    Repository
+	symbols ActiveSymbolChecker
+	now     func() time.Time
+}
+
+// NewUsecase は usecase の新しいインスタンスを生成します。
+func NewUsecase(repo Repository, symbols ActiveSymbolChecker) *usecase {
+	return &usecase{repo: repo, symbols: symbols, now: time.Now}
+}
+
+// List は銘柄 code の from 〜 to（両端を含む、UTC の暦日）のイベントを日付順に、補った範囲とともに返します。
+// from / to がゼロ値の場合はそれぞれ当日の DefaultLookback 前・DefaultLookahead 後とします。
+// from が to より後、または範囲が MaxRange を超える場合は ErrInvalidRange、
+// 銘柄がアクティブでない場合は ErrSymbolNotFound を返します。
+func (u *usecase) List(ctx context.Context, code string, from, to time.Time) (Calendar, error) {
+	today := utcDay(u.now())
+	if from.IsZero() {
+		from = today.Add(-DefaultLookback)
+	}
+	if to.IsZero() {
+		to = today.Add(DefaultLookahead)
+	}
+	from, to = utcDay(from), utcDay(to)
+	if from.After(to) {
+		return Calendar{}, fmt.Errorf("%w: from must not be after to", ErrInvalidRange)
+	}
+	if to.Sub(from) > MaxRange {
+		return Calendar{}, fmt.Errorf("%w: range must be at most %d days", ErrInvalidRange, int(MaxRange.Hours()/24))
+	}
+
+	active, err := u.symbols.Contains(ctx, code)
+	if err != nil {
+		return Calendar{}, fmt.Errorf("checking symbol %s: %w", code, err)
+	}
+	if !active {
+		return Calendar{}, ErrSymbolNotFound
+	}
+	evs, err := u.Between(ctx, code, from, to)
+	if err != nil {
+		return Calendar{}, err
+	}
+	return Calendar{From: from, To: to, Events: evs}, nil
+}
+
+// Between は銘柄 code の from 〜 to（両端を含む）のイベントを、銘柄・範囲の検証なしに返します。
+// ローソク足への重ね合わせなど、解決済みの銘柄と取得済みの足の範囲で呼び出す場合に使います。
+func (u *usecase) Between(ctx context.Context, code string, from, to time.Time) ([]Event, error) {
+	evs, err := u.repo.List(ctx, code, utcDay(from), utcDay(to))
+	if err != nil {
+		return nil, fmt.Errorf("list events %s: %w", code, err)
+	}
+	return evs, nil
+}
@@ -0,0 +1,136 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockRepository struct {
+	events  []Event
+	listErr error
+	upserts [][]Event
+	// upsertFunc が設定されていれば Upsert はその結果を返す
+	upsertFunc func(events []Event) (UpsertStats, error)
+	lastFrom   time.Time
+	lastTo     time.Time
+}
+
+func (m *mockRepository) Upsert(ctx context.Context, events []Event) (UpsertStats, error) {
+	m.upserts = append(m.upserts, events)
+	if m.upsertFunc != nil {
+		return m.upsertFunc(events)
+	}
+	return UpsertStats{Inserted: len(events)}, nil
+}
+
+func (m *mockRepository) List(ctx context.Context, symbol string, from, to time.Time) ([]Event, error) {
+	m.lastFrom, m.lastTo = from, to
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+	var out []Event
+	for _, e := range m.events {
+		if e.SymbolCode == symbol && !e.Date.Before(from) && !e.Date.After(to) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+type stubSymbols map[string]bool
+
+func (s stubSymbols) Contains(ctx context.Context, code string) (bool, error) {
+	return s[code], nil
+}
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func ptr(v float64) *float64 { return &v }
+
+func TestUsecase_List(t *testing.T) {
+	t.Parallel()
+
+	repo := &mockRepository{events: []Event{
+		{SymbolCode: "AAPL", Type: TypeDividend, Date: day(2026, 2, 9), Value: ptr(0.26), Source: "twelvedata"},
+		{SymbolCode: "AAPL", Type: TypeEarnings, Date: day(2026, 4, 30), Estimate: ptr(1.6), Source: "twelvedata"},
+		{SymbolCode: "AAPL", Type: TypeDividend, Date: day(2026, 5, 11), Value: ptr(0.27), Source: "twelvedata"},
+		{SymbolCode: "MSFT", Type: TypeDividend, Date: day(2026, 5, 14), Value: ptr(0.83), Source: "twelvedata"},
+	}}
+	uc := NewUsecase(repo, stubSymbols{"AAPL": true, "MSFT": true})
+	uc.now = func() time.Time { return time.Date(2026, 6, 1, 15, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     []time.Time
+		wantFrom time.Time
+		wantTo   time.Time
+	}{
+		{"両端を含む", day(2026, 2, 9), day(2026, 4, 30), []time.Time{day(2026, 2, 9), day(2026, 4, 30)}, day(2026, 2, 9), day(2026, 4, 30)},
+		{"範囲外は含まない", day(2026, 2, 10), day(2026, 4, 29), nil, day(2026, 2, 10), day(2026, 4, 29)},
+		{"時刻は UTC の暦日にそろえる", time.Date(2026, 5, 11, 23, 0, 0, 0, time.UTC), time.Date(2026, 5, 11, 1, 0, 0, 0, time.UTC), []time.Time{day(2026, 5, 11)}, day(2026, 5, 11), day(2026, 5, 11)},
+		{"未指定は当日の 1 年前〜90 日後", time.Time{}, time.Time{}, []time.Time{day(2026, 2, 9), day(2026, 4, 30), day(2026, 5, 11)}, day(2025, 6, 1), day(2026, 8, 30)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cal, err := uc.List(context.Background(), "AAPL", tt.from, tt.to)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFrom, cal.From)
+			assert.Equal(t, tt.wantTo, cal.To)
+			var got []time.Time
+			for _, e := range cal.Events {
+				assert.Equal(t, "AAPL", e.SymbolCode)
+				got = append(got, e.Date)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantFrom, repo.lastFrom)
+			assert.Equal(t, tt.wantTo, repo.lastTo)
+		})
+	}
+}
+
+func TestUsecase_List_Errors(t *testing.T) {
+	t.Parallel()
+
+	dbErr := errors.New("db down")
+	tests := []struct {
+		name     string
+		code     string
+		from, to time.Time
+		listErr  error
+		wantErr  error
+	}{
+		{"from が to より後", "AAPL", day(2026, 5, 2), day(2026, 5, 1), nil, ErrInvalidRange},
+		{"範囲が上限を超える", "AAPL", day(2020, 1, 1), day(2026, 1, 1), nil, ErrInvalidRange},
+		{"アクティブでない銘柄", "DELISTED", day(2026, 1, 1), day(2026, 2, 1), nil, ErrSymbolNotFound},
+		{"リポジトリのエラー", "AAPL", day(2026, 1, 1), day(2026, 2, 1), dbErr, dbErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			uc := NewUsecase(&mockRepository{listErr: tt.listErr}, stubSymbols{"AAPL": true})
+			_, err := uc.List(context.Background(), tt.code, tt.from, tt.to)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestUsecase_Between_SkipsRangeLimit(t *testing.T) {
+	t.Parallel()
+
+	// ローソク足の重ね合わせは取得済みの足の範囲（上限を超えうる）でそのまま引く
+	repo := &mockRepository{events: []Event{
+		{SymbolCode: "AAPL", Type: TypeDividend, Date: day(2012, 8, 9), Value: ptr(2.65), Source: "twelvedata"},
+	}}
+	uc := NewUsecase(repo, stubSymbols{})
+	evs, err := uc.Between(context.Background(), "AAPL", day(2010, 1, 1), day(2026, 1, 1))
+	require.NoError(t, err)
+	assert.Len(t, evs, 1)
+}
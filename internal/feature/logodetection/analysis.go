@@ -1,7 +1,18 @@
 package logodetection
 
+import "time"
+
 // CompanyAnalysis は企業の分析結果を表します。
 type CompanyAnalysis struct {
-	CompanyName string // 分析対象の企業名
-	Summary     string // AI生成の分析サマリー
+	CompanyName string    // 分析対象の企業名
+	Summary     string    // AI生成の分析サマリー
+	GeneratedAt time.Time // サマリーを生成した日時（キャッシュから返した場合は元の生成日時）
+	Stale       bool      // キャッシュの鮮度期限を過ぎたサマリーを返したか（バックグラウンドで再生成中）
+}
+
+// Analysis は生成日時付きの分析サマリーです（AnalysisSource の戻り値）。
+type Analysis struct {
+	Summary     string
+	GeneratedAt time.Time
+	Stale       bool
 }
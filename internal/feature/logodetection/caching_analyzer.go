@@ -0,0 +1,270 @@
+package logodetection
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// DefaultAnalysisSoftTTL はキャッシュした分析をそのまま返す期間です。
+	DefaultAnalysisSoftTTL = 24 * time.Hour
+	// DefaultAnalysisHardTTL はキャッシュした分析を（再生成を待たずに）返せる上限です。
+	DefaultAnalysisHardTTL = 7 * 24 * time.Hour
+	// DefaultAnalysisRefreshConcurrency は同時に走らせるバックグラウンド再生成の既定の上限です。
+	DefaultAnalysisRefreshConcurrency = 2
+)
+
+// analysisFetchTimeout は 1 回の分析の生成（Gemini の呼び出しとキャッシュ書き込み）にかける時間の上限です。
+const analysisFetchTimeout = 60 * time.Second
+
+// RedisProvider は現在利用できる Redis クライアントを返します。障害中は nil を返します。
+type RedisProvider interface {
+	Client() *redis.Client
+}
+
+// AnalysisCacheConfig は企業分析のキャッシュ（stale-while-revalidate）の設定です。
+//
+// 生成から SoftTTL 未満の分析はそのまま返し、SoftTTL 以上 HardTTL 未満の分析は古いまま即座に返しつつ
+// バックグラウンドで再生成します。HardTTL 以上経過した分析は返さず、再生成を待ちます。
+type AnalysisCacheConfig struct {
+	SoftTTL time.Duration // 0 以下の場合は DefaultAnalysisSoftTTL
+	HardTTL time.Duration // SoftTTL 以下の場合は DefaultAnalysisHardTTL（と SoftTTL の大きい方）
+	// MaxConcurrentRefresh は同時に走らせるバックグラウンド再生成の上限です。上限に達している間の古いヒットでは再生成しません。
+	// 0 以下の場合は DefaultAnalysisRefreshConcurrency を使います。
+	MaxConcurrentRefresh int
+}
+
+func (c AnalysisCacheConfig) withDefaults() AnalysisCacheConfig {
+	if c.SoftTTL <= 0 {
+		c.SoftTTL = DefaultAnalysisSoftTTL
+	}
+	if c.HardTTL <= c.SoftTTL {
+		c.HardTTL = max(DefaultAnalysisHardTTL, c.SoftTTL)
+	}
+	if c.MaxConcurrentRefresh <= 0 {
+		c.MaxConcurrentRefresh = DefaultAnalysisRefreshConcurrency
+	}
+	return c
+}
+
+// AnalysisCacheStats はキャッシュの累計です。
+type AnalysisCacheStats struct {
+	Hits      uint64 // SoftTTL 内のヒット
+	StaleHits uint64 // 古い分析を返したヒット
+	Misses    uint64 // 再生成を待ったリクエスト（未保存・HardTTL 超過・Redis 障害）
+	Refreshed uint64 // バックグラウンドで再生成して書き換えた回数
+	Skipped   uint64 // 同時実行数の上限により再生成を見送った古いヒット
+	Failed    uint64 // 失敗したバックグラウンド再生成（古い分析はそのまま残る）
+}
+
+// cachedAnalysis はキャッシュに保存する分析です。
+type cachedAnalysis struct {
+	Summary     string    `json:"summary"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// CachingAnalyzer は CompanyAnalyzer に stale-while-revalidate の Redis キャッシュをデコレータパターンで追加します。
+// キャッシュはプロンプトのハッシュごとのキー（<prefix>:<sha256>）に JSON で保存するため、
+// プロンプトのテンプレートを変更すると以前の分析は使われなくなります。
+type CachingAnalyzer struct {
+	inner    CompanyAnalyzer
+	rdb      *redis.Client
+	provider RedisProvider
+	prefix   string
+	cfg      AnalysisCacheConfig
+	now      func() time.Time
+
+	group singleflight.Group // 同じプロンプトの同時のミスを 1 回の生成にまとめる
+	sem   chan struct{}
+
+	mu       sync.Mutex
+	inflight map[string]struct{} // バックグラウンド再生成中のキー
+	wg       sync.WaitGroup
+
+	hits, staleHits, misses, refreshed, skipped, failed atomic.Uint64
+}
+
+var _ AnalysisSource = (*CachingAnalyzer)(nil)
+
+// NewCachingAnalyzer は inner にキャッシュを追加する CachingAnalyzer を生成します。
+// prefix は環境の名前空間を含むキーの接頭辞（例: "staging:analysis"）です。
+// rdb が nil の場合はキャッシュせず、毎回 inner で生成します。
+func NewCachingAnalyzer(rdb *redis.Client, inner CompanyAnalyzer, prefix string, cfg AnalysisCacheConfig) *CachingAnalyzer {
+	cfg = cfg.withDefaults()
+	return &CachingAnalyzer{
+		inner:    inner,
+		rdb:      rdb,
+		prefix:   prefix,
+		cfg:      cfg,
+		now:      time.Now,
+		sem:      make(chan struct{}, cfg.MaxConcurrentRefresh),
+		inflight: map[string]struct{}{},
+	}
+}
+
+// WithRedisProvider は Redis クライアントを呼び出しごとに p から取得するよう設定します。
+// p が nil を返す間はキャッシュせず、毎回 inner で生成します。
+func (c *CachingAnalyzer) WithRedisProvider(p RedisProvider) *CachingAnalyzer {
+	c.provider = p
+	return c
+}
+
+func (c *CachingAnalyzer) client() *redis.Client {
+	if c.provider != nil {
+		return c.provider.Client()
+	}
+	return c.rdb
+}
+
+// Stats はキャッシュの累計を返します。
+func (c *CachingAnalyzer) Stats() AnalysisCacheStats {
+	return AnalysisCacheStats{
+		Hits:      c.hits.Load(),
+		StaleHits: c.staleHits.Load(),
+		Misses:    c.misses.Load(),
+		Refreshed: c.refreshed.Load(),
+		Skipped:   c.skipped.Load(),
+		Failed:    c.failed.Load(),
+	}
+}
+
+// Analysis はプロンプトに対する分析を返します。
+// SoftTTL 内ならキャッシュを、HardTTL 内なら古い分析（Stale=true）を返しつつバックグラウンドで再生成し、
+// それ以外は inner で生成してキャッシュします。
+func (c *CachingAnalyzer) Analysis(ctx context.Context, prompt string) (Analysis, error) {
+	key := c.key(prompt)
+	if rdb := c.client(); rdb != nil {
+		if cached, ok := c.load(ctx, rdb, key); ok {
+			age := c.now().Sub(cached.GeneratedAt)
+			switch {
+			case age < c.cfg.SoftTTL:
+				c.hits.Add(1)
+				return Analysis{Summary: cached.Summary, GeneratedAt: cached.GeneratedAt}, nil
+			case age < c.cfg.HardTTL:
+				c.staleHits.Add(1)
+				c.refreshInBackground(ctx, key, prompt)
+				return Analysis{Summary: cached.Summary, GeneratedAt: cached.GeneratedAt, Stale: true}, nil
+			}
+		}
+	}
+	c.misses.Add(1)
+	return c.fetchShared(ctx, key, prompt)
+}
+
+// fetchShared は同じキーの同時の生成を 1 回にまとめます。待機中の呼び出し元は自身の ctx の終了で待機をやめます。
+// 生成は最初の呼び出し元の ctx のキャンセルを引き継がない（待機中の他の呼び出し元を巻き込まない）ため、
+// 時間の上限は analysisFetchTimeout で設けます。
+func (c *CachingAnalyzer) fetchShared(ctx context.Context, key, prompt string) (Analysis, error) {
+	ch := c.group.DoChan(key, func() (any, error) {
+		fctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), analysisFetchTimeout)
+		defer cancel()
+		return c.fetch(fctx, key, prompt)
+	})
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return Analysis{}, res.Err
+		}
+		return res.Val.(Analysis), nil
+	case <-ctx.Done():
+		return Analysis{}, ctx.Err()
+	}
+}
+
+// fetch は inner で分析を生成し、キャッシュに保存します（保存はベストエフォート）。
+func (c *CachingAnalyzer) fetch(ctx context.Context, key, prompt string) (Analysis, error) {
+	summary, err := c.inner.Analyze(ctx, prompt)
+	if err != nil {
+		return Analysis{}, err
+	}
+	a := Analysis{Summary: summary, GeneratedAt: c.now()}
+	c.store(ctx, key, a)
+	return a, nil
+}
+
+// refreshInBackground は key のバックグラウンド再生成を開始します。
+// 同じキーの再生成が実行中の場合、同時実行数の上限に達している場合は何もしません。
+// 再生成はクライアントのキャンセルの影響を受けないよう、ctx から切り離したコンテキストで行います。
+// 失敗しても古い分析はそのまま残り、HardTTL までは次の古いヒットで再び再生成を試みます。
+func (c *CachingAnalyzer) refreshInBackground(ctx context.Context, key, prompt string) {
+	c.mu.Lock()
+	if _, ok := c.inflight[key]; ok {
+		c.mu.Unlock()
+		return
+	}
+	select {
+	case c.sem <- struct{}{}:
+	default:
+		c.mu.Unlock()
+		c.skipped.Add(1)
+		return
+	}
+	c.inflight[key] = struct{}{}
+	c.mu.Unlock()
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer func() {
+			c.mu.Lock()
+			delete(c.inflight, key)
+			c.mu.Unlock()
+			<-c.sem
+		}()
+
+		bctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), analysisFetchTimeout)
+		defer cancel()
+		if _, err, _ := c.group.Do(key, func() (any, error) { return c.fetch(bctx, key, prompt) }); err != nil {
+			c.failed.Add(1)
+			slog.Warn("company analysis background refresh failed", "component", "cache", "key", key, "error", err)
+			return
+		}
+		c.refreshed.Add(1)
+	}()
+}
+
+// load はキャッシュから分析を読み込みます。ミス・破損・Redis 障害時は ok=false を返します。
+func (c *CachingAnalyzer) load(ctx context.Context, rdb *redis.Client, key string) (cachedAnalysis, bool) {
+	b, err := rdb.Get(ctx, key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			slog.WarnContext(ctx, "company analysis cache read failed", "component", "cache", "key", key, "error", err)
+		}
+		return cachedAnalysis{}, false
+	}
+	var cached cachedAnalysis
+	if err := json.Unmarshal(b, &cached); err != nil || cached.GeneratedAt.IsZero() {
+		return cachedAnalysis{}, false
+	}
+	return cached, true
+}
+
+// store は分析を HardTTL の期限で保存します（ベストエフォート）。
+func (c *CachingAnalyzer) store(ctx context.Context, key string, a Analysis) {
+	rdb := c.client()
+	if rdb == nil {
+		return
+	}
+	b, err := json.Marshal(cachedAnalysis{Summary: a.Summary, GeneratedAt: a.GeneratedAt})
+	if err != nil {
+		return
+	}
+	if err := rdb.Set(ctx, key, b, c.cfg.HardTTL).Err(); err != nil {
+		slog.WarnContext(ctx, "failed to cache company analysis", "component", "cache", "key", key, "error", err)
+	}
+}
+
+func (c *CachingAnalyzer) key(prompt string) string {
+	sum := sha256.Sum256([]byte(prompt))
+	return c.prefix + ":" + hex.EncodeToString(sum[:])
+}
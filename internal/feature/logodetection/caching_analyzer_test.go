@@ -0,0 +1,231 @@
+package logodetection
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/testsupport"
+)
+
+// scriptedAnalyzer は呼び出しごとに連番のサマリーを返す CompanyAnalyzer です。
+// gate を設定すると、閉じられるまで応答を返しません。err を設定すると失敗を返します。
+type scriptedAnalyzer struct {
+	calls atomic.Int32
+	gate  chan struct{}
+	err   atomic.Pointer[error]
+}
+
+func (a *scriptedAnalyzer) Analyze(ctx context.Context, _ string) (string, error) {
+	n := a.calls.Add(1)
+	if a.gate != nil {
+		select {
+		case <-a.gate:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	if err := a.err.Load(); err != nil {
+		return "", *err
+	}
+	return "summary-" + string(rune('0'+n)), nil
+}
+
+func (a *scriptedAnalyzer) fail(err error) { a.err.Store(&err) }
+
+// newTestCachingAnalyzer は miniredis 上で SoftTTL 24h・HardTTL 7d の CachingAnalyzer を返します。
+// 時計を進めると miniredis のキーの TTL も同じだけ進みます。
+func newTestCachingAnalyzer(t *testing.T, inner CompanyAnalyzer, maxConcurrent int) (*CachingAnalyzer, *miniredis.Miniredis, *testsupport.Clock) {
+	t.Helper()
+	mr, rdb := testsupport.NewMiniRedis(t)
+	clock := testsupport.NewClock(time.Date(2026, 8, 1, 9, 0, 0, 0, time.UTC))
+	clock.SyncRedis(mr)
+	c := NewCachingAnalyzer(rdb, inner, "test:analysis", AnalysisCacheConfig{MaxConcurrentRefresh: maxConcurrent})
+	c.now = clock.Now
+	return c, mr, clock
+}
+
+func TestCachingAnalyzer_Windows(t *testing.T) {
+	t.Parallel()
+	inner := &scriptedAnalyzer{}
+	c, mr, clock := newTestCachingAnalyzer(t, inner, 1)
+	ctx := context.Background()
+	generated := clock.Now()
+
+	// ミスは生成を待ってキャッシュする（TTL は HardTTL）
+	a, err := c.Analysis(ctx, "prompt")
+	require.NoError(t, err)
+	assert.Equal(t, Analysis{Summary: "summary-1", GeneratedAt: generated}, a)
+	assert.Equal(t, DefaultAnalysisHardTTL, mr.TTL(c.key("prompt")))
+
+	// SoftTTL 内はキャッシュをそのまま返す
+	clock.Advance(23 * time.Hour)
+	a, err = c.Analysis(ctx, "prompt")
+	require.NoError(t, err)
+	assert.Equal(t, Analysis{Summary: "summary-1", GeneratedAt: generated}, a)
+	assert.EqualValues(t, 1, inner.calls.Load())
+
+	// SoftTTL を過ぎると古い分析を即座に返し、バックグラウンドで再生成する
+	clock.Advance(2 * time.Hour)
+	a, err = c.Analysis(ctx, "prompt")
+	require.NoError(t, err)
+	assert.Equal(t, Analysis{Summary: "summary-1", GeneratedAt: generated, Stale: true}, a)
+	c.wg.Wait()
+	assert.EqualValues(t, 2, inner.calls.Load())
+
+	// 再生成後は新しい分析が新鮮なものとして返る
+	a, err = c.Analysis(ctx, "prompt")
+	require.NoError(t, err)
+	assert.Equal(t, Analysis{Summary: "summary-2", GeneratedAt: clock.Now()}, a)
+
+	// HardTTL を過ぎた分析は返さず、生成を待つ
+	clock.Advance(DefaultAnalysisHardTTL)
+	a, err = c.Analysis(ctx, "prompt")
+	require.NoError(t, err)
+	assert.Equal(t, Analysis{Summary: "summary-3", GeneratedAt: clock.Now()}, a)
+
+	assert.Equal(t, AnalysisCacheStats{Hits: 2, StaleHits: 1, Misses: 2, Refreshed: 1}, c.Stats())
+}
+
+func TestCachingAnalyzer_HardTTLWithoutRedisExpiry(t *testing.T) {
+	t.Parallel()
+	inner := &scriptedAnalyzer{}
+	c, mr, clock := newTestCachingAnalyzer(t, inner, 1)
+	ctx := context.Background()
+
+	_, err := c.Analysis(ctx, "prompt")
+	require.NoError(t, err)
+	mr.SetTTL(c.key("prompt"), 30*24*time.Hour) // Redis 側の期限が残っていても生成日時で判定する
+
+	clock.Advance(DefaultAnalysisHardTTL)
+	a, err := c.Analysis(ctx, "prompt")
+	require.NoError(t, err)
+	assert.False(t, a.Stale)
+	assert.Equal(t, "summary-2", a.Summary)
+	assert.EqualValues(t, 2, inner.calls.Load())
+}
+
+func TestCachingAnalyzer_SingleBackgroundRefresh(t *testing.T) {
+	t.Parallel()
+	inner := &scriptedAnalyzer{}
+	c, _, clock := newTestCachingAnalyzer(t, inner, 4)
+	ctx := context.Background()
+
+	_, err := c.Analysis(ctx, "prompt")
+	require.NoError(t, err)
+	clock.Advance(25 * time.Hour)
+
+	// 再生成を止めたまま古いヒットを同時に送る
+	inner.gate = make(chan struct{})
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cctx, cancel := context.WithCancel(ctx)
+			defer cancel() // クライアントのキャンセルは再生成を止めない
+			a, err := c.Analysis(cctx, "prompt")
+			assert.NoError(t, err)
+			assert.True(t, a.Stale)
+			assert.Equal(t, "summary-1", a.Summary)
+		}()
+	}
+	wg.Wait()
+	close(inner.gate)
+	c.wg.Wait()
+
+	assert.EqualValues(t, 2, inner.calls.Load(), "1 回のミスと 1 回の再生成のみ")
+	assert.Equal(t, uint64(20), c.Stats().StaleHits)
+	assert.Equal(t, uint64(1), c.Stats().Refreshed)
+}
+
+func TestCachingAnalyzer_RefreshConcurrencyLimit(t *testing.T) {
+	t.Parallel()
+	inner := &scriptedAnalyzer{}
+	c, _, clock := newTestCachingAnalyzer(t, inner, 1)
+	ctx := context.Background()
+
+	for _, p := range []string{"a", "b"} {
+		_, err := c.Analysis(ctx, p)
+		require.NoError(t, err)
+	}
+	clock.Advance(25 * time.Hour)
+
+	inner.gate = make(chan struct{})
+	_, err := c.Analysis(ctx, "a")
+	require.NoError(t, err)
+	_, err = c.Analysis(ctx, "b") // 上限（1）に達しているため見送る
+	require.NoError(t, err)
+	close(inner.gate)
+	c.wg.Wait()
+
+	stats := c.Stats()
+	assert.Equal(t, uint64(1), stats.Refreshed)
+	assert.Equal(t, uint64(1), stats.Skipped)
+}
+
+func TestCachingAnalyzer_RefreshFailureKeepsStaleCopy(t *testing.T) {
+	t.Parallel()
+	inner := &scriptedAnalyzer{}
+	c, mr, clock := newTestCachingAnalyzer(t, inner, 1)
+	ctx := context.Background()
+	generated := clock.Now()
+
+	_, err := c.Analysis(ctx, "prompt")
+	require.NoError(t, err)
+	before, err := mr.Get(c.key("prompt"))
+	require.NoError(t, err)
+
+	clock.Advance(25 * time.Hour)
+	inner.fail(errors.New("gemini unavailable"))
+	a, err := c.Analysis(ctx, "prompt")
+	require.NoError(t, err, "古い分析を返すため再生成の失敗は呼び出し元に見えない")
+	assert.Equal(t, Analysis{Summary: "summary-1", GeneratedAt: generated, Stale: true}, a)
+	c.wg.Wait()
+
+	after, err := mr.Get(c.key("prompt"))
+	require.NoError(t, err)
+	assert.Equal(t, before, after, "失敗した再生成はキャッシュを書き換えない")
+	assert.Equal(t, uint64(1), c.Stats().Failed)
+
+	// 次の古いヒットで再び再生成を試みる
+	a, err = c.Analysis(ctx, "prompt")
+	require.NoError(t, err)
+	assert.True(t, a.Stale)
+	c.wg.Wait()
+	assert.EqualValues(t, 3, inner.calls.Load())
+	assert.Equal(t, uint64(2), c.Stats().Failed)
+}
+
+func TestCachingAnalyzer_WithoutRedis(t *testing.T) {
+	t.Parallel()
+	inner := &scriptedAnalyzer{}
+	c := NewCachingAnalyzer(nil, inner, "test:analysis", AnalysisCacheConfig{})
+
+	for range 2 {
+		a, err := c.Analysis(context.Background(), "prompt")
+		require.NoError(t, err)
+		assert.False(t, a.Stale)
+	}
+	assert.EqualValues(t, 2, inner.calls.Load(), "Redis がなければ毎回生成する")
+
+	inner.fail(errors.New("gemini unavailable"))
+	_, err := c.Analysis(context.Background(), "prompt")
+	assert.Error(t, err)
+}
+
+func TestAnalysisCacheConfig_WithDefaults(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, AnalysisCacheConfig{SoftTTL: DefaultAnalysisSoftTTL, HardTTL: DefaultAnalysisHardTTL, MaxConcurrentRefresh: DefaultAnalysisRefreshConcurrency},
+		AnalysisCacheConfig{}.withDefaults())
+	// HardTTL が SoftTTL 以下なら既定値に戻す
+	assert.Equal(t, DefaultAnalysisHardTTL, AnalysisCacheConfig{SoftTTL: time.Hour, HardTTL: time.Hour}.withDefaults().HardTTL)
+	assert.Equal(t, 30*24*time.Hour, AnalysisCacheConfig{SoftTTL: 30 * 24 * time.Hour}.withDefaults().HardTTL)
+}
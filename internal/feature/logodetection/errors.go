@@ -0,0 +1,39 @@
+package logodetection
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
+)
+
+// ErrQuotaExceeded はユーザーの日次の利用枠（ロゴ検出・企業分析の回数）を使い切った場合のエラーです。
+// 429 と Retry-After（利用枠が戻るまでの秒数）でクライアントに待機を促します（QuotaExceededError 参照）。
+var ErrQuotaExceeded = apperr.New(apperr.KindRateLimited, "quota_exceeded", "daily quota exceeded")
+
+// QuotaExceededError は ErrQuotaExceeded の詳細（操作・上限・利用枠が戻る時刻）を保持します。
+// errors.Is(err, ErrQuotaExceeded) で判定でき、HTTP 層は RetryAfter を Retry-After ヘッダに、Hint を hint に使います。
+type QuotaExceededError struct {
+	Operation Operation
+	Limit     int
+	ResetAt   time.Time // 利用枠が戻る時刻（翌日の 0 時 UTC）
+	now       time.Time // RetryAfter の基準時刻
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("daily %s quota of %d exceeded", e.Operation, e.Limit)
+}
+
+func (e *QuotaExceededError) Unwrap() error {
+	return ErrQuotaExceeded
+}
+
+// RetryAfter は利用枠が戻るまでの時間を返します。
+func (e *QuotaExceededError) RetryAfter() time.Duration {
+	return e.ResetAt.Sub(e.now)
+}
+
+// Hint はクライアント向けに上限と利用枠が戻る時刻を返します。
+func (e *QuotaExceededError) Hint() string {
+	return fmt.Sprintf("daily %s limit of %d reached; resets at %s", e.Operation, e.Limit, e.ResetAt.UTC().Format(time.RFC3339))
+}
@@ -0,0 +1,149 @@
+package logodetectionhttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/logodetection"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/asyncjob"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+)
+
+// AnalysisJobKind は企業分析のジョブの種類です（asyncjob.Worker に RunAnalysisJob を登録する名前）。
+const AnalysisJobKind = "logo.analyze"
+
+// 失敗したジョブの理由（AnalysisJobResponse.error）です。
+const (
+	jobErrQuotaExceeded  = "quota_exceeded"
+	jobErrAnalysisFailed = "analysis_failed"
+)
+
+// AnalysisJobQueue は企業分析のジョブを登録・参照します（asyncjob.Store が実装）。
+// Goの慣例に従い、インターフェースは利用者（handler）側で定義します。
+type AnalysisJobQueue interface {
+	Enqueue(ctx context.Context, kind string, owner int64, payload any) (asyncjob.Job, error)
+	Get(ctx context.Context, id string, owner int64) (asyncjob.Job, error)
+}
+
+// analysisJobPayload は企業分析のジョブのペイロードです。
+type analysisJobPayload struct {
+	CompanyName string `json:"company_name"`
+}
+
+// WithAnalysisJobs は ?async=true の企業分析をジョブとして q に登録するよう設定します。
+// 設定しない場合、?async=true のリクエストは 503 になります。
+func (h *Handler) WithAnalysisJobs(q AnalysisJobQueue) *Handler {
+	h.jobs = q
+	return h
+}
+
+// enqueueAnalysis は企業名の検証と利用枠の確認をしてから分析をジョブとして登録し、202 とポーリング先を返します。
+// 利用回数はジョブの分析が成功した時点で数えます。
+func (h *Handler) enqueueAnalysis(w http.ResponseWriter, r *http.Request, userID int64, companyName string) {
+	if h.jobs == nil {
+		httpx.WriteJSON(w, http.StatusServiceUnavailable, api.ErrorResponse{Error: "非同期の企業分析は利用できません"})
+		return
+	}
+	if err := h.uc.CheckAnalyze(r.Context(), userID, companyName); err != nil {
+		if errors.Is(err, logodetection.ErrQuotaExceeded) {
+			httpx.WriteError(w, err, "企業分析の利用枠超過")
+			return
+		}
+		slog.Warn("企業分析ジョブの企業名が不正", "error", err, "company", companyName)
+		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "企業名が不正です"})
+		return
+	}
+
+	job, err := h.jobs.Enqueue(r.Context(), AnalysisJobKind, userID, analysisJobPayload{CompanyName: companyName})
+	if errors.Is(err, asyncjob.ErrUnavailable) {
+		httpx.WriteJSON(w, http.StatusServiceUnavailable, api.ErrorResponse{Error: "非同期の企業分析は利用できません"})
+		return
+	}
+	if err != nil {
+		slog.Error("企業分析ジョブの登録に失敗", "error", err, "userID", userID)
+		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
+		return
+	}
+	w.Header().Set("Location", "/v1/logo/analyze/jobs/"+job.ID)
+	httpx.WriteJSON(w, http.StatusAccepted, toJobResponse(job))
+}
+
+// AnalysisJob はログインユーザーが登録した企業分析のジョブの状態と結果を返します。
+//
+// エンドポイント: GET /v1/logo/analyze/jobs/{id}
+// 他のユーザーのジョブ・期限切れのジョブは 404 を返します。
+func (h *Handler) AnalysisJob(w http.ResponseWriter, r *http.Request) {
+	userID, ok := jwt.UserIDFromContext(r.Context())
+	if !ok {
+		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
+		return
+	}
+	if h.jobs == nil {
+		httpx.WriteJSON(w, http.StatusNotFound, api.ErrorResponse{Error: "job not found"})
+		return
+	}
+	job, err := h.jobs.Get(r.Context(), chi.URLParam(r, "id"), userID)
+	switch {
+	case errors.Is(err, asyncjob.ErrNotFound):
+		httpx.WriteJSON(w, http.StatusNotFound, api.ErrorResponse{Error: "job not found"})
+		return
+	case errors.Is(err, asyncjob.ErrUnavailable):
+		httpx.WriteJSON(w, http.StatusServiceUnavailable, api.ErrorResponse{Error: "非同期の企業分析は利用できません"})
+		return
+	case err != nil:
+		slog.Error("企業分析ジョブの取得に失敗", "error", err, "userID", userID)
+		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	httpx.WriteJSON(w, http.StatusOK, toJobResponse(job))
+}
+
+// RunAnalysisJob は企業分析のジョブを処理します（asyncjob.Worker の AnalysisJobKind の Handler）。
+// 結果は api.CompanyAnalysisResponse です。利用枠の超過は再試行せず、その他の失敗は詳細をログに残して
+// analysis_failed として返します（Worker が 1 回だけ再試行する）。
+func (h *Handler) RunAnalysisJob(ctx context.Context, job asyncjob.Job) (any, error) {
+	var p analysisJobPayload
+	if err := job.Decode(&p); err != nil {
+		return nil, err
+	}
+	analysis, err := h.uc.AnalyzeCompany(ctx, job.Owner, p.CompanyName)
+	if errors.Is(err, logodetection.ErrQuotaExceeded) {
+		return nil, asyncjob.Permanent(errors.New(jobErrQuotaExceeded))
+	}
+	if err != nil {
+		slog.Error("企業分析ジョブに失敗", "error", err, "job_id", job.ID, "company", p.CompanyName, "attempt", job.Attempts)
+		return nil, errors.New(jobErrAnalysisFailed)
+	}
+	return toAnalysisResponse(analysis), nil
+}
+
+// toJobResponse はジョブを API の応答に変換します。結果は done の場合のみ、理由は failed の場合のみ含めます。
+func toJobResponse(job asyncjob.Job) api.AnalysisJobResponse {
+	res := api.AnalysisJobResponse{
+		Id:        job.ID,
+		Status:    api.AnalysisJobResponseStatus(job.Status),
+		CreatedAt: api.NewTimestamp(job.CreatedAt),
+		UpdatedAt: api.NewTimestamp(job.UpdatedAt),
+	}
+	switch job.Status {
+	case asyncjob.StatusDone:
+		var result api.CompanyAnalysisResponse
+		if err := json.Unmarshal(job.Result, &result); err != nil {
+			slog.Error("企業分析ジョブの結果を解釈できない", "error", err, "job_id", job.ID)
+			res.Status, res.Error = api.Failed, new(jobErrAnalysisFailed)
+			return res
+		}
+		res.Result = &result
+	case asyncjob.StatusFailed:
+		res.Error = new(job.Error)
+	}
+	return res
+}
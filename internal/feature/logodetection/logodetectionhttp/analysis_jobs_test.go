@@ -0,0 +1,215 @@
+package logodetectionhttp_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/logodetection"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/logodetection/logodetectionhttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/asyncjob"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+)
+
+// newJobRouter は h の企業分析のルートを持つルーターと、miniredis を使うジョブの Store を返します。
+func newJobRouter(t *testing.T, h *logodetectionhttp.Handler) (http.Handler, *asyncjob.Store) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	store := asyncjob.NewStore(rdb, "test:jobs", time.Hour)
+	h.WithAnalysisJobs(store)
+
+	r := chi.NewRouter()
+	r.Post("/v1/logo/analyze", h.AnalyzeCompany)
+	r.Get("/v1/logo/analyze/jobs/{id}", h.AnalysisJob)
+	return r, store
+}
+
+// startWorker は h.RunAnalysisJob で企業分析のジョブを処理するワーカーをテストの間起動します。
+func startWorker(t *testing.T, h *logodetectionhttp.Handler, store *asyncjob.Store) {
+	t.Helper()
+	w := asyncjob.NewWorker(store, map[string]asyncjob.Handler{
+		logodetectionhttp.AnalysisJobKind: h.RunAnalysisJob,
+	}, asyncjob.WorkerConfig{Concurrency: 1, RetryDelay: time.Millisecond, PollInterval: 50 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func postAsync(t *testing.T, r http.Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req := withUser(httptest.NewRequest(http.MethodPost, "/v1/logo/analyze?async=true", strings.NewReader(body)))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func getJob(t *testing.T, r http.Handler, path string, userID int64) (int, api.AnalysisJobResponse) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	r.ServeHTTP(w, req.WithContext(jwt.WithUserID(req.Context(), userID)))
+	var res api.AnalysisJobResponse
+	if w.Code == http.StatusOK {
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	}
+	return w.Code, res
+}
+
+// waitJob はジョブが pending でなくなるまでポーリングして返します。
+func waitJob(t *testing.T, r http.Handler, path string) api.AnalysisJobResponse {
+	t.Helper()
+	var res api.AnalysisJobResponse
+	require.Eventually(t, func() bool {
+		var code int
+		code, res = getJob(t, r, path, 1)
+		require.Equal(t, http.StatusOK, code)
+		return res.Status != api.Pending
+	}, 5*time.Second, 10*time.Millisecond)
+	return res
+}
+
+// TestAnalyzeCompany_Async は ?async=true で 202 とジョブの ID を返し、ポーリングで分析の結果を取得できること、
+// 他のユーザーのジョブは 404 になることを検証します。
+func TestAnalyzeCompany_Async(t *testing.T) {
+	t.Parallel()
+	h := logodetectionhttp.NewHandler(&mockUsecase{
+		AnalyzeCompanyFunc: func(ctx context.Context, companyName string) (*logodetection.CompanyAnalysis, error) {
+			assert.Equal(t, "任天堂", companyName)
+			return &logodetection.CompanyAnalysis{
+				CompanyName: "任天堂",
+				Summary:     "任天堂の強みは...",
+				GeneratedAt: time.Date(2026, 8, 1, 9, 0, 0, 0, time.UTC),
+			}, nil
+		},
+	})
+	r, store := newJobRouter(t, h)
+
+	w := postAsync(t, r, `{"company_name":"任天堂"}`)
+	require.Equal(t, http.StatusAccepted, w.Code)
+	var accepted api.AnalysisJobResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &accepted))
+	assert.Equal(t, api.Pending, accepted.Status)
+	assert.Nil(t, accepted.Result)
+	path := w.Header().Get("Location")
+	assert.Equal(t, "/v1/logo/analyze/jobs/"+accepted.Id, path)
+
+	code, pending := getJob(t, r, path, 1)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, api.Pending, pending.Status)
+
+	code, _ = getJob(t, r, path, 2)
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = getJob(t, r, "/v1/logo/analyze/jobs/unknown", 1)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	startWorker(t, h, store)
+	done := waitJob(t, r, path)
+	assert.Equal(t, api.Done, done.Status)
+	require.NotNil(t, done.Result)
+	assert.Equal(t, "任天堂の強みは...", done.Result.Summary)
+	assert.Nil(t, done.Error)
+
+	code, _ = getJob(t, r, path, 2)
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+// TestAnalyzeCompany_AsyncFailure はジョブの分析の失敗を公開してよい理由（quota_exceeded / analysis_failed）で返すことを検証します。
+func TestAnalyzeCompany_AsyncFailure(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		err       func(t *testing.T) error
+		wantCalls int
+		wantError string
+	}{
+		{name: "quota exceeded is not retried", err: func(t *testing.T) error { return quotaExceeded(t, logodetection.OperationAnalyze) }, wantCalls: 1, wantError: "quota_exceeded"},
+		{name: "analysis failure is retried once", err: func(*testing.T) error { return errors.New("gemini API error: secret detail") }, wantCalls: 2, wantError: "analysis_failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			calls := make(chan struct{}, 4)
+			h := logodetectionhttp.NewHandler(&mockUsecase{
+				AnalyzeCompanyFunc: func(context.Context, string) (*logodetection.CompanyAnalysis, error) {
+					calls <- struct{}{}
+					return nil, tt.err(t)
+				},
+			})
+			r, store := newJobRouter(t, h)
+			startWorker(t, h, store)
+
+			w := postAsync(t, r, `{"company_name":"任天堂"}`)
+			require.Equal(t, http.StatusAccepted, w.Code)
+			res := waitJob(t, r, w.Header().Get("Location"))
+			assert.Equal(t, api.Failed, res.Status)
+			assert.Nil(t, res.Result)
+			require.NotNil(t, res.Error)
+			assert.Equal(t, tt.wantError, *res.Error)
+			assert.Len(t, calls, tt.wantCalls)
+		})
+	}
+}
+
+// TestAnalyzeCompany_AsyncRejected は登録前の検査に失敗した場合・ジョブを登録できない場合にジョブを登録しないことを検証します。
+func TestAnalyzeCompany_AsyncRejected(t *testing.T) {
+	t.Parallel()
+
+	t.Run("quota exceeded", func(t *testing.T) {
+		t.Parallel()
+		h := logodetectionhttp.NewHandler(&mockUsecase{
+			CheckAnalyzeFunc: func(_ context.Context, userID int64, companyName string) error {
+				assert.Equal(t, int64(1), userID)
+				assert.Equal(t, "任天堂", companyName)
+				return quotaExceeded(t, logodetection.OperationAnalyze)
+			},
+		})
+		r, _ := newJobRouter(t, h)
+		w := postAsync(t, r, `{"company_name":"任天堂"}`)
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assertQuotaExceeded(t, w)
+		assert.Empty(t, w.Header().Get("Location"))
+	})
+
+	t.Run("invalid company name", func(t *testing.T) {
+		t.Parallel()
+		h := logodetectionhttp.NewHandler(&mockUsecase{
+			CheckAnalyzeFunc: func(context.Context, int64, string) error { return errors.New("invalid company name") },
+		})
+		r, _ := newJobRouter(t, h)
+		w := postAsync(t, r, `{"company_name":"   "}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("no job queue", func(t *testing.T) {
+		t.Parallel()
+		h := logodetectionhttp.NewHandler(&mockUsecase{})
+		w := httptest.NewRecorder()
+		req := withUser(httptest.NewRequest(http.MethodPost, "/logo/analyze?async=true", strings.NewReader(`{"company_name":"任天堂"}`)))
+		req.Header.Set("Content-Type", "application/json")
+		h.AnalyzeCompany(w, req)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
@@ -10,18 +10,22 @@ import (
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/logodetection"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
 )
 
 // Usecase はロゴ検出・企業分析のユースケースインターフェースを定義します。
 // Goの慣例に従い、インターフェースは利用者（handler）側で定義します。
 type Usecase interface {
-	DetectLogos(ctx context.Context, imageData []byte) ([]logodetection.DetectedLogo, error)
-	AnalyzeCompany(ctx context.Context, companyName string) (*logodetection.CompanyAnalysis, error)
+	DetectLogos(ctx context.Context, userID int64, imageData []byte) ([]logodetection.DetectedLogo, error)
+	AnalyzeCompany(ctx context.Context, userID int64, companyName string) (*logodetection.CompanyAnalysis, error)
+	CheckAnalyze(ctx context.Context, userID int64, companyName string) error
+	Usage(ctx context.Context, userID int64) (logodetection.Usage, error)
 }
 
 // Handler はロゴ検出・企業分析のHTTPリクエストを処理します。
 type Handler struct {
-	uc Usecase
+	uc   Usecase
+	jobs AnalysisJobQueue
 }
 
 // NewHandler はHandlerの新しいインスタンスを生成します。
@@ -34,9 +38,16 @@ func NewHandler(uc Usecase) *Handler {
 // エンドポイント: POST /v1/logo/detect
 // Content-Type: multipart/form-data
 // フィールド: image（画像ファイル、最大10MB）
+// 当日の利用枠を使い切っている場合は 429（quota_exceeded）と Retry-After を返します。
 func (h *Handler) DetectLogos(w http.ResponseWriter, r *http.Request) {
 	const maxImageSize = 10 * 1024 * 1024 // 10MB
 
+	userID, ok := jwt.UserIDFromContext(r.Context())
+	if !ok {
+		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
+		return
+	}
+
 	// multipart の境界・ヘッダ分の余裕を見込み、リクエスト全体のサイズを制限する。
 	// 一時ファイルの肥大を防ぐため、ParseMultipartForm の前段でハードリミットをかける。
 	r.Body = http.MaxBytesReader(w, r.Body, maxImageSize+1<<20)
@@ -79,7 +90,11 @@ func (h *Handler) DetectLogos(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	logos, err := h.uc.DetectLogos(r.Context(), imageData)
+	logos, err := h.uc.DetectLogos(r.Context(), userID, imageData)
+	if errors.Is(err, logodetection.ErrQuotaExceeded) {
+		httpx.WriteError(w, err, "ロゴ検出の利用枠超過")
+		return
+	}
 	if err != nil {
 		slog.Error("ロゴ検出に失敗", "error", err)
 		httpx.WriteJSON(w, http.StatusBadGateway, api.ErrorResponse{Error: "ロゴ検出に失敗しました"})
@@ -96,27 +111,84 @@ func (h *Handler) DetectLogos(w http.ResponseWriter, r *http.Request) {
 	httpx.WriteJSON(w, http.StatusOK, out)
 }
 
+// maxAnalyzeBodyBytes は企業分析のリクエストボディの上限です（企業名のみのため小さくする）。
+const maxAnalyzeBodyBytes = 4 << 10
+
 // AnalyzeCompany は企業分析サマリーを生成します。
 //
 // エンドポイント: POST /v1/logo/analyze
 // Content-Type: application/json
+// 当日の利用枠を使い切っている場合は 429（quota_exceeded）と Retry-After を返します。
+// ?async=true の場合は分析をジョブとして登録し、202 を返します（enqueueAnalysis 参照）。
 func (h *Handler) AnalyzeCompany(w http.ResponseWriter, r *http.Request) {
+	userID, ok := jwt.UserIDFromContext(r.Context())
+	if !ok {
+		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
+		return
+	}
 	var req api.CompanyAnalysisRequest
-	if err := httpx.DecodeAndValidate(r, &req); err != nil {
+	if err := httpx.DecodeAndValidate(r, &req, httpx.MaxBytes(maxAnalyzeBodyBytes)); err != nil {
 		slog.Warn("企業分析リクエストのバリデーションに失敗", "error", err, "remote_addr", httpx.ClientIP(r))
-		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "企業名が必要です"})
+		httpx.WriteDecodeError(w, err, "企業名が必要です")
+		return
+	}
+	if r.URL.Query().Get("async") == "true" {
+		h.enqueueAnalysis(w, r, userID, req.CompanyName)
 		return
 	}
 
-	analysis, err := h.uc.AnalyzeCompany(r.Context(), req.CompanyName)
+	analysis, err := h.uc.AnalyzeCompany(r.Context(), userID, req.CompanyName)
+	if errors.Is(err, logodetection.ErrQuotaExceeded) {
+		httpx.WriteError(w, err, "企業分析の利用枠超過")
+		return
+	}
 	if err != nil {
 		slog.Error("企業分析に失敗", "error", err, "company", req.CompanyName)
 		httpx.WriteJSON(w, http.StatusBadGateway, api.ErrorResponse{Error: "企業分析に失敗しました"})
 		return
 	}
 
-	httpx.WriteJSON(w, http.StatusOK, api.CompanyAnalysisResponse{
-		CompanyName: analysis.CompanyName,
-		Summary:     analysis.Summary,
+	httpx.WriteJSON(w, http.StatusOK, toAnalysisResponse(analysis))
+}
+
+func toAnalysisResponse(a *logodetection.CompanyAnalysis) api.CompanyAnalysisResponse {
+	return api.CompanyAnalysisResponse{
+		CompanyName: a.CompanyName,
+		Summary:     a.Summary,
+		GeneratedAt: a.GeneratedAt,
+		Stale:       a.Stale,
+	}
+}
+
+// Usage はログインユーザーの当日のロゴ検出・企業分析の利用状況を返します。
+//
+// エンドポイント: GET /v1/me/usage
+func (h *Handler) Usage(w http.ResponseWriter, r *http.Request) {
+	userID, ok := jwt.UserIDFromContext(r.Context())
+	if !ok {
+		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
+		return
+	}
+	u, err := h.uc.Usage(r.Context(), userID)
+	if err != nil {
+		slog.Error("利用状況の取得に失敗", "error", err, "userID", userID)
+		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
+		return
+	}
+
+	ops := make([]api.OperationUsage, 0, len(u.Operations))
+	for _, o := range u.Operations {
+		ops = append(ops, api.OperationUsage{
+			Operation: api.OperationUsageOperation(o.Operation),
+			Used:      o.Used,
+			Limit:     o.Limit,
+			Remaining: o.Remaining(),
+		})
+	}
+	httpx.WriteJSON(w, http.StatusOK, api.UsageResponse{
+		Date:       api.NewDate(u.Date),
+		ResetAt:    u.ResetAt,
+		Exempt:     u.Exempt,
+		Operations: ops,
 	})
 }
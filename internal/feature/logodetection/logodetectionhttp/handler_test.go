@@ -3,34 +3,81 @@ package logodetectionhttp_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/logodetection"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/logodetection/logodetectionhttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
 )
 
 // mockUsecase はUsecaseインターフェースのモック実装です。
 type mockUsecase struct {
 	DetectLogosFunc    func(ctx context.Context, imageData []byte) ([]logodetection.DetectedLogo, error)
 	AnalyzeCompanyFunc func(ctx context.Context, companyName string) (*logodetection.CompanyAnalysis, error)
+	UsageFunc          func(ctx context.Context, userID int64) (logodetection.Usage, error)
+	CheckAnalyzeFunc   func(ctx context.Context, userID int64, companyName string) error
 }
 
-func (m *mockUsecase) DetectLogos(ctx context.Context, imageData []byte) ([]logodetection.DetectedLogo, error) {
+func (m *mockUsecase) DetectLogos(ctx context.Context, _ int64, imageData []byte) ([]logodetection.DetectedLogo, error) {
 	return m.DetectLogosFunc(ctx, imageData)
 }
 
-func (m *mockUsecase) AnalyzeCompany(ctx context.Context, companyName string) (*logodetection.CompanyAnalysis, error) {
+func (m *mockUsecase) AnalyzeCompany(ctx context.Context, _ int64, companyName string) (*logodetection.CompanyAnalysis, error) {
 	return m.AnalyzeCompanyFunc(ctx, companyName)
 }
 
+// CheckAnalyze は CheckAnalyzeFunc が nil の場合は常に成功します。
+func (m *mockUsecase) CheckAnalyze(ctx context.Context, userID int64, companyName string) error {
+	if m.CheckAnalyzeFunc == nil {
+		return nil
+	}
+	return m.CheckAnalyzeFunc(ctx, userID, companyName)
+}
+
+func (m *mockUsecase) Usage(ctx context.Context, userID int64) (logodetection.Usage, error) {
+	return m.UsageFunc(ctx, userID)
+}
+
+// withUser は認証済みユーザー（ID 1）のリクエストを返します。
+func withUser(r *http.Request) *http.Request {
+	return r.WithContext(jwt.WithUserID(r.Context(), 1))
+}
+
+// quotaExceeded は上限に達した場合の usecase のエラーを返します。
+func quotaExceeded(t *testing.T, op logodetection.Operation) error {
+	t.Helper()
+	q := logodetection.NewQuota(fullCounter{}, logodetection.QuotaConfig{})
+	err := q.Check(context.Background(), 1, op)
+	if !errors.Is(err, logodetection.ErrQuotaExceeded) {
+		t.Fatalf("expected ErrQuotaExceeded, got %v", err)
+	}
+	return err
+}
+
+// fullCounter は常に上限を超える利用回数を返す UsageCounter です。
+type fullCounter struct{}
+
+func (fullCounter) Count(context.Context, int64, logodetection.Operation, time.Time) (int, error) {
+	return 1 << 20, nil
+}
+
+func (fullCounter) Increment(context.Context, int64, logodetection.Operation, time.Time) error {
+	return nil
+}
+
 // createMultipartRequest はテスト用のマルチパートリクエストを生成するヘルパー関数です。
 func createMultipartRequest(t *testing.T, fieldName, fileName string, content []byte) (*http.Request, string) {
 	t.Helper()
@@ -100,6 +147,17 @@ func TestLogoDetectionHandler_DetectLogos(t *testing.T) {
 			expectedStatus: http.StatusBadGateway,
 			expectedBody:   `{"error":"ロゴ検出に失敗しました"}`,
 		},
+		{
+			name: "error: daily quota exceeded",
+			setupRequest: func(t *testing.T) *http.Request {
+				req, _ := createMultipartRequest(t, "image", "test.jpg", []byte("fake-image"))
+				return req
+			},
+			mockFunc: func(ctx context.Context, imageData []byte) ([]logodetection.DetectedLogo, error) {
+				return nil, quotaExceeded(t, logodetection.OperationDetect)
+			},
+			expectedStatus: http.StatusTooManyRequests,
+		},
 	}
 
 	for _, tt := range tests {
@@ -111,11 +169,15 @@ func TestLogoDetectionHandler_DetectLogos(t *testing.T) {
 			h := logodetectionhttp.NewHandler(mockUC)
 
 			w := httptest.NewRecorder()
-			req := tt.setupRequest(t)
+			req := withUser(tt.setupRequest(t))
 
 			h.DetectLogos(w, req)
 
 			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusTooManyRequests {
+				assertQuotaExceeded(t, w)
+				return
+			}
 			assert.JSONEq(t, tt.expectedBody, w.Body.String())
 		})
 	}
@@ -137,10 +199,25 @@ func TestLogoDetectionHandler_AnalyzeCompany(t *testing.T) {
 				return &logodetection.CompanyAnalysis{
 					CompanyName: "任天堂",
 					Summary:     "任天堂の強みは...",
+					GeneratedAt: time.Date(2026, 8, 1, 9, 0, 0, 0, time.UTC),
 				}, nil
 			},
 			expectedStatus: http.StatusOK,
-			expectedBody:   `{"company_name":"任天堂","summary":"任天堂の強みは..."}`,
+			expectedBody:   `{"company_name":"任天堂","summary":"任天堂の強みは...","generated_at":"2026-08-01T09:00:00Z","stale":false}`,
+		},
+		{
+			name:        "success: stale analysis served while refreshing",
+			requestBody: `{"company_name":"任天堂"}`,
+			mockFunc: func(ctx context.Context, companyName string) (*logodetection.CompanyAnalysis, error) {
+				return &logodetection.CompanyAnalysis{
+					CompanyName: "任天堂",
+					Summary:     "任天堂の強みは...",
+					GeneratedAt: time.Date(2026, 7, 30, 9, 0, 0, 0, time.UTC),
+					Stale:       true,
+				}, nil
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"company_name":"任天堂","summary":"任天堂の強みは...","generated_at":"2026-07-30T09:00:00Z","stale":true}`,
 		},
 		{
 			name:           "error: empty request body",
@@ -154,6 +231,18 @@ func TestLogoDetectionHandler_AnalyzeCompany(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   `{"error":"企業名が必要です"}`,
 		},
+		{
+			name:           "error: body exceeds 4KB",
+			requestBody:    `{"company_name":"` + strings.Repeat("あ", 2000) + `"}`,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody:   `{"error":"request_too_large"}`,
+		},
+		{
+			name:           "error: deeply nested json",
+			requestBody:    `{"company_name":"任天堂","x":` + strings.Repeat("[", 100) + strings.Repeat("]", 100) + `}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"企業名が必要です"}`,
+		},
 		{
 			name:        "error: usecase returns error",
 			requestBody: `{"company_name":"テスト企業"}`,
@@ -163,6 +252,14 @@ func TestLogoDetectionHandler_AnalyzeCompany(t *testing.T) {
 			expectedStatus: http.StatusBadGateway,
 			expectedBody:   `{"error":"企業分析に失敗しました"}`,
 		},
+		{
+			name:        "error: daily quota exceeded",
+			requestBody: `{"company_name":"任天堂"}`,
+			mockFunc: func(ctx context.Context, companyName string) (*logodetection.CompanyAnalysis, error) {
+				return nil, quotaExceeded(t, logodetection.OperationAnalyze)
+			},
+			expectedStatus: http.StatusTooManyRequests,
+		},
 	}
 
 	for _, tt := range tests {
@@ -174,13 +271,62 @@ func TestLogoDetectionHandler_AnalyzeCompany(t *testing.T) {
 			h := logodetectionhttp.NewHandler(mockUC)
 
 			w := httptest.NewRecorder()
-			req := httptest.NewRequest(http.MethodPost, "/logo/analyze", strings.NewReader(tt.requestBody))
+			req := withUser(httptest.NewRequest(http.MethodPost, "/logo/analyze", strings.NewReader(tt.requestBody)))
 			req.Header.Set("Content-Type", "application/json")
 
 			h.AnalyzeCompany(w, req)
 
 			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusTooManyRequests {
+				assertQuotaExceeded(t, w)
+				return
+			}
 			assert.JSONEq(t, tt.expectedBody, w.Body.String())
 		})
 	}
 }
+
+// assertQuotaExceeded は 429 の応答に error・hint・Retry-After が含まれることを検証します。
+func assertQuotaExceeded(t *testing.T, w *httptest.ResponseRecorder) {
+	t.Helper()
+	var body api.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "quota_exceeded", body.Error)
+	assert.Contains(t, body.Hint, "resets at")
+	retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+	require.NoError(t, err)
+	assert.Positive(t, retryAfter)
+	assert.LessOrEqual(t, retryAfter, 24*60*60)
+}
+
+func TestLogoDetectionHandler_Usage(t *testing.T) {
+	t.Parallel()
+	day := time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC)
+	h := logodetectionhttp.NewHandler(&mockUsecase{
+		UsageFunc: func(ctx context.Context, userID int64) (logodetection.Usage, error) {
+			assert.Equal(t, int64(1), userID)
+			return logodetection.Usage{
+				Date:    day,
+				ResetAt: day.AddDate(0, 0, 1),
+				Operations: []logodetection.OperationUsage{
+					{Operation: logodetection.OperationDetect, Used: 3, Limit: 50},
+					{Operation: logodetection.OperationAnalyze, Used: 25, Limit: 20},
+				},
+			}, nil
+		},
+	})
+
+	w := httptest.NewRecorder()
+	h.Usage(w, withUser(httptest.NewRequest(http.MethodGet, "/me/usage", nil)))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"date":"2026-08-01",
+		"reset_at":"2026-08-02T00:00:00Z",
+		"exempt":false,
+		"operations":[
+			{"operation":"detect","used":3,"limit":50,"remaining":47},
+			{"operation":"analyze","used":25,"limit":20,"remaining":0}
+		]
+	}`, w.Body.String())
+}
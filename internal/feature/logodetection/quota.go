@@ -0,0 +1,240 @@
+package logodetection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Operation は利用枠を数える操作の種類です。
+type Operation string
+
+const (
+	// OperationDetect はロゴ検出（Cloud Vision の呼び出し）です。
+	OperationDetect Operation = "detect"
+	// OperationAnalyze は企業分析（Gemini の呼び出し）です。
+	OperationAnalyze Operation = "analyze"
+)
+
+// Operations は利用枠のある操作です（利用状況はこの順に返します）。
+var Operations = []Operation{OperationDetect, OperationAnalyze}
+
+const (
+	// DefaultDetectDailyLimit はユーザーごとの 1 日あたりのロゴ検出の既定の上限です。
+	DefaultDetectDailyLimit = 50
+	// DefaultAnalyzeDailyLimit はユーザーごとの 1 日あたりの企業分析の既定の上限です。
+	DefaultAnalyzeDailyLimit = 20
+)
+
+// QuotaConfig はユーザーごとの日次の利用枠の設定です。日付は UTC で区切ります。
+type QuotaConfig struct {
+	DetectDailyLimit  int // 0 以下の場合は DefaultDetectDailyLimit
+	AnalyzeDailyLimit int // 0 以下の場合は DefaultAnalyzeDailyLimit
+	// ExemptUserIDs は上限を適用しないユーザー（管理者・動作確認用のアカウント等）です。利用回数は数えます。
+	ExemptUserIDs []int64
+}
+
+// Limit は op の 1 日あたりの上限を返します。
+func (c QuotaConfig) Limit(op Operation) int {
+	switch op {
+	case OperationDetect:
+		if c.DetectDailyLimit > 0 {
+			return c.DetectDailyLimit
+		}
+		return DefaultDetectDailyLimit
+	case OperationAnalyze:
+		if c.AnalyzeDailyLimit > 0 {
+			return c.AnalyzeDailyLimit
+		}
+		return DefaultAnalyzeDailyLimit
+	default:
+		return 0
+	}
+}
+
+// ParseUserIDs はカンマ区切りのユーザー ID（例: "1, 42"）を解釈します。空の要素は無視します。
+func ParseUserIDs(s string) ([]int64, error) {
+	var ids []int64
+	for part := range strings.SplitSeq(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid user ID %q", part)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// UsageCounter はユーザー・操作・日付（UTC の 0 時）ごとの利用回数を数えます。
+type UsageCounter interface {
+	Count(ctx context.Context, userID int64, op Operation, day time.Time) (int, error)
+	Increment(ctx context.Context, userID int64, op Operation, day time.Time) error
+}
+
+// OperationUsage は 1 つの操作の当日の利用状況です。
+type OperationUsage struct {
+	Operation Operation
+	Used      int
+	Limit     int
+}
+
+// Remaining は当日の残りの回数を返します（0 未満にはしません）。
+func (u OperationUsage) Remaining() int {
+	return max(u.Limit-u.Used, 0)
+}
+
+// Usage はユーザーの当日の利用状況です。
+type Usage struct {
+	Date       time.Time // 当日（UTC の 0 時）
+	ResetAt    time.Time // 利用回数が 0 に戻る時刻（翌日の 0 時 UTC）
+	Exempt     bool      // 上限を適用しないユーザーか
+	Operations []OperationUsage
+}
+
+// Quota はユーザーごとの日次の利用枠を管理します。
+//
+// 上限は緩やかに適用します（ソフトリミット）。確認（Check）と記録（Record）の間に同じユーザーの
+// 別のリクエストが成功した場合は上限をわずかに超えることがあり、利用回数を読めない場合は上限を適用しません
+// （カウンターの障害でロゴ検出・企業分析を止めないため）。
+type Quota struct {
+	counter UsageCounter
+	cfg     QuotaConfig
+	exempt  map[int64]struct{}
+	now     func() time.Time
+}
+
+// NewQuota は counter で利用回数を数える Quota を生成します。
+func NewQuota(counter UsageCounter, cfg QuotaConfig) *Quota {
+	exempt := make(map[int64]struct{}, len(cfg.ExemptUserIDs))
+	for _, id := range cfg.ExemptUserIDs {
+		exempt[id] = struct{}{}
+	}
+	return &Quota{counter: counter, cfg: cfg, exempt: exempt, now: time.Now}
+}
+
+// usageDay は t の UTC の日付（0 時）と、翌日に切り替わる時刻を返します。
+func usageDay(t time.Time) (day, resetAt time.Time) {
+	y, m, d := t.UTC().Date()
+	day = time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	return day, day.AddDate(0, 0, 1)
+}
+
+func (q *Quota) isExempt(userID int64) bool {
+	_, ok := q.exempt[userID]
+	return ok
+}
+
+// Check は userID が op を当日あと 1 回実行できるかを確認し、上限に達していれば QuotaExceededError を返します。
+func (q *Quota) Check(ctx context.Context, userID int64, op Operation) error {
+	if q.isExempt(userID) {
+		return nil
+	}
+	now := q.now()
+	day, resetAt := usageDay(now)
+	used, err := q.counter.Count(ctx, userID, op, day)
+	if err != nil {
+		slog.Warn("failed to read logo usage, skipping quota check", "error", err, "userID", userID, "operation", op)
+		return nil
+	}
+	if limit := q.cfg.Limit(op); used >= limit {
+		return &QuotaExceededError{Operation: op, Limit: limit, ResetAt: resetAt, now: now}
+	}
+	return nil
+}
+
+// Record は userID の op の成功を当日の利用回数に加えます。記録の失敗は警告ログに残すだけで、呼び出し元の結果は変えません。
+func (q *Quota) Record(ctx context.Context, userID int64, op Operation) {
+	day, _ := usageDay(q.now())
+	if err := q.counter.Increment(ctx, userID, op, day); err != nil {
+		slog.Warn("failed to record logo usage", "error", err, "userID", userID, "operation", op)
+	}
+}
+
+// Usage は userID の当日の利用状況を返します。
+func (q *Quota) Usage(ctx context.Context, userID int64) (Usage, error) {
+	day, resetAt := usageDay(q.now())
+	u := Usage{Date: day, ResetAt: resetAt, Exempt: q.isExempt(userID), Operations: make([]OperationUsage, 0, len(Operations))}
+	for _, op := range Operations {
+		used, err := q.counter.Count(ctx, userID, op, day)
+		if err != nil {
+			return Usage{}, fmt.Errorf("count %s usage: %w", op, err)
+		}
+		u.Operations = append(u.Operations, OperationUsage{Operation: op, Used: used, Limit: q.cfg.Limit(op)})
+	}
+	return u, nil
+}
+
+// usageKeyTTL は日次キーを当日の終わりから残す期間です（日付の境目をまたいだリクエストが前日のキーを読んでも消えていないように）。
+const usageKeyTTL = 24 * time.Hour
+
+// RedisUsageCounter は Redis の日次キー（<prefix>:<userID>:<operation>:<YYYY-MM-DD>）で利用回数を数える UsageCounter です。
+// Redis が未設定・障害中（RedisProvider が nil を返す間）は fallback（DB のテーブル）で数えます。
+type RedisUsageCounter struct {
+	rdb      *redis.Client
+	provider RedisProvider
+	prefix   string
+	fallback UsageCounter
+}
+
+var _ UsageCounter = (*RedisUsageCounter)(nil)
+
+// NewRedisUsageCounter は RedisUsageCounter を生成します。
+// prefix は環境の名前空間を含むキーの接頭辞（例: "staging:logo:usage"）です。rdb が nil の場合は常に fallback で数えます。
+func NewRedisUsageCounter(rdb *redis.Client, prefix string, fallback UsageCounter) *RedisUsageCounter {
+	return &RedisUsageCounter{rdb: rdb, prefix: prefix, fallback: fallback}
+}
+
+// WithRedisProvider は Redis クライアントを呼び出しごとに p から取得するよう設定します。
+// p が nil を返す間は fallback で数えます。
+func (c *RedisUsageCounter) WithRedisProvider(p RedisProvider) *RedisUsageCounter {
+	c.provider = p
+	return c
+}
+
+func (c *RedisUsageCounter) client() *redis.Client {
+	if c.provider != nil {
+		return c.provider.Client()
+	}
+	return c.rdb
+}
+
+func (c *RedisUsageCounter) key(userID int64, op Operation, day time.Time) string {
+	return fmt.Sprintf("%s:%d:%s:%s", c.prefix, userID, op, day.Format(time.DateOnly))
+}
+
+// Count は day の利用回数を返します。キーがない場合は 0 です。
+func (c *RedisUsageCounter) Count(ctx context.Context, userID int64, op Operation, day time.Time) (int, error) {
+	rdb := c.client()
+	if rdb == nil {
+		return c.fallback.Count(ctx, userID, op, day)
+	}
+	n, err := rdb.Get(ctx, c.key(userID, op, day)).Int()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return n, err
+}
+
+// Increment は day の利用回数を 1 増やします。キーは翌日の終わりに期限切れになります。
+func (c *RedisUsageCounter) Increment(ctx context.Context, userID int64, op Operation, day time.Time) error {
+	rdb := c.client()
+	if rdb == nil {
+		return c.fallback.Increment(ctx, userID, op, day)
+	}
+	key := c.key(userID, op, day)
+	pipe := rdb.TxPipeline()
+	pipe.Incr(ctx, key)
+	pipe.ExpireAt(ctx, key, day.AddDate(0, 0, 1).Add(usageKeyTTL))
+	_, err := pipe.Exec(ctx)
+	return err
+}
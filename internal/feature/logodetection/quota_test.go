@@ -0,0 +1,175 @@
+package logodetection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/testsupport"
+)
+
+// countingStore はインメモリで利用回数を数える UsageCounter です（DB フォールバックの代わり）。
+type countingStore struct {
+	counts map[string]int
+	err    error
+}
+
+func newCountingStore() *countingStore {
+	return &countingStore{counts: map[string]int{}}
+}
+
+func (s *countingStore) key(userID int64, op Operation, day time.Time) string {
+	return fmt.Sprintf("%d:%s:%s", userID, op, day.Format(time.DateOnly))
+}
+
+func (s *countingStore) Count(_ context.Context, userID int64, op Operation, day time.Time) (int, error) {
+	return s.counts[s.key(userID, op, day)], s.err
+}
+
+func (s *countingStore) Increment(_ context.Context, userID int64, op Operation, day time.Time) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.counts[s.key(userID, op, day)]++
+	return nil
+}
+
+// switchableProvider は Redis の接続・切断を切り替えられる RedisProvider です。
+type switchableProvider struct {
+	rdb *redis.Client
+}
+
+func (p *switchableProvider) Client() *redis.Client { return p.rdb }
+
+// newTestQuota は miniredis で数え、Redis がない間は返り値の countingStore で数える Quota を返します。
+func newTestQuota(t *testing.T, cfg QuotaConfig) (*Quota, *miniredis.Miniredis, *testsupport.Clock, *switchableProvider, *countingStore) {
+	t.Helper()
+	mr, rdb := testsupport.NewMiniRedis(t)
+	provider := &switchableProvider{rdb: rdb}
+	fallback := newCountingStore()
+	q := NewQuota(NewRedisUsageCounter(nil, "test:logo:usage", fallback).WithRedisProvider(provider), cfg)
+	clock := testsupport.NewClock(time.Date(2026, 8, 1, 21, 0, 0, 0, time.UTC))
+	clock.SyncRedis(mr) // EXPIREAT の期限を時計に合わせて判定させる
+	q.now = clock.Now
+	return q, mr, clock, provider, fallback
+}
+
+func TestQuota_LimitBoundary(t *testing.T) {
+	t.Parallel()
+	q, mr, _, _, _ := newTestQuota(t, QuotaConfig{DetectDailyLimit: 3})
+	ctx := context.Background()
+
+	for i := range 3 {
+		require.NoError(t, q.Check(ctx, 1, OperationDetect), "call #%d is within the limit", i+1)
+		q.Record(ctx, 1, OperationDetect)
+	}
+	err := q.Check(ctx, 1, OperationDetect)
+	require.ErrorIs(t, err, ErrQuotaExceeded)
+
+	var qe *QuotaExceededError
+	require.ErrorAs(t, err, &qe)
+	assert.Equal(t, 3, qe.Limit)
+	assert.Equal(t, time.Date(2026, 8, 2, 0, 0, 0, 0, time.UTC), qe.ResetAt)
+	assert.Equal(t, 3*time.Hour, qe.RetryAfter())
+	assert.Contains(t, qe.Hint(), "2026-08-02T00:00:00Z")
+
+	// 他の操作・他のユーザーの利用枠には影響しない
+	assert.NoError(t, q.Check(ctx, 1, OperationAnalyze))
+	assert.NoError(t, q.Check(ctx, 2, OperationDetect))
+
+	// 日次キーは翌日の終わりに期限切れになる
+	assert.Equal(t, 27*time.Hour, mr.TTL("test:logo:usage:1:detect:2026-08-01"))
+}
+
+func TestQuota_DailyReset(t *testing.T) {
+	t.Parallel()
+	q, _, clock, _, _ := newTestQuota(t, QuotaConfig{AnalyzeDailyLimit: 1})
+	ctx := context.Background()
+
+	require.NoError(t, q.Check(ctx, 1, OperationAnalyze))
+	q.Record(ctx, 1, OperationAnalyze)
+	require.ErrorIs(t, q.Check(ctx, 1, OperationAnalyze), ErrQuotaExceeded)
+
+	// 0 時 UTC の直前はまだ使えない
+	clock.Advance(3*time.Hour - time.Second)
+	require.ErrorIs(t, q.Check(ctx, 1, OperationAnalyze), ErrQuotaExceeded)
+
+	// 日付が変わると利用枠が戻る
+	clock.Advance(time.Second)
+	require.NoError(t, q.Check(ctx, 1, OperationAnalyze))
+
+	u, err := q.Usage(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 8, 2, 0, 0, 0, 0, time.UTC), u.Date)
+	assert.Equal(t, time.Date(2026, 8, 3, 0, 0, 0, 0, time.UTC), u.ResetAt)
+	assert.Equal(t, []OperationUsage{
+		{Operation: OperationDetect, Used: 0, Limit: DefaultDetectDailyLimit},
+		{Operation: OperationAnalyze, Used: 0, Limit: 1},
+	}, u.Operations)
+}
+
+func TestQuota_ExemptUsers(t *testing.T) {
+	t.Parallel()
+	q, _, _, _, _ := newTestQuota(t, QuotaConfig{DetectDailyLimit: 1, ExemptUserIDs: []int64{42}})
+	ctx := context.Background()
+
+	for range 3 {
+		require.NoError(t, q.Check(ctx, 42, OperationDetect))
+		q.Record(ctx, 42, OperationDetect)
+	}
+
+	// 上限は適用しないが、利用回数は数える
+	u, err := q.Usage(ctx, 42)
+	require.NoError(t, err)
+	assert.True(t, u.Exempt)
+	assert.Equal(t, 3, u.Operations[0].Used)
+	assert.Zero(t, u.Operations[0].Remaining())
+
+	q.Record(ctx, 1, OperationDetect)
+	assert.ErrorIs(t, q.Check(ctx, 1, OperationDetect), ErrQuotaExceeded)
+}
+
+func TestQuota_FallbackWithoutRedis(t *testing.T) {
+	t.Parallel()
+	q, mr, _, provider, fallback := newTestQuota(t, QuotaConfig{DetectDailyLimit: 2})
+	ctx := context.Background()
+
+	// Redis がない間は DB（fallback）で数え、上限も適用する
+	provider.rdb = nil
+	q.Record(ctx, 1, OperationDetect)
+	q.Record(ctx, 1, OperationDetect)
+	assert.Equal(t, 2, fallback.counts["1:detect:2026-08-01"])
+	assert.Empty(t, mr.Keys())
+	require.ErrorIs(t, q.Check(ctx, 1, OperationDetect), ErrQuotaExceeded)
+
+	// 利用回数を読めない場合は上限を適用しない（カウンターの障害で機能を止めない）
+	fallback.err = errors.New("db down")
+	assert.NoError(t, q.Check(ctx, 1, OperationDetect))
+	q.Record(ctx, 1, OperationDetect)
+	_, err := q.Usage(ctx, 1)
+	assert.ErrorContains(t, err, "db down")
+}
+
+func TestParseUserIDs(t *testing.T) {
+	t.Parallel()
+
+	ids, err := ParseUserIDs(" 1, 42 ,,7")
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 42, 7}, ids)
+
+	ids, err = ParseUserIDs("")
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+
+	for _, s := range []string{"1,abc", "0", "-3"} {
+		_, err := ParseUserIDs(s)
+		assert.Error(t, err, s)
+	}
+}
@@ -0,0 +1,11 @@
+-- name: GetUsage :one
+SELECT count
+FROM logo_usage
+WHERE user_id = $1 AND operation = $2 AND usage_date = $3;
+
+-- name: IncrementUsage :exec
+INSERT INTO logo_usage (user_id, operation, usage_date, count)
+VALUES ($1, $2, $3, 1)
+ON CONFLICT (user_id, operation, usage_date) DO UPDATE
+SET count = logo_usage.count + 1, updated_at = now();
+
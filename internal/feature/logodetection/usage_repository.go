@@ -0,0 +1,40 @@
+package logodetection
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/logodetection/sqlc"
+)
+
+// usageRepository は logo_usage テーブルで利用回数を数える UsageCounter の sqlc ベース実装です。
+// Redis に接続できない間の RedisUsageCounter のフォールバックとして使います。
+type usageRepository struct {
+	q *logodetectionsqlc.Queries
+}
+
+var _ UsageCounter = (*usageRepository)(nil)
+
+// NewUsageRepository は指定された *sql.DB で usageRepository の新しいインスタンスを生成します。
+func NewUsageRepository(db *sql.DB) *usageRepository {
+	return &usageRepository{q: logodetectionsqlc.New(db)}
+}
+
+// Count は day の利用回数を返します。行がない場合は 0 です。
+func (r *usageRepository) Count(ctx context.Context, userID int64, op Operation, day time.Time) (int, error) {
+	n, err := r.q.GetUsage(ctx, logodetectionsqlc.GetUsageParams{UserID: userID, Operation: string(op), UsageDate: day})
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+// Increment は day の利用回数を 1 増やします。
+func (r *usageRepository) Increment(ctx context.Context, userID int64, op Operation, day time.Time) error {
+	return r.q.IncrementUsage(ctx, logodetectionsqlc.IncrementUsageParams{UserID: userID, Operation: string(op), UsageDate: day})
+}
@@ -5,7 +5,11 @@ import (
 	_ "embed"
 	"fmt"
 	"regexp"
+	"strings"
+	"time"
 	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 const (
@@ -24,8 +28,16 @@ var analysisFormat string
 // AnalysisPromptTemplate は指示文とフォーマットを結合した企業分析のプロンプトテンプレートです。
 var AnalysisPromptTemplate = analysisPrompt + "\n## 出力フォーマット\n" + analysisFormat
 
-// validCompanyName は企業名に許可される文字パターンです（英数字・日本語・スペース・中黒）。
-var validCompanyName = regexp.MustCompile(`^[\p{L}\p{N} ・\-\.&,'']+$`)
+// validCompanyName は企業名に許可される文字パターンです（NFKC 正規化後の文字に対して判定します）。
+// 英数字・日本語（結合文字を含む）・スペース・中黒に加え、「株式会社（…）」の括弧や波ダッシュ（〜）等の記号を許可します。
+// 制御文字・改行・波括弧・山括弧等、プロンプトの構造を崩しうる文字は含めません。
+var validCompanyName = regexp.MustCompile(`^[\p{L}\p{M}\p{N} ・\-\.&,'’()〜~]+$`)
+
+// NormalizeCompanyName は企業名を NFKC で正規化し、前後の空白を取り除きます。
+// 全角英数字・全角括弧は半角に、半角カナは全角にそろうため、表記の揺れによらず同じ名前として扱えます。
+func NormalizeCompanyName(name string) string {
+	return strings.TrimSpace(norm.NFKC.String(name))
+}
 
 // LogoDetector は画像からロゴを検出するリポジトリインターフェースです。
 // Goの慣例に従い、インターフェースは利用者（usecase）側で定義します。
@@ -41,46 +53,111 @@ type CompanyAnalyzer interface {
 	Analyze(ctx context.Context, prompt string) (string, error)
 }
 
+// AnalysisSource は生成日時付きの企業分析を返すインターフェースです。
+// キャッシュを挟む場合は CachingAnalyzer、挟まない場合は Uncached で CompanyAnalyzer を包みます。
+type AnalysisSource interface {
+	Analysis(ctx context.Context, prompt string) (Analysis, error)
+}
+
+// Uncached はキャッシュを挟まずに毎回 ca で生成する AnalysisSource を返します。
+func Uncached(ca CompanyAnalyzer) AnalysisSource {
+	return uncached{ca: ca}
+}
+
+type uncached struct {
+	ca CompanyAnalyzer
+}
+
+func (u uncached) Analysis(ctx context.Context, prompt string) (Analysis, error) {
+	summary, err := u.ca.Analyze(ctx, prompt)
+	if err != nil {
+		return Analysis{}, err
+	}
+	return Analysis{Summary: summary, GeneratedAt: time.Now()}, nil
+}
+
 // usecase はロゴ検出・企業分析のビジネスロジックを提供します。
 type usecase struct {
-	logoDetector    LogoDetector
-	companyAnalyzer CompanyAnalyzer
+	logoDetector LogoDetector
+	analyses     AnalysisSource
+	quota        *Quota
 }
 
 // NewUsecase はusecaseの新しいインスタンスを生成します。
-func NewUsecase(ld LogoDetector, ca CompanyAnalyzer) *usecase {
-	return &usecase{logoDetector: ld, companyAnalyzer: ca}
+// ロゴ検出・企業分析はユーザーごとに quota の日次の利用枠の範囲で実行し、成功した呼び出しだけを利用回数に数えます。
+func NewUsecase(ld LogoDetector, analyses AnalysisSource, quota *Quota) *usecase {
+	return &usecase{logoDetector: ld, analyses: analyses, quota: quota}
 }
 
 // DetectLogos は画像データからロゴを検出します。
-func (u *usecase) DetectLogos(ctx context.Context, imageData []byte) ([]DetectedLogo, error) {
+// 当日の利用枠を使い切っている場合は QuotaExceededError（ErrQuotaExceeded）を返します。
+func (u *usecase) DetectLogos(ctx context.Context, userID int64, imageData []byte) ([]DetectedLogo, error) {
 	if len(imageData) == 0 {
 		return nil, fmt.Errorf("image data is empty")
 	}
 	if len(imageData) > MaxImageSize {
 		return nil, fmt.Errorf("image size exceeds maximum of %d bytes", MaxImageSize)
 	}
-	return u.logoDetector.DetectLogos(ctx, imageData)
+	if err := u.quota.Check(ctx, userID, OperationDetect); err != nil {
+		return nil, err
+	}
+	logos, err := u.logoDetector.DetectLogos(ctx, imageData)
+	if err != nil {
+		return nil, err
+	}
+	u.quota.Record(ctx, userID, OperationDetect)
+	return logos, nil
 }
 
 // AnalyzeCompany は企業名から分析サマリーを生成します。
-func (u *usecase) AnalyzeCompany(ctx context.Context, companyName string) (*CompanyAnalysis, error) {
-	if companyName == "" {
-		return nil, fmt.Errorf("company name is required")
-	}
-	if utf8.RuneCountInString(companyName) > MaxCompanyNameLength {
-		return nil, fmt.Errorf("company name exceeds maximum length of %d characters", MaxCompanyNameLength)
-	}
-	if !validCompanyName.MatchString(companyName) {
-		return nil, fmt.Errorf("company name contains invalid characters")
+// 企業名は NormalizeCompanyName で正規化してから検証・プロンプトへの埋め込みを行い、結果にも正規化後の名前を返します。
+// 当日の利用枠を使い切っている場合は QuotaExceededError（ErrQuotaExceeded）を返します。
+// キャッシュから返した分析も、ユーザーにとっては 1 回の利用として数えます。
+func (u *usecase) AnalyzeCompany(ctx context.Context, userID int64, companyName string) (*CompanyAnalysis, error) {
+	companyName, err := u.checkAnalyze(ctx, userID, companyName)
+	if err != nil {
+		return nil, err
 	}
 	prompt := fmt.Sprintf(AnalysisPromptTemplate, companyName)
-	summary, err := u.companyAnalyzer.Analyze(ctx, prompt)
+	a, err := u.analyses.Analysis(ctx, prompt)
 	if err != nil {
 		return nil, fmt.Errorf("company analyzer failed for %q: %w", companyName, err)
 	}
+	u.quota.Record(ctx, userID, OperationAnalyze)
 	return &CompanyAnalysis{
 		CompanyName: companyName,
-		Summary:     summary,
+		Summary:     a.Summary,
+		GeneratedAt: a.GeneratedAt,
+		Stale:       a.Stale,
 	}, nil
 }
+
+// CheckAnalyze は AnalyzeCompany を呼び出す前に、企業名の検証と当日の利用枠の確認だけを行います
+// （非同期の分析で、ジョブを登録する前に 400・429 を返すため）。利用回数は数えません。
+func (u *usecase) CheckAnalyze(ctx context.Context, userID int64, companyName string) error {
+	_, err := u.checkAnalyze(ctx, userID, companyName)
+	return err
+}
+
+// checkAnalyze は企業名を正規化・検証し、当日の利用枠を確認して、正規化後の企業名を返します。
+func (u *usecase) checkAnalyze(ctx context.Context, userID int64, companyName string) (string, error) {
+	companyName = NormalizeCompanyName(companyName)
+	if companyName == "" {
+		return "", fmt.Errorf("company name is required")
+	}
+	if utf8.RuneCountInString(companyName) > MaxCompanyNameLength {
+		return "", fmt.Errorf("company name exceeds maximum length of %d characters", MaxCompanyNameLength)
+	}
+	if !validCompanyName.MatchString(companyName) {
+		return "", fmt.Errorf("company name contains invalid characters")
+	}
+	if err := u.quota.Check(ctx, userID, OperationAnalyze); err != nil {
+		return "", err
+	}
+	return companyName, nil
+}
+
+// Usage はユーザーの当日の利用状況（操作ごとの利用回数・上限・リセット時刻）を返します。
+func (u *usecase) Usage(ctx context.Context, userID int64) (Usage, error) {
+	return u.quota.Usage(ctx, userID)
+}
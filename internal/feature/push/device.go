@@ -0,0 +1,66 @@
+// Package push はアラートの発火をユーザーの端末にプッシュ通知で届けます。
+// 端末の登録トークンの管理（Usecase）と、送信待ちの通知を送るバックグラウンドのディスパッチャー（Dispatcher）を提供します。
+package push
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// MaxTokenLength は登録トークンの最大長（バイト数）です。FCM のトークンは 200 バイト前後です。
+	MaxTokenLength = 4096
+	// MaxAppVersionLength はアプリのバージョン文字列の最大文字数です（push_devices.app_version の列幅）。
+	MaxAppVersionLength = 32
+)
+
+// Platform は端末のプラットフォームです。
+type Platform string
+
+const (
+	PlatformIOS     Platform = "ios"
+	PlatformAndroid Platform = "android"
+	PlatformWeb     Platform = "web"
+)
+
+// Valid は p が対応しているプラットフォームかを返します。
+func (p Platform) Valid() bool {
+	switch p {
+	case PlatformIOS, PlatformAndroid, PlatformWeb:
+		return true
+	}
+	return false
+}
+
+// Device はプッシュ通知の送信先として登録された端末（アプリのインストール）です。
+// Token は FCM の登録トークンで、端末ごとに一意です（iOS も APNs のトークンを FCM 経由で扱います）。
+type Device struct {
+	ID         int64
+	UserID     int64
+	Token      string
+	Platform   Platform
+	AppVersion string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	// DisabledAt はプロバイダーが無効なトークンと応答して送信を止めた日時です。有効な端末では nil です。
+	DisabledAt *time.Time
+}
+
+// Validate は端末として登録できるかを検証します。不正な場合は ErrInvalidDevice をラップしたエラーを返します。
+func (d Device) Validate() error {
+	if strings.TrimSpace(d.Token) == "" {
+		return fmt.Errorf("%w: missing token", ErrInvalidDevice)
+	}
+	if len(d.Token) > MaxTokenLength || strings.ContainsAny(d.Token, " \t\r\n") {
+		return fmt.Errorf("%w: malformed token", ErrInvalidDevice)
+	}
+	if !d.Platform.Valid() {
+		return fmt.Errorf("%w: platform must be one of ios, android, web", ErrInvalidDevice)
+	}
+	if utf8.RuneCountInString(d.AppVersion) > MaxAppVersionLength {
+		return fmt.Errorf("%w: appVersion must be at most %d characters", ErrInvalidDevice, MaxAppVersionLength)
+	}
+	return nil
+}
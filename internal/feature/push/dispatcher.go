@@ -0,0 +1,318 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
+)
+
+// Dispatcher の既定値です。
+const (
+	DefaultWorkers      = 4
+	DefaultBatchSize    = 32
+	DefaultPollInterval = 2 * time.Second
+	DefaultMaxAttempts  = 5
+	DefaultBaseBackoff  = 30 * time.Second
+	DefaultMaxBackoff   = 30 * time.Minute
+	DefaultLease        = 5 * time.Minute
+	DefaultSendTimeout  = 10 * time.Second
+
+	// maxErrorLength は push_jobs.last_error・alerts.notify_error に残すエラー文字列の最大長です。
+	maxErrorLength = 500
+
+	// recordTimeout は送信結果の記録・送信待ちの手放しにかける時間の上限です。
+	recordTimeout = 5 * time.Second
+)
+
+// Notification は発火したアラート 1 件の通知です。送信待ちにはユーザーの有効な端末ごとに 1 件ずつ登録します。
+type Notification struct {
+	AlertID int64
+	UserID  int64
+	Title   string
+	Body    string
+	Data    map[string]string
+}
+
+// Job は取得済みの送信待ち（1 台の端末への 1 件の通知）です。Attempts は今回の試行を含む試行回数です。
+type Job struct {
+	ID       int64
+	AlertID  int64
+	DeviceID int64
+	Attempts int
+	Message  Message
+}
+
+// JobStore は送信待ちの永続化層を抽象化します。repository が実装します。
+type JobStore interface {
+	// Claim は期限を過ぎた送信待ちを最大 limit 件取得し、試行回数を増やして leaseUntil までは他から取得されないようにします。
+	Claim(ctx context.Context, limit int, leaseUntil time.Time) ([]Job, error)
+	MarkSent(ctx context.Context, jobID int64) error
+	MarkFailed(ctx context.Context, jobID int64, reason string) error
+	// Retry は送信待ちを at に再試行するよう戻します。
+	Retry(ctx context.Context, jobID int64, at time.Time, reason string) error
+	// Release は送信前に手放す送信待ちを、試行回数を戻してすぐに再取得できるようにします。
+	Release(ctx context.Context, jobID int64) error
+	// DisableDevice は端末を無効化し、その端末の送信待ち（取得中のものを含む）を reason で失敗にします。
+	DisableDevice(ctx context.Context, deviceID int64, reason string) error
+}
+
+// AlertRecorder は通知の配信結果をアラートに記録するインターフェースです（alerts のリポジトリが実装）。
+// push が alerts feature に直接依存しないよう、ここで定義します。
+type AlertRecorder interface {
+	RecordDelivered(ctx context.Context, alertID int64, at time.Time) error
+	RecordDeliveryFailure(ctx context.Context, alertID int64, reason string) error
+}
+
+// DispatcherConfig は Dispatcher の設定です。ゼロ値の項目は既定値を使います。
+type DispatcherConfig struct {
+	// Workers は並行して送信する worker の数です。
+	Workers int
+	// BatchSize は 1 回の取得で取り出す送信待ちの最大件数です。
+	BatchSize int
+	// PollInterval は送信待ちがないときに次の取得まで待つ時間です。
+	PollInterval time.Duration
+	// MaxAttempts は 1 件の送信待ちを試行する最大回数です。超えたら失敗としてアラートに記録します。
+	MaxAttempts int
+	// BaseBackoff・MaxBackoff は再試行までの待ち時間（BaseBackoff × 2^(試行回数-1)、MaxBackoff で頭打ち）です。
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Lease は取得した送信待ちを他から取得させない時間です。送信中にプロセスが停止した場合は Lease の経過後に再取得されます。
+	// 取得した件数分の送信（BatchSize / Workers × SendTimeout）より長くします。
+	Lease time.Duration
+	// SendTimeout は 1 件の送信にかける時間の上限です。
+	SendTimeout time.Duration
+}
+
+func (c DispatcherConfig) withDefaults() DispatcherConfig {
+	if c.Workers <= 0 {
+		c.Workers = DefaultWorkers
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = DefaultBatchSize
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = DefaultPollInterval
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = DefaultMaxAttempts
+	}
+	if c.BaseBackoff <= 0 {
+		c.BaseBackoff = DefaultBaseBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = DefaultMaxBackoff
+	}
+	if c.Lease <= 0 {
+		c.Lease = DefaultLease
+	}
+	if c.SendTimeout <= 0 {
+		c.SendTimeout = DefaultSendTimeout
+	}
+	return c
+}
+
+// DispatcherStats は Dispatcher の処理件数の累計です。
+type DispatcherStats struct {
+	Sent        uint64
+	Retried     uint64
+	Failed      uint64
+	Deactivated uint64
+}
+
+// Dispatcher は送信待ちの通知を取得し、worker プールで Sender に送ります。
+//
+// 一時的な失敗は指数バックオフで MaxAttempts まで再試行し、諦めた送信と拒否された送信（ErrRejected）は失敗として
+// アラートに理由を記録します。無効なトークン（ErrInvalidToken）の端末は無効化し、以降は送りません。
+// 送信待ちは DB の行で、取得は FOR UPDATE SKIP LOCKED で行うため、複数の API インスタンスで起動できます。
+type Dispatcher struct {
+	store  JobStore
+	sender Sender
+	alerts AlertRecorder
+	cfg    DispatcherConfig
+	now    func() time.Time
+	// pause が有効な間は取得しません（WithPause で設定。nil なら止めない）
+	pause Pauser
+
+	sent        atomic.Uint64
+	retried     atomic.Uint64
+	failed      atomic.Uint64
+	deactivated atomic.Uint64
+}
+
+// NewDispatcher は Dispatcher を生成します。送信を行うには Run を起動する必要があります。
+func NewDispatcher(store JobStore, sender Sender, alerts AlertRecorder, cfg DispatcherConfig) *Dispatcher {
+	return &Dispatcher{
+		store:  store,
+		sender: sender,
+		alerts: alerts,
+		cfg:    cfg.withDefaults(),
+		now:    time.Now,
+	}
+}
+
+// Pauser は送信を一時的に止めるべきか（メンテナンスモード）を返します。
+type Pauser interface {
+	Active(ctx context.Context) bool
+}
+
+// WithPause は p が有効な間、送信待ちの取得を止めます。送信待ちは DB に残り、再開後に送ります。
+// 送信の結果（送信済み・再試行・端末の無効化）は DB への書き込みを伴うためです。
+func (d *Dispatcher) WithPause(p Pauser) *Dispatcher {
+	d.pause = p
+	return d
+}
+
+// Stats はこれまでの処理件数を返します。
+func (d *Dispatcher) Stats() DispatcherStats {
+	return DispatcherStats{
+		Sent:        d.sent.Load(),
+		Retried:     d.retried.Load(),
+		Failed:      d.failed.Load(),
+		Deactivated: d.deactivated.Load(),
+	}
+}
+
+// Run は ctx が終了するまで送信待ちを取得して送信します。バックグラウンドの goroutine で起動し、
+// ctx の終了後は worker に渡していない送信待ちを手放し、送信中の通知（SendTimeout まで）の完了を待ってから戻ります。
+func (d *Dispatcher) Run(ctx context.Context) {
+	jobs := make(chan Job)
+	var wg sync.WaitGroup
+	for range d.cfg.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				d.deliver(j)
+			}
+		}()
+	}
+	defer func() {
+		close(jobs)
+		wg.Wait()
+	}()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		n := d.dispatch(ctx, jobs)
+		// 上限まで取得できた場合は残りがある可能性が高いため、待たずに次を取得する
+		if n == d.cfg.BatchSize {
+			timer.Reset(0)
+		} else {
+			timer.Reset(d.cfg.PollInterval)
+		}
+	}
+}
+
+// dispatch は送信待ちを 1 回取得して worker に渡し、取得した件数を返します。
+// 渡し終える前に ctx が終了した場合は、残りを手放します。
+// pause が有効な間は取得せず 0 を返します。
+func (d *Dispatcher) dispatch(ctx context.Context, jobs chan<- Job) int {
+	if d.pause != nil && d.pause.Active(ctx) {
+		return 0
+	}
+	claimed, err := d.store.Claim(ctx, d.cfg.BatchSize, d.now().Add(d.cfg.Lease))
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("failed to claim push jobs", "error", err)
+		}
+		return 0
+	}
+	for i, j := range claimed {
+		select {
+		case jobs <- j:
+		case <-ctx.Done():
+			d.release(claimed[i:])
+			return len(claimed)
+		}
+	}
+	return len(claimed)
+}
+
+// release は worker に渡していない送信待ちを手放します。停止中に呼ばれるため ctx の終了に影響されません。
+func (d *Dispatcher) release(jobs []Job) {
+	for _, j := range jobs {
+		ctx, cancel := context.WithTimeout(context.Background(), recordTimeout)
+		if err := d.store.Release(ctx, j.ID); err != nil {
+			slog.Warn("failed to release push job", "error", err, "job_id", j.ID)
+		}
+		cancel()
+	}
+}
+
+// deliver は 1 件を送信し、結果を記録します。停止中も送信中の 1 件は完了させるため、Run の ctx とは切り離して
+// SendTimeout を上限に送ります。結果の記録は送信のタイムアウトに巻き込まれないよう別の上限で行います。
+func (d *Dispatcher) deliver(j Job) {
+	sendCtx, cancelSend := context.WithTimeout(context.Background(), d.cfg.SendTimeout)
+	err := d.sender.Send(sendCtx, j.Message)
+	cancelSend()
+
+	ctx, cancel := context.WithTimeout(context.Background(), recordTimeout)
+	defer cancel()
+	switch {
+	case err == nil:
+		d.sent.Add(1)
+		if err := d.store.MarkSent(ctx, j.ID); err != nil {
+			slog.Error("failed to mark push job sent", "error", err, "job_id", j.ID)
+		}
+		if err := d.alerts.RecordDelivered(ctx, j.AlertID, d.now()); err != nil {
+			slog.Error("failed to record alert delivery", "error", err, "alert_id", j.AlertID)
+		}
+
+	case errors.Is(err, ErrInvalidToken):
+		d.deactivated.Add(1)
+		d.failed.Add(1)
+		reason := apperr.Truncate(err, maxErrorLength)
+		slog.Info("push token invalid, deactivating device", "device_id", j.DeviceID, "job_id", j.ID, "error", err)
+		if err := d.store.DisableDevice(ctx, j.DeviceID, reason); err != nil {
+			slog.Error("failed to deactivate push device", "error", err, "device_id", j.DeviceID)
+		}
+		d.recordFailure(ctx, j, reason)
+
+	case errors.Is(err, ErrRejected) || j.Attempts >= d.cfg.MaxAttempts:
+		d.failed.Add(1)
+		reason := apperr.Truncate(err, maxErrorLength)
+		slog.Warn("push notification failed, giving up", "job_id", j.ID, "alert_id", j.AlertID, "attempts", j.Attempts, "error", err)
+		if err := d.store.MarkFailed(ctx, j.ID, reason); err != nil {
+			slog.Error("failed to mark push job failed", "error", err, "job_id", j.ID)
+		}
+		d.recordFailure(ctx, j, reason)
+
+	default:
+		d.retried.Add(1)
+		at := d.now().Add(d.backoff(j.Attempts, err))
+		if err := d.store.Retry(ctx, j.ID, at, apperr.Truncate(err, maxErrorLength)); err != nil {
+			slog.Error("failed to schedule push job retry", "error", err, "job_id", j.ID)
+		}
+	}
+}
+
+func (d *Dispatcher) recordFailure(ctx context.Context, j Job, reason string) {
+	if err := d.alerts.RecordDeliveryFailure(ctx, j.AlertID, reason); err != nil {
+		slog.Error("failed to record alert delivery failure", "error", err, "alert_id", j.AlertID)
+	}
+}
+
+// backoff は attempts 回目の試行が err で失敗した後、次の試行までの待ち時間を返します。
+// BaseBackoff × 2^(attempts-1) を MaxBackoff で頭打ちにし、err が RetryAfter を示していればそれより短くしません。
+func (d *Dispatcher) backoff(attempts int, err error) time.Duration {
+	wait := d.cfg.BaseBackoff
+	for i := 1; i < attempts && wait < d.cfg.MaxBackoff; i++ {
+		wait *= 2
+	}
+	var ra interface{ RetryAfter() time.Duration }
+	if errors.As(err, &ra) && ra.RetryAfter() > wait {
+		wait = ra.RetryAfter()
+	}
+	return min(wait, d.cfg.MaxBackoff)
+}
@@ -0,0 +1,290 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeJobStore は取得させる送信待ちと、記録された結果を保持するインメモリの JobStore です。
+type fakeJobStore struct {
+	mu       sync.Mutex
+	pending  []Job
+	sent     []int64
+	failed   map[int64]string
+	retried  map[int64]time.Time
+	released []int64
+	disabled map[int64]string
+}
+
+func newFakeJobStore(jobs ...Job) *fakeJobStore {
+	return &fakeJobStore{
+		pending:  jobs,
+		failed:   map[int64]string{},
+		retried:  map[int64]time.Time{},
+		disabled: map[int64]string{},
+	}
+}
+
+func (s *fakeJobStore) Claim(_ context.Context, limit int, _ time.Time) ([]Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := min(limit, len(s.pending))
+	out := s.pending[:n]
+	s.pending = s.pending[n:]
+	return out, nil
+}
+
+func (s *fakeJobStore) MarkSent(_ context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, id)
+	return nil
+}
+
+func (s *fakeJobStore) MarkFailed(_ context.Context, id int64, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed[id] = reason
+	return nil
+}
+
+func (s *fakeJobStore) Retry(_ context.Context, id int64, at time.Time, _ string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retried[id] = at
+	return nil
+}
+
+func (s *fakeJobStore) Release(_ context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.released = append(s.released, id)
+	return nil
+}
+
+func (s *fakeJobStore) DisableDevice(_ context.Context, deviceID int64, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.disabled[deviceID] = reason
+	return nil
+}
+
+// outcomes は結果が記録された送信待ちの件数を返します。
+func (s *fakeJobStore) outcomes() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sent) + len(s.failed) + len(s.retried) + len(s.disabled)
+}
+
+// fakeAlerts はアラートに記録された配信結果を保持する AlertRecorder です。
+type fakeAlerts struct {
+	mu        sync.Mutex
+	delivered map[int64]bool
+	failures  map[int64]string
+}
+
+func newFakeAlerts() *fakeAlerts {
+	return &fakeAlerts{delivered: map[int64]bool{}, failures: map[int64]string{}}
+}
+
+func (a *fakeAlerts) RecordDelivered(_ context.Context, alertID int64, _ time.Time) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.delivered[alertID] = true
+	return nil
+}
+
+func (a *fakeAlerts) RecordDeliveryFailure(_ context.Context, alertID int64, reason string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.failures[alertID] = reason
+	return nil
+}
+
+// fakeSender はトークンごとに決めたエラーを返す Sender です。
+type fakeSender map[string]error
+
+func (s fakeSender) Send(_ context.Context, msg Message) error {
+	return s[msg.Token]
+}
+
+// retryAfterError は RetryAfter を示す一時的なエラーです（FCM の 429 相当）。
+type retryAfterError struct{ wait time.Duration }
+
+func (e retryAfterError) Error() string             { return fmt.Sprintf("throttled for %s", e.wait) }
+func (e retryAfterError) RetryAfter() time.Duration { return e.wait }
+
+func job(id int64, token string, attempts int) Job {
+	return Job{ID: id, AlertID: id * 10, DeviceID: id * 100, Attempts: attempts, Message: Message{Token: token}}
+}
+
+// runUntil は d を起動し、done が真になったら停止して Run の終了を待ちます。
+func runUntil(t *testing.T, d *Dispatcher, done func() bool) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		d.Run(ctx)
+	}()
+	require.Eventually(t, done, 2*time.Second, time.Millisecond)
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+}
+
+func TestDispatcher_Outcomes(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 8, 1, 6, 0, 0, 0, time.UTC)
+	store := newFakeJobStore(
+		job(1, "ok", 1),
+		job(2, "flaky", 2),     // 一時的な失敗 → 再試行
+		job(3, "flaky", 3),     // 上限回数での一時的な失敗 → 失敗
+		job(4, "malformed", 1), // 拒否（poison）→ 再試行せずに失敗
+		job(5, "gone", 1),      // 無効なトークン → 端末の無効化
+	)
+	sender := fakeSender{
+		"flaky":     errors.New("fcm http 503"),
+		"malformed": fmt.Errorf("fcm http 400 INVALID_ARGUMENT: %w", ErrRejected),
+		"gone":      fmt.Errorf("fcm http 404 UNREGISTERED: %w", ErrInvalidToken),
+	}
+	alerts := newFakeAlerts()
+	d := NewDispatcher(store, sender, alerts, DispatcherConfig{
+		Workers:      2,
+		MaxAttempts:  3,
+		BaseBackoff:  time.Minute,
+		MaxBackoff:   time.Hour,
+		PollInterval: time.Millisecond,
+	})
+	d.now = func() time.Time { return now }
+
+	runUntil(t, d, func() bool { return store.outcomes() == 5 })
+
+	assert.Equal(t, []int64{1}, store.sent)
+	assert.True(t, alerts.delivered[10])
+
+	// 2 回目の失敗は Base × 2 後に再試行し、アラートには記録しない
+	assert.Equal(t, map[int64]time.Time{2: now.Add(2 * time.Minute)}, store.retried)
+	assert.NotContains(t, alerts.failures, int64(20))
+
+	assert.Contains(t, store.failed[3], "503")
+	assert.Contains(t, alerts.failures[30], "503")
+	assert.Contains(t, store.failed[4], "INVALID_ARGUMENT")
+	assert.Contains(t, alerts.failures[40], "INVALID_ARGUMENT")
+
+	// 無効なトークンは端末を無効化し（端末の送信待ちも失敗になる）、アラートに記録する
+	assert.Contains(t, store.disabled[500], "UNREGISTERED")
+	assert.NotContains(t, store.failed, int64(5))
+	assert.Contains(t, alerts.failures[50], "UNREGISTERED")
+
+	assert.Equal(t, DispatcherStats{Sent: 1, Retried: 1, Failed: 3, Deactivated: 1}, d.Stats())
+}
+
+func TestDispatcher_Backoff(t *testing.T) {
+	t.Parallel()
+	d := NewDispatcher(nil, nil, nil, DispatcherConfig{BaseBackoff: 30 * time.Second, MaxBackoff: 10 * time.Minute})
+	transient := errors.New("unavailable")
+
+	tests := []struct {
+		name     string
+		attempts int
+		err      error
+		want     time.Duration
+	}{
+		{"1 回目", 1, transient, 30 * time.Second},
+		{"2 回目で倍", 2, transient, time.Minute},
+		{"4 回目", 4, transient, 4 * time.Minute},
+		{"上限で頭打ち", 10, transient, 10 * time.Minute},
+		{"RetryAfter が長ければ従う", 1, retryAfterError{2 * time.Minute}, 2 * time.Minute},
+		{"RetryAfter が短ければバックオフ", 3, retryAfterError{time.Second}, 2 * time.Minute},
+		{"RetryAfter も上限で頭打ち", 1, fmt.Errorf("wrapped: %w", retryAfterError{time.Hour}), 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, d.backoff(tt.attempts, tt.err))
+		})
+	}
+}
+
+// blockingSender は release が閉じられるまで送信を終えない Sender です。送信の開始を started に知らせます。
+type blockingSender struct {
+	started chan string
+	release chan struct{}
+}
+
+func (s *blockingSender) Send(_ context.Context, msg Message) error {
+	s.started <- msg.Token
+	<-s.release
+	return nil
+}
+
+func TestDispatcher_ShutdownReleasesUnsentJobs(t *testing.T) {
+	t.Parallel()
+
+	store := newFakeJobStore(job(1, "a", 1), job(2, "b", 1), job(3, "c", 1))
+	sender := &blockingSender{started: make(chan string, 3), release: make(chan struct{})}
+	d := NewDispatcher(store, sender, newFakeAlerts(), DispatcherConfig{Workers: 1, PollInterval: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		d.Run(ctx)
+	}()
+
+	// 1 件目の送信中（worker は 1 つ）に停止する
+	assert.Equal(t, "a", <-sender.started)
+	cancel()
+	require.Eventually(t, func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		return len(store.released) == 2
+	}, 2*time.Second, time.Millisecond)
+
+	// 送信中の 1 件が終わるまで Run は戻らない
+	select {
+	case <-stopped:
+		t.Fatal("Run returned before the in-flight send finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(sender.release)
+	<-stopped
+
+	assert.Equal(t, []int64{1}, store.sent)
+	assert.ElementsMatch(t, []int64{2, 3}, store.released)
+}
+
+// pauseSwitch は切り替えられる Pauser です。
+type pauseSwitch struct{ atomic.Bool }
+
+func (p *pauseSwitch) Active(context.Context) bool { return p.Load() }
+
+// TestDispatcher_Pause は停止中は送信待ちを取得せず、再開後に送ることを検証します。
+func TestDispatcher_Pause(t *testing.T) {
+	t.Parallel()
+
+	store := newFakeJobStore(job(1, "ok", 1))
+	pause := &pauseSwitch{}
+	pause.Store(true)
+	d := NewDispatcher(store, fakeSender{}, newFakeAlerts(), DispatcherConfig{PollInterval: time.Millisecond}).WithPause(pause)
+
+	assert.Zero(t, d.dispatch(context.Background(), make(chan Job)))
+	assert.Len(t, store.pending, 1)
+
+	pause.Store(false)
+	runUntil(t, d, func() bool { return store.outcomes() == 1 })
+	assert.Equal(t, []int64{1}, store.sent)
+}
@@ -0,0 +1,11 @@
+package push
+
+import "github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
+
+var (
+	// ErrDeviceNotFound は指定IDの端末が存在しないか、他のユーザーの端末である場合のエラーです。
+	ErrDeviceNotFound = apperr.New(apperr.KindNotFound, "device_not_found", "device not found")
+
+	// ErrInvalidDevice は端末の登録内容が不正（トークンの欠落、未対応のプラットフォーム等）な場合のエラーです。
+	ErrInvalidDevice = apperr.New(apperr.KindInvalid, "invalid_device", "invalid device")
+)
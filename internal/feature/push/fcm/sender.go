@@ -0,0 +1,222 @@
+// Package fcm は Firebase Cloud Messaging の HTTP v1 API でプッシュ通知を送る push.Sender を提供します。
+package fcm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/push"
+)
+
+const (
+	// DefaultEndpoint は FCM HTTP v1 API のベース URL です。
+	DefaultEndpoint = "https://fcm.googleapis.com"
+	// Scope は FCM の送信に必要な OAuth 2.0 のスコープです。
+	Scope = "https://www.googleapis.com/auth/firebase.messaging"
+
+	// maxErrorBody はエラーレスポンスとして読み込む本文の上限です。
+	maxErrorBody = 64 << 10
+	// maxRetryAfter は Retry-After として受け付ける待機時間の上限です。
+	maxRetryAfter = time.Hour
+)
+
+// Sender は FCM HTTP v1 API（messages:send）で 1 件ずつ通知を送ります。
+type Sender struct {
+	client *http.Client
+	url    string
+}
+
+var _ push.Sender = (*Sender)(nil)
+
+// NewSender はサービスアカウントの JSON 鍵 credentialsJSON で認証する Sender を生成します。
+// 送信先のプロジェクトは鍵の project_id です。client のタイムアウトとトランスポートを引き継ぎ、アクセストークンは自動で更新します。
+func NewSender(ctx context.Context, credentialsJSON []byte, client *http.Client) (*Sender, error) {
+	creds, err := google.CredentialsFromJSONWithType(ctx, credentialsJSON, google.ServiceAccount, Scope)
+	if err != nil {
+		return nil, fmt.Errorf("parse fcm service account: %w", err)
+	}
+	if creds.ProjectID == "" {
+		return nil, errors.New("fcm service account has no project_id")
+	}
+	authed := &http.Client{
+		Transport: &oauth2.Transport{Source: creds.TokenSource, Base: client.Transport},
+		Timeout:   client.Timeout,
+	}
+	return NewSenderWithClient(authed, DefaultEndpoint, creds.ProjectID), nil
+}
+
+// NewSenderWithClient は認証済みの client で endpoint（FCM のベース URL）のプロジェクト projectID に送る Sender を生成します。
+// テストやエミュレーターの URL を指定する場合に使います。
+func NewSenderWithClient(client *http.Client, endpoint, projectID string) *Sender {
+	return &Sender{
+		client: client,
+		url:    fmt.Sprintf("%s/v1/projects/%s/messages:send", strings.TrimRight(endpoint, "/"), url.PathEscape(projectID)),
+	}
+}
+
+type sendRequest struct {
+	Message message `json:"message"`
+}
+
+type message struct {
+	Token        string            `json:"token"`
+	Notification notification      `json:"notification"`
+	Data         map[string]string `json:"data,omitempty"`
+	Android      *androidConfig    `json:"android,omitempty"`
+	APNS         *apnsConfig       `json:"apns,omitempty"`
+}
+
+type notification struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+type androidConfig struct {
+	Priority string `json:"priority"`
+}
+
+type apnsConfig struct {
+	Headers map[string]string `json:"headers"`
+}
+
+// errorResponse は FCM のエラーレスポンス（google.rpc.Status）です。
+type errorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+		Details []struct {
+			Type      string `json:"@type"`
+			ErrorCode string `json:"errorCode"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+// Send は msg を送信します。アラートの通知は即時性が重要なため、Android・iOS とも高優先度で送ります。
+func (s *Sender) Send(ctx context.Context, msg push.Message) error {
+	m := message{
+		Token:        msg.Token,
+		Notification: notification{Title: msg.Title, Body: msg.Body},
+		Data:         msg.Data,
+	}
+	switch msg.Platform {
+	case push.PlatformAndroid:
+		m.Android = &androidConfig{Priority: "HIGH"}
+	case push.PlatformIOS:
+		m.APNS = &apnsConfig{Headers: map[string]string{"apns-priority": "10"}}
+	}
+	body, err := json.Marshal(sendRequest{Message: m})
+	if err != nil {
+		return fmt.Errorf("marshal fcm message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create fcm request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("fcm request: %w", err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode == http.StatusOK {
+		_, _ = io.Copy(io.Discard, res.Body)
+		return nil
+	}
+	return parseError(res)
+}
+
+// Error は FCM が返したエラーです。無効なトークンは push.ErrInvalidToken、再試行しても成功しない拒否は
+// push.ErrRejected として errors.Is で判定でき、それ以外（429・5xx・認証エラー等）は一時的な失敗です。
+type Error struct {
+	StatusCode int
+	// Code は FCM のエラーコード（UNREGISTERED 等）です。なければ google.rpc の status（INVALID_ARGUMENT 等）です。
+	Code    string
+	Message string
+	// Wait は Retry-After で指示された待機時間です（指示がなければ 0）。
+	Wait time.Duration
+
+	kind error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("fcm http %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// Unwrap は push.ErrInvalidToken / push.ErrRejected（一時的な失敗なら nil）を返します。
+func (e *Error) Unwrap() error {
+	return e.kind
+}
+
+// RetryAfter は再試行まで待つべき時間を返します。
+func (e *Error) RetryAfter() time.Duration {
+	return e.Wait
+}
+
+// parseError はエラーレスポンスを Error に変換します。
+// 分類は FCM のエラーコード（https://firebase.google.com/docs/reference/fcm/rest/v1/ErrorCode）に従います。
+func parseError(res *http.Response) error {
+	e := &Error{StatusCode: res.StatusCode, Wait: parseRetryAfter(res.Header.Get("Retry-After"))}
+
+	var body errorResponse
+	raw, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBody))
+	if err := json.Unmarshal(raw, &body); err == nil {
+		e.Code = body.Error.Status
+		e.Message = body.Error.Message
+		for _, d := range body.Error.Details {
+			if d.ErrorCode != "" {
+				e.Code = d.ErrorCode
+				break
+			}
+		}
+	}
+	if e.Message == "" {
+		e.Message = http.StatusText(res.StatusCode)
+	}
+
+	switch {
+	case e.Code == "UNREGISTERED" || e.Code == "SENDER_ID_MISMATCH" || res.StatusCode == http.StatusNotFound:
+		// アンインストール・期限切れ、または別プロジェクトのトークン
+		e.kind = push.ErrInvalidToken
+	case res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500:
+		// QUOTA_EXCEEDED・UNAVAILABLE・INTERNAL は一時的な失敗
+	case res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden:
+		// 認証・APNs の資格情報（THIRD_PARTY_AUTH_ERROR）の問題は設定を直せば成功するため一時的な失敗として扱う
+	default:
+		// INVALID_ARGUMENT 等の 4xx はメッセージ自体の問題で、再試行しても成功しない
+		e.kind = push.ErrRejected
+	}
+	return e
+}
+
+// parseRetryAfter は Retry-After ヘッダー（秒数または HTTP 日付）を解釈します。解釈できなければ 0 です。
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs <= 0 {
+			return 0
+		}
+		return min(time.Duration(secs)*time.Second, maxRetryAfter)
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return min(max(time.Until(t), 0), maxRetryAfter)
+	}
+	return 0
+}
@@ -0,0 +1,116 @@
+package fcm_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/push"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/push/fcm"
+)
+
+func TestSender_Send(t *testing.T) {
+	t.Parallel()
+
+	var gotPath string
+	var gotBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		raw, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(raw, &gotBody)
+		_, _ = io.WriteString(w, `{"name":"projects/demo/messages/1"}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	s := fcm.NewSenderWithClient(srv.Client(), srv.URL, "demo")
+	err := s.Send(context.Background(), push.Message{
+		Token:    "tok-1",
+		Platform: push.PlatformAndroid,
+		Title:    "AAPL の価格アラート",
+		Body:     "終値 201.3 が 200 を上回りました（1day）",
+		Data:     map[string]string{"alert_id": "9"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "/v1/projects/demo/messages:send", gotPath)
+	msg := gotBody["message"].(map[string]any)
+	assert.Equal(t, "tok-1", msg["token"])
+	assert.Equal(t, map[string]any{"title": "AAPL の価格アラート", "body": "終値 201.3 が 200 を上回りました（1day）"}, msg["notification"])
+	assert.Equal(t, map[string]any{"alert_id": "9"}, msg["data"])
+	assert.Equal(t, map[string]any{"priority": "HIGH"}, msg["android"])
+	assert.NotContains(t, msg, "apns")
+}
+
+func TestSender_Send_Errors(t *testing.T) {
+	t.Parallel()
+
+	fcmError := func(code int, status, errorCode string) string {
+		details := `[]`
+		if errorCode != "" {
+			details = `[{"@type":"type.googleapis.com/google.firebase.fcm.v1.FcmError","errorCode":"` + errorCode + `"}]`
+		}
+		return fmt.Sprintf(`{"error":{"code":%d,"message":"failed","status":%q,"details":%s}}`, code, status, details)
+	}
+
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		retryAfter  string
+		wantInvalid bool
+		wantReject  bool
+		wantWait    time.Duration
+		wantCode    string
+	}{
+		{"UNREGISTERED は無効なトークン", 404, fcmError(404, "NOT_FOUND", "UNREGISTERED"), "", true, false, 0, "UNREGISTERED"},
+		{"SENDER_ID_MISMATCH は無効なトークン", 403, fcmError(403, "PERMISSION_DENIED", "SENDER_ID_MISMATCH"), "", true, false, 0, "SENDER_ID_MISMATCH"},
+		{"INVALID_ARGUMENT は拒否", 400, fcmError(400, "INVALID_ARGUMENT", "INVALID_ARGUMENT"), "", false, true, 0, "INVALID_ARGUMENT"},
+		{"429 は Retry-After 付きの一時的な失敗", 429, fcmError(429, "RESOURCE_EXHAUSTED", "QUOTA_EXCEEDED"), "120", false, false, 2 * time.Minute, "QUOTA_EXCEEDED"},
+		{"503 は一時的な失敗", 503, fcmError(503, "UNAVAILABLE", "UNAVAILABLE"), "", false, false, 0, "UNAVAILABLE"},
+		{"401 は一時的な失敗", 401, fcmError(401, "UNAUTHENTICATED", "THIRD_PARTY_AUTH_ERROR"), "", false, false, 0, "THIRD_PARTY_AUTH_ERROR"},
+		{"本文が JSON でない 502", 502, "<html>bad gateway</html>", "", false, false, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = io.WriteString(w, tt.body)
+			}))
+			t.Cleanup(srv.Close)
+
+			err := fcm.NewSenderWithClient(srv.Client(), srv.URL, "demo").
+				Send(context.Background(), push.Message{Token: "tok", Platform: push.PlatformIOS})
+			require.Error(t, err)
+			assert.Equal(t, tt.wantInvalid, errors.Is(err, push.ErrInvalidToken), "ErrInvalidToken: %v", err)
+			assert.Equal(t, tt.wantReject, errors.Is(err, push.ErrRejected), "ErrRejected: %v", err)
+
+			var fe *fcm.Error
+			require.ErrorAs(t, err, &fe)
+			assert.Equal(t, tt.status, fe.StatusCode)
+			assert.Equal(t, tt.wantCode, fe.Code)
+			assert.Equal(t, tt.wantWait, fe.RetryAfter())
+		})
+	}
+}
+
+func TestNewSender_InvalidCredentials(t *testing.T) {
+	t.Parallel()
+
+	_, err := fcm.NewSender(context.Background(), []byte(`{"type":"authorized_user"}`), http.DefaultClient)
+	assert.Error(t, err, "サービスアカウント以外の鍵は受け付けない")
+	_, err = fcm.NewSender(context.Background(), []byte(`not json`), http.DefaultClient)
+	assert.Error(t, err)
+}
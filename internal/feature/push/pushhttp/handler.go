@@ -0,0 +1,92 @@
+package pushhttp
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/push"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+)
+
+// Usecase はプッシュ通知の端末の登録・解除のユースケースインターフェースを定義します。
+type Usecase interface {
+	Register(ctx context.Context, userID int64, token string, platform push.Platform, appVersion string) (push.Device, bool, error)
+	Unregister(ctx context.Context, userID, id int64) error
+}
+
+// Handler はプッシュ通知の端末に関連するHTTPリクエストを処理します。
+// ユーザーはJWTから取得し、リクエストで他のユーザーを指定する手段は設けません。
+type Handler struct {
+	uc Usecase
+}
+
+// NewHandler はHandlerの新しいインスタンスを生成します。
+func NewHandler(uc Usecase) *Handler {
+	return &Handler{uc: uc}
+}
+
+// Register は端末を登録し、新規登録なら 201、登録済みのトークンの更新なら 200 で端末を返します。
+func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
+	userID, ok := jwt.UserIDFromContext(r.Context())
+	if !ok {
+		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
+		return
+	}
+	var req api.RegisterDeviceRequest
+	if err := httpx.DecodeAndValidate(r, &req); err != nil {
+		httpx.WriteDecodeError(w, err, "invalid request")
+		return
+	}
+	var appVersion string
+	if req.AppVersion != nil {
+		appVersion = *req.AppVersion
+	}
+
+	d, created, err := h.uc.Register(r.Context(), userID, req.Token, push.Platform(req.Platform), appVersion)
+	if err != nil {
+		httpx.WriteError(w, err, "failed to register device", "userID", userID, "platform", req.Platform)
+		return
+	}
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	httpx.WriteJSON(w, status, toDevice(d))
+}
+
+// Unregister は {id} の端末を削除します。
+func (h *Handler) Unregister(w http.ResponseWriter, r *http.Request) {
+	userID, ok := jwt.UserIDFromContext(r.Context())
+	if !ok {
+		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
+		return
+	}
+	raw := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		// 数値でない ID は存在しない端末として扱う
+		httpx.WriteError(w, push.ErrDeviceNotFound, "invalid device id", "id", raw)
+		return
+	}
+	if err := h.uc.Unregister(r.Context(), userID, id); err != nil {
+		httpx.WriteError(w, err, "failed to unregister device", "userID", userID, "id", id)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// toDevice は端末をレスポンスに変換します。トークンは送信の資格情報に当たるため返しません。
+func toDevice(d push.Device) api.Device {
+	return api.Device{
+		Id:         d.ID,
+		Platform:   api.DevicePlatform(d.Platform),
+		AppVersion: d.AppVersion,
+		CreatedAt:  api.NewTimestamp(d.CreatedAt),
+		UpdatedAt:  api.NewTimestamp(d.UpdatedAt),
+	}
+}
@@ -0,0 +1,163 @@
+package pushhttp_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/push"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/push/pushhttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+)
+
+const testUserID int64 = 1
+
+// mockUsecase は Usecase インターフェースのモック実装です。
+type mockUsecase struct {
+	RegisterFunc   func(ctx context.Context, userID int64, token string, platform push.Platform, appVersion string) (push.Device, bool, error)
+	UnregisterFunc func(ctx context.Context, userID, id int64) error
+}
+
+func (m *mockUsecase) Register(ctx context.Context, userID int64, token string, platform push.Platform, appVersion string) (push.Device, bool, error) {
+	if m.RegisterFunc != nil {
+		return m.RegisterFunc(ctx, userID, token, platform, appVersion)
+	}
+	return push.Device{}, true, nil
+}
+
+func (m *mockUsecase) Unregister(ctx context.Context, userID, id int64) error {
+	if m.UnregisterFunc != nil {
+		return m.UnregisterFunc(ctx, userID, id)
+	}
+	return nil
+}
+
+// newRouter は認証済みユーザーIDを context に注入し、本番と同じパスでハンドラーを登録した chi ルーターを構築します。
+func newRouter(uc *mockUsecase) chi.Router {
+	h := pushhttp.NewHandler(uc)
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			next.ServeHTTP(w, req.WithContext(jwt.WithUserID(req.Context(), testUserID)))
+		})
+	})
+	r.Post("/me/devices", h.Register)
+	r.Delete("/me/devices/{id}", h.Unregister)
+	return r
+}
+
+func serve(r http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestDevicesHandler_Register(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2026, 8, 1, 6, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		created bool
+		want    int
+	}{
+		{"新規登録は 201", true, http.StatusCreated},
+		{"登録済みのトークンの更新は 200", false, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var gotUser int64
+			var gotToken, gotVersion string
+			var gotPlatform push.Platform
+			uc := &mockUsecase{RegisterFunc: func(_ context.Context, userID int64, token string, platform push.Platform, appVersion string) (push.Device, bool, error) {
+				gotUser, gotToken, gotPlatform, gotVersion = userID, token, platform, appVersion
+				return push.Device{ID: 3, UserID: userID, Token: token, Platform: platform, AppVersion: appVersion, CreatedAt: at, UpdatedAt: at}, tt.created, nil
+			}}
+
+			w := serve(newRouter(uc), http.MethodPost, "/me/devices", `{"token":"tok-1","platform":"ios","appVersion":"1.2.0"}`)
+			require.Equal(t, tt.want, w.Code, w.Body.String())
+			assert.Equal(t, testUserID, gotUser)
+			assert.Equal(t, "tok-1", gotToken)
+			assert.Equal(t, push.PlatformIOS, gotPlatform)
+			assert.Equal(t, "1.2.0", gotVersion)
+
+			var got api.Device
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			assert.Equal(t, int64(3), got.Id)
+			assert.Equal(t, api.DevicePlatformIos, got.Platform)
+			assert.NotContains(t, w.Body.String(), "tok-1", "トークンは返さない")
+		})
+	}
+}
+
+func TestDevicesHandler_Register_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"トークンなし", `{"platform":"ios"}`},
+		{"未対応のプラットフォーム", `{"token":"tok","platform":"windows"}`},
+		{"長すぎるバージョン", `{"token":"tok","platform":"web","appVersion":"` + strings.Repeat("9", 33) + `"}`},
+		{"JSON でない", `token=tok`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			called := false
+			uc := &mockUsecase{RegisterFunc: func(context.Context, int64, string, push.Platform, string) (push.Device, bool, error) {
+				called = true
+				return push.Device{}, true, nil
+			}}
+			w := serve(newRouter(uc), http.MethodPost, "/me/devices", tt.body)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.False(t, called)
+		})
+	}
+
+	t.Run("usecase の検証エラーは 400", func(t *testing.T) {
+		t.Parallel()
+		uc := &mockUsecase{RegisterFunc: func(context.Context, int64, string, push.Platform, string) (push.Device, bool, error) {
+			return push.Device{}, false, push.ErrInvalidDevice
+		}}
+		w := serve(newRouter(uc), http.MethodPost, "/me/devices", `{"token":"tok en","platform":"ios"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestDevicesHandler_Unregister(t *testing.T) {
+	t.Parallel()
+
+	var gotUser, gotID int64
+	uc := &mockUsecase{UnregisterFunc: func(_ context.Context, userID, id int64) error {
+		gotUser, gotID = userID, id
+		if id != 3 {
+			return push.ErrDeviceNotFound
+		}
+		return nil
+	}}
+	r := newRouter(uc)
+
+	w := serve(r, http.MethodDelete, "/me/devices/3", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, testUserID, gotUser)
+	assert.Equal(t, int64(3), gotID)
+
+	assert.Equal(t, http.StatusNotFound, serve(r, http.MethodDelete, "/me/devices/4", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(r, http.MethodDelete, "/me/devices/abc", "").Code)
+}
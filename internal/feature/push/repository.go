@@ -0,0 +1,167 @@
+package push
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/push/sqlc"
+)
+
+// repository は Repository・JobStore の sqlc ベース実装です。
+type repository struct {
+	db *sql.DB
+	q  *pushsqlc.Queries
+}
+
+var (
+	_ Repository = (*repository)(nil)
+	_ JobStore   = (*repository)(nil)
+)
+
+// NewRepository は指定された *sql.DB で repository の新しいインスタンスを生成します。
+func NewRepository(db *sql.DB) *repository {
+	return &repository{db: db, q: pushsqlc.New(db)}
+}
+
+// Upsert はトークンをキーに端末を登録します。既存のトークンは内容を置き換えて無効化を解除し、created=false を返します。
+func (r *repository) Upsert(ctx context.Context, d Device) (Device, bool, error) {
+	row, err := r.q.UpsertDevice(ctx, pushsqlc.UpsertDeviceParams{
+		UserID:     d.UserID,
+		Token:      d.Token,
+		Platform:   string(d.Platform),
+		AppVersion: d.AppVersion,
+	})
+	if err != nil {
+		return Device{}, false, err
+	}
+	dev := Device{
+		ID:         row.ID,
+		UserID:     row.UserID,
+		Token:      row.Token,
+		Platform:   Platform(row.Platform),
+		AppVersion: row.AppVersion,
+		CreatedAt:  row.CreatedAt,
+		UpdatedAt:  row.UpdatedAt,
+	}
+	if row.DisabledAt.Valid {
+		t := row.DisabledAt.Time
+		dev.DisabledAt = &t
+	}
+	return dev, row.Inserted, nil
+}
+
+// Delete はユーザー userID の端末 id を削除します。存在しない・他のユーザーの端末の場合は ErrDeviceNotFound を返します。
+// 端末の送信待ちは FK の ON DELETE CASCADE で削除されます。
+func (r *repository) Delete(ctx context.Context, userID, id int64) error {
+	n, err := r.q.DeleteDevice(ctx, pushsqlc.DeleteDeviceParams{UserID: userID, ID: id})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrDeviceNotFound
+	}
+	return nil
+}
+
+// Enqueue は通知 n をユーザーの有効な端末ごとの送信待ちとして登録し、登録した件数を返します。
+// 同じアラート・端末の送信待ちが登録済みの場合は登録しないため、同じ通知を繰り返し登録しても重複しません。
+func (r *repository) Enqueue(ctx context.Context, n Notification) (int, error) {
+	data, err := json.Marshal(n.Data)
+	if err != nil {
+		return 0, fmt.Errorf("marshal push data: %w", err)
+	}
+	if n.Data == nil {
+		data = []byte("{}")
+	}
+	count, err := r.q.EnqueueJobs(ctx, pushsqlc.EnqueueJobsParams{
+		AlertID: n.AlertID,
+		UserID:  n.UserID,
+		Title:   n.Title,
+		Body:    n.Body,
+		Data:    string(data),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// Claim は期限を過ぎた送信待ちを最大 limit 件取得します。他のディスパッチャーが取得中の行は飛ばします。
+func (r *repository) Claim(ctx context.Context, limit int, leaseUntil time.Time) ([]Job, error) {
+	rows, err := r.q.ClaimJobs(ctx, pushsqlc.ClaimJobsParams{MaxJobs: int32(limit), LeaseUntil: leaseUntil})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Job, 0, len(rows))
+	for _, row := range rows {
+		var data map[string]string
+		if err := json.Unmarshal([]byte(row.Data), &data); err != nil {
+			return nil, fmt.Errorf("unmarshal push data of job %d: %w", row.ID, err)
+		}
+		out = append(out, Job{
+			ID:       row.ID,
+			AlertID:  row.AlertID,
+			DeviceID: row.DeviceID,
+			Attempts: int(row.Attempts),
+			Message: Message{
+				Token:    row.Token,
+				Platform: Platform(row.Platform),
+				Title:    row.Title,
+				Body:     row.Body,
+				Data:     data,
+			},
+		})
+	}
+	return out, nil
+}
+
+// MarkSent は送信待ちを送信済みにします。
+func (r *repository) MarkSent(ctx context.Context, jobID int64) error {
+	return r.q.MarkJobSent(ctx, jobID)
+}
+
+// MarkFailed は送信待ちを reason で失敗にします。
+func (r *repository) MarkFailed(ctx context.Context, jobID int64, reason string) error {
+	return r.q.MarkJobFailed(ctx, pushsqlc.MarkJobFailedParams{
+		ID:        jobID,
+		LastError: sql.NullString{String: reason, Valid: true},
+	})
+}
+
+// Retry は送信待ちを at に再試行するよう戻し、直近の失敗の理由を残します。
+func (r *repository) Retry(ctx context.Context, jobID int64, at time.Time, reason string) error {
+	return r.q.RetryJob(ctx, pushsqlc.RetryJobParams{
+		ID:            jobID,
+		NextAttemptAt: at,
+		LastError:     sql.NullString{String: reason, Valid: true},
+	})
+}
+
+// Release は送信前に手放す送信待ちの試行回数を戻し、すぐに再取得できるようにします。
+func (r *repository) Release(ctx context.Context, jobID int64) error {
+	return r.q.ReleaseJob(ctx, jobID)
+}
+
+// DisableDevice は端末を無効化し、その端末の送信待ちを reason で失敗にします。両者は 1 トランザクションで行います。
+func (r *repository) DisableDevice(ctx context.Context, deviceID int64, reason string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	q := r.q.WithTx(tx)
+	if err := q.DisableDevice(ctx, deviceID); err != nil {
+		return fmt.Errorf("disable device: %w", err)
+	}
+	if err := q.FailPendingJobsForDevice(ctx, pushsqlc.FailPendingJobsForDeviceParams{
+		DeviceID:  deviceID,
+		LastError: sql.NullString{String: reason, Valid: true},
+	}); err != nil {
+		return fmt.Errorf("fail pending jobs: %w", err)
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,162 @@
+package push
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/db/dbtest"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/testsupport"
+)
+
+func TestMain(m *testing.M) {
+	code, err := dbtest.RunMainWithPostgres(m)
+	if err != nil {
+		log.Fatalf("dbtest setup: %v", err)
+	}
+	os.Exit(code)
+}
+
+type fixture struct {
+	db       *sql.DB
+	u1, u2   int64
+	alertID  int64
+	alert2ID int64
+}
+
+// newFixture はテスト用 DB を作成し、push_devices / push_jobs の FK 先であるユーザーとアラートをあらかじめ投入します。
+func newFixture(t *testing.T) fixture {
+	t.Helper()
+	f := fixture{db: testsupport.NewDB(t, testsupport.InsertSymbols("AAPL"))}
+	f.u1, f.u2 = testsupport.InsertUser(t, f.db).ID, testsupport.InsertUser(t, f.db).ID
+	for _, id := range []*int64{&f.alertID, &f.alert2ID} {
+		require.NoError(t, f.db.QueryRowContext(t.Context(),
+			`INSERT INTO alerts (user_id, symbol_code, "interval", direction, threshold)
+			 VALUES ($1, 'AAPL', '1day', 'above', 100) RETURNING id`, f.u1).Scan(id))
+	}
+	return f
+}
+
+func TestRepository_DeviceUpsertAndDelete(t *testing.T) {
+	t.Parallel()
+	f := newFixture(t)
+	repo := NewRepository(f.db)
+	ctx := context.Background()
+
+	d, created, err := repo.Upsert(ctx, Device{UserID: f.u1, Token: "tok-1", Platform: PlatformIOS, AppVersion: "1.0.0"})
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Positive(t, d.ID)
+	assert.Nil(t, d.DisabledAt)
+
+	// 同じトークンは上書きし、別のユーザーなら付け替える
+	again, created, err := repo.Upsert(ctx, Device{UserID: f.u2, Token: "tok-1", Platform: PlatformIOS, AppVersion: "1.1.0"})
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, d.ID, again.ID)
+	assert.Equal(t, f.u2, again.UserID)
+	assert.Equal(t, "1.1.0", again.AppVersion)
+
+	assert.ErrorIs(t, repo.Delete(ctx, f.u1, d.ID), ErrDeviceNotFound, "付け替え前のユーザーからは削除できない")
+	require.NoError(t, repo.Delete(ctx, f.u2, d.ID))
+	assert.ErrorIs(t, repo.Delete(ctx, f.u2, d.ID), ErrDeviceNotFound)
+}
+
+func TestRepository_EnqueueAndClaim(t *testing.T) {
+	t.Parallel()
+	f := newFixture(t)
+	repo := NewRepository(f.db)
+	ctx := context.Background()
+
+	_, _, err := repo.Upsert(ctx, Device{UserID: f.u1, Token: "tok-1", Platform: PlatformAndroid})
+	require.NoError(t, err)
+	_, _, err = repo.Upsert(ctx, Device{UserID: f.u1, Token: "tok-2", Platform: PlatformIOS})
+	require.NoError(t, err)
+	_, _, err = repo.Upsert(ctx, Device{UserID: f.u2, Token: "tok-other", Platform: PlatformIOS})
+	require.NoError(t, err)
+
+	n := Notification{AlertID: f.alertID, UserID: f.u1, Title: "AAPL", Body: "上回りました", Data: map[string]string{"symbol": "AAPL"}}
+	count, err := repo.Enqueue(ctx, n)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count, "ユーザーの有効な端末ごとに 1 件")
+	count, err = repo.Enqueue(ctx, n)
+	require.NoError(t, err)
+	assert.Zero(t, count, "同じアラート・端末は重複させない")
+
+	lease := time.Now().Add(time.Minute)
+	jobs, err := repo.Claim(ctx, 10, lease)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, 1, jobs[0].Attempts)
+	assert.Equal(t, map[string]string{"symbol": "AAPL"}, jobs[0].Message.Data)
+	assert.ElementsMatch(t, []string{"tok-1", "tok-2"}, []string{jobs[0].Message.Token, jobs[1].Message.Token})
+
+	// lease 中は再取得されない。手放した行は試行回数を戻してすぐに再取得される
+	again, err := repo.Claim(ctx, 10, lease)
+	require.NoError(t, err)
+	assert.Empty(t, again)
+	require.NoError(t, repo.Release(ctx, jobs[0].ID))
+	again, err = repo.Claim(ctx, 10, lease)
+	require.NoError(t, err)
+	require.Len(t, again, 1)
+	assert.Equal(t, jobs[0].ID, again[0].ID)
+	assert.Equal(t, 1, again[0].Attempts)
+
+	// 再試行は期限まで取得されない
+	require.NoError(t, repo.Retry(ctx, again[0].ID, time.Now().Add(-time.Second), "fcm http 503"))
+	require.NoError(t, repo.MarkSent(ctx, jobs[1].ID))
+	again, err = repo.Claim(ctx, 10, lease)
+	require.NoError(t, err)
+	require.Len(t, again, 1)
+	assert.Equal(t, 2, again[0].Attempts)
+	require.NoError(t, repo.MarkFailed(ctx, again[0].ID, "gave up"))
+
+	var status, lastError string
+	require.NoError(t, f.db.QueryRowContext(ctx,
+		`SELECT status, last_error FROM push_jobs WHERE id = $1`, again[0].ID).Scan(&status, &lastError))
+	assert.Equal(t, "failed", status)
+	assert.Equal(t, "gave up", lastError)
+	require.NoError(t, f.db.QueryRowContext(ctx,
+		`SELECT status FROM push_jobs WHERE id = $1`, jobs[1].ID).Scan(&status))
+	assert.Equal(t, "sent", status)
+}
+
+func TestRepository_DisableDevice(t *testing.T) {
+	t.Parallel()
+	f := newFixture(t)
+	repo := NewRepository(f.db)
+	ctx := context.Background()
+
+	d, _, err := repo.Upsert(ctx, Device{UserID: f.u1, Token: "tok-1", Platform: PlatformAndroid})
+	require.NoError(t, err)
+	for _, alertID := range []int64{f.alertID, f.alert2ID} {
+		_, err := repo.Enqueue(ctx, Notification{AlertID: alertID, UserID: f.u1, Title: "t", Body: "b"})
+		require.NoError(t, err)
+	}
+	jobs, err := repo.Claim(ctx, 1, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+
+	require.NoError(t, repo.DisableDevice(ctx, d.ID, "UNREGISTERED"))
+
+	// 取得中のものを含め、端末の送信待ちは全て失敗になり、以降は取得・登録されない
+	var failed int
+	require.NoError(t, f.db.QueryRowContext(ctx,
+		`SELECT count(*) FROM push_jobs WHERE device_id = $1 AND status = 'failed' AND last_error = 'UNREGISTERED'`, d.ID).Scan(&failed))
+	assert.Equal(t, 2, failed)
+	jobs, err = repo.Claim(ctx, 10, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+
+	// 再登録で送信を再開する
+	again, created, err := repo.Upsert(ctx, Device{UserID: f.u1, Token: "tok-1", Platform: PlatformAndroid})
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Nil(t, again.DisabledAt)
+}
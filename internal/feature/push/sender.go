@@ -0,0 +1,60 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+var (
+	// ErrInvalidToken は送信先のトークンが無効（アンインストール・期限切れ・別プロジェクトのトークン）である場合のエラーです。
+	// ディスパッチャーは端末を無効化し、以降そのトークンには送りません。
+	ErrInvalidToken = errors.New("push: invalid registration token")
+
+	// ErrRejected はプロバイダーがメッセージ自体を不正として拒否した場合のエラーです。
+	// 再試行しても成功しないため、ディスパッチャーは再試行せずに失敗とします。
+	ErrRejected = errors.New("push: message rejected")
+)
+
+// Message は 1 台の端末に送る通知です。Data はアプリに渡すキーと値で、FCM の data と同じく値は文字列に限ります。
+type Message struct {
+	Token    string
+	Platform Platform
+	Title    string
+	Body     string
+	Data     map[string]string
+}
+
+// Sender はプッシュ通知の送信を抽象化します。fcm.Sender と LogSender が実装します。
+//
+// トークンが無効なら ErrInvalidToken、メッセージが拒否されたなら ErrRejected をラップしたエラーを返します。
+// それ以外のエラーは一時的な失敗として再試行します。エラーが RetryAfter() time.Duration を実装していれば、
+// 次の試行はその時間より前には行いません。
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// LogSender は通知を送らずにログへ出力する Sender です。プッシュ通知の資格情報を設定しない開発環境で使います。
+type LogSender struct{}
+
+var _ Sender = LogSender{}
+
+// Send は通知の内容をログに出力します。トークンは先頭のみを出力します。
+func (LogSender) Send(_ context.Context, msg Message) error {
+	slog.Info("push notification (log sender)",
+		"token_prefix", tokenPrefix(msg.Token),
+		"platform", msg.Platform,
+		"title", msg.Title,
+		"body", msg.Body,
+		"data", msg.Data,
+	)
+	return nil
+}
+
+// tokenPrefix はログに出す登録トークンの先頭 8 文字を返します。トークン全体は送信の資格情報に当たるため出力しません。
+func tokenPrefix(token string) string {
+	if len(token) <= 8 {
+		return token
+	}
+	return token[:8] + "..."
+}
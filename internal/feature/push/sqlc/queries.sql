@@ -0,0 +1,83 @@
+-- name: UpsertDevice :one
+-- トークンをキーに登録する。既存のトークンはユーザー・プラットフォーム・バージョンを置き換え、無効化を解除する。
+-- inserted は新規登録なら true（xmax = 0 は INSERT された行）。
+INSERT INTO push_devices (user_id, token, platform, app_version)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (token) DO UPDATE
+SET user_id = EXCLUDED.user_id,
+    platform = EXCLUDED.platform,
+    app_version = EXCLUDED.app_version,
+    updated_at = now(),
+    disabled_at = NULL
+RETURNING id, user_id, token, platform, app_version, created_at, updated_at, disabled_at,
+          (xmax = 0)::boolean AS inserted;
+
+-- name: DeleteDevice :execrows
+DELETE FROM push_devices
+WHERE user_id = $1 AND id = $2;
+
+-- name: DisableDevice :exec
+UPDATE push_devices
+SET disabled_at = now()
+WHERE id = $1 AND disabled_at IS NULL;
+
+-- name: EnqueueJobs :execrows
+-- ユーザーの有効な端末ごとに送信待ちを登録する。同じアラート・端末の登録済みの行はそのままにする（再実行で重複させない）。
+INSERT INTO push_jobs (alert_id, device_id, title, body, data)
+SELECT sqlc.arg(alert_id), d.id, sqlc.arg(title), sqlc.arg(body), sqlc.arg(data)
+FROM push_devices d
+WHERE d.user_id = sqlc.arg(user_id) AND d.disabled_at IS NULL
+ON CONFLICT (alert_id, device_id) DO NOTHING;
+
+-- name: ClaimJobs :many
+-- 期限を過ぎた送信待ちを最大 max_jobs 件取得し、attempts を増やして next_attempt_at を lease_until まで進める。
+-- SKIP LOCKED で他のディスパッチャーが取得中の行は飛ばす。無効化された端末の行は取得しない。
+WITH due AS (
+    SELECT j.id
+    FROM push_jobs j
+    JOIN push_devices d ON d.id = j.device_id
+    WHERE j.status = 'pending'
+      AND j.next_attempt_at <= now()
+      AND d.disabled_at IS NULL
+    ORDER BY j.next_attempt_at, j.id
+    LIMIT sqlc.arg(max_jobs)
+    FOR UPDATE OF j SKIP LOCKED
+), claimed AS (
+    UPDATE push_jobs j
+    SET attempts = j.attempts + 1,
+        next_attempt_at = sqlc.arg(lease_until)
+    FROM due
+    WHERE j.id = due.id
+    RETURNING j.id, j.alert_id, j.device_id, j.title, j.body, j.data, j.attempts
+)
+SELECT c.id, c.alert_id, c.device_id, c.title, c.body, c.data, c.attempts, d.token, d.platform
+FROM claimed c
+JOIN push_devices d ON d.id = c.device_id
+ORDER BY c.id;
+
+-- name: MarkJobSent :exec
+UPDATE push_jobs
+SET status = 'sent', last_error = NULL, completed_at = now()
+WHERE id = $1;
+
+-- name: MarkJobFailed :exec
+UPDATE push_jobs
+SET status = 'failed', last_error = $2, completed_at = now()
+WHERE id = $1;
+
+-- name: RetryJob :exec
+UPDATE push_jobs
+SET next_attempt_at = $2, last_error = $3
+WHERE id = $1;
+
+-- name: ReleaseJob :exec
+-- 送信前に停止した取得済みの行を、試行回数を戻してすぐに再取得できるようにする。
+UPDATE push_jobs
+SET attempts = GREATEST(attempts - 1, 0), next_attempt_at = now()
+WHERE id = $1 AND status = 'pending';
+
+-- name: FailPendingJobsForDevice :exec
+-- 無効化した端末の残りの送信待ちを失敗にする。
+UPDATE push_jobs
+SET status = 'failed', last_error = $2, completed_at = now()
+WHERE device_id = $1 AND status = 'pending';
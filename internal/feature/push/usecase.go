@@ -0,0 +1,50 @@
+package push
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Repository は端末の永続化層を抽象化します。
+type Repository interface {
+	// Upsert はトークンをキーに端末を登録します。既存のトークンはユーザー・プラットフォーム・バージョンを置き換え、
+	// 無効化を解除します。新規登録なら created=true です。
+	Upsert(ctx context.Context, d Device) (dev Device, created bool, err error)
+	// Delete はユーザー userID の端末 id を削除します。存在しない・他のユーザーの端末の場合は ErrDeviceNotFound です。
+	Delete(ctx context.Context, userID, id int64) error
+}
+
+// usecase は端末の登録・解除のビジネスロジックを提供します。
+type usecase struct {
+	repo Repository
+}
+
+// NewUsecase は usecase の新しいインスタンスを生成します。
+func NewUsecase(repo Repository) *usecase {
+	return &usecase{repo: repo}
+}
+
+// Register はユーザー userID の端末を登録し、登録後の端末と新規登録かどうかを返します。
+// 同じトークンが登録済みの場合は上書きします（別のユーザーの端末だった場合は userID に付け替えます）。
+func (u *usecase) Register(ctx context.Context, userID int64, token string, platform Platform, appVersion string) (Device, bool, error) {
+	d := Device{
+		UserID:     userID,
+		Token:      strings.TrimSpace(token),
+		Platform:   platform,
+		AppVersion: strings.TrimSpace(appVersion),
+	}
+	if err := d.Validate(); err != nil {
+		return Device{}, false, err
+	}
+	dev, created, err := u.repo.Upsert(ctx, d)
+	if err != nil {
+		return Device{}, false, fmt.Errorf("upsert device: %w", err)
+	}
+	return dev, created, nil
+}
+
+// Unregister はユーザー userID の端末 id を削除します。送信待ちの通知も削除されます。
+func (u *usecase) Unregister(ctx context.Context, userID, id int64) error {
+	return u.repo.Delete(ctx, userID, id)
+}
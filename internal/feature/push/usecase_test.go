@@ -0,0 +1,108 @@
+package push_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/push"
+)
+
+// fakeRepository はトークンをキーにしたインメモリの Repository です（DB の ON CONFLICT (token) と同じ振る舞い）。
+type fakeRepository struct {
+	nextID  int64
+	byToken map[string]push.Device
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{byToken: make(map[string]push.Device)}
+}
+
+func (f *fakeRepository) Upsert(_ context.Context, d push.Device) (push.Device, bool, error) {
+	if cur, ok := f.byToken[d.Token]; ok {
+		d.ID = cur.ID
+		f.byToken[d.Token] = d
+		return d, false, nil
+	}
+	f.nextID++
+	d.ID = f.nextID
+	f.byToken[d.Token] = d
+	return d, true, nil
+}
+
+func (f *fakeRepository) Delete(_ context.Context, userID, id int64) error {
+	for token, d := range f.byToken {
+		if d.ID == id && d.UserID == userID {
+			delete(f.byToken, token)
+			return nil
+		}
+	}
+	return push.ErrDeviceNotFound
+}
+
+func TestUsecase_Register(t *testing.T) {
+	t.Parallel()
+	repo := newFakeRepository()
+	uc := push.NewUsecase(repo)
+	ctx := context.Background()
+
+	d, created, err := uc.Register(ctx, 1, " tok-1 ", push.PlatformIOS, "1.2.0")
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, "tok-1", d.Token, "前後の空白は除く")
+	assert.Equal(t, int64(1), d.UserID)
+
+	// 同じトークンの再登録は上書き（別のユーザーなら付け替え）
+	again, created, err := uc.Register(ctx, 2, "tok-1", push.PlatformIOS, "1.3.0")
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, d.ID, again.ID)
+	assert.Equal(t, int64(2), again.UserID)
+	assert.Equal(t, "1.3.0", again.AppVersion)
+	assert.Len(t, repo.byToken, 1)
+}
+
+func TestUsecase_Register_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		token      string
+		platform   push.Platform
+		appVersion string
+	}{
+		{"トークンなし", "  ", push.PlatformAndroid, ""},
+		{"空白を含むトークン", "tok en", push.PlatformAndroid, ""},
+		{"長すぎるトークン", strings.Repeat("a", push.MaxTokenLength+1), push.PlatformAndroid, ""},
+		{"未対応のプラットフォーム", "tok", "windows", ""},
+		{"長すぎるバージョン", "tok", push.PlatformWeb, strings.Repeat("9", push.MaxAppVersionLength+1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repo := newFakeRepository()
+			_, _, err := push.NewUsecase(repo).Register(context.Background(), 1, tt.token, tt.platform, tt.appVersion)
+			assert.True(t, errors.Is(err, push.ErrInvalidDevice), "got %v", err)
+			assert.Empty(t, repo.byToken)
+		})
+	}
+}
+
+func TestUsecase_Unregister(t *testing.T) {
+	t.Parallel()
+	repo := newFakeRepository()
+	uc := push.NewUsecase(repo)
+	ctx := context.Background()
+
+	d, _, err := uc.Register(ctx, 1, "tok-1", push.PlatformAndroid, "")
+	require.NoError(t, err)
+
+	// 他のユーザーの端末は存在しないものとして扱う
+	assert.ErrorIs(t, uc.Unregister(ctx, 2, d.ID), push.ErrDeviceNotFound)
+	require.NoError(t, uc.Unregister(ctx, 1, d.ID))
+	assert.ErrorIs(t, uc.Unregister(ctx, 1, d.ID), push.ErrDeviceNotFound)
+}
@@ -0,0 +1,116 @@
+package rates
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultCacheTTL は外部APIから取得したレートをキャッシュする期間です。
+const DefaultCacheTTL = time.Hour
+
+// fallbackCacheTTL は外部API以外（設定値）のレートをキャッシュする期間です。
+// 外部APIの復旧後に早く最新のレートへ戻れるよう、DefaultCacheTTL より短くしています。
+const fallbackCacheTTL = 5 * time.Minute
+
+// RedisProvider は現在利用できる Redis クライアントを返します。障害中は nil を返します。
+type RedisProvider interface {
+	Client() *redis.Client
+}
+
+// CachingProvider は Provider に Redis キャッシュをデコレータパターンで追加します。
+// キャッシュはペアごとのキー（<prefix>:<BASE>:<QUOTE>）に JSON で保存します。
+type CachingProvider struct {
+	inner    Provider
+	rdb      *redis.Client
+	provider RedisProvider
+	prefix   string
+	ttl      time.Duration
+}
+
+var _ Provider = (*CachingProvider)(nil)
+
+// NewCachingProvider は inner にキャッシュを追加する Provider を生成します。
+// prefix は環境の名前空間を含むキーの接頭辞（例: "staging:fx"）です。
+// rdb が nil の場合はキャッシュせず inner の結果をそのまま返します。
+func NewCachingProvider(rdb *redis.Client, inner Provider, prefix string, ttl time.Duration) *CachingProvider {
+	return &CachingProvider{inner: inner, rdb: rdb, prefix: prefix, ttl: ttl}
+}
+
+// WithRedisProvider は Redis クライアントを呼び出しごとに p から取得するよう設定します。
+// p が nil を返す間はキャッシュせず inner の結果をそのまま返します。
+func (c *CachingProvider) WithRedisProvider(p RedisProvider) *CachingProvider {
+	c.provider = p
+	return c
+}
+
+func (c *CachingProvider) client() *redis.Client {
+	if c.provider != nil {
+		return c.provider.Client()
+	}
+	return c.rdb
+}
+
+// Rate はキャッシュからレートを返し、ミス時は inner から取得してキャッシュします。
+func (c *CachingProvider) Rate(ctx context.Context, base, quote string) (Rate, error) {
+	if base == quote {
+		return Identity(base), nil
+	}
+	if r, ok := c.load(ctx, base, quote); ok {
+		return r, nil
+	}
+	r, err := c.inner.Rate(ctx, base, quote)
+	if err != nil {
+		return Rate{}, err
+	}
+	c.Store(ctx, r)
+	return r, nil
+}
+
+// Store はレートをキャッシュに保存します（ベストエフォート）。
+// ingest 時の定期取得で得たレートを書き込み、ユーザー向けのリクエストで外部APIを呼ばずに済むようにします。
+func (c *CachingProvider) Store(ctx context.Context, r Rate) {
+	rdb := c.client()
+	if rdb == nil {
+		return
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return
+	}
+	ttl := c.ttl
+	if r.Source != SourceUpstream {
+		ttl = min(ttl, fallbackCacheTTL)
+	}
+	if err := rdb.Set(ctx, c.key(r.Base, r.Quote), b, ttl).Err(); err != nil {
+		slog.WarnContext(ctx, "failed to cache exchange rate", "component", "cache", "pair", r.Pair(), "error", err)
+	}
+}
+
+// load はキャッシュからレートを読み込みます。ミス・破損・Redis 障害時は ok=false を返します。
+func (c *CachingProvider) load(ctx context.Context, base, quote string) (Rate, bool) {
+	rdb := c.client()
+	if rdb == nil {
+		return Rate{}, false
+	}
+	b, err := rdb.Get(ctx, c.key(base, quote)).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			slog.WarnContext(ctx, "exchange rate cache read failed", "component", "cache", "pair", Pair(base, quote), "error", err)
+		}
+		return Rate{}, false
+	}
+	var r Rate
+	if err := json.Unmarshal(b, &r); err != nil {
+		return Rate{}, false
+	}
+	return r, true
+}
+
+func (c *CachingProvider) key(base, quote string) string {
+	return c.prefix + ":" + base + ":" + quote
+}
@@ -0,0 +1,98 @@
+package rates
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testFXPrefix = "test:fx"
+
+// countingProvider は呼び出し回数を数える Provider です。err を設定すると取得失敗を模擬します。
+type countingProvider struct {
+	rate  Rate
+	err   error
+	calls int
+}
+
+func (p *countingProvider) Rate(_ context.Context, base, quote string) (Rate, error) {
+	p.calls++
+	if p.err != nil {
+		return Rate{}, p.err
+	}
+	r := p.rate
+	r.Base, r.Quote = base, quote
+	return r, nil
+}
+
+func newTestCachingProvider(t *testing.T, inner Provider) (*CachingProvider, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return NewCachingProvider(rdb, inner, testFXPrefix, DefaultCacheTTL), mr
+}
+
+func TestCachingProvider_CachesUpstreamForAnHour(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	inner := &countingProvider{rate: Rate{Value: 150.25, AsOf: at, Source: SourceUpstream}}
+	p, mr := newTestCachingProvider(t, inner)
+	ctx := context.Background()
+
+	for range 3 {
+		r, err := p.Rate(ctx, "USD", "JPY")
+		require.NoError(t, err)
+		assert.Equal(t, 150.25, r.Value)
+		assert.True(t, r.AsOf.Equal(at))
+	}
+	assert.Equal(t, 1, inner.calls, "2 回目以降はキャッシュから返すこと")
+	assert.Equal(t, time.Hour, mr.TTL(testFXPrefix+":USD:JPY"))
+
+	mr.FastForward(time.Hour)
+	_, err := p.Rate(ctx, "USD", "JPY")
+	require.NoError(t, err)
+	assert.Equal(t, 2, inner.calls, "TTL 経過後は再取得すること")
+}
+
+func TestCachingProvider_FallbackRateHasShortTTL(t *testing.T) {
+	t.Parallel()
+
+	inner := NewStaticProvider(map[string]float64{"USD/JPY": 160})
+	p, mr := newTestCachingProvider(t, inner)
+
+	r, err := p.Rate(context.Background(), "USD", "JPY")
+	require.NoError(t, err)
+	assert.Equal(t, SourceStatic, r.Source)
+	assert.Equal(t, fallbackCacheTTL, mr.TTL(testFXPrefix+":USD:JPY"))
+}
+
+func TestCachingProvider_Unavailable(t *testing.T) {
+	t.Parallel()
+
+	inner := NewStaticProvider(nil)
+	p, mr := newTestCachingProvider(t, inner)
+
+	_, err := p.Rate(context.Background(), "USD", "JPY")
+	assert.Error(t, err)
+	assert.False(t, mr.Exists(testFXPrefix+":USD:JPY"), "失敗はキャッシュしないこと")
+}
+
+func TestCachingProvider_NilClient(t *testing.T) {
+	t.Parallel()
+
+	inner := &countingProvider{rate: Rate{Value: 150, Source: SourceUpstream}}
+	p := NewCachingProvider(nil, inner, testFXPrefix, DefaultCacheTTL)
+
+	for range 2 {
+		_, err := p.Rate(context.Background(), "USD", "JPY")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, inner.calls)
+}
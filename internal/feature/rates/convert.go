@@ -0,0 +1,50 @@
+package rates
+
+import "math"
+
+// 換算計算と丸めは I/O を持たない純粋関数として実装します。
+
+// minorUnits は ISO 4217 の補助単位の桁数です。未登録の通貨は defaultMinorUnits を使います。
+var minorUnits = map[string]int{
+	"JPY": 0,
+	"KRW": 0,
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+}
+
+// defaultMinorUnits は minorUnits に未登録の通貨の桁数です（大半の通貨は 2 桁）。
+const defaultMinorUnits = 2
+
+// MinorUnits は通貨の補助単位の桁数（丸めに使う小数点以下の桁数）を返します。
+func MinorUnits(currency string) int {
+	if d, ok := minorUnits[currency]; ok {
+		return d
+	}
+	return defaultMinorUnits
+}
+
+// Round は v を通貨の補助単位の桁数に丸めます（0.5 は 0 から遠い方へ丸める）。
+func Round(v float64, currency string) float64 {
+	p := math.Pow10(MinorUnits(currency))
+	return math.Round(v*p) / p
+}
+
+// Convert は Base 建ての amount を r で Quote 建てに換算し、Quote の桁数に丸めます。
+func Convert(amount float64, r Rate) float64 {
+	return Round(amount*r.Value, r.Quote)
+}
+
+// Invert は r の逆向きのレート（Quote/Base）を返します。Value が 0 の場合は ok=false を返します。
+// 逆レートは丸めず、換算時の Convert でのみ丸めます（丸め誤差の累積を避けるため）。
+func Invert(r Rate) (Rate, bool) {
+	if r.Value == 0 {
+		return Rate{}, false
+	}
+	return Rate{Base: r.Quote, Quote: r.Base, Value: 1 / r.Value, AsOf: r.AsOf, Source: r.Source}, true
+}
+
+// Identity は同一通貨間のレート（1）を返します。
+func Identity(currency string) Rate {
+	return Rate{Base: currency, Quote: currency, Value: 1, Source: SourceIdentity}
+}
@@ -0,0 +1,59 @@
+package rates_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/rates"
+)
+
+func TestConvert(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		amount float64
+		rate   rates.Rate
+		want   float64
+	}{
+		{name: "USD→JPY は円未満を丸める（28544.495）", amount: 189.98, rate: rates.Rate{Base: "USD", Quote: "JPY", Value: 150.25}, want: 28544},
+		{name: "0.5 は 0 から遠い方へ", amount: 1, rate: rates.Rate{Base: "USD", Quote: "JPY", Value: 150.5}, want: 151},
+		{name: "JPY→USD は小数点以下 2 桁", amount: 2850, rate: rates.Rate{Base: "JPY", Quote: "USD", Value: 1 / 150.25}, want: 18.97},
+		{name: "未登録の通貨は 2 桁", amount: 10, rate: rates.Rate{Base: "USD", Quote: "CHF", Value: 0.88888}, want: 8.89},
+		{name: "負の値も対称に丸める", amount: -1, rate: rates.Rate{Base: "USD", Quote: "JPY", Value: 150.5}, want: -151},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.InDelta(t, tt.want, rates.Convert(tt.amount, tt.rate), 1e-9)
+		})
+	}
+}
+
+func TestInvert(t *testing.T) {
+	t.Parallel()
+
+	inv, ok := rates.Invert(rates.Rate{Base: "USD", Quote: "JPY", Value: 160, Source: rates.SourceStatic})
+	assert.True(t, ok)
+	assert.Equal(t, "JPY", inv.Base)
+	assert.Equal(t, "USD", inv.Quote)
+	assert.InDelta(t, 0.00625, inv.Value, 1e-12)
+	assert.Equal(t, rates.SourceStatic, inv.Source)
+
+	_, ok = rates.Invert(rates.Rate{Base: "USD", Quote: "JPY"})
+	assert.False(t, ok, "0 のレートは逆数を取れない")
+}
+
+func TestNormalizeCurrency(t *testing.T) {
+	t.Parallel()
+
+	got, err := rates.NormalizeCurrency(" jpy ")
+	assert.NoError(t, err)
+	assert.Equal(t, "JPY", got)
+
+	for _, in := range []string{"", "JP", "JPYY", "J1Y"} {
+		_, err := rates.NormalizeCurrency(in)
+		assert.Error(t, err, in)
+	}
+}
@@ -0,0 +1,58 @@
+// Package rates は価格の通貨換算に使う為替レートの取得・キャッシュ・換算計算を提供します。
+package rates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// レートの取得元です。
+const (
+	SourceUpstream = "twelvedata" // 外部API（Twelve Data）
+	SourceStatic   = "static"     // 設定値（FX_STATIC_RATES）
+	SourceIdentity = "identity"   // 同一通貨（レート 1）
+)
+
+// ErrRateUnavailable はどの取得元からもレートを得られないことを示します。
+var ErrRateUnavailable = errors.New("exchange rate unavailable")
+
+// currencyPattern は ISO 4217 の通貨コード（英大文字 3 文字）です。
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Rate は 1 Base あたりの Quote の量を表す為替レートです（例: USD/JPY=150.25）。
+type Rate struct {
+	Base   string    // 換算元通貨（例: "USD"）
+	Quote  string    // 換算先通貨（例: "JPY"）
+	Value  float64   // 1 Base あたりの Quote
+	AsOf   time.Time // レートの時刻（設定値の場合はゼロ値）
+	Source string    // 取得元（SourceUpstream / SourceStatic / SourceIdentity）
+}
+
+// Pair は "BASE/QUOTE" 形式の通貨ペア名を返します。
+func (r Rate) Pair() string {
+	return Pair(r.Base, r.Quote)
+}
+
+// Pair は "BASE/QUOTE" 形式の通貨ペア名を返します（Twelve Data の symbol 表記と同じ）。
+func Pair(base, quote string) string {
+	return base + "/" + quote
+}
+
+// Provider は為替レートの取得元を抽象化します。
+type Provider interface {
+	// Rate は 1 base あたりの quote のレートを返します。取得できない場合は ErrRateUnavailable を含むエラーを返します。
+	Rate(ctx context.Context, base, quote string) (Rate, error)
+}
+
+// NormalizeCurrency は通貨コードを大文字に正規化し、ISO 4217 の形式（英字 3 文字）かを検証します。
+func NormalizeCurrency(s string) (string, error) {
+	c := strings.ToUpper(strings.TrimSpace(s))
+	if !currencyPattern.MatchString(c) {
+		return "", fmt.Errorf("invalid currency %q: want ISO 4217 code (e.g. JPY)", s)
+	}
+	return c, nil
+}
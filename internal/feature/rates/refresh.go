@@ -0,0 +1,100 @@
+package rates
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// FreshnessInterval はデータ鮮度マーカーに為替レートを記録する際の interval 値です。
+// market には通貨ペア（例: "USD/JPY"）を記録し、ローソク足の (interval, market) マーカーと並べて鮮度を確認できるようにします。
+const FreshnessInterval = "fx"
+
+// FreshnessWriter はデータ鮮度マーカーの書き込みを抽象化します。
+// candles.FreshnessRepository が実装します（rates は candles に依存しないため利用者側で定義）。
+type FreshnessWriter interface {
+	RecordSuccess(ctx context.Context, interval, market string, at time.Time) error
+	RecordFailure(ctx context.Context, interval, market string, at time.Time, errMsg string) error
+}
+
+// RateStore は取得したレートの保存先です。CachingProvider が実装します。
+type RateStore interface {
+	Store(ctx context.Context, r Rate)
+}
+
+// RateLimiter は外部APIの呼び出し間隔を制御します。ローソク足の ingest と同じリミッターを共有し、
+// 同じバッチ内でのAPIクレジットの超過を防ぎます。
+type RateLimiter interface {
+	WaitIfNeeded(ctx context.Context) error
+}
+
+// RefreshResult は Refresh の集計結果です。
+type RefreshResult struct {
+	Total     int
+	Succeeded int
+	Failed    int
+}
+
+// Refresher は ingest バッチから呼び出し、対象通貨ペアのレートを外部APIから取得してキャッシュを更新します。
+// 各ペアの成否はデータ鮮度マーカーに記録するため、レートが古くなっていることを確認できます。
+type Refresher struct {
+	upstream  Provider
+	store     RateStore
+	freshness FreshnessWriter
+	limiter   RateLimiter
+	now       func() time.Time
+}
+
+// NewRefresher は Refresher を生成します。upstream には設定値へのフォールバックを含まない Provider を渡します
+// （フォールバックで成功扱いにすると、外部APIの障害が鮮度マーカーに現れないため）。
+func NewRefresher(upstream Provider, store RateStore, freshness FreshnessWriter, limiter RateLimiter) *Refresher {
+	return &Refresher{upstream: upstream, store: store, freshness: freshness, limiter: limiter, now: time.Now}
+}
+
+// Refresh は currencies のすべての組み合わせ（順序付き）のレートを取得してキャッシュに保存します。
+// 失敗したペアがあっても残りのペアの取得を続け、失敗件数を含む結果を返します。
+func (r *Refresher) Refresh(ctx context.Context, currencies []string) (RefreshResult, error) {
+	result := RefreshResult{Total: len(currencies) * (len(currencies) - 1)}
+	var firstErr error
+	for _, base := range currencies {
+		for _, quote := range currencies {
+			if base == quote {
+				continue
+			}
+			if err := r.limiter.WaitIfNeeded(ctx); err != nil {
+				// ctx の終了（タイムアウト）以外では失敗しないため、残りのペアは失敗として打ち切る
+				result.Failed = result.Total - result.Succeeded
+				return result, fmt.Errorf("rate limiter: %w", err)
+			}
+			if err := r.refreshPair(ctx, base, quote); err != nil {
+				result.Failed++
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			result.Succeeded++
+		}
+	}
+	if firstErr != nil {
+		return result, fmt.Errorf("%d of %d exchange rates failed: %w", result.Failed, result.Total, firstErr)
+	}
+	return result, nil
+}
+
+// refreshPair は 1 ペアを取得・保存し、鮮度マーカーに成否を記録します。
+func (r *Refresher) refreshPair(ctx context.Context, base, quote string) error {
+	pair := Pair(base, quote)
+	rate, err := r.upstream.Rate(ctx, base, quote)
+	if err != nil {
+		if ferr := r.freshness.RecordFailure(ctx, FreshnessInterval, pair, r.now(), err.Error()); ferr != nil {
+			return fmt.Errorf("%w (recording freshness: %v)", err, ferr)
+		}
+		return err
+	}
+	r.store.Store(ctx, rate)
+	if err := r.freshness.RecordSuccess(ctx, FreshnessInterval, pair, r.now()); err != nil {
+		return fmt.Errorf("record freshness %s: %w", pair, err)
+	}
+	return nil
+}
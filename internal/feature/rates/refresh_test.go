@@ -0,0 +1,105 @@
+package rates
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingFreshness は鮮度マーカーの書き込みを記録する FreshnessWriter です。
+type recordingFreshness struct {
+	success map[string]time.Time // market → at
+	failure map[string]string    // market → errMsg
+}
+
+func newRecordingFreshness() *recordingFreshness {
+	return &recordingFreshness{success: map[string]time.Time{}, failure: map[string]string{}}
+}
+
+func (f *recordingFreshness) RecordSuccess(_ context.Context, interval, market string, at time.Time) error {
+	if interval != FreshnessInterval {
+		return errors.New("unexpected interval " + interval)
+	}
+	f.success[market] = at
+	return nil
+}
+
+func (f *recordingFreshness) RecordFailure(_ context.Context, interval, market string, _ time.Time, errMsg string) error {
+	if interval != FreshnessInterval {
+		return errors.New("unexpected interval " + interval)
+	}
+	f.failure[market] = errMsg
+	return nil
+}
+
+// memoryStore は保存されたレートを保持する RateStore です。
+type memoryStore map[string]Rate
+
+func (m memoryStore) Store(_ context.Context, r Rate) { m[r.Pair()] = r }
+
+// noWait は待機しない RateLimiter です。
+type noWait struct{}
+
+func (noWait) WaitIfNeeded(ctx context.Context) error { return ctx.Err() }
+
+// pairProvider はペアごとに成否を切り替えられる Provider です。
+type pairProvider map[string]error
+
+func (p pairProvider) Rate(_ context.Context, base, quote string) (Rate, error) {
+	if err := p[Pair(base, quote)]; err != nil {
+		return Rate{}, err
+	}
+	return Rate{Base: base, Quote: quote, Value: 2, Source: SourceUpstream}, nil
+}
+
+func TestRefresher_RecordsFreshnessPerPair(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 1, 21, 0, 0, 0, time.UTC)
+	upstream := pairProvider{"EUR/JPY": errors.New("symbol not found")}
+	store := memoryStore{}
+	freshness := newRecordingFreshness()
+	r := NewRefresher(upstream, store, freshness, noWait{})
+	r.now = func() time.Time { return now }
+
+	result, err := r.Refresh(context.Background(), []string{"USD", "JPY", "EUR"})
+
+	require.Error(t, err, "失敗したペアがあればエラーを返すこと")
+	assert.Equal(t, RefreshResult{Total: 6, Succeeded: 5, Failed: 1}, result)
+	assert.Len(t, store, 5)
+	assert.NotContains(t, store, "EUR/JPY")
+	assert.Equal(t, now, freshness.success["USD/JPY"])
+	assert.Contains(t, freshness.failure["EUR/JPY"], "symbol not found")
+	assert.NotContains(t, freshness.success, "EUR/JPY")
+}
+
+func TestRefresher_AllSucceeded(t *testing.T) {
+	t.Parallel()
+
+	freshness := newRecordingFreshness()
+	r := NewRefresher(pairProvider{}, memoryStore{}, freshness, noWait{})
+
+	result, err := r.Refresh(context.Background(), []string{"USD", "JPY"})
+
+	require.NoError(t, err)
+	assert.Equal(t, RefreshResult{Total: 2, Succeeded: 2}, result)
+	assert.Len(t, freshness.success, 2)
+	assert.Empty(t, freshness.failure)
+}
+
+func TestRefresher_StopsWhenContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r := NewRefresher(pairProvider{}, memoryStore{}, newRecordingFreshness(), noWait{})
+
+	result, err := r.Refresh(ctx, []string{"USD", "JPY", "EUR"})
+
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, RefreshResult{Total: 6, Failed: 6}, result)
+}
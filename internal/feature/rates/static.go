@@ -0,0 +1,70 @@
+package rates
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StaticProvider は設定値の固定レートを返す Provider です。外部APIが使えない場合のフォールバックに使います。
+// 逆向きのペア（例: USD/JPY 設定時の JPY/USD）は逆数で応答します。
+type StaticProvider struct {
+	rates map[string]float64 // "BASE/QUOTE" → レート
+}
+
+var _ Provider = (*StaticProvider)(nil)
+
+// NewStaticProvider は "BASE/QUOTE" → レートの対応から StaticProvider を生成します。
+func NewStaticProvider(rates map[string]float64) *StaticProvider {
+	return &StaticProvider{rates: rates}
+}
+
+// Rate は設定値のレートを返します。設定がない場合は ErrRateUnavailable を返します。
+func (p *StaticProvider) Rate(_ context.Context, base, quote string) (Rate, error) {
+	if base == quote {
+		return Identity(base), nil
+	}
+	if v, ok := p.rates[Pair(base, quote)]; ok {
+		return Rate{Base: base, Quote: quote, Value: v, Source: SourceStatic}, nil
+	}
+	if v, ok := p.rates[Pair(quote, base)]; ok {
+		if inv, ok := Invert(Rate{Base: quote, Quote: base, Value: v, Source: SourceStatic}); ok {
+			return inv, nil
+		}
+	}
+	return Rate{}, fmt.Errorf("static rate %s: %w", Pair(base, quote), ErrRateUnavailable)
+}
+
+// ParseStaticRates は "USD/JPY=150.25,EUR/USD=1.08" 形式の設定を解釈します。空文字は空の対応を返します。
+func ParseStaticRates(s string) (map[string]float64, error) {
+	out := map[string]float64{}
+	for item := range strings.SplitSeq(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		pair, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid static rate %q: want BASE/QUOTE=RATE", item)
+		}
+		base, quote, ok := strings.Cut(strings.TrimSpace(pair), "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid static rate %q: want BASE/QUOTE=RATE", item)
+		}
+		b, err := NormalizeCurrency(base)
+		if err != nil {
+			return nil, fmt.Errorf("invalid static rate %q: %w", item, err)
+		}
+		q, err := NormalizeCurrency(quote)
+		if err != nil {
+			return nil, fmt.Errorf("invalid static rate %q: %w", item, err)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("invalid static rate %q: rate must be a positive number", item)
+		}
+		out[Pair(b, q)] = v
+	}
+	return out, nil
+}
@@ -0,0 +1,52 @@
+package rates_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/rates"
+)
+
+func TestParseStaticRates(t *testing.T) {
+	t.Parallel()
+
+	got, err := rates.ParseStaticRates(" usd/jpy=150.25, EUR/USD=1.08 ,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"USD/JPY": 150.25, "EUR/USD": 1.08}, got)
+
+	got, err = rates.ParseStaticRates("")
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	for _, in := range []string{"USD/JPY", "USDJPY=150", "USD/JPY=abc", "USD/JPY=0", "US/JPY=1"} {
+		_, err := rates.ParseStaticRates(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestStaticProvider_Rate(t *testing.T) {
+	t.Parallel()
+
+	p := rates.NewStaticProvider(map[string]float64{"USD/JPY": 160})
+	ctx := context.Background()
+
+	r, err := p.Rate(ctx, "USD", "JPY")
+	require.NoError(t, err)
+	assert.Equal(t, 160.0, r.Value)
+	assert.Equal(t, rates.SourceStatic, r.Source)
+
+	r, err = p.Rate(ctx, "JPY", "USD")
+	require.NoError(t, err)
+	assert.InDelta(t, 1.0/160, r.Value, 1e-12, "逆向きのペアは逆数で応答する")
+
+	r, err = p.Rate(ctx, "JPY", "JPY")
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, r.Value)
+
+	_, err = p.Rate(ctx, "EUR", "JPY")
+	assert.True(t, errors.Is(err, rates.ErrRateUnavailable))
+}
@@ -0,0 +1,39 @@
+package rates
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ExchangeRateFetcher は外部APIから為替レートを取得するインターフェースです。
+// Twelve Data クライアント（twelvedata.TwelveDataMarket）が実装します。
+type ExchangeRateFetcher interface {
+	// GetExchangeRate は 1 base あたりの quote のレートと、その時刻を返します。
+	GetExchangeRate(ctx context.Context, base, quote string) (float64, time.Time, error)
+}
+
+// upstreamProvider は ExchangeRateFetcher を Provider に適合させます。
+type upstreamProvider struct {
+	fetcher ExchangeRateFetcher
+}
+
+// NewUpstreamProvider は外部APIからレートを取得する Provider を生成します。
+func NewUpstreamProvider(fetcher ExchangeRateFetcher) Provider {
+	return &upstreamProvider{fetcher: fetcher}
+}
+
+// Rate は外部APIからレートを取得します。
+func (p *upstreamProvider) Rate(ctx context.Context, base, quote string) (Rate, error) {
+	if base == quote {
+		return Identity(base), nil
+	}
+	v, at, err := p.fetcher.GetExchangeRate(ctx, base, quote)
+	if err != nil {
+		return Rate{}, fmt.Errorf("fetch rate %s: %w: %w", Pair(base, quote), ErrRateUnavailable, err)
+	}
+	if v <= 0 {
+		return Rate{}, fmt.Errorf("fetch rate %s: non-positive rate %v: %w", Pair(base, quote), v, ErrRateUnavailable)
+	}
+	return Rate{Base: base, Quote: quote, Value: v, AsOf: at.UTC(), Source: SourceUpstream}, nil
+}
@@ -0,0 +1,14 @@
+package realtime
+
+import "github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
+
+var (
+	// ErrInvalidMessage はクライアントのメッセージが JSON として不正、または必須項目の欠落・未知の action の場合のエラーです。
+	ErrInvalidMessage = apperr.New(apperr.KindInvalid, "invalid_message", "invalid message")
+
+	// ErrTooManyConnections はユーザーごとの同時接続数の上限に達している場合のエラーです。
+	ErrTooManyConnections = apperr.New(apperr.KindConflict, "too_many_connections", "too many connections")
+
+	// ErrTooManySubscriptions は 1 接続あたりの購読数の上限に達している場合のエラーです。
+	ErrTooManySubscriptions = apperr.New(apperr.KindConflict, "too_many_subscriptions", "too many subscriptions")
+)
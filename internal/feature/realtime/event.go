@@ -0,0 +1,102 @@
+// Package realtime は WebSocket でクライアントへ配信するリアルタイムイベント（ローソク足の更新・アラートの発火と閾値の書き換え）の
+// 購読管理とプロセス間の中継を提供します。
+//
+// ローソク足の取り込みとアラートの評価は batch で行われるため、batch が RedisPublisher で Redis Pub/Sub に
+// 発行したイベントを API の RedisSubscriber が受け取って Hub に渡し、Hub が購読中の接続へ振り分けます。
+// 同一プロセス内で発行する場合は Hub.Publish を直接呼び出せます。
+package realtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// チャネル（購読の種類）です。
+const (
+	// ChannelCandles は銘柄・時間間隔ごとのローソク足の更新です。subscribe で購読します。
+	ChannelCandles = "candles"
+	// ChannelAlerts は接続したユーザー自身のアラートの発火・閾値の書き換えです。購読は不要で、接続すると自動的に届きます。
+	ChannelAlerts = "alerts"
+)
+
+// イベントの種類です。
+const (
+	EventCandleUpdated  = "candle.updated"
+	EventAlertTriggered = "alert.triggered"
+	EventAlertAdjusted  = "alert.adjusted"
+)
+
+// Event はプロセス間で中継するイベントです（Redis Pub/Sub のメッセージは Event の JSON）。
+type Event struct {
+	Type     string          `json:"type"`
+	Symbol   string          `json:"symbol"`
+	Interval string          `json:"interval"`
+	UserID   int64           `json:"user_id,omitempty"` // alert.triggered・alert.adjusted の宛先。クライアントには送らない
+	Data     json.RawMessage `json:"data"`
+}
+
+// Topic はローソク足の購読単位です。
+type Topic struct {
+	Symbol   string
+	Interval string
+}
+
+// CandleData は candle.updated の data です。
+type CandleData struct {
+	Time   time.Time `json:"time"`
+	Open   float64   `json:"open"`
+	High   float64   `json:"high"`
+	Low    float64   `json:"low"`
+	Close  float64   `json:"close"`
+	Volume int64     `json:"volume"`
+}
+
+// AlertData は alert.triggered の data です。
+type AlertData struct {
+	AlertID     int64     `json:"alert_id"`
+	Direction   string    `json:"direction"`
+	Threshold   float64   `json:"threshold"`
+	Close       float64   `json:"close"`
+	BarTime     time.Time `json:"bar_time"`
+	TriggeredAt time.Time `json:"triggered_at"`
+}
+
+// AlertAdjustedData は alert.adjusted（株式分割・併合によるアラートの閾値の書き換え）の data です。
+type AlertAdjustedData struct {
+	AlertID           int64     `json:"alert_id"`
+	Direction         string    `json:"direction"`
+	Threshold         float64   `json:"threshold"`
+	PreviousThreshold float64   `json:"previous_threshold"`
+	OriginalThreshold float64   `json:"original_threshold"`
+	AdjustmentID      int64     `json:"adjustment_id"`
+	Factor            float64   `json:"factor"`
+	EffectiveDate     time.Time `json:"effective_date"`
+}
+
+// NewCandleUpdated は銘柄・時間間隔の最新の足を知らせる candle.updated イベントを生成します。
+func NewCandleUpdated(symbol, interval string, c CandleData) (Event, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return Event{}, fmt.Errorf("marshal candle: %w", err)
+	}
+	return Event{Type: EventCandleUpdated, Symbol: symbol, Interval: interval, Data: data}, nil
+}
+
+// NewAlertTriggered はユーザー userID のアラートの発火を知らせる alert.triggered イベントを生成します。
+func NewAlertTriggered(userID int64, symbol, interval string, a AlertData) (Event, error) {
+	data, err := json.Marshal(a)
+	if err != nil {
+		return Event{}, fmt.Errorf("marshal alert: %w", err)
+	}
+	return Event{Type: EventAlertTriggered, Symbol: symbol, Interval: interval, UserID: userID, Data: data}, nil
+}
+
+// NewAlertAdjusted はユーザー userID のアラートの閾値を分割の調整で書き換えたことを知らせる alert.adjusted イベントを生成します。
+func NewAlertAdjusted(userID int64, symbol, interval string, a AlertAdjustedData) (Event, error) {
+	data, err := json.Marshal(a)
+	if err != nil {
+		return Event{}, fmt.Errorf("marshal alert adjustment: %w", err)
+	}
+	return Event{Type: EventAlertAdjusted, Symbol: symbol, Interval: interval, UserID: userID, Data: data}, nil
+}
@@ -0,0 +1,259 @@
+package realtime
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// Hub の上限の既定値です。
+const (
+	DefaultMaxConnsPerUser  = 5  // ユーザーごとの同時接続数
+	DefaultMaxSubscriptions = 50 // 1 接続あたりの購読数
+	DefaultQueueSize        = 64 // 1 接続あたりの送信待ちメッセージ数
+)
+
+// Config は Hub の上限です。ゼロ値の項目は既定値を使います。
+type Config struct {
+	MaxConnsPerUser  int
+	MaxSubscriptions int
+	QueueSize        int
+}
+
+func (c Config) withDefaults() Config {
+	if c.MaxConnsPerUser <= 0 {
+		c.MaxConnsPerUser = DefaultMaxConnsPerUser
+	}
+	if c.MaxSubscriptions <= 0 {
+		c.MaxSubscriptions = DefaultMaxSubscriptions
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = DefaultQueueSize
+	}
+	return c
+}
+
+// Hub は接続とローソク足の購読を管理し、イベントを宛先の接続の送信待ちに振り分けます。並行呼び出しに対して安全です。
+//
+// Publish は接続ごとの送信待ち（Conn）に積むだけで、ソケットへの書き込みは接続ごとの書き込みループが行います。
+// 遅いクライアントが他の接続や発行元を待たせないよう、送信待ちが上限に達した接続では古いメッセージから破棄します。
+type Hub struct {
+	cfg Config
+
+	mu     sync.Mutex
+	users  map[int64]map[*Conn]struct{}
+	topics map[Topic]map[*Conn]struct{}
+}
+
+// NewHub は空の Hub を生成します。
+func NewHub(cfg Config) *Hub {
+	return &Hub{
+		cfg:    cfg.withDefaults(),
+		users:  map[int64]map[*Conn]struct{}{},
+		topics: map[Topic]map[*Conn]struct{}{},
+	}
+}
+
+// Connect はユーザー userID の接続を登録します。同時接続数が上限に達している場合は ErrTooManyConnections です。
+// 接続の終了時には必ず Disconnect を呼び出します。
+func (h *Hub) Connect(userID int64) (*Conn, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.users[userID]) >= h.cfg.MaxConnsPerUser {
+		return nil, ErrTooManyConnections
+	}
+	c := newConn(userID, h.cfg.QueueSize)
+	if h.users[userID] == nil {
+		h.users[userID] = map[*Conn]struct{}{}
+	}
+	h.users[userID][c] = struct{}{}
+	return c, nil
+}
+
+// Disconnect は接続とその全ての購読を登録解除します。冪等です。
+func (h *Hub) Disconnect(c *Conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for t := range c.topics {
+		h.removeTopic(c, t)
+	}
+	delete(h.users[c.userID], c)
+	if len(h.users[c.userID]) == 0 {
+		delete(h.users, c.userID)
+	}
+}
+
+// Subscribe は接続 c に銘柄・時間間隔 t のローソク足を購読させます。購読済みの場合は何もしません。
+// 購読数が上限に達している場合は ErrTooManySubscriptions です。
+func (h *Hub) Subscribe(c *Conn, t Topic) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := c.topics[t]; ok {
+		return nil
+	}
+	if len(c.topics) >= h.cfg.MaxSubscriptions {
+		return ErrTooManySubscriptions
+	}
+	c.topics[t] = struct{}{}
+	if h.topics[t] == nil {
+		h.topics[t] = map[*Conn]struct{}{}
+	}
+	h.topics[t][c] = struct{}{}
+	return nil
+}
+
+// Unsubscribe は接続 c の t の購読を解除し、購読していたかを返します。
+func (h *Hub) Unsubscribe(c *Conn, t Topic) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := c.topics[t]; !ok {
+		return false
+	}
+	h.removeTopic(c, t)
+	return true
+}
+
+// UnsubscribeAll は接続 c の全ての購読を解除し、解除した数を返します。
+func (h *Hub) UnsubscribeAll(c *Conn) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	n := len(c.topics)
+	for t := range c.topics {
+		h.removeTopic(c, t)
+	}
+	return n
+}
+
+// removeTopic は c の t の購読を削除します。h.mu を保持して呼び出します。
+func (h *Hub) removeTopic(c *Conn, t Topic) {
+	delete(c.topics, t)
+	delete(h.topics[t], c)
+	if len(h.topics[t]) == 0 {
+		delete(h.topics, t)
+	}
+}
+
+// Publish は ev を宛先の接続の送信待ちに積み、積んだ接続数を返します。
+// candle.updated は銘柄・時間間隔を購読中の接続に、alert.triggered・alert.adjusted は宛先ユーザーの全ての接続に届けます。
+// 未知の種類のイベントは破棄します。
+func (h *Hub) Publish(ev Event) int {
+	var channel string
+	switch ev.Type {
+	case EventCandleUpdated:
+		channel = ChannelCandles
+	case EventAlertTriggered, EventAlertAdjusted:
+		channel = ChannelAlerts
+	default:
+		slog.Warn("realtime: dropping event of unknown type", "type", ev.Type)
+		return 0
+	}
+	frame, err := json.Marshal(ServerMessage{
+		Type:     MessageEvent,
+		Channel:  channel,
+		Event:    ev.Type,
+		Symbol:   ev.Symbol,
+		Interval: ev.Interval,
+		Data:     ev.Data,
+	})
+	if err != nil {
+		slog.Warn("realtime: dropping unencodable event", "type", ev.Type, "error", err)
+		return 0
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	targets := h.users[ev.UserID]
+	if channel == ChannelCandles {
+		targets = h.topics[Topic{Symbol: ev.Symbol, Interval: ev.Interval}]
+	}
+	for c := range targets {
+		c.Send(frame)
+	}
+	return len(targets)
+}
+
+// Conns は登録中の接続数を返します。
+func (h *Hub) Conns() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	n := 0
+	for _, cs := range h.users {
+		n += len(cs)
+	}
+	return n
+}
+
+// Conn は 1 つの WebSocket 接続の送信待ちと購読です。
+//
+// 送信待ちは上限付きで、満杯のときに Send すると最も古いメッセージを破棄します（drop-oldest）。
+// 破棄した数は次の Drain の先頭に events_dropped の notice として入れ、クライアントに欠落を知らせます。
+type Conn struct {
+	userID int64
+	ready  chan struct{} // 送信待ちが空でなくなったことの通知（容量 1）
+
+	mu      sync.Mutex
+	queue   [][]byte
+	size    int
+	dropped int // 未通知の破棄数
+
+	totalDropped atomic.Uint64
+	topics       map[Topic]struct{} // Hub.mu で保護する
+}
+
+func newConn(userID int64, size int) *Conn {
+	return &Conn{
+		userID: userID,
+		ready:  make(chan struct{}, 1),
+		size:   size,
+		topics: map[Topic]struct{}{},
+	}
+}
+
+// UserID は接続したユーザーの ID を返します。
+func (c *Conn) UserID() int64 {
+	return c.userID
+}
+
+// Send はメッセージ（エンコード済みの JSON）を送信待ちに積みます。呼び出し元をブロックしません。
+func (c *Conn) Send(msg []byte) {
+	c.mu.Lock()
+	if len(c.queue) >= c.size {
+		c.queue[0] = nil
+		c.queue = c.queue[1:]
+		c.dropped++
+		c.totalDropped.Add(1)
+	}
+	c.queue = append(c.queue, msg)
+	c.mu.Unlock()
+
+	select {
+	case c.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Ready は送信待ちにメッセージが積まれると受信可能になるチャネルを返します。受信後に Drain で取り出します。
+func (c *Conn) Ready() <-chan struct{} {
+	return c.ready
+}
+
+// Drain は送信待ちのメッセージを古い順に全て取り出します。
+// 前回の Drain 以降に破棄したメッセージがあれば、先頭に events_dropped の notice を加えます。
+func (c *Conn) Drain() [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := c.queue
+	c.queue = nil
+	if c.dropped > 0 {
+		notice, _ := json.Marshal(ServerMessage{Type: MessageNotice, Code: NoticeEventsDropped, Dropped: c.dropped})
+		out = append([][]byte{notice}, out...)
+		c.dropped = 0
+	}
+	return out
+}
+
+// Dropped はこの接続で破棄したメッセージの累計を返します（ログ用）。
+func (c *Conn) Dropped() uint64 {
+	return c.totalDropped.Load()
+}
@@ -0,0 +1,150 @@
+package realtime
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// drainMessages は送信待ちを取り出して ServerMessage に復号します。
+func drainMessages(t *testing.T, c *Conn) []ServerMessage {
+	t.Helper()
+	var out []ServerMessage
+	for _, b := range c.Drain() {
+		var m ServerMessage
+		require.NoError(t, json.Unmarshal(b, &m))
+		out = append(out, m)
+	}
+	return out
+}
+
+func candleEvent(t *testing.T, symbol, interval string, close float64) Event {
+	t.Helper()
+	ev, err := NewCandleUpdated(symbol, interval, CandleData{Time: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Close: close})
+	require.NoError(t, err)
+	return ev
+}
+
+func TestHub_PublishCandles(t *testing.T) {
+	t.Parallel()
+	h := NewHub(Config{})
+	a, err := h.Connect(1)
+	require.NoError(t, err)
+	b, err := h.Connect(2)
+	require.NoError(t, err)
+
+	require.NoError(t, h.Subscribe(a, Topic{Symbol: "AAPL", Interval: "1day"}))
+	require.NoError(t, h.Subscribe(a, Topic{Symbol: "AAPL", Interval: "1day"}), "購読済みは何もしない")
+	require.NoError(t, h.Subscribe(b, Topic{Symbol: "AAPL", Interval: "1week"}))
+
+	assert.Equal(t, 1, h.Publish(candleEvent(t, "AAPL", "1day", 101)))
+	assert.Equal(t, 0, h.Publish(candleEvent(t, "MSFT", "1day", 1)))
+
+	got := drainMessages(t, a)
+	require.Len(t, got, 1)
+	assert.Equal(t, MessageEvent, got[0].Type)
+	assert.Equal(t, ChannelCandles, got[0].Channel)
+	assert.Equal(t, EventCandleUpdated, got[0].Event)
+	assert.Equal(t, "AAPL", got[0].Symbol)
+	assert.Equal(t, "1day", got[0].Interval)
+	assert.JSONEq(t, `{"time":"2024-01-02T00:00:00Z","open":0,"high":0,"low":0,"close":101,"volume":0}`, string(got[0].Data))
+	assert.Empty(t, drainMessages(t, b), "時間間隔の異なる購読には届かない")
+
+	assert.True(t, h.Unsubscribe(a, Topic{Symbol: "AAPL", Interval: "1day"}))
+	assert.False(t, h.Unsubscribe(a, Topic{Symbol: "AAPL", Interval: "1day"}))
+	assert.Equal(t, 0, h.Publish(candleEvent(t, "AAPL", "1day", 102)))
+}
+
+func TestHub_PublishAlerts(t *testing.T) {
+	t.Parallel()
+	h := NewHub(Config{})
+	a1, _ := h.Connect(1)
+	a2, _ := h.Connect(1)
+	b, _ := h.Connect(2)
+
+	ev, err := NewAlertTriggered(1, "AAPL", "1day", AlertData{AlertID: 7, Direction: "above", Threshold: 100, Close: 101})
+	require.NoError(t, err)
+	assert.Equal(t, 2, h.Publish(ev), "宛先ユーザーの全ての接続に購読なしで届く")
+
+	for _, c := range []*Conn{a1, a2} {
+		got := drainMessages(t, c)
+		require.Len(t, got, 1)
+		assert.Equal(t, ChannelAlerts, got[0].Channel)
+		assert.Equal(t, EventAlertTriggered, got[0].Event)
+		assert.NotContains(t, string(got[0].Data), "user_id")
+	}
+	assert.Empty(t, drainMessages(t, b))
+
+	adj, err := NewAlertAdjusted(2, "AAPL", "1day", AlertAdjustedData{AlertID: 8, Threshold: 750, PreviousThreshold: 3000, Factor: 0.25})
+	require.NoError(t, err)
+	assert.Equal(t, 1, h.Publish(adj), "閾値の書き換えも宛先ユーザーにだけ届く")
+	got := drainMessages(t, b)
+	require.Len(t, got, 1)
+	assert.Equal(t, ChannelAlerts, got[0].Channel)
+	assert.Equal(t, EventAlertAdjusted, got[0].Event)
+	assert.Empty(t, drainMessages(t, a1))
+
+	assert.Equal(t, 0, h.Publish(Event{Type: "unknown", UserID: 1}), "未知の種類は破棄")
+}
+
+func TestHub_Limits(t *testing.T) {
+	t.Parallel()
+	h := NewHub(Config{MaxConnsPerUser: 2, MaxSubscriptions: 1})
+	a, err := h.Connect(1)
+	require.NoError(t, err)
+	_, err = h.Connect(1)
+	require.NoError(t, err)
+	_, err = h.Connect(1)
+	assert.ErrorIs(t, err, ErrTooManyConnections)
+	_, err = h.Connect(2)
+	assert.NoError(t, err, "上限はユーザーごと")
+
+	require.NoError(t, h.Subscribe(a, Topic{Symbol: "AAPL", Interval: "1day"}))
+	assert.ErrorIs(t, h.Subscribe(a, Topic{Symbol: "MSFT", Interval: "1day"}), ErrTooManySubscriptions)
+
+	h.Disconnect(a)
+	h.Disconnect(a) // 冪等
+	assert.Equal(t, 2, h.Conns())
+	_, err = h.Connect(1)
+	assert.NoError(t, err, "切断すると枠が空く")
+	assert.Equal(t, 0, h.Publish(candleEvent(t, "AAPL", "1day", 1)), "切断した接続の購読は残らない")
+}
+
+func TestHub_UnsubscribeAll(t *testing.T) {
+	t.Parallel()
+	h := NewHub(Config{})
+	c, _ := h.Connect(1)
+	require.NoError(t, h.Subscribe(c, Topic{Symbol: "AAPL", Interval: "1day"}))
+	require.NoError(t, h.Subscribe(c, Topic{Symbol: "MSFT", Interval: "1day"}))
+
+	assert.Equal(t, 2, h.UnsubscribeAll(c))
+	assert.Equal(t, 0, h.Publish(candleEvent(t, "AAPL", "1day", 1)))
+	assert.Equal(t, 0, h.UnsubscribeAll(c))
+}
+
+// TestConn_DropOldest は送信待ちが満杯のとき古いメッセージから破棄し、次の Drain の先頭で破棄数を知らせることを検証します。
+func TestConn_DropOldest(t *testing.T) {
+	t.Parallel()
+	c := newConn(1, 3)
+	for i := range 5 {
+		c.Send([]byte{byte('0' + i)})
+	}
+	select {
+	case <-c.Ready():
+	default:
+		t.Fatal("Ready should be signaled after Send")
+	}
+
+	got := c.Drain()
+	require.Len(t, got, 4)
+	assert.JSONEq(t, `{"type":"notice","code":"events_dropped","dropped":2}`, string(got[0]))
+	assert.Equal(t, [][]byte{[]byte("2"), []byte("3"), []byte("4")}, got[1:])
+	assert.Equal(t, uint64(2), c.Dropped())
+
+	c.Send([]byte("5"))
+	assert.Equal(t, [][]byte{[]byte("5")}, c.Drain(), "通知済みの破棄は繰り返さない")
+	assert.Empty(t, c.Drain())
+}
@@ -0,0 +1,134 @@
+package realtime
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// クライアントから送るメッセージの action です。
+const (
+	// ActionAuth はアクセストークンによる認証です。クエリパラメータでトークンを渡さない場合、最初のメッセージで送ります。
+	ActionAuth = "auth"
+	// ActionSubscribe はローソク足の購読の開始です。
+	ActionSubscribe = "subscribe"
+	// ActionUnsubscribe は購読の解除です。channel を省略すると全ての購読を解除します。
+	ActionUnsubscribe = "unsubscribe"
+	// ActionPing はアプリケーションレベルの死活確認です（pong を返す）。
+	ActionPing = "ping"
+)
+
+// サーバーから送るメッセージの type です。
+const (
+	MessageReady        = "ready"        // 認証が完了し、購読を受け付けられる
+	MessageSubscribed   = "subscribed"   // subscribe の完了
+	MessageUnsubscribed = "unsubscribed" // unsubscribe の完了
+	MessageEvent        = "event"        // イベントの配信
+	MessageNotice       = "notice"       // 配信の欠落等の通知
+	MessageError        = "error"        // メッセージの処理の失敗（接続は維持する）
+	MessagePong         = "pong"         // ping への応答
+)
+
+// NoticeEventsDropped は送信待ちの上限を超えたため古いイベントを破棄したことを知らせる notice の code です。
+const NoticeEventsDropped = "events_dropped"
+
+const (
+	// MaxMessageSize はクライアントから受け付けるメッセージの最大バイト数です。
+	MaxMessageSize = 4096
+	// DefaultInterval は subscribe / unsubscribe で interval を省略した場合の時間間隔です。
+	DefaultInterval = "1day"
+
+	maxSymbolLength   = 32
+	maxIntervalLength = 16
+	maxIDLength       = 64
+)
+
+// ClientMessage はクライアントから送られるメッセージです。
+type ClientMessage struct {
+	Action   string `json:"action"`
+	ID       string `json:"id,omitempty"` // 任意。応答にそのまま返す（クライアントが要求と応答を対応付けるため）
+	Token    string `json:"token,omitempty"`
+	Channel  string `json:"channel,omitempty"`
+	Symbol   string `json:"symbol,omitempty"`
+	Interval string `json:"interval,omitempty"`
+}
+
+// Topic はローソク足の購読単位を返します。
+func (m ClientMessage) Topic() Topic {
+	return Topic{Symbol: m.Symbol, Interval: m.Interval}
+}
+
+// ServerMessage はサーバーから送るメッセージです。
+type ServerMessage struct {
+	Type     string          `json:"type"`
+	ID       string          `json:"id,omitempty"`
+	Channel  string          `json:"channel,omitempty"`
+	Event    string          `json:"event,omitempty"`
+	Symbol   string          `json:"symbol,omitempty"`
+	Interval string          `json:"interval,omitempty"`
+	Data     json.RawMessage `json:"data,omitempty"`
+	Code     string          `json:"code,omitempty"`    // error / notice の種類
+	Dropped  int             `json:"dropped,omitempty"` // events_dropped で破棄したイベント数
+}
+
+// ParseClientMessage は JSON のメッセージを解析・検証し、symbol・interval を正規化（空白の除去、interval の既定値）します。
+// 未知のフィールドや action、必須項目の欠落は ErrInvalidMessage です。
+func ParseClientMessage(data []byte) (ClientMessage, error) {
+	var m ClientMessage
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&m); err != nil {
+		return ClientMessage{}, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+	if dec.More() {
+		return ClientMessage{}, fmt.Errorf("%w: trailing data", ErrInvalidMessage)
+	}
+	if len(m.ID) > maxIDLength {
+		return ClientMessage{}, fmt.Errorf("%w: id too long", ErrInvalidMessage)
+	}
+	m.Symbol = strings.TrimSpace(m.Symbol)
+	m.Interval = strings.TrimSpace(m.Interval)
+
+	switch m.Action {
+	case ActionAuth:
+		if m.Token == "" {
+			return ClientMessage{}, fmt.Errorf("%w: token is required", ErrInvalidMessage)
+		}
+		return m, nil
+	case ActionPing:
+		return m, nil
+	case ActionSubscribe:
+		return m, validateTopic(&m)
+	case ActionUnsubscribe:
+		if m.Channel == "" && m.Symbol == "" && m.Interval == "" {
+			return m, nil // 全ての購読を解除
+		}
+		return m, validateTopic(&m)
+	case "":
+		return ClientMessage{}, fmt.Errorf("%w: action is required", ErrInvalidMessage)
+	default:
+		return ClientMessage{}, fmt.Errorf("%w: unknown action %q", ErrInvalidMessage, m.Action)
+	}
+}
+
+// validateTopic はローソク足の購読単位（channel・symbol・interval）を検証します。
+func validateTopic(m *ClientMessage) error {
+	switch m.Channel {
+	case ChannelCandles:
+	case ChannelAlerts:
+		return fmt.Errorf("%w: alerts are delivered without subscribing", ErrInvalidMessage)
+	default:
+		return fmt.Errorf("%w: unknown channel %q", ErrInvalidMessage, m.Channel)
+	}
+	if m.Symbol == "" || len(m.Symbol) > maxSymbolLength {
+		return fmt.Errorf("%w: symbol must be 1-%d characters", ErrInvalidMessage, maxSymbolLength)
+	}
+	if m.Interval == "" {
+		m.Interval = DefaultInterval
+	}
+	if len(m.Interval) > maxIntervalLength {
+		return fmt.Errorf("%w: interval too long", ErrInvalidMessage)
+	}
+	return nil
+}
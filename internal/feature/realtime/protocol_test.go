@@ -0,0 +1,69 @@
+package realtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseClientMessage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		data    string
+		want    ClientMessage
+		wantErr bool
+	}{
+		{
+			name: "auth",
+			data: `{"action":"auth","token":"t"}`,
+			want: ClientMessage{Action: ActionAuth, Token: "t"},
+		},
+		{
+			name: "subscribe は symbol の空白を除き interval を補う",
+			data: `{"action":"subscribe","id":"1","channel":"candles","symbol":" AAPL "}`,
+			want: ClientMessage{Action: ActionSubscribe, ID: "1", Channel: ChannelCandles, Symbol: "AAPL", Interval: DefaultInterval},
+		},
+		{
+			name: "subscribe の interval 指定",
+			data: `{"action":"subscribe","channel":"candles","symbol":"7203.T","interval":"1week"}`,
+			want: ClientMessage{Action: ActionSubscribe, Channel: ChannelCandles, Symbol: "7203.T", Interval: "1week"},
+		},
+		{
+			name: "channel なしの unsubscribe は全解除",
+			data: `{"action":"unsubscribe"}`,
+			want: ClientMessage{Action: ActionUnsubscribe},
+		},
+		{
+			name: "unsubscribe の購読単位",
+			data: `{"action":"unsubscribe","channel":"candles","symbol":"AAPL"}`,
+			want: ClientMessage{Action: ActionUnsubscribe, Channel: ChannelCandles, Symbol: "AAPL", Interval: DefaultInterval},
+		},
+		{name: "ping", data: `{"action":"ping","id":"p"}`, want: ClientMessage{Action: ActionPing, ID: "p"}},
+		{name: "JSON でない", data: `subscribe`, wantErr: true},
+		{name: "末尾に余分なデータ", data: `{"action":"ping"}{}`, wantErr: true},
+		{name: "未知のフィールド", data: `{"action":"ping","foo":1}`, wantErr: true},
+		{name: "action なし", data: `{}`, wantErr: true},
+		{name: "未知の action", data: `{"action":"publish"}`, wantErr: true},
+		{name: "token なしの auth", data: `{"action":"auth"}`, wantErr: true},
+		{name: "未知の channel", data: `{"action":"subscribe","channel":"quotes","symbol":"AAPL"}`, wantErr: true},
+		{name: "alerts は購読不要", data: `{"action":"subscribe","channel":"alerts"}`, wantErr: true},
+		{name: "symbol なし", data: `{"action":"subscribe","channel":"candles","symbol":"  "}`, wantErr: true},
+		{name: "symbol が長すぎる", data: `{"action":"subscribe","channel":"candles","symbol":"ABCDEFGHIJKLMNOPQRSTUVWXYZABCDEFG"}`, wantErr: true},
+		{name: "channel なしで symbol だけの unsubscribe", data: `{"action":"unsubscribe","symbol":"AAPL"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseClientMessage([]byte(tt.data))
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidMessage)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
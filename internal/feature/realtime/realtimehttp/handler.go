@@ -0,0 +1,304 @@
+// Package realtimehttp は GET /v1/ws（WebSocket）でリアルタイムイベントを配信します。
+//
+// プロトコル（メッセージはすべて JSON のテキストフレーム）:
+//   - 認証: ?access_token= で渡すか、最初のメッセージ {"action":"auth","token":"..."} で渡す。
+//     完了すると {"type":"ready"} を返す
+//   - 購読: {"action":"subscribe","channel":"candles","symbol":"AAPL","interval":"1day"} → {"type":"subscribed",...}
+//   - 解除: {"action":"unsubscribe", ...}（channel を省略すると全て解除） → {"type":"unsubscribed",...}
+//   - 配信: {"type":"event","channel":"candles","event":"candle.updated","symbol":...,"interval":...,"data":{...}}
+//     アラートの発火（channel "alerts"）は購読なしで接続したユーザーに届く
+//   - 欠落: 送信待ちが上限を超えると古いメッセージから破棄し、{"type":"notice","code":"events_dropped","dropped":n} で知らせる
+//
+// サーバーは定期的に WebSocket の ping を送り、pong が返らない接続を閉じます。
+// シャットダウン時はストリームの排出（stream.Registry）に従い、close コード 1001 で接続を閉じます。
+package realtimehttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/coder/websocket"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/realtime"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/stream"
+)
+
+// アプリケーション定義の close コード（4000〜4999）です。
+const (
+	// CloseUnauthorized は最初のメッセージでの認証に失敗した場合の close コードです。
+	CloseUnauthorized websocket.StatusCode = 4401
+	// CloseTooManyConnections はユーザーごとの同時接続数の上限に達した場合の close コードです。
+	CloseTooManyConnections websocket.StatusCode = 4429
+)
+
+const (
+	// DefaultPingInterval は WebSocket の ping を送る間隔です。
+	DefaultPingInterval = 30 * time.Second
+	// DefaultPongTimeout は ping に対する pong を待つ上限です。
+	DefaultPongTimeout = 10 * time.Second
+	// DefaultAuthTimeout は最初のメッセージでの認証を待つ上限です。
+	DefaultAuthTimeout = 10 * time.Second
+
+	writeTimeout = 10 * time.Second
+)
+
+// Authenticator はアクセストークンを検証してユーザーIDを返します（jwt.Authenticator が実装）。
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (int64, error)
+}
+
+// SymbolResolver は入力された銘柄コードを正規コードに解決します（candles.Usecase が実装）。
+// 未知・非アクティブの銘柄は candles.ErrSymbolNotFound 等のエラーを返します。
+type SymbolResolver interface {
+	ResolveSymbol(ctx context.Context, symbol string) (string, error)
+}
+
+// Handler は WebSocket の接続を受け付け、Hub に登録してイベントを配信します。
+type Handler struct {
+	hub      *realtime.Hub
+	auth     Authenticator
+	resolver SymbolResolver
+
+	originPatterns []string
+	pingInterval   time.Duration
+	pongTimeout    time.Duration
+	authTimeout    time.Duration
+}
+
+// NewHandler は Handler の新しいインスタンスを生成します。resolver が nil の場合は銘柄コードを解決せずに購読します。
+func NewHandler(hub *realtime.Hub, auth Authenticator, resolver SymbolResolver) *Handler {
+	return &Handler{
+		hub:          hub,
+		auth:         auth,
+		resolver:     resolver,
+		pingInterval: DefaultPingInterval,
+		pongTimeout:  DefaultPongTimeout,
+		authTimeout:  DefaultAuthTimeout,
+	}
+}
+
+// WithAllowedOrigins はブラウザからの接続を許可するオリジン（CORS_ORIGINS と同じ形式の URL）を設定します。
+// 未設定の場合は同一オリジンと Origin ヘッダーのない（ブラウザ以外の）クライアントのみ許可します。
+func (h *Handler) WithAllowedOrigins(origins []string) *Handler {
+	h.originPatterns = nil
+	for _, o := range origins {
+		if u, err := url.Parse(o); err == nil && u.Host != "" {
+			h.originPatterns = append(h.originPatterns, u.Host)
+		}
+	}
+	return h
+}
+
+// WithKeepalive は ping の間隔と pong を待つ上限を設定します（テスト用）。
+func (h *Handler) WithKeepalive(interval, timeout time.Duration) *Handler {
+	h.pingInterval = interval
+	h.pongTimeout = timeout
+	return h
+}
+
+// Serve は GET /v1/ws を WebSocket にアップグレードし、接続が閉じるまで購読の操作とイベントの配信を行います。
+// ?access_token= のトークンが不正な場合と同時接続数の上限に達している場合は、アップグレードせずに 401 / 429 を返します。
+// stream.Registry のミドルウェアの後段に置き、シャットダウン時の排出の対象にします。
+func (h *Handler) Serve(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var conn *realtime.Conn
+	if token := r.URL.Query().Get("access_token"); token != "" {
+		userID, err := h.auth.Authenticate(ctx, token)
+		if err != nil {
+			httpx.WriteJSON(w, http.StatusUnauthorized, api.ErrorResponse{Error: "invalid token"})
+			return
+		}
+		if conn, err = h.hub.Connect(userID); err != nil {
+			httpx.WriteJSON(w, http.StatusTooManyRequests, api.ErrorResponse{Error: "too_many_connections"})
+			return
+		}
+		defer h.hub.Disconnect(conn)
+	}
+
+	ws, err := websocket.Accept(w, r, &websocket.AcceptOptions{OriginPatterns: h.originPatterns})
+	if err != nil {
+		// Accept がレスポンス（400 / 403）を書き込み済み
+		slog.DebugContext(ctx, "websocket accept failed", "error", err)
+		return
+	}
+	defer func() { _ = ws.CloseNow() }()
+	ws.SetReadLimit(realtime.MaxMessageSize)
+
+	// 読み取りのコンテキストがキャンセルされると接続が即座に閉じられるため、シャットダウンでは読み取りを止めず、
+	// 書き込みループが close フレーム（1001）を送ってクライアントの応答を読み取りループで受け取る
+	readCtx := context.WithoutCancel(ctx)
+
+	if conn == nil {
+		if conn, err = h.authenticateFirstMessage(readCtx, ws); err != nil {
+			return
+		}
+		defer h.hub.Disconnect(conn)
+	}
+	sendJSON(conn, realtime.ServerMessage{Type: realtime.MessageReady})
+
+	writeCtx, stopWrite := context.WithCancel(ctx)
+	writerDone := make(chan struct{})
+	go func() {
+		defer close(writerDone)
+		err := h.writeLoop(writeCtx, ws, conn)
+		if stream.ShuttingDown(ctx) {
+			_ = ws.Close(websocket.StatusGoingAway, "server shutting down")
+			return
+		}
+		if err != nil && !errors.Is(err, context.Canceled) {
+			slog.DebugContext(ctx, "websocket write loop ended", "error", err, "user_id", conn.UserID())
+		}
+		_ = ws.CloseNow()
+	}()
+
+	h.readLoop(readCtx, ws, conn)
+	stopWrite()
+	<-writerDone
+	if n := conn.Dropped(); n > 0 {
+		slog.InfoContext(ctx, "websocket closed with dropped messages", "user_id", conn.UserID(), "dropped", n)
+	}
+}
+
+// authenticateFirstMessage は最初のメッセージ（action "auth"）でトークンを検証し、接続を Hub に登録します。
+// 失敗した場合は close コードを送って接続を閉じます。
+func (h *Handler) authenticateFirstMessage(ctx context.Context, ws *websocket.Conn) (*realtime.Conn, error) {
+	authCtx, cancel := context.WithTimeout(ctx, h.authTimeout)
+	defer cancel()
+	_, data, err := ws.Read(authCtx)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := realtime.ParseClientMessage(data)
+	if err == nil && msg.Action != realtime.ActionAuth {
+		err = errors.New("first message must be auth")
+	}
+	var userID int64
+	if err == nil {
+		userID, err = h.auth.Authenticate(ctx, msg.Token)
+	}
+	if err != nil {
+		_ = ws.Close(CloseUnauthorized, "unauthorized")
+		return nil, err
+	}
+	conn, err := h.hub.Connect(userID)
+	if err != nil {
+		_ = ws.Close(CloseTooManyConnections, "too_many_connections")
+		return nil, err
+	}
+	return conn, nil
+}
+
+// readLoop は接続が閉じるまでクライアントのメッセージを読み、購読の操作を行います。
+// 不正なメッセージには error を返し、接続は維持します。
+func (h *Handler) readLoop(ctx context.Context, ws *websocket.Conn, conn *realtime.Conn) {
+	for {
+		typ, data, err := ws.Read(ctx)
+		if err != nil {
+			return
+		}
+		if typ != websocket.MessageText {
+			sendError(ctx, conn, "", realtime.ErrInvalidMessage)
+			continue
+		}
+		msg, err := realtime.ParseClientMessage(data)
+		if err != nil {
+			sendError(ctx, conn, "", err)
+			continue
+		}
+		h.handle(ctx, conn, msg)
+	}
+}
+
+// handle は 1 つのメッセージを処理し、応答を送信待ちに積みます。
+func (h *Handler) handle(ctx context.Context, conn *realtime.Conn, msg realtime.ClientMessage) {
+	switch msg.Action {
+	case realtime.ActionPing:
+		sendJSON(conn, realtime.ServerMessage{Type: realtime.MessagePong, ID: msg.ID})
+	case realtime.ActionAuth:
+		sendError(ctx, conn, msg.ID, realtime.ErrInvalidMessage)
+	case realtime.ActionSubscribe:
+		if h.resolver != nil {
+			code, err := h.resolver.ResolveSymbol(ctx, msg.Symbol)
+			if err != nil {
+				sendError(ctx, conn, msg.ID, err)
+				return
+			}
+			msg.Symbol = code
+		}
+		if err := h.hub.Subscribe(conn, msg.Topic()); err != nil {
+			sendError(ctx, conn, msg.ID, err)
+			return
+		}
+		sendJSON(conn, realtime.ServerMessage{Type: realtime.MessageSubscribed, ID: msg.ID, Channel: msg.Channel, Symbol: msg.Symbol, Interval: msg.Interval})
+	case realtime.ActionUnsubscribe:
+		if msg.Channel == "" {
+			h.hub.UnsubscribeAll(conn)
+			sendJSON(conn, realtime.ServerMessage{Type: realtime.MessageUnsubscribed, ID: msg.ID})
+			return
+		}
+		// 購読時に解決した正規コードで登録しているため、解除も同じく解決する（解決できなければ入力のまま）
+		if h.resolver != nil {
+			if code, err := h.resolver.ResolveSymbol(ctx, msg.Symbol); err == nil {
+				msg.Symbol = code
+			}
+		}
+		h.hub.Unsubscribe(conn, msg.Topic())
+		sendJSON(conn, realtime.ServerMessage{Type: realtime.MessageUnsubscribed, ID: msg.ID, Channel: msg.Channel, Symbol: msg.Symbol, Interval: msg.Interval})
+	}
+}
+
+// writeLoop は送信待ちのメッセージの書き込みと ping の送信を、ctx の終了か書き込みの失敗まで続けます。
+// ソケットへの書き込みはこのループだけが行います。
+func (h *Handler) writeLoop(ctx context.Context, ws *websocket.Conn, conn *realtime.Conn) error {
+	ping := time.NewTicker(h.pingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-conn.Ready():
+			for _, msg := range conn.Drain() {
+				wctx, cancel := context.WithTimeout(ctx, writeTimeout)
+				err := ws.Write(wctx, websocket.MessageText, msg)
+				cancel()
+				if err != nil {
+					return err
+				}
+			}
+		case <-ping.C:
+			pctx, cancel := context.WithTimeout(ctx, h.pongTimeout)
+			err := ws.Ping(pctx)
+			cancel()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// sendJSON は応答を送信待ちに積みます。
+func sendJSON(conn *realtime.Conn, msg realtime.ServerMessage) {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	conn.Send(b)
+}
+
+// sendError はエラーを HTTP と同じエラーコード（例: "invalid_message", "symbol_not_found"）で送信待ちに積みます。
+// 内部エラー（HTTP なら 500）の場合のみログを出力します。
+func sendError(ctx context.Context, conn *realtime.Conn, id string, err error) {
+	status, code := httpx.ErrorStatus(err)
+	if status == http.StatusInternalServerError {
+		slog.ErrorContext(ctx, "websocket request failed", "error", err, "user_id", conn.UserID())
+	}
+	sendJSON(conn, realtime.ServerMessage{Type: realtime.MessageError, ID: id, Code: code})
+}
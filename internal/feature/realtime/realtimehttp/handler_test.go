@@ -0,0 +1,247 @@
+package realtimehttp_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/realtime"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/realtime/realtimehttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/stream"
+)
+
+// stubAuth は "user-<id>" 形式のトークンだけを受け付けます。
+type stubAuth struct{}
+
+func (stubAuth) Authenticate(_ context.Context, token string) (int64, error) {
+	switch token {
+	case "user-1":
+		return 1, nil
+	case "user-2":
+		return 2, nil
+	}
+	return 0, errors.New("unauthenticated")
+}
+
+// stubResolver は 7203 を 7203.T に解決し、UNKNOWN を未知の銘柄として扱います。
+type stubResolver struct{}
+
+func (stubResolver) ResolveSymbol(_ context.Context, symbol string) (string, error) {
+	switch symbol {
+	case "7203":
+		return "7203.T", nil
+	case "UNKNOWN":
+		return "", apperr.New(apperr.KindNotFound, "symbol_not_found", "symbol not found")
+	}
+	return symbol, nil
+}
+
+type testServer struct {
+	*httptest.Server
+	hub     *realtime.Hub
+	streams *stream.Registry
+}
+
+func newTestServer(t *testing.T, cfg realtime.Config) *testServer {
+	t.Helper()
+	hub := realtime.NewHub(cfg)
+	streams := stream.NewRegistry()
+	h := realtimehttp.NewHandler(hub, stubAuth{}, stubResolver{})
+	srv := httptest.NewServer(streams.Middleware()(http.HandlerFunc(h.Serve)))
+	t.Cleanup(srv.Close)
+	return &testServer{Server: srv, hub: hub, streams: streams}
+}
+
+func (s *testServer) wsURL(query string) string {
+	return "ws" + strings.TrimPrefix(s.URL, "http") + "/v1/ws" + query
+}
+
+func dial(t *testing.T, url string) *websocket.Conn {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	c, _, err := websocket.Dial(ctx, url, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = c.CloseNow() })
+	return c
+}
+
+func send(t *testing.T, c *websocket.Conn, msg string) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, c.Write(ctx, websocket.MessageText, []byte(msg)))
+}
+
+func read(t *testing.T, c *websocket.Conn) realtime.ServerMessage {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, data, err := c.Read(ctx)
+	require.NoError(t, err)
+	var m realtime.ServerMessage
+	require.NoError(t, json.Unmarshal(data, &m))
+	return m
+}
+
+func candleEvent(t *testing.T, symbol string, close float64) realtime.Event {
+	t.Helper()
+	ev, err := realtime.NewCandleUpdated(symbol, "1day", realtime.CandleData{Time: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Close: close})
+	require.NoError(t, err)
+	return ev
+}
+
+// TestServe_SubscribePublishUnsubscribe は実際の WebSocket クライアントで subscribe → publish → 受信 → unsubscribe を検証します。
+func TestServe_SubscribePublishUnsubscribe(t *testing.T) {
+	t.Parallel()
+	srv := newTestServer(t, realtime.Config{})
+	c := dial(t, srv.wsURL("?access_token=user-1"))
+	assert.Equal(t, realtime.MessageReady, read(t, c).Type)
+
+	send(t, c, `{"action":"subscribe","id":"s1","channel":"candles","symbol":"7203"}`)
+	ack := read(t, c)
+	assert.Equal(t, realtime.ServerMessage{Type: realtime.MessageSubscribed, ID: "s1", Channel: "candles", Symbol: "7203.T", Interval: "1day"}, ack)
+
+	assert.Equal(t, 1, srv.hub.Publish(candleEvent(t, "7203.T", 2500)))
+	ev := read(t, c)
+	assert.Equal(t, realtime.MessageEvent, ev.Type)
+	assert.Equal(t, realtime.EventCandleUpdated, ev.Event)
+	assert.Equal(t, "7203.T", ev.Symbol)
+	assert.Contains(t, string(ev.Data), `"close":2500`)
+
+	send(t, c, `{"action":"unsubscribe","id":"u1","channel":"candles","symbol":"7203"}`)
+	assert.Equal(t, realtime.MessageUnsubscribed, read(t, c).Type)
+	assert.Equal(t, 0, srv.hub.Publish(candleEvent(t, "7203.T", 2600)), "解除後は届かない")
+
+	// 解除後も接続は維持され、以降のメッセージに応答する
+	send(t, c, `{"action":"ping","id":"p"}`)
+	assert.Equal(t, realtime.ServerMessage{Type: realtime.MessagePong, ID: "p"}, read(t, c))
+}
+
+func TestServe_Errors(t *testing.T) {
+	t.Parallel()
+	srv := newTestServer(t, realtime.Config{})
+	c := dial(t, srv.wsURL("?access_token=user-1"))
+	read(t, c) // ready
+
+	send(t, c, `{"action":"subscribe","id":"x","channel":"candles","symbol":"UNKNOWN"}`)
+	assert.Equal(t, realtime.ServerMessage{Type: realtime.MessageError, ID: "x", Code: "symbol_not_found"}, read(t, c))
+
+	send(t, c, `not json`)
+	assert.Equal(t, realtime.ServerMessage{Type: realtime.MessageError, Code: "invalid_message"}, read(t, c))
+
+	send(t, c, `{"action":"auth","token":"user-2"}`)
+	assert.Equal(t, "invalid_message", read(t, c).Code, "認証後のユーザーの切り替えは受け付けない")
+}
+
+// TestServe_AlertsWithoutSubscription はアラートの発火が購読なしで宛先ユーザーの接続だけに届くことを検証します。
+func TestServe_AlertsWithoutSubscription(t *testing.T) {
+	t.Parallel()
+	srv := newTestServer(t, realtime.Config{})
+	c1 := dial(t, srv.wsURL("?access_token=user-1"))
+	read(t, c1)
+	c2 := dial(t, srv.wsURL("?access_token=user-2"))
+	read(t, c2)
+
+	ev, err := realtime.NewAlertTriggered(2, "AAPL", "1day", realtime.AlertData{AlertID: 9, Direction: "above", Threshold: 200, Close: 201})
+	require.NoError(t, err)
+	assert.Equal(t, 1, srv.hub.Publish(ev))
+
+	got := read(t, c2)
+	assert.Equal(t, realtime.ChannelAlerts, got.Channel)
+	assert.Equal(t, realtime.EventAlertTriggered, got.Event)
+	assert.Contains(t, string(got.Data), `"alert_id":9`)
+}
+
+func TestServe_Auth(t *testing.T) {
+	t.Parallel()
+	srv := newTestServer(t, realtime.Config{MaxConnsPerUser: 1})
+
+	t.Run("クエリのトークンが不正なら 401 でアップグレードしない", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_, res, err := websocket.Dial(ctx, srv.wsURL("?access_token=bad"), nil)
+		require.Error(t, err)
+		require.NotNil(t, res)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+
+	t.Run("最初のメッセージで認証", func(t *testing.T) {
+		c := dial(t, srv.wsURL(""))
+		send(t, c, `{"action":"auth","token":"user-2"}`)
+		assert.Equal(t, realtime.MessageReady, read(t, c).Type)
+		require.NoError(t, c.Close(websocket.StatusNormalClosure, ""))
+	})
+
+	t.Run("最初のメッセージが auth でなければ 4401 で閉じる", func(t *testing.T) {
+		c := dial(t, srv.wsURL(""))
+		send(t, c, `{"action":"ping"}`)
+		_, _, err := c.Read(context.Background())
+		assert.Equal(t, realtimehttp.CloseUnauthorized, websocket.CloseStatus(err))
+	})
+
+	t.Run("同時接続数の上限を超えると 429", func(t *testing.T) {
+		c := dial(t, srv.wsURL("?access_token=user-1"))
+		read(t, c)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_, res, err := websocket.Dial(ctx, srv.wsURL("?access_token=user-1"), nil)
+		require.Error(t, err)
+		require.NotNil(t, res)
+		assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+
+		second := dial(t, srv.wsURL(""))
+		send(t, second, `{"action":"auth","token":"user-1"}`)
+		_, _, err = second.Read(context.Background())
+		assert.Equal(t, realtimehttp.CloseTooManyConnections, websocket.CloseStatus(err), "最初のメッセージでの認証も上限を適用する")
+
+		require.NoError(t, c.Close(websocket.StatusNormalClosure, ""))
+		require.Eventually(t, func() bool { return srv.hub.Conns() == 0 }, 2*time.Second, 10*time.Millisecond, "切断で枠を返す")
+	})
+}
+
+// TestServe_Shutdown はストリームの排出で接続を 1001 で閉じ、排出が完了することを検証します。
+func TestServe_Shutdown(t *testing.T) {
+	t.Parallel()
+	srv := newTestServer(t, realtime.Config{})
+	c := dial(t, srv.wsURL("?access_token=user-1"))
+	read(t, c)
+	require.Equal(t, 1, srv.streams.Len())
+
+	closed := make(chan error, 1)
+	go func() {
+		_, _, err := c.Read(context.Background())
+		closed <- err
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, srv.streams.Drain(ctx))
+	assert.Equal(t, websocket.StatusGoingAway, websocket.CloseStatus(<-closed))
+	assert.Equal(t, 0, srv.hub.Conns())
+}
+
+// TestServe_Keepalive は pong を返さないクライアントの接続を閉じることを検証します。
+func TestServe_Keepalive(t *testing.T) {
+	t.Parallel()
+	hub := realtime.NewHub(realtime.Config{})
+	h := realtimehttp.NewHandler(hub, stubAuth{}, nil).WithKeepalive(20*time.Millisecond, 50*time.Millisecond)
+	srv := httptest.NewServer(http.HandlerFunc(h.Serve))
+	t.Cleanup(srv.Close)
+
+	// 読み取らないクライアントは ping に応答しない
+	dial(t, "ws"+strings.TrimPrefix(srv.URL, "http")+"?access_token=user-1")
+	require.Eventually(t, func() bool { return hub.Conns() == 1 }, 2*time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool { return hub.Conns() == 0 }, 2*time.Second, 10*time.Millisecond)
+}
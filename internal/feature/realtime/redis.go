@@ -0,0 +1,93 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisPublisher はイベントを Redis Pub/Sub のチャネルに発行します（batch → API の中継）。
+// rdb が nil の場合は何もしません（Redis なしの起動ではリアルタイム配信を行わない）。
+type RedisPublisher struct {
+	rdb     *redis.Client
+	channel string
+}
+
+// NewRedisPublisher は RedisPublisher を生成します。
+// channel は環境の名前空間を含む Pub/Sub のチャネル名（例: "staging:realtime:events"）です。
+func NewRedisPublisher(rdb *redis.Client, channel string) *RedisPublisher {
+	return &RedisPublisher{rdb: rdb, channel: channel}
+}
+
+// Publish は ev を発行します。購読者（API インスタンス）がいない場合も成功します。
+func (p *RedisPublisher) Publish(ctx context.Context, ev Event) error {
+	if p.rdb == nil {
+		return nil
+	}
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+	if err := p.rdb.Publish(ctx, p.channel, payload).Err(); err != nil {
+		return fmt.Errorf("publish realtime event: %w", err)
+	}
+	return nil
+}
+
+// RedisSubscriber は Redis Pub/Sub のチャネルを購読し、受け取ったイベントを Hub に渡します。
+type RedisSubscriber struct {
+	rdb       *redis.Client
+	channel   string
+	hub       *Hub
+	listeners []func(Event) // Hub に渡す前に呼ぶ（WithListener 参照）
+}
+
+// NewRedisSubscriber は RedisSubscriber を生成します。channel は RedisPublisher と同じ値を指定します。
+func NewRedisSubscriber(rdb *redis.Client, channel string, hub *Hub) *RedisSubscriber {
+	return &RedisSubscriber{rdb: rdb, channel: channel, hub: hub}
+}
+
+// WithListener は受け取ったイベントを Hub に渡す前に fn にも渡します（プロセス内キャッシュの無効化等）。
+// fn は購読の goroutine で順に呼ぶため、すぐに戻るようにしてください。
+func (s *RedisSubscriber) WithListener(fn func(Event)) *RedisSubscriber {
+	s.listeners = append(s.listeners, fn)
+	return s
+}
+
+// Run は ctx が終了するまで購読を続けます。rdb が nil の場合は何もしません。
+// Redis との接続が切れた場合はクライアントが再接続・再購読します（切断中に発行されたイベントは届きません）。
+func (s *RedisSubscriber) Run(ctx context.Context) {
+	if s.rdb == nil {
+		return
+	}
+	ps := s.rdb.Subscribe(ctx, s.channel)
+	defer func() {
+		if err := ps.Close(); err != nil {
+			slog.Warn("realtime: failed to close subscription", "error", err)
+		}
+	}()
+
+	ch := ps.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			var ev Event
+			if err := json.Unmarshal([]byte(msg.Payload), &ev); err != nil {
+				slog.Warn("realtime: dropping malformed event", "error", err)
+				continue
+			}
+			for _, fn := range s.listeners {
+				fn(ev)
+			}
+			s.hub.Publish(ev)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package realtime
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRedisBridge は RedisPublisher が発行したイベントを RedisSubscriber がリスナーと Hub に渡すことを検証します。
+func TestRedisBridge(t *testing.T) {
+	t.Parallel()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	hub := NewHub(Config{})
+	conn, err := hub.Connect(1)
+	require.NoError(t, err)
+	require.NoError(t, hub.Subscribe(conn, Topic{Symbol: "AAPL", Interval: "1day"}))
+
+	var listened atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		NewRedisSubscriber(rdb, "test:realtime:events", hub).
+			WithListener(func(Event) { listened.Add(1) }).
+			Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	require.Eventually(t, func() bool { return mr.PubSubNumSub("test:realtime:events")["test:realtime:events"] == 1 }, time.Second, 10*time.Millisecond)
+
+	pub := NewRedisPublisher(rdb, "test:realtime:events")
+	require.NoError(t, pub.Publish(ctx, candleEvent(t, "AAPL", "1day", 101)))
+	mr.Publish("test:realtime:events", "not json") // 不正なメッセージは破棄して購読を続ける
+	require.NoError(t, pub.Publish(ctx, candleEvent(t, "AAPL", "1day", 102)))
+
+	var got []ServerMessage
+	require.Eventually(t, func() bool {
+		got = append(got, drainMessages(t, conn)...)
+		return len(got) == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.Contains(t, string(got[0].Data), `"close":101`)
+	assert.Contains(t, string(got[1].Data), `"close":102`)
+	assert.Equal(t, int32(2), listened.Load(), "不正なメッセージはリスナーにも渡さない")
+}
+
+func TestRedisBridge_NilClient(t *testing.T) {
+	t.Parallel()
+	assert.NoError(t, NewRedisPublisher(nil, "c").Publish(context.Background(), Event{}))
+	NewRedisSubscriber(nil, "c", NewHub(Config{})).Run(context.Background()) // 即座に戻る
+}
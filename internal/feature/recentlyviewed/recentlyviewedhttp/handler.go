@@ -0,0 +1,74 @@
+package recentlyviewedhttp
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/recentlyviewed"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+)
+
+// defaultLimit は limit 未指定時に返す件数です。
+const defaultLimit = 10
+
+// Usecase は最近閲覧した銘柄のユースケースインターフェースを定義します。
+type Usecase interface {
+	ListRecent(ctx context.Context, userID int64, limit int, locale string) ([]recentlyviewed.Entry, error)
+}
+
+// Handler は最近閲覧した銘柄に関連するHTTPリクエストを処理します。
+type Handler struct {
+	uc      Usecase
+	locales []string
+}
+
+// NewHandler はHandlerの新しいインスタンスを生成します。
+// locales は銘柄名の応答ロケールの候補で、先頭が既定のロケールです。
+func NewHandler(uc Usecase, locales []string) *Handler {
+	return &Handler{uc: uc, locales: locales}
+}
+
+// List はユーザーが最近閲覧した銘柄を新しい順に返します。
+//
+// エンドポイント例:
+// GET /me/recent-symbols?limit=10
+//
+// 銘柄名は Accept-Language から決めたロケールの表記で返します。
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := jwt.UserIDFromContext(r.Context())
+	if !ok {
+		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
+		return
+	}
+
+	limit := defaultLimit
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > recentlyviewed.MaxEntries {
+			httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "limit must be an integer between 1 and " + strconv.Itoa(recentlyviewed.MaxEntries)})
+			return
+		}
+		limit = n
+	}
+
+	locale := httpx.NegotiateLocale(r, h.locales)
+	entries, err := h.uc.ListRecent(r.Context(), userID, limit, locale)
+	if err != nil {
+		httpx.WriteError(w, err, "failed to list recently viewed symbols", "userID", userID)
+		return
+	}
+
+	out := make([]api.RecentSymbol, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, api.RecentSymbol{
+			SymbolCode: e.SymbolCode,
+			Name:       e.Name,
+			ViewedAt:   api.NewTimestamp(e.ViewedAt),
+		})
+	}
+	httpx.SetLocaleHeaders(w, locale)
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
@@ -0,0 +1,142 @@
+package recentlyviewedhttp_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/recentlyviewed"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/recentlyviewed/recentlyviewedhttp"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+)
+
+const testUserID int64 = 1
+
+// mockUsecase は Usecase インターフェースのモック実装です。
+type mockUsecase struct {
+	ListRecentFunc func(ctx context.Context, userID int64, limit int) ([]recentlyviewed.Entry, error)
+	gotLocale      string // 最後に渡されたロケール
+}
+
+func (m *mockUsecase) ListRecent(ctx context.Context, userID int64, limit int, locale string) ([]recentlyviewed.Entry, error) {
+	m.gotLocale = locale
+	return m.ListRecentFunc(ctx, userID, limit)
+}
+
+var testLocales = []string{"ja", "en"}
+
+func TestRecentlyViewedHandler_List(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2026, 10, 1, 18, 0, 0, 500, time.FixedZone("JST", 9*60*60))
+
+	tests := []struct {
+		name           string
+		url            string
+		mockList       func(ctx context.Context, userID int64, limit int) ([]recentlyviewed.Entry, error)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "success: default limit",
+			url:  "/me/recent-symbols",
+			mockList: func(ctx context.Context, userID int64, limit int) ([]recentlyviewed.Entry, error) {
+				assert.Equal(t, testUserID, userID)
+				assert.Equal(t, 10, limit)
+				return []recentlyviewed.Entry{{SymbolCode: "AAPL", Name: "Apple Inc.", ViewedAt: at}}, nil
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"symbol_code":"AAPL","name":"Apple Inc.","viewed_at":"2026-10-01T09:00:00Z"}]`,
+		},
+		{
+			name: "success: explicit limit and empty history",
+			url:  "/me/recent-symbols?limit=50",
+			mockList: func(ctx context.Context, userID int64, limit int) ([]recentlyviewed.Entry, error) {
+				assert.Equal(t, 50, limit)
+				return []recentlyviewed.Entry{}, nil
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[]`,
+		},
+		{
+			name:           "error: limit out of range returns 400",
+			url:            "/me/recent-symbols?limit=51",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"limit must be an integer between 1 and 50"}`,
+		},
+		{
+			name:           "error: non-integer limit returns 400",
+			url:            "/me/recent-symbols?limit=abc",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"limit must be an integer between 1 and 50"}`,
+		},
+		{
+			name: "error: usecase returns error",
+			url:  "/me/recent-symbols",
+			mockList: func(ctx context.Context, userID int64, limit int) ([]recentlyviewed.Entry, error) {
+				return nil, errors.New("redis down")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := recentlyviewedhttp.NewHandler(&mockUsecase{ListRecentFunc: tt.mockList}, testLocales)
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			req = req.WithContext(jwt.WithUserID(req.Context(), testUserID))
+			w := httptest.NewRecorder()
+
+			h.List(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+// TestRecentlyViewedHandler_List_Locale は Accept-Language から決めたロケールが usecase と応答ヘッダーに反映されることを検証します。
+func TestRecentlyViewedHandler_List_Locale(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		want           string
+	}{
+		{name: "no header uses default", want: "ja"},
+		{name: "supported language", acceptLanguage: "en-US,en;q=0.9", want: "en"},
+		{name: "unsupported language uses default", acceptLanguage: "ko", want: "ja"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc := &mockUsecase{ListRecentFunc: func(context.Context, int64, int) ([]recentlyviewed.Entry, error) {
+				return []recentlyviewed.Entry{}, nil
+			}}
+			h := recentlyviewedhttp.NewHandler(uc, testLocales)
+			req := httptest.NewRequest(http.MethodGet, "/me/recent-symbols", nil)
+			req = req.WithContext(jwt.WithUserID(req.Context(), testUserID))
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			w := httptest.NewRecorder()
+
+			h.List(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.want, uc.gotLocale)
+			assert.Equal(t, tt.want, w.Header().Get("Content-Language"))
+			assert.Equal(t, "Accept-Language", w.Header().Get("Vary"))
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package recentlyviewed
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultBufferSize は書き込み待ちの閲覧イベントを溜めるバッファの既定サイズです。
+	DefaultBufferSize = 1024
+
+	// defaultWriteTimeout は 1 件の書き込みにかける時間の上限です。
+	// Redis の応答が遅い間もバッファの消化が止まり続けないようにします。
+	defaultWriteTimeout = 500 * time.Millisecond
+)
+
+// Store は閲覧履歴の書き込み先を抽象化します。RedisStore が実装します。
+type Store interface {
+	Add(ctx context.Context, userID int64, symbolCode string, viewedAt time.Time) error
+}
+
+type viewEvent struct {
+	userID   int64
+	code     string
+	viewedAt time.Time
+}
+
+// Recorder は閲覧の記録をリクエストから切り離して非同期に行います（fire-and-forget）。
+//
+// Record はバッファ付きチャネルへの非ブロッキング送信のみを行い、書き込みは Run のバックグラウンド
+// goroutine が担います。閲覧履歴は失っても支障のない補助データのため、バッファが満杯の場合や
+// 書き込みに失敗した場合（Redis 障害時など）はイベントを破棄し、Dropped で件数を確認できるようにします。
+type Recorder struct {
+	store        Store
+	events       chan viewEvent
+	writeTimeout time.Duration
+	now          func() time.Time
+	dropped      atomic.Uint64
+}
+
+// NewRecorder は store に書き込む Recorder を生成します。bufferSize が 0 以下の場合は DefaultBufferSize を使います。
+// 書き込みを行うには Run を起動する必要があります。
+func NewRecorder(store Store, bufferSize int) *Recorder {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+	return &Recorder{
+		store:        store,
+		events:       make(chan viewEvent, bufferSize),
+		writeTimeout: defaultWriteTimeout,
+		now:          time.Now,
+	}
+}
+
+// Record はユーザーの銘柄閲覧を記録待ちに積みます。呼び出し元をブロックすることはありません。
+// バッファが満杯の場合はイベントを破棄します。
+func (r *Recorder) Record(userID int64, symbolCode string) {
+	select {
+	case r.events <- viewEvent{userID: userID, code: symbolCode, viewedAt: r.now()}:
+	default:
+		r.dropped.Add(1)
+	}
+}
+
+// Dropped はこれまでに破棄した閲覧イベントの累計を返します。
+func (r *Recorder) Dropped() uint64 {
+	return r.dropped.Load()
+}
+
+// Run は ctx が終了するまで記録待ちのイベントを store へ書き込みます。
+// バックグラウンドの goroutine で 1 つだけ起動します。終了時に残っているイベントは書き込みません。
+func (r *Recorder) Run(ctx context.Context) {
+	failing := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev := <-r.events:
+			failing = r.write(ctx, ev, failing)
+		}
+	}
+}
+
+// write は 1 件を書き込み、書き込み失敗中かどうかを返します。
+// 障害中に 1 件ごとにログを出さないよう、失敗と復旧の切り替わりでのみログを出します。
+func (r *Recorder) write(ctx context.Context, ev viewEvent, failing bool) bool {
+	wctx, cancel := context.WithTimeout(ctx, r.writeTimeout)
+	defer cancel()
+
+	if err := r.store.Add(wctx, ev.userID, ev.code, ev.viewedAt); err != nil {
+		n := r.dropped.Add(1)
+		if !failing {
+			slog.Warn("failed to record recently viewed symbol, dropping views until the store recovers", "error", err, "dropped_total", n)
+		}
+		return true
+	}
+	if failing {
+		slog.Info("recently viewed store recovered", "dropped_total", r.dropped.Load())
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package recentlyviewed
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// funcStore は関数で振る舞いを差し替えられる Store です。
+type funcStore func(ctx context.Context, userID int64, code string, at time.Time) error
+
+func (f funcStore) Add(ctx context.Context, userID int64, code string, at time.Time) error {
+	return f(ctx, userID, code, at)
+}
+
+func TestRecorder_WritesInBackground(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var got []string
+	written := make(chan struct{}, 2)
+	store := funcStore(func(_ context.Context, _ int64, code string, _ time.Time) error {
+		mu.Lock()
+		got = append(got, code)
+		mu.Unlock()
+		written <- struct{}{}
+		return nil
+	})
+	rec := NewRecorder(store, 8)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go rec.Run(ctx)
+
+	rec.Record(1, "AAPL")
+	rec.Record(1, "MSFT")
+	for range 2 {
+		select {
+		case <-written:
+		case <-time.After(time.Second):
+			t.Fatal("views were not written")
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"AAPL", "MSFT"}, got)
+	assert.Zero(t, rec.Dropped())
+}
+
+func TestRecorder_DropsWhenStoreUnavailable(t *testing.T) {
+	t.Parallel()
+
+	attempts := make(chan struct{}, 3)
+	store := funcStore(func(context.Context, int64, string, time.Time) error {
+		attempts <- struct{}{}
+		return errors.New("connection refused")
+	})
+	rec := NewRecorder(store, 8)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go rec.Run(ctx)
+
+	for range 3 {
+		rec.Record(1, "AAPL")
+	}
+	for range 3 {
+		<-attempts
+	}
+	require.Eventually(t, func() bool { return rec.Dropped() == 3 }, time.Second, time.Millisecond)
+}
+
+func TestRecorder_DropsWhenBufferFull(t *testing.T) {
+	t.Parallel()
+
+	// Run を起動しないため、バッファを超えた分は即座に破棄される
+	rec := NewRecorder(funcStore(func(context.Context, int64, string, time.Time) error { return nil }), 2)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 5 {
+			rec.Record(1, "AAPL")
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Record must never block")
+	}
+	assert.Equal(t, uint64(3), rec.Dropped())
+}
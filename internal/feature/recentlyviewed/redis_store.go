@@ -0,0 +1,88 @@
+package recentlyviewed
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// errStoreUnavailable は Redis 未接続のため閲覧を記録できないことを示します。
+var errStoreUnavailable = errors.New("recently viewed store: redis unavailable")
+
+// RedisProvider は現在利用できる Redis クライアントを返します。障害中は nil を返します。
+type RedisProvider interface {
+	Client() *redis.Client
+}
+
+// RedisStore は閲覧履歴をユーザーごとの Redis ZSET（メンバー=銘柄コード、スコア=閲覧日時のミリ秒）に保存します。
+// 同じ銘柄の再閲覧はスコアの更新になるため重複せず、MaxEntries を超えた古い閲覧は書き込みのたびに削除されます。
+type RedisStore struct {
+	rdb      *redis.Client
+	provider RedisProvider
+	prefix   string
+}
+
+// NewRedisStore は RedisStore を生成します。
+// prefix は環境の名前空間を含むキーの接頭辞（例: "staging:recent:symbols"）で、末尾にユーザーIDを付与します。
+// rdb が nil の場合、記録はエラーになり、読み取りは空の履歴を返します。
+func NewRedisStore(rdb *redis.Client, prefix string) *RedisStore {
+	return &RedisStore{rdb: rdb, prefix: prefix}
+}
+
+// WithRedisProvider は Redis クライアントを呼び出しごとに p から取得するよう設定します。
+// p が nil を返す間は rdb が nil の場合と同じ扱い（記録はエラー、読み取りは空）になります。
+func (s *RedisStore) WithRedisProvider(p RedisProvider) *RedisStore {
+	s.provider = p
+	return s
+}
+
+func (s *RedisStore) client() *redis.Client {
+	if s.provider != nil {
+		return s.provider.Client()
+	}
+	return s.rdb
+}
+
+// Add は閲覧を記録し、上限を超えた古い閲覧を削除します。
+func (s *RedisStore) Add(ctx context.Context, userID int64, symbolCode string, viewedAt time.Time) error {
+	rdb := s.client()
+	if rdb == nil {
+		return errStoreUnavailable
+	}
+	key := s.key(userID)
+	_, err := rdb.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.ZAdd(ctx, key, redis.Z{Score: float64(viewedAt.UnixMilli()), Member: symbolCode})
+		// スコアの昇順で先頭（古い側）から、末尾 MaxEntries 件を残して削除する
+		p.ZRemRangeByRank(ctx, key, 0, -MaxEntries-1)
+		return nil
+	})
+	return err
+}
+
+// Recent は閲覧日時の新しい順に最大 limit 件の閲覧を返します。
+func (s *RedisStore) Recent(ctx context.Context, userID int64, limit int) ([]View, error) {
+	rdb := s.client()
+	if rdb == nil || limit <= 0 {
+		return []View{}, nil
+	}
+	zs, err := rdb.ZRevRangeWithScores(ctx, s.key(userID), 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+	views := make([]View, 0, len(zs))
+	for _, z := range zs {
+		code, ok := z.Member.(string)
+		if !ok {
+			continue
+		}
+		views = append(views, View{SymbolCode: code, ViewedAt: time.UnixMilli(int64(z.Score)).UTC()})
+	}
+	return views, nil
+}
+
+func (s *RedisStore) key(userID int64) string {
+	return s.prefix + ":" + strconv.FormatInt(userID, 10)
+}
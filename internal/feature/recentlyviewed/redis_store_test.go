@@ -0,0 +1,87 @@
+package recentlyviewed
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKeyPrefix = "test:recent:symbols"
+
+func newTestRedisStore(t *testing.T) (*RedisStore, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return NewRedisStore(rdb, testKeyPrefix), mr
+}
+
+func TestRedisStore_OrderAndRevisit(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store, _ := newTestRedisStore(t)
+	base := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	require.NoError(t, store.Add(ctx, 1, "AAPL", base))
+	require.NoError(t, store.Add(ctx, 1, "MSFT", base.Add(time.Minute)))
+	require.NoError(t, store.Add(ctx, 1, "7203.T", base.Add(2*time.Minute)))
+	// 再閲覧は重複せず、先頭に移動する
+	require.NoError(t, store.Add(ctx, 1, "AAPL", base.Add(3*time.Minute)))
+	// 他ユーザーの閲覧は混ざらない
+	require.NoError(t, store.Add(ctx, 2, "TSLA", base.Add(4*time.Minute)))
+
+	got, err := store.Recent(ctx, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []View{
+		{SymbolCode: "AAPL", ViewedAt: base.Add(3 * time.Minute)},
+		{SymbolCode: "7203.T", ViewedAt: base.Add(2 * time.Minute)},
+		{SymbolCode: "MSFT", ViewedAt: base.Add(time.Minute)},
+	}, got)
+
+	got, err = store.Recent(ctx, 1, 2)
+	require.NoError(t, err)
+	assert.Len(t, got, 2, "limit 件までに切り詰めること")
+}
+
+func TestRedisStore_TrimsToMaxEntries(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store, mr := newTestRedisStore(t)
+	base := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	const total = MaxEntries + 5
+	for i := range total {
+		require.NoError(t, store.Add(ctx, 1, fmt.Sprintf("S%02d", i), base.Add(time.Duration(i)*time.Second)))
+	}
+
+	members, err := mr.ZMembers(testKeyPrefix + ":1")
+	require.NoError(t, err)
+	assert.Len(t, members, MaxEntries)
+	assert.NotContains(t, members, "S00", "最も古い閲覧から削除されること")
+	assert.NotContains(t, members, "S04")
+	assert.Contains(t, members, "S05")
+
+	got, err := store.Recent(ctx, 1, MaxEntries)
+	require.NoError(t, err)
+	require.Len(t, got, MaxEntries)
+	assert.Equal(t, fmt.Sprintf("S%02d", total-1), got[0].SymbolCode)
+	assert.Equal(t, "S05", got[MaxEntries-1].SymbolCode)
+}
+
+func TestRedisStore_NilClient(t *testing.T) {
+	t.Parallel()
+
+	store := NewRedisStore(nil, testKeyPrefix)
+	assert.Error(t, store.Add(context.Background(), 1, "AAPL", time.Now()))
+	got, err := store.Recent(context.Background(), 1, 10)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
@@ -0,0 +1,62 @@
+package recentlyviewed
+
+import (
+	"context"
+	"fmt"
+)
+
+// Reader は閲覧履歴の読み取り元を抽象化します。RedisStore が実装します。
+type Reader interface {
+	// Recent は閲覧日時の新しい順に最大 limit 件の閲覧を返します。
+	Recent(ctx context.Context, userID int64, limit int) ([]View, error)
+}
+
+// SymbolNameLookup は銘柄コードから銘柄名を引くインターフェースです。
+// recentlyviewed が symbollist feature に直接依存しないよう、利用者側で定義します。
+type SymbolNameLookup interface {
+	// SymbolNames はアクティブな銘柄のうち codes に含まれるものについて、コード → 企業名の対応を返します。
+	// 企業名は locale の表記で、未登録ならフォールバックした表記になります。
+	SymbolNames(ctx context.Context, codes []string, locale string) (map[string]string, error)
+}
+
+// usecase は最近閲覧した銘柄の一覧を提供します。
+type usecase struct {
+	views Reader
+	names SymbolNameLookup
+}
+
+// NewUsecase は指定された閲覧履歴の読み取り元と銘柄名の参照先で usecase の新しいインスタンスを生成します。
+func NewUsecase(views Reader, names SymbolNameLookup) *usecase {
+	return &usecase{views: views, names: names}
+}
+
+// ListRecent はユーザーが最近閲覧した銘柄を新しい順に最大 limit 件、locale の銘柄名を結合して返します。
+// 閲覧後に非アクティブ化された銘柄は名前を引けないため結果から除外します（limit 件に満たない場合があります）。
+func (u *usecase) ListRecent(ctx context.Context, userID int64, limit int, locale string) ([]Entry, error) {
+	views, err := u.views.Recent(ctx, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("load recently viewed symbols: %w", err)
+	}
+	if len(views) == 0 {
+		return []Entry{}, nil
+	}
+
+	codes := make([]string, 0, len(views))
+	for _, v := range views {
+		codes = append(codes, v.SymbolCode)
+	}
+	names, err := u.names.SymbolNames(ctx, codes, locale)
+	if err != nil {
+		return nil, fmt.Errorf("look up symbol names: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(views))
+	for _, v := range views {
+		name, ok := names[v.SymbolCode]
+		if !ok {
+			continue
+		}
+		entries = append(entries, Entry{SymbolCode: v.SymbolCode, Name: name, ViewedAt: v.ViewedAt})
+	}
+	return entries, nil
+}
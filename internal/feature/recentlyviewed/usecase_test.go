@@ -0,0 +1,90 @@
+package recentlyviewed_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/recentlyviewed"
+)
+
+// stubReader は固定の閲覧履歴を返す Reader です。
+type stubReader struct {
+	views []recentlyviewed.View
+	err   error
+}
+
+func (s stubReader) Recent(_ context.Context, _ int64, limit int) ([]recentlyviewed.View, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if len(s.views) > limit {
+		return s.views[:limit], nil
+	}
+	return s.views, nil
+}
+
+// stubNames はアクティブな銘柄の名前表を持つ SymbolNameLookup です。
+type stubNames struct {
+	names     map[string]string
+	calls     int
+	gotLocale string
+}
+
+func (s *stubNames) SymbolNames(_ context.Context, codes []string, locale string) (map[string]string, error) {
+	s.calls++
+	s.gotLocale = locale
+	out := map[string]string{}
+	for _, c := range codes {
+		if n, ok := s.names[c]; ok {
+			out[c] = n
+		}
+	}
+	return out, nil
+}
+
+func TestUsecase_ListRecentJoinsNames(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	views := stubReader{views: []recentlyviewed.View{
+		{SymbolCode: "AAPL", ViewedAt: at.Add(2 * time.Minute)},
+		{SymbolCode: "DELISTED", ViewedAt: at.Add(time.Minute)},
+		{SymbolCode: "7203.T", ViewedAt: at},
+	}}
+	names := &stubNames{names: map[string]string{"AAPL": "Apple Inc.", "7203.T": "Toyota Motor Corp."}}
+	uc := recentlyviewed.NewUsecase(views, names)
+
+	got, err := uc.ListRecent(context.Background(), 1, 10, "en")
+	require.NoError(t, err)
+	assert.Equal(t, "en", names.gotLocale, "ロケールを銘柄名の参照先に渡すこと")
+	assert.Equal(t, []recentlyviewed.Entry{
+		{SymbolCode: "AAPL", Name: "Apple Inc.", ViewedAt: at.Add(2 * time.Minute)},
+		{SymbolCode: "7203.T", Name: "Toyota Motor Corp.", ViewedAt: at},
+	}, got, "非アクティブな銘柄は除外し、閲覧順を保つこと")
+}
+
+func TestUsecase_ListRecentEmpty(t *testing.T) {
+	t.Parallel()
+
+	names := &stubNames{}
+	uc := recentlyviewed.NewUsecase(stubReader{}, names)
+
+	got, err := uc.ListRecent(context.Background(), 1, 10, "ja")
+	require.NoError(t, err)
+	assert.NotNil(t, got)
+	assert.Empty(t, got)
+	assert.Zero(t, names.calls, "履歴が空なら銘柄名を引かないこと")
+}
+
+func TestUsecase_ListRecentReaderError(t *testing.T) {
+	t.Parallel()
+
+	uc := recentlyviewed.NewUsecase(stubReader{err: errors.New("redis down")}, &stubNames{})
+	_, err := uc.ListRecent(context.Background(), 1, 10, "ja")
+	assert.Error(t, err)
+}
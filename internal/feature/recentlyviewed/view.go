@@ -0,0 +1,19 @@
+package recentlyviewed
+
+import "time"
+
+// MaxEntries はユーザーごとに保持する閲覧履歴の上限です。超えた分は閲覧日時の古い順に削除されます。
+const MaxEntries = 50
+
+// View は銘柄の閲覧 1 件を表します。同じ銘柄を再度閲覧した場合は ViewedAt のみ更新されます。
+type View struct {
+	SymbolCode string    // 正規の銘柄コード（例: "AAPL", "7203.T"）
+	ViewedAt   time.Time // 最後に閲覧した日時
+}
+
+// Entry は閲覧履歴に銘柄名を結合したものです。
+type Entry struct {
+	SymbolCode string    // 銘柄コード
+	Name       string    // 企業名
+	ViewedAt   time.Time // 最後に閲覧した日時
+}
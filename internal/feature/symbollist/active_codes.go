@@ -0,0 +1,151 @@
+package symbollist
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultActiveCodeTTL は銘柄コード集合をプロセス内に保持する既定の期間です。
+// 銘柄の追加・状態の変更は稀なため、リクエスト毎の DB 問い合わせを避けつつ数十秒で追従させます。
+const DefaultActiveCodeTTL = 60 * time.Second
+
+// CodeStatusLister は保存済みのデータを返せる（active / delisted の）銘柄のコードと状態の取得を抽象化します。
+type CodeStatusLister interface {
+	ListVisibleCodes(ctx context.Context) ([]CodeStatus, error)
+}
+
+// ActiveCodeSet は /candles 等で参照できる銘柄のコード集合のプロセス内キャッシュです。
+// 各銘柄の状態に加え、/candles の ?include=symbol で返す名前（多言語の名前を含む）と市場、プランによる参照の制限に使う区分も保持します。
+// 上場廃止（delisted）の銘柄も保存済みの履歴を返すため含み、非表示（hidden）の銘柄は含みません。
+// 取り込み（ingest）の対象は Repository.ListActive（active のみ）で、この集合とは異なります。
+// TTL 経過後の最初の Contains で一覧を再取得します（read-through）。
+// 並行呼び出しに対して安全で、期限切れ時に同時に届いた Contains の再取得は 1 回にまとめます（singleflight）。
+type ActiveCodeSet struct {
+	src CodeStatusLister
+	ttl time.Duration
+	now func() time.Time
+
+	group singleflight.Group
+
+	mu        sync.RWMutex
+	codes     map[string]CodeStatus
+	expiresAt time.Time
+	gen       uint64 // Invalidate のたびに進める。古い世代で始まった再取得の結果は保存しない
+}
+
+// NewActiveCodeSet は指定された取得元と TTL で ActiveCodeSet を生成します。
+// ttl が 0 以下の場合は DefaultActiveCodeTTL を使用します。
+func NewActiveCodeSet(src CodeStatusLister, ttl time.Duration) *ActiveCodeSet {
+	if ttl <= 0 {
+		ttl = DefaultActiveCodeTTL
+	}
+	return &ActiveCodeSet{src: src, ttl: ttl, now: time.Now}
+}
+
+// Contains は code が参照できる（active / delisted の）銘柄かを返します。
+// キャッシュが未取得または期限切れの場合は取得元から再読み込みし、その失敗はエラーとして返します。
+func (s *ActiveCodeSet) Contains(ctx context.Context, code string) (bool, error) {
+	st, err := s.Status(ctx, code)
+	return st != "", err
+}
+
+// Status は code の銘柄の状態（active / delisted）を返します。参照できない銘柄の場合は空文字を返します。
+// キャッシュの扱いは Contains と同じです。
+func (s *ActiveCodeSet) Status(ctx context.Context, code string) (Status, error) {
+	c, err := s.get(ctx, code)
+	return c.Status, err
+}
+
+// Tier は code の銘柄の区分を返します。参照できない銘柄の場合は空文字を返します。
+// キャッシュの扱いは Contains と同じで、区分の変更は TTL（または Invalidate）で反映されます。
+func (s *ActiveCodeSet) Tier(ctx context.Context, code string) (Tier, error) {
+	c, err := s.get(ctx, code)
+	if err != nil || c.Status == "" {
+		return "", err
+	}
+	return c.Tier.OrBasic(), nil
+}
+
+// Lookup は code の銘柄の状態・名前・市場を返します。Name は locale の名前（LocalizedName）です。
+// 参照できない銘柄の場合は ok=false を返します。キャッシュの扱いは Contains と同じで、
+// 名前の変更は状態の変更と同じく TTL（または Invalidate）で反映されます。
+func (s *ActiveCodeSet) Lookup(ctx context.Context, code, locale string) (c CodeStatus, ok bool, err error) {
+	c, err = s.get(ctx, code)
+	if err != nil || c.Status == "" {
+		return CodeStatus{}, false, err
+	}
+	c.Name = LocalizedName(c.Name, c.Names, locale)
+	c.Names = nil
+	return c, true, nil
+}
+
+// get はキャッシュから code の行を返します。参照できない銘柄の場合はゼロ値を返します。
+func (s *ActiveCodeSet) get(ctx context.Context, code string) (CodeStatus, error) {
+	s.mu.RLock()
+	if s.codes != nil && s.now().Before(s.expiresAt) {
+		c := s.codes[code]
+		s.mu.RUnlock()
+		return c, nil
+	}
+	s.mu.RUnlock()
+
+	codes, err := s.refresh(ctx)
+	if err != nil {
+		return CodeStatus{}, err
+	}
+	return codes[code], nil
+}
+
+// Invalidate はキャッシュを破棄し、次回の Contains で即座に再取得させます。
+// 銘柄の追加・状態の切り替え後に呼び出します。
+// 実行中の再取得は変更前の一覧を読んでいる可能性があるため、その結果は保存せず、以降の Contains は新しく取得し直します。
+func (s *ActiveCodeSet) Invalidate() {
+	s.mu.Lock()
+	s.codes = nil
+	s.expiresAt = time.Time{}
+	s.gen++
+	s.mu.Unlock()
+}
+
+// refresh は取得元から一覧を読み込み、キャッシュを差し替えます。
+// 同じ世代の同時の再取得は 1 回にまとめ、待機中の呼び出し元は自身の ctx の終了で待機をやめます。
+// 取得は最初の呼び出し元の ctx のキャンセルを引き継がない（待機中の他の呼び出し元を巻き込まない）ため、
+// 時間の上限は取得元（DB の statement_timeout 等）に委ねます。
+func (s *ActiveCodeSet) refresh(ctx context.Context) (map[string]CodeStatus, error) {
+	s.mu.RLock()
+	gen := s.gen
+	s.mu.RUnlock()
+
+	ch := s.group.DoChan(strconv.FormatUint(gen, 10), func() (any, error) {
+		list, err := s.src.ListVisibleCodes(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, err
+		}
+		codes := make(map[string]CodeStatus, len(list))
+		for _, c := range list {
+			codes[c.Code] = c
+		}
+
+		s.mu.Lock()
+		if s.gen == gen {
+			s.codes = codes
+			s.expiresAt = s.now().Add(s.ttl)
+		}
+		s.mu.Unlock()
+		return codes, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(map[string]CodeStatus), nil
+	}
+}
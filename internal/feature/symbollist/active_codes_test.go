@@ -0,0 +1,368 @@
+package symbollist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubCodeLister は CodeStatusLister のスタブで、呼び出し回数を記録します。
+// codes は active、delisted は上場廃止の銘柄として返します。
+type stubCodeLister struct {
+	mu       sync.Mutex
+	codes    []string
+	delisted []string
+	err      error
+	calls    int
+}
+
+func (s *stubCodeLister) ListVisibleCodes(ctx context.Context) ([]CodeStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	out := activeCodeStatuses(s.codes)
+	for _, c := range s.delisted {
+		out = append(out, CodeStatus{Code: c, Status: StatusDelisted})
+	}
+	return out, s.err
+}
+
+func activeCodeStatuses(codes []string) []CodeStatus {
+	out := make([]CodeStatus, 0, len(codes))
+	for _, c := range codes {
+		out = append(out, CodeStatus{Code: c, Status: StatusActive})
+	}
+	return out
+}
+
+func (s *stubCodeLister) set(codes ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.codes = codes
+}
+
+// newTestActiveCodeSet は時刻を差し替え可能な ActiveCodeSet を返します。
+func newTestActiveCodeSet(src CodeStatusLister, ttl time.Duration, now *time.Time) *ActiveCodeSet {
+	s := NewActiveCodeSet(src, ttl)
+	s.now = func() time.Time { return *now }
+	return s
+}
+
+func TestActiveCodeSet_Contains(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	src := &stubCodeLister{codes: []string{"AAPL", "7203.T"}}
+	set := newTestActiveCodeSet(src, time.Minute, &now)
+
+	ok, err := set.Contains(context.Background(), "AAPL")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = set.Contains(context.Background(), "UNKNOWN")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.Equal(t, 1, src.calls, "TTL 内は取得元を再度呼ばない")
+}
+
+// TestActiveCodeSet_Status は上場廃止の銘柄も参照できる銘柄として含み、状態を返すことを検証します。
+func TestActiveCodeSet_Status(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	src := &stubCodeLister{codes: []string{"AAPL"}, delisted: []string{"TWTR"}}
+	set := newTestActiveCodeSet(src, time.Minute, &now)
+
+	tests := []struct {
+		code       string
+		wantStatus Status
+	}{
+		{"AAPL", StatusActive},
+		{"TWTR", StatusDelisted},
+		{"HIDDEN", ""}, // 非表示の銘柄は取得元が返さない
+	}
+	for _, tt := range tests {
+		st, err := set.Status(context.Background(), tt.code)
+		require.NoError(t, err)
+		assert.Equal(t, tt.wantStatus, st, tt.code)
+
+		ok, err := set.Contains(context.Background(), tt.code)
+		require.NoError(t, err)
+		assert.Equal(t, tt.wantStatus != "", ok, tt.code)
+	}
+	assert.Equal(t, 1, src.calls)
+}
+
+// staticCodeLister は固定の一覧を返す CodeStatusLister です。
+type staticCodeLister []CodeStatus
+
+func (s staticCodeLister) ListVisibleCodes(context.Context) ([]CodeStatus, error) {
+	return s, nil
+}
+
+// TestActiveCodeSet_Lookup は名前を locale の表記にして返し、参照できない銘柄は ok=false になることを検証します。
+func TestActiveCodeSet_Lookup(t *testing.T) {
+	t.Parallel()
+
+	set := NewActiveCodeSet(staticCodeLister{
+		{Code: "7203.T", Status: StatusActive, Name: "トヨタ自動車", Market: "TSE", Names: map[string]string{"en": "Toyota Motor"}},
+		{Code: "TWTR", Status: StatusDelisted, Name: "Twitter Inc.", Market: "NYSE"},
+	}, time.Minute)
+	ctx := context.Background()
+
+	tests := []struct {
+		code, locale string
+		want         CodeStatus
+		wantOK       bool
+	}{
+		{"7203.T", "ja", CodeStatus{Code: "7203.T", Status: StatusActive, Name: "トヨタ自動車", Market: "TSE"}, true},
+		{"7203.T", "en", CodeStatus{Code: "7203.T", Status: StatusActive, Name: "Toyota Motor", Market: "TSE"}, true},
+		// 名前の登録がないロケールは symbols.name
+		{"TWTR", "en", CodeStatus{Code: "TWTR", Status: StatusDelisted, Name: "Twitter Inc.", Market: "NYSE"}, true},
+		{"HIDDEN", "ja", CodeStatus{}, false},
+	}
+	for _, tt := range tests {
+		got, ok, err := set.Lookup(ctx, tt.code, tt.locale)
+		require.NoError(t, err)
+		assert.Equal(t, tt.wantOK, ok, tt.code)
+		assert.Equal(t, tt.want, got, tt.code+"/"+tt.locale)
+	}
+
+	// 返した値を書き換えてもキャッシュには影響しない
+	got, _, _ := set.Lookup(ctx, "7203.T", "en")
+	got.Name = "changed"
+	again, _, _ := set.Lookup(ctx, "7203.T", "en")
+	assert.Equal(t, "Toyota Motor", again.Name)
+}
+
+// TestActiveCodeSet_Tier は銘柄の区分を返し、区分のない行は basic として扱うことを検証します。
+func TestActiveCodeSet_Tier(t *testing.T) {
+	t.Parallel()
+
+	set := NewActiveCodeSet(staticCodeLister{
+		{Code: "AAPL", Status: StatusActive, Tier: TierBasic},
+		{Code: "NVDA", Status: StatusActive, Tier: TierPremium},
+		{Code: "TWTR", Status: StatusDelisted, Tier: TierPremium},
+		{Code: "7203.T", Status: StatusActive},
+	}, time.Minute)
+
+	tests := []struct {
+		code string
+		want Tier
+	}{
+		{"AAPL", TierBasic},
+		{"NVDA", TierPremium},
+		{"TWTR", TierPremium},
+		{"7203.T", TierBasic},
+		{"HIDDEN", ""},
+	}
+	for _, tt := range tests {
+		got, err := set.Tier(context.Background(), tt.code)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, tt.code)
+	}
+}
+
+func TestActiveCodeSet_RefreshAfterTTL(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	src := &stubCodeLister{codes: []string{"AAPL"}}
+	set := newTestActiveCodeSet(src, time.Minute, &now)
+
+	ok, err := set.Contains(context.Background(), "MSFT")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// 新規にアクティブ化された銘柄は TTL 経過後に反映される
+	src.set("AAPL", "MSFT")
+	now = now.Add(30 * time.Second)
+	ok, err = set.Contains(context.Background(), "MSFT")
+	require.NoError(t, err)
+	assert.False(t, ok, "TTL 内は古い集合を返す")
+
+	now = now.Add(31 * time.Second)
+	ok, err = set.Contains(context.Background(), "MSFT")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 2, src.calls)
+}
+
+func TestActiveCodeSet_Invalidate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	src := &stubCodeLister{codes: []string{"AAPL"}}
+	set := newTestActiveCodeSet(src, time.Hour, &now)
+
+	_, err := set.Contains(context.Background(), "AAPL")
+	require.NoError(t, err)
+
+	src.set()
+	set.Invalidate()
+
+	ok, err := set.Contains(context.Background(), "AAPL")
+	require.NoError(t, err)
+	assert.False(t, ok, "Invalidate 後は即座に再取得する")
+	assert.Equal(t, 2, src.calls)
+}
+
+func TestActiveCodeSet_PropagatesError(t *testing.T) {
+	t.Parallel()
+
+	wantErr := errors.New("db down")
+	src := &stubCodeLister{err: wantErr}
+	set := NewActiveCodeSet(src, time.Minute)
+
+	ok, err := set.Contains(context.Background(), "AAPL")
+	assert.ErrorIs(t, err, wantErr)
+	assert.False(t, ok)
+
+	// 失敗はキャッシュしない
+	_, _ = set.Contains(context.Background(), "AAPL")
+	assert.Equal(t, 2, src.calls)
+}
+
+// blockingCodeLister は release が閉じられるまで ListVisibleCodes の応答を保留するスタブです。
+type blockingCodeLister struct {
+	started chan struct{} // 呼び出しのたびに送信する
+	release chan struct{}
+	codes   func() []string
+	calls   atomic.Int32
+}
+
+func newBlockingCodeLister(codes ...string) *blockingCodeLister {
+	return &blockingCodeLister{
+		started: make(chan struct{}, 8),
+		release: make(chan struct{}),
+		codes:   func() []string { return codes },
+	}
+}
+
+func (s *blockingCodeLister) ListVisibleCodes(ctx context.Context) ([]CodeStatus, error) {
+	s.calls.Add(1)
+	s.started <- struct{}{}
+	<-s.release
+	return activeCodeStatuses(s.codes()), nil
+}
+
+// TestActiveCodeSet_ConcurrentRefresh は期限切れ時に同時に届いた Contains が 1 回の再取得を共有することを検証します。
+func TestActiveCodeSet_ConcurrentRefresh(t *testing.T) {
+	t.Parallel()
+
+	src := newBlockingCodeLister("AAPL")
+	set := NewActiveCodeSet(src, time.Minute)
+
+	const n = 32
+	var wg sync.WaitGroup
+	results := make(chan bool, n)
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := set.Contains(context.Background(), "AAPL")
+			assert.NoError(t, err)
+			results <- ok
+		}()
+	}
+	<-src.started
+	close(src.release)
+	wg.Wait()
+	close(results)
+
+	for ok := range results {
+		assert.True(t, ok)
+	}
+	assert.Equal(t, int32(1), src.calls.Load(), "同時の再取得は 1 回にまとめる")
+}
+
+// TestActiveCodeSet_InvalidateDuringRefresh は Invalidate 前に始まった再取得の結果を保存しないことを検証します。
+func TestActiveCodeSet_InvalidateDuringRefresh(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	current := []string{"AAPL"}
+	src := newBlockingCodeLister()
+	src.codes = func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return current
+	}
+	set := NewActiveCodeSet(src, time.Hour)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = set.Contains(context.Background(), "AAPL")
+	}()
+	<-src.started
+
+	// 再取得の実行中に銘柄が無効化された
+	mu.Lock()
+	current = nil
+	mu.Unlock()
+	set.Invalidate()
+	close(src.release)
+	<-done
+
+	ok, err := set.Contains(context.Background(), "AAPL")
+	require.NoError(t, err)
+	assert.False(t, ok, "Invalidate 後は新しく取得した集合を使う")
+	assert.Equal(t, int32(2), src.calls.Load())
+}
+
+// TestActiveCodeSet_CallerCanceled は待機中の呼び出し元が自身の ctx の終了で戻り、共有の再取得は続くことを検証します。
+func TestActiveCodeSet_CallerCanceled(t *testing.T) {
+	t.Parallel()
+
+	src := newBlockingCodeLister("AAPL")
+	set := NewActiveCodeSet(src, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		_, err := set.Contains(ctx, "AAPL")
+		errc <- err
+	}()
+	<-src.started
+	cancel()
+	assert.ErrorIs(t, <-errc, context.Canceled)
+
+	close(src.release)
+	require.Eventually(t, func() bool {
+		ok, err := set.Contains(context.Background(), "AAPL")
+		return err == nil && ok
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(1), src.calls.Load(), "キャンセルされた呼び出し元の再取得の結果を保存する")
+}
+
+func BenchmarkActiveCodeSet_Contains(b *testing.B) {
+	codes := make([]string, 5000)
+	for i := range codes {
+		codes[i] = fmt.Sprintf("SYM%04d", i)
+	}
+	set := NewActiveCodeSet(&stubCodeLister{codes: codes}, time.Hour)
+	ctx := context.Background()
+	if _, err := set.Contains(ctx, codes[0]); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if _, err := set.Contains(ctx, codes[i%len(codes)]); err != nil {
+				b.Fatal(err)
+			}
+			i++
+		}
+	})
+}
@@ -0,0 +1,163 @@
+package symbollist
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// FlagServeStaleOnError は DB からの一覧取得に失敗した際、最後に成功した一覧（last-known-good）を
+// 返すかを切り替えるフィーチャーフラグ名です。無効時は従来どおりエラーを返します。
+const FlagServeStaleOnError = "serve_stale_on_error"
+
+// FlagDefault はフラグ未注入時に使う既定値を返します。
+func FlagDefault(name string) bool {
+	return name == FlagServeStaleOnError
+}
+
+// FlagChecker はフィーチャーフラグの参照を抽象化します。
+// Goの慣例に従い、インターフェースは利用者側で定義します。
+type FlagChecker interface {
+	Enabled(ctx context.Context, name string) bool
+}
+
+// RedisProvider は現在利用できる Redis クライアントを返します。障害中は nil を返します。
+type RedisProvider interface {
+	Client() *redis.Client
+}
+
+// CachingRepository は Repository に last-known-good（LKG）キャッシュをデコレータパターンで追加します。
+//
+// DB からの取得に成功するたびに一覧を Redis の専用キーへ TTL なしで保存し、
+// DB 障害時はその写しを返します。銘柄一覧の変更は稀なため、障害中に古い一覧を返す方が
+// アプリ全体を使えなくするより望ましいという判断です。LKG は通常のキャッシュと異なり
+// 期限切れで消えることはなく、DB 読み取りの成功または Refresh でのみ上書きされます。
+type CachingRepository struct {
+	inner    Repository
+	rdb      *redis.Client
+	provider RedisProvider
+	key      string
+	flags    FlagChecker
+}
+
+var _ Repository = (*CachingRepository)(nil)
+
+// NewCachingRepository は Repository に LKG キャッシュを追加するデコレータを生成します。
+// key は環境の名前空間を含む LKG のキー（例: "staging:symbols:lkg"）です。
+// rdb が nil の場合は LKG を使わず inner の結果をそのまま返します。
+// flags が nil の場合は各フラグを FlagDefault の値として扱います。
+func NewCachingRepository(rdb *redis.Client, inner Repository, key string, flags FlagChecker) *CachingRepository {
+	return &CachingRepository{inner: inner, rdb: rdb, key: key, flags: flags}
+}
+
+// WithRedisProvider は Redis クライアントを呼び出しごとに p から取得するよう設定します。
+// p が nil を返す間は rdb が nil の場合と同じく LKG を読み書きしません。
+func (c *CachingRepository) WithRedisProvider(p RedisProvider) *CachingRepository {
+	c.provider = p
+	return c
+}
+
+func (c *CachingRepository) client() *redis.Client {
+	if c.provider != nil {
+		return c.provider.Client()
+	}
+	return c.rdb
+}
+
+// ListActive は DB からアクティブな銘柄を取得し、成功時は LKG を更新します。
+// 失敗時に LKG へフォールバックしないため、古いデータを許容しない用途で使います。
+func (c *CachingRepository) ListActive(ctx context.Context) ([]Symbol, error) {
+	symbols, err := c.inner.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.store(ctx, symbols)
+	return symbols, nil
+}
+
+// ListActiveLocalized は locale が既定ロケールなら ListActive（LKG を更新）、それ以外は inner の結果をそのまま返します。
+// LKG は既定ロケールの一覧のみを保持します。
+func (c *CachingRepository) ListActiveLocalized(ctx context.Context, locale string) ([]Symbol, error) {
+	if locale == "" || locale == DefaultLocale {
+		return c.ListActive(ctx)
+	}
+	return c.inner.ListActiveLocalized(ctx, locale)
+}
+
+// ListActiveOrStale は ListActive と同様に取得し、DB 障害時は LKG を返します（stale=true）。
+// FlagServeStaleOnError が無効、または LKG が存在しない場合は DB のエラーをそのまま返します。
+func (c *CachingRepository) ListActiveOrStale(ctx context.Context) ([]Symbol, bool, error) {
+	return c.ListActiveLocalizedOrStale(ctx, DefaultLocale)
+}
+
+// ListActiveLocalizedOrStale は ListActiveLocalized と同様に取得し、DB 障害時は LKG を返します（stale=true）。
+// LKG は既定ロケールの一覧のため、障害中は locale によらず既定ロケールの名前になります。
+func (c *CachingRepository) ListActiveLocalizedOrStale(ctx context.Context, locale string) ([]Symbol, bool, error) {
+	symbols, err := c.ListActiveLocalized(ctx, locale)
+	if err == nil {
+		return symbols, false, nil
+	}
+	rdb := c.client()
+	if rdb == nil || !c.enabled(ctx, FlagServeStaleOnError) {
+		return nil, false, err
+	}
+
+	stale, lkgErr := c.load(ctx, rdb)
+	if lkgErr != nil {
+		slog.WarnContext(ctx, "symbols last-known-good unavailable", "component", "cache", "error", lkgErr, "db_error", err)
+		return nil, false, err
+	}
+	slog.WarnContext(ctx, "serving last-known-good symbols due to DB error", "component", "cache", "error", err, "count", len(stale))
+	return stale, true, nil
+}
+
+// Refresh は DB から一覧を読み直して LKG を上書きします。
+// 銘柄情報を書き換えるバッチ等の後に呼び出し、障害時に返す写しを最新に保ちます。
+func (c *CachingRepository) Refresh(ctx context.Context) error {
+	_, err := c.ListActive(ctx)
+	return err
+}
+
+// store は一覧を LKG として保存します（ベストエフォート）。
+func (c *CachingRepository) store(ctx context.Context, symbols []Symbol) {
+	rdb := c.client()
+	if rdb == nil {
+		return
+	}
+	b, err := json.Marshal(symbols)
+	if err != nil {
+		return
+	}
+	// TTL なし: 通常の期限切れで LKG を失わないため
+	if err := rdb.Set(ctx, c.key, b, 0).Err(); err != nil {
+		slog.WarnContext(ctx, "failed to store symbols last-known-good", "component", "cache", "error", err)
+	}
+}
+
+// load は LKG を読み込みます。存在しない場合もエラーを返します。
+func (c *CachingRepository) load(ctx context.Context, rdb *redis.Client) ([]Symbol, error) {
+	b, err := rdb.Get(ctx, c.key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, errors.New("no last-known-good entry")
+	}
+	if err != nil {
+		return nil, err
+	}
+	var symbols []Symbol
+	if err := json.Unmarshal(b, &symbols); err != nil {
+		return nil, fmt.Errorf("corrupt last-known-good entry: %w", err)
+	}
+	return symbols, nil
+}
+
+// enabled はフラグの値を返します。flags 未注入時は FlagDefault を使います。
+func (c *CachingRepository) enabled(ctx context.Context, name string) bool {
+	if c.flags == nil {
+		return FlagDefault(name)
+	}
+	return c.flags.Enabled(ctx, name)
+}
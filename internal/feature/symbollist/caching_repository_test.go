@@ -0,0 +1,173 @@
+package symbollist
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testLKGKey = "test:symbols:lkg"
+
+// stubRepository は Repository のスタブで、err を設定すると DB 障害を模擬します。
+// names を設定すると ListActiveLocalized がその名前で Localize した一覧を返します。
+type stubRepository struct {
+	symbols []Symbol
+	names   map[string]map[string]string
+	err     error
+}
+
+func (s *stubRepository) ListActive(ctx context.Context) ([]Symbol, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.symbols, nil
+}
+
+func (s *stubRepository) ListActiveLocalized(ctx context.Context, locale string) ([]Symbol, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return Localize(s.symbols, s.names, locale), nil
+}
+
+// staticFlags は固定値を返す FlagChecker です。
+type staticFlags map[string]bool
+
+func (f staticFlags) Enabled(_ context.Context, name string) bool { return f[name] }
+
+func newTestCachingRepository(t *testing.T, inner Repository, flags FlagChecker) (*CachingRepository, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return NewCachingRepository(rdb, inner, testLKGKey, flags), mr
+}
+
+func TestCachingRepository_ServesLKGOnDBError(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	want := []Symbol{{ID: 1, Code: "AAPL", Name: "Apple Inc.", Market: "NASDAQ", Status: StatusActive}}
+	inner := &stubRepository{symbols: want}
+	repo, mr := newTestCachingRepository(t, inner, nil)
+
+	// 成功した読み取りで LKG が TTL なしで保存される
+	got, stale, err := repo.ListActiveOrStale(ctx)
+	require.NoError(t, err)
+	assert.False(t, stale)
+	assert.Equal(t, want, got)
+	require.True(t, mr.Exists(testLKGKey))
+	assert.Zero(t, mr.TTL(testLKGKey), "LKG は期限切れで消えないこと")
+
+	// DB 障害時は LKG を stale として返す
+	inner.err = errors.New("connection refused")
+	got, stale, err = repo.ListActiveOrStale(ctx)
+	require.NoError(t, err)
+	assert.True(t, stale)
+	assert.Equal(t, want, got)
+
+	// ListActive は LKG にフォールバックしない
+	_, err = repo.ListActive(ctx)
+	assert.ErrorIs(t, err, inner.err)
+}
+
+func TestCachingRepository_ListActiveLocalizedOrStale(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	inner := &stubRepository{
+		symbols: []Symbol{{Code: "7203.T", Name: "トヨタ自動車"}},
+		names:   map[string]map[string]string{"7203.T": {"en": "Toyota Motor"}},
+	}
+	repo, mr := newTestCachingRepository(t, inner, nil)
+
+	// 既定ロケール以外は inner の結果をそのまま返し、LKG には保存しない
+	got, stale, err := repo.ListActiveLocalizedOrStale(ctx, "en")
+	require.NoError(t, err)
+	assert.False(t, stale)
+	assert.Equal(t, "Toyota Motor", got[0].Name)
+	assert.False(t, mr.Exists(testLKGKey), "LKG は既定ロケールの一覧のみ保持すること")
+
+	// 既定ロケールの読み取りで LKG が保存される
+	got, _, err = repo.ListActiveLocalizedOrStale(ctx, DefaultLocale)
+	require.NoError(t, err)
+	assert.Equal(t, "トヨタ自動車", got[0].Name)
+	require.True(t, mr.Exists(testLKGKey))
+
+	// DB 障害時は locale によらず既定ロケールの LKG を返す
+	inner.err = errors.New("connection refused")
+	got, stale, err = repo.ListActiveLocalizedOrStale(ctx, "en")
+	require.NoError(t, err)
+	assert.True(t, stale)
+	assert.Equal(t, "トヨタ自動車", got[0].Name)
+}
+
+func TestCachingRepository_HardFail(t *testing.T) {
+	t.Parallel()
+
+	dbErr := errors.New("connection refused")
+	tests := []struct {
+		name     string
+		populate bool
+		corrupt  bool
+		flags    FlagChecker
+	}{
+		{name: "LKG が空"},
+		{name: "フラグ無効", populate: true, flags: staticFlags{FlagServeStaleOnError: false}},
+		{name: "LKG が破損", corrupt: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			inner := &stubRepository{symbols: []Symbol{{Code: "AAPL"}}}
+			repo, mr := newTestCachingRepository(t, inner, tt.flags)
+			if tt.populate {
+				require.NoError(t, repo.Refresh(context.Background()))
+			}
+			if tt.corrupt {
+				require.NoError(t, mr.Set(testLKGKey, "{not json"))
+			}
+			inner.err = dbErr
+
+			got, stale, err := repo.ListActiveOrStale(context.Background())
+			assert.ErrorIs(t, err, dbErr)
+			assert.False(t, stale)
+			assert.Nil(t, got)
+		})
+	}
+}
+
+func TestCachingRepository_RefreshOverwritesLKG(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	inner := &stubRepository{symbols: []Symbol{{Code: "AAPL"}}}
+	repo, _ := newTestCachingRepository(t, inner, nil)
+	require.NoError(t, repo.Refresh(ctx))
+
+	inner.symbols = []Symbol{{Code: "AAPL"}, {Code: "MSFT"}}
+	require.NoError(t, repo.Refresh(ctx))
+
+	inner.err = errors.New("connection refused")
+	got, stale, err := repo.ListActiveOrStale(ctx)
+	require.NoError(t, err)
+	assert.True(t, stale)
+	assert.Equal(t, inner.symbols, got)
+}
+
+func TestCachingRepository_NilRedis(t *testing.T) {
+	t.Parallel()
+
+	dbErr := errors.New("connection refused")
+	repo := NewCachingRepository(nil, &stubRepository{err: dbErr}, testLKGKey, nil)
+
+	_, stale, err := repo.ListActiveOrStale(context.Background())
+	assert.ErrorIs(t, err, dbErr)
+	assert.False(t, stale)
+}
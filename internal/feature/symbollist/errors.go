@@ -0,0 +1,17 @@
+package symbollist
+
+import "github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
+
+var (
+	// ErrSymbolNotFound は指定された銘柄コードが存在しない場合のエラーです。
+	ErrSymbolNotFound = apperr.New(apperr.KindNotFound, "symbol_not_found", "symbol not found")
+
+	// ErrSymbolNameNotFound は指定された銘柄・ロケールの名前が登録されていない場合のエラーです。
+	ErrSymbolNameNotFound = apperr.New(apperr.KindNotFound, "symbol_name_not_found", "symbol name not found")
+
+	// ErrInvalidSymbolName は銘柄名の値が不正（ロケールの形式・空の名前・長すぎる名前）な場合のエラーです。
+	ErrInvalidSymbolName = apperr.New(apperr.KindInvalid, "invalid_symbol_name", "invalid symbol name")
+
+	// ErrInvalidSymbolStatus は銘柄の状態の値が active / delisted / hidden のいずれでもない場合のエラーです。
+	ErrInvalidSymbolStatus = apperr.New(apperr.KindInvalid, "invalid_symbol_status", "invalid symbol status")
+)
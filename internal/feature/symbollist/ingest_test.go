@@ -70,8 +70,8 @@ func TestLogoIngestUsecase_IngestAll_Success(t *testing.T) {
 	now := time.Date(2026, 5, 3, 12, 0, 0, 0, time.UTC)
 	repo := &mockLogoSymbolRepository{
 		symbols: []Symbol{
-			{Code: "AAPL", IsActive: true},
-			{Code: "MSFT", IsActive: true},
+			{Code: "AAPL", Status: StatusActive},
+			{Code: "MSFT", Status: StatusActive},
 		},
 	}
 	provider := &mockLogoProvider{
@@ -100,8 +100,8 @@ func TestLogoIngestUsecase_IngestAll_ContinuesOnFetchFailure(t *testing.T) {
 
 	repo := &mockLogoSymbolRepository{
 		symbols: []Symbol{
-			{Code: "AAPL", IsActive: true},
-			{Code: "MSFT", IsActive: true},
+			{Code: "AAPL", Status: StatusActive},
+			{Code: "MSFT", Status: StatusActive},
 		},
 	}
 	provider := &mockLogoProvider{
@@ -127,8 +127,8 @@ func TestLogoIngestUsecase_IngestAll_UpdateFailureDoesNotStopBatch(t *testing.T)
 
 	repo := &mockLogoSymbolRepository{
 		symbols: []Symbol{
-			{Code: "AAPL", IsActive: true},
-			{Code: "MSFT", IsActive: true},
+			{Code: "AAPL", Status: StatusActive},
+			{Code: "MSFT", Status: StatusActive},
 		},
 		updateLogoURLFunc: func(ctx context.Context, code, logoURL string, updatedAt time.Time) error {
 			if code == "AAPL" {
@@ -168,8 +168,8 @@ func TestLogoIngestUsecase_IngestAll_ContextCancelled(t *testing.T) {
 
 	repo := &mockLogoSymbolRepository{
 		symbols: []Symbol{
-			{Code: "AAPL", IsActive: true},
-			{Code: "MSFT", IsActive: true},
+			{Code: "AAPL", Status: StatusActive},
+			{Code: "MSFT", Status: StatusActive},
 		},
 	}
 	provider := &mockLogoProvider{
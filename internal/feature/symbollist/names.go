@@ -0,0 +1,97 @@
+package symbollist
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// DefaultLocale は symbols.name の言語です。このロケールでは symbol_names を参照しません。
+	DefaultLocale = "ja"
+	// FallbackLocale は要求されたロケールの名前が登録されていない場合に次に使うロケールです。
+	FallbackLocale = "en"
+	// MaxSymbolNameLength は銘柄名の最大文字数です（symbol_names.name VARCHAR(255)）。
+	MaxSymbolNameLength = 255
+)
+
+// SupportedLocales は銘柄名を返せるロケールです（Accept-Language の交渉対象。先頭が既定）。
+// symbol_names には任意のロケールを登録できますが、ここに含まれないロケールは交渉で選ばれません。
+var SupportedLocales = []string{DefaultLocale, FallbackLocale}
+
+// localePattern はロケールとして受け付ける形式（ISO 639 の言語コード、小文字 2〜3 文字）です。
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}$`)
+
+// SymbolName は既定ロケール以外での銘柄名です。
+type SymbolName struct {
+	SymbolCode string
+	Locale     string
+	Name       string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// NormalizeName は銘柄名を NFKC で正規化し、前後の空白（全角スペースを含む）を取り除きます。
+// 全角英数字・全角括弧は半角に、半角カナは全角にそろえ、取り込み元による表記の揺れを保存前に吸収します。
+func NormalizeName(name string) string {
+	return strings.TrimSpace(norm.NFKC.String(name))
+}
+
+// Normalize はロケールを小文字に、名前を NormalizeName で正規化した SymbolName を返します。
+func (n SymbolName) Normalize() SymbolName {
+	n.SymbolCode = strings.TrimSpace(n.SymbolCode)
+	n.Locale = strings.ToLower(strings.TrimSpace(n.Locale))
+	n.Name = NormalizeName(n.Name)
+	return n
+}
+
+// Validate は銘柄名として登録できるかを検証します。不正な場合は ErrInvalidSymbolName をラップしたエラーを返します。
+// 既定ロケールの名前は symbols.name で管理するため登録できません。
+func (n SymbolName) Validate() error {
+	if n.SymbolCode == "" {
+		return fmt.Errorf("%w: missing symbol", ErrInvalidSymbolName)
+	}
+	if !localePattern.MatchString(n.Locale) {
+		return fmt.Errorf("%w: locale must be a lowercase language code", ErrInvalidSymbolName)
+	}
+	if n.Locale == DefaultLocale {
+		return fmt.Errorf("%w: %s names are managed in symbols.name", ErrInvalidSymbolName, DefaultLocale)
+	}
+	if n.Name == "" {
+		return fmt.Errorf("%w: missing name", ErrInvalidSymbolName)
+	}
+	if len([]rune(n.Name)) > MaxSymbolNameLength {
+		return fmt.Errorf("%w: name must be at most %d characters", ErrInvalidSymbolName, MaxSymbolNameLength)
+	}
+	return nil
+}
+
+// LocalizedName は locale で表示する銘柄名を返します。
+// names（ロケール → 名前）から 要求ロケール → FallbackLocale → defaultName（symbols.name）の順に最初に見つかった名前を使います。
+// locale が空または DefaultLocale の場合は names を参照せず defaultName を返します。
+func LocalizedName(defaultName string, names map[string]string, locale string) string {
+	if locale == "" || locale == DefaultLocale {
+		return defaultName
+	}
+	if name := names[locale]; name != "" {
+		return name
+	}
+	if name := names[FallbackLocale]; name != "" {
+		return name
+	}
+	return defaultName
+}
+
+// Localize は symbols の Name を LocalizedName で locale の名前に置き換えた新しいスライスを返します。
+// names は銘柄コード → ロケール → 名前の対応です。symbols は変更しません。
+func Localize(symbols []Symbol, names map[string]map[string]string, locale string) []Symbol {
+	out := make([]Symbol, len(symbols))
+	for i, s := range symbols {
+		s.Name = LocalizedName(s.Name, names[s.Code], locale)
+		out[i] = s
+	}
+	return out
+}
@@ -0,0 +1,231 @@
+package symbollist
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// DefaultNamesCSVBatchSize は銘柄名の CSV 取り込みで UpsertNames 1 回に書き込む行数です。
+	DefaultNamesCSVBatchSize = 500
+	// DefaultNamesCSVMaxRowErrors は NamesCSVResult に保持する不正行の上限数です。
+	DefaultNamesCSVMaxRowErrors = 100
+)
+
+// namesCSVColumnAliases はヘッダー名（小文字・前後空白除去後）から列種別への対応です。
+var namesCSVColumnAliases = map[string]string{
+	"symbol":      "symbol",
+	"symbol_code": "symbol",
+	"code":        "symbol",
+	"locale":      "locale",
+	"lang":        "locale",
+	"name":        "name",
+}
+
+// namesCSVRequiredColumns は銘柄名の CSV に必須の列です。
+var namesCSVRequiredColumns = []string{"symbol", "locale", "name"}
+
+// NameWriter は銘柄名の CSV 取り込みの書き込み先です。repository が実装します。
+type NameWriter interface {
+	// ListActiveCodes はアクティブな銘柄のコードを返します（存在しない銘柄の行をスキップするため）。
+	ListActiveCodes(ctx context.Context) ([]string, error)
+	// UpsertNames は names を 1 つのトランザクションで登録・上書きします。
+	UpsertNames(ctx context.Context, names []SymbolName) error
+}
+
+// NamesCSVOptions は ImportNamesCSV の取り込み設定です。
+type NamesCSVOptions struct {
+	BatchSize int  // UpsertNames 1 回あたりの行数。0 以下なら DefaultNamesCSVBatchSize
+	MaxErrors int  // 保持する不正行の上限。0 以下なら DefaultNamesCSVMaxRowErrors
+	Strict    bool // true なら最初の不正行で中断する
+}
+
+// NamesCSVRowError は取り込めなかった行とその理由です。Line はヘッダーを 1 行目とする行番号です。
+type NamesCSVRowError struct {
+	Line   int
+	Reason string
+}
+
+func (e NamesCSVRowError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Reason)
+}
+
+// NamesCSVResult は ImportNamesCSV の集計結果です。
+// Errors は先頭から MaxErrors 件までで、Skipped は上限を超えた分も含む総数です。
+type NamesCSVResult struct {
+	Read     int // 読み込んだデータ行数（ヘッダーを除く）
+	Upserted int // 登録・上書きした行数
+	Skipped  int // 不正・重複・未知の銘柄によりスキップした行数
+	Errors   []NamesCSVRowError
+}
+
+// ImportNamesCSV は symbol,locale,name の CSV を読み込み、検証したうえで repo にバッチ Upsert します。
+//
+// 1 行目はヘッダーとして扱い、列順は列名から検出します（symbol_code / code、lang も可）。
+// 各行は SymbolName.Validate で検証し、アクティブでない銘柄の行とファイル内で重複する銘柄・ロケールの行
+// （最初の行を採用）はスキップして NamesCSVResult に記録します。opts.Strict が true の場合は
+// 最初の不正行で NamesCSVRowError を返して中断します（それまでのバッチは書き込み済み）。
+func ImportNamesCSV(ctx context.Context, r io.Reader, repo NameWriter, opts NamesCSVOptions) (NamesCSVResult, error) {
+	opts = opts.withDefaults()
+	var result NamesCSVResult
+
+	codes, err := repo.ListActiveCodes(ctx)
+	if err != nil {
+		return result, fmt.Errorf("csv: list symbols: %w", err)
+	}
+	active := make(map[string]struct{}, len(codes))
+	for _, c := range codes {
+		active[c] = struct{}{}
+	}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1 // 列数の不一致は行単位のエラーとして扱う
+
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return result, errors.New("csv: empty input")
+		}
+		return result, fmt.Errorf("csv: read header: %w", err)
+	}
+	cols, err := detectNamesCSVColumns(header)
+	if err != nil {
+		return result, err
+	}
+
+	batch := make([]SymbolName, 0, opts.BatchSize)
+	seen := make(map[[2]string]struct{})
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := repo.UpsertNames(ctx, batch); err != nil {
+			return fmt.Errorf("csv: upsert batch ending at row %d: %w", result.Read, err)
+		}
+		result.Upserted += len(batch)
+		batch = make([]SymbolName, 0, opts.BatchSize)
+		return nil
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return result, fmt.Errorf("csv: read: %w", err)
+			}
+			result.Read++
+			if err := result.skip(opts, NamesCSVRowError{Line: parseErr.StartLine, Reason: parseErr.Err.Error()}); err != nil {
+				return result, err
+			}
+			continue
+		}
+		result.Read++
+		line, _ := cr.FieldPos(0)
+
+		n, err := parseNamesCSVRow(record, cols)
+		if err == nil {
+			err = n.Validate()
+		}
+		key := [2]string{n.SymbolCode, n.Locale}
+		if err == nil {
+			if _, ok := active[n.SymbolCode]; !ok {
+				err = fmt.Errorf("unknown or inactive symbol %q", n.SymbolCode)
+			} else if _, dup := seen[key]; dup {
+				err = fmt.Errorf("duplicate name for %s/%s", n.SymbolCode, n.Locale)
+			}
+		}
+		if err != nil {
+			if err := result.skip(opts, NamesCSVRowError{Line: line, Reason: err.Error()}); err != nil {
+				return result, err
+			}
+			continue
+		}
+
+		seen[key] = struct{}{}
+		batch = append(batch, n)
+		if len(batch) >= opts.BatchSize {
+			if err := flush(); err != nil {
+				return result, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// skip は不正行を記録します。Strict モードでは rowErr を返して取り込みを中断させます。
+func (r *NamesCSVResult) skip(opts NamesCSVOptions, rowErr NamesCSVRowError) error {
+	r.Skipped++
+	if len(r.Errors) < opts.MaxErrors {
+		r.Errors = append(r.Errors, rowErr)
+	}
+	if opts.Strict {
+		return rowErr
+	}
+	return nil
+}
+
+func (o NamesCSVOptions) withDefaults() NamesCSVOptions {
+	if o.BatchSize <= 0 {
+		o.BatchSize = DefaultNamesCSVBatchSize
+	}
+	if o.MaxErrors <= 0 {
+		o.MaxErrors = DefaultNamesCSVMaxRowErrors
+	}
+	return o
+}
+
+// detectNamesCSVColumns はヘッダー行から列種別ごとの列インデックスを返します。
+// 必須列（symbol / locale / name）が欠けている、または同じ種別の列が重複している場合はエラーを返します。
+func detectNamesCSVColumns(header []string) (map[string]int, error) {
+	cols := make(map[string]int, len(namesCSVRequiredColumns))
+	for i, name := range header {
+		// Excel 出力の UTF-8 BOM を除去する
+		key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		kind, ok := namesCSVColumnAliases[key]
+		if !ok {
+			continue
+		}
+		if _, dup := cols[kind]; dup {
+			return nil, fmt.Errorf("csv: duplicate %s column %q", kind, name)
+		}
+		cols[kind] = i
+	}
+	var missing []string
+	for _, kind := range namesCSVRequiredColumns {
+		if _, ok := cols[kind]; !ok {
+			missing = append(missing, kind)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("csv: header missing columns: %s", strings.Join(missing, ", "))
+	}
+	return cols, nil
+}
+
+// parseNamesCSVRow は 1 行を正規化した SymbolName に変換します。
+func parseNamesCSVRow(record []string, cols map[string]int) (SymbolName, error) {
+	for _, i := range cols {
+		if i >= len(record) {
+			return SymbolName{}, fmt.Errorf("expected at least %d columns, got %d", i+1, len(record))
+		}
+	}
+	return SymbolName{
+		SymbolCode: record[cols["symbol"]],
+		Locale:     record[cols["locale"]],
+		Name:       record[cols["name"]],
+	}.Normalize(), nil
+}
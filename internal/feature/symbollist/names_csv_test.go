@@ -0,0 +1,125 @@
+package symbollist
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubNameWriter は NameWriter のスタブで、書き込まれたバッチを保持します。
+type stubNameWriter struct {
+	codes   []string
+	batches [][]SymbolName
+	err     error
+}
+
+func (s *stubNameWriter) ListActiveCodes(context.Context) ([]string, error) { return s.codes, nil }
+
+func (s *stubNameWriter) UpsertNames(_ context.Context, names []SymbolName) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.batches = append(s.batches, names)
+	return nil
+}
+
+func TestImportNamesCSV(t *testing.T) {
+	t.Parallel()
+
+	input := "\ufeffCode,Lang,Name,Note\n" +
+		"7203.T,en,Toyota Motor,\n" +
+		"7203.T,EN,Toyota (dup),\n" + // ロケールの大文字小文字を区別せず重複
+		"6758.T,en, Sony Group ,\n" +
+		"NOPE,en,Unknown,\n" + // 未知の銘柄
+		"6758.T,ja,ソニー,\n" + // 既定ロケールは登録不可
+		"6758.T,ko\n" // 列不足
+	w := &stubNameWriter{codes: []string{"6758.T", "7203.T"}}
+
+	res, err := ImportNamesCSV(context.Background(), strings.NewReader(input), w, NamesCSVOptions{BatchSize: 1})
+	require.NoError(t, err)
+
+	assert.Equal(t, 6, res.Read)
+	assert.Equal(t, 2, res.Upserted)
+	assert.Equal(t, 4, res.Skipped)
+	require.Len(t, res.Errors, 4)
+	assert.Equal(t, 3, res.Errors[0].Line)
+	assert.Contains(t, res.Errors[0].Reason, "duplicate")
+	assert.Contains(t, res.Errors[1].Reason, "unknown or inactive symbol")
+	assert.Equal(t, [][]SymbolName{
+		{{SymbolCode: "7203.T", Locale: "en", Name: "Toyota Motor"}},
+		{{SymbolCode: "6758.T", Locale: "en", Name: "Sony Group"}},
+	}, w.batches)
+}
+
+// TestImportNamesCSV_Unicode は全角記号・半角カナ等を含む名前が NFKC で正規化されて取り込まれることを検証します。
+func TestImportNamesCSV_Unicode(t *testing.T) {
+	t.Parallel()
+
+	input := "symbol,locale,name\n" +
+		"7203.T,zh,丰田汽车（中国）\n" +
+		"6758.T,en,ＳＯＮＹ　Ｇｒｏｕｐ\n" +
+		"9984.T,ko,\u3000소프트뱅크 그룹\u3000\n" +
+		"9983.T,en,ﾌｧｰｽﾄﾘﾃｲﾘﾝｸﾞ\n"
+	w := &stubNameWriter{codes: []string{"6758.T", "7203.T", "9983.T", "9984.T"}}
+
+	res, err := ImportNamesCSV(context.Background(), strings.NewReader(input), w, NamesCSVOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 4, res.Upserted)
+	assert.Equal(t, [][]SymbolName{{
+		{SymbolCode: "7203.T", Locale: "zh", Name: "丰田汽车(中国)"},
+		{SymbolCode: "6758.T", Locale: "en", Name: "SONY Group"},
+		{SymbolCode: "9984.T", Locale: "ko", Name: "소프트뱅크 그룹"},
+		{SymbolCode: "9983.T", Locale: "en", Name: "ファーストリテイリング"},
+	}}, w.batches)
+}
+
+func TestImportNamesCSV_Strict(t *testing.T) {
+	t.Parallel()
+
+	input := "symbol,locale,name\n7203.T,en,Toyota Motor\nNOPE,en,Unknown\n6758.T,en,Sony Group\n"
+	w := &stubNameWriter{codes: []string{"6758.T", "7203.T"}}
+
+	res, err := ImportNamesCSV(context.Background(), strings.NewReader(input), w, NamesCSVOptions{Strict: true})
+
+	var rowErr NamesCSVRowError
+	require.ErrorAs(t, err, &rowErr)
+	assert.Equal(t, 3, rowErr.Line)
+	assert.Equal(t, 0, res.Upserted, "中断前の行はバッチに溜まったまま書き込まない")
+	assert.Empty(t, w.batches)
+}
+
+func TestImportNamesCSV_HeaderErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "empty", input: "", want: "empty input"},
+		{name: "missing column", input: "symbol,name\n", want: "missing columns: locale"},
+		{name: "duplicate column", input: "symbol,code,locale,name\n", want: "duplicate symbol column"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := ImportNamesCSV(context.Background(), strings.NewReader(tt.input), &stubNameWriter{}, NamesCSVOptions{})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestImportNamesCSV_WriteError(t *testing.T) {
+	t.Parallel()
+
+	dbErr := errors.New("connection refused")
+	w := &stubNameWriter{codes: []string{"7203.T"}, err: dbErr}
+
+	_, err := ImportNamesCSV(context.Background(), strings.NewReader("symbol,locale,name\n7203.T,en,Toyota Motor\n"), w, NamesCSVOptions{})
+	assert.ErrorIs(t, err, dbErr)
+}
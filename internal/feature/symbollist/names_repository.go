@@ -0,0 +1,105 @@
+package symbollist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist/sqlc"
+)
+
+// pgForeignKeyViolation は外部キー制約違反の SQLSTATE です（存在しない銘柄への名前の登録）。
+const pgForeignKeyViolation = "23503"
+
+var _ NameRepository = (*repository)(nil)
+
+// ListNames は銘柄 code に登録されている名前をロケール順に返します。
+func (r *repository) ListNames(ctx context.Context, code string) ([]SymbolName, error) {
+	rows, err := r.q.ListSymbolNames(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]SymbolName, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, symbolNameFromSQLC(row))
+	}
+	return out, nil
+}
+
+// UpsertName は銘柄・ロケールの名前を登録し、登録済みなら上書きします。
+// 銘柄が存在しない場合は ErrSymbolNotFound を返します。
+func (r *repository) UpsertName(ctx context.Context, n SymbolName) (SymbolName, error) {
+	row, err := r.q.UpsertSymbolName(ctx, symbollistsqlc.UpsertSymbolNameParams{
+		SymbolCode: n.SymbolCode,
+		Locale:     n.Locale,
+		Name:       n.Name,
+	})
+	if err != nil {
+		return SymbolName{}, mapNamePGErr(err)
+	}
+	return symbolNameFromSQLC(row), nil
+}
+
+// UpsertNames は names を 1 つのトランザクションで登録・上書きします（CSV 取り込み用）。
+// いずれかの銘柄が存在しない場合は ErrSymbolNotFound を返し、何も書き込みません。
+func (r *repository) UpsertNames(ctx context.Context, names []SymbolName) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback()
+		}
+	}()
+
+	qtx := r.q.WithTx(tx)
+	for _, n := range names {
+		if _, err := qtx.UpsertSymbolName(ctx, symbollistsqlc.UpsertSymbolNameParams{
+			SymbolCode: n.SymbolCode,
+			Locale:     n.Locale,
+			Name:       n.Name,
+		}); err != nil {
+			return fmt.Errorf("upsert %s/%s: %w", n.SymbolCode, n.Locale, mapNamePGErr(err))
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	committed = true
+	return nil
+}
+
+// DeleteName は銘柄・ロケールの名前を削除します。登録されていない場合は ErrSymbolNameNotFound を返します。
+func (r *repository) DeleteName(ctx context.Context, code, locale string) error {
+	n, err := r.q.DeleteSymbolName(ctx, symbollistsqlc.DeleteSymbolNameParams{SymbolCode: code, Locale: locale})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrSymbolNameNotFound
+	}
+	return nil
+}
+
+// mapNamePGErr は外部キー制約違反（存在しない銘柄）を ErrSymbolNotFound に変換します。
+func mapNamePGErr(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+		return ErrSymbolNotFound
+	}
+	return err
+}
+
+func symbolNameFromSQLC(row symbollistsqlc.SymbolName) SymbolName {
+	return SymbolName{
+		SymbolCode: row.SymbolCode,
+		Locale:     row.Locale,
+		Name:       row.Name,
+		CreatedAt:  row.CreatedAt,
+		UpdatedAt:  row.UpdatedAt,
+	}
+}
@@ -0,0 +1,115 @@
+package symbollist
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	infradb "github.com/UCHIDAnobuhiro/stock-backend/internal/infra/db"
+)
+
+func TestSymbolRepository_ListActiveLocalized(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	seedSymbol(t, db, "7203.T", "トヨタ自動車", "TSE", true)
+	seedSymbol(t, db, "6758.T", "ソニーグループ", "TSE", true)
+	seedSymbol(t, db, "9984.T", "ソフトバンクグループ", "TSE", true)
+	require.NoError(t, repo.UpsertNames(ctx, []SymbolName{
+		{SymbolCode: "7203.T", Locale: "en", Name: "Toyota Motor"},
+		{SymbolCode: "7203.T", Locale: "ko", Name: "도요타"},
+		{SymbolCode: "6758.T", Locale: "en", Name: "Sony Group"},
+	}))
+
+	names := func(symbols []Symbol) map[string]string {
+		out := make(map[string]string, len(symbols))
+		for _, s := range symbols {
+			out[s.Code] = s.Name
+		}
+		return out
+	}
+
+	ko, err := repo.ListActiveLocalized(ctx, "ko")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"7203.T": "도요타",
+		"6758.T": "Sony Group", // en にフォールバック
+		"9984.T": "ソフトバンクグループ", // 既定の名前にフォールバック
+	}, names(ko))
+
+	ja, err := repo.ListActiveLocalized(ctx, DefaultLocale)
+	require.NoError(t, err)
+	assert.Equal(t, "トヨタ自動車", names(ja)["7203.T"])
+}
+
+func TestSymbolRepository_NameCRUD(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	seedSymbol(t, db, "7203.T", "トヨタ自動車", "TSE", true)
+
+	_, err := repo.UpsertName(ctx, SymbolName{SymbolCode: "7203.T", Locale: "en", Name: "Toyota"})
+	require.NoError(t, err)
+	updated, err := repo.UpsertName(ctx, SymbolName{SymbolCode: "7203.T", Locale: "en", Name: "Toyota Motor"})
+	require.NoError(t, err)
+	assert.Equal(t, "Toyota Motor", updated.Name)
+
+	list, err := repo.ListNames(ctx, "7203.T")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "Toyota Motor", list[0].Name)
+
+	require.NoError(t, repo.DeleteName(ctx, "7203.T", "en"))
+	assert.ErrorIs(t, repo.DeleteName(ctx, "7203.T", "en"), ErrSymbolNameNotFound)
+
+	_, err = repo.UpsertName(ctx, SymbolName{SymbolCode: "MISSING", Locale: "en", Name: "Missing"})
+	assert.ErrorIs(t, err, ErrSymbolNotFound)
+}
+
+// TestSymbolRepository_UnicodeRoundTrip は全角記号・結合文字を含む名前が PostgreSQL を往復しても変わらないことを検証します。
+func TestSymbolRepository_UnicodeRoundTrip(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	require.NoError(t, infradb.CheckEncoding(ctx, db))
+
+	syms := []Symbol{
+		{Code: "9432.T", Name: NormalizeName("ＮＴＴ（日本電信電話）"), Market: "TSE", Timezone: "Asia/Tokyo"},
+		{Code: "9983.T", Name: NormalizeName("ﾌｧｰｽﾄﾘﾃｲﾘﾝｸﾞ"), Market: "TSE", Timezone: "Asia/Tokyo"},
+		{Code: "9020.T", Name: "東日本旅客鉄道〜JR東日本・グループ", Market: "TSE", Timezone: "Asia/Tokyo"},
+		{Code: "4755.T", Name: "楽天グループ🚀", Market: "TSE", Timezone: "Asia/Tokyo"}, // 4 バイトの文字
+	}
+	_, err := repo.UpsertSymbols(ctx, syms)
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `UPDATE symbols SET is_active = TRUE`)
+	require.NoError(t, err)
+	require.NoError(t, repo.UpsertNames(ctx, []SymbolName{
+		SymbolName{SymbolCode: "9432.T", Locale: "zh", Name: "日本电信电话（NTT）"}.Normalize(),
+		{SymbolCode: "9432.T", Locale: "ko", Name: "일본전신전화"},
+	}))
+
+	got, err := repo.ListActive(ctx)
+	require.NoError(t, err)
+	byCode := make(map[string]string, len(got))
+	for _, s := range got {
+		byCode[s.Code] = s.Name
+	}
+	assert.Equal(t, map[string]string{
+		"9432.T": "NTT(日本電信電話)",
+		"9983.T": "ファーストリテイリング",
+		"9020.T": "東日本旅客鉄道〜JR東日本・グループ",
+		"4755.T": "楽天グループ🚀",
+	}, byCode)
+
+	names, err := repo.ListNames(ctx, "9432.T")
+	require.NoError(t, err)
+	require.Len(t, names, 2)
+	assert.Equal(t, "일본전신전화", names[0].Name)
+	assert.Equal(t, "日本电信电话(NTT)", names[1].Name)
+}
@@ -0,0 +1,107 @@
+package symbollist
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalizedName(t *testing.T) {
+	t.Parallel()
+
+	const defaultName = "トヨタ自動車"
+	tests := []struct {
+		name   string
+		names  map[string]string
+		locale string
+		want   string
+	}{
+		{name: "requested locale", names: map[string]string{"en": "Toyota Motor", "ko": "도요타"}, locale: "ko", want: "도요타"},
+		{name: "falls back to en", names: map[string]string{"en": "Toyota Motor"}, locale: "ko", want: "Toyota Motor"},
+		{name: "falls back to default", names: map[string]string{"zh": "丰田汽车"}, locale: "ko", want: defaultName},
+		{name: "no names", names: nil, locale: "en", want: defaultName},
+		{name: "empty name skipped", names: map[string]string{"ko": "", "en": "Toyota Motor"}, locale: "ko", want: "Toyota Motor"},
+		{name: "default locale ignores names", names: map[string]string{"ja": "トヨタ", "en": "Toyota Motor"}, locale: DefaultLocale, want: defaultName},
+		{name: "empty locale is default", names: map[string]string{"en": "Toyota Motor"}, locale: "", want: defaultName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, LocalizedName(defaultName, tt.names, tt.locale))
+		})
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	t.Parallel()
+
+	in := []Symbol{{Code: "7203.T", Name: "トヨタ自動車"}, {Code: "6758.T", Name: "ソニーグループ"}}
+	got := Localize(in, map[string]map[string]string{"7203.T": {"en": "Toyota Motor"}}, "en")
+
+	assert.Equal(t, "Toyota Motor", got[0].Name)
+	assert.Equal(t, "ソニーグループ", got[1].Name, "名前がない銘柄は既定の名前")
+	assert.Equal(t, "トヨタ自動車", in[0].Name, "入力は変更しない")
+}
+
+func TestSymbolName_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		in      SymbolName
+		wantErr bool
+	}{
+		{name: "valid", in: SymbolName{SymbolCode: "7203.T", Locale: "en", Name: "Toyota Motor"}},
+		{name: "three letter locale", in: SymbolName{SymbolCode: "7203.T", Locale: "fil", Name: "Toyota"}},
+		{name: "missing symbol", in: SymbolName{Locale: "en", Name: "Toyota Motor"}, wantErr: true},
+		{name: "region tag", in: SymbolName{SymbolCode: "7203.T", Locale: "en-us", Name: "Toyota Motor"}, wantErr: true},
+		{name: "default locale", in: SymbolName{SymbolCode: "7203.T", Locale: DefaultLocale, Name: "トヨタ"}, wantErr: true},
+		{name: "empty name", in: SymbolName{SymbolCode: "7203.T", Locale: "en"}, wantErr: true},
+		{name: "too long", in: SymbolName{SymbolCode: "7203.T", Locale: "en", Name: strings.Repeat("あ", MaxSymbolNameLength+1)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.in.Validate()
+			if tt.wantErr {
+				assert.True(t, errors.Is(err, ErrInvalidSymbolName), "err = %v", err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSymbolName_Normalize(t *testing.T) {
+	t.Parallel()
+
+	got := SymbolName{SymbolCode: " 7203.T ", Locale: " EN ", Name: "  Toyota Motor "}.Normalize()
+	assert.Equal(t, SymbolName{SymbolCode: "7203.T", Locale: "en", Name: "Toyota Motor"}, got)
+}
+
+func TestNormalizeName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "full-width parentheses", in: "トヨタ自動車株式会社（TOYOTA）", want: "トヨタ自動車株式会社(TOYOTA)"},
+		{name: "parenthesized kabu", in: "㈱ファーストリテイリング", want: "(株)ファーストリテイリング"},
+		{name: "full-width alphanumerics", in: "ＫＤＤＩ", want: "KDDI"},
+		{name: "half-width katakana", in: "ｿﾌﾄﾊﾞﾝｸｸﾞﾙｰﾌﾟ", want: "ソフトバンクグループ"},
+		{name: "decomposed dakuten", in: "ソニーク\u3099ループ", want: "ソニーグループ"},
+		{name: "wave dash kept", in: "東京〜大阪", want: "東京〜大阪"},
+		{name: "ideographic space trimmed", in: "\u3000任天堂\u3000", want: "任天堂"},
+		{name: "hangul unchanged", in: "도요타", want: "도요타"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, NormalizeName(tt.in))
+		})
+	}
+}
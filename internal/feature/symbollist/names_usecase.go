@@ -0,0 +1,46 @@
+package symbollist
+
+import (
+	"context"
+	"strings"
+)
+
+// NameRepository は管理者向けの銘柄名（既定ロケール以外）の登録・削除を抽象化します。
+// Goの慣例に従い、インターフェースは利用者（usecase）側で定義します。
+type NameRepository interface {
+	ListNames(ctx context.Context, code string) ([]SymbolName, error)
+	UpsertName(ctx context.Context, n SymbolName) (SymbolName, error)
+	DeleteName(ctx context.Context, code, locale string) error
+}
+
+// NameUsecase は銘柄名の多言語表記を管理者が登録・変更するためのユースケースです。
+// 多言語の一覧はキャッシュしないため（LKG は既定ロケールのみ）、変更時の無効化は不要です。
+type NameUsecase struct {
+	repo NameRepository
+}
+
+// NewNameUsecase は NameUsecase の新しいインスタンスを生成します。
+func NewNameUsecase(repo NameRepository) *NameUsecase {
+	return &NameUsecase{repo: repo}
+}
+
+// List は銘柄 code に登録されている名前をロケール順に返します。
+func (u *NameUsecase) List(ctx context.Context, code string) ([]SymbolName, error) {
+	return u.repo.ListNames(ctx, strings.TrimSpace(code))
+}
+
+// Put は銘柄・ロケールの名前を登録し、登録済みなら上書きします。
+// 値が不正な場合は ErrInvalidSymbolName、銘柄が存在しない場合は ErrSymbolNotFound を返します。
+func (u *NameUsecase) Put(ctx context.Context, n SymbolName) (SymbolName, error) {
+	n = n.Normalize()
+	if err := n.Validate(); err != nil {
+		return SymbolName{}, err
+	}
+	return u.repo.UpsertName(ctx, n)
+}
+
+// Delete は銘柄・ロケールの名前を削除します。登録されていない場合は ErrSymbolNameNotFound を返します。
+func (u *NameUsecase) Delete(ctx context.Context, code, locale string) error {
+	n := SymbolName{SymbolCode: code, Locale: locale}.Normalize()
+	return u.repo.DeleteName(ctx, n.SymbolCode, n.Locale)
+}
@@ -0,0 +1,58 @@
+package symbollist
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubNameRepository は NameRepository のスタブで、渡された値を保持します。
+type stubNameRepository struct {
+	upserted SymbolName
+	deleted  [2]string
+}
+
+func (s *stubNameRepository) ListNames(context.Context, string) ([]SymbolName, error) {
+	return nil, nil
+}
+
+func (s *stubNameRepository) UpsertName(_ context.Context, n SymbolName) (SymbolName, error) {
+	s.upserted = n
+	return n, nil
+}
+
+func (s *stubNameRepository) DeleteName(_ context.Context, code, locale string) error {
+	s.deleted = [2]string{code, locale}
+	return nil
+}
+
+func TestNameUsecase_Put(t *testing.T) {
+	t.Parallel()
+
+	t.Run("normalizes before saving", func(t *testing.T) {
+		t.Parallel()
+		repo := &stubNameRepository{}
+		_, err := NewNameUsecase(repo).Put(context.Background(), SymbolName{SymbolCode: "7203.T", Locale: "EN", Name: " Toyota Motor "})
+		require.NoError(t, err)
+		assert.Equal(t, SymbolName{SymbolCode: "7203.T", Locale: "en", Name: "Toyota Motor"}, repo.upserted)
+	})
+
+	t.Run("rejects invalid names", func(t *testing.T) {
+		t.Parallel()
+		repo := &stubNameRepository{}
+		_, err := NewNameUsecase(repo).Put(context.Background(), SymbolName{SymbolCode: "7203.T", Locale: "ja", Name: "トヨタ"})
+		assert.True(t, errors.Is(err, ErrInvalidSymbolName), "err = %v", err)
+		assert.Zero(t, repo.upserted)
+	})
+}
+
+func TestNameUsecase_Delete_NormalizesLocale(t *testing.T) {
+	t.Parallel()
+
+	repo := &stubNameRepository{}
+	require.NoError(t, NewNameUsecase(repo).Delete(context.Background(), "7203.T", "EN"))
+	assert.Equal(t, [2]string{"7203.T", "en"}, repo.deleted)
+}
@@ -3,6 +3,8 @@ package symbollist
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"log/slog"
 	"time"
 
@@ -18,6 +20,10 @@ type repository struct {
 var (
 	_ Repository           = (*repository)(nil)
 	_ LogoSymbolRepository = (*repository)(nil)
+	_ StatusRepository     = (*repository)(nil)
+	_ CodeStatusLister     = (*repository)(nil)
+	_ SymbolFinder         = (*repository)(nil)
+	_ SymbolSearcher       = (*repository)(nil)
 )
 
 // NewRepository は指定された *sql.DB で repository の新しいインスタンスを生成します。
@@ -25,7 +31,8 @@ func NewRepository(db *sql.DB) *repository {
 	return &repository{db: db, q: symbollistsqlc.New(db)}
 }
 
-// ListActive はコード昇順にすべてのアクティブな銘柄を返します。
+// ListActive はコード昇順にすべてのアクティブな（status = active の）銘柄を返します。
+// 上場廃止・非表示の銘柄は含まないため、取り込み（ingest）と一覧の対象になります。
 func (r *repository) ListActive(ctx context.Context) ([]Symbol, error) {
 	rows, err := r.q.ListActiveSymbols(ctx)
 	if err != nil {
@@ -38,11 +45,169 @@ func (r *repository) ListActive(ctx context.Context) ([]Symbol, error) {
 	return out, nil
 }
 
-// Exists は指定されたコードの銘柄が存在するかを返します。
+// ListActiveLocalized は ListActive と同じ順にアクティブな銘柄を返し、Name を locale の名前に置き換えます
+// （フォールバックは LocalizedName）。名前は要求ロケールとフォールバックロケールの行を 1 回のクエリで
+// まとめて読み込み、銘柄ごとには問い合わせません。locale が空または DefaultLocale の場合は ListActive と同じです。
+func (r *repository) ListActiveLocalized(ctx context.Context, locale string) ([]Symbol, error) {
+	symbols, err := r.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.localize(ctx, symbols, locale)
+}
+
+// Search は code・名前・多言語の名前のいずれかが query を含む（大文字小文字を区別しない部分一致の）アクティブな銘柄を、
+// ListActive と同じコード昇順で offset 件読み飛ばして最大 limit 件返します。total はページングによらない一致件数です。
+// query が空の場合はアクティブな全銘柄が対象です（ListActive のページ）。
+func (r *repository) Search(ctx context.Context, query string, limit, offset int) ([]Symbol, int64, error) {
+	pattern := searchPattern(query)
+	total, err := r.q.CountActiveSymbolsMatching(ctx, pattern)
+	if err != nil {
+		return nil, 0, err
+	}
+	rows, err := r.q.SearchActiveSymbols(ctx, symbollistsqlc.SearchActiveSymbolsParams{
+		Pattern:  pattern,
+		MaxRows:  int32(limit),
+		SkipRows: int32(offset),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	out := make([]Symbol, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, symbolFromSQLC(row))
+	}
+	return out, total, nil
+}
+
+// SearchLocalized は Search と同じ銘柄を返し、Name を locale の名前（LocalizedName）に置き換えます。
+func (r *repository) SearchLocalized(ctx context.Context, query, locale string, limit, offset int) ([]Symbol, int64, error) {
+	symbols, total, err := r.Search(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	symbols, err = r.localize(ctx, symbols, locale)
+	if err != nil {
+		return nil, 0, err
+	}
+	return symbols, total, nil
+}
+
+// localize は symbols の Name を locale の名前に置き換えます。名前は要求ロケールとフォールバックロケールの行を
+// 1 回のクエリでまとめて読み込みます。locale が空または DefaultLocale の場合は symbols をそのまま返します。
+func (r *repository) localize(ctx context.Context, symbols []Symbol, locale string) ([]Symbol, error) {
+	if locale == "" || locale == DefaultLocale || len(symbols) == 0 {
+		return symbols, nil
+	}
+	rows, err := r.q.ListSymbolNamesByLocales(ctx, symbollistsqlc.ListSymbolNamesByLocalesParams{
+		Locale:         locale,
+		FallbackLocale: FallbackLocale,
+	})
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]map[string]string)
+	for _, row := range rows {
+		if names[row.SymbolCode] == nil {
+			names[row.SymbolCode] = make(map[string]string, 2)
+		}
+		names[row.SymbolCode][row.Locale] = row.Name
+	}
+	return Localize(symbols, names, locale), nil
+}
+
+// ListActiveCodes はアクティブな（status = active の）銘柄のコードのみをコード昇順で返します。
+// 全カラムを読み込まないよう、ListActive とは別クエリにしています。
+func (r *repository) ListActiveCodes(ctx context.Context) ([]string, error) {
+	return r.q.ListActiveSymbolCodes(ctx)
+}
+
+// ListVisibleCodes は保存済みのデータを返せる（active / delisted の）銘柄のコード・状態・名前・市場・区分をコード昇順で返します。
+// /candles 等の銘柄の存在チェック（ActiveCodeSet）の取得元です。
+func (r *repository) ListVisibleCodes(ctx context.Context) ([]CodeStatus, error) {
+	rows, err := r.q.ListVisibleSymbolStatuses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nameRows, err := r.q.ListVisibleSymbolNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]map[string]string)
+	for _, n := range nameRows {
+		if names[n.SymbolCode] == nil {
+			names[n.SymbolCode] = make(map[string]string)
+		}
+		names[n.SymbolCode][n.Locale] = n.Name
+	}
+	out := make([]CodeStatus, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, CodeStatus{
+			Code:   row.Code,
+			Status: Status(row.Status),
+			Name:   row.Name,
+			Market: row.Market,
+			Tier:   Tier(row.Tier),
+			Names:  names[row.Code],
+		})
+	}
+	return out, nil
+}
+
+// FindVisibleLocalized は銘柄 code を、Name を locale の名前（LocalizedName）にして返します。
+// 存在しない・非表示の銘柄の場合は ErrSymbolNotFound を返します。
+func (r *repository) FindVisibleLocalized(ctx context.Context, code, locale string) (Symbol, error) {
+	row, err := r.q.GetVisibleSymbol(ctx, code)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Symbol{}, ErrSymbolNotFound
+	}
+	if err != nil {
+		return Symbol{}, err
+	}
+	s := symbolFromSQLC(row)
+	if locale == "" || locale == DefaultLocale {
+		return s, nil
+	}
+	rows, err := r.q.ListSymbolNames(ctx, code)
+	if err != nil {
+		return Symbol{}, err
+	}
+	names := make(map[string]string, len(rows))
+	for _, n := range rows {
+		names[n.Locale] = n.Name
+	}
+	s.Name = LocalizedName(s.Name, names, locale)
+	return s, nil
+}
+
+// Exists は指定されたコードの銘柄が存在するかを返します。非表示（hidden）の銘柄は存在しないものとして扱います。
 func (r *repository) Exists(ctx context.Context, code string) (bool, error) {
 	return r.q.SymbolExists(ctx, code)
 }
 
+// IsActive は指定されたコードの銘柄がアクティブ（status = active）かを返します。
+// 上場廃止・非表示・存在しない銘柄は false です。
+func (r *repository) IsActive(ctx context.Context, code string) (bool, error) {
+	return r.q.SymbolIsActive(ctx, code)
+}
+
+// UpdateStatus は銘柄 code の状態と効力発生日を更新し、更新後の銘柄を返します。
+// 銘柄が存在しない場合は ErrSymbolNotFound を返します。
+func (r *repository) UpdateStatus(ctx context.Context, code string, status Status, since time.Time) (Symbol, error) {
+	row, err := r.q.UpdateSymbolStatus(ctx, symbollistsqlc.UpdateSymbolStatusParams{
+		Code:        code,
+		Status:      string(status),
+		StatusSince: sql.NullTime{Time: since, Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return Symbol{}, ErrSymbolNotFound
+	}
+	if err != nil {
+		return Symbol{}, err
+	}
+	return symbolFromSQLC(row), nil
+}
+
 // UpdateLogoURL は指定された銘柄のロゴURLと取得日時を更新します。
 // 対象行が存在しない場合はエラーとせず警告ログを出力します（バッチの続行を優先するため）。
 func (r *repository) UpdateLogoURL(ctx context.Context, code, logoURL string, updatedAt time.Time) error {
@@ -60,6 +225,47 @@ func (r *repository) UpdateLogoURL(ctx context.Context, code, logoURL string, up
 	return nil
 }
 
+// UpsertSymbols は syms（Code / Name / Market / Timezone / Currency）を 1 つのトランザクションで登録し、
+// 登録済みの銘柄は値が異なる場合だけ更新します。status / priority / ロゴは変更しません。
+// 戻り値は新規登録・更新した銘柄の数で、同じ内容で再実行した場合は 0 になります。
+func (r *repository) UpsertSymbols(ctx context.Context, syms []Symbol) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback()
+		}
+	}()
+
+	qtx := r.q.WithTx(tx)
+	changed := 0
+	for _, s := range syms {
+		var currency sql.NullString
+		if s.Currency != nil {
+			currency = sql.NullString{String: *s.Currency, Valid: true}
+		}
+		n, err := qtx.UpsertSymbol(ctx, symbollistsqlc.UpsertSymbolParams{
+			Code:     s.Code,
+			Name:     s.Name,
+			Market:   s.Market,
+			Timezone: s.Timezone,
+			Currency: currency,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("upsert %s: %w", s.Code, err)
+		}
+		changed += int(n)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit: %w", err)
+	}
+	committed = true
+	return changed, nil
+}
+
 // symbolFromSQLC は sqlc 生成モデルをドメインエンティティに変換します。
 func symbolFromSQLC(m symbollistsqlc.Symbol) Symbol {
 	var logoURL *string
@@ -72,15 +278,29 @@ func symbolFromSQLC(m symbollistsqlc.Symbol) Symbol {
 		t := m.LogoUpdatedAt.Time
 		logoUpdatedAt = &t
 	}
+	var currency *string
+	if m.Currency.Valid {
+		c := m.Currency.String
+		currency = &c
+	}
+	var statusSince *time.Time
+	if m.StatusSince.Valid {
+		t := m.StatusSince.Time
+		statusSince = &t
+	}
 	return Symbol{
 		ID:            m.ID,
 		Code:          m.Code,
 		Name:          m.Name,
 		Market:        m.Market,
 		Timezone:      m.Timezone,
+		Currency:      currency,
 		LogoURL:       logoURL,
 		LogoUpdatedAt: logoUpdatedAt,
-		IsActive:      m.IsActive,
+		Status:        Status(m.Status),
+		StatusSince:   statusSince,
+		Priority:      int(m.Priority),
+		Tier:          Tier(m.Tier),
 		CreatedAt:     m.CreatedAt,
 		UpdatedAt:     m.UpdatedAt,
 	}
@@ -28,6 +28,7 @@ func setupTestDB(t *testing.T) *sql.DB {
 }
 
 // seedSymbol はテスト用の銘柄データをデータベースに作成し、ID 付きで返します。
+// is_active だけを指定する従来の書き込みで、status はトリガーが active / hidden に読み替えます。
 func seedSymbol(t *testing.T, db *sql.DB, code, name, market string, isActive bool) *Symbol {
 	t.Helper()
 	row := db.QueryRowContext(context.Background(),
@@ -41,7 +42,10 @@ func seedSymbol(t *testing.T, db *sql.DB, code, name, market string, isActive bo
 		Name:     name,
 		Market:   market,
 		Timezone: "Asia/Tokyo",
-		IsActive: isActive,
+		Status:   StatusHidden,
+	}
+	if isActive {
+		s.Status = StatusActive
 	}
 	var id int64
 	require.NoError(t, row.Scan(&id, &s.CreatedAt, &s.UpdatedAt), "failed to seed symbol")
@@ -60,11 +64,18 @@ func seedSymbolFull(t *testing.T, db *sql.DB, s *Symbol) {
 	if s.LogoUpdatedAt != nil {
 		logoAt = sql.NullTime{Time: *s.LogoUpdatedAt, Valid: true}
 	}
+	var currency sql.NullString
+	if s.Currency != nil {
+		currency = sql.NullString{String: *s.Currency, Valid: true}
+	}
+	if s.Status == "" {
+		s.Status = StatusActive
+	}
 	row := db.QueryRowContext(context.Background(),
-		`INSERT INTO symbols (code, name, market, timezone, logo_url, logo_updated_at, is_active)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`INSERT INTO symbols (code, name, market, timezone, logo_url, logo_updated_at, status, currency)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		 RETURNING id, created_at, updated_at`,
-		s.Code, s.Name, s.Market, s.Timezone, logoURL, logoAt, s.IsActive,
+		s.Code, s.Name, s.Market, s.Timezone, logoURL, logoAt, string(s.Status), currency,
 	)
 	var id int64
 	require.NoError(t, row.Scan(&id, &s.CreatedAt, &s.UpdatedAt))
@@ -178,9 +189,11 @@ func TestSymbolRepository_ListActive_FieldValues(t *testing.T) {
 	assert.Equal(t, "Toyota Motor Corporation", got.Name)
 	assert.Equal(t, "Tokyo Stock Exchange", got.Market)
 	assert.Equal(t, "Asia/Tokyo", got.Timezone)
+	assert.Nil(t, got.Currency)
 	assert.Nil(t, got.LogoURL)
 	assert.Nil(t, got.LogoUpdatedAt)
-	assert.True(t, got.IsActive)
+	assert.Equal(t, StatusActive, got.Status)
+	assert.Nil(t, got.StatusSince)
 	assert.False(t, got.UpdatedAt.IsZero())
 }
 
@@ -191,24 +204,29 @@ func TestSymbolRepository_ListActive_LogoURL(t *testing.T) {
 
 	logoURL := "https://api.twelvedata.com/logo/apple.com"
 	logoUpdatedAt := time.Date(2026, 5, 3, 12, 0, 0, 0, time.UTC)
+	currency := "USD"
 	s := &Symbol{
 		Code:          "AAPL",
 		Name:          "Apple Inc.",
 		Market:        "NASDAQ",
 		Timezone:      "America/New_York",
+		Currency:      &currency,
 		LogoURL:       &logoURL,
 		LogoUpdatedAt: &logoUpdatedAt,
-		IsActive:      true,
+		Status:        StatusActive,
 	}
 	seedSymbolFull(t, db, s)
 
 	symbols, err := repo.ListActive(context.Background())
 	require.NoError(t, err)
 	require.Len(t, symbols, 1)
+	require.NotNil(t, symbols[0].Currency)
+	assert.Equal(t, "USD", *symbols[0].Currency)
 	require.NotNil(t, symbols[0].LogoURL)
 	require.NotNil(t, symbols[0].LogoUpdatedAt)
 	assert.Equal(t, logoURL, *symbols[0].LogoURL)
 	assert.True(t, symbols[0].LogoUpdatedAt.Equal(logoUpdatedAt))
+	assert.Equal(t, 3, symbols[0].Priority, "priority defaults to 3")
 }
 
 func TestSymbolRepository_UpdateLogoURL(t *testing.T) {
@@ -256,13 +274,21 @@ func TestSymbolRepository_Exists(t *testing.T) {
 			wantExists: true,
 		},
 		{
-			name: "success: returns true for inactive symbol",
+			name: "success: returns true for delisted symbol",
 			setupFunc: func(t *testing.T, db *sql.DB) {
-				seedSymbol(t, db, "AAPL", "Apple Inc.", "NASDAQ", false)
+				seedSymbolFull(t, db, &Symbol{Code: "AAPL", Name: "Apple Inc.", Market: "NASDAQ", Timezone: "America/New_York", Status: StatusDelisted})
 			},
 			code:       "AAPL",
 			wantExists: true,
 		},
+		{
+			name: "success: returns false for hidden symbol",
+			setupFunc: func(t *testing.T, db *sql.DB) {
+				seedSymbol(t, db, "AAPL", "Apple Inc.", "NASDAQ", false)
+			},
+			code:       "AAPL",
+			wantExists: false,
+		},
 		{
 			name:       "success: returns false for non-existent symbol",
 			setupFunc:  func(t *testing.T, db *sql.DB) {},
@@ -286,6 +312,220 @@ func TestSymbolRepository_Exists(t *testing.T) {
 	}
 }
 
+func TestSymbolRepository_ListActiveCodes(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	seedSymbol(t, db, "9984.T", "SoftBank Group", "TSE", true)
+	seedSymbol(t, db, "7203.T", "Toyota Motor", "TSE", true)
+	seedSymbol(t, db, "6758.T", "Sony Group", "TSE", false)
+
+	codes, err := repo.ListActiveCodes(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"7203.T", "9984.T"}, codes)
+}
+
+// seedStatuses は active / delisted / hidden の銘柄を 1 つずつ登録します。
+func seedStatuses(t *testing.T, db *sql.DB) {
+	t.Helper()
+	seedSymbolFull(t, db, &Symbol{Code: "AAPL", Name: "Apple Inc.", Market: "NASDAQ", Timezone: "America/New_York", Status: StatusActive})
+	seedSymbolFull(t, db, &Symbol{Code: "TWTR", Name: "Twitter Inc.", Market: "NYSE", Timezone: "America/New_York", Status: StatusDelisted})
+	seedSymbolFull(t, db, &Symbol{Code: "XXXX", Name: "Hidden Corp.", Market: "NYSE", Timezone: "America/New_York", Status: StatusHidden})
+}
+
+// TestSymbolRepository_Statuses は状態ごとに、取り込み・一覧（active のみ）と参照（active / delisted）の対象を検証します。
+func TestSymbolRepository_Statuses(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	seedStatuses(t, db)
+
+	active, err := repo.ListActive(ctx)
+	require.NoError(t, err)
+	require.Len(t, active, 1, "取り込み・一覧は active のみ")
+	assert.Equal(t, "AAPL", active[0].Code)
+
+	codes, err := repo.ListActiveCodes(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AAPL"}, codes)
+
+	for code, want := range map[string]bool{"AAPL": true, "TWTR": false, "XXXX": false, "ZZZZ": false} {
+		active, err := repo.IsActive(ctx, code)
+		require.NoError(t, err)
+		assert.Equal(t, want, active, "IsActive(%s)", code)
+	}
+
+	_, err = repo.UpsertName(ctx, SymbolName{SymbolCode: "AAPL", Locale: "en", Name: "Apple"})
+	require.NoError(t, err)
+	_, err = repo.UpsertName(ctx, SymbolName{SymbolCode: "XXXX", Locale: "en", Name: "Hidden"})
+	require.NoError(t, err)
+
+	visible, err := repo.ListVisibleCodes(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []CodeStatus{
+		{Code: "AAPL", Status: StatusActive, Name: "Apple Inc.", Market: "NASDAQ", Names: map[string]string{"en": "Apple"}},
+		{Code: "TWTR", Status: StatusDelisted, Name: "Twitter Inc.", Market: "NYSE"},
+	}, visible, "参照できるのは active と delisted")
+
+	s, err := repo.FindVisibleLocalized(ctx, "TWTR", DefaultLocale)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDelisted, s.Status)
+
+	_, err = repo.FindVisibleLocalized(ctx, "XXXX", DefaultLocale)
+	assert.ErrorIs(t, err, ErrSymbolNotFound, "hidden は存在しない銘柄として扱う")
+}
+
+func TestSymbolRepository_FindVisibleLocalized_Name(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	seedSymbol(t, db, "7203.T", "トヨタ自動車", "TSE", true)
+	_, err := repo.UpsertName(ctx, SymbolName{SymbolCode: "7203.T", Locale: "en", Name: "Toyota Motor"})
+	require.NoError(t, err)
+
+	s, err := repo.FindVisibleLocalized(ctx, "7203.T", "en")
+	require.NoError(t, err)
+	assert.Equal(t, "Toyota Motor", s.Name)
+
+	s, err = repo.FindVisibleLocalized(ctx, "7203.T", DefaultLocale)
+	require.NoError(t, err)
+	assert.Equal(t, "トヨタ自動車", s.Name)
+}
+
+// TestSymbolRepository_Search は大文字小文字を区別しない部分一致・アクティブな銘柄のみ・コード昇順・ページングによらない
+// 件数と、空の検索語・LIKE のメタ文字の扱いを検証します。
+func TestSymbolRepository_Search(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	seedSymbol(t, db, "7203.T", "トヨタ自動車", "TSE", true)
+	seedSymbol(t, db, "7267.T", "本田技研工業", "TSE", true)
+	seedSymbol(t, db, "TOYO", "Toyo Hidden", "TSE", false)
+	seedSymbol(t, db, "AAPL", "Apple Inc.", "NASDAQ", true)
+	seedSymbol(t, db, "TTT", "100% Pure_Co", "NYSE", true)
+	_, err := repo.UpsertName(ctx, SymbolName{SymbolCode: "7203.T", Locale: "en", Name: "Toyota Motor"})
+	require.NoError(t, err)
+
+	codes := func(ss []Symbol) []string {
+		out := make([]string, 0, len(ss))
+		for _, s := range ss {
+			out = append(out, s.Code)
+		}
+		return out
+	}
+
+	got, total, err := repo.Search(ctx, "toyo", 20, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"7203.T"}, codes(got), "多言語の名前にも一致し、非表示の銘柄は含まない")
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, "トヨタ自動車", got[0].Name, "Search は既定の名前を返す")
+
+	got, total, err = repo.Search(ctx, "72", 20, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"7203.T", "7267.T"}, codes(got), "コードの部分一致")
+	assert.Equal(t, int64(2), total)
+
+	got, total, err = repo.Search(ctx, "", 2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"7267.T", "AAPL"}, codes(got), "空の検索語は一覧と同じ順のページ")
+	assert.Equal(t, int64(4), total, "件数はページングによらない")
+
+	got, total, err = repo.Search(ctx, "", 20, 10)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+	assert.Equal(t, int64(4), total, "範囲外のページでも件数を返す")
+
+	got, _, err = repo.Search(ctx, "%", 20, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TTT"}, codes(got), "% はワイルドカードではない")
+	got, _, err = repo.Search(ctx, "e_c", 20, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TTT"}, codes(got), "_ はワイルドカードではない")
+
+	got, total, err = repo.SearchLocalized(ctx, "TOYO", "en", 20, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total)
+	assert.Equal(t, "Toyota Motor", got[0].Name)
+}
+
+func TestSymbolRepository_UpdateStatus(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	seedSymbol(t, db, "TWTR", "Twitter Inc.", "NYSE", true)
+
+	since := time.Date(2022, 11, 8, 0, 0, 0, 0, time.UTC)
+	s, err := repo.UpdateStatus(ctx, "TWTR", StatusDelisted, since)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDelisted, s.Status)
+	d, ok := s.DelistedAsOf()
+	require.True(t, ok)
+	assert.True(t, d.Equal(since))
+
+	var active bool
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT is_active FROM symbols WHERE code = 'TWTR'`).Scan(&active))
+	assert.False(t, active, "移行期間中は is_active が status に追従する")
+
+	_, err = repo.UpdateStatus(ctx, "NOPE", StatusHidden, since)
+	assert.ErrorIs(t, err, ErrSymbolNotFound)
+}
+
+// TestSymbolRepository_LegacyIsActiveWrites は is_active だけを書き換える従来の書き込みが status に反映されることを検証します。
+func TestSymbolRepository_LegacyIsActiveWrites(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	ctx := context.Background()
+	s := seedSymbol(t, db, "AAPL", "Apple Inc.", "NASDAQ", true)
+
+	status := func() string {
+		var st string
+		require.NoError(t, db.QueryRowContext(ctx, `SELECT status FROM symbols WHERE id = $1`, s.ID).Scan(&st))
+		return st
+	}
+	updateSymbolActive(t, db, s, false)
+	assert.Equal(t, "hidden", status())
+	updateSymbolActive(t, db, s, true)
+	assert.Equal(t, "active", status())
+}
+
+func TestSymbolRepository_UpsertSymbols(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	// 既存の銘柄は status / priority を保持したまま名前だけ更新される
+	seedSymbol(t, db, "7203.T", "Toyota (old)", "TSE", false)
+	usd, jpy := "USD", "JPY"
+	syms := []Symbol{
+		{Code: "AAPL", Name: "Apple Inc.", Market: "NASDAQ", Timezone: "America/New_York", Currency: &usd},
+		{Code: "7203.T", Name: "Toyota Motor", Market: "TSE", Timezone: "Asia/Tokyo", Currency: &jpy},
+	}
+
+	changed, err := repo.UpsertSymbols(ctx, syms)
+	require.NoError(t, err)
+	assert.Equal(t, 2, changed)
+
+	changed, err = repo.UpsertSymbols(ctx, syms)
+	require.NoError(t, err)
+	assert.Equal(t, 0, changed, "re-running with the same values must not touch any row")
+
+	var count int
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT count(*) FROM symbols`).Scan(&count))
+	assert.Equal(t, 2, count)
+
+	var name, status string
+	var active bool
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT name, status, is_active FROM symbols WHERE code = '7203.T'`).Scan(&name, &status, &active))
+	assert.Equal(t, "Toyota Motor", name)
+	assert.Equal(t, "hidden", status, "status must be preserved")
+	assert.False(t, active, "is_active must be preserved")
+}
+
 func TestSymbolRepository_ContextCancellation(t *testing.T) {
 	t.Parallel()
 	db := setupTestDB(t)
@@ -0,0 +1,26 @@
+package symbollist
+
+import (
+	"math"
+	"strings"
+)
+
+const (
+	// DefaultSearchLimit は銘柄の検索で limit 未指定時に返す件数です。
+	DefaultSearchLimit = 20
+	// MaxSearchLimit は銘柄の検索で 1 回に返せる最大件数です。
+	MaxSearchLimit = 100
+	// MaxSearchQueryLength は銘柄の検索語の最大文字数です。
+	MaxSearchQueryLength = 100
+	// MaxSearchOffset は銘柄の検索で読み飛ばせる最大件数です（SQL の OFFSET に渡す int32 の上限）。
+	MaxSearchOffset = math.MaxInt32
+)
+
+// likeEscaper は LIKE のメタ文字（% _ と既定のエスケープ文字 \）をエスケープします。
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// searchPattern は検索語 query を部分一致の ILIKE パターンにします。
+// query の % や _ は文字どおりに扱い、空の query はすべての銘柄に一致します。
+func searchPattern(query string) string {
+	return "%" + likeEscaper.Replace(query) + "%"
+}
@@ -9,6 +9,37 @@ import (
 	"time"
 )
 
+type Alert struct {
+	ID                int64
+	UserID            int64
+	SymbolCode        string
+	Interval          string
+	Direction         string
+	Threshold         float64
+	CreatedAt         time.Time
+	TriggeredAt       sql.NullTime
+	NotifiedAt        sql.NullTime
+	NotifyError       sql.NullString
+	Mode              string
+	CooldownMinutes   int32
+	LastTriggeredAt   sql.NullTime
+	LastSide          sql.NullString
+	OriginalThreshold sql.NullFloat64
+	AdjustmentID      sql.NullInt64
+	EditedAt          sql.NullTime
+}
+
+type Annotation struct {
+	ID         int64
+	UserID     int64
+	SymbolCode string
+	Interval   string
+	Time       time.Time
+	Text       string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
 type Candle struct {
 	ID         int64
 	SymbolCode string
@@ -19,6 +50,73 @@ type Candle struct {
 	Low        string
 	Close      string
 	Volume     int64
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	Source     string
+}
+
+type CandleAdjustment struct {
+	ID            int64
+	SymbolCode    string
+	EffectiveDate time.Time
+	Factor        float64
+	Reason        string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type CandleAnomaly struct {
+	ID                  int64
+	SymbolCode          string
+	Time                time.Time
+	PrevClose           string
+	Close               string
+	Ratio               float64
+	Status              string
+	DetectedAt          time.Time
+	ResolvedAt          sql.NullTime
+	BackfillRequestedAt sql.NullTime
+	BackfilledAt        sql.NullTime
+}
+
+type CorporateEvent struct {
+	ID         int64
+	SymbolCode string
+	Type       string
+	Date       time.Time
+	Value      sql.NullString
+	Estimate   sql.NullString
+	Source     string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type DataFreshness struct {
+	Interval      string
+	Market        string
+	LastSuccessAt sql.NullTime
+	LastAttemptAt time.Time
+	LastError     sql.NullString
+	UpdatedAt     time.Time
+}
+
+type DigestSend struct {
+	UserID     int64
+	DigestDate time.Time
+	SentAt     time.Time
+}
+
+type DigestSubscription struct {
+	UserID    int64
+	CreatedAt time.Time
+}
+
+type LogoUsage struct {
+	UserID    int64
+	Operation string
+	UsageDate time.Time
+	Count     int32
+	UpdatedAt time.Time
 }
 
 type OauthAccount struct {
@@ -29,6 +127,67 @@ type OauthAccount struct {
 	CreatedAt   time.Time
 }
 
+type OutboxEvent struct {
+	ID            int64
+	Topic         string
+	AggregateKey  string
+	Payload       string
+	Status        string
+	Attempts      int32
+	NextAttemptAt time.Time
+	ClaimedBy     sql.NullString
+	LastError     sql.NullString
+	CreatedAt     time.Time
+	CompletedAt   sql.NullTime
+}
+
+type PasswordReset struct {
+	TokenHash []byte
+	UserID    int64
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+type PushDevice struct {
+	ID         int64
+	UserID     int64
+	Token      string
+	Platform   string
+	AppVersion string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	DisabledAt sql.NullTime
+}
+
+type PushJob struct {
+	ID            int64
+	AlertID       int64
+	DeviceID      int64
+	Title         string
+	Body          string
+	Data          string
+	Status        string
+	Attempts      int32
+	NextAttemptAt time.Time
+	LastError     sql.NullString
+	CreatedAt     time.Time
+	CompletedAt   sql.NullTime
+}
+
+type ScheduledJob struct {
+	Name           string
+	NextRunAt      time.Time
+	RunRequestedAt sql.NullTime
+	LastStartedAt  sql.NullTime
+	LastFinishedAt sql.NullTime
+	LastStatus     sql.NullString
+	LastError      sql.NullString
+	LastDurationMs int64
+	RunCount       int64
+	FailureCount   int64
+	UpdatedAt      time.Time
+}
+
 type Symbol struct {
 	ID            int64
 	Code          string
@@ -40,14 +199,46 @@ type Symbol struct {
 	IsActive      bool
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
+	Currency      sql.NullString
+	Priority      int16
+	Status        string
+	StatusSince   sql.NullTime
+	Tier          string
+}
+
+type SymbolDailyStat struct {
+	SymbolCode   string
+	AsOf         time.Time
+	High52w      string
+	Low52w       string
+	AvgVol30d    float64
+	YtdChangePct sql.NullFloat64
+	ComputedAt   time.Time
+}
+
+type SymbolName struct {
+	SymbolCode string
+	Locale     string
+	Name       string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
 }
 
 type User struct {
-	ID        int64
-	Email     string
-	Password  sql.NullString
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID          int64
+	Email       string
+	Password    sql.NullString
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	LastLoginAt sql.NullTime
+	Plan        string
+	Role        string
+}
+
+type UserResourceVersion struct {
+	UserID   int64
+	Resource string
+	Version  int64
 }
 
 type Watchlist struct {
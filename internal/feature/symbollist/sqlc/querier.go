@@ -9,9 +9,34 @@ import (
 )
 
 type Querier interface {
+	// SearchActiveSymbols のページングによらない一致件数。
+	CountActiveSymbolsMatching(ctx context.Context, pattern string) (int64, error)
+	DeleteSymbolName(ctx context.Context, arg DeleteSymbolNameParams) (int64, error)
+	// 銘柄の詳細用。非表示（hidden）の銘柄は存在しないものとして扱う。
+	GetVisibleSymbol(ctx context.Context, code string) (Symbol, error)
+	ListActiveSymbolCodes(ctx context.Context) ([]string, error)
+	// 取り込み・一覧の対象（status = 'active'）。上場廃止（delisted）と非表示（hidden）は含まない。
 	ListActiveSymbols(ctx context.Context) ([]Symbol, error)
+	ListSymbolNames(ctx context.Context, symbolCode string) ([]SymbolName, error)
+	// 銘柄一覧の多言語化用。要求ロケールとフォールバックロケールの行を全銘柄分まとめて読み込む。
+	ListSymbolNamesByLocales(ctx context.Context, arg ListSymbolNamesByLocalesParams) ([]SymbolName, error)
+	// 保存済みのデータを返せる銘柄（active と delisted）の多言語の名前。/candles の ?include=symbol 用。
+	ListVisibleSymbolNames(ctx context.Context) ([]ListVisibleSymbolNamesRow, error)
+	// 保存済みのデータを返せる銘柄（active と delisted）のコード・状態・名前・市場・区分。/candles 等の銘柄の存在チェックと
+	// 料金プランによる参照の制限用。
+	ListVisibleSymbolStatuses(ctx context.Context) ([]ListVisibleSymbolStatusesRow, error)
+	// 銘柄の検索用。コード・名前・多言語の名前のいずれかが pattern（ILIKE）に一致するアクティブな銘柄を一覧と同じコード昇順で返す。
+	SearchActiveSymbols(ctx context.Context, arg SearchActiveSymbolsParams) ([]Symbol, error)
+	// 非表示（hidden）の銘柄は存在しないものとして扱う。
 	SymbolExists(ctx context.Context, code string) (bool, error)
+	// ウォッチリストへの追加用。上場廃止（delisted）と非表示（hidden）の銘柄は対象外。
+	SymbolIsActive(ctx context.Context, code string) (bool, error)
 	UpdateSymbolLogoURL(ctx context.Context, arg UpdateSymbolLogoURLParams) (int64, error)
+	UpdateSymbolStatus(ctx context.Context, arg UpdateSymbolStatusParams) (Symbol, error)
+	// 開発用データの投入（batch seed）用。既存の銘柄は名前・市場・タイムゾーン・通貨が異なる場合だけ更新し、
+	// status / priority / ロゴは保持する。変更がなければ 0 行を返す。
+	UpsertSymbol(ctx context.Context, arg UpsertSymbolParams) (int64, error)
+	UpsertSymbolName(ctx context.Context, arg UpsertSymbolNameParams) (SymbolName, error)
 }
 
 var _ Querier = (*Queries)(nil)
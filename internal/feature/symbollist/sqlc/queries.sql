@@ -1,17 +1,116 @@
 -- name: ListActiveSymbols :many
-SELECT id, code, name, market, timezone, logo_url, logo_updated_at, is_active, created_at, updated_at
+-- 取り込み・一覧の対象（status = 'active'）。上場廃止（delisted）と非表示（hidden）は含まない。
+SELECT id, code, name, market, timezone, logo_url, logo_updated_at, is_active, created_at, updated_at, currency, priority, status, status_since, tier
 FROM symbols
-WHERE is_active = TRUE
+WHERE status = 'active'
 ORDER BY code ASC;
 
+-- name: SearchActiveSymbols :many
+-- 銘柄の検索用。コード・名前・多言語の名前のいずれかが pattern（ILIKE）に一致するアクティブな銘柄を一覧と同じコード昇順で返す。
+SELECT s.id, s.code, s.name, s.market, s.timezone, s.logo_url, s.logo_updated_at, s.is_active, s.created_at, s.updated_at, s.currency, s.priority, s.status, s.status_since, s.tier
+FROM symbols s
+WHERE s.status = 'active'
+  AND (s.code ILIKE sqlc.arg(pattern) OR s.name ILIKE sqlc.arg(pattern)
+    OR EXISTS (SELECT 1 FROM symbol_names n WHERE n.symbol_code = s.code AND n.name ILIKE sqlc.arg(pattern)))
+ORDER BY s.code ASC
+LIMIT sqlc.arg(max_rows) OFFSET sqlc.arg(skip_rows);
+
+-- name: CountActiveSymbolsMatching :one
+-- SearchActiveSymbols のページングによらない一致件数。
+SELECT COUNT(*)
+FROM symbols s
+WHERE s.status = 'active'
+  AND (s.code ILIKE sqlc.arg(pattern) OR s.name ILIKE sqlc.arg(pattern)
+    OR EXISTS (SELECT 1 FROM symbol_names n WHERE n.symbol_code = s.code AND n.name ILIKE sqlc.arg(pattern)));
+
+-- name: GetVisibleSymbol :one
+-- 銘柄の詳細用。非表示（hidden）の銘柄は存在しないものとして扱う。
+SELECT id, code, name, market, timezone, logo_url, logo_updated_at, is_active, created_at, updated_at, currency, priority, status, status_since, tier
+FROM symbols
+WHERE code = $1 AND status <> 'hidden';
+
 -- name: SymbolExists :one
+-- 非表示（hidden）の銘柄は存在しないものとして扱う。
 SELECT EXISTS (
-  SELECT 1 FROM symbols WHERE code = $1
+  SELECT 1 FROM symbols WHERE code = $1 AND status <> 'hidden'
 ) AS exists;
 
+-- name: SymbolIsActive :one
+-- ウォッチリストへの追加用。上場廃止（delisted）と非表示（hidden）の銘柄は対象外。
+SELECT EXISTS (
+  SELECT 1 FROM symbols WHERE code = $1 AND status = 'active'
+) AS active;
+
 -- name: UpdateSymbolLogoURL :execrows
 UPDATE symbols
 SET logo_url = $2,
     logo_updated_at = $3,
     updated_at = now()
 WHERE code = $1;
+
+-- name: ListActiveSymbolCodes :many
+SELECT code
+FROM symbols
+WHERE status = 'active'
+ORDER BY code ASC;
+
+-- name: ListVisibleSymbolStatuses :many
+-- 保存済みのデータを返せる銘柄（active と delisted）のコード・状態・名前・市場・区分。/candles 等の銘柄の存在チェックと
+-- 料金プランによる参照の制限用。
+SELECT code, status, name, market, tier
+FROM symbols
+WHERE status <> 'hidden'
+ORDER BY code ASC;
+
+-- name: ListVisibleSymbolNames :many
+-- 保存済みのデータを返せる銘柄（active と delisted）の多言語の名前。/candles の ?include=symbol 用。
+SELECT n.symbol_code, n.locale, n.name
+FROM symbol_names n
+JOIN symbols s ON s.code = n.symbol_code
+WHERE s.status <> 'hidden';
+
+-- name: UpdateSymbolStatus :one
+UPDATE symbols
+SET status = sqlc.arg(status),
+    status_since = sqlc.arg(status_since),
+    updated_at = now()
+WHERE code = sqlc.arg(code)
+RETURNING id, code, name, market, timezone, logo_url, logo_updated_at, is_active, created_at, updated_at, currency, priority, status, status_since, tier;
+
+-- name: ListSymbolNames :many
+SELECT symbol_code, locale, name, created_at, updated_at
+FROM symbol_names
+WHERE symbol_code = $1
+ORDER BY locale ASC;
+
+-- name: ListSymbolNamesByLocales :many
+-- 銘柄一覧の多言語化用。要求ロケールとフォールバックロケールの行を全銘柄分まとめて読み込む。
+SELECT symbol_code, locale, name, created_at, updated_at
+FROM symbol_names
+WHERE locale = sqlc.arg(locale) OR locale = sqlc.arg(fallback_locale);
+
+-- name: UpsertSymbolName :one
+INSERT INTO symbol_names (symbol_code, locale, name)
+VALUES ($1, $2, $3)
+ON CONFLICT (symbol_code, locale) DO UPDATE
+SET name = EXCLUDED.name,
+    updated_at = now()
+RETURNING symbol_code, locale, name, created_at, updated_at;
+
+-- name: DeleteSymbolName :execrows
+DELETE FROM symbol_names
+WHERE symbol_code = $1 AND locale = $2;
+
+-- name: UpsertSymbol :execrows
+-- 開発用データの投入（batch seed）用。既存の銘柄は名前・市場・タイムゾーン・通貨が異なる場合だけ更新し、
+-- status / priority / ロゴは保持する。変更がなければ 0 行を返す。
+INSERT INTO symbols (code, name, market, timezone, currency)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (code) DO UPDATE
+SET name = EXCLUDED.name,
+    market = EXCLUDED.market,
+    timezone = EXCLUDED.timezone,
+    currency = EXCLUDED.currency,
+    updated_at = now()
+WHERE (symbols.name, symbols.market, symbols.timezone, symbols.currency)
+    IS DISTINCT FROM (EXCLUDED.name, EXCLUDED.market, EXCLUDED.timezone, EXCLUDED.currency);
@@ -10,13 +10,108 @@ import (
 	"database/sql"
 )
 
+const countActiveSymbolsMatching = `-- name: CountActiveSymbolsMatching :one
+SELECT COUNT(*)
+FROM symbols s
+WHERE s.status = 'active'
+  AND (s.code ILIKE $1 OR s.name ILIKE $1
+    OR EXISTS (SELECT 1 FROM symbol_names n WHERE n.symbol_code = s.code AND n.name ILIKE $1))
+`
+
+// SearchActiveSymbols のページングによらない一致件数。
+func (q *Queries) CountActiveSymbolsMatching(ctx context.Context, pattern string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countActiveSymbolsMatching, pattern)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteSymbolName = `-- name: DeleteSymbolName :execrows
+DELETE FROM symbol_names
+WHERE symbol_code = $1 AND locale = $2
+`
+
+type DeleteSymbolNameParams struct {
+	SymbolCode string
+	Locale     string
+}
+
+func (q *Queries) DeleteSymbolName(ctx context.Context, arg DeleteSymbolNameParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteSymbolName, arg.SymbolCode, arg.Locale)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getVisibleSymbol = `-- name: GetVisibleSymbol :one
+SELECT id, code, name, market, timezone, logo_url, logo_updated_at, is_active, created_at, updated_at, currency, priority, status, status_since, tier
+FROM symbols
+WHERE code = $1 AND status <> 'hidden'
+`
+
+// 銘柄の詳細用。非表示（hidden）の銘柄は存在しないものとして扱う。
+func (q *Queries) GetVisibleSymbol(ctx context.Context, code string) (Symbol, error) {
+	row := q.db.QueryRowContext(ctx, getVisibleSymbol, code)
+	var i Symbol
+	err := row.Scan(
+		&i.ID,
+		&i.Code,
+		&i.Name,
+		&i.Market,
+		&i.Timezone,
+		&i.LogoUrl,
+		&i.LogoUpdatedAt,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Currency,
+		&i.Priority,
+		&i.Status,
+		&i.StatusSince,
+		&i.Tier,
+	)
+	return i, err
+}
+
+const listActiveSymbolCodes = `-- name: ListActiveSymbolCodes :many
+SELECT code
+FROM symbols
+WHERE status = 'active'
+ORDER BY code ASC
+`
+
+func (q *Queries) ListActiveSymbolCodes(ctx context.Context) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listActiveSymbolCodes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return nil, err
+		}
+		items = append(items, code)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listActiveSymbols = `-- name: ListActiveSymbols :many
-SELECT id, code, name, market, timezone, logo_url, logo_updated_at, is_active, created_at, updated_at
+SELECT id, code, name, market, timezone, logo_url, logo_updated_at, is_active, created_at, updated_at, currency, priority, status, status_since, tier
 FROM symbols
-WHERE is_active = TRUE
+WHERE status = 'active'
 ORDER BY code ASC
 `
 
+// 取り込み・一覧の対象（status = 'active'）。上場廃止（delisted）と非表示（hidden）は含まない。
 func (q *Queries) ListActiveSymbols(ctx context.Context) ([]Symbol, error) {
 	rows, err := q.db.QueryContext(ctx, listActiveSymbols)
 	if err != nil {
@@ -37,6 +132,227 @@ func (q *Queries) ListActiveSymbols(ctx context.Context) ([]Symbol, error) {
 			&i.IsActive,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Currency,
+			&i.Priority,
+			&i.Status,
+			&i.StatusSince,
+			&i.Tier,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSymbolNames = `-- name: ListSymbolNames :many
+SELECT symbol_code, locale, name, created_at, updated_at
+FROM symbol_names
+WHERE symbol_code = $1
+ORDER BY locale ASC
+`
+
+func (q *Queries) ListSymbolNames(ctx context.Context, symbolCode string) ([]SymbolName, error) {
+	rows, err := q.db.QueryContext(ctx, listSymbolNames, symbolCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SymbolName{}
+	for rows.Next() {
+		var i SymbolName
+		if err := rows.Scan(
+			&i.SymbolCode,
+			&i.Locale,
+			&i.Name,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSymbolNamesByLocales = `-- name: ListSymbolNamesByLocales :many
+SELECT symbol_code, locale, name, created_at, updated_at
+FROM symbol_names
+WHERE locale = $1 OR locale = $2
+`
+
+type ListSymbolNamesByLocalesParams struct {
+	Locale         string
+	FallbackLocale string
+}
+
+// 銘柄一覧の多言語化用。要求ロケールとフォールバックロケールの行を全銘柄分まとめて読み込む。
+func (q *Queries) ListSymbolNamesByLocales(ctx context.Context, arg ListSymbolNamesByLocalesParams) ([]SymbolName, error) {
+	rows, err := q.db.QueryContext(ctx, listSymbolNamesByLocales, arg.Locale, arg.FallbackLocale)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SymbolName{}
+	for rows.Next() {
+		var i SymbolName
+		if err := rows.Scan(
+			&i.SymbolCode,
+			&i.Locale,
+			&i.Name,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listVisibleSymbolNames = `-- name: ListVisibleSymbolNames :many
+SELECT n.symbol_code, n.locale, n.name
+FROM symbol_names n
+JOIN symbols s ON s.code = n.symbol_code
+WHERE s.status <> 'hidden'
+`
+
+type ListVisibleSymbolNamesRow struct {
+	SymbolCode string
+	Locale     string
+	Name       string
+}
+
+// 保存済みのデータを返せる銘柄（active と delisted）の多言語の名前。/candles の ?include=symbol 用。
+func (q *Queries) ListVisibleSymbolNames(ctx context.Context) ([]ListVisibleSymbolNamesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listVisibleSymbolNames)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListVisibleSymbolNamesRow{}
+	for rows.Next() {
+		var i ListVisibleSymbolNamesRow
+		if err := rows.Scan(&i.SymbolCode, &i.Locale, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listVisibleSymbolStatuses = `-- name: ListVisibleSymbolStatuses :many
+SELECT code, status, name, market, tier
+FROM symbols
+WHERE status <> 'hidden'
+ORDER BY code ASC
+`
+
+type ListVisibleSymbolStatusesRow struct {
+	Code   string
+	Status string
+	Name   string
+	Market string
+	Tier   string
+}
+
+// 保存済みのデータを返せる銘柄（active と delisted）のコード・状態・名前・市場・区分。/candles 等の銘柄の存在チェックと
+// 料金プランによる参照の制限用。
+func (q *Queries) ListVisibleSymbolStatuses(ctx context.Context) ([]ListVisibleSymbolStatusesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listVisibleSymbolStatuses)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListVisibleSymbolStatusesRow{}
+	for rows.Next() {
+		var i ListVisibleSymbolStatusesRow
+		if err := rows.Scan(
+			&i.Code,
+			&i.Status,
+			&i.Name,
+			&i.Market,
+			&i.Tier,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchActiveSymbols = `-- name: SearchActiveSymbols :many
+SELECT s.id, s.code, s.name, s.market, s.timezone, s.logo_url, s.logo_updated_at, s.is_active, s.created_at, s.updated_at, s.currency, s.priority, s.status, s.status_since, s.tier
+FROM symbols s
+WHERE s.status = 'active'
+  AND (s.code ILIKE $1 OR s.name ILIKE $1
+    OR EXISTS (SELECT 1 FROM symbol_names n WHERE n.symbol_code = s.code AND n.name ILIKE $1))
+ORDER BY s.code ASC
+LIMIT $2 OFFSET $3
+`
+
+type SearchActiveSymbolsParams struct {
+	Pattern  string
+	MaxRows  int32
+	SkipRows int32
+}
+
+// 銘柄の検索用。コード・名前・多言語の名前のいずれかが pattern（ILIKE）に一致するアクティブな銘柄を一覧と同じコード昇順で返す。
+func (q *Queries) SearchActiveSymbols(ctx context.Context, arg SearchActiveSymbolsParams) ([]Symbol, error) {
+	rows, err := q.db.QueryContext(ctx, searchActiveSymbols, arg.Pattern, arg.MaxRows, arg.SkipRows)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Symbol{}
+	for rows.Next() {
+		var i Symbol
+		if err := rows.Scan(
+			&i.ID,
+			&i.Code,
+			&i.Name,
+			&i.Market,
+			&i.Timezone,
+			&i.LogoUrl,
+			&i.LogoUpdatedAt,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Currency,
+			&i.Priority,
+			&i.Status,
+			&i.StatusSince,
+			&i.Tier,
 		); err != nil {
 			return nil, err
 		}
@@ -53,10 +369,11 @@ func (q *Queries) ListActiveSymbols(ctx context.Context) ([]Symbol, error) {
 
 const symbolExists = `-- name: SymbolExists :one
 SELECT EXISTS (
-  SELECT 1 FROM symbols WHERE code = $1
+  SELECT 1 FROM symbols WHERE code = $1 AND status <> 'hidden'
 ) AS exists
 `
 
+// 非表示（hidden）の銘柄は存在しないものとして扱う。
 func (q *Queries) SymbolExists(ctx context.Context, code string) (bool, error) {
 	row := q.db.QueryRowContext(ctx, symbolExists, code)
 	var exists bool
@@ -64,6 +381,20 @@ func (q *Queries) SymbolExists(ctx context.Context, code string) (bool, error) {
 	return exists, err
 }
 
+const symbolIsActive = `-- name: SymbolIsActive :one
+SELECT EXISTS (
+  SELECT 1 FROM symbols WHERE code = $1 AND status = 'active'
+) AS active
+`
+
+// ウォッチリストへの追加用。上場廃止（delisted）と非表示（hidden）の銘柄は対象外。
+func (q *Queries) SymbolIsActive(ctx context.Context, code string) (bool, error) {
+	row := q.db.QueryRowContext(ctx, symbolIsActive, code)
+	var active bool
+	err := row.Scan(&active)
+	return active, err
+}
+
 const updateSymbolLogoURL = `-- name: UpdateSymbolLogoURL :execrows
 UPDATE symbols
 SET logo_url = $2,
@@ -85,3 +416,106 @@ func (q *Queries) UpdateSymbolLogoURL(ctx context.Context, arg UpdateSymbolLogoU
 	}
 	return result.RowsAffected()
 }
+
+const updateSymbolStatus = `-- name: UpdateSymbolStatus :one
+UPDATE symbols
+SET status = $1,
+    status_since = $2,
+    updated_at = now()
+WHERE code = $3
+RETURNING id, code, name, market, timezone, logo_url, logo_updated_at, is_active, created_at, updated_at, currency, priority, status, status_since, tier
+`
+
+type UpdateSymbolStatusParams struct {
+	Status      string
+	StatusSince sql.NullTime
+	Code        string
+}
+
+func (q *Queries) UpdateSymbolStatus(ctx context.Context, arg UpdateSymbolStatusParams) (Symbol, error) {
+	row := q.db.QueryRowContext(ctx, updateSymbolStatus, arg.Status, arg.StatusSince, arg.Code)
+	var i Symbol
+	err := row.Scan(
+		&i.ID,
+		&i.Code,
+		&i.Name,
+		&i.Market,
+		&i.Timezone,
+		&i.LogoUrl,
+		&i.LogoUpdatedAt,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Currency,
+		&i.Priority,
+		&i.Status,
+		&i.StatusSince,
+		&i.Tier,
+	)
+	return i, err
+}
+
+const upsertSymbol = `-- name: UpsertSymbol :execrows
+INSERT INTO symbols (code, name, market, timezone, currency)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (code) DO UPDATE
+SET name = EXCLUDED.name,
+    market = EXCLUDED.market,
+    timezone = EXCLUDED.timezone,
+    currency = EXCLUDED.currency,
+    updated_at = now()
+WHERE (symbols.name, symbols.market, symbols.timezone, symbols.currency)
+    IS DISTINCT FROM (EXCLUDED.name, EXCLUDED.market, EXCLUDED.timezone, EXCLUDED.currency)
+`
+
+type UpsertSymbolParams struct {
+	Code     string
+	Name     string
+	Market   string
+	Timezone string
+	Currency sql.NullString
+}
+
+// 開発用データの投入（batch seed）用。既存の銘柄は名前・市場・タイムゾーン・通貨が異なる場合だけ更新し、
+// status / priority / ロゴは保持する。変更がなければ 0 行を返す。
+func (q *Queries) UpsertSymbol(ctx context.Context, arg UpsertSymbolParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, upsertSymbol,
+		arg.Code,
+		arg.Name,
+		arg.Market,
+		arg.Timezone,
+		arg.Currency,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const upsertSymbolName = `-- name: UpsertSymbolName :one
+INSERT INTO symbol_names (symbol_code, locale, name)
+VALUES ($1, $2, $3)
+ON CONFLICT (symbol_code, locale) DO UPDATE
+SET name = EXCLUDED.name,
+    updated_at = now()
+RETURNING symbol_code, locale, name, created_at, updated_at
+`
+
+type UpsertSymbolNameParams struct {
+	SymbolCode string
+	Locale     string
+	Name       string
+}
+
+func (q *Queries) UpsertSymbolName(ctx context.Context, arg UpsertSymbolNameParams) (SymbolName, error) {
+	row := q.db.QueryRowContext(ctx, upsertSymbolName, arg.SymbolCode, arg.Locale, arg.Name)
+	var i SymbolName
+	err := row.Scan(
+		&i.SymbolCode,
+		&i.Locale,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
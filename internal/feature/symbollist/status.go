@@ -0,0 +1,106 @@
+package symbollist
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Status は銘柄の状態です（symbols.status）。
+type Status string
+
+const (
+	// StatusActive は取り込み・一覧の対象の銘柄です。
+	StatusActive Status = "active"
+	// StatusDelisted は上場廃止の銘柄です。取り込みと一覧からは外しますが、保存済みのローソク足は返します。
+	StatusDelisted Status = "delisted"
+	// StatusHidden はすべての API で存在しないものとして扱う銘柄です。
+	StatusHidden Status = "hidden"
+)
+
+// ParseStatus は s を Status として解釈します（大文字小文字・前後の空白は無視）。
+// 未知の値の場合は ErrInvalidSymbolStatus をラップしたエラーを返します。
+func ParseStatus(s string) (Status, error) {
+	switch st := Status(strings.ToLower(strings.TrimSpace(s))); st {
+	case StatusActive, StatusDelisted, StatusHidden:
+		return st, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrInvalidSymbolStatus, s)
+}
+
+// Visible は保存済みのデータを返せる状態（active / delisted）かを返します。
+func (s Status) Visible() bool {
+	return s == StatusActive || s == StatusDelisted
+}
+
+// CodeStatus は銘柄コードと状態の組です。/candles の ?include=symbol のため名前と市場、プランによる参照の制限のため区分も持ちます。
+type CodeStatus struct {
+	Code   string
+	Status Status
+	Name   string            // symbols.name（DefaultLocale の名前）
+	Market string            // 市場識別子（例: "NASDAQ", "TSE"）
+	Tier   Tier              // 区分（basic / premium）
+	Names  map[string]string // DefaultLocale 以外のロケール → 名前（symbol_names）。登録がなければ nil
+}
+
+// StatusRepository は銘柄の状態の変更を抽象化します。
+type StatusRepository interface {
+	// UpdateStatus は銘柄 code の状態と効力発生日を更新し、更新後の銘柄を返します。
+	// 銘柄が存在しない場合は ErrSymbolNotFound を返します。
+	UpdateStatus(ctx context.Context, code string, status Status, since time.Time) (Symbol, error)
+}
+
+// CodeSetInvalidator は銘柄コード集合のキャッシュの破棄を抽象化します。ActiveCodeSet が実装します。
+type CodeSetInvalidator interface {
+	Invalidate()
+}
+
+// ListRefresher は銘柄一覧の last-known-good の再読み込みを抽象化します。CachingRepository が実装します。
+type ListRefresher interface {
+	Refresh(ctx context.Context) error
+}
+
+// StatusUsecase は管理者が銘柄の状態（上場廃止・非表示）を切り替えるためのユースケースです。
+type StatusUsecase struct {
+	repo  StatusRepository
+	codes CodeSetInvalidator
+	list  ListRefresher
+	now   func() time.Time
+}
+
+// NewStatusUsecase は StatusUsecase の新しいインスタンスを生成します。
+// 状態の変更後は codes を破棄し、list の last-known-good を読み直します（nil の場合は何もしません）。
+// 破棄はこのプロセスのキャッシュのみが対象で、他のインスタンスは ActiveCodeSet の TTL で追従します。
+func NewStatusUsecase(repo StatusRepository, codes CodeSetInvalidator, list ListRefresher) *StatusUsecase {
+	return &StatusUsecase{repo: repo, codes: codes, list: list, now: time.Now}
+}
+
+// SetStatus は銘柄 code の状態を status に変更し、変更後の銘柄を返します。
+// since は効力発生日（delisted では上場廃止日）で、ゼロ値の場合は今日（UTC）とします。
+func (u *StatusUsecase) SetStatus(ctx context.Context, code string, status Status, since time.Time) (Symbol, error) {
+	if _, err := ParseStatus(string(status)); err != nil {
+		return Symbol{}, err
+	}
+	if since.IsZero() {
+		since = u.now().UTC()
+	}
+	since = time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, time.UTC)
+
+	s, err := u.repo.UpdateStatus(ctx, strings.TrimSpace(code), status, since)
+	if err != nil {
+		return Symbol{}, err
+	}
+	if u.codes != nil {
+		u.codes.Invalidate()
+	}
+	if u.list != nil {
+		// 状態の変更は保存済みのため、一覧の読み直しの失敗は警告に留める（次の読み取りで更新される）
+		if err := u.list.Refresh(ctx); err != nil {
+			slog.WarnContext(ctx, "failed to refresh symbols last-known-good after status change", "code", s.Code, "error", err)
+		}
+	}
+	slog.InfoContext(ctx, "symbol status changed", "code", s.Code, "status", s.Status, "since", since.Format(time.DateOnly))
+	return s, nil
+}
@@ -0,0 +1,163 @@
+package symbollist_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist"
+)
+
+func TestParseStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in      string
+		want    symbollist.Status
+		wantErr bool
+	}{
+		{"active", symbollist.StatusActive, false},
+		{"delisted", symbollist.StatusDelisted, false},
+		{" Hidden ", symbollist.StatusHidden, false},
+		{"inactive", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := symbollist.ParseStatus(tt.in)
+		if tt.wantErr {
+			assert.ErrorIs(t, err, symbollist.ErrInvalidSymbolStatus, tt.in)
+			continue
+		}
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got)
+	}
+}
+
+func TestStatus_Visible(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, symbollist.StatusActive.Visible())
+	assert.True(t, symbollist.StatusDelisted.Visible())
+	assert.False(t, symbollist.StatusHidden.Visible())
+}
+
+func TestSymbol_DelistedAsOf(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2022, 11, 8, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		symbol symbollist.Symbol
+		wantOK bool
+	}{
+		{"上場廃止（日付あり）", symbollist.Symbol{Status: symbollist.StatusDelisted, StatusSince: &day}, true},
+		{"上場廃止（日付なし）", symbollist.Symbol{Status: symbollist.StatusDelisted}, false},
+		{"アクティブ", symbollist.Symbol{Status: symbollist.StatusActive, StatusSince: &day}, false},
+		{"非表示", symbollist.Symbol{Status: symbollist.StatusHidden, StatusSince: &day}, false},
+	}
+	for _, tt := range tests {
+		got, ok := tt.symbol.DelistedAsOf()
+		assert.Equal(t, tt.wantOK, ok, tt.name)
+		if ok {
+			assert.True(t, got.Equal(day), tt.name)
+		}
+	}
+}
+
+// fakeStatusRepo は UpdateStatus の引数を記録する StatusRepository です。
+type fakeStatusRepo struct {
+	err       error
+	gotCode   string
+	gotStatus symbollist.Status
+	gotSince  time.Time
+}
+
+func (f *fakeStatusRepo) UpdateStatus(ctx context.Context, code string, status symbollist.Status, since time.Time) (symbollist.Symbol, error) {
+	f.gotCode, f.gotStatus, f.gotSince = code, status, since
+	if f.err != nil {
+		return symbollist.Symbol{}, f.err
+	}
+	return symbollist.Symbol{Code: code, Status: status, StatusSince: &since}, nil
+}
+
+type fakeInvalidator struct{ calls int }
+
+func (f *fakeInvalidator) Invalidate() { f.calls++ }
+
+type fakeRefresher struct {
+	err   error
+	calls int
+}
+
+func (f *fakeRefresher) Refresh(ctx context.Context) error {
+	f.calls++
+	return f.err
+}
+
+func TestStatusUsecase_SetStatus(t *testing.T) {
+	t.Parallel()
+
+	t.Run("状態を変更しキャッシュを破棄する", func(t *testing.T) {
+		t.Parallel()
+		repo, codes, list := &fakeStatusRepo{}, &fakeInvalidator{}, &fakeRefresher{}
+		uc := symbollist.NewStatusUsecase(repo, codes, list)
+
+		since := time.Date(2022, 11, 8, 15, 30, 0, 0, time.FixedZone("JST", 9*60*60))
+		s, err := uc.SetStatus(context.Background(), " TWTR ", symbollist.StatusDelisted, since)
+		require.NoError(t, err)
+		assert.Equal(t, "TWTR", repo.gotCode)
+		assert.Equal(t, symbollist.StatusDelisted, repo.gotStatus)
+		assert.Equal(t, time.Date(2022, 11, 8, 0, 0, 0, 0, time.UTC), repo.gotSince, "効力発生日は暦日に切り詰める")
+		assert.Equal(t, symbollist.StatusDelisted, s.Status)
+		assert.Equal(t, 1, codes.calls)
+		assert.Equal(t, 1, list.calls)
+	})
+
+	t.Run("効力発生日の省略は今日（UTC）", func(t *testing.T) {
+		t.Parallel()
+		repo := &fakeStatusRepo{}
+		uc := symbollist.NewStatusUsecase(repo, nil, nil)
+
+		before := time.Now().UTC()
+		_, err := uc.SetStatus(context.Background(), "AAPL", symbollist.StatusHidden, time.Time{})
+		require.NoError(t, err)
+		today := time.Date(before.Year(), before.Month(), before.Day(), 0, 0, 0, 0, time.UTC)
+		assert.False(t, repo.gotSince.Before(today))
+	})
+
+	t.Run("不正な状態は更新しない", func(t *testing.T) {
+		t.Parallel()
+		repo, codes := &fakeStatusRepo{}, &fakeInvalidator{}
+		uc := symbollist.NewStatusUsecase(repo, codes, nil)
+
+		_, err := uc.SetStatus(context.Background(), "AAPL", symbollist.Status("inactive"), time.Time{})
+		assert.ErrorIs(t, err, symbollist.ErrInvalidSymbolStatus)
+		assert.Empty(t, repo.gotCode)
+		assert.Zero(t, codes.calls)
+	})
+
+	t.Run("銘柄が存在しない場合はキャッシュを破棄しない", func(t *testing.T) {
+		t.Parallel()
+		repo, codes, list := &fakeStatusRepo{err: symbollist.ErrSymbolNotFound}, &fakeInvalidator{}, &fakeRefresher{}
+		uc := symbollist.NewStatusUsecase(repo, codes, list)
+
+		_, err := uc.SetStatus(context.Background(), "NOPE", symbollist.StatusDelisted, time.Time{})
+		assert.ErrorIs(t, err, symbollist.ErrSymbolNotFound)
+		assert.Zero(t, codes.calls)
+		assert.Zero(t, list.calls)
+	})
+
+	t.Run("一覧の読み直しの失敗は変更を失敗にしない", func(t *testing.T) {
+		t.Parallel()
+		list := &fakeRefresher{err: errors.New("db down")}
+		uc := symbollist.NewStatusUsecase(&fakeStatusRepo{}, &fakeInvalidator{}, list)
+
+		_, err := uc.SetStatus(context.Background(), "AAPL", symbollist.StatusDelisted, time.Time{})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, list.calls)
+	})
+}
@@ -10,9 +10,39 @@ type Symbol struct {
 	Name          string     // 企業名
 	Market        string     // 市場識別子（例: "NASDAQ", "TSE"）
 	Timezone      string     // 取引所の IANA タイムゾーン（例: "America/New_York", "Asia/Tokyo"）
+	Currency      *string    // 取引通貨（ISO 4217、例: "USD", "JPY"）。未設定時はNULL
 	LogoURL       *string    // Twelve DataのロゴURL（未取得時はNULL）
 	LogoUpdatedAt *time.Time // ロゴURLを最後に取得・更新した日時
-	IsActive      bool       // トラッキング対象かどうか
+	Status        Status     // 状態（active / delisted / hidden）
+	StatusSince   *time.Time // 状態の効力発生日（delisted では上場廃止日、UTC の暦日）。未設定時はNULL
+	Priority      int        // 取り込み優先度（1 が最優先、既定は 3）
+	Tier          Tier       // 区分（basic / premium）。premium は premium プランのユーザーだけが参照できる
 	CreatedAt     time.Time  // 登録日時
 	UpdatedAt     time.Time  // 最終更新日時
 }
+
+// Tier は銘柄の区分です（symbols.tier）。
+type Tier string
+
+const (
+	// TierBasic はすべてのプランで参照できる銘柄です。
+	TierBasic Tier = "basic"
+	// TierPremium は premium プランのユーザーだけが参照できる銘柄です。
+	TierPremium Tier = "premium"
+)
+
+// OrBasic は t を返します。空（区分を持たない古いキャッシュの値）の場合は TierBasic を返します。
+func (t Tier) OrBasic() Tier {
+	if t == "" {
+		return TierBasic
+	}
+	return t
+}
+
+// DelistedAsOf は上場廃止の銘柄について上場廃止日を返します。上場廃止でない、または日付が未設定の場合は ok=false です。
+func (s Symbol) DelistedAsOf() (time.Time, bool) {
+	if s.Status != StatusDelisted || s.StatusSince == nil {
+		return time.Time{}, false
+	}
+	return *s.StatusSince, true
+}
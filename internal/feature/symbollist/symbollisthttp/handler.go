@@ -4,16 +4,33 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
 
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
 )
 
+// symbolCodePattern は銘柄コードとして許可する形式（例: AAPL, 7203.T）。
+// symbols.code が VARCHAR(20) のため最大20文字、英数字と . _ - のみ許可する。
+var symbolCodePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,20}$`)
+
 // Usecase は銘柄（株式コード）操作のユースケースインターフェースを定義します。
 // Goの慣例に従い、インターフェースは利用者（handler）側で定義します。
 type Usecase interface {
-	ListActiveSymbols(ctx context.Context) ([]symbollist.Symbol, error)
+	// ListActiveSymbols はアクティブな銘柄を、銘柄名を locale の表記にして返します。
+	// stale=true は DB 障害時の last-known-good を返したことを示します。
+	ListActiveSymbols(ctx context.Context, locale string) (symbols []symbollist.Symbol, stale bool, err error)
+	// GetSymbol は銘柄 code の詳細を、銘柄名を locale の表記にして返します（上場廃止の銘柄を含む）。
+	GetSymbol(ctx context.Context, code, locale string) (symbollist.Symbol, error)
+	// Search はコード・名前が query を含むアクティブな銘柄を、銘柄名を locale の表記にして最大 limit 件返します。
+	// total はページングによらない一致件数です。
+	Search(ctx context.Context, query, locale string, limit, offset int) (symbols []symbollist.Symbol, total int64, err error)
 }
 
 // Handler は銘柄情報に関連するHTTPリクエストを処理します。
@@ -26,11 +43,14 @@ func NewHandler(uc Usecase) *Handler {
 	return &Handler{uc: uc}
 }
 
-// List はアクティブな銘柄の一覧を取得します。
+// List はアクティブな銘柄の一覧を取得します。上場廃止・非表示の銘柄は含みません。
 // ユースケースを呼び出して銘柄リストを取得し、DTOに変換してJSONレスポンスとして返します。
 // ユースケースがエラーを返した場合は500 Internal Server Errorを返します。
+// DB 障害のため最後に取得できた一覧を返す場合は X-Data-Stale: true ヘッダーを付与します。
+// 銘柄名は Accept-Language から選んだロケール（symbollist.SupportedLocales）の表記で返し、Content-Language に示します。
 func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
-	symbols, err := h.uc.ListActiveSymbols(r.Context())
+	locale := httpx.NegotiateLocale(r, symbollist.SupportedLocales)
+	symbols, stale, err := h.uc.ListActiveSymbols(r.Context(), locale)
 	if err != nil {
 		slog.Error("failed to list symbols", "error", err)
 		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
@@ -38,7 +58,89 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	}
 	out := make([]api.SymbolItem, 0, len(symbols))
 	for _, s := range symbols {
-		out = append(out, api.SymbolItem{Code: s.Code, Name: s.Name, LogoUrl: s.LogoURL})
+		out = append(out, api.SymbolItem{Code: s.Code, Name: s.Name, LogoUrl: s.LogoURL, Tier: api.SymbolTier(s.Tier.OrBasic())})
+	}
+	if stale {
+		w.Header().Set("X-Data-Stale", "true")
+	}
+	httpx.SetLocaleHeaders(w, locale)
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+// Search はコード・名前が q を含むアクティブな銘柄を、一覧と同じ並び順で limit / offset のページにして返します。
+//
+// エンドポイント例:
+// GET /v1/symbols/search?q=toyo&limit=20&offset=0
+//
+// 応答は {"items":[...],"total":N} で、total はページングによらない一致件数です。q が空なら一覧のページを返します。
+// limit は 1〜symbollist.MaxSearchLimit（省略時は symbollist.DefaultSearchLimit）、offset は 0〜symbollist.MaxSearchOffset で、範囲外は 400 を返します。
+func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := strings.TrimSpace(q.Get("q"))
+	if utf8.RuneCountInString(query) > symbollist.MaxSearchQueryLength {
+		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "q must be at most " + strconv.Itoa(symbollist.MaxSearchQueryLength) + " characters"})
+		return
+	}
+	limit := symbollist.DefaultSearchLimit
+	if s := q.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > symbollist.MaxSearchLimit {
+			httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "limit must be an integer between 1 and " + strconv.Itoa(symbollist.MaxSearchLimit)})
+			return
+		}
+		limit = n
+	}
+	offset := 0
+	if s := q.Get("offset"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 || n > symbollist.MaxSearchOffset {
+			httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "offset must be an integer between 0 and " + strconv.Itoa(symbollist.MaxSearchOffset)})
+			return
+		}
+		offset = n
+	}
+
+	locale := httpx.NegotiateLocale(r, symbollist.SupportedLocales)
+	symbols, total, err := h.uc.Search(r.Context(), query, locale, limit, offset)
+	if err != nil {
+		httpx.WriteError(w, err, "failed to search symbols", "query", query)
+		return
+	}
+	out := api.SymbolSearchPage{Items: make([]api.SymbolItem, 0, len(symbols)), Total: total}
+	for _, s := range symbols {
+		out.Items = append(out.Items, api.SymbolItem{Code: s.Code, Name: s.Name, LogoUrl: s.LogoURL, Tier: api.SymbolTier(s.Tier.OrBasic())})
+	}
+	httpx.SetLocaleHeaders(w, locale)
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+// Get は {code} の銘柄の詳細を返します。
+// 上場廃止の銘柄は status: delisted と上場廃止日（delisted_as_of）付きで返し、非表示・存在しない銘柄は 404 を返します。
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	code := chi.URLParam(r, "code")
+	if !symbolCodePattern.MatchString(code) {
+		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "invalid symbol code"})
+		return
+	}
+	locale := httpx.NegotiateLocale(r, symbollist.SupportedLocales)
+	s, err := h.uc.GetSymbol(r.Context(), code, locale)
+	if err != nil {
+		httpx.WriteError(w, err, "failed to get symbol", "code", code)
+		return
+	}
+
+	out := api.SymbolDetail{
+		Code:     s.Code,
+		Name:     s.Name,
+		Market:   s.Market,
+		Currency: s.Currency,
+		LogoUrl:  s.LogoURL,
+		Status:   api.SymbolDetailStatus(s.Status),
+		Tier:     api.SymbolTier(s.Tier.OrBasic()),
+	}
+	if d, ok := s.DelistedAsOf(); ok {
+		out.DelistedAsOf = api.NewDate(d)
 	}
+	httpx.SetLocaleHeaders(w, locale)
 	httpx.WriteJSON(w, http.StatusOK, out)
 }
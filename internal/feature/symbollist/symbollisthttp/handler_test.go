@@ -2,12 +2,17 @@ package symbollisthttp_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist/symbollisthttp"
@@ -20,14 +25,32 @@ func strPtr(s string) *string {
 // mockUsecase はUsecaseインターフェースのモック実装です。
 type mockUsecase struct {
 	ListActiveSymbolsFunc func(ctx context.Context) ([]symbollist.Symbol, error)
+	GetSymbolFunc         func(ctx context.Context, code string) (symbollist.Symbol, error)
+	SearchFunc            func(ctx context.Context, query string, limit, offset int) ([]symbollist.Symbol, int64, error)
+	Stale                 bool   // 成功時に stale として返すか
+	gotLocale             string // 最後に渡されたロケール
+}
+
+// GetSymbol はモックのGetSymbol関数を呼び出します。
+func (m *mockUsecase) GetSymbol(ctx context.Context, code, locale string) (symbollist.Symbol, error) {
+	m.gotLocale = locale
+	return m.GetSymbolFunc(ctx, code)
+}
+
+// Search はモックのSearch関数を呼び出します。
+func (m *mockUsecase) Search(ctx context.Context, query, locale string, limit, offset int) ([]symbollist.Symbol, int64, error) {
+	m.gotLocale = locale
+	return m.SearchFunc(ctx, query, limit, offset)
 }
 
 // ListActiveSymbols はモックのListActiveSymbols関数を呼び出します。
-func (m *mockUsecase) ListActiveSymbols(ctx context.Context) ([]symbollist.Symbol, error) {
+func (m *mockUsecase) ListActiveSymbols(ctx context.Context, locale string) ([]symbollist.Symbol, bool, error) {
+	m.gotLocale = locale
 	if m.ListActiveSymbolsFunc != nil {
-		return m.ListActiveSymbolsFunc(ctx)
+		symbols, err := m.ListActiveSymbolsFunc(ctx)
+		return symbols, m.Stale && err == nil, err
 	}
-	return nil, nil
+	return nil, m.Stale, nil
 }
 
 // TestNewSymbolHandler はNewHandlerコンストラクタが正しくインスタンスを生成することを検証します。
@@ -52,12 +75,12 @@ func TestSymbolHandler_List(t *testing.T) {
 			name: "success: returns list of symbols",
 			mockListActiveFunc: func(ctx context.Context) ([]symbollist.Symbol, error) {
 				return []symbollist.Symbol{
-					{ID: 1, Code: "7203.T", Name: "Toyota Motor", Market: "TSE", LogoURL: strPtr("https://api.twelvedata.com/logo/toyota.com"), IsActive: true},
-					{ID: 2, Code: "6758.T", Name: "Sony Group", Market: "TSE", IsActive: true},
+					{ID: 1, Code: "7203.T", Name: "Toyota Motor", Market: "TSE", LogoURL: strPtr("https://api.twelvedata.com/logo/toyota.com"), Status: symbollist.StatusActive},
+					{ID: 2, Code: "6758.T", Name: "Sony Group", Market: "TSE", Status: symbollist.StatusActive, Tier: symbollist.TierPremium},
 				}, nil
 			},
 			expectedStatus: http.StatusOK,
-			expectedBody:   `[{"code":"7203.T","name":"Toyota Motor","logo_url":"https://api.twelvedata.com/logo/toyota.com"},{"code":"6758.T","name":"Sony Group","logo_url":null}]`,
+			expectedBody:   `[{"code":"7203.T","name":"Toyota Motor","logo_url":"https://api.twelvedata.com/logo/toyota.com","tier":"basic"},{"code":"6758.T","name":"Sony Group","logo_url":null,"tier":"premium"}]`,
 		},
 		{
 			name: "success: returns empty list when no symbols",
@@ -71,11 +94,11 @@ func TestSymbolHandler_List(t *testing.T) {
 			name: "success: returns single symbol",
 			mockListActiveFunc: func(ctx context.Context) ([]symbollist.Symbol, error) {
 				return []symbollist.Symbol{
-					{ID: 1, Code: "9984.T", Name: "SoftBank Group", Market: "TSE", IsActive: true},
+					{ID: 1, Code: "9984.T", Name: "SoftBank Group", Market: "TSE", Status: symbollist.StatusActive},
 				}, nil
 			},
 			expectedStatus: http.StatusOK,
-			expectedBody:   `[{"code":"9984.T","name":"SoftBank Group","logo_url":null}]`,
+			expectedBody:   `[{"code":"9984.T","name":"SoftBank Group","logo_url":null,"tier":"basic"}]`,
 		},
 		{
 			name: "failure: usecase returns error",
@@ -124,12 +147,12 @@ func TestSymbolHandler_List_DTOConversion(t *testing.T) {
 		ListActiveSymbolsFunc: func(ctx context.Context) ([]symbollist.Symbol, error) {
 			return []symbollist.Symbol{
 				{
-					ID:       999,
-					Code:     "TEST.T",
-					Name:     "Test Company",
-					Market:   "NYSE",
-					LogoURL:  strPtr("https://api.twelvedata.com/logo/test.com"),
-					IsActive: true,
+					ID:      999,
+					Code:    "TEST.T",
+					Name:    "Test Company",
+					Market:  "NYSE",
+					LogoURL: strPtr("https://api.twelvedata.com/logo/test.com"),
+					Status:  symbollist.StatusActive,
 				},
 			}, nil
 		},
@@ -142,10 +165,177 @@ func TestSymbolHandler_List_DTOConversion(t *testing.T) {
 	h.List(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
-	assert.JSONEq(t, `[{"code":"TEST.T","name":"Test Company","logo_url":"https://api.twelvedata.com/logo/test.com"}]`, w.Body.String())
+	assert.JSONEq(t, `[{"code":"TEST.T","name":"Test Company","logo_url":"https://api.twelvedata.com/logo/test.com","tier":"basic"}]`, w.Body.String())
 	// 内部フィールドが公開されていないことを検証
 	assert.NotContains(t, w.Body.String(), "999")
 	assert.NotContains(t, w.Body.String(), "NYSE")
 	assert.NotContains(t, w.Body.String(), "is_active")
 	assert.NotContains(t, w.Body.String(), "sort_key")
 }
+
+// TestSymbolHandler_List_JapaneseNames は日本語の銘柄名がエスケープされず UTF-8 のまま返ることを検証します。
+func TestSymbolHandler_List_JapaneseNames(t *testing.T) {
+	t.Parallel()
+
+	names := []string{"トヨタ自動車", "NTT(日本電信電話)", "ソフトバンクグループ", "東京〜大阪運輸・ホールディングス"}
+	mockUC := &mockUsecase{
+		ListActiveSymbolsFunc: func(ctx context.Context) ([]symbollist.Symbol, error) {
+			symbols := make([]symbollist.Symbol, len(names))
+			for i, n := range names {
+				symbols[i] = symbollist.Symbol{Code: fmt.Sprintf("%d.T", 1000+i), Name: n}
+			}
+			return symbols, nil
+		},
+	}
+	h := symbollisthttp.NewHandler(mockUC)
+
+	w := httptest.NewRecorder()
+	h.List(w, httptest.NewRequest(http.MethodGet, "/symbols", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.True(t, utf8.Valid(w.Body.Bytes()))
+	var got []struct {
+		Name string `json:"name"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	for i, n := range names {
+		assert.Equal(t, n, got[i].Name)
+		assert.Contains(t, w.Body.String(), n, "\\u エスケープせずそのまま返す")
+	}
+}
+
+// TestSymbolHandler_List_Stale は last-known-good を返した場合のみ X-Data-Stale ヘッダーが付くことを検証します。
+func TestSymbolHandler_List_Stale(t *testing.T) {
+	t.Parallel()
+
+	for _, stale := range []bool{true, false} {
+		mockUC := &mockUsecase{
+			ListActiveSymbolsFunc: func(ctx context.Context) ([]symbollist.Symbol, error) {
+				return []symbollist.Symbol{{Code: "AAPL", Name: "Apple Inc."}}, nil
+			},
+			Stale: stale,
+		}
+		h := symbollisthttp.NewHandler(mockUC)
+
+		w := httptest.NewRecorder()
+		h.List(w, httptest.NewRequest(http.MethodGet, "/symbols", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[{"code":"AAPL","name":"Apple Inc.","logo_url":null,"tier":"basic"}]`, w.Body.String())
+		if stale {
+			assert.Equal(t, "true", w.Header().Get("X-Data-Stale"))
+		} else {
+			assert.Empty(t, w.Header().Get("X-Data-Stale"))
+		}
+	}
+}
+
+// TestSymbolHandler_List_Locale は Accept-Language から選んだロケールがユースケースに渡り、Content-Language に示されることを検証します。
+func TestSymbolHandler_List_Locale(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{name: "no header uses default", header: "", want: symbollist.DefaultLocale},
+		{name: "english with region", header: "en-US,en;q=0.9", want: "en"},
+		{name: "unsupported uses default", header: "fr", want: symbollist.DefaultLocale},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockUC := &mockUsecase{}
+			req := httptest.NewRequest(http.MethodGet, "/symbols", nil)
+			if tt.header != "" {
+				req.Header.Set("Accept-Language", tt.header)
+			}
+			w := httptest.NewRecorder()
+			symbollisthttp.NewHandler(mockUC).List(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.want, mockUC.gotLocale)
+			assert.Equal(t, tt.want, w.Header().Get("Content-Language"))
+			assert.Equal(t, "Accept-Language", w.Header().Get("Vary"))
+		})
+	}
+}
+
+// TestSymbolHandler_Search は検索語・limit・offset の受け渡しと既定値、{"items":[...],"total":N} の応答、
+// 不正な limit / offset / 長すぎる検索語の 400 を検証します。
+func TestSymbolHandler_Search(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		wantCall   string // ユースケースに渡る "query:limit:offset"
+	}{
+		{name: "query with paging", target: "/symbols/search?q=toyo&limit=5&offset=10", wantStatus: http.StatusOK, wantCall: "toyo:5:10"},
+		{name: "defaults", target: "/symbols/search", wantStatus: http.StatusOK, wantCall: ":20:0"},
+		{name: "query is trimmed", target: "/symbols/search?q=+toyo+", wantStatus: http.StatusOK, wantCall: "toyo:20:0"},
+		{name: "max limit", target: "/symbols/search?limit=100", wantStatus: http.StatusOK, wantCall: ":100:0"},
+		{name: "limit over max", target: "/symbols/search?limit=101", wantStatus: http.StatusBadRequest},
+		{name: "zero limit", target: "/symbols/search?limit=0", wantStatus: http.StatusBadRequest},
+		{name: "non-numeric limit", target: "/symbols/search?limit=abc", wantStatus: http.StatusBadRequest},
+		{name: "negative offset", target: "/symbols/search?offset=-1", wantStatus: http.StatusBadRequest},
+		{name: "max offset", target: "/symbols/search?offset=2147483647", wantStatus: http.StatusOK, wantCall: ":20:2147483647"},
+		{name: "offset over int32", target: "/symbols/search?offset=2147483648", wantStatus: http.StatusBadRequest},
+		{name: "query too long", target: "/symbols/search?q=" + strings.Repeat("a", symbollist.MaxSearchQueryLength+1), wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var gotCall string
+			mockUC := &mockUsecase{SearchFunc: func(ctx context.Context, query string, limit, offset int) ([]symbollist.Symbol, int64, error) {
+				gotCall = fmt.Sprintf("%s:%d:%d", query, limit, offset)
+				return []symbollist.Symbol{{Code: "7203.T", Name: "Toyota Motor", Tier: symbollist.TierPremium}}, 42, nil
+			}}
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req.Header.Set("Accept-Language", "en")
+			symbollisthttp.NewHandler(mockUC).Search(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantCall, gotCall)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			assert.JSONEq(t, `{"items":[{"code":"7203.T","name":"Toyota Motor","logo_url":null,"tier":"premium"}],"total":42}`, w.Body.String())
+			assert.Equal(t, "en", mockUC.gotLocale)
+			assert.Equal(t, "en", w.Header().Get("Content-Language"))
+		})
+	}
+}
+
+// TestSymbolHandler_Search_Empty は一致する銘柄がない場合に items を空配列（null ではない）で返すことを検証します。
+func TestSymbolHandler_Search_Empty(t *testing.T) {
+	t.Parallel()
+
+	mockUC := &mockUsecase{SearchFunc: func(ctx context.Context, query string, limit, offset int) ([]symbollist.Symbol, int64, error) {
+		return nil, 0, nil
+	}}
+	w := httptest.NewRecorder()
+	symbollisthttp.NewHandler(mockUC).Search(w, httptest.NewRequest(http.MethodGet, "/symbols/search?q=zzz", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"items":[],"total":0}`, w.Body.String())
+}
+
+// TestSymbolHandler_Search_Error はユースケースのエラーで 500 を返すことを検証します。
+func TestSymbolHandler_Search_Error(t *testing.T) {
+	t.Parallel()
+
+	mockUC := &mockUsecase{SearchFunc: func(ctx context.Context, query string, limit, offset int) ([]symbollist.Symbol, int64, error) {
+		return nil, 0, errors.New("db down")
+	}}
+	w := httptest.NewRecorder()
+	symbollisthttp.NewHandler(mockUC).Search(w, httptest.NewRequest(http.MethodGet, "/symbols/search?q=toyo", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,84 @@
+package symbollisthttp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+)
+
+// NameUsecase は銘柄名の多言語表記の管理操作のユースケースインターフェースを定義します。
+// Goの慣例に従い、インターフェースは利用者（handler）側で定義します。
+type NameUsecase interface {
+	List(ctx context.Context, code string) ([]symbollist.SymbolName, error)
+	Put(ctx context.Context, n symbollist.SymbolName) (symbollist.SymbolName, error)
+	Delete(ctx context.Context, code, locale string) error
+}
+
+// NameHandler は銘柄名の多言語表記の管理エンドポイントを処理します。
+// 認可（symbols:admin スコープ）はルーター側のミドルウェアで行います。
+type NameHandler struct {
+	uc NameUsecase
+}
+
+// NewNameHandler は NameHandler を生成します。
+func NewNameHandler(uc NameUsecase) *NameHandler {
+	return &NameHandler{uc: uc}
+}
+
+// List は {code} の銘柄に登録されている名前をロケール順に返します。
+func (h *NameHandler) List(w http.ResponseWriter, r *http.Request) {
+	code := chi.URLParam(r, "code")
+	names, err := h.uc.List(r.Context(), code)
+	if err != nil {
+		httpx.WriteError(w, err, "failed to list symbol names", "code", code)
+		return
+	}
+
+	out := make([]api.SymbolName, 0, len(names))
+	for _, n := range names {
+		out = append(out, toSymbolName(n))
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+// Put は {code} の銘柄の {locale} の名前を登録（登録済みなら上書き）し、登録した名前を返します。
+func (h *NameHandler) Put(w http.ResponseWriter, r *http.Request) {
+	code, locale := chi.URLParam(r, "code"), chi.URLParam(r, "locale")
+	var req api.PutSymbolNameRequest
+	if err := httpx.DecodeAndValidate(r, &req); err != nil {
+		httpx.WriteDecodeError(w, err, "invalid request")
+		return
+	}
+
+	n, err := h.uc.Put(r.Context(), symbollist.SymbolName{SymbolCode: code, Locale: locale, Name: req.Name})
+	if err != nil {
+		httpx.WriteError(w, err, "failed to put symbol name", "code", code, "locale", locale)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, toSymbolName(n))
+}
+
+// Delete は {code} の銘柄の {locale} の名前を削除します。
+func (h *NameHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	code, locale := chi.URLParam(r, "code"), chi.URLParam(r, "locale")
+	if err := h.uc.Delete(r.Context(), code, locale); err != nil {
+		httpx.WriteError(w, err, "failed to delete symbol name", "code", code, "locale", locale)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func toSymbolName(n symbollist.SymbolName) api.SymbolName {
+	return api.SymbolName{
+		Symbol:    n.SymbolCode,
+		Locale:    n.Locale,
+		Name:      n.Name,
+		UpdatedAt: api.NewTimestamp(n.UpdatedAt),
+	}
+}
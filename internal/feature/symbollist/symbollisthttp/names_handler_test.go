@@ -0,0 +1,150 @@
+package symbollisthttp_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist/symbollisthttp"
+)
+
+// mockNameUsecase は NameUsecase インターフェースのモック実装です。
+// 登録された名前をそのまま返し、err が設定されていれば全操作で返します。
+type mockNameUsecase struct {
+	err     error
+	put     symbollist.SymbolName
+	deleted [2]string
+}
+
+func (m *mockNameUsecase) List(_ context.Context, code string) ([]symbollist.SymbolName, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return []symbollist.SymbolName{{SymbolCode: code, Locale: "en", Name: "Toyota Motor"}}, nil
+}
+
+func (m *mockNameUsecase) Put(_ context.Context, n symbollist.SymbolName) (symbollist.SymbolName, error) {
+	m.put = n
+	return n, m.err
+}
+
+func (m *mockNameUsecase) Delete(_ context.Context, code, locale string) error {
+	m.deleted = [2]string{code, locale}
+	return m.err
+}
+
+func newNameRouter(uc symbollisthttp.NameUsecase) http.Handler {
+	h := symbollisthttp.NewNameHandler(uc)
+	r := chi.NewRouter()
+	r.Get("/admin/symbols/{code}/names", h.List)
+	r.Put("/admin/symbols/{code}/names/{locale}", h.Put)
+	r.Delete("/admin/symbols/{code}/names/{locale}", h.Delete)
+	return r
+}
+
+func TestNameHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		method   string
+		url      string
+		body     string
+		err      error
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "list",
+			method:   http.MethodGet,
+			url:      "/admin/symbols/7203.T/names",
+			wantCode: http.StatusOK,
+			wantBody: `"name":"Toyota Motor"`,
+		},
+		{
+			name:     "put",
+			method:   http.MethodPut,
+			url:      "/admin/symbols/7203.T/names/en",
+			body:     `{"name":"Toyota Motor"}`,
+			wantCode: http.StatusOK,
+			wantBody: `"locale":"en"`,
+		},
+		{
+			name:     "put: missing name",
+			method:   http.MethodPut,
+			url:      "/admin/symbols/7203.T/names/en",
+			body:     `{}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `"invalid request"`,
+		},
+		{
+			name:     "put: invalid locale",
+			method:   http.MethodPut,
+			url:      "/admin/symbols/7203.T/names/ja",
+			body:     `{"name":"トヨタ自動車"}`,
+			err:      fmt.Errorf("%w: ja names are managed in symbols.name", symbollist.ErrInvalidSymbolName),
+			wantCode: http.StatusBadRequest,
+			wantBody: `"invalid_symbol_name"`,
+		},
+		{
+			name:     "put: unknown symbol",
+			method:   http.MethodPut,
+			url:      "/admin/symbols/NOPE/names/en",
+			body:     `{"name":"Nope"}`,
+			err:      symbollist.ErrSymbolNotFound,
+			wantCode: http.StatusNotFound,
+			wantBody: `"symbol_not_found"`,
+		},
+		{
+			name:     "delete",
+			method:   http.MethodDelete,
+			url:      "/admin/symbols/7203.T/names/en",
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:     "delete: not found",
+			method:   http.MethodDelete,
+			url:      "/admin/symbols/7203.T/names/ko",
+			err:      symbollist.ErrSymbolNameNotFound,
+			wantCode: http.StatusNotFound,
+			wantBody: `"symbol_name_not_found"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			uc := &mockNameUsecase{err: tt.err}
+
+			w := httptest.NewRecorder()
+			newNameRouter(uc).ServeHTTP(w, httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+		})
+	}
+
+	t.Run("put passes the path and body to the usecase", func(t *testing.T) {
+		t.Parallel()
+		uc := &mockNameUsecase{}
+		w := httptest.NewRecorder()
+		newNameRouter(uc).ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/admin/symbols/7203.T/names/en", strings.NewReader(`{"name":"Toyota Motor"}`)))
+
+		assert.Equal(t, symbollist.SymbolName{SymbolCode: "7203.T", Locale: "en", Name: "Toyota Motor"}, uc.put)
+	})
+
+	t.Run("delete passes the path to the usecase", func(t *testing.T) {
+		t.Parallel()
+		uc := &mockNameUsecase{}
+		w := httptest.NewRecorder()
+		newNameRouter(uc).ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/admin/symbols/7203.T/names/en", nil))
+
+		assert.Equal(t, [2]string{"7203.T", "en"}, uc.deleted)
+	})
+}
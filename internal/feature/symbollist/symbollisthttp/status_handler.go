@@ -0,0 +1,56 @@
+package symbollisthttp
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+)
+
+// StatusUsecase は銘柄の状態の管理操作のユースケースインターフェースを定義します。
+type StatusUsecase interface {
+	SetStatus(ctx context.Context, code string, status symbollist.Status, since time.Time) (symbollist.Symbol, error)
+}
+
+// StatusHandler は銘柄の状態（上場廃止・非表示）の管理エンドポイントを処理します。
+// 認可（symbols:admin スコープ）はルーター側のミドルウェアで行います。
+type StatusHandler struct {
+	uc StatusUsecase
+}
+
+// NewStatusHandler は StatusHandler を生成します。
+func NewStatusHandler(uc StatusUsecase) *StatusHandler {
+	return &StatusHandler{uc: uc}
+}
+
+// Put は {code} の銘柄の状態と効力発生日を変更し、変更後の状態を返します。
+func (h *StatusHandler) Put(w http.ResponseWriter, r *http.Request) {
+	code := chi.URLParam(r, "code")
+	var req api.PutSymbolStatusRequest
+	if err := httpx.DecodeAndValidate(r, &req); err != nil {
+		httpx.WriteDecodeError(w, err, "invalid request")
+		return
+	}
+	status, err := symbollist.ParseStatus(string(req.Status))
+	if err != nil {
+		httpx.WriteError(w, err, "invalid symbol status", "code", code)
+		return
+	}
+
+	s, err := h.uc.SetStatus(r.Context(), code, status, req.EffectiveDate.Time)
+	if err != nil {
+		httpx.WriteError(w, err, "failed to set symbol status", "code", code, "status", status)
+		return
+	}
+	out := api.SymbolStatusResponse{Code: s.Code, Status: api.SymbolStatusResponseStatus(s.Status)}
+	if s.StatusSince != nil {
+		out.EffectiveDate = api.NewDate(*s.StatusSince)
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
@@ -0,0 +1,141 @@
+package symbollisthttp_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist/symbollisthttp"
+)
+
+// TestSymbolHandler_Get は銘柄の詳細を状態ごとに検証します。
+func TestSymbolHandler_Get(t *testing.T) {
+	t.Parallel()
+
+	delistedOn := time.Date(2022, 11, 8, 0, 0, 0, 0, time.UTC)
+	usd := "USD"
+	symbols := map[string]symbollist.Symbol{
+		"AAPL": {Code: "AAPL", Name: "Apple Inc.", Market: "NASDAQ", Currency: &usd, Status: symbollist.StatusActive},
+		"TWTR": {Code: "TWTR", Name: "Twitter Inc.", Market: "NYSE", Currency: &usd, Status: symbollist.StatusDelisted, StatusSince: &delistedOn},
+	}
+	uc := &mockUsecase{GetSymbolFunc: func(ctx context.Context, code string) (symbollist.Symbol, error) {
+		s, ok := symbols[code]
+		if !ok {
+			// 非表示・存在しない銘柄はユースケースが ErrSymbolNotFound を返す
+			return symbollist.Symbol{}, symbollist.ErrSymbolNotFound
+		}
+		return s, nil
+	}}
+	router := chi.NewRouter()
+	router.Get("/symbols/{code}", symbollisthttp.NewHandler(uc).Get)
+
+	tests := []struct {
+		name       string
+		code       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"アクティブ", "AAPL", http.StatusOK,
+			`{"code":"AAPL","name":"Apple Inc.","market":"NASDAQ","currency":"USD","logo_url":null,"status":"active","tier":"basic"}`},
+		{"上場廃止は上場廃止日付き", "TWTR", http.StatusOK,
+			`{"code":"TWTR","name":"Twitter Inc.","market":"NYSE","currency":"USD","logo_url":null,"status":"delisted","delisted_as_of":"2022-11-08","tier":"basic"}`},
+		{"非表示・存在しない銘柄は 404", "XXXX", http.StatusNotFound, `{"error":"symbol_not_found"}`},
+		{"不正なコード", "bad%20code", http.StatusBadRequest, `{"error":"invalid symbol code"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/symbols/"+tt.code, nil))
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
+
+// fakeStatusUsecase は SetStatus の引数を記録する StatusUsecase です。
+type fakeStatusUsecase struct {
+	err       error
+	gotCode   string
+	gotStatus symbollist.Status
+	gotSince  time.Time
+}
+
+func (f *fakeStatusUsecase) SetStatus(ctx context.Context, code string, status symbollist.Status, since time.Time) (symbollist.Symbol, error) {
+	f.gotCode, f.gotStatus, f.gotSince = code, status, since
+	if f.err != nil {
+		return symbollist.Symbol{}, f.err
+	}
+	day := time.Date(2022, 11, 8, 0, 0, 0, 0, time.UTC)
+	return symbollist.Symbol{Code: code, Status: status, StatusSince: &day}, nil
+}
+
+func TestStatusHandler_Put(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		body       string
+		ucErr      error
+		wantStatus int
+		wantBody   string
+		wantSince  time.Time
+	}{
+		{
+			name:       "上場廃止（効力発生日あり）",
+			body:       `{"status":"delisted","effectiveDate":"2022-11-08"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"code":"TWTR","status":"delisted","effectiveDate":"2022-11-08"}`,
+			wantSince:  time.Date(2022, 11, 8, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "効力発生日の省略",
+			body:       `{"status":"hidden"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"code":"TWTR","status":"hidden","effectiveDate":"2022-11-08"}`,
+		},
+		{
+			name:       "未知の状態",
+			body:       `{"status":"inactive"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"invalid_symbol_status"}`,
+		},
+		{
+			name:       "状態の指定なし",
+			body:       `{"effectiveDate":"2022-11-08"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"invalid request"}`,
+		},
+		{
+			name:       "銘柄が存在しない",
+			body:       `{"status":"delisted"}`,
+			ucErr:      symbollist.ErrSymbolNotFound,
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"error":"symbol_not_found"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			uc := &fakeStatusUsecase{err: tt.ucErr}
+			router := chi.NewRouter()
+			router.Put("/admin/symbols/{code}/status", symbollisthttp.NewStatusHandler(uc).Put)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/admin/symbols/TWTR/status", strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, "TWTR", uc.gotCode)
+				assert.True(t, tt.wantSince.Equal(uc.gotSince), "since = %v", uc.gotSince)
+			}
+		})
+	}
+}
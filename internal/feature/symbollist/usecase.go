@@ -2,6 +2,7 @@ package symbollist
 
 import (
 	"context"
+	"strings"
 )
 
 // Repository は銘柄（株式コード）データの永続化レイヤーを抽象化します。
@@ -9,19 +10,61 @@ import (
 type Repository interface {
 	// ListActive はすべてのアクティブな銘柄を返します。
 	ListActive(ctx context.Context) ([]Symbol, error)
+	// ListActiveLocalized はすべてのアクティブな銘柄を、Name を locale の名前（LocalizedName）にして返します。
+	ListActiveLocalized(ctx context.Context, locale string) ([]Symbol, error)
+}
+
+// StaleRepository は DB 障害時に最後に取得できた一覧（last-known-good）を返せる Repository です。
+// CachingRepository が実装します。
+type StaleRepository interface {
+	// ListActiveLocalizedOrStale はすべてのアクティブな銘柄を、Name を locale の名前にして返します。
+	// stale=true は DB 障害のため last-known-good の一覧（既定ロケールの名前）を返したことを示します。
+	ListActiveLocalizedOrStale(ctx context.Context, locale string) (symbols []Symbol, stale bool, err error)
+}
+
+// SymbolFinder は銘柄 1 件の詳細の取得を抽象化します。
+type SymbolFinder interface {
+	// FindVisibleLocalized は銘柄 code を、Name を locale の名前にして返します。
+	// 存在しない・非表示（hidden）の銘柄の場合は ErrSymbolNotFound を返します。
+	FindVisibleLocalized(ctx context.Context, code, locale string) (Symbol, error)
+}
+
+// SymbolSearcher はアクティブな銘柄の検索を抽象化します。
+type SymbolSearcher interface {
+	// SearchLocalized はコード・名前が query を含むアクティブな銘柄を、Name を locale の名前にして
+	// コード昇順で offset 件読み飛ばして最大 limit 件返します。total はページングによらない一致件数です。
+	SearchLocalized(ctx context.Context, query, locale string, limit, offset int) (symbols []Symbol, total int64, err error)
 }
 
 // usecase は銘柄操作のビジネスロジックを提供します。
 type usecase struct {
-	repo Repository
+	repo     StaleRepository
+	finder   SymbolFinder
+	searcher SymbolSearcher
 }
 
 // NewUsecase は指定されたリポジトリでusecaseの新しいインスタンスを生成します。
-func NewUsecase(r Repository) *usecase {
-	return &usecase{repo: r}
+// 一覧は r（last-known-good 付き）、銘柄の詳細は finder、検索は searcher から読みます。
+func NewUsecase(r StaleRepository, finder SymbolFinder, searcher SymbolSearcher) *usecase {
+	return &usecase{repo: r, finder: finder, searcher: searcher}
+}
+
+// ListActiveSymbols はリポジトリからすべてのアクティブな銘柄を、銘柄名を locale の表記にして返します。
+// stale=true の場合、返した一覧は DB 障害時の last-known-good です。
+func (u *usecase) ListActiveSymbols(ctx context.Context, locale string) ([]Symbol, bool, error) {
+	return u.repo.ListActiveLocalizedOrStale(ctx, locale)
+}
+
+// GetSymbol は銘柄 code の詳細を、銘柄名を locale の表記にして返します。
+// 上場廃止（delisted）の銘柄も返し、非表示（hidden）・存在しない銘柄は ErrSymbolNotFound を返します。
+func (u *usecase) GetSymbol(ctx context.Context, code, locale string) (Symbol, error) {
+	return u.finder.FindVisibleLocalized(ctx, strings.TrimSpace(code), locale)
 }
 
-// ListActiveSymbols はリポジトリからすべてのアクティブな銘柄を取得して返します。
-func (u *usecase) ListActiveSymbols(ctx context.Context) ([]Symbol, error) {
-	return u.repo.ListActive(ctx)
+// Search はコード・名前が query を含むアクティブな銘柄を、銘柄名を locale の表記にして一覧と同じコード昇順で
+// offset 件読み飛ばして最大 limit 件返します。total はページングによらない一致件数です。
+// query の前後の空白は無視し、空の query はアクティブな全銘柄（一覧のページ）を返します。
+// 検索は DB から直接読むため、一覧と異なり DB 障害時の last-known-good はありません。
+func (u *usecase) Search(ctx context.Context, query, locale string, limit, offset int) ([]Symbol, int64, error) {
+	return u.searcher.SearchLocalized(ctx, strings.TrimSpace(query), locale, limit, offset)
 }
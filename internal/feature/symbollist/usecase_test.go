@@ -10,17 +10,38 @@ import (
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/feature/symbollist"
 )
 
-// mockRepository はRepositoryインターフェースのモック実装です。
+// mockRepository はStaleRepository・SymbolFinderインターフェースのモック実装です。
 type mockRepository struct {
 	ListActiveFunc func(ctx context.Context) ([]symbollist.Symbol, error)
+	FindFunc       func(ctx context.Context, code string) (symbollist.Symbol, error)
+	SearchFunc     func(ctx context.Context, query string, limit, offset int) ([]symbollist.Symbol, int64, error)
+	Stale          bool   // 成功時に stale として返すか
+	gotLocale      string // 最後に渡されたロケール
 }
 
-// ListActive はモックのListActive関数を呼び出します。
-func (m *mockRepository) ListActive(ctx context.Context) ([]symbollist.Symbol, error) {
+// FindVisibleLocalized はモックのFind関数を呼び出します。
+func (m *mockRepository) FindVisibleLocalized(ctx context.Context, code, locale string) (symbollist.Symbol, error) {
+	m.gotLocale = locale
+	return m.FindFunc(ctx, code)
+}
+
+// SearchLocalized はモックのSearch関数を呼び出します。
+func (m *mockRepository) SearchLocalized(ctx context.Context, query, locale string, limit, offset int) ([]symbollist.Symbol, int64, error) {
+	m.gotLocale = locale
+	return m.SearchFunc(ctx, query, limit, offset)
+}
+
+// ListActiveLocalizedOrStale はモックのListActive関数を呼び出します。
+func (m *mockRepository) ListActiveLocalizedOrStale(ctx context.Context, locale string) ([]symbollist.Symbol, bool, error) {
+	m.gotLocale = locale
 	if m.ListActiveFunc != nil {
-		return m.ListActiveFunc(ctx)
+		symbols, err := m.ListActiveFunc(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+		return symbols, m.Stale, nil
 	}
-	return nil, nil
+	return nil, m.Stale, nil
 }
 
 // TestNewSymbolUsecase はNewUsecaseコンストラクタが正しくインスタンスを生成することを検証します。
@@ -28,7 +49,7 @@ func TestNewSymbolUsecase(t *testing.T) {
 	t.Parallel()
 
 	mockRepo := &mockRepository{}
-	uc := symbollist.NewUsecase(mockRepo)
+	uc := symbollist.NewUsecase(mockRepo, mockRepo, mockRepo)
 
 	assert.NotNil(t, uc, "usecase should not be nil")
 }
@@ -48,13 +69,13 @@ func TestSymbolUsecase_ListActiveSymbols(t *testing.T) {
 			name: "success: returns list of active symbols",
 			mockListActive: func(ctx context.Context) ([]symbollist.Symbol, error) {
 				return []symbollist.Symbol{
-					{ID: 1, Code: "7203.T", Name: "Toyota Motor", Market: "TSE", IsActive: true},
-					{ID: 2, Code: "6758.T", Name: "Sony Group", Market: "TSE", IsActive: true},
+					{ID: 1, Code: "7203.T", Name: "Toyota Motor", Market: "TSE", Status: symbollist.StatusActive},
+					{ID: 2, Code: "6758.T", Name: "Sony Group", Market: "TSE", Status: symbollist.StatusActive},
 				}, nil
 			},
 			expectedSymbols: []symbollist.Symbol{
-				{ID: 1, Code: "7203.T", Name: "Toyota Motor", Market: "TSE", IsActive: true},
-				{ID: 2, Code: "6758.T", Name: "Sony Group", Market: "TSE", IsActive: true},
+				{ID: 1, Code: "7203.T", Name: "Toyota Motor", Market: "TSE", Status: symbollist.StatusActive},
+				{ID: 2, Code: "6758.T", Name: "Sony Group", Market: "TSE", Status: symbollist.StatusActive},
 			},
 			wantErr: false,
 		},
@@ -87,11 +108,11 @@ func TestSymbolUsecase_ListActiveSymbols(t *testing.T) {
 			name: "success: returns single symbol",
 			mockListActive: func(ctx context.Context) ([]symbollist.Symbol, error) {
 				return []symbollist.Symbol{
-					{ID: 1, Code: "9984.T", Name: "SoftBank Group", Market: "TSE", IsActive: true},
+					{ID: 1, Code: "9984.T", Name: "SoftBank Group", Market: "TSE", Status: symbollist.StatusActive},
 				}, nil
 			},
 			expectedSymbols: []symbollist.Symbol{
-				{ID: 1, Code: "9984.T", Name: "SoftBank Group", Market: "TSE", IsActive: true},
+				{ID: 1, Code: "9984.T", Name: "SoftBank Group", Market: "TSE", Status: symbollist.StatusActive},
 			},
 			wantErr: false,
 		},
@@ -104,10 +125,11 @@ func TestSymbolUsecase_ListActiveSymbols(t *testing.T) {
 			mockRepo := &mockRepository{
 				ListActiveFunc: tt.mockListActive,
 			}
-			uc := symbollist.NewUsecase(mockRepo)
+			uc := symbollist.NewUsecase(mockRepo, mockRepo, mockRepo)
 
-			symbols, err := uc.ListActiveSymbols(context.Background())
+			symbols, stale, err := uc.ListActiveSymbols(context.Background(), "en")
 
+			assert.Equal(t, "en", mockRepo.gotLocale)
 			if tt.wantErr {
 				assert.Error(t, err)
 				if tt.errMsg != "" {
@@ -118,6 +140,7 @@ func TestSymbolUsecase_ListActiveSymbols(t *testing.T) {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.expectedSymbols, symbols)
 			}
+			assert.False(t, stale)
 		})
 	}
 }
@@ -134,11 +157,74 @@ func TestSymbolUsecase_ListActiveSymbols_ContextCancellation(t *testing.T) {
 			return nil, ctx.Err()
 		},
 	}
-	uc := symbollist.NewUsecase(mockRepo)
+	uc := symbollist.NewUsecase(mockRepo, mockRepo, mockRepo)
 
-	symbols, err := uc.ListActiveSymbols(ctx)
+	symbols, _, err := uc.ListActiveSymbols(ctx, symbollist.DefaultLocale)
 
 	assert.Error(t, err)
 	assert.Nil(t, symbols)
 	assert.ErrorIs(t, err, context.Canceled)
 }
+
+// TestSymbolUsecase_ListActiveSymbols_Stale はリポジトリが last-known-good を返した場合に stale が伝わることを検証します。
+func TestSymbolUsecase_ListActiveSymbols_Stale(t *testing.T) {
+	t.Parallel()
+
+	want := []symbollist.Symbol{{ID: 1, Code: "AAPL", Name: "Apple Inc.", Market: "NASDAQ", Status: symbollist.StatusActive}}
+	mockRepo := &mockRepository{
+		ListActiveFunc: func(ctx context.Context) ([]symbollist.Symbol, error) { return want, nil },
+		Stale:          true,
+	}
+	uc := symbollist.NewUsecase(mockRepo, mockRepo, mockRepo)
+
+	symbols, stale, err := uc.ListActiveSymbols(context.Background(), symbollist.DefaultLocale)
+
+	assert.NoError(t, err)
+	assert.True(t, stale)
+	assert.Equal(t, want, symbols)
+}
+
+// TestSymbolUsecase_GetSymbol は銘柄の詳細の取得で、コードの前後の空白を除きロケールを渡すことを検証します。
+func TestSymbolUsecase_GetSymbol(t *testing.T) {
+	t.Parallel()
+
+	want := symbollist.Symbol{Code: "TWTR", Name: "Twitter Inc.", Status: symbollist.StatusDelisted}
+	mockRepo := &mockRepository{FindFunc: func(ctx context.Context, code string) (symbollist.Symbol, error) {
+		if code != "TWTR" {
+			return symbollist.Symbol{}, symbollist.ErrSymbolNotFound
+		}
+		return want, nil
+	}}
+	uc := symbollist.NewUsecase(mockRepo, mockRepo, mockRepo)
+
+	got, err := uc.GetSymbol(context.Background(), " TWTR ", "en")
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+	assert.Equal(t, "en", mockRepo.gotLocale)
+
+	_, err = uc.GetSymbol(context.Background(), "XXXX", "en")
+	assert.ErrorIs(t, err, symbollist.ErrSymbolNotFound)
+}
+
+// TestSymbolUsecase_Search は検索語の前後の空白を除き、ロケール・limit・offset と件数をそのまま受け渡すことを検証します。
+func TestSymbolUsecase_Search(t *testing.T) {
+	t.Parallel()
+
+	want := []symbollist.Symbol{{Code: "7203.T", Name: "Toyota Motor", Status: symbollist.StatusActive}}
+	var gotQuery string
+	var gotLimit, gotOffset int
+	mockRepo := &mockRepository{SearchFunc: func(ctx context.Context, query string, limit, offset int) ([]symbollist.Symbol, int64, error) {
+		gotQuery, gotLimit, gotOffset = query, limit, offset
+		return want, 41, nil
+	}}
+	uc := symbollist.NewUsecase(mockRepo, mockRepo, mockRepo)
+
+	got, total, err := uc.Search(context.Background(), "  toyo ", "en", 20, 40)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+	assert.Equal(t, int64(41), total)
+	assert.Equal(t, "toyo", gotQuery)
+	assert.Equal(t, 20, gotLimit)
+	assert.Equal(t, 40, gotOffset)
+	assert.Equal(t, "en", mockRepo.gotLocale)
+}
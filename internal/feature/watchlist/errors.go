@@ -1,14 +1,22 @@
 package watchlist
 
-import "errors"
+import "github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
 
+// Code は既存クライアントとの互換性のため、従来のレスポンス本文と同じ文字列を維持しています。
 var (
-	// ErrSymbolNotFound は指定された銘柄コードが symbols テーブルに存在しない場合のエラーです。
-	ErrSymbolNotFound = errors.New("symbol not found")
+	// ErrSymbolNotFound は追加する銘柄コードが symbols テーブルに存在しないか、アクティブでない（上場廃止・非表示）場合のエラーです。
+	// リクエストの形式は正しいが参照先の銘柄を追加できないため 422 です。
+	ErrSymbolNotFound = apperr.New(apperr.KindUnprocessable, "symbol not found", "symbol not found or not active")
+
+	// ErrWatchlistFull はウォッチリストが既に上限（MaxItems 件）に達している場合のエラーです。
+	ErrWatchlistFull = apperr.New(apperr.KindUnprocessable, "watchlist full", "watchlist is full")
 
 	// ErrAlreadyInWatchlist は銘柄が既にウォッチリストに存在する場合のエラーです。
-	ErrAlreadyInWatchlist = errors.New("symbol already in watchlist")
+	ErrAlreadyInWatchlist = apperr.New(apperr.KindConflict, "symbol already in watchlist", "symbol already in watchlist")
 
 	// ErrNotInWatchlist は削除対象の銘柄がウォッチリストに存在しない場合のエラーです。
-	ErrNotInWatchlist = errors.New("symbol not in watchlist")
+	ErrNotInWatchlist = apperr.New(apperr.KindNotFound, "symbol not in watchlist", "symbol not in watchlist")
+
+	// ErrVersionMismatch はクライアントが見たウォッチリストのバージョン（If-Match）が現在のバージョンと一致しない場合のエラーです。
+	ErrVersionMismatch = apperr.New(apperr.KindPreconditionFailed, "version mismatch", "watchlist version mismatch")
 )
@@ -49,7 +49,12 @@ func (r *repository) ListByUser(ctx context.Context, userID int64) ([]UserSymbol
 	return out, nil
 }
 
-// Add はウォッチリストに銘柄を追加します。
+// Version はユーザーのウォッチリストのバージョンを返します（一度も変更していなければ 0）。
+func (r *repository) Version(ctx context.Context, userID int64) (int64, error) {
+	return r.q.GetWatchlistVersion(ctx, userID)
+}
+
+// Add はウォッチリストに銘柄を追加します。バージョンは増やしません（サインアップ時の初期化用）。
 // 重複エントリは ErrAlreadyInWatchlist、FK 違反は ErrSymbolNotFound を返します。
 func (r *repository) Add(ctx context.Context, entry UserSymbol) error {
 	err := r.q.InsertWatchlist(ctx, watchlistsqlc.InsertWatchlistParams{
@@ -60,10 +65,22 @@ func (r *repository) Add(ctx context.Context, entry UserSymbol) error {
 	return mapWatchlistPGErr(err)
 }
 
-// Remove はウォッチリストから銘柄を削除します。
+// Remove はウォッチリストから銘柄を削除し、同じトランザクションでバージョンを増やします。
 // 対象が存在しない場合は ErrNotInWatchlist を返します。
 func (r *repository) Remove(ctx context.Context, userID int64, symbolCode string) error {
-	rowsAffected, err := r.q.DeleteWatchlist(ctx, watchlistsqlc.DeleteWatchlistParams{
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback()
+		}
+	}()
+	qtx := r.q.WithTx(tx)
+
+	rowsAffected, err := qtx.DeleteWatchlist(ctx, watchlistsqlc.DeleteWatchlistParams{
 		UserID:     userID,
 		SymbolCode: symbolCode,
 	})
@@ -73,16 +90,27 @@ func (r *repository) Remove(ctx context.Context, userID int64, symbolCode string
 	if rowsAffected == 0 {
 		return ErrNotInWatchlist
 	}
+	if _, err := qtx.BumpWatchlistVersion(ctx, userID); err != nil {
+		return fmt.Errorf("bump watchlist version: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	committed = true
 	return nil
 }
 
-// UpdateSortKeys はウォッチリストの sort_key をトランザクション内で一括更新します。
+// UpdateSortKeys はウォッチリストの sort_key をトランザクション内で一括更新し、更新後のバージョンを返します。
 // (user_id, sort_key) のユニーク制約が一時的に違反しないよう、まず全レコードを
 // 負値（-(i+1)）にシフトしてから最終値に更新します。
-func (r *repository) UpdateSortKeys(ctx context.Context, userID int64, entries []UserSymbol) error {
+//
+// ifVersion が nil でない場合は、現在のバージョンが *ifVersion の場合だけ更新します（一致しなければ ErrVersionMismatch）。
+// 判定は WHERE version = ? の条件付き UPDATE の結果で行うため、読み取りと更新の間に他の更新が割り込むことはありません
+// （並行した条件付き更新は行のロックで直列化され、成功するのは 1 つだけ）。
+func (r *repository) UpdateSortKeys(ctx context.Context, userID int64, entries []UserSymbol, ifVersion *int64) (int64, error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("begin tx: %w", err)
+		return 0, fmt.Errorf("begin tx: %w", err)
 	}
 	committed := false
 	defer func() {
@@ -92,6 +120,23 @@ func (r *repository) UpdateSortKeys(ctx context.Context, userID int64, entries [
 	}()
 	qtx := r.q.WithTx(tx)
 
+	// バージョンを先に進める（並び替えより前に行をロックし、不一致なら何も書き込まない）
+	var version int64
+	if ifVersion != nil {
+		if err := qtx.EnsureWatchlistVersion(ctx, userID); err != nil {
+			return 0, fmt.Errorf("ensure watchlist version: %w", err)
+		}
+		version, err = qtx.BumpWatchlistVersionIf(ctx, watchlistsqlc.BumpWatchlistVersionIfParams{UserID: userID, Expected: *ifVersion})
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrVersionMismatch
+		}
+	} else {
+		version, err = qtx.BumpWatchlistVersion(ctx, userID)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("bump watchlist version: %w", err)
+	}
+
 	// Phase 1: 負値にシフト
 	for i, e := range entries {
 		if _, err := qtx.UpdateWatchlistSortKey(ctx, watchlistsqlc.UpdateWatchlistSortKeyParams{
@@ -99,7 +144,7 @@ func (r *repository) UpdateSortKeys(ctx context.Context, userID int64, entries [
 			SymbolCode: e.SymbolCode,
 			SortKey:    int64(-(i + 1)),
 		}); err != nil {
-			return err
+			return 0, err
 		}
 	}
 	// Phase 2: 最終値に更新
@@ -109,20 +154,22 @@ func (r *repository) UpdateSortKeys(ctx context.Context, userID int64, entries [
 			SymbolCode: e.SymbolCode,
 			SortKey:    int64(e.SortKey),
 		}); err != nil {
-			return err
+			return 0, err
 		}
 	}
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit tx: %w", err)
+		return 0, fmt.Errorf("commit tx: %w", err)
 	}
 	committed = true
-	return nil
+	return version, nil
 }
 
-// AddWithNextSortKey は sort_key をトランザクション内で MAX+1 採番して銘柄を追加します。
+// AddWithNextSortKey は sort_key をトランザクション内で MAX+1 採番して銘柄を追加し、バージョンを増やします。
 // MAX(sort_key) 取得と INSERT を同一トランザクションで実行し、(user_id, sort_key) ユニーク制約で
 // 並行追加の二重登録を最終的にブロックします。
-func (r *repository) AddWithNextSortKey(ctx context.Context, userID int64, symbolCode string) error {
+// 既に maxItems 件ある場合は追加せず ErrWatchlistFull を返します。件数の確認はユーザーの行をロックしてから行うため、
+// 同じユーザーの並行追加で上限を超えることはありません。
+func (r *repository) AddWithNextSortKey(ctx context.Context, userID int64, symbolCode string, maxItems int) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("begin tx: %w", err)
@@ -135,6 +182,16 @@ func (r *repository) AddWithNextSortKey(ctx context.Context, userID int64, symbo
 	}()
 	qtx := r.q.WithTx(tx)
 
+	if err := qtx.LockWatchlistUser(ctx, userID); err != nil {
+		return fmt.Errorf("lock watchlist user: %w", err)
+	}
+	count, err := qtx.CountWatchlistByUser(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("count watchlist: %w", err)
+	}
+	if count >= int64(maxItems) {
+		return ErrWatchlistFull
+	}
 	maxKey, err := qtx.MaxWatchlistSortKey(ctx, userID)
 	if err != nil {
 		return err
@@ -146,6 +203,9 @@ func (r *repository) AddWithNextSortKey(ctx context.Context, userID int64, symbo
 	}); err != nil {
 		return mapWatchlistPGErr(err)
 	}
+	if _, err := qtx.BumpWatchlistVersion(ctx, userID); err != nil {
+		return fmt.Errorf("bump watchlist version: %w", err)
+	}
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("commit tx: %w", err)
 	}
@@ -3,8 +3,10 @@ package watchlist
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"log"
 	"os"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -123,9 +125,9 @@ func TestWatchlistRepository_AddWithNextSortKey(t *testing.T) {
 	db, ids := setupTestDB(t)
 	repo := NewRepository(db)
 
-	require.NoError(t, repo.AddWithNextSortKey(context.Background(), ids.u1, "AAPL"))
-	require.NoError(t, repo.AddWithNextSortKey(context.Background(), ids.u1, "GOOGL"))
-	require.NoError(t, repo.AddWithNextSortKey(context.Background(), ids.u1, "MSFT"))
+	require.NoError(t, repo.AddWithNextSortKey(context.Background(), ids.u1, "AAPL", MaxItems))
+	require.NoError(t, repo.AddWithNextSortKey(context.Background(), ids.u1, "GOOGL", MaxItems))
+	require.NoError(t, repo.AddWithNextSortKey(context.Background(), ids.u1, "MSFT", MaxItems))
 
 	list, err := repo.ListByUser(context.Background(), ids.u1)
 	require.NoError(t, err)
@@ -143,7 +145,7 @@ func TestWatchlistRepository_AddWithNextSortKey_FirstEntry(t *testing.T) {
 	db, ids := setupTestDB(t)
 	repo := NewRepository(db)
 
-	require.NoError(t, repo.AddWithNextSortKey(context.Background(), ids.u1, "AAPL"))
+	require.NoError(t, repo.AddWithNextSortKey(context.Background(), ids.u1, "AAPL", MaxItems))
 
 	list, err := repo.ListByUser(context.Background(), ids.u1)
 	require.NoError(t, err)
@@ -156,11 +158,52 @@ func TestWatchlistRepository_AddWithNextSortKey_DuplicateSymbol(t *testing.T) {
 	db, ids := setupTestDB(t)
 	repo := NewRepository(db)
 
-	require.NoError(t, repo.AddWithNextSortKey(context.Background(), ids.u1, "AAPL"))
-	err := repo.AddWithNextSortKey(context.Background(), ids.u1, "AAPL")
+	require.NoError(t, repo.AddWithNextSortKey(context.Background(), ids.u1, "AAPL", MaxItems))
+	err := repo.AddWithNextSortKey(context.Background(), ids.u1, "AAPL", MaxItems)
 	assert.ErrorIs(t, err, ErrAlreadyInWatchlist)
 }
 
+// TestWatchlistRepository_AddWithNextSortKey_Full は上限の件数に達したウォッチリストへの追加を ErrWatchlistFull とし、
+// 並行した追加でも上限を超えないことを検証します。
+func TestWatchlistRepository_AddWithNextSortKey_Full(t *testing.T) {
+	t.Parallel()
+	db, ids := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	require.NoError(t, repo.AddWithNextSortKey(ctx, ids.u1, "AAPL", 2))
+	require.NoError(t, repo.AddWithNextSortKey(ctx, ids.u1, "GOOGL", 2))
+	assert.ErrorIs(t, repo.AddWithNextSortKey(ctx, ids.u1, "MSFT", 2), ErrWatchlistFull)
+	require.NoError(t, repo.AddWithNextSortKey(ctx, ids.u2, "MSFT", 2), "上限はユーザーごと")
+
+	list, err := repo.ListByUser(ctx, ids.u1)
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+	version, err := repo.Version(ctx, ids.u1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), version, "追加しなかった場合はバージョンを増やさない")
+
+	// 並行した追加: u2（1 件・上限 2）に 3 銘柄を同時に追加しても、成功するのは残りの 1 件分だけ
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for i, code := range []string{"AAPL", "GOOGL", "MSFT"} {
+		wg.Go(func() { errs[i] = repo.AddWithNextSortKey(ctx, ids.u2, code, 2) })
+	}
+	wg.Wait()
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.True(t, errors.Is(err, ErrWatchlistFull) || errors.Is(err, ErrAlreadyInWatchlist), "unexpected error: %v", err)
+	}
+	assert.Equal(t, 1, succeeded)
+	list, err = repo.ListByUser(ctx, ids.u2)
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+}
+
 func TestWatchlistRepository_UpdateSortKeys(t *testing.T) {
 	t.Parallel()
 	db, ids := setupTestDB(t)
@@ -172,11 +215,13 @@ func TestWatchlistRepository_UpdateSortKeys(t *testing.T) {
 	require.NoError(t, repo.Add(ctx, UserSymbol{UserID: ids.u1, SymbolCode: "MSFT", SortKey: 2}))
 
 	// 並び替え: MSFT(0), AAPL(1), GOOGL(2)
-	require.NoError(t, repo.UpdateSortKeys(ctx, ids.u1, []UserSymbol{
+	version, err := repo.UpdateSortKeys(ctx, ids.u1, []UserSymbol{
 		{SymbolCode: "MSFT", SortKey: 0},
 		{SymbolCode: "AAPL", SortKey: 1},
 		{SymbolCode: "GOOGL", SortKey: 2},
-	}))
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), version)
 
 	list, err := repo.ListByUser(ctx, ids.u1)
 	require.NoError(t, err)
@@ -186,6 +231,108 @@ func TestWatchlistRepository_UpdateSortKeys(t *testing.T) {
 	assert.Equal(t, "GOOGL", list[2].SymbolCode)
 }
 
+func TestWatchlistRepository_Version(t *testing.T) {
+	t.Parallel()
+	db, ids := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	version, err := repo.Version(ctx, ids.u1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), version, "一度も変更していなければ 0")
+
+	// 追加・削除・並び替えのたびに増える
+	require.NoError(t, repo.AddWithNextSortKey(ctx, ids.u1, "AAPL", MaxItems))
+	require.NoError(t, repo.AddWithNextSortKey(ctx, ids.u1, "MSFT", MaxItems))
+	require.NoError(t, repo.Remove(ctx, ids.u1, "AAPL"))
+	version, err = repo.Version(ctx, ids.u1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), version)
+
+	other, err := repo.Version(ctx, ids.u2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), other, "他のユーザーのバージョンは変わらない")
+}
+
+func TestWatchlistRepository_UpdateSortKeys_IfVersion(t *testing.T) {
+	t.Parallel()
+	db, ids := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	require.NoError(t, repo.Add(ctx, UserSymbol{UserID: ids.u1, SymbolCode: "AAPL", SortKey: 0}))
+	require.NoError(t, repo.Add(ctx, UserSymbol{UserID: ids.u1, SymbolCode: "MSFT", SortKey: 1}))
+	reorder := []UserSymbol{{SymbolCode: "MSFT", SortKey: 0}, {SymbolCode: "AAPL", SortKey: 1}}
+
+	// 初回（行がない状態）でも見たバージョン 0 なら更新できる
+	seen := int64(0)
+	version, err := repo.UpdateSortKeys(ctx, ids.u1, reorder, &seen)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), version)
+
+	// 古いバージョンでは更新しない（並び順も変わらない）
+	_, err = repo.UpdateSortKeys(ctx, ids.u1, []UserSymbol{{SymbolCode: "AAPL", SortKey: 0}, {SymbolCode: "MSFT", SortKey: 1}}, &seen)
+	assert.ErrorIs(t, err, ErrVersionMismatch)
+	list, err := repo.ListByUser(ctx, ids.u1)
+	require.NoError(t, err)
+	assert.Equal(t, "MSFT", list[0].SymbolCode)
+
+	current, err := repo.Version(ctx, ids.u1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), current)
+}
+
+// TestWatchlistRepository_UpdateSortKeys_ConcurrentIfVersion は同じバージョンを見た 2 つの条件付き更新を並行に実行すると、
+// 成功するのがちょうど 1 つであることを検証します。
+func TestWatchlistRepository_UpdateSortKeys_ConcurrentIfVersion(t *testing.T) {
+	t.Parallel()
+	db, ids := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	require.NoError(t, repo.Add(ctx, UserSymbol{UserID: ids.u1, SymbolCode: "AAPL", SortKey: 0}))
+	require.NoError(t, repo.Add(ctx, UserSymbol{UserID: ids.u1, SymbolCode: "MSFT", SortKey: 1}))
+
+	for round := range 5 {
+		seen, err := repo.Version(ctx, ids.u1)
+		require.NoError(t, err)
+
+		orders := [][]UserSymbol{
+			{{SymbolCode: "MSFT", SortKey: 0}, {SymbolCode: "AAPL", SortKey: 1}},
+			{{SymbolCode: "AAPL", SortKey: 0}, {SymbolCode: "MSFT", SortKey: 1}},
+		}
+		errs := make([]error, len(orders))
+		start := make(chan struct{})
+		var wg sync.WaitGroup
+		for i, order := range orders {
+			wg.Go(func() {
+				<-start
+				_, errs[i] = repo.UpdateSortKeys(ctx, ids.u1, order, &seen)
+			})
+		}
+		close(start)
+		wg.Wait()
+
+		var won, lost int
+		for _, err := range errs {
+			switch {
+			case err == nil:
+				won++
+			case errors.Is(err, ErrVersionMismatch):
+				lost++
+			default:
+				t.Fatalf("round %d: unexpected error: %v", round, err)
+			}
+		}
+		assert.Equal(t, 1, won, "round %d: exactly one update wins", round)
+		assert.Equal(t, 1, lost, "round %d: the other gets ErrVersionMismatch", round)
+
+		version, err := repo.Version(ctx, ids.u1)
+		require.NoError(t, err)
+		assert.Equal(t, seen+1, version, "round %d: version advances once", round)
+	}
+}
+
 func TestWatchlistRepository_ListByUser_Isolation(t *testing.T) {
 	t.Parallel()
 	db, ids := setupTestDB(t)
@@ -9,9 +9,20 @@ import (
 )
 
 type Querier interface {
+	BumpWatchlistVersion(ctx context.Context, userID int64) (int64, error)
+	// 現在のバージョンが expected の場合だけ増やす。一致しなければ行を返さない（sql.ErrNoRows）。
+	BumpWatchlistVersionIf(ctx context.Context, arg BumpWatchlistVersionIfParams) (int64, error)
+	CountWatchlistByUser(ctx context.Context, userID int64) (int64, error)
 	DeleteWatchlist(ctx context.Context, arg DeleteWatchlistParams) (int64, error)
+	// 条件付きの更新（BumpWatchlistVersionIf）の前に行を用意する（行がないと初回の更新が一致しないため）。
+	EnsureWatchlistVersion(ctx context.Context, userID int64) error
+	// 一度も書き込んでいない場合は 0。
+	GetWatchlistVersion(ctx context.Context, userID int64) (int64, error)
 	InsertWatchlist(ctx context.Context, arg InsertWatchlistParams) error
 	ListWatchlistByUser(ctx context.Context, userID int64) ([]Watchlist, error)
+	// 件数の上限の確認と追加を直列化する（同じユーザーの並行追加が上限を超えないよう、ユーザーの行をロックする）。
+	// FOR NO KEY UPDATE は他テーブルの FK 参照（FOR KEY SHARE）とは競合しない。
+	LockWatchlistUser(ctx context.Context, id int64) error
 	MaxWatchlistSortKey(ctx context.Context, userID int64) (int64, error)
 	UpdateWatchlistSortKey(ctx context.Context, arg UpdateWatchlistSortKeyParams) (int64, error)
 }
@@ -17,8 +17,44 @@ SELECT COALESCE(MAX(sort_key), -1)::bigint AS max_key
 FROM watchlists
 WHERE user_id = $1;
 
+-- name: LockWatchlistUser :exec
+-- 件数の上限の確認と追加を直列化する（同じユーザーの並行追加が上限を超えないよう、ユーザーの行をロックする）。
+-- FOR NO KEY UPDATE は他テーブルの FK 参照（FOR KEY SHARE）とは競合しない。
+SELECT 1 FROM users WHERE id = $1 FOR NO KEY UPDATE;
+
+-- name: CountWatchlistByUser :one
+SELECT COUNT(*)
+FROM watchlists
+WHERE user_id = $1;
+
 -- name: UpdateWatchlistSortKey :execrows
 UPDATE watchlists
 SET sort_key = $3,
     updated_at = now()
 WHERE user_id = $1 AND symbol_code = $2;
+
+-- name: GetWatchlistVersion :one
+-- 一度も書き込んでいない場合は 0。
+SELECT COALESCE((
+    SELECT version FROM user_resource_versions WHERE user_id = $1 AND resource = 'watchlist'
+), 0)::bigint AS version;
+
+-- name: EnsureWatchlistVersion :exec
+-- 条件付きの更新（BumpWatchlistVersionIf）の前に行を用意する（行がないと初回の更新が一致しないため）。
+INSERT INTO user_resource_versions (user_id, resource)
+VALUES ($1, 'watchlist')
+ON CONFLICT (user_id, resource) DO NOTHING;
+
+-- name: BumpWatchlistVersion :one
+INSERT INTO user_resource_versions (user_id, resource, version)
+VALUES ($1, 'watchlist', 1)
+ON CONFLICT (user_id, resource) DO UPDATE
+SET version = user_resource_versions.version + 1
+RETURNING version;
+
+-- name: BumpWatchlistVersionIf :one
+-- 現在のバージョンが expected の場合だけ増やす。一致しなければ行を返さない（sql.ErrNoRows）。
+UPDATE user_resource_versions
+SET version = version + 1
+WHERE user_id = sqlc.arg(user_id) AND resource = 'watchlist' AND version = sqlc.arg(expected)
+RETURNING version;
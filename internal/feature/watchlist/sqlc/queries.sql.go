@@ -9,6 +9,54 @@ import (
 	"context"
 )
 
+const bumpWatchlistVersion = `-- name: BumpWatchlistVersion :one
+INSERT INTO user_resource_versions (user_id, resource, version)
+VALUES ($1, 'watchlist', 1)
+ON CONFLICT (user_id, resource) DO UPDATE
+SET version = user_resource_versions.version + 1
+RETURNING version
+`
+
+func (q *Queries) BumpWatchlistVersion(ctx context.Context, userID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, bumpWatchlistVersion, userID)
+	var version int64
+	err := row.Scan(&version)
+	return version, err
+}
+
+const bumpWatchlistVersionIf = `-- name: BumpWatchlistVersionIf :one
+UPDATE user_resource_versions
+SET version = version + 1
+WHERE user_id = $1 AND resource = 'watchlist' AND version = $2
+RETURNING version
+`
+
+type BumpWatchlistVersionIfParams struct {
+	UserID   int64
+	Expected int64
+}
+
+// 現在のバージョンが expected の場合だけ増やす。一致しなければ行を返さない（sql.ErrNoRows）。
+func (q *Queries) BumpWatchlistVersionIf(ctx context.Context, arg BumpWatchlistVersionIfParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, bumpWatchlistVersionIf, arg.UserID, arg.Expected)
+	var version int64
+	err := row.Scan(&version)
+	return version, err
+}
+
+const countWatchlistByUser = `-- name: CountWatchlistByUser :one
+SELECT COUNT(*)
+FROM watchlists
+WHERE user_id = $1
+`
+
+func (q *Queries) CountWatchlistByUser(ctx context.Context, userID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countWatchlistByUser, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const deleteWatchlist = `-- name: DeleteWatchlist :execrows
 DELETE FROM watchlists
 WHERE user_id = $1 AND symbol_code = $2
@@ -27,6 +75,32 @@ func (q *Queries) DeleteWatchlist(ctx context.Context, arg DeleteWatchlistParams
 	return result.RowsAffected()
 }
 
+const ensureWatchlistVersion = `-- name: EnsureWatchlistVersion :exec
+INSERT INTO user_resource_versions (user_id, resource)
+VALUES ($1, 'watchlist')
+ON CONFLICT (user_id, resource) DO NOTHING
+`
+
+// 条件付きの更新（BumpWatchlistVersionIf）の前に行を用意する（行がないと初回の更新が一致しないため）。
+func (q *Queries) EnsureWatchlistVersion(ctx context.Context, userID int64) error {
+	_, err := q.db.ExecContext(ctx, ensureWatchlistVersion, userID)
+	return err
+}
+
+const getWatchlistVersion = `-- name: GetWatchlistVersion :one
+SELECT COALESCE((
+    SELECT version FROM user_resource_versions WHERE user_id = $1 AND resource = 'watchlist'
+), 0)::bigint AS version
+`
+
+// 一度も書き込んでいない場合は 0。
+func (q *Queries) GetWatchlistVersion(ctx context.Context, userID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getWatchlistVersion, userID)
+	var version int64
+	err := row.Scan(&version)
+	return version, err
+}
+
 const insertWatchlist = `-- name: InsertWatchlist :exec
 INSERT INTO watchlists (user_id, symbol_code, sort_key)
 VALUES ($1, $2, $3)
@@ -80,6 +154,17 @@ func (q *Queries) ListWatchlistByUser(ctx context.Context, userID int64) ([]Watc
 	return items, nil
 }
 
+const lockWatchlistUser = `-- name: LockWatchlistUser :exec
+SELECT 1 FROM users WHERE id = $1 FOR NO KEY UPDATE
+`
+
+// 件数の上限の確認と追加を直列化する（同じユーザーの並行追加が上限を超えないよう、ユーザーの行をロックする）。
+// FOR NO KEY UPDATE は他テーブルの FK 参照（FOR KEY SHARE）とは競合しない。
+func (q *Queries) LockWatchlistUser(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, lockWatchlistUser, id)
+	return err
+}
+
 const maxWatchlistSortKey = `-- name: MaxWatchlistSortKey :one
 SELECT COALESCE(MAX(sort_key), -1)::bigint AS max_key
 FROM watchlists
@@ -6,54 +6,72 @@ import (
 	"log/slog"
 )
 
+// MaxItems はユーザーごとのウォッチリストに登録できる銘柄の上限です。
+const MaxItems = 50
+
 // Repository はウォッチリスト操作の永続化層を抽象化します。
 type Repository interface {
 	ListByUser(ctx context.Context, userID int64) ([]UserSymbol, error)
+	// Version はウォッチリストのバージョン（追加・削除・並び替えのたびに増える）を返します。
+	Version(ctx context.Context, userID int64) (int64, error)
 	// Add はsort_keyを指定してウォッチリストに銘柄を追加します。
 	Add(ctx context.Context, entry UserSymbol) error
 	// AddWithNextSortKey はsort_keyをトランザクション内でMAX+1採番して銘柄を追加します。
 	// MaxSortKey取得とInsertをアトミックに実行するため、並行追加時の重複順位を防ぎます。
-	AddWithNextSortKey(ctx context.Context, userID int64, symbolCode string) error
+	// 既に maxItems 件ある場合は追加せず ErrWatchlistFull を返します。
+	AddWithNextSortKey(ctx context.Context, userID int64, symbolCode string, maxItems int) error
 	Remove(ctx context.Context, userID int64, symbolCode string) error
-	UpdateSortKeys(ctx context.Context, userID int64, entries []UserSymbol) error
+	// UpdateSortKeys は並び順を更新し、更新後のバージョンを返します。
+	// ifVersion が nil でなければ現在のバージョンと一致する場合だけ更新します（不一致は ErrVersionMismatch）。
+	UpdateSortKeys(ctx context.Context, userID int64, entries []UserSymbol, ifVersion *int64) (int64, error)
 }
 
-// SymbolExistsChecker は銘柄の存在確認を行うインターフェースです。
+// SymbolActiveChecker は銘柄がアクティブ（上場廃止・非表示でない）かの確認を行うインターフェースです。
 // watchlist usecase が symbollist feature に直接依存しないよう、
 // 最小限の読み取り専用インターフェースをここで定義します。
-type SymbolExistsChecker interface {
-	Exists(ctx context.Context, code string) (bool, error)
+type SymbolActiveChecker interface {
+	IsActive(ctx context.Context, code string) (bool, error)
 }
 
 // usecase はウォッチリスト操作のビジネスロジックを提供します。
 type usecase struct {
 	repo          Repository
-	symbolChecker SymbolExistsChecker
+	symbolChecker SymbolActiveChecker
 }
 
 // NewUsecase は指定されたリポジトリと銘柄チェッカーで usecase の新しいインスタンスを生成します。
-func NewUsecase(repo Repository, symbolChecker SymbolExistsChecker) *usecase {
+func NewUsecase(repo Repository, symbolChecker SymbolActiveChecker) *usecase {
 	return &usecase{repo: repo, symbolChecker: symbolChecker}
 }
 
-// ListUserSymbols はユーザーのウォッチリストをソート順で返します。
-func (u *usecase) ListUserSymbols(ctx context.Context, userID int64) ([]UserSymbol, error) {
-	return u.repo.ListByUser(ctx, userID)
+// Snapshot はユーザーのウォッチリストをソート順で、バージョンとともに返します。
+// バージョンを一覧より先に読むため、間に更新が入った場合のバージョンは一覧より古くなります
+// （その場合の並び替えは 412 になり、古い一覧に基づく並び替えが成功することはありません）。
+func (u *usecase) Snapshot(ctx context.Context, userID int64) (Snapshot, error) {
+	version, err := u.repo.Version(ctx, userID)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("get watchlist version: %w", err)
+	}
+	items, err := u.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return Snapshot{Items: items, Version: version}, nil
 }
 
 // AddSymbol はウォッチリストに銘柄を追加します。
-// symbols テーブルに存在しない銘柄コードの場合は ErrSymbolNotFound を返します。
-// 既にウォッチリストに存在する場合は ErrAlreadyInWatchlist を返します。
+// symbols テーブルに存在しない・アクティブでない（上場廃止・非表示の）銘柄コードの場合は ErrSymbolNotFound を返します。
+// 既にウォッチリストに存在する場合は ErrAlreadyInWatchlist、既に MaxItems 件ある場合は ErrWatchlistFull を返します。
 func (u *usecase) AddSymbol(ctx context.Context, userID int64, symbolCode string) error {
-	exists, err := u.symbolChecker.Exists(ctx, symbolCode)
+	active, err := u.symbolChecker.IsActive(ctx, symbolCode)
 	if err != nil {
 		return fmt.Errorf("checking symbol existence: %w", err)
 	}
-	if !exists {
+	if !active {
 		return ErrSymbolNotFound
 	}
 
-	return u.repo.AddWithNextSortKey(ctx, userID, symbolCode)
+	return u.repo.AddWithNextSortKey(ctx, userID, symbolCode, MaxItems)
 }
 
 // RemoveSymbol はウォッチリストから銘柄を削除します。
@@ -61,8 +79,10 @@ func (u *usecase) RemoveSymbol(ctx context.Context, userID int64, symbolCode str
 	return u.repo.Remove(ctx, userID, symbolCode)
 }
 
-// ReorderSymbols はウォッチリストの並び順を更新します。
-func (u *usecase) ReorderSymbols(ctx context.Context, userID int64, orderedCodes []string) error {
+// ReorderSymbols はウォッチリストの並び順を更新し、更新後のバージョンを返します。
+// ifVersion が nil でなければ、クライアントが見たバージョンが現在のバージョンと一致する場合だけ更新します
+// （他の端末が先に変更していれば ErrVersionMismatch）。
+func (u *usecase) ReorderSymbols(ctx context.Context, userID int64, orderedCodes []string, ifVersion *int64) (int64, error) {
 	entries := make([]UserSymbol, 0, len(orderedCodes))
 	for i, code := range orderedCodes {
 		entries = append(entries, UserSymbol{
@@ -71,7 +91,7 @@ func (u *usecase) ReorderSymbols(ctx context.Context, userID int64, orderedCodes
 			SortKey:    i,
 		})
 	}
-	return u.repo.UpdateSortKeys(ctx, userID, entries)
+	return u.repo.UpdateSortKeys(ctx, userID, entries, ifVersion)
 }
 
 // OnUserCreated は PostSignupHook インターフェースを実装します。
@@ -81,17 +101,17 @@ func (u *usecase) OnUserCreated(ctx context.Context, userID int64) error {
 }
 
 // InitializeDefaults は新規ユーザー向けにデフォルト銘柄（AAPL/MSFT/GOOGL）を追加します。
-// symbols テーブルに存在する銘柄のみ追加します（存在しない場合はスキップ）。
+// アクティブな銘柄のみ追加します（存在しない・アクティブでない場合はスキップ）。
 func (u *usecase) InitializeDefaults(ctx context.Context, userID int64) error {
 	defaultSymbols := []string{"AAPL", "MSFT", "GOOGL"}
 
 	for i, code := range defaultSymbols {
-		exists, err := u.symbolChecker.Exists(ctx, code)
+		active, err := u.symbolChecker.IsActive(ctx, code)
 		if err != nil {
 			return fmt.Errorf("checking symbol %s: %w", code, err)
 		}
-		if !exists {
-			slog.Warn("default symbol not found in symbols table, skipping", "code", code)
+		if !active {
+			slog.Warn("default symbol is not an active symbol, skipping", "code", code)
 			continue
 		}
 		if err := u.repo.Add(ctx, UserSymbol{
@@ -14,13 +14,16 @@ import (
 // mockRepository はRepositoryインターフェースのモック実装です。
 type mockRepository struct {
 	ListByUserFunc         func(ctx context.Context, userID int64) ([]watchlist.UserSymbol, error)
+	VersionFunc            func(ctx context.Context, userID int64) (int64, error)
 	AddFunc                func(ctx context.Context, entry watchlist.UserSymbol) error
 	AddWithNextSortKeyFunc func(ctx context.Context, userID int64, symbolCode string) error
+	GotMaxItems            int // 最後の AddWithNextSortKey に渡された上限
 	RemoveFunc             func(ctx context.Context, userID int64, symbolCode string) error
-	UpdateSortKeysFunc     func(ctx context.Context, userID int64, entries []watchlist.UserSymbol) error
+	UpdateSortKeysFunc     func(ctx context.Context, userID int64, entries []watchlist.UserSymbol, ifVersion *int64) (int64, error)
 
 	AddedEntries     []watchlist.UserSymbol
 	UpdatedEntries   []watchlist.UserSymbol
+	UpdatedIfVersion *int64
 	AddWithNextCalls int
 	RemoveCalls      int
 }
@@ -32,6 +35,13 @@ func (m *mockRepository) ListByUser(ctx context.Context, userID int64) ([]watchl
 	return nil, nil
 }
 
+func (m *mockRepository) Version(ctx context.Context, userID int64) (int64, error) {
+	if m.VersionFunc != nil {
+		return m.VersionFunc(ctx, userID)
+	}
+	return 0, nil
+}
+
 func (m *mockRepository) Add(ctx context.Context, entry watchlist.UserSymbol) error {
 	m.AddedEntries = append(m.AddedEntries, entry)
 	if m.AddFunc != nil {
@@ -40,8 +50,9 @@ func (m *mockRepository) Add(ctx context.Context, entry watchlist.UserSymbol) er
 	return nil
 }
 
-func (m *mockRepository) AddWithNextSortKey(ctx context.Context, userID int64, symbolCode string) error {
+func (m *mockRepository) AddWithNextSortKey(ctx context.Context, userID int64, symbolCode string, maxItems int) error {
 	m.AddWithNextCalls++
+	m.GotMaxItems = maxItems
 	if m.AddWithNextSortKeyFunc != nil {
 		return m.AddWithNextSortKeyFunc(ctx, userID, symbolCode)
 	}
@@ -56,24 +67,25 @@ func (m *mockRepository) Remove(ctx context.Context, userID int64, symbolCode st
 	return nil
 }
 
-func (m *mockRepository) UpdateSortKeys(ctx context.Context, userID int64, entries []watchlist.UserSymbol) error {
+func (m *mockRepository) UpdateSortKeys(ctx context.Context, userID int64, entries []watchlist.UserSymbol, ifVersion *int64) (int64, error) {
 	m.UpdatedEntries = entries
+	m.UpdatedIfVersion = ifVersion
 	if m.UpdateSortKeysFunc != nil {
-		return m.UpdateSortKeysFunc(ctx, userID, entries)
+		return m.UpdateSortKeysFunc(ctx, userID, entries, ifVersion)
 	}
-	return nil
+	return 1, nil
 }
 
-// mockSymbolExistsChecker はSymbolExistsCheckerインターフェースのモック実装です。
-type mockSymbolExistsChecker struct {
-	ExistsFunc   func(ctx context.Context, code string) (bool, error)
+// mockSymbolActiveChecker はSymbolActiveCheckerインターフェースのモック実装です。
+type mockSymbolActiveChecker struct {
+	IsActiveFunc func(ctx context.Context, code string) (bool, error)
 	CheckedCodes []string
 }
 
-func (m *mockSymbolExistsChecker) Exists(ctx context.Context, code string) (bool, error) {
+func (m *mockSymbolActiveChecker) IsActive(ctx context.Context, code string) (bool, error) {
 	m.CheckedCodes = append(m.CheckedCodes, code)
-	if m.ExistsFunc != nil {
-		return m.ExistsFunc(ctx, code)
+	if m.IsActiveFunc != nil {
+		return m.IsActiveFunc(ctx, code)
 	}
 	return false, nil
 }
@@ -81,12 +93,12 @@ func (m *mockSymbolExistsChecker) Exists(ctx context.Context, code string) (bool
 func TestNewWatchlistUsecase(t *testing.T) {
 	t.Parallel()
 
-	uc := watchlist.NewUsecase(&mockRepository{}, &mockSymbolExistsChecker{})
+	uc := watchlist.NewUsecase(&mockRepository{}, &mockSymbolActiveChecker{})
 
 	assert.NotNil(t, uc, "usecase should not be nil")
 }
 
-func TestWatchlistUsecase_ListUserSymbols(t *testing.T) {
+func TestWatchlistUsecase_Snapshot(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
@@ -133,20 +145,24 @@ func TestWatchlistUsecase_ListUserSymbols(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			repo := &mockRepository{ListByUserFunc: tt.listByUser}
-			uc := watchlist.NewUsecase(repo, &mockSymbolExistsChecker{})
+			repo := &mockRepository{
+				ListByUserFunc: tt.listByUser,
+				VersionFunc:    func(context.Context, int64) (int64, error) { return 7, nil },
+			}
+			uc := watchlist.NewUsecase(repo, &mockSymbolActiveChecker{})
 
-			symbols, err := uc.ListUserSymbols(context.Background(), 42)
+			snap, err := uc.Snapshot(context.Background(), 42)
 
 			if tt.wantErr {
 				assert.Error(t, err)
 				if tt.errMsg != "" {
 					assert.EqualError(t, err, tt.errMsg)
 				}
-				assert.Nil(t, symbols)
+				assert.Nil(t, snap.Items)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, tt.wantSymbols, symbols)
+				assert.Equal(t, tt.wantSymbols, snap.Items)
+				assert.Equal(t, int64(7), snap.Version)
 			}
 		})
 	}
@@ -157,7 +173,7 @@ func TestWatchlistUsecase_AddSymbol(t *testing.T) {
 
 	tests := []struct {
 		name             string
-		exists           func(ctx context.Context, code string) (bool, error)
+		isActive         func(ctx context.Context, code string) (bool, error)
 		addWithNext      func(ctx context.Context, userID int64, symbolCode string) error
 		wantErr          bool
 		wantErrIs        error
@@ -166,15 +182,15 @@ func TestWatchlistUsecase_AddSymbol(t *testing.T) {
 	}{
 		{
 			name: "success: existing symbol is added",
-			exists: func(ctx context.Context, code string) (bool, error) {
+			isActive: func(ctx context.Context, code string) (bool, error) {
 				return true, nil
 			},
 			wantErr:          false,
 			wantAddWithCalls: 1,
 		},
 		{
-			name: "failure: symbol does not exist returns ErrSymbolNotFound",
-			exists: func(ctx context.Context, code string) (bool, error) {
+			name: "failure: missing or inactive symbol returns ErrSymbolNotFound",
+			isActive: func(ctx context.Context, code string) (bool, error) {
 				return false, nil
 			},
 			wantErr:          true,
@@ -183,16 +199,28 @@ func TestWatchlistUsecase_AddSymbol(t *testing.T) {
 		},
 		{
 			name: "failure: existence check returns wrapped error",
-			exists: func(ctx context.Context, code string) (bool, error) {
+			isActive: func(ctx context.Context, code string) (bool, error) {
 				return false, errors.New("checker down")
 			},
 			wantErr:          true,
 			wantErrContains:  "checking symbol existence",
 			wantAddWithCalls: 0,
 		},
+		{
+			name: "failure: full watchlist returns ErrWatchlistFull",
+			isActive: func(ctx context.Context, code string) (bool, error) {
+				return true, nil
+			},
+			addWithNext: func(ctx context.Context, userID int64, symbolCode string) error {
+				return watchlist.ErrWatchlistFull
+			},
+			wantErr:          true,
+			wantErrIs:        watchlist.ErrWatchlistFull,
+			wantAddWithCalls: 1,
+		},
 		{
 			name: "failure: repository add returns error",
-			exists: func(ctx context.Context, code string) (bool, error) {
+			isActive: func(ctx context.Context, code string) (bool, error) {
 				return true, nil
 			},
 			addWithNext: func(ctx context.Context, userID int64, symbolCode string) error {
@@ -209,7 +237,7 @@ func TestWatchlistUsecase_AddSymbol(t *testing.T) {
 			t.Parallel()
 
 			repo := &mockRepository{AddWithNextSortKeyFunc: tt.addWithNext}
-			checker := &mockSymbolExistsChecker{ExistsFunc: tt.exists}
+			checker := &mockSymbolActiveChecker{IsActiveFunc: tt.isActive}
 			uc := watchlist.NewUsecase(repo, checker)
 
 			err := uc.AddSymbol(context.Background(), 42, "AAPL")
@@ -226,6 +254,9 @@ func TestWatchlistUsecase_AddSymbol(t *testing.T) {
 				assert.NoError(t, err)
 			}
 			assert.Equal(t, tt.wantAddWithCalls, repo.AddWithNextCalls)
+			if tt.wantAddWithCalls > 0 {
+				assert.Equal(t, watchlist.MaxItems, repo.GotMaxItems)
+			}
 		})
 	}
 }
@@ -259,7 +290,7 @@ func TestWatchlistUsecase_RemoveSymbol(t *testing.T) {
 			t.Parallel()
 
 			repo := &mockRepository{RemoveFunc: tt.remove}
-			uc := watchlist.NewUsecase(repo, &mockSymbolExistsChecker{})
+			uc := watchlist.NewUsecase(repo, &mockSymbolActiveChecker{})
 
 			err := uc.RemoveSymbol(context.Background(), 42, "AAPL")
 
@@ -280,25 +311,57 @@ func TestWatchlistUsecase_ReorderSymbols(t *testing.T) {
 		t.Parallel()
 
 		repo := &mockRepository{}
-		uc := watchlist.NewUsecase(repo, &mockSymbolExistsChecker{})
+		uc := watchlist.NewUsecase(repo, &mockSymbolActiveChecker{})
 
-		err := uc.ReorderSymbols(context.Background(), 42, []string{"MSFT", "AAPL", "GOOGL"})
+		version, err := uc.ReorderSymbols(context.Background(), 42, []string{"MSFT", "AAPL", "GOOGL"}, nil)
 
 		require.NoError(t, err)
+		assert.Equal(t, int64(1), version)
 		assert.Equal(t, []watchlist.UserSymbol{
 			{UserID: 42, SymbolCode: "MSFT", SortKey: 0},
 			{UserID: 42, SymbolCode: "AAPL", SortKey: 1},
 			{UserID: 42, SymbolCode: "GOOGL", SortKey: 2},
 		}, repo.UpdatedEntries)
+		assert.Nil(t, repo.UpdatedIfVersion)
+	})
+
+	t.Run("success: passes the expected version to the repository", func(t *testing.T) {
+		t.Parallel()
+
+		repo := &mockRepository{}
+		uc := watchlist.NewUsecase(repo, &mockSymbolActiveChecker{})
+		seen := int64(4)
+
+		_, err := uc.ReorderSymbols(context.Background(), 42, []string{"AAPL"}, &seen)
+
+		require.NoError(t, err)
+		require.NotNil(t, repo.UpdatedIfVersion)
+		assert.Equal(t, int64(4), *repo.UpdatedIfVersion)
+	})
+
+	t.Run("failure: version mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		repo := &mockRepository{
+			UpdateSortKeysFunc: func(context.Context, int64, []watchlist.UserSymbol, *int64) (int64, error) {
+				return 0, watchlist.ErrVersionMismatch
+			},
+		}
+		uc := watchlist.NewUsecase(repo, &mockSymbolActiveChecker{})
+		seen := int64(4)
+
+		_, err := uc.ReorderSymbols(context.Background(), 42, []string{"AAPL"}, &seen)
+
+		assert.ErrorIs(t, err, watchlist.ErrVersionMismatch)
 	})
 
 	t.Run("success: empty order produces empty entries", func(t *testing.T) {
 		t.Parallel()
 
 		repo := &mockRepository{}
-		uc := watchlist.NewUsecase(repo, &mockSymbolExistsChecker{})
+		uc := watchlist.NewUsecase(repo, &mockSymbolActiveChecker{})
 
-		err := uc.ReorderSymbols(context.Background(), 42, []string{})
+		_, err := uc.ReorderSymbols(context.Background(), 42, []string{}, nil)
 
 		require.NoError(t, err)
 		assert.Empty(t, repo.UpdatedEntries)
@@ -308,13 +371,13 @@ func TestWatchlistUsecase_ReorderSymbols(t *testing.T) {
 		t.Parallel()
 
 		repo := &mockRepository{
-			UpdateSortKeysFunc: func(ctx context.Context, userID int64, entries []watchlist.UserSymbol) error {
-				return errors.New("update failed")
+			UpdateSortKeysFunc: func(ctx context.Context, userID int64, entries []watchlist.UserSymbol, ifVersion *int64) (int64, error) {
+				return 0, errors.New("update failed")
 			},
 		}
-		uc := watchlist.NewUsecase(repo, &mockSymbolExistsChecker{})
+		uc := watchlist.NewUsecase(repo, &mockSymbolActiveChecker{})
 
-		err := uc.ReorderSymbols(context.Background(), 42, []string{"AAPL"})
+		_, err := uc.ReorderSymbols(context.Background(), 42, []string{"AAPL"}, nil)
 
 		assert.EqualError(t, err, "update failed")
 	})
@@ -327,8 +390,8 @@ func TestWatchlistUsecase_InitializeDefaults(t *testing.T) {
 		t.Parallel()
 
 		repo := &mockRepository{}
-		checker := &mockSymbolExistsChecker{
-			ExistsFunc: func(ctx context.Context, code string) (bool, error) { return true, nil },
+		checker := &mockSymbolActiveChecker{
+			IsActiveFunc: func(ctx context.Context, code string) (bool, error) { return true, nil },
 		}
 		uc := watchlist.NewUsecase(repo, checker)
 
@@ -346,8 +409,8 @@ func TestWatchlistUsecase_InitializeDefaults(t *testing.T) {
 		t.Parallel()
 
 		repo := &mockRepository{}
-		checker := &mockSymbolExistsChecker{
-			ExistsFunc: func(ctx context.Context, code string) (bool, error) {
+		checker := &mockSymbolActiveChecker{
+			IsActiveFunc: func(ctx context.Context, code string) (bool, error) {
 				return code == "MSFT", nil
 			},
 		}
@@ -365,8 +428,8 @@ func TestWatchlistUsecase_InitializeDefaults(t *testing.T) {
 		t.Parallel()
 
 		repo := &mockRepository{}
-		checker := &mockSymbolExistsChecker{
-			ExistsFunc: func(ctx context.Context, code string) (bool, error) {
+		checker := &mockSymbolActiveChecker{
+			IsActiveFunc: func(ctx context.Context, code string) (bool, error) {
 				return false, errors.New("checker down")
 			},
 		}
@@ -387,8 +450,8 @@ func TestWatchlistUsecase_InitializeDefaults(t *testing.T) {
 				return errors.New("insert failed")
 			},
 		}
-		checker := &mockSymbolExistsChecker{
-			ExistsFunc: func(ctx context.Context, code string) (bool, error) { return true, nil },
+		checker := &mockSymbolActiveChecker{
+			IsActiveFunc: func(ctx context.Context, code string) (bool, error) { return true, nil },
 		}
 		uc := watchlist.NewUsecase(repo, checker)
 
@@ -403,8 +466,8 @@ func TestWatchlistUsecase_OnUserCreated(t *testing.T) {
 	t.Parallel()
 
 	repo := &mockRepository{}
-	checker := &mockSymbolExistsChecker{
-		ExistsFunc: func(ctx context.Context, code string) (bool, error) { return true, nil },
+	checker := &mockSymbolActiveChecker{
+		IsActiveFunc: func(ctx context.Context, code string) (bool, error) { return true, nil },
 	}
 	uc := watchlist.NewUsecase(repo, checker)
 
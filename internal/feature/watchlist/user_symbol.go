@@ -12,3 +12,10 @@ type UserSymbol struct {
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
 }
+
+// Snapshot はユーザーのウォッチリストとそのバージョンです。
+// Version は追加・削除・並び替えのたびに増え、並び替えの楽観的同時実行制御（If-Match）に使います。
+type Snapshot struct {
+	Items   []UserSymbol
+	Version int64
+}
@@ -21,15 +21,16 @@ var symbolCodePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,20}$`)
 
 // Usecase はウォッチリスト操作のユースケースインターフェースを定義します。
 type Usecase interface {
-	ListUserSymbols(ctx context.Context, userID int64) ([]watchlist.UserSymbol, error)
+	Snapshot(ctx context.Context, userID int64) (watchlist.Snapshot, error)
 	AddSymbol(ctx context.Context, userID int64, symbolCode string) error
 	RemoveSymbol(ctx context.Context, userID int64, symbolCode string) error
-	ReorderSymbols(ctx context.Context, userID int64, orderedCodes []string) error
+	ReorderSymbols(ctx context.Context, userID int64, orderedCodes []string, ifVersion *int64) (int64, error)
 }
 
 // Handler はウォッチリストに関連するHTTPリクエストを処理します。
 type Handler struct {
-	uc Usecase
+	uc             Usecase
+	requireIfMatch bool
 }
 
 // NewHandler はHandlerの新しいインスタンスを生成します。
@@ -37,6 +38,13 @@ func NewHandler(uc Usecase) *Handler {
 	return &Handler{uc: uc}
 }
 
+// WithRequireIfMatch は並び替えに If-Match を必須にするかを設定します（必須の場合、ないと 428）。
+// 既定は省略を受け付けます（If-Match を送らない既存のクライアントとの互換のため）。
+func (h *Handler) WithRequireIfMatch(required bool) *Handler {
+	h.requireIfMatch = required
+	return h
+}
+
 // List はユーザーのウォッチリスト一覧を取得します。
 func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	userID, ok := jwt.UserIDFromContext(r.Context())
@@ -45,13 +53,19 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	entries, err := h.uc.ListUserSymbols(r.Context(), userID)
+	snap, err := h.uc.Snapshot(r.Context(), userID)
 	if err != nil {
 		slog.Error("failed to list watchlist", "error", err, "userID", userID)
 		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
 		return
 	}
 
+	w.Header().Set("ETag", httpx.ETag(snap.Version))
+	httpx.WriteJSON(w, http.StatusOK, toWatchlistItems(snap.Items))
+}
+
+// toWatchlistItems はウォッチリストのエントリをレスポンスの型に変換します。
+func toWatchlistItems(entries []watchlist.UserSymbol) []api.WatchlistItem {
 	out := make([]api.WatchlistItem, 0, len(entries))
 	for _, e := range entries {
 		out = append(out, api.WatchlistItem{
@@ -60,7 +74,7 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 			SortKey:    e.SortKey,
 		})
 	}
-	httpx.WriteJSON(w, http.StatusOK, out)
+	return out
 }
 
 // Add はウォッチリストに銘柄を追加します。
@@ -73,20 +87,12 @@ func (h *Handler) Add(w http.ResponseWriter, r *http.Request) {
 
 	var req api.AddWatchlistRequest
 	if err := httpx.DecodeAndValidate(r, &req); err != nil {
-		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "invalid request"})
+		httpx.WriteDecodeError(w, err, "invalid request")
 		return
 	}
 
 	if err := h.uc.AddSymbol(r.Context(), userID, req.SymbolCode); err != nil {
-		switch {
-		case errors.Is(err, watchlist.ErrSymbolNotFound):
-			httpx.WriteJSON(w, http.StatusNotFound, api.ErrorResponse{Error: err.Error()})
-		case errors.Is(err, watchlist.ErrAlreadyInWatchlist):
-			httpx.WriteJSON(w, http.StatusConflict, api.ErrorResponse{Error: err.Error()})
-		default:
-			slog.Error("failed to add watchlist symbol", "error", err, "userID", userID)
-			httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
-		}
+		httpx.WriteError(w, err, "failed to add watchlist symbol", "userID", userID)
 		return
 	}
 
@@ -107,13 +113,7 @@ func (h *Handler) Remove(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.uc.RemoveSymbol(r.Context(), userID, code); err != nil {
-		switch {
-		case errors.Is(err, watchlist.ErrNotInWatchlist):
-			httpx.WriteJSON(w, http.StatusNotFound, api.ErrorResponse{Error: err.Error()})
-		default:
-			slog.Error("failed to remove watchlist symbol", "error", err, "userID", userID)
-			httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
-		}
+		httpx.WriteError(w, err, "failed to remove watchlist symbol", "userID", userID)
 		return
 	}
 
@@ -121,24 +121,52 @@ func (h *Handler) Remove(w http.ResponseWriter, r *http.Request) {
 }
 
 // Reorder はウォッチリストの並び順を更新します。
+// If-Match のバージョンが現在のバージョンと一致しない場合は更新せず、412 で現在のウォッチリストとバージョンを返します
+// （クライアントは自身の並び替えとマージして再送する）。
 func (h *Handler) Reorder(w http.ResponseWriter, r *http.Request) {
 	userID, ok := jwt.UserIDFromContext(r.Context())
 	if !ok {
 		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
 		return
 	}
+	ifVersion, ok := httpx.CheckIfMatch(w, r, h.requireIfMatch)
+	if !ok {
+		return
+	}
 
 	var req api.ReorderWatchlistRequest
 	if err := httpx.DecodeAndValidate(r, &req); err != nil {
-		httpx.WriteJSON(w, http.StatusBadRequest, api.ErrorResponse{Error: "invalid request"})
+		httpx.WriteDecodeError(w, err, "invalid request")
 		return
 	}
 
-	if err := h.uc.ReorderSymbols(r.Context(), userID, req.Codes); err != nil {
+	version, err := h.uc.ReorderSymbols(r.Context(), userID, req.Codes, ifVersion)
+	if errors.Is(err, watchlist.ErrVersionMismatch) {
+		h.writeConflict(w, r, userID)
+		return
+	}
+	if err != nil {
 		slog.Error("failed to reorder watchlist", "error", err, "userID", userID)
 		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
 		return
 	}
 
+	w.Header().Set("ETag", httpx.ETag(version))
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// writeConflict は 412 で現在のウォッチリストとバージョンを返します。
+func (h *Handler) writeConflict(w http.ResponseWriter, r *http.Request, userID int64) {
+	snap, err := h.uc.Snapshot(r.Context(), userID)
+	if err != nil {
+		slog.Error("failed to list watchlist", "error", err, "userID", userID)
+		httpx.WriteJSON(w, http.StatusInternalServerError, api.ErrorResponse{Error: "internal server error"})
+		return
+	}
+	w.Header().Set("ETag", httpx.ETag(snap.Version))
+	httpx.WriteJSON(w, http.StatusPreconditionFailed, api.WatchlistVersionConflict{
+		Error:   watchlist.ErrVersionMismatch.Code,
+		Version: snap.Version,
+		Items:   toWatchlistItems(snap.Items),
+	})
+}
@@ -20,17 +20,17 @@ const testUserID int64 = 1
 
 // mockUsecase は Usecase インターフェースのモック実装です。
 type mockUsecase struct {
-	ListUserSymbolsFunc func(ctx context.Context, userID int64) ([]watchlist.UserSymbol, error)
-	AddSymbolFunc       func(ctx context.Context, userID int64, symbolCode string) error
-	RemoveSymbolFunc    func(ctx context.Context, userID int64, symbolCode string) error
-	ReorderSymbolsFunc  func(ctx context.Context, userID int64, orderedCodes []string) error
+	SnapshotFunc       func(ctx context.Context, userID int64) (watchlist.Snapshot, error)
+	AddSymbolFunc      func(ctx context.Context, userID int64, symbolCode string) error
+	RemoveSymbolFunc   func(ctx context.Context, userID int64, symbolCode string) error
+	ReorderSymbolsFunc func(ctx context.Context, userID int64, orderedCodes []string, ifVersion *int64) (int64, error)
 }
 
-func (m *mockUsecase) ListUserSymbols(ctx context.Context, userID int64) ([]watchlist.UserSymbol, error) {
-	if m.ListUserSymbolsFunc != nil {
-		return m.ListUserSymbolsFunc(ctx, userID)
+func (m *mockUsecase) Snapshot(ctx context.Context, userID int64) (watchlist.Snapshot, error) {
+	if m.SnapshotFunc != nil {
+		return m.SnapshotFunc(ctx, userID)
 	}
-	return nil, nil
+	return watchlist.Snapshot{}, nil
 }
 
 func (m *mockUsecase) AddSymbol(ctx context.Context, userID int64, symbolCode string) error {
@@ -47,11 +47,11 @@ func (m *mockUsecase) RemoveSymbol(ctx context.Context, userID int64, symbolCode
 	return nil
 }
 
-func (m *mockUsecase) ReorderSymbols(ctx context.Context, userID int64, orderedCodes []string) error {
+func (m *mockUsecase) ReorderSymbols(ctx context.Context, userID int64, orderedCodes []string, ifVersion *int64) (int64, error) {
 	if m.ReorderSymbolsFunc != nil {
-		return m.ReorderSymbolsFunc(ctx, userID, orderedCodes)
+		return m.ReorderSymbolsFunc(ctx, userID, orderedCodes, ifVersion)
 	}
-	return nil
+	return 0, nil
 }
 
 // newRouter は認証済みユーザーIDを context に注入するミドルウェア付きの chi ルーターを構築します。
@@ -72,34 +72,37 @@ func TestWatchlistHandler_List(t *testing.T) {
 
 	tests := []struct {
 		name           string
-		mockList       func(ctx context.Context, userID int64) ([]watchlist.UserSymbol, error)
+		mockList       func(ctx context.Context, userID int64) (watchlist.Snapshot, error)
 		expectedStatus int
 		expectedBody   string
+		expectedETag   string
 	}{
 		{
 			name: "success: returns watchlist items",
-			mockList: func(ctx context.Context, userID int64) ([]watchlist.UserSymbol, error) {
+			mockList: func(ctx context.Context, userID int64) (watchlist.Snapshot, error) {
 				assert.Equal(t, testUserID, userID)
-				return []watchlist.UserSymbol{
+				return watchlist.Snapshot{Items: []watchlist.UserSymbol{
 					{ID: 1, UserID: testUserID, SymbolCode: "AAPL", SortKey: 0},
 					{ID: 2, UserID: testUserID, SymbolCode: "MSFT", SortKey: 1},
-				}, nil
+				}, Version: 3}, nil
 			},
 			expectedStatus: http.StatusOK,
 			expectedBody:   `[{"id":1,"symbol_code":"AAPL","sort_key":0},{"id":2,"symbol_code":"MSFT","sort_key":1}]`,
+			expectedETag:   `"3"`,
 		},
 		{
 			name: "success: empty watchlist returns empty array",
-			mockList: func(ctx context.Context, userID int64) ([]watchlist.UserSymbol, error) {
-				return []watchlist.UserSymbol{}, nil
+			mockList: func(ctx context.Context, userID int64) (watchlist.Snapshot, error) {
+				return watchlist.Snapshot{Items: []watchlist.UserSymbol{}}, nil
 			},
 			expectedStatus: http.StatusOK,
 			expectedBody:   `[]`,
+			expectedETag:   `"0"`,
 		},
 		{
 			name: "error: usecase returns error",
-			mockList: func(ctx context.Context, userID int64) ([]watchlist.UserSymbol, error) {
-				return nil, errors.New("db failure")
+			mockList: func(ctx context.Context, userID int64) (watchlist.Snapshot, error) {
+				return watchlist.Snapshot{}, errors.New("db failure")
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   `{"error":"internal server error"}`,
@@ -110,7 +113,7 @@ func TestWatchlistHandler_List(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			mockUC := &mockUsecase{ListUserSymbolsFunc: tt.mockList}
+			mockUC := &mockUsecase{SnapshotFunc: tt.mockList}
 			h := watchlisthttp.NewHandler(mockUC)
 			router := newRouter(t, func(r chi.Router) {
 				r.Get("/watchlist", h.List)
@@ -122,6 +125,7 @@ func TestWatchlistHandler_List(t *testing.T) {
 
 			assert.Equal(t, tt.expectedStatus, w.Code)
 			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			assert.Equal(t, tt.expectedETag, w.Header().Get("ETag"))
 		})
 	}
 }
@@ -148,14 +152,23 @@ func TestWatchlistHandler_Add(t *testing.T) {
 			expectedBody:   `{"message":"added to watchlist"}`,
 		},
 		{
-			name: "error: symbol not found",
+			name: "error: symbol not found or inactive",
 			body: `{"symbol_code":"XXXX"}`,
 			mockAdd: func(ctx context.Context, userID int64, symbolCode string) error {
 				return watchlist.ErrSymbolNotFound
 			},
-			expectedStatus: http.StatusNotFound,
+			expectedStatus: http.StatusUnprocessableEntity,
 			expectedBody:   `{"error":"symbol not found"}`,
 		},
+		{
+			name: "error: watchlist full",
+			body: `{"symbol_code":"AAPL"}`,
+			mockAdd: func(ctx context.Context, userID int64, symbolCode string) error {
+				return watchlist.ErrWatchlistFull
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `{"error":"watchlist full"}`,
+		},
 		{
 			name: "error: already in watchlist",
 			body: `{"symbol_code":"AAPL"}`,
@@ -289,20 +302,80 @@ func TestWatchlistHandler_Reorder(t *testing.T) {
 	tests := []struct {
 		name           string
 		body           string
-		mockReorder    func(ctx context.Context, userID int64, orderedCodes []string) error
+		ifMatch        string
+		requireIfMatch bool
+		mockReorder    func(ctx context.Context, userID int64, orderedCodes []string, ifVersion *int64) (int64, error)
 		expectedStatus int
 		expectedBody   string
+		expectedETag   string
 	}{
 		{
-			name: "success: watchlist reordered",
-			body: `{"codes":["MSFT","AAPL"]}`,
-			mockReorder: func(ctx context.Context, userID int64, orderedCodes []string) error {
+			name:    "success: watchlist reordered with If-Match",
+			body:    `{"codes":["MSFT","AAPL"]}`,
+			ifMatch: `"3"`,
+			mockReorder: func(ctx context.Context, userID int64, orderedCodes []string, ifVersion *int64) (int64, error) {
 				assert.Equal(t, testUserID, userID)
 				assert.Equal(t, []string{"MSFT", "AAPL"}, orderedCodes)
-				return nil
+				if assert.NotNil(t, ifVersion) {
+					assert.Equal(t, int64(3), *ifVersion)
+				}
+				return 4, nil
 			},
 			expectedStatus: http.StatusNoContent,
-			expectedBody:   "",
+			expectedETag:   `"4"`,
+		},
+		{
+			name: "success: legacy request without If-Match is unconditional",
+			body: `{"codes":["MSFT","AAPL"]}`,
+			mockReorder: func(ctx context.Context, userID int64, orderedCodes []string, ifVersion *int64) (int64, error) {
+				assert.Nil(t, ifVersion)
+				return 4, nil
+			},
+			expectedStatus: http.StatusNoContent,
+			expectedETag:   `"4"`,
+		},
+		{
+			name:    "success: If-Match * is unconditional",
+			body:    `{"codes":["AAPL"]}`,
+			ifMatch: "*",
+			mockReorder: func(ctx context.Context, userID int64, orderedCodes []string, ifVersion *int64) (int64, error) {
+				assert.Nil(t, ifVersion)
+				return 1, nil
+			},
+			expectedStatus: http.StatusNoContent,
+			expectedETag:   `"1"`,
+		},
+		{
+			name:    "conflict: version mismatch returns 412 with the current watchlist",
+			body:    `{"codes":["MSFT","AAPL"]}`,
+			ifMatch: `"3"`,
+			mockReorder: func(ctx context.Context, userID int64, orderedCodes []string, ifVersion *int64) (int64, error) {
+				return 0, watchlist.ErrVersionMismatch
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedBody:   `{"error":"version mismatch","version":5,"items":[{"id":1,"symbol_code":"AAPL","sort_key":0},{"id":2,"symbol_code":"GOOGL","sort_key":1}]}`,
+			expectedETag:   `"5"`,
+		},
+		{
+			name:    "conflict: malformed If-Match never matches",
+			body:    `{"codes":["AAPL"]}`,
+			ifMatch: "3",
+			mockReorder: func(ctx context.Context, userID int64, orderedCodes []string, ifVersion *int64) (int64, error) {
+				if assert.NotNil(t, ifVersion) {
+					assert.Equal(t, int64(-1), *ifVersion)
+				}
+				return 0, watchlist.ErrVersionMismatch
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedETag:   `"5"`,
+		},
+		{
+			name:           "error: missing If-Match returns 428 when required",
+			body:           `{"codes":["AAPL"]}`,
+			requireIfMatch: true,
+			mockReorder:    nil,
+			expectedStatus: http.StatusPreconditionRequired,
+			expectedBody:   `{"error":"if-match required"}`,
 		},
 		{
 			name:           "error: invalid request body returns 400",
@@ -314,8 +387,8 @@ func TestWatchlistHandler_Reorder(t *testing.T) {
 		{
 			name: "error: usecase returns internal error",
 			body: `{"codes":["AAPL"]}`,
-			mockReorder: func(ctx context.Context, userID int64, orderedCodes []string) error {
-				return errors.New("db failure")
+			mockReorder: func(ctx context.Context, userID int64, orderedCodes []string, ifVersion *int64) (int64, error) {
+				return 0, errors.New("db failure")
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   `{"error":"internal server error"}`,
@@ -326,8 +399,22 @@ func TestWatchlistHandler_Reorder(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			mockUC := &mockUsecase{ReorderSymbolsFunc: tt.mockReorder}
-			h := watchlisthttp.NewHandler(mockUC)
+			mockUC := &mockUsecase{
+				ReorderSymbolsFunc: tt.mockReorder,
+				SnapshotFunc: func(context.Context, int64) (watchlist.Snapshot, error) {
+					return watchlist.Snapshot{Items: []watchlist.UserSymbol{
+						{ID: 1, UserID: testUserID, SymbolCode: "AAPL", SortKey: 0},
+						{ID: 2, UserID: testUserID, SymbolCode: "GOOGL", SortKey: 1},
+					}, Version: 5}, nil
+				},
+			}
+			if tt.mockReorder == nil {
+				mockUC.ReorderSymbolsFunc = func(context.Context, int64, []string, *int64) (int64, error) {
+					t.Error("ReorderSymbols should not be called")
+					return 0, nil
+				}
+			}
+			h := watchlisthttp.NewHandler(mockUC).WithRequireIfMatch(tt.requireIfMatch)
 			router := newRouter(t, func(r chi.Router) {
 				r.Put("/watchlist/reorder", h.Reorder)
 			})
@@ -335,12 +422,16 @@ func TestWatchlistHandler_Reorder(t *testing.T) {
 			w := httptest.NewRecorder()
 			req := httptest.NewRequest(http.MethodPut, "/watchlist/reorder", bytes.NewReader([]byte(tt.body)))
 			req.Header.Set("Content-Type", "application/json")
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
 			router.ServeHTTP(w, req)
 
 			assert.Equal(t, tt.expectedStatus, w.Code)
 			if tt.expectedBody != "" {
 				assert.JSONEq(t, tt.expectedBody, w.Body.String())
 			}
+			assert.Equal(t, tt.expectedETag, w.Header().Get("ETag"))
 		})
 	}
 }
@@ -0,0 +1,74 @@
+// Package asyncjob は Redis を使った非同期ジョブ（登録・ワーカーでの処理・結果のポーリング）を提供します。
+//
+// 時間のかかる処理（Gemini による企業分析等）をリクエストの中で待つと、モバイル回線のクライアントが先に
+// タイムアウトします。ハンドラーは Store.Enqueue でジョブを登録してすぐに ID を返し、Worker が Kind ごとの
+// Handler で処理して結果を保存します。クライアントは Store.Get で状態と結果をポーリングします。
+//
+// ジョブは登録したユーザー（Owner）に限って参照でき、結果は完了から Retention の間保持します。
+// 処理は最大 1 回です（処理中にプロセスが停止したジョブは pending のまま Retention の経過で消える）。
+package asyncjob
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Status はジョブの状態です。
+type Status string
+
+const (
+	// StatusPending は処理待ち・処理中です。
+	StatusPending Status = "pending"
+	// StatusDone は処理が成功し、Result に結果があります。
+	StatusDone Status = "done"
+	// StatusFailed は処理が失敗し、Error に理由があります。
+	StatusFailed Status = "failed"
+)
+
+var (
+	// ErrNotFound はジョブが存在しない（期限切れ・他のユーザーのジョブを含む）ことを示します。
+	ErrNotFound = errors.New("asyncjob: job not found")
+	// ErrUnavailable は Redis に接続できず、ジョブを登録・参照できないことを示します。
+	ErrUnavailable = errors.New("asyncjob: store unavailable")
+	// ErrPermanent は再試行しても成功しない失敗（利用枠の超過・解釈できないペイロード等）を示します。
+	// Handler が Permanent で包んだエラーを返すと、Worker は再試行せずに失敗として記録します。
+	ErrPermanent = errors.New("asyncjob: permanent failure")
+)
+
+// Job は 1 件のジョブです。
+type Job struct {
+	ID     string `json:"id"`
+	Kind   string `json:"kind"`
+	Owner  int64  `json:"owner"`
+	Status Status `json:"status"`
+	// Payload は Enqueue に渡した値の JSON です。
+	Payload json.RawMessage `json:"payload"`
+	// Result は Handler が返した値の JSON です（StatusDone の場合のみ）。
+	Result json.RawMessage `json:"result,omitempty"`
+	// Error は最後の試行の失敗の理由です（StatusFailed の場合のみ）。クライアントに返すため、Handler は公開してよい文言のエラーを返します。
+	Error string `json:"error,omitempty"`
+	// Attempts は処理を試行した回数です。
+	Attempts  int       `json:"attempts"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Decode は Payload を v にデコードします。失敗した場合は Permanent で包んだエラーを返します。
+func (j Job) Decode(v any) error {
+	if err := json.Unmarshal(j.Payload, v); err != nil {
+		return Permanent(errors.New("invalid payload"))
+	}
+	return nil
+}
+
+// permanentError は Error() を元のエラーのまま保ち、errors.Is(err, ErrPermanent) で判定できるエラーです。
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string   { return e.err.Error() }
+func (e permanentError) Unwrap() []error { return []error{e.err, ErrPermanent} }
+
+// Permanent は err を再試行しない失敗として包みます。Job.Error には err の文言をそのまま記録します。
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
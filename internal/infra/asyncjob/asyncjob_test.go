@@ -0,0 +1,226 @@
+package asyncjob
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T) (*Store, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return NewStore(rdb, "test:jobs", time.Hour), mr
+}
+
+// runWorker は w を起動し、停止して戻るのを待つ関数を返します（テストの終了時にも停止する）。
+func runWorker(t *testing.T, w *Worker) (stop func()) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.Run(ctx)
+	}()
+	stop = func() {
+		cancel()
+		<-done
+	}
+	t.Cleanup(stop)
+	return stop
+}
+
+// waitFinished は id のジョブが pending でなくなるまでポーリングして返します。
+func waitFinished(t *testing.T, s *Store, id string, owner int64) Job {
+	t.Helper()
+	var job Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = s.Get(context.Background(), id, owner)
+		require.NoError(t, err)
+		return job.Status != StatusPending
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+type echoPayload struct {
+	Text string `json:"text"`
+}
+
+// TestWorker_Lifecycle は登録したジョブが pending から done になり、結果を取得できることを検証します。
+func TestWorker_Lifecycle(t *testing.T) {
+	t.Parallel()
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	job, err := s.Enqueue(ctx, "echo", 7, echoPayload{Text: "hello"})
+	require.NoError(t, err)
+	assert.Regexp(t, `^[0-9a-f]{32}$`, job.ID)
+
+	got, err := s.Get(ctx, job.ID, 7)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, got.Status)
+	assert.Equal(t, "echo", got.Kind)
+
+	w := NewWorker(s, map[string]Handler{
+		"echo": func(_ context.Context, j Job) (any, error) {
+			var p echoPayload
+			if err := j.Decode(&p); err != nil {
+				return nil, err
+			}
+			return echoPayload{Text: p.Text + "!"}, nil
+		},
+	}, WorkerConfig{})
+	stop := runWorker(t, w)
+
+	done := waitFinished(t, s, job.ID, 7)
+	assert.Equal(t, StatusDone, done.Status)
+	assert.JSONEq(t, `{"text":"hello!"}`, string(done.Result))
+	assert.Equal(t, 1, done.Attempts)
+	assert.Empty(t, done.Error)
+	stop()
+	assert.Equal(t, WorkerStats{Done: 1}, w.Stats())
+}
+
+// TestWorker_Retry は失敗したジョブを 1 回だけ再試行し、再試行しない失敗は 1 回で失敗にすることを検証します。
+func TestWorker_Retry(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		failures     int32 // 失敗させる試行の回数
+		err          error
+		wantStatus   Status
+		wantAttempts int
+		wantError    string
+		wantStats    WorkerStats
+	}{
+		{name: "succeeds on retry", failures: 1, err: errors.New("upstream timeout"), wantStatus: StatusDone, wantAttempts: 2, wantStats: WorkerStats{Done: 1, Retried: 1}},
+		{name: "fails after retry", failures: 2, err: errors.New("upstream timeout"), wantStatus: StatusFailed, wantAttempts: 2, wantError: "upstream timeout", wantStats: WorkerStats{Failed: 1, Retried: 1}},
+		{name: "permanent failure", failures: 2, err: Permanent(errors.New("quota_exceeded")), wantStatus: StatusFailed, wantAttempts: 1, wantError: "quota_exceeded", wantStats: WorkerStats{Failed: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s, _ := newTestStore(t)
+			var calls atomic.Int32
+			w := NewWorker(s, map[string]Handler{
+				"flaky": func(context.Context, Job) (any, error) {
+					if calls.Add(1) <= tt.failures {
+						return nil, tt.err
+					}
+					return "ok", nil
+				},
+			}, WorkerConfig{Concurrency: 1, RetryDelay: time.Millisecond})
+			stop := runWorker(t, w)
+
+			job, err := s.Enqueue(context.Background(), "flaky", 1, nil)
+			require.NoError(t, err)
+			done := waitFinished(t, s, job.ID, 1)
+			assert.Equal(t, tt.wantStatus, done.Status)
+			assert.Equal(t, tt.wantAttempts, done.Attempts)
+			assert.Equal(t, tt.wantError, done.Error)
+			stop()
+			assert.Equal(t, tt.wantStats, w.Stats())
+		})
+	}
+}
+
+// TestWorker_Timeout は試行が Timeout で打ち切られ、再試行の後に失敗になることを検証します。
+func TestWorker_Timeout(t *testing.T) {
+	t.Parallel()
+	s, _ := newTestStore(t)
+	w := NewWorker(s, map[string]Handler{
+		"slow": func(ctx context.Context, _ Job) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}, WorkerConfig{Concurrency: 1, Timeout: 20 * time.Millisecond, RetryDelay: time.Millisecond})
+	runWorker(t, w)
+
+	job, err := s.Enqueue(context.Background(), "slow", 1, nil)
+	require.NoError(t, err)
+	done := waitFinished(t, s, job.ID, 1)
+	assert.Equal(t, StatusFailed, done.Status)
+	assert.Equal(t, 2, done.Attempts)
+	assert.Equal(t, context.DeadlineExceeded.Error(), done.Error)
+}
+
+// TestWorker_UnknownKind は Handler のないジョブを再試行せずに失敗にすることを検証します。
+func TestWorker_UnknownKind(t *testing.T) {
+	t.Parallel()
+	s, _ := newTestStore(t)
+	runWorker(t, NewWorker(s, map[string]Handler{}, WorkerConfig{Concurrency: 1}))
+
+	job, err := s.Enqueue(context.Background(), "missing", 1, nil)
+	require.NoError(t, err)
+	done := waitFinished(t, s, job.ID, 1)
+	assert.Equal(t, StatusFailed, done.Status)
+	assert.Equal(t, "unsupported job", done.Error)
+}
+
+// TestStore_OwnerScoping は他のユーザーのジョブ・形式の不正な ID を ErrNotFound にすることを検証します。
+func TestStore_OwnerScoping(t *testing.T) {
+	t.Parallel()
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+	job, err := s.Enqueue(ctx, "echo", 1, nil)
+	require.NoError(t, err)
+
+	_, err = s.Get(ctx, job.ID, 2)
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = s.Get(ctx, "../"+job.ID, 1)
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = s.Get(ctx, "0123456789abcdef0123456789abcdef", 1)
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = s.Get(ctx, job.ID, 1)
+	require.NoError(t, err)
+}
+
+// TestStore_Expiry は完了したジョブの結果を完了から Retention の間だけ保持することを検証します。
+func TestStore_Expiry(t *testing.T) {
+	t.Parallel()
+	s, mr := newTestStore(t)
+	ctx := context.Background()
+	job, err := s.Enqueue(ctx, "echo", 1, nil)
+	require.NoError(t, err)
+
+	mr.FastForward(50 * time.Minute)
+	runWorker(t, NewWorker(s, map[string]Handler{
+		"echo": func(context.Context, Job) (any, error) { return json.RawMessage(`true`), nil },
+	}, WorkerConfig{Concurrency: 1}))
+	waitFinished(t, s, job.ID, 1)
+
+	mr.FastForward(50 * time.Minute) // 登録からは 1 時間を過ぎたが、完了からは 1 時間以内
+	_, err = s.Get(ctx, job.ID, 1)
+	require.NoError(t, err)
+
+	mr.FastForward(11 * time.Minute)
+	_, err = s.Get(ctx, job.ID, 1)
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+type nilProvider struct{}
+
+func (nilProvider) Client() *redis.Client { return nil }
+
+// TestStore_Unavailable は Redis を利用できない間、登録・参照が ErrUnavailable を返すことを検証します。
+func TestStore_Unavailable(t *testing.T) {
+	t.Parallel()
+	s, _ := newTestStore(t)
+	s.WithRedisProvider(nilProvider{})
+
+	_, err := s.Enqueue(context.Background(), "echo", 1, nil)
+	require.ErrorIs(t, err, ErrUnavailable)
+	_, err = s.Get(context.Background(), "0123456789abcdef0123456789abcdef", 1)
+	require.ErrorIs(t, err, ErrUnavailable)
+}
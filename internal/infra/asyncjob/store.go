@@ -0,0 +1,185 @@
+package asyncjob
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultRetention はジョブ（結果を含む）を保持する既定の期間です。
+const DefaultRetention = time.Hour
+
+// idPattern はジョブ ID（16 バイトの乱数の 16 進表記）の形式です。
+var idPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// RedisProvider は現在利用できる Redis クライアントを返します。障害中は nil を返します。
+type RedisProvider interface {
+	Client() *redis.Client
+}
+
+// Store はジョブを Redis に保存します。
+// ジョブは <prefix>:job:<ID> に JSON で保存し（期限は Retention）、処理待ちの ID を <prefix>:queue のリストに積みます。
+type Store struct {
+	rdb       *redis.Client
+	provider  RedisProvider
+	prefix    string
+	retention time.Duration
+	now       func() time.Time
+}
+
+// NewStore は prefix（例: KeyBuilder.Key("jobs")）の下にジョブを保存する Store を生成します。
+// retention が 0 以下の場合は DefaultRetention です。
+func NewStore(rdb *redis.Client, prefix string, retention time.Duration) *Store {
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	return &Store{rdb: rdb, prefix: prefix, retention: retention, now: time.Now}
+}
+
+// WithRedisProvider は Redis クライアントを呼び出しごとに p から取得するよう設定します。
+// p が nil を返す間、Enqueue・Get は ErrUnavailable を返します。
+func (s *Store) WithRedisProvider(p RedisProvider) *Store {
+	s.provider = p
+	return s
+}
+
+func (s *Store) client() *redis.Client {
+	if s.provider != nil {
+		return s.provider.Client()
+	}
+	return s.rdb
+}
+
+func (s *Store) jobKey(id string) string { return s.prefix + ":job:" + id }
+func (s *Store) queueKey() string        { return s.prefix + ":queue" }
+
+// Enqueue は owner の kind のジョブを payload（JSON にして保存）で登録し、処理待ちに積みます。
+func (s *Store) Enqueue(ctx context.Context, kind string, owner int64, payload any) (Job, error) {
+	rdb := s.client()
+	if rdb == nil {
+		return Job{}, ErrUnavailable
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return Job{}, fmt.Errorf("encode %s payload: %w", kind, err)
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return Job{}, fmt.Errorf("generate job id: %w", err)
+	}
+	now := s.now().UTC()
+	job := Job{
+		ID:        hex.EncodeToString(b),
+		Kind:      kind,
+		Owner:     owner,
+		Status:    StatusPending,
+		Payload:   raw,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	data, err := json.Marshal(job)
+	if err != nil {
+		return Job{}, fmt.Errorf("encode job: %w", err)
+	}
+	if _, err := rdb.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.Set(ctx, s.jobKey(job.ID), data, s.retention)
+		p.LPush(ctx, s.queueKey(), job.ID)
+		return nil
+	}); err != nil {
+		return Job{}, fmt.Errorf("enqueue %s job: %w", kind, err)
+	}
+	return job, nil
+}
+
+// Get は owner が登録した id のジョブを返します。
+// 存在しない・期限切れ・他のユーザーのジョブの場合は ErrNotFound を返します（他のユーザーのジョブの存在は明かさない）。
+func (s *Store) Get(ctx context.Context, id string, owner int64) (Job, error) {
+	rdb := s.client()
+	if rdb == nil {
+		return Job{}, ErrUnavailable
+	}
+	if !idPattern.MatchString(id) {
+		return Job{}, ErrNotFound
+	}
+	job, err := s.load(ctx, rdb, id)
+	if err != nil {
+		return Job{}, err
+	}
+	if job.Owner != owner {
+		return Job{}, ErrNotFound
+	}
+	return job, nil
+}
+
+// load は id のジョブを読み込みます。存在しない場合は ErrNotFound です。
+func (s *Store) load(ctx context.Context, rdb *redis.Client, id string) (Job, error) {
+	data, err := rdb.Get(ctx, s.jobKey(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return Job{}, ErrNotFound
+	}
+	if err != nil {
+		return Job{}, fmt.Errorf("load job %s: %w", id, err)
+	}
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return Job{}, fmt.Errorf("decode job %s: %w", id, err)
+	}
+	return job, nil
+}
+
+// dequeue は処理待ちのジョブを 1 件取り出します。wait の間に処理待ちがなければ ok = false を返します。
+// 取り出した ID のジョブが期限切れで消えている場合も ok = false です。
+func (s *Store) dequeue(ctx context.Context, rdb *redis.Client, wait time.Duration) (Job, bool, error) {
+	res, err := rdb.BRPop(ctx, wait, s.queueKey()).Result()
+	if errors.Is(err, redis.Nil) {
+		return Job{}, false, nil
+	}
+	if err != nil {
+		return Job{}, false, fmt.Errorf("dequeue job: %w", err)
+	}
+	job, err := s.load(ctx, rdb, res[1])
+	if errors.Is(err, ErrNotFound) {
+		return Job{}, false, nil
+	}
+	if err != nil {
+		return Job{}, false, err
+	}
+	return job, true, nil
+}
+
+// requeue は取り出したジョブを（試行回数を保存して）処理待ちの先頭（次に取り出される位置）に戻します。
+func (s *Store) requeue(ctx context.Context, rdb *redis.Client, job Job) error {
+	job.UpdatedAt = s.now().UTC()
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("encode job %s: %w", job.ID, err)
+	}
+	if _, err := rdb.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.Set(ctx, s.jobKey(job.ID), data, s.retention)
+		p.RPush(ctx, s.queueKey(), job.ID)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("requeue job %s: %w", job.ID, err)
+	}
+	return nil
+}
+
+// save はジョブを保存し、期限を Retention に延ばします（完了したジョブの結果は完了から Retention の間残る）。
+func (s *Store) save(ctx context.Context, rdb *redis.Client, job Job) error {
+	job.UpdatedAt = s.now().UTC()
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("encode job %s: %w", job.ID, err)
+	}
+	if err := rdb.Set(ctx, s.jobKey(job.ID), data, s.retention).Err(); err != nil {
+		return fmt.Errorf("save job %s: %w", job.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package asyncjob
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
+)
+
+// Worker の既定値です。
+const (
+	DefaultConcurrency  = 2
+	DefaultTimeout      = 30 * time.Second
+	DefaultMaxAttempts  = 2 // 失敗したジョブを 1 回だけ再試行する
+	DefaultRetryDelay   = time.Second
+	DefaultPollInterval = time.Second
+
+	// recordTimeout は結果の保存・処理待ちへの戻しにかける時間の上限です。
+	recordTimeout = 5 * time.Second
+	// maxErrorLength は Job.Error に残すエラー文字列の最大長です。
+	maxErrorLength = 500
+)
+
+// Handler は 1 件のジョブを処理し、結果（JSON にして保存する）を返します。
+// エラーの文言は Job.Error としてクライアントに返るため、公開してよい文言にします（詳細は Handler 側でログに残す）。
+type Handler func(ctx context.Context, job Job) (any, error)
+
+// WorkerConfig は Worker の設定です。ゼロ値の項目は既定値を使います。
+type WorkerConfig struct {
+	// Concurrency は同時に処理するジョブの数です。
+	Concurrency int
+	// Timeout は 1 回の試行にかける時間の上限です。
+	Timeout time.Duration
+	// MaxAttempts は 1 件のジョブを試行する最大回数です（ErrPermanent の失敗は再試行しない）。
+	MaxAttempts int
+	// RetryDelay は失敗してから再試行するまでの待ち時間です。
+	RetryDelay time.Duration
+	// PollInterval は処理待ちを待つ時間（BRPOP のタイムアウト）と、Redis 障害中に次に試すまでの間隔です。
+	PollInterval time.Duration
+}
+
+func (c WorkerConfig) withDefaults() WorkerConfig {
+	if c.Concurrency <= 0 {
+		c.Concurrency = DefaultConcurrency
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultTimeout
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = DefaultMaxAttempts
+	}
+	if c.RetryDelay <= 0 {
+		c.RetryDelay = DefaultRetryDelay
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = DefaultPollInterval
+	}
+	return c
+}
+
+// WorkerStats は Worker の処理件数の累計です。
+type WorkerStats struct {
+	Done    uint64 // 成功した件数
+	Failed  uint64 // 失敗として記録した件数
+	Retried uint64 // 失敗して再試行した回数
+}
+
+// Worker は処理待ちのジョブを取り出し、Kind ごとの Handler で処理して結果を保存します。
+// 取り出しは BRPOP で行うため、複数のインスタンスで起動できます（1 件のジョブは 1 つのワーカーだけが処理する）。
+type Worker struct {
+	store    *Store
+	handlers map[string]Handler
+	cfg      WorkerConfig
+
+	done, failed, retried atomic.Uint64
+}
+
+// NewWorker は Worker を生成します。handlers は Kind ごとの Handler です。処理するには Run を起動する必要があります。
+func NewWorker(store *Store, handlers map[string]Handler, cfg WorkerConfig) *Worker {
+	return &Worker{store: store, handlers: handlers, cfg: cfg.withDefaults()}
+}
+
+// Stats はこれまでの処理件数を返します。
+func (w *Worker) Stats() WorkerStats {
+	return WorkerStats{Done: w.done.Load(), Failed: w.failed.Load(), Retried: w.retried.Load()}
+}
+
+// Run は ctx が終了するまで Concurrency 個の goroutine でジョブを処理します。
+// ctx の終了後は処理中のジョブ（Timeout まで）の完了を待ってから戻ります。再試行を待っていたジョブは処理待ちに戻します。
+func (w *Worker) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for range w.cfg.Concurrency {
+		wg.Go(func() { w.loop(ctx) })
+	}
+	wg.Wait()
+}
+
+func (w *Worker) loop(ctx context.Context) {
+	for ctx.Err() == nil {
+		rdb := w.store.client()
+		if rdb == nil {
+			sleep(ctx, w.cfg.PollInterval)
+			continue
+		}
+		job, ok, err := w.store.dequeue(ctx, rdb, w.cfg.PollInterval)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Error("failed to dequeue async job", "error", err)
+				sleep(ctx, w.cfg.PollInterval)
+			}
+			continue
+		}
+		if ok {
+			w.process(ctx, job)
+		}
+	}
+}
+
+// process は 1 件のジョブを最大 MaxAttempts 回試行し、結果を保存します。
+// 各試行は Run の ctx から切り離して Timeout を上限に行い（停止中も試行中の 1 回は完了させる）、
+// 再試行の待機中に ctx が終了した場合は、ジョブを処理待ちに戻して他のワーカーに任せます。
+func (w *Worker) process(ctx context.Context, job Job) {
+	h, ok := w.handlers[job.Kind]
+	if !ok {
+		slog.Error("no handler for async job", "job_id", job.ID, "kind", job.Kind)
+		w.finish(job, nil, Permanent(errors.New("unsupported job")))
+		return
+	}
+	for {
+		job.Attempts++
+		hctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), w.cfg.Timeout)
+		result, err := h(hctx, job)
+		cancel()
+		if err == nil || errors.Is(err, ErrPermanent) || job.Attempts >= w.cfg.MaxAttempts {
+			w.finish(job, result, err)
+			return
+		}
+		w.retried.Add(1)
+		slog.Warn("async job failed, retrying", "job_id", job.ID, "kind", job.Kind, "attempts", job.Attempts, "error", err)
+		if !sleep(ctx, w.cfg.RetryDelay) {
+			rctx, cancel := context.WithTimeout(context.Background(), recordTimeout)
+			if rdb := w.store.client(); rdb == nil {
+				slog.Warn("could not requeue async job on shutdown", "job_id", job.ID, "error", ErrUnavailable)
+			} else if err := w.store.requeue(rctx, rdb, job); err != nil {
+				slog.Warn("could not requeue async job on shutdown", "job_id", job.ID, "error", err)
+			}
+			cancel()
+			return
+		}
+	}
+}
+
+// finish はジョブの結果（成功なら result、失敗なら err の文言）を保存します。
+func (w *Worker) finish(job Job, result any, err error) {
+	if err == nil {
+		data, merr := json.Marshal(result)
+		if merr != nil {
+			err = Permanent(fmt.Errorf("encode result: %w", merr))
+		} else {
+			job.Status, job.Result = StatusDone, data
+		}
+	}
+	if err != nil {
+		job.Status, job.Error = StatusFailed, apperr.Truncate(err, maxErrorLength)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), recordTimeout)
+	defer cancel()
+	rdb := w.store.client()
+	if rdb == nil {
+		slog.Error("failed to record async job result", "job_id", job.ID, "kind", job.Kind, "error", ErrUnavailable)
+		return
+	}
+	if serr := w.store.save(ctx, rdb, job); serr != nil {
+		slog.Error("failed to record async job result", "job_id", job.ID, "kind", job.Kind, "error", serr)
+		return
+	}
+	if job.Status == StatusDone {
+		w.done.Add(1)
+		return
+	}
+	w.failed.Add(1)
+	slog.Warn("async job failed", "job_id", job.ID, "kind", job.Kind, "attempts", job.Attempts, "error", err)
+}
+
+// sleep は d だけ待ちます。ctx が先に終了した場合は false を返します。
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
@@ -0,0 +1,97 @@
+// Package blobstore は一時ファイル（エクスポートアーカイブ等）の保管先を提供します。
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// keyPattern は保存キーとして許可する文字です。パス区切りを含められないようにし、
+// ディレクトリ外への書き込み（パストラバーサル）を防ぎます。
+var keyPattern = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]{0,127}$`)
+
+// FileStore はローカルディレクトリにファイルとして保存する BlobStore です。
+// 書き込みは一時ファイルへ行い、Close でリネームして確定するため、書きかけの内容が読まれることはありません。
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore は dir（存在しなければ 0700 で作成）を保存先とする FileStore を生成します。
+func NewFileStore(dir string) (*FileStore, error) {
+	if dir == "" {
+		return nil, errors.New("blobstore: dir is empty")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("blobstore: create dir: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Create は key への書き込みを開始します。返り値の Close で内容を確定します。
+func (s *FileStore) Create(_ context.Context, key string) (io.WriteCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp(s.dir, "."+key+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("blobstore: create %s: %w", key, err)
+	}
+	return &pendingFile{File: f, dest: path}, nil
+}
+
+// Open は key の内容を読み出します。存在しない場合は fs.ErrNotExist をラップしたエラーを返します。
+func (s *FileStore) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("blobstore: open %s: %w", key, err)
+	}
+	return f, nil
+}
+
+// Delete は key を削除します。存在しない場合もエラーにしません。
+func (s *FileStore) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("blobstore: delete %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *FileStore) path(key string) (string, error) {
+	if !keyPattern.MatchString(key) {
+		return "", fmt.Errorf("blobstore: invalid key %q", key)
+	}
+	return filepath.Join(s.dir, key), nil
+}
+
+// pendingFile は Close 時に一時ファイルを保存先へリネームする書き込み先です。
+type pendingFile struct {
+	*os.File
+	dest string
+}
+
+func (p *pendingFile) Close() error {
+	if err := p.File.Close(); err != nil {
+		_ = os.Remove(p.Name())
+		return err
+	}
+	if err := os.Rename(p.Name(), p.dest); err != nil {
+		_ = os.Remove(p.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "exports")
+	s, err := NewFileStore(dir)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	w, err := s.Create(ctx, "job.zip")
+	require.NoError(t, err)
+	_, err = io.WriteString(w, "payload")
+	require.NoError(t, err)
+
+	// Close 前は読めない（書きかけの内容を公開しない）
+	_, err = s.Open(ctx, "job.zip")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+
+	require.NoError(t, w.Close())
+	r, err := s.Open(ctx, "job.zip")
+	require.NoError(t, err)
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, "payload", string(got))
+
+	require.NoError(t, s.Delete(ctx, "job.zip"))
+	require.NoError(t, s.Delete(ctx, "job.zip"), "存在しないキーの削除はエラーにしない")
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "一時ファイルも残らない")
+}
+
+func TestFileStore_RejectsUnsafeKeys(t *testing.T) {
+	t.Parallel()
+
+	s, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	for _, key := range []string{"", "../escape.zip", "a/b.zip", ".hidden", "..", "a\\b"} {
+		_, err := s.Create(ctx, key)
+		assert.Error(t, err, "key=%q", key)
+		_, err = s.Open(ctx, key)
+		assert.Error(t, err, "key=%q", key)
+		assert.Error(t, s.Delete(ctx, key), "key=%q", key)
+	}
+}
@@ -79,15 +79,16 @@ func quotePGValue(v string) string {
 // それ以外の場合はHostとPortを使用してTCP接続を作成します。
 // 各値は libpq の仕様に従ってエスケープされるため、パスワード等に空白や特殊文字が
 // 含まれていても安全に DSN を生成できます。
+// 日本語の銘柄名等が文字化けしないよう、クライアントの文字コードは常に UTF8 を明示します（サーバー側は CheckEncoding で検証）。
 func BuildDSN(cfg Config) string {
 	if cfg.InstanceName != "" {
-		return fmt.Sprintf("host=%s user=%s password=%s dbname=%s",
+		return fmt.Sprintf("host=%s user=%s password=%s dbname=%s client_encoding=UTF8",
 			quotePGValue("/cloudsql/"+cfg.InstanceName),
 			quotePGValue(cfg.User),
 			quotePGValue(string(cfg.Password)),
 			quotePGValue(cfg.Name))
 	}
-	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable client_encoding=UTF8",
 		quotePGValue(cfg.Host),
 		quotePGValue(cfg.Port),
 		quotePGValue(cfg.User),
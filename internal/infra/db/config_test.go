@@ -23,7 +23,7 @@ func TestBuildDSN_TCP(t *testing.T) {
 
 	dsn := BuildDSN(cfg)
 
-	expected := "host=localhost port=5432 user=testuser password=testpass dbname=testdb sslmode=disable"
+	expected := "host=localhost port=5432 user=testuser password=testpass dbname=testdb sslmode=disable client_encoding=UTF8"
 	if dsn != expected {
 		t.Errorf("expected DSN %q, got %q", expected, dsn)
 	}
@@ -42,7 +42,7 @@ func TestBuildDSN_CloudSQL(t *testing.T) {
 
 	dsn := BuildDSN(cfg)
 
-	expected := "host=/cloudsql/project:region:instance user=testuser password=testpass dbname=testdb"
+	expected := "host=/cloudsql/project:region:instance user=testuser password=testpass dbname=testdb client_encoding=UTF8"
 	if dsn != expected {
 		t.Errorf("expected DSN %q, got %q", expected, dsn)
 	}
@@ -63,10 +63,10 @@ func TestBuildDSN_CloudSQLTakesPrecedence(t *testing.T) {
 
 	dsn := BuildDSN(cfg)
 
-	if dsn == "host=localhost port=5432 user=testuser password=testpass dbname=testdb sslmode=disable" {
+	if dsn == "host=localhost port=5432 user=testuser password=testpass dbname=testdb sslmode=disable client_encoding=UTF8" {
 		t.Error("expected Cloud SQL DSN format, but got TCP format")
 	}
-	expected := "host=/cloudsql/project:region:instance user=testuser password=testpass dbname=testdb"
+	expected := "host=/cloudsql/project:region:instance user=testuser password=testpass dbname=testdb client_encoding=UTF8"
 	if dsn != expected {
 		t.Errorf("expected DSN %q, got %q", expected, dsn)
 	}
@@ -87,28 +87,28 @@ func TestBuildDSN_EscapesSpecialCharacters(t *testing.T) {
 			cfg: Config{
 				User: "u", Password: "p@ss word", Name: "d", Host: "h", Port: "5432",
 			},
-			expected: "host=h port=5432 user=u password='p@ss word' dbname=d sslmode=disable",
+			expected: "host=h port=5432 user=u password='p@ss word' dbname=d sslmode=disable client_encoding=UTF8",
 		},
 		{
 			name: "password with single quote and backslash",
 			cfg: Config{
 				User: "u", Password: `p'a\ss`, Name: "d", Host: "h", Port: "5432",
 			},
-			expected: `host=h port=5432 user=u password='p\'a\\ss' dbname=d sslmode=disable`,
+			expected: `host=h port=5432 user=u password='p\'a\\ss' dbname=d sslmode=disable client_encoding=UTF8`,
 		},
 		{
 			name: "empty password is quoted",
 			cfg: Config{
 				User: "u", Password: "", Name: "d", Host: "h", Port: "5432",
 			},
-			expected: "host=h port=5432 user=u password='' dbname=d sslmode=disable",
+			expected: "host=h port=5432 user=u password='' dbname=d sslmode=disable client_encoding=UTF8",
 		},
 		{
 			name: "user with equals sign",
 			cfg: Config{
 				User: "us=er", Password: "p", Name: "d", Host: "h", Port: "5432",
 			},
-			expected: "host=h port=5432 user='us=er' password=p dbname=d sslmode=disable",
+			expected: "host=h port=5432 user='us=er' password=p dbname=d sslmode=disable client_encoding=UTF8",
 		},
 	}
 
@@ -64,10 +64,10 @@ func RunMainWithPostgres(m *testing.M) (int, error) {
 	return m.Run(), nil
 }
 
-// OpenIsolatedDB はテストごとに独立した PostgreSQL データベースを作成し、
+// OpenIsolatedDB はテスト（ベンチマーク）ごとに独立した PostgreSQL データベースを作成し、
 // マイグレーションを適用した *sql.DB を返します。
 // t.Cleanup で DB は自動的に DROP されます。
-func OpenIsolatedDB(t *testing.T) *sql.DB {
+func OpenIsolatedDB(t testing.TB) *sql.DB {
 	t.Helper()
 	mu.Lock()
 	dsn := adminDSN
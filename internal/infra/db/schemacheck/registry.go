@@ -0,0 +1,133 @@
+package schemacheck
+
+// Expected はアプリケーションが前提とするスキーマ（db/migrations を最新まで適用した状態）を返します。
+// マイグレーションでテーブル・列・インデックスを追加・削除したら、ここも合わせて更新します
+// （TestExpected_MatchesMigrations が、マイグレーション後の DB と一致しない場合に失敗します）。
+func Expected() []Table {
+	return []Table{
+		{
+			Name:    "users",
+			Columns: []string{"id", "email", "password", "created_at", "updated_at", "last_login_at", "plan"},
+			Indexes: []string{"idx_users_email"},
+		},
+		{
+			Name:    "oauth_accounts",
+			Columns: []string{"id", "user_id", "provider", "provider_uid", "created_at"},
+			Indexes: []string{"idx_oauth_accounts_user_id", "idx_oauth_provider_uid"},
+		},
+		{
+			Name: "symbols",
+			Columns: []string{
+				"id", "code", "name", "market", "timezone", "logo_url", "logo_updated_at", "is_active",
+				"created_at", "updated_at", "currency", "priority", "status", "status_since", "tier",
+			},
+			Indexes: []string{"idx_symbols_code"},
+		},
+		{
+			Name: "candles",
+			Columns: []string{
+				"id", "symbol_code", "interval", "time", "open", "high", "low", "close", "volume",
+				"created_at", "updated_at", "source",
+			},
+			Indexes: []string{"candle_sym_int_time"},
+		},
+		{
+			Name:    "watchlists",
+			Columns: []string{"id", "user_id", "symbol_code", "sort_key", "created_at", "updated_at"},
+			Indexes: []string{"idx_watchlist_user_symbol", "idx_watchlist_user_sort_key", "idx_watchlists_symbol_code"},
+		},
+		{
+			Name:    "data_freshness",
+			Columns: []string{"interval", "market", "last_success_at", "last_attempt_at", "last_error", "updated_at"},
+		},
+		{
+			Name:    "password_resets",
+			Columns: []string{"token_hash", "user_id", "expires_at", "created_at"},
+			Indexes: []string{"idx_password_resets_user_id"},
+		},
+		{
+			Name: "candle_anomalies",
+			Columns: []string{
+				"id", "symbol_code", "time", "prev_close", "close", "ratio", "status",
+				"detected_at", "resolved_at", "backfill_requested_at", "backfilled_at",
+			},
+			Indexes: []string{"uq_candle_anomalies_symbol_time", "idx_candle_anomalies_status_detected"},
+		},
+		{
+			Name:    "candle_adjustments",
+			Columns: []string{"id", "symbol_code", "effective_date", "factor", "reason", "created_at", "updated_at"},
+			Indexes: []string{"uq_candle_adjustments_symbol_date"},
+		},
+		{
+			Name: "alerts",
+			Columns: []string{
+				"id", "user_id", "symbol_code", "interval", "direction", "threshold", "created_at", "triggered_at",
+				"notified_at", "notify_error", "mode", "cooldown_minutes", "last_triggered_at", "last_side",
+			},
+			Indexes: []string{"idx_alerts_active_symbol_interval", "idx_alerts_user_id"},
+		},
+		{
+			Name:    "symbol_names",
+			Columns: []string{"symbol_code", "locale", "name", "created_at", "updated_at"},
+			Indexes: []string{"idx_symbol_names_locale"},
+		},
+		{
+			Name:    "annotations",
+			Columns: []string{"id", "user_id", "symbol_code", "interval", "time", "text", "created_at", "updated_at"},
+			Indexes: []string{"idx_annotations_user_symbol"},
+		},
+		{
+			Name:    "push_devices",
+			Columns: []string{"id", "user_id", "token", "platform", "app_version", "created_at", "updated_at", "disabled_at"},
+			Indexes: []string{"uq_push_devices_token", "idx_push_devices_user_active"},
+		},
+		{
+			Name: "push_jobs",
+			Columns: []string{
+				"id", "alert_id", "device_id", "title", "body", "data", "status", "attempts",
+				"next_attempt_at", "last_error", "created_at", "completed_at",
+			},
+			Indexes: []string{"uq_push_jobs_alert_device", "idx_push_jobs_due"},
+		},
+		{
+			Name:    "corporate_events",
+			Columns: []string{"id", "symbol_code", "type", "date", "value", "estimate", "source", "created_at", "updated_at"},
+			Indexes: []string{"uq_corporate_events_symbol_type_date", "idx_corporate_events_symbol_date"},
+		},
+		{
+			Name:    "symbol_daily_stats",
+			Columns: []string{"symbol_code", "as_of", "high_52w", "low_52w", "avg_vol_30d", "ytd_change_pct", "computed_at"},
+		},
+		{
+			Name:    "digest_subscriptions",
+			Columns: []string{"user_id", "created_at"},
+		},
+		{
+			Name:    "digest_sends",
+			Columns: []string{"user_id", "digest_date", "sent_at"},
+		},
+		{
+			Name: "outbox_events",
+			Columns: []string{
+				"id", "topic", "aggregate_key", "payload", "status", "attempts", "next_attempt_at",
+				"claimed_by", "last_error", "created_at", "completed_at",
+			},
+			Indexes: []string{"idx_outbox_events_due", "idx_outbox_events_key", "idx_outbox_events_done"},
+		},
+		{
+			Name:    "logo_usage",
+			Columns: []string{"user_id", "operation", "usage_date", "count", "updated_at"},
+		},
+		{
+			Name: "scheduled_jobs",
+			Columns: []string{
+				"name", "next_run_at", "run_requested_at", "last_started_at", "last_finished_at",
+				"last_status", "last_error", "last_duration_ms", "run_count", "failure_count", "updated_at",
+			},
+		},
+		{
+			Name:    "user_resource_versions",
+			Columns: []string{"user_id", "resource", "version"},
+		},
+	}
+}
@@ -0,0 +1,204 @@
+// Package schemacheck は稼働中の PostgreSQL のスキーマを、アプリケーションが前提とするスキーマ（Expected）と比べます。
+//
+// マイグレーション（cmd/migrate）の適用漏れや手作業での変更で、列・インデックスが欠けたまま
+// API を起動すると、該当するクエリが実行時に初めて失敗します。起動時・マイグレーション後・seed の前に
+// Check を実行し、欠けているテーブル・列・インデックスを一覧にして報告します。
+//
+// 比べるのは名前のみです（型・制約・インデックスの定義は比べない）。
+// 前提とするスキーマは registry.go の Expected に一か所で定義し、マイグレーションを追加したら合わせて更新します。
+package schemacheck
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// Table はアプリケーションが前提とするテーブルの列名とインデックス名です。
+type Table struct {
+	Name    string
+	Columns []string
+	// Indexes は名前を付けて作成したインデックス（UNIQUE 制約のインデックスを含む）です。主キーは含めません。
+	Indexes []string
+}
+
+// DriftKind はスキーマの差異の種類です。
+type DriftKind string
+
+const (
+	MissingTable  DriftKind = "missing_table"
+	MissingColumn DriftKind = "missing_column"
+	MissingIndex  DriftKind = "missing_index"
+)
+
+// Drift は前提とするスキーマにあって稼働中の DB にない 1 件です。Name は列名・インデックス名（テーブルの場合は空）です。
+type Drift struct {
+	Kind  DriftKind
+	Table string
+	Name  string
+}
+
+// String は "missing_column candles.source" の形で差異を返します。
+func (d Drift) String() string {
+	if d.Name == "" {
+		return string(d.Kind) + " " + d.Table
+	}
+	return string(d.Kind) + " " + d.Table + "." + d.Name
+}
+
+// Report は Check の結果です。
+type Report struct {
+	// Drift は欠けているテーブル・列・インデックスです（Expected の順）。
+	Drift []Drift
+	// Unregistered は稼働中の DB にあって Expected にないテーブルです（goose の管理テーブルを除く。差異としては扱わない）。
+	Unregistered []string
+}
+
+// OK は欠けているものがないかを返します。
+func (r Report) OK() bool { return len(r.Drift) == 0 }
+
+// Strings は差異を文字列の一覧で返します（/readyz・エラーメッセージ用）。
+func (r Report) Strings() []string {
+	out := make([]string, 0, len(r.Drift))
+	for _, d := range r.Drift {
+		out = append(out, d.String())
+	}
+	return out
+}
+
+// Log は差異を 1 件ずつ構造化ログ（ERROR）に出力します。
+func (r Report) Log(ctx context.Context, logger *slog.Logger) {
+	for _, d := range r.Drift {
+		logger.ErrorContext(ctx, "schema drift", "kind", string(d.Kind), "table", d.Table, "name", d.Name)
+	}
+}
+
+// ErrDrift は前提とするスキーマとの差異がある場合に Verify が返すエラーです。
+var ErrDrift = errors.New("schema drift")
+
+// Err は差異がある場合に ErrDrift を包んだエラー（差異の一覧を含む）を、ない場合は nil を返します。
+func (r Report) Err() error {
+	if r.OK() {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrDrift, strings.Join(r.Strings(), ", "))
+}
+
+// Schema は稼働中の DB のテーブルごとの列名・インデックス名です。
+type Schema map[string]LiveTable
+
+// LiveTable は稼働中の DB の 1 テーブルの列名・インデックス名です。
+type LiveTable struct {
+	Columns map[string]struct{}
+	Indexes map[string]struct{}
+}
+
+// gooseTable は goose がマイグレーションの適用状況を記録するテーブルです。
+const gooseTable = "goose_db_version"
+
+// Compare は前提とするスキーマ tables と稼働中のスキーマ live を比べます。
+func Compare(tables []Table, live Schema) Report {
+	var r Report
+	registered := make(map[string]struct{}, len(tables))
+	for _, t := range tables {
+		registered[t.Name] = struct{}{}
+		lt, ok := live[t.Name]
+		if !ok {
+			r.Drift = append(r.Drift, Drift{Kind: MissingTable, Table: t.Name})
+			continue
+		}
+		for _, c := range t.Columns {
+			if _, ok := lt.Columns[c]; !ok {
+				r.Drift = append(r.Drift, Drift{Kind: MissingColumn, Table: t.Name, Name: c})
+			}
+		}
+		for _, ix := range t.Indexes {
+			if _, ok := lt.Indexes[ix]; !ok {
+				r.Drift = append(r.Drift, Drift{Kind: MissingIndex, Table: t.Name, Name: ix})
+			}
+		}
+	}
+	for name := range live {
+		if _, ok := registered[name]; !ok && name != gooseTable {
+			r.Unregistered = append(r.Unregistered, name)
+		}
+	}
+	slices.Sort(r.Unregistered)
+	return r
+}
+
+// Check は db の現在のスキーマ（search_path の先頭のスキーマ）を読み取り、tables と比べます。
+// 読み取りに失敗した場合はエラーを返します（差異の有無は判定できない）。
+func Check(ctx context.Context, db *sql.DB, tables []Table) (Report, error) {
+	live, err := Inspect(ctx, db)
+	if err != nil {
+		return Report{}, err
+	}
+	return Compare(tables, live), nil
+}
+
+// Inspect は db の現在のスキーマのテーブル・列・インデックスの名前を読み取ります。
+func Inspect(ctx context.Context, db *sql.DB) (Schema, error) {
+	live := Schema{}
+	table := func(name string) LiveTable {
+		lt, ok := live[name]
+		if !ok {
+			lt = LiveTable{Columns: map[string]struct{}{}, Indexes: map[string]struct{}{}}
+			live[name] = lt
+		}
+		return lt
+	}
+
+	if err := eachRow(ctx, db, `
+		SELECT c.table_name, c.column_name
+		FROM information_schema.columns c
+		JOIN information_schema.tables t
+		  ON t.table_schema = c.table_schema AND t.table_name = c.table_name
+		WHERE c.table_schema = current_schema() AND t.table_type = 'BASE TABLE'`,
+		func(tbl, col string) { table(tbl).Columns[col] = struct{}{} },
+	); err != nil {
+		return nil, fmt.Errorf("inspect columns: %w", err)
+	}
+	if err := eachRow(ctx, db, `
+		SELECT tablename, indexname
+		FROM pg_indexes
+		WHERE schemaname = current_schema()`,
+		func(tbl, ix string) { table(tbl).Indexes[ix] = struct{}{} },
+	); err != nil {
+		return nil, fmt.Errorf("inspect indexes: %w", err)
+	}
+	return live, nil
+}
+
+// eachRow は 2 列の文字列を返す query の各行で fn を呼びます。
+func eachRow(ctx context.Context, db *sql.DB, query string, fn func(a, b string)) error {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var a, b string
+		if err := rows.Scan(&a, &b); err != nil {
+			return err
+		}
+		fn(a, b)
+	}
+	return rows.Err()
+}
+
+// Verify は db を Expected と比べ、差異を logger に 1 件ずつ出力します。
+// 読み取りに失敗した場合・差異がある場合はエラー（差異は ErrDrift を包む）を返します。
+// マイグレーション後の確認・seed の前提条件の確認など、差異があれば処理を止める用途に使います。
+func Verify(ctx context.Context, db *sql.DB, logger *slog.Logger) (Report, error) {
+	report, err := Check(ctx, db, Expected())
+	if err != nil {
+		return report, fmt.Errorf("check schema: %w", err)
+	}
+	report.Log(ctx, logger)
+	return report, report.Err()
+}
@@ -0,0 +1,97 @@
+package schemacheck
+
+import (
+	"context"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/db/dbtest"
+)
+
+func TestMain(m *testing.M) {
+	code, err := dbtest.RunMainWithPostgres(m)
+	if err != nil {
+		log.Fatalf("dbtest setup: %v", err)
+	}
+	os.Exit(code)
+}
+
+func set(names ...string) map[string]struct{} {
+	m := make(map[string]struct{}, len(names))
+	for _, n := range names {
+		m[n] = struct{}{}
+	}
+	return m
+}
+
+// TestCompare は欠けているテーブル・列・インデックスを Expected の順に列挙し、未登録のテーブルを別に返すことを検証します。
+func TestCompare(t *testing.T) {
+	t.Parallel()
+	tables := []Table{
+		{Name: "users", Columns: []string{"id", "email"}, Indexes: []string{"idx_users_email"}},
+		{Name: "candles", Columns: []string{"id", "source"}, Indexes: []string{"candle_sym_int_time"}},
+		{Name: "digest_sends", Columns: []string{"user_id"}},
+	}
+	live := Schema{
+		"users":            {Columns: set("id", "email"), Indexes: set("users_pkey", "idx_users_email")},
+		"candles":          {Columns: set("id"), Indexes: set("candles_pkey")},
+		"goose_db_version": {Columns: set("id")},
+		"legacy_quotes":    {Columns: set("id")},
+	}
+
+	r := Compare(tables, live)
+	assert.False(t, r.OK())
+	assert.Equal(t, []Drift{
+		{Kind: MissingColumn, Table: "candles", Name: "source"},
+		{Kind: MissingIndex, Table: "candles", Name: "candle_sym_int_time"},
+		{Kind: MissingTable, Table: "digest_sends"},
+	}, r.Drift)
+	assert.Equal(t, []string{"missing_column candles.source", "missing_index candles.candle_sym_int_time", "missing_table digest_sends"}, r.Strings())
+	assert.Equal(t, []string{"legacy_quotes"}, r.Unregistered)
+	require.ErrorIs(t, r.Err(), ErrDrift)
+	assert.Contains(t, r.Err().Error(), "missing_column candles.source")
+
+	assert.True(t, Compare(tables[:1], live).OK())
+	assert.NoError(t, Compare(tables[:1], live).Err())
+}
+
+// TestExpected_MatchesMigrations はマイグレーションを最新まで適用した DB が Expected と一致し、
+// Expected にないテーブルがないことを検証します（マイグレーションを追加して registry を更新し忘れると失敗する）。
+func TestExpected_MatchesMigrations(t *testing.T) {
+	t.Parallel()
+	db := dbtest.OpenIsolatedDB(t)
+
+	r, err := Check(context.Background(), db, Expected())
+	require.NoError(t, err)
+	assert.Empty(t, r.Drift)
+	assert.Empty(t, r.Unregistered)
+}
+
+// TestVerify_Drift は列・インデックス・テーブルを削除した DB で差異を一覧にし、ErrDrift を返すことを検証します。
+func TestVerify_Drift(t *testing.T) {
+	t.Parallel()
+	db := dbtest.OpenIsolatedDB(t)
+	ctx := context.Background()
+	for _, stmt := range []string{
+		`ALTER TABLE candles DROP COLUMN source`,
+		`DROP INDEX idx_alerts_user_id`,
+		`DROP TABLE digest_sends`,
+	} {
+		_, err := db.ExecContext(ctx, stmt)
+		require.NoError(t, err, stmt)
+	}
+
+	r, err := Verify(ctx, db, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.ErrorIs(t, err, ErrDrift)
+	assert.Equal(t, []string{
+		"missing_column candles.source",
+		"missing_index alerts.idx_alerts_user_id",
+		"missing_table digest_sends",
+	}, r.Strings())
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log/slog"
@@ -47,9 +48,41 @@ func ConnectSQLWithRetry(dsn string, timeout time.Duration, opener SQLOpener) (*
 
 // OpenSQL は渡された設定を検証して *sql.DB を返します。
 // リトライロジックを含み、設定不正や接続失敗は呼び出し元へ返します。
+// 接続後に CheckEncoding で文字コードを検証し、UTF8 でなければエラーを返します（リトライしません）。
 // 設定の読み込み（環境変数）は internal/app/config に集約されています。
 func OpenSQL(cfg Config) (*sql.DB, error) {
-	return openSQLWithRetry(cfg, 60*time.Second, DefaultSQLOpener)
+	db, err := openSQLWithRetry(cfg, 60*time.Second, DefaultSQLOpener)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := CheckEncoding(ctx, db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// CheckEncoding はセッションのサーバー・クライアントの文字コードがいずれも UTF8 であることを検証します。
+// SQL_ASCII 等で作成されたデータベースでは日本語がバイト列のまま保存され、読み出し側で文字化けするため、
+// 起動時に検出して接続を拒否します。
+func CheckEncoding(ctx context.Context, db *sql.DB) error {
+	var server, client string
+	if err := db.QueryRowContext(ctx,
+		"SELECT current_setting('server_encoding'), current_setting('client_encoding')",
+	).Scan(&server, &client); err != nil {
+		return fmt.Errorf("query session encoding: %w", err)
+	}
+	return validateEncoding(server, client)
+}
+
+// validateEncoding は CheckEncoding の判定部分です。
+func validateEncoding(server, client string) error {
+	if server != "UTF8" || client != "UTF8" {
+		return fmt.Errorf("database encoding must be UTF8 (server_encoding=%s, client_encoding=%s)", server, client)
+	}
+	return nil
 }
 
 // openSQLWithRetry は OpenSQL の検証と接続処理を実行します。
@@ -135,3 +135,25 @@ func TestOpenSQLWithRetry_ConnectionFailure(t *testing.T) {
 		t.Errorf("expected 1 attempt, got %d", calls)
 	}
 }
+
+// TestValidateEncoding はサーバー・クライアントのいずれかが UTF8 でなければエラーになることを検証します。
+func TestValidateEncoding(t *testing.T) {
+	tests := []struct {
+		server, client string
+		wantErr        bool
+	}{
+		{server: "UTF8", client: "UTF8"},
+		{server: "SQL_ASCII", client: "UTF8", wantErr: true},
+		{server: "UTF8", client: "SJIS", wantErr: true},
+		{server: "EUC_JP", client: "EUC_JP", wantErr: true},
+	}
+	for _, tt := range tests {
+		err := validateEncoding(tt.server, tt.client)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateEncoding(%q, %q) error = %v, wantErr %v", tt.server, tt.client, err, tt.wantErr)
+		}
+		if err != nil && !strings.Contains(err.Error(), tt.server) {
+			t.Errorf("error should name the encodings, got %q", err)
+		}
+	}
+}
@@ -4,33 +4,86 @@ package httpclient
 import (
 	"net"
 	"net/http"
+	"net/url"
 	"time"
 )
 
-// New は外部API呼び出し用に設定されたHTTPクライアントを作成します。
+// Config の既定値です。ゼロ値の項目にはこれらを使います。
+const (
+	DefaultDialTimeout         = 5 * time.Second
+	DefaultKeepAlive           = 30 * time.Second
+	DefaultMaxIdleConns        = 100
+	DefaultMaxIdleConnsPerHost = 16
+	DefaultIdleConnTimeout     = 90 * time.Second
+	DefaultTLSHandshakeTimeout = 5 * time.Second
+)
+
+// Config は外部API用HTTPクライアントの接続・プール設定です。ゼロ値の項目は既定値を使います。
+type Config struct {
+	Timeout             time.Duration // リクエスト全体のタイムアウト（http.Client.Timeout）。0 ならタイムアウトなし
+	DialTimeout         time.Duration // TCP 接続のタイムアウト
+	KeepAlive           time.Duration // TCP キープアライブの間隔
+	MaxIdleConns        int           // 全ホスト合計のアイドル接続の上限
+	MaxIdleConnsPerHost int           // ホストごとのアイドル接続の上限（標準の 2 では並列呼び出しで接続を使い捨てる）
+	IdleConnTimeout     time.Duration // アイドル接続を閉じるまでの時間
+	TLSHandshakeTimeout time.Duration // TLS ハンドシェイクの上限時間
+	// Proxy は経由するプロキシです。nil の場合は環境変数（HTTPS_PROXY / HTTP_PROXY / NO_PROXY）に従います。
+	Proxy *url.URL
+}
+
+// New は timeout 以外を既定値にした外部API呼び出し用のHTTPクライアントを作成します。
+func New(timeout time.Duration) *http.Client {
+	return NewWithConfig(Config{Timeout: timeout})
+}
+
+// NewWithConfig は cfg の設定で外部API呼び出し用のHTTPクライアントを作成します。
 //
-// 設定:
-//   - Proxy: 環境変数（HTTP_PROXYなど）が設定されている場合に使用
-//   - Dialer.Timeout: TCP接続タイムアウト（デフォルトより短い）
-//   - Dialer.KeepAlive: 再利用可能なTCP接続の維持期間
-//   - MaxIdleConns: 最大アイドル接続数（高負荷時の枯渇防止のため100）
-//   - IdleConnTimeout: アイドル接続の維持期間
-//   - TLSHandshakeTimeout: HTTPSハンドシェイクの最大時間
-//   - Client.Timeout: リクエスト全体のタイムアウト（呼び出し元から渡される）
+// 接続（TCP / TLS）を再利用してハンドシェイクの遅延と接続数の制限への抵触を避けるため、
+// クライアントは呼び出しごとに作らず、同じ外部APIを呼ぶ処理（並列のワーカーを含む）で共有すること。
+// HTTP/2 は ForceAttemptHTTP2 で有効にし、対応するホストでは 1 接続に多重化します。
 //
 // 注意:
 //   - http.DefaultClientにはタイムアウトがないため、常にカスタムクライアントを使用すること
 //   - Transportは接続の安定性とリソース管理のために明示的に設定
-func New(timeout time.Duration) *http.Client {
+func NewWithConfig(cfg Config) *http.Client {
+	cfg = cfg.withDefaults()
+	proxy := http.ProxyFromEnvironment
+	if cfg.Proxy != nil {
+		proxy = http.ProxyURL(cfg.Proxy)
+	}
 	t := &http.Transport{
-		Proxy: http.ProxyFromEnvironment,
+		Proxy: proxy,
 		DialContext: (&net.Dialer{
-			Timeout:   5 * time.Second,
-			KeepAlive: 30 * time.Second,
+			Timeout:   cfg.DialTimeout,
+			KeepAlive: cfg.KeepAlive,
 		}).DialContext,
-		MaxIdleConns:        100,
-		IdleConnTimeout:     90 * time.Second,
-		TLSHandshakeTimeout: 5 * time.Second,
+		ForceAttemptHTTP2:   true,
+		MaxIdleConns:        cfg.MaxIdleConns,
+		MaxIdleConnsPerHost: cfg.MaxIdleConnsPerHost,
+		IdleConnTimeout:     cfg.IdleConnTimeout,
+		TLSHandshakeTimeout: cfg.TLSHandshakeTimeout,
+	}
+	return &http.Client{Timeout: cfg.Timeout, Transport: t}
+}
+
+func (c Config) withDefaults() Config {
+	if c.DialTimeout <= 0 {
+		c.DialTimeout = DefaultDialTimeout
+	}
+	if c.KeepAlive <= 0 {
+		c.KeepAlive = DefaultKeepAlive
+	}
+	if c.MaxIdleConns <= 0 {
+		c.MaxIdleConns = DefaultMaxIdleConns
+	}
+	if c.MaxIdleConnsPerHost <= 0 {
+		c.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	}
+	if c.IdleConnTimeout <= 0 {
+		c.IdleConnTimeout = DefaultIdleConnTimeout
+	}
+	if c.TLSHandshakeTimeout <= 0 {
+		c.TLSHandshakeTimeout = DefaultTLSHandshakeTimeout
 	}
-	return &http.Client{Timeout: timeout, Transport: t}
+	return c
 }
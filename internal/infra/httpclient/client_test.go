@@ -0,0 +1,131 @@
+package httpclient
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// okHandler は "ok" を返すハンドラーです。
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+	_, _ = io.WriteString(w, "ok")
+})
+
+// newCountingServer は新規に受け付けた TCP 接続の数を数えるテストサーバーを起動します。
+func newCountingServer(t *testing.T, h http.Handler) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	var conns atomic.Int64
+	srv := httptest.NewUnstartedServer(h)
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return srv, &conns
+}
+
+func get(t *testing.T, c *http.Client, u string) {
+	t.Helper()
+	resp, err := c.Get(u)
+	if err != nil {
+		t.Errorf("GET: %v", err)
+		return
+	}
+	// ボディを読み切って閉じないと接続はプールに戻らない
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+}
+
+// TestNewWithConfig_ReusesConnections は連続した呼び出しが 1 本の接続を使い回すことを検証します。
+func TestNewWithConfig_ReusesConnections(t *testing.T) {
+	t.Parallel()
+
+	srv, conns := newCountingServer(t, okHandler)
+	c := NewWithConfig(Config{Timeout: 5 * time.Second})
+	for range 10 {
+		get(t, c, srv.URL)
+	}
+	if got := conns.Load(); got != 1 {
+		t.Errorf("new connections = %d, want 1 (keep-alive reuse)", got)
+	}
+}
+
+// TestNewWithConfig_PoolsConcurrentConnections は並列の呼び出しで開いた接続がプールに残り、
+// 以降の並列呼び出しで新しい接続を開かないことを検証します（標準の MaxIdleConnsPerHost=2 では閉じられる）。
+func TestNewWithConfig_PoolsConcurrentConnections(t *testing.T) {
+	t.Parallel()
+
+	const workers = 8
+	// 全ワーカーが同時にリクエスト中になるよう、サーバー側で揃えてから応答する
+	var arrived sync.WaitGroup
+	arrived.Add(workers)
+	release := make(chan struct{})
+	srv, conns := newCountingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("sync") != "" {
+			arrived.Done()
+			<-release
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	c := New(5 * time.Second)
+	burst := func(query string) {
+		var wg sync.WaitGroup
+		for range workers {
+			wg.Go(func() { get(t, c, srv.URL+query) })
+		}
+		if query != "" {
+			arrived.Wait()
+			close(release)
+		}
+		wg.Wait()
+	}
+
+	burst("?sync=1")
+	opened := conns.Load()
+	if opened != workers {
+		t.Fatalf("first burst opened %d connections, want %d", opened, workers)
+	}
+	for range 3 {
+		burst("")
+	}
+	if got := conns.Load(); got != opened {
+		t.Errorf("connections after reuse = %d, want %d (idle connections must stay pooled)", got, opened)
+	}
+}
+
+func TestNewWithConfig_Defaults(t *testing.T) {
+	t.Parallel()
+
+	c := New(3 * time.Second)
+	tr, ok := c.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("transport = %T, want *http.Transport", c.Transport)
+	}
+	if c.Timeout != 3*time.Second {
+		t.Errorf("Timeout = %v", c.Timeout)
+	}
+	if tr.MaxIdleConnsPerHost != DefaultMaxIdleConnsPerHost || tr.MaxIdleConns != DefaultMaxIdleConns ||
+		tr.IdleConnTimeout != DefaultIdleConnTimeout || tr.TLSHandshakeTimeout != DefaultTLSHandshakeTimeout || !tr.ForceAttemptHTTP2 {
+		t.Errorf("unexpected transport settings: %+v", tr)
+	}
+}
+
+func TestNewWithConfig_Proxy(t *testing.T) {
+	t.Parallel()
+
+	proxy, _ := url.Parse("http://proxy.internal:3128")
+	tr := NewWithConfig(Config{Proxy: proxy}).Transport.(*http.Transport)
+	req, _ := http.NewRequest(http.MethodGet, "https://api.twelvedata.com/time_series", nil)
+	got, err := tr.Proxy(req)
+	if err != nil || got == nil || got.String() != proxy.String() {
+		t.Errorf("Proxy = %v, %v; want %v", got, err, proxy)
+	}
+}
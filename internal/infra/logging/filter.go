@@ -0,0 +1,130 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+)
+
+// 記録の絞り込みに使う属性キーです。
+const (
+	// ComponentKey は記録の出どころ（例: "cache"）を示す属性です。コンポーネントごとのレベル上書きの対象になります。
+	ComponentKey = "component"
+	// SampledKey が true の記録は間引きの対象です（例: アクセスログの 2xx）。ERROR 以上は間引きません。
+	SampledKey = "sampled"
+)
+
+// FilterOptions は NewFilterHandler の設定です。
+type FilterOptions struct {
+	// Level はコンポーネントの上書きがない記録の最低レベルです。
+	Level slog.Level
+	// ComponentLevels はコンポーネント（ComponentKey の値）ごとの最低レベルで、Level より優先します。
+	ComponentLevels map[string]slog.Level
+	// SampleEvery は sampled=true の記録を確率的に N 件に 1 件だけ残します。1 以下なら間引きません。
+	SampleEvery int
+}
+
+// filterHandler はコンポーネントごとのレベルと間引きを適用して inner に渡す slog.Handler です。
+// WithAttrs で束ねた component / sampled は保持し、記録ごとの属性はトップレベルのもののみ参照します。
+type filterHandler struct {
+	inner  slog.Handler
+	opts   *FilterOptions
+	minLvl slog.Level // Level と ComponentLevels の最小値（Enabled の足切り）
+
+	component string // WithAttrs で束ねた component（空なら記録の属性を参照）
+	sampled   bool   // WithAttrs で sampled=true を束ねたか
+	grouped   bool   // WithGroup 後は記録の属性がグループ内になるため参照しない
+}
+
+// NewFilterHandler は inner をラップし、opts に従って記録を絞り込むハンドラーを返します。
+// inner 自体のレベルは opts の最小レベル以下にしておく必要があります（New 参照）。
+// 記録ごとの追加のアロケーションはありません（BenchmarkFilterHandler 参照）。
+func NewFilterHandler(inner slog.Handler, opts FilterOptions) slog.Handler {
+	return &filterHandler{inner: inner, opts: &opts, minLvl: opts.minLevel()}
+}
+
+// minLevel は Level と ComponentLevels のうち最も低いレベルを返します。
+func (o FilterOptions) minLevel() slog.Level {
+	lvl := o.Level
+	for _, l := range o.ComponentLevels {
+		lvl = min(lvl, l)
+	}
+	return lvl
+}
+
+// Enabled は記録の属性を見る前の足切りです。コンポーネントが束ねられていればそのレベルで判定します。
+func (h *filterHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.component != "" {
+		return level >= h.levelFor(h.component) && h.inner.Enabled(ctx, level)
+	}
+	return level >= h.minLvl && h.inner.Enabled(ctx, level)
+}
+
+// Handle はコンポーネントのレベルと間引きを判定し、残す記録のみ inner に渡します。
+func (h *filterHandler) Handle(ctx context.Context, r slog.Record) error {
+	component, sampled := h.component, h.sampled
+	if !h.grouped && (component == "" || !sampled) {
+		r.Attrs(func(a slog.Attr) bool {
+			switch a.Key {
+			case ComponentKey:
+				if component == "" && a.Value.Kind() == slog.KindString {
+					component = a.Value.String()
+				}
+			case SampledKey:
+				if a.Value.Kind() == slog.KindBool && a.Value.Bool() {
+					sampled = true
+				}
+			}
+			return true
+		})
+	}
+	if r.Level < h.levelFor(component) {
+		return nil
+	}
+	if sampled && r.Level < slog.LevelError && h.opts.SampleEvery > 1 && rand.IntN(h.opts.SampleEvery) != 0 {
+		return nil
+	}
+	return h.inner.Handle(ctx, r)
+}
+
+// levelFor は component の最低レベルを返します。上書きがなければ Level を返します。
+func (h *filterHandler) levelFor(component string) slog.Level {
+	if component != "" {
+		if l, ok := h.opts.ComponentLevels[component]; ok {
+			return l
+		}
+	}
+	return h.opts.Level
+}
+
+// WithAttrs は component / sampled を束ねた場合に記録ごとの判定で使えるよう保持します。
+func (h *filterHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.inner = h.inner.WithAttrs(attrs)
+	if !h.grouped {
+		for _, a := range attrs {
+			switch a.Key {
+			case ComponentKey:
+				if a.Value.Kind() == slog.KindString {
+					clone.component = a.Value.String()
+				}
+			case SampledKey:
+				if a.Value.Kind() == slog.KindBool {
+					clone.sampled = a.Value.Bool()
+				}
+			}
+		}
+	}
+	return &clone
+}
+
+// WithGroup 以降の属性はグループ内に入るため、component / sampled としては扱いません。
+func (h *filterHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.inner = h.inner.WithGroup(name)
+	clone.grouped = true
+	return &clone
+}
@@ -0,0 +1,193 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+)
+
+// captureHandler は受け取った記録を保持するテスト用ハンドラーです（全レベルを受け付けます）。
+type captureHandler struct {
+	mu      *sync.Mutex
+	records *[]slog.Record
+}
+
+func newCaptureHandler() captureHandler {
+	return captureHandler{mu: &sync.Mutex{}, records: &[]slog.Record{}}
+}
+
+func (h captureHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h captureHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	*h.records = append(*h.records, r)
+	return nil
+}
+
+func (h captureHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h captureHandler) WithGroup(string) slog.Handler      { return h }
+
+func (h captureHandler) count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(*h.records)
+}
+
+// TestFilterHandler_ComponentLevels はコンポーネントごとのレベル上書きが既定のレベルより優先されることを検証します。
+func TestFilterHandler_ComponentLevels(t *testing.T) {
+	t.Parallel()
+
+	opts := FilterOptions{
+		Level:           slog.LevelInfo,
+		ComponentLevels: map[string]slog.Level{"cache": slog.LevelWarn, "candles": slog.LevelDebug},
+	}
+	tests := []struct {
+		name string
+		log  func(l *slog.Logger)
+		want bool
+	}{
+		{name: "no component info passes", log: func(l *slog.Logger) { l.Info("m") }, want: true},
+		{name: "no component debug dropped", log: func(l *slog.Logger) { l.Debug("m") }, want: false},
+		{name: "raised component info dropped", log: func(l *slog.Logger) { l.Info("m", ComponentKey, "cache") }, want: false},
+		{name: "raised component warn passes", log: func(l *slog.Logger) { l.Warn("m", ComponentKey, "cache") }, want: true},
+		{name: "lowered component debug passes", log: func(l *slog.Logger) { l.Debug("m", ComponentKey, "candles") }, want: true},
+		{name: "unknown component uses default", log: func(l *slog.Logger) { l.Debug("m", ComponentKey, "auth") }, want: false},
+		{name: "bound component applies", log: func(l *slog.Logger) { l.With(ComponentKey, "cache").Info("m") }, want: false},
+		{name: "bound lowered component debug passes", log: func(l *slog.Logger) { l.With(ComponentKey, "candles").Debug("m") }, want: true},
+		{name: "component inside group is ignored", log: func(l *slog.Logger) { l.WithGroup("g").Info("m", ComponentKey, "cache") }, want: true},
+		{name: "non-string component uses default", log: func(l *slog.Logger) { l.Info("m", ComponentKey, 1) }, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			capture := newCaptureHandler()
+			tt.log(slog.New(NewFilterHandler(capture, opts)))
+			if got := capture.count() == 1; got != tt.want {
+				t.Errorf("passed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFilterHandler_Sampling は sampled=true の記録がおおむね N 件に 1 件残ることを検証します。
+func TestFilterHandler_Sampling(t *testing.T) {
+	t.Parallel()
+
+	const (
+		total = 20000
+		every = 10
+	)
+	tests := []struct {
+		name string
+		log  func(l *slog.Logger)
+	}{
+		{name: "record attr", log: func(l *slog.Logger) { l.Info("request", SampledKey, true) }},
+		{name: "bound attr", log: func(l *slog.Logger) { l.With(SampledKey, true).Info("request") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			capture := newCaptureHandler()
+			l := slog.New(NewFilterHandler(capture, FilterOptions{Level: slog.LevelInfo, SampleEvery: every}))
+			for range total {
+				tt.log(l)
+			}
+			// 期待値 2000 件・標準偏差約 42 件に対して ±15% （7σ 以上）の許容幅
+			want := total / every
+			if got := capture.count(); got < want*85/100 || got > want*115/100 {
+				t.Errorf("kept %d of %d, want about %d", got, total, want)
+			}
+		})
+	}
+}
+
+// TestFilterHandler_SamplingPassThrough は間引きの対象外の記録が全件残ることを検証します。
+func TestFilterHandler_SamplingPassThrough(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		sampleEvery int
+		log         func(l *slog.Logger)
+	}{
+		{name: "error always passes", sampleEvery: 1000, log: func(l *slog.Logger) { l.Error("request", SampledKey, true) }},
+		{name: "unsampled record", sampleEvery: 1000, log: func(l *slog.Logger) { l.Info("request") }},
+		{name: "sampled=false", sampleEvery: 1000, log: func(l *slog.Logger) { l.Info("request", SampledKey, false) }},
+		{name: "sampling disabled", sampleEvery: 1, log: func(l *slog.Logger) { l.Info("request", SampledKey, true) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			capture := newCaptureHandler()
+			l := slog.New(NewFilterHandler(capture, FilterOptions{Level: slog.LevelInfo, SampleEvery: tt.sampleEvery}))
+			for range 100 {
+				tt.log(l)
+			}
+			if got := capture.count(); got != 100 {
+				t.Errorf("kept %d of 100, want all", got)
+			}
+		})
+	}
+}
+
+// TestNew_LowersOutputLevelForOverrides は既定より低いコンポーネント上書きが実際に出力されることを検証します。
+func TestNew_LowersOutputLevelForOverrides(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	l := slog.New(New(&buf, Options{
+		Level:           slog.LevelWarn,
+		UseJSON:         true,
+		ComponentLevels: map[string]slog.Level{"cache": slog.LevelDebug},
+	}))
+	l.Debug("cache miss", ComponentKey, "cache")
+	l.Info("ignored")
+
+	got := decodeLog(t, buf.Bytes())
+	if got["message"] != "cache miss" || got["severity"] != "DEBUG" {
+		t.Errorf("got %v, want the cache debug record only", got)
+	}
+}
+
+// BenchmarkFilterHandler は記録ごとの判定が追加のアロケーションを起こさないことを確認するためのベンチマークです。
+func BenchmarkFilterHandler(b *testing.B) {
+	discard := slog.DiscardHandler
+	opts := FilterOptions{
+		Level:           slog.LevelInfo,
+		ComponentLevels: map[string]slog.Level{"cache": slog.LevelWarn},
+		SampleEvery:     10,
+	}
+	ctx := context.Background()
+
+	b.Run("baseline", func(b *testing.B) {
+		l := slog.New(discard)
+		b.ReportAllocs()
+		for b.Loop() {
+			l.LogAttrs(ctx, slog.LevelInfo, "request", slog.Int("status", 200), slog.Bool(SampledKey, true))
+		}
+	})
+	b.Run("sampled", func(b *testing.B) {
+		l := slog.New(NewFilterHandler(discard, opts))
+		b.ReportAllocs()
+		for b.Loop() {
+			l.LogAttrs(ctx, slog.LevelInfo, "request", slog.Int("status", 200), slog.Bool(SampledKey, true))
+		}
+	})
+	b.Run("component", func(b *testing.B) {
+		l := slog.New(NewFilterHandler(discard, opts))
+		b.ReportAllocs()
+		for b.Loop() {
+			l.LogAttrs(ctx, slog.LevelWarn, "cache error", slog.String(ComponentKey, "cache"))
+		}
+	})
+	b.Run("bound component", func(b *testing.B) {
+		l := slog.New(NewFilterHandler(discard, opts)).With(ComponentKey, "cache")
+		b.ReportAllocs()
+		for b.Loop() {
+			l.LogAttrs(ctx, slog.LevelWarn, "cache error")
+		}
+	})
+}
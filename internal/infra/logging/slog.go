@@ -18,6 +18,27 @@ func NewHandler(w io.Writer, level slog.Level, useJSON bool) slog.Handler {
 	return slog.NewTextHandler(w, &slog.HandlerOptions{Level: level})
 }
 
+// Options は New に渡すロガー構成です。
+type Options struct {
+	Level   slog.Level
+	UseJSON bool
+	// ComponentLevels は component 属性ごとのレベル上書きです（例: "cache" → WARN）。
+	ComponentLevels map[string]slog.Level
+	// SampleEvery は sampled=true の記録を N 件に 1 件だけ残します。1 以下なら間引きません。
+	SampleEvery int
+}
+
+// New は NewHandler に FilterHandler を被せた、エントリポイント共通の slog ハンドラーを生成します。
+// 出力側のレベルはコンポーネント上書きを含めた最小値にし、最終的な判定はフィルターで行います。
+func New(w io.Writer, opts Options) slog.Handler {
+	filter := FilterOptions{
+		Level:           opts.Level,
+		ComponentLevels: opts.ComponentLevels,
+		SampleEvery:     opts.SampleEvery,
+	}
+	return NewFilterHandler(NewHandler(w, filter.minLevel(), opts.UseJSON), filter)
+}
+
 // NewCloudLoggingHandler は Cloud Logging（Cloud Run）が構造化ログとして解釈できる
 // JSON ハンドラーを生成します。slog 既定のキー（level / msg）を Cloud Logging の
 // 特別フィールド（severity / message）にリマップします。time キーは既定のまま
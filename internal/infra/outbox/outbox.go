@@ -0,0 +1,71 @@
+// Package outbox はトランザクショナルアウトボックス（書き込みと同じトランザクションで登録したイベントの配信）を提供します。
+//
+// 書き込みに伴う通知（アラートの評価・キャッシュの破棄等）を書き込みの後にベストエフォートで行うと、
+// 書き込みと通知の間でプロセスが停止した場合に通知が失われます。アウトボックスでは書き込み側が Enqueue で
+// イベントを outbox_events の行として同じトランザクションに登録し、Relay が後から取得して Handler に配信します。
+//
+// 配信は少なくとも 1 回です（処理中に停止した行は Lease の経過後に再配信される）。Handler は同じイベントを
+// 複数回受け取っても結果が変わらないように実装してください。同じ Key のイベントは登録した順に 1 件ずつ配信します。
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/outbox/sqlc"
+)
+
+// ErrPermanent は再試行しても成功しない失敗（解釈できないペイロード等）を示します。
+// Handler がこれをラップしたエラーを返すと、Relay は再試行せずに dead にします。
+var ErrPermanent = errors.New("outbox: permanent failure")
+
+// Message は Enqueue で登録するイベントです。
+type Message struct {
+	// Topic は配信先の Handler を決める名前です（例: candles.upserted）。
+	Topic string
+	// Key は順序を保つ単位です（例: candles:AAPL）。同じ Key のイベントは登録した順に配信します。
+	Key string
+	// Payload は JSON にして保存します。
+	Payload any
+}
+
+// Event は取得済みの配信待ち（1 件のイベント）です。Attempts は今回の試行を含む試行回数です。
+type Event struct {
+	ID       int64
+	Topic    string
+	Key      string
+	Payload  []byte
+	Attempts int
+	// ClaimedBy は取得したリレーの ID です。結果の記録は取得した本人の場合のみ反映します。
+	ClaimedBy string
+}
+
+// Decode は Payload を v にデコードします。失敗した場合は ErrPermanent をラップしたエラーを返します。
+func (e Event) Decode(v any) error {
+	if err := json.Unmarshal(e.Payload, v); err != nil {
+		return fmt.Errorf("%w: decode %s payload: %w", ErrPermanent, e.Topic, err)
+	}
+	return nil
+}
+
+// Handler は 1 件のイベントを処理します。エラーを返すと Relay はバックオフの後に再試行します。
+type Handler func(ctx context.Context, e Event) error
+
+// Enqueue は m を tx の中で配信待ちとして登録します。tx がロールバックされた場合はイベントも登録されません。
+func Enqueue(ctx context.Context, tx *sql.Tx, m Message) error {
+	payload, err := json.Marshal(m.Payload)
+	if err != nil {
+		return fmt.Errorf("marshal %s payload: %w", m.Topic, err)
+	}
+	if err := outboxsqlc.New(tx).InsertEvent(ctx, outboxsqlc.InsertEventParams{
+		Topic:        m.Topic,
+		AggregateKey: m.Key,
+		Payload:      string(payload),
+	}); err != nil {
+		return fmt.Errorf("enqueue %s event: %w", m.Topic, err)
+	}
+	return nil
+}
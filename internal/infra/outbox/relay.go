@@ -0,0 +1,305 @@
+package outbox
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
+)
+
+// Relay の既定値です。
+const (
+	DefaultBatchSize     = 64
+	DefaultPollInterval  = time.Second
+	DefaultMaxAttempts   = 8
+	DefaultBaseBackoff   = 5 * time.Second
+	DefaultMaxBackoff    = 10 * time.Minute
+	DefaultLease         = 2 * time.Minute
+	DefaultHandleTimeout = 30 * time.Second
+	DefaultRetention     = 7 * 24 * time.Hour
+
+	// maxErrorLength は outbox_events.last_error に残すエラー文字列の最大長です。
+	maxErrorLength = 500
+	// recordTimeout は配信結果の記録・取得済みのイベントの手放しにかける時間の上限です。
+	recordTimeout = 5 * time.Second
+)
+
+// Store は配信待ちのイベントの永続化層を抽象化します。repository が実装します。
+// 結果の記録（MarkDone・MarkDead・Retry）は、イベントを取得したリレー（Event.ClaimedBy と試行回数）が一致する場合のみ反映し、
+// 期限切れで他のリレーが取得し直した場合は false を返します。
+type Store interface {
+	// Claim は期限を過ぎた配信待ちを最大 limit 件取得し、試行回数を増やして leaseUntil までは他から取得されないようにします。
+	// 同じ Key に先の配信待ちがあるイベントは取得しません。戻り値は登録順です。
+	Claim(ctx context.Context, limit int, leaseUntil time.Time, relayID string) ([]Event, error)
+	MarkDone(ctx context.Context, e Event) (bool, error)
+	MarkDead(ctx context.Context, e Event, reason string) (bool, error)
+	// Retry はイベントを at に再試行するよう戻します。
+	Retry(ctx context.Context, e Event, at time.Time, reason string) (bool, error)
+	// Release は処理前に手放すイベントを、試行回数を戻してすぐに再取得できるようにします。
+	Release(ctx context.Context, e Event) error
+	// PurgeDone は before より前に配信済みになったイベントを削除します。
+	PurgeDone(ctx context.Context, before time.Time) (int64, error)
+}
+
+// RelayConfig は Relay の設定です。ゼロ値の項目は既定値を使います。
+type RelayConfig struct {
+	// BatchSize は 1 回の取得で取り出すイベントの最大件数です。
+	BatchSize int
+	// PollInterval は配信待ちがないときに次の取得まで待つ時間です。
+	PollInterval time.Duration
+	// MaxAttempts は 1 件のイベントを試行する最大回数です。超えたら dead にします。
+	MaxAttempts int
+	// BaseBackoff・MaxBackoff は再試行までの待ち時間（BaseBackoff × 2^(試行回数-1)、MaxBackoff で頭打ち）です。
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Lease は取得したイベントを他から取得させない時間です。処理中にプロセスが停止した場合は Lease の経過後に再取得されます。
+	// HandleTimeout より長くします（期限内に処理を終えられないイベントは処理せずに手放し、次の取得で取得し直すため）。
+	Lease time.Duration
+	// HandleTimeout は 1 件の処理にかける時間の上限です。
+	HandleTimeout time.Duration
+	// Retention は配信済みのイベントを残す期間です（Purge が削除します）。dead のイベントは削除しません。
+	Retention time.Duration
+}
+
+func (c RelayConfig) withDefaults() RelayConfig {
+	if c.BatchSize <= 0 {
+		c.BatchSize = DefaultBatchSize
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = DefaultPollInterval
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = DefaultMaxAttempts
+	}
+	if c.BaseBackoff <= 0 {
+		c.BaseBackoff = DefaultBaseBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = DefaultMaxBackoff
+	}
+	if c.Lease <= 0 {
+		c.Lease = DefaultLease
+	}
+	if c.HandleTimeout <= 0 {
+		c.HandleTimeout = DefaultHandleTimeout
+	}
+	if c.Lease <= c.HandleTimeout {
+		c.Lease = 2 * c.HandleTimeout
+	}
+	if c.Retention <= 0 {
+		c.Retention = DefaultRetention
+	}
+	return c
+}
+
+// RelayStats は Relay の処理件数の累計です。
+type RelayStats struct {
+	Delivered    uint64 // Handler が成功して配信済みにした件数
+	Retried      uint64 // 失敗して再試行に戻した件数
+	DeadLettered uint64 // 上限まで失敗した・再試行しても成功しない・Handler のないイベントとして dead にした件数
+	LostClaims   uint64 // 処理中に期限が切れ、他のリレーが取得し直していたため結果を記録しなかった件数
+}
+
+// Relay は配信待ちのイベントを取得し、Topic ごとの Handler に配信します。
+//
+// 失敗したイベントは指数バックオフで MaxAttempts まで再試行し、諦めたイベントは dead にしてログに残します。
+// 取得は FOR UPDATE SKIP LOCKED で行うため、複数のインスタンスで起動できます。同じ Key のイベントは
+// 先のイベントが配信済み・dead になるまで取得しないため、Key ごとに登録順で 1 件ずつ処理します。
+type Relay struct {
+	store    Store
+	handlers map[string]Handler
+	cfg      RelayConfig
+	id       string
+	now      func() time.Time
+	// pause が有効な間は取得しません（WithPause で設定。nil なら止めない）
+	pause Pauser
+
+	delivered    atomic.Uint64
+	retried      atomic.Uint64
+	deadLettered atomic.Uint64
+	lostClaims   atomic.Uint64
+}
+
+// NewRelay は Relay を生成します。handlers は Topic ごとの Handler です。配信するには Run を起動する必要があります。
+func NewRelay(store Store, handlers map[string]Handler, cfg RelayConfig) *Relay {
+	return &Relay{
+		store:    store,
+		handlers: handlers,
+		cfg:      cfg.withDefaults(),
+		id:       newRelayID(),
+		now:      time.Now,
+	}
+}
+
+// Pauser は配信を一時的に止めるべきか（メンテナンスモード）を返します。
+type Pauser interface {
+	Active(ctx context.Context) bool
+}
+
+// WithPause は p が有効な間、配信待ちの取得を止めます。イベントは outbox に残り、再開後に登録順で配信します。
+func (r *Relay) WithPause(p Pauser) *Relay {
+	r.pause = p
+	return r
+}
+
+// newRelayID はイベントの取得者として記録するリレーの ID（ホスト名・PID・乱数）を返します。
+func newRelayID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "unknown"
+	}
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	id := fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(b))
+	if len(id) > 64 {
+		id = id[len(id)-64:]
+	}
+	return id
+}
+
+// Stats はこれまでの処理件数を返します。
+func (r *Relay) Stats() RelayStats {
+	return RelayStats{
+		Delivered:    r.delivered.Load(),
+		Retried:      r.retried.Load(),
+		DeadLettered: r.deadLettered.Load(),
+		LostClaims:   r.lostClaims.Load(),
+	}
+}
+
+// Run は ctx が終了するまで配信待ちを取得して配信します。バックグラウンドの goroutine で起動し、
+// ctx の終了後は処理していないイベントを手放し、処理中のイベント（HandleTimeout まで）の完了を待ってから戻ります。
+func (r *Relay) Run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		n := r.relayOnce(ctx)
+		// 上限まで取得できた場合は残りがある可能性が高いため、待たずに次を取得する
+		if n == r.cfg.BatchSize {
+			timer.Reset(0)
+		} else {
+			timer.Reset(r.cfg.PollInterval)
+		}
+	}
+}
+
+// relayOnce は配信待ちを 1 回取得して順に処理し、取得した件数を返します。
+// 処理し終える前に ctx が終了した場合と、Lease の期限内に処理を終えられなくなった場合は、残りを手放します。
+// pause が有効な間は取得せず 0 を返します。
+func (r *Relay) relayOnce(ctx context.Context) int {
+	if r.pause != nil && r.pause.Active(ctx) {
+		return 0
+	}
+	leaseUntil := r.now().Add(r.cfg.Lease)
+	claimed, err := r.store.Claim(ctx, r.cfg.BatchSize, leaseUntil, r.id)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("failed to claim outbox events", "error", err)
+		}
+		return 0
+	}
+	for i, e := range claimed {
+		if ctx.Err() != nil || r.now().Add(r.cfg.HandleTimeout).After(leaseUntil) {
+			r.release(claimed[i:])
+			break
+		}
+		r.deliver(e)
+	}
+	return len(claimed)
+}
+
+// release は処理していないイベントを手放します。停止中に呼ばれるため ctx の終了に影響されません。
+func (r *Relay) release(events []Event) {
+	for _, e := range events {
+		ctx, cancel := context.WithTimeout(context.Background(), recordTimeout)
+		if err := r.store.Release(ctx, e); err != nil {
+			slog.Warn("failed to release outbox event", "error", err, "event_id", e.ID)
+		}
+		cancel()
+	}
+}
+
+// deliver は 1 件を Handler に渡し、結果を記録します。停止中も処理中の 1 件は完了させるため、Run の ctx とは切り離して
+// HandleTimeout を上限に処理します。結果の記録は処理のタイムアウトに巻き込まれないよう別の上限で行います。
+func (r *Relay) deliver(e Event) {
+	var err error
+	h, ok := r.handlers[e.Topic]
+	if ok {
+		hctx, cancel := context.WithTimeout(context.Background(), r.cfg.HandleTimeout)
+		err = h(hctx, e)
+		cancel()
+	} else {
+		err = fmt.Errorf("%w: no handler for topic %q", ErrPermanent, e.Topic)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), recordTimeout)
+	defer cancel()
+	var (
+		recorded bool
+		rerr     error
+	)
+	switch {
+	case err == nil:
+		recorded, rerr = r.store.MarkDone(ctx, e)
+		if recorded {
+			r.delivered.Add(1)
+		}
+
+	case errors.Is(err, ErrPermanent) || e.Attempts >= r.cfg.MaxAttempts:
+		recorded, rerr = r.store.MarkDead(ctx, e, apperr.Truncate(err, maxErrorLength))
+		if recorded {
+			r.deadLettered.Add(1)
+			slog.Error("outbox event dead-lettered", "event_id", e.ID, "topic", e.Topic, "key", e.Key, "attempts", e.Attempts, "error", err)
+		}
+
+	default:
+		recorded, rerr = r.store.Retry(ctx, e, r.now().Add(r.backoff(e.Attempts)), apperr.Truncate(err, maxErrorLength))
+		if recorded {
+			r.retried.Add(1)
+			slog.Warn("outbox event failed, retrying", "event_id", e.ID, "topic", e.Topic, "key", e.Key, "attempts", e.Attempts, "error", err)
+		}
+	}
+	switch {
+	case rerr != nil:
+		// 記録できなかったイベントは Lease の経過後に再配信される
+		slog.Error("failed to record outbox event result", "error", rerr, "event_id", e.ID, "topic", e.Topic)
+	case !recorded:
+		r.lostClaims.Add(1)
+		slog.Warn("outbox event lease expired before its result was recorded", "event_id", e.ID, "topic", e.Topic, "key", e.Key)
+	}
+}
+
+// Purge は保持期間（Retention）を過ぎた配信済みのイベントを削除します。
+// 定期的な実行はスケジューラー（internal/infra/scheduler）に登録して行います。
+func (r *Relay) Purge(ctx context.Context) error {
+	n, err := r.store.PurgeDone(ctx, r.now().Add(-r.cfg.Retention))
+	if err != nil {
+		return fmt.Errorf("purge delivered outbox events: %w", err)
+	}
+	if n > 0 {
+		slog.Info("purged delivered outbox events", "deleted", n)
+	}
+	return nil
+}
+
+// backoff は attempts 回目の試行が失敗した後、次の試行までの待ち時間を返します。
+// BaseBackoff × 2^(attempts-1) を MaxBackoff で頭打ちにします。
+func (r *Relay) backoff(attempts int) time.Duration {
+	wait := r.cfg.BaseBackoff
+	for i := 1; i < attempts && wait < r.cfg.MaxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, r.cfg.MaxBackoff)
+}
@@ -0,0 +1,317 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore は取得させるイベントと、記録された結果を保持するインメモリの Store です。
+// lost に含む ID の結果は、他のリレーが取得し直した場合と同じく記録せずに false を返します。
+type fakeStore struct {
+	mu       sync.Mutex
+	pending  []Event
+	done     []int64
+	dead     map[int64]string
+	retried  map[int64]time.Time
+	released []int64
+	lost     map[int64]bool
+	// purgedBefore は PurgeDone に渡された基準の日時、purgeErr は PurgeDone が返すエラーです。
+	purgedBefore time.Time
+	purgeErr     error
+}
+
+func newFakeStore(events ...Event) *fakeStore {
+	return &fakeStore{
+		pending: events,
+		dead:    map[int64]string{},
+		retried: map[int64]time.Time{},
+		lost:    map[int64]bool{},
+	}
+}
+
+func (s *fakeStore) Claim(_ context.Context, limit int, _ time.Time, relayID string) ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := min(limit, len(s.pending))
+	out := s.pending[:n]
+	s.pending = s.pending[n:]
+	for i := range out {
+		out[i].ClaimedBy = relayID
+	}
+	return out, nil
+}
+
+func (s *fakeStore) MarkDone(_ context.Context, e Event) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lost[e.ID] {
+		return false, nil
+	}
+	s.done = append(s.done, e.ID)
+	return true, nil
+}
+
+func (s *fakeStore) MarkDead(_ context.Context, e Event, reason string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lost[e.ID] {
+		return false, nil
+	}
+	s.dead[e.ID] = reason
+	return true, nil
+}
+
+func (s *fakeStore) Retry(_ context.Context, e Event, at time.Time, _ string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lost[e.ID] {
+		return false, nil
+	}
+	s.retried[e.ID] = at
+	return true, nil
+}
+
+func (s *fakeStore) Release(_ context.Context, e Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.released = append(s.released, e.ID)
+	return nil
+}
+
+func (s *fakeStore) PurgeDone(_ context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.purgedBefore = before
+	return 3, s.purgeErr
+}
+
+// outcomes は結果が記録されたイベントの件数を返します。
+func (s *fakeStore) outcomes() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.done) + len(s.dead) + len(s.retried)
+}
+
+func event(id int64, topic string, attempts int) Event {
+	return Event{ID: id, Topic: topic, Key: fmt.Sprintf("k%d", id), Payload: []byte(`{}`), Attempts: attempts}
+}
+
+// runUntil は r を起動し、done が真になったら停止して Run の終了を待ちます。
+func runUntil(t *testing.T, r *Relay, done func() bool) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		r.Run(ctx)
+	}()
+	require.Eventually(t, done, 2*time.Second, time.Millisecond)
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+}
+
+func TestRelay_Outcomes(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 1, 6, 0, 0, 0, time.UTC)
+	store := newFakeStore(
+		event(1, "ok", 1),
+		event(2, "flaky", 2),     // 一時的な失敗 → 再試行
+		event(3, "flaky", 3),     // 上限回数での一時的な失敗 → dead
+		event(4, "poison", 1),    // ErrPermanent → 再試行せずに dead
+		event(5, "unknown", 1),   // Handler のない Topic → dead
+		event(6, "malformed", 1), // 解釈できないペイロード → dead
+	)
+	store.pending[5].Payload = []byte(`{`)
+	handlers := map[string]Handler{
+		"ok":    func(context.Context, Event) error { return nil },
+		"flaky": func(context.Context, Event) error { return errors.New("redis unavailable") },
+		"poison": func(context.Context, Event) error {
+			return fmt.Errorf("unknown symbol: %w", ErrPermanent)
+		},
+		"malformed": func(_ context.Context, e Event) error {
+			var v struct{}
+			return e.Decode(&v)
+		},
+	}
+	r := NewRelay(store, handlers, RelayConfig{
+		MaxAttempts:  3,
+		BaseBackoff:  time.Minute,
+		MaxBackoff:   time.Hour,
+		PollInterval: time.Millisecond,
+	})
+	r.now = func() time.Time { return now }
+
+	runUntil(t, r, func() bool { return store.outcomes() == 6 })
+
+	assert.Equal(t, []int64{1}, store.done)
+	// 2 回目の失敗は Base × 2 後に再試行する
+	assert.Equal(t, map[int64]time.Time{2: now.Add(2 * time.Minute)}, store.retried)
+	assert.Contains(t, store.dead[3], "redis unavailable")
+	assert.Contains(t, store.dead[4], "unknown symbol")
+	assert.Contains(t, store.dead[5], `no handler for topic "unknown"`)
+	assert.Contains(t, store.dead[6], "decode malformed payload")
+
+	assert.Equal(t, RelayStats{Delivered: 1, Retried: 1, DeadLettered: 4}, r.Stats())
+}
+
+func TestRelay_Backoff(t *testing.T) {
+	t.Parallel()
+	r := NewRelay(nil, nil, RelayConfig{BaseBackoff: 30 * time.Second, MaxBackoff: 10 * time.Minute})
+
+	tests := []struct {
+		name     string
+		attempts int
+		want     time.Duration
+	}{
+		{"1 回目", 1, 30 * time.Second},
+		{"2 回目で倍", 2, time.Minute},
+		{"4 回目", 4, 4 * time.Minute},
+		{"上限で頭打ち", 10, 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, r.backoff(tt.attempts))
+		})
+	}
+}
+
+// TestRelay_Purge は保持期間より前に配信済みになったイベントを削除し、失敗をエラーとして返すことを検証します。
+func TestRelay_Purge(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	store := newFakeStore()
+	r := NewRelay(store, nil, RelayConfig{Retention: 48 * time.Hour})
+	r.now = func() time.Time { return now }
+
+	require.NoError(t, r.Purge(context.Background()))
+	assert.Equal(t, now.Add(-48*time.Hour), store.purgedBefore)
+
+	store.purgeErr = errors.New("db down")
+	assert.ErrorIs(t, r.Purge(context.Background()), store.purgeErr)
+}
+
+func TestRelayConfig_LeaseExceedsHandleTimeout(t *testing.T) {
+	t.Parallel()
+
+	cfg := RelayConfig{Lease: time.Minute, HandleTimeout: 2 * time.Minute}.withDefaults()
+	assert.Equal(t, 4*time.Minute, cfg.Lease)
+	assert.Equal(t, DefaultLease, RelayConfig{}.withDefaults().Lease)
+}
+
+func TestRelay_LostClaimIsNotRecorded(t *testing.T) {
+	t.Parallel()
+
+	store := newFakeStore(event(1, "ok", 1), event(2, "ok", 1))
+	store.lost[1] = true // 処理中に期限が切れ、他のリレーが取得し直した
+	r := NewRelay(store, map[string]Handler{"ok": func(context.Context, Event) error { return nil }},
+		RelayConfig{PollInterval: time.Millisecond})
+
+	runUntil(t, r, func() bool { return r.Stats().Delivered == 1 && r.Stats().LostClaims == 1 })
+
+	assert.Equal(t, []int64{2}, store.done)
+}
+
+func TestRelay_ReleasesEventsBeyondLease(t *testing.T) {
+	t.Parallel()
+
+	// 1 件目の処理で時計が進み、2 件目以降は Lease の期限内に処理を終えられない
+	start := time.Date(2026, 10, 1, 6, 0, 0, 0, time.UTC)
+	var mu sync.Mutex
+	now := start
+	store := newFakeStore(event(1, "slow", 1), event(2, "slow", 1), event(3, "slow", 1))
+	r := NewRelay(store, map[string]Handler{"slow": func(context.Context, Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(100 * time.Second)
+		return nil
+	}}, RelayConfig{Lease: 2 * time.Minute, HandleTimeout: 30 * time.Second})
+	r.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+
+	assert.Equal(t, 3, r.relayOnce(context.Background()))
+	assert.Equal(t, []int64{1}, store.done)
+	assert.Equal(t, []int64{2, 3}, store.released)
+}
+
+// blockingHandler は release が閉じられるまで処理を終えない Handler を返します。処理の開始を started に知らせます。
+func blockingHandler(started chan<- int64, release <-chan struct{}) Handler {
+	return func(_ context.Context, e Event) error {
+		started <- e.ID
+		<-release
+		return nil
+	}
+}
+
+func TestRelay_ShutdownReleasesUnhandledEvents(t *testing.T) {
+	t.Parallel()
+
+	store := newFakeStore(event(1, "block", 1), event(2, "block", 1), event(3, "block", 1))
+	started := make(chan int64, 3)
+	release := make(chan struct{})
+	r := NewRelay(store, map[string]Handler{"block": blockingHandler(started, release)}, RelayConfig{PollInterval: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		r.Run(ctx)
+	}()
+
+	// 1 件目の処理中に停止する
+	assert.Equal(t, int64(1), <-started)
+	cancel()
+
+	// 処理中の 1 件が終わるまで Run は戻らない
+	select {
+	case <-stopped:
+		t.Fatal("Run returned before the in-flight event finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	<-stopped
+
+	assert.Equal(t, []int64{1}, store.done)
+	assert.Equal(t, []int64{2, 3}, store.released)
+}
+
+// pauseSwitch は切り替えられる Pauser です。
+type pauseSwitch struct{ atomic.Bool }
+
+func (p *pauseSwitch) Active(context.Context) bool { return p.Load() }
+
+// TestRelay_Pause は停止中は取得せず（イベントは outbox に残る）、再開後に配信することを検証します。
+func TestRelay_Pause(t *testing.T) {
+	t.Parallel()
+
+	store := newFakeStore(event(1, "ok", 1))
+	pause := &pauseSwitch{}
+	pause.Store(true)
+	r := NewRelay(store, map[string]Handler{
+		"ok": func(context.Context, Event) error { return nil },
+	}, RelayConfig{PollInterval: time.Millisecond}).WithPause(pause)
+
+	assert.Zero(t, r.relayOnce(context.Background()))
+	assert.Len(t, store.pending, 1)
+	assert.Zero(t, store.outcomes())
+
+	pause.Store(false)
+	runUntil(t, r, func() bool { return store.outcomes() == 1 })
+	assert.Equal(t, []int64{1}, store.done)
+}
@@ -0,0 +1,90 @@
+package outbox
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/outbox/sqlc"
+)
+
+// repository は Store の sqlc ベース実装です。
+type repository struct {
+	q *outboxsqlc.Queries
+}
+
+var _ Store = (*repository)(nil)
+
+// NewRepository は指定された *sql.DB で repository の新しいインスタンスを生成します。
+func NewRepository(db *sql.DB) *repository {
+	return &repository{q: outboxsqlc.New(db)}
+}
+
+// Claim は期限を過ぎた配信待ちを最大 limit 件、id の順に取得します。
+// 同じ Key に先の配信待ちがあるイベントは取得しないため、1 回の取得に同じ Key のイベントは含まれません。
+func (r *repository) Claim(ctx context.Context, limit int, leaseUntil time.Time, relayID string) ([]Event, error) {
+	rows, err := r.q.ClaimEvents(ctx, outboxsqlc.ClaimEventsParams{
+		LeaseUntil: leaseUntil,
+		ClaimedBy:  nullString(relayID),
+		MaxEvents:  int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	events := make([]Event, len(rows))
+	for i, row := range rows {
+		events[i] = Event{
+			ID:        row.ID,
+			Topic:     row.Topic,
+			Key:       row.AggregateKey,
+			Payload:   []byte(row.Payload),
+			Attempts:  int(row.Attempts),
+			ClaimedBy: relayID,
+		}
+	}
+	// UPDATE ... RETURNING の順序は保証されないため、登録順に並べ直す
+	slices.SortFunc(events, func(a, b Event) int { return cmp.Compare(a.ID, b.ID) })
+	return events, nil
+}
+
+// MarkDone は e を配信済みにします。取得し直された（期限切れの）イベントは変更せず false を返します。
+func (r *repository) MarkDone(ctx context.Context, e Event) (bool, error) {
+	n, err := r.q.MarkEventDone(ctx, outboxsqlc.MarkEventDoneParams{
+		ID: e.ID, ClaimedBy: nullString(e.ClaimedBy), Attempts: int32(e.Attempts),
+	})
+	return n > 0, err
+}
+
+// MarkDead は e を dead にします。取得し直された（期限切れの）イベントは変更せず false を返します。
+func (r *repository) MarkDead(ctx context.Context, e Event, reason string) (bool, error) {
+	n, err := r.q.MarkEventDead(ctx, outboxsqlc.MarkEventDeadParams{
+		ID: e.ID, ClaimedBy: nullString(e.ClaimedBy), Attempts: int32(e.Attempts), LastError: nullString(reason),
+	})
+	return n > 0, err
+}
+
+// Retry は e を at に再試行するよう戻します。取得し直された（期限切れの）イベントは変更せず false を返します。
+func (r *repository) Retry(ctx context.Context, e Event, at time.Time, reason string) (bool, error) {
+	n, err := r.q.RetryEvent(ctx, outboxsqlc.RetryEventParams{
+		ID: e.ID, ClaimedBy: nullString(e.ClaimedBy), Attempts: int32(e.Attempts), NextAttemptAt: at, LastError: nullString(reason),
+	})
+	return n > 0, err
+}
+
+// Release は処理前に手放す e を、試行回数を戻してすぐに再取得できるようにします。
+func (r *repository) Release(ctx context.Context, e Event) error {
+	return r.q.ReleaseEvent(ctx, outboxsqlc.ReleaseEventParams{
+		ID: e.ID, ClaimedBy: nullString(e.ClaimedBy), Attempts: int32(e.Attempts),
+	})
+}
+
+// PurgeDone は before より前に配信済みになったイベントを削除し、削除した件数を返します。
+func (r *repository) PurgeDone(ctx context.Context, before time.Time) (int64, error) {
+	return r.q.DeleteDoneEvents(ctx, sql.NullTime{Time: before, Valid: true})
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,243 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/db/dbtest"
+)
+
+func TestMain(m *testing.M) {
+	code, err := dbtest.RunMainWithPostgres(m)
+	if err != nil {
+		log.Fatalf("dbtest setup: %v", err)
+	}
+	os.Exit(code)
+}
+
+// enqueue は msgs を 1 つのトランザクションで登録してコミットします。
+func enqueue(t *testing.T, db *sql.DB, msgs ...Message) {
+	t.Helper()
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	for _, m := range msgs {
+		require.NoError(t, Enqueue(ctx, tx, m))
+	}
+	require.NoError(t, tx.Commit())
+}
+
+func eventStatus(t *testing.T, db *sql.DB, id int64) (status string, attempts int) {
+	t.Helper()
+	require.NoError(t, db.QueryRowContext(context.Background(),
+		`SELECT status, attempts FROM outbox_events WHERE id = $1`, id).Scan(&status, &attempts))
+	return status, attempts
+}
+
+func TestRepository_EnqueueFollowsTransaction(t *testing.T) {
+	t.Parallel()
+	db := dbtest.OpenIsolatedDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	// ロールバックした書き込みのイベントは登録されない
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, Enqueue(ctx, tx, Message{Topic: "t", Key: "k", Payload: map[string]string{"v": "rolled back"}}))
+	require.NoError(t, tx.Rollback())
+
+	enqueue(t, db, Message{Topic: "t", Key: "k", Payload: map[string]string{"v": "committed"}})
+
+	claimed, err := repo.Claim(ctx, 10, time.Now().Add(time.Minute), "relay-a")
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, "t", claimed[0].Topic)
+	assert.Equal(t, "k", claimed[0].Key)
+	assert.Equal(t, 1, claimed[0].Attempts)
+	assert.Equal(t, "relay-a", claimed[0].ClaimedBy)
+	var payload map[string]string
+	require.NoError(t, claimed[0].Decode(&payload))
+	assert.Equal(t, "committed", payload["v"])
+}
+
+func TestRepository_OrderingPerKey(t *testing.T) {
+	t.Parallel()
+	db := dbtest.OpenIsolatedDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	enqueue(t, db,
+		Message{Topic: "t", Key: "candles:AAPL", Payload: 1},
+		Message{Topic: "t", Key: "candles:AAPL", Payload: 2},
+		Message{Topic: "t", Key: "candles:MSFT", Payload: 3},
+		Message{Topic: "t", Key: "candles:AAPL", Payload: 4},
+	)
+	lease := time.Now().Add(time.Minute)
+
+	// 同じ Key は先頭の 1 件だけを取得する
+	first, err := repo.Claim(ctx, 10, lease, "relay-a")
+	require.NoError(t, err)
+	require.Len(t, first, 2)
+	assert.Equal(t, []string{"candles:AAPL", "candles:MSFT"}, []string{first[0].Key, first[1].Key})
+
+	// 処理中（取得済み）の間は、他のリレーも同じ Key の後続を取得しない
+	other, err := repo.Claim(ctx, 10, lease, "relay-b")
+	require.NoError(t, err)
+	assert.Empty(t, other)
+
+	// 再試行待ちの間も後続は取得しない
+	ok, err := repo.Retry(ctx, first[0], time.Now().Add(time.Hour), "boom")
+	require.NoError(t, err)
+	require.True(t, ok)
+	other, err = repo.Claim(ctx, 10, lease, "relay-b")
+	require.NoError(t, err)
+	assert.Empty(t, other)
+
+	// dead にすると後続に進む（1 件の失敗で Key 全体を止めない）
+	ok, err = repo.MarkDead(ctx, first[0], "boom")
+	require.NoError(t, err)
+	require.True(t, ok)
+	second, err := repo.Claim(ctx, 10, lease, "relay-b")
+	require.NoError(t, err)
+	require.Len(t, second, 1)
+	assert.Greater(t, second[0].ID, first[0].ID)
+
+	ok, err = repo.MarkDone(ctx, second[0])
+	require.NoError(t, err)
+	require.True(t, ok)
+	third, err := repo.Claim(ctx, 10, lease, "relay-b")
+	require.NoError(t, err)
+	require.Len(t, third, 1)
+	assert.Greater(t, third[0].ID, second[0].ID)
+}
+
+func TestRepository_RedeliveryAfterRelayRestart(t *testing.T) {
+	t.Parallel()
+	db := dbtest.OpenIsolatedDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	enqueue(t, db, Message{Topic: "t", Key: "k", Payload: 1})
+
+	// relay-a が取得した後に停止した（期限切れを再現するため過去の期限で取得する）
+	crashed, err := repo.Claim(ctx, 10, time.Now().Add(-time.Second), "relay-a")
+	require.NoError(t, err)
+	require.Len(t, crashed, 1)
+
+	// 期限が切れると再起動した（または他の）リレーが取得し直す
+	redelivered, err := repo.Claim(ctx, 10, time.Now().Add(time.Minute), "relay-b")
+	require.NoError(t, err)
+	require.Len(t, redelivered, 1)
+	assert.Equal(t, crashed[0].ID, redelivered[0].ID)
+	assert.Equal(t, 2, redelivered[0].Attempts)
+
+	// 遅れて完了した relay-a の結果は反映せず、取得し直した relay-b の結果だけを記録する
+	ok, err := repo.MarkDone(ctx, crashed[0])
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = repo.MarkDone(ctx, redelivered[0])
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	status, attempts := eventStatus(t, db, crashed[0].ID)
+	assert.Equal(t, "done", status)
+	assert.Equal(t, 2, attempts)
+	again, err := repo.Claim(ctx, 10, time.Now().Add(time.Minute), "relay-b")
+	require.NoError(t, err)
+	assert.Empty(t, again)
+}
+
+func TestRepository_ReleaseRestoresAttempts(t *testing.T) {
+	t.Parallel()
+	db := dbtest.OpenIsolatedDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	enqueue(t, db, Message{Topic: "t", Key: "k", Payload: 1})
+	claimed, err := repo.Claim(ctx, 10, time.Now().Add(time.Hour), "relay-a")
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+
+	require.NoError(t, repo.Release(ctx, claimed[0]))
+
+	// 期限を待たずに同じ試行回数で取得し直せる
+	again, err := repo.Claim(ctx, 10, time.Now().Add(time.Hour), "relay-b")
+	require.NoError(t, err)
+	require.Len(t, again, 1)
+	assert.Equal(t, 1, again[0].Attempts)
+}
+
+func TestRepository_RelayDeadLettersAfterMaxAttempts(t *testing.T) {
+	t.Parallel()
+	db := dbtest.OpenIsolatedDB(t)
+	ctx := context.Background()
+
+	enqueue(t, db,
+		Message{Topic: "fail", Key: "k", Payload: 1},
+		Message{Topic: "ok", Key: "k", Payload: 2},
+	)
+	var mu sync.Mutex
+	var delivered []int64
+	r := NewRelay(NewRepository(db), map[string]Handler{
+		"fail": func(context.Context, Event) error { return errors.New("unavailable") },
+		"ok": func(_ context.Context, e Event) error {
+			mu.Lock()
+			defer mu.Unlock()
+			delivered = append(delivered, e.ID)
+			return nil
+		},
+	}, RelayConfig{MaxAttempts: 3, BaseBackoff: time.Millisecond, MaxBackoff: time.Millisecond, PollInterval: time.Millisecond})
+
+	runUntil(t, r, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(delivered) == 1
+	})
+
+	// 失敗し続けたイベントは上限の試行で dead になり、同じ Key の後続はその後に配信される
+	var deadID int64
+	var lastError string
+	require.NoError(t, db.QueryRowContext(ctx,
+		`SELECT id, last_error FROM outbox_events WHERE topic = 'fail'`).Scan(&deadID, &lastError))
+	status, attempts := eventStatus(t, db, deadID)
+	assert.Equal(t, "dead", status)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, "unavailable", lastError)
+	assert.Equal(t, RelayStats{Delivered: 1, Retried: 2, DeadLettered: 1}, r.Stats())
+}
+
+func TestRepository_PurgeDone(t *testing.T) {
+	t.Parallel()
+	db := dbtest.OpenIsolatedDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	enqueue(t, db, Message{Topic: "t", Key: "a", Payload: 1}, Message{Topic: "t", Key: "b", Payload: 2})
+	claimed, err := repo.Claim(ctx, 10, time.Now().Add(time.Minute), "relay-a")
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	_, err = repo.MarkDone(ctx, claimed[0])
+	require.NoError(t, err)
+	_, err = repo.MarkDead(ctx, claimed[1], "boom")
+	require.NoError(t, err)
+
+	n, err := repo.PurgeDone(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, n, "retention not yet elapsed")
+
+	// dead のイベントは調査のため残す
+	n, err = repo.PurgeDone(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	status, _ := eventStatus(t, db, claimed[1].ID)
+	assert.Equal(t, "dead", status)
+}
@@ -0,0 +1,58 @@
+-- name: InsertEvent :exec
+INSERT INTO outbox_events (topic, aggregate_key, payload)
+VALUES ($1, $2, $3);
+
+-- name: ClaimEvents :many
+-- 期限を過ぎた配信待ちを最大 max_events 件取得し、attempts を増やして next_attempt_at を lease_until まで進める。
+-- 同じ aggregate_key に先の配信待ち（処理中・再試行待ちを含む）がある行は取得しない（キーごとの順序を保つため）。
+-- SKIP LOCKED で他のリレーが取得中の行は飛ばす。
+WITH due AS (
+    SELECT e.id
+    FROM outbox_events e
+    WHERE e.status = 'pending'
+      AND e.next_attempt_at <= now()
+      AND NOT EXISTS (
+          SELECT 1 FROM outbox_events p
+          WHERE p.aggregate_key = e.aggregate_key
+            AND p.status = 'pending'
+            AND p.id < e.id
+      )
+    ORDER BY e.id
+    LIMIT sqlc.arg(max_events)
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE outbox_events e
+SET attempts = e.attempts + 1,
+    next_attempt_at = sqlc.arg(lease_until),
+    claimed_by = sqlc.arg(claimed_by)
+FROM due
+WHERE e.id = due.id
+RETURNING e.id, e.topic, e.aggregate_key, e.payload, e.attempts;
+
+-- name: MarkEventDone :execrows
+-- 取得した本人（claimed_by・attempts が一致）の場合のみ配信済みにする。期限切れで他のリレーが取得し直した行は変更しない。
+UPDATE outbox_events
+SET status = 'done', last_error = NULL, completed_at = now()
+WHERE id = $1 AND status = 'pending' AND claimed_by = $2 AND attempts = $3;
+
+-- name: MarkEventDead :execrows
+UPDATE outbox_events
+SET status = 'dead', last_error = $4, completed_at = now()
+WHERE id = $1 AND status = 'pending' AND claimed_by = $2 AND attempts = $3;
+
+-- name: RetryEvent :execrows
+UPDATE outbox_events
+SET next_attempt_at = $4, last_error = $5
+WHERE id = $1 AND status = 'pending' AND claimed_by = $2 AND attempts = $3;
+
+-- name: ReleaseEvent :exec
+-- 処理前に停止した取得済みの行を、試行回数を戻してすぐに再取得できるようにする。
+UPDATE outbox_events
+SET attempts = GREATEST(attempts - 1, 0), next_attempt_at = now()
+WHERE id = $1 AND status = 'pending' AND claimed_by = $2 AND attempts = $3;
+
+-- name: DeleteDoneEvents :execrows
+-- 保持期間を過ぎた配信済みの行を削除する。dead の行は調査のため残す。
+DELETE FROM outbox_events
+WHERE status = 'done' AND completed_at < $1;
+
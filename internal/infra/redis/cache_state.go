@@ -0,0 +1,144 @@
+package redis
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// CacheState のヘルスチェックの既定値です。
+const (
+	DefaultHealthInterval    = 15 * time.Second // 正常時の PING 間隔
+	DefaultHealthMaxBackoff  = 2 * time.Minute  // 障害中の PING 間隔の上限（失敗ごとに倍にする）
+	DefaultHealthPingTimeout = 2 * time.Second  // PING 1 回の上限時間
+	DefaultFailThreshold     = 2                // 無効化までの連続失敗回数
+	DefaultRecoverThreshold  = 2                // 再有効化までの連続成功回数
+)
+
+// CacheStateOptions は CacheState のヘルスチェック設定です。ゼロ値の項目は既定値を使います。
+type CacheStateOptions struct {
+	Interval         time.Duration
+	MaxBackoff       time.Duration
+	PingTimeout      time.Duration
+	FailThreshold    int
+	RecoverThreshold int
+}
+
+// CacheState は Redis の死活を監視し、正常な間だけクライアントを払い出します。
+//
+// 起動時に Redis へ接続できなくてもクライアントは保持しておき、Run のバックグラウンド監視が
+// 復旧を検知した時点で Client が非 nil を返すようになります（再起動は不要）。
+// 有効・無効の切り替えは連続成功・連続失敗の回数でヒステリシスを持たせ、一時的な瞬断で揺れないようにします。
+// Client はフラグを読むだけで、リクエストがヘルスチェックを待つことはありません。
+type CacheState struct {
+	rdb     *redis.Client
+	opts    CacheStateOptions
+	enabled atomic.Bool
+
+	// 以下は監視ゴルーチン（check）のみが更新する
+	successes int
+	failures  int
+}
+
+// NewCacheState は rdb を監視する CacheState を生成し、初回の PING で初期状態を決めます。
+// 初回はヒステリシスを適用せず、成功すれば有効、失敗すれば無効で開始します。
+// rdb が nil の場合は常に無効です（Run は何もしません）。
+func NewCacheState(ctx context.Context, rdb *redis.Client, opts CacheStateOptions) *CacheState {
+	s := &CacheState{rdb: rdb, opts: opts.withDefaults()}
+	if rdb == nil {
+		return s
+	}
+	if err := s.ping(ctx); err != nil {
+		slog.Warn("Redis unavailable, running without cache until it recovers", "component", "cache", "error", err)
+		return s
+	}
+	s.enabled.Store(true)
+	return s
+}
+
+// Client は Redis が正常であればクライアントを、無効（未接続・障害中）であれば nil を返します。
+// 利用側は nil を従来の「Redis なし」と同じ経路で扱います。nil レシーバーでも nil を返します。
+func (s *CacheState) Client() *redis.Client {
+	if s == nil || !s.enabled.Load() {
+		return nil
+	}
+	return s.rdb
+}
+
+// Enabled は Redis を利用できる状態かを返します（readiness の表示用）。
+func (s *CacheState) Enabled() bool {
+	return s != nil && s.enabled.Load()
+}
+
+// Run は ctx が終了するまで Redis を定期的に PING し、状態を切り替えます。
+// 障害中は間隔を倍にして MaxBackoff まで延ばし、復旧後は Interval に戻します。
+func (s *CacheState) Run(ctx context.Context) {
+	if s == nil || s.rdb == nil {
+		return
+	}
+	delay := s.opts.Interval
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		if s.check(ctx) {
+			delay = s.opts.Interval
+		} else {
+			delay = min(delay*2, s.opts.MaxBackoff)
+		}
+		timer.Reset(delay)
+	}
+}
+
+// check は PING を 1 回行って状態を更新し、PING が成功したかを返します。
+// 連続失敗が FailThreshold に達すると無効に、連続成功が RecoverThreshold に達すると有効に切り替えます。
+func (s *CacheState) check(ctx context.Context) bool {
+	err := s.ping(ctx)
+	if err != nil {
+		s.successes = 0
+		s.failures++
+		if s.failures >= s.opts.FailThreshold && s.enabled.CompareAndSwap(true, false) {
+			slog.Warn("Redis health check failed, cache disabled", "component", "cache", "failures", s.failures, "error", err)
+		}
+		return false
+	}
+	s.failures = 0
+	s.successes++
+	if s.successes >= s.opts.RecoverThreshold && s.enabled.CompareAndSwap(false, true) {
+		slog.Info("Redis recovered, cache re-enabled", "component", "cache", "successes", s.successes)
+	}
+	return true
+}
+
+// ping は PingTimeout を上限に PING を送ります。
+func (s *CacheState) ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, s.opts.PingTimeout)
+	defer cancel()
+	return s.rdb.Ping(ctx).Err()
+}
+
+func (o CacheStateOptions) withDefaults() CacheStateOptions {
+	if o.Interval <= 0 {
+		o.Interval = DefaultHealthInterval
+	}
+	if o.MaxBackoff < o.Interval {
+		o.MaxBackoff = max(DefaultHealthMaxBackoff, o.Interval)
+	}
+	if o.PingTimeout <= 0 {
+		o.PingTimeout = DefaultHealthPingTimeout
+	}
+	if o.FailThreshold <= 0 {
+		o.FailThreshold = DefaultFailThreshold
+	}
+	if o.RecoverThreshold <= 0 {
+		o.RecoverThreshold = DefaultRecoverThreshold
+	}
+	return o
+}
@@ -0,0 +1,134 @@
+package redis
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestClient(t *testing.T, addr string) *redis.Client {
+	t.Helper()
+	rdb := redis.NewClient(&redis.Options{Addr: addr, MaxRetries: -1, DialTimeout: 200 * time.Millisecond})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return rdb
+}
+
+// TestCacheState_FlipsWithHysteresis は miniredis の停止・再開で無効化・再有効化が
+// 連続失敗・連続成功のしきい値に達した時点で切り替わることを検証します。
+func TestCacheState_FlipsWithHysteresis(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := newTestClient(t, mr.Addr())
+	ctx := context.Background()
+
+	s := NewCacheState(ctx, rdb, CacheStateOptions{FailThreshold: 2, RecoverThreshold: 2, PingTimeout: 500 * time.Millisecond})
+	if s.Client() != rdb || !s.Enabled() {
+		t.Fatal("reachable Redis at startup should start enabled")
+	}
+
+	mr.Close()
+	s.check(ctx)
+	if s.Client() == nil {
+		t.Fatal("a single failure must not disable the cache")
+	}
+	s.check(ctx)
+	if s.Client() != nil || s.Enabled() {
+		t.Fatal("cache should be disabled after consecutive failures")
+	}
+
+	if err := mr.Restart(); err != nil {
+		t.Fatalf("restart miniredis: %v", err)
+	}
+	s.check(ctx)
+	if s.Client() != nil {
+		t.Fatal("a single success must not re-enable the cache")
+	}
+	s.check(ctx)
+	if s.Client() != rdb || !s.Enabled() {
+		t.Fatal("cache should be re-enabled after consecutive successes")
+	}
+}
+
+// TestCacheState_StartsDisabledAndRecovers は起動時に Redis が停止していても、
+// Run の監視で復旧を検知してクライアントを払い出すことを検証します。
+func TestCacheState_StartsDisabledAndRecovers(t *testing.T) {
+	mr := miniredis.RunT(t)
+	addr := mr.Addr()
+	mr.Close()
+	rdb := newTestClient(t, addr)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := NewCacheState(ctx, rdb, CacheStateOptions{Interval: 10 * time.Millisecond, MaxBackoff: 20 * time.Millisecond, RecoverThreshold: 1})
+	if s.Client() != nil {
+		t.Fatal("unreachable Redis at startup should start disabled")
+	}
+	go s.Run(ctx)
+
+	if err := mr.StartAddr(addr); err != nil {
+		t.Fatalf("start miniredis: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for s.Client() == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("cache was not re-enabled after Redis came back")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestCacheState_ClientDoesNotBlockOnHealthCheck は PING が応答しない間も Client が即座に返ることを検証します。
+func TestCacheState_ClientDoesNotBlockOnHealthCheck(t *testing.T) {
+	// 接続は受け付けるが応答しないサーバー
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { _ = conn.Close() })
+		}
+	}()
+
+	rdb := newTestClient(t, ln.Addr().String())
+	s := &CacheState{rdb: rdb, opts: CacheStateOptions{PingTimeout: time.Second}.withDefaults()}
+	s.enabled.Store(true)
+
+	done := make(chan struct{})
+	go func() {
+		s.check(context.Background()) // PingTimeout まで待たされる
+		close(done)
+	}()
+
+	start := time.Now()
+	for range 1000 {
+		_ = s.Client()
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Client blocked for %v while a health check was in flight", elapsed)
+	}
+	<-done
+}
+
+func TestCacheState_NilClient(t *testing.T) {
+	t.Parallel()
+
+	s := NewCacheState(context.Background(), nil, CacheStateOptions{})
+	if s.Client() != nil || s.Enabled() {
+		t.Error("nil client should always be disabled")
+	}
+	s.Run(context.Background()) // 即座に戻る
+
+	var nilState *CacheState
+	if nilState.Client() != nil {
+		t.Error("nil CacheState should return nil client")
+	}
+}
@@ -27,18 +27,23 @@ func (Password) MarshalJSON() ([]byte, error) { return []byte(`"***"`), nil }
 // LogValue は slog による構造化ログ出力時にパスワードをマスクします。
 func (Password) LogValue() slog.Value { return slog.StringValue("***") }
 
+// NewClient は接続を検証せずに Redis クライアントを作成します。
+// 起動時に Redis が停止していても後から復旧を検知できるよう、CacheState と組み合わせて使います。
+func NewClient(host, port, password string) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     net.JoinHostPort(host, port),
+		Password: password,
+		DB:       0,
+	})
+}
+
 // NewRedisClient は渡された接続情報で新しいRedisクライアントを作成します。
 // 返却前にPINGコマンドで接続を検証します。
 // 設定の読み込み（環境変数 REDIS_HOST / REDIS_PORT / REDIS_PASSWORD）は
 // internal/app/config に集約されています。password は空文字を許容します。
 func NewRedisClient(host, port, password string) (*redis.Client, error) {
-	addr := net.JoinHostPort(host, port)
-
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     addr,
-		Password: password,
-		DB:       0,
-	})
+	rdb := NewClient(host, port, password)
+	addr := rdb.Options().Addr
 
 	// 接続を検証
 	if err := rdb.Ping(context.Background()).Err(); err != nil {
@@ -0,0 +1,46 @@
+package redis
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// namespacePattern は名前空間に使える文字です。区切り文字 ":" を含められないようにし、
+// 名前空間同士が前方一致で衝突しないことを保証します。
+var namespacePattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// KeyBuilder は環境ごとの名前空間を Redis キーの先頭に付与します。
+// staging と production のように同じ Redis を共有する環境間でキーが衝突しないよう、
+// Redis にキーを書き込む全てのコンポーネントはこのヘルパーでキーを組み立てます。
+// ゼロ値は名前空間なし（従来どおりのキー）として動作します。
+type KeyBuilder struct {
+	namespace string
+}
+
+// NewKeyBuilder は namespace を検証して KeyBuilder を生成します。
+// 空文字は名前空間なしとして許容し、それ以外は英小文字・数字・"_"・"-" のみ受け付けます。
+func NewKeyBuilder(namespace string) (KeyBuilder, error) {
+	if namespace != "" && !namespacePattern.MatchString(namespace) {
+		return KeyBuilder{}, fmt.Errorf("invalid cache namespace %q: must match %s", namespace, namespacePattern)
+	}
+	return KeyBuilder{namespace: namespace}, nil
+}
+
+// Namespace は名前空間を返します。名前空間なしの場合は空文字です。
+func (b KeyBuilder) Namespace() string {
+	return b.namespace
+}
+
+// Key は parts を ":" で連結し、名前空間を先頭に付与したキーを返します。
+// 例: 名前空間 "staging" で Key("candles", "AAPL") → "staging:candles:AAPL"
+func (b KeyBuilder) Key(parts ...string) string {
+	key := strings.Join(parts, ":")
+	if b.namespace == "" {
+		return key
+	}
+	if key == "" {
+		return b.namespace
+	}
+	return b.namespace + ":" + key
+}
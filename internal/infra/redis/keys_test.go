@@ -0,0 +1,87 @@
+package redis
+
+import "testing"
+
+func TestNewKeyBuilder(t *testing.T) {
+	testCases := []struct {
+		namespace string
+		wantErr   bool
+	}{
+		{namespace: ""},
+		{namespace: "production"},
+		{namespace: "staging-2"},
+		{namespace: "pr_123"},
+		{namespace: "Production", wantErr: true},
+		{namespace: "staging:candles", wantErr: true},
+		{namespace: "has space", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.namespace, func(t *testing.T) {
+			_, err := NewKeyBuilder(tc.namespace)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("NewKeyBuilder(%q) err=%v, wantErr=%v", tc.namespace, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeyBuilder_Key(t *testing.T) {
+	staging, _ := NewKeyBuilder("staging")
+
+	testCases := []struct {
+		name string
+		b    KeyBuilder
+		in   []string
+		want string
+	}{
+		{name: "no namespace", b: KeyBuilder{}, in: []string{"candles", "AAPL", "1day"}, want: "candles:AAPL:1day"},
+		{name: "with namespace", b: staging, in: []string{"candles", "AAPL", "1day"}, want: "staging:candles:AAPL:1day"},
+		{name: "single part", b: staging, in: []string{"rl:login:ip"}, want: "staging:rl:login:ip"},
+		{name: "no parts", b: staging, in: nil, want: "staging"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.b.Key(tc.in...); got != tc.want {
+				t.Errorf("Key(%v)=%q, want %q", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestKeyBuilder_NamespacesNeverIntersect は異なる名前空間で同じ論理キーを組み立てても、
+// 生成されるキー（およびその前方一致範囲）が重ならないことを検証します。
+func TestKeyBuilder_NamespacesNeverIntersect(t *testing.T) {
+	staging, _ := NewKeyBuilder("staging")
+	production, _ := NewKeyBuilder("production")
+	prod2, _ := NewKeyBuilder("production-2")
+
+	logical := [][]string{
+		{"candles", "AAPL", "1day"},
+		{"candles", "7203.T", "1week"},
+		{"oauth", "state", "abc"},
+		{"rl:login:ip:192.0.2.1"},
+		{"rl:apikey:svc"},
+	}
+
+	builders := []KeyBuilder{staging, production, prod2}
+	seen := map[string]string{}
+	for _, b := range builders {
+		for _, parts := range logical {
+			key := b.Key(parts...)
+			if owner, dup := seen[key]; dup {
+				t.Fatalf("key %q produced by both %q and %q", key, owner, b.Namespace())
+			}
+			seen[key] = b.Namespace()
+
+			// 他の名前空間の SCAN パターン（"<ns>:*"）に一致しないこと
+			for _, other := range builders {
+				if other.Namespace() == b.Namespace() {
+					continue
+				}
+				if prefix := other.Namespace() + ":"; len(key) >= len(prefix) && key[:len(prefix)] == prefix {
+					t.Errorf("key %q of %q falls under namespace %q", key, b.Namespace(), other.Namespace())
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// unlockScript は保持者のトークンが一致する場合のみロックを削除します（期限切れ後に他が取得したロックを消さないため）。
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Locker は Redis の SET NX PX による分散ロックです。複数のインスタンスのうち 1 つだけが処理を実行するために使います。
+// ロックは ttl の経過で自動的に解放されるため、保持したままプロセスが停止しても次の取得を妨げません。
+type Locker struct {
+	rdb    *redis.Client
+	prefix string
+}
+
+// NewLocker は prefix（例: KeyBuilder.Key("lock")）の下にロックのキーを作る Locker を生成します。
+func NewLocker(rdb *redis.Client, prefix string) *Locker {
+	return &Locker{rdb: rdb, prefix: prefix}
+}
+
+// TryLock は name のロックを ttl の間取得します。他が保持している場合は待たずに ok = false を返します。
+// 返す unlock は取得したロックがまだ自分のものである場合のみ解放します。
+func (l *Locker) TryLock(ctx context.Context, name string, ttl time.Duration) (unlock func(context.Context) error, ok bool, err error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, false, fmt.Errorf("generate lock token: %w", err)
+	}
+	key, token := l.prefix+":"+name, hex.EncodeToString(b)
+
+	ok, err = l.rdb.SetNX(ctx, key, token, ttl).Result()
+	if err != nil {
+		return nil, false, fmt.Errorf("acquire lock %q: %w", name, err)
+	}
+	if !ok {
+		return nil, false, nil
+	}
+	return func(ctx context.Context) error {
+		if err := unlockScript.Run(ctx, l.rdb, []string{key}, token).Err(); err != nil {
+			return fmt.Errorf("release lock %q: %w", name, err)
+		}
+		return nil
+	}, true, nil
+}
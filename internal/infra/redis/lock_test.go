@@ -0,0 +1,71 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// TestLocker_TryLock は保持中のロックを他が取得できず、解放・期限切れの後は取得できることを検証します。
+func TestLocker_TryLock(t *testing.T) {
+	mr := miniredis.RunT(t)
+	l := NewLocker(newTestClient(t, mr.Addr()), "lock")
+	ctx := context.Background()
+
+	unlock, ok, err := l.TryLock(ctx, "job", time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("first TryLock = %v, %v; want acquired", ok, err)
+	}
+	if !mr.Exists("lock:job") {
+		t.Fatal("lock key lock:job should exist")
+	}
+	if _, ok, err := l.TryLock(ctx, "job", time.Minute); err != nil || ok {
+		t.Fatalf("second TryLock = %v, %v; want not acquired", ok, err)
+	}
+	if _, ok, err := l.TryLock(ctx, "other", time.Minute); err != nil || !ok {
+		t.Fatalf("TryLock(other) = %v, %v; want acquired", ok, err)
+	}
+
+	if err := unlock(ctx); err != nil {
+		t.Fatalf("unlock: %v", err)
+	}
+	if _, ok, err := l.TryLock(ctx, "job", time.Minute); err != nil || !ok {
+		t.Fatalf("TryLock after unlock = %v, %v; want acquired", ok, err)
+	}
+}
+
+// TestLocker_UnlockAfterExpiry は期限切れ後に他が取得したロックを、元の保持者の unlock で消さないことを検証します。
+func TestLocker_UnlockAfterExpiry(t *testing.T) {
+	mr := miniredis.RunT(t)
+	l := NewLocker(newTestClient(t, mr.Addr()), "lock")
+	ctx := context.Background()
+
+	stale, ok, err := l.TryLock(ctx, "job", time.Second)
+	if err != nil || !ok {
+		t.Fatalf("TryLock = %v, %v; want acquired", ok, err)
+	}
+	mr.FastForward(2 * time.Second)
+	if _, ok, err := l.TryLock(ctx, "job", time.Minute); err != nil || !ok {
+		t.Fatalf("TryLock after expiry = %v, %v; want acquired", ok, err)
+	}
+
+	if err := stale(ctx); err != nil {
+		t.Fatalf("stale unlock: %v", err)
+	}
+	if _, ok, _ := l.TryLock(ctx, "job", time.Minute); ok {
+		t.Fatal("stale unlock must not release the lock held by the new owner")
+	}
+}
+
+// TestLocker_Unavailable は Redis に接続できない場合にエラーを返し、ロックを取得したことにしないことを検証します。
+func TestLocker_Unavailable(t *testing.T) {
+	mr := miniredis.RunT(t)
+	l := NewLocker(newTestClient(t, mr.Addr()), "lock")
+	mr.Close()
+
+	if _, ok, err := l.TryLock(context.Background(), "job", time.Minute); err == nil || ok {
+		t.Fatalf("TryLock = %v, %v; want error", ok, err)
+	}
+}
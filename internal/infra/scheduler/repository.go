@@ -0,0 +1,123 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/scheduler/sqlc"
+)
+
+// repository は Store の sqlc ベース実装です。
+type repository struct {
+	q *schedulersqlc.Queries
+}
+
+var _ Store = (*repository)(nil)
+
+// NewRepository は指定された *sql.DB で repository の新しいインスタンスを生成します。
+func NewRepository(db *sql.DB) *repository {
+	return &repository{q: schedulersqlc.New(db)}
+}
+
+// Ensure は name が未登録の場合のみ next を次の予定として登録します。
+func (r *repository) Ensure(ctx context.Context, name string, next time.Time) error {
+	return r.q.EnsureJob(ctx, schedulersqlc.EnsureJobParams{Name: name, NextRunAt: next})
+}
+
+// List は登録済みの全ジョブを名前順に返します。
+func (r *repository) List(ctx context.Context) ([]State, error) {
+	rows, err := r.q.ListJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	states := make([]State, len(rows))
+	for i, row := range rows {
+		states[i] = toState(schedulersqlc.GetJobRow(row))
+	}
+	return states, nil
+}
+
+// Get は name のジョブを返します。未登録の場合は ErrUnknownJob を返します。
+func (r *repository) Get(ctx context.Context, name string) (State, error) {
+	row, err := r.q.GetJob(ctx, name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return State{}, ErrUnknownJob
+		}
+		return State{}, err
+	}
+	return toState(row), nil
+}
+
+// Start は expected の予定から変わっていない場合のみ次の予定を next に進め、開始日時を記録します。
+func (r *repository) Start(ctx context.Context, expected State, next, startedAt time.Time) (bool, error) {
+	n, err := r.q.StartJob(ctx, schedulersqlc.StartJobParams{
+		NextRunAt:              next,
+		StartedAt:              sql.NullTime{Time: startedAt, Valid: true},
+		Name:                   expected.Name,
+		ExpectedNextRunAt:      expected.NextRunAt,
+		ExpectedRunRequestedAt: nullTime(expected.RunRequestedAt),
+	})
+	return n == 1, err
+}
+
+// Reschedule は expected の予定から変わっていない場合のみ、実行せずに次の予定を next に変えます。
+func (r *repository) Reschedule(ctx context.Context, expected State, next time.Time) (bool, error) {
+	n, err := r.q.RescheduleJob(ctx, schedulersqlc.RescheduleJobParams{
+		NextRunAt:         next,
+		Name:              expected.Name,
+		ExpectedNextRunAt: expected.NextRunAt,
+	})
+	return n == 1, err
+}
+
+// Finish は実行結果を記録し、実行回数（失敗した場合は失敗回数も）を増やします。
+func (r *repository) Finish(ctx context.Context, name string, res Result) error {
+	return r.q.FinishJob(ctx, schedulersqlc.FinishJobParams{
+		FinishedAt: sql.NullTime{Time: res.FinishedAt, Valid: true},
+		Status:     sql.NullString{String: string(res.Status), Valid: true},
+		Error:      sql.NullString{String: res.Error, Valid: res.Error != ""},
+		DurationMs: res.Duration.Milliseconds(),
+		Name:       name,
+	})
+}
+
+// RequestRun は即時実行を依頼します。依頼済みの場合は最初の依頼の日時を残します。未登録の場合は false を返します。
+func (r *repository) RequestRun(ctx context.Context, name string, at time.Time) (bool, error) {
+	n, err := r.q.RequestJobRun(ctx, schedulersqlc.RequestJobRunParams{
+		RequestedAt: sql.NullTime{Time: at, Valid: true},
+		Name:        name,
+	})
+	return n == 1, err
+}
+
+func toState(row schedulersqlc.GetJobRow) State {
+	return State{
+		Name:           row.Name,
+		NextRunAt:      row.NextRunAt,
+		RunRequestedAt: timePtr(row.RunRequestedAt),
+		LastStartedAt:  timePtr(row.LastStartedAt),
+		LastFinishedAt: timePtr(row.LastFinishedAt),
+		LastStatus:     Status(row.LastStatus.String),
+		LastError:      row.LastError.String,
+		LastDuration:   time.Duration(row.LastDurationMs) * time.Millisecond,
+		RunCount:       row.RunCount,
+		FailureCount:   row.FailureCount,
+	}
+}
+
+func timePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
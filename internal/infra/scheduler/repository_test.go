@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/db/dbtest"
+)
+
+func TestMain(m *testing.M) {
+	code, err := dbtest.RunMainWithPostgres(m)
+	if err != nil {
+		log.Fatalf("dbtest setup: %v", err)
+	}
+	os.Exit(code)
+}
+
+// TestRepository_Lifecycle は登録・開始・結果の記録・即時実行の依頼が予定を条件付きで更新することを検証します。
+func TestRepository_Lifecycle(t *testing.T) {
+	t.Parallel()
+	db := dbtest.OpenIsolatedDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	next := time.Date(2026, 3, 1, 1, 0, 0, 0, time.UTC)
+
+	require.NoError(t, repo.Ensure(ctx, "janitor", next))
+	require.NoError(t, repo.Ensure(ctx, "janitor", next.Add(time.Hour)), "登録済みの予定は変えない")
+	require.NoError(t, repo.Ensure(ctx, "pruner", next))
+
+	_, err := repo.Get(ctx, "missing")
+	require.ErrorIs(t, err, ErrUnknownJob)
+
+	st, err := repo.Get(ctx, "janitor")
+	require.NoError(t, err)
+	assert.True(t, st.NextRunAt.Equal(next))
+	assert.Nil(t, st.RunRequestedAt)
+	assert.Empty(t, st.LastStatus)
+
+	// 読み込んだ予定のまま開始できるのは 1 回だけ
+	startedAt := next.Add(10 * time.Second)
+	ok, err := repo.Start(ctx, st, next.Add(time.Hour), startedAt)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = repo.Start(ctx, st, next.Add(time.Hour), startedAt)
+	require.NoError(t, err)
+	assert.False(t, ok, "同じ予定を二重に開始しない")
+	ok, err = repo.Reschedule(ctx, st, next.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, repo.Finish(ctx, "janitor", Result{FinishedAt: startedAt.Add(time.Second), Status: StatusFailed, Error: "boom", Duration: 1500 * time.Millisecond}))
+	require.NoError(t, repo.Finish(ctx, "janitor", Result{FinishedAt: startedAt.Add(2 * time.Second), Status: StatusSucceeded, Duration: time.Second}))
+
+	// 即時実行の依頼は最初の依頼の日時を残し、依頼を読み込んだ状態でのみ開始できる
+	requestedAt := next.Add(20 * time.Minute)
+	ok, err = repo.RequestRun(ctx, "janitor", requestedAt)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = repo.RequestRun(ctx, "janitor", requestedAt.Add(time.Minute))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = repo.RequestRun(ctx, "missing", requestedAt)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	st, err = repo.Get(ctx, "janitor")
+	require.NoError(t, err)
+	assert.True(t, st.NextRunAt.Equal(next.Add(time.Hour)))
+	require.NotNil(t, st.RunRequestedAt)
+	assert.True(t, st.RunRequestedAt.Equal(requestedAt))
+	require.NotNil(t, st.LastStartedAt)
+	assert.True(t, st.LastStartedAt.Equal(startedAt))
+	assert.Equal(t, StatusSucceeded, st.LastStatus)
+	assert.Empty(t, st.LastError)
+	assert.Equal(t, time.Second, st.LastDuration)
+	assert.EqualValues(t, 2, st.RunCount)
+	assert.EqualValues(t, 1, st.FailureCount)
+
+	stale := st
+	stale.RunRequestedAt = nil
+	ok, err = repo.Start(ctx, stale, st.NextRunAt, requestedAt)
+	require.NoError(t, err)
+	assert.False(t, ok, "依頼を読み込んでいない開始は反映しない")
+	ok, err = repo.Start(ctx, st, st.NextRunAt, requestedAt)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	states, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, states, 2)
+	assert.Equal(t, "janitor", states[0].Name)
+	assert.Nil(t, states[0].RunRequestedAt)
+	assert.Equal(t, "pruner", states[1].Name)
+
+	ok, err = repo.Reschedule(ctx, states[1], next.Add(30*time.Minute))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	st, err = repo.Get(ctx, "pruner")
+	require.NoError(t, err)
+	assert.True(t, st.NextRunAt.Equal(next.Add(30*time.Minute)))
+}
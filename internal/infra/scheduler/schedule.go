@@ -0,0 +1,243 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule はジョブの実行予定です。実装は純粋な計算で、同じ入力には全インスタンスで同じ予定を返します。
+type Schedule interface {
+	// Next は after より後の最初の実行予定を返します。予定がない場合はゼロ値を返します。
+	Next(after time.Time) time.Time
+	// String は一覧に表示する予定の表記です。
+	String() string
+}
+
+// every は一定間隔の予定です。
+type every time.Duration
+
+// Every は d ごとの予定を返します。予定は Unix エポック（UTC）から d の倍数の時刻に揃えるため、
+// どのインスタンスで計算しても、いつ起動しても同じ時刻になります。d は正の値にします。
+func Every(d time.Duration) Schedule {
+	return every(d)
+}
+
+func (e every) Next(after time.Time) time.Time {
+	d := time.Duration(e)
+	if d <= 0 {
+		return time.Time{}
+	}
+	return after.UTC().Truncate(d).Add(d)
+}
+
+func (e every) String() string {
+	return "every " + time.Duration(e).String()
+}
+
+// cronSpec は 5 項目（分 時 日 月 曜日）の cron 形式の予定です。時刻は UTC で解釈します。
+type cronSpec struct {
+	expr                          string
+	minute, hour, dom, month, dow uint64
+	// domAny・dowAny は日・曜日が "*" か。両方とも指定された場合はどちらかに一致すれば実行する（cron の慣例）。
+	domAny, dowAny bool
+}
+
+// cronSearchLimit は Next が予定を探す範囲です。2 月 30 日のように存在しない日付の予定が無限に探し続けないよう区切ります。
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+// cronAliases は @ で始まる省略表記です。
+var cronAliases = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+}
+
+// ParseCron は cron 形式の予定（"分 時 日 月 曜日"、UTC）を解析します。
+// 各項目は "*"・数値・範囲（"1-5"）・間隔（"*/15"・"0-30/10"）とそのカンマ区切りの組み合わせです。
+// 曜日は 0（日曜）〜6 で、7 も日曜として受け付けます。"@hourly"・"@daily"・"@weekly"・"@monthly" も使えます。
+func ParseCron(expr string) (Schedule, error) {
+	spec := strings.TrimSpace(expr)
+	if alias, ok := cronAliases[spec]; ok {
+		spec = alias
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron %q: want 5 fields (minute hour day month weekday), got %d", expr, len(fields))
+	}
+
+	c := &cronSpec{expr: strings.TrimSpace(expr)}
+	var err error
+	if c.minute, _, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("cron %q: minute: %w", expr, err)
+	}
+	if c.hour, _, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("cron %q: hour: %w", expr, err)
+	}
+	if c.dom, c.domAny, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("cron %q: day of month: %w", expr, err)
+	}
+	if c.month, _, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("cron %q: month: %w", expr, err)
+	}
+	if c.dow, c.dowAny, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("cron %q: day of week: %w", expr, err)
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow = c.dow&^(1<<7) | 1
+	}
+	return c, nil
+}
+
+// MustParseCron は ParseCron の結果を返し、解析できない場合は panic します（コードに埋め込んだ予定用）。
+func MustParseCron(expr string) Schedule {
+	s, err := ParseCron(expr)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// parseCronField は 1 項目を解析し、一致する値のビット集合と "*" だったかを返します。
+func parseCronField(field string, lo, hi int) (bits uint64, wildcard bool, err error) {
+	for part := range strings.SplitSeq(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			if step, err = strconv.Atoi(stepStr); err != nil || step <= 0 {
+				return 0, false, fmt.Errorf("invalid step %q", part)
+			}
+		}
+
+		from, to := lo, hi
+		switch {
+		case rng == "*":
+			wildcard = wildcard || !hasStep
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			if from, err = strconv.Atoi(a); err != nil {
+				return 0, false, fmt.Errorf("invalid range %q", part)
+			}
+			if to, err = strconv.Atoi(b); err != nil {
+				return 0, false, fmt.Errorf("invalid range %q", part)
+			}
+		default:
+			if from, err = strconv.Atoi(rng); err != nil {
+				return 0, false, fmt.Errorf("invalid value %q", part)
+			}
+			to = from
+			if hasStep {
+				to = hi
+			}
+		}
+		if from < lo || to > hi || from > to {
+			return 0, false, fmt.Errorf("%q out of range %d-%d", part, lo, hi)
+		}
+		for v := from; v <= to; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, wildcard, nil
+}
+
+func (c *cronSpec) Next(after time.Time) time.Time {
+	t := after.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(cronSearchLimit)
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches は t の日付が日・曜日の指定に一致するかを返します。
+// 片方が "*" の場合はもう片方だけで判定し、両方とも指定された場合はどちらかに一致すれば一致とします。
+func (c *cronSpec) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case c.domAny && c.dowAny:
+		return true
+	case c.domAny:
+		return dow
+	case c.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+func (c *cronSpec) String() string {
+	return c.expr
+}
+
+// MissedRuns は停止中などで実行予定を過ぎていた場合の扱いです。
+type MissedRuns int
+
+const (
+	// CatchUpOnce は過ぎた予定がいくつあっても 1 回だけ実行し、次の予定を現在より後にします（既定）。
+	// 掃除・保持期間の削除のように、1 回実行すれば遅れを取り戻せるジョブ向けです。
+	CatchUpOnce MissedRuns = iota
+	// SkipMissed は直近の予定から Config.Grace 以内の場合だけ実行し、それより古い予定は実行せずに次の予定を待ちます。
+	// 決まった時刻に実行しないと意味のないジョブ向けです。
+	SkipMissed
+)
+
+// maxCountedMissed は見送った予定を数える上限です（長期間停止していた場合に数え続けないため）。
+// 上限を超えた場合、SkipMissed のジョブは今回は実行せずに次の予定を待ちます。
+const maxCountedMissed = 1000
+
+// decision は 1 回の確認で決めたジョブの扱いです。
+type decision struct {
+	// Run は今回実行するか。
+	Run bool
+	// Next は次の実行予定。
+	Next time.Time
+	// Skipped は実行しなかった過去の予定の数（CatchUpOnce でまとめて 1 回にした分を含む）。
+	Skipped int
+}
+
+// decide は永続化された次の予定 next・即時実行の依頼の有無と現在時刻 now から、今回実行するかと次の予定を決めます。
+// 予定を過ぎていなくても、スケジュールの変更で now の後の最初の予定が next より早くなった場合は次の予定を早めます。
+func decide(s Schedule, policy MissedRuns, grace time.Duration, next time.Time, requested bool, now time.Time) decision {
+	upcoming := s.Next(now)
+	if now.Before(next) {
+		d := decision{Run: requested, Next: next}
+		if !upcoming.IsZero() && upcoming.Before(next) {
+			d.Next = upcoming
+		}
+		return d
+	}
+
+	// next から now までの予定（next を含む）を数え、最後の予定を求める
+	last, due := next, 1
+	for t := s.Next(next); !t.IsZero() && !t.After(now) && due < maxCountedMissed; t = s.Next(t) {
+		last, due = t, due+1
+	}
+
+	d := decision{Next: upcoming}
+	switch {
+	case requested, policy == CatchUpOnce:
+		d.Run = true
+	case policy == SkipMissed:
+		d.Run = now.Sub(last) <= grace
+	}
+	d.Skipped = due
+	if d.Run {
+		d.Skipped--
+	}
+	return d
+}
@@ -0,0 +1,205 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func utc(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t.UTC()
+}
+
+func TestEvery_Next(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		every time.Duration
+		after string
+		want  string
+	}{
+		{"間隔の途中は次の倍数", time.Hour, "2026-03-01T10:20:00Z", "2026-03-01T11:00:00Z"},
+		{"ちょうど倍数の時刻はその次", time.Hour, "2026-03-01T11:00:00Z", "2026-03-01T12:00:00Z"},
+		{"15 分", 15 * time.Minute, "2026-03-01T10:44:59Z", "2026-03-01T10:45:00Z"},
+		{"日をまたぐ", 6 * time.Hour, "2026-03-01T23:00:00Z", "2026-03-02T00:00:00Z"},
+		{"タイムゾーンによらない", time.Hour, "2026-03-01T19:20:00+09:00", "2026-03-01T11:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, utc(tt.want), Every(tt.every).Next(utc(tt.after)))
+		})
+	}
+
+	assert.True(t, Every(0).Next(utc("2026-03-01T00:00:00Z")).IsZero())
+	assert.Equal(t, "every 1h0m0s", Every(time.Hour).String())
+}
+
+func TestParseCron_Next(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		expr  string
+		after string
+		want  string
+	}{
+		{"*/15 * * * *", "2026-03-01T10:07:30Z", "2026-03-01T10:15:00Z"},
+		{"*/15 * * * *", "2026-03-01T10:45:00Z", "2026-03-01T11:00:00Z"},
+		{"30 3 * * *", "2026-03-01T03:30:00Z", "2026-03-02T03:30:00Z"},
+		{"30 3 * * *", "2026-03-01T03:29:59Z", "2026-03-01T03:30:00Z"},
+		{"0 9-17/4 * * *", "2026-03-01T13:00:00Z", "2026-03-01T17:00:00Z"},
+		{"0 9-17/4 * * *", "2026-03-01T17:00:00Z", "2026-03-02T09:00:00Z"},
+		{"5,35 * * * *", "2026-03-01T10:06:00Z", "2026-03-01T10:35:00Z"},
+		// 2026-03-01 は日曜日
+		{"0 0 * * 1-5", "2026-02-28T12:00:00Z", "2026-03-02T00:00:00Z"},
+		{"0 0 * * 7", "2026-03-02T00:00:00Z", "2026-03-08T00:00:00Z"},
+		// 日・曜日の両方を指定した場合はどちらかに一致すれば実行する
+		{"0 0 15 * 1", "2026-03-03T00:00:00Z", "2026-03-09T00:00:00Z"},
+		{"0 0 15 * 1", "2026-03-09T00:00:00Z", "2026-03-15T00:00:00Z"},
+		{"0 0 31 * *", "2026-04-01T00:00:00Z", "2026-05-31T00:00:00Z"},
+		{"0 0 29 2 *", "2026-03-01T00:00:00Z", "2028-02-29T00:00:00Z"},
+		{"0 0 1 1 *", "2026-12-31T23:59:00Z", "2027-01-01T00:00:00Z"},
+		{"@hourly", "2026-03-01T10:00:00Z", "2026-03-01T11:00:00Z"},
+		{"@daily", "2026-03-01T10:00:00Z", "2026-03-02T00:00:00Z"},
+		{"@weekly", "2026-03-01T10:00:00Z", "2026-03-08T00:00:00Z"},
+		{"@monthly", "2026-03-01T10:00:00Z", "2026-04-01T00:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr+"/"+tt.after, func(t *testing.T) {
+			t.Parallel()
+			s, err := ParseCron(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, utc(tt.want), s.Next(utc(tt.after)))
+			assert.Equal(t, tt.expr, s.String())
+		})
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	t.Parallel()
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1-x * * * *",
+		"@yearly",
+	} {
+		_, err := ParseCron(expr)
+		assert.Error(t, err, expr)
+	}
+	assert.Panics(t, func() { MustParseCron("bad") })
+}
+
+// TestParseCron_NoUpcomingRun は存在しない日付の予定がゼロ値を返し、探し続けないことを検証します。
+func TestParseCron_NoUpcomingRun(t *testing.T) {
+	t.Parallel()
+	s := MustParseCron("0 0 30 2 *")
+	assert.True(t, s.Next(utc("2026-01-01T00:00:00Z")).IsZero())
+}
+
+func TestDecide(t *testing.T) {
+	t.Parallel()
+	hourly := Every(time.Hour)
+	grace := 2 * time.Minute
+	tests := []struct {
+		name      string
+		schedule  Schedule
+		policy    MissedRuns
+		next      string
+		requested bool
+		now       string
+		want      decision
+	}{
+		{
+			name: "予定前は実行しない", schedule: hourly, next: "2026-03-01T11:00:00Z", now: "2026-03-01T10:59:59Z",
+			want: decision{Next: utc("2026-03-01T11:00:00Z")},
+		},
+		{
+			name: "予定の時刻ちょうどに実行し次の予定に進める", schedule: hourly, next: "2026-03-01T11:00:00Z", now: "2026-03-01T11:00:00Z",
+			want: decision{Run: true, Next: utc("2026-03-01T12:00:00Z")},
+		},
+		{
+			name: "確認の遅れは見送りに数えない", schedule: hourly, next: "2026-03-01T11:00:00Z", now: "2026-03-01T11:00:40Z",
+			want: decision{Run: true, Next: utc("2026-03-01T12:00:00Z")},
+		},
+		{
+			name: "停止中に過ぎた予定は 1 回にまとめる", schedule: hourly, next: "2026-03-01T11:00:00Z", now: "2026-03-01T14:30:00Z",
+			want: decision{Run: true, Next: utc("2026-03-01T15:00:00Z"), Skipped: 3},
+		},
+		{
+			name: "SkipMissed は猶予内なら実行する", schedule: hourly, policy: SkipMissed, next: "2026-03-01T11:00:00Z", now: "2026-03-01T11:01:00Z",
+			want: decision{Run: true, Next: utc("2026-03-01T12:00:00Z")},
+		},
+		{
+			name: "SkipMissed は猶予を過ぎた予定を実行しない", schedule: hourly, policy: SkipMissed, next: "2026-03-01T11:00:00Z", now: "2026-03-01T11:30:00Z",
+			want: decision{Next: utc("2026-03-01T12:00:00Z"), Skipped: 1},
+		},
+		{
+			name: "SkipMissed は直近の予定が猶予内なら古い予定を見送って実行する", schedule: hourly, policy: SkipMissed, next: "2026-03-01T08:00:00Z", now: "2026-03-01T11:01:00Z",
+			want: decision{Run: true, Next: utc("2026-03-01T12:00:00Z"), Skipped: 3},
+		},
+		{
+			name: "即時実行の依頼は予定前でも実行し予定は変えない", schedule: hourly, next: "2026-03-01T11:00:00Z", requested: true, now: "2026-03-01T10:15:00Z",
+			want: decision{Run: true, Next: utc("2026-03-01T11:00:00Z")},
+		},
+		{
+			name: "即時実行の依頼は SkipMissed でも実行する", schedule: hourly, policy: SkipMissed, next: "2026-03-01T08:00:00Z", requested: true, now: "2026-03-01T11:30:00Z",
+			want: decision{Run: true, Next: utc("2026-03-01T12:00:00Z"), Skipped: 3},
+		},
+		{
+			name: "スケジュールの変更で予定が早まった場合は実行せずに早める", schedule: Every(15 * time.Minute), next: "2026-03-02T00:00:00Z", now: "2026-03-01T10:05:00Z",
+			want: decision{Next: utc("2026-03-01T10:15:00Z")},
+		},
+		{
+			name: "cron の予定", schedule: MustParseCron("30 3 * * *"), next: "2026-03-01T03:30:00Z", now: "2026-03-03T04:00:00Z",
+			want: decision{Run: true, Next: utc("2026-03-04T03:30:00Z"), Skipped: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := decide(tt.schedule, tt.policy, grace, utc(tt.next), tt.requested, utc(tt.now))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestDecide_SkippedCountIsBounded は長期間停止していた場合も見送りを数え続けないことを検証します。
+func TestDecide_SkippedCountIsBounded(t *testing.T) {
+	t.Parallel()
+	d := decide(Every(time.Minute), CatchUpOnce, time.Minute, utc("2026-01-01T00:00:00Z"), false, utc("2026-03-01T00:00:00Z"))
+	assert.True(t, d.Run)
+	assert.Equal(t, maxCountedMissed-1, d.Skipped)
+	assert.Equal(t, utc("2026-03-01T00:01:00Z"), d.Next)
+}
+
+// TestDecide_Simulation は 1 日分の確認を 1 分ごとに繰り返し、毎時の予定をちょうど 24 回実行することを検証します。
+func TestDecide_Simulation(t *testing.T) {
+	t.Parallel()
+	s := Every(time.Hour)
+	start := utc("2026-03-01T00:00:30Z")
+	next := s.Next(start)
+	runs := 0
+	for now := start; !now.After(start.Add(24 * time.Hour)); now = now.Add(time.Minute) {
+		d := decide(s, CatchUpOnce, time.Minute, next, false, now)
+		if d.Run {
+			runs++
+			assert.Zero(t, d.Skipped, now)
+		}
+		next = d.Next
+	}
+	assert.Equal(t, 24, runs)
+}
@@ -0,0 +1,488 @@
+// Package scheduler は API の各インスタンスで定期的に実行するジョブ（掃除・保持期間の削除等）を管理します。
+//
+// ジョブの次の予定と直近の結果は DB（scheduled_jobs）に保存し、どのインスタンスも同じ予定を読みます。
+// 予定を過ぎたジョブは Redis のロックを取得したインスタンスだけが、予定を条件付きで進めてから実行するため、
+// 複数のインスタンスで起動しても 1 つの予定は 1 回だけ実行されます。
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
+)
+
+// Scheduler の既定値です。
+const (
+	DefaultTickInterval = 30 * time.Second
+	DefaultGrace        = 2 * time.Minute
+	DefaultJobTimeout   = 5 * time.Minute
+
+	// lockMargin はロックの期限をジョブのタイムアウトより長く取る分です（結果の記録を含めて保持するため）。
+	lockMargin = 30 * time.Second
+	// recordTimeout は結果の記録にかける時間の上限です。
+	recordTimeout = 5 * time.Second
+	// maxErrorLength は scheduled_jobs.last_error に残すエラー文字列の最大長です。
+	maxErrorLength = 500
+)
+
+var (
+	// ErrUnknownJob は登録されていないジョブ名が指定されたことを示します。
+	ErrUnknownJob = apperr.New(apperr.KindNotFound, "unknown_job", "unknown job")
+
+	// jobNamePattern はジョブ名に使える文字です（ロックのキーと URL のパスに使うため）。
+	jobNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+)
+
+// Status はジョブの直近の実行結果です。
+type Status string
+
+const (
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	// StatusTimedOut は Job.Timeout までに終わらなかったことを示します。
+	StatusTimedOut Status = "timed_out"
+)
+
+// State は DB に保存したジョブの予定と直近の結果です。
+type State struct {
+	Name      string
+	NextRunAt time.Time
+	// RunRequestedAt は即時実行の依頼の日時です（依頼がなければ nil）。
+	RunRequestedAt *time.Time
+	LastStartedAt  *time.Time
+	LastFinishedAt *time.Time
+	// LastStatus は直近の結果です（実行前は空）。
+	LastStatus   Status
+	LastError    string
+	LastDuration time.Duration
+	RunCount     int64
+	FailureCount int64
+}
+
+// Result は 1 回の実行結果です。
+type Result struct {
+	FinishedAt time.Time
+	Status     Status
+	Error      string
+	Duration   time.Duration
+}
+
+// Store はジョブの予定と結果の永続化層を抽象化します。repository が実装します。
+// Start・Reschedule は読み込んだ時点の予定（expected）から変わっていない場合のみ反映し、変わっていれば false を返します。
+type Store interface {
+	// Ensure は name が未登録の場合のみ next を次の予定として登録します。
+	Ensure(ctx context.Context, name string, next time.Time) error
+	// List は登録済みの全ジョブを名前順に返します。
+	List(ctx context.Context) ([]State, error)
+	// Get は name のジョブを返します。未登録の場合は ErrUnknownJob を返します。
+	Get(ctx context.Context, name string) (State, error)
+	// Start は次の予定を next に進め、即時実行の依頼を消して開始日時を記録します。
+	Start(ctx context.Context, expected State, next, startedAt time.Time) (bool, error)
+	// Reschedule は実行せずに次の予定を next に変えます。
+	Reschedule(ctx context.Context, expected State, next time.Time) (bool, error)
+	// Finish は実行結果を記録します。
+	Finish(ctx context.Context, name string, r Result) error
+	// RequestRun は即時実行を依頼します。未登録の場合は false を返します。
+	RequestRun(ctx context.Context, name string, at time.Time) (bool, error)
+}
+
+// Locker はインスタンス間の排他です（infraredis.Locker が実装）。
+type Locker interface {
+	// TryLock は name のロックを ttl の間取得します。他が保持している場合は ok = false を返します。
+	TryLock(ctx context.Context, name string, ttl time.Duration) (unlock func(context.Context) error, ok bool, err error)
+}
+
+// Job は定期的に実行する処理です。
+type Job struct {
+	// Name はジョブの名前です（英小文字・数字・"_"・"-"、64 文字まで）。DB の行とロックのキーに使います。
+	Name string
+	// Description は一覧に表示する説明です。
+	Description string
+	// Schedule は実行予定です（Every・ParseCron）。
+	Schedule Schedule
+	// Timeout は 1 回の実行の上限です。0 の場合は DefaultJobTimeout です。
+	Timeout time.Duration
+	// MissedRuns は予定を過ぎていた場合の扱いです。ゼロ値は CatchUpOnce です。
+	MissedRuns MissedRuns
+	// Run は処理の本体です。ctx は Timeout かスケジューラーの停止で終了します。
+	Run func(ctx context.Context) error
+}
+
+// Config は Scheduler の設定です。ゼロ値の項目は既定値を使います。
+type Config struct {
+	// TickInterval は予定を確認する間隔です。
+	TickInterval time.Duration
+	// Grace は SkipMissed のジョブを予定から遅れて実行してよい時間です。TickInterval より長くします。
+	Grace time.Duration
+}
+
+func (c Config) withDefaults() Config {
+	if c.TickInterval <= 0 {
+		c.TickInterval = DefaultTickInterval
+	}
+	if c.Grace <= 0 {
+		c.Grace = DefaultGrace
+	}
+	if c.Grace < c.TickInterval {
+		c.Grace = 2 * c.TickInterval
+	}
+	return c
+}
+
+// Stats は Scheduler が実行した件数の累計です（このインスタンスの分）。
+type Stats struct {
+	Succeeded uint64 // 成功した実行の件数
+	Failed    uint64 // 失敗・タイムアウトした実行の件数
+	Skipped   uint64 // 予定を過ぎていたため実行しなかった（CatchUpOnce で 1 回にまとめた分を含む）予定の件数
+}
+
+// JobStatus は一覧に返すジョブの定義と状態です。
+type JobStatus struct {
+	Name        string
+	Description string
+	Schedule    string
+	Timeout     time.Duration
+	State       State
+}
+
+// Pauser はジョブの実行を一時的に止めるべきか（メンテナンスモード）を返します。
+type Pauser interface {
+	Active(ctx context.Context) bool
+}
+
+// Scheduler は登録したジョブを予定どおりに実行します。
+type Scheduler struct {
+	store  Store
+	locker Locker
+	cfg    Config
+	now    func() time.Time
+	// pause が有効な間は予定を確認しません（WithPause で設定。nil なら止めない）
+	pause Pauser
+
+	mu   sync.Mutex
+	jobs map[string]*Job
+	// running はこのインスタンスで実行中のジョブです（同じジョブを重ねて起動しないため）。
+	running map[string]bool
+	wg      sync.WaitGroup
+	// wake は即時実行の依頼を受けた際に次の確認を待たずに起こすためのチャネルです。
+	wake chan struct{}
+
+	succeeded atomic.Uint64
+	failed    atomic.Uint64
+	skipped   atomic.Uint64
+}
+
+// New は Scheduler を生成します。ジョブを Register してから Run を起動します。
+func New(store Store, locker Locker, cfg Config) *Scheduler {
+	return &Scheduler{
+		store:   store,
+		locker:  locker,
+		cfg:     cfg.withDefaults(),
+		now:     time.Now,
+		jobs:    make(map[string]*Job),
+		running: make(map[string]bool),
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// WithPause は p が有効な間、予定の確認（ジョブの実行・予定の更新）を止めます。
+// 止めている間に過ぎた予定は、再開後に MissedRuns に従って扱います（CatchUpOnce なら 1 回にまとめて実行）。
+func (s *Scheduler) WithPause(p Pauser) *Scheduler {
+	s.pause = p
+	return s
+}
+
+// Register はジョブを登録します。名前の重複・不正な名前・予定のないスケジュールはエラーです。
+func (s *Scheduler) Register(job Job) error {
+	if !jobNamePattern.MatchString(job.Name) {
+		return fmt.Errorf("scheduler: invalid job name %q", job.Name)
+	}
+	if job.Schedule == nil || job.Schedule.Next(s.now()).IsZero() {
+		return fmt.Errorf("scheduler: job %q has no upcoming run", job.Name)
+	}
+	if job.Run == nil {
+		return fmt.Errorf("scheduler: job %q has no run function", job.Name)
+	}
+	if job.Timeout <= 0 {
+		job.Timeout = DefaultJobTimeout
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[job.Name]; ok {
+		return fmt.Errorf("scheduler: job %q already registered", job.Name)
+	}
+	s.jobs[job.Name] = &job
+	return nil
+}
+
+// Stats はこれまでの実行件数を返します。
+func (s *Scheduler) Stats() Stats {
+	return Stats{
+		Succeeded: s.succeeded.Load(),
+		Failed:    s.failed.Load(),
+		Skipped:   s.skipped.Load(),
+	}
+}
+
+// Run は ctx が終了するまで TickInterval ごとに予定を確認し、予定を過ぎたジョブを実行します。
+// バックグラウンドの goroutine で起動し、ctx の終了後は実行中のジョブ（ctx の終了で中断される）の結果の記録を待ってから戻ります。
+func (s *Scheduler) Run(ctx context.Context) {
+	defer s.wg.Wait()
+	s.ensure(ctx)
+
+	ticker := time.NewTicker(s.cfg.TickInterval)
+	defer ticker.Stop()
+	for {
+		s.tick(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// ensure は登録したジョブのうち DB に行がないものを、次の予定とともに登録します。
+func (s *Scheduler) ensure(ctx context.Context) {
+	now := s.now()
+	for _, job := range s.registered() {
+		if err := s.store.Ensure(ctx, job.Name, job.Schedule.Next(now)); err != nil && ctx.Err() == nil {
+			slog.Error("failed to register scheduled job", "job", job.Name, "error", err)
+		}
+	}
+}
+
+// tick は全ジョブの予定を 1 回読み、予定を過ぎた・即時実行を依頼されたジョブをそれぞれ別の goroutine で起動します。
+// pause が有効な間は何もしません。
+func (s *Scheduler) tick(ctx context.Context) {
+	if s.pause != nil && s.pause.Active(ctx) {
+		return
+	}
+	states, err := s.store.List(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("failed to list scheduled jobs", "error", err)
+		}
+		return
+	}
+	now := s.now()
+	seen := make(map[string]bool, len(states))
+	for _, st := range states {
+		seen[st.Name] = true
+		job := s.job(st.Name)
+		if job == nil {
+			continue // 他のバージョンのインスタンスが登録したジョブ
+		}
+		if d := decide(job.Schedule, job.MissedRuns, s.cfg.Grace, st.NextRunAt, st.RunRequestedAt != nil, now); d.Run || !d.Next.Equal(st.NextRunAt) {
+			s.start(ctx, job)
+		}
+	}
+	// 起動時に登録できなかったジョブは次の確認で登録し直す
+	for _, job := range s.registered() {
+		if !seen[job.Name] {
+			if err := s.store.Ensure(ctx, job.Name, job.Schedule.Next(now)); err != nil && ctx.Err() == nil {
+				slog.Error("failed to register scheduled job", "job", job.Name, "error", err)
+			}
+		}
+	}
+}
+
+// start はこのインスタンスで実行中でなければ、job を別の goroutine で実行します。
+func (s *Scheduler) start(ctx context.Context, job *Job) {
+	s.mu.Lock()
+	if s.running[job.Name] {
+		s.mu.Unlock()
+		return
+	}
+	s.running[job.Name] = true
+	s.mu.Unlock()
+
+	s.wg.Go(func() {
+		defer func() {
+			s.mu.Lock()
+			delete(s.running, job.Name)
+			s.mu.Unlock()
+		}()
+		s.runJob(ctx, job)
+	})
+}
+
+// runJob はロックを取得して予定を読み直し、まだ実行されていなければ予定を進めてから実行し、結果を記録します。
+// ロックを他のインスタンスが保持している・予定が既に進んでいる場合は何もしません。
+func (s *Scheduler) runJob(ctx context.Context, job *Job) {
+	unlock, ok, err := s.locker.TryLock(ctx, job.Name, job.Timeout+lockMargin)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Warn("failed to lock scheduled job, skipping this tick", "job", job.Name, "error", err)
+		}
+		return
+	}
+	if !ok {
+		return
+	}
+	defer func() {
+		uctx, cancel := context.WithTimeout(context.Background(), recordTimeout)
+		defer cancel()
+		if err := unlock(uctx); err != nil {
+			slog.Warn("failed to unlock scheduled job", "job", job.Name, "error", err)
+		}
+	}()
+
+	st, err := s.store.Get(ctx, job.Name)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("failed to load scheduled job", "job", job.Name, "error", err)
+		}
+		return
+	}
+	now := s.now()
+	d := decide(job.Schedule, job.MissedRuns, s.cfg.Grace, st.NextRunAt, st.RunRequestedAt != nil, now)
+	if !d.Run {
+		if d.Next.Equal(st.NextRunAt) {
+			return
+		}
+		if _, err := s.store.Reschedule(ctx, st, d.Next); err != nil && ctx.Err() == nil {
+			slog.Error("failed to reschedule job", "job", job.Name, "error", err)
+			return
+		}
+		if d.Skipped > 0 {
+			s.skipped.Add(uint64(d.Skipped))
+			slog.Warn("skipped missed scheduled runs", "job", job.Name, "skipped", d.Skipped, "next_run_at", d.Next)
+		}
+		return
+	}
+
+	started, err := s.store.Start(ctx, st, d.Next, now)
+	if err != nil || !started {
+		if err != nil && ctx.Err() == nil {
+			slog.Error("failed to start scheduled job", "job", job.Name, "error", err)
+		}
+		return
+	}
+	if d.Skipped > 0 {
+		s.skipped.Add(uint64(d.Skipped))
+		slog.Info("coalesced missed scheduled runs", "job", job.Name, "skipped", d.Skipped)
+	}
+
+	res := s.execute(ctx, job)
+	rctx, cancel := context.WithTimeout(context.Background(), recordTimeout)
+	defer cancel()
+	if err := s.store.Finish(rctx, job.Name, res); err != nil {
+		slog.Error("failed to record scheduled job result", "job", job.Name, "error", err)
+	}
+}
+
+// execute は Timeout を上限に job を実行し、結果を返します。panic はエラーとして記録します。
+func (s *Scheduler) execute(ctx context.Context, job *Job) Result {
+	jctx, cancel := context.WithTimeout(ctx, job.Timeout)
+	defer cancel()
+
+	started := s.now()
+	err := func() (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = fmt.Errorf("panic: %v", p)
+			}
+		}()
+		return job.Run(jctx)
+	}()
+	res := Result{FinishedAt: s.now(), Status: StatusSucceeded}
+	res.Duration = res.FinishedAt.Sub(started)
+
+	switch {
+	case err == nil:
+		s.succeeded.Add(1)
+		slog.Info("scheduled job succeeded", "job", job.Name, "duration_ms", res.Duration.Milliseconds())
+		return res
+	case errors.Is(jctx.Err(), context.DeadlineExceeded) && ctx.Err() == nil:
+		res.Status = StatusTimedOut
+	default:
+		res.Status = StatusFailed
+	}
+	res.Error = apperr.Truncate(err, maxErrorLength)
+	s.failed.Add(1)
+	slog.Error("scheduled job failed", "job", job.Name, "status", res.Status, "duration_ms", res.Duration.Milliseconds(), "error", err)
+	return res
+}
+
+// Jobs は登録済みのジョブの定義と DB の状態を名前順に返します。
+func (s *Scheduler) Jobs(ctx context.Context) ([]JobStatus, error) {
+	states, err := s.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]State, len(states))
+	for _, st := range states {
+		byName[st.Name] = st
+	}
+	jobs := s.registered()
+	out := make([]JobStatus, 0, len(jobs))
+	for _, job := range jobs {
+		st, ok := byName[job.Name]
+		if !ok {
+			st = State{Name: job.Name, NextRunAt: job.Schedule.Next(s.now())}
+		}
+		out = append(out, JobStatus{
+			Name:        job.Name,
+			Description: job.Description,
+			Schedule:    job.Schedule.String(),
+			Timeout:     job.Timeout,
+			State:       st,
+		})
+	}
+	return out, nil
+}
+
+// RunNow は name のジョブの即時実行を依頼します。次の確認でいずれかのインスタンスが 1 回だけ実行します
+// （このインスタンスは確認を待たずに起こします）。未登録のジョブは ErrUnknownJob を返します。
+func (s *Scheduler) RunNow(ctx context.Context, name string) error {
+	job := s.job(name)
+	if job == nil {
+		return ErrUnknownJob
+	}
+	now := s.now()
+	if err := s.store.Ensure(ctx, name, job.Schedule.Next(now)); err != nil {
+		return err
+	}
+	ok, err := s.store.RequestRun(ctx, name, now)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrUnknownJob
+	}
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (s *Scheduler) job(name string) *Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.jobs[name]
+}
+
+// registered は登録済みのジョブを名前順に返します。
+func (s *Scheduler) registered() []*Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]*Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		out = append(out, job)
+	}
+	slices.SortFunc(out, func(a, b *Job) int { return strings.Compare(a.Name, b.Name) })
+	return out
+}
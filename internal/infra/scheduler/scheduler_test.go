@@ -0,0 +1,466 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	infraredis "github.com/UCHIDAnobuhiro/stock-backend/internal/infra/redis"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/testsupport"
+)
+
+// memStore は Store のインメモリ実装です。Start・Reschedule は repository の SQL と同じく、
+// 読み込んだ時点の予定から変わっていない場合のみ反映します。
+type memStore struct {
+	mu     sync.Mutex
+	states map[string]State
+}
+
+func newMemStore() *memStore {
+	return &memStore{states: make(map[string]State)}
+}
+
+func (m *memStore) Ensure(_ context.Context, name string, next time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.states[name]; !ok {
+		m.states[name] = State{Name: name, NextRunAt: next}
+	}
+	return nil
+}
+
+func (m *memStore) List(context.Context) ([]State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]State, 0, len(m.states))
+	for _, st := range m.states {
+		out = append(out, st)
+	}
+	return out, nil
+}
+
+func (m *memStore) Get(_ context.Context, name string) (State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st, ok := m.states[name]
+	if !ok {
+		return State{}, ErrUnknownJob
+	}
+	return st, nil
+}
+
+func (m *memStore) Start(_ context.Context, expected State, next, startedAt time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st, ok := m.states[expected.Name]
+	if !ok || !st.NextRunAt.Equal(expected.NextRunAt) || !sameTime(st.RunRequestedAt, expected.RunRequestedAt) {
+		return false, nil
+	}
+	st.NextRunAt, st.RunRequestedAt, st.LastStartedAt = next, nil, &startedAt
+	m.states[expected.Name] = st
+	return true, nil
+}
+
+func (m *memStore) Reschedule(_ context.Context, expected State, next time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st, ok := m.states[expected.Name]
+	if !ok || !st.NextRunAt.Equal(expected.NextRunAt) {
+		return false, nil
+	}
+	st.NextRunAt = next
+	m.states[expected.Name] = st
+	return true, nil
+}
+
+func (m *memStore) Finish(_ context.Context, name string, r Result) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st := m.states[name]
+	st.LastFinishedAt, st.LastStatus, st.LastError, st.LastDuration = &r.FinishedAt, r.Status, r.Error, r.Duration
+	st.RunCount++
+	if r.Status != StatusSucceeded {
+		st.FailureCount++
+	}
+	m.states[name] = st
+	return nil
+}
+
+func (m *memStore) RequestRun(_ context.Context, name string, at time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st, ok := m.states[name]
+	if !ok {
+		return false, nil
+	}
+	if st.RunRequestedAt == nil {
+		st.RunRequestedAt = &at
+	}
+	m.states[name] = st
+	return true, nil
+}
+
+func (m *memStore) state(name string) State {
+	st, _ := m.Get(context.Background(), name)
+	return st
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// newTestScheduler は store・miniredis のロックと clock を共有する Scheduler を生成します。
+func newTestScheduler(t *testing.T, store Store, locker Locker, clock *testsupport.Clock) *Scheduler {
+	t.Helper()
+	s := New(store, locker, Config{TickInterval: time.Minute})
+	s.now = clock.Now
+	return s
+}
+
+// tickAndWait は確認を 1 回行い、起動したジョブの終了を待ちます。
+func tickAndWait(ctx context.Context, s *Scheduler) {
+	s.tick(ctx)
+	s.wg.Wait()
+}
+
+// TestScheduler_SingleExecutionAcrossInstances は同じ DB・Redis を共有する 2 つのインスタンスが同時に確認しても、
+// 各予定を 1 回だけ実行することを検証します。
+func TestScheduler_SingleExecutionAcrossInstances(t *testing.T) {
+	t.Parallel()
+	mr, rdb := testsupport.NewMiniRedis(t)
+	clock := testsupport.NewClock(utc("2026-03-01T00:00:30Z"))
+	clock.SyncRedis(mr)
+	store := newMemStore()
+	ctx := context.Background()
+
+	var runs atomic.Int64
+	var perTick sync.Map
+	job := Job{
+		Name:     "janitor",
+		Schedule: Every(10 * time.Minute),
+		Timeout:  time.Minute,
+		Run: func(context.Context) error {
+			runs.Add(1)
+			n, _ := perTick.LoadOrStore(clock.Now().Truncate(10*time.Minute), new(atomic.Int64))
+			n.(*atomic.Int64).Add(1)
+			time.Sleep(5 * time.Millisecond) // 2 つのインスタンスの実行を重ねる
+			return nil
+		},
+	}
+	instances := make([]*Scheduler, 2)
+	for i := range instances {
+		instances[i] = newTestScheduler(t, store, infraredis.NewLocker(rdb, "lock"), clock)
+		require.NoError(t, instances[i].Register(job))
+		instances[i].ensure(ctx)
+	}
+
+	const ticks = 12
+	for range ticks {
+		clock.Advance(10 * time.Minute)
+		var wg sync.WaitGroup
+		for _, s := range instances {
+			wg.Go(func() { tickAndWait(ctx, s) })
+		}
+		wg.Wait()
+	}
+
+	assert.EqualValues(t, ticks, runs.Load())
+	perTick.Range(func(k, v any) bool {
+		assert.EqualValues(t, 1, v.(*atomic.Int64).Load(), "tick %v", k)
+		return true
+	})
+	st := store.state("janitor")
+	assert.EqualValues(t, ticks, st.RunCount)
+	assert.Equal(t, StatusSucceeded, st.LastStatus)
+	assert.Equal(t, utc("2026-03-01T02:10:00Z"), st.NextRunAt)
+	total := instances[0].Stats().Succeeded + instances[1].Stats().Succeeded
+	assert.EqualValues(t, ticks, total)
+}
+
+// TestScheduler_SkipsWhileLockHeld は他のインスタンスがロックを保持している間は実行せず、予定も進めないことを検証します。
+func TestScheduler_SkipsWhileLockHeld(t *testing.T) {
+	t.Parallel()
+	mr, rdb := testsupport.NewMiniRedis(t)
+	clock := testsupport.NewClock(utc("2026-03-01T00:00:30Z"))
+	clock.SyncRedis(mr)
+	store := newMemStore()
+	locker := infraredis.NewLocker(rdb, "lock")
+	ctx := context.Background()
+
+	var runs atomic.Int64
+	s := newTestScheduler(t, store, locker, clock)
+	require.NoError(t, s.Register(Job{Name: "pruner", Schedule: Every(time.Hour), Run: func(context.Context) error {
+		runs.Add(1)
+		return nil
+	}}))
+	s.ensure(ctx)
+
+	unlock, ok, err := locker.TryLock(ctx, "pruner", 2*time.Hour)
+	require.NoError(t, err)
+	require.True(t, ok)
+	clock.Advance(time.Hour)
+	tickAndWait(ctx, s)
+	assert.Zero(t, runs.Load())
+	assert.Equal(t, utc("2026-03-01T01:00:00Z"), store.state("pruner").NextRunAt)
+
+	require.NoError(t, unlock(ctx))
+	tickAndWait(ctx, s)
+	assert.EqualValues(t, 1, runs.Load())
+	assert.Equal(t, utc("2026-03-01T02:00:00Z"), store.state("pruner").NextRunAt)
+}
+
+// TestScheduler_RedisUnavailable は Redis に接続できない間は実行しないことを検証します。
+func TestScheduler_RedisUnavailable(t *testing.T) {
+	t.Parallel()
+	mr, _ := testsupport.NewMiniRedis(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = rdb.Close() })
+	clock := testsupport.NewClock(utc("2026-03-01T00:00:30Z"))
+	store := newMemStore()
+	ctx := context.Background()
+
+	var runs atomic.Int64
+	s := newTestScheduler(t, store, infraredis.NewLocker(rdb, "lock"), clock)
+	require.NoError(t, s.Register(Job{Name: "pruner", Schedule: Every(time.Hour), Run: func(context.Context) error {
+		runs.Add(1)
+		return nil
+	}}))
+	s.ensure(ctx)
+	mr.Close()
+
+	clock.Advance(time.Hour)
+	tickAndWait(ctx, s)
+	assert.Zero(t, runs.Load())
+	assert.Equal(t, utc("2026-03-01T01:00:00Z"), store.state("pruner").NextRunAt)
+}
+
+// TestScheduler_RunNow は即時実行の依頼を次の確認でいずれか 1 つのインスタンスが実行し、予定は変えないことを検証します。
+func TestScheduler_RunNow(t *testing.T) {
+	t.Parallel()
+	mr, rdb := testsupport.NewMiniRedis(t)
+	clock := testsupport.NewClock(utc("2026-03-01T00:00:30Z"))
+	clock.SyncRedis(mr)
+	store := newMemStore()
+	ctx := context.Background()
+
+	var runs atomic.Int64
+	job := Job{Name: "janitor", Schedule: Every(24 * time.Hour), Run: func(context.Context) error {
+		runs.Add(1)
+		return nil
+	}}
+	a := newTestScheduler(t, store, infraredis.NewLocker(rdb, "lock"), clock)
+	b := newTestScheduler(t, store, infraredis.NewLocker(rdb, "lock"), clock)
+	require.NoError(t, a.Register(job))
+	require.NoError(t, b.Register(job))
+
+	require.ErrorIs(t, a.RunNow(ctx, "missing"), ErrUnknownJob)
+	require.NoError(t, a.RunNow(ctx, "janitor"))
+	require.NoError(t, a.RunNow(ctx, "janitor"), "重ねて依頼しても 1 回にまとめる")
+	require.NotNil(t, store.state("janitor").RunRequestedAt)
+
+	var wg sync.WaitGroup
+	wg.Go(func() { tickAndWait(ctx, a) })
+	wg.Go(func() { tickAndWait(ctx, b) })
+	wg.Wait()
+	assert.EqualValues(t, 1, runs.Load())
+	st := store.state("janitor")
+	assert.Nil(t, st.RunRequestedAt)
+	assert.Equal(t, utc("2026-03-02T00:00:00Z"), st.NextRunAt)
+
+	tickAndWait(ctx, a)
+	assert.EqualValues(t, 1, runs.Load(), "依頼の後は予定まで実行しない")
+}
+
+// TestScheduler_RecordsFailures は失敗・タイムアウト・panic を結果として記録し、次の予定には進めることを検証します。
+func TestScheduler_RecordsFailures(t *testing.T) {
+	t.Parallel()
+	_, rdb := testsupport.NewMiniRedis(t)
+	clock := testsupport.NewClock(utc("2026-03-01T00:00:30Z"))
+	store := newMemStore()
+	ctx := context.Background()
+
+	s := newTestScheduler(t, store, infraredis.NewLocker(rdb, "lock"), clock)
+	require.NoError(t, s.Register(Job{Name: "failing", Schedule: Every(time.Hour), Run: func(context.Context) error {
+		return errors.New("boom")
+	}}))
+	require.NoError(t, s.Register(Job{Name: "slow", Schedule: Every(time.Hour), Timeout: 10 * time.Millisecond, Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}))
+	require.NoError(t, s.Register(Job{Name: "panicking", Schedule: Every(time.Hour), Run: func(context.Context) error {
+		panic("unexpected")
+	}}))
+	s.ensure(ctx)
+
+	clock.Advance(time.Hour)
+	tickAndWait(ctx, s)
+
+	failing := store.state("failing")
+	assert.Equal(t, StatusFailed, failing.LastStatus)
+	assert.Equal(t, "boom", failing.LastError)
+	assert.EqualValues(t, 1, failing.FailureCount)
+	assert.Equal(t, utc("2026-03-01T02:00:00Z"), failing.NextRunAt)
+
+	slow := store.state("slow")
+	assert.Equal(t, StatusTimedOut, slow.LastStatus)
+	assert.Contains(t, slow.LastError, "deadline exceeded")
+
+	panicking := store.state("panicking")
+	assert.Equal(t, StatusFailed, panicking.LastStatus)
+	assert.Equal(t, "panic: unexpected", panicking.LastError)
+
+	assert.EqualValues(t, 3, s.Stats().Failed)
+}
+
+// TestScheduler_CatchUpAfterDowntime は停止中に過ぎた予定を、再開後に 1 回だけ実行することを検証します。
+func TestScheduler_CatchUpAfterDowntime(t *testing.T) {
+	t.Parallel()
+	mr, rdb := testsupport.NewMiniRedis(t)
+	clock := testsupport.NewClock(utc("2026-03-01T00:00:30Z"))
+	clock.SyncRedis(mr)
+	store := newMemStore()
+	ctx := context.Background()
+
+	var onceRuns, skipRuns atomic.Int64
+	s := newTestScheduler(t, store, infraredis.NewLocker(rdb, "lock"), clock)
+	require.NoError(t, s.Register(Job{Name: "once", Schedule: Every(time.Hour), Run: func(context.Context) error {
+		onceRuns.Add(1)
+		return nil
+	}}))
+	require.NoError(t, s.Register(Job{Name: "skip", Schedule: Every(time.Hour), MissedRuns: SkipMissed, Run: func(context.Context) error {
+		skipRuns.Add(1)
+		return nil
+	}}))
+	s.ensure(ctx)
+
+	clock.Advance(5*time.Hour + 30*time.Minute)
+	tickAndWait(ctx, s)
+	assert.EqualValues(t, 1, onceRuns.Load())
+	assert.Zero(t, skipRuns.Load())
+	assert.Equal(t, utc("2026-03-01T06:00:00Z"), store.state("once").NextRunAt)
+	assert.Equal(t, utc("2026-03-01T06:00:00Z"), store.state("skip").NextRunAt)
+	assert.EqualValues(t, 4+5, s.Stats().Skipped)
+
+	clock.Advance(30 * time.Minute)
+	tickAndWait(ctx, s)
+	assert.EqualValues(t, 2, onceRuns.Load())
+	assert.EqualValues(t, 1, skipRuns.Load())
+}
+
+func TestScheduler_Register(t *testing.T) {
+	t.Parallel()
+	s := New(newMemStore(), nil, Config{})
+	run := func(context.Context) error { return nil }
+
+	require.NoError(t, s.Register(Job{Name: "janitor", Schedule: Every(time.Hour), Run: run}))
+	assert.Error(t, s.Register(Job{Name: "janitor", Schedule: Every(time.Hour), Run: run}), "重複")
+	assert.Error(t, s.Register(Job{Name: "Bad Name", Schedule: Every(time.Hour), Run: run}))
+	assert.Error(t, s.Register(Job{Name: "no-schedule", Run: run}))
+	assert.Error(t, s.Register(Job{Name: "never", Schedule: MustParseCron("0 0 30 2 *"), Run: run}))
+	assert.Error(t, s.Register(Job{Name: "no-run", Schedule: Every(time.Hour)}))
+}
+
+// TestScheduler_Jobs は一覧が登録したジョブの定義と DB の状態を名前順に返すことを検証します。
+func TestScheduler_Jobs(t *testing.T) {
+	t.Parallel()
+	store := newMemStore()
+	clock := testsupport.NewClock(utc("2026-03-01T00:00:30Z"))
+	s := newTestScheduler(t, store, nil, clock)
+	run := func(context.Context) error { return nil }
+	require.NoError(t, s.Register(Job{Name: "pruner", Description: "prune", Schedule: MustParseCron("@daily"), Run: run}))
+	require.NoError(t, s.Register(Job{Name: "janitor", Schedule: Every(time.Hour), Timeout: time.Minute, Run: run}))
+	require.NoError(t, store.Ensure(context.Background(), "janitor", utc("2026-03-01T01:00:00Z")))
+
+	jobs, err := s.Jobs(context.Background())
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, "janitor", jobs[0].Name)
+	assert.Equal(t, "every 1h0m0s", jobs[0].Schedule)
+	assert.Equal(t, time.Minute, jobs[0].Timeout)
+	assert.Equal(t, "pruner", jobs[1].Name)
+	assert.Equal(t, "@daily", jobs[1].Schedule)
+	assert.Equal(t, DefaultJobTimeout, jobs[1].Timeout)
+	assert.Equal(t, utc("2026-03-02T00:00:00Z"), jobs[1].State.NextRunAt, "DB に行がないジョブは次の予定を計算する")
+}
+
+// TestScheduler_Run は Run が起動時に予定を登録し、即時実行の依頼で確認を待たずに実行して、停止時に戻ることを検証します。
+func TestScheduler_Run(t *testing.T) {
+	t.Parallel()
+	_, rdb := testsupport.NewMiniRedis(t)
+	store := newMemStore()
+	s := New(store, infraredis.NewLocker(rdb, "lock"), Config{TickInterval: time.Hour})
+	ran := make(chan struct{}, 1)
+	require.NoError(t, s.Register(Job{Name: "janitor", Schedule: Every(24 * time.Hour), Run: func(context.Context) error {
+		ran <- struct{}{}
+		return nil
+	}}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+	require.Eventually(t, func() bool { return store.state("janitor").Name != "" }, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, s.RunNow(ctx, "janitor"))
+	select {
+	case <-ran:
+	case <-time.After(2 * time.Second):
+		t.Fatal("run-now did not wake the scheduler")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+}
+
+// pauseSwitch は切り替えられる Pauser です。
+type pauseSwitch struct{ atomic.Bool }
+
+func (p *pauseSwitch) Active(context.Context) bool { return p.Load() }
+
+// TestScheduler_Pause は停止中は予定を過ぎても実行せず、再開後に 1 回にまとめて実行することを検証します。
+func TestScheduler_Pause(t *testing.T) {
+	t.Parallel()
+	mr, rdb := testsupport.NewMiniRedis(t)
+	clock := testsupport.NewClock(utc("2026-03-01T00:00:30Z"))
+	clock.SyncRedis(mr)
+	store := newMemStore()
+	ctx := context.Background()
+
+	var runs atomic.Int64
+	pause := &pauseSwitch{}
+	s := newTestScheduler(t, store, infraredis.NewLocker(rdb, "lock"), clock).WithPause(pause)
+	require.NoError(t, s.Register(Job{Name: "janitor", Schedule: Every(10 * time.Minute), Run: func(context.Context) error {
+		runs.Add(1)
+		return nil
+	}}))
+	s.ensure(ctx)
+
+	pause.Store(true)
+	for range 3 {
+		clock.Advance(10 * time.Minute)
+		tickAndWait(ctx, s)
+	}
+	assert.Zero(t, runs.Load())
+	assert.Equal(t, utc("2026-03-01T00:10:00Z"), store.state("janitor").NextRunAt, "停止中は予定を進めない")
+
+	pause.Store(false)
+	tickAndWait(ctx, s)
+	assert.EqualValues(t, 1, runs.Load())
+	assert.Equal(t, utc("2026-03-01T00:40:00Z"), store.state("janitor").NextRunAt)
+}
@@ -0,0 +1,54 @@
+-- name: EnsureJob :exec
+-- 未登録のジョブだけ登録する（登録済みの予定・結果は変えない）。
+INSERT INTO scheduled_jobs (name, next_run_at)
+VALUES ($1, $2)
+ON CONFLICT (name) DO NOTHING;
+
+-- name: ListJobs :many
+SELECT name, next_run_at, run_requested_at, last_started_at, last_finished_at,
+       last_status, last_error, last_duration_ms, run_count, failure_count
+FROM scheduled_jobs
+ORDER BY name;
+
+-- name: GetJob :one
+SELECT name, next_run_at, run_requested_at, last_started_at, last_finished_at,
+       last_status, last_error, last_duration_ms, run_count, failure_count
+FROM scheduled_jobs
+WHERE name = $1;
+
+-- name: StartJob :execrows
+-- 読み込んだ時点の予定（next_run_at・run_requested_at）から変わっていない場合のみ、次の予定に進めて開始を記録する。
+UPDATE scheduled_jobs
+SET next_run_at = sqlc.arg(next_run_at),
+    run_requested_at = NULL,
+    last_started_at = sqlc.arg(started_at),
+    updated_at = now()
+WHERE name = sqlc.arg(name)
+  AND next_run_at = sqlc.arg(expected_next_run_at)
+  AND run_requested_at IS NOT DISTINCT FROM sqlc.narg(expected_run_requested_at);
+
+-- name: RescheduleJob :execrows
+-- 読み込んだ時点の予定から変わっていない場合のみ、実行せずに次の予定を変える（見送った予定・スケジュールの変更）。
+UPDATE scheduled_jobs
+SET next_run_at = sqlc.arg(next_run_at),
+    updated_at = now()
+WHERE name = sqlc.arg(name)
+  AND next_run_at = sqlc.arg(expected_next_run_at);
+
+-- name: FinishJob :exec
+UPDATE scheduled_jobs
+SET last_finished_at = sqlc.arg(finished_at),
+    last_status = sqlc.arg(status),
+    last_error = sqlc.narg(error),
+    last_duration_ms = sqlc.arg(duration_ms),
+    run_count = run_count + 1,
+    failure_count = failure_count + CASE WHEN sqlc.arg(status)::varchar = 'succeeded' THEN 0 ELSE 1 END,
+    updated_at = now()
+WHERE name = sqlc.arg(name);
+
+-- name: RequestJobRun :execrows
+-- 即時実行を依頼する。依頼済みの場合は最初の依頼の日時を残す。
+UPDATE scheduled_jobs
+SET run_requested_at = COALESCE(run_requested_at, sqlc.arg(requested_at)),
+    updated_at = now()
+WHERE name = sqlc.arg(name);
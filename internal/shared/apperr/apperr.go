@@ -0,0 +1,140 @@
+// Package apperr はフィーチャー横断で使う型付きドメインエラーを提供します。
+//
+// 各フィーチャーはセンチネルエラーを apperr.New で定義し、エラーの種類（Kind）を宣言します。
+// HTTP 層は Kind からステータスコードを一括で決定するため（httpx.ErrorStatus 参照）、
+// 新しいエラーを追加してもハンドラー側の switch を増やす必要はありません。
+// センチネルはポインタ値のため、既存の errors.Is による判定はそのまま機能します。
+package apperr
+
+import (
+	"errors"
+	"strings"
+)
+
+// Kind はエラーの分類です。ゼロ値は KindInternal で、未分類のエラーは内部エラーとして扱われます。
+type Kind uint8
+
+const (
+	// KindInternal は内部エラー（想定外の失敗）です。クライアントに詳細は返しません。
+	KindInternal Kind = iota
+	// KindNotFound は対象リソースが存在しないことを表します。
+	KindNotFound
+	// KindInvalid はリクエスト内容が不正であることを表します。
+	KindInvalid
+	// KindConflict は既存の状態と競合することを表します。
+	KindConflict
+	// KindUnauthorized は認証に失敗したことを表します。
+	KindUnauthorized
+	// KindUpstream は外部サービス（上流 API・OAuth プロバイダー等）起因の失敗を表します。
+	KindUpstream
+	// KindUnavailable は一時的に処理できない（上流の利用枠の枯渇等）ことを表します。時間をおいて再試行できます。
+	KindUnavailable
+	// KindTimeout は処理が時間の上限（DB の statement_timeout 等）を超えて打ち切られたことを表します。
+	KindTimeout
+	// KindRateLimited は利用者ごとの利用回数の上限（日次の利用枠等）に達したことを表します。上限の回復後に再試行できます。
+	KindRateLimited
+	// KindPreconditionFailed は更新の前提条件（クライアントが見たバージョン）が現在の状態と一致しないことを表します。
+	// 最新の状態を取得し直してから再試行します。
+	KindPreconditionFailed
+	// KindPaymentRequired は利用者の料金プランでは利用できない（上位のプランへの変更が必要な）ことを表します。
+	KindPaymentRequired
+	// KindUnprocessable はリクエストの形式は正しいが、参照先や現在の状態のために処理できない
+	// （存在しない・扱えない銘柄の指定、件数の上限に達した追加等）ことを表します。
+	KindUnprocessable
+)
+
+// String は Kind の名前を返します。ログ出力用です。
+func (k Kind) String() string {
+	switch k {
+	case KindInternal:
+		return "internal"
+	case KindNotFound:
+		return "not_found"
+	case KindInvalid:
+		return "invalid"
+	case KindConflict:
+		return "conflict"
+	case KindUnauthorized:
+		return "unauthorized"
+	case KindUpstream:
+		return "upstream"
+	case KindUnavailable:
+		return "unavailable"
+	case KindTimeout:
+		return "timeout"
+	case KindRateLimited:
+		return "rate_limited"
+	case KindPreconditionFailed:
+		return "precondition_failed"
+	case KindPaymentRequired:
+		return "payment_required"
+	case KindUnprocessable:
+		return "unprocessable"
+	default:
+		return "unknown"
+	}
+}
+
+// Error は Kind と公開用コードを持つドメインエラーです。
+//
+// Code はレスポンスの error フィールドにそのまま返される、クライアント向けの安定した文字列です。
+// Message は Error() が返す内部向けの説明で、ログに出力されます。
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Err     error
+}
+
+// New は原因を持たない Error を生成します。センチネルエラーの定義に使います。
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// Wrap は err を原因として持つ Error を生成します。err が nil の場合は nil を返します。
+func Wrap(kind Kind, code string, err error) *Error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Code: code, Message: err.Error(), Err: err}
+}
+
+// Error は内部向けの説明を返します。
+func (e *Error) Error() string {
+	if e.Err != nil && e.Message != e.Err.Error() {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap は原因となったエラーを返します。
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// As は err のチェーンから最初に見つかった *Error を返します。
+func As(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// KindOf は err の Kind を返します。*Error を含まない場合は KindInternal を返します。
+func KindOf(err error) Kind {
+	if e, ok := As(err); ok {
+		return e.Kind
+	}
+	return KindInternal
+}
+
+// Truncate は err のメッセージを maxBytes バイトまでに切り詰めて返します。
+// DB のエラー記録用の列に残す用途を想定し、切り詰めで壊れた末尾の UTF-8 は取り除きます。
+func Truncate(err error, maxBytes int) string {
+	s := err.Error()
+	if len(s) > maxBytes {
+		s = strings.ToValidUTF8(s[:maxBytes], "")
+	}
+	return s
+}
@@ -0,0 +1,96 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestError_IsCompatibility(t *testing.T) {
+	sentinel := New(KindNotFound, "symbol_not_found", "symbol not found")
+	other := New(KindNotFound, "symbol_not_found", "symbol not found")
+
+	wrapped := fmt.Errorf("get candles: %w", sentinel)
+	if !errors.Is(wrapped, sentinel) {
+		t.Error("errors.Is(wrapped, sentinel)=false, want true")
+	}
+	// 同じ Kind・Code でも別のセンチネルとは一致しない（フィーチャー間の取り違えを防ぐ）
+	if errors.Is(wrapped, other) {
+		t.Error("errors.Is(wrapped, other)=true, want false")
+	}
+	if got := wrapped.Error(); got != "get candles: symbol not found" {
+		t.Errorf("Error()=%q, want %q", got, "get candles: symbol not found")
+	}
+}
+
+func TestWrap(t *testing.T) {
+	if Wrap(KindUpstream, "upstream_failed", nil) != nil {
+		t.Fatal("Wrap(nil) should return nil")
+	}
+
+	cause := errors.New("connection reset")
+	err := Wrap(KindUpstream, "upstream_failed", cause)
+	if !errors.Is(err, cause) {
+		t.Error("errors.Is(err, cause)=false, want true")
+	}
+	if got := err.Error(); got != "connection reset" {
+		t.Errorf("Error()=%q, want %q", got, "connection reset")
+	}
+
+	described := &Error{Kind: KindUpstream, Code: "upstream_failed", Message: "fetch quotes", Err: cause}
+	if got := described.Error(); got != "fetch quotes: connection reset" {
+		t.Errorf("Error()=%q, want %q", got, "fetch quotes: connection reset")
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want Kind
+	}{
+		{name: "plain error is internal", err: errors.New("boom"), want: KindInternal},
+		{name: "nil is internal", err: nil, want: KindInternal},
+		{name: "direct", err: New(KindConflict, "c", "m"), want: KindConflict},
+		{name: "wrapped", err: fmt.Errorf("ctx: %w", New(KindInvalid, "c", "m")), want: KindInvalid},
+		{name: "joined", err: errors.Join(errors.New("a"), New(KindUnauthorized, "c", "m")), want: KindUnauthorized},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := KindOf(tc.err); got != tc.want {
+				t.Errorf("KindOf()=%v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestKind_String(t *testing.T) {
+	if got := Kind(0).String(); got != "internal" {
+		t.Errorf("zero Kind String()=%q, want internal", got)
+	}
+	if got := Kind(200).String(); got != "unknown" {
+		t.Errorf("undefined Kind String()=%q, want unknown", got)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		max  int
+		want string
+	}{
+		{name: "short message is kept", err: errors.New("boom"), max: 10, want: "boom"},
+		{name: "exact length is kept", err: errors.New("0123456789"), max: 10, want: "0123456789"},
+		{name: "long message is cut", err: errors.New("0123456789abc"), max: 10, want: "0123456789"},
+		// 「あ」は 3 バイト。途中で切れた文字は取り除く
+		{name: "broken multibyte rune is dropped", err: errors.New("ああ"), max: 4, want: "あ"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Truncate(tc.err, tc.max); got != tc.want {
+				t.Errorf("Truncate()=%q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+// Package buildinfo はビルド時に埋め込むバイナリの情報（バージョン・git のコミット・ビルド日時）を提供します。
+//
+// 値は -ldflags で埋め込みます（docker/Dockerfile.* と CD のワークフローが設定します）。
+//
+//	go build -ldflags "-X github.com/UCHIDAnobuhiro/stock-backend/internal/shared/buildinfo.version=v1.2.3 \
+//	  -X github.com/UCHIDAnobuhiro/stock-backend/internal/shared/buildinfo.commit=$(git rev-parse HEAD) \
+//	  -X github.com/UCHIDAnobuhiro/stock-backend/internal/shared/buildinfo.buildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/api
+//
+// 埋め込まなかった値（go run・go test・ローカルのビルド）は Unknown（"dev"）になります。
+package buildinfo
+
+import (
+	"log/slog"
+	"runtime"
+)
+
+// Unknown は -ldflags で埋め込まなかった値です。
+const Unknown = "dev"
+
+// -ldflags "-X" で上書きする値。
+var (
+	version   = Unknown
+	commit    = Unknown
+	buildTime = Unknown
+)
+
+// Info はバイナリのビルド情報です。秘密情報は含まないため、公開のエンドポイントでそのまま返せます。
+type Info struct {
+	Version   string // リリースのタグ（例: v1.2.3）
+	Commit    string // git のコミット SHA
+	BuildTime string // ビルド日時（RFC 3339、UTC）
+	GoVersion string // ビルドに使った Go のバージョン
+}
+
+// Get は実行中のバイナリのビルド情報を返します。
+func Get() Info {
+	return Info{Version: version, Commit: commit, BuildTime: buildTime, GoVersion: runtime.Version()}
+}
+
+// ShortCommit はコミット SHA の先頭 12 文字を返します。埋め込まれていない場合は Unknown です。
+func (i Info) ShortCommit() string {
+	if len(i.Commit) > 12 {
+		return i.Commit[:12]
+	}
+	return i.Commit
+}
+
+// LogAttrs は起動時のログなどに付ける属性を返します。
+func (i Info) LogAttrs() []any {
+	return []any{
+		slog.String("version", i.Version),
+		slog.String("commit", i.Commit),
+		slog.String("build_time", i.BuildTime),
+		slog.String("go_version", i.GoVersion),
+	}
+}
+
+// ServerHeader は Server レスポンスヘッダーの値（例: stock-backend/v1.2.3 (0123456789ab)）を返します。
+func (i Info) ServerHeader() string {
+	return "stock-backend/" + i.Version + " (" + i.ShortCommit() + ")"
+}
@@ -0,0 +1,36 @@
+package buildinfo
+
+import (
+	"runtime"
+	"testing"
+)
+
+// TestGet_Defaults は -ldflags で埋め込まない場合に各値が "dev" になることを検証します。
+func TestGet_Defaults(t *testing.T) {
+	got := Get()
+	want := Info{Version: "dev", Commit: "dev", BuildTime: "dev", GoVersion: runtime.Version()}
+	if got != want {
+		t.Errorf("Get() = %+v, want %+v", got, want)
+	}
+	if got.ServerHeader() != "stock-backend/dev (dev)" {
+		t.Errorf("ServerHeader() = %q", got.ServerHeader())
+	}
+}
+
+func TestInfo_ShortCommit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		commit string
+		want   string
+	}{
+		{"0123456789abcdef0123456789abcdef01234567", "0123456789ab"},
+		{"0123456", "0123456"},
+		{Unknown, Unknown},
+	}
+	for _, tt := range tests {
+		if got := (Info{Commit: tt.commit}).ShortCommit(); got != tt.want {
+			t.Errorf("ShortCommit(%q) = %q, want %q", tt.commit, got, tt.want)
+		}
+	}
+}
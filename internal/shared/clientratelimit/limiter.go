@@ -4,14 +4,18 @@ package clientratelimit
 import (
 	"context"
 	"log/slog"
+	"sync"
 	"time"
 )
 
 // RateLimiter はAPI呼び出しなどの操作頻度を制限します。
-// 並行呼び出し非対応です。複数 goroutine から呼び出す場合は呼び出し側で排他制御してください。
+// 複数 goroutine から呼び出せます。WaitIfNeeded は呼び出し順に直列化され、待機中の呼び出しの後ろに並びます。
 type RateLimiter struct {
-	limit     int           // インターバルあたりの最大操作回数
-	interval  time.Duration // カウンターをリセットする時間間隔
+	limit    int           // インターバルあたりの最大操作回数
+	interval time.Duration // カウンターをリセットする時間間隔
+
+	turn      chan struct{} // WaitIfNeeded を直列化する順番（容量 1。待機中も保持し、順番待ちも ctx で打ち切れる）
+	mu        sync.Mutex    // count / lastReset を保護する（待機中は保持しないため NextSlot は待たされない）
 	count     int
 	lastReset time.Time
 }
@@ -21,13 +25,28 @@ func NewRateLimiter(limit int, interval time.Duration) *RateLimiter {
 	return &RateLimiter{
 		limit:     limit,
 		interval:  interval,
+		turn:      make(chan struct{}, 1),
 		lastReset: time.Now(),
 	}
 }
 
 // WaitIfNeeded はレートリミットに達しているか確認し、必要に応じて待機します。
-// ctx がキャンセル/タイムアウトした場合は待機を中断し ctx.Err() を返します。
+// ctx がキャンセル/タイムアウトした場合は待機（他の呼び出しの待機の後ろでの順番待ちを含む）を中断し ctx.Err() を返します。
 func (rl *RateLimiter) WaitIfNeeded(ctx context.Context) error {
+	// 他の呼び出しが待機している間は順番を待つ（sync.Mutex と違い、順番待ちの間も ctx の終了で抜けられる）。
+	// 順番がすぐに回ってくる場合は ctx が終了していても取得する（limit 未到達なら従来どおり nil を返すため）
+	select {
+	case rl.turn <- struct{}{}:
+	default:
+		select {
+		case rl.turn <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	defer func() { <-rl.turn }()
+
+	rl.mu.Lock()
 	now := time.Now()
 	// インターバルが経過していればカウンターをリセット
 	if now.Sub(rl.lastReset) >= rl.interval {
@@ -36,8 +55,11 @@ func (rl *RateLimiter) WaitIfNeeded(ctx context.Context) error {
 	}
 
 	rl.count++
-	if rl.count > rl.limit {
-		sleep := rl.interval - now.Sub(rl.lastReset)
+	over := rl.count > rl.limit
+	sleep := rl.interval - now.Sub(rl.lastReset)
+	rl.mu.Unlock()
+
+	if over {
 		if sleep > 0 {
 			slog.Info("rate limit reached, sleeping", "limit", rl.limit, "sleep_duration", sleep)
 			timer := time.NewTimer(sleep)
@@ -47,13 +69,29 @@ func (rl *RateLimiter) WaitIfNeeded(ctx context.Context) error {
 			case <-ctx.Done():
 				// 待機を完了せず抜けるため、増分済みのカウンタを巻き戻して呼び出しが
 				// 発生しなかった状態に戻す（同一インスタンスを別 ctx で再利用する場合の状態破壊を防ぐ）。
+				rl.mu.Lock()
 				rl.count--
+				rl.mu.Unlock()
 				return ctx.Err()
 			}
 		}
 		// 待機後にリセット
+		rl.mu.Lock()
 		rl.count = 1
 		rl.lastReset = time.Now()
+		rl.mu.Unlock()
 	}
 	return nil
 }
+
+// NextSlot は次の操作を待機なしで実行できるまでの時間を返します（すぐに実行できる場合は 0）。
+// 上流から待機時間が返されなかった場合の目安として使います。カウンターは変更しません。
+func (rl *RateLimiter) NextSlot() time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	elapsed := time.Since(rl.lastReset)
+	if rl.count < rl.limit || elapsed >= rl.interval {
+		return 0
+	}
+	return rl.interval - elapsed
+}
@@ -3,6 +3,7 @@ package clientratelimit
 import (
 	"context"
 	"errors"
+	"sync"
 	"testing"
 	"time"
 )
@@ -193,3 +194,111 @@ func TestRateLimiter_WaitIfNeeded_CountRollbackOnCancel(t *testing.T) {
 		t.Errorf("count = %d, want %d (ctx キャンセル時のロールバックが効いていない)", rl.count, countBefore)
 	}
 }
+
+// TestRateLimiter_NextSlot は limit 到達前は 0、到達後はインターバルの残り時間を返し、カウンターを変えないことを検証します。
+func TestRateLimiter_NextSlot(t *testing.T) {
+	interval := time.Minute
+	rl := NewRateLimiter(2, interval)
+	if got := rl.NextSlot(); got != 0 {
+		t.Errorf("NextSlot before any call = %v, want 0", got)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := rl.WaitIfNeeded(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	got := rl.NextSlot()
+	if got <= 0 || got > interval {
+		t.Errorf("NextSlot at limit = %v, want (0, %v]", got, interval)
+	}
+	if rl.count != 2 {
+		t.Errorf("count = %d, want 2 (NextSlot must not consume a slot)", rl.count)
+	}
+
+	rl.lastReset = time.Now().Add(-interval)
+	if got := rl.NextSlot(); got != 0 {
+		t.Errorf("NextSlot after interval = %v, want 0", got)
+	}
+}
+
+// TestRateLimiter_WaitIfNeeded_Concurrent は複数 goroutine から呼び出しても limit を超えて通さないことを検証します
+// （待機中に NextSlot が待たされないことも確認します）。
+func TestRateLimiter_WaitIfNeeded_Concurrent(t *testing.T) {
+	interval := 200 * time.Millisecond
+	rl := NewRateLimiter(3, interval)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := rl.WaitIfNeeded(context.Background()); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	// 4 回目以降は次のインターバルまで待機する。待機中も NextSlot はすぐに返る
+	time.Sleep(20 * time.Millisecond)
+	done := make(chan struct{})
+	go func() {
+		rl.NextSlot()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(100 * time.Millisecond):
+		t.Error("NextSlot blocked while another call was waiting")
+	}
+	wg.Wait()
+
+	if elapsed := time.Since(start); elapsed < interval-20*time.Millisecond {
+		t.Errorf("6 calls with limit 3 finished in %v, want >= %v", elapsed, interval)
+	}
+	if rl.count != 3 {
+		t.Errorf("count = %d, want 3 (3 calls in the second interval)", rl.count)
+	}
+}
+
+// TestRateLimiter_WaitIfNeeded_CancelWhileQueued は他の呼び出しが待機している間に順番を待つ呼び出しも、
+// ctx の終了から数ミリ秒で抜け、待機中の呼び出しとカウンタに影響しないことを検証します。
+func TestRateLimiter_WaitIfNeeded_CancelWhileQueued(t *testing.T) {
+	interval := 300 * time.Millisecond
+	rl := NewRateLimiter(1, interval)
+	if err := rl.WaitIfNeeded(context.Background()); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	// 2 回目は次のインターバルまで待機する
+	firstDone := make(chan error, 1)
+	go func() { firstDone <- rl.WaitIfNeeded(context.Background()) }()
+	time.Sleep(20 * time.Millisecond)
+
+	// 3 回目は 2 回目の後ろで順番を待ち、その間に cancel される
+	ctx, cancel := context.WithCancel(context.Background())
+	queuedDone := make(chan error, 1)
+	go func() { queuedDone <- rl.WaitIfNeeded(ctx) }()
+	time.Sleep(20 * time.Millisecond)
+
+	start := time.Now()
+	cancel()
+	select {
+	case err := <-queuedDone:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("err = %v, want context.Canceled", err)
+		}
+		if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+			t.Errorf("queued call returned %v after cancel, want within a few milliseconds", elapsed)
+		}
+	case <-time.After(interval):
+		t.Fatal("queued call did not return after cancel")
+	}
+
+	if err := <-firstDone; err != nil {
+		t.Fatalf("waiting call failed: %v", err)
+	}
+	if rl.count != 1 {
+		t.Errorf("count = %d, want 1 (the cancelled call must not consume a slot)", rl.count)
+	}
+}
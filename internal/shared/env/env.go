@@ -0,0 +1,162 @@
+// Package env は環境変数を型付きで読み取る Reader を提供します。
+//
+// 未設定と空文字はどちらも「指定なし」としてデフォルト値を返し、値が指定されていて
+// 解釈できない場合はデフォルト値に黙って戻さず、エラーを蓄積します（例: REDIS_POOL_SIZE=ten が
+// 0 や既定値として起動してしまうのを防ぐため）。呼び出し側（設定ローダー）は読み取りを
+// 終えたあとに Err でまとめて確認します。
+//
+// 値の取得元は LookupFunc として注入するため、テストは実際の環境変数に触れずに map から読み取れます。
+package env
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrRequired は必須の環境変数が未設定（または空文字）であることを示します。
+var ErrRequired = errors.New("is required")
+
+// LookupFunc は環境変数の値と、設定されているかどうかを返します（os.LookupEnv と同じ形）。
+type LookupFunc func(key string) (string, bool)
+
+// MapLookup は m から読み取る LookupFunc を返します（テスト用）。
+func MapLookup(m map[string]string) LookupFunc {
+	return func(key string) (string, bool) {
+		v, ok := m[key]
+		return v, ok
+	}
+}
+
+// Error は 1 つの環境変数の欠落・不正値です。
+type Error struct {
+	Key   string
+	Value string
+	Err   error
+}
+
+func (e *Error) Error() string {
+	switch {
+	case errors.Is(e.Err, ErrRequired):
+		return e.Key + " " + e.Err.Error()
+	case e.Value == "":
+		return e.Key + ": " + e.Err.Error()
+	default:
+		return fmt.Sprintf("%s=%q: %v", e.Key, e.Value, e.Err)
+	}
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Reader は LookupFunc から型付きの値を読み取り、欠落・不正値のエラーを蓄積します。
+// ゴルーチン間で共有しないでください。
+type Reader struct {
+	lookup LookupFunc
+	errs   []error
+}
+
+// NewReader は lookup から読み取る Reader を返します。
+func NewReader(lookup LookupFunc) *Reader {
+	return &Reader{lookup: lookup}
+}
+
+// OS はプロセスの環境変数（os.LookupEnv）から読み取る Reader を返します。
+func OS() *Reader {
+	return NewReader(os.LookupEnv)
+}
+
+// Err は蓄積したエラーを errors.Join でまとめて返します。エラーがなければ nil です。
+func (r *Reader) Err() error {
+	return errors.Join(r.errs...)
+}
+
+// Invalid は key の値が不正であることを記録します。型としては解釈できるが範囲外の値など、
+// 呼び出し側での検証に失敗した場合に使います。
+func (r *Reader) Invalid(key string, err error) {
+	raw, _ := r.lookup(key)
+	r.errs = append(r.errs, &Error{Key: key, Value: raw, Err: err})
+}
+
+// Lookup は生の値と、設定されているかどうかを返します。
+// 空文字の明示と未設定を区別する必要がある場合（例: CACHE_NAMESPACE）に使います。
+func (r *Reader) Lookup(key string) (string, bool) {
+	return r.lookup(key)
+}
+
+// String は値をそのまま（前後の空白も含めて）返します。未設定・空文字なら def を返します。
+func (r *Reader) String(key, def string) string {
+	if v, ok := r.lookup(key); ok && v != "" {
+		return v
+	}
+	return def
+}
+
+// Require は値を返します。未設定・空文字ならエラーを蓄積して空文字を返します。
+func (r *Reader) Require(key string) string {
+	v, ok := r.lookup(key)
+	if !ok || v == "" {
+		r.errs = append(r.errs, &Error{Key: key, Err: ErrRequired})
+		return ""
+	}
+	return v
+}
+
+// Int は整数を返します。未設定・空白のみなら def、解釈できなければエラーを蓄積して def を返します。
+func (r *Reader) Int(key string, def int) int {
+	return parse(r, key, def, strconv.Atoi)
+}
+
+// Float は浮動小数点数を返します。未設定・空白のみなら def、解釈できなければエラーを蓄積して def を返します。
+func (r *Reader) Float(key string, def float64) float64 {
+	return parse(r, key, def, func(s string) (float64, error) { return strconv.ParseFloat(s, 64) })
+}
+
+// Bool は strconv.ParseBool 形式（true / false / 1 / 0 など）の真偽値を返します。
+// 未設定・空白のみなら def、解釈できなければエラーを蓄積して def を返します。
+func (r *Reader) Bool(key string, def bool) bool {
+	return parse(r, key, def, strconv.ParseBool)
+}
+
+// Duration は time.ParseDuration 形式（例: "30s"）の期間を返します。
+// 未設定・空白のみなら def、解釈できなければエラーを蓄積して def を返します。
+func (r *Reader) Duration(key string, def time.Duration) time.Duration {
+	return parse(r, key, def, time.ParseDuration)
+}
+
+// StringSlice はカンマ区切りの値を、各要素の前後の空白を除き空要素を除いて返します。
+// 未設定・有効な要素がない場合は def を返します。
+func (r *Reader) StringSlice(key string, def []string) []string {
+	v, _ := r.lookup(key)
+	var items []string
+	for p := range strings.SplitSeq(v, ",") {
+		if trimmed := strings.TrimSpace(p); trimmed != "" {
+			items = append(items, trimmed)
+		}
+	}
+	if len(items) == 0 {
+		return def
+	}
+	return items
+}
+
+// parse は前後の空白を除いた値を fn で解釈します。strconv のエラーは構文エラー・範囲外のみを残して簡潔にします。
+func parse[T any](r *Reader, key string, def T, fn func(string) (T, error)) T {
+	raw, _ := r.lookup(key)
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return def
+	}
+	v, err := fn(s)
+	if err != nil {
+		var numErr *strconv.NumError
+		if errors.As(err, &numErr) {
+			err = numErr.Err
+		}
+		r.errs = append(r.errs, &Error{Key: key, Value: raw, Err: err})
+		return def
+	}
+	return v
+}
@@ -0,0 +1,255 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readOne は key=raw（set=false なら未設定）の Reader で read を呼び、値と蓄積したエラーを返します。
+func readOne[T any](raw string, set bool, read func(r *Reader) T) (T, error) {
+	m := map[string]string{}
+	if set {
+		m["KEY"] = raw
+	}
+	r := NewReader(MapLookup(m))
+	v := read(r)
+	return v, r.Err()
+}
+
+func TestReader_Int(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		raw     string
+		set     bool
+		want    int
+		wantErr error
+	}{
+		{"未設定はデフォルト", "", false, 7, nil},
+		{"空文字はデフォルト", "", true, 7, nil},
+		{"空白のみはデフォルト", "  ", true, 7, nil},
+		{"整数", "42", true, 42, nil},
+		{"前後の空白は除く", " 42 ", true, 42, nil},
+		{"負の数", "-3", true, -3, nil},
+		{"数字以外", "ten", true, 7, strconv.ErrSyntax},
+		{"小数", "1.5", true, 7, strconv.ErrSyntax},
+		{"単位付き", "10s", true, 7, strconv.ErrSyntax},
+		{"範囲外", "99999999999999999999", true, 7, strconv.ErrRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := readOne(tt.raw, tt.set, func(r *Reader) int { return r.Int("KEY", 7) })
+			assert.Equal(t, tt.want, got)
+			assertErr(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestReader_Float(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		raw     string
+		set     bool
+		want    float64
+		wantErr error
+	}{
+		{"未設定はデフォルト", "", false, 0.2, nil},
+		{"空白のみはデフォルト", " ", true, 0.2, nil},
+		{"小数", "0.45", true, 0.45, nil},
+		{"整数", "1", true, 1, nil},
+		{"数字以外", "half", true, 0.2, strconv.ErrSyntax},
+		{"カンマ区切りの小数", "0,5", true, 0.2, strconv.ErrSyntax},
+		{"範囲外", "1e400", true, 0.2, strconv.ErrRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := readOne(tt.raw, tt.set, func(r *Reader) float64 { return r.Float("KEY", 0.2) })
+			assert.Equal(t, tt.want, got)
+			assertErr(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestReader_Bool(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		raw     string
+		set     bool
+		def     bool
+		want    bool
+		wantErr error
+	}{
+		{"未設定はデフォルト", "", false, true, true, nil},
+		{"空文字はデフォルト", "", true, true, true, nil},
+		{"true", "true", true, false, true, nil},
+		{"大文字の TRUE", "TRUE", true, false, true, nil},
+		{"1", "1", true, false, true, nil},
+		{"false", "false", true, true, false, nil},
+		{"0", "0", true, true, false, nil},
+		{"前後の空白は除く", " false ", true, true, false, nil},
+		{"yes は不正", "yes", true, true, true, strconv.ErrSyntax},
+		{"on は不正", "on", true, false, false, strconv.ErrSyntax},
+		{"2 は不正", "2", true, false, false, strconv.ErrSyntax},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := readOne(tt.raw, tt.set, func(r *Reader) bool { return r.Bool("KEY", tt.def) })
+			assert.Equal(t, tt.want, got)
+			assertErr(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestReader_Duration(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		raw     string
+		set     bool
+		want    time.Duration
+		wantErr bool
+	}{
+		{"未設定はデフォルト", "", false, time.Minute, false},
+		{"空白のみはデフォルト", "\t", true, time.Minute, false},
+		{"秒", "30s", true, 30 * time.Second, false},
+		{"複合", "1h30m", true, 90 * time.Minute, false},
+		{"ミリ秒", "1500ms", true, 1500 * time.Millisecond, false},
+		{"0", "0", true, 0, false},
+		{"負の期間", "-1s", true, -time.Second, false},
+		{"単位なし", "30", true, time.Minute, true},
+		{"未知の単位", "3d", true, time.Minute, true},
+		{"文字列", "soon", true, time.Minute, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := readOne(tt.raw, tt.set, func(r *Reader) time.Duration { return r.Duration("KEY", time.Minute) })
+			assert.Equal(t, tt.want, got)
+			if tt.wantErr {
+				var envErr *Error
+				require.ErrorAs(t, err, &envErr)
+				assert.Equal(t, "KEY", envErr.Key)
+				assert.Equal(t, tt.raw, envErr.Value)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestReader_String(t *testing.T) {
+	t.Parallel()
+
+	r := NewReader(MapLookup(map[string]string{"EMPTY": "", "SPACED": " v "}))
+	assert.Equal(t, "def", r.String("UNSET", "def"))
+	assert.Equal(t, "def", r.String("EMPTY", "def"))
+	assert.Equal(t, " v ", r.String("SPACED", "def"), "値は加工しない（パスワード等の空白を保つ）")
+	assert.NoError(t, r.Err())
+}
+
+func TestReader_StringSlice(t *testing.T) {
+	t.Parallel()
+
+	def := []string{"JPY", "USD"}
+	tests := []struct {
+		name string
+		raw  string
+		set  bool
+		want []string
+	}{
+		{"未設定はデフォルト", "", false, def},
+		{"空文字はデフォルト", "", true, def},
+		{"カンマのみはデフォルト", " , ,", true, def},
+		{"1 要素", "EUR", true, []string{"EUR"}},
+		{"空白と空要素を除く", " jpy, ,usd ,", true, []string{"jpy", "usd"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := readOne(tt.raw, tt.set, func(r *Reader) []string { return r.StringSlice("KEY", def) })
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestReader_Require(t *testing.T) {
+	t.Parallel()
+
+	r := NewReader(MapLookup(map[string]string{"SET": "secret", "EMPTY": ""}))
+	assert.Equal(t, "secret", r.Require("SET"))
+	assert.Empty(t, r.Require("EMPTY"))
+	assert.Empty(t, r.Require("UNSET"))
+
+	err := r.Err()
+	require.ErrorIs(t, err, ErrRequired)
+	assert.Equal(t, "EMPTY is required\nUNSET is required", err.Error())
+}
+
+func TestReader_Lookup(t *testing.T) {
+	t.Parallel()
+
+	r := NewReader(MapLookup(map[string]string{"EMPTY": ""}))
+	v, ok := r.Lookup("EMPTY")
+	assert.True(t, ok, "空文字の明示は設定ありとして区別する")
+	assert.Empty(t, v)
+	_, ok = r.Lookup("UNSET")
+	assert.False(t, ok)
+}
+
+func TestReader_Err(t *testing.T) {
+	t.Parallel()
+
+	r := NewReader(MapLookup(map[string]string{
+		"POOL_SIZE": "ten",
+		"TIMEOUT":   "soon",
+		"RATE":      "2",
+		"OK":        "3",
+	}))
+	assert.NoError(t, r.Err(), "読み取り前はエラーなし")
+
+	assert.Equal(t, 10, r.Int("POOL_SIZE", 10))
+	assert.Equal(t, time.Second, r.Duration("TIMEOUT", time.Second))
+	assert.Equal(t, 3, r.Int("OK", 1))
+	if rate := r.Float("RATE", 0.2); rate > 1 {
+		r.Invalid("RATE", errors.New("must be between 0 and 1"))
+	}
+	r.Invalid("OAUTH_SECRET", fmt.Errorf("%w when OAUTH_ID is set", ErrRequired))
+
+	// 全てのエラーを読み取り順に 1 つにまとめる
+	err := r.Err()
+	assert.Equal(t, `POOL_SIZE="ten": invalid syntax`+"\n"+
+		`TIMEOUT="soon": time: invalid duration "soon"`+"\n"+
+		`RATE="2": must be between 0 and 1`+"\n"+
+		`OAUTH_SECRET is required when OAUTH_ID is set`, err.Error())
+	assert.ErrorIs(t, err, strconv.ErrSyntax)
+	assert.ErrorIs(t, err, ErrRequired)
+}
+
+// assertErr は want が nil ならエラーなし、そうでなければ want を含む *Error であることを確認します。
+func assertErr(t *testing.T, err, want error) {
+	t.Helper()
+	if want == nil {
+		assert.NoError(t, err)
+		return
+	}
+	var envErr *Error
+	require.ErrorAs(t, err, &envErr)
+	assert.Equal(t, "KEY", envErr.Key)
+	assert.ErrorIs(t, err, want)
+}
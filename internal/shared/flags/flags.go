@@ -0,0 +1,209 @@
+// Package flags は段階的に有効化したい挙動を切り替えるための小さなフィーチャーフラグ機構を提供します。
+//
+// フラグはコード上で Definition（名前・デフォルト値）として定義し、値は次の優先順で決まります。
+//
+//	Redis ハッシュ（Backend 有効時） > 環境変数（FLAG_<NAME>） > デフォルト値
+//
+// Backend の値はプロセス内で ttl の間キャッシュするため、運用者が切り替えた値が
+// 全インスタンスへ反映されるまで最大 ttl の遅延があります。
+// 利用側は Registry に直接依存せず、自身で定義した Enabled(ctx, name) を持つ
+// インターフェース経由で参照します（テストで値を固定できるようにするため）。
+package flags
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
+)
+
+// DefaultCacheTTL は Backend から読み込んだ値をプロセス内で再利用する期間のデフォルト値です。
+const DefaultCacheTTL = 10 * time.Second
+
+var (
+	// ErrUnknownFlag は定義されていないフラグ名が指定されたことを示します。
+	ErrUnknownFlag = apperr.New(apperr.KindNotFound, "unknown_flag", "unknown flag")
+	// ErrReadOnly は Backend が未設定のため実行時の切り替えができないことを示します。
+	ErrReadOnly = apperr.New(apperr.KindConflict, "flags_read_only", "flag backend is not configured")
+)
+
+// Checker はフラグの有効/無効を参照するインターフェースです。
+type Checker interface {
+	Enabled(ctx context.Context, name string) bool
+}
+
+// Definition はコード上で定義するフラグ 1 件です。
+type Definition struct {
+	Name        string // snake_case のフラグ名（環境変数は FLAG_ + 大文字化した名前）
+	Default     bool
+	Description string
+}
+
+// Source はフラグ値の決定元です。
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceEnv     Source = "env"
+	SourceRedis   Source = "redis"
+)
+
+// State はフラグ 1 件の現在値と決定元です。
+type State struct {
+	Name        string
+	Description string
+	Default     bool
+	Enabled     bool
+	Source      Source
+}
+
+// Backend は実行時に切り替え可能なフラグ値の保存先です。
+// Load は上書きされているフラグのみを返します（未設定のフラグは含めない）。
+type Backend interface {
+	Load(ctx context.Context) (map[string]bool, error)
+	Store(ctx context.Context, name string, enabled bool) error
+}
+
+// Registry は定義済みフラグの値を解決する Checker 実装です。
+type Registry struct {
+	defs    []Definition
+	env     map[string]bool
+	backend Backend
+	ttl     time.Duration
+	now     func() time.Time
+
+	mu        sync.Mutex
+	cached    map[string]bool
+	fetchedAt time.Time
+}
+
+// NewRegistry は Registry を生成します。
+// env は環境変数由来の上書き値で、未定義のフラグ名は警告を出して無視します。
+// backend が nil の場合は実行時の切り替えを無効化し、Set は ErrReadOnly を返します。
+// ttl が 0 以下の場合は DefaultCacheTTL を使用します。
+func NewRegistry(defs []Definition, env map[string]bool, backend Backend, ttl time.Duration) *Registry {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	defs = slices.Clone(defs)
+	slices.SortFunc(defs, func(a, b Definition) int { return strings.Compare(a.Name, b.Name) })
+
+	r := &Registry{defs: defs, env: map[string]bool{}, backend: backend, ttl: ttl, now: time.Now}
+	for name, v := range env {
+		if _, ok := r.lookup(name); !ok {
+			slog.Warn("ignoring override for undefined flag", "flag", name)
+			continue
+		}
+		r.env[name] = v
+	}
+	return r
+}
+
+// Enabled は name のフラグが有効かを返します。未定義のフラグは常に false です。
+func (r *Registry) Enabled(ctx context.Context, name string) bool {
+	def, ok := r.lookup(name)
+	if !ok {
+		return false
+	}
+	enabled, _ := r.resolve(def, r.overrides(ctx))
+	return enabled
+}
+
+// Snapshot は定義済みの全フラグの現在値を名前順で返します。
+func (r *Registry) Snapshot(ctx context.Context) []State {
+	overrides := r.overrides(ctx)
+	states := make([]State, 0, len(r.defs))
+	for _, def := range r.defs {
+		enabled, src := r.resolve(def, overrides)
+		states = append(states, State{
+			Name:        def.Name,
+			Description: def.Description,
+			Default:     def.Default,
+			Enabled:     enabled,
+			Source:      src,
+		})
+	}
+	return states
+}
+
+// Writable は Backend が設定され、Set による切り替えが可能かを返します。
+func (r *Registry) Writable() bool {
+	return r.backend != nil
+}
+
+// Set は name のフラグ値を Backend に保存します。
+// 自プロセスのキャッシュは即座に破棄しますが、他インスタンスへの反映は最大 ttl 遅れます。
+func (r *Registry) Set(ctx context.Context, name string, enabled bool) error {
+	if _, ok := r.lookup(name); !ok {
+		return ErrUnknownFlag
+	}
+	if r.backend == nil {
+		return ErrReadOnly
+	}
+	if err := r.backend.Store(ctx, name, enabled); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.fetchedAt = time.Time{}
+	r.mu.Unlock()
+	return nil
+}
+
+// resolve は Backend > env > デフォルトの優先順で値と決定元を返します。
+func (r *Registry) resolve(def Definition, overrides map[string]bool) (bool, Source) {
+	if v, ok := overrides[def.Name]; ok {
+		return v, SourceRedis
+	}
+	if v, ok := r.env[def.Name]; ok {
+		return v, SourceEnv
+	}
+	return def.Default, SourceDefault
+}
+
+// overrides は Backend の値をキャッシュ経由で返します。
+// 読み込みに失敗した場合は直前の値を使い続け、次の読み込みも ttl 経過後まで行いません
+// （Redis 障害時にリクエストごとの再試行で負荷をかけないため）。
+func (r *Registry) overrides(ctx context.Context) map[string]bool {
+	if r.backend == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.fetchedAt.IsZero() && r.now().Sub(r.fetchedAt) < r.ttl {
+		return r.cached
+	}
+	loaded, err := r.backend.Load(ctx)
+	r.fetchedAt = r.now()
+	if err != nil {
+		slog.Warn("failed to load flags, using last known values", "error", err)
+		return r.cached
+	}
+	r.cached = loaded
+	return r.cached
+}
+
+func (r *Registry) lookup(name string) (Definition, bool) {
+	i, ok := slices.BinarySearchFunc(r.defs, name, func(d Definition, n string) int { return strings.Compare(d.Name, n) })
+	if !ok {
+		return Definition{}, false
+	}
+	return r.defs[i], true
+}
+
+// EnvName はフラグ名に対応する環境変数名（例: fetch_through → FLAG_FETCH_THROUGH）を返します。
+func EnvName(name string) string {
+	return "FLAG_" + strings.ToUpper(name)
+}
+
+// NameFromEnv は環境変数名からフラグ名を返します。FLAG_ で始まらない場合は ok=false です。
+func NameFromEnv(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, "FLAG_")
+	if !ok || rest == "" {
+		return "", false
+	}
+	return strings.ToLower(rest), true
+}
@@ -0,0 +1,152 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
+)
+
+var testDefs = []Definition{
+	{Name: "write_through", Default: true},
+	{Name: "negative_cache", Default: false},
+	{Name: "fetch_through", Default: true},
+}
+
+// newMiniredisBackend は miniredis 上のハッシュ "test:flags" を使う RedisBackend を返します。
+func newMiniredisBackend(t *testing.T) (*RedisBackend, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return NewRedisBackend(rdb, "test:flags"), mr
+}
+
+func TestRegistry_Precedence(t *testing.T) {
+	t.Parallel()
+
+	backend, mr := newMiniredisBackend(t)
+	mr.HSet("test:flags", "negative_cache", "true")
+	mr.HSet("test:flags", "write_through", "false")
+
+	// write_through は env でも上書きされているが Redis が優先される
+	r := NewRegistry(testDefs, map[string]bool{"write_through": true, "fetch_through": false}, backend, time.Minute)
+	ctx := context.Background()
+
+	assert.True(t, r.Enabled(ctx, "negative_cache"), "redis > default")
+	assert.False(t, r.Enabled(ctx, "write_through"), "redis > env")
+	assert.False(t, r.Enabled(ctx, "fetch_through"), "env > default")
+	assert.False(t, r.Enabled(ctx, "undefined"), "未定義のフラグは false")
+
+	want := []State{
+		{Name: "fetch_through", Default: true, Enabled: false, Source: SourceEnv},
+		{Name: "negative_cache", Default: false, Enabled: true, Source: SourceRedis},
+		{Name: "write_through", Default: true, Enabled: false, Source: SourceRedis},
+	}
+	assert.Equal(t, want, r.Snapshot(ctx))
+}
+
+func TestRegistry_WithoutBackend(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry(testDefs, map[string]bool{"negative_cache": true, "unknown": true}, nil, 0)
+	ctx := context.Background()
+
+	assert.True(t, r.Enabled(ctx, "negative_cache"))
+	assert.True(t, r.Enabled(ctx, "write_through"))
+	assert.False(t, r.Writable())
+
+	err := r.Set(ctx, "negative_cache", false)
+	assert.ErrorIs(t, err, ErrReadOnly)
+	assert.Equal(t, apperr.KindConflict, apperr.KindOf(err))
+}
+
+func TestRegistry_CacheStalenessWindow(t *testing.T) {
+	t.Parallel()
+
+	backend, mr := newMiniredisBackend(t)
+	r := NewRegistry(testDefs, nil, backend, 10*time.Second)
+	now := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	require.False(t, r.Enabled(ctx, "negative_cache"))
+
+	// 他インスタンスが切り替えても、ttl 内はキャッシュ済みの値を返す
+	mr.HSet("test:flags", "negative_cache", "true")
+	now = now.Add(9 * time.Second)
+	assert.False(t, r.Enabled(ctx, "negative_cache"), "ttl 内は古い値")
+
+	now = now.Add(1 * time.Second)
+	assert.True(t, r.Enabled(ctx, "negative_cache"), "ttl 経過後は再読み込み")
+}
+
+func TestRegistry_BackendErrorKeepsLastKnown(t *testing.T) {
+	t.Parallel()
+
+	backend, mr := newMiniredisBackend(t)
+	mr.HSet("test:flags", "negative_cache", "true")
+	r := NewRegistry(testDefs, nil, backend, time.Second)
+	now := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	require.True(t, r.Enabled(ctx, "negative_cache"))
+
+	mr.SetError("connection lost")
+	now = now.Add(2 * time.Second)
+	assert.True(t, r.Enabled(ctx, "negative_cache"), "読み込み失敗時は直前の値を使う")
+}
+
+func TestRegistry_SetRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	backend, mr := newMiniredisBackend(t)
+	r := NewRegistry(testDefs, nil, backend, time.Hour)
+	ctx := context.Background()
+
+	require.False(t, r.Enabled(ctx, "negative_cache"))
+	require.NoError(t, r.Set(ctx, "negative_cache", true))
+
+	// 自プロセスのキャッシュは即座に破棄される
+	assert.True(t, r.Enabled(ctx, "negative_cache"))
+	assert.Equal(t, "true", mr.HGet("test:flags", "negative_cache"))
+
+	err := r.Set(ctx, "undefined", true)
+	assert.True(t, errors.Is(err, ErrUnknownFlag))
+	assert.Equal(t, apperr.KindNotFound, apperr.KindOf(err))
+}
+
+func TestRedisBackend_IgnoresInvalidValues(t *testing.T) {
+	t.Parallel()
+
+	backend, mr := newMiniredisBackend(t)
+	mr.HSet("test:flags", "negative_cache", "yes please")
+	mr.HSet("test:flags", "write_through", "0")
+
+	got, err := backend.Load(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"write_through": false}, got)
+}
+
+func TestEnvName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "FLAG_FETCH_THROUGH", EnvName("fetch_through"))
+
+	name, ok := NameFromEnv("FLAG_FETCH_THROUGH")
+	assert.True(t, ok)
+	assert.Equal(t, "fetch_through", name)
+
+	_, ok = NameFromEnv("FLAG_")
+	assert.False(t, ok)
+	_, ok = NameFromEnv("REDIS_HOST")
+	assert.False(t, ok)
+}
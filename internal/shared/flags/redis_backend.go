@@ -0,0 +1,43 @@
+package flags
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisBackend はフラグの上書き値を Redis ハッシュ（フィールド=フラグ名、値="true"/"false"）に保存します。
+type RedisBackend struct {
+	rdb *redis.Client
+	key string
+}
+
+// NewRedisBackend は key のハッシュを使う RedisBackend を生成します。
+func NewRedisBackend(rdb *redis.Client, key string) *RedisBackend {
+	return &RedisBackend{rdb: rdb, key: key}
+}
+
+// Load はハッシュ全体を読み込みます。真偽値として解釈できないフィールドは警告を出して無視します。
+func (b *RedisBackend) Load(ctx context.Context) (map[string]bool, error) {
+	raw, err := b.rdb.HGetAll(ctx, b.key).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]bool, len(raw))
+	for name, v := range raw {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			slog.Warn("ignoring invalid flag value in redis", "flag", name, "value", v)
+			continue
+		}
+		out[name] = enabled
+	}
+	return out, nil
+}
+
+// Store は name の値をハッシュに書き込みます。
+func (b *RedisBackend) Store(ctx context.Context, name string, enabled bool) error {
+	return b.rdb.HSet(ctx, b.key, name, strconv.FormatBool(enabled)).Err()
+}
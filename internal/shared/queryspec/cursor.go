@@ -0,0 +1,72 @@
+package queryspec
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// cursorPrefix はカーソルの版です。形式を変える場合は接頭辞を変え、古いカーソルを不正として扱います。
+const cursorPrefix = "id:"
+
+// Page は ID のキーセット（カーソル）によるページングの指定です。
+//
+//	?limit=50&cursor=aWQ6MTIz
+//
+// 取得側は ID が AfterID より大きい行を ID 昇順で返します。offset と違い、
+// 閲覧中に行が追加されてもページ間で行が重複・欠落しません（新しい行は末尾のページに現れます）。
+type Page struct {
+	Limit   int
+	AfterID int64 // 0 は先頭ページ
+}
+
+// ParsePage はクエリパラメータの limit / cursor を Page に変換します。
+// limit 未指定時は defaultLimit を使います。不正な指定は ErrInvalidQuery を返します。
+func ParsePage(q url.Values, defaultLimit, maxLimit int) (Page, error) {
+	p := Page{Limit: defaultLimit}
+	if vals, ok := q["limit"]; ok {
+		if err := single("limit", vals); err != nil {
+			return Page{}, err
+		}
+		n, err := strconv.Atoi(vals[0])
+		if err != nil || n < 1 || n > maxLimit {
+			return Page{}, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidQuery, maxLimit)
+		}
+		p.Limit = n
+	}
+	if vals, ok := q["cursor"]; ok {
+		if err := single("cursor", vals); err != nil {
+			return Page{}, err
+		}
+		id, err := decodeCursor(vals[0])
+		if err != nil {
+			return Page{}, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+		}
+		p.AfterID = id
+	}
+	return p, nil
+}
+
+// EncodeCursor は id の次から始まるページを指すカーソルを返します。
+// クライアントには中身を解釈させないよう、不透明な文字列（base64url）にします。
+func EncodeCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.FormatInt(id, 10)))
+}
+
+func decodeCursor(s string) (int64, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return 0, err
+	}
+	raw, ok := strings.CutPrefix(string(b), cursorPrefix)
+	if !ok {
+		return 0, fmt.Errorf("unknown cursor format")
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid cursor id %q", raw)
+	}
+	return id, nil
+}
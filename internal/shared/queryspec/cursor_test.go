@@ -0,0 +1,40 @@
+package queryspec
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePage(t *testing.T) {
+	t.Parallel()
+
+	p, err := ParsePage(mustQuery(t, ""), 50, 200)
+	require.NoError(t, err)
+	assert.Equal(t, Page{Limit: 50}, p)
+
+	p, err = ParsePage(mustQuery(t, "limit=10&cursor="+EncodeCursor(123)+"&query=ignored"), 50, 200)
+	require.NoError(t, err)
+	assert.Equal(t, Page{Limit: 10, AfterID: 123}, p)
+}
+
+func TestParsePage_Rejects(t *testing.T) {
+	t.Parallel()
+
+	for _, raw := range []string{
+		"limit=0",
+		"limit=201",
+		"limit=ten",
+		"limit=1&limit=2",
+		"cursor=!!",
+		"cursor=" + base64.RawURLEncoding.EncodeToString([]byte("123")),       // 接頭辞なし
+		"cursor=" + base64.RawURLEncoding.EncodeToString([]byte("id:-1")),     // 負の ID
+		"cursor=" + base64.RawURLEncoding.EncodeToString([]byte("offset:10")), // 別形式
+		"cursor=" + EncodeCursor(1) + "&cursor=" + EncodeCursor(2),
+	} {
+		_, err := ParsePage(mustQuery(t, raw), 50, 200)
+		assert.ErrorIs(t, err, ErrInvalidQuery, raw)
+	}
+}
@@ -0,0 +1,315 @@
+// Package queryspec は管理用の一覧 API の絞り込み・並び替え・ページングを、一覧ごとに許可した項目だけで組み立てます。
+//
+// 一覧ごとに Schema（絞り込み可能な項目と演算子、並び替え可能な項目）を宣言し、
+// クエリパラメータを Parse で検証済みの Spec に変換してから、Query で SQL に適用します。
+//
+//	?filter[status]=pending&filter[detected_at][gte]=2025-01-01&sort=-detected_at&limit=50&offset=100
+//
+// SQL に埋め込む列名は Schema に書いた固定値だけで、クライアントの値はすべてプレースホルダーの引数になります。
+// 並び替えの最後には常に一意な列（TieBreaker）を加えるため、同じ値が並んでもページ間で行が重複・欠落しません。
+//
+// 行が追加され続ける一覧には、offset の代わりに ID のキーセットで進むカーソル方式のページング（ParsePage / EncodeCursor）を使えます。
+package queryspec
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
+)
+
+// ErrInvalidQuery は許可されていない項目・演算子、または不正な値が指定されたことを示します。
+var ErrInvalidQuery = apperr.New(apperr.KindInvalid, "invalid_list_query", "invalid filter, sort or paging parameter")
+
+// maxInValues は in 演算子に指定できる値の最大数です。
+const maxInValues = 50
+
+// Op は絞り込みの演算子です。
+type Op string
+
+const (
+	OpEq  Op = "eq"  // 一致（filter[field]=v の既定）
+	OpGte Op = "gte" // 以上
+	OpLte Op = "lte" // 以下
+	OpIn  Op = "in"  // いずれかに一致（カンマ区切り）
+)
+
+// Kind は絞り込みの値の型です。
+type Kind int
+
+const (
+	// KindString は文字列の値です。
+	KindString Kind = iota
+	// KindTime は日時の値です。RFC 3339 または日付のみ（2006-01-02、UTC の 0 時）を受け付けます。
+	KindTime
+)
+
+// Field は絞り込み可能な項目 1 件です。
+type Field struct {
+	Column string // SQL の列（固定値。クライアントの値は入らない）
+	Kind   Kind
+	Ops    []Op     // 許可する演算子
+	Enum   []string // 空でない場合、値はこのいずれかでなければならない
+}
+
+// Sort は並び替えのキー 1 件です。
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// Schema は一覧ごとに許可する絞り込み・並び替え・ページングの宣言です。
+type Schema struct {
+	Filters     map[string]Field  // クエリ上の項目名 → 絞り込み
+	Sorts       map[string]string // クエリ上の項目名 → 並び替えの列
+	DefaultSort []Sort            // sort 未指定時の並び替え
+	TieBreaker  string            // 一意な列（通常 id）。並び替えの最後に必ず加える
+	// DefaultLimit は limit 未指定時の件数、MaxLimit は指定可能な最大件数です。
+	DefaultLimit int
+	MaxLimit     int
+}
+
+// Condition は検証済みの絞り込み条件 1 件です。
+type Condition struct {
+	Field  string
+	Op     Op
+	Values []any // OpIn 以外は 1 件
+}
+
+// Spec は Schema で検証済みの一覧の取得条件です。
+type Spec struct {
+	Conditions []Condition
+	Sorts      []Sort
+	Limit      int
+	Offset     int
+}
+
+// filterKey は filter[field] / filter[field][op] の形式です。
+var filterKey = regexp.MustCompile(`^filter\[([a-z_]+)\](?:\[([a-z]+)\])?$`)
+
+// Parse はクエリパラメータを Spec に変換します。
+// filter / sort / limit / offset 以外のパラメータは無視します。不正な指定は ErrInvalidQuery を返します。
+func (s Schema) Parse(q url.Values) (Spec, error) {
+	spec := Spec{Limit: s.DefaultLimit}
+
+	// 生成される SQL を入力の順序によらず一定にするため、キーを整列して処理する
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		vals := q[key]
+		switch {
+		case key == "sort":
+			if err := single(key, vals); err != nil {
+				return Spec{}, err
+			}
+			sorts, err := s.parseSort(vals[0])
+			if err != nil {
+				return Spec{}, err
+			}
+			spec.Sorts = sorts
+		case key == "limit":
+			if err := single(key, vals); err != nil {
+				return Spec{}, err
+			}
+			n, err := strconv.Atoi(vals[0])
+			if err != nil || n < 1 || n > s.MaxLimit {
+				return Spec{}, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidQuery, s.MaxLimit)
+			}
+			spec.Limit = n
+		case key == "offset":
+			if err := single(key, vals); err != nil {
+				return Spec{}, err
+			}
+			n, err := strconv.Atoi(vals[0])
+			if err != nil || n < 0 {
+				return Spec{}, fmt.Errorf("%w: offset must be a non-negative integer", ErrInvalidQuery)
+			}
+			spec.Offset = n
+		case strings.HasPrefix(key, "filter["):
+			if err := single(key, vals); err != nil {
+				return Spec{}, err
+			}
+			c, err := s.parseFilter(key, vals[0])
+			if err != nil {
+				return Spec{}, err
+			}
+			spec.Conditions = append(spec.Conditions, c)
+		}
+	}
+	return spec, nil
+}
+
+// Where は絞り込み条件 1 件を追加した Spec を返します。
+// 既存のクエリパラメータ（例: ?status=）を Spec に取り込むために使い、Parse と同じ検証を行います。
+func (s Schema) Where(spec Spec, field string, op Op, raw string) (Spec, error) {
+	key := "filter[" + field + "][" + string(op) + "]"
+	c, err := s.parseFilter(key, raw)
+	if err != nil {
+		return Spec{}, err
+	}
+	spec.Conditions = append(slices.Clone(spec.Conditions), c)
+	return spec, nil
+}
+
+func single(key string, vals []string) error {
+	if len(vals) != 1 {
+		return fmt.Errorf("%w: %s must be specified once", ErrInvalidQuery, key)
+	}
+	return nil
+}
+
+func (s Schema) parseFilter(key, raw string) (Condition, error) {
+	m := filterKey.FindStringSubmatch(key)
+	if m == nil {
+		return Condition{}, fmt.Errorf("%w: malformed filter %q", ErrInvalidQuery, key)
+	}
+	name, op := m[1], Op(m[2])
+	if op == "" {
+		op = OpEq
+	}
+	f, ok := s.Filters[name]
+	if !ok {
+		return Condition{}, fmt.Errorf("%w: unknown filter field %q", ErrInvalidQuery, name)
+	}
+	if !slices.Contains(f.Ops, op) {
+		return Condition{}, fmt.Errorf("%w: operator %q is not allowed for %q", ErrInvalidQuery, op, name)
+	}
+
+	raws := []string{raw}
+	if op == OpIn {
+		raws = strings.Split(raw, ",")
+		if len(raws) > maxInValues {
+			return Condition{}, fmt.Errorf("%w: too many values for %q (max %d)", ErrInvalidQuery, name, maxInValues)
+		}
+	}
+	c := Condition{Field: name, Op: op, Values: make([]any, 0, len(raws))}
+	for _, r := range raws {
+		v, err := f.value(strings.TrimSpace(r))
+		if err != nil {
+			return Condition{}, fmt.Errorf("%w: %s: %v", ErrInvalidQuery, name, err)
+		}
+		c.Values = append(c.Values, v)
+	}
+	return c, nil
+}
+
+// value はクエリの値を Kind に従って変換します。
+func (f Field) value(raw string) (any, error) {
+	if raw == "" {
+		return nil, fmt.Errorf("empty value")
+	}
+	if len(f.Enum) > 0 && !slices.Contains(f.Enum, raw) {
+		return nil, fmt.Errorf("unknown value %q", raw)
+	}
+	switch f.Kind {
+	case KindTime:
+		if t, err := time.Parse(time.DateOnly, raw); err == nil {
+			return t, nil
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time %q", raw)
+		}
+		return t, nil
+	default:
+		return raw, nil
+	}
+}
+
+func (s Schema) parseSort(raw string) ([]Sort, error) {
+	var out []Sort
+	for _, part := range strings.Split(raw, ",") {
+		desc := strings.HasPrefix(part, "-")
+		name := strings.TrimPrefix(part, "-")
+		if _, ok := s.Sorts[name]; !ok {
+			return nil, fmt.Errorf("%w: unknown sort field %q", ErrInvalidQuery, name)
+		}
+		if slices.ContainsFunc(out, func(x Sort) bool { return x.Field == name }) {
+			return nil, fmt.Errorf("%w: duplicate sort field %q", ErrInvalidQuery, name)
+		}
+		out = append(out, Sort{Field: name, Desc: desc})
+	}
+	return out, nil
+}
+
+// Query は base（SELECT ... FROM ...。WHERE 句を含まない）に spec の WHERE / ORDER BY / LIMIT / OFFSET を付けた
+// SQL と引数を返します。spec は同じ Schema の Parse / Where で生成したものでなければなりません。
+// 並び替えの最後には TieBreaker を直前のキーと同じ向きで加えます。
+func (s Schema) Query(base string, spec Spec) (string, []any) {
+	var (
+		b     strings.Builder
+		args  []any
+		conds []string
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	for _, c := range spec.Conditions {
+		col := s.Filters[c.Field].Column
+		switch c.Op {
+		case OpGte:
+			conds = append(conds, col+" >= "+arg(c.Values[0]))
+		case OpLte:
+			conds = append(conds, col+" <= "+arg(c.Values[0]))
+		case OpIn:
+			ph := make([]string, 0, len(c.Values))
+			for _, v := range c.Values {
+				ph = append(ph, arg(v))
+			}
+			conds = append(conds, col+" IN ("+strings.Join(ph, ", ")+")")
+		default:
+			conds = append(conds, col+" = "+arg(c.Values[0]))
+		}
+	}
+
+	b.WriteString(base)
+	if len(conds) > 0 {
+		b.WriteString(" WHERE ")
+		b.WriteString(strings.Join(conds, " AND "))
+	}
+
+	sorts := spec.Sorts
+	if len(sorts) == 0 {
+		sorts = s.DefaultSort
+	}
+	order := make([]string, 0, len(sorts)+1)
+	desc, tied := false, false
+	for _, st := range sorts {
+		col := s.Sorts[st.Field]
+		desc = st.Desc
+		tied = tied || col == s.TieBreaker
+		order = append(order, col+direction(desc))
+	}
+	if !tied && s.TieBreaker != "" {
+		order = append(order, s.TieBreaker+direction(desc))
+	}
+	if len(order) > 0 {
+		b.WriteString(" ORDER BY ")
+		b.WriteString(strings.Join(order, ", "))
+	}
+
+	b.WriteString(" LIMIT " + arg(spec.Limit))
+	if spec.Offset > 0 {
+		b.WriteString(" OFFSET " + arg(spec.Offset))
+	}
+	return b.String(), args
+}
+
+func direction(desc bool) string {
+	if desc {
+		return " DESC"
+	}
+	return " ASC"
+}
@@ -0,0 +1,142 @@
+package queryspec
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSchema = Schema{
+	Filters: map[string]Field{
+		"type":       {Column: "type", Ops: []Op{OpEq, OpIn}, Enum: []string{"login", "login_failed", "logout"}},
+		"user":       {Column: "user_id", Ops: []Op{OpEq}},
+		"created_at": {Column: "created_at", Kind: KindTime, Ops: []Op{OpGte, OpLte}},
+	},
+	Sorts:        map[string]string{"created_at": "created_at", "type": "type", "id": "id"},
+	DefaultSort:  []Sort{{Field: "created_at", Desc: true}},
+	TieBreaker:   "id",
+	DefaultLimit: 50,
+	MaxLimit:     200,
+}
+
+func mustQuery(t *testing.T, raw string) url.Values {
+	t.Helper()
+	q, err := url.ParseQuery(raw)
+	require.NoError(t, err)
+	return q
+}
+
+func TestSchema_Parse(t *testing.T) {
+	t.Parallel()
+
+	spec, err := testSchema.Parse(mustQuery(t, "filter[type]=login_failed&filter[created_at][gte]=2025-01-01&sort=-created_at&limit=10&offset=20&status=ignored"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []Condition{
+		{Field: "created_at", Op: OpGte, Values: []any{time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}},
+		{Field: "type", Op: OpEq, Values: []any{"login_failed"}},
+	}, spec.Conditions)
+	assert.Equal(t, []Sort{{Field: "created_at", Desc: true}}, spec.Sorts)
+	assert.Equal(t, 10, spec.Limit)
+	assert.Equal(t, 20, spec.Offset)
+
+	spec, err = testSchema.Parse(url.Values{})
+	require.NoError(t, err)
+	assert.Equal(t, Spec{Limit: 50}, spec)
+}
+
+func TestSchema_Parse_Rejects(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "unknown field", query: "filter[password]=x"},
+		{name: "operator not allowed", query: "filter[type][gte]=login"},
+		{name: "unknown operator", query: "filter[created_at][like]=2025"},
+		{name: "malformed key", query: "filter[type]]=login"},
+		{name: "injection in field name", query: "filter[type)--]=login"},
+		{name: "value outside enum", query: "filter[type]=admin"},
+		{name: "empty value", query: "filter[user]="},
+		{name: "invalid time", query: "filter[created_at][gte]=yesterday"},
+		{name: "empty in element", query: "filter[type][in]=login,"},
+		{name: "repeated filter", query: "filter[user]=1&filter[user]=2"},
+		{name: "unknown sort field", query: "sort=password"},
+		{name: "duplicate sort field", query: "sort=type,-type"},
+		{name: "limit too large", query: "limit=201"},
+		{name: "limit zero", query: "limit=0"},
+		{name: "negative offset", query: "offset=-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := testSchema.Parse(mustQuery(t, tt.query))
+			assert.ErrorIs(t, err, ErrInvalidQuery)
+		})
+	}
+}
+
+func TestSchema_Query(t *testing.T) {
+	t.Parallel()
+
+	const base = "SELECT id FROM audit_logs"
+	tests := []struct {
+		name     string
+		query    string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "default sort with tie breaker",
+			query:    "",
+			wantSQL:  base + " ORDER BY created_at DESC, id DESC LIMIT $1",
+			wantArgs: []any{50},
+		},
+		{
+			name:    "filters, sort and paging",
+			query:   "filter[type][in]=login,logout&filter[created_at][lte]=2025-02-01T00:00:00Z&sort=type,-created_at&limit=5&offset=10",
+			wantSQL: base + " WHERE created_at <= $1 AND type IN ($2, $3) ORDER BY type ASC, created_at DESC, id DESC LIMIT $4 OFFSET $5",
+			wantArgs: []any{
+				time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), "login", "logout", 5, 10,
+			},
+		},
+		{
+			name:     "tie breaker already sorted",
+			query:    "sort=id",
+			wantSQL:  base + " ORDER BY id ASC LIMIT $1",
+			wantArgs: []any{50},
+		},
+		{
+			name:     "values are never inlined",
+			query:    "filter[user]=1' OR '1'='1",
+			wantSQL:  base + " WHERE user_id = $1 ORDER BY created_at DESC, id DESC LIMIT $2",
+			wantArgs: []any{"1' OR '1'='1", 50},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			spec, err := testSchema.Parse(mustQuery(t, tt.query))
+			require.NoError(t, err)
+
+			sql, args := testSchema.Query(base, spec)
+			assert.Equal(t, tt.wantSQL, sql)
+			assert.Equal(t, tt.wantArgs, args)
+		})
+	}
+}
+
+func TestSchema_Where(t *testing.T) {
+	t.Parallel()
+
+	spec, err := testSchema.Where(Spec{Limit: 50}, "type", OpEq, "logout")
+	require.NoError(t, err)
+	assert.Equal(t, []Condition{{Field: "type", Op: OpEq, Values: []any{"logout"}}}, spec.Conditions)
+
+	_, err = testSchema.Where(spec, "type", OpEq, "admin")
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+}
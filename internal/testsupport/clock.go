@@ -0,0 +1,50 @@
+package testsupport
+
+import (
+	"sync"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// Clock はテストから止めたり進めたりできる時計です。
+// Now は各パッケージの now func() time.Time フィールドにそのまま設定できます（例: uc.now = clock.Now）。
+// バックグラウンドの goroutine からも読めるようロックで保護しています。
+type Clock struct {
+	mu    sync.Mutex
+	now   time.Time
+	redis []*miniredis.Miniredis
+}
+
+// NewClock は now で止まった Clock を返します。
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now は現在の時計の時刻を返します。
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance は時計を d だけ進めます。SyncRedis で登録した miniredis のキーの TTL も同じだけ進めます。
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	now, mrs := c.now, c.redis
+	c.mu.Unlock()
+	for _, mr := range mrs {
+		mr.SetTime(now)
+		mr.FastForward(d)
+	}
+}
+
+// SyncRedis は mr の時刻（EXPIREAT 等の期限の判定に使う）を時計に合わせ、以後の Advance で TTL も進めます。
+// 過去の日付で止めた時計で EXPIREAT を使う場合、合わせないと miniredis は実時刻で判定してキーを即座に期限切れにします。
+func (c *Clock) SyncRedis(mr *miniredis.Miniredis) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	mr.SetTime(c.now)
+	c.redis = append(c.redis, mr)
+}
@@ -0,0 +1,22 @@
+package testsupport
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/db/dbtest"
+)
+
+// Seed は NewDB が作成直後の DB に適用するデータ投入です（例: InsertSymbols）。
+type Seed func(t testing.TB, db *sql.DB)
+
+// NewDB はテストごとに独立した PostgreSQL データベースを作成してマイグレーションを適用し、seeds を順に適用した *sql.DB を返します。
+// DB はテストの終了時に DROP されます。TestMain で dbtest.RunMainWithPostgres を呼んでいる必要があります。
+func NewDB(t testing.TB, seeds ...Seed) *sql.DB {
+	t.Helper()
+	db := dbtest.OpenIsolatedDB(t)
+	for _, seed := range seeds {
+		seed(t, db)
+	}
+	return db
+}
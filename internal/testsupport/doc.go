@@ -0,0 +1,12 @@
+// Package testsupport はテストで共通に使うフィクスチャ（DB・Redis・時計・行のファクトリ・HTTP ハーネス・golden 比較用の JSON の正規化）を提供します。
+//
+// テストファイル（_test.go）からのみ import してください。各ヘルパーはテストごとに独立したインスタンス
+// （DB・miniredis・時計）を作り、後始末を t.Cleanup に登録します。パッケージ変数に状態を持たないため
+// t.Parallel のテストから並行に使えます。
+//
+// フィーチャーのパッケージ内テスト（package candles 等）からも使えるよう、このパッケージは
+// フィーチャー・transport のパッケージに依存しません（行は SQL で直接作り、ユーザー ID の注入等は
+// 呼び出し側がミドルウェアとして渡します）。
+//
+// DB を使うテストは従来どおり TestMain で dbtest.RunMainWithPostgres を呼んでください。
+package testsupport
@@ -0,0 +1,137 @@
+package testsupport
+
+import (
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// SymbolRow は InsertSymbol が作る symbols の行です。既定値は NASDAQ の active な銘柄です。
+type SymbolRow struct {
+	Code     string
+	Name     string // 既定は "<Code> Inc."
+	Market   string
+	Timezone string
+	Status   string  // active / delisted / hidden
+	Currency *string // nil の場合は NULL
+	Priority int
+}
+
+// InsertSymbol は code の銘柄を登録します。opts で既定値を上書きできます。
+func InsertSymbol(t testing.TB, db *sql.DB, code string, opts ...func(*SymbolRow)) SymbolRow {
+	t.Helper()
+	row := SymbolRow{Code: code, Name: code + " Inc.", Market: "NASDAQ", Timezone: "America/New_York", Status: "active", Priority: 3}
+	for _, opt := range opts {
+		opt(&row)
+	}
+	_, err := db.ExecContext(t.Context(),
+		`INSERT INTO symbols (code, name, market, timezone, status, currency, priority) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		row.Code, row.Name, row.Market, row.Timezone, row.Status, row.Currency, row.Priority)
+	require.NoError(t, err, "insert symbol %s", code)
+	return row
+}
+
+// InsertSymbols は codes の銘柄を既定値で登録する Seed を返します（例: NewDB(t, InsertSymbols("AAPL", "GOOGL"))）。
+func InsertSymbols(codes ...string) Seed {
+	return func(t testing.TB, db *sql.DB) {
+		t.Helper()
+		for _, code := range codes {
+			InsertSymbol(t, db, code)
+		}
+	}
+}
+
+// CandleRow は InsertCandle が作る candles の行です。既定値は日足・始値 100・高値 110・安値 90・終値 105・出来高 1000 です。
+type CandleRow struct {
+	SymbolCode string
+	Interval   string
+	Time       time.Time
+	Open       float64
+	High       float64
+	Low        float64
+	Close      float64
+	Volume     int64
+}
+
+// InsertCandle は symbol の ts の足を登録します。銘柄は登録済みである必要があります（FK 制約）。
+func InsertCandle(t testing.TB, db *sql.DB, symbol string, ts time.Time, opts ...func(*CandleRow)) CandleRow {
+	t.Helper()
+	row := CandleRow{SymbolCode: symbol, Interval: "1day", Time: ts, Open: 100, High: 110, Low: 90, Close: 105, Volume: 1000}
+	for _, opt := range opts {
+		opt(&row)
+	}
+	_, err := db.ExecContext(t.Context(),
+		`INSERT INTO candles (symbol_code, "interval", "time", open, high, low, close, volume) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		row.SymbolCode, row.Interval, row.Time, row.Open, row.High, row.Low, row.Close, row.Volume)
+	require.NoError(t, err, "insert candle %s %s %s", symbol, row.Interval, ts)
+	return row
+}
+
+// WithInterval は足の時間間隔を上書きする InsertCandle のオプションです。
+func WithInterval(interval string) func(*CandleRow) {
+	return func(r *CandleRow) { r.Interval = interval }
+}
+
+// UserRow は InsertUser が作る users の行です。
+type UserRow struct {
+	ID       int64
+	Email    string  // 既定は連番の user-N@example.com
+	Password *string // パスワードハッシュ。nil の場合は NULL（OAuth のみのユーザー）
+}
+
+// userSeq は既定のメールアドレスの連番です（同じテスト内で複数のユーザーを作っても重複しないように）。
+var userSeq atomic.Int64
+
+// InsertUser はユーザーを登録し、採番された ID を設定した行を返します。既定のパスワードハッシュは "p" です。
+func InsertUser(t testing.TB, db *sql.DB, opts ...func(*UserRow)) UserRow {
+	t.Helper()
+	password := "p"
+	row := UserRow{Email: fmt.Sprintf("user-%d@example.com", userSeq.Add(1)), Password: &password}
+	for _, opt := range opts {
+		opt(&row)
+	}
+	err := db.QueryRowContext(t.Context(),
+		`INSERT INTO users (email, password) VALUES ($1, $2) RETURNING id`, row.Email, row.Password).Scan(&row.ID)
+	require.NoError(t, err, "insert user %s", row.Email)
+	return row
+}
+
+// Session はテスト用のログインセッション（アクセストークンのクレーム）です。
+// 既定は発行が現在時刻で、有効期間は 1 時間です。
+type Session struct {
+	UserID   int64
+	Email    string
+	IssuedAt time.Time
+	TTL      time.Duration
+}
+
+// SessionToken は secret で署名した userID のアクセストークン（jwt.Generator と同じクレーム）を返します。
+// 発行時刻の指定（失効の判定）や期限切れのトークンを opts で作れます。
+func SessionToken(t testing.TB, secret string, userID int64, opts ...func(*Session)) string {
+	t.Helper()
+	s := Session{UserID: userID, IssuedAt: time.Now(), TTL: time.Hour}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	claims := gojwt.MapClaims{
+		"sub": fmt.Sprint(s.UserID),
+		"iat": s.IssuedAt.Unix(),
+		"exp": s.IssuedAt.Add(s.TTL).Unix(),
+	}
+	if s.Email != "" {
+		claims["email"] = s.Email
+	}
+	signed, err := gojwt.NewWithClaims(gojwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	require.NoError(t, err, "sign session token")
+	return signed
+}
+
+// IssuedAt はトークンの発行時刻を上書きする SessionToken のオプションです。
+func IssuedAt(at time.Time) func(*Session) {
+	return func(s *Session) { s.IssuedAt = at }
+}
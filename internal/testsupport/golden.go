@@ -0,0 +1,23 @@
+package testsupport
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// CanonicalJSON は JSON の b をキーの昇順・2 スペースのインデントに整形して返します。
+// 応答のフィールドの順序（構造体の定義順や map の反復順）によらず同じ文字列になるため、
+// golden ファイルとの比較や差分のレビューに使います。数値は元の表記（例: 100 と 100.5）を保ちます。
+func CanonicalJSON(t testing.TB, b []byte) []byte {
+	t.Helper()
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v any
+	require.NoError(t, dec.Decode(&v), "decode JSON: %s", b)
+	out, err := json.MarshalIndent(v, "", "  ")
+	require.NoError(t, err, "encode canonical JSON")
+	return out
+}
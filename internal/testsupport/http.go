@@ -0,0 +1,55 @@
+package testsupport
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Harness はハンドラーのテスト用の chi ルーターです。NewHarness で選んだミドルウェアを通してリクエストを処理します。
+type Harness struct {
+	chi.Router
+	t testing.TB
+}
+
+// NewHarness は middlewares を順に適用した（先頭が最も外側）ルーターを返します。
+// ルートは返り値の Get / Post 等（chi.Router）で登録し、Do / DoGet でリクエストを処理します。
+func NewHarness(t testing.TB, middlewares ...func(http.Handler) http.Handler) *Harness {
+	r := chi.NewRouter()
+	r.Use(middlewares...)
+	return &Harness{Router: r, t: t}
+}
+
+// WithContext はリクエストの context を f で差し替えるミドルウェアです。
+// 認証済みユーザーの注入（例: WithContext(func(ctx context.Context) context.Context { return jwt.WithUserID(ctx, 1) })）に使います。
+func WithContext(f func(context.Context) context.Context) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(f(r.Context())))
+		})
+	}
+}
+
+// Do は method・target・body のリクエストを header 付きで処理し、レスポンスを返します。
+func (h *Harness) Do(method, target string, body io.Reader, header http.Header) *httptest.ResponseRecorder {
+	h.t.Helper()
+	req := httptest.NewRequestWithContext(h.t.Context(), method, target, body)
+	for k, vs := range header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+// DoGet は target への GET を header 付きで処理し、レスポンスを返します。
+func (h *Harness) DoGet(target string, header http.Header) *httptest.ResponseRecorder {
+	h.t.Helper()
+	return h.Do(http.MethodGet, target, nil, header)
+}
@@ -0,0 +1,18 @@
+package testsupport
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// NewMiniRedis はテストごとの miniredis と、それに接続した *redis.Client を返します。
+// どちらもテストの終了時に閉じます。TTL を時計に合わせるには Clock.SyncRedis を使います。
+func NewMiniRedis(t testing.TB) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return mr, rdb
+}
@@ -0,0 +1,84 @@
+package testsupport
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClock_SyncRedis(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 8, 1, 23, 0, 0, 0, time.UTC)
+	clock := NewClock(start)
+	mr, rdb := NewMiniRedis(t)
+	clock.SyncRedis(mr)
+	ctx := context.Background()
+
+	// 過去の時刻で止めた時計でも、時計に合わせた EXPIREAT はすぐには期限切れにならない
+	require.NoError(t, rdb.Set(ctx, "k", "v", 0).Err())
+	require.NoError(t, rdb.ExpireAt(ctx, "k", start.Add(2*time.Hour)).Err())
+	assert.True(t, mr.Exists("k"))
+
+	clock.Advance(time.Hour)
+	assert.Equal(t, start.Add(time.Hour), clock.Now())
+	assert.True(t, mr.Exists("k"))
+
+	clock.Advance(time.Hour)
+	assert.False(t, mr.Exists("k"), "時計を進めると TTL も進む")
+}
+
+func TestHarness(t *testing.T) {
+	t.Parallel()
+
+	type ctxKey struct{}
+	h := NewHarness(t, WithContext(func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, ctxKey{}, "user-1")
+	}))
+	h.Get("/who", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-User", r.Context().Value(ctxKey{}).(string))
+		w.Header().Set("X-Accept", r.Header.Get("Accept"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	w := h.DoGet("/who", http.Header{"Accept": {"application/json"}})
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "user-1", w.Header().Get("X-User"))
+	assert.Equal(t, "application/json", w.Header().Get("X-Accept"))
+	assert.Equal(t, http.StatusNotFound, h.DoGet("/missing", nil).Code)
+}
+
+func TestSessionToken(t *testing.T) {
+	t.Parallel()
+
+	const secret = "test-secret"
+	issuedAt := time.Now().Add(-10 * time.Minute).Truncate(time.Second)
+	token := SessionToken(t, secret, 42, IssuedAt(issuedAt))
+
+	parsed, err := gojwt.Parse(token, func(*gojwt.Token) (any, error) { return []byte(secret), nil })
+	require.NoError(t, err)
+	claims := parsed.Claims.(gojwt.MapClaims)
+	sub, err := claims.GetSubject()
+	require.NoError(t, err)
+	assert.Equal(t, "42", sub)
+	iat, err := claims.GetIssuedAt()
+	require.NoError(t, err)
+	assert.True(t, iat.Equal(issuedAt))
+	exp, err := claims.GetExpirationTime()
+	require.NoError(t, err)
+	assert.True(t, exp.Equal(issuedAt.Add(time.Hour)))
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	t.Parallel()
+
+	a := CanonicalJSON(t, []byte(`{"b":1,"a":{"d":[2,1],"c":100.50}}`))
+	b := CanonicalJSON(t, []byte(`{"a": {"c": 100.50, "d": [2, 1]}, "b": 1}`))
+	assert.Equal(t, string(a), string(b), "キーの順序と空白によらない")
+	assert.Equal(t, "{\n  \"a\": {\n    \"c\": 100.50,\n    \"d\": [\n      2,\n      1\n    ]\n  },\n  \"b\": 1\n}", string(a))
+}
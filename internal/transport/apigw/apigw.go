@@ -0,0 +1,127 @@
+// Package apigw は API Gateway（HTTP API、ペイロード形式 2.0）の Lambda イベントを net/http のハンドラーに橋渡しします。
+// サーバーレス構成（cmd/lambda）で、常駐サーバーと同じルーターをそのまま使うためのアダプターです。
+//
+// 応答は全体をバッファしてから返すため、WebSocket（/v1/ws）と長時間のストリーミング応答には対応しません。
+package apigw
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Handler は h を API Gateway の Lambda ハンドラーとして返します。
+func Handler(h http.Handler) func(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	return func(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+		r, err := NewRequest(ctx, req)
+		if err != nil {
+			return events.APIGatewayV2HTTPResponse{}, err
+		}
+		w := newResponseWriter()
+		h.ServeHTTP(w, r)
+		return w.response(), nil
+	}
+}
+
+// NewRequest は API Gateway のイベントを *http.Request に変換します。
+// 接続元は API Gateway が観測した送信元 IP（requestContext.http.sourceIp）を RemoteAddr に設定します
+// （httpx.ClientIP はプロキシヘッダーを信頼しないため、レートリミットとアクセスログはこの値を使います）。
+func NewRequest(ctx context.Context, req events.APIGatewayV2HTTPRequest) (*http.Request, error) {
+	body := []byte(req.Body)
+	if req.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("apigw: decode body: %w", err)
+		}
+		body = decoded
+	}
+
+	target := req.RawPath
+	if target == "" {
+		target = "/"
+	}
+	if req.RawQueryString != "" {
+		target += "?" + req.RawQueryString
+	}
+	r, err := http.NewRequestWithContext(ctx, req.RequestContext.HTTP.Method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("apigw: build request: %w", err)
+	}
+
+	// HTTP API は同名ヘッダーをカンマ区切りで 1 つにまとめ、Cookie は cookies に分けて渡す
+	for k, v := range req.Headers {
+		r.Header.Set(k, v)
+	}
+	if len(req.Cookies) > 0 {
+		r.Header.Set("Cookie", strings.Join(req.Cookies, "; "))
+	}
+	r.Host = req.RequestContext.DomainName
+	if host := r.Header.Get("Host"); host != "" {
+		r.Host = host
+	}
+	r.ContentLength = int64(len(body))
+	r.RemoteAddr = net.JoinHostPort(req.RequestContext.HTTP.SourceIP, "0")
+	r.RequestURI = target
+	return r, nil
+}
+
+// responseWriter は応答をメモリにバッファする http.ResponseWriter です。
+type responseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+var _ http.ResponseWriter = (*responseWriter)(nil)
+
+func newResponseWriter() *responseWriter {
+	return &responseWriter{header: make(http.Header)}
+}
+
+func (w *responseWriter) Header() http.Header { return w.header }
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(b)
+}
+
+// response はバッファした応答を API Gateway の形式に変換します。
+// Set-Cookie は cookies に分け、他の同名ヘッダーはカンマ区切りでまとめます。
+// UTF-8 として読めない本文（画像・アーカイブ等）は base64 で返します。
+func (w *responseWriter) response() events.APIGatewayV2HTTPResponse {
+	status := w.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	res := events.APIGatewayV2HTTPResponse{
+		StatusCode: status,
+		Headers:    make(map[string]string, len(w.header)),
+	}
+	for k, vs := range w.header {
+		if k == "Set-Cookie" {
+			res.Cookies = append(res.Cookies, vs...)
+			continue
+		}
+		res.Headers[k] = strings.Join(vs, ", ")
+	}
+	if b := w.body.Bytes(); utf8.Valid(b) {
+		res.Body = string(b)
+	} else {
+		res.Body = base64.StdEncoding.EncodeToString(b)
+		res.IsBase64Encoded = true
+	}
+	return res
+}
@@ -0,0 +1,163 @@
+package apigw_test
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/apigw"
+)
+
+func newEvent(method, path string) events.APIGatewayV2HTTPRequest {
+	return events.APIGatewayV2HTTPRequest{
+		RawPath: path,
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			DomainName: "api.example.com",
+			HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{
+				Method:   method,
+				SourceIP: "203.0.113.7",
+			},
+		},
+	}
+}
+
+func TestNewRequest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		event    func() events.APIGatewayV2HTTPRequest
+		wantBody string
+		wantErr  bool
+	}{
+		{
+			name: "plain body",
+			event: func() events.APIGatewayV2HTTPRequest {
+				e := newEvent(http.MethodPost, "/v1/login")
+				e.Body = `{"email":"a@example.com"}`
+				return e
+			},
+			wantBody: `{"email":"a@example.com"}`,
+		},
+		{
+			name: "base64 body",
+			event: func() events.APIGatewayV2HTTPRequest {
+				e := newEvent(http.MethodPost, "/v1/login")
+				e.Body = base64.StdEncoding.EncodeToString([]byte("binary\x00data"))
+				e.IsBase64Encoded = true
+				return e
+			},
+			wantBody: "binary\x00data",
+		},
+		{
+			name: "invalid base64 body",
+			event: func() events.APIGatewayV2HTTPRequest {
+				e := newEvent(http.MethodPost, "/v1/login")
+				e.Body = "!!not base64"
+				e.IsBase64Encoded = true
+				return e
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r, err := apigw.NewRequest(context.Background(), tt.event())
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			b, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantBody, string(b))
+			assert.Equal(t, int64(len(tt.wantBody)), r.ContentLength)
+		})
+	}
+}
+
+func TestNewRequest_URLHeadersAndRemoteAddr(t *testing.T) {
+	t.Parallel()
+	e := newEvent(http.MethodGet, "/v1/candles/AAPL")
+	e.RawQueryString = "interval=1day&outputsize=10"
+	e.Headers = map[string]string{"authorization": "Bearer tok", "x-request-id": "req-1"}
+	e.Cookies = []string{"a=1", "b=2"}
+
+	r, err := apigw.NewRequest(context.Background(), e)
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodGet, r.Method)
+	assert.Equal(t, "/v1/candles/AAPL", r.URL.Path)
+	assert.Equal(t, "1day", r.URL.Query().Get("interval"))
+	assert.Equal(t, "Bearer tok", r.Header.Get("Authorization"))
+	assert.Equal(t, "req-1", r.Header.Get("X-Request-Id"))
+	assert.Equal(t, "a=1; b=2", r.Header.Get("Cookie"))
+	assert.Equal(t, "api.example.com", r.Host)
+	assert.Equal(t, "203.0.113.7:0", r.RemoteAddr)
+}
+
+func TestHandler_Response(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		handler     http.HandlerFunc
+		wantStatus  int
+		wantBody    string
+		wantBase64  bool
+		wantHeaders map[string]string
+		wantCookies []string
+	}{
+		{
+			name: "json with cookies",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				http.SetCookie(w, &http.Cookie{Name: "a", Value: "1"})
+				http.SetCookie(w, &http.Cookie{Name: "b", Value: "2"})
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Add("Vary", "Accept")
+				w.Header().Add("Vary", "Origin")
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte(`{"ok":true}`))
+			},
+			wantStatus:  http.StatusCreated,
+			wantBody:    `{"ok":true}`,
+			wantHeaders: map[string]string{"Content-Type": "application/json", "Vary": "Accept, Origin"},
+			wantCookies: []string{"a=1", "b=2"},
+		},
+		{
+			name: "binary body is base64 encoded",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte{0xff, 0xfe, 0x00})
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   base64.StdEncoding.EncodeToString([]byte{0xff, 0xfe, 0x00}),
+			wantBase64: true,
+		},
+		{
+			name:       "no write defaults to 200",
+			handler:    func(http.ResponseWriter, *http.Request) {},
+			wantStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			res, err := apigw.Handler(tt.handler)(context.Background(), newEvent(http.MethodGet, "/"))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, res.StatusCode)
+			assert.Equal(t, tt.wantBody, res.Body)
+			assert.Equal(t, tt.wantBase64, res.IsBase64Encoded)
+			for k, v := range tt.wantHeaders {
+				assert.Equal(t, v, res.Headers[k], k)
+			}
+			assert.Equal(t, tt.wantCookies, res.Cookies)
+			assert.NotContains(t, res.Headers, "Set-Cookie")
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// Package apikey はサーバー間連携向けの静的APIキー認証を提供します。
+//
+// APIキーは X-API-Key ヘッダーで受け取り、設定に保持した SHA-256 ハッシュと
+// 定数時間で照合します（平文のキーは設定・メモリのいずれにも保持しません）。
+// 認証に成功したリクエストにはユーザーIDではなく Principal（キーIDとスコープ）を
+// context に格納するため、ユーザー前提のルート（auth・watchlist 等）には到達できません。
+package apikey
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// HeaderName はAPIキーを送信するリクエストヘッダー名です。
+	HeaderName = "X-API-Key"
+
+	// ScopeCandlesRead はローソク足データの読み取りを許可するスコープです。
+	ScopeCandlesRead = "candles:read"
+	// ScopeSymbolsRead は銘柄一覧の読み取りを許可するスコープです。
+	ScopeSymbolsRead = "symbols:read"
+	// ScopePremiumData は premium の銘柄のローソク足・統計・スパークラインの読み取りを許可するスコープです。
+	// 持たないAPIキーは free プランのユーザーと同じく basic の銘柄だけを参照できます。
+	ScopePremiumData = "data:premium"
+	// ScopeCandlesAdmin はローソク足の異常値の参照・確認（/v1/admin/anomalies）、分割調整の係数の管理（/v1/admin/adjustments）と重複行の解消（/v1/admin/candles/dedupe）を許可するスコープです。
+	ScopeCandlesAdmin = "candles:admin"
+	// ScopeSymbolsAdmin は銘柄名の多言語表記の管理（/v1/admin/symbols/{code}/names）を許可するスコープです。
+	ScopeSymbolsAdmin = "symbols:admin"
+	// ScopeJobsAdmin は定期ジョブの参照・即時実行（/v1/admin/jobs）を許可するスコープです。
+	ScopeJobsAdmin = "jobs:admin"
+)
+
+// knownScopes は設定で指定可能なスコープの一覧です。
+var knownScopes = []string{ScopeCandlesRead, ScopeSymbolsRead, ScopePremiumData, ScopeCandlesAdmin, ScopeSymbolsAdmin, ScopeJobsAdmin}
+
+// retiredScopes は廃止したスコープと、代わりの手段の説明です。設定に残っている場合は起動時に理由付きで拒否します。
+var retiredScopes = map[string]string{
+	"users:admin":       "user administration (/v1/admin/users) requires an admin user and is no longer reachable with api keys",
+	"flags:admin":       "feature flags (/v1/admin/flags) require an admin user and are no longer reachable with api keys",
+	"users:impersonate": "impersonation (/v1/admin/users/{id}/impersonate) requires an admin user and is no longer reachable with api keys",
+}
+
+// Key は設定済みのAPIキー1件を表します。
+// 同じ ID を持つ Key を複数登録することで、新旧キーを並行運用するローテーションに対応します。
+type Key struct {
+	ID     string            // クライアント識別子（ログ・レートリミットのキーに使用）
+	Hash   [sha256.Size]byte // APIキー平文の SHA-256 ハッシュ
+	Scopes []string          // 付与するスコープ
+}
+
+// Principal はAPIキー認証で確立された呼び出し元を表します。
+type Principal struct {
+	KeyID  string
+	Scopes []string
+}
+
+// HasScope は Principal が指定スコープを持つかを返します。
+func (p Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+// ctxKey は context へ値を格納するための非公開キー型です。
+type ctxKey struct{}
+
+// WithPrincipal は context にAPIキーの Principal を格納した新しい context を返します。
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, ctxKey{}, p)
+}
+
+// PrincipalFromContext は context からAPIキーの Principal を取り出します。
+// APIキー認証を通過したリクエストでのみ ok=true を返します。
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(ctxKey{}).(Principal)
+	return p, ok
+}
+
+// HashKey はAPIキー平文の SHA-256 ハッシュを hex 文字列で返します。
+// 運用者が API_KEYS に登録する値を生成する用途に使います。
+func HashKey(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+// match は plain に一致する Key を返します。
+// 早期リターンによるタイミング差を避けるため、全キーと定数時間で比較します。
+func match(keys []Key, plain string) (Key, bool) {
+	sum := sha256.Sum256([]byte(plain))
+	var (
+		found Key
+		ok    bool
+	)
+	for _, k := range keys {
+		if subtle.ConstantTimeCompare(k.Hash[:], sum[:]) == 1 && !ok {
+			found, ok = k, true
+		}
+	}
+	return found, ok
+}
+
+// ParseKeys は API_KEYS 環境変数の生文字列を Key のスライスに変換します。
+//
+// 形式はカンマ区切りの "<id>:<sha256 hex>:<scope>[|<scope>...]" です。
+//
+//	analytics:9f86d0...:candles:read|symbols:read
+//
+// raw が空の場合は nil を返します（APIキー認証無効）。
+func ParseKeys(raw string) ([]Key, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var keys []Key
+	for entry := range strings.SplitSeq(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, rest, ok1 := strings.Cut(entry, ":")
+		hashHex, scopesRaw, ok2 := strings.Cut(rest, ":")
+		if !ok1 || !ok2 || id == "" {
+			return nil, fmt.Errorf("invalid api key entry %q: want <id>:<sha256 hex>:<scopes>", id)
+		}
+		hash, err := hex.DecodeString(hashHex)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("invalid api key hash for %q: must be %d-byte hex", id, sha256.Size)
+		}
+		var scopes []string
+		for s := range strings.SplitSeq(scopesRaw, "|") {
+			s = strings.TrimSpace(s)
+			if reason, ok := retiredScopes[s]; ok {
+				return nil, fmt.Errorf("retired api key scope %q for %q: %s", s, id, reason)
+			}
+			if !slices.Contains(knownScopes, s) {
+				return nil, fmt.Errorf("unknown api key scope %q for %q", s, id)
+			}
+			scopes = append(scopes, s)
+		}
+		k := Key{ID: id, Scopes: scopes}
+		copy(k.Hash[:], hash)
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
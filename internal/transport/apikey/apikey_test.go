@@ -0,0 +1,81 @@
+package apikey
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKeys(t *testing.T) {
+	t.Parallel()
+
+	h1 := HashKey("secret-1")
+	h2 := HashKey("secret-2")
+
+	tests := []struct {
+		name    string
+		raw     string
+		wantIDs []string
+		wantErr bool
+	}{
+		{name: "空文字は無効（nil）", raw: "", wantIDs: nil},
+		{name: "単一キー", raw: "analytics:" + h1 + ":candles:read", wantIDs: []string{"analytics"}},
+		{
+			name:    "ローテーション用に同一IDの複数キー",
+			raw:     "analytics:" + h1 + ":candles:read|symbols:read, analytics:" + h2 + ":candles:read",
+			wantIDs: []string{"analytics", "analytics"},
+		},
+		{name: "区切り不足はエラー", raw: "analytics:" + h1, wantErr: true},
+		{name: "ハッシュ長不正はエラー", raw: "analytics:abcd:candles:read", wantErr: true},
+		{name: "未知のスコープはエラー", raw: "analytics:" + h1 + ":admin", wantErr: true},
+		{name: "ID 空はエラー", raw: ":" + h1 + ":candles:read", wantErr: true},
+		{name: "廃止したスコープはエラー", raw: "support:" + h1 + ":users:admin", wantErr: true},
+		{name: "廃止したスコープはエラー（flags:admin）", raw: "ops:" + h1 + ":flags:admin", wantErr: true},
+		{name: "廃止したスコープはエラー（users:impersonate）", raw: "support:" + h1 + ":users:impersonate", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			keys, err := ParseKeys(tt.raw)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			var ids []string
+			for _, k := range keys {
+				ids = append(ids, k.ID)
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+		})
+	}
+}
+
+// TestParseKeys_RetiredScope は廃止したスコープの設定を、代わりの手段が分かるエラーで拒否することを検証します。
+func TestParseKeys_RetiredScope(t *testing.T) {
+	t.Parallel()
+
+	_, err := ParseKeys("support:" + HashKey("secret") + ":candles:read|users:admin")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "retired api key scope \"users:admin\"")
+	assert.Contains(t, err.Error(), "admin user")
+}
+
+func TestParseKeys_ScopesAndHash(t *testing.T) {
+	t.Parallel()
+
+	keys, err := ParseKeys("analytics:" + strings.ToUpper(HashKey("secret")) + ":candles:read|symbols:read")
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, []string{ScopeCandlesRead, ScopeSymbolsRead}, keys[0].Scopes)
+
+	got, ok := match(keys, "secret")
+	assert.True(t, ok)
+	assert.Equal(t, "analytics", got.ID)
+
+	_, ok = match(keys, "wrong")
+	assert.False(t, ok)
+}
@@ -0,0 +1,82 @@
+package apikey
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpratelimit"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+)
+
+// rateLimitPrefix はキーごとのレートリミットに使う Redis キーのプレフィックスです。
+const rateLimitPrefix = "rl:apikey"
+
+// Config はAPIキー認証ミドルウェアの設定を保持します。
+type Config struct {
+	Keys   []Key         // 有効なAPIキー（空ならAPIキーでの認証はすべて失敗）
+	Limit  int           // キーごとのウィンドウ内最大リクエスト数
+	Window time.Duration // レートリミットのスライディングウィンドウ幅
+}
+
+// Authenticate はAPIキーまたは fallback（JWT認証）のいずれかで認証するミドルウェアを返します。
+//   - X-API-Key ヘッダーがない場合は fallback に委譲します
+//   - X-API-Key ヘッダーがある場合はキーを照合し、キーIDごとのレートリミットを適用したうえで
+//     Principal を context に格納します。不一致は 401、レート超過は 429 を返します
+//
+// スコープの検証は RequireScope で経路ごとに行います。
+func Authenticate(limiter *httpratelimit.Limiter, cfg Config, fallback func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		viaFallback := fallback(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			plain := r.Header.Get(HeaderName)
+			if plain == "" {
+				viaFallback.ServeHTTP(w, r)
+				return
+			}
+
+			key, ok := match(cfg.Keys, plain)
+			if !ok {
+				slog.Warn("invalid api key", "ip", httpx.ClientIP(r))
+				httpx.WriteJSON(w, http.StatusUnauthorized, api.ErrorResponse{Error: "invalid api key"})
+				return
+			}
+
+			result := limiter.Allow(r.Context(), fmt.Sprintf("%s:%s", rateLimitPrefix, key.ID), cfg.Limit, cfg.Window)
+			if !result.Allowed {
+				slog.Warn("rate limit exceeded", "type", "api_key", "key_id", key.ID)
+				w.Header().Set("Retry-After", strconv.Itoa(int(result.RetryAfter.Seconds())))
+				httpx.WriteJSON(w, http.StatusTooManyRequests, api.ErrorResponse{Error: "too many requests"})
+				return
+			}
+
+			ctx := WithPrincipal(r.Context(), Principal{KeyID: key.ID, Scopes: key.Scopes})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// KeyRequired は Authenticate の fallback に渡し、APIキーのないリクエストを 401 で拒否します。
+// 管理系ルートなど、JWT（ユーザー）での到達を許可しない経路に使います。
+func KeyRequired(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httpx.WriteJSON(w, http.StatusUnauthorized, api.ErrorResponse{Error: "api key required"})
+	})
+}
+
+// RequireScope はAPIキーで認証されたリクエストが scope を持つことを要求するミドルウェアを返します。
+// JWT（ユーザー）で認証されたリクエストはスコープの概念を持たないため、そのまま通過させます。
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if p, ok := PrincipalFromContext(r.Context()); ok && !p.HasScope(scope) {
+				httpx.WriteJSON(w, http.StatusForbidden, api.ErrorResponse{Error: "insufficient scope"})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package apikey
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	infraredis "github.com/UCHIDAnobuhiro/stock-backend/internal/infra/redis"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpratelimit"
+)
+
+// fallbackMarker は fallback（JWT認証）に委譲されたことを示すヘッダーを付与するテスト用ミドルウェアです。
+func fallbackMarker(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Fallback", "1")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// principalHandler は context の Principal を X-Key-ID ヘッダーで返す終端ハンドラーです。
+func principalHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p, ok := PrincipalFromContext(r.Context()); ok {
+			w.Header().Set("X-Key-ID", p.KeyID)
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func testConfig(t *testing.T) Config {
+	t.Helper()
+	keys, err := ParseKeys(
+		"analytics:" + HashKey("current") + ":candles:read," +
+			"analytics:" + HashKey("previous") + ":candles:read," +
+			"listing:" + HashKey("symbols-only") + ":symbols:read",
+	)
+	require.NoError(t, err)
+	return Config{Keys: keys, Limit: 100, Window: time.Minute}
+}
+
+func TestAuthenticate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		apiKey       string
+		wantStatus   int
+		wantKeyID    string
+		wantFallback bool
+	}{
+		{name: "ヘッダーなしは fallback に委譲", apiKey: "", wantStatus: http.StatusOK, wantFallback: true},
+		{name: "有効なキー", apiKey: "current", wantStatus: http.StatusOK, wantKeyID: "analytics"},
+		{name: "ローテーション中の旧キーも有効", apiKey: "previous", wantStatus: http.StatusOK, wantKeyID: "analytics"},
+		{name: "無効なキーは 401", apiKey: "unknown", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := Authenticate(httpratelimit.NewLimiter(nil, infraredis.KeyBuilder{}), testConfig(t), fallbackMarker)(principalHandler())
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/candles/AAPL", nil)
+			if tt.apiKey != "" {
+				req.Header.Set(HeaderName, tt.apiKey)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantKeyID, w.Header().Get("X-Key-ID"))
+			assert.Equal(t, tt.wantFallback, w.Header().Get("X-Fallback") == "1")
+		})
+	}
+}
+
+func TestAuthenticate_RateLimitedPerKey(t *testing.T) {
+	t.Parallel()
+
+	rdb, mock := redismock.NewClientMock()
+	defer func() { _ = rdb.Close() }()
+	// ARGV（タイムスタンプ等）は実行ごとに変わるため CustomMatch で無視する
+	match := mock.CustomMatch(func(expected, actual []interface{}) error { return nil })
+	httpratelimit.ExpectAllow(match, "rl:apikey:analytics", false, 100)
+
+	h := Authenticate(httpratelimit.NewLimiter(rdb, infraredis.KeyBuilder{}), testConfig(t), fallbackMarker)(principalHandler())
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/candles/AAPL", nil)
+	req.Header.Set(HeaderName, "current")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRequireScope(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		apiKey     string
+		scope      string
+		wantStatus int
+	}{
+		{name: "スコープあり", apiKey: "current", scope: ScopeCandlesRead, wantStatus: http.StatusOK},
+		{name: "スコープなしは 403", apiKey: "symbols-only", scope: ScopeCandlesRead, wantStatus: http.StatusForbidden},
+		{name: "JWT 認証（Principal なし）は通過", apiKey: "", scope: ScopeCandlesRead, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := Authenticate(httpratelimit.NewLimiter(nil, infraredis.KeyBuilder{}), testConfig(t), fallbackMarker)(
+				RequireScope(tt.scope)(principalHandler()),
+			)
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/candles/AAPL", nil)
+			if tt.apiKey != "" {
+				req.Header.Set(HeaderName, tt.apiKey)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestKeyRequired(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		apiKey     string
+		wantStatus int
+	}{
+		{name: "APIキーなしは 401", apiKey: "", wantStatus: http.StatusUnauthorized},
+		{name: "有効なキーは通過", apiKey: "current", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := Authenticate(httpratelimit.NewLimiter(nil, infraredis.KeyBuilder{}), testConfig(t), KeyRequired)(principalHandler())
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/admin/flags", nil)
+			if tt.apiKey != "" {
+				req.Header.Set(HeaderName, tt.apiKey)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,208 @@
+// Package deprecation は廃止予定のルートに Deprecation / Sunset / Link ヘッダーを付け、
+// 廃止予定のルートをまだ使っているクライアントを数えるミドルウェアを提供します。
+//
+// 対象のルートと廃止日は設定（DEPRECATED_ROUTES）で指定するため、日付の変更にコードの変更は要りません。
+package deprecation
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/apikey"
+)
+
+// EnvKeyRoutes は廃止予定のルートの環境変数キーです。
+const EnvKeyRoutes = "DEPRECATED_ROUTES"
+
+// maxClients はルートごとに区別して数えるクライアントの上限です。超えた分は "other" にまとめます（メモリを有界にするため）。
+const maxClients = 1000
+
+// Rule は廃止予定のルート 1 件です。
+type Rule struct {
+	Method    string    // HTTP メソッド（大文字）
+	Route     string    // ルーターに登録したパターン（例: /v1/candles/{code}）
+	Sunset    time.Time // 廃止日（Sunset ヘッダー）
+	Successor string    // 移行先（Link ヘッダーの rel="successor-version"）。空なら Link を付けない
+}
+
+// key は Rule を照合するキー（"<METHOD> <パターン>"）です。
+func (r Rule) key() string {
+	return r.Method + " " + r.Route
+}
+
+// ParseRules は DEPRECATED_ROUTES の各要素（"<METHOD> <ルートのパターン>|<廃止日 YYYY-MM-DD>[|<移行先>]"）を検証して Rule にします。
+//
+//	GET /v1/candles/{code}|2027-03-31|/v2/candles/{code}
+func ParseRules(entries []string) ([]Rule, error) {
+	rules := make([]Rule, 0, len(entries))
+	seen := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		parts := strings.Split(e, "|")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("invalid entry %q: want \"<METHOD> <route pattern>|<sunset YYYY-MM-DD>[|<successor>]\"", e)
+		}
+		fields := strings.Fields(parts[0])
+		if len(fields) != 2 || !strings.HasPrefix(fields[1], "/") {
+			return nil, fmt.Errorf("invalid entry %q: want \"<METHOD> <route pattern>\" before the first |", e)
+		}
+		sunset, err := time.Parse(time.DateOnly, strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid entry %q: sunset must be YYYY-MM-DD", e)
+		}
+		rule := Rule{Method: strings.ToUpper(fields[0]), Route: fields[1], Sunset: sunset}
+		if len(parts) == 3 {
+			rule.Successor = strings.TrimSpace(parts[2])
+			if !strings.HasPrefix(rule.Successor, "/") && !strings.HasPrefix(rule.Successor, "https://") {
+				return nil, fmt.Errorf("invalid entry %q: successor must be a path or an https URL", e)
+			}
+		}
+		if seen[rule.key()] {
+			return nil, fmt.Errorf("duplicate entry for %q", rule.key())
+		}
+		seen[rule.key()] = true
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Hit は廃止予定のルートをクライアントが呼び出した回数です。
+type Hit struct {
+	Route  string // "<METHOD> <パターン>"
+	Client string // "apikey:<キーID>" または "ua:<User-Agent の製品名>"
+	Count  uint64
+}
+
+// Tracker は廃止予定のルートに応答ヘッダーを付け、クライアントごとの呼び出し回数を数えます。
+// nil の Tracker の Middleware は何もしません。
+type Tracker struct {
+	rules map[string]Rule
+
+	mu      sync.Mutex
+	hits    map[string]map[string]uint64 // ルート → クライアント → 回数
+	clients map[string]int               // ルートごとのクライアントの数（maxClients の判定用）
+}
+
+// NewTracker は rules の Tracker を生成します。rules が空なら nil を返します（無効）。
+func NewTracker(rules []Rule) *Tracker {
+	if len(rules) == 0 {
+		return nil
+	}
+	t := &Tracker{
+		rules:   make(map[string]Rule, len(rules)),
+		hits:    make(map[string]map[string]uint64),
+		clients: make(map[string]int),
+	}
+	for _, r := range rules {
+		t.rules[r.key()] = r
+	}
+	return t
+}
+
+// Middleware はルーティング後（グループの r.Use）に置くミドルウェアを返します。
+// 廃止予定のルートには Deprecation: true、Sunset（RFC 1123 形式の廃止日）、移行先があれば Link ヘッダーを付け、
+// 呼び出し元をクライアントごとに数えます。クライアントごとの初回の呼び出しはログに出します。
+// APIキーのクライアントを区別するため、apikey.Authenticate より後に置いてください。
+func (t *Tracker) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if t == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rc := chi.RouteContext(r.Context())
+			if rc == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			rule, ok := t.rules[r.Method+" "+rc.RoutePattern()]
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			h := w.Header()
+			h.Set("Deprecation", "true")
+			h.Set("Sunset", rule.Sunset.UTC().Format(http.TimeFormat))
+			if rule.Successor != "" {
+				h.Add("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", rule.Successor))
+			}
+			client := clientKey(r)
+			if t.record(rule.key(), client) {
+				slog.WarnContext(r.Context(), "deprecated route used",
+					"route", rule.key(), "client", client, "sunset", rule.Sunset.Format(time.DateOnly), "successor", rule.Successor)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// record はルートとクライアントの呼び出しを数え、そのクライアントの初回なら true を返します。
+func (t *Tracker) record(route, client string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	byClient := t.hits[route]
+	if byClient == nil {
+		byClient = make(map[string]uint64)
+		t.hits[route] = byClient
+	}
+	if _, ok := byClient[client]; !ok && t.clients[route] >= maxClients {
+		client = "other"
+	}
+	byClient[client]++
+	if byClient[client] > 1 {
+		return false
+	}
+	t.clients[route]++
+	return true
+}
+
+// Stats はこれまでの呼び出し回数をルート・クライアントの順に並べて返します。
+func (t *Tracker) Stats() []Hit {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var out []Hit
+	for route, byClient := range t.hits {
+		for client, n := range byClient {
+			out = append(out, Hit{Route: route, Client: client, Count: n})
+		}
+	}
+	slices.SortFunc(out, func(a, b Hit) int {
+		return cmp.Or(cmp.Compare(a.Route, b.Route), cmp.Compare(a.Client, b.Client))
+	})
+	return out
+}
+
+// clientKey は呼び出し元を数えるキーです。APIキーで認証したリクエストはキーID、それ以外は User-Agent の製品名です。
+func clientKey(r *http.Request) string {
+	if p, ok := apikey.PrincipalFromContext(r.Context()); ok {
+		return "apikey:" + p.KeyID
+	}
+	return "ua:" + userAgentFamily(r.UserAgent())
+}
+
+// userAgentFamily は User-Agent の先頭の製品名（バージョンを除く・小文字）を返します。
+// ブラウザは "Mozilla/5.0 (...)" で始まるため "browser" にまとめます。
+func userAgentFamily(ua string) string {
+	product, _, _ := strings.Cut(strings.TrimSpace(ua), " ")
+	name, _, _ := strings.Cut(product, "/")
+	name = strings.ToLower(name)
+	switch {
+	case name == "":
+		return "unknown"
+	case name == "mozilla":
+		return "browser"
+	case len(name) > 32:
+		return name[:32]
+	default:
+		return name
+	}
+}
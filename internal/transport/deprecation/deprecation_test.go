@@ -0,0 +1,181 @@
+package deprecation
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/apikey"
+)
+
+func TestParseRules(t *testing.T) {
+	t.Parallel()
+
+	got, err := ParseRules([]string{
+		"get /v1/candles/{code}|2027-03-31|/v2/candles/{code}",
+		"DELETE  /v1/watchlist/{code} | 2027-06-30",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Rule{
+		{Method: "GET", Route: "/v1/candles/{code}", Sunset: time.Date(2027, 3, 31, 0, 0, 0, 0, time.UTC), Successor: "/v2/candles/{code}"},
+		{Method: "DELETE", Route: "/v1/watchlist/{code}", Sunset: time.Date(2027, 6, 30, 0, 0, 0, 0, time.UTC)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseRules = %+v, want %+v", got, want)
+	}
+
+	for _, entries := range [][]string{
+		{"GET /v1/candles/{code}"},
+		{"/v1/candles/{code}|2027-03-31"},
+		{"GET v1/candles|2027-03-31"},
+		{"GET /v1/candles/{code}|2027/03/31"},
+		{"GET /v1/candles/{code}|2027-03-31|http://example.com/v2"},
+		{"GET /v1/candles/{code}|2027-03-31|/v2|extra"},
+		{"GET /v1/stats|2027-03-31", "get /v1/stats|2027-04-30"},
+	} {
+		if _, err := ParseRules(entries); err == nil {
+			t.Errorf("ParseRules(%q) expected error", entries)
+		}
+	}
+}
+
+// newTestRouter は tracker のミドルウェアをグループに置き、/v1/candles/{code} と /v1/stats を登録したルーターを返します。
+// keyID が空でなければ、そのキーIDのAPIキーで認証済みのリクエストとして扱います。
+func newTestRouter(tracker *Tracker, keyID string) http.Handler {
+	r := chi.NewRouter()
+	r.Route("/v1", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					if keyID != "" {
+						req = req.WithContext(apikey.WithPrincipal(req.Context(), apikey.Principal{KeyID: keyID}))
+					}
+					next.ServeHTTP(w, req)
+				})
+			})
+			r.Use(tracker.Middleware())
+			r.Get("/candles/{code}", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+			r.Get("/stats", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+		})
+	})
+	return r
+}
+
+// TestTracker_Middleware は設定したルートにだけ Deprecation / Sunset / Link ヘッダーを付けることを検証します。
+func TestTracker_Middleware(t *testing.T) {
+	t.Parallel()
+
+	rules, err := ParseRules([]string{"GET /v1/candles/{code}|2027-03-31|/v2/candles/{code}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := newTestRouter(NewTracker(rules), "")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/candles/AAPL", nil))
+	if got := rec.Header().Get("Deprecation"); got != "true" {
+		t.Errorf("Deprecation = %q, want true", got)
+	}
+	if got := rec.Header().Get("Sunset"); got != "Wed, 31 Mar 2027 00:00:00 GMT" {
+		t.Errorf("Sunset = %q, want the configured date", got)
+	}
+	if got := rec.Header().Get("Link"); got != `</v2/candles/{code}>; rel="successor-version"` {
+		t.Errorf("Link = %q", got)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/stats", nil))
+	for _, h := range []string{"Deprecation", "Sunset", "Link"} {
+		if got := rec.Header().Get(h); got != "" {
+			t.Errorf("non-deprecated route: %s = %q, want empty", h, got)
+		}
+	}
+}
+
+// TestTracker_Nil は無効（nil）の Tracker がリクエストに何もしないことを検証します。
+func TestTracker_Nil(t *testing.T) {
+	t.Parallel()
+
+	tracker := NewTracker(nil)
+	if tracker != nil {
+		t.Fatalf("NewTracker(nil) = %v, want nil", tracker)
+	}
+	rec := httptest.NewRecorder()
+	newTestRouter(tracker, "").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/candles/AAPL", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Deprecation") != "" {
+		t.Errorf("status = %d, Deprecation = %q, want 200 without the header", rec.Code, rec.Header().Get("Deprecation"))
+	}
+	if got := tracker.Stats(); got != nil {
+		t.Errorf("Stats = %v, want nil", got)
+	}
+}
+
+// TestTracker_Stats はAPIキーのID・User-Agent の製品名ごとに呼び出しを数え、初回だけログに出すことを検証します。
+// slog のデフォルトロガーを差し替えるため並列実行しません。
+func TestTracker_Stats(t *testing.T) {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	rules, err := ParseRules([]string{"GET /v1/candles/{code}|2027-03-31"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker := NewTracker(rules)
+	call := func(keyID, ua string) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/v1/candles/AAPL", nil)
+		req.Header.Set("User-Agent", ua)
+		newTestRouter(tracker, keyID).ServeHTTP(httptest.NewRecorder(), req)
+	}
+	call("ops", "curl/8.5.0")
+	call("ops", "curl/8.5.0")
+	call("", "Mozilla/5.0 (Macintosh)")
+	call("", "Mozilla/5.0 (iPhone)")
+	call("", "okhttp/4.12.0")
+	call("", "")
+	// 廃止予定でないルートは数えない
+	newTestRouter(tracker, "").ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/stats", nil))
+
+	want := []Hit{
+		{Route: "GET /v1/candles/{code}", Client: "apikey:ops", Count: 2},
+		{Route: "GET /v1/candles/{code}", Client: "ua:browser", Count: 2},
+		{Route: "GET /v1/candles/{code}", Client: "ua:okhttp", Count: 1},
+		{Route: "GET /v1/candles/{code}", Client: "ua:unknown", Count: 1},
+	}
+	if got := tracker.Stats(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Stats = %+v, want %+v", got, want)
+	}
+	if got := strings.Count(buf.String(), `"msg":"deprecated route used"`); got != len(want) {
+		t.Errorf("logged %d first hits, want %d:\n%s", got, len(want), buf.String())
+	}
+}
+
+// TestTracker_MaxClients はクライアントの数が上限を超えた分を "other" にまとめることを検証します。
+func TestTracker_MaxClients(t *testing.T) {
+	t.Parallel()
+
+	tracker := NewTracker([]Rule{{Method: "GET", Route: "/v1/stats", Sunset: time.Now()}})
+	for i := range maxClients + 5 {
+		tracker.record("GET /v1/stats", "apikey:"+strings.Repeat("k", i+1))
+	}
+	stats := tracker.Stats()
+	if len(stats) != maxClients+1 {
+		t.Fatalf("clients = %d, want %d", len(stats), maxClients+1)
+	}
+	for _, h := range stats {
+		if h.Client == "other" && h.Count != 5 {
+			t.Errorf("other = %d, want 5", h.Count)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+)
+
+// allowCandidates は 405 の Allow ヘッダーに列挙するかを確認するメソッドです（ルーターに登録し得るもの）。
+var allowCandidates = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+}
+
+// NotFound は一致するルートがないリクエストに 404 を返します。
+// chi の既定（text/plain の "404 page not found"）の代わりに使い、他のエンドポイントと同じ ErrorResponse の形にします。
+func NotFound(w http.ResponseWriter, _ *http.Request) {
+	httpx.WriteJSON(w, http.StatusNotFound, api.ErrorResponse{Error: "not found"})
+}
+
+// MethodNotAllowed はパスは一致するがメソッドが一致しないリクエストに 405 を ErrorResponse で返すハンドラーを返します。
+// chi の既定と同じく、routes（ルートのルーター）でそのパスに登録されているメソッドを Allow ヘッダーに列挙します。
+func MethodNotAllowed(routes chi.Routes) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		for _, m := range allowCandidates {
+			if routes.Match(chi.NewRouteContext(), m, r.URL.Path) {
+				w.Header().Add("Allow", m)
+			}
+		}
+		httpx.WriteJSON(w, http.StatusMethodNotAllowed, api.ErrorResponse{Error: "method not allowed"})
+	}
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// newFallbackRouter は /v1 のサブルーターを持つ、NotFound・MethodNotAllowed を設定したテスト用ルーターを返します。
+func newFallbackRouter() http.Handler {
+	r := chi.NewRouter()
+	r.NotFound(NotFound)
+	r.MethodNotAllowed(MethodNotAllowed(r))
+	r.Route("/v1", func(r chi.Router) {
+		r.Get("/items/{id}", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+		r.Delete("/items/{id}", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	})
+	return r
+}
+
+// TestFallback はサブルーター配下を含め、未知のパスは 404、メソッドの不一致は Allow 付きの 405 を ErrorResponse で返すことを検証します。
+func TestFallback(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		wantCode  int
+		wantBody  string
+		wantAllow []string
+	}{
+		{"unknown top-level path", http.MethodGet, "/nope", http.StatusNotFound, `{"error":"not found"}`, nil},
+		{"unknown path under subrouter", http.MethodGet, "/v1/nope", http.StatusNotFound, `{"error":"not found"}`, nil},
+		{"method mismatch", http.MethodPost, "/v1/items/1", http.StatusMethodNotAllowed, `{"error":"method not allowed"}`, []string{"GET", "DELETE"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			w := httptest.NewRecorder()
+			newFallbackRouter().ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.wantCode {
+				t.Errorf("expected status %d, got %d", tt.wantCode, w.Code)
+			}
+			if got := w.Body.String(); got != tt.wantBody+"\n" {
+				t.Errorf("expected body %s, got %s", tt.wantBody, got)
+			}
+			if got := w.Header().Get("Content-Type"); got != "application/json; charset=utf-8" {
+				t.Errorf("expected JSON content type, got %q", got)
+			}
+			allow := w.Header().Values("Allow")
+			if len(allow) != len(tt.wantAllow) {
+				t.Fatalf("expected Allow %v, got %v", tt.wantAllow, allow)
+			}
+			for i := range allow {
+				if allow[i] != tt.wantAllow[i] {
+					t.Errorf("expected Allow %v, got %v", tt.wantAllow, allow)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/flags"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/jwt"
+)
+
+// flagStore はフラグ管理エンドポイントが必要とする操作です（flags.Registry が実装）。
+type flagStore interface {
+	Snapshot(ctx context.Context) []flags.State
+	Writable() bool
+	Set(ctx context.Context, name string, enabled bool) error
+}
+
+// FlagsHandler はフィーチャーフラグの参照・切り替え用の管理エンドポイントを処理します。
+// 認可（管理者ユーザーのみ）はルーター側のミドルウェア（authhttp.AdminRequired）で行います。
+type FlagsHandler struct {
+	flags flagStore
+}
+
+// NewFlagsHandler は FlagsHandler を生成します。
+func NewFlagsHandler(store flagStore) *FlagsHandler {
+	return &FlagsHandler{flags: store}
+}
+
+// List は定義済みの全フラグの現在値を返します。
+func (h *FlagsHandler) List(w http.ResponseWriter, r *http.Request) {
+	states := h.flags.Snapshot(r.Context())
+	out := api.FlagListResponse{Flags: make([]api.FlagState, 0, len(states)), Writable: h.flags.Writable()}
+	for _, s := range states {
+		out.Flags = append(out.Flags, toFlagState(s))
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+// Update は {name} のフラグを切り替え、切り替え後の状態を返します。切り替えは変更した管理者のユーザーIDとともに監査ログ（audit=true）に記録します。
+func (h *FlagsHandler) Update(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	var req api.UpdateFlagRequest
+	if err := httpx.DecodeAndValidate(r, &req); err != nil {
+		httpx.WriteDecodeError(w, err, "invalid request")
+		return
+	}
+
+	if err := h.flags.Set(r.Context(), name, *req.Enabled); err != nil {
+		httpx.WriteError(w, err, "failed to update flag", "flag", name)
+		return
+	}
+	adminID, _ := jwt.UserIDFromContext(r.Context())
+	slog.InfoContext(r.Context(), "feature flag changed", "audit", true, "actor", adminID, "flag", name, "enabled", *req.Enabled)
+
+	for _, s := range h.flags.Snapshot(r.Context()) {
+		if s.Name == name {
+			httpx.WriteJSON(w, http.StatusOK, toFlagState(s))
+			return
+		}
+	}
+	httpx.WriteError(w, flags.ErrUnknownFlag, "flag disappeared after update", "flag", name)
+}
+
+func toFlagState(s flags.State) api.FlagState {
+	return api.FlagState{
+		Name:        s.Name,
+		Description: s.Description,
+		Default:     s.Default,
+		Enabled:     s.Enabled,
+		Source:      string(s.Source),
+	}
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/flags"
+)
+
+var testFlagDefs = []flags.Definition{
+	{Name: "negative_cache", Default: false, Description: "0 件の結果をキャッシュする"},
+	{Name: "write_through", Default: true, Description: "書き込み後にキャッシュを再生成する"},
+}
+
+// newFlagsRouter は FlagsHandler を本番と同じパスで登録したルーターを返します。
+func newFlagsRouter(store flagStore) http.Handler {
+	h := NewFlagsHandler(store)
+	r := chi.NewRouter()
+	r.Get("/v1/admin/flags", h.List)
+	r.Put("/v1/admin/flags/{name}", h.Update)
+	return r
+}
+
+func listFlags(t *testing.T, router http.Handler) api.FlagListResponse {
+	t.Helper()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/admin/flags", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var out api.FlagListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &out))
+	return out
+}
+
+// TestFlagsHandler_ToggleRoundTrip は PUT で切り替えた値が Redis に保存され、GET に反映されることを検証します。
+func TestFlagsHandler_ToggleRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	registry := flags.NewRegistry(testFlagDefs, nil, flags.NewRedisBackend(rdb, "test:flags"), time.Minute)
+	router := newFlagsRouter(registry)
+
+	before := listFlags(t, router)
+	assert.True(t, before.Writable)
+	assert.Equal(t, []api.FlagState{
+		{Name: "negative_cache", Description: "0 件の結果をキャッシュする", Default: false, Enabled: false, Source: "default"},
+		{Name: "write_through", Description: "書き込み後にキャッシュを再生成する", Default: true, Enabled: true, Source: "default"},
+	}, before.Flags)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/v1/admin/flags/negative_cache", strings.NewReader(`{"enabled":true}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+	var updated api.FlagState
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+	assert.Equal(t, api.FlagState{Name: "negative_cache", Description: "0 件の結果をキャッシュする", Default: false, Enabled: true, Source: "redis"}, updated)
+	assert.Equal(t, "true", mr.HGet("test:flags", "negative_cache"))
+
+	after := listFlags(t, router)
+	assert.True(t, after.Flags[0].Enabled)
+	assert.Equal(t, "redis", after.Flags[0].Source)
+}
+
+func TestFlagsHandler_UpdateErrors(t *testing.T) {
+	t.Parallel()
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	writable := flags.NewRegistry(testFlagDefs, nil, flags.NewRedisBackend(rdb, "test:flags"), time.Minute)
+	readOnly := flags.NewRegistry(testFlagDefs, nil, nil, time.Minute)
+
+	tests := []struct {
+		name       string
+		store      flagStore
+		flag       string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "未定義のフラグは 404", store: writable, flag: "undefined", body: `{"enabled":true}`, wantStatus: http.StatusNotFound, wantError: "unknown_flag"},
+		{name: "enabled 未指定は 400", store: writable, flag: "negative_cache", body: `{}`, wantStatus: http.StatusBadRequest, wantError: "invalid request"},
+		{name: "Redis 未設定は 409", store: readOnly, flag: "negative_cache", body: `{"enabled":true}`, wantStatus: http.StatusConflict, wantError: "flags_read_only"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/v1/admin/flags/"+tt.flag, strings.NewReader(tt.body))
+			newFlagsRouter(tt.store).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			var body api.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.wantError, body.Error)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/scheduler"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+)
+
+// jobScheduler は定期ジョブの管理エンドポイントが必要とする操作です（scheduler.Scheduler が実装）。
+type jobScheduler interface {
+	Jobs(ctx context.Context) ([]scheduler.JobStatus, error)
+	RunNow(ctx context.Context, name string) error
+}
+
+// JobsHandler は定期ジョブの参照・即時実行の管理エンドポイントを処理します。
+// 認可（jobs:admin スコープ）はルーター側のミドルウェアで行います。
+type JobsHandler struct {
+	jobs jobScheduler
+}
+
+// NewJobsHandler は JobsHandler を生成します。
+func NewJobsHandler(jobs jobScheduler) *JobsHandler {
+	return &JobsHandler{jobs: jobs}
+}
+
+// List は登録済みのジョブの予定と直近の実行結果を返します。
+func (h *JobsHandler) List(w http.ResponseWriter, r *http.Request) {
+	jobs, err := h.jobs.Jobs(r.Context())
+	if err != nil {
+		httpx.WriteError(w, err, "failed to list scheduled jobs")
+		return
+	}
+	out := api.JobListResponse{Jobs: make([]api.JobState, 0, len(jobs))}
+	for _, j := range jobs {
+		out.Jobs = append(out.Jobs, toJobState(j))
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+// RunNow は {name} のジョブの即時実行を依頼し、依頼後の状態を 202 で返します。
+func (h *JobsHandler) RunNow(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if err := h.jobs.RunNow(r.Context(), name); err != nil {
+		httpx.WriteError(w, err, "failed to request scheduled job run", "job", name)
+		return
+	}
+
+	jobs, err := h.jobs.Jobs(r.Context())
+	if err != nil {
+		httpx.WriteError(w, err, "failed to load scheduled job", "job", name)
+		return
+	}
+	for _, j := range jobs {
+		if j.Name == name {
+			httpx.WriteJSON(w, http.StatusAccepted, toJobState(j))
+			return
+		}
+	}
+	httpx.WriteError(w, scheduler.ErrUnknownJob, "job disappeared after run-now", "job", name)
+}
+
+func toJobState(j scheduler.JobStatus) api.JobState {
+	st := j.State
+	out := api.JobState{
+		Name:           j.Name,
+		Description:    j.Description,
+		Schedule:       j.Schedule,
+		TimeoutSeconds: int64(j.Timeout.Seconds()),
+		NextRunAt:      api.NewTimestamp(st.NextRunAt),
+		LastStatus:     string(st.LastStatus),
+		LastError:      st.LastError,
+		LastDurationMs: st.LastDuration.Milliseconds(),
+		RunCount:       st.RunCount,
+		FailureCount:   st.FailureCount,
+	}
+	if st.RunRequestedAt != nil {
+		out.RunRequestedAt = api.NewTimestamp(*st.RunRequestedAt)
+	}
+	if st.LastStartedAt != nil {
+		out.LastStartedAt = api.NewTimestamp(*st.LastStartedAt)
+	}
+	if st.LastFinishedAt != nil {
+		out.LastFinishedAt = api.NewTimestamp(*st.LastFinishedAt)
+	}
+	return out
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/scheduler"
+)
+
+// fakeJobScheduler は jobs を返し、RunNow で依頼日時を記録する jobScheduler です。
+type fakeJobScheduler struct {
+	jobs    []scheduler.JobStatus
+	listErr error
+}
+
+func (f *fakeJobScheduler) Jobs(context.Context) ([]scheduler.JobStatus, error) {
+	return f.jobs, f.listErr
+}
+
+func (f *fakeJobScheduler) RunNow(_ context.Context, name string) error {
+	for i := range f.jobs {
+		if f.jobs[i].Name == name {
+			at := time.Date(2026, 3, 1, 10, 15, 0, 0, time.UTC)
+			f.jobs[i].State.RunRequestedAt = &at
+			return nil
+		}
+	}
+	return scheduler.ErrUnknownJob
+}
+
+func newJobsRouter(jobs jobScheduler) http.Handler {
+	h := NewJobsHandler(jobs)
+	r := chi.NewRouter()
+	r.Get("/v1/admin/jobs", h.List)
+	r.Post("/v1/admin/jobs/{name}/run-now", h.RunNow)
+	return r
+}
+
+func testJobs() []scheduler.JobStatus {
+	started := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	finished := started.Add(1500 * time.Millisecond)
+	return []scheduler.JobStatus{
+		{
+			Name: "outbox-retention", Description: "配信済みのイベントを削除する", Schedule: "every 1h0m0s", Timeout: 5 * time.Minute,
+			State: scheduler.State{
+				Name: "outbox-retention", NextRunAt: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC),
+				LastStartedAt: &started, LastFinishedAt: &finished, LastStatus: scheduler.StatusFailed, LastError: "db down",
+				LastDuration: 1500 * time.Millisecond, RunCount: 3, FailureCount: 1,
+			},
+		},
+		{
+			Name: "password-reset-janitor", Schedule: "@daily", Timeout: time.Minute,
+			State: scheduler.State{Name: "password-reset-janitor", NextRunAt: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+}
+
+func TestJobsHandler_List(t *testing.T) {
+	t.Parallel()
+	router := newJobsRouter(&fakeJobScheduler{jobs: testJobs()})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/admin/jobs", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+	var out api.JobListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &out))
+	require.Len(t, out.Jobs, 2)
+	assert.Equal(t, api.JobState{
+		Name: "outbox-retention", Description: "配信済みのイベントを削除する", Schedule: "every 1h0m0s", TimeoutSeconds: 300,
+		NextRunAt:      api.NewTimestamp(time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)),
+		LastStartedAt:  api.NewTimestamp(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)),
+		LastFinishedAt: api.NewTimestamp(time.Date(2026, 3, 1, 9, 0, 1, 0, time.UTC)),
+		LastStatus:     "failed", LastError: "db down", LastDurationMs: 1500, RunCount: 3, FailureCount: 1,
+	}, out.Jobs[0])
+
+	// 一度も実行していないジョブは結果の項目を省略する
+	var raw struct {
+		Jobs []map[string]any `json:"jobs"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &raw))
+	for _, key := range []string{"lastStartedAt", "lastFinishedAt", "lastStatus", "lastError", "runRequestedAt"} {
+		assert.NotContains(t, raw.Jobs[1], key)
+	}
+}
+
+func TestJobsHandler_RunNow(t *testing.T) {
+	t.Parallel()
+	router := newJobsRouter(&fakeJobScheduler{jobs: testJobs()})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/admin/jobs/password-reset-janitor/run-now", nil))
+	require.Equal(t, http.StatusAccepted, w.Code)
+	var out api.JobState
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &out))
+	assert.Equal(t, "password-reset-janitor", out.Name)
+	assert.Equal(t, api.NewTimestamp(time.Date(2026, 3, 1, 10, 15, 0, 0, time.UTC)), out.RunRequestedAt)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/admin/jobs/missing/run-now", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	var errOut api.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errOut))
+	assert.Equal(t, "unknown_job", errOut.Error)
+}
+
+func TestJobsHandler_ListError(t *testing.T) {
+	t.Parallel()
+	router := newJobsRouter(&fakeJobScheduler{listErr: errors.New("db down")})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/admin/jobs", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/buildinfo"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+)
+
+// readyCheckTimeout は /readyz のリクエストごとの依存先の確認（DB・Redis の ping）にかける時間の上限です。
+// ロードバランサーのヘルスチェックの期限より十分短くし、DB の応答が遅い場合も down として応答を返します。
+const readyCheckTimeout = 500 * time.Millisecond
+
+// dbPinger は DB に接続できるかを確認します（*sql.DB が実装）。
+type dbPinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// cacheStatus はキャッシュ（Redis）を利用できる状態かを返します（infra/redis の CacheState が実装）。
+type cacheStatus interface {
+	Enabled() bool
+}
+
+// maintenanceStatus はメンテナンスモードが有効かを返します（transport/maintenance の Mode が実装）。
+type maintenanceStatus interface {
+	Active(ctx context.Context) bool
+}
+
+// marketHealthStatus は市場データ連携の状態を返します（app/server が candles.MarketHealthMonitor を適合させて実装）。
+type marketHealthStatus interface {
+	MarketHealth(ctx context.Context) api.ReadyResponseMarket
+}
+
+// freshnessStatus は日足のデータ鮮度を返します（app/server が candles.FreshnessReader を適合させて実装）。
+type freshnessStatus interface {
+	Freshness(ctx context.Context) api.ReadyResponseFreshness
+}
+
+// ReadyHandler は /readyz エンドポイントを処理します。
+type ReadyHandler struct {
+	db          dbPinger
+	redis       *redis.Client
+	cache       cacheStatus
+	schema      api.ReadyResponseSchema
+	schemaDrift []string
+	maintenance maintenanceStatus
+	market      marketHealthStatus
+	freshness   freshnessStatus
+}
+
+// NewReadyHandler は ReadyHandler を生成します。cache が nil の場合はキャッシュを常に disabled と報告します。
+// スキーマは WithSchema で設定するまで ok と報告します。
+func NewReadyHandler(cache cacheStatus) *ReadyHandler {
+	return &ReadyHandler{cache: cache, schema: api.Ok}
+}
+
+// WithSchema は起動時のスキーマ検証の結果（ok / drift / unknown）と、欠けているテーブル・列・インデックスを設定します。
+// ok 以外の場合、/readyz の status は degraded になります。
+func (h *ReadyHandler) WithSchema(status api.ReadyResponseSchema, drift []string) *ReadyHandler {
+	h.schema = status
+	h.schemaDrift = drift
+	return h
+}
+
+// WithDB はリクエストごとに db へ ping し（上限 readyCheckTimeout）、結果を db に報告します。
+// 接続できない場合は 503 を返します。未設定なら db は常に ok です。
+func (h *ReadyHandler) WithDB(db dbPinger) *ReadyHandler {
+	h.db = db
+	return h
+}
+
+// WithRedis はリクエストごとに rdb へ ping し（上限 readyCheckTimeout）、結果を redis に報告します。
+// cache（ヘルスモニターの判定）と異なり、設定された Redis に今接続できるかを示します。
+// 接続できない場合も DB 直読みで応答できるため、status を degraded にして 200 を返します。未設定（nil）なら redis は disabled です。
+func (h *ReadyHandler) WithRedis(rdb *redis.Client) *ReadyHandler {
+	h.redis = rdb
+	return h
+}
+
+// WithMaintenance は mode に m のメンテナンスモードの状態（normal / maintenance）を報告します。未設定なら常に normal です。
+func (h *ReadyHandler) WithMaintenance(m maintenanceStatus) *ReadyHandler {
+	h.maintenance = m
+	return h
+}
+
+// WithMarketHealth は market に m の市場データ連携の状態を報告します。未設定なら常に unknown です。
+func (h *ReadyHandler) WithMarketHealth(m marketHealthStatus) *ReadyHandler {
+	h.market = m
+	return h
+}
+
+// WithFreshness は freshness に f の日足のデータ鮮度（ok / stale / unknown）を報告します（上限 readyCheckTimeout）。未設定なら常に unknown です。
+func (h *ReadyHandler) WithFreshness(f freshnessStatus) *ReadyHandler {
+	h.freshness = f
+	return h
+}
+
+// Ready は依存先の状態とビルド情報を返します。
+// キャッシュが無効でもサービスは DB 直読みで応答できるため、cache の状態によらず 200 を返します。
+// スキーマの差異も、影響のないエンドポイントは応答できるため 200 のまま status を degraded にして知らせます。
+// メンテナンス中も読み取りは応答できるため 200 のまま mode で知らせます。
+// 市場データ連携の障害もキャッシュ・DB から応答できるため、status は変えずに market で知らせます。
+// 日足が古い（ingest の失敗・遅延）場合も保存済みのデータで応答できるため、status は変えずに freshness で知らせます。
+// Redis に接続できない場合は DB 直読みで応答できるため、200 のまま status を degraded にして redis で知らせます。
+// DB に接続できない場合のみ、ほとんどのエンドポイントが応答できないため status を unavailable にして 503 を返します。
+func (h *ReadyHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	cache := api.Disabled
+	if h.cache != nil && h.cache.Enabled() {
+		cache = api.Enabled
+	}
+	mode := api.Normal
+	if h.maintenance != nil && h.maintenance.Active(r.Context()) {
+		mode = api.Maintenance
+	}
+	market := api.MarketUnknown
+	if h.market != nil {
+		market = h.market.MarketHealth(r.Context())
+	}
+	res := api.ReadyResponse{Status: "ok", Cache: cache, Schema: h.schema, Mode: mode, Market: market, Db: h.pingDB(r.Context()), Redis: h.pingRedis(r.Context()), Freshness: h.checkFreshness(r.Context()), Build: toBuildInfo(buildinfo.Get())}
+	if h.schema != api.Ok || res.Redis == api.RedisDown {
+		res.Status = "degraded"
+	}
+	if len(h.schemaDrift) > 0 {
+		res.SchemaDrift = &h.schemaDrift
+	}
+	if res.Db == api.DBDown {
+		res.Status = "unavailable"
+		httpx.WriteJSON(w, http.StatusServiceUnavailable, res)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, res)
+}
+
+// checkFreshness は日足のデータ鮮度を readyCheckTimeout 以内に確認します。未設定なら unknown です。
+func (h *ReadyHandler) checkFreshness(ctx context.Context) api.ReadyResponseFreshness {
+	if h.freshness == nil {
+		return api.FreshnessUnknown
+	}
+	ctx, cancel := context.WithTimeout(ctx, readyCheckTimeout)
+	defer cancel()
+	return h.freshness.Freshness(ctx)
+}
+
+// pingDB は DB に readyCheckTimeout 以内に接続できれば ok、できなければ down を返します。
+func (h *ReadyHandler) pingDB(ctx context.Context) api.ReadyResponseDb {
+	if h.db == nil {
+		return api.DBOk
+	}
+	ctx, cancel := context.WithTimeout(ctx, readyCheckTimeout)
+	defer cancel()
+	if err := h.db.PingContext(ctx); err != nil {
+		slog.WarnContext(ctx, "readiness check: db ping failed", "error", err)
+		return api.DBDown
+	}
+	return api.DBOk
+}
+
+// pingRedis は Redis に readyCheckTimeout 以内に接続できれば ok、できなければ down を返します。未設定なら disabled です。
+func (h *ReadyHandler) pingRedis(ctx context.Context) api.ReadyResponseRedis {
+	if h.redis == nil {
+		return api.RedisDisabled
+	}
+	ctx, cancel := context.WithTimeout(ctx, readyCheckTimeout)
+	defer cancel()
+	if err := h.redis.Ping(ctx).Err(); err != nil {
+		slog.WarnContext(ctx, "readiness check: redis ping failed", "error", err)
+		return api.RedisDown
+	}
+	return api.RedisOk
+}
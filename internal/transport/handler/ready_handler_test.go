@@ -0,0 +1,326 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/testsupport"
+)
+
+type fakeCacheStatus bool
+
+func (f fakeCacheStatus) Enabled() bool { return bool(f) }
+
+// TestReady_CacheState はキャッシュの状態が cache フィールドに反映され、いずれの場合も 200 を返すことを検証します。
+func TestReady_CacheState(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		cache cacheStatus
+		want  string
+	}{
+		{"enabled", fakeCacheStatus(true), "enabled"},
+		{"disabled", fakeCacheStatus(false), "disabled"},
+		{"nil", nil, "disabled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			NewReadyHandler(tt.cache).Ready(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if w.Code != http.StatusOK {
+				t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+			}
+			var response struct {
+				Status string            `json:"status"`
+				Cache  string            `json:"cache"`
+				Build  map[string]string `json:"build"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if response.Status != "ok" || response.Cache != tt.want {
+				t.Errorf("got %+v, want status=ok cache=%s", response, tt.want)
+			}
+			if response.Build["commit"] != "dev" {
+				t.Errorf("build.commit: got %q, want dev", response.Build["commit"])
+			}
+			if w.Header().Get("Cache-Control") != "no-store" {
+				t.Errorf("expected Cache-Control 'no-store', got %q", w.Header().Get("Cache-Control"))
+			}
+		})
+	}
+}
+
+// TestReady_Schema はスキーマ検証の結果が schema・schema_drift に反映され、ok 以外で status が degraded になることを検証します。
+func TestReady_Schema(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		schema     api.ReadyResponseSchema
+		drift      []string
+		wantStatus string
+	}{
+		{"ok", api.Ok, nil, "ok"},
+		{"drift", api.Drift, []string{"missing_column candles.source", "missing_table digest_sends"}, "degraded"},
+		{"unknown", api.Unknown, nil, "degraded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			NewReadyHandler(fakeCacheStatus(true)).WithSchema(tt.schema, tt.drift).
+				Ready(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if w.Code != http.StatusOK {
+				t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+			}
+			var response struct {
+				Status      string   `json:"status"`
+				Schema      string   `json:"schema"`
+				SchemaDrift []string `json:"schema_drift"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if response.Status != tt.wantStatus || response.Schema != string(tt.schema) {
+				t.Errorf("got %+v, want status=%s schema=%s", response, tt.wantStatus, tt.schema)
+			}
+			if !slices.Equal(response.SchemaDrift, tt.drift) {
+				t.Errorf("schema_drift: got %v, want %v", response.SchemaDrift, tt.drift)
+			}
+		})
+	}
+}
+
+type fakeMaintenance bool
+
+func (f fakeMaintenance) Active(context.Context) bool { return bool(f) }
+
+// TestReady_Mode はメンテナンスモードの状態が mode に反映され、メンテナンス中も 200・status=ok を返すことを検証します。
+func TestReady_Mode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		maintenance maintenanceStatus
+		want        api.ReadyResponseMode
+	}{
+		{"maintenance", fakeMaintenance(true), api.Maintenance},
+		{"normal", fakeMaintenance(false), api.Normal},
+		{"nil", nil, api.Normal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			NewReadyHandler(fakeCacheStatus(true)).WithMaintenance(tt.maintenance).
+				Ready(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if w.Code != http.StatusOK {
+				t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+			}
+			var response api.ReadyResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if response.Mode != tt.want || response.Status != "ok" {
+				t.Errorf("got mode=%s status=%s, want mode=%s status=ok", response.Mode, response.Status, tt.want)
+			}
+		})
+	}
+}
+
+type fakeMarketHealth api.ReadyResponseMarket
+
+func (f fakeMarketHealth) MarketHealth(context.Context) api.ReadyResponseMarket {
+	return api.ReadyResponseMarket(f)
+}
+
+// TestReady_Market は市場データ連携の状態が market に反映され、down でも 200・status=ok のままであることを検証します。
+func TestReady_Market(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		market marketHealthStatus
+		want   api.ReadyResponseMarket
+	}{
+		{"down", fakeMarketHealth(api.MarketDown), api.MarketDown},
+		{"healthy", fakeMarketHealth(api.MarketHealthy), api.MarketHealthy},
+		{"nil", nil, api.MarketUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			NewReadyHandler(fakeCacheStatus(true)).WithMarketHealth(tt.market).
+				Ready(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if w.Code != http.StatusOK {
+				t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+			}
+			var response api.ReadyResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if response.Market != tt.want || response.Status != "ok" {
+				t.Errorf("got market=%s status=%s, want market=%s status=ok", response.Market, response.Status, tt.want)
+			}
+		})
+	}
+}
+
+type fakeFreshness api.ReadyResponseFreshness
+
+func (f fakeFreshness) Freshness(ctx context.Context) api.ReadyResponseFreshness {
+	if _, ok := ctx.Deadline(); !ok {
+		return api.FreshnessUnknown // 上限なしで呼ばれた場合はテストを失敗させる
+	}
+	return api.ReadyResponseFreshness(f)
+}
+
+// TestReady_Freshness は日足のデータ鮮度が freshness に反映され、stale でも 200・status=ok のままであることを検証します。
+func TestReady_Freshness(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		freshness freshnessStatus
+		want      api.ReadyResponseFreshness
+	}{
+		{"stale", fakeFreshness(api.FreshnessStale), api.FreshnessStale},
+		{"ok", fakeFreshness(api.FreshnessOk), api.FreshnessOk},
+		{"nil", nil, api.FreshnessUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			NewReadyHandler(fakeCacheStatus(true)).WithFreshness(tt.freshness).
+				Ready(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if w.Code != http.StatusOK {
+				t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+			}
+			var response api.ReadyResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if response.Freshness != tt.want || response.Status != "ok" {
+				t.Errorf("got freshness=%s status=%s, want freshness=%s status=ok", response.Freshness, response.Status, tt.want)
+			}
+		})
+	}
+}
+
+// fakePinger は err を返す DB の ping です。block の場合は ctx の期限まで応答しません（応答の遅い DB）。
+type fakePinger struct {
+	err   error
+	block bool
+}
+
+func (f fakePinger) PingContext(ctx context.Context) error {
+	if f.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return f.err
+}
+
+// TestReady_DB は DB に接続できない場合（エラー・期限切れ）に 503・status=unavailable・db=down を返すことを検証します。
+func TestReady_DB(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		db         dbPinger
+		wantCode   int
+		wantStatus string
+		wantDB     api.ReadyResponseDb
+	}{
+		{"ok", fakePinger{}, http.StatusOK, "ok", api.DBOk},
+		{"error", fakePinger{err: errors.New("connection refused")}, http.StatusServiceUnavailable, "unavailable", api.DBDown},
+		{"timeout", fakePinger{block: true}, http.StatusServiceUnavailable, "unavailable", api.DBDown},
+		{"nil", nil, http.StatusOK, "ok", api.DBOk},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			NewReadyHandler(nil).WithDB(tt.db).Ready(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if w.Code != tt.wantCode {
+				t.Errorf("expected status %d, got %d", tt.wantCode, w.Code)
+			}
+			var response api.ReadyResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if response.Status != tt.wantStatus || response.Db != tt.wantDB || response.Cache != api.Disabled {
+				t.Errorf("got status=%s db=%s cache=%s, want status=%s db=%s cache=disabled", response.Status, response.Db, response.Cache, tt.wantStatus, tt.wantDB)
+			}
+		})
+	}
+}
+
+// TestReady_Redis は Redis への ping の結果を redis に報告し、接続できない場合は 200 のまま status=degraded にすることを検証します。
+func TestReady_Redis(t *testing.T) {
+	t.Parallel()
+
+	up := func(t *testing.T) *redis.Client {
+		_, rdb := testsupport.NewMiniRedis(t)
+		return rdb
+	}
+	down := func(t *testing.T) *redis.Client {
+		mr, rdb := testsupport.NewMiniRedis(t)
+		mr.Close()
+		return rdb
+	}
+	tests := []struct {
+		name       string
+		rdb        func(*testing.T) *redis.Client
+		wantStatus string
+		wantRedis  api.ReadyResponseRedis
+	}{
+		{"ok", up, "ok", api.RedisOk},
+		{"down", down, "degraded", api.RedisDown},
+		{"nil", func(*testing.T) *redis.Client { return nil }, "ok", api.RedisDisabled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			NewReadyHandler(nil).WithRedis(tt.rdb(t)).Ready(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if w.Code != http.StatusOK {
+				t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+			}
+			var response api.ReadyResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if response.Status != tt.wantStatus || response.Redis != tt.wantRedis {
+				t.Errorf("got status=%s redis=%s, want status=%s redis=%s", response.Status, response.Redis, tt.wantStatus, tt.wantRedis)
+			}
+		})
+	}
+}
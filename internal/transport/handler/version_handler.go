@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/buildinfo"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+)
+
+// versionMaxAge は /v1/version の Cache-Control の max-age です。値はデプロイまで変わりません。
+const versionMaxAge = "public, max-age=300"
+
+// Version は実行中のサーバーのビルド情報を返す /v1/version エンドポイントを処理します（認証不要）。
+func Version(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", versionMaxAge)
+	httpx.WriteJSON(w, http.StatusOK, toBuildInfo(buildinfo.Get()))
+}
+
+// toBuildInfo はビルド情報をレスポンスの型に変換します。
+func toBuildInfo(i buildinfo.Info) api.BuildInfo {
+	return api.BuildInfo{Version: i.Version, Commit: i.Commit, BuildTime: i.BuildTime, GoVersion: i.GoVersion}
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+)
+
+// TestVersion はビルド情報の各項目（-ldflags なしでは dev）とキャッシュ可能なヘッダーを返すことを検証します。
+func TestVersion(t *testing.T) {
+	t.Parallel()
+
+	w := httptest.NewRecorder()
+	Version(w, httptest.NewRequest(http.MethodGet, "/v1/version", nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if got := w.Header().Get("Cache-Control"); got != "public, max-age=300" {
+		t.Errorf("expected Cache-Control 'public, max-age=300', got %q", got)
+	}
+	var response map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	want := map[string]string{"version": "dev", "commit": "dev", "build_time": "dev", "go_version": runtime.Version()}
+	if len(response) != len(want) {
+		t.Errorf("got %v, want %v", response, want)
+	}
+	for k, v := range want {
+		if response[k] != v {
+			t.Errorf("%s: got %q, want %q", k, response[k], v)
+		}
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/redis/go-redis/v9"
+
+	infraredis "github.com/UCHIDAnobuhiro/stock-backend/internal/infra/redis"
 )
 
 // Result はレートリミットチェックの結果を保持します。
@@ -18,15 +20,37 @@ type Result struct {
 	RetryAfter time.Duration
 }
 
+// RedisProvider は現在利用できる Redis クライアントを返します。障害中は nil を返します。
+type RedisProvider interface {
+	Client() *redis.Client
+}
+
 // Limiter はRedisソート済みセットを使用したスライディングウィンドウレートリミッターです。
 // rdbがnilの場合、すべてのリクエストを許可します（グレースフルデグレード）。
 type Limiter struct {
-	rdb *redis.Client
+	rdb      *redis.Client
+	provider RedisProvider
+	keys     infraredis.KeyBuilder
 }
 
 // NewLimiter はLimiterの新しいインスタンスを生成します。
-func NewLimiter(rdb *redis.Client) *Limiter {
-	return &Limiter{rdb: rdb}
+// Allow に渡されたキーには keys の名前空間が付与されるため、呼び出し側は環境を意識する必要はありません。
+func NewLimiter(rdb *redis.Client, keys infraredis.KeyBuilder) *Limiter {
+	return &Limiter{rdb: rdb, keys: keys}
+}
+
+// WithRedisProvider は Redis クライアントをリクエストごとに p から取得するよう設定します。
+// p が nil を返す間は rdb が nil の場合と同じくすべてのリクエストを許可します。
+func (l *Limiter) WithRedisProvider(p RedisProvider) *Limiter {
+	l.provider = p
+	return l
+}
+
+func (l *Limiter) client() *redis.Client {
+	if l.provider != nil {
+		return l.provider.Client()
+	}
+	return l.rdb
 }
 
 // rateLimitScript はスライディングウィンドウレートリミットをRedis上で原子的に実行するLuaスクリプトです。
@@ -53,7 +77,11 @@ return {0, count}
 // limitはウィンドウ内の最大リクエスト数、windowはスライディングウィンドウの時間幅です。
 // Luaスクリプトにより判定と追加を原子的に実行し、レースコンディションを防止します。
 func (l *Limiter) Allow(ctx context.Context, key string, limit int, window time.Duration) Result {
-	if l == nil || l.rdb == nil {
+	if l == nil {
+		return Result{Allowed: true}
+	}
+	rdb := l.client()
+	if rdb == nil {
 		return Result{Allowed: true}
 	}
 
@@ -70,7 +98,7 @@ func (l *Limiter) Allow(ctx context.Context, key string, limit int, window time.
 		ttlSeconds = 1
 	}
 
-	res, err := rateLimitScript.Run(ctx, l.rdb, []string{key},
+	res, err := rateLimitScript.Run(ctx, rdb, []string{l.keys.Key(key)},
 		fmt.Sprintf("%d", windowStart),
 		limit,
 		fmt.Sprintf("%d", nowNano),
@@ -8,6 +8,8 @@ import (
 
 	"github.com/go-redis/redismock/v9"
 	"github.com/stretchr/testify/assert"
+
+	infraredis "github.com/UCHIDAnobuhiro/stock-backend/internal/infra/redis"
 )
 
 // setupEvalMock はAllow()のLuaスクリプト実行（EvalSha）のモック期待値を設定します。
@@ -35,7 +37,7 @@ func setupEvalErrorMock(mock redismock.ClientMock, key string, err error) {
 func TestLimiter_Allow_NilRedis(t *testing.T) {
 	t.Parallel()
 
-	limiter := NewLimiter(nil)
+	limiter := NewLimiter(nil, infraredis.KeyBuilder{})
 	result := limiter.Allow(context.Background(), "test:key", 5, time.Minute)
 
 	assert.True(t, result.Allowed, "nil Redisの場合はリクエストを許可すべき")
@@ -90,7 +92,7 @@ func TestLimiter_Allow(t *testing.T) {
 			}
 			setupEvalMock(mock, "test:key", allowed, tt.count)
 
-			limiter := NewLimiter(rdb)
+			limiter := NewLimiter(rdb, infraredis.KeyBuilder{})
 			result := limiter.Allow(context.Background(), "test:key", tt.limit, time.Minute)
 
 			assert.Equal(t, tt.wantAllowed, result.Allowed)
@@ -115,7 +117,7 @@ func TestLimiter_Allow_RedisError_GracefulDegradation(t *testing.T) {
 	connErr := fmt.Errorf("connection refused")
 	setupEvalErrorMock(mock, "test:key", connErr)
 
-	limiter := NewLimiter(rdb)
+	limiter := NewLimiter(rdb, infraredis.KeyBuilder{})
 	result := limiter.Allow(context.Background(), "test:key", 5, time.Minute)
 
 	assert.True(t, result.Allowed, "Redisエラー時はリクエストを許可すべき")
@@ -7,7 +7,6 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
 )
 
@@ -33,9 +32,7 @@ func ByIP(limiter *Limiter, cfg IPRateLimitConfig) func(http.Handler) http.Handl
 					"prefix", cfg.Prefix,
 				)
 				w.Header().Set("Retry-After", strconv.Itoa(int(result.RetryAfter.Seconds())))
-				httpx.WriteJSON(w, http.StatusTooManyRequests, api.ErrorResponse{
-					Error: "too many requests",
-				})
+				httpx.WriteLocalizedCode(w, r, http.StatusTooManyRequests, "too many requests")
 				return
 			}
 			next.ServeHTTP(w, r)
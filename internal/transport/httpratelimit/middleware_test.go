@@ -10,6 +10,8 @@ import (
 	"github.com/go-redis/redismock/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	infraredis "github.com/UCHIDAnobuhiro/stock-backend/internal/infra/redis"
 )
 
 // okHandler はレートリミットを通過した場合に呼ばれる終端ハンドラーです。
@@ -35,7 +37,7 @@ func TestByIP_Allowed(t *testing.T) {
 	window := time.Minute
 	setupEvalMock(mock, "rl:test:ip:192.0.2.1", 1, 0) // allowed=1, count=0
 
-	limiter := NewLimiter(rdb)
+	limiter := NewLimiter(rdb, infraredis.KeyBuilder{})
 	cfg := IPRateLimitConfig{Prefix: "rl:test:ip", Limit: 10, Window: window}
 
 	called := false
@@ -62,7 +64,7 @@ func TestByIP_RateLimited(t *testing.T) {
 	window := time.Minute
 	setupEvalMock(mock, "rl:test:ip:192.0.2.1", 0, 10) // allowed=0, count=10 (at limit)
 
-	limiter := NewLimiter(rdb)
+	limiter := NewLimiter(rdb, infraredis.KeyBuilder{})
 	cfg := IPRateLimitConfig{Prefix: "rl:test:ip", Limit: 10, Window: window}
 
 	handlerCalled := false
@@ -92,7 +94,7 @@ func TestByIP_RateLimited(t *testing.T) {
 func TestByIP_NilRedis_Allowed(t *testing.T) {
 	t.Parallel()
 
-	limiter := NewLimiter(nil)
+	limiter := NewLimiter(nil, infraredis.KeyBuilder{})
 	cfg := IPRateLimitConfig{Prefix: "rl:test:ip", Limit: 10, Window: time.Minute}
 
 	called := false
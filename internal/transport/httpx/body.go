@@ -0,0 +1,130 @@
+package httpx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+)
+
+const (
+	// DefaultMaxBodyBytes は JSON のリクエストボディの既定の上限です。
+	// API のリクエスト型はどれも数 KB に収まるため、余裕を持たせつつデコード前に巨大なボディを断ります。
+	DefaultMaxBodyBytes int64 = 64 << 10
+
+	// MaxJSONDepth は JSON のリクエストボディで許す配列・オブジェクトの入れ子の深さの上限です。
+	// API のリクエスト型の入れ子は 3 段以下のため、小さな値で深い入れ子によるデコードの CPU 消費を防ぎます。
+	MaxJSONDepth = 16
+
+	// bodyTooLargeCode は 413 のレスポンスの error フィールドです。
+	bodyTooLargeCode = "request_too_large"
+)
+
+var (
+	// ErrBodyTooLarge はリクエストボディが上限（DefaultMaxBodyBytes または MaxBytes の指定）を超えたことを示します。
+	ErrBodyTooLarge = errors.New("httpx: request body too large")
+	// ErrBodyTooDeep は JSON の入れ子が MaxJSONDepth を超えたことを示します。
+	ErrBodyTooDeep = errors.New("httpx: request body nested too deeply")
+)
+
+// DecodeOption は DecodeAndValidate の挙動を変更します。
+type DecodeOption func(*decodeOptions)
+
+type decodeOptions struct {
+	maxBytes int64
+}
+
+// MaxBytes はリクエストボディの上限を n バイトに設定します（既定は DefaultMaxBodyBytes）。0 以下は既定値のままです。
+func MaxBytes(n int64) DecodeOption {
+	return func(o *decodeOptions) {
+		if n > 0 {
+			o.maxBytes = n
+		}
+	}
+}
+
+// readJSONBody は上限までリクエストボディを読み、入れ子の深さを検証して返します。
+// 上限を超えた場合は ErrBodyTooLarge、入れ子が深すぎる場合は ErrBodyTooDeep を返します。
+func readJSONBody(r *http.Request, opts []DecodeOption) ([]byte, error) {
+	o := decodeOptions{maxBytes: DefaultMaxBodyBytes}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if r.ContentLength > o.maxBytes {
+		return nil, fmt.Errorf("%w: content length %d exceeds %d bytes", ErrBodyTooLarge, r.ContentLength, o.maxBytes)
+	}
+	// Content-Length がない（chunked）・偽りのボディも上限 +1 バイトまでしか読まない
+	b, err := io.ReadAll(io.LimitReader(r.Body, o.maxBytes+1))
+	if err != nil {
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			return nil, fmt.Errorf("%w: %w", ErrBodyTooLarge, err)
+		}
+		return nil, err
+	}
+	if int64(len(b)) > o.maxBytes {
+		return nil, fmt.Errorf("%w: exceeds %d bytes", ErrBodyTooLarge, o.maxBytes)
+	}
+	if err := checkJSONDepth(b, MaxJSONDepth); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// checkJSONDepth は文字列の外にある [ { の入れ子が max を超えないことを 1 回の走査で確認します。
+// 構文の検証はしません（不正な JSON はこの後のデコードで失敗します）。
+func checkJSONDepth(b []byte, max int) error {
+	depth := 0
+	inString, escaped := false, false
+	for _, c := range b {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '[', '{':
+			depth++
+			if depth > max {
+				return fmt.Errorf("%w: exceeds depth %d", ErrBodyTooDeep, max)
+			}
+		case ']', '}':
+			depth--
+		}
+	}
+	return nil
+}
+
+// WriteDecodeError は DecodeAndValidate のエラーをレスポンスに書き込みます。
+// ボディが上限を超えた場合は 413（request_too_large）、それ以外は 400 で invalidMsg を返します。
+// 413 では読み残したボディを読み捨てずに済むよう、接続を閉じます。
+func WriteDecodeError(w http.ResponseWriter, err error, invalidMsg string) {
+	status, resp := decodeErrorResponse(w, err, invalidMsg)
+	WriteJSON(w, status, resp)
+}
+
+// decodeErrorResponse は WriteDecodeError のステータスと応答を組み立てます（413 の Connection: close を含む）。
+func decodeErrorResponse(w http.ResponseWriter, err error, invalidMsg string) (int, api.ErrorResponse) {
+	if errors.Is(err, ErrBodyTooLarge) {
+		w.Header().Set("Connection", "close")
+		return http.StatusRequestEntityTooLarge, api.ErrorResponse{Error: bodyTooLargeCode}
+	}
+	return http.StatusBadRequest, api.ErrorResponse{Error: invalidMsg}
+}
+
+// decodeJSON は b を dst にデコードします。先頭の値のみを読み、後続のデータは無視します（従来の json.Decoder と同じ挙動）。
+func decodeJSON(b []byte, dst any) error {
+	return json.NewDecoder(bytes.NewReader(b)).Decode(dst)
+}
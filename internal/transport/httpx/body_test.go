@@ -0,0 +1,133 @@
+package httpx
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type bodyReq struct {
+	Email string   `json:"email" binding:"required"`
+	Tags  []string `json:"tags"`
+}
+
+// chunked は Content-Length を持たない（chunked 転送相当の）ボディです。
+func chunked(s string) io.Reader { return io.MultiReader(strings.NewReader(s)) }
+
+func TestDecodeAndValidate_Limits(t *testing.T) {
+	t.Parallel()
+
+	big := `{"email":"a@example.com","tags":["` + strings.Repeat("x", int(DefaultMaxBodyBytes)) + `"]}`
+	testCases := []struct {
+		name    string
+		body    io.Reader
+		opts    []DecodeOption
+		wantErr error // nil は成功
+		wantAny bool  // ErrBody* 以外のエラー（構文・バリデーション）
+	}{
+		{name: "通常のリクエスト", body: strings.NewReader(`{"email":"a@example.com","tags":["a","b"]}`)},
+		{name: "上限ちょうど", body: strings.NewReader(`{"email":"a@example.com"}`), opts: []DecodeOption{MaxBytes(int64(len(`{"email":"a@example.com"}`)))}},
+		{name: "上限超過（Content-Length あり）", body: strings.NewReader(big), wantErr: ErrBodyTooLarge},
+		{name: "上限超過（Content-Length なし）", body: chunked(big), wantErr: ErrBodyTooLarge},
+		{name: "ルートごとの上限", body: strings.NewReader(`{"email":"a@example.com","tags":["` + strings.Repeat("x", 100) + `"]}`), opts: []DecodeOption{MaxBytes(64)}, wantErr: ErrBodyTooLarge},
+		{name: "1000 段の入れ子の配列", body: strings.NewReader(strings.Repeat("[", 1000) + strings.Repeat("]", 1000)), wantErr: ErrBodyTooDeep},
+		{name: "上限を 1 段超える入れ子", body: strings.NewReader(`{"email":"a@example.com","x":` + strings.Repeat("[", MaxJSONDepth) + strings.Repeat("]", MaxJSONDepth) + `}`), wantErr: ErrBodyTooDeep},
+		{name: "文字列中の括弧は数えない", body: strings.NewReader(`{"email":"a@example.com","tags":["` + strings.Repeat(`[{\"`, 100) + `"]}`)},
+		{name: "不正な JSON", body: strings.NewReader(`{"email":`), wantAny: true},
+		{name: "バリデーションエラー", body: strings.NewReader(`{"tags":[]}`), wantAny: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			r := httptest.NewRequest(http.MethodPost, "/", tc.body)
+			var dst bodyReq
+			err := DecodeAndValidate(r, &dst, tc.opts...)
+			switch {
+			case tc.wantErr != nil:
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tc.wantErr)
+				}
+			case tc.wantAny:
+				if err == nil || errors.Is(err, ErrBodyTooLarge) || errors.Is(err, ErrBodyTooDeep) {
+					t.Fatalf("err = %v, want a decode/validation error", err)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if dst.Email != "a@example.com" {
+					t.Errorf("Email = %q", dst.Email)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeAndValidate_MaxBytesReaderFromCaller(t *testing.T) {
+	t.Parallel()
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", chunked(`{"email":"a@example.com","tags":["`+strings.Repeat("x", 100)+`"]}`))
+	r.Body = http.MaxBytesReader(w, r.Body, 32)
+	if err := DecodeAndValidate(r, &bodyReq{}); !errors.Is(err, ErrBodyTooLarge) {
+		t.Fatalf("err = %v, want ErrBodyTooLarge", err)
+	}
+}
+
+func TestWriteDecodeError(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantBody   string
+		wantClose  bool
+	}{
+		{"上限超過は 413", ErrBodyTooLarge, http.StatusRequestEntityTooLarge, `{"error":"request_too_large"}` + "\n", true},
+		{"入れ子が深すぎる場合は 400", ErrBodyTooDeep, http.StatusBadRequest, `{"error":"invalid request"}` + "\n", false},
+		{"その他は 400", errors.New("unexpected EOF"), http.StatusBadRequest, `{"error":"invalid request"}` + "\n", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			w := httptest.NewRecorder()
+			WriteDecodeError(w, tc.err, "invalid request")
+			if w.Code != tc.wantStatus || w.Body.String() != tc.wantBody {
+				t.Errorf("got (%d, %q), want (%d, %q)", w.Code, w.Body.String(), tc.wantStatus, tc.wantBody)
+			}
+			if got := w.Header().Get("Connection") == "close"; got != tc.wantClose {
+				t.Errorf("Connection: close = %v, want %v", got, tc.wantClose)
+			}
+		})
+	}
+}
+
+// BenchmarkDecodeAndValidate は通常の大きさのリクエストで、上限と入れ子の検証の上乗せが小さいことを確認します。
+// go test ./internal/transport/httpx/ -bench DecodeAndValidate -benchmem
+func BenchmarkDecodeAndValidate(b *testing.B) {
+	body := `{"email":"user@example.com","tags":["AAPL","MSFT","7203.T","9432.T"]}`
+	b.Run("guarded", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+			var dst bodyReq
+			if err := DecodeAndValidate(r, &dst); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("decode only", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			var dst bodyReq
+			if err := decodeJSON([]byte(body), &dst); err != nil {
+				b.Fatal(err)
+			}
+			if err := validate.Struct(&dst); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package httpx
+
+import (
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
+)
+
+// internalErrorCode は内部エラー時にクライアントへ返す固定のエラー文字列です。
+const internalErrorCode = "internal server error"
+
+// kindStatus は apperr.Kind から HTTP ステータスへの対応表です。
+// ここに無い Kind（KindInternal・未定義値）は 500 として扱います。
+var kindStatus = map[apperr.Kind]int{
+	apperr.KindNotFound:           http.StatusNotFound,
+	apperr.KindInvalid:            http.StatusBadRequest,
+	apperr.KindConflict:           http.StatusConflict,
+	apperr.KindUnauthorized:       http.StatusUnauthorized,
+	apperr.KindUpstream:           http.StatusBadGateway,
+	apperr.KindUnavailable:        http.StatusServiceUnavailable,
+	apperr.KindTimeout:            http.StatusGatewayTimeout,
+	apperr.KindRateLimited:        http.StatusTooManyRequests,
+	apperr.KindPreconditionFailed: http.StatusPreconditionFailed,
+	apperr.KindPaymentRequired:    http.StatusPaymentRequired,
+	apperr.KindUnprocessable:      http.StatusUnprocessableEntity,
+}
+
+// RetryAfterer は再試行までの待機時間を持つエラーです（例: candles.ThrottledError）。
+// WriteError はエラーチェーンに含まれる場合、Retry-After ヘッダを付与します。
+type RetryAfterer interface {
+	RetryAfter() time.Duration
+}
+
+// Hinter はクライアントが次に取るべき対処（例: 取得範囲を狭める）を持つエラーです（例: candles.QueryTimeoutError）。
+// WriteError はエラーチェーンに含まれる場合、ErrorResponse の hint に設定します。
+type Hinter interface {
+	Hint() string
+}
+
+// ErrorStatus は err を HTTP ステータスとレスポンスの error フィールドに変換します。
+// apperr.Error を含まないエラーや KindInternal は 500 と "internal server error" になり、
+// 内部の詳細はクライアントに返しません。
+func ErrorStatus(err error) (int, string) {
+	e, ok := apperr.As(err)
+	if !ok {
+		return http.StatusInternalServerError, internalErrorCode
+	}
+	status, ok := kindStatus[e.Kind]
+	if !ok {
+		return http.StatusInternalServerError, internalErrorCode
+	}
+	return status, e.Code
+}
+
+// WriteError は ErrorStatus に従って err を ErrorResponse として書き込みます。
+// 500 になる場合のみ logMsg と logArgs で slog.Error を出力します（4xx は想定内のためログしません）。
+// err が RetryAfterer を含む場合は Retry-After（秒、切り上げ）を、Hinter を含む場合は hint を付与します。
+func WriteError(w http.ResponseWriter, err error, logMsg string, logArgs ...any) {
+	status, resp := errorResponse(w, err, logMsg, logArgs...)
+	WriteJSON(w, status, resp)
+}
+
+// errorResponse は WriteError のステータスと応答を組み立てます（ログの出力と Retry-After の設定を含む）。
+func errorResponse(w http.ResponseWriter, err error, logMsg string, logArgs ...any) (int, api.ErrorResponse) {
+	status, code := ErrorStatus(err)
+	if status == http.StatusInternalServerError {
+		slog.Error(logMsg, append([]any{"error", err}, logArgs...)...)
+	}
+	var ra RetryAfterer
+	if errors.As(err, &ra) {
+		if d := ra.RetryAfter(); d > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(d.Seconds()))))
+		}
+	}
+	resp := api.ErrorResponse{Error: code}
+	var h Hinter
+	if errors.As(err, &h) {
+		resp.Hint = h.Hint()
+	}
+	return status, resp
+}
@@ -0,0 +1,134 @@
+package httpx
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
+)
+
+// TestErrorStatus_AllKinds は全ての Kind 値（未定義値を含む）について、
+// 意図して対応付けた Kind だけが非 500 になることを検証します。
+func TestErrorStatus_AllKinds(t *testing.T) {
+	intended := map[apperr.Kind]int{
+		apperr.KindNotFound:           http.StatusNotFound,
+		apperr.KindInvalid:            http.StatusBadRequest,
+		apperr.KindConflict:           http.StatusConflict,
+		apperr.KindUnauthorized:       http.StatusUnauthorized,
+		apperr.KindUpstream:           http.StatusBadGateway,
+		apperr.KindUnavailable:        http.StatusServiceUnavailable,
+		apperr.KindTimeout:            http.StatusGatewayTimeout,
+		apperr.KindRateLimited:        http.StatusTooManyRequests,
+		apperr.KindPreconditionFailed: http.StatusPreconditionFailed,
+		apperr.KindPaymentRequired:    http.StatusPaymentRequired,
+		apperr.KindUnprocessable:      http.StatusUnprocessableEntity,
+	}
+
+	for k := 0; k <= math.MaxUint8; k++ {
+		kind := apperr.Kind(k)
+		err := fmt.Errorf("layer: %w", apperr.New(kind, "some_code", "detail that must not leak"))
+
+		status, code := ErrorStatus(err)
+		want, ok := intended[kind]
+		if !ok {
+			if status != http.StatusInternalServerError || code != internalErrorCode {
+				t.Errorf("kind %d: got (%d, %q), want (500, %q)", k, status, code, internalErrorCode)
+			}
+			continue
+		}
+		if status != want || code != "some_code" {
+			t.Errorf("kind %v: got (%d, %q), want (%d, %q)", kind, status, code, want, "some_code")
+		}
+	}
+}
+
+func TestErrorStatus_NonAppErr(t *testing.T) {
+	for _, err := range []error{errors.New("boom"), fmt.Errorf("wrapped: %w", errors.New("boom"))} {
+		status, code := ErrorStatus(err)
+		if status != http.StatusInternalServerError || code != internalErrorCode {
+			t.Errorf("ErrorStatus(%v)=(%d, %q), want (500, %q)", err, status, code, internalErrorCode)
+		}
+	}
+}
+
+// retryAfterErr は RetryAfterer を実装するテスト用のエラーです。
+type retryAfterErr struct {
+	d time.Duration
+}
+
+func (e *retryAfterErr) Error() string             { return "throttled" }
+func (e *retryAfterErr) RetryAfter() time.Duration { return e.d }
+func (e *retryAfterErr) Unwrap() error {
+	return apperr.New(apperr.KindUnavailable, "upstream_throttled", "throttled")
+}
+
+// hintErr は Hinter を実装するテスト用のエラーです。
+type hintErr struct{}
+
+func (hintErr) Error() string { return "query timed out" }
+func (hintErr) Hint() string  { return "narrow the range" }
+func (hintErr) Unwrap() error {
+	return apperr.New(apperr.KindTimeout, "query_timeout", "query timed out")
+}
+
+func TestWriteError(t *testing.T) {
+	testCases := []struct {
+		name           string
+		err            error
+		wantStatus     int
+		wantBody       string
+		wantRetryAfter string
+	}{
+		{
+			name:       "not found",
+			err:        fmt.Errorf("get: %w", apperr.New(apperr.KindNotFound, "symbol_not_found", "symbol not found")),
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"error":"symbol_not_found"}` + "\n",
+		},
+		{
+			name:       "internal hides details",
+			err:        errors.New("pq: connection refused"),
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"error":"internal server error"}` + "\n",
+		},
+		{
+			name:           "retry after is rounded up",
+			err:            fmt.Errorf("get: %w", &retryAfterErr{d: 1500 * time.Millisecond}),
+			wantStatus:     http.StatusServiceUnavailable,
+			wantBody:       `{"error":"upstream_throttled"}` + "\n",
+			wantRetryAfter: "2",
+		},
+		{
+			name:       "zero retry after is omitted",
+			err:        &retryAfterErr{},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   `{"error":"upstream_throttled"}` + "\n",
+		},
+		{
+			name:       "hint is included",
+			err:        fmt.Errorf("find: %w", hintErr{}),
+			wantStatus: http.StatusGatewayTimeout,
+			wantBody:   `{"error":"query_timeout","hint":"narrow the range"}` + "\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			WriteError(w, tc.err, "request failed")
+			if w.Code != tc.wantStatus {
+				t.Errorf("status=%d, want %d", w.Code, tc.wantStatus)
+			}
+			if w.Body.String() != tc.wantBody {
+				t.Errorf("body=%q, want %q", w.Body.String(), tc.wantBody)
+			}
+			if got := w.Header().Get("Retry-After"); got != tc.wantRetryAfter {
+				t.Errorf("Retry-After=%q, want %q", got, tc.wantRetryAfter)
+			}
+		})
+	}
+}
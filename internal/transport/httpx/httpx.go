@@ -7,6 +7,8 @@ import (
 	"encoding/json"
 	"net"
 	"net/http"
+	"reflect"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -14,11 +16,19 @@ import (
 // validate は構造体タグによるバリデーションを行うシングルトンです。
 // api パッケージの型は `binding:"..."` タグ（Gin 由来）を持つため、
 // validator のタグ名を "binding" に切り替えて既存タグをそのまま利用します。
+// 検証エラーの項目名は JSON のキー（json タグ）にします（クライアントに返す FieldError.field のため）。
 var validate = newValidator()
 
 func newValidator() *validator.Validate {
 	v := validator.New(validator.WithRequiredStructEnabled())
 	v.SetTagName("binding")
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			return f.Name
+		}
+		return name
+	})
 	return v
 }
 
@@ -36,10 +46,14 @@ func WriteJSON(w http.ResponseWriter, status int, v any) {
 
 // DecodeAndValidate はリクエストボディを JSON として dst にデコードし、
 // `binding` タグに基づくバリデーションを実行します。Gin の c.ShouldBindJSON 相当です。
-// デコードまたはバリデーションに失敗した場合はエラーを返します。
-func DecodeAndValidate(r *http.Request, dst any) error {
-	dec := json.NewDecoder(r.Body)
-	if err := dec.Decode(dst); err != nil {
+// デコードの前にボディの大きさ（既定は DefaultMaxBodyBytes。MaxBytes で変更）と入れ子の深さ（MaxJSONDepth）を検証し、
+// 超えた場合は ErrBodyTooLarge / ErrBodyTooDeep を返します。エラーの書き込みには WriteDecodeError を使います。
+func DecodeAndValidate(r *http.Request, dst any, opts ...DecodeOption) error {
+	b, err := readJSONBody(r, opts)
+	if err != nil {
+		return err
+	}
+	if err := decodeJSON(b, dst); err != nil {
 		return err
 	}
 	return validate.Struct(dst)
@@ -0,0 +1,73 @@
+package httpx
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// NegotiateLocale は Accept-Language ヘッダーの優先度（q 値）に従い、supported から応答に使うロケールを選びます。
+// supported は小文字の言語コード（例: "ja", "en"）で、先頭を既定として扱います。
+//
+// 地域付きのタグ（en-US）は言語部分（en）でも照合し、"*" は既定に一致します。q=0 のタグは除外し、
+// 同じ q 値のタグはヘッダーでの記述順を優先します。ヘッダーがない・一致するロケールがない場合は既定を返します。
+func NegotiateLocale(r *http.Request, supported []string) string {
+	if len(supported) == 0 {
+		return ""
+	}
+	for _, tag := range acceptedLanguages(r.Header.Get("Accept-Language")) {
+		if tag == "*" {
+			return supported[0]
+		}
+		if slices.Contains(supported, tag) {
+			return tag
+		}
+		if lang, _, ok := strings.Cut(tag, "-"); ok && slices.Contains(supported, lang) {
+			return lang
+		}
+	}
+	return supported[0]
+}
+
+// SetLocaleHeaders は Accept-Language によって内容が変わる応答に Content-Language と Vary を設定します。
+func SetLocaleHeaders(w http.ResponseWriter, locale string) {
+	w.Header().Set("Content-Language", locale)
+	w.Header().Add("Vary", "Accept-Language")
+}
+
+// acceptedLanguages は Accept-Language の値を q 値の降順（同順位は記述順）に並べた小文字の言語タグを返します。
+// q 値が解釈できないタグと q=0 のタグは除外します。
+func acceptedLanguages(header string) []string {
+	type entry struct {
+		tag string
+		q   float64
+	}
+	var entries []entry
+	for part := range strings.SplitSeq(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		entries = append(entries, entry{tag: tag, q: q})
+	}
+	slices.SortStableFunc(entries, func(a, b entry) int { return cmp.Compare(b.q, a.q) })
+	tags := make([]string, len(entries))
+	for i, e := range entries {
+		tags[i] = e.tag
+	}
+	return tags
+}
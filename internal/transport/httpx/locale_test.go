@@ -0,0 +1,52 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiateLocale(t *testing.T) {
+	supported := []string{"ja", "en"}
+	testCases := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{name: "no header uses default", header: "", want: "ja"},
+		{name: "exact match", header: "en", want: "en"},
+		{name: "region falls back to language", header: "en-US", want: "en"},
+		{name: "case insensitive", header: "EN-gb", want: "en"},
+		{name: "highest q wins", header: "ja;q=0.5, en;q=0.9", want: "en"},
+		{name: "equal q keeps header order", header: "en, ja", want: "en"},
+		{name: "unsupported skipped", header: "fr-FR, en;q=0.8", want: "en"},
+		{name: "nothing supported uses default", header: "fr, de", want: "ja"},
+		{name: "wildcard uses default", header: "fr, *;q=0.5", want: "ja"},
+		{name: "q=0 excluded", header: "en;q=0, ja;q=0.1", want: "ja"},
+		{name: "malformed q ignored", header: "en;q=high, ja;q=0.1", want: "ja"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.header != "" {
+				r.Header.Set("Accept-Language", tc.header)
+			}
+			if got := NegotiateLocale(r, supported); got != tc.want {
+				t.Errorf("NegotiateLocale(%q) = %q, want %q", tc.header, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSetLocaleHeaders(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.Header().Set("Vary", "Origin")
+	SetLocaleHeaders(w, "en")
+
+	if got := w.Header().Get("Content-Language"); got != "en" {
+		t.Errorf("Content-Language = %q, want en", got)
+	}
+	if got := w.Header().Values("Vary"); len(got) != 2 || got[1] != "Accept-Language" {
+		t.Errorf("Vary = %v, want [Origin Accept-Language]", got)
+	}
+}
@@ -0,0 +1,84 @@
+package httpx
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/i18n"
+)
+
+// Localize は Accept-Language から cat のロケールを選び、i18n.Translator を context に格納するミドルウェアを返します。
+// 配下のハンドラーが WriteLocalizedError 等で書き込むエラーレスポンスは、error（コード）を変えずに
+// 選んだロケールの文言を message に載せます。
+func Localize(cat *i18n.Catalog) func(http.Handler) http.Handler {
+	locales := cat.Locales()
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tr := cat.Translator(NegotiateLocale(r, locales))
+			next.ServeHTTP(w, r.WithContext(i18n.NewContext(r.Context(), tr)))
+		})
+	}
+}
+
+// localize は Localize 配下のリクエストであれば resp.Message を翻訳し、Content-Language と Vary を設定します。
+// Localize を通っていないリクエストでは resp を変えません（従来の応答のまま）。
+func localize(w http.ResponseWriter, r *http.Request, resp *api.ErrorResponse) (i18n.Translator, bool) {
+	tr, ok := i18n.FromContext(r.Context())
+	if !ok {
+		return tr, false
+	}
+	SetLocaleHeaders(w, tr.Locale())
+	resp.Message = tr.Message(resp.Error)
+	return tr, true
+}
+
+// WriteLocalizedCode は code を error とする ErrorResponse を status で書き込みます（message は localize 参照）。
+func WriteLocalizedCode(w http.ResponseWriter, r *http.Request, status int, code string) {
+	resp := api.ErrorResponse{Error: code}
+	localize(w, r, &resp)
+	WriteJSON(w, status, resp)
+}
+
+// WriteLocalizedError は WriteError と同じ規則で err を書き込み、message にロケールの文言を載せます。
+func WriteLocalizedError(w http.ResponseWriter, r *http.Request, err error, logMsg string, logArgs ...any) {
+	status, resp := errorResponse(w, err, logMsg, logArgs...)
+	localize(w, r, &resp)
+	WriteJSON(w, status, resp)
+}
+
+// WriteLocalizedDecodeError は WriteDecodeError と同じ規則で DecodeAndValidate のエラーを書き込み、message にロケールの文言を載せます。
+// 入力検証のエラーは項目ごとに fields（検証タグを code、"validation.<タグ>" の文言を message）として返します。
+func WriteLocalizedDecodeError(w http.ResponseWriter, r *http.Request, err error, invalidCode string) {
+	status, resp := decodeErrorResponse(w, err, invalidCode)
+	tr, ok := localize(w, r, &resp)
+	var verrs validator.ValidationErrors
+	if ok && errors.As(err, &verrs) {
+		resp.Fields = fieldErrors(tr, verrs)
+	}
+	WriteJSON(w, status, resp)
+}
+
+// fieldErrors は検証エラーを FieldError に変換します。カタログにない検証タグは "validation.invalid" の文言にし、
+// 項目名は "field.<JSON 名>" の文言があればそれを、なければ JSON 名をそのまま文言に埋め込みます。
+func fieldErrors(tr i18n.Translator, verrs validator.ValidationErrors) []api.FieldError {
+	fields := make([]api.FieldError, 0, len(verrs))
+	for _, fe := range verrs {
+		key := "validation." + fe.Tag()
+		if !tr.Has(key) {
+			key = "validation.invalid"
+		}
+		label := fe.Field()
+		if tr.Has("field." + label) {
+			label = tr.Message("field." + label)
+		}
+		fields = append(fields, api.FieldError{
+			Field:   fe.Field(),
+			Code:    fe.Tag(),
+			Message: tr.Message(key, "field", label, "param", fe.Param()),
+		})
+	}
+	return fields
+}
@@ -0,0 +1,125 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/i18n"
+)
+
+type signupBody struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,min=12"`
+}
+
+// serveLocalized は Localize 配下で h を実行し、応答のボディを ErrorResponse として返します。
+func serveLocalized(t *testing.T, acceptLanguage, body string, h http.HandlerFunc) (*httptest.ResponseRecorder, api.ErrorResponse) {
+	t.Helper()
+	cat, err := i18n.NewCatalog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	if acceptLanguage != "" {
+		req.Header.Set("Accept-Language", acceptLanguage)
+	}
+	w := httptest.NewRecorder()
+	Localize(cat)(h).ServeHTTP(w, req)
+	var resp api.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal %q: %v", w.Body.String(), err)
+	}
+	return w, resp
+}
+
+func decodeSignup(w http.ResponseWriter, r *http.Request) {
+	var req signupBody
+	if err := DecodeAndValidate(r, &req); err != nil {
+		WriteLocalizedDecodeError(w, r, err, "invalid request")
+	}
+}
+
+func TestWriteLocalizedDecodeError_FieldErrors(t *testing.T) {
+	body := `{"email":"not-an-email","password":"short"}`
+
+	w, en := serveLocalized(t, "en-US,en;q=0.9", body, decodeSignup)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", w.Code)
+	}
+	if got := w.Header().Get("Content-Language"); got != "en" {
+		t.Errorf("Content-Language = %q, want en", got)
+	}
+	wantEN := api.ErrorResponse{
+		Error:   "invalid request",
+		Message: "The request is invalid.",
+		Fields: []api.FieldError{
+			{Field: "email", Code: "email", Message: "Email must be a valid email address."},
+			{Field: "password", Code: "min", Message: "Password must be at least 12 characters."},
+		},
+	}
+	assertErrorResponse(t, en, wantEN)
+
+	w, ja := serveLocalized(t, "ja", body, decodeSignup)
+	if got := w.Header().Get("Content-Language"); got != "ja" {
+		t.Errorf("Content-Language = %q, want ja", got)
+	}
+	wantJA := api.ErrorResponse{
+		Error:   "invalid request",
+		Message: "リクエストの内容が正しくありません。",
+		Fields: []api.FieldError{
+			{Field: "email", Code: "email", Message: "メールアドレスには正しいメールアドレスを入力してください。"},
+			{Field: "password", Code: "min", Message: "パスワードは12文字以上で入力してください。"},
+		},
+	}
+	assertErrorResponse(t, ja, wantJA)
+}
+
+func TestWriteLocalizedDecodeError_Malformed(t *testing.T) {
+	// 入力検証以外のデコードエラーは fields を返さない
+	_, resp := serveLocalized(t, "ja", `{`, decodeSignup)
+	assertErrorResponse(t, resp, api.ErrorResponse{Error: "invalid request", Message: "リクエストの内容が正しくありません。"})
+}
+
+func TestWriteLocalizedError(t *testing.T) {
+	err := apperr.New(apperr.KindInvalid, "invalid or expired reset token", "invalid or expired reset token")
+	h := func(w http.ResponseWriter, r *http.Request) { WriteLocalizedError(w, r, err, "reset failed") }
+
+	w, resp := serveLocalized(t, "fr, ja;q=0.5", "", h)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", w.Code)
+	}
+	assertErrorResponse(t, resp, api.ErrorResponse{Error: "invalid or expired reset token", Message: "パスワード再設定のリンクが無効か、有効期限が切れています。"})
+
+	// Accept-Language がない場合は英語
+	_, resp = serveLocalized(t, "", "", h)
+	assertErrorResponse(t, resp, api.ErrorResponse{Error: "invalid or expired reset token", Message: "The password reset link is invalid or has expired."})
+}
+
+// TestWriteLocalizedCode_WithoutLocalize は Localize を通らないリクエストでは従来の応答のままであることを検証します。
+func TestWriteLocalizedCode_WithoutLocalize(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "ja")
+	w := httptest.NewRecorder()
+	WriteLocalizedCode(w, req, http.StatusTooManyRequests, "too many requests")
+
+	if got, want := w.Body.String(), `{"error":"too many requests"}`+"\n"; got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+	if got := w.Header().Get("Content-Language"); got != "" {
+		t.Errorf("Content-Language = %q, want empty", got)
+	}
+}
+
+func assertErrorResponse(t *testing.T, got, want api.ErrorResponse) {
+	t.Helper()
+	gb, _ := json.Marshal(got)
+	wb, _ := json.Marshal(want)
+	if string(gb) != string(wb) {
+		t.Errorf("response = %s, want %s", gb, wb)
+	}
+}
@@ -0,0 +1,23 @@
+package httpx
+
+import (
+	"net/http"
+	"strings"
+)
+
+// V2MediaType は v2 の応答形式を要求する Accept のメディアタイプです。
+// v1 のクライアントの応答を変えずに、応答の形を拡張するエンドポイントが共通で使います。
+const V2MediaType = "application/vnd.stock.v2+json"
+
+// AcceptsMediaType は Accept ヘッダーのいずれかに mediaType（大文字小文字は区別しない、パラメータは無視）が含まれるかを返します。
+func AcceptsMediaType(r *http.Request, mediaType string) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for mt := range strings.SplitSeq(accept, ",") {
+			mt, _, _ = strings.Cut(mt, ";")
+			if strings.EqualFold(strings.TrimSpace(mt), mediaType) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package httpx
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcceptsMediaType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		accepts []string
+		want    bool
+	}{
+		{"no header", nil, false},
+		{"exact", []string{V2MediaType}, true},
+		{"list with params", []string{"application/json, Application/Vnd.Stock.V2+json; q=0.9"}, true},
+		{"multiple headers", []string{"application/json", V2MediaType}, true},
+		{"other", []string{"application/json"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := httptest.NewRequest("GET", "/", nil)
+			for _, a := range tt.accepts {
+				r.Header.Add("Accept", a)
+			}
+			assert.Equal(t, tt.want, AcceptsMediaType(r, V2MediaType))
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package httpx
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+)
+
+// ETag は楽観的同時実行制御のバージョンを ETag ヘッダーの値（"<バージョン>"）にします。
+func ETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// CheckIfMatch は If-Match ヘッダーからクライアントが見たバージョンを読み取ります。
+// 戻り値 expected が nil の場合は条件なしの更新です（ヘッダーがない、または "*"）。
+// ETag（ETag 関数の形式）として読めない値・複数の値は、どのバージョンとも一致しない -1 にします（412 になる）。
+// 圧縮の際にプロキシが弱い ETag（W/ 付き）に変えることがあるため、W/ は無視して比較します。
+//
+// required でヘッダーがない場合は 428 を書き込み、ok=false を返します（ハンドラーはそのまま return する）。
+func CheckIfMatch(w http.ResponseWriter, r *http.Request, required bool) (expected *int64, ok bool) {
+	raw := strings.TrimSpace(r.Header.Get("If-Match"))
+	if raw == "" {
+		if required {
+			WriteJSON(w, http.StatusPreconditionRequired, api.ErrorResponse{Error: "if-match required"})
+			return nil, false
+		}
+		return nil, true
+	}
+	if raw == "*" {
+		return nil, true
+	}
+	version := int64(-1)
+	tag := strings.TrimPrefix(raw, "W/")
+	if len(tag) >= 2 && tag[0] == '"' && tag[len(tag)-1] == '"' {
+		if v, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64); err == nil && v >= 0 {
+			version = v
+		}
+	}
+	return &version, true
+}
@@ -0,0 +1,61 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestETag(t *testing.T) {
+	t.Parallel()
+	if got := ETag(42); got != `"42"` {
+		t.Errorf("ETag(42) = %s, want \"42\"", got)
+	}
+}
+
+// TestCheckIfMatch は If-Match の値ごとに、読み取るバージョンと 428 の書き込みを検証します。
+func TestCheckIfMatch(t *testing.T) {
+	t.Parallel()
+	v := func(n int64) *int64 { return &n }
+	tests := []struct {
+		name     string
+		header   string
+		required bool
+		want     *int64
+		wantOK   bool
+	}{
+		{name: "no header", want: nil, wantOK: true},
+		{name: "no header required", required: true, want: nil, wantOK: false},
+		{name: "wildcard", header: "*", required: true, want: nil, wantOK: true},
+		{name: "strong", header: `"7"`, want: v(7), wantOK: true},
+		{name: "weak", header: `W/"7"`, want: v(7), wantOK: true},
+		{name: "unquoted", header: "7", want: v(-1), wantOK: true},
+		{name: "not a number", header: `"abc"`, want: v(-1), wantOK: true},
+		{name: "multiple", header: `"7", "8"`, want: v(-1), wantOK: true},
+		{name: "negative", header: `"-3"`, want: v(-1), wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequest(http.MethodPut, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("If-Match", tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			got, ok := CheckIfMatch(rec, req, tt.required)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			switch {
+			case tt.want == nil && got != nil:
+				t.Errorf("expected = %d, want nil", *got)
+			case tt.want != nil && (got == nil || *got != *tt.want):
+				t.Errorf("expected = %v, want %d", got, *tt.want)
+			}
+			if !ok && rec.Code != http.StatusPreconditionRequired {
+				t.Errorf("status = %d, want 428", rec.Code)
+			}
+		})
+	}
+}
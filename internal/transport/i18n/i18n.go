@@ -0,0 +1,153 @@
+// Package i18n はクライアントに表示するエラーメッセージの翻訳（メッセージカタログ）を提供します。
+//
+// カタログはロケールごとの JSON（locales/<locale>.json）を埋め込み、エラーレスポンスの error フィールドの
+// コード（例: "invalid email or password"）と入力検証のキー（"validation.<タグ>"・"field.<JSON 名>"）で引きます。
+// コード自体は機械可読な値としてロケールに関わらず変えず、翻訳した文言は message に載せます（httpx.Localize 参照）。
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// DefaultLocale は既定のロケールです。翻訳のないキーはこのロケールの文言で返します。
+const DefaultLocale = "en"
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// Catalog はロケールごとのメッセージの対応表です。生成後は読み取り専用で、並行に使えます。
+type Catalog struct {
+	messages map[string]map[string]string
+	locales  []string
+	warned   sync.Map // 欠けていると警告済みの "<locale>:<key>"
+}
+
+// NewCatalog は埋め込みのカタログを読み込みます。
+func NewCatalog() (*Catalog, error) {
+	return loadCatalog(localeFS, "locales")
+}
+
+// loadCatalog は fsys の dir 直下の <locale>.json を読み込みます。DefaultLocale のファイルは必須です。
+func loadCatalog(fsys fs.FS, dir string) (*Catalog, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("read locales: %w", err)
+	}
+	c := &Catalog{messages: make(map[string]map[string]string)}
+	for _, e := range entries {
+		locale, ok := strings.CutSuffix(e.Name(), ".json")
+		if e.IsDir() || !ok {
+			continue
+		}
+		b, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read locale %s: %w", locale, err)
+		}
+		var m map[string]string
+		if err := json.Unmarshal(b, &m); err != nil {
+			return nil, fmt.Errorf("parse locale %s: %w", locale, err)
+		}
+		c.messages[locale] = m
+	}
+	if _, ok := c.messages[DefaultLocale]; !ok {
+		return nil, fmt.Errorf("default locale %s is missing", DefaultLocale)
+	}
+	// 先頭を既定にする（httpx.NegotiateLocale の規約）
+	c.locales = append(c.locales, DefaultLocale)
+	for locale := range c.messages {
+		if locale != DefaultLocale {
+			c.locales = append(c.locales, locale)
+		}
+	}
+	slices.Sort(c.locales[1:])
+	return c, nil
+}
+
+// Locales はカタログのロケールを返します（先頭が DefaultLocale。Accept-Language の交渉対象）。
+func (c *Catalog) Locales() []string {
+	return slices.Clone(c.locales)
+}
+
+// Translator は locale のメッセージを返す Translator を返します。未知のロケールは DefaultLocale として扱います。
+func (c *Catalog) Translator(locale string) Translator {
+	if _, ok := c.messages[locale]; !ok {
+		locale = DefaultLocale
+	}
+	return Translator{catalog: c, locale: locale}
+}
+
+// warnMissing は locale に key の翻訳がないことをキーごとに 1 回だけ警告ログに残します（カタログの欠けに気付けるように）。
+func (c *Catalog) warnMissing(locale, key string) {
+	if _, loaded := c.warned.LoadOrStore(locale+":"+key, struct{}{}); !loaded {
+		slog.Warn("missing translation", "locale", locale, "key", key)
+	}
+}
+
+// Translator は 1 つのロケールでメッセージを引きます。
+type Translator struct {
+	catalog *Catalog
+	locale  string
+}
+
+// Locale は翻訳に使うロケールを返します。
+func (t Translator) Locale() string {
+	return t.locale
+}
+
+// Has は key の文言が locale または DefaultLocale にあるかを返します。
+func (t Translator) Has(key string) bool {
+	_, ok := t.lookup(key)
+	return ok
+}
+
+func (t Translator) lookup(key string) (string, bool) {
+	if msg, ok := t.catalog.messages[t.locale][key]; ok {
+		return msg, true
+	}
+	msg, ok := t.catalog.messages[DefaultLocale][key]
+	return msg, ok
+}
+
+// Message は key の文言を返します。文言中の {name} は args（name, value の組）で置き換えます。
+//
+// locale に翻訳がない場合は警告ログを残して DefaultLocale の文言を返し、それもない場合は key をそのまま返します
+// （エラーのコードは英語の既定の文言を兼ねるため）。
+func (t Translator) Message(key string, args ...string) string {
+	msg, ok := t.catalog.messages[t.locale][key]
+	if !ok {
+		t.catalog.warnMissing(t.locale, key)
+		if msg, ok = t.catalog.messages[DefaultLocale][key]; !ok {
+			msg = key
+		}
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	pairs := make([]string, 0, len(args))
+	for i := 0; i+1 < len(args); i += 2 {
+		pairs = append(pairs, "{"+args[i]+"}", args[i+1])
+	}
+	return strings.NewReplacer(pairs...).Replace(msg)
+}
+
+type ctxKey struct{}
+
+// NewContext は t を格納した context を返します。
+func NewContext(ctx context.Context, t Translator) context.Context {
+	return context.WithValue(ctx, ctxKey{}, t)
+}
+
+// FromContext は NewContext で格納した Translator を返します。格納されていない場合は ok=false です。
+func FromContext(ctx context.Context) (Translator, bool) {
+	t, ok := ctx.Value(ctxKey{}).(Translator)
+	return t, ok
+}
@@ -0,0 +1,94 @@
+package i18n
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+// TestNewCatalog_LocalesShareKeys は埋め込みのカタログが読み込め、全ロケールが既定のロケールと同じキーを持つことを検証します
+// （翻訳の欠けはフォールバックで動くが、カタログの追加漏れとして検出する）。
+func TestNewCatalog_LocalesShareKeys(t *testing.T) {
+	c, err := NewCatalog()
+	if err != nil {
+		t.Fatalf("NewCatalog() error = %v", err)
+	}
+	if got, want := c.Locales(), []string{"en", "ja"}; !slices.Equal(got, want) {
+		t.Fatalf("Locales() = %v, want %v", got, want)
+	}
+	want := slices.Sorted(maps.Keys(c.messages[DefaultLocale]))
+	for _, locale := range c.Locales() {
+		if got := slices.Sorted(maps.Keys(c.messages[locale])); !slices.Equal(got, want) {
+			t.Errorf("keys of %s = %v, want %v", locale, got, want)
+		}
+	}
+}
+
+func TestTranslator_Message(t *testing.T) {
+	c, err := loadCatalog(fstest.MapFS{
+		"locales/en.json": {Data: []byte(`{"greeting":"Hello, {name}.","only.en":"English only"}`)},
+		"locales/ja.json": {Data: []byte(`{"greeting":"こんにちは、{name}さん。"}`)},
+	}, "locales")
+	if err != nil {
+		t.Fatalf("loadCatalog() error = %v", err)
+	}
+
+	ja := c.Translator("ja")
+	if got, want := ja.Message("greeting", "name", "Alice"), "こんにちは、Aliceさん。"; got != want {
+		t.Errorf("ja greeting = %q, want %q", got, want)
+	}
+	if got, want := c.Translator("en").Message("greeting", "name", "Alice"), "Hello, Alice."; got != want {
+		t.Errorf("en greeting = %q, want %q", got, want)
+	}
+
+	// 翻訳がないキーは既定のロケールの文言で返し、欠けを警告済みとして記録する
+	if got, want := ja.Message("only.en"), "English only"; got != want {
+		t.Errorf("fallback = %q, want %q", got, want)
+	}
+	if _, ok := c.warned.Load("ja:only.en"); !ok {
+		t.Error("missing translation was not reported")
+	}
+	if !ja.Has("only.en") {
+		t.Error(`Has("only.en") = false, want true (default locale)`)
+	}
+
+	// どのロケールにもないキーはそのまま返す（コードが英語の既定の文言を兼ねる）
+	if got, want := ja.Message("signup failed"), "signup failed"; got != want {
+		t.Errorf("unknown key = %q, want %q", got, want)
+	}
+	if ja.Has("signup failed") {
+		t.Error(`Has("signup failed") = true, want false`)
+	}
+
+	// 未知のロケールは既定のロケール
+	if got := c.Translator("fr").Locale(); got != DefaultLocale {
+		t.Errorf("unknown locale = %q, want %q", got, DefaultLocale)
+	}
+}
+
+func TestLoadCatalog_RequiresDefaultLocale(t *testing.T) {
+	_, err := loadCatalog(fstest.MapFS{"locales/ja.json": {Data: []byte(`{}`)}}, "locales")
+	if err == nil {
+		t.Fatal("expected error without default locale")
+	}
+	_, err = loadCatalog(fstest.MapFS{"locales/en.json": {Data: []byte(`{`)}}, "locales")
+	if err == nil {
+		t.Fatal("expected error for malformed locale")
+	}
+}
+
+func TestContext(t *testing.T) {
+	if _, ok := FromContext(context.Background()); ok {
+		t.Fatal("FromContext() ok = true on empty context")
+	}
+	c, err := NewCatalog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr, ok := FromContext(NewContext(context.Background(), c.Translator("ja")))
+	if !ok || tr.Locale() != "ja" {
+		t.Fatalf("FromContext() = %v, %v", tr.Locale(), ok)
+	}
+}
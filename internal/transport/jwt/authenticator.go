@@ -0,0 +1,38 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrUnauthenticated はトークンが不正・期限切れ、または一括失効の対象の場合のエラーです。
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// Authenticator は Cookie・Authorization ヘッダー以外の経路（WebSocket のクエリパラメータや最初のメッセージ）で
+// 渡されたアクセストークンを、AuthRequired と RejectRevoked と同じ規則で検証します。
+type Authenticator struct {
+	secret      string
+	revocations *Revocations
+}
+
+// NewAuthenticator は Authenticator を生成します。revocations が nil の場合は一括失効を照合しません。
+func NewAuthenticator(secret string, revocations *Revocations) *Authenticator {
+	return &Authenticator{secret: secret, revocations: revocations}
+}
+
+// Authenticate はトークンを検証してユーザーIDを返します。検証に失敗した場合は ErrUnauthenticated です。
+// なりすましトークンは受け付けません。接続はトークンの有効期限を超えて続き、リクエストごとの監査ログ
+// （ImpersonationGuard）も残らないためです。
+func (a *Authenticator) Authenticate(ctx context.Context, token string) (int64, error) {
+	if a.secret == "" || token == "" {
+		return 0, ErrUnauthenticated
+	}
+	claims, err := parseToken(a.secret, token)
+	if err != nil || claims.actor != "" {
+		return 0, ErrUnauthenticated
+	}
+	if a.revocations != nil && a.revocations.rejects(ctx, claims.userID, claims.issuedAt) {
+		return 0, ErrUnauthenticated
+	}
+	return claims.userID, nil
+}
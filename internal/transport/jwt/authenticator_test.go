@@ -0,0 +1,64 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/testsupport"
+)
+
+func TestAuthenticator_Authenticate(t *testing.T) {
+	t.Parallel()
+
+	const secret = "test-secret-key-for-authenticator"
+	rev, _, setNow := newTestRevocations(t)
+	revokedAt := time.Now().Add(-10 * time.Minute).Truncate(time.Second)
+	setNow(revokedAt)
+	if err := rev.RevokeAllByUserID(context.Background(), 2); err != nil {
+		t.Fatalf("RevokeAllByUserID: %v", err)
+	}
+	a := NewAuthenticator(secret, rev)
+
+	tests := []struct {
+		name    string
+		token   string
+		want    int64
+		wantErr bool
+	}{
+		{name: "有効なトークン", token: testsupport.SessionToken(t, secret, 1), want: 1},
+		{name: "失効前に発行されたトークン", token: testsupport.SessionToken(t, secret, 2, testsupport.IssuedAt(revokedAt.Add(-time.Minute))), wantErr: true},
+		{name: "失効後に発行されたトークン", token: testsupport.SessionToken(t, secret, 2, testsupport.IssuedAt(revokedAt.Add(time.Minute))), want: 2},
+		{name: "署名が異なる", token: testsupport.SessionToken(t, "other-secret", 1), wantErr: true},
+		{name: "期限切れ", token: testsupport.SessionToken(t, secret, 1, testsupport.IssuedAt(time.Now().Add(-2*time.Hour))), wantErr: true},
+		{name: "空", token: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := a.Authenticate(context.Background(), tt.token)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnauthenticated) {
+					t.Errorf("err = %v, want ErrUnauthenticated", err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Authenticate() = (%d, %v), want (%d, nil)", got, err, tt.want)
+			}
+		})
+	}
+
+	impersonated, err := NewGenerator(secret, time.Hour).GenerateImpersonationToken(1, "user@example.com", "12")
+	if err != nil {
+		t.Fatalf("GenerateImpersonationToken: %v", err)
+	}
+	if _, err := a.Authenticate(context.Background(), impersonated); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("impersonation token: err = %v, want ErrUnauthenticated", err)
+	}
+
+	if _, err := NewAuthenticator(secret, nil).Authenticate(context.Background(), testsupport.SessionToken(t, secret, 2, testsupport.IssuedAt(revokedAt.Add(-time.Minute)))); err != nil {
+		t.Errorf("nil revocations should skip the revocation check, got %v", err)
+	}
+}
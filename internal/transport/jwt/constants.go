@@ -1,6 +1,18 @@
 package jwt
 
+import "time"
+
 const (
 	// EnvKeyJWTSecret はJWT署名シークレットの環境変数キーです。
 	EnvKeyJWTSecret = "JWT_SECRET"
+
+	// EnvKeyJWTExpiration はJWT有効期間（time.ParseDuration 形式）の環境変数キーです。
+	EnvKeyJWTExpiration = "JWT_EXPIRATION"
 )
+
+// DefaultExpiration は JWT_EXPIRATION 未設定時のトークン有効期間です。
+const DefaultExpiration = time.Hour
+
+// ImpersonationExpiration はなりすましトークン（GenerateImpersonationToken）の有効期間です。
+// サポート対応の調査に必要な時間だけ有効にし、延長（再発行）は管理APIの呼び出しで行います。
+const ImpersonationExpiration = 15 * time.Minute
@@ -1,6 +1,9 @@
 package jwt
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // ctxKey は context へ値を格納するための非公開キー型です。
 // 文字列キーの衝突を避けるため、パッケージ固有の型を使用します。
@@ -12,6 +15,11 @@ const (
 	// ctxKeyAuthSource は認証方式（"cookie" または "bearer"）を context に格納するためのキーです。
 	// CSRFミドルウェアがBearer認証時にCSRFチェックをスキップするために使用します。
 	ctxKeyAuthSource
+	// ctxKeyIssuedAt はトークンの発行日時（iat）を context に格納するためのキーです。
+	// RejectRevoked が一括失効の判定に使用します。
+	ctxKeyIssuedAt
+	// ctxKeyActor はなりすましトークンの発行者（act.sub）を context に格納するためのキーです。
+	ctxKeyActor
 )
 
 // AuthSourceCookie / AuthSourceBearer は認証方式を表す値です。
@@ -31,6 +39,17 @@ func withAuthSource(ctx context.Context, source string) context.Context {
 	return context.WithValue(ctx, ctxKeyAuthSource, source)
 }
 
+// withIssuedAt は context にトークンの発行日時を格納した新しい context を返します。
+func withIssuedAt(ctx context.Context, iat time.Time) context.Context {
+	return context.WithValue(ctx, ctxKeyIssuedAt, iat)
+}
+
+// issuedAtFromContext は context からトークンの発行日時を取り出します。未設定の場合はゼロ値を返します。
+func issuedAtFromContext(ctx context.Context) time.Time {
+	iat, _ := ctx.Value(ctxKeyIssuedAt).(time.Time)
+	return iat
+}
+
 // UserIDFromContext は context から認証済みユーザーIDを取り出します。
 // 認証ミドルウェア（AuthRequired）を通過したリクエストでのみ ok=true を返します。
 func UserIDFromContext(ctx context.Context) (int64, bool) {
@@ -44,3 +63,16 @@ func AuthSourceFromContext(ctx context.Context) string {
 	source, _ := ctx.Value(ctxKeyAuthSource).(string)
 	return source
 }
+
+// WithActor は context になりすましの発行者を格納した新しい context を返します。
+// 認証ミドルウェア（AuthRequired）がなりすましトークンの場合に使用するほか、テストでの注入にも利用できます。
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, ctxKeyActor, actor)
+}
+
+// ActorFromContext は context からなりすましの発行者（管理者のユーザーID。例: "12"）を取り出します。
+// なりすましトークンで認証したリクエストでのみ ok=true を返します（ユーザーIDは対象のユーザーです）。
+func ActorFromContext(ctx context.Context) (string, bool) {
+	actor, ok := ctx.Value(ctxKeyActor).(string)
+	return actor, ok && actor != ""
+}
@@ -24,15 +24,47 @@ func NewGenerator(secret string, expiration time.Duration) *Generator {
 	}
 }
 
+// ExpiresIn は発行するトークンの有効期間を返します。
+// Cookie の Max-Age などクライアントへ伝える有効期間は、この値から導出してください。
+func (g *Generator) ExpiresIn() time.Duration {
+	return g.expiration
+}
+
 // GenerateToken は標準クレームを含む署名済みJWTトークンを生成します。
 func (g *Generator) GenerateToken(userID int64, email string) (string, error) {
-	claims := gojwt.MapClaims{
+	return g.sign(gojwt.MapClaims{
 		"sub":   strconv.FormatInt(userID, 10),
 		"exp":   time.Now().Add(g.expiration).Unix(),
 		"iat":   time.Now().Unix(),
 		"email": email,
+	})
+}
+
+// GenerateImpersonationToken は actor（発行した管理者）が userID のユーザーとして振る舞うトークンを生成します。
+// sub は対象のユーザー、act.sub（RFC 8693 の actor クレーム）は actor で、impersonation=true を付けます。
+// 有効期間は Generator の設定によらず ImpersonationExpiration です。
+func (g *Generator) GenerateImpersonationToken(userID int64, email, actor string) (string, error) {
+	if actor == "" {
+		return "", fmt.Errorf("impersonation token requires an actor")
 	}
+	now := time.Now()
+	return g.sign(gojwt.MapClaims{
+		"sub":           strconv.FormatInt(userID, 10),
+		"exp":           now.Add(ImpersonationExpiration).Unix(),
+		"iat":           now.Unix(),
+		"email":         email,
+		"act":           map[string]any{"sub": actor},
+		"impersonation": true,
+	})
+}
+
+// ImpersonationExpiresIn はなりすましトークンの有効期間（ImpersonationExpiration）を返します。
+func (g *Generator) ImpersonationExpiresIn() time.Duration {
+	return ImpersonationExpiration
+}
 
+// sign は claims を HS256 で署名したトークンを返します。
+func (g *Generator) sign(claims gojwt.MapClaims) (string, error) {
 	token := gojwt.NewWithClaims(gojwt.SigningMethodHS256, claims)
 	signed, err := token.SignedString(g.secret)
 	if err != nil {
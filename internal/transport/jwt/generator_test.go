@@ -37,6 +37,9 @@ func TestNewGenerator(t *testing.T) {
 			if gen.expiration != tt.expiration {
 				t.Errorf("expected expiration %v, got %v", tt.expiration, gen.expiration)
 			}
+			if gen.ExpiresIn() != tt.expiration {
+				t.Errorf("expected ExpiresIn %v, got %v", tt.expiration, gen.ExpiresIn())
+			}
 		})
 	}
 }
@@ -177,3 +180,41 @@ func TestGenerator_GenerateToken_DifferentUsersProduceDifferentTokens(t *testing
 		t.Error("expected different tokens for different users")
 	}
 }
+
+// TestGenerator_GenerateImpersonationToken はなりすましトークンが発行者（act）と印を含み、短い期限で発行されることを検証します。
+func TestGenerator_GenerateImpersonationToken(t *testing.T) {
+	t.Parallel()
+
+	gen := NewGenerator("test-secret", 24*time.Hour)
+	if gen.ImpersonationExpiresIn() != ImpersonationExpiration {
+		t.Errorf("ImpersonationExpiresIn = %v, want %v", gen.ImpersonationExpiresIn(), ImpersonationExpiration)
+	}
+
+	before := time.Now().Truncate(time.Second)
+	tokenStr, err := gen.GenerateImpersonationToken(7, "user@example.com", "12")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	claims, err := parseToken("test-secret", tokenStr)
+	if err != nil {
+		t.Fatalf("parseToken: %v", err)
+	}
+	if claims.userID != 7 || claims.actor != "12" {
+		t.Errorf("claims = %+v, want userID 7 and actor 12", claims)
+	}
+
+	token, _ := gojwt.Parse(tokenStr, func(*gojwt.Token) (interface{}, error) { return []byte("test-secret"), nil })
+	mc := token.Claims.(gojwt.MapClaims)
+	if mc["impersonation"] != true {
+		t.Errorf("impersonation = %v, want true", mc["impersonation"])
+	}
+	exp, _ := mc.GetExpirationTime()
+	if exp == nil || exp.Time.Before(before.Add(ImpersonationExpiration)) || exp.Time.After(time.Now().Add(ImpersonationExpiration)) {
+		t.Errorf("exp = %v, want about %v from now", exp, ImpersonationExpiration)
+	}
+
+	if _, err := gen.GenerateImpersonationToken(7, "user@example.com", ""); err == nil {
+		t.Error("expected error for empty actor")
+	}
+}
@@ -0,0 +1,87 @@
+package jwt
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+)
+
+// EnvKeyImpersonationWriteAllowlist はなりすまし中でも書き込みを許可するルートの環境変数キーです。
+const EnvKeyImpersonationWriteAllowlist = "IMPERSONATION_WRITE_ALLOWLIST"
+
+// writeMethods は状態を変更するメソッドです。なりすまし中は許可リストにないルートで拒否します。
+var writeMethods = []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// ParseWriteAllowlist は IMPERSONATION_WRITE_ALLOWLIST の各要素（"<METHOD> <ルートのパターン>"、例: "PUT /v1/me/digest"）を検証し、
+// 比較に使う正規化した形（メソッドは大文字、空白 1 つ区切り）で返します。パターンはルーターに登録した形（{id} 等を含む）で指定します。
+func ParseWriteAllowlist(entries []string) ([]string, error) {
+	out := make([]string, 0, len(entries))
+	for _, e := range entries {
+		fields := strings.Fields(e)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid entry %q: want \"<METHOD> <route pattern>\"", e)
+		}
+		method := strings.ToUpper(fields[0])
+		if !slices.Contains(writeMethods, method) {
+			return nil, fmt.Errorf("invalid entry %q: method must be one of %s", e, strings.Join(writeMethods, ", "))
+		}
+		if !strings.HasPrefix(fields[1], "/") {
+			return nil, fmt.Errorf("invalid entry %q: route pattern must start with /", e)
+		}
+		out = append(out, method+" "+fields[1])
+	}
+	return out, nil
+}
+
+// ImpersonationGuard はなりすましトークン（GenerateImpersonationToken）で認証したリクエストを制限・記録するミドルウェアを返します。
+// AuthRequired の後に置き、通常のトークンのリクエストはそのまま通します。
+//
+//   - 状態を変更するリクエスト（POST / PUT / PATCH / DELETE）は、allow（ParseWriteAllowlist の結果）に
+//     "<METHOD> <ルートのパターン>" がない限り 403 で拒否します（既定は読み取り専用）
+//   - 拒否したものを含むすべてのリクエストを、発行者と対象のユーザーIDとともに監査ログ（audit=true）に出力します
+func ImpersonationGuard(allow []string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			actor, ok := ActorFromContext(r.Context())
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			route := r.URL.Path
+			if rc := chi.RouteContext(r.Context()); rc != nil && rc.RoutePattern() != "" {
+				route = rc.RoutePattern()
+			}
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			blocked := slices.Contains(writeMethods, r.Method) && !slices.Contains(allow, r.Method+" "+route)
+			if blocked {
+				httpx.WriteJSON(ww, http.StatusForbidden, api.ErrorResponse{Error: "impersonation is read-only"})
+			} else {
+				next.ServeHTTP(ww, r)
+			}
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			userID, _ := UserIDFromContext(r.Context())
+			slog.InfoContext(r.Context(), "impersonated request",
+				"audit", true,
+				"actor", actor,
+				"user_id", userID,
+				"method", r.Method,
+				"route", route,
+				"status", status,
+				"blocked", blocked,
+			)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestParseWriteAllowlist(t *testing.T) {
+	t.Parallel()
+
+	got, err := ParseWriteAllowlist([]string{"put /v1/me/digest", "DELETE  /v1/watchlist/{symbol}"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"PUT /v1/me/digest", "DELETE /v1/watchlist/{symbol}"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("ParseWriteAllowlist = %q, want %q", got, want)
+	}
+
+	for _, entry := range []string{"/v1/me/digest", "GET /v1/me", "PUT v1/me/digest", "PUT /a /b"} {
+		if _, err := ParseWriteAllowlist([]string{entry}); err == nil {
+			t.Errorf("ParseWriteAllowlist(%q) expected error", entry)
+		}
+	}
+}
+
+// TestImpersonationGuard は、なりすまし中は許可リストにない書き込みを拒否し、すべてのリクエストを監査ログに残すことを検証します。
+// slog のデフォルトロガーを差し替えるため並列実行しません。
+func TestImpersonationGuard(t *testing.T) {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	newRouter := func(actor string) http.Handler {
+		r := chi.NewRouter()
+		r.Route("/v1", func(r chi.Router) {
+			r.Group(func(r chi.Router) {
+				r.Use(func(next http.Handler) http.Handler {
+					return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+						ctx := WithUserID(req.Context(), 5)
+						if actor != "" {
+							ctx = WithActor(ctx, actor)
+						}
+						next.ServeHTTP(w, req.WithContext(ctx))
+					})
+				})
+				r.Use(ImpersonationGuard([]string{"PUT /v1/me/digest"}))
+				r.Get("/watchlist", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+				r.Delete("/watchlist/{symbol}", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) })
+				r.Put("/me/digest", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) })
+			})
+		})
+		return r
+	}
+
+	tests := []struct {
+		name        string
+		actor       string
+		method      string
+		target      string
+		wantStatus  int
+		wantRoute   string
+		wantBlocked bool
+	}{
+		{"read is allowed", "12", http.MethodGet, "/v1/watchlist", http.StatusOK, "/v1/watchlist", false},
+		{"write is blocked", "12", http.MethodDelete, "/v1/watchlist/AAPL", http.StatusForbidden, "/v1/watchlist/{symbol}", true},
+		{"allowlisted write", "12", http.MethodPut, "/v1/me/digest", http.StatusNoContent, "/v1/me/digest", false},
+		{"regular token is untouched", "", http.MethodDelete, "/v1/watchlist/AAPL", http.StatusNoContent, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			w := httptest.NewRecorder()
+			req := httptest.NewRequestWithContext(context.Background(), tt.method, tt.target, nil)
+			newRouter(tt.actor).ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body: %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.actor == "" {
+				if buf.Len() != 0 {
+					t.Errorf("expected no audit log, got %s", buf.String())
+				}
+				return
+			}
+
+			var entry map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("audit log is not JSON: %v (%s)", err, buf.String())
+			}
+			if entry["msg"] != "impersonated request" || entry["audit"] != true || entry["actor"] != tt.actor ||
+				entry["user_id"] != float64(5) || entry["method"] != tt.method || entry["route"] != tt.wantRoute ||
+				entry["status"] != float64(tt.wantStatus) || entry["blocked"] != tt.wantBlocked {
+				t.Errorf("unexpected audit log: %v", entry)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	gojwt "github.com/golang-jwt/jwt/v5"
 
@@ -48,40 +49,76 @@ func AuthRequired(secret string) func(http.Handler) http.Handler {
 				return
 			}
 
-			// 3. JWT署名をパースして検証
-			token, err := gojwt.Parse(tokenStr, func(t *gojwt.Token) (interface{}, error) {
-				// 署名アルゴリズムを確認（HMACのみ許可）
-				if _, ok := t.Method.(*gojwt.SigningMethodHMAC); !ok {
-					return nil, gojwt.ErrSignatureInvalid
-				}
-				return []byte(secret), nil
-			})
-			if err != nil || !token.Valid {
-				// 検証エラーまたは無効なトークン
-				httpx.WriteJSON(w, http.StatusUnauthorized, api.ErrorResponse{Error: "invalid token"})
-				return
-			}
-
-			// 4. クレーム（ペイロード）を抽出
-			claims, ok := token.Claims.(gojwt.MapClaims)
-			if !ok {
-				httpx.WriteJSON(w, http.StatusUnauthorized, api.ErrorResponse{Error: "invalid token claims"})
-				return
-			}
-			userID, err := parseSubject(claims["sub"])
+			// 3. JWT署名とクレーム（ペイロード）を検証
+			claims, err := parseToken(secret, tokenStr)
 			if err != nil {
-				httpx.WriteJSON(w, http.StatusUnauthorized, api.ErrorResponse{Error: "invalid token: invalid subject"})
+				httpx.WriteJSON(w, http.StatusUnauthorized, api.ErrorResponse{Error: err.Error()})
 				return
 			}
 
-			// 5. ユーザーIDと認証方式を context に格納し、次のハンドラーへ制御を渡す
-			ctx := WithUserID(r.Context(), userID)
+			// 4. ユーザーID・認証方式・発行日時（なりすましトークンの場合は発行者）を context に格納し、次のハンドラーへ制御を渡す
+			ctx := WithUserID(r.Context(), claims.userID)
 			ctx = withAuthSource(ctx, authSource)
+			if !claims.issuedAt.IsZero() {
+				ctx = withIssuedAt(ctx, claims.issuedAt)
+			}
+			if claims.actor != "" {
+				ctx = WithActor(ctx, claims.actor)
+			}
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+// トークンの検証エラーです。メッセージは 401 のレスポンスにそのまま使います。
+var (
+	errInvalidToken   = errors.New("invalid token")
+	errInvalidClaims  = errors.New("invalid token claims")
+	errInvalidSubject = errors.New("invalid token: invalid subject")
+)
+
+// tokenClaims は検証したトークンから取り出した値です。
+type tokenClaims struct {
+	userID   int64
+	issuedAt time.Time // iat。ない場合はゼロ値
+	actor    string    // なりすましトークンの発行者（act.sub）。通常のトークンでは空
+}
+
+// parseToken は HMAC 署名のトークンを検証し、ユーザーID（sub）・発行日時・なりすましの発行者を返します。
+// impersonation=true と act.sub はそろっている場合だけ受け付け、片方だけのトークンは不正なクレームとして拒否します。
+func parseToken(secret, tokenStr string) (tokenClaims, error) {
+	token, err := gojwt.Parse(tokenStr, func(t *gojwt.Token) (interface{}, error) {
+		// 署名アルゴリズムを確認（HMACのみ許可）
+		if _, ok := t.Method.(*gojwt.SigningMethodHMAC); !ok {
+			return nil, gojwt.ErrSignatureInvalid
+		}
+		return []byte(secret), nil
+	})
+	if err != nil || !token.Valid {
+		return tokenClaims{}, errInvalidToken
+	}
+	claims, ok := token.Claims.(gojwt.MapClaims)
+	if !ok {
+		return tokenClaims{}, errInvalidClaims
+	}
+	userID, err := parseSubject(claims["sub"])
+	if err != nil {
+		return tokenClaims{}, errInvalidSubject
+	}
+	out := tokenClaims{userID: userID}
+	if t, err := claims.GetIssuedAt(); err == nil && t != nil {
+		out.issuedAt = t.Time
+	}
+	impersonation, _ := claims["impersonation"].(bool)
+	if act, ok := claims["act"].(map[string]any); ok {
+		out.actor, _ = act["sub"].(string)
+	}
+	if impersonation != (out.actor != "") {
+		return tokenClaims{}, errInvalidClaims
+	}
+	return out, nil
+}
+
 // parseSubject はJWT subjectをユーザーIDへ変換します。
 // 新規トークンは文字列を使用しますが、移行中の既存トークン向けに安全な範囲の数値も受理します。
 func parseSubject(claim any) (int64, error) {
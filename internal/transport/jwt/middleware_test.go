@@ -244,3 +244,52 @@ func createTokenWithSubject(secret string, subject any, expiration time.Duration
 	signed, _ := token.SignedString([]byte(secret))
 	return signed
 }
+
+// TestAuthRequired_Impersonation はなりすましトークンの発行者がコンテキストに設定され、act と impersonation が揃わないトークンが拒否されることを検証します。
+func TestAuthRequired_Impersonation(t *testing.T) {
+	const testSecret = "test-secret-key-for-impersonation"
+	t.Setenv(EnvKeyJWTSecret, testSecret)
+
+	token, err := NewGenerator(testSecret, time.Hour).GenerateImpersonationToken(5, "user@example.com", "12")
+	if err != nil {
+		t.Fatalf("GenerateImpersonationToken: %v", err)
+	}
+	w, nextCalled, seen := runAuth("Bearer "+token, nil)
+	if !nextCalled {
+		t.Fatalf("expected request not to be aborted, response: %s", w.Body.String())
+	}
+	if actor, ok := ActorFromContext(seen.Context()); !ok || actor != "12" {
+		t.Errorf("ActorFromContext = (%q, %v), want (12, true)", actor, ok)
+	}
+	if userID, _ := UserIDFromContext(seen.Context()); userID != 5 {
+		t.Errorf("expected userID 5, got %d", userID)
+	}
+
+	// 通常のトークンには発行者が設定されない
+	_, _, seen = runAuth("Bearer "+createTokenWithSecret(testSecret, 5, time.Hour), nil)
+	if _, ok := ActorFromContext(seen.Context()); ok {
+		t.Error("expected no actor for a regular token")
+	}
+
+	tests := []struct {
+		name   string
+		claims gojwt.MapClaims
+	}{
+		{"act without impersonation", gojwt.MapClaims{"act": map[string]any{"sub": "12"}}},
+		{"impersonation without act", gojwt.MapClaims{"impersonation": true}},
+		{"empty act subject", gojwt.MapClaims{"act": map[string]any{"sub": ""}, "impersonation": true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.claims["sub"] = "5"
+			tt.claims["exp"] = time.Now().Add(time.Hour).Unix()
+			tt.claims["iat"] = time.Now().Unix()
+			tokenStr, _ := gojwt.NewWithClaims(gojwt.SigningMethodHS256, tt.claims).SignedString([]byte(testSecret))
+
+			w, _, _ := runAuth("Bearer "+tokenStr, nil)
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
+			}
+		})
+	}
+}
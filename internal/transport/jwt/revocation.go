@@ -0,0 +1,128 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/apperr"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+)
+
+// ErrRevocationUnavailable は Redis に接続できず、一括失効を記録できなかったことを表します。
+// 失効を前提に状態を変える呼び出し元（アカウントの削除など）は、この場合に処理を中止します。
+var ErrRevocationUnavailable = apperr.New(apperr.KindUnavailable, "session revocation unavailable", "token revocation skipped: Redis unavailable")
+
+// RedisProvider は現在利用できる Redis クライアントを返します。障害中は nil を返します。
+type RedisProvider interface {
+	Client() *redis.Client
+}
+
+// Revocations はユーザー単位のトークン一括失効を Redis に記録します。
+//
+// JWT はステートレスなため発行済みトークンを個別に無効化できません。代わりにユーザーごとに
+// 「この時刻より前に発行された（iat が古い）トークンは無効」という下限を保存し、RejectRevoked で照合します。
+// 下限より古いトークンは有効期間の経過後に exp で失効するため、キーの TTL はトークンの有効期間で十分です。
+//
+// rdb が nil の場合は失効を記録できません（RevokeAllByUserID は ErrRevocationUnavailable を返し、照合は常に通します）。
+type Revocations struct {
+	rdb       *redis.Client
+	provider  RedisProvider
+	keyPrefix string
+	ttl       time.Duration
+	now       func() time.Time
+}
+
+// NewRevocations は指定された Redis クライアントで Revocations を生成します。
+// keyPrefix は環境の名前空間を含むキープレフィックス（例: "staging:auth:revoked"）、
+// ttl は発行するトークンの有効期間（Generator.ExpiresIn）です。
+func NewRevocations(rdb *redis.Client, keyPrefix string, ttl time.Duration) *Revocations {
+	return &Revocations{rdb: rdb, keyPrefix: keyPrefix, ttl: ttl, now: time.Now}
+}
+
+// WithRedisProvider は Redis クライアントを呼び出しごとに p から取得するよう設定します。
+// p が nil を返す間は rdb が nil の場合と同じ縮退動作になります。
+func (r *Revocations) WithRedisProvider(p RedisProvider) *Revocations {
+	r.provider = p
+	return r
+}
+
+func (r *Revocations) client() *redis.Client {
+	if r.provider != nil {
+		return r.provider.Client()
+	}
+	return r.rdb
+}
+
+func (r *Revocations) key(userID int64) string {
+	return r.keyPrefix + ":" + strconv.FormatInt(userID, 10)
+}
+
+// RevokeAllByUserID は userID に現在までに発行されたトークンをすべて失効させます。
+// iat は秒精度のため、失効と同じ秒に発行されたトークンも失効対象に含めます。
+// Redis がない（障害中を含む）場合は何も記録せず ErrRevocationUnavailable を返します。
+func (r *Revocations) RevokeAllByUserID(ctx context.Context, userID int64) error {
+	rdb := r.client()
+	if rdb == nil {
+		return ErrRevocationUnavailable
+	}
+	cutoff := r.now().Unix() + 1
+	if err := rdb.Set(ctx, r.key(userID), cutoff, r.ttl).Err(); err != nil {
+		return fmt.Errorf("revocation store error: %w", err)
+	}
+	return nil
+}
+
+// RevokedBefore は userID のトークン失効の下限を返します。失効が記録されていない場合は ok=false です。
+func (r *Revocations) RevokedBefore(ctx context.Context, userID int64) (time.Time, bool, error) {
+	rdb := r.client()
+	if rdb == nil {
+		return time.Time{}, false, nil
+	}
+	cutoff, err := rdb.Get(ctx, r.key(userID)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("revocation store error: %w", err)
+	}
+	return time.Unix(cutoff, 0), true, nil
+}
+
+// rejects は発行日時 iat のトークンが一括失効の対象かを返します。
+// 失効ストアの障害時は警告ログを出力して false を返します（Redis 障害で全ユーザーを締め出さないため）。
+func (r *Revocations) rejects(ctx context.Context, userID int64, iat time.Time) bool {
+	cutoff, revoked, err := r.RevokedBefore(ctx, userID)
+	if err != nil {
+		slog.WarnContext(ctx, "token revocation check failed, allowing request", "error", err, "user_id", userID)
+		return false
+	}
+	return revoked && iat.Before(cutoff)
+}
+
+// RejectRevoked は一括失効より前に発行されたトークンを 401 で拒否するミドルウェアを返します。
+// AuthRequired の後段に置きます。JWT で認証されていないリクエスト（API キー等）はそのまま通します。
+// 失効ストアの障害時はリクエストを通し、警告ログを出力します（Redis 障害で全ユーザーを締め出さないため）。
+func RejectRevoked(revocations *Revocations) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := UserIDFromContext(r.Context())
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if revocations.rejects(r.Context(), userID, issuedAtFromContext(r.Context())) {
+				httpx.WriteJSON(w, http.StatusUnauthorized, api.ErrorResponse{Error: "token revoked"})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
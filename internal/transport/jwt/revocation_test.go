@@ -0,0 +1,107 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/testsupport"
+)
+
+// newTestRevocations は miniredis を使う Revocations と、時刻を差し替えるための関数を返します。
+func newTestRevocations(t *testing.T) (*Revocations, *miniredis.Miniredis, func(time.Time)) {
+	t.Helper()
+	mr, rdb := testsupport.NewMiniRedis(t)
+	rev := NewRevocations(rdb, "test:auth:revoked", time.Hour)
+	return rev, mr, func(now time.Time) { rev.now = func() time.Time { return now } }
+}
+
+// serveWithRevocation は AuthRequired → RejectRevoked の順でトークンを検証し、ステータスを返します。
+func serveWithRevocation(t *testing.T, secret string, rev *Revocations, token string) int {
+	t.Helper()
+	h := testsupport.NewHarness(t, AuthRequired(secret), RejectRevoked(rev))
+	h.Get("/", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	return h.DoGet("/", http.Header{"Authorization": {"Bearer " + token}}).Code
+}
+
+func TestRejectRevoked(t *testing.T) {
+	t.Parallel()
+
+	const secret = "test-secret-key-for-revocation"
+	rev, mr, setNow := newTestRevocations(t)
+	revokedAt := time.Now().Add(-10 * time.Minute).Truncate(time.Second)
+	setNow(revokedAt)
+	if err := rev.RevokeAllByUserID(context.Background(), 1); err != nil {
+		t.Fatalf("RevokeAllByUserID: %v", err)
+	}
+	if ttl := mr.TTL("test:auth:revoked:1"); ttl != time.Hour {
+		t.Errorf("cutoff TTL = %v, want token lifetime 1h", ttl)
+	}
+
+	tests := []struct {
+		name     string
+		userID   int64
+		issuedAt time.Time
+		want     int
+	}{
+		{name: "失効前に発行されたトークンは拒否", userID: 1, issuedAt: revokedAt.Add(-time.Minute), want: http.StatusUnauthorized},
+		{name: "失効と同じ秒に発行されたトークンも拒否", userID: 1, issuedAt: revokedAt, want: http.StatusUnauthorized},
+		{name: "失効後に発行されたトークンは許可", userID: 1, issuedAt: revokedAt.Add(time.Minute), want: http.StatusNoContent},
+		{name: "他ユーザーのトークンは影響を受けない", userID: 2, issuedAt: revokedAt.Add(-time.Minute), want: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			token := testsupport.SessionToken(t, secret, tt.userID, testsupport.IssuedAt(tt.issuedAt))
+			if got := serveWithRevocation(t, secret, rev, token); got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRejectRevoked_Degraded(t *testing.T) {
+	t.Parallel()
+
+	const secret = "test-secret-key-for-revocation"
+	token := testsupport.SessionToken(t, secret, 1, testsupport.IssuedAt(time.Now().Add(-time.Hour+time.Minute)))
+
+	t.Run("Redis なしでは記録も照合もしない", func(t *testing.T) {
+		t.Parallel()
+		rev := NewRevocations(nil, "test:auth:revoked", time.Hour)
+		if err := rev.RevokeAllByUserID(context.Background(), 1); !errors.Is(err, ErrRevocationUnavailable) {
+			t.Fatalf("nil Redis should report ErrRevocationUnavailable, got %v", err)
+		}
+		if got := serveWithRevocation(t, secret, rev, token); got != http.StatusNoContent {
+			t.Errorf("status = %d, want %d", got, http.StatusNoContent)
+		}
+	})
+
+	t.Run("Redis 障害時は通す", func(t *testing.T) {
+		t.Parallel()
+		rev, mr, _ := newTestRevocations(t)
+		mr.Close()
+		if got := serveWithRevocation(t, secret, rev, token); got != http.StatusNoContent {
+			t.Errorf("status = %d, want %d", got, http.StatusNoContent)
+		}
+		if err := rev.RevokeAllByUserID(context.Background(), 1); err == nil {
+			t.Error("RevokeAllByUserID should report a store failure")
+		}
+	})
+
+	t.Run("JWT 以外の認証（ユーザーなし）は通す", func(t *testing.T) {
+		t.Parallel()
+		rev, _, _ := newTestRevocations(t)
+		w := httptest.NewRecorder()
+		next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) })
+		RejectRevoked(rev)(next).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != http.StatusNoContent {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusNoContent)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+// Package maintenance はスキーマの移行中などに API を読み取り専用にするメンテナンスモードを提供します。
+//
+// モードはフィーチャーフラグ（FlagName）で切り替えるため、FLAG_MAINTENANCE_MODE で起動時に、
+// 管理API（PUT /v1/admin/flags/maintenance_mode）で実行中に全インスタンスへ反映できます（最大でフラグのキャッシュの TTL 遅れ）。
+// 有効な間は Guard が状態を変更するリクエストを 503 で拒否し、読み取りはそのまま通します。
+// バッチ・定期ジョブは Mode.Active で確認して書き込みを見送ります。
+package maintenance
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+)
+
+// FlagName はメンテナンスモードのフィーチャーフラグ名です。
+const FlagName = "maintenance_mode"
+
+const (
+	// EnvKeyWriteAllowlist はメンテナンス中でも書き込みを許可するルートの環境変数キーです。
+	EnvKeyWriteAllowlist = "MAINTENANCE_WRITE_ALLOWLIST"
+	// EnvKeyRetryAfter はメンテナンス中に拒否した書き込みに返す Retry-After の環境変数キーです。
+	EnvKeyRetryAfter = "MAINTENANCE_RETRY_AFTER"
+)
+
+// DefaultRetryAfter はメンテナンス中に拒否した書き込みに返す Retry-After の既定値です。
+const DefaultRetryAfter = 5 * time.Minute
+
+// DefaultWriteAllowlist は MAINTENANCE_WRITE_ALLOWLIST 未設定時にメンテナンス中でも許可する書き込みです。
+// ログイン・ログアウト（全端末のトークンの失効を含む）と、メンテナンスモードを解除するためのフラグの切り替えです
+// （トークンの失効とフラグは DB ではなく Redis に保存します）。
+var DefaultWriteAllowlist = []string{
+	"POST /v1/login",
+	"DELETE /v1/logout",
+	"POST /v1/auth/logout_all",
+	"PUT /v1/admin/flags/{name}",
+}
+
+// writeMethods は状態を変更するメソッドです。メンテナンス中は許可リストにないルートで拒否します。
+var writeMethods = []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// FlagChecker はフィーチャーフラグの参照を抽象化します（shared/flags の Registry が実装します）。
+type FlagChecker interface {
+	Enabled(ctx context.Context, name string) bool
+}
+
+// Mode はメンテナンスモードの状態です。nil の Mode は常に無効です。
+type Mode struct {
+	flags FlagChecker
+}
+
+// New は flags の FlagName でメンテナンスモードを判定する Mode を生成します。flags が nil の場合は常に無効です。
+func New(flags FlagChecker) *Mode {
+	return &Mode{flags: flags}
+}
+
+// Active はメンテナンスモードが有効かを返します。
+func (m *Mode) Active(ctx context.Context) bool {
+	if m == nil || m.flags == nil {
+		return false
+	}
+	return m.flags.Enabled(ctx, FlagName)
+}
+
+type activeKey struct{}
+
+// ActiveFromContext はリクエストを Guard がメンテナンス中に通したか（読み取りを DB よりキャッシュで返すべきか）を返します。
+func ActiveFromContext(ctx context.Context) bool {
+	v, _ := ctx.Value(activeKey{}).(bool)
+	return v
+}
+
+// Guard はメンテナンス中の書き込みを拒否するミドルウェアを返します。
+// ルートのパターンで照合するため、ルーティング後に実行されるグループのミドルウェア（r.Group 内の r.Use）に置きます。
+//
+//   - 状態を変更するリクエスト（POST / PUT / PATCH / DELETE）は、allow（"<METHOD> <ルートのパターン>"）にない限り
+//     503・Retry-After（retryAfter、0 以下なら DefaultRetryAfter）で拒否します
+//   - それ以外のリクエストは、メンテナンス中であることを context に記録して通します（ActiveFromContext）
+//
+// メンテナンスモードが無効の間はすべてのリクエストをそのまま通します。
+func Guard(mode *Mode, allow []string, retryAfter time.Duration) func(http.Handler) http.Handler {
+	if retryAfter <= 0 {
+		retryAfter = DefaultRetryAfter
+	}
+	retryAfterSeconds := strconv.Itoa(int(math.Ceil(retryAfter.Seconds())))
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !mode.Active(r.Context()) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			route := r.URL.Path
+			if rc := chi.RouteContext(r.Context()); rc != nil && rc.RoutePattern() != "" {
+				route = rc.RoutePattern()
+			}
+			if slices.Contains(writeMethods, r.Method) && !slices.Contains(allow, r.Method+" "+route) {
+				w.Header().Set("Retry-After", retryAfterSeconds)
+				httpx.WriteJSON(w, http.StatusServiceUnavailable, api.ErrorResponse{
+					Error: "maintenance",
+					Hint:  "the service is in read-only maintenance mode; retry later",
+				})
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), activeKey{}, true)))
+		})
+	}
+}
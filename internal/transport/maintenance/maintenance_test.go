@@ -0,0 +1,155 @@
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/shared/flags"
+)
+
+// memBackend はフラグの上書き値をメモリに保存する flags.Backend です（Redis の代わり）。
+type memBackend struct {
+	mu     sync.Mutex
+	values map[string]bool
+}
+
+func (b *memBackend) Load(context.Context) (map[string]bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make(map[string]bool, len(b.values))
+	for k, v := range b.values {
+		out[k] = v
+	}
+	return out, nil
+}
+
+func (b *memBackend) Store(_ context.Context, name string, enabled bool) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.values[name] = enabled
+	return nil
+}
+
+func newRegistry() *flags.Registry {
+	return flags.NewRegistry([]flags.Definition{{Name: FlagName}}, nil, &memBackend{values: map[string]bool{}}, time.Hour)
+}
+
+// newRouter は本番と同じくグループのミドルウェアとして Guard を置き、書き込み・読み取りのルートを登録します。
+// 読み取りのハンドラーは ActiveFromContext の値を X-Maintenance に返します。
+func newRouter(mode *Mode) http.Handler {
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Maintenance", map[bool]string{true: "1", false: "0"}[ActiveFromContext(r.Context())])
+		w.WriteHeader(http.StatusOK)
+	}
+	r := chi.NewRouter()
+	r.Route("/v1", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(Guard(mode, DefaultWriteAllowlist, 90*time.Second))
+			r.Get("/candles/{code}", ok)
+			r.Head("/candles/{code}", ok)
+			r.Options("/watchlist", ok)
+			r.Post("/login", ok)
+			r.Delete("/logout", ok)
+			r.Post("/auth/logout_all", ok)
+			r.Post("/watchlist", ok)
+			r.Put("/watchlist/order", ok)
+			r.Patch("/annotations/{id}", ok)
+			r.Delete("/watchlist/{code}", ok)
+			r.Put("/admin/flags/{name}", ok)
+		})
+	})
+	return r
+}
+
+func TestGuard_MethodMatrix(t *testing.T) {
+	t.Parallel()
+
+	registry := newRegistry()
+	require.NoError(t, registry.Set(context.Background(), FlagName, true))
+	h := newRouter(New(registry))
+
+	tests := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/v1/candles/AAPL", http.StatusOK},
+		{http.MethodHead, "/v1/candles/AAPL", http.StatusOK},
+		{http.MethodOptions, "/v1/watchlist", http.StatusOK},
+		{http.MethodPost, "/v1/watchlist", http.StatusServiceUnavailable},
+		{http.MethodPut, "/v1/watchlist/order", http.StatusServiceUnavailable},
+		{http.MethodPatch, "/v1/annotations/3", http.StatusServiceUnavailable},
+		{http.MethodDelete, "/v1/watchlist/AAPL", http.StatusServiceUnavailable},
+		// 既定の許可リスト（ログイン・ログアウト・フラグの切り替え）
+		{http.MethodPost, "/v1/login", http.StatusOK},
+		{http.MethodDelete, "/v1/logout", http.StatusOK},
+		{http.MethodPost, "/v1/auth/logout_all", http.StatusOK},
+		{http.MethodPut, "/v1/admin/flags/maintenance_mode", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			t.Parallel()
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			require.Equal(t, tt.want, w.Code)
+			if tt.want != http.StatusServiceUnavailable {
+				assert.Equal(t, "1", w.Header().Get("X-Maintenance"), "通したリクエストにはメンテナンス中であることを記録する")
+				return
+			}
+			assert.Equal(t, "90", w.Header().Get("Retry-After"))
+			var body api.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, "maintenance", body.Error)
+			assert.NotEmpty(t, body.Hint)
+		})
+	}
+}
+
+// TestGuard_FlipAtRuntime はフラグの Backend での切り替えが、再起動なしで次のリクエストから反映されることを検証します。
+func TestGuard_FlipAtRuntime(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	registry := newRegistry()
+	mode := New(registry)
+	h := newRouter(mode)
+	post := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/watchlist", nil))
+		return w
+	}
+
+	assert.False(t, mode.Active(ctx))
+	w := post()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-Maintenance"))
+
+	require.NoError(t, registry.Set(ctx, FlagName, true))
+	assert.True(t, mode.Active(ctx))
+	assert.Equal(t, http.StatusServiceUnavailable, post().Code)
+
+	require.NoError(t, registry.Set(ctx, FlagName, false))
+	assert.Equal(t, http.StatusOK, post().Code)
+}
+
+func TestMode_Nil(t *testing.T) {
+	t.Parallel()
+
+	var mode *Mode
+	assert.False(t, mode.Active(context.Background()))
+	assert.False(t, New(nil).Active(context.Background()))
+
+	// メンテナンスモードが無効の間は、Retry-After の既定値によらずすべて通す
+	w := httptest.NewRecorder()
+	newRouter(nil).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/watchlist", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
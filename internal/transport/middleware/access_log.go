@@ -4,11 +4,14 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5/middleware"
 
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/infra/logging"
 	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
 )
 
@@ -18,13 +21,19 @@ import (
 // projectID（GOOGLE_CLOUD_PROJECT）が指定されている場合、X-Cloud-Trace-Context ヘッダーから
 // トレース ID を抽出してログをリクエスト単位で相関させます。空の場合はトレースフィールドを
 // 出力しません。
+//
+// 2xx のレスポンスは件数が多いため sampled=true を付け、LOG_SAMPLE_EVERY による間引きの対象にします
+// （4xx / 5xx は常に出力）。
+//
+// URL に載る資格情報（WebSocket の ?access_token=、エクスポートのダウンロードリンクの ?sig=）は
+// requestUrl に出力する前に REDACTED に置き換えます（redactedQueryParams）。
 func AccessLog(projectID string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
 			path := r.URL.Path
 			if raw := r.URL.RawQuery; raw != "" {
-				path = path + "?" + raw
+				path = path + "?" + redactQuery(raw)
 			}
 
 			// ステータスコードと書き込みバイト数を捕捉するためレスポンスライターをラップする。
@@ -62,11 +71,46 @@ func AccessLog(projectID string) func(http.Handler) http.Handler {
 				)
 			}
 
+			if status >= http.StatusOK && status < http.StatusMultipleChoices {
+				attrs = append(attrs, slog.Bool(logging.SampledKey, true))
+			}
+
 			slog.LogAttrs(r.Context(), severityForStatus(status), "request", attrs...)
 		})
 	}
 }
 
+// redactedQueryParams はアクセスログに値を出力しないクエリパラメータです。
+// いずれも URL だけで認可が成立する資格情報のため、ログから再利用されないようにします。
+var redactedQueryParams = []string{
+	"access_token", // WebSocket（/v1/ws）の JWT
+	"sig",          // エクスポートのダウンロードリンク（/v1/me/export/{id}/download）の署名
+}
+
+// redactedValue は redactedQueryParams の値の置き換え先です。
+const redactedValue = "REDACTED"
+
+// redactQuery は生のクエリ文字列のうち redactedQueryParams の値を REDACTED に置き換えて返します。
+// 調査で元の URL と突き合わせやすいよう、パラメータの順序とそれ以外の値（エスケープを含む）は変えません。
+func redactQuery(raw string) string {
+	parts := strings.Split(raw, "&")
+	changed := false
+	for i, part := range parts {
+		key, _, _ := strings.Cut(part, "=")
+		if k, err := url.QueryUnescape(key); err == nil {
+			key = k
+		}
+		if slices.Contains(redactedQueryParams, key) {
+			parts[i] = url.QueryEscape(key) + "=" + redactedValue
+			changed = true
+		}
+	}
+	if !changed {
+		return raw
+	}
+	return strings.Join(parts, "&")
+}
+
 // severityForStatus は HTTP ステータスコードを slog のレベルに対応付けます。
 // 5xx は Error、4xx は Warn、それ以外は Info とします。
 func severityForStatus(status int) slog.Level {
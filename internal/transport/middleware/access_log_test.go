@@ -124,12 +124,67 @@ func TestAccessLog_LogsStructuredRequest(t *testing.T) {
 	assert.Equal(t, http.MethodGet, httpReq["requestMethod"])
 	assert.Equal(t, "/v1/symbols?interval=1day", httpReq["requestUrl"])
 	assert.EqualValues(t, http.StatusOK, httpReq["status"])
+	// 2xx は間引きの対象として sampled=true が付く。
+	assert.Equal(t, true, got["sampled"])
 
 	// projectID が空のためトレースフィールドは出力されない。
 	_, hasTrace := got["logging.googleapis.com/trace"]
 	assert.False(t, hasTrace)
 }
 
+// TestAccessLog_RedactsCredentials は URL に載る資格情報（WebSocket の access_token・ダウンロードリンクの sig）が
+// アクセスログに出力されず、それ以外のクエリパラメータはそのまま残ることを検証します。
+func TestAccessLog_RedactsCredentials(t *testing.T) {
+	// 並列化しない: slog.Default() というグローバルを差し替えるため。
+	const secret = "eyJhbGciOiJIUzI1NiJ9.secret-token"
+
+	tests := []struct {
+		name   string
+		target string
+		want   string
+	}{
+		{
+			name:   "websocket access token",
+			target: "/v1/ws?access_token=" + secret + "&symbols=AAPL",
+			want:   "/v1/ws?access_token=REDACTED&symbols=AAPL",
+		},
+		{
+			name:   "export download signature",
+			target: "/v1/me/export/job-1/download?expires=1735689600&sig=" + secret,
+			want:   "/v1/me/export/job-1/download?expires=1735689600&sig=REDACTED",
+		},
+		{
+			name:   "escaped key",
+			target: "/v1/ws?access%5Ftoken=" + secret,
+			want:   "/v1/ws?access_token=REDACTED",
+		},
+		{
+			name:   "no credentials",
+			target: "/v1/symbols?q=a%26b&limit=5",
+			want:   "/v1/symbols?q=a%26b&limit=5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			restore := swapDefaultLogger(&buf)
+			defer restore()
+
+			h := AccessLog("")(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			}))
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			assert.NotContains(t, buf.String(), secret)
+			got := decodeLog(t, buf.Bytes())
+			httpReq, ok := got["httpRequest"].(map[string]any)
+			require.True(t, ok, "httpRequest field missing: %v", got)
+			assert.Equal(t, tt.want, httpReq["requestUrl"])
+		})
+	}
+}
+
 // TestAccessLog_IncludesTrace は projectID と X-Cloud-Trace-Context が揃っている場合に
 // トレース相関フィールドが出力されることを検証します。
 func TestAccessLog_IncludesTrace(t *testing.T) {
@@ -169,6 +224,9 @@ func TestAccessLog_ErrorStatusSeverity(t *testing.T) {
 
 	got := decodeLog(t, buf.Bytes())
 	assert.Equal(t, "ERROR", got["severity"])
+	// エラーは間引かせないため sampled を付けない。
+	_, hasSampled := got["sampled"]
+	assert.False(t, hasSampled)
 }
 
 // swapDefaultLogger は slog のデフォルトロガーをバッファ出力に差し替え、復元関数を返します。
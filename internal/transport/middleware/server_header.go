@@ -0,0 +1,14 @@
+package middleware
+
+import "net/http"
+
+// ServerHeader は Server レスポンスヘッダーに value（バージョンとコミット）を設定するミドルウェアを返します。
+// 運用者がどのデプロイが応答したかを確認するためのもので、SERVER_HEADER=false で無効にできます。
+func ServerHeader(value string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Server", value)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
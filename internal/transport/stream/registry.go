@@ -0,0 +1,151 @@
+// Package stream は SSE やファイルダウンロードなど、長時間レスポンスを返し続けるストリーミング接続を
+// グレースフルシャットダウン時に排出（drain）するための接続レジストリを提供します。
+//
+// シャットダウン手順:
+//  1. Drain で新規ストリームの受け付けを停止する（Middleware が 503 を返す）
+//  2. 既存ストリームのコンテキストを ErrShuttingDown でキャンセルし、ハンドラーに終了を促す
+//     （SSE ハンドラーは WriteReconnect で再接続イベントを送ってから戻る）
+//  3. 全ストリームの終了を排出予算（drain budget）まで待ち、その後 http.Server.Shutdown に進む
+//
+// ストリーミングしないハンドラーは登録不要です（http.Server.Shutdown が完了を待つため）。
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/api"
+	"github.com/UCHIDAnobuhiro/stock-backend/internal/transport/httpx"
+)
+
+// DefaultDrainBudget はシャットダウン時にストリームの終了を待つ既定の上限です。
+const DefaultDrainBudget = 5 * time.Second
+
+// ErrShuttingDown はサーバーのシャットダウンによりストリームのコンテキストがキャンセルされたことを示す原因です。
+// context.Cause で判定します（ShuttingDown を参照）。
+var ErrShuttingDown = errors.New("server shutting down")
+
+// Registry は実行中のストリーミング接続を追跡します。並行呼び出しに対して安全です。
+type Registry struct {
+	mu       sync.Mutex
+	draining bool
+	nextID   uint64
+	streams  map[uint64]context.CancelCauseFunc
+	idle     chan struct{} // draining 中に最後のストリームが終了したら close する
+}
+
+// NewRegistry は空の Registry を生成します。
+func NewRegistry() *Registry {
+	return &Registry{streams: map[uint64]context.CancelCauseFunc{}}
+}
+
+// Register はストリームを登録し、シャットダウン時にキャンセルされるコンテキストと登録解除関数を返します。
+// 登録解除関数は冪等で、ハンドラーの終了時（panic・切断を含む）に必ず呼び出します。
+// 排出中は登録せず ok=false を返します。
+func (r *Registry) Register(parent context.Context) (ctx context.Context, release func(), ok bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.draining {
+		return parent, func() {}, false
+	}
+
+	ctx, cancel := context.WithCancelCause(parent)
+	id := r.nextID
+	r.nextID++
+	r.streams[id] = cancel
+
+	var once sync.Once
+	release = func() {
+		once.Do(func() {
+			cancel(context.Canceled)
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			delete(r.streams, id)
+			if r.draining && len(r.streams) == 0 && r.idle != nil {
+				close(r.idle)
+				r.idle = nil
+			}
+		})
+	}
+	return ctx, release, true
+}
+
+// Len は登録中のストリーム数を返します。
+func (r *Registry) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.streams)
+}
+
+// Draining は排出中（新規ストリームを受け付けない状態）かを返します。
+func (r *Registry) Draining() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.draining
+}
+
+// Drain は新規ストリームの受け付けを停止し、既存ストリームに終了を通知して全ストリームの終了を待ちます。
+// ctx の期限（排出予算）までに終了しなかった場合は残数を含むエラーを返します。
+// 2 回目以降の呼び出しは通知を繰り返さず、終了の待機のみを行います。
+func (r *Registry) Drain(ctx context.Context) error {
+	r.mu.Lock()
+	if !r.draining {
+		r.draining = true
+		for _, cancel := range r.streams {
+			cancel(ErrShuttingDown)
+		}
+	}
+	if len(r.streams) == 0 {
+		r.mu.Unlock()
+		return nil
+	}
+	if r.idle == nil {
+		r.idle = make(chan struct{})
+	}
+	idle := r.idle
+	r.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("stream drain: %d stream(s) still open: %w", r.Len(), ctx.Err())
+	}
+}
+
+// Middleware はリクエストをストリームとして登録するミドルウェアを返します。
+// 排出中の新規リクエストには 503 と Retry-After を返し、別インスタンスへの再試行を促します。
+// ハンドラーには Register のコンテキストを渡し、終了時（panic を含む）に登録を解除します。
+func (r *Registry) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			ctx, release, ok := r.Register(req.Context())
+			if !ok {
+				w.Header().Set("Retry-After", "1")
+				httpx.WriteJSON(w, http.StatusServiceUnavailable, api.ErrorResponse{Error: "server is shutting down"})
+				return
+			}
+			defer release()
+			next.ServeHTTP(w, req.WithContext(ctx))
+		})
+	}
+}
+
+// ShuttingDown は ctx がサーバーのシャットダウンによりキャンセルされたかを返します。
+// クライアントの切断によるキャンセルと区別し、再接続イベントを送るかの判定に使います。
+func ShuttingDown(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrShuttingDown)
+}
+
+// WriteReconnect は SSE の再接続イベント（event: reconnect）を書き込んでフラッシュします。
+// シャットダウンを検知した SSE ハンドラーが戻る直前に呼び出し、クライアントに別インスタンスへの再接続を促します。
+func WriteReconnect(w http.ResponseWriter) error {
+	if _, err := fmt.Fprint(w, "event: reconnect\ndata: {}\n\n"); err != nil {
+		return err
+	}
+	return http.NewResponseController(w).Flush()
+}
@@ -0,0 +1,129 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSSE はシャットダウンまでイベントを待ち、シャットダウン時は再接続イベントを送って戻る SSE ハンドラーです。
+func fakeSSE(started *sync.WaitGroup) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		started.Done()
+		<-r.Context().Done()
+		if ShuttingDown(r.Context()) {
+			_ = WriteReconnect(w)
+		}
+	}
+}
+
+func TestRegistry_DrainSignalsAllStreams(t *testing.T) {
+	t.Parallel()
+
+	const n = 5
+	reg := NewRegistry()
+	var started sync.WaitGroup
+	started.Add(n)
+	h := reg.Middleware()(fakeSSE(&started))
+
+	recorders := make([]*httptest.ResponseRecorder, n)
+	var finished sync.WaitGroup
+	for i := range n {
+		recorders[i] = httptest.NewRecorder()
+		finished.Add(1)
+		go func() {
+			defer finished.Done()
+			h.ServeHTTP(recorders[i], httptest.NewRequest(http.MethodGet, "/stream", nil))
+		}()
+	}
+	started.Wait()
+	require.Equal(t, n, reg.Len())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	require.NoError(t, reg.Drain(ctx))
+	assert.Less(t, time.Since(start), time.Second, "排出予算内に全ストリームが終了すること")
+
+	finished.Wait()
+	for i, w := range recorders {
+		assert.Contains(t, w.Body.String(), "event: reconnect", "stream %d should receive the reconnect event", i)
+	}
+	assert.Zero(t, reg.Len(), "排出後はレジストリが空であること")
+}
+
+func TestRegistry_RejectsNewStreamsWhileDraining(t *testing.T) {
+	t.Parallel()
+
+	reg := NewRegistry()
+	require.NoError(t, reg.Drain(context.Background()))
+	assert.True(t, reg.Draining())
+
+	called := false
+	h := reg.Middleware()(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { called = true }))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stream", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{"error":"server is shutting down"}`, w.Body.String())
+	assert.False(t, called, "排出中はハンドラーを呼ばないこと")
+	assert.Zero(t, reg.Len())
+}
+
+func TestRegistry_BudgetExceeded(t *testing.T) {
+	t.Parallel()
+
+	reg := NewRegistry()
+	_, release, ok := reg.Register(context.Background())
+	require.True(t, ok)
+
+	// 終了通知を無視するストリームは排出予算の経過でエラーになる
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := reg.Drain(ctx)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.True(t, strings.Contains(err.Error(), "1 stream(s) still open"), err.Error())
+
+	release()
+	release() // 冪等
+	assert.Zero(t, reg.Len())
+	assert.NoError(t, reg.Drain(context.Background()), "全ストリーム終了後の Drain は即座に戻る")
+}
+
+func TestRegistry_ReleaseOnPanicAndDisconnect(t *testing.T) {
+	t.Parallel()
+
+	reg := NewRegistry()
+
+	t.Run("panic", func(t *testing.T) {
+		h := reg.Middleware()(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { panic("boom") }))
+		assert.Panics(t, func() {
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/stream", nil))
+		})
+		assert.Zero(t, reg.Len(), "panic でも登録が解除されること")
+	})
+
+	t.Run("クライアント切断", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var shuttingDown bool
+		h := reg.Middleware()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			cancel()
+			<-r.Context().Done()
+			shuttingDown = ShuttingDown(r.Context())
+		}))
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/stream", nil).WithContext(ctx))
+		assert.False(t, shuttingDown, "切断はシャットダウンと区別されること")
+		assert.Zero(t, reg.Len())
+	})
+}